@@ -6,17 +6,20 @@ Provides REST endpoints for:
 - Manual entity resync
 - Viewing inconsistencies and alerts
 - Generating integrity reports
+- Replaying chaincode events to downstream consumers
 """
 
 from datetime import datetime
 from typing import List, Optional, Dict, Any
-from fastapi import APIRouter, HTTPException, Query, Path, BackgroundTasks
+from fastapi import APIRouter, HTTPException, Query, Path, BackgroundTasks, Depends
 from pydantic import BaseModel, Field
 import structlog
 
 from .consistency_checker import consistency_checker, SeverityLevel, InconsistencyType
 from .consistency_monitoring import consistency_monitor
 from .service import event_listener
+from .replay import event_replay_service, ReplayFilter, EventReplayError
+from shared.auth import Actor, Role, require_roles
 from .models import (
     ReconciliationRequest,
     ManualResyncRequest,
@@ -24,12 +27,15 @@ from .models import (
     ReconciliationResponse,
     AlertResponse,
     ConsistencySummaryResponse,
-    IntegrityReportResponse
+    IntegrityReportResponse,
+    EventReplayRequest,
+    EventReplayResponse
 )
 
 logger = structlog.get_logger(__name__)
 
 router = APIRouter(prefix="/consistency", tags=["Data Consistency"])
+replay_router = APIRouter(prefix="/events", tags=["Event Replay"])
 
 
 # Request/Response Models are imported from models.py
@@ -424,4 +430,71 @@ async def stop_monitoring():
 # Include router in main application
 def get_consistency_router():
     """Get the consistency checking router."""
-    return router
\ No newline at end of file
+    return router
+
+
+@replay_router.post("/replay", response_model=EventReplayResponse)
+async def replay_events(
+    request: EventReplayRequest,
+    current_user: Actor = Depends(require_roles(Role.SYSTEM_ADMINISTRATOR))
+):
+    """
+    Replay chaincode events for a block range.
+    
+    Events are read from committed blocks, not produced by re-executing
+    transactions, and re-emitted to consumers with a replay marker carrying
+    the original transaction's deduplication key.
+    """
+    try:
+        logger.info("Starting event replay via API",
+                   start_block=request.start_block,
+                   end_block=request.end_block,
+                   entity_id=request.entity_id,
+                   actor_id=current_user.actor_id)
+        
+        report = await event_replay_service.replay(
+            ReplayFilter(
+                start_block=request.start_block,
+                end_block=request.end_block,
+                entity_id=request.entity_id,
+                chaincodes=request.chaincodes,
+                event_types=request.event_types
+            ),
+            reprocess=request.reprocess,
+            requested_by=current_user.actor_id
+        )
+        
+        return EventReplayResponse(**report.to_dict())
+        
+    except EventReplayError as e:
+        raise HTTPException(status_code=400, detail=str(e))
+    except Exception as e:
+        logger.error("Event replay failed via API", error=str(e))
+        raise HTTPException(status_code=500, detail=f"Event replay failed: {str(e)}")
+
+
+@replay_router.get("/replay", response_model=List[EventReplayResponse])
+async def get_replay_history(
+    limit: int = Query(20, ge=1, le=100, description="Maximum number of replays to return"),
+    current_user: Actor = Depends(require_roles(Role.SYSTEM_ADMINISTRATOR))
+):
+    """Get the most recent event replays, newest first."""
+    return [EventReplayResponse(**report.to_dict())
+            for report in event_replay_service.get_replay_history(limit)]
+
+
+@replay_router.get("/replay/{replay_id}", response_model=EventReplayResponse)
+async def get_replay(
+    replay_id: str = Path(..., description="Replay ID"),
+    current_user: Actor = Depends(require_roles(Role.SYSTEM_ADMINISTRATOR))
+):
+    """Get the report of an event replay."""
+    report = event_replay_service.get_replay(replay_id)
+    if not report:
+        raise HTTPException(status_code=404, detail=f"Replay {replay_id} not found")
+    return EventReplayResponse(**report.to_dict())
+
+
+def get_event_replay_router():
+    """Get the event replay router."""
+    return replay_router
@@ -81,4 +81,34 @@ class IntegrityReportResponse(BaseModel):
     inconsistency_summary: ConsistencySummaryResponse
     recommendations: List[str]
     success: bool
-    error: Optional[str] = None
\ No newline at end of file
+    error: Optional[str] = None
+
+class EventReplayRequest(BaseModel):
+    """Request model for replaying chaincode events."""
+    start_block: int = Field(ge=0, description="First block to replay")
+    end_block: int = Field(ge=0, description="Last block to replay, inclusive")
+    entity_id: Optional[str] = Field(default=None, description="Only replay events concerning this entity")
+    chaincodes: Optional[List[str]] = Field(default=None, description="Only replay events from these chaincodes")
+    event_types: Optional[List[str]] = Field(default=None, description="Only replay these event names")
+    reprocess: bool = Field(default=False, description="Reprocess events consumers have already seen")
+
+
+class EventReplayResponse(BaseModel):
+    """Response model for event replay results."""
+    replay_id: str
+    start_block: int
+    end_block: int
+    entity_id: Optional[str] = None
+    chaincodes: Optional[List[str]] = None
+    event_types: Optional[List[str]] = None
+    reprocess: bool
+    requested_by: Optional[str] = None
+    started_at: datetime
+    completed_at: Optional[datetime] = None
+    blocks_scanned: int
+    events_matched: int
+    events_delivered: int
+    events_failed: int
+    events_skipped: int
+    success: bool
+    errors: List[str]
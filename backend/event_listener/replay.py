@@ -0,0 +1,289 @@
+"""
+Event replay for downstream consumers that lost data.
+
+This module provides functionality to:
+- Re-emit chaincode events for a block range, read from committed blocks
+  rather than by re-executing transactions
+- Narrow a replay to a single entity, chaincode or event type
+- Tag every replayed event with a replay marker so consumers can deduplicate
+  against events they already hold
+"""
+
+import json
+import uuid
+from datetime import datetime
+from typing import Dict, Any, List, Optional, Callable, Awaitable
+from dataclasses import dataclass, field
+
+import structlog
+
+from shared.fabric_gateway import get_fabric_gateway, FabricError
+from .service import event_listener
+
+logger = structlog.get_logger(__name__)
+
+# Key under which replayed events carry their replay marker
+REPLAY_MARKER_KEY = 'replay'
+
+# Largest block range a single replay may scan
+MAX_REPLAY_BLOCKS = 10000
+
+# Payload keys that identify the entity an event concerns, for events
+# emitted before the standard entityID field
+LEGACY_ENTITY_KEYS = ('customerID', 'loanApplicationID', 'loanID')
+
+
+class EventReplayError(Exception):
+    """Raised when a replay request is invalid or cannot be completed."""
+    pass
+
+
+@dataclass
+class ReplayFilter:
+    """Selects the events a replay re-emits."""
+    start_block: int
+    end_block: int
+    entity_id: Optional[str] = None
+    chaincodes: Optional[List[str]] = None
+    event_types: Optional[List[str]] = None
+
+    def validate(self):
+        """Reject ranges that are empty, negative or too large to replay at once."""
+        if self.start_block < 0 or self.end_block < self.start_block:
+            raise EventReplayError(
+                f"Invalid block range {self.start_block}-{self.end_block}"
+            )
+        if self.end_block - self.start_block + 1 > MAX_REPLAY_BLOCKS:
+            raise EventReplayError(
+                f"Block range exceeds the maximum of {MAX_REPLAY_BLOCKS} blocks per replay"
+            )
+
+    def matches(self, raw_event: Dict[str, Any], payload: Dict[str, Any]) -> bool:
+        """Check whether an event read from the ledger is selected by this filter."""
+        if self.chaincodes and raw_event.get('chaincodeId') not in self.chaincodes:
+            return False
+        if self.event_types and raw_event.get('eventName') not in self.event_types:
+            return False
+        if self.entity_id and self.entity_id not in event_entity_ids(payload):
+            return False
+        return True
+
+
+@dataclass
+class ReplayReport:
+    """Outcome of a replay."""
+    replay_id: str
+    replay_filter: ReplayFilter
+    reprocess: bool
+    requested_by: Optional[str]
+    started_at: datetime
+    completed_at: Optional[datetime] = None
+    blocks_scanned: int = 0
+    events_matched: int = 0
+    events_delivered: int = 0
+    events_failed: int = 0
+    events_skipped: int = 0  # Matched, but of a type the consumer does not handle
+    success: bool = False
+    errors: List[str] = field(default_factory=list)
+
+    def to_dict(self) -> Dict[str, Any]:
+        """Convert to dictionary for serialization."""
+        return {
+            'replay_id': self.replay_id,
+            'start_block': self.replay_filter.start_block,
+            'end_block': self.replay_filter.end_block,
+            'entity_id': self.replay_filter.entity_id,
+            'chaincodes': self.replay_filter.chaincodes,
+            'event_types': self.replay_filter.event_types,
+            'reprocess': self.reprocess,
+            'requested_by': self.requested_by,
+            'started_at': self.started_at.isoformat(),
+            'completed_at': self.completed_at.isoformat() if self.completed_at else None,
+            'blocks_scanned': self.blocks_scanned,
+            'events_matched': self.events_matched,
+            'events_delivered': self.events_delivered,
+            'events_failed': self.events_failed,
+            'events_skipped': self.events_skipped,
+            'success': self.success,
+            'errors': self.errors
+        }
+
+
+def event_dedup_key(raw_event: Dict[str, Any]) -> str:
+    """
+    Key identifying an original chaincode event.
+
+    Matches the key the event listener deduplicates live events on, so a
+    replayed event and its live original share it.
+    """
+    return f"{raw_event.get('txId', '')}_{raw_event.get('eventName', '')}"
+
+
+def event_entity_ids(payload: Dict[str, Any]) -> List[str]:
+    """Collect the entity IDs an event payload refers to."""
+    entity_ids = []
+    for source in (payload, payload.get('data') if isinstance(payload.get('data'), dict) else {}):
+        for key in ('entityID',) + LEGACY_ENTITY_KEYS:
+            value = source.get(key)
+            if value and value not in entity_ids:
+                entity_ids.append(value)
+    return entity_ids
+
+
+def decode_event_payload(raw_event: Dict[str, Any]) -> Dict[str, Any]:
+    """Decode a raw event's JSON payload, returning an empty dict if it is not a JSON object."""
+    payload = raw_event.get('payload', b'')
+    try:
+        if isinstance(payload, bytes):
+            payload = json.loads(payload.decode('utf-8'))
+        elif isinstance(payload, str):
+            payload = json.loads(payload)
+    except (ValueError, UnicodeDecodeError):
+        return {}
+    return payload if isinstance(payload, dict) else {}
+
+
+def mark_replayed(raw_event: Dict[str, Any], replay_id: str, sequence: int,
+                  reprocess: bool) -> Dict[str, Any]:
+    """Return a copy of an event carrying a replay marker."""
+    marked = dict(raw_event)
+    marked[REPLAY_MARKER_KEY] = {
+        'replayId': replay_id,
+        'sequence': sequence,
+        'dedupKey': event_dedup_key(raw_event),
+        'originalBlockNumber': raw_event.get('blockNumber'),
+        'originalTxId': raw_event.get('txId'),
+        'replayedAt': datetime.utcnow().isoformat(),
+        'reprocess': reprocess
+    }
+    return marked
+
+
+def get_replay_marker(raw_event: Dict[str, Any]) -> Optional[Dict[str, Any]]:
+    """Return an event's replay marker, or None for live events."""
+    return raw_event.get(REPLAY_MARKER_KEY)
+
+
+class EventReplayService:
+    """Re-emits committed chaincode events to downstream consumers."""
+
+    def __init__(self, consumer: Optional[Callable[[Dict[str, Any]], Awaitable[bool]]] = None,
+                 supported_event_types: Optional[List[str]] = None):
+        # Replayed events go through the same entry point as live events
+        self.consumer = consumer or event_listener.process_raw_event
+        self.supported_event_types = set(
+            supported_event_types or event_listener.get_supported_event_types()
+        )
+        self.replay_history: List[ReplayReport] = []
+        self.max_history = 100
+
+    async def replay(self, replay_filter: ReplayFilter, reprocess: bool = False,
+                     requested_by: Optional[str] = None) -> ReplayReport:
+        """
+        Re-emit the events in a block range that match a filter.
+
+        Args:
+            replay_filter: Block range and optional entity, chaincode and event type filters
+            reprocess: Whether consumers should reprocess events they have already seen
+            requested_by: Actor requesting the replay, for the audit trail
+
+        Returns:
+            Report of the replay
+
+        Raises:
+            EventReplayError: If the filter is invalid
+        """
+        replay_filter.validate()
+
+        report = ReplayReport(
+            replay_id=f"REPLAY_{uuid.uuid4().hex[:12]}",
+            replay_filter=replay_filter,
+            reprocess=reprocess,
+            requested_by=requested_by,
+            started_at=datetime.utcnow()
+        )
+
+        logger.info("Starting event replay",
+                   replay_id=report.replay_id,
+                   start_block=replay_filter.start_block,
+                   end_block=replay_filter.end_block,
+                   entity_id=replay_filter.entity_id,
+                   requested_by=requested_by)
+
+        try:
+            gateway = await get_fabric_gateway()
+
+            for block_number in range(replay_filter.start_block, replay_filter.end_block + 1):
+                raw_events = await gateway.get_block_events(block_number)
+                report.blocks_scanned += 1
+
+                for raw_event in raw_events:
+                    await self._replay_event(raw_event, replay_filter, report)
+
+            report.success = report.events_failed == 0
+
+        except FabricError as e:
+            logger.error("Event replay aborted reading the ledger",
+                        replay_id=report.replay_id, error=str(e))
+            report.errors.append(f"Failed to read block {replay_filter.start_block + report.blocks_scanned}: {e}")
+            report.success = False
+
+        report.completed_at = datetime.utcnow()
+        self._record(report)
+
+        logger.info("Completed event replay",
+                   replay_id=report.replay_id,
+                   blocks_scanned=report.blocks_scanned,
+                   events_matched=report.events_matched,
+                   events_delivered=report.events_delivered,
+                   events_failed=report.events_failed,
+                   events_skipped=report.events_skipped)
+
+        return report
+
+    async def _replay_event(self, raw_event: Dict[str, Any], replay_filter: ReplayFilter,
+                            report: ReplayReport):
+        """Re-emit a single event if it matches the filter."""
+        if not replay_filter.matches(raw_event, decode_event_payload(raw_event)):
+            return
+
+        report.events_matched += 1
+        if raw_event.get('eventName') not in self.supported_event_types:
+            report.events_skipped += 1
+            return
+
+        marked = mark_replayed(raw_event, report.replay_id, report.events_matched, report.reprocess)
+
+        try:
+            delivered = await self.consumer(marked)
+        except Exception as e:
+            logger.error("Failed to deliver replayed event",
+                        replay_id=report.replay_id, tx_id=raw_event.get('txId'), error=str(e))
+            delivered = False
+
+        if delivered:
+            report.events_delivered += 1
+        else:
+            report.events_failed += 1
+            report.errors.append(f"Consumer rejected event {event_dedup_key(raw_event)}")
+
+    def _record(self, report: ReplayReport):
+        """Keep a bounded history of replays for operators."""
+        self.replay_history.append(report)
+        if len(self.replay_history) > self.max_history:
+            self.replay_history = self.replay_history[-self.max_history:]
+
+    def get_replay(self, replay_id: str) -> Optional[ReplayReport]:
+        """Get a replay report by ID."""
+        for report in self.replay_history:
+            if report.replay_id == replay_id:
+                return report
+        return None
+
+    def get_replay_history(self, limit: int = 20) -> List[ReplayReport]:
+        """Get the most recent replay reports, newest first."""
+        return list(reversed(self.replay_history[-limit:]))
+
+
+# Global event replay service instance
+event_replay_service = EventReplayService()
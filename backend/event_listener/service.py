@@ -949,6 +949,7 @@ class EventListenerService:
             'successful_events': 0,
             'failed_events': 0,
             'duplicate_events': 0,
+            'replayed_events': 0,
             'last_sync_time': None
         }
     
@@ -1054,9 +1055,14 @@ class EventListenerService:
                 self.sync_stats['failed_events'] += 1
                 return False
             
-            # Check for duplicates
+            # Check for duplicates. Replayed events share their original's key, and are
+            # only processed again when the replay asks consumers to reprocess
             event_key = f"{event.transaction_id}_{event.event_type.value}"
-            if event_key in self.processed_events:
+            replay_marker = raw_event.get('replay')
+            if replay_marker:
+                self.sync_stats['replayed_events'] += 1
+            reprocess = bool(replay_marker and replay_marker.get('reprocess'))
+            if event_key in self.processed_events and not reprocess:
                 logger.debug("Skipping duplicate event", event_key=event_key)
                 self.sync_stats['duplicate_events'] += 1
                 return True
@@ -1189,6 +1195,7 @@ class EventListenerService:
             'successful_events': 0,
             'failed_events': 0,
             'duplicate_events': 0,
+            'replayed_events': 0,
             'last_sync_time': None
         }
         logger.info("Synchronization statistics reset")
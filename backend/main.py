@@ -7,7 +7,7 @@ from fastapi.middleware.cors import CORSMiddleware
 from customer_mastery.api import router as customer_router
 from loan_origination.api import router as loan_router
 from compliance_reporting.api import router as compliance_router
-from event_listener.api import get_consistency_router
+from event_listener.api import get_consistency_router, get_event_replay_router
 from shared.config import settings
 
 app = FastAPI(
@@ -30,6 +30,7 @@ app.include_router(customer_router, prefix="/api/v1/customers", tags=["customers
 app.include_router(loan_router, prefix="/api/v1/loans", tags=["loans"])
 app.include_router(compliance_router, prefix="/api/v1/compliance", tags=["compliance"])
 app.include_router(get_consistency_router(), prefix="/api/v1", tags=["consistency"])
+app.include_router(get_event_replay_router(), prefix="/api/v1", tags=["events"])
 
 @app.get("/")
 async def root():
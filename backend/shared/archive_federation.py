@@ -0,0 +1,434 @@
+"""
+Archive-aware query federation.
+
+Compliance events and customer event streams older than the hot retention
+window are copied to the archive store and then pruned from the ledger. List
+queries served through the gateway span both tiers so callers never need to
+know where a record lives: archived records come first (they are always the
+oldest), followed by the chaincode's hot results, and every record is marked
+with the tier it came from in its ``sourceTier`` field.
+
+Each archive partition has a watermark, the timestamp up to which its records
+have been archived. Hot records at or before the watermark are skipped, since
+they are already served from the archive and are only awaiting pruning.
+
+Pagination behaves as it does against chaincode: a page holds at most
+page_size records, only the final page has an empty bookmark, and a page is
+only short when it is the last. The bookmark is an opaque cursor naming the
+tier to resume in and that tier's own cursor (an archive offset or a chaincode
+bookmark).
+"""
+
+import base64
+import json
+import os
+import threading
+from dataclasses import dataclass, field
+from datetime import datetime, timezone
+from typing import Any, Callable, Dict, List, Optional, Tuple
+
+import structlog
+
+from .config import settings
+
+logger = structlog.get_logger(__name__)
+
+TIER_HOT = "HOT"
+TIER_ARCHIVE = "ARCHIVE"
+
+SOURCE_TIER_FIELD = "sourceTier"
+
+# Chaincode page size bounds (config.DefaultPageSize and config.MaxPageSize)
+DEFAULT_PAGE_SIZE = 20
+MAX_PAGE_SIZE = 100
+
+# Partition used for namespaces that are not partitioned by entity
+ALL_PARTITION = "_all"
+
+
+class FederationError(Exception):
+    """Raised when a federated query or its cursor is invalid."""
+    pass
+
+
+def parse_timestamp(value: Any) -> Optional[datetime]:
+    """Parse an RFC 3339 timestamp as written by the chaincode, or a YYYY-MM-DD date."""
+    if isinstance(value, datetime):
+        return value if value.tzinfo else value.replace(tzinfo=timezone.utc)
+    if not value or not isinstance(value, str):
+        return None
+    text = value.strip()
+    if text.endswith("Z") or text.endswith("z"):
+        text = text[:-1] + "+00:00"
+    # Go writes up to nine fractional digits; datetime accepts at most six
+    if "." in text:
+        head, _, rest = text.partition(".")
+        digits = ""
+        while rest and rest[0].isdigit():
+            digits, rest = digits + rest[0], rest[1:]
+        text = f"{head}.{digits[:6].ljust(6, '0')}{rest}"
+    try:
+        parsed = datetime.fromisoformat(text)
+    except ValueError:
+        return None
+    return parsed if parsed.tzinfo else parsed.replace(tzinfo=timezone.utc)
+
+
+@dataclass
+class ArchivePage:
+    """A page of archived records, oldest first, and the cursor for the next ("" when done)."""
+    records: List[Dict[str, Any]]
+    next_cursor: str = ""
+
+
+class ArchiveStore:
+    """Storage for records moved off the ledger, grouped by namespace and partition."""
+
+    async def fetch(self, namespace: str, partition: str, cursor: str, limit: int) -> ArchivePage:
+        """Return up to limit records after cursor ("" for the start), oldest first."""
+        raise NotImplementedError
+
+    async def watermark(self, namespace: str, partition: str) -> Optional[datetime]:
+        """Return the timestamp up to which the partition has been archived, if any."""
+        raise NotImplementedError
+
+
+class InMemoryArchiveStore(ArchiveStore):
+    """Archive store held in memory, for tests and local development."""
+
+    def __init__(self):
+        self._records: Dict[Tuple[str, str], List[Dict[str, Any]]] = {}
+        self._watermarks: Dict[Tuple[str, str], datetime] = {}
+        self._lock = threading.Lock()
+
+    def archive(self, namespace: str, partition: str, records: List[Dict[str, Any]], watermark: Any) -> None:
+        """Add records to a partition and advance its watermark."""
+        watermark_time = parse_timestamp(watermark)
+        if watermark_time is None:
+            raise FederationError(f"invalid archive watermark {watermark!r}")
+
+        key = (namespace, partition or ALL_PARTITION)
+        with self._lock:
+            stored = self._records.setdefault(key, [])
+            stored.extend(records)
+            stored.sort(key=lambda record: parse_timestamp(record.get("timestamp")) or datetime.min.replace(tzinfo=timezone.utc))
+            current = self._watermarks.get(key)
+            if current is None or watermark_time > current:
+                self._watermarks[key] = watermark_time
+
+    async def fetch(self, namespace: str, partition: str, cursor: str, limit: int) -> ArchivePage:
+        offset = _parse_offset(cursor)
+        with self._lock:
+            stored = list(self._records.get((namespace, partition or ALL_PARTITION), []))
+        page = stored[offset:offset + limit]
+        next_offset = offset + len(page)
+        return ArchivePage(records=[dict(record) for record in page],
+                           next_cursor=str(next_offset) if next_offset < len(stored) else "")
+
+    async def watermark(self, namespace: str, partition: str) -> Optional[datetime]:
+        with self._lock:
+            return self._watermarks.get((namespace, partition or ALL_PARTITION))
+
+
+class JsonlArchiveStore(ArchiveStore):
+    """
+    Archive store kept as files by the archival job::
+
+        <root>/<namespace>/<partition>.jsonl     one record per line, oldest first
+        <root>/<namespace>/watermarks.json       {"<partition>": "<RFC 3339 timestamp>"}
+    """
+
+    def __init__(self, root: str):
+        self.root = root
+
+    def _partition_path(self, namespace: str, partition: str) -> str:
+        name = partition or ALL_PARTITION
+        if os.sep in name or (os.altsep and os.altsep in name) or name in (".", ".."):
+            raise FederationError(f"invalid archive partition {partition!r}")
+        return os.path.join(self.root, namespace, f"{name}.jsonl")
+
+    async def fetch(self, namespace: str, partition: str, cursor: str, limit: int) -> ArchivePage:
+        offset = _parse_offset(cursor)
+        path = self._partition_path(namespace, partition)
+        if not os.path.exists(path):
+            return ArchivePage(records=[])
+
+        records: List[Dict[str, Any]] = []
+        has_more = False
+        with open(path, "r", encoding="utf-8") as archive_file:
+            index = 0
+            for line in archive_file:
+                if not line.strip():
+                    continue
+                if index >= offset + limit:
+                    has_more = True
+                    break
+                if index >= offset:
+                    records.append(json.loads(line))
+                index += 1
+        return ArchivePage(records=records, next_cursor=str(offset + len(records)) if has_more else "")
+
+    async def watermark(self, namespace: str, partition: str) -> Optional[datetime]:
+        path = os.path.join(self.root, namespace, "watermarks.json")
+        if not os.path.exists(path):
+            return None
+        with open(path, "r", encoding="utf-8") as watermark_file:
+            watermarks = json.load(watermark_file)
+        return parse_timestamp(watermarks.get(partition or ALL_PARTITION))
+
+
+def _parse_offset(cursor: str) -> int:
+    if not cursor:
+        return 0
+    try:
+        offset = int(cursor)
+    except ValueError:
+        raise FederationError(f"invalid archive cursor {cursor!r}")
+    if offset < 0:
+        raise FederationError(f"invalid archive cursor {cursor!r}")
+    return offset
+
+
+@dataclass
+class FederatedQuery:
+    """
+    A paged chaincode query whose records may also be archived.
+
+    The chaincode function takes scope_args scoping arguments followed by the
+    page size and bookmark, and returns its records under records_field with a
+    bookmark. partition picks the archive partition from the scoping arguments
+    and matches decides whether an archived record belongs in the results, as
+    the chaincode's own filters would.
+    """
+    chaincode: str
+    function: str
+    namespace: str
+    records_field: str
+    id_field: str
+    scope_args: int
+    timestamp_field: str = "timestamp"
+    partition: Callable[[List[str]], str] = lambda args: ALL_PARTITION
+    matches: Callable[[List[str], Dict[str, Any]], bool] = lambda args, record: True
+
+
+def _stream_matches(args: List[str], record: Dict[str, Any]) -> bool:
+    """Apply GetCustomerEventStream's since argument to an archived entry."""
+    since = parse_timestamp(args[1]) if len(args) > 1 else None
+    if since is None:
+        return True
+    timestamp = parse_timestamp(record.get("timestamp"))
+    return timestamp is not None and timestamp >= since
+
+
+def _compliance_event_matches(args: List[str], record: Dict[str, Any]) -> bool:
+    """Apply a QueryComplianceEvents selector to an archived event."""
+    try:
+        selector = json.loads(args[0]) if args and args[0] else {}
+    except ValueError:
+        raise FederationError("failed to unmarshal selector")
+
+    for name in ("eventType", "actorID", "ruleID", "affectedEntityID", "severity", "resolutionStatus"):
+        if selector.get(name) and record.get(name) != selector[name]:
+            return False
+    if selector.get("isAlerted") is not None and bool(record.get("isAlerted")) != selector["isAlerted"]:
+        return False
+
+    timestamp = parse_timestamp(record.get("timestamp"))
+    date_from = parse_timestamp(selector.get("dateFrom"))
+    date_to = parse_timestamp(selector.get("dateTo"))
+    if date_from is not None and (timestamp is None or timestamp < date_from):
+        return False
+    if date_to is not None and (timestamp is None or timestamp > date_to):
+        return False
+    return True
+
+
+# Queries that span the hot and archive tiers, keyed by (chaincode, function)
+FEDERATED_QUERIES: Dict[Tuple[str, str], FederatedQuery] = {
+    ("customer", "GetCustomerEventStream"): FederatedQuery(
+        chaincode="customer",
+        function="GetCustomerEventStream",
+        namespace="customer_event_stream",
+        records_field="entries",
+        id_field="entryID",
+        scope_args=2,  # customerID, since
+        partition=lambda args: args[0],
+        matches=_stream_matches,
+    ),
+    ("compliance", "QueryComplianceEvents"): FederatedQuery(
+        chaincode="compliance",
+        function="QueryComplianceEvents",
+        namespace="compliance_events",
+        records_field="events",
+        id_field="eventID",
+        scope_args=1,  # selector JSON
+        matches=_compliance_event_matches,
+    ),
+}
+
+
+@dataclass
+class FederatedCursor:
+    """Position in a federated query: the tier to resume in and that tier's cursor."""
+    tier: str = TIER_ARCHIVE
+    cursor: str = ""
+
+    def encode(self) -> str:
+        raw = json.dumps({"tier": self.tier, "cursor": self.cursor}, separators=(",", ":"))
+        return base64.urlsafe_b64encode(raw.encode("utf-8")).decode("ascii")
+
+    @classmethod
+    def decode(cls, bookmark: str) -> "FederatedCursor":
+        if not bookmark:
+            return cls()
+        try:
+            raw = json.loads(base64.urlsafe_b64decode(bookmark.encode("ascii")))
+            tier, cursor = raw["tier"], raw.get("cursor", "")
+        except (ValueError, KeyError, TypeError):
+            raise FederationError("invalid bookmark")
+        if tier not in (TIER_ARCHIVE, TIER_HOT) or not isinstance(cursor, str):
+            raise FederationError("invalid bookmark")
+        return cls(tier=tier, cursor=cursor)
+
+
+@dataclass
+class FederatedPage:
+    """A page of records from both tiers, oldest first, with the bookmark for the next page."""
+    records: List[Dict[str, Any]] = field(default_factory=list)
+    bookmark: str = ""
+    archive_count: int = 0
+    hot_count: int = 0
+
+    def to_payload(self, records_field: str) -> Dict[str, Any]:
+        """Shape the page like the chaincode's own result."""
+        return {
+            records_field: self.records,
+            "count": len(self.records),
+            "bookmark": self.bookmark,
+        }
+
+
+class ArchiveFederation:
+    """Serves federated queries by merging archived records with chaincode results."""
+
+    def __init__(self, gateway: Any, store: ArchiveStore,
+                 queries: Optional[Dict[Tuple[str, str], FederatedQuery]] = None):
+        self.gateway = gateway
+        self.store = store
+        self.queries = queries if queries is not None else FEDERATED_QUERIES
+
+    def is_federated(self, chaincode: str, function: str) -> bool:
+        return (chaincode, function) in self.queries
+
+    async def query(self, chaincode: str, function: str, args: List[str],
+                    page_size: int = DEFAULT_PAGE_SIZE, bookmark: str = "") -> FederatedPage:
+        """
+        Run a federated query and return one page spanning both tiers.
+
+        Args:
+            chaincode: Chaincode the query belongs to
+            function: Chaincode function name
+            args: The function's scoping arguments, without page size and bookmark
+            page_size: Records per page, 1 to MAX_PAGE_SIZE
+            bookmark: Bookmark from the previous page, or "" for the first
+
+        Raises:
+            FederationError: If the query is not federated or the arguments are invalid
+        """
+        query = self.queries.get((chaincode, function))
+        if query is None:
+            raise FederationError(f"{chaincode}.{function} is not a federated query")
+        if len(args) != query.scope_args:
+            raise FederationError(f"incorrect number of arguments. Expected {query.scope_args}, got {len(args)}")
+        if page_size < 1 or page_size > MAX_PAGE_SIZE:
+            raise FederationError(f"page size must be between 1 and {MAX_PAGE_SIZE}, got {page_size}")
+
+        position = FederatedCursor.decode(bookmark)
+        partition = query.partition(args) or ALL_PARTITION
+        watermark = await self.store.watermark(query.namespace, partition)
+        page = FederatedPage()
+
+        if position.tier == TIER_ARCHIVE:
+            cursor = position.cursor
+            while len(page.records) < page_size:
+                archived = await self.store.fetch(query.namespace, partition, cursor, page_size - len(page.records))
+                for record in archived.records:
+                    if query.matches(args, record):
+                        page.records.append(_tag(record, TIER_ARCHIVE))
+                        page.archive_count += 1
+                cursor = archived.next_cursor
+                if not cursor:
+                    break
+            if cursor:
+                page.bookmark = FederatedCursor(TIER_ARCHIVE, cursor).encode()
+                return page
+            position = FederatedCursor(TIER_HOT, "")
+
+        # Records awaiting pruning are skipped by watermark, and by ID if served on this page
+        served = {record.get(query.id_field) for record in page.records}
+        cursor, exhausted = position.cursor, False
+        while len(page.records) < page_size:
+            payload = await self._query_hot(query, args, page_size - len(page.records), cursor)
+            records = payload.get(query.records_field) or []
+            for record in records:
+                if _archived(record, query, watermark) or record.get(query.id_field) in served:
+                    continue
+                page.records.append(_tag(record, TIER_HOT))
+                page.hot_count += 1
+            cursor = payload.get("bookmark") or ""
+            if not cursor or not records:
+                exhausted = not cursor
+                break
+        if not exhausted:
+            page.bookmark = FederatedCursor(TIER_HOT, cursor).encode()
+
+        logger.info("Federated query served",
+                    chaincode=chaincode,
+                    function=function,
+                    archive_records=page.archive_count,
+                    hot_records=page.hot_count,
+                    has_more=bool(page.bookmark))
+        return page
+
+    async def _query_hot(self, query: FederatedQuery, args: List[str], limit: int, bookmark: str) -> Dict[str, Any]:
+        result = await self.gateway.query_chaincode(query.chaincode, query.function, list(args) + [str(limit), bookmark])
+        payload = result.get("payload") if isinstance(result, dict) else None
+        if isinstance(payload, (str, bytes)):
+            payload = json.loads(payload) if payload else {}
+        return payload or {}
+
+
+def _tag(record: Dict[str, Any], tier: str) -> Dict[str, Any]:
+    tagged = dict(record)
+    tagged[SOURCE_TIER_FIELD] = tier
+    return tagged
+
+
+def _archived(record: Dict[str, Any], query: FederatedQuery, watermark: Optional[datetime]) -> bool:
+    """Whether a hot record falls within the archived range and is served from the archive."""
+    if watermark is None:
+        return False
+    timestamp = parse_timestamp(record.get(query.timestamp_field))
+    return timestamp is not None and timestamp <= watermark
+
+
+_archive_store: Optional[ArchiveStore] = None
+
+
+def get_archive_store() -> ArchiveStore:
+    """Archive store from settings; an empty in-memory store when ARCHIVE_STORE_DIR is unset."""
+    global _archive_store
+    if _archive_store is None:
+        if settings.ARCHIVE_STORE_DIR:
+            _archive_store = JsonlArchiveStore(settings.ARCHIVE_STORE_DIR)
+        else:
+            _archive_store = InMemoryArchiveStore()
+    return _archive_store
+
+
+async def query_federated(gateway: Any, chaincode: str, function: str, args: List[str],
+                          page_size: int = DEFAULT_PAGE_SIZE, bookmark: str = "") -> Dict[str, Any]:
+    """Run a federated query through the gateway and return a chaincode-shaped page."""
+    federation = ArchiveFederation(gateway, get_archive_store())
+    page = await federation.query(chaincode, function, args, page_size, bookmark)
+    return page.to_payload(federation.queries[(chaincode, function)].records_field)
@@ -1,7 +1,7 @@
 """
 Application configuration settings
 """
-from typing import List
+from typing import Dict, List
 from pydantic import ConfigDict
 from pydantic_settings import BaseSettings
 
@@ -29,6 +29,14 @@ class Settings(BaseSettings):
     ALGORITHM: str = "HS256"
     ACCESS_TOKEN_EXPIRE_MINUTES: int = 30
     
+    # Partner request signing: key ID -> {"partner_id", "secret", optional "client_cert_fingerprint"}
+    PARTNER_SIGNING_KEYS: Dict[str, Dict[str, str]] = {}
+    PARTNER_SIGNATURE_MAX_SKEW_SECONDS: int = 300  # Chaincode allows config.TransactionTimeout
+    PARTNER_CLIENT_CERT_HEADER: str = "X-Client-Cert-Fingerprint"  # Set by the TLS-terminating proxy
+    
+    # Archive store for records moved off the ledger; queries are served from the ledger alone when unset
+    ARCHIVE_STORE_DIR: str = ""
+    
     # Logging
     LOG_LEVEL: str = "INFO"
 
@@ -8,7 +8,7 @@ chaincode, including connection management, transaction invocation, and query op
 import asyncio
 import json
 import logging
-from typing import Any, Dict, List, Optional, Union
+from typing import TYPE_CHECKING, Any, Dict, List, Optional, Union
 from contextlib import asynccontextmanager
 from dataclasses import dataclass
 from enum import Enum
@@ -18,6 +18,9 @@ from tenacity import retry, stop_after_attempt, wait_exponential, retry_if_excep
 
 from .config import settings
 
+if TYPE_CHECKING:
+    from .request_signing import SignatureEvidence
+
 logger = structlog.get_logger(__name__)
 
 
@@ -195,6 +198,43 @@ class FabricGateway:
                         error=str(e))
             raise TransactionError(f"Failed to invoke {chaincode_name}.{function_name}: {e}")
     
+    async def invoke_chaincode_for_partner(
+        self,
+        chaincode_name: str,
+        function_name: str,
+        args: List[str],
+        evidence: "SignatureEvidence"
+    ) -> Dict[str, Any]:
+        """
+        Invoke a chaincode function on behalf of a verified partner request.
+
+        The request's signature evidence is bound to this payload and passed as
+        transient data, so it is checked by the chaincode and recorded on the
+        archived request and any disclosure without being broadcast in the
+        transaction proposal.
+
+        Args:
+            chaincode_name: Name of the chaincode to invoke
+            function_name: Function name to call
+            args: List of string arguments for the function
+            evidence: Evidence from request_signing.require_partner_signature
+
+        Returns:
+            Transaction result as dictionary
+        """
+        logger.info("Invoking chaincode for partner",
+                   chaincode=chaincode_name,
+                   function=function_name,
+                   partner_id=evidence.partner_id,
+                   algorithm=evidence.algorithm)
+
+        return await self.invoke_chaincode(
+            chaincode_name,
+            function_name,
+            args,
+            transient_data=evidence.to_transient(function_name, args)
+        )
+
     @retry(
         stop=stop_after_attempt(3),
         wait=wait_exponential(multiplier=1, min=2, max=8),
@@ -325,6 +365,45 @@ class FabricGateway:
                         error=str(e))
             raise QueryError(f"Failed to retrieve block {block_number}: {e}")
 
+    async def get_block_events(self, block_number: int) -> List[Dict[str, Any]]:
+        """
+        Read the chaincode events committed in a block.
+
+        Events are read from the block as stored on the ledger; no transaction
+        is re-executed. Events of transactions that failed validation are
+        skipped, as peers never delivered them.
+
+        Args:
+            block_number: The block number to read
+
+        Returns:
+            Raw events in the shape delivered to event listeners
+
+        Raises:
+            QueryError: If block retrieval fails
+        """
+        block = await self.get_block_by_number(block_number)
+
+        events = []
+        for transaction in block.get("transactions", []):
+            if transaction.get("validation_code", "VALID") != "VALID":
+                continue
+
+            chaincode_event = transaction.get("chaincode_event")
+            if not chaincode_event or not chaincode_event.get("event_name"):
+                continue
+
+            events.append({
+                "eventName": chaincode_event["event_name"],
+                "chaincodeId": chaincode_event.get("chaincode_id", transaction.get("chaincode_name", "")),
+                "txId": chaincode_event.get("tx_id", transaction.get("transaction_id", "")),
+                "blockNumber": block_number,
+                "timestamp": transaction.get("timestamp", block.get("timestamp")),
+                "payload": chaincode_event.get("payload", b""),
+            })
+
+        return events
+
 
 class ChaincodeClient:
     """
@@ -0,0 +1,306 @@
+"""
+Request signing for external partner API calls.
+
+Partners sign each request with HMAC-SHA256 over a canonical form of the
+request, or authenticate with a client certificate on an mTLS connection. The
+gateway verifies the request and passes the resulting evidence to chaincode as
+transient data, where it is checked against the submitted payload and recorded
+on disclosure and audit entries.
+
+The canonical request is::
+
+    METHOD\\nPATH\\nTIMESTAMP\\nNONCE\\nHEX(SHA-256(BODY))
+
+sent with the headers X-Partner-Key-Id, X-Signature-Timestamp (RFC 3339),
+X-Signature-Nonce and X-Signature (hex HMAC).
+"""
+
+import hashlib
+import hmac
+import json
+import threading
+import time
+from dataclasses import dataclass
+from datetime import datetime
+from typing import Callable, Dict, List, Optional
+
+import structlog
+from fastapi import HTTPException, Request
+
+from .config import settings
+
+logger = structlog.get_logger(__name__)
+
+# Algorithms recorded in the evidence; must match the chaincode's services.RequestSignature*
+ALGORITHM_HMAC = "HMAC-SHA256"
+ALGORITHM_MTLS = "MTLS"
+
+# Transient data field read by the chaincode (config.RequestSignatureTransientKey)
+TRANSIENT_KEY = "requestSignature"
+
+HEADER_KEY_ID = "X-Partner-Key-Id"
+HEADER_TIMESTAMP = "X-Signature-Timestamp"
+HEADER_NONCE = "X-Signature-Nonce"
+HEADER_SIGNATURE = "X-Signature"
+
+
+class SignatureError(Exception):
+    """Raised when a partner request cannot be verified."""
+    pass
+
+
+@dataclass
+class PartnerKey:
+    """A partner's signing key, optionally bound to its TLS client certificate."""
+    key_id: str
+    partner_id: str
+    secret: str
+    client_cert_fingerprint: Optional[str] = None
+
+
+@dataclass
+class SignatureEvidence:
+    """Evidence of a verified partner request, in the shape the chaincode records."""
+    partner_id: str
+    algorithm: str
+    method: str
+    path: str
+    timestamp: str
+    nonce: str
+    body_hash: str
+    key_id: Optional[str] = None
+    signature: Optional[str] = None
+    client_cert_fingerprint: Optional[str] = None
+
+    def to_transient(self, function_name: str, args: List[str]) -> Dict[str, bytes]:
+        """
+        Build the transient data for the chaincode request made on the partner's behalf.
+
+        Args:
+            function_name: Chaincode function to invoke
+            args: Arguments of the chaincode function
+
+        Returns:
+            Transient data binding the evidence to this payload
+        """
+        evidence = {
+            "partnerID": self.partner_id,
+            "algorithm": self.algorithm,
+            "method": self.method,
+            "path": self.path,
+            "timestamp": self.timestamp,
+            "nonce": self.nonce,
+            "bodyHash": self.body_hash,
+            "payloadHash": payload_hash(function_name, args),
+        }
+        if self.key_id:
+            evidence["keyID"] = self.key_id
+        if self.signature:
+            evidence["signature"] = self.signature
+        if self.client_cert_fingerprint:
+            evidence["clientCertFingerprint"] = self.client_cert_fingerprint
+        return {TRANSIENT_KEY: json.dumps(evidence).encode("utf-8")}
+
+
+def canonical_request(method: str, path: str, timestamp: str, nonce: str, body: bytes) -> str:
+    """Return the canonical form of a request that partners sign."""
+    body_hash = hashlib.sha256(body).hexdigest()
+    return "\n".join([method.upper(), path, timestamp, nonce, body_hash])
+
+
+def sign_request(secret: str, method: str, path: str, timestamp: str, nonce: str, body: bytes) -> str:
+    """Return the hex HMAC-SHA256 of a request's canonical form."""
+    canonical = canonical_request(method, path, timestamp, nonce, body)
+    return hmac.new(secret.encode("utf-8"), canonical.encode("utf-8"), hashlib.sha256).hexdigest()
+
+
+def payload_hash(function_name: str, args: List[str]) -> str:
+    """
+    Return the chaincode's PayloadHash of a request: the hex SHA-256 of the
+    JSON array [function, args...] as encoded by Go's encoding/json.
+    """
+    encoded = json.dumps([function_name] + list(args), separators=(",", ":"), ensure_ascii=False)
+    # Go escapes these characters in strings; match it so the hashes agree
+    for char, escaped in (("<", "\\u003c"), (">", "\\u003e"), ("&", "\\u0026"),
+                          ("\u2028", "\\u2028"), ("\u2029", "\\u2029")):
+        encoded = encoded.replace(char, escaped)
+    return hashlib.sha256(encoded.encode("utf-8")).hexdigest()
+
+
+class RequestSignatureVerifier:
+    """Verifies partner request signatures and rejects replayed nonces."""
+
+    def __init__(
+        self,
+        keys: Dict[str, PartnerKey],
+        max_skew_seconds: int = 300,
+        clock: Callable[[], float] = time.time
+    ):
+        self.keys = keys
+        self.max_skew_seconds = max_skew_seconds
+        self.clock = clock
+        self._seen_nonces: Dict[str, float] = {}
+        self._lock = threading.Lock()
+
+    @classmethod
+    def from_settings(cls) -> "RequestSignatureVerifier":
+        """Create a verifier from the configured partner keys."""
+        keys = {
+            key_id: PartnerKey(
+                key_id=key_id,
+                partner_id=key["partner_id"],
+                secret=key["secret"],
+                client_cert_fingerprint=key.get("client_cert_fingerprint"),
+            )
+            for key_id, key in settings.PARTNER_SIGNING_KEYS.items()
+        }
+        return cls(keys, settings.PARTNER_SIGNATURE_MAX_SKEW_SECONDS)
+
+    def verify(
+        self,
+        method: str,
+        path: str,
+        headers: Dict[str, str],
+        body: bytes,
+        client_cert_fingerprint: Optional[str] = None
+    ) -> SignatureEvidence:
+        """
+        Verify a partner request.
+
+        A request carrying an HMAC signature is checked against the partner's
+        key, and against the key's client certificate when the key is bound to
+        one. A request without a signature is accepted only with a client
+        certificate registered to a partner.
+
+        Args:
+            method: HTTP method
+            path: Request path, as signed
+            headers: Request headers
+            body: Raw request body
+            client_cert_fingerprint: SHA-256 fingerprint of the TLS client certificate, if any
+
+        Returns:
+            Evidence to pass to chaincode
+
+        Raises:
+            SignatureError: If the request cannot be verified
+        """
+        headers = {name.lower(): value for name, value in headers.items()}
+        timestamp = headers.get(HEADER_TIMESTAMP.lower(), "")
+        nonce = headers.get(HEADER_NONCE.lower(), "")
+        if not timestamp or not nonce:
+            raise SignatureError(f"{HEADER_TIMESTAMP} and {HEADER_NONCE} are required")
+        self._check_timestamp(timestamp)
+
+        fingerprint = _normalise_fingerprint(client_cert_fingerprint)
+        key_id = headers.get(HEADER_KEY_ID.lower())
+        signature = headers.get(HEADER_SIGNATURE.lower())
+
+        if key_id or signature:
+            key = self.keys.get(key_id or "")
+            if key is None:
+                raise SignatureError(f"unknown partner key {key_id}")
+            expected = sign_request(key.secret, method, path, timestamp, nonce, body)
+            if not signature or not hmac.compare_digest(expected, signature.lower()):
+                raise SignatureError("request signature does not match")
+            bound = _normalise_fingerprint(key.client_cert_fingerprint)
+            if bound and bound != fingerprint:
+                raise SignatureError(f"partner key {key_id} must be used over its mTLS connection")
+            partner_id, algorithm = key.partner_id, ALGORITHM_HMAC
+        else:
+            key = self._key_for_certificate(fingerprint)
+            if key is None:
+                raise SignatureError("request is neither signed nor sent with a registered client certificate")
+            partner_id, algorithm, key_id = key.partner_id, ALGORITHM_MTLS, key.key_id
+
+        self._check_nonce(partner_id, nonce)
+
+        logger.info("Verified partner request",
+                   partner_id=partner_id,
+                   algorithm=algorithm,
+                   path=path)
+
+        return SignatureEvidence(
+            partner_id=partner_id,
+            algorithm=algorithm,
+            method=method.upper(),
+            path=path,
+            timestamp=timestamp,
+            nonce=nonce,
+            body_hash=hashlib.sha256(body).hexdigest(),
+            key_id=key_id,
+            signature=signature.lower() if signature else None,
+            client_cert_fingerprint=fingerprint,
+        )
+
+    def _check_timestamp(self, timestamp: str) -> None:
+        """Reject timestamps outside the allowed skew."""
+        try:
+            signed_at = datetime.fromisoformat(timestamp.replace("Z", "+00:00"))
+        except ValueError:
+            raise SignatureError(f"{HEADER_TIMESTAMP} {timestamp} is not RFC 3339")
+        if signed_at.tzinfo is None:
+            raise SignatureError(f"{HEADER_TIMESTAMP} {timestamp} has no time zone")
+        skew = abs(self.clock() - signed_at.timestamp())
+        if skew > self.max_skew_seconds:
+            raise SignatureError(f"request was signed {int(skew)}s from now, more than {self.max_skew_seconds}s")
+
+    def _check_nonce(self, partner_id: str, nonce: str) -> None:
+        """Reject a nonce the partner used within the skew window."""
+        now = self.clock()
+        with self._lock:
+            for seen, seen_at in list(self._seen_nonces.items()):
+                if now - seen_at > 2 * self.max_skew_seconds:
+                    del self._seen_nonces[seen]
+            scoped = f"{partner_id}:{nonce}"
+            if scoped in self._seen_nonces:
+                raise SignatureError(f"nonce {nonce} was already used")
+            self._seen_nonces[scoped] = now
+
+    def _key_for_certificate(self, fingerprint: Optional[str]) -> Optional[PartnerKey]:
+        """Return the partner key bound to a client certificate."""
+        if not fingerprint:
+            return None
+        for key in self.keys.values():
+            if _normalise_fingerprint(key.client_cert_fingerprint) == fingerprint:
+                return key
+        return None
+
+
+def _normalise_fingerprint(fingerprint: Optional[str]) -> Optional[str]:
+    """Return a certificate fingerprint as lower-case hex without separators."""
+    if not fingerprint:
+        return None
+    return fingerprint.replace(":", "").lower()
+
+
+_verifier: Optional[RequestSignatureVerifier] = None
+
+
+def get_request_signature_verifier() -> RequestSignatureVerifier:
+    """Return the verifier built from settings."""
+    global _verifier
+    if _verifier is None:
+        _verifier = RequestSignatureVerifier.from_settings()
+    return _verifier
+
+
+async def require_partner_signature(request: Request) -> SignatureEvidence:
+    """
+    FastAPI dependency verifying a partner request.
+
+    The client certificate fingerprint is read from the header set by the
+    TLS-terminating proxy (settings.PARTNER_CLIENT_CERT_HEADER).
+    """
+    body = await request.body()
+    try:
+        return get_request_signature_verifier().verify(
+            request.method,
+            request.url.path,
+            dict(request.headers),
+            body,
+            request.headers.get(settings.PARTNER_CLIENT_CERT_HEADER),
+        )
+    except SignatureError as e:
+        logger.warning("Rejected partner request", path=request.url.path, error=str(e))
+        raise HTTPException(status_code=401, detail=f"Invalid partner request signature: {e}")
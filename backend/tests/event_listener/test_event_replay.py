@@ -0,0 +1,145 @@
+"""
+Unit tests for event replay.
+
+Tests block range replay, entity filtering and replay markers.
+"""
+import pytest
+import json
+from unittest.mock import AsyncMock, patch
+
+from event_listener.replay import (
+    EventReplayService, ReplayFilter, EventReplayError,
+    REPLAY_MARKER_KEY, MAX_REPLAY_BLOCKS, event_entity_ids
+)
+from event_listener.service import EventListenerService
+
+
+def make_raw_event(event_name, tx_id, block_number, payload, chaincode='customer'):
+    """Build a raw event as read from a block."""
+    return {
+        'eventName': event_name,
+        'chaincodeId': chaincode,
+        'txId': tx_id,
+        'blockNumber': block_number,
+        'timestamp': '2024-01-01T10:00:00Z',
+        'payload': json.dumps(payload).encode('utf-8')
+    }
+
+
+@pytest.fixture
+def block_events():
+    """Events committed in blocks 10 to 12."""
+    return {
+        10: [make_raw_event('CustomerCreated', 'tx1', 10, {'entityID': 'CUST001', 'actorID': 'ACTOR001'})],
+        11: [
+            make_raw_event('CustomerUpdated', 'tx2', 11, {'entityID': 'CUST002', 'actorID': 'ACTOR001'}),
+            make_raw_event('LoanApplicationSubmitted', 'tx3', 11,
+                           {'loanApplicationID': 'LOAN001', 'customerID': 'CUST001'}, chaincode='loan'),
+        ],
+        12: [make_raw_event('ActorRegistered', 'tx4', 12, {'entityID': 'CUST001'})],
+    }
+
+
+@pytest.fixture
+def mock_gateway(block_events):
+    """Gateway serving the fixture blocks."""
+    gateway = AsyncMock()
+    gateway.get_block_events.side_effect = lambda block_number: block_events.get(block_number, [])
+    with patch('event_listener.replay.get_fabric_gateway', AsyncMock(return_value=gateway)):
+        yield gateway
+
+
+class TestReplayFilter:
+    """Test replay filter validation and matching."""
+
+    def test_rejects_inverted_range(self):
+        """Test an end block before the start block is rejected."""
+        with pytest.raises(EventReplayError):
+            ReplayFilter(start_block=10, end_block=9).validate()
+
+    def test_rejects_oversized_range(self):
+        """Test ranges beyond the per-replay maximum are rejected."""
+        with pytest.raises(EventReplayError):
+            ReplayFilter(start_block=0, end_block=MAX_REPLAY_BLOCKS).validate()
+
+    def test_entity_ids_include_legacy_keys(self):
+        """Test entity IDs are found in entityID, legacy keys and nested data."""
+        payload = {'loanApplicationID': 'LOAN001', 'data': {'customerID': 'CUST001'}}
+        assert event_entity_ids(payload) == ['LOAN001', 'CUST001']
+
+
+class TestEventReplayService:
+    """Test replaying events to a consumer."""
+
+    @pytest.mark.asyncio
+    async def test_replay_marks_events_in_range(self, mock_gateway):
+        """Test every supported event in the range is delivered with a replay marker."""
+        consumer = AsyncMock(return_value=True)
+        service = EventReplayService(consumer=consumer)
+
+        report = await service.replay(ReplayFilter(start_block=10, end_block=12), requested_by='ADMIN')
+
+        assert report.success
+        assert report.blocks_scanned == 3
+        assert report.events_matched == 4
+        assert report.events_delivered == 3
+        assert report.events_skipped == 1  # ActorRegistered is not consumed by the listener
+
+        delivered = [call.args[0] for call in consumer.call_args_list]
+        assert [event['txId'] for event in delivered] == ['tx1', 'tx2', 'tx3']
+        marker = delivered[0][REPLAY_MARKER_KEY]
+        assert marker['replayId'] == report.replay_id
+        assert marker['dedupKey'] == 'tx1_CustomerCreated'
+        assert marker['originalBlockNumber'] == 10
+        assert marker['sequence'] == 1
+        assert service.get_replay(report.replay_id) is report
+
+    @pytest.mark.asyncio
+    async def test_replay_filters_by_entity(self, mock_gateway):
+        """Test only events concerning the entity are delivered."""
+        consumer = AsyncMock(return_value=True)
+        service = EventReplayService(consumer=consumer)
+
+        report = await service.replay(ReplayFilter(start_block=10, end_block=11, entity_id='CUST001'))
+
+        delivered = [call.args[0]['txId'] for call in consumer.call_args_list]
+        assert delivered == ['tx1', 'tx3']
+        assert report.events_matched == 2
+
+    @pytest.mark.asyncio
+    async def test_replay_reports_consumer_failures(self, mock_gateway):
+        """Test events a consumer rejects are counted as failed."""
+        consumer = AsyncMock(side_effect=[True, False])
+        service = EventReplayService(consumer=consumer)
+
+        report = await service.replay(ReplayFilter(start_block=10, end_block=11, chaincodes=['customer']))
+
+        assert not report.success
+        assert report.events_delivered == 1
+        assert report.events_failed == 1
+        assert report.errors == ['Consumer rejected event tx2_CustomerUpdated']
+
+
+class TestListenerReplayDeduplication:
+    """Test the event listener honours replay markers."""
+
+    @pytest.mark.asyncio
+    async def test_replayed_event_deduplicated_unless_reprocessing(self, block_events):
+        """Test a replay of a processed event is skipped unless reprocess is set."""
+        listener = EventListenerService()
+        listener.event_processor.process_event = AsyncMock(return_value=True)
+        raw_event = block_events[10][0]
+
+        assert await listener.process_raw_event(raw_event)
+
+        service = EventReplayService(consumer=listener.process_raw_event)
+        with patch('event_listener.replay.get_fabric_gateway',
+                   AsyncMock(return_value=AsyncMock(get_block_events=AsyncMock(return_value=[raw_event])))):
+            await service.replay(ReplayFilter(start_block=10, end_block=10))
+            assert listener.event_processor.process_event.await_count == 1
+            assert listener.sync_stats['duplicate_events'] == 1
+
+            await service.replay(ReplayFilter(start_block=10, end_block=10), reprocess=True)
+            assert listener.event_processor.process_event.await_count == 2
+
+        assert listener.sync_stats['replayed_events'] == 2
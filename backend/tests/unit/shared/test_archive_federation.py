@@ -0,0 +1,176 @@
+"""
+Unit tests for archive-aware query federation.
+"""
+
+import json
+
+import pytest
+
+from shared.archive_federation import (
+    SOURCE_TIER_FIELD,
+    TIER_ARCHIVE,
+    TIER_HOT,
+    ArchiveFederation,
+    FederatedCursor,
+    FederationError,
+    InMemoryArchiveStore,
+    JsonlArchiveStore,
+)
+
+
+def entry(n, day):
+    return {"entryID": f"E{n}", "entryType": "KYC", "timestamp": f"2026-01-{day:02d}T09:00:00.123456789Z"}
+
+
+class FakeGateway:
+    """Serves a fixed list of hot records with offset bookmarks, as chaincode paging would."""
+
+    def __init__(self, records, records_field="entries"):
+        self.records = records
+        self.records_field = records_field
+        self.calls = []
+
+    async def query_chaincode(self, chaincode_name, function_name, args):
+        self.calls.append((chaincode_name, function_name, list(args)))
+        page_size, bookmark = int(args[-2]), args[-1]
+        offset = int(bookmark) if bookmark else 0
+        page = self.records[offset:offset + page_size]
+        next_offset = offset + len(page)
+        return {
+            "status": "SUCCESS",
+            "payload": {
+                self.records_field: page,
+                "count": len(page),
+                "bookmark": str(next_offset) if next_offset < len(self.records) else "",
+            },
+        }
+
+
+@pytest.fixture
+def store():
+    """Customer stream with days 1-4 archived up to the end of day 4."""
+    store = InMemoryArchiveStore()
+    store.archive("customer_event_stream", "CUST_1", [entry(n, n) for n in range(1, 5)],
+                  "2026-01-04T23:59:59Z")
+    return store
+
+
+async def read_all(federation, args, page_size):
+    pages, bookmark = [], ""
+    while True:
+        page = await federation.query("customer", "GetCustomerEventStream", args, page_size, bookmark)
+        pages.append(page)
+        bookmark = page.bookmark
+        if not bookmark:
+            return pages
+
+
+class TestArchiveFederation:
+    """Test cases for ArchiveFederation."""
+
+    async def test_pages_span_tiers_oldest_first(self, store):
+        """Archived records come first, hot records follow, and each is marked with its tier."""
+        # Day 4 has been archived but not yet pruned from the ledger
+        gateway = FakeGateway([entry(n, n) for n in range(4, 9)])
+        federation = ArchiveFederation(gateway, store)
+
+        pages = await read_all(federation, ["CUST_1", ""], 3)
+
+        assert [len(page.records) for page in pages] == [3, 3, 2]
+        records = [record for page in pages for record in page.records]
+        assert [record["entryID"] for record in records] == [f"E{n}" for n in range(1, 9)]
+        assert [record[SOURCE_TIER_FIELD] for record in records] == [TIER_ARCHIVE] * 4 + [TIER_HOT] * 4
+        # The second page crosses from the archive into the ledger
+        assert pages[1].archive_count == 1 and pages[1].hot_count == 2
+
+    async def test_page_ending_at_archive_boundary_keeps_bookmark(self, store):
+        """A page filled by the last archived record still leads on to the ledger."""
+        gateway = FakeGateway([entry(n, n) for n in range(5, 7)])
+        federation = ArchiveFederation(gateway, store)
+
+        page = await federation.query("customer", "GetCustomerEventStream", ["CUST_1", ""], 4)
+
+        assert [record["entryID"] for record in page.records] == ["E1", "E2", "E3", "E4"]
+        assert FederatedCursor.decode(page.bookmark) == FederatedCursor(TIER_HOT, "")
+        assert gateway.calls == []
+
+        page = await federation.query("customer", "GetCustomerEventStream", ["CUST_1", ""], 4, page.bookmark)
+        assert [record["entryID"] for record in page.records] == ["E5", "E6"]
+        assert page.bookmark == ""
+
+    async def test_filters_archived_records_like_chaincode(self, store):
+        """The since argument applies to archived entries and is passed through to chaincode."""
+        gateway = FakeGateway([entry(5, 5)])
+        federation = ArchiveFederation(gateway, store)
+
+        page = await federation.query("customer", "GetCustomerEventStream", ["CUST_1", "2026-01-03"], 10)
+
+        assert [record["entryID"] for record in page.records] == ["E3", "E4", "E5"]
+        assert gateway.calls == [("customer", "GetCustomerEventStream", ["CUST_1", "2026-01-03", "8", ""])]
+
+    async def test_unarchived_partition_is_served_from_ledger(self, store):
+        """Without archived records the results are the chaincode's own."""
+        gateway = FakeGateway([entry(1, 1)])
+        federation = ArchiveFederation(gateway, store)
+
+        page = await federation.query("customer", "GetCustomerEventStream", ["CUST_2", ""], 10)
+
+        assert [record[SOURCE_TIER_FIELD] for record in page.records] == [TIER_HOT]
+        assert page.to_payload("entries") == {"entries": page.records, "count": 1, "bookmark": ""}
+
+    async def test_compliance_event_selector_applies_to_archive(self):
+        """Archived compliance events are matched against the query selector."""
+        store = InMemoryArchiveStore()
+        store.archive("compliance_events", "", [
+            {"eventID": "EV1", "eventType": "AML_ALERT", "timestamp": "2026-01-01T00:00:00Z"},
+            {"eventID": "EV2", "eventType": "KYC_EXPIRED", "timestamp": "2026-01-02T00:00:00Z"},
+        ], "2026-01-02T00:00:00Z")
+        gateway = FakeGateway([{"eventID": "EV3", "eventType": "AML_ALERT", "timestamp": "2026-02-01T00:00:00Z"}],
+                              records_field="events")
+        federation = ArchiveFederation(gateway, store)
+
+        page = await federation.query("compliance", "QueryComplianceEvents",
+                                      [json.dumps({"eventType": "AML_ALERT"})], 10)
+
+        assert [record["eventID"] for record in page.records] == ["EV1", "EV3"]
+
+    async def test_rejects_invalid_queries(self, store):
+        """Unknown functions, bad arguments and forged bookmarks are rejected."""
+        federation = ArchiveFederation(FakeGateway([]), store)
+
+        with pytest.raises(FederationError, match="not a federated query"):
+            await federation.query("customer", "GetCustomer", ["CUST_1"])
+        with pytest.raises(FederationError, match="Expected 2, got 1"):
+            await federation.query("customer", "GetCustomerEventStream", ["CUST_1"])
+        with pytest.raises(FederationError, match="page size"):
+            await federation.query("customer", "GetCustomerEventStream", ["CUST_1", ""], 101)
+        with pytest.raises(FederationError, match="invalid bookmark"):
+            await federation.query("customer", "GetCustomerEventStream", ["CUST_1", ""], 10, "not-a-bookmark")
+
+
+class TestJsonlArchiveStore:
+    """Test cases for JsonlArchiveStore."""
+
+    async def test_reads_partition_files(self, tmp_path):
+        """Records are paged by line and watermarks are read per partition."""
+        namespace = tmp_path / "customer_event_stream"
+        namespace.mkdir()
+        (namespace / "CUST_1.jsonl").write_text("\n".join(json.dumps(entry(n, n)) for n in range(1, 4)) + "\n")
+        (namespace / "watermarks.json").write_text(json.dumps({"CUST_1": "2026-01-03T23:59:59Z"}))
+        store = JsonlArchiveStore(str(tmp_path))
+
+        first = await store.fetch("customer_event_stream", "CUST_1", "", 2)
+        second = await store.fetch("customer_event_stream", "CUST_1", first.next_cursor, 2)
+
+        assert [record["entryID"] for record in first.records] == ["E1", "E2"]
+        assert [record["entryID"] for record in second.records] == ["E3"]
+        assert second.next_cursor == ""
+        assert (await store.watermark("customer_event_stream", "CUST_1")).day == 3
+        assert await store.watermark("customer_event_stream", "CUST_2") is None
+
+    async def test_rejects_partition_paths(self, tmp_path):
+        """Partition names cannot escape the namespace directory."""
+        store = JsonlArchiveStore(str(tmp_path))
+
+        with pytest.raises(FederationError):
+            await store.fetch("customer_event_stream", "../secrets", "", 10)
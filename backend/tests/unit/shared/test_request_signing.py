@@ -0,0 +1,115 @@
+"""
+Unit tests for partner request signing.
+"""
+
+import hashlib
+import json
+from datetime import datetime, timezone
+
+import pytest
+
+from shared.request_signing import (
+    ALGORITHM_HMAC,
+    ALGORITHM_MTLS,
+    TRANSIENT_KEY,
+    PartnerKey,
+    RequestSignatureVerifier,
+    SignatureError,
+    payload_hash,
+    sign_request,
+)
+
+NOW = datetime(2026, 5, 4, 12, 0, 0, tzinfo=timezone.utc)
+TIMESTAMP = "2026-05-04T11:59:30Z"
+BODY = b'{"customerID": "CUST_1"}'
+PATH = "/api/v1/partners/disclosures"
+FINGERPRINT = "AB:CD:EF:01"
+
+
+@pytest.fixture
+def verifier():
+    """Verifier with one HMAC key and one key bound to a client certificate."""
+    keys = {
+        "insurer-1": PartnerKey(key_id="insurer-1", partner_id="INSURER", secret="s3cret"),
+        "broker-1": PartnerKey(key_id="broker-1", partner_id="BROKER", secret="br0ker",
+                               client_cert_fingerprint=FINGERPRINT),
+    }
+    return RequestSignatureVerifier(keys, max_skew_seconds=300, clock=NOW.timestamp)
+
+
+def signed_headers(key_id, secret, nonce="n-1", timestamp=TIMESTAMP, body=BODY):
+    return {
+        "X-Partner-Key-Id": key_id,
+        "X-Signature-Timestamp": timestamp,
+        "X-Signature-Nonce": nonce,
+        "X-Signature": sign_request(secret, "POST", PATH, timestamp, nonce, body),
+    }
+
+
+class TestRequestSignatureVerifier:
+    """Test cases for RequestSignatureVerifier."""
+
+    def test_verifies_hmac_signed_request(self, verifier):
+        """A correctly signed request yields evidence for the chaincode."""
+        evidence = verifier.verify("post", PATH, signed_headers("insurer-1", "s3cret"), BODY)
+
+        assert evidence.partner_id == "INSURER"
+        assert evidence.algorithm == ALGORITHM_HMAC
+        assert evidence.method == "POST"
+        assert evidence.key_id == "insurer-1"
+
+    def test_rejects_tampered_body(self, verifier):
+        """A body changed after signing does not verify."""
+        with pytest.raises(SignatureError, match="does not match"):
+            verifier.verify("POST", PATH, signed_headers("insurer-1", "s3cret"), b'{"customerID": "CUST_2"}')
+
+    def test_rejects_replayed_nonce(self, verifier):
+        """A nonce can only be used once."""
+        verifier.verify("POST", PATH, signed_headers("insurer-1", "s3cret"), BODY)
+        with pytest.raises(SignatureError, match="already used"):
+            verifier.verify("POST", PATH, signed_headers("insurer-1", "s3cret"), BODY)
+
+    def test_rejects_stale_timestamp(self, verifier):
+        """Requests signed outside the skew window are rejected."""
+        headers = signed_headers("insurer-1", "s3cret", timestamp="2026-05-04T11:50:00Z")
+        with pytest.raises(SignatureError, match="more than 300s"):
+            verifier.verify("POST", PATH, headers, BODY)
+
+    def test_bound_key_requires_its_certificate(self, verifier):
+        """A key bound to a client certificate only verifies over that connection."""
+        headers = signed_headers("broker-1", "br0ker")
+        with pytest.raises(SignatureError, match="mTLS"):
+            verifier.verify("POST", PATH, headers, BODY)
+
+        evidence = verifier.verify("POST", PATH, signed_headers("broker-1", "br0ker", nonce="n-2"), BODY, "ab:cd:ef:01")
+        assert evidence.client_cert_fingerprint == "abcdef01"
+
+    def test_accepts_registered_certificate_without_signature(self, verifier):
+        """An unsigned request is accepted over a registered mTLS connection."""
+        headers = {"X-Signature-Timestamp": TIMESTAMP, "X-Signature-Nonce": "n-3"}
+        evidence = verifier.verify("POST", PATH, headers, BODY, FINGERPRINT)
+        assert evidence.algorithm == ALGORITHM_MTLS
+        assert evidence.partner_id == "BROKER"
+
+        with pytest.raises(SignatureError, match="neither signed"):
+            verifier.verify("POST", PATH, {"X-Signature-Timestamp": TIMESTAMP, "X-Signature-Nonce": "n-4"}, BODY)
+
+
+class TestSignatureEvidence:
+    """Test cases for the evidence passed to chaincode."""
+
+    def test_transient_data_is_bound_to_payload(self, verifier):
+        """The transient evidence carries the chaincode payload hash."""
+        evidence = verifier.verify("POST", PATH, signed_headers("insurer-1", "s3cret"), BODY)
+        transient = evidence.to_transient("RecordDisclosure", ['{"customerID":"CUST_1"}'])
+
+        recorded = json.loads(transient[TRANSIENT_KEY])
+        assert recorded["partnerID"] == "INSURER"
+        assert recorded["payloadHash"] == payload_hash("RecordDisclosure", ['{"customerID":"CUST_1"}'])
+        assert "clientCertFingerprint" not in recorded
+
+    def test_payload_hash_matches_go_encoding(self):
+        """Characters Go escapes are escaped the same way."""
+        # sha256 of ["F","a<b&c"] as produced by Go's json.Marshal
+        expected = hashlib.sha256(b'["F","a\\u003cb\\u0026c"]').hexdigest()
+        assert payload_hash("F", ["a<b&c"]) == expected
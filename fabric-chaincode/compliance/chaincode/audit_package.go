@@ -0,0 +1,66 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"unicode/utf8"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// complianceEntityAuditKeys are the records whose ledger history ExportEntityAudit exports: an
+// escalation case, or a customer's latest AML check pointer
+var complianceEntityAuditKeys = []string{"ESCALATION_%s", "AML_LATEST_%s"}
+
+// complianceAuditCollectors lists the evidence the compliance chaincode holds about an entity or
+// an escalation case, for ExportAuditPackage
+func complianceAuditCollectors() []services.AuditCollector {
+	return []services.AuditCollector{
+		services.RecordCollector("case", "Escalation case, when the subject is a case ID", "ESCALATION_%s"),
+		services.IndexedRecordCollector("escalations", "Escalations raised against the entity", "ESCALATION_BY_ENTITY", func(escalationID string) string {
+			return "ESCALATION_" + escalationID
+		}),
+		services.IndexedRecordCollector("events", "Compliance events affecting the entity", "event_entity", func(eventID string) string {
+			return "compliance_event~" + eventID
+		}),
+		amlScreeningCollector(),
+		services.CompositeKeyCollector("decisions", "Journaled compliance decisions and their co-signatures", config.DecisionJournalPrefix),
+		services.HistoryCollector(),
+	}
+}
+
+// amlScreeningCollector collects every AML check result of a customer through the
+// CUSTOMER_AML_<customerID>_<checkID> index
+func amlScreeningCollector() services.AuditCollector {
+	return services.AuditCollector{
+		Name:        "amlScreenings",
+		Description: "AML and sanctions screening results",
+		Collect: func(stub shim.ChaincodeStubInterface, customerID string) ([]json.RawMessage, error) {
+			prefix := fmt.Sprintf("CUSTOMER_AML_%s_", customerID)
+			iterator, err := stub.GetStateByRange(prefix, prefix+string(utf8.MaxRune))
+			if err != nil {
+				return nil, fmt.Errorf("failed to query AML checks: %w", err)
+			}
+			defer iterator.Close()
+
+			records := []json.RawMessage{}
+			for iterator.HasNext() {
+				response, err := iterator.Next()
+				if err != nil {
+					return nil, fmt.Errorf("failed to iterate AML checks: %w", err)
+				}
+				resultBytes, err := stub.GetState(fmt.Sprintf("AML_RESULT_%s", string(response.Value)))
+				if err != nil {
+					return nil, fmt.Errorf("failed to read AML check %s: %w", string(response.Value), err)
+				}
+				if resultBytes == nil {
+					continue
+				}
+				records = append(records, json.RawMessage(resultBytes))
+			}
+			return records, nil
+		},
+	}
+}
@@ -3,13 +3,17 @@ package chaincode
 import (
 	"context"
 	"encoding/json"
+	"errors"
 	"fmt"
-	"time"
+	"strconv"
 
 	"github.com/hyperledger/fabric-chaincode-go/shim"
 	"github.com/hyperledger/fabric-protos-go/peer"
 	
 	"github.com/brycemacchaveli/origin.block/fabric-chaincode/compliance/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/compliance/handlers"
+	sharedChaincode "github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/chaincode"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
 )
 
 // ComplianceContract implements the chaincode interface with comprehensive rule engine
@@ -18,8 +22,94 @@ type ComplianceContract struct {
 	ruleRepository  domain.RuleRepository
 	eventEmitter    domain.EventEmitter
 	approvalManager *domain.ApprovalWorkflowManager
+	flagHandler     *sharedChaincode.FunctionFlagHandler
+	sandboxHandler  *sharedChaincode.SandboxHandler
+	compatibilityHandler *sharedChaincode.CompatibilityHandler
+	journalHandler  *sharedChaincode.DecisionJournalHandler
+	auditPackageHandler *sharedChaincode.AuditPackageHandler
+	entityAuditHandler *sharedChaincode.EntityAuditHandler
+	identityHandler *sharedChaincode.IdentityHandler
+	archiveHandler  *sharedChaincode.PayloadArchiveHandler
+	counterHandler  *sharedChaincode.CounterHandler
+	amlHandler      *handlers.AMLCheckHandler
+	pepListManager  *handlers.PEPListManager
+	sanctionListManager *handlers.SanctionListManager
+	adverseMediaManager *handlers.AdverseMediaManager
+	textScreeningManager *handlers.TextScreeningManager
+	loanDefaultHandler *handlers.LoanDefaultHandler
+	channelMonitoringHandler *handlers.ChannelMonitoringHandler
+	transactionMonitoringHandler *handlers.TransactionMonitoringHandler
+	countryRiskHandler *handlers.CountryRiskHandler
+	riskCatalogHandler *handlers.RiskCatalogHandler
+	riskModelHandler *handlers.RiskModelHandler
+	whitelistHandler *handlers.ScreeningWhitelistHandler
+	governanceHandler *handlers.GovernanceHandler
+	reportHandler *handlers.ReportGenerationHandler
+	escalationHandler *handlers.ViolationEscalationHandler
+	eventQueryHandler *handlers.ComplianceEventQueryHandler
 }
 
+// compliancePIIFunctions are the functions whose arguments carry customer PII, which is never
+// retained in the payload archive
+var compliancePIIFunctions = map[string]bool{
+	"PerformAMLCheck":     true,
+	"ScreenPEP":           true,
+	"BatchScreenEntities": true,
+}
+
+// complianceActorArguments locates the acting actor of functions that take it positionally or
+// under a JSON field other than actorID, see sharedChaincode.CheckActorBinding
+var complianceActorArguments = sharedChaincode.ActorArguments{
+	"SubmitRuleForApproval":    {Index: 1},
+	"ApproveRule":              {Index: 1},
+	"RejectRule":               {Index: 1},
+	"AcknowledgeEvent":         {Index: 1},
+	"ActivateRiskModelVersion": {Index: 1},
+	"CreateComplianceRule":     {Index: 0, Field: "createdBy"},
+	"UpdateComplianceRule":     {Index: 0, Field: "lastModifiedBy"},
+	"CreateEscalation":         {Index: 0, Field: "createdBy"},
+	"AssignEscalation":         {Index: 0, Field: "assignedBy"},
+	"EscalateToNextLevel":      {Index: 0, Field: "escalatedBy"},
+	"ResolveEscalation":        {Index: 0, Field: "resolvedBy"},
+	"AddEscalationComment":     {Index: 0, Field: "authorID"},
+	"CreateSanctionList":       {Index: 0, Field: "createdBy"},
+	"UpdateSanctionList":       {Index: 0, Field: "updatedBy"},
+}
+
+// complianceFreezeArguments exempts the state-changing compliance functions from entity freezes:
+// a freeze blocks changes to a customer or loan except compliance actions, which is everything
+// this chaincode does. Functions missing here are checked as JSON requests naming their entity.
+var complianceFreezeArguments = sharedChaincode.FreezeExempt(
+	"CreateComplianceRule", "UpdateComplianceRule",
+	"ExecuteRule", "ExecuteRulesForEntity", "ExecuteRulesForEvent",
+	"ValidateRule", "TestRule", "RunAllTests",
+	"ResolveDependencies", "CheckConflicts",
+	"SubmitRuleForApproval", "ApproveRule", "RejectRule",
+	"AcknowledgeEvent", "UpdateEventResolution",
+	"RecordDecision", "CosignDecision",
+	"ExportAuditPackage", "VerifyAuditPackage", "ExportEntityAudit", "VerifyEntityAudit",
+	"PerformAMLCheck", "UpdateAMLStatus", "UpdateKYCStatus", "VerifyKYCDocuments",
+	"AddPEPEntry", "BulkImportPEPList", "DeactivatePEPEntry", "ScreenPEP",
+	"CreateSanctionList", "UpdateSanctionList",
+	"AddAdverseMediaRecord", "DeactivateAdverseMediaRecord",
+	"AddScreeningTerm", "DeactivateScreeningTerm", "ScreenText",
+	"RecordLoanDefault",
+	"SetChannelFraudRule", "MonitorApplicationChannel",
+	"SetTransactionTypologyRule", "IngestTransactions", "MapPaymentMessage", "IngestPaymentMessage",
+	"SetCountryRisk",
+	"CreateRiskCatalogEntry", "UpdateRiskCatalogEntry", "RetireRiskCatalogEntry",
+	"CreateRiskModelVersion", "ActivateRiskModelVersion",
+	"AddScreeningWhitelistEntry", "RevokeScreeningWhitelistEntry",
+	"SetGovernanceMember", "ProposeParameterChange", "CastGovernanceVote", "CloseGovernanceProposal",
+	"GenerateComplianceReport", "FinalizeReportingPeriod",
+	"CreateEscalation", "AssignEscalation", "EscalateToNextLevel", "ResolveEscalation",
+	"AddEscalationComment", "SetEscalationRoutingRule", "EscalateOverdue",
+	"TriggerPeriodicReview", "TargetedRescreen", "StartScreeningJob", "BatchScreenEntities",
+	"InitLedger",
+	"SetFunctionFlag", "SetSandboxActor", "ValidateStateCompatibility", "RecordTimestampCutover",
+	"RegisterActor", "AttestCredentialRotation", "SetPayloadArchivePolicy", "CompactCounters",
+)
+
 // NewComplianceContract creates a new compliance contract with full rule engine
 func NewComplianceContract() *ComplianceContract {
 	repository := domain.NewFabricRuleRepository()
@@ -32,6 +122,31 @@ func NewComplianceContract() *ComplianceContract {
 		ruleRepository:  repository,
 		eventEmitter:    emitter,
 		approvalManager: approvalManager,
+		flagHandler:     sharedChaincode.NewFunctionFlagHandler(),
+		sandboxHandler:  sharedChaincode.NewSandboxHandler(),
+		compatibilityHandler: sharedChaincode.NewCompatibilityHandler(newComplianceSchemaRegistry()),
+		journalHandler:  sharedChaincode.NewDecisionJournalHandler(),
+		auditPackageHandler: sharedChaincode.NewAuditPackageHandler(complianceAuditCollectors()...),
+		entityAuditHandler: sharedChaincode.NewEntityAuditHandler(complianceEntityAuditKeys...),
+		identityHandler: sharedChaincode.NewIdentityHandler(),
+		archiveHandler:  sharedChaincode.NewPayloadArchiveHandler(),
+		counterHandler:  sharedChaincode.NewCounterHandler(),
+		amlHandler:      handlers.NewAMLCheckHandler(emitter),
+		pepListManager:  handlers.NewPEPListManager(emitter),
+		sanctionListManager: handlers.NewSanctionListManager(emitter),
+		adverseMediaManager: handlers.NewAdverseMediaManager(emitter),
+		textScreeningManager: handlers.NewTextScreeningManager(emitter),
+		loanDefaultHandler: handlers.NewLoanDefaultHandler(emitter),
+		channelMonitoringHandler: handlers.NewChannelMonitoringHandler(emitter),
+		transactionMonitoringHandler: handlers.NewTransactionMonitoringHandler(emitter),
+		countryRiskHandler: handlers.NewCountryRiskHandler(),
+		riskCatalogHandler: handlers.NewRiskCatalogHandler(),
+		riskModelHandler: handlers.NewRiskModelHandler(),
+		whitelistHandler: handlers.NewScreeningWhitelistHandler(),
+		governanceHandler: handlers.NewGovernanceHandler(),
+		reportHandler: handlers.NewReportGenerationHandler(),
+		escalationHandler: handlers.NewViolationEscalationHandler(emitter),
+		eventQueryHandler: handlers.NewComplianceEventQueryHandler(),
 	}
 }
 
@@ -42,8 +157,25 @@ func (c *ComplianceContract) Init(stub shim.ChaincodeStubInterface) peer.Respons
 
 // Invoke is called per transaction on the chaincode
 func (c *ComplianceContract) Invoke(stub shim.ChaincodeStubInterface) peer.Response {
+	// Handlers share the invocation's state, such as the IDs generated so far, through its stub
+	stub = services.NewInvocation(stub)
 	function, args := stub.GetFunctionAndParameters()
 
+	// Run the kill switch, binding, freeze, rotation, signature and archive checks of every entry point
+	if err := sharedChaincode.GuardInvocation(stub, function, args, complianceActorArguments, complianceFreezeArguments, compliancePIIFunctions[function]); err != nil {
+		return sharedChaincode.ErrorResponse(err)
+	}
+
+	// Failures leave as the shared error envelope, see services.ChaincodeError
+	response := c.dispatch(stub, function, args)
+	if response.Status >= shim.ERRORTHRESHOLD && !services.IsErrorEnvelope(response.Message) {
+		return sharedChaincode.ErrorResponse(errors.New(response.Message))
+	}
+	return response
+}
+
+// dispatch invokes the contract function named by the transaction
+func (c *ComplianceContract) dispatch(stub shim.ChaincodeStubInterface, function string, args []string) peer.Response {
 	switch function {
 	// Rule management
 	case "CreateComplianceRule":
@@ -110,13 +242,257 @@ func (c *ComplianceContract) Invoke(stub shim.ChaincodeStubInterface) peer.Respo
 		return c.AcknowledgeEvent(stub, args)
 	case "UpdateEventResolution":
 		return c.UpdateEventResolution(stub, args)
+	case "QueryComplianceEvents":
+		return c.QueryComplianceEvents(stub, args)
+	
+	// Decision journal
+	case "RecordDecision":
+		return c.RecordDecision(stub, args)
+	case "CosignDecision":
+		return c.CosignDecision(stub, args)
+	case "GetDecisionJournal":
+		return c.GetDecisionJournal(stub, args)
+	
+	// Audit packages
+	case "ExportAuditPackage":
+		return c.ExportAuditPackage(stub, args)
+	case "GetAuditManifest":
+		return c.GetAuditManifest(stub, args)
+	case "VerifyAuditPackage":
+		return c.VerifyAuditPackage(stub, args)
+	case "ExportEntityAudit":
+		return c.ExportEntityAudit(stub, args)
+	case "VerifyEntityAudit":
+		return c.VerifyEntityAudit(stub, args)
+	
+	// PEP list management
+	case "AddPEPEntry":
+		return c.AddPEPEntry(stub, args)
+	case "BulkImportPEPList":
+		return c.BulkImportPEPList(stub, args)
+	case "DeactivatePEPEntry":
+		return c.DeactivatePEPEntry(stub, args)
+	case "GetPEPEntry":
+		return c.GetPEPEntry(stub, args)
+	case "QueryPEPEntriesByCountry":
+		return c.QueryPEPEntriesByCountry(stub, args)
+	case "ScreenPEP":
+		return c.ScreenPEP(stub, args)
+	
+	// Sanction list management
+	case "CreateSanctionList":
+		return c.CreateSanctionList(stub, args)
+	case "UpdateSanctionList":
+		return c.UpdateSanctionList(stub, args)
+	case "GetSanctionList":
+		return c.GetSanctionList(stub, args)
+	case "GetActiveSanctionLists":
+		return c.GetActiveSanctionLists(stub, args)
+	case "SearchSanctionEntries":
+		return c.SearchSanctionEntries(stub, args)
+	
+	// Adverse media registry
+	case "AddAdverseMediaRecord":
+		return c.AddAdverseMediaRecord(stub, args)
+	case "DeactivateAdverseMediaRecord":
+		return c.DeactivateAdverseMediaRecord(stub, args)
+	case "GetAdverseMediaRecord":
+		return c.GetAdverseMediaRecord(stub, args)
+	case "QueryAdverseMediaByEntity":
+		return c.QueryAdverseMediaByEntity(stub, args)
+	
+	// Free-text screening
+	case "AddScreeningTerm":
+		return c.AddScreeningTerm(stub, args)
+	case "DeactivateScreeningTerm":
+		return c.DeactivateScreeningTerm(stub, args)
+	case "GetScreeningTerms":
+		return c.GetScreeningTerms(stub, args)
+	case "ScreenText":
+		return c.ScreenText(stub, args)
+	
+	// Loan defaults
+	case "RecordLoanDefault":
+		return c.RecordLoanDefault(stub, args)
+	
+	// Origination channel monitoring
+	case "SetChannelFraudRule":
+		return c.SetChannelFraudRule(stub, args)
+	case "GetChannelFraudRules":
+		return c.GetChannelFraudRules(stub, args)
+	case "MonitorApplicationChannel":
+		return c.MonitorApplicationChannel(stub, args)
+	case "GetDeviceApplications":
+		return c.GetDeviceApplications(stub, args)
+	
+	// Transaction monitoring
+	case "SetTransactionTypologyRule":
+		return c.SetTransactionTypologyRule(stub, args)
+	case "GetTransactionTypologyRules":
+		return c.GetTransactionTypologyRules(stub, args)
+	case "IngestTransactions":
+		return c.IngestTransactions(stub, args)
+	case "MapPaymentMessage":
+		return c.MapPaymentMessage(stub, args)
+	case "IngestPaymentMessage":
+		return c.IngestPaymentMessage(stub, args)
+	case "GetTransactionAlert":
+		return c.GetTransactionAlert(stub, args)
+	case "GetTransactionAlertsByCustomer":
+		return c.GetTransactionAlertsByCustomer(stub, args)
+	
+	// Country risk
+	case "SetCountryRisk":
+		return c.SetCountryRisk(stub, args)
+	case "GetCountryRiskTable":
+		return c.GetCountryRiskTable(stub, args)
+	case "GetCountryRiskHistory":
+		return c.GetCountryRiskHistory(stub, args)
+	
+	// Occupation and industry risk catalogs
+	case "CreateRiskCatalogEntry":
+		return c.CreateRiskCatalogEntry(stub, args)
+	case "UpdateRiskCatalogEntry":
+		return c.UpdateRiskCatalogEntry(stub, args)
+	case "RetireRiskCatalogEntry":
+		return c.RetireRiskCatalogEntry(stub, args)
+	case "GetRiskCatalogEntry":
+		return c.GetRiskCatalogEntry(stub, args)
+	case "GetRiskCatalog":
+		return c.GetRiskCatalog(stub, args)
+	
+	// AML risk model versions
+	case "CreateRiskModelVersion":
+		return c.CreateRiskModelVersion(stub, args)
+	case "ActivateRiskModelVersion":
+		return c.ActivateRiskModelVersion(stub, args)
+	case "GetActiveRiskModel":
+		return c.GetActiveRiskModel(stub, args)
+	case "GetRiskModelVersions":
+		return c.GetRiskModelVersions(stub, args)
+	
+	// Screening false positive whitelist
+	case "AddScreeningWhitelistEntry":
+		return c.AddScreeningWhitelistEntry(stub, args)
+	case "RevokeScreeningWhitelistEntry":
+		return c.RevokeScreeningWhitelistEntry(stub, args)
+	case "GetScreeningWhitelist":
+		return c.GetScreeningWhitelist(stub, args)
+	
+	// Consortium governance
+	case "SetGovernanceMember":
+		return c.SetGovernanceMember(stub, args)
+	case "GetGovernanceMembers":
+		return c.GetGovernanceMembers(stub, args)
+	case "ProposeParameterChange":
+		return c.ProposeParameterChange(stub, args)
+	case "CastGovernanceVote":
+		return c.CastGovernanceVote(stub, args)
+	case "CloseGovernanceProposal":
+		return c.CloseGovernanceProposal(stub, args)
+	case "GetGovernanceProposal":
+		return c.GetGovernanceProposal(stub, args)
+	case "GetGovernanceProposals":
+		return c.GetGovernanceProposals(stub, args)
+	case "GetPlatformParameter":
+		return c.GetPlatformParameter(stub, args)
+	case "GetPlatformParameters":
+		return c.GetPlatformParameters(stub, args)
+	
+	// Regulatory reporting
+	case "GenerateComplianceReport":
+		return c.GenerateComplianceReport(stub, args)
+	case "GetComplianceReport":
+		return c.GetComplianceReport(stub, args)
+	case "QueryReportsByType":
+		return c.QueryReportsByType(stub, args)
+	case "FinalizeReportingPeriod":
+		return c.FinalizeReportingPeriod(stub, args)
+	case "GetReportingPeriod":
+		return c.GetReportingPeriod(stub, args)
+	
+	// Violation escalations
+	case "CreateEscalation":
+		return c.CreateEscalation(stub, args)
+	case "AssignEscalation":
+		return c.AssignEscalation(stub, args)
+	case "EscalateToNextLevel":
+		return c.EscalateToNextLevel(stub, args)
+	case "ResolveEscalation":
+		return c.ResolveEscalation(stub, args)
+	case "AddEscalationComment":
+		return c.AddEscalationComment(stub, args)
+	case "GetEscalation":
+		return c.GetEscalation(stub, args)
+	case "GetEscalationsByStatus":
+		return c.GetEscalationsByStatus(stub, args)
+	case "GetEscalationsByAssignee":
+		return c.GetEscalationsByAssignee(stub, args)
+	case "SetEscalationRoutingRule":
+		return c.SetEscalationRoutingRule(stub, args)
+	case "GetEscalationRoutingRules":
+		return c.GetEscalationRoutingRules(stub, args)
+	case "EscalateOverdue":
+		return c.EscalateOverdue(stub, args)
+	
+	// Periodic re-screening
+	case "GetExpiringChecks":
+		return c.GetExpiringChecks(stub, args)
+	case "TriggerPeriodicReview":
+		return c.TriggerPeriodicReview(stub, args)
+	case "TargetedRescreen":
+		return c.TargetedRescreen(stub, args)
+	case "StartScreeningJob":
+		return c.StartScreeningJob(stub, args)
+	case "BatchScreenEntities":
+		return c.BatchScreenEntities(stub, args)
+	case "GetScreeningJob":
+		return c.GetScreeningJob(stub, args)
+	case "GetScreeningBatch":
+		return c.GetScreeningBatch(stub, args)
 	
 	// Initialization
 	case "InitLedger":
 		return c.InitLedger(stub)
 	
+	// Operational functions
+	case "SetFunctionFlag":
+		return c.SetFunctionFlag(stub, args)
+	case "GetFunctionFlags":
+		return c.GetFunctionFlags(stub, args)
+	case "SetSandboxActor":
+		return c.SetSandboxActor(stub, args)
+	case "GetSandboxActors":
+		return c.GetSandboxActors(stub, args)
+	case "ValidateStateCompatibility":
+		return c.ValidateStateCompatibility(stub, args)
+	case "RecordTimestampCutover":
+		return c.RecordTimestampCutover(stub, args)
+	case "GetTimestampCutover":
+		return c.GetTimestampCutover(stub, args)
+	case "RegisterActor":
+		return c.RegisterActor(stub, args)
+	case "GetActor":
+		return c.GetActor(stub, args)
+	case "AttestCredentialRotation":
+		return c.AttestCredentialRotation(stub, args)
+	case "GetCredentialRotation":
+		return c.GetCredentialRotation(stub, args)
+	case "GetInvokerIdentity":
+		return c.GetInvokerIdentity(stub, args)
+	case "SetPayloadArchivePolicy":
+		return c.SetPayloadArchivePolicy(stub, args)
+	case "GetPayloadArchivePolicies":
+		return c.GetPayloadArchivePolicies(stub, args)
+	case "GetArchivedPayload":
+		return c.GetArchivedPayload(stub, args)
+	case "GetCounter":
+		return c.GetCounter(stub, args)
+	case "CompactCounters":
+		return c.CompactCounters(stub, args)
+	
 	default:
-		return shim.Error(fmt.Sprintf("Unknown function: %s", function))
+		return sharedChaincode.ErrorResponse(services.NewChaincodeError(services.ErrCodeUnknownFunction, "", "function %s not found", function))
 	}
 }
 
@@ -132,38 +508,54 @@ func (c *ComplianceContract) CreateComplianceRule(stub shim.ChaincodeStubInterfa
 
 	var rule domain.ComplianceRule
 	if err := json.Unmarshal([]byte(args[0]), &rule); err != nil {
-		return shim.Error(fmt.Sprintf("Failed to unmarshal rule: %v", err))
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to unmarshal rule: %w", err))
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(err)
 	}
 
 	// Set creation metadata
-	rule.CreationDate = time.Now()
-	rule.LastModifiedDate = time.Now()
+	rule.CreationDate = now
+	rule.LastModifiedDate = now
 	rule.Status = domain.RuleStatusDraft
 
 	// Save the rule
 	if err := c.ruleRepository.SaveRule(stub, &rule); err != nil {
-		return shim.Error(fmt.Sprintf("Failed to save rule: %v", err))
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to save rule: %w", err))
 	}
 
 	ruleBytes, _ := json.Marshal(rule)
 	return shim.Success(ruleBytes)
 }
 
-// UpdateComplianceRule updates an existing compliance rule
+// UpdateComplianceRule updates an existing compliance rule. An optional second argument names the
+// rule revision the update was made against; the update is rejected with CONFLICT if the rule has
+// been saved since.
 func (c *ComplianceContract) UpdateComplianceRule(stub shim.ChaincodeStubInterface, args []string) peer.Response {
-	if len(args) != 1 {
-		return shim.Error("Incorrect number of arguments. Expecting 1 (JSON rule)")
+	if len(args) != 1 && len(args) != 2 {
+		return shim.Error("Incorrect number of arguments. Expecting 1 (JSON rule) or 2 (JSON rule, expectedVersion)")
 	}
 
 	var updatedRule domain.ComplianceRule
 	if err := json.Unmarshal([]byte(args[0]), &updatedRule); err != nil {
-		return shim.Error(fmt.Sprintf("Failed to unmarshal rule: %v", err))
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to unmarshal rule: %w", err))
 	}
 
 	// Get existing rule
 	existingRule, err := c.ruleRepository.GetLatestRule(stub, updatedRule.RuleID)
 	if err != nil {
-		return shim.Error(fmt.Sprintf("Failed to get existing rule: %v", err))
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get existing rule: %w", err))
+	}
+	if len(args) == 2 {
+		expectedVersion, err := strconv.Atoi(args[1])
+		if err != nil {
+			return shim.Error(fmt.Sprintf("Invalid expectedVersion: %s", args[1]))
+		}
+		if err := services.CheckExpectedVersion("ComplianceRule", updatedRule.RuleID, &expectedVersion, existingRule.Revision); err != nil {
+			return sharedChaincode.ErrorResponse(err)
+		}
 	}
 
 	// Check if rule can be updated
@@ -171,14 +563,19 @@ func (c *ComplianceContract) UpdateComplianceRule(stub shim.ChaincodeStubInterfa
 		return shim.Error("Cannot update active rule. Create a new version or deactivate first.")
 	}
 
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(err)
+	}
+
 	// Update metadata
 	updatedRule.CreationDate = existingRule.CreationDate
 	updatedRule.CreatedBy = existingRule.CreatedBy
-	updatedRule.LastModifiedDate = time.Now()
+	updatedRule.LastModifiedDate = now
 
 	// Save updated rule
 	if err := c.ruleRepository.SaveRule(stub, &updatedRule); err != nil {
-		return shim.Error(fmt.Sprintf("Failed to update rule: %v", err))
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to update rule: %w", err))
 	}
 
 	ruleBytes, _ := json.Marshal(updatedRule)
@@ -203,7 +600,7 @@ func (c *ComplianceContract) GetComplianceRule(stub shim.ChaincodeStubInterface,
 	}
 
 	if err != nil {
-		return shim.Error(fmt.Sprintf("Failed to get rule: %v", err))
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get rule: %w", err))
 	}
 
 	ruleBytes, _ := json.Marshal(rule)
@@ -219,7 +616,7 @@ func (c *ComplianceContract) GetRuleHistory(stub shim.ChaincodeStubInterface, ar
 	ruleID := args[0]
 	history, err := c.ruleRepository.(*domain.FabricRuleRepository).GetRuleHistory(stub, ruleID)
 	if err != nil {
-		return shim.Error(fmt.Sprintf("Failed to get rule history: %v", err))
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get rule history: %w", err))
 	}
 
 	historyBytes, _ := json.Marshal(history)
@@ -230,7 +627,7 @@ func (c *ComplianceContract) GetRuleHistory(stub shim.ChaincodeStubInterface, ar
 func (c *ComplianceContract) GetActiveRules(stub shim.ChaincodeStubInterface, args []string) peer.Response {
 	rules, err := c.ruleRepository.GetActiveRules(stub)
 	if err != nil {
-		return shim.Error(fmt.Sprintf("Failed to get active rules: %v", err))
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get active rules: %w", err))
 	}
 
 	rulesBytes, _ := json.Marshal(rules)
@@ -246,7 +643,7 @@ func (c *ComplianceContract) GetRulesByDomain(stub shim.ChaincodeStubInterface,
 	domain := args[0]
 	rules, err := c.ruleRepository.GetRulesByDomain(stub, domain)
 	if err != nil {
-		return shim.Error(fmt.Sprintf("Failed to get rules by domain: %v", err))
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get rules by domain: %w", err))
 	}
 
 	rulesBytes, _ := json.Marshal(rules)
@@ -262,7 +659,7 @@ func (c *ComplianceContract) GetRulesByEntityType(stub shim.ChaincodeStubInterfa
 	entityType := args[0]
 	rules, err := c.ruleRepository.GetRulesByEntityType(stub, entityType)
 	if err != nil {
-		return shim.Error(fmt.Sprintf("Failed to get rules by entity type: %v", err))
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get rules by entity type: %w", err))
 	}
 
 	rulesBytes, _ := json.Marshal(rules)
@@ -278,7 +675,7 @@ func (c *ComplianceContract) SearchRules(stub shim.ChaincodeStubInterface, args
 	searchTerm := args[0]
 	rules, err := c.ruleRepository.(*domain.FabricRuleRepository).SearchRules(stub, searchTerm)
 	if err != nil {
-		return shim.Error(fmt.Sprintf("Failed to search rules: %v", err))
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to search rules: %w", err))
 	}
 
 	rulesBytes, _ := json.Marshal(rules)
@@ -298,13 +695,13 @@ func (c *ComplianceContract) ExecuteRule(stub shim.ChaincodeStubInterface, args
 	ruleID := args[0]
 	var entityData map[string]interface{}
 	if err := json.Unmarshal([]byte(args[1]), &entityData); err != nil {
-		return shim.Error(fmt.Sprintf("Failed to unmarshal entity data: %v", err))
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to unmarshal entity data: %w", err))
 	}
 
 	ctx := context.Background()
 	result, err := c.ruleEngine.ExecuteRule(ctx, stub, ruleID, entityData)
 	if err != nil {
-		return shim.Error(fmt.Sprintf("Failed to execute rule: %v", err))
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to execute rule: %w", err))
 	}
 
 	resultBytes, _ := json.Marshal(result)
@@ -320,13 +717,13 @@ func (c *ComplianceContract) ExecuteRulesForEntity(stub shim.ChaincodeStubInterf
 	entityType := args[0]
 	var entityData map[string]interface{}
 	if err := json.Unmarshal([]byte(args[1]), &entityData); err != nil {
-		return shim.Error(fmt.Sprintf("Failed to unmarshal entity data: %v", err))
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to unmarshal entity data: %w", err))
 	}
 
 	ctx := context.Background()
 	results, err := c.ruleEngine.ExecuteRulesForEntity(ctx, stub, entityType, entityData)
 	if err != nil {
-		return shim.Error(fmt.Sprintf("Failed to execute rules for entity: %v", err))
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to execute rules for entity: %w", err))
 	}
 
 	resultsBytes, _ := json.Marshal(results)
@@ -342,13 +739,13 @@ func (c *ComplianceContract) ExecuteRulesForEvent(stub shim.ChaincodeStubInterfa
 	eventType := args[0]
 	var entityData map[string]interface{}
 	if err := json.Unmarshal([]byte(args[1]), &entityData); err != nil {
-		return shim.Error(fmt.Sprintf("Failed to unmarshal entity data: %v", err))
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to unmarshal entity data: %w", err))
 	}
 
 	ctx := context.Background()
 	results, err := c.ruleEngine.ExecuteRulesForEvent(ctx, stub, eventType, entityData)
 	if err != nil {
-		return shim.Error(fmt.Sprintf("Failed to execute rules for event: %v", err))
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to execute rules for event: %w", err))
 	}
 
 	resultsBytes, _ := json.Marshal(results)
@@ -367,13 +764,13 @@ func (c *ComplianceContract) ValidateRule(stub shim.ChaincodeStubInterface, args
 
 	var rule domain.ComplianceRule
 	if err := json.Unmarshal([]byte(args[0]), &rule); err != nil {
-		return shim.Error(fmt.Sprintf("Failed to unmarshal rule: %v", err))
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to unmarshal rule: %w", err))
 	}
 
 	ctx := context.Background()
 	results, err := c.ruleEngine.ValidateRule(ctx, stub, &rule)
 	if err != nil {
-		return shim.Error(fmt.Sprintf("Failed to validate rule: %v", err))
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to validate rule: %w", err))
 	}
 
 	resultsBytes, _ := json.Marshal(results)
@@ -392,7 +789,7 @@ func (c *ComplianceContract) TestRule(stub shim.ChaincodeStubInterface, args []s
 	ctx := context.Background()
 	result, err := c.ruleEngine.TestRule(ctx, stub, ruleID, testCaseID)
 	if err != nil {
-		return shim.Error(fmt.Sprintf("Failed to test rule: %v", err))
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to test rule: %w", err))
 	}
 
 	resultBytes, _ := json.Marshal(result)
@@ -410,7 +807,7 @@ func (c *ComplianceContract) RunAllTests(stub shim.ChaincodeStubInterface, args
 	ctx := context.Background()
 	results, err := c.ruleEngine.RunAllTests(ctx, stub, ruleID)
 	if err != nil {
-		return shim.Error(fmt.Sprintf("Failed to run all tests: %v", err))
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to run all tests: %w", err))
 	}
 
 	resultsBytes, _ := json.Marshal(results)
@@ -432,7 +829,7 @@ func (c *ComplianceContract) ResolveDependencies(stub shim.ChaincodeStubInterfac
 	ctx := context.Background()
 	dependencies, err := c.ruleEngine.ResolveDependencies(ctx, stub, ruleID)
 	if err != nil {
-		return shim.Error(fmt.Sprintf("Failed to resolve dependencies: %v", err))
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to resolve dependencies: %w", err))
 	}
 
 	dependenciesBytes, _ := json.Marshal(dependencies)
@@ -450,7 +847,7 @@ func (c *ComplianceContract) CheckConflicts(stub shim.ChaincodeStubInterface, ar
 	ctx := context.Background()
 	conflicts, err := c.ruleEngine.CheckConflicts(ctx, stub, ruleID)
 	if err != nil {
-		return shim.Error(fmt.Sprintf("Failed to check conflicts: %v", err))
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to check conflicts: %w", err))
 	}
 
 	conflictsBytes, _ := json.Marshal(conflicts)
@@ -465,13 +862,13 @@ func (c *ComplianceContract) GetExecutionOrder(stub shim.ChaincodeStubInterface,
 
 	var ruleIDs []string
 	if err := json.Unmarshal([]byte(args[0]), &ruleIDs); err != nil {
-		return shim.Error(fmt.Sprintf("Failed to unmarshal rule IDs: %v", err))
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to unmarshal rule IDs: %w", err))
 	}
 
 	ctx := context.Background()
 	order, err := c.ruleEngine.GetExecutionOrder(ctx, stub, ruleIDs)
 	if err != nil {
-		return shim.Error(fmt.Sprintf("Failed to get execution order: %v", err))
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get execution order: %w", err))
 	}
 
 	orderBytes, _ := json.Marshal(order)
@@ -494,7 +891,7 @@ func (c *ComplianceContract) SubmitRuleForApproval(stub shim.ChaincodeStubInterf
 
 	request, err := c.approvalManager.SubmitRuleForApproval(stub, ruleID, requestedBy, justification)
 	if err != nil {
-		return shim.Error(fmt.Sprintf("Failed to submit rule for approval: %v", err))
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to submit rule for approval: %w", err))
 	}
 
 	requestBytes, _ := json.Marshal(request)
@@ -512,7 +909,7 @@ func (c *ComplianceContract) ApproveRule(stub shim.ChaincodeStubInterface, args
 	comments := args[2]
 
 	if err := c.approvalManager.ApproveRule(stub, requestID, reviewedBy, comments); err != nil {
-		return shim.Error(fmt.Sprintf("Failed to approve rule: %v", err))
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to approve rule: %w", err))
 	}
 
 	return shim.Success([]byte("Rule approved successfully"))
@@ -529,7 +926,7 @@ func (c *ComplianceContract) RejectRule(stub shim.ChaincodeStubInterface, args [
 	comments := args[2]
 
 	if err := c.approvalManager.RejectRule(stub, requestID, reviewedBy, comments); err != nil {
-		return shim.Error(fmt.Sprintf("Failed to reject rule: %v", err))
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to reject rule: %w", err))
 	}
 
 	return shim.Success([]byte("Rule rejected successfully"))
@@ -539,7 +936,7 @@ func (c *ComplianceContract) RejectRule(stub shim.ChaincodeStubInterface, args [
 func (c *ComplianceContract) GetPendingApprovals(stub shim.ChaincodeStubInterface, args []string) peer.Response {
 	requests, err := c.approvalManager.GetPendingApprovals(stub)
 	if err != nil {
-		return shim.Error(fmt.Sprintf("Failed to get pending approvals: %v", err))
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get pending approvals: %w", err))
 	}
 
 	requestsBytes, _ := json.Marshal(requests)
@@ -555,7 +952,7 @@ func (c *ComplianceContract) GetApprovalHistory(stub shim.ChaincodeStubInterface
 	ruleID := args[0]
 	history, err := c.approvalManager.GetApprovalHistory(stub, ruleID)
 	if err != nil {
-		return shim.Error(fmt.Sprintf("Failed to get approval history: %v", err))
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get approval history: %w", err))
 	}
 
 	historyBytes, _ := json.Marshal(history)
@@ -575,13 +972,23 @@ func (c *ComplianceContract) GetComplianceEvents(stub shim.ChaincodeStubInterfac
 	eventType := args[0]
 	events, err := c.eventEmitter.(*domain.FabricEventEmitter).GetEventsByType(stub, eventType)
 	if err != nil {
-		return shim.Error(fmt.Sprintf("Failed to get compliance events: %v", err))
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get compliance events: %w", err))
 	}
 
 	eventsBytes, _ := json.Marshal(events)
 	return shim.Success(eventsBytes)
 }
 
+// QueryComplianceEvents retrieves a page of compliance events matching a selector (CouchDB only)
+func (c *ComplianceContract) QueryComplianceEvents(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	resultBytes, err := c.eventQueryHandler.QueryComplianceEvents(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to query compliance events: %w", err))
+	}
+
+	return shim.Success(resultBytes)
+}
+
 // GetEventsByRule retrieves events for a specific rule
 func (c *ComplianceContract) GetEventsByRule(stub shim.ChaincodeStubInterface, args []string) peer.Response {
 	if len(args) != 1 {
@@ -591,7 +998,7 @@ func (c *ComplianceContract) GetEventsByRule(stub shim.ChaincodeStubInterface, a
 	ruleID := args[0]
 	events, err := c.eventEmitter.(*domain.FabricEventEmitter).GetEventsByRule(stub, ruleID)
 	if err != nil {
-		return shim.Error(fmt.Sprintf("Failed to get events by rule: %v", err))
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get events by rule: %w", err))
 	}
 
 	eventsBytes, _ := json.Marshal(events)
@@ -607,7 +1014,7 @@ func (c *ComplianceContract) GetEventsByEntity(stub shim.ChaincodeStubInterface,
 	entityID := args[0]
 	events, err := c.eventEmitter.(*domain.FabricEventEmitter).GetEventsByEntity(stub, entityID)
 	if err != nil {
-		return shim.Error(fmt.Sprintf("Failed to get events by entity: %v", err))
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get events by entity: %w", err))
 	}
 
 	eventsBytes, _ := json.Marshal(events)
@@ -624,7 +1031,7 @@ func (c *ComplianceContract) AcknowledgeEvent(stub shim.ChaincodeStubInterface,
 	acknowledgedBy := args[1]
 
 	if err := c.eventEmitter.(*domain.FabricEventEmitter).AcknowledgeEvent(stub, eventID, acknowledgedBy); err != nil {
-		return shim.Error(fmt.Sprintf("Failed to acknowledge event: %v", err))
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to acknowledge event: %w", err))
 	}
 
 	return shim.Success([]byte("Event acknowledged successfully"))
@@ -641,88 +1048,1181 @@ func (c *ComplianceContract) UpdateEventResolution(stub shim.ChaincodeStubInterf
 	notes := args[2]
 
 	if err := c.eventEmitter.(*domain.FabricEventEmitter).UpdateEventResolution(stub, eventID, status, notes); err != nil {
-		return shim.Error(fmt.Sprintf("Failed to update event resolution: %v", err))
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to update event resolution: %w", err))
 	}
 
 	return shim.Success([]byte("Event resolution updated successfully"))
 }
 
 // ============================================================================
-// INITIALIZATION FUNCTIONS
+// DECISION JOURNAL FUNCTIONS
 // ============================================================================
 
-// InitLedger initializes the ledger with sample compliance rules
-func (c *ComplianceContract) InitLedger(stub shim.ChaincodeStubInterface) peer.Response {
-	// Create sample rules with comprehensive structure
-	sampleRules := []*domain.ComplianceRule{
-		{
-			RuleID:              "RULE_KYC_001",
-			RuleName:            "Customer KYC Verification Required",
-			RuleDescription:     "All customers must complete KYC verification before account activation",
-			Version:             "1.0.0",
-			RuleLogic:           `{"type": "validation", "validations": [{"field": "kycStatus", "required": true}, {"field": "kycStatus", "value": "VERIFIED"}]}`,
-			ExecutionMode:       domain.ExecutionModeSync,
-			Priority:            domain.PriorityHigh,
-			AppliesToDomain:     "CUSTOMER",
-			AppliesToEntityType: "Customer",
-			TriggerEvents:       []string{"CustomerCreated", "CustomerUpdated"},
-			Status:              domain.RuleStatusActive,
-			EffectiveDate:       time.Now(),
-			CreatedBy:           "SYSTEM",
-			CreationDate:        time.Now(),
-			LastModifiedBy:      "SYSTEM",
-			LastModifiedDate:    time.Now(),
-			BusinessJustification: "Regulatory requirement for customer identification",
-			TestCases: []domain.RuleTestCase{
-				{
-					TestID:          "TEST_KYC_001_PASS",
-					TestName:        "Valid KYC Status",
-					TestDescription: "Customer with verified KYC status should pass",
-					InputData:       map[string]interface{}{"kycStatus": "VERIFIED"},
-					ExpectedResult:  domain.RuleExecutionResult{Passed: true},
-					CreatedBy:       "SYSTEM",
-					CreationDate:    time.Now(),
-				},
-			},
-		},
-		{
-			RuleID:              "RULE_AML_001",
-			RuleName:            "AML Screening Required",
-			RuleDescription:     "All transactions above threshold require AML screening",
-			Version:             "1.0.0",
-			RuleLogic:           `{"type": "threshold", "field": "amount", "threshold": 10000, "operator": ">"}`,
-			ExecutionMode:       domain.ExecutionModeSync,
-			Priority:            domain.PriorityCritical,
-			AppliesToDomain:     "LOAN",
-			AppliesToEntityType: "LoanApplication",
-			TriggerEvents:       []string{"LoanSubmitted"},
-			Status:              domain.RuleStatusActive,
-			EffectiveDate:       time.Now(),
-			CreatedBy:           "SYSTEM",
-			CreationDate:        time.Now(),
-			LastModifiedBy:      "SYSTEM",
-			LastModifiedDate:    time.Now(),
-			BusinessJustification: "Anti-money laundering compliance requirement",
-			TestCases: []domain.RuleTestCase{
-				{
-					TestID:          "TEST_AML_001_TRIGGER",
-					TestName:        "High Amount Triggers AML",
-					TestDescription: "Loan amount above 10000 should trigger AML screening",
-					InputData:       map[string]interface{}{"amount": 15000.0},
-					ExpectedResult:  domain.RuleExecutionResult{Passed: true},
-					CreatedBy:       "SYSTEM",
-					CreationDate:    time.Now(),
-				},
-			},
-		},
+// RecordDecision journals a sensitive decision pending regulator co-signature
+func (c *ComplianceContract) RecordDecision(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	entryBytes, err := c.journalHandler.RecordDecision(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to record decision: %w", err))
 	}
 
-	// Save sample rules
-	for _, rule := range sampleRules {
-		if err := c.ruleRepository.SaveRule(stub, rule); err != nil {
-			return shim.Error(fmt.Sprintf("Failed to save sample rule %s: %v", rule.RuleID, err))
-		}
+	return shim.Success(entryBytes)
+}
+
+// CosignDecision adds the regulator co-signature to a journaled decision
+func (c *ComplianceContract) CosignDecision(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	entryBytes, err := c.journalHandler.CosignDecision(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to co-sign decision: %w", err))
 	}
 
-	return shim.Success([]byte("Ledger initialized with sample compliance rules"))
-}
\ No newline at end of file
+	return shim.Success(entryBytes)
+}
+
+// GetDecisionJournal retrieves the journaled decisions for an entity
+func (c *ComplianceContract) GetDecisionJournal(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	entriesBytes, err := c.journalHandler.GetDecisionJournal(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get decision journal: %w", err))
+	}
+
+	return shim.Success(entriesBytes)
+}
+
+// ============================================================================
+// AUDIT PACKAGE FUNCTIONS
+// ============================================================================
+
+// ExportAuditPackage bundles the evidence held about an entity or case and records its manifest
+func (c *ComplianceContract) ExportAuditPackage(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	packageBytes, err := c.auditPackageHandler.ExportAuditPackage(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to export audit package: %w", err))
+	}
+
+	return shim.Success(packageBytes)
+}
+
+// GetAuditManifest retrieves the manifest recorded for an exported audit package
+func (c *ComplianceContract) GetAuditManifest(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	manifestBytes, err := c.auditPackageHandler.GetAuditManifest(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get audit manifest: %w", err))
+	}
+
+	return shim.Success(manifestBytes)
+}
+
+// VerifyAuditPackage checks an exported audit package against its recorded manifest
+func (c *ComplianceContract) VerifyAuditPackage(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	verificationBytes, err := c.auditPackageHandler.VerifyAuditPackage(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to verify audit package: %w", err))
+	}
+
+	return shim.Success(verificationBytes)
+}
+
+// ExportEntityAudit returns an entity's full chronological history with a hash chain over it
+func (c *ComplianceContract) ExportEntityAudit(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	auditBytes, err := c.entityAuditHandler.ExportEntityAudit(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to export entity audit: %w", err))
+	}
+
+	return shim.Success(auditBytes)
+}
+
+// VerifyEntityAudit checks an exported entity history against the ledger
+func (c *ComplianceContract) VerifyEntityAudit(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	verificationBytes, err := c.entityAuditHandler.VerifyEntityAudit(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to verify entity audit: %w", err))
+	}
+
+	return shim.Success(verificationBytes)
+}
+
+// ============================================================================
+// PEP LIST FUNCTIONS
+// ============================================================================
+
+// AddPEPEntry adds a politically exposed person to the on-chain PEP list
+func (c *ComplianceContract) AddPEPEntry(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	entryBytes, err := c.pepListManager.AddPEPEntry(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to add PEP entry: %w", err))
+	}
+
+	return shim.Success(entryBytes)
+}
+
+// BulkImportPEPList imports a batch of PEP entries from an external list provider
+func (c *ComplianceContract) BulkImportPEPList(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	resultBytes, err := c.pepListManager.BulkImportPEPList(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to import PEP list: %w", err))
+	}
+
+	return shim.Success(resultBytes)
+}
+
+// DeactivatePEPEntry withdraws a PEP entry from screening
+func (c *ComplianceContract) DeactivatePEPEntry(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	entryBytes, err := c.pepListManager.DeactivatePEPEntry(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to deactivate PEP entry: %w", err))
+	}
+
+	return shim.Success(entryBytes)
+}
+
+// GetPEPEntry retrieves a PEP entry
+func (c *ComplianceContract) GetPEPEntry(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	entryBytes, err := c.pepListManager.GetPEPEntry(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get PEP entry: %w", err))
+	}
+
+	return shim.Success(entryBytes)
+}
+
+// QueryPEPEntriesByCountry retrieves the PEP entries for a country
+func (c *ComplianceContract) QueryPEPEntriesByCountry(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	entriesBytes, err := c.pepListManager.QueryPEPEntriesByCountry(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to query PEP entries: %w", err))
+	}
+
+	return shim.Success(entriesBytes)
+}
+
+// ScreenPEP screens a name against the PEP list
+func (c *ComplianceContract) ScreenPEP(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	resultBytes, err := c.amlHandler.ScreenPEP(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to screen PEP: %w", err))
+	}
+
+	return shim.Success(resultBytes)
+}
+
+// ============================================================================
+// SANCTION LIST FUNCTIONS
+// ============================================================================
+
+// CreateSanctionList defines a managed sanction list
+func (c *ComplianceContract) CreateSanctionList(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	listBytes, err := c.sanctionListManager.CreateSanctionList(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to create sanction list: %w", err))
+	}
+
+	return shim.Success(listBytes)
+}
+
+// UpdateSanctionList loads entries or an official list document into a managed sanction list
+func (c *ComplianceContract) UpdateSanctionList(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	resultBytes, err := c.sanctionListManager.UpdateSanctionList(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to update sanction list: %w", err))
+	}
+
+	return shim.Success(resultBytes)
+}
+
+// GetSanctionList retrieves a managed sanction list definition
+func (c *ComplianceContract) GetSanctionList(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	listBytes, err := c.sanctionListManager.GetSanctionList(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get sanction list: %w", err))
+	}
+
+	return shim.Success(listBytes)
+}
+
+// GetActiveSanctionLists retrieves the active managed sanction lists
+func (c *ComplianceContract) GetActiveSanctionLists(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	listsBytes, err := c.sanctionListManager.GetActiveSanctionLists(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get sanction lists: %w", err))
+	}
+
+	return shim.Success(listsBytes)
+}
+
+// SearchSanctionEntries searches the entries imported into managed sanction lists by name
+func (c *ComplianceContract) SearchSanctionEntries(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	entriesBytes, err := c.sanctionListManager.SearchSanctionEntries(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to search sanction entries: %w", err))
+	}
+
+	return shim.Success(entriesBytes)
+}
+
+// ============================================================================
+// TEXT SCREENING FUNCTIONS
+// ============================================================================
+
+// AddScreeningTerm adds a prohibited activity term to the on-chain screening list
+func (c *ComplianceContract) AddScreeningTerm(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	termBytes, err := c.textScreeningManager.AddScreeningTerm(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to add screening term: %w", err))
+	}
+
+	return shim.Success(termBytes)
+}
+
+// DeactivateScreeningTerm withdraws a screening term
+func (c *ComplianceContract) DeactivateScreeningTerm(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	termBytes, err := c.textScreeningManager.DeactivateScreeningTerm(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to deactivate screening term: %w", err))
+	}
+
+	return shim.Success(termBytes)
+}
+
+// GetScreeningTerms lists the active screening terms
+func (c *ComplianceContract) GetScreeningTerms(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	termsBytes, err := c.textScreeningManager.GetScreeningTerms(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get screening terms: %w", err))
+	}
+
+	return shim.Success(termsBytes)
+}
+
+// ScreenText screens free-text fields against the screening terms
+func (c *ComplianceContract) ScreenText(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	resultBytes, err := c.textScreeningManager.ScreenText(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to screen text: %w", err))
+	}
+
+	return shim.Success(resultBytes)
+}
+
+// RecordLoanDefault raises a loan default reported by the loan chaincode as a compliance event
+func (c *ComplianceContract) RecordLoanDefault(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	resultBytes, err := c.loanDefaultHandler.RecordLoanDefault(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to record loan default: %w", err))
+	}
+
+	return shim.Success(resultBytes)
+}
+
+// SetChannelFraudRule adds or replaces the fraud rule for an origination channel
+func (c *ComplianceContract) SetChannelFraudRule(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	ruleBytes, err := c.channelMonitoringHandler.SetChannelFraudRule(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to set channel fraud rule: %w", err))
+	}
+
+	return shim.Success(ruleBytes)
+}
+
+// GetChannelFraudRules lists the channel fraud rules
+func (c *ComplianceContract) GetChannelFraudRules(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	rulesBytes, err := c.channelMonitoringHandler.GetChannelFraudRules(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get channel fraud rules: %w", err))
+	}
+
+	return shim.Success(rulesBytes)
+}
+
+// MonitorApplicationChannel applies channel fraud rules to a loan application reported by the loan chaincode
+func (c *ComplianceContract) MonitorApplicationChannel(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	resultBytes, err := c.channelMonitoringHandler.MonitorApplicationChannel(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to monitor application channel: %w", err))
+	}
+
+	return shim.Success(resultBytes)
+}
+
+// GetDeviceApplications lists the loan applications submitted from a device
+func (c *ComplianceContract) GetDeviceApplications(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	applicationsBytes, err := c.channelMonitoringHandler.GetDeviceApplications(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get device applications: %w", err))
+	}
+
+	return shim.Success(applicationsBytes)
+}
+
+// SetTransactionTypologyRule tunes a transaction monitoring typology
+func (c *ComplianceContract) SetTransactionTypologyRule(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	ruleBytes, err := c.transactionMonitoringHandler.SetTransactionTypologyRule(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to set typology rule: %w", err))
+	}
+
+	return shim.Success(ruleBytes)
+}
+
+// GetTransactionTypologyRules lists the typology rules in force
+func (c *ComplianceContract) GetTransactionTypologyRules(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	rulesBytes, err := c.transactionMonitoringHandler.GetTransactionTypologyRules(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get typology rules: %w", err))
+	}
+
+	return shim.Success(rulesBytes)
+}
+
+// IngestTransactions records transaction summaries and raises alerts for typologies they trip
+func (c *ComplianceContract) IngestTransactions(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	resultBytes, err := c.transactionMonitoringHandler.IngestTransactions(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to ingest transactions: %w", err))
+	}
+
+	return shim.Success(resultBytes)
+}
+
+// MapPaymentMessage converts an ISO 20022 payment message to AML screening data and monitoring
+// transactions
+func (c *ComplianceContract) MapPaymentMessage(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	mappingBytes, err := c.transactionMonitoringHandler.MapPaymentMessage(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to map payment message: %w", err))
+	}
+
+	return shim.Success(mappingBytes)
+}
+
+// IngestPaymentMessage ingests the credit transfers of an ISO 20022 payment message for monitoring
+func (c *ComplianceContract) IngestPaymentMessage(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	resultBytes, err := c.transactionMonitoringHandler.IngestPaymentMessage(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to ingest payment message: %w", err))
+	}
+
+	return shim.Success(resultBytes)
+}
+
+// GetTransactionAlert retrieves a transaction monitoring alert with its evidence
+func (c *ComplianceContract) GetTransactionAlert(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	alertBytes, err := c.transactionMonitoringHandler.GetTransactionAlert(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get transaction alert: %w", err))
+	}
+
+	return shim.Success(alertBytes)
+}
+
+// GetTransactionAlertsByCustomer lists a customer's transaction monitoring alerts
+func (c *ComplianceContract) GetTransactionAlertsByCustomer(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	alertsBytes, err := c.transactionMonitoringHandler.GetTransactionAlertsByCustomer(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get customer transaction alerts: %w", err))
+	}
+
+	return shim.Success(alertsBytes)
+}
+
+// SetCountryRisk sets a country's geographic risk score
+func (c *ComplianceContract) SetCountryRisk(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	riskBytes, err := c.countryRiskHandler.SetCountryRisk(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to set country risk: %w", err))
+	}
+
+	return shim.Success(riskBytes)
+}
+
+// GetCountryRiskTable lists the country risk scores in force
+func (c *ComplianceContract) GetCountryRiskTable(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	tableBytes, err := c.countryRiskHandler.GetCountryRiskTable(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get country risk table: %w", err))
+	}
+
+	return shim.Success(tableBytes)
+}
+
+// GetCountryRiskHistory lists the changes to a country's risk score
+func (c *ComplianceContract) GetCountryRiskHistory(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	historyBytes, err := c.countryRiskHandler.GetCountryRiskHistory(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get country risk history: %w", err))
+	}
+
+	return shim.Success(historyBytes)
+}
+
+// CreateRiskCatalogEntry adds an occupation or industry to its risk catalog
+func (c *ComplianceContract) CreateRiskCatalogEntry(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	entryBytes, err := c.riskCatalogHandler.CreateRiskCatalogEntry(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to create risk catalog entry: %w", err))
+	}
+
+	return shim.Success(entryBytes)
+}
+
+// UpdateRiskCatalogEntry adds a version of a risk catalog entry, effective from its effective date
+func (c *ComplianceContract) UpdateRiskCatalogEntry(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	entryBytes, err := c.riskCatalogHandler.UpdateRiskCatalogEntry(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to update risk catalog entry: %w", err))
+	}
+
+	return shim.Success(entryBytes)
+}
+
+// RetireRiskCatalogEntry takes an occupation or industry out of its risk catalog
+func (c *ComplianceContract) RetireRiskCatalogEntry(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	entryBytes, err := c.riskCatalogHandler.RetireRiskCatalogEntry(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to retire risk catalog entry: %w", err))
+	}
+
+	return shim.Success(entryBytes)
+}
+
+// GetRiskCatalogEntry lists every version of a risk catalog entry
+func (c *ComplianceContract) GetRiskCatalogEntry(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	versionsBytes, err := c.riskCatalogHandler.GetRiskCatalogEntry(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get risk catalog entry: %w", err))
+	}
+
+	return shim.Success(versionsBytes)
+}
+
+// GetRiskCatalog lists a risk catalog's entries in force
+func (c *ComplianceContract) GetRiskCatalog(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	catalogBytes, err := c.riskCatalogHandler.GetRiskCatalog(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get risk catalog: %w", err))
+	}
+
+	return shim.Success(catalogBytes)
+}
+
+// CreateRiskModelVersion drafts the next version of the AML risk model weights and thresholds
+func (c *ComplianceContract) CreateRiskModelVersion(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	modelBytes, err := c.riskModelHandler.CreateRiskModelVersion(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to create risk model version: %w", err))
+	}
+
+	return shim.Success(modelBytes)
+}
+
+// ActivateRiskModelVersion puts a drafted risk model version in force
+func (c *ComplianceContract) ActivateRiskModelVersion(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	modelBytes, err := c.riskModelHandler.ActivateRiskModelVersion(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to activate risk model version: %w", err))
+	}
+
+	return shim.Success(modelBytes)
+}
+
+// GetActiveRiskModel returns the risk model version scoring AML checks
+func (c *ComplianceContract) GetActiveRiskModel(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	modelBytes, err := c.riskModelHandler.GetActiveRiskModel(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get active risk model: %w", err))
+	}
+
+	return shim.Success(modelBytes)
+}
+
+// GetRiskModelVersions lists every version of the AML risk model
+func (c *ComplianceContract) GetRiskModelVersions(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	versionsBytes, err := c.riskModelHandler.GetRiskModelVersions(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get risk model versions: %w", err))
+	}
+
+	return shim.Success(versionsBytes)
+}
+
+// AddScreeningWhitelistEntry whitelists a sanction match reviewed as a false positive for a customer
+func (c *ComplianceContract) AddScreeningWhitelistEntry(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	entryBytes, err := c.whitelistHandler.AddScreeningWhitelistEntry(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to add screening whitelist entry: %w", err))
+	}
+
+	return shim.Success(entryBytes)
+}
+
+// RevokeScreeningWhitelistEntry withdraws a false positive disposition
+func (c *ComplianceContract) RevokeScreeningWhitelistEntry(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	entryBytes, err := c.whitelistHandler.RevokeScreeningWhitelistEntry(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to revoke screening whitelist entry: %w", err))
+	}
+
+	return shim.Success(entryBytes)
+}
+
+// GetScreeningWhitelist lists a customer's whitelisted sanction matches
+func (c *ComplianceContract) GetScreeningWhitelist(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	entriesBytes, err := c.whitelistHandler.GetScreeningWhitelist(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get screening whitelist: %w", err))
+	}
+
+	return shim.Success(entriesBytes)
+}
+
+// SetGovernanceMember admits a consortium member organization or changes its voting weight
+func (c *ComplianceContract) SetGovernanceMember(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	memberBytes, err := c.governanceHandler.SetGovernanceMember(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to set governance member: %w", err))
+	}
+
+	return shim.Success(memberBytes)
+}
+
+// GetGovernanceMembers lists the consortium's member organizations
+func (c *ComplianceContract) GetGovernanceMembers(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	membersBytes, err := c.governanceHandler.GetGovernanceMembers(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get governance members: %w", err))
+	}
+
+	return shim.Success(membersBytes)
+}
+
+// ProposeParameterChange opens a consortium vote on a platform parameter change
+func (c *ComplianceContract) ProposeParameterChange(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	proposalBytes, err := c.governanceHandler.ProposeParameterChange(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to propose parameter change: %w", err))
+	}
+
+	return shim.Success(proposalBytes)
+}
+
+// CastGovernanceVote records the invoking organization's vote, enacting the proposal once approved
+func (c *ComplianceContract) CastGovernanceVote(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	proposalBytes, err := c.governanceHandler.CastGovernanceVote(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to cast governance vote: %w", err))
+	}
+
+	return shim.Success(proposalBytes)
+}
+
+// CloseGovernanceProposal decides a proposal whose voting period has ended
+func (c *ComplianceContract) CloseGovernanceProposal(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	proposalBytes, err := c.governanceHandler.CloseGovernanceProposal(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to close governance proposal: %w", err))
+	}
+
+	return shim.Success(proposalBytes)
+}
+
+// GetGovernanceProposal returns a proposal with its public voting record
+func (c *ComplianceContract) GetGovernanceProposal(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	proposalBytes, err := c.governanceHandler.GetGovernanceProposal(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get governance proposal: %w", err))
+	}
+
+	return shim.Success(proposalBytes)
+}
+
+// GetGovernanceProposals lists governance proposals, optionally by status
+func (c *ComplianceContract) GetGovernanceProposals(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	proposalsBytes, err := c.governanceHandler.GetGovernanceProposals(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get governance proposals: %w", err))
+	}
+
+	return shim.Success(proposalsBytes)
+}
+
+// GetPlatformParameter returns the value of a governed platform parameter in force
+func (c *ComplianceContract) GetPlatformParameter(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	parameterBytes, err := c.governanceHandler.GetPlatformParameter(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get platform parameter: %w", err))
+	}
+
+	return shim.Success(parameterBytes)
+}
+
+// GetPlatformParameters lists every governed platform parameter in force
+func (c *ComplianceContract) GetPlatformParameters(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	parametersBytes, err := c.governanceHandler.GetPlatformParameters(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get platform parameters: %w", err))
+	}
+
+	return shim.Success(parametersBytes)
+}
+
+// GenerateComplianceReport assembles a large-transaction or threshold-breach report for a period
+func (c *ComplianceContract) GenerateComplianceReport(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	reportBytes, err := c.reportHandler.GenerateComplianceReport(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to generate compliance report: %w", err))
+	}
+
+	return shim.Success(reportBytes)
+}
+
+// GetComplianceReport returns a regulatory report with its included record references
+func (c *ComplianceContract) GetComplianceReport(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	reportBytes, err := c.reportHandler.GetComplianceReport(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get compliance report: %w", err))
+	}
+
+	return shim.Success(reportBytes)
+}
+
+// QueryReportsByType lists the regulatory reports of a type, optionally for one period
+func (c *ComplianceContract) QueryReportsByType(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	reportsBytes, err := c.reportHandler.QueryReportsByType(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to query compliance reports: %w", err))
+	}
+
+	return shim.Success(reportsBytes)
+}
+
+// FinalizeReportingPeriod files the current reports for a period and locks it
+func (c *ComplianceContract) FinalizeReportingPeriod(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	periodBytes, err := c.reportHandler.FinalizeReportingPeriod(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to finalize reporting period: %w", err))
+	}
+
+	return shim.Success(periodBytes)
+}
+
+// GetReportingPeriod returns a reporting period's status and filed reports
+func (c *ComplianceContract) GetReportingPeriod(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	periodBytes, err := c.reportHandler.GetReportingPeriod(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get reporting period: %w", err))
+	}
+
+	return shim.Success(periodBytes)
+}
+
+// CreateEscalation opens an escalation for a compliance violation, routed to its level and team
+func (c *ComplianceContract) CreateEscalation(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	resultBytes, err := c.escalationHandler.CreateEscalation(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to create escalation: %w", err))
+	}
+
+	return shim.Success(resultBytes)
+}
+
+// AssignEscalation assigns an escalation to a specific person
+func (c *ComplianceContract) AssignEscalation(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	resultBytes, err := c.escalationHandler.AssignEscalation(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to assign escalation: %w", err))
+	}
+
+	return shim.Success(resultBytes)
+}
+
+// EscalateToNextLevel escalates the violation to the next level
+func (c *ComplianceContract) EscalateToNextLevel(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	resultBytes, err := c.escalationHandler.EscalateToNextLevel(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to escalate: %w", err))
+	}
+
+	return shim.Success(resultBytes)
+}
+
+// ResolveEscalation resolves an escalation with resolution details
+func (c *ComplianceContract) ResolveEscalation(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	resultBytes, err := c.escalationHandler.ResolveEscalation(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to resolve escalation: %w", err))
+	}
+
+	return shim.Success(resultBytes)
+}
+
+// AddEscalationComment adds a comment to an escalation
+func (c *ComplianceContract) AddEscalationComment(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	resultBytes, err := c.escalationHandler.AddComment(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to add escalation comment: %w", err))
+	}
+
+	return shim.Success(resultBytes)
+}
+
+// GetEscalation retrieves an escalation by ID
+func (c *ComplianceContract) GetEscalation(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	resultBytes, err := c.escalationHandler.GetEscalation(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get escalation: %w", err))
+	}
+
+	return shim.Success(resultBytes)
+}
+
+// GetEscalationsByStatus retrieves escalations by status
+func (c *ComplianceContract) GetEscalationsByStatus(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	resultBytes, err := c.escalationHandler.GetEscalationsByStatus(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get escalations by status: %w", err))
+	}
+
+	return shim.Success(resultBytes)
+}
+
+// GetEscalationsByAssignee retrieves escalations assigned to a specific person
+func (c *ComplianceContract) GetEscalationsByAssignee(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	resultBytes, err := c.escalationHandler.GetEscalationsByAssignee(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get escalations by assignee: %w", err))
+	}
+
+	return shim.Success(resultBytes)
+}
+
+// SetEscalationRoutingRule adds or replaces the routing rule for a violation type and severity
+func (c *ComplianceContract) SetEscalationRoutingRule(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	resultBytes, err := c.escalationHandler.SetEscalationRoutingRule(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to set escalation routing rule: %w", err))
+	}
+
+	return shim.Success(resultBytes)
+}
+
+// GetEscalationRoutingRules returns every escalation routing rule
+func (c *ComplianceContract) GetEscalationRoutingRules(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	resultBytes, err := c.escalationHandler.GetEscalationRoutingRules(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get escalation routing rules: %w", err))
+	}
+
+	return shim.Success(resultBytes)
+}
+
+// EscalateOverdue bumps escalations past their SLA due date to the next level and raises alerts
+func (c *ComplianceContract) EscalateOverdue(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	resultBytes, err := c.escalationHandler.EscalateOverdue(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to escalate overdue escalations: %w", err))
+	}
+
+	return shim.Success(resultBytes)
+}
+
+// GetExpiringChecks lists the current AML checks expiring before a date
+func (c *ComplianceContract) GetExpiringChecks(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	resultBytes, err := c.amlHandler.GetExpiringChecks(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get expiring checks: %w", err))
+	}
+
+	return shim.Success(resultBytes)
+}
+
+// TriggerPeriodicReview re-screens a batch of customers and raises events for lapsed checks
+func (c *ComplianceContract) TriggerPeriodicReview(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	resultBytes, err := c.amlHandler.TriggerPeriodicReview(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to trigger periodic review: %w", err))
+	}
+
+	return shim.Success(resultBytes)
+}
+
+// TargetedRescreen screens the customers resembling a newly added sanction entry straight away
+func (c *ComplianceContract) TargetedRescreen(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	resultBytes, err := c.amlHandler.TargetedRescreen(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to run targeted rescreen: %w", err))
+	}
+
+	return shim.Success(resultBytes)
+}
+
+// StartScreeningJob opens a resumable job re-screening every customer with a check on file
+func (c *ComplianceContract) StartScreeningJob(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	jobBytes, err := c.amlHandler.StartScreeningJob(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to start screening job: %w", err))
+	}
+
+	return shim.Success(jobBytes)
+}
+
+// BatchScreenEntities screens a batch of customers, or a screening job's next batch, in one transaction
+func (c *ComplianceContract) BatchScreenEntities(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	batchBytes, err := c.amlHandler.BatchScreenEntities(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to batch screen entities: %w", err))
+	}
+
+	return shim.Success(batchBytes)
+}
+
+// GetScreeningJob returns a screening job with its progress
+func (c *ComplianceContract) GetScreeningJob(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	jobBytes, err := c.amlHandler.GetScreeningJob(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get screening job: %w", err))
+	}
+
+	return shim.Success(jobBytes)
+}
+
+// GetScreeningBatch returns a batch screening's summary and per-entity results
+func (c *ComplianceContract) GetScreeningBatch(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	batchBytes, err := c.amlHandler.GetScreeningBatch(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get screening batch: %w", err))
+	}
+
+	return shim.Success(batchBytes)
+}
+
+// ============================================================================
+// ADVERSE MEDIA FUNCTIONS
+// ============================================================================
+
+// AddAdverseMediaRecord adds an adverse media record to the on-chain registry
+func (c *ComplianceContract) AddAdverseMediaRecord(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	recordBytes, err := c.adverseMediaManager.AddAdverseMediaRecord(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to add adverse media record: %w", err))
+	}
+
+	return shim.Success(recordBytes)
+}
+
+// DeactivateAdverseMediaRecord withdraws an adverse media record from screening
+func (c *ComplianceContract) DeactivateAdverseMediaRecord(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	recordBytes, err := c.adverseMediaManager.DeactivateAdverseMediaRecord(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to deactivate adverse media record: %w", err))
+	}
+
+	return shim.Success(recordBytes)
+}
+
+// GetAdverseMediaRecord retrieves an adverse media record
+func (c *ComplianceContract) GetAdverseMediaRecord(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	recordBytes, err := c.adverseMediaManager.GetAdverseMediaRecord(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get adverse media record: %w", err))
+	}
+
+	return shim.Success(recordBytes)
+}
+
+// QueryAdverseMediaByEntity retrieves the adverse media records concerning an entity
+func (c *ComplianceContract) QueryAdverseMediaByEntity(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	recordsBytes, err := c.adverseMediaManager.QueryAdverseMediaByEntity(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to query adverse media records: %w", err))
+	}
+
+	return shim.Success(recordsBytes)
+}
+
+// ============================================================================
+// OPERATIONAL FUNCTIONS
+// ============================================================================
+
+// SetFunctionFlag disables or restores a compliance chaincode function
+func (c *ComplianceContract) SetFunctionFlag(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	flagBytes, err := c.flagHandler.SetFunctionFlag(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to set function flag: %w", err))
+	}
+
+	return shim.Success(flagBytes)
+}
+
+// GetFunctionFlags retrieves all function flags for the compliance chaincode
+func (c *ComplianceContract) GetFunctionFlags(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	flagsBytes, err := c.flagHandler.GetFunctionFlags(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get function flags: %w", err))
+	}
+
+	return shim.Success(flagsBytes)
+}
+
+// SetSandboxActor designates or undesignates a sandbox (UAT) actor
+func (c *ComplianceContract) SetSandboxActor(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	actorBytes, err := c.sandboxHandler.SetSandboxActor(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to set sandbox actor: %w", err))
+	}
+
+	return shim.Success(actorBytes)
+}
+
+// GetSandboxActors retrieves all sandbox actor designations for the compliance chaincode
+func (c *ComplianceContract) GetSandboxActors(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	actorsBytes, err := c.sandboxHandler.GetSandboxActors(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get sandbox actors: %w", err))
+	}
+
+	return shim.Success(actorsBytes)
+}
+
+// ValidateStateCompatibility checks stored compliance state against the current schemas
+func (c *ComplianceContract) ValidateStateCompatibility(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	reportBytes, err := c.compatibilityHandler.ValidateStateCompatibility(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to validate state compatibility: %w", err))
+	}
+
+	return shim.Success(reportBytes)
+}
+
+// RecordTimestampCutover records the transaction from which compliance records carry transaction timestamps
+func (c *ComplianceContract) RecordTimestampCutover(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	cutoverBytes, err := c.compatibilityHandler.RecordTimestampCutover(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to record timestamp cutover: %w", err))
+	}
+
+	return shim.Success(cutoverBytes)
+}
+
+// GetTimestampCutover retrieves the recorded timestamp cutover
+func (c *ComplianceContract) GetTimestampCutover(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	cutoverBytes, err := c.compatibilityHandler.GetTimestampCutover(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get timestamp cutover: %w", err))
+	}
+
+	return shim.Success(cutoverBytes)
+}
+
+// ============================================================================
+// INITIALIZATION FUNCTIONS
+// ============================================================================
+
+// InitLedger initializes the ledger with sample compliance rules
+func (c *ComplianceContract) InitLedger(stub shim.ChaincodeStubInterface) peer.Response {
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(err)
+	}
+
+	// Create sample rules with comprehensive structure
+	sampleRules := []*domain.ComplianceRule{
+		{
+			RuleID:              "RULE_KYC_001",
+			RuleName:            "Customer KYC Verification Required",
+			RuleDescription:     "All customers must complete KYC verification before account activation",
+			Version:             "1.0.0",
+			RuleLogic:           `{"type": "validation", "validations": [{"field": "kycStatus", "required": true}, {"field": "kycStatus", "value": "VERIFIED"}]}`,
+			ExecutionMode:       domain.ExecutionModeSync,
+			Priority:            domain.PriorityHigh,
+			AppliesToDomain:     "CUSTOMER",
+			AppliesToEntityType: "Customer",
+			TriggerEvents:       []string{"CustomerCreated", "CustomerUpdated"},
+			Status:              domain.RuleStatusActive,
+			EffectiveDate:       now,
+			CreatedBy:           "SYSTEM",
+			CreationDate:        now,
+			LastModifiedBy:      "SYSTEM",
+			LastModifiedDate:    now,
+			BusinessJustification: "Regulatory requirement for customer identification",
+			TestCases: []domain.RuleTestCase{
+				{
+					TestID:          "TEST_KYC_001_PASS",
+					TestName:        "Valid KYC Status",
+					TestDescription: "Customer with verified KYC status should pass",
+					InputData:       map[string]interface{}{"kycStatus": "VERIFIED"},
+					ExpectedResult:  domain.RuleExecutionResult{Passed: true},
+					CreatedBy:       "SYSTEM",
+					CreationDate:    now,
+				},
+			},
+		},
+		{
+			RuleID:              "RULE_AML_001",
+			RuleName:            "AML Screening Required",
+			RuleDescription:     "All transactions above threshold require AML screening",
+			Version:             "1.0.0",
+			RuleLogic:           `{"type": "threshold", "field": "amount", "threshold": 10000, "operator": ">"}`,
+			ExecutionMode:       domain.ExecutionModeSync,
+			Priority:            domain.PriorityCritical,
+			AppliesToDomain:     "LOAN",
+			AppliesToEntityType: "LoanApplication",
+			TriggerEvents:       []string{"LoanSubmitted"},
+			Status:              domain.RuleStatusActive,
+			EffectiveDate:       now,
+			CreatedBy:           "SYSTEM",
+			CreationDate:        now,
+			LastModifiedBy:      "SYSTEM",
+			LastModifiedDate:    now,
+			BusinessJustification: "Anti-money laundering compliance requirement",
+			TestCases: []domain.RuleTestCase{
+				{
+					TestID:          "TEST_AML_001_TRIGGER",
+					TestName:        "High Amount Triggers AML",
+					TestDescription: "Loan amount above 10000 should trigger AML screening",
+					InputData:       map[string]interface{}{"amount": 15000.0},
+					ExpectedResult:  domain.RuleExecutionResult{Passed: true},
+					CreatedBy:       "SYSTEM",
+					CreationDate:    now,
+				},
+			},
+		},
+		{
+			RuleID:              "LOAN_AMOUNT_THRESHOLD",
+			RuleName:            "Loan Amount Threshold Rule",
+			RuleDescription:     "Loans above the threshold for their currency require additional approval",
+			Version:             "1.0.0",
+			RuleLogic:           `{"type": "threshold", "field": "requestedAmount", "threshold": 100000, "operator": "<=", "thresholdsByCurrency": {"EUR": 90000, "GBP": 80000, "CHF": 90000, "CAD": 135000, "AUD": 150000, "SGD": 135000, "HKD": 780000, "JPY": 15000000, "KWD": 30000, "BHD": 37000}}`,
+			ExecutionMode:       domain.ExecutionModeSync,
+			Priority:            domain.PriorityHigh,
+			AppliesToDomain:     "LOAN",
+			AppliesToEntityType: "LoanApplication",
+			TriggerEvents:       []string{"LoanSubmitted"},
+			Status:              domain.RuleStatusActive,
+			EffectiveDate:       now,
+			CreatedBy:           "SYSTEM",
+			CreationDate:        now,
+			LastModifiedBy:      "SYSTEM",
+			LastModifiedDate:    now,
+			BusinessJustification: "Credit policy escalation for large loans",
+			TestCases: []domain.RuleTestCase{
+				{
+					TestID:          "TEST_LOAN_AMOUNT_THRESHOLD_EUR",
+					TestName:        "EUR Loan Above Threshold",
+					TestDescription: "A EUR loan above the EUR threshold requires additional approval",
+					InputData:       map[string]interface{}{"requestedAmount": 95000.0, "currency": "EUR"},
+					ExpectedResult:  domain.RuleExecutionResult{Passed: false},
+					CreatedBy:       "SYSTEM",
+					CreationDate:    now,
+				},
+			},
+		},
+	}
+
+	// Save sample rules
+	for _, rule := range sampleRules {
+		if err := c.ruleRepository.SaveRule(stub, rule); err != nil {
+			return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to save sample rule %s: %w", rule.RuleID, err))
+		}
+	}
+
+	return shim.Success([]byte("Ledger initialized with sample compliance rules"))
+}
+
+// RegisterActor binds an actor to a blockchain identity for the compliance chaincode
+func (c *ComplianceContract) RegisterActor(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	actorBytes, err := c.identityHandler.RegisterActor(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to register actor: %w", err))
+	}
+
+	return shim.Success(actorBytes)
+}
+
+// GetActor retrieves an actor's identity binding
+func (c *ComplianceContract) GetActor(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	actorBytes, err := c.identityHandler.GetActor(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get actor: %w", err))
+	}
+
+	return shim.Success(actorBytes)
+}
+
+// AttestCredentialRotation records that an actor's off-chain credentials were rotated
+func (c *ComplianceContract) AttestCredentialRotation(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	rotationBytes, err := c.identityHandler.AttestCredentialRotation(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to attest credential rotation: %w", err))
+	}
+
+	return shim.Success(rotationBytes)
+}
+
+// GetCredentialRotation returns the latest credential rotation attested for an actor
+func (c *ComplianceContract) GetCredentialRotation(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	rotationBytes, err := c.identityHandler.GetCredentialRotation(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get credential rotation: %w", err))
+	}
+
+	return shim.Success(rotationBytes)
+}
+
+// GetInvokerIdentity returns the caller's blockchain identity, MSP and role
+func (c *ComplianceContract) GetInvokerIdentity(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	identityBytes, err := c.identityHandler.GetInvokerIdentity(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get invoker identity: %w", err))
+	}
+
+	return shim.Success(identityBytes)
+}
+// SetPayloadArchivePolicy sets whether a function's full request payloads are archived
+func (c *ComplianceContract) SetPayloadArchivePolicy(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	policyBytes, err := c.archiveHandler.SetPayloadArchivePolicy(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to set payload archive policy: %w", err))
+	}
+
+	return shim.Success(policyBytes)
+}
+
+// GetPayloadArchivePolicies returns the payload archive policies stored for the chaincode
+func (c *ComplianceContract) GetPayloadArchivePolicies(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	policiesBytes, err := c.archiveHandler.GetPayloadArchivePolicies(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get payload archive policies: %w", err))
+	}
+
+	return shim.Success(policiesBytes)
+}
+
+// GetArchivedPayload returns the archived request payload of a transaction
+func (c *ComplianceContract) GetArchivedPayload(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	archiveBytes, err := c.archiveHandler.GetArchivedPayload(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get archived payload: %w", err))
+	}
+
+	return shim.Success(archiveBytes)
+}
+
+// GetCounter returns a metric's daily or monthly total
+func (c *ComplianceContract) GetCounter(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	counterBytes, err := c.counterHandler.GetCounter(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to get counter: %w", err))
+	}
+
+	return shim.Success(counterBytes)
+}
+
+// CompactCounters folds a metric's recorded counts into its daily and monthly totals
+func (c *ComplianceContract) CompactCounters(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	countersBytes, err := c.counterHandler.CompactCounters(stub, args)
+	if err != nil {
+		return sharedChaincode.ErrorResponse(fmt.Errorf("Failed to compact counters: %w", err))
+	}
+
+	return shim.Success(countersBytes)
+}
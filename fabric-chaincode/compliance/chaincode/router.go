@@ -1,9 +1,9 @@
 package chaincode
 
 import (
-	"fmt"
-
 	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/chaincode"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
 	"github.com/brycemacchaveli/origin.block/fabric-chaincode/compliance/handlers"
 )
 
@@ -14,9 +14,31 @@ type Router struct {
 
 // NewRouter creates a new router with all handler mappings
 func NewRouter() *Router {
-	amlHandler := handlers.NewAMLCheckHandler()
+	amlHandler := handlers.NewAMLCheckHandler(nil)
 	kycHandler := handlers.NewKYCVerificationHandler()
 	reportHandler := handlers.NewReportGenerationHandler()
+	flagHandler := chaincode.NewFunctionFlagHandler()
+	sandboxHandler := chaincode.NewSandboxHandler()
+	identityHandler := chaincode.NewIdentityHandler()
+	archiveHandler := chaincode.NewPayloadArchiveHandler()
+	counterHandler := chaincode.NewCounterHandler()
+	compatibilityHandler := chaincode.NewCompatibilityHandler(newComplianceSchemaRegistry())
+	journalHandler := chaincode.NewDecisionJournalHandler()
+	auditPackageHandler := chaincode.NewAuditPackageHandler(complianceAuditCollectors()...)
+	entityAuditHandler := chaincode.NewEntityAuditHandler(complianceEntityAuditKeys...)
+	pepHandler := handlers.NewPEPListManager(nil)
+	sanctionListHandler := handlers.NewSanctionListManager(nil)
+	adverseMediaHandler := handlers.NewAdverseMediaManager(nil)
+	textScreeningHandler := handlers.NewTextScreeningManager(nil)
+	loanDefaultHandler := handlers.NewLoanDefaultHandler(nil)
+	channelMonitoringHandler := handlers.NewChannelMonitoringHandler(nil)
+	transactionMonitoringHandler := handlers.NewTransactionMonitoringHandler(nil)
+	countryRiskHandler := handlers.NewCountryRiskHandler()
+	riskCatalogHandler := handlers.NewRiskCatalogHandler()
+	riskModelHandler := handlers.NewRiskModelHandler()
+	whitelistHandler := handlers.NewScreeningWhitelistHandler()
+	governanceHandler := handlers.NewGovernanceHandler()
+	escalationHandler := handlers.NewViolationEscalationHandler(nil)
 	
 	return &Router{
 		handlers: map[string]func(shim.ChaincodeStubInterface, []string) ([]byte, error){
@@ -24,6 +46,105 @@ func NewRouter() *Router {
 			"PerformAMLCheck":         amlHandler.PerformAMLCheck,
 			"UpdateAMLStatus":         amlHandler.UpdateAMLStatus,
 			"GetAMLReport":            amlHandler.GetAMLReport,
+			"ScreenPEP":               amlHandler.ScreenPEP,
+			"GetExpiringChecks":       amlHandler.GetExpiringChecks,
+			"TriggerPeriodicReview":   amlHandler.TriggerPeriodicReview,
+			"TargetedRescreen":        amlHandler.TargetedRescreen,
+			"StartScreeningJob":       amlHandler.StartScreeningJob,
+			"BatchScreenEntities":     amlHandler.BatchScreenEntities,
+			"GetScreeningJob":         amlHandler.GetScreeningJob,
+			"GetScreeningBatch":       amlHandler.GetScreeningBatch,
+			
+			// PEP list functions
+			"AddPEPEntry":              pepHandler.AddPEPEntry,
+			"BulkImportPEPList":        pepHandler.BulkImportPEPList,
+			"DeactivatePEPEntry":       pepHandler.DeactivatePEPEntry,
+			"GetPEPEntry":              pepHandler.GetPEPEntry,
+			"QueryPEPEntriesByCountry": pepHandler.QueryPEPEntriesByCountry,
+			
+			// Sanction list functions
+			"CreateSanctionList":     sanctionListHandler.CreateSanctionList,
+			"UpdateSanctionList":     sanctionListHandler.UpdateSanctionList,
+			"GetSanctionList":        sanctionListHandler.GetSanctionList,
+			"GetActiveSanctionLists": sanctionListHandler.GetActiveSanctionLists,
+			"SearchSanctionEntries":  sanctionListHandler.SearchSanctionEntries,
+			
+			// Adverse media functions
+			"AddAdverseMediaRecord":        adverseMediaHandler.AddAdverseMediaRecord,
+			"DeactivateAdverseMediaRecord": adverseMediaHandler.DeactivateAdverseMediaRecord,
+			"GetAdverseMediaRecord":        adverseMediaHandler.GetAdverseMediaRecord,
+			"QueryAdverseMediaByEntity":    adverseMediaHandler.QueryAdverseMediaByEntity,
+			
+			// Free-text screening functions
+			"AddScreeningTerm":        textScreeningHandler.AddScreeningTerm,
+			"DeactivateScreeningTerm": textScreeningHandler.DeactivateScreeningTerm,
+			"GetScreeningTerms":       textScreeningHandler.GetScreeningTerms,
+			"ScreenText":              textScreeningHandler.ScreenText,
+			
+			// Loan default functions
+			"RecordLoanDefault":       loanDefaultHandler.RecordLoanDefault,
+			
+			// Origination channel monitoring functions
+			"SetChannelFraudRule":       channelMonitoringHandler.SetChannelFraudRule,
+			"GetChannelFraudRules":      channelMonitoringHandler.GetChannelFraudRules,
+			"MonitorApplicationChannel": channelMonitoringHandler.MonitorApplicationChannel,
+			"GetDeviceApplications":     channelMonitoringHandler.GetDeviceApplications,
+			
+			// Transaction monitoring functions
+			"SetTransactionTypologyRule":     transactionMonitoringHandler.SetTransactionTypologyRule,
+			"GetTransactionTypologyRules":    transactionMonitoringHandler.GetTransactionTypologyRules,
+			"IngestTransactions":             transactionMonitoringHandler.IngestTransactions,
+			"MapPaymentMessage":              transactionMonitoringHandler.MapPaymentMessage,
+			"IngestPaymentMessage":           transactionMonitoringHandler.IngestPaymentMessage,
+			"GetTransactionAlert":            transactionMonitoringHandler.GetTransactionAlert,
+			"GetTransactionAlertsByCustomer": transactionMonitoringHandler.GetTransactionAlertsByCustomer,
+			
+			// Country risk functions
+			"SetCountryRisk":        countryRiskHandler.SetCountryRisk,
+			"GetCountryRiskTable":   countryRiskHandler.GetCountryRiskTable,
+			"GetCountryRiskHistory": countryRiskHandler.GetCountryRiskHistory,
+			
+			// Occupation and industry risk catalog functions
+			"CreateRiskCatalogEntry": riskCatalogHandler.CreateRiskCatalogEntry,
+			"UpdateRiskCatalogEntry": riskCatalogHandler.UpdateRiskCatalogEntry,
+			"RetireRiskCatalogEntry": riskCatalogHandler.RetireRiskCatalogEntry,
+			"GetRiskCatalogEntry":    riskCatalogHandler.GetRiskCatalogEntry,
+			"GetRiskCatalog":         riskCatalogHandler.GetRiskCatalog,
+			
+			// AML risk model functions
+			"CreateRiskModelVersion":   riskModelHandler.CreateRiskModelVersion,
+			"ActivateRiskModelVersion": riskModelHandler.ActivateRiskModelVersion,
+			"GetActiveRiskModel":       riskModelHandler.GetActiveRiskModel,
+			"GetRiskModelVersions":     riskModelHandler.GetRiskModelVersions,
+			
+			// Screening whitelist functions
+			"AddScreeningWhitelistEntry":    whitelistHandler.AddScreeningWhitelistEntry,
+			"RevokeScreeningWhitelistEntry": whitelistHandler.RevokeScreeningWhitelistEntry,
+			"GetScreeningWhitelist":         whitelistHandler.GetScreeningWhitelist,
+			
+			// Consortium governance functions
+			"SetGovernanceMember":     governanceHandler.SetGovernanceMember,
+			"GetGovernanceMembers":    governanceHandler.GetGovernanceMembers,
+			"ProposeParameterChange":  governanceHandler.ProposeParameterChange,
+			"CastGovernanceVote":      governanceHandler.CastGovernanceVote,
+			"CloseGovernanceProposal": governanceHandler.CloseGovernanceProposal,
+			"GetGovernanceProposal":   governanceHandler.GetGovernanceProposal,
+			"GetGovernanceProposals":  governanceHandler.GetGovernanceProposals,
+			"GetPlatformParameter":    governanceHandler.GetPlatformParameter,
+			"GetPlatformParameters":   governanceHandler.GetPlatformParameters,
+			
+			// Violation escalation functions
+			"CreateEscalation":          escalationHandler.CreateEscalation,
+			"AssignEscalation":          escalationHandler.AssignEscalation,
+			"EscalateToNextLevel":       escalationHandler.EscalateToNextLevel,
+			"ResolveEscalation":         escalationHandler.ResolveEscalation,
+			"AddEscalationComment":      escalationHandler.AddComment,
+			"GetEscalation":             escalationHandler.GetEscalation,
+			"GetEscalationsByStatus":    escalationHandler.GetEscalationsByStatus,
+			"GetEscalationsByAssignee":  escalationHandler.GetEscalationsByAssignee,
+			"SetEscalationRoutingRule":  escalationHandler.SetEscalationRoutingRule,
+			"GetEscalationRoutingRules": escalationHandler.GetEscalationRoutingRules,
+			"EscalateOverdue":           escalationHandler.EscalateOverdue,
 			
 			// KYC functions
 			"VerifyKYCDocuments":      kycHandler.VerifyKYCDocuments,
@@ -34,6 +155,39 @@ func NewRouter() *Router {
 			"GenerateComplianceReport": reportHandler.GenerateComplianceReport,
 			"GetComplianceReport":      reportHandler.GetComplianceReport,
 			"QueryReportsByType":       reportHandler.QueryReportsByType,
+			"FinalizeReportingPeriod":  reportHandler.FinalizeReportingPeriod,
+			"GetReportingPeriod":       reportHandler.GetReportingPeriod,
+			
+			// Decision journal functions
+			"RecordDecision":     journalHandler.RecordDecision,
+			"CosignDecision":     journalHandler.CosignDecision,
+			"GetDecisionJournal": journalHandler.GetDecisionJournal,
+			
+			// Audit package functions
+			"ExportAuditPackage": auditPackageHandler.ExportAuditPackage,
+			"GetAuditManifest":   auditPackageHandler.GetAuditManifest,
+			"VerifyAuditPackage": auditPackageHandler.VerifyAuditPackage,
+			"ExportEntityAudit":  entityAuditHandler.ExportEntityAudit,
+			"VerifyEntityAudit":  entityAuditHandler.VerifyEntityAudit,
+			
+			// Operational functions
+			"SetFunctionFlag":  flagHandler.SetFunctionFlag,
+			"GetFunctionFlags": flagHandler.GetFunctionFlags,
+			"SetSandboxActor":  sandboxHandler.SetSandboxActor,
+			"GetSandboxActors": sandboxHandler.GetSandboxActors,
+			"RegisterActor":      identityHandler.RegisterActor,
+			"GetActor":           identityHandler.GetActor,
+			"AttestCredentialRotation": identityHandler.AttestCredentialRotation,
+			"GetCredentialRotation":    identityHandler.GetCredentialRotation,
+			"GetInvokerIdentity": identityHandler.GetInvokerIdentity,
+			"SetPayloadArchivePolicy":   archiveHandler.SetPayloadArchivePolicy,
+			"GetPayloadArchivePolicies": archiveHandler.GetPayloadArchivePolicies,
+			"GetArchivedPayload":        archiveHandler.GetArchivedPayload,
+			"GetCounter":                counterHandler.GetCounter,
+			"CompactCounters":           counterHandler.CompactCounters,
+			"ValidateStateCompatibility": compatibilityHandler.ValidateStateCompatibility,
+			"RecordTimestampCutover":     compatibilityHandler.RecordTimestampCutover,
+			"GetTimestampCutover":        compatibilityHandler.GetTimestampCutover,
 		},
 	}
 }
@@ -42,8 +196,23 @@ func NewRouter() *Router {
 func (r *Router) Route(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
 	handler, exists := r.handlers[function]
 	if !exists {
-		return nil, fmt.Errorf("function %s not found", function)
+		return nil, services.NewChaincodeError(services.ErrCodeUnknownFunction, "", "function %s not found", function)
 	}
 	
 	return handler(stub, args)
+}
+
+// ActorArguments locates the acting actor of functions not naming it in a JSON request's actorID
+func (r *Router) ActorArguments() chaincode.ActorArguments {
+	return complianceActorArguments
+}
+
+// FreezeArguments exempts the compliance functions from entity freezes
+func (r *Router) FreezeArguments() chaincode.FreezeArguments {
+	return complianceFreezeArguments
+}
+
+// CarriesPII reports whether a function's arguments carry customer PII
+func (r *Router) CarriesPII(function string) bool {
+	return compliancePIIFunctions[function]
 }
\ No newline at end of file
@@ -0,0 +1,55 @@
+package chaincode
+
+import (
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/compliance/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/compliance/handlers"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// newComplianceSchemaRegistry registers every namespace the compliance chaincode writes. Keep this
+// in step with new entities so ValidateStateCompatibility covers them before an upgrade.
+func newComplianceSchemaRegistry() *services.SchemaRegistry {
+	registry := services.NewSchemaRegistry()
+
+	// Entities keyed by prefix
+	registry.RegisterPrefix("AML_RESULT_", "AMLCheckResult", func() interface{} { return &handlers.AMLCheckResult{} })
+	registry.RegisterPrefix("AML_ESCALATION_", "AMLEscalation", func() interface{} { return &map[string]interface{}{} })
+	registry.RegisterPrefix("COMPLIANCE_EVENT_", "ComplianceEvent", func() interface{} { return &domain.ComplianceEvent{} })
+	registry.RegisterPrefix("PEP_", "PEPEntry", func() interface{} { return &handlers.PEPEntry{} })
+	registry.RegisterPrefix("ADVERSE_MEDIA_", "AdverseMediaRecord", func() interface{} { return &handlers.AdverseMediaRecord{} })
+	registry.RegisterPrefix("SCREEN_TERM_", "ScreeningTerm", func() interface{} { return &handlers.ScreeningTerm{} })
+	registry.RegisterPrefix("TXN_ALERT_", "TransactionAlert", func() interface{} { return &handlers.TransactionAlert{} })
+
+	// Raw ID indexes
+	registry.RegisterIndexPrefix("CUSTOMER_AML_")
+	registry.RegisterIndexPrefix("CUSTOMER_ESCALATION_")
+	registry.RegisterIndexPrefix("AML_LATEST_")
+	registry.RegisterIndexPrefix("AML_EXPIRY_")
+	registry.RegisterIndexPrefix("ESCALATION_DUE_")
+	registry.RegisterIndexPrefix("MONITORED_TXN_")
+
+	// Entities keyed by composite key
+	registry.RegisterCompositeKey(config.DecisionJournalPrefix, "DecisionJournalEntry", func() interface{} { return &services.DecisionJournalEntry{} })
+	registry.RegisterCompositeKey(config.EscalationRoutePrefix, "EscalationRoutingRule", func() interface{} { return &handlers.EscalationRoutingRule{} })
+	registry.RegisterCompositeKey(config.ChannelFraudRulePrefix, "ChannelFraudRule", func() interface{} { return &handlers.ChannelFraudRule{} })
+	registry.RegisterCompositeKey(config.DeviceApplicationPrefix, "DeviceApplication", func() interface{} { return &handlers.DeviceApplication{} })
+	registry.RegisterCompositeKey(config.TransactionTypologyRulePrefix, "TransactionTypologyRule", func() interface{} { return &handlers.TransactionTypologyRule{} })
+	registry.RegisterCompositeKey(config.MonitoredTransactionPrefix, "MonitoredTransaction", func() interface{} { return &handlers.MonitoredTransaction{} })
+	registry.RegisterCompositeKey(config.CountryRiskPrefix, "CountryRisk", func() interface{} { return &handlers.CountryRisk{} })
+	registry.RegisterCompositeKey(config.CountryRiskHistoryPrefix, "CountryRisk", func() interface{} { return &handlers.CountryRisk{} })
+	registry.RegisterCompositeKey(config.RiskCatalogEntryPrefix, "RiskCatalogEntry", func() interface{} { return &handlers.RiskCatalogEntry{} })
+	registry.RegisterCompositeKey(config.RiskModelPrefix, "RiskModel", func() interface{} { return &handlers.RiskModel{} })
+	registry.RegisterCompositeKey(config.RiskModelActivePrefix, "RiskModel", func() interface{} { return &handlers.RiskModel{} })
+	registry.RegisterCompositeKey(config.ScreeningWhitelistPrefix, "ScreeningWhitelistEntry", func() interface{} { return &handlers.ScreeningWhitelistEntry{} })
+	registry.RegisterCompositeKey(config.ScreeningJobPrefix, "ScreeningJob", func() interface{} { return &handlers.ScreeningJob{} })
+	registry.RegisterCompositeKey(config.ScreeningBatchPrefix, "ScreeningBatch", func() interface{} { return &handlers.ScreeningBatch{} })
+	registry.RegisterCompositeKey(config.GovernanceMemberPrefix, "GovernanceMember", func() interface{} { return &handlers.GovernanceMember{} })
+	registry.RegisterCompositeKey(config.GovernanceProposalPrefix, "GovernanceProposal", func() interface{} { return &handlers.GovernanceProposal{} })
+	registry.RegisterCompositeKey(config.PlatformParameterPrefix, "PlatformParameter", func() interface{} { return &handlers.PlatformParameter{} })
+	registry.RegisterCompositeKey(config.PlatformParameterHistoryPrefix, "PlatformParameter", func() interface{} { return &handlers.PlatformParameter{} })
+	registry.RegisterCompositeKey(config.ComplianceReportPrefix, "RegulatoryReport", func() interface{} { return &handlers.RegulatoryReport{} })
+	registry.RegisterCompositeKey(config.ReportingPeriodPrefix, "ReportingPeriod", func() interface{} { return &handlers.ReportingPeriod{} })
+
+	return registry
+}
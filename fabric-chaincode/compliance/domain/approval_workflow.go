@@ -3,9 +3,9 @@ package domain
 import (
 	"encoding/json"
 	"fmt"
-	"time"
 
 	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
 )
 
 // ApprovalWorkflowManager manages the rule approval workflow
@@ -24,21 +24,26 @@ func NewApprovalWorkflowManager(repository RuleRepository, emitter EventEmitter)
 
 // SubmitRuleForApproval submits a rule for approval
 func (w *ApprovalWorkflowManager) SubmitRuleForApproval(stub shim.ChaincodeStubInterface, ruleID string, requestedBy string, justification string) (*RuleApprovalRequest, error) {
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
 	// Get the rule
 	rule, err := w.ruleRepository.GetLatestRule(stub, ruleID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get rule for approval: %v", err)
+		return nil, fmt.Errorf("failed to get rule for approval: %w", err)
 	}
 	
 	// Check if rule is in a state that can be approved
 	if rule.Status != RuleStatusDraft && rule.Status != RuleStatusPending {
-		return nil, fmt.Errorf("rule %s is not in a state that can be submitted for approval (current status: %s)", ruleID, rule.Status)
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "rule %s is not in a state that can be submitted for approval (current status: %s)", ruleID, rule.Status)
 	}
 	
 	// Validate the rule before submission
 	validationResults, err := w.validateRuleForApproval(stub, rule)
 	if err != nil {
-		return nil, fmt.Errorf("failed to validate rule for approval: %v", err)
+		return nil, fmt.Errorf("failed to validate rule for approval: %w", err)
 	}
 	
 	// Check if validation passed
@@ -50,33 +55,33 @@ func (w *ApprovalWorkflowManager) SubmitRuleForApproval(stub shim.ChaincodeStubI
 	
 	// Create approval request
 	request := &RuleApprovalRequest{
-		RequestID:     fmt.Sprintf("approval_%s_%d", ruleID, time.Now().UnixNano()),
+		RequestID:     fmt.Sprintf("approval_%s_%d", ruleID, now.UnixNano()),
 		RuleID:        ruleID,
 		RequestedBy:   requestedBy,
-		RequestDate:   time.Now(),
+		RequestDate:   now,
 		Justification: justification,
 		Status:        "PENDING",
 	}
 	
 	// Save approval request
 	if err := w.saveApprovalRequest(stub, request); err != nil {
-		return nil, fmt.Errorf("failed to save approval request: %v", err)
+		return nil, fmt.Errorf("failed to save approval request: %w", err)
 	}
 	
 	// Update rule status to pending approval
 	rule.Status = RuleStatusPending
 	rule.LastModifiedBy = requestedBy
-	rule.LastModifiedDate = time.Now()
+	rule.LastModifiedDate = now
 	
 	if err := w.ruleRepository.SaveRule(stub, rule); err != nil {
-		return nil, fmt.Errorf("failed to update rule status: %v", err)
+		return nil, fmt.Errorf("failed to update rule status: %w", err)
 	}
 	
 	// Emit approval request event
 	if w.eventEmitter != nil {
 		event := &ComplianceEvent{
 			EventID:            fmt.Sprintf("approval_request_%s", request.RequestID),
-			Timestamp:          time.Now(),
+			Timestamp:          now,
 			RuleID:             ruleID,
 			EventType:          "RULE_APPROVAL_REQUESTED",
 			Severity:           PriorityMedium,
@@ -95,28 +100,31 @@ func (w *ApprovalWorkflowManager) ApproveRule(stub shim.ChaincodeStubInterface,
 	// Get approval request
 	request, err := w.getApprovalRequest(stub, requestID)
 	if err != nil {
-		return fmt.Errorf("failed to get approval request: %v", err)
+		return fmt.Errorf("failed to get approval request: %w", err)
 	}
 	
 	if request.Status != "PENDING" {
-		return fmt.Errorf("approval request %s is not pending (current status: %s)", requestID, request.Status)
+		return services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "approval request %s is not pending (current status: %s)", requestID, request.Status)
 	}
 	
 	// Get the rule
 	rule, err := w.ruleRepository.GetLatestRule(stub, request.RuleID)
 	if err != nil {
-		return fmt.Errorf("failed to get rule for approval: %v", err)
+		return fmt.Errorf("failed to get rule for approval: %w", err)
 	}
 	
 	// Update approval request
 	request.Status = "APPROVED"
 	request.ReviewedBy = reviewedBy
-	now := time.Now()
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return err
+	}
 	request.ReviewDate = &now
 	request.ReviewComments = comments
 	
 	if err := w.saveApprovalRequest(stub, request); err != nil {
-		return fmt.Errorf("failed to update approval request: %v", err)
+		return fmt.Errorf("failed to update approval request: %w", err)
 	}
 	
 	// Update rule status and approval information
@@ -131,13 +139,18 @@ func (w *ApprovalWorkflowManager) ApproveRule(stub shim.ChaincodeStubInterface,
 		rule.EffectiveDate = now
 	}
 	
+	// Rules with a rollout start in shadow and are observed before they are enforced
+	if rule.Rollout != nil {
+		rule.Rollout.Start(now)
+	}
+	
 	if err := w.ruleRepository.SaveRule(stub, rule); err != nil {
-		return fmt.Errorf("failed to update approved rule: %v", err)
+		return fmt.Errorf("failed to update approved rule: %w", err)
 	}
 	
 	// Handle rule supersession
 	if err := w.handleRuleSupersession(stub, rule); err != nil {
-		return fmt.Errorf("failed to handle rule supersession: %v", err)
+		return fmt.Errorf("failed to handle rule supersession: %w", err)
 	}
 	
 	// Emit approval event
@@ -163,28 +176,31 @@ func (w *ApprovalWorkflowManager) RejectRule(stub shim.ChaincodeStubInterface, r
 	// Get approval request
 	request, err := w.getApprovalRequest(stub, requestID)
 	if err != nil {
-		return fmt.Errorf("failed to get approval request: %v", err)
+		return fmt.Errorf("failed to get approval request: %w", err)
 	}
 	
 	if request.Status != "PENDING" {
-		return fmt.Errorf("approval request %s is not pending (current status: %s)", requestID, request.Status)
+		return services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "approval request %s is not pending (current status: %s)", requestID, request.Status)
 	}
 	
 	// Get the rule
 	rule, err := w.ruleRepository.GetLatestRule(stub, request.RuleID)
 	if err != nil {
-		return fmt.Errorf("failed to get rule for rejection: %v", err)
+		return fmt.Errorf("failed to get rule for rejection: %w", err)
 	}
 	
 	// Update approval request
 	request.Status = "REJECTED"
 	request.ReviewedBy = reviewedBy
-	now := time.Now()
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return err
+	}
 	request.ReviewDate = &now
 	request.ReviewComments = comments
 	
 	if err := w.saveApprovalRequest(stub, request); err != nil {
-		return fmt.Errorf("failed to update approval request: %v", err)
+		return fmt.Errorf("failed to update approval request: %w", err)
 	}
 	
 	// Update rule status back to draft
@@ -193,7 +209,7 @@ func (w *ApprovalWorkflowManager) RejectRule(stub shim.ChaincodeStubInterface, r
 	rule.LastModifiedDate = now
 	
 	if err := w.ruleRepository.SaveRule(stub, rule); err != nil {
-		return fmt.Errorf("failed to update rejected rule: %v", err)
+		return fmt.Errorf("failed to update rejected rule: %w", err)
 	}
 	
 	// Emit rejection event
@@ -218,7 +234,7 @@ func (w *ApprovalWorkflowManager) RejectRule(stub shim.ChaincodeStubInterface, r
 func (w *ApprovalWorkflowManager) GetPendingApprovals(stub shim.ChaincodeStubInterface) ([]*RuleApprovalRequest, error) {
 	iterator, err := stub.GetStateByPartialCompositeKey("approval_status", []string{"PENDING"})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get pending approvals: %v", err)
+		return nil, fmt.Errorf("failed to get pending approvals: %w", err)
 	}
 	defer iterator.Close()
 	
@@ -227,7 +243,7 @@ func (w *ApprovalWorkflowManager) GetPendingApprovals(stub shim.ChaincodeStubInt
 	for iterator.HasNext() {
 		response, err := iterator.Next()
 		if err != nil {
-			return nil, fmt.Errorf("failed to iterate pending approvals: %v", err)
+			return nil, fmt.Errorf("failed to iterate pending approvals: %w", err)
 		}
 		
 		// Extract request ID from composite key
@@ -252,7 +268,7 @@ func (w *ApprovalWorkflowManager) GetPendingApprovals(stub shim.ChaincodeStubInt
 func (w *ApprovalWorkflowManager) GetApprovalHistory(stub shim.ChaincodeStubInterface, ruleID string) ([]*RuleApprovalRequest, error) {
 	iterator, err := stub.GetStateByPartialCompositeKey("approval_rule", []string{ruleID})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get approval history for rule %s: %v", ruleID, err)
+		return nil, fmt.Errorf("failed to get approval history for rule %s: %w", ruleID, err)
 	}
 	defer iterator.Close()
 	
@@ -261,7 +277,7 @@ func (w *ApprovalWorkflowManager) GetApprovalHistory(stub shim.ChaincodeStubInte
 	for iterator.HasNext() {
 		response, err := iterator.Next()
 		if err != nil {
-			return nil, fmt.Errorf("failed to iterate approval history: %v", err)
+			return nil, fmt.Errorf("failed to iterate approval history: %w", err)
 		}
 		
 		// Extract request ID from composite key
@@ -284,10 +300,15 @@ func (w *ApprovalWorkflowManager) GetApprovalHistory(stub shim.ChaincodeStubInte
 
 // validateRuleForApproval performs comprehensive validation before approval
 func (w *ApprovalWorkflowManager) validateRuleForApproval(stub shim.ChaincodeStubInterface, rule *ComplianceRule) ([]ValidationResult, error) {
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
 	var results []ValidationResult
 	
 	// Basic rule validation
-	basicResults := rule.Validate()
+	basicResults := rule.Validate(now)
 	results = append(results, basicResults...)
 	
 	// Dependency validation
@@ -295,23 +316,23 @@ func (w *ApprovalWorkflowManager) validateRuleForApproval(stub shim.ChaincodeStu
 		depRule, err := w.ruleRepository.GetLatestRule(stub, depID)
 		if err != nil {
 			result := ValidationResult{
-				ValidationID:   fmt.Sprintf("dep_approval_%s_%d", rule.RuleID, time.Now().Unix()),
+				ValidationID:   fmt.Sprintf("dep_approval_%s_%d", rule.RuleID, now.Unix()),
 				ValidationType: "DEPENDENCY",
 				IsValid:        false,
 				ErrorMessages:  []string{fmt.Sprintf("Dependency rule %s not found", depID)},
-				ValidationDate: time.Now(),
+				ValidationDate: now,
 			}
 			results = append(results, result)
 			continue
 		}
 		
-		if !depRule.IsActive() {
+		if !depRule.IsActive(now) {
 			result := ValidationResult{
-				ValidationID:   fmt.Sprintf("dep_active_%s_%d", rule.RuleID, time.Now().Unix()),
+				ValidationID:   fmt.Sprintf("dep_active_%s_%d", rule.RuleID, now.Unix()),
 				ValidationType: "DEPENDENCY",
 				IsValid:        false,
 				ErrorMessages:  []string{fmt.Sprintf("Dependency rule %s is not active", depID)},
-				ValidationDate: time.Now(),
+				ValidationDate: now,
 			}
 			results = append(results, result)
 		}
@@ -320,13 +341,13 @@ func (w *ApprovalWorkflowManager) validateRuleForApproval(stub shim.ChaincodeStu
 	// Conflict validation
 	for _, conflictID := range rule.ConflictsWith {
 		conflictRule, err := w.ruleRepository.GetLatestRule(stub, conflictID)
-		if err == nil && conflictRule.IsActive() {
+		if err == nil && conflictRule.IsActive(now) {
 			result := ValidationResult{
-				ValidationID:   fmt.Sprintf("conflict_approval_%s_%d", rule.RuleID, time.Now().Unix()),
+				ValidationID:   fmt.Sprintf("conflict_approval_%s_%d", rule.RuleID, now.Unix()),
 				ValidationType: "CONFLICT",
 				IsValid:        false,
 				ErrorMessages:  []string{fmt.Sprintf("Conflicting rule %s is currently active", conflictID)},
-				ValidationDate: time.Now(),
+				ValidationDate: now,
 			}
 			results = append(results, result)
 		}
@@ -335,11 +356,11 @@ func (w *ApprovalWorkflowManager) validateRuleForApproval(stub shim.ChaincodeStu
 	// Test case validation
 	if len(rule.TestCases) == 0 {
 		result := ValidationResult{
-			ValidationID:   fmt.Sprintf("test_approval_%s_%d", rule.RuleID, time.Now().Unix()),
+			ValidationID:   fmt.Sprintf("test_approval_%s_%d", rule.RuleID, now.Unix()),
 			ValidationType: "TESTING",
 			IsValid:        false,
 			ErrorMessages:  []string{"Rule must have at least one test case before approval"},
-			ValidationDate: time.Now(),
+			ValidationDate: now,
 		}
 		results = append(results, result)
 	}
@@ -349,6 +370,11 @@ func (w *ApprovalWorkflowManager) validateRuleForApproval(stub shim.ChaincodeStu
 
 // handleRuleSupersession handles rules that are superseded by the approved rule
 func (w *ApprovalWorkflowManager) handleRuleSupersession(stub shim.ChaincodeStubInterface, rule *ComplianceRule) error {
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return err
+	}
+
 	for _, supersededID := range rule.Supersedes {
 		supersededRule, err := w.ruleRepository.GetLatestRule(stub, supersededID)
 		if err != nil {
@@ -358,17 +384,17 @@ func (w *ApprovalWorkflowManager) handleRuleSupersession(stub shim.ChaincodeStub
 		// Mark superseded rule as deprecated
 		supersededRule.Status = RuleStatusDeprecated
 		supersededRule.LastModifiedBy = rule.ApprovedBy
-		supersededRule.LastModifiedDate = time.Now()
+		supersededRule.LastModifiedDate = now
 		
 		if err := w.ruleRepository.SaveRule(stub, supersededRule); err != nil {
-			return fmt.Errorf("failed to deprecate superseded rule %s: %v", supersededID, err)
+			return fmt.Errorf("failed to deprecate superseded rule %s: %w", supersededID, err)
 		}
 		
 		// Emit supersession event
 		if w.eventEmitter != nil {
 			event := &ComplianceEvent{
 				EventID:            fmt.Sprintf("rule_superseded_%s", supersededID),
-				Timestamp:          time.Now(),
+				Timestamp:          now,
 				RuleID:             supersededID,
 				EventType:          "RULE_SUPERSEDED",
 				Severity:           PriorityMedium,
@@ -387,18 +413,18 @@ func (w *ApprovalWorkflowManager) handleRuleSupersession(stub shim.ChaincodeStub
 func (w *ApprovalWorkflowManager) saveApprovalRequest(stub shim.ChaincodeStubInterface, request *RuleApprovalRequest) error {
 	requestBytes, err := json.Marshal(request)
 	if err != nil {
-		return fmt.Errorf("failed to marshal approval request: %v", err)
+		return fmt.Errorf("failed to marshal approval request: %w", err)
 	}
 	
 	// Save the request
 	requestKey := fmt.Sprintf("approval_request~%s", request.RequestID)
 	if err := stub.PutState(requestKey, requestBytes); err != nil {
-		return fmt.Errorf("failed to save approval request: %v", err)
+		return fmt.Errorf("failed to save approval request: %w", err)
 	}
 	
 	// Create index entries
 	if err := w.createApprovalIndexEntries(stub, request); err != nil {
-		return fmt.Errorf("failed to create approval index entries: %v", err)
+		return fmt.Errorf("failed to create approval index entries: %w", err)
 	}
 	
 	return nil
@@ -409,16 +435,16 @@ func (w *ApprovalWorkflowManager) getApprovalRequest(stub shim.ChaincodeStubInte
 	requestKey := fmt.Sprintf("approval_request~%s", requestID)
 	requestBytes, err := stub.GetState(requestKey)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get approval request %s: %v", requestID, err)
+		return nil, fmt.Errorf("failed to get approval request %s: %w", requestID, err)
 	}
 	
 	if requestBytes == nil {
-		return nil, fmt.Errorf("approval request %s not found", requestID)
+		return nil, services.NewChaincodeError(services.ErrCodeNotFound, "", "approval request %s not found", requestID)
 	}
 	
 	var request RuleApprovalRequest
 	if err := json.Unmarshal(requestBytes, &request); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal approval request: %v", err)
+		return nil, fmt.Errorf("failed to unmarshal approval request: %w", err)
 	}
 	
 	return &request, nil
@@ -429,38 +455,38 @@ func (w *ApprovalWorkflowManager) createApprovalIndexEntries(stub shim.Chaincode
 	// Status index
 	statusKey, err := stub.CreateCompositeKey("approval_status", []string{request.Status, request.RequestID})
 	if err != nil {
-		return fmt.Errorf("failed to create status composite key: %v", err)
+		return fmt.Errorf("failed to create status composite key: %w", err)
 	}
-	if err := stub.PutState(statusKey, []byte{}); err != nil {
-		return fmt.Errorf("failed to save status index: %v", err)
+	if err := stub.PutState(statusKey, []byte(request.RequestID)); err != nil {
+		return fmt.Errorf("failed to save status index: %w", err)
 	}
 	
 	// Rule index
 	ruleKey, err := stub.CreateCompositeKey("approval_rule", []string{request.RuleID, request.RequestID})
 	if err != nil {
-		return fmt.Errorf("failed to create rule composite key: %v", err)
+		return fmt.Errorf("failed to create rule composite key: %w", err)
 	}
-	if err := stub.PutState(ruleKey, []byte{}); err != nil {
-		return fmt.Errorf("failed to save rule index: %v", err)
+	if err := stub.PutState(ruleKey, []byte(request.RequestID)); err != nil {
+		return fmt.Errorf("failed to save rule index: %w", err)
 	}
 	
 	// Requester index
 	requesterKey, err := stub.CreateCompositeKey("approval_requester", []string{request.RequestedBy, request.RequestID})
 	if err != nil {
-		return fmt.Errorf("failed to create requester composite key: %v", err)
+		return fmt.Errorf("failed to create requester composite key: %w", err)
 	}
-	if err := stub.PutState(requesterKey, []byte{}); err != nil {
-		return fmt.Errorf("failed to save requester index: %v", err)
+	if err := stub.PutState(requesterKey, []byte(request.RequestID)); err != nil {
+		return fmt.Errorf("failed to save requester index: %w", err)
 	}
 	
 	// Reviewer index (if reviewed)
 	if request.ReviewedBy != "" {
 		reviewerKey, err := stub.CreateCompositeKey("approval_reviewer", []string{request.ReviewedBy, request.RequestID})
 		if err != nil {
-			return fmt.Errorf("failed to create reviewer composite key: %v", err)
+			return fmt.Errorf("failed to create reviewer composite key: %w", err)
 		}
-		if err := stub.PutState(reviewerKey, []byte{}); err != nil {
-			return fmt.Errorf("failed to save reviewer index: %v", err)
+		if err := stub.PutState(reviewerKey, []byte(request.RequestID)); err != nil {
+			return fmt.Errorf("failed to save reviewer index: %w", err)
 		}
 	}
 	
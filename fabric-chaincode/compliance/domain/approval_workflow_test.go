@@ -359,7 +359,8 @@ func TestApprovalWorkflowManager_GetApprovalHistory(t *testing.T) {
 	err = manager.RejectRule(stub, request1.RequestID, "REVIEWER_1", "Needs improvement")
 	require.NoError(t, err)
 
-	// Submit again
+	// Submit again in a later transaction
+	stub.(*EnhancedMockStub).MockTransactionStart("txid2")
 	request2, err := manager.SubmitRuleForApproval(stub, "HISTORY_TEST_RULE", "REQUESTER_2", "Second submission")
 	require.NoError(t, err)
 
@@ -43,6 +43,7 @@ type ComplianceRule struct {
 	RuleName            string                 `json:"ruleName"`
 	RuleDescription     string                 `json:"ruleDescription"`
 	Version             string                 `json:"version"`
+	Revision            int                    `json:"revision"` // Advanced on every save; UpdateComplianceRule may name it as expectedVersion
 	
 	// Rule logic and execution
 	RuleLogic           string                 `json:"ruleLogic"`
@@ -63,6 +64,7 @@ type ComplianceRule struct {
 	Status              ComplianceRuleStatus   `json:"status"`
 	EffectiveDate       time.Time              `json:"effectiveDate"`
 	ExpirationDate      *time.Time             `json:"expirationDate,omitempty"`
+	Rollout             *RuleRollout           `json:"rollout,omitempty"`   // Gradual rollout on activation; enforced at once when absent
 	
 	// Approval workflow
 	CreatedBy           string                 `json:"createdBy"`
@@ -114,7 +116,6 @@ type RuleExecutionResult struct {
 	Score           float64                `json:"score,omitempty"`
 	Details         map[string]interface{} `json:"details"`
 	ErrorMessage    string                 `json:"errorMessage,omitempty"`
-	ExecutionTime   int64                  `json:"executionTimeMs"`
 }
 
 // ComplianceEvent represents a compliance event with enhanced tracking
@@ -157,32 +158,33 @@ type RuleApprovalRequest struct {
 	ReviewComments  string    `json:"reviewComments,omitempty"`
 }
 
-// Validate performs comprehensive validation of the ComplianceRule
-func (r *ComplianceRule) Validate() []ValidationResult {
+// Validate performs comprehensive validation of the ComplianceRule, stamping the results with the
+// given time, which on the ledger is the transaction timestamp
+func (r *ComplianceRule) Validate(now time.Time) []ValidationResult {
 	var results []ValidationResult
 	
 	// Syntax validation
-	syntaxResult := r.validateSyntax()
+	syntaxResult := r.validateSyntax(now)
 	results = append(results, syntaxResult)
 	
 	// Logic validation
-	logicResult := r.validateLogic()
+	logicResult := r.validateLogic(now)
 	results = append(results, logicResult)
 	
 	// Dependency validation
-	depResult := r.validateDependencies()
+	depResult := r.validateDependencies(now)
 	results = append(results, depResult)
 	
 	return results
 }
 
 // validateSyntax validates the basic syntax and structure of the rule
-func (r *ComplianceRule) validateSyntax() ValidationResult {
+func (r *ComplianceRule) validateSyntax(now time.Time) ValidationResult {
 	result := ValidationResult{
-		ValidationID:   fmt.Sprintf("syntax_%s_%d", r.RuleID, time.Now().Unix()),
+		ValidationID:   fmt.Sprintf("syntax_%s_%d", r.RuleID, now.Unix()),
 		ValidationType: "SYNTAX",
 		IsValid:        true,
-		ValidationDate: time.Now(),
+		ValidationDate: now,
 	}
 	
 	var errors []string
@@ -246,6 +248,11 @@ func (r *ComplianceRule) validateSyntax() ValidationResult {
 		errors = append(errors, "ExpirationDate cannot be before EffectiveDate")
 	}
 	
+	// Rollout validation
+	if r.Rollout != nil {
+		errors = append(errors, r.Rollout.validate()...)
+	}
+	
 	// Warning for missing test cases
 	if len(r.TestCases) == 0 {
 		warnings = append(warnings, "No test cases defined for this rule")
@@ -259,12 +266,12 @@ func (r *ComplianceRule) validateSyntax() ValidationResult {
 }
 
 // validateLogic validates the rule logic syntax and structure
-func (r *ComplianceRule) validateLogic() ValidationResult {
+func (r *ComplianceRule) validateLogic(now time.Time) ValidationResult {
 	result := ValidationResult{
-		ValidationID:   fmt.Sprintf("logic_%s_%d", r.RuleID, time.Now().Unix()),
+		ValidationID:   fmt.Sprintf("logic_%s_%d", r.RuleID, now.Unix()),
 		ValidationType: "LOGIC",
 		IsValid:        true,
-		ValidationDate: time.Now(),
+		ValidationDate: now,
 	}
 	
 	var errors []string
@@ -295,12 +302,12 @@ func (r *ComplianceRule) validateLogic() ValidationResult {
 }
 
 // validateDependencies validates rule dependencies and conflicts
-func (r *ComplianceRule) validateDependencies() ValidationResult {
+func (r *ComplianceRule) validateDependencies(now time.Time) ValidationResult {
 	result := ValidationResult{
-		ValidationID:   fmt.Sprintf("dependency_%s_%d", r.RuleID, time.Now().Unix()),
+		ValidationID:   fmt.Sprintf("dependency_%s_%d", r.RuleID, now.Unix()),
 		ValidationType: "DEPENDENCY",
 		IsValid:        true,
-		ValidationDate: time.Now(),
+		ValidationDate: now,
 	}
 	
 	var errors []string
@@ -346,17 +353,16 @@ func (r *ComplianceRule) GetLatestVersionKey() string {
 	return fmt.Sprintf("rule_latest~%s", r.RuleID)
 }
 
-// IsActive returns true if the rule is currently active
-func (r *ComplianceRule) IsActive() bool {
-	now := time.Now()
+// IsActive returns true if the rule is active at the given time
+func (r *ComplianceRule) IsActive(now time.Time) bool {
 	return r.Status == RuleStatusActive &&
 		(r.EffectiveDate.Before(now) || r.EffectiveDate.Equal(now)) &&
 		(r.ExpirationDate == nil || r.ExpirationDate.After(now))
 }
 
-// CanExecute returns true if the rule can be executed
-func (r *ComplianceRule) CanExecute() bool {
-	return r.IsActive() && len(r.ValidationResults) > 0 && r.allValidationsPassed()
+// CanExecute returns true if the rule can be executed at the given time
+func (r *ComplianceRule) CanExecute(now time.Time) bool {
+	return r.IsActive(now) && len(r.ValidationResults) > 0 && r.allValidationsPassed()
 }
 
 // allValidationsPassed checks if all validations have passed
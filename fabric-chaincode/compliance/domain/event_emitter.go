@@ -5,6 +5,8 @@ import (
 	"fmt"
 
 	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/interfaces"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
 )
 
 // FabricEventEmitter implements EventEmitter using Hyperledger Fabric events
@@ -21,22 +23,29 @@ func (e *FabricEventEmitter) EmitComplianceEvent(stub shim.ChaincodeStubInterfac
 	eventKey := fmt.Sprintf("compliance_event~%s", event.EventID)
 	eventBytes, err := json.Marshal(event)
 	if err != nil {
-		return fmt.Errorf("failed to marshal compliance event: %v", err)
+		return fmt.Errorf("failed to marshal compliance event: %w", err)
 	}
 	
 	if err := stub.PutState(eventKey, eventBytes); err != nil {
-		return fmt.Errorf("failed to save compliance event: %v", err)
+		return fmt.Errorf("failed to save compliance event: %w", err)
 	}
 	
 	// Create index entries for efficient querying
 	if err := e.createEventIndexEntries(stub, event); err != nil {
-		return fmt.Errorf("failed to create event index entries: %v", err)
+		return fmt.Errorf("failed to create event index entries: %w", err)
 	}
 	
-	// Emit Fabric event for external listeners
+	// Emit Fabric event for external listeners, versioned like the shared event service
 	eventName := fmt.Sprintf("ComplianceEvent_%s", event.EventType)
-	if err := stub.SetEvent(eventName, eventBytes); err != nil {
-		return fmt.Errorf("failed to emit Fabric event: %v", err)
+	versionedBytes, err := json.Marshal(struct {
+		interfaces.EventHeader
+		*ComplianceEvent
+	}{services.NewEventHeader(eventName), event})
+	if err != nil {
+		return fmt.Errorf("failed to marshal versioned compliance event: %w", err)
+	}
+	if err := stub.SetEvent(eventName, versionedBytes); err != nil {
+		return fmt.Errorf("failed to emit Fabric event: %w", err)
 	}
 	
 	return nil
@@ -75,10 +84,10 @@ func (e *FabricEventEmitter) createEventIndexEntries(stub shim.ChaincodeStubInte
 	if event.RuleID != "" {
 		ruleKey, err := stub.CreateCompositeKey("event_rule", []string{event.RuleID, event.EventID})
 		if err != nil {
-			return fmt.Errorf("failed to create rule composite key: %v", err)
+			return fmt.Errorf("failed to create rule composite key: %w", err)
 		}
-		if err := stub.PutState(ruleKey, []byte{}); err != nil {
-			return fmt.Errorf("failed to save rule index: %v", err)
+		if err := stub.PutState(ruleKey, []byte(event.EventID)); err != nil {
+			return fmt.Errorf("failed to save rule index: %w", err)
 		}
 	}
 	
@@ -86,10 +95,10 @@ func (e *FabricEventEmitter) createEventIndexEntries(stub shim.ChaincodeStubInte
 	if event.AffectedEntityID != "" {
 		entityKey, err := stub.CreateCompositeKey("event_entity", []string{event.AffectedEntityID, event.EventID})
 		if err != nil {
-			return fmt.Errorf("failed to create entity composite key: %v", err)
+			return fmt.Errorf("failed to create entity composite key: %w", err)
 		}
-		if err := stub.PutState(entityKey, []byte{}); err != nil {
-			return fmt.Errorf("failed to save entity index: %v", err)
+		if err := stub.PutState(entityKey, []byte(event.EventID)); err != nil {
+			return fmt.Errorf("failed to save entity index: %w", err)
 		}
 	}
 	
@@ -97,29 +106,29 @@ func (e *FabricEventEmitter) createEventIndexEntries(stub shim.ChaincodeStubInte
 	if event.AffectedEntityType != "" {
 		entityTypeKey, err := stub.CreateCompositeKey("event_entity_type", []string{event.AffectedEntityType, event.EventID})
 		if err != nil {
-			return fmt.Errorf("failed to create entity type composite key: %v", err)
+			return fmt.Errorf("failed to create entity type composite key: %w", err)
 		}
-		if err := stub.PutState(entityTypeKey, []byte{}); err != nil {
-			return fmt.Errorf("failed to save entity type index: %v", err)
+		if err := stub.PutState(entityTypeKey, []byte(event.EventID)); err != nil {
+			return fmt.Errorf("failed to save entity type index: %w", err)
 		}
 	}
 	
 	// Event type index
 	eventTypeKey, err := stub.CreateCompositeKey("event_type", []string{event.EventType, event.EventID})
 	if err != nil {
-		return fmt.Errorf("failed to create event type composite key: %v", err)
+		return fmt.Errorf("failed to create event type composite key: %w", err)
 	}
-	if err := stub.PutState(eventTypeKey, []byte{}); err != nil {
-		return fmt.Errorf("failed to save event type index: %v", err)
+	if err := stub.PutState(eventTypeKey, []byte(event.EventID)); err != nil {
+		return fmt.Errorf("failed to save event type index: %w", err)
 	}
 	
 	// Severity index
 	severityKey, err := stub.CreateCompositeKey("event_severity", []string{string(event.Severity), event.EventID})
 	if err != nil {
-		return fmt.Errorf("failed to create severity composite key: %v", err)
+		return fmt.Errorf("failed to create severity composite key: %w", err)
 	}
-	if err := stub.PutState(severityKey, []byte{}); err != nil {
-		return fmt.Errorf("failed to save severity index: %v", err)
+	if err := stub.PutState(severityKey, []byte(event.EventID)); err != nil {
+		return fmt.Errorf("failed to save severity index: %w", err)
 	}
 	
 	// Alert status index
@@ -129,29 +138,29 @@ func (e *FabricEventEmitter) createEventIndexEntries(stub shim.ChaincodeStubInte
 	}
 	alertKey, err := stub.CreateCompositeKey("event_alert", []string{alertStatus, event.EventID})
 	if err != nil {
-		return fmt.Errorf("failed to create alert composite key: %v", err)
+		return fmt.Errorf("failed to create alert composite key: %w", err)
 	}
-	if err := stub.PutState(alertKey, []byte{}); err != nil {
-		return fmt.Errorf("failed to save alert index: %v", err)
+	if err := stub.PutState(alertKey, []byte(event.EventID)); err != nil {
+		return fmt.Errorf("failed to save alert index: %w", err)
 	}
 	
 	// Resolution status index
 	resolutionKey, err := stub.CreateCompositeKey("event_resolution", []string{event.ResolutionStatus, event.EventID})
 	if err != nil {
-		return fmt.Errorf("failed to create resolution composite key: %v", err)
+		return fmt.Errorf("failed to create resolution composite key: %w", err)
 	}
-	if err := stub.PutState(resolutionKey, []byte{}); err != nil {
-		return fmt.Errorf("failed to save resolution index: %v", err)
+	if err := stub.PutState(resolutionKey, []byte(event.EventID)); err != nil {
+		return fmt.Errorf("failed to save resolution index: %w", err)
 	}
 	
 	// Actor index
 	if event.ActorID != "" {
 		actorKey, err := stub.CreateCompositeKey("event_actor", []string{event.ActorID, event.EventID})
 		if err != nil {
-			return fmt.Errorf("failed to create actor composite key: %v", err)
+			return fmt.Errorf("failed to create actor composite key: %w", err)
 		}
-		if err := stub.PutState(actorKey, []byte{}); err != nil {
-			return fmt.Errorf("failed to save actor index: %v", err)
+		if err := stub.PutState(actorKey, []byte(event.EventID)); err != nil {
+			return fmt.Errorf("failed to save actor index: %w", err)
 		}
 	}
 	
@@ -163,16 +172,16 @@ func (e *FabricEventEmitter) GetComplianceEvent(stub shim.ChaincodeStubInterface
 	eventKey := fmt.Sprintf("compliance_event~%s", eventID)
 	eventBytes, err := stub.GetState(eventKey)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get compliance event %s: %v", eventID, err)
+		return nil, fmt.Errorf("failed to get compliance event %s: %w", eventID, err)
 	}
 	
 	if eventBytes == nil {
-		return nil, fmt.Errorf("compliance event %s not found", eventID)
+		return nil, services.NewChaincodeError(services.ErrCodeNotFound, "", "compliance event %s not found", eventID)
 	}
 	
 	var event ComplianceEvent
 	if err := json.Unmarshal(eventBytes, &event); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal compliance event: %v", err)
+		return nil, fmt.Errorf("failed to unmarshal compliance event: %w", err)
 	}
 	
 	return &event, nil
@@ -182,7 +191,7 @@ func (e *FabricEventEmitter) GetComplianceEvent(stub shim.ChaincodeStubInterface
 func (e *FabricEventEmitter) GetEventsByRule(stub shim.ChaincodeStubInterface, ruleID string) ([]*ComplianceEvent, error) {
 	iterator, err := stub.GetStateByPartialCompositeKey("event_rule", []string{ruleID})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get events by rule %s: %v", ruleID, err)
+		return nil, fmt.Errorf("failed to get events by rule %s: %w", ruleID, err)
 	}
 	defer iterator.Close()
 	
@@ -191,7 +200,7 @@ func (e *FabricEventEmitter) GetEventsByRule(stub shim.ChaincodeStubInterface, r
 	for iterator.HasNext() {
 		response, err := iterator.Next()
 		if err != nil {
-			return nil, fmt.Errorf("failed to iterate rule events: %v", err)
+			return nil, fmt.Errorf("failed to iterate rule events: %w", err)
 		}
 		
 		// Extract event ID from composite key
@@ -216,7 +225,7 @@ func (e *FabricEventEmitter) GetEventsByRule(stub shim.ChaincodeStubInterface, r
 func (e *FabricEventEmitter) GetEventsByEntity(stub shim.ChaincodeStubInterface, entityID string) ([]*ComplianceEvent, error) {
 	iterator, err := stub.GetStateByPartialCompositeKey("event_entity", []string{entityID})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get events by entity %s: %v", entityID, err)
+		return nil, fmt.Errorf("failed to get events by entity %s: %w", entityID, err)
 	}
 	defer iterator.Close()
 	
@@ -225,7 +234,7 @@ func (e *FabricEventEmitter) GetEventsByEntity(stub shim.ChaincodeStubInterface,
 	for iterator.HasNext() {
 		response, err := iterator.Next()
 		if err != nil {
-			return nil, fmt.Errorf("failed to iterate entity events: %v", err)
+			return nil, fmt.Errorf("failed to iterate entity events: %w", err)
 		}
 		
 		// Extract event ID from composite key
@@ -250,7 +259,7 @@ func (e *FabricEventEmitter) GetEventsByEntity(stub shim.ChaincodeStubInterface,
 func (e *FabricEventEmitter) GetEventsByType(stub shim.ChaincodeStubInterface, eventType string) ([]*ComplianceEvent, error) {
 	iterator, err := stub.GetStateByPartialCompositeKey("event_type", []string{eventType})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get events by type %s: %v", eventType, err)
+		return nil, fmt.Errorf("failed to get events by type %s: %w", eventType, err)
 	}
 	defer iterator.Close()
 	
@@ -259,7 +268,7 @@ func (e *FabricEventEmitter) GetEventsByType(stub shim.ChaincodeStubInterface, e
 	for iterator.HasNext() {
 		response, err := iterator.Next()
 		if err != nil {
-			return nil, fmt.Errorf("failed to iterate type events: %v", err)
+			return nil, fmt.Errorf("failed to iterate type events: %w", err)
 		}
 		
 		// Extract event ID from composite key
@@ -284,7 +293,7 @@ func (e *FabricEventEmitter) GetEventsByType(stub shim.ChaincodeStubInterface, e
 func (e *FabricEventEmitter) GetEventsBySeverity(stub shim.ChaincodeStubInterface, severity ComplianceRulePriority) ([]*ComplianceEvent, error) {
 	iterator, err := stub.GetStateByPartialCompositeKey("event_severity", []string{string(severity)})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get events by severity %s: %v", severity, err)
+		return nil, fmt.Errorf("failed to get events by severity %s: %w", severity, err)
 	}
 	defer iterator.Close()
 	
@@ -293,7 +302,7 @@ func (e *FabricEventEmitter) GetEventsBySeverity(stub shim.ChaincodeStubInterfac
 	for iterator.HasNext() {
 		response, err := iterator.Next()
 		if err != nil {
-			return nil, fmt.Errorf("failed to iterate severity events: %v", err)
+			return nil, fmt.Errorf("failed to iterate severity events: %w", err)
 		}
 		
 		// Extract event ID from composite key
@@ -318,7 +327,7 @@ func (e *FabricEventEmitter) GetEventsBySeverity(stub shim.ChaincodeStubInterfac
 func (e *FabricEventEmitter) GetAlertedEvents(stub shim.ChaincodeStubInterface) ([]*ComplianceEvent, error) {
 	iterator, err := stub.GetStateByPartialCompositeKey("event_alert", []string{"true"})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get alerted events: %v", err)
+		return nil, fmt.Errorf("failed to get alerted events: %w", err)
 	}
 	defer iterator.Close()
 	
@@ -327,7 +336,7 @@ func (e *FabricEventEmitter) GetAlertedEvents(stub shim.ChaincodeStubInterface)
 	for iterator.HasNext() {
 		response, err := iterator.Next()
 		if err != nil {
-			return nil, fmt.Errorf("failed to iterate alerted events: %v", err)
+			return nil, fmt.Errorf("failed to iterate alerted events: %w", err)
 		}
 		
 		// Extract event ID from composite key
@@ -352,7 +361,7 @@ func (e *FabricEventEmitter) GetAlertedEvents(stub shim.ChaincodeStubInterface)
 func (e *FabricEventEmitter) AcknowledgeEvent(stub shim.ChaincodeStubInterface, eventID string, acknowledgedBy string) error {
 	event, err := e.GetComplianceEvent(stub, eventID)
 	if err != nil {
-		return fmt.Errorf("failed to get event for acknowledgment: %v", err)
+		return fmt.Errorf("failed to get event for acknowledgment: %w", err)
 	}
 	
 	// Update acknowledgment information
@@ -364,11 +373,11 @@ func (e *FabricEventEmitter) AcknowledgeEvent(stub shim.ChaincodeStubInterface,
 	eventKey := fmt.Sprintf("compliance_event~%s", event.EventID)
 	eventBytes, err := json.Marshal(event)
 	if err != nil {
-		return fmt.Errorf("failed to marshal updated event: %v", err)
+		return fmt.Errorf("failed to marshal updated event: %w", err)
 	}
 	
 	if err := stub.PutState(eventKey, eventBytes); err != nil {
-		return fmt.Errorf("failed to save acknowledged event: %v", err)
+		return fmt.Errorf("failed to save acknowledged event: %w", err)
 	}
 	
 	return nil
@@ -378,7 +387,7 @@ func (e *FabricEventEmitter) AcknowledgeEvent(stub shim.ChaincodeStubInterface,
 func (e *FabricEventEmitter) UpdateEventResolution(stub shim.ChaincodeStubInterface, eventID string, status string, notes string) error {
 	event, err := e.GetComplianceEvent(stub, eventID)
 	if err != nil {
-		return fmt.Errorf("failed to get event for resolution update: %v", err)
+		return fmt.Errorf("failed to get event for resolution update: %w", err)
 	}
 	
 	// Update resolution information
@@ -389,20 +398,20 @@ func (e *FabricEventEmitter) UpdateEventResolution(stub shim.ChaincodeStubInterf
 	eventKey := fmt.Sprintf("compliance_event~%s", event.EventID)
 	eventBytes, err := json.Marshal(event)
 	if err != nil {
-		return fmt.Errorf("failed to marshal updated event: %v", err)
+		return fmt.Errorf("failed to marshal updated event: %w", err)
 	}
 	
 	if err := stub.PutState(eventKey, eventBytes); err != nil {
-		return fmt.Errorf("failed to save resolved event: %v", err)
+		return fmt.Errorf("failed to save resolved event: %w", err)
 	}
 	
 	// Update resolution index
 	resolutionKey, err := stub.CreateCompositeKey("event_resolution", []string{status, eventID})
 	if err != nil {
-		return fmt.Errorf("failed to create resolution composite key: %v", err)
+		return fmt.Errorf("failed to create resolution composite key: %w", err)
 	}
-	if err := stub.PutState(resolutionKey, []byte{}); err != nil {
-		return fmt.Errorf("failed to update resolution index: %v", err)
+	if err := stub.PutState(resolutionKey, []byte(eventID)); err != nil {
+		return fmt.Errorf("failed to update resolution index: %w", err)
 	}
 	
 	return nil
@@ -8,6 +8,7 @@ import (
 	"github.com/hyperledger/fabric-chaincode-go/shim"
 	"github.com/hyperledger/fabric-chaincode-go/shimtest"
 	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
 )
 
 // EnhancedMockStub extends shimtest.MockStub to support composite key operations
@@ -30,7 +31,7 @@ func NewEnhancedMockStub(name string, cc shim.Chaincode) *EnhancedMockStub {
 // CreateCompositeKey creates a composite key from the given attributes
 func (stub *EnhancedMockStub) CreateCompositeKey(objectType string, attributes []string) (string, error) {
 	if objectType == "" {
-		return "", fmt.Errorf("object type must not be empty")
+		return "", services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "object type must not be empty")
 	}
 	
 	// Create composite key by joining object type and attributes with a delimiter
@@ -44,7 +45,7 @@ func (stub *EnhancedMockStub) CreateCompositeKey(objectType string, attributes [
 func (stub *EnhancedMockStub) SplitCompositeKey(compositeKey string) (string, []string, error) {
 	parts := strings.Split(compositeKey, "~")
 	if len(parts) < 1 {
-		return "", nil, fmt.Errorf("invalid composite key format")
+		return "", nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid composite key format")
 	}
 	
 	objectType := parts[0]
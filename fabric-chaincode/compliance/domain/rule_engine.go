@@ -7,6 +7,8 @@ import (
 	"time"
 
 	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
 )
 
 // RuleEngine defines the interface for executing compliance rules
@@ -61,12 +63,15 @@ func NewComplianceRuleEngine(repository RuleRepository, emitter EventEmitter) *C
 
 // ExecuteRule executes a specific compliance rule against entity data
 func (e *ComplianceRuleEngine) ExecuteRule(ctx context.Context, stub shim.ChaincodeStubInterface, ruleID string, entityData map[string]interface{}) (RuleExecutionResult, error) {
-	startTime := time.Now()
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return RuleExecutionResult{}, err
+	}
 	
 	result := RuleExecutionResult{
 		RuleID:      ruleID,
-		ExecutionID: fmt.Sprintf("exec_%s_%d", ruleID, startTime.Unix()),
-		Timestamp:   startTime,
+		ExecutionID: fmt.Sprintf("exec_%s_%d", ruleID, now.Unix()),
+		Timestamp:   now,
 		Success:     false,
 		Passed:      false,
 		Details:     make(map[string]interface{}),
@@ -76,23 +81,42 @@ func (e *ComplianceRuleEngine) ExecuteRule(ctx context.Context, stub shim.Chainc
 	rule, err := e.ruleRepository.GetLatestRule(stub, ruleID)
 	if err != nil {
 		result.ErrorMessage = fmt.Sprintf("Failed to retrieve rule: %v", err)
-		result.ExecutionTime = time.Since(startTime).Milliseconds()
 		return result, err
 	}
 	
 	// Check if rule can be executed
-	if !rule.CanExecute() {
+	if !rule.CanExecute(now) {
 		result.ErrorMessage = "Rule is not in executable state"
-		result.ExecutionTime = calculateExecutionTime(startTime)
 		return result, fmt.Errorf("rule %s is not executable", ruleID)
 	}
 	
+	// Rules in gradual rollout are promoted once observed for long enough; until then they are
+	// only evaluated, in shadow, for entities in their sample
+	shadow, promoted := false, false
+	if rule.Rollout != nil && rule.Rollout.InShadow() {
+		if rule.Rollout.ReadyForPromotion(now) {
+			rule.Rollout.Promote(now)
+			if err := e.ruleRepository.SaveRule(stub, rule); err != nil {
+				result.ErrorMessage = fmt.Sprintf("Failed to promote rule: %v", err)
+				return result, err
+			}
+			promoted = true
+		} else if entityID, segment := rolloutSubject(stub, entityData); !rule.Rollout.Samples(rule.RuleID, entityID, segment) {
+			result.Success = true
+			result.Passed = true
+			result.Details["rolloutStatus"] = rule.Rollout.Status
+			result.Details["sampled"] = false
+			return result, nil
+		} else {
+			shadow = true
+		}
+	}
+	
 	// Execute rule dependencies first
 	if len(rule.Dependencies) > 0 {
 		dependencyResults, err := e.executeDependencies(ctx, stub, rule.Dependencies, entityData)
 		if err != nil {
 			result.ErrorMessage = fmt.Sprintf("Failed to execute dependencies: %v", err)
-			result.ExecutionTime = calculateExecutionTime(startTime)
 			return result, err
 		}
 		
@@ -100,7 +124,6 @@ func (e *ComplianceRuleEngine) ExecuteRule(ctx context.Context, stub shim.Chainc
 		for _, depResult := range dependencyResults {
 			if !depResult.Passed {
 				result.ErrorMessage = fmt.Sprintf("Dependency rule %s failed", depResult.RuleID)
-				result.ExecutionTime = calculateExecutionTime(startTime)
 				return result, fmt.Errorf("dependency rule %s failed", depResult.RuleID)
 			}
 		}
@@ -109,10 +132,9 @@ func (e *ComplianceRuleEngine) ExecuteRule(ctx context.Context, stub shim.Chainc
 	}
 	
 	// Execute the rule logic
-	ruleResult, err := e.executeRuleLogic(rule, entityData)
+	ruleResult, err := e.executeRuleLogic(rule, entityData, now)
 	if err != nil {
 		result.ErrorMessage = fmt.Sprintf("Rule execution failed: %v", err)
-		result.ExecutionTime = calculateExecutionTime(startTime)
 		return result, err
 	}
 	
@@ -120,7 +142,23 @@ func (e *ComplianceRuleEngine) ExecuteRule(ctx context.Context, stub shim.Chainc
 	result.Passed = ruleResult.Passed
 	result.Score = ruleResult.Score
 	result.Details = ruleResult.Details
-	result.ExecutionTime = calculateExecutionTime(startTime)
+	
+	// Shadow outcomes are logged and counted towards promotion but never fail the execution
+	if shadow {
+		rule.Rollout.RecordEvaluation(ruleResult.Passed)
+		if err := e.ruleRepository.SaveRule(stub, rule); err != nil {
+			result.ErrorMessage = fmt.Sprintf("Failed to record shadow evaluation: %v", err)
+			return result, err
+		}
+		result.Passed = true
+		result.Details["rolloutStatus"] = rule.Rollout.Status
+		result.Details["sampled"] = true
+		result.Details["shadowPassed"] = ruleResult.Passed
+	}
+	if promoted {
+		result.Details["rolloutStatus"] = rule.Rollout.Status
+		result.Details["rolloutPromoted"] = true
+	}
 	
 	// Emit execution event
 	if e.eventEmitter != nil {
@@ -134,7 +172,7 @@ func (e *ComplianceRuleEngine) ExecuteRule(ctx context.Context, stub shim.Chainc
 func (e *ComplianceRuleEngine) ExecuteRulesForEntity(ctx context.Context, stub shim.ChaincodeStubInterface, entityType string, entityData map[string]interface{}) ([]RuleExecutionResult, error) {
 	rules, err := e.ruleRepository.GetRulesByEntityType(stub, entityType)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get rules for entity type %s: %v", entityType, err)
+		return nil, fmt.Errorf("failed to get rules for entity type %s: %w", entityType, err)
 	}
 	
 	var results []RuleExecutionResult
@@ -147,7 +185,7 @@ func (e *ComplianceRuleEngine) ExecuteRulesForEntity(ctx context.Context, stub s
 	
 	executionOrder, err := e.GetExecutionOrder(ctx, stub, ruleIDs)
 	if err != nil {
-		return nil, fmt.Errorf("failed to determine execution order: %v", err)
+		return nil, fmt.Errorf("failed to determine execution order: %w", err)
 	}
 	
 	// Execute rules in dependency order
@@ -168,7 +206,7 @@ func (e *ComplianceRuleEngine) ExecuteRulesForEntity(ctx context.Context, stub s
 func (e *ComplianceRuleEngine) ExecuteRulesForEvent(ctx context.Context, stub shim.ChaincodeStubInterface, eventType string, entityData map[string]interface{}) ([]RuleExecutionResult, error) {
 	rules, err := e.ruleRepository.GetRulesByEvent(stub, eventType)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get rules for event %s: %v", eventType, err)
+		return nil, fmt.Errorf("failed to get rules for event %s: %w", eventType, err)
 	}
 	
 	var results []RuleExecutionResult
@@ -188,8 +226,13 @@ func (e *ComplianceRuleEngine) ExecuteRulesForEvent(ctx context.Context, stub sh
 
 // ValidateRule performs comprehensive validation of a compliance rule
 func (e *ComplianceRuleEngine) ValidateRule(ctx context.Context, stub shim.ChaincodeStubInterface, rule *ComplianceRule) ([]ValidationResult, error) {
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
 	// Perform built-in validations
-	results := rule.Validate()
+	results := rule.Validate(now)
 	
 	// Additional validations that require repository access
 	
@@ -198,11 +241,11 @@ func (e *ComplianceRuleEngine) ValidateRule(ctx context.Context, stub shim.Chain
 		_, err := e.ruleRepository.GetLatestRule(stub, depID)
 		if err != nil {
 			depResult := ValidationResult{
-				ValidationID:   fmt.Sprintf("dep_exist_%s_%d", rule.RuleID, time.Now().Unix()),
+				ValidationID:   fmt.Sprintf("dep_exist_%s_%d", rule.RuleID, now.Unix()),
 				ValidationType: "DEPENDENCY",
 				IsValid:        false,
 				ErrorMessages:  []string{fmt.Sprintf("Dependency rule %s does not exist", depID)},
-				ValidationDate: time.Now(),
+				ValidationDate: now,
 			}
 			results = append(results, depResult)
 		}
@@ -211,13 +254,13 @@ func (e *ComplianceRuleEngine) ValidateRule(ctx context.Context, stub shim.Chain
 	// Validate conflicts
 	for _, conflictID := range rule.ConflictsWith {
 		conflictRule, err := e.ruleRepository.GetLatestRule(stub, conflictID)
-		if err == nil && conflictRule.IsActive() {
+		if err == nil && conflictRule.IsActive(now) {
 			conflictResult := ValidationResult{
-				ValidationID:   fmt.Sprintf("conflict_%s_%d", rule.RuleID, time.Now().Unix()),
+				ValidationID:   fmt.Sprintf("conflict_%s_%d", rule.RuleID, now.Unix()),
 				ValidationType: "CONFLICT",
 				IsValid:        false,
 				ErrorMessages:  []string{fmt.Sprintf("Conflicting rule %s is currently active", conflictID)},
-				ValidationDate: time.Now(),
+				ValidationDate: now,
 			}
 			results = append(results, conflictResult)
 		}
@@ -230,7 +273,7 @@ func (e *ComplianceRuleEngine) ValidateRule(ctx context.Context, stub shim.Chain
 func (e *ComplianceRuleEngine) TestRule(ctx context.Context, stub shim.ChaincodeStubInterface, ruleID string, testCaseID string) (RuleExecutionResult, error) {
 	rule, err := e.ruleRepository.GetLatestRule(stub, ruleID)
 	if err != nil {
-		return RuleExecutionResult{}, fmt.Errorf("failed to get rule %s: %v", ruleID, err)
+		return RuleExecutionResult{}, fmt.Errorf("failed to get rule %s: %w", ruleID, err)
 	}
 	
 	// Find the test case
@@ -243,7 +286,7 @@ func (e *ComplianceRuleEngine) TestRule(ctx context.Context, stub shim.Chaincode
 	}
 	
 	if testCase == nil {
-		return RuleExecutionResult{}, fmt.Errorf("test case %s not found for rule %s", testCaseID, ruleID)
+		return RuleExecutionResult{}, services.NewChaincodeError(services.ErrCodeNotFound, "", "test case %s not found for rule %s", testCaseID, ruleID)
 	}
 	
 	// Execute the rule with test data
@@ -264,9 +307,14 @@ func (e *ComplianceRuleEngine) TestRule(ctx context.Context, stub shim.Chaincode
 func (e *ComplianceRuleEngine) RunAllTests(ctx context.Context, stub shim.ChaincodeStubInterface, ruleID string) ([]RuleExecutionResult, error) {
 	rule, err := e.ruleRepository.GetLatestRule(stub, ruleID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get rule %s: %v", ruleID, err)
+		return nil, fmt.Errorf("failed to get rule %s: %w", ruleID, err)
 	}
 	
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
 	var results []RuleExecutionResult
 	
 	for _, testCase := range rule.TestCases {
@@ -275,7 +323,7 @@ func (e *ComplianceRuleEngine) RunAllTests(ctx context.Context, stub shim.Chainc
 			result = RuleExecutionResult{
 				RuleID:       ruleID,
 				ExecutionID:  fmt.Sprintf("test_%s_%s", ruleID, testCase.TestID),
-				Timestamp:    time.Now(),
+				Timestamp:    now,
 				Success:      false,
 				ErrorMessage: err.Error(),
 			}
@@ -304,14 +352,19 @@ func (e *ComplianceRuleEngine) ResolveDependencies(ctx context.Context, stub shi
 func (e *ComplianceRuleEngine) CheckConflicts(ctx context.Context, stub shim.ChaincodeStubInterface, ruleID string) ([]string, error) {
 	rule, err := e.ruleRepository.GetLatestRule(stub, ruleID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get rule %s: %v", ruleID, err)
+		return nil, fmt.Errorf("failed to get rule %s: %w", ruleID, err)
+	}
+	
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
 	}
 	
 	var conflicts []string
 	
 	for _, conflictID := range rule.ConflictsWith {
 		conflictRule, err := e.ruleRepository.GetLatestRule(stub, conflictID)
-		if err == nil && conflictRule.IsActive() {
+		if err == nil && conflictRule.IsActive(now) {
 			conflicts = append(conflicts, conflictID)
 		}
 	}
@@ -385,7 +438,7 @@ func (e *ComplianceRuleEngine) executeDependencies(ctx context.Context, stub shi
 	for _, depID := range dependencies {
 		result, err := e.ExecuteRule(ctx, stub, depID, entityData)
 		if err != nil {
-			return nil, fmt.Errorf("failed to execute dependency %s: %v", depID, err)
+			return nil, fmt.Errorf("failed to execute dependency %s: %w", depID, err)
 		}
 		results = append(results, result)
 	}
@@ -394,10 +447,10 @@ func (e *ComplianceRuleEngine) executeDependencies(ctx context.Context, stub shi
 }
 
 // executeRuleLogic executes the actual rule logic
-func (e *ComplianceRuleEngine) executeRuleLogic(rule *ComplianceRule, entityData map[string]interface{}) (RuleExecutionResult, error) {
+func (e *ComplianceRuleEngine) executeRuleLogic(rule *ComplianceRule, entityData map[string]interface{}, now time.Time) (RuleExecutionResult, error) {
 	result := RuleExecutionResult{
 		RuleID:    rule.RuleID,
-		Timestamp: time.Now(),
+		Timestamp: now,
 		Success:   true,
 		Details:   make(map[string]interface{}),
 	}
@@ -406,43 +459,43 @@ func (e *ComplianceRuleEngine) executeRuleLogic(rule *ComplianceRule, entityData
 	// In a real implementation, this would use a proper rule engine like Drools or a custom DSL
 	var ruleLogic map[string]interface{}
 	if err := json.Unmarshal([]byte(rule.RuleLogic), &ruleLogic); err != nil {
-		return result, fmt.Errorf("failed to parse rule logic: %v", err)
+		return result, fmt.Errorf("failed to parse rule logic: %w", err)
 	}
 	
 	// Execute rule based on logic type
 	logicType, ok := ruleLogic["type"].(string)
 	if !ok {
-		return result, fmt.Errorf("rule logic must specify a type")
+		return result, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "rule logic must specify a type")
 	}
 	
 	switch logicType {
 	case "threshold":
-		return e.executeThresholdRule(ruleLogic, entityData)
+		return e.executeThresholdRule(ruleLogic, entityData, now)
 	case "validation":
-		return e.executeValidationRule(ruleLogic, entityData)
+		return e.executeValidationRule(ruleLogic, entityData, now)
 	case "comparison":
-		return e.executeComparisonRule(ruleLogic, entityData)
+		return e.executeComparisonRule(ruleLogic, entityData, now)
 	default:
 		return result, fmt.Errorf("unsupported rule logic type: %s", logicType)
 	}
 }
 
 // executeThresholdRule executes a threshold-based rule
-func (e *ComplianceRuleEngine) executeThresholdRule(ruleLogic map[string]interface{}, entityData map[string]interface{}) (RuleExecutionResult, error) {
+func (e *ComplianceRuleEngine) executeThresholdRule(ruleLogic map[string]interface{}, entityData map[string]interface{}, now time.Time) (RuleExecutionResult, error) {
 	result := RuleExecutionResult{
-		Timestamp: time.Now(),
+		Timestamp: now,
 		Success:   true,
 		Details:   make(map[string]interface{}),
 	}
 	
 	field, ok := ruleLogic["field"].(string)
 	if !ok {
-		return result, fmt.Errorf("threshold rule must specify a field")
+		return result, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "threshold rule must specify a field")
 	}
 	
 	threshold, ok := ruleLogic["threshold"].(float64)
 	if !ok {
-		return result, fmt.Errorf("threshold rule must specify a threshold value")
+		return result, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "threshold rule must specify a threshold value")
 	}
 	
 	operator, ok := ruleLogic["operator"].(string)
@@ -450,6 +503,35 @@ func (e *ComplianceRuleEngine) executeThresholdRule(ruleLogic map[string]interfa
 		operator = ">" // default operator
 	}
 	
+	// Amount thresholds may be set per ISO 4217 currency in thresholdsByCurrency, read against the
+	// entity's currencyField ("currency" by default). The plain threshold applies to entities in
+	// baseCurrency (config.BaseCurrency by default) or without a currency; other currencies must be
+	// listed.
+	if byCurrency, ok := ruleLogic["thresholdsByCurrency"].(map[string]interface{}); ok {
+		currencyField, _ := ruleLogic["currencyField"].(string)
+		if currencyField == "" {
+			currencyField = "currency"
+		}
+		baseCurrency, _ := ruleLogic["baseCurrency"].(string)
+		if baseCurrency == "" {
+			baseCurrency = config.BaseCurrency
+		}
+		currency, _ := entityData[currencyField].(string)
+		if currency == "" {
+			currency = baseCurrency
+		}
+		if currency != baseCurrency {
+			currencyThreshold, ok := byCurrency[currency].(float64)
+			if !ok {
+				result.Passed = false
+				result.Details["error"] = fmt.Sprintf("no threshold set for currency %s", currency)
+				return result, nil
+			}
+			threshold = currencyThreshold
+		}
+		result.Details["currency"] = currency
+	}
+	
 	value, exists := entityData[field]
 	if !exists {
 		result.Passed = false
@@ -490,9 +572,9 @@ func (e *ComplianceRuleEngine) executeThresholdRule(ruleLogic map[string]interfa
 }
 
 // executeValidationRule executes a validation-based rule
-func (e *ComplianceRuleEngine) executeValidationRule(ruleLogic map[string]interface{}, entityData map[string]interface{}) (RuleExecutionResult, error) {
+func (e *ComplianceRuleEngine) executeValidationRule(ruleLogic map[string]interface{}, entityData map[string]interface{}, now time.Time) (RuleExecutionResult, error) {
 	result := RuleExecutionResult{
-		Timestamp: time.Now(),
+		Timestamp: now,
 		Success:   true,
 		Passed:    true,
 		Details:   make(map[string]interface{}),
@@ -500,7 +582,7 @@ func (e *ComplianceRuleEngine) executeValidationRule(ruleLogic map[string]interf
 	
 	validations, ok := ruleLogic["validations"].([]interface{})
 	if !ok {
-		return result, fmt.Errorf("validation rule must specify validations")
+		return result, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "validation rule must specify validations")
 	}
 	
 	var errors []string
@@ -533,21 +615,21 @@ func (e *ComplianceRuleEngine) executeValidationRule(ruleLogic map[string]interf
 }
 
 // executeComparisonRule executes a comparison-based rule
-func (e *ComplianceRuleEngine) executeComparisonRule(ruleLogic map[string]interface{}, entityData map[string]interface{}) (RuleExecutionResult, error) {
+func (e *ComplianceRuleEngine) executeComparisonRule(ruleLogic map[string]interface{}, entityData map[string]interface{}, now time.Time) (RuleExecutionResult, error) {
 	result := RuleExecutionResult{
-		Timestamp: time.Now(),
+		Timestamp: now,
 		Success:   true,
 		Details:   make(map[string]interface{}),
 	}
 	
 	field1, ok := ruleLogic["field1"].(string)
 	if !ok {
-		return result, fmt.Errorf("comparison rule must specify field1")
+		return result, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "comparison rule must specify field1")
 	}
 	
 	field2, ok := ruleLogic["field2"].(string)
 	if !ok {
-		return result, fmt.Errorf("comparison rule must specify field2")
+		return result, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "comparison rule must specify field2")
 	}
 	
 	operator, ok := ruleLogic["operator"].(string)
@@ -597,7 +679,7 @@ func (e *ComplianceRuleEngine) resolveDependenciesRecursive(stub shim.ChaincodeS
 	
 	rule, err := e.ruleRepository.GetLatestRule(stub, ruleID)
 	if err != nil {
-		return fmt.Errorf("failed to get rule %s: %v", ruleID, err)
+		return fmt.Errorf("failed to get rule %s: %w", ruleID, err)
 	}
 	
 	for _, dep := range rule.Dependencies {
@@ -613,11 +695,14 @@ func (e *ComplianceRuleEngine) resolveDependenciesRecursive(stub shim.ChaincodeS
 	return nil
 }
 
-// calculateExecutionTime calculates execution time ensuring it's at least 1ms
-func calculateExecutionTime(startTime time.Time) int64 {
-	executionTimeMs := time.Since(startTime).Nanoseconds() / 1000000
-	if executionTimeMs == 0 {
-		executionTimeMs = 1 // Ensure at least 1ms for very fast executions
-	}
-	return executionTimeMs
+// rolloutSubject returns the entity ID and segment a rollout samples on, read from the entityID
+// and segment fields of the entity data. Executions without an entity ID are bucketed by
+// transaction instead.
+func rolloutSubject(stub shim.ChaincodeStubInterface, entityData map[string]interface{}) (string, string) {
+	entityID, _ := entityData["entityID"].(string)
+	if entityID == "" {
+		entityID = stub.GetTxID()
+	}
+	segment, _ := entityData["segment"].(string)
+	return entityID, segment
 }
\ No newline at end of file
@@ -11,6 +11,7 @@ import (
 	"github.com/hyperledger/fabric-chaincode-go/shimtest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
 )
 
 // setupMockStub creates a properly initialized mock stub for testing
@@ -52,7 +53,7 @@ func (m *MockRuleRepository) GetLatestRule(stub shim.ChaincodeStubInterface, rul
 func (m *MockRuleRepository) GetActiveRules(stub shim.ChaincodeStubInterface) ([]*ComplianceRule, error) {
 	var activeRules []*ComplianceRule
 	for _, rule := range m.rules {
-		if rule.IsActive() {
+		if rule.IsActive(time.Now()) {
 			activeRules = append(activeRules, rule)
 		}
 	}
@@ -62,7 +63,7 @@ func (m *MockRuleRepository) GetActiveRules(stub shim.ChaincodeStubInterface) ([
 func (m *MockRuleRepository) GetRulesByDomain(stub shim.ChaincodeStubInterface, domain string) ([]*ComplianceRule, error) {
 	var rules []*ComplianceRule
 	for _, rule := range m.rules {
-		if rule.AppliesToDomain == domain && rule.IsActive() {
+		if rule.AppliesToDomain == domain && rule.IsActive(time.Now()) {
 			rules = append(rules, rule)
 		}
 	}
@@ -72,7 +73,7 @@ func (m *MockRuleRepository) GetRulesByDomain(stub shim.ChaincodeStubInterface,
 func (m *MockRuleRepository) GetRulesByEntityType(stub shim.ChaincodeStubInterface, entityType string) ([]*ComplianceRule, error) {
 	var rules []*ComplianceRule
 	for _, rule := range m.rules {
-		if rule.AppliesToEntityType == entityType && rule.IsActive() {
+		if rule.AppliesToEntityType == entityType && rule.IsActive(time.Now()) {
 			rules = append(rules, rule)
 		}
 	}
@@ -83,7 +84,7 @@ func (m *MockRuleRepository) GetRulesByEvent(stub shim.ChaincodeStubInterface, e
 	var rules []*ComplianceRule
 	for _, rule := range m.rules {
 		for _, trigger := range rule.TriggerEvents {
-			if trigger == eventType && rule.IsActive() {
+			if trigger == eventType && rule.IsActive(time.Now()) {
 				rules = append(rules, rule)
 				break
 			}
@@ -94,7 +95,7 @@ func (m *MockRuleRepository) GetRulesByEvent(stub shim.ChaincodeStubInterface, e
 
 func (m *MockRuleRepository) SaveRule(stub shim.ChaincodeStubInterface, rule *ComplianceRule) error {
 	// Validate the rule before saving
-	validationResults := rule.Validate()
+	validationResults := rule.Validate(time.Now())
 	for _, result := range validationResults {
 		if !result.IsValid {
 			return fmt.Errorf("validation failed: %v", result.ErrorMessages)
@@ -224,10 +225,6 @@ func TestComplianceRuleEngine_ExecuteRule(t *testing.T) {
 				assert.Equal(t, tt.expectedPassed, result.Passed)
 				assert.True(t, result.Success)
 				assert.NotEmpty(t, result.ExecutionID)
-				if result.ExecutionTime == 0 {
-					t.Logf("ExecutionTime is 0, result: %+v", result)
-				}
-				assert.NotZero(t, result.ExecutionTime)
 			}
 		})
 	}
@@ -637,7 +634,7 @@ func TestComplianceRule_Validation(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			results := tt.rule.Validate()
+			results := tt.rule.Validate(time.Now())
 			assert.NotEmpty(t, results)
 
 			// Check if all validations passed
@@ -699,7 +696,185 @@ func TestComplianceRule_IsActive(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			assert.Equal(t, tt.expectedActive, tt.rule.IsActive())
+			assert.Equal(t, tt.expectedActive, tt.rule.IsActive(time.Now()))
+		})
+	}
+}
+
+func TestComplianceRuleEngine_GradualRollout(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	newRolloutRule := func(ruleID string) *ComplianceRule {
+		return &ComplianceRule{
+			RuleID:              ruleID,
+			RuleName:            "Rollout Threshold Rule",
+			Version:             "1.0.0",
+			RuleLogic:           `{"type": "threshold", "field": "amount", "threshold": 1000, "operator": ">"}`,
+			ExecutionMode:       ExecutionModeSync,
+			Priority:            PriorityMedium,
+			AppliesToDomain:     "LOAN",
+			AppliesToEntityType: "LoanApplication",
+			Status:              RuleStatusActive,
+			EffectiveDate:       start,
+			Rollout: &RuleRollout{
+				Status:                 RolloutStatusShadow,
+				Segments:               []string{"SME"},
+				ObservationWindowHours: 24,
+				MaxViolationRate:       0.5,
+				StartDate:              start,
+			},
+			ValidationResults: []ValidationResult{
+				{IsValid: true, ValidationDate: start},
+			},
+		}
+	}
+	execute := func(engine *ComplianceRuleEngine, stub shim.ChaincodeStubInterface, ruleID, entityID, segment string, amount float64) RuleExecutionResult {
+		result, err := engine.ExecuteRule(context.Background(), stub, ruleID, map[string]interface{}{
+			"entityID": entityID,
+			"segment":  segment,
+			"amount":   amount,
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	t.Run("Shadow evaluations are logged without enforcing, then promoted", func(t *testing.T) {
+		clock := services.NewFixedClock(start)
+		defer services.SetClock(clock)()
+		mockRepo := NewMockRuleRepository()
+		mockEmitter := NewMockEventEmitter()
+		engine := NewComplianceRuleEngine(mockRepo, mockEmitter)
+		stub := setupMockStubForRuleEngine()
+		rule := newRolloutRule("ROLLOUT_RULE_001")
+		require.NoError(t, mockRepo.SaveRule(stub, rule))
+
+		// Entities outside the sample are not evaluated
+		result := execute(engine, stub, rule.RuleID, "LOAN_1", "RETAIL", 500)
+		assert.True(t, result.Passed)
+		assert.Equal(t, false, result.Details["sampled"])
+		assert.Equal(t, int64(0), rule.Rollout.Evaluations)
+
+		// Sampled violations are logged but do not fail the execution
+		result = execute(engine, stub, rule.RuleID, "LOAN_2", "SME", 500)
+		assert.True(t, result.Passed)
+		assert.Equal(t, false, result.Details["shadowPassed"])
+		result = execute(engine, stub, rule.RuleID, "LOAN_3", "SME", 1500)
+		assert.True(t, result.Passed)
+		assert.Equal(t, true, result.Details["shadowPassed"])
+		assert.Equal(t, int64(2), rule.Rollout.Evaluations)
+		assert.Equal(t, int64(1), rule.Rollout.Violations)
+		assert.Equal(t, "RULE_EXECUTED", mockEmitter.events[len(mockEmitter.events)-1].EventType)
+
+		// Still inside the observation window
+		clock.Advance(23 * time.Hour)
+		result = execute(engine, stub, rule.RuleID, "LOAN_4", "RETAIL", 500)
+		assert.True(t, result.Passed)
+		assert.Equal(t, RolloutStatusShadow, rule.Rollout.Status)
+
+		// After the window with an acceptable violation rate the rule is enforced for everyone
+		clock.Advance(time.Hour)
+		result = execute(engine, stub, rule.RuleID, "LOAN_5", "RETAIL", 500)
+		assert.False(t, result.Passed)
+		assert.Equal(t, true, result.Details["rolloutPromoted"])
+		assert.Equal(t, RolloutStatusEnforced, rule.Rollout.Status)
+		require.NotNil(t, rule.Rollout.PromotedDate)
+		assert.Equal(t, start.Add(24*time.Hour), *rule.Rollout.PromotedDate)
+	})
+
+	t.Run("Rules above the violation rate stay in shadow", func(t *testing.T) {
+		clock := services.NewFixedClock(start)
+		defer services.SetClock(clock)()
+		mockRepo := NewMockRuleRepository()
+		engine := NewComplianceRuleEngine(mockRepo, NewMockEventEmitter())
+		stub := setupMockStubForRuleEngine()
+		rule := newRolloutRule("ROLLOUT_RULE_002")
+		require.NoError(t, mockRepo.SaveRule(stub, rule))
+
+		execute(engine, stub, rule.RuleID, "LOAN_1", "SME", 500)
+		execute(engine, stub, rule.RuleID, "LOAN_2", "SME", 500)
+
+		clock.Advance(48 * time.Hour)
+		result := execute(engine, stub, rule.RuleID, "LOAN_3", "SME", 500)
+		assert.True(t, result.Passed)
+		assert.Equal(t, RolloutStatusShadow, rule.Rollout.Status)
+		assert.Equal(t, 1.0, rule.Rollout.ViolationRate())
+	})
+}
+
+func TestRuleRollout_Samples(t *testing.T) {
+	all := &RuleRollout{Percentage: 100}
+	none := &RuleRollout{Segments: []string{"SME"}}
+	half := &RuleRollout{Percentage: 50}
+
+	sampled := 0
+	for i := 0; i < 200; i++ {
+		entityID := fmt.Sprintf("CUST_%03d", i)
+		assert.True(t, all.Samples("RULE", entityID, ""))
+		assert.False(t, none.Samples("RULE", entityID, "RETAIL"))
+		assert.True(t, none.Samples("RULE", entityID, "SME"))
+
+		// The same entity is consistently in or out of the sample
+		inSample := half.Samples("RULE", entityID, "")
+		assert.Equal(t, inSample, half.Samples("RULE", entityID, ""))
+		if inSample {
+			sampled++
+		}
+	}
+	assert.InDelta(t, 100, sampled, 30)
+
+	assert.NotEmpty(t, (&RuleRollout{}).validate())
+	assert.NotEmpty(t, (&RuleRollout{Percentage: 101}).validate())
+	assert.NotEmpty(t, (&RuleRollout{Percentage: 10, MaxViolationRate: 1.5}).validate())
+	assert.Empty(t, (&RuleRollout{Percentage: 10, MaxViolationRate: 0.05}).validate())
+}
+
+func TestComplianceRuleEngine_ThresholdByCurrency(t *testing.T) {
+	engine := NewComplianceRuleEngine(NewMockRuleRepository(), NewMockEventEmitter())
+
+	var ruleLogic map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(`{"type": "threshold", "field": "requestedAmount", "threshold": 100000, "operator": "<=", "thresholdsByCurrency": {"EUR": 90000, "JPY": 15000000}}`), &ruleLogic))
+
+	tests := []struct {
+		name           string
+		entityData     map[string]interface{}
+		expectedPassed bool
+		expectedError  string
+	}{
+		{
+			name:           "Base currency uses the plain threshold",
+			entityData:     map[string]interface{}{"requestedAmount": 95000.0, "currency": "USD"},
+			expectedPassed: true,
+		},
+		{
+			name:           "Entities without a currency are in the base currency",
+			entityData:     map[string]interface{}{"requestedAmount": 95000.0},
+			expectedPassed: true,
+		},
+		{
+			name:           "Listed currency uses its own threshold",
+			entityData:     map[string]interface{}{"requestedAmount": 95000.0, "currency": "EUR"},
+			expectedPassed: false,
+		},
+		{
+			name:           "Threshold in a currency without minor units",
+			entityData:     map[string]interface{}{"requestedAmount": 12000000.0, "currency": "JPY"},
+			expectedPassed: true,
+		},
+		{
+			name:           "Unlisted currency fails",
+			entityData:     map[string]interface{}{"requestedAmount": 100.0, "currency": "GBP"},
+			expectedPassed: false,
+			expectedError:  "no threshold set for currency GBP",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			result, err := engine.executeThresholdRule(ruleLogic, tt.entityData, time.Now())
+			require.NoError(t, err)
+			assert.Equal(t, tt.expectedPassed, result.Passed)
+			if tt.expectedError != "" {
+				assert.Equal(t, tt.expectedError, result.Details["error"])
+			}
 		})
 	}
 }
@@ -713,7 +888,6 @@ func TestRuleExecutionResult_JSON(t *testing.T) {
 		Passed:        true,
 		Score:         0.95,
 		Details:       map[string]interface{}{"field": "value"},
-		ExecutionTime: 150,
 	}
 
 	// Test JSON marshaling
@@ -4,8 +4,10 @@ import (
 	"encoding/json"
 	"fmt"
 	"strings"
+	"unicode/utf8"
 
 	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
 )
 
 // FabricRuleRepository implements RuleRepository using Hyperledger Fabric state database
@@ -22,16 +24,16 @@ func (r *FabricRuleRepository) GetRule(stub shim.ChaincodeStubInterface, ruleID
 	
 	ruleBytes, err := stub.GetState(key)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get rule %s version %s: %v", ruleID, version, err)
+		return nil, fmt.Errorf("failed to get rule %s version %s: %w", ruleID, version, err)
 	}
 	
 	if ruleBytes == nil {
-		return nil, fmt.Errorf("rule %s version %s not found", ruleID, version)
+		return nil, services.NewChaincodeError(services.ErrCodeNotFound, "", "rule %s version %s not found", ruleID, version)
 	}
 	
 	var rule ComplianceRule
 	if err := json.Unmarshal(ruleBytes, &rule); err != nil {
-		return nil, fmt.Errorf("failed to unmarshal rule %s version %s: %v", ruleID, version, err)
+		return nil, fmt.Errorf("failed to unmarshal rule %s version %s: %w", ruleID, version, err)
 	}
 	
 	return &rule, nil
@@ -43,11 +45,11 @@ func (r *FabricRuleRepository) GetLatestRule(stub shim.ChaincodeStubInterface, r
 	latestKey := fmt.Sprintf("rule_latest~%s", ruleID)
 	versionBytes, err := stub.GetState(latestKey)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get latest version for rule %s: %v", ruleID, err)
+		return nil, fmt.Errorf("failed to get latest version for rule %s: %w", ruleID, err)
 	}
 	
 	if versionBytes == nil {
-		return nil, fmt.Errorf("rule %s not found", ruleID)
+		return nil, services.NewChaincodeError(services.ErrCodeNotFound, "", "rule %s not found", ruleID)
 	}
 	
 	version := string(versionBytes)
@@ -56,28 +58,40 @@ func (r *FabricRuleRepository) GetLatestRule(stub shim.ChaincodeStubInterface, r
 
 // GetActiveRules retrieves all currently active rules
 func (r *FabricRuleRepository) GetActiveRules(stub shim.ChaincodeStubInterface) ([]*ComplianceRule, error) {
-	// Use composite key query to get all rules
-	iterator, err := stub.GetStateByPartialCompositeKey("rule", []string{})
+	// Rules are stored under plain version keys, so find them through the status index
+	iterator, err := stub.GetStateByPartialCompositeKey("rule_status", []string{string(RuleStatusActive)})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get active rules: %v", err)
+		return nil, fmt.Errorf("failed to get active rules: %w", err)
 	}
 	defer iterator.Close()
 	
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
 	var activeRules []*ComplianceRule
 	
 	for iterator.HasNext() {
 		response, err := iterator.Next()
 		if err != nil {
-			return nil, fmt.Errorf("failed to iterate rules: %v", err)
+			return nil, fmt.Errorf("failed to iterate rules: %w", err)
 		}
 		
-		var rule ComplianceRule
-		if err := json.Unmarshal(response.Value, &rule); err != nil {
-			continue // Skip malformed rules
+		// Extract rule ID from composite key
+		_, compositeKeyParts, err := stub.SplitCompositeKey(response.Key)
+		if err != nil || len(compositeKeyParts) < 2 {
+			continue
 		}
 		
-		if rule.IsActive() {
-			activeRules = append(activeRules, &rule)
+		// The index keeps entries for earlier statuses, so check the latest version
+		rule, err := r.GetLatestRule(stub, compositeKeyParts[1])
+		if err != nil {
+			continue // Skip rules that can't be loaded
+		}
+		
+		if rule.IsActive(now) {
+			activeRules = append(activeRules, rule)
 		}
 	}
 	
@@ -89,16 +103,21 @@ func (r *FabricRuleRepository) GetRulesByDomain(stub shim.ChaincodeStubInterface
 	// Use composite key query
 	iterator, err := stub.GetStateByPartialCompositeKey("rule_domain", []string{domain})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get rules by domain %s: %v", domain, err)
+		return nil, fmt.Errorf("failed to get rules by domain %s: %w", domain, err)
 	}
 	defer iterator.Close()
 	
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
 	var rules []*ComplianceRule
 	
 	for iterator.HasNext() {
 		response, err := iterator.Next()
 		if err != nil {
-			return nil, fmt.Errorf("failed to iterate domain rules: %v", err)
+			return nil, fmt.Errorf("failed to iterate domain rules: %w", err)
 		}
 		
 		// Extract rule ID from composite key
@@ -113,7 +132,7 @@ func (r *FabricRuleRepository) GetRulesByDomain(stub shim.ChaincodeStubInterface
 			continue // Skip rules that can't be loaded
 		}
 		
-		if rule.IsActive() {
+		if rule.IsActive(now) {
 			rules = append(rules, rule)
 		}
 	}
@@ -126,16 +145,21 @@ func (r *FabricRuleRepository) GetRulesByEntityType(stub shim.ChaincodeStubInter
 	// Use composite key query
 	iterator, err := stub.GetStateByPartialCompositeKey("rule_entity", []string{entityType})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get rules by entity type %s: %v", entityType, err)
+		return nil, fmt.Errorf("failed to get rules by entity type %s: %w", entityType, err)
 	}
 	defer iterator.Close()
 	
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
 	var rules []*ComplianceRule
 	
 	for iterator.HasNext() {
 		response, err := iterator.Next()
 		if err != nil {
-			return nil, fmt.Errorf("failed to iterate entity rules: %v", err)
+			return nil, fmt.Errorf("failed to iterate entity rules: %w", err)
 		}
 		
 		// Extract rule ID from composite key
@@ -150,7 +174,7 @@ func (r *FabricRuleRepository) GetRulesByEntityType(stub shim.ChaincodeStubInter
 			continue // Skip rules that can't be loaded
 		}
 		
-		if rule.IsActive() {
+		if rule.IsActive(now) {
 			rules = append(rules, rule)
 		}
 	}
@@ -163,16 +187,21 @@ func (r *FabricRuleRepository) GetRulesByEvent(stub shim.ChaincodeStubInterface,
 	// Use composite key query
 	iterator, err := stub.GetStateByPartialCompositeKey("rule_event", []string{eventType})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get rules by event %s: %v", eventType, err)
+		return nil, fmt.Errorf("failed to get rules by event %s: %w", eventType, err)
 	}
 	defer iterator.Close()
 	
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
 	var rules []*ComplianceRule
 	
 	for iterator.HasNext() {
 		response, err := iterator.Next()
 		if err != nil {
-			return nil, fmt.Errorf("failed to iterate event rules: %v", err)
+			return nil, fmt.Errorf("failed to iterate event rules: %w", err)
 		}
 		
 		// Extract rule ID from composite key
@@ -187,7 +216,7 @@ func (r *FabricRuleRepository) GetRulesByEvent(stub shim.ChaincodeStubInterface,
 			continue // Skip rules that can't be loaded
 		}
 		
-		if rule.IsActive() {
+		if rule.IsActive(now) {
 			rules = append(rules, rule)
 		}
 	}
@@ -198,13 +227,23 @@ func (r *FabricRuleRepository) GetRulesByEvent(stub shim.ChaincodeStubInterface,
 // SaveRule saves a new rule or updates an existing rule
 func (r *FabricRuleRepository) SaveRule(stub shim.ChaincodeStubInterface, rule *ComplianceRule) error {
 	// Validate rule before saving
-	validationResults := rule.Validate()
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return err
+	}
+	validationResults := rule.Validate(now)
 	for _, result := range validationResults {
 		if !result.IsValid {
 			return fmt.Errorf("rule validation failed: %v", result.ErrorMessages)
 		}
 	}
 	
+	// Every save advances the rule's revision so updates can detect concurrent changes
+	rule.Revision = 1
+	if latest, err := r.GetLatestRule(stub, rule.RuleID); err == nil {
+		rule.Revision = latest.Revision + 1
+	}
+	
 	// Save the rule with version
 	if err := r.SaveRuleVersion(stub, rule); err != nil {
 		return err
@@ -213,12 +252,12 @@ func (r *FabricRuleRepository) SaveRule(stub shim.ChaincodeStubInterface, rule *
 	// Update latest version pointer
 	latestKey := rule.GetLatestVersionKey()
 	if err := stub.PutState(latestKey, []byte(rule.Version)); err != nil {
-		return fmt.Errorf("failed to update latest version pointer: %v", err)
+		return fmt.Errorf("failed to update latest version pointer: %w", err)
 	}
 	
 	// Create index entries for efficient querying
 	if err := r.createIndexEntries(stub, rule); err != nil {
-		return fmt.Errorf("failed to create index entries: %v", err)
+		return fmt.Errorf("failed to create index entries: %w", err)
 	}
 	
 	return nil
@@ -228,12 +267,12 @@ func (r *FabricRuleRepository) SaveRule(stub shim.ChaincodeStubInterface, rule *
 func (r *FabricRuleRepository) SaveRuleVersion(stub shim.ChaincodeStubInterface, rule *ComplianceRule) error {
 	ruleBytes, err := json.Marshal(rule)
 	if err != nil {
-		return fmt.Errorf("failed to marshal rule: %v", err)
+		return fmt.Errorf("failed to marshal rule: %w", err)
 	}
 	
 	key := rule.GetCompositeKey()
 	if err := stub.PutState(key, ruleBytes); err != nil {
-		return fmt.Errorf("failed to save rule version: %v", err)
+		return fmt.Errorf("failed to save rule version: %w", err)
 	}
 	
 	return nil
@@ -245,10 +284,10 @@ func (r *FabricRuleRepository) createIndexEntries(stub shim.ChaincodeStubInterfa
 	if rule.AppliesToDomain != "" {
 		domainKey, err := stub.CreateCompositeKey("rule_domain", []string{rule.AppliesToDomain, rule.RuleID})
 		if err != nil {
-			return fmt.Errorf("failed to create domain composite key: %v", err)
+			return fmt.Errorf("failed to create domain composite key: %w", err)
 		}
-		if err := stub.PutState(domainKey, []byte{}); err != nil {
-			return fmt.Errorf("failed to save domain index: %v", err)
+		if err := stub.PutState(domainKey, []byte(rule.RuleID)); err != nil {
+			return fmt.Errorf("failed to save domain index: %w", err)
 		}
 	}
 	
@@ -256,10 +295,10 @@ func (r *FabricRuleRepository) createIndexEntries(stub shim.ChaincodeStubInterfa
 	if rule.AppliesToEntityType != "" {
 		entityKey, err := stub.CreateCompositeKey("rule_entity", []string{rule.AppliesToEntityType, rule.RuleID})
 		if err != nil {
-			return fmt.Errorf("failed to create entity composite key: %v", err)
+			return fmt.Errorf("failed to create entity composite key: %w", err)
 		}
-		if err := stub.PutState(entityKey, []byte{}); err != nil {
-			return fmt.Errorf("failed to save entity index: %v", err)
+		if err := stub.PutState(entityKey, []byte(rule.RuleID)); err != nil {
+			return fmt.Errorf("failed to save entity index: %w", err)
 		}
 	}
 	
@@ -267,29 +306,29 @@ func (r *FabricRuleRepository) createIndexEntries(stub shim.ChaincodeStubInterfa
 	for _, event := range rule.TriggerEvents {
 		eventKey, err := stub.CreateCompositeKey("rule_event", []string{event, rule.RuleID})
 		if err != nil {
-			return fmt.Errorf("failed to create event composite key: %v", err)
+			return fmt.Errorf("failed to create event composite key: %w", err)
 		}
-		if err := stub.PutState(eventKey, []byte{}); err != nil {
-			return fmt.Errorf("failed to save event index: %v", err)
+		if err := stub.PutState(eventKey, []byte(rule.RuleID)); err != nil {
+			return fmt.Errorf("failed to save event index: %w", err)
 		}
 	}
 	
 	// Status index
 	statusKey, err := stub.CreateCompositeKey("rule_status", []string{string(rule.Status), rule.RuleID})
 	if err != nil {
-		return fmt.Errorf("failed to create status composite key: %v", err)
+		return fmt.Errorf("failed to create status composite key: %w", err)
 	}
-	if err := stub.PutState(statusKey, []byte{}); err != nil {
-		return fmt.Errorf("failed to save status index: %v", err)
+	if err := stub.PutState(statusKey, []byte(rule.RuleID)); err != nil {
+		return fmt.Errorf("failed to save status index: %w", err)
 	}
 	
 	// Priority index
 	priorityKey, err := stub.CreateCompositeKey("rule_priority", []string{string(rule.Priority), rule.RuleID})
 	if err != nil {
-		return fmt.Errorf("failed to create priority composite key: %v", err)
+		return fmt.Errorf("failed to create priority composite key: %w", err)
 	}
-	if err := stub.PutState(priorityKey, []byte{}); err != nil {
-		return fmt.Errorf("failed to save priority index: %v", err)
+	if err := stub.PutState(priorityKey, []byte(rule.RuleID)); err != nil {
+		return fmt.Errorf("failed to save priority index: %w", err)
 	}
 	
 	return nil
@@ -299,7 +338,7 @@ func (r *FabricRuleRepository) createIndexEntries(stub shim.ChaincodeStubInterfa
 func (r *FabricRuleRepository) GetRulesByStatus(stub shim.ChaincodeStubInterface, status ComplianceRuleStatus) ([]*ComplianceRule, error) {
 	iterator, err := stub.GetStateByPartialCompositeKey("rule_status", []string{string(status)})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get rules by status %s: %v", status, err)
+		return nil, fmt.Errorf("failed to get rules by status %s: %w", status, err)
 	}
 	defer iterator.Close()
 	
@@ -308,7 +347,7 @@ func (r *FabricRuleRepository) GetRulesByStatus(stub shim.ChaincodeStubInterface
 	for iterator.HasNext() {
 		response, err := iterator.Next()
 		if err != nil {
-			return nil, fmt.Errorf("failed to iterate status rules: %v", err)
+			return nil, fmt.Errorf("failed to iterate status rules: %w", err)
 		}
 		
 		// Extract rule ID from composite key
@@ -333,7 +372,7 @@ func (r *FabricRuleRepository) GetRulesByStatus(stub shim.ChaincodeStubInterface
 func (r *FabricRuleRepository) GetRulesByPriority(stub shim.ChaincodeStubInterface, priority ComplianceRulePriority) ([]*ComplianceRule, error) {
 	iterator, err := stub.GetStateByPartialCompositeKey("rule_priority", []string{string(priority)})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get rules by priority %s: %v", priority, err)
+		return nil, fmt.Errorf("failed to get rules by priority %s: %w", priority, err)
 	}
 	defer iterator.Close()
 	
@@ -342,7 +381,7 @@ func (r *FabricRuleRepository) GetRulesByPriority(stub shim.ChaincodeStubInterfa
 	for iterator.HasNext() {
 		response, err := iterator.Next()
 		if err != nil {
-			return nil, fmt.Errorf("failed to iterate priority rules: %v", err)
+			return nil, fmt.Errorf("failed to iterate priority rules: %w", err)
 		}
 		
 		// Extract rule ID from composite key
@@ -365,9 +404,11 @@ func (r *FabricRuleRepository) GetRulesByPriority(stub shim.ChaincodeStubInterfa
 
 // GetRuleHistory retrieves the version history of a rule
 func (r *FabricRuleRepository) GetRuleHistory(stub shim.ChaincodeStubInterface, ruleID string) ([]*ComplianceRule, error) {
-	iterator, err := stub.GetStateByPartialCompositeKey("rule", []string{ruleID})
+	// Versions are stored under plain "rule~<id>~<version>" keys, see GetCompositeKey
+	prefix := fmt.Sprintf("rule~%s~", ruleID)
+	iterator, err := stub.GetStateByRange(prefix, prefix+string(utf8.MaxRune))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get rule history for %s: %v", ruleID, err)
+		return nil, fmt.Errorf("failed to get rule history for %s: %w", ruleID, err)
 	}
 	defer iterator.Close()
 	
@@ -376,7 +417,7 @@ func (r *FabricRuleRepository) GetRuleHistory(stub shim.ChaincodeStubInterface,
 	for iterator.HasNext() {
 		response, err := iterator.Next()
 		if err != nil {
-			return nil, fmt.Errorf("failed to iterate rule versions: %v", err)
+			return nil, fmt.Errorf("failed to iterate rule versions: %w", err)
 		}
 		
 		var rule ComplianceRule
@@ -394,12 +435,16 @@ func (r *FabricRuleRepository) GetRuleHistory(stub shim.ChaincodeStubInterface,
 func (r *FabricRuleRepository) DeleteRule(stub shim.ChaincodeStubInterface, ruleID string) error {
 	rule, err := r.GetLatestRule(stub, ruleID)
 	if err != nil {
-		return fmt.Errorf("failed to get rule for deletion: %v", err)
+		return fmt.Errorf("failed to get rule for deletion: %w", err)
 	}
 	
 	// Mark as deprecated instead of hard delete
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return err
+	}
 	rule.Status = RuleStatusDeprecated
-	rule.LastModifiedDate = rule.LastModifiedDate
+	rule.LastModifiedDate = now
 	
 	return r.SaveRule(stub, rule)
 }
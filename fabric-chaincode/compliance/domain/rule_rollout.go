@@ -0,0 +1,128 @@
+package domain
+
+import (
+	"fmt"
+	"hash/fnv"
+	"time"
+)
+
+// RuleRolloutStatus tracks how far a gradually rolled out rule has progressed
+type RuleRolloutStatus string
+
+const (
+	// RolloutStatusShadow rules are evaluated and logged for their sample but never fail an execution
+	RolloutStatusShadow RuleRolloutStatus = "SHADOW"
+	// RolloutStatusEnforced rules are evaluated and enforced for all traffic
+	RolloutStatusEnforced RuleRolloutStatus = "ENFORCED"
+)
+
+// DefaultRolloutObservationWindow applies when a rollout does not set its own window
+const DefaultRolloutObservationWindow = 7 * 24 * time.Hour
+
+// RuleRollout configures the gradual rollout of a newly activated rule. While in shadow the rule is
+// evaluated for a sample of entities, chosen by percentage or segment, and its outcomes are logged
+// without being enforced. Once the observation window has passed with a violation rate at or below
+// the maximum, the next execution promotes the rule to full enforcement.
+type RuleRollout struct {
+	Status                 RuleRolloutStatus `json:"status"`
+	Percentage             int               `json:"percentage,omitempty"`             // Share of entities (0-100) sampled while in shadow
+	Segments               []string          `json:"segments,omitempty"`               // Entity segments always sampled while in shadow
+	ObservationWindowHours int               `json:"observationWindowHours,omitempty"` // Defaults to DefaultRolloutObservationWindow
+	MaxViolationRate       float64           `json:"maxViolationRate"`                 // Highest violation rate (0-1) that still allows promotion
+	StartDate              time.Time         `json:"startDate"`
+	Evaluations            int64             `json:"evaluations"`
+	Violations             int64             `json:"violations"`
+	PromotedDate           *time.Time        `json:"promotedDate,omitempty"`
+}
+
+// Start puts the rollout into shadow from the given time, clearing any earlier observations
+func (ro *RuleRollout) Start(now time.Time) {
+	ro.Status = RolloutStatusShadow
+	ro.StartDate = now
+	ro.Evaluations = 0
+	ro.Violations = 0
+	ro.PromotedDate = nil
+}
+
+// InShadow reports whether the rule is still being observed rather than enforced
+func (ro *RuleRollout) InShadow() bool {
+	return ro.Status == RolloutStatusShadow
+}
+
+// Samples reports whether an entity falls in the shadow sample of a rule. Segment matches are
+// always sampled; otherwise the entity is bucketed by a hash of the rule and entity IDs so the
+// same entity is consistently in or out of the sample.
+func (ro *RuleRollout) Samples(ruleID, entityID, segment string) bool {
+	for _, s := range ro.Segments {
+		if segment != "" && s == segment {
+			return true
+		}
+	}
+	if ro.Percentage <= 0 {
+		return false
+	}
+	h := fnv.New32a()
+	h.Write([]byte(ruleID + ":" + entityID))
+	return int(h.Sum32()%100) < ro.Percentage
+}
+
+// RecordEvaluation counts a shadow evaluation and whether it found a violation
+func (ro *RuleRollout) RecordEvaluation(passed bool) {
+	ro.Evaluations++
+	if !passed {
+		ro.Violations++
+	}
+}
+
+// ViolationRate returns the share of shadow evaluations that found a violation
+func (ro *RuleRollout) ViolationRate() float64 {
+	if ro.Evaluations == 0 {
+		return 0
+	}
+	return float64(ro.Violations) / float64(ro.Evaluations)
+}
+
+// ObservationWindow returns how long the rule stays in shadow before it may be promoted
+func (ro *RuleRollout) ObservationWindow() time.Duration {
+	if ro.ObservationWindowHours > 0 {
+		return time.Duration(ro.ObservationWindowHours) * time.Hour
+	}
+	return DefaultRolloutObservationWindow
+}
+
+// ReadyForPromotion reports whether a shadow rule has been observed for its full window, on at
+// least one evaluation, with an acceptable violation rate. Rules that exceed the rate stay in
+// shadow for compliance officers to review.
+func (ro *RuleRollout) ReadyForPromotion(now time.Time) bool {
+	return ro.InShadow() &&
+		!now.Before(ro.StartDate.Add(ro.ObservationWindow())) &&
+		ro.Evaluations > 0 &&
+		ro.ViolationRate() <= ro.MaxViolationRate
+}
+
+// Promote moves the rule to full enforcement
+func (ro *RuleRollout) Promote(now time.Time) {
+	ro.Status = RolloutStatusEnforced
+	ro.PromotedDate = &now
+}
+
+// validate returns the problems with a rollout configuration
+func (ro *RuleRollout) validate() []string {
+	var errors []string
+	if ro.Status != "" && ro.Status != RolloutStatusShadow && ro.Status != RolloutStatusEnforced {
+		errors = append(errors, fmt.Sprintf("Invalid rollout status: %s", ro.Status))
+	}
+	if ro.Percentage < 0 || ro.Percentage > 100 {
+		errors = append(errors, "Rollout percentage must be between 0 and 100")
+	}
+	if ro.Percentage == 0 && len(ro.Segments) == 0 {
+		errors = append(errors, "Rollout must sample a percentage of traffic or at least one segment")
+	}
+	if ro.ObservationWindowHours < 0 {
+		errors = append(errors, "Rollout observation window cannot be negative")
+	}
+	if ro.MaxViolationRate < 0 || ro.MaxViolationRate > 1 {
+		errors = append(errors, "Rollout maximum violation rate must be between 0 and 1")
+	}
+	return errors
+}
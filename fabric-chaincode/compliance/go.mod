@@ -1,9 +1,10 @@
-module github.com/blockchain-financial-platform/fabric-chaincode/compliance
+module github.com/brycemacchaveli/origin.block/fabric-chaincode/compliance
 
 go 1.23
 
 require (
-	github.com/blockchain-financial-platform/fabric-chaincode/shared v0.0.0
+	github.com/brycemacchaveli/origin.block/fabric-chaincode/shared v0.0.0
+	github.com/golang/protobuf v1.5.2
 	github.com/hyperledger/fabric-chaincode-go v0.0.0-20220920210243-7bc6fa0dd58b
 	github.com/hyperledger/fabric-protos-go v0.0.0-20220827195505-ce4c067a561d
 	github.com/stretchr/testify v1.8.4
@@ -11,7 +12,6 @@ require (
 
 require (
 	github.com/davecgh/go-spew v1.1.1 // indirect
-	github.com/golang/protobuf v1.5.2 // indirect
 	github.com/pmezard/go-difflib v1.0.0 // indirect
 	golang.org/x/net v0.0.0-20220708220712-1185a9018129 // indirect
 	golang.org/x/sys v0.26.0 // indirect
@@ -22,4 +22,4 @@ require (
 	gopkg.in/yaml.v3 v3.0.1 // indirect
 )
 
-replace github.com/blockchain-financial-platform/fabric-chaincode/shared => ../shared
+replace github.com/brycemacchaveli/origin.block/fabric-chaincode/shared => ../shared
@@ -0,0 +1,517 @@
+package handlers
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/compliance/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// adverseMediaSeverityWeights scales a match's confidence into its contribution to the AML risk
+// score by the severity of the reported conduct
+var adverseMediaSeverityWeights = map[RiskLevel]float64{
+	RiskLevelLow:      0.25,
+	RiskLevelMedium:   0.5,
+	RiskLevelHigh:     0.75,
+	RiskLevelCritical: 1.0,
+}
+
+// AdverseMediaManager maintains the registry of adverse media records screened by AML checks.
+// Only a hash of each headline is kept on the ledger; the article itself stays with the source.
+type AdverseMediaManager struct {
+	persistenceService *services.PersistenceService
+	eventEmitter       domain.EventEmitter
+}
+
+// NewAdverseMediaManager creates a new adverse media manager
+func NewAdverseMediaManager(eventEmitter domain.EventEmitter) *AdverseMediaManager {
+	return &AdverseMediaManager{
+		persistenceService: services.NewPersistenceService(),
+		eventEmitter:       eventEmitter,
+	}
+}
+
+// AdverseMediaRecord represents a published report of adverse conduct by an entity
+type AdverseMediaRecord struct {
+	RecordID           string    `json:"recordID"`
+	EntityID           string    `json:"entityID,omitempty"` // Customer the report is known to concern
+	EntityName         string    `json:"entityName"`
+	Aliases            []string  `json:"aliases,omitempty"`
+	Source             string    `json:"source"`
+	HeadlineHash       string    `json:"headlineHash"` // Hex SHA-256 of the headline
+	Category           string    `json:"category,omitempty"`
+	Severity           RiskLevel `json:"severity"`
+	PublicationDate    time.Time `json:"publicationDate"`
+	IsActive           bool      `json:"isActive"`
+	DeactivationReason string    `json:"deactivationReason,omitempty"`
+	CreatedBy          string    `json:"createdBy"`
+	CreatedDate        time.Time `json:"createdDate"`
+	LastUpdatedBy      string    `json:"lastUpdatedBy"`
+	LastUpdated        time.Time `json:"lastUpdated"`
+}
+
+// AdverseMediaRecordRequest represents a request to add an adverse media record
+type AdverseMediaRecordRequest struct {
+	RecordID        string    `json:"recordID,omitempty"` // Generated when empty
+	EntityID        string    `json:"entityID,omitempty"`
+	EntityName      string    `json:"entityName"`
+	Aliases         []string  `json:"aliases,omitempty"`
+	Source          string    `json:"source"`
+	HeadlineHash    string    `json:"headlineHash"`
+	Category        string    `json:"category,omitempty"`
+	Severity        string    `json:"severity"`
+	PublicationDate time.Time `json:"publicationDate"`
+	ActorID         string    `json:"actorID"`
+}
+
+// AdverseMediaDeactivationRequest represents a request to withdraw an adverse media record
+type AdverseMediaDeactivationRequest struct {
+	RecordID string `json:"recordID"`
+	Reason   string `json:"reason"`
+	ActorID  string `json:"actorID"`
+}
+
+// AdverseMediaScreenResult represents adverse media screening results
+type AdverseMediaScreenResult struct {
+	IsMatch          bool                `json:"isMatch"`
+	Matches          []AdverseMediaMatch `json:"matches"`
+	HighestSeverity  RiskLevel           `json:"highestSeverity,omitempty"`
+	RiskContribution float64             `json:"riskContribution"` // 0-1, confidence weighted by severity
+	ScreeningDate    time.Time           `json:"screeningDate"`
+}
+
+// AdverseMediaMatch represents an adverse media record matched to the screened customer
+type AdverseMediaMatch struct {
+	RecordID        string    `json:"recordID"`
+	MatchedName     string    `json:"matchedName"`
+	MatchedOn       string    `json:"matchedOn"` // ENTITY_ID or NAME
+	Source          string    `json:"source"`
+	HeadlineHash    string    `json:"headlineHash"`
+	Category        string    `json:"category,omitempty"`
+	Severity        RiskLevel `json:"severity"`
+	PublicationDate time.Time `json:"publicationDate"`
+	Confidence      float64   `json:"confidence"`
+}
+
+// AddAdverseMediaRecord adds an adverse media record to the registry
+func (m *AdverseMediaManager) AddAdverseMediaRecord(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req AdverseMediaRecordRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse adverse media record request: %v", err)
+	}
+
+	if err := m.requireMediaMaintainer(stub); err != nil {
+		return nil, err
+	}
+
+	record, err := m.putAdverseMediaRecord(stub, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.recordAdverseMediaEvent(stub, "ADVERSE_MEDIA_RECORD_ADDED", record.RecordID, map[string]interface{}{
+		"recordID": record.RecordID,
+		"entityID": record.EntityID,
+		"severity": record.Severity,
+		"source":   record.Source,
+	}, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to record adverse media event: %w", err)
+	}
+
+	return json.Marshal(record)
+}
+
+// DeactivateAdverseMediaRecord withdraws a record from screening, for example after a retraction.
+// The record is kept for the audit trail.
+func (m *AdverseMediaManager) DeactivateAdverseMediaRecord(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req AdverseMediaDeactivationRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse adverse media deactivation request: %v", err)
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "reason", "reason is required")
+	}
+
+	if err := m.requireMediaMaintainer(stub); err != nil {
+		return nil, err
+	}
+
+	var record AdverseMediaRecord
+	if err := m.persistenceService.Get(stub, fmt.Sprintf("ADVERSE_MEDIA_%s", req.RecordID), &record); err != nil {
+		return nil, fmt.Errorf("adverse media record not found: %w", err)
+	}
+	if !record.IsActive {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "adverse media record %s is already inactive", req.RecordID)
+	}
+
+	// Inactive records drop out of the name index so screening no longer finds them
+	for _, prefix := range adverseMediaRecordPrefixes(&record) {
+		if err := m.deleteAdverseMediaIndex(stub, "ADVERSE_MEDIA_BY_NAME", prefix, record.RecordID); err != nil {
+			return nil, err
+		}
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	record.IsActive = false
+	record.DeactivationReason = req.Reason
+	record.LastUpdatedBy = req.ActorID
+	record.LastUpdated = now
+	if err := m.persistenceService.Put(stub, fmt.Sprintf("ADVERSE_MEDIA_%s", record.RecordID), &record); err != nil {
+		return nil, fmt.Errorf("failed to update adverse media record: %w", err)
+	}
+
+	if err := m.recordAdverseMediaEvent(stub, "ADVERSE_MEDIA_RECORD_DEACTIVATED", record.RecordID, map[string]interface{}{
+		"recordID": record.RecordID,
+		"reason":   req.Reason,
+	}, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to record adverse media event: %w", err)
+	}
+
+	return json.Marshal(&record)
+}
+
+// GetAdverseMediaRecord retrieves an adverse media record by ID
+func (m *AdverseMediaManager) GetAdverseMediaRecord(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var record AdverseMediaRecord
+	if err := m.persistenceService.Get(stub, fmt.Sprintf("ADVERSE_MEDIA_%s", args[0]), &record); err != nil {
+		return nil, fmt.Errorf("adverse media record not found: %w", err)
+	}
+
+	return json.Marshal(&record)
+}
+
+// QueryAdverseMediaByEntity retrieves the adverse media records known to concern an entity,
+// active and inactive, newest first
+func (m *AdverseMediaManager) QueryAdverseMediaByEntity(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	records, err := m.entityRecords(stub, args[0], false)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(records)
+}
+
+// Helper methods
+
+// putAdverseMediaRecord validates and stores a new record with its index keys
+func (m *AdverseMediaManager) putAdverseMediaRecord(stub shim.ChaincodeStubInterface, req *AdverseMediaRecordRequest) (*AdverseMediaRecord, error) {
+	if err := m.validateAdverseMediaRecordRequest(req); err != nil {
+		return nil, err
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &AdverseMediaRecord{
+		RecordID:        req.RecordID,
+		EntityID:        strings.TrimSpace(req.EntityID),
+		EntityName:      strings.TrimSpace(req.EntityName),
+		Aliases:         req.Aliases,
+		Source:          req.Source,
+		HeadlineHash:    strings.ToLower(req.HeadlineHash),
+		Category:        strings.ToUpper(req.Category),
+		Severity:        RiskLevel(strings.ToUpper(req.Severity)),
+		PublicationDate: req.PublicationDate,
+		IsActive:        true,
+		CreatedBy:       req.ActorID,
+		CreatedDate:     now,
+		LastUpdatedBy:   req.ActorID,
+		LastUpdated:     now,
+	}
+	if record.RecordID == "" {
+		record.RecordID = services.GenerateDeterministicID(stub, config.AdverseMediaPrefix)
+	}
+
+	// Records are immutable once published to the registry; corrections are a new record
+	// and a deactivation of the old one
+	var existing AdverseMediaRecord
+	if err := m.persistenceService.Get(stub, fmt.Sprintf("ADVERSE_MEDIA_%s", record.RecordID), &existing); err == nil {
+		return nil, fmt.Errorf("adverse media record %s already exists", record.RecordID)
+	}
+
+	if err := m.persistenceService.Put(stub, fmt.Sprintf("ADVERSE_MEDIA_%s", record.RecordID), record); err != nil {
+		return nil, fmt.Errorf("failed to store adverse media record: %w", err)
+	}
+	for _, prefix := range adverseMediaRecordPrefixes(record) {
+		if err := m.putAdverseMediaIndex(stub, "ADVERSE_MEDIA_BY_NAME", prefix, record.RecordID); err != nil {
+			return nil, err
+		}
+	}
+	if record.EntityID != "" {
+		if err := m.putAdverseMediaIndex(stub, "ADVERSE_MEDIA_BY_ENTITY", record.EntityID, record.RecordID); err != nil {
+			return nil, err
+		}
+	}
+
+	return record, nil
+}
+
+// entityRecords returns the records known to concern an entity, newest first
+func (m *AdverseMediaManager) entityRecords(stub shim.ChaincodeStubInterface, entityID string, activeOnly bool) ([]AdverseMediaRecord, error) {
+	iterator, err := stub.GetStateByPartialCompositeKey("ADVERSE_MEDIA_BY_ENTITY", []string{entityID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query adverse media by entity: %w", err)
+	}
+	defer iterator.Close()
+
+	records := []AdverseMediaRecord{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate adverse media records: %w", err)
+		}
+
+		var record AdverseMediaRecord
+		if err := m.persistenceService.Get(stub, fmt.Sprintf("ADVERSE_MEDIA_%s", string(response.Value)), &record); err != nil {
+			continue // Skip if record not found
+		}
+		if activeOnly && !record.IsActive {
+			continue
+		}
+		records = append(records, record)
+	}
+
+	sort.SliceStable(records, func(i, j int) bool { return records[i].PublicationDate.After(records[j].PublicationDate) })
+	return records, nil
+}
+
+// candidateRecords returns the active records sharing a name prefix with the screened name
+func (m *AdverseMediaManager) candidateRecords(stub shim.ChaincodeStubInterface, name string) ([]AdverseMediaRecord, error) {
+	seen := map[string]bool{}
+	candidates := []AdverseMediaRecord{}
+
+	for _, prefix := range pepNamePrefixes(name) {
+		iterator, err := stub.GetStateByPartialCompositeKey("ADVERSE_MEDIA_BY_NAME", []string{prefix})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query adverse media name index: %w", err)
+		}
+
+		for iterator.HasNext() {
+			response, err := iterator.Next()
+			if err != nil {
+				iterator.Close()
+				return nil, fmt.Errorf("failed to iterate adverse media name index: %w", err)
+			}
+
+			recordID := string(response.Value)
+			if seen[recordID] {
+				continue
+			}
+			seen[recordID] = true
+
+			var record AdverseMediaRecord
+			if err := m.persistenceService.Get(stub, fmt.Sprintf("ADVERSE_MEDIA_%s", recordID), &record); err != nil {
+				continue // Skip if record not found
+			}
+			if record.IsActive {
+				candidates = append(candidates, record)
+			}
+		}
+		iterator.Close()
+	}
+
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].RecordID < candidates[j].RecordID })
+	return candidates, nil
+}
+
+func (m *AdverseMediaManager) putAdverseMediaIndex(stub shim.ChaincodeStubInterface, objectType, value, recordID string) error {
+	indexKey, err := stub.CreateCompositeKey(objectType, []string{value, recordID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := stub.PutState(indexKey, []byte(recordID)); err != nil {
+		return fmt.Errorf("failed to create %s index: %w", objectType, err)
+	}
+	return nil
+}
+
+func (m *AdverseMediaManager) deleteAdverseMediaIndex(stub shim.ChaincodeStubInterface, objectType, value, recordID string) error {
+	indexKey, err := stub.CreateCompositeKey(objectType, []string{value, recordID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := stub.DelState(indexKey); err != nil {
+		return fmt.Errorf("failed to remove %s index: %w", objectType, err)
+	}
+	return nil
+}
+
+// adverseMediaRecordPrefixes returns the name index keys of a record's entity name and aliases
+func adverseMediaRecordPrefixes(record *AdverseMediaRecord) []string {
+	seen := map[string]bool{}
+	prefixes := []string{}
+	for _, name := range append([]string{record.EntityName}, record.Aliases...) {
+		for _, prefix := range pepNamePrefixes(name) {
+			if !seen[prefix] {
+				seen[prefix] = true
+				prefixes = append(prefixes, prefix)
+			}
+		}
+	}
+	return prefixes
+}
+
+// requireMediaMaintainer restricts adverse media registry changes to compliance officers
+func (m *AdverseMediaManager) requireMediaMaintainer(stub shim.ChaincodeStubInterface) error {
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return err
+	}
+	if role != string(validation.ActorRoleComplianceOfficer) && role != string(validation.ActorRoleChiefComplianceOfficer) {
+		return services.NewChaincodeError(services.ErrCodeAccessDenied, "", "the adverse media registry may only be maintained by a %s or %s", validation.ActorRoleComplianceOfficer, validation.ActorRoleChiefComplianceOfficer)
+	}
+	return nil
+}
+
+func (m *AdverseMediaManager) validateAdverseMediaRecordRequest(req *AdverseMediaRecordRequest) error {
+	if strings.TrimSpace(req.EntityName) == "" {
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "entityName", "entityName is required")
+	}
+	if strings.TrimSpace(req.Source) == "" {
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "source", "source is required")
+	}
+	if hash, err := hex.DecodeString(req.HeadlineHash); err != nil || len(hash) != 32 {
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "headlineHash must be a hex-encoded SHA-256 digest")
+	}
+	if req.PublicationDate.IsZero() {
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "publicationDate", "publicationDate is required")
+	}
+
+	if _, ok := adverseMediaSeverityWeights[RiskLevel(strings.ToUpper(req.Severity))]; !ok {
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid severity %q: expected LOW, MEDIUM, HIGH or CRITICAL", req.Severity)
+	}
+
+	return nil
+}
+
+func (m *AdverseMediaManager) recordAdverseMediaEvent(stub shim.ChaincodeStubInterface, eventType, entityID string, details map[string]interface{}, actorID string) error {
+	if m.eventEmitter == nil {
+		return nil // No event emitter configured
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return err
+	}
+
+	event := &domain.ComplianceEvent{
+		EventID:            services.GenerateDeterministicID(stub, config.EventPrefix),
+		Timestamp:          now,
+		RuleID:             "ADVERSE_MEDIA_MANAGEMENT_RULE",
+		RuleVersion:        "1.0",
+		AffectedEntityID:   entityID,
+		AffectedEntityType: "AdverseMedia",
+		EventType:          eventType,
+		Severity:           domain.PriorityMedium,
+		Details:            details,
+		ActorID:            actorID,
+		IsAlerted:          false,
+		ResolutionStatus:   "CLOSED",
+	}
+
+	return m.eventEmitter.EmitComplianceEvent(stub, event)
+}
+
+// performAdverseMediaScreening matches a customer against the adverse media registry: records
+// naming the customer's ID match outright, others on the closest of their entity name and aliases
+func (h *AMLCheckHandler) performAdverseMediaScreening(stub shim.ChaincodeStubInterface, customerID string, customerData *CustomerAMLData) (AdverseMediaScreenResult, error) {
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return AdverseMediaScreenResult{}, err
+	}
+
+	result := AdverseMediaScreenResult{
+		IsMatch:       false,
+		Matches:       []AdverseMediaMatch{},
+		ScreeningDate: now,
+	}
+
+	matched := map[string]bool{}
+	if customerID != "" {
+		records, err := h.adverseMediaManager.entityRecords(stub, customerID, true)
+		if err != nil {
+			return result, err
+		}
+		for _, record := range records {
+			matched[record.RecordID] = true
+			result.Matches = append(result.Matches, newAdverseMediaMatch(&record, "ENTITY_ID", 1.0))
+		}
+	}
+
+	fullName := strings.TrimSpace(fmt.Sprintf("%s %s", customerData.FirstName, customerData.LastName))
+	candidates, err := h.adverseMediaManager.candidateRecords(stub, fullName)
+	if err != nil {
+		return result, fmt.Errorf("failed to get adverse media records: %w", err)
+	}
+	for _, record := range candidates {
+		if matched[record.RecordID] {
+			continue
+		}
+		confidence := h.calculateNameMatchConfidence(fullName, record.EntityName)
+		for _, alias := range record.Aliases {
+			if aliasConfidence := h.calculateNameMatchConfidence(fullName, alias); aliasConfidence > confidence {
+				confidence = aliasConfidence
+			}
+		}
+		if confidence >= 0.8 { // 80% threshold, as for PEP matches
+			result.Matches = append(result.Matches, newAdverseMediaMatch(&record, "NAME", confidence))
+		}
+	}
+
+	for _, match := range result.Matches {
+		weight := adverseMediaSeverityWeights[match.Severity]
+		if contribution := match.Confidence * weight; contribution > result.RiskContribution {
+			result.RiskContribution = contribution
+		}
+		if weight > adverseMediaSeverityWeights[result.HighestSeverity] {
+			result.HighestSeverity = match.Severity
+		}
+	}
+	result.IsMatch = len(result.Matches) > 0
+
+	return result, nil
+}
+
+func newAdverseMediaMatch(record *AdverseMediaRecord, matchedOn string, confidence float64) AdverseMediaMatch {
+	return AdverseMediaMatch{
+		RecordID:        record.RecordID,
+		MatchedName:     record.EntityName,
+		MatchedOn:       matchedOn,
+		Source:          record.Source,
+		HeadlineHash:    record.HeadlineHash,
+		Category:        record.Category,
+		Severity:        record.Severity,
+		PublicationDate: record.PublicationDate,
+		Confidence:      confidence,
+	}
+}
@@ -0,0 +1,140 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+func headlineHash(headline string) string {
+	sum := sha256.Sum256([]byte(headline))
+	return hex.EncodeToString(sum[:])
+}
+
+func TestAdverseMediaScreening(t *testing.T) {
+	stub := shimtest.NewMockStub("adverse_media_test", nil)
+	handler := NewAMLCheckHandler(&MockEventEmitter{})
+	manager := handler.adverseMediaManager
+
+	stub.MockTransactionStart("seed")
+	for _, req := range []AdverseMediaRecordRequest{
+		{RecordID: "AM_NAME", EntityName: "Victor Crane", Aliases: []string{"Vic Crane"}, Source: "FT", HeadlineHash: headlineHash("Crane charged in bribery probe"), Severity: "high", PublicationDate: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC), ActorID: "ACTOR_001"},
+		{RecordID: "AM_UNICODE", EntityName: "Ólafur Þórsson", Source: "RÚV", HeadlineHash: headlineHash("Þórsson indicted"), Severity: "MEDIUM", PublicationDate: time.Date(2025, 2, 1, 0, 0, 0, 0, time.UTC), ActorID: "ACTOR_001"},
+		{RecordID: "AM_ENTITY", EntityID: "CUST_AM_1", EntityName: "V. Crane Holdings", Source: "Reuters", HeadlineHash: headlineHash("Holdings fined"), Severity: "LOW", PublicationDate: time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC), ActorID: "ACTOR_001"},
+	} {
+		req := req
+		_, err := manager.putAdverseMediaRecord(stub, &req)
+		require.NoError(t, err)
+	}
+
+	// Records are not overwritten in place
+	_, err := manager.putAdverseMediaRecord(stub, &AdverseMediaRecordRequest{RecordID: "AM_NAME", EntityName: "Victor Crane", Source: "FT", HeadlineHash: headlineHash("x"), Severity: "LOW", PublicationDate: time.Now()})
+	assert.Error(t, err)
+	stub.MockTransactionEnd("seed")
+
+	check := func(txID, customerID, firstName, lastName string) AMLCheckResult {
+		stub.MockTransactionStart(txID)
+		defer stub.MockTransactionEnd(txID)
+		requestBytes, _ := json.Marshal(AMLCheckRequest{
+			CustomerID: customerID,
+			CustomerData: CustomerAMLData{
+				FirstName:   firstName,
+				LastName:    lastName,
+				DateOfBirth: time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC),
+				NationalID:  "ID" + customerID,
+				Nationality: "US",
+				Address:     "1 Main St",
+				Country:     "US",
+			},
+			CheckType: AMLCheckTypeCustomerOnboarding,
+			ActorID:   "ACTOR_001",
+		})
+		resultBytes, err := handler.PerformAMLCheck(stub, []string{string(requestBytes)})
+		require.NoError(t, err)
+		var result AMLCheckResult
+		require.NoError(t, json.Unmarshal(resultBytes, &result))
+		return result
+	}
+
+	clear := check("tx_clear", "CUST_AM_0", "Alice", "Brown")
+	assert.False(t, clear.AdverseMediaResult.IsMatch)
+	assert.Equal(t, validation.AMLStatusClear, clear.Status)
+
+	// A name match contributes by confidence and severity and sends the customer to review
+	named := check("tx_named", "CUST_AM_2", "Victor", "Crane")
+	require.True(t, named.AdverseMediaResult.IsMatch)
+	require.Len(t, named.AdverseMediaResult.Matches, 1)
+	assert.Equal(t, "AM_NAME", named.AdverseMediaResult.Matches[0].RecordID)
+	assert.Equal(t, "NAME", named.AdverseMediaResult.Matches[0].MatchedOn)
+	assert.Equal(t, RiskLevelHigh, named.AdverseMediaResult.HighestSeverity)
+	assert.InDelta(t, 0.75, named.AdverseMediaResult.RiskContribution, 0.001)
+	assert.Greater(t, named.OverallRiskScore, clear.OverallRiskScore)
+	assert.Equal(t, validation.AMLStatusReviewing, named.Status)
+	assert.NotEmpty(t, named.Recommendations)
+
+	// Names outside ASCII are indexed by whole characters and still match
+	unicode := check("tx_unicode", "CUST_AM_3", "Ólafur", "Þórsson")
+	require.Len(t, unicode.AdverseMediaResult.Matches, 1)
+	assert.Equal(t, "AM_UNICODE", unicode.AdverseMediaResult.Matches[0].RecordID)
+
+	// A record naming the customer's ID matches regardless of the name screened
+	linked := check("tx_linked", "CUST_AM_1", "Alice", "Brown")
+	require.Len(t, linked.AdverseMediaResult.Matches, 1)
+	assert.Equal(t, "ENTITY_ID", linked.AdverseMediaResult.Matches[0].MatchedOn)
+	assert.InDelta(t, 0.25, linked.AdverseMediaResult.RiskContribution, 0.001)
+}
+
+func TestPEPNamePrefixesCutByRune(t *testing.T) {
+	tests := []struct {
+		name     string
+		expected []string
+	}{
+		{"Viktor Minister", []string{"vik", "min"}},
+		{"Al B", []string{"al", "b"}},
+		{"Ólafur Þórsson", []string{"óla", "þór"}},
+		{"Дмитрий Ёлкин", []string{"дми", "ёлк"}},
+		{"習近平", []string{"習近平"}},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			prefixes := pepNamePrefixes(tt.name)
+			assert.Equal(t, tt.expected, prefixes)
+			for _, prefix := range prefixes {
+				assert.True(t, utf8.ValidString(prefix))
+			}
+		})
+	}
+}
+
+func TestAdverseMediaRecordValidation(t *testing.T) {
+	manager := NewAdverseMediaManager(nil)
+	valid := AdverseMediaRecordRequest{EntityName: "Victor Crane", Source: "FT", HeadlineHash: headlineHash("headline"), Severity: "MEDIUM", PublicationDate: time.Date(2025, 3, 1, 0, 0, 0, 0, time.UTC)}
+	require.NoError(t, manager.validateAdverseMediaRecordRequest(&valid))
+
+	tests := []struct {
+		name   string
+		modify func(req *AdverseMediaRecordRequest)
+	}{
+		{"missing entity name", func(req *AdverseMediaRecordRequest) { req.EntityName = " " }},
+		{"missing source", func(req *AdverseMediaRecordRequest) { req.Source = "" }},
+		{"headline in plain text", func(req *AdverseMediaRecordRequest) { req.HeadlineHash = "Crane charged" }},
+		{"short hash", func(req *AdverseMediaRecordRequest) { req.HeadlineHash = "abcd" }},
+		{"missing publication date", func(req *AdverseMediaRecordRequest) { req.PublicationDate = time.Time{} }},
+		{"unknown severity", func(req *AdverseMediaRecordRequest) { req.Severity = "SEVERE" }},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			req := valid
+			tt.modify(&req)
+			assert.Error(t, manager.validateAdverseMediaRecordRequest(&req))
+		})
+	}
+}
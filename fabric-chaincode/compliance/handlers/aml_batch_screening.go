@@ -0,0 +1,372 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// Screening job statuses
+const (
+	ScreeningJobRunning   = "RUNNING"
+	ScreeningJobCompleted = "COMPLETED"
+)
+
+// amlLatestKeyPrefix prefixes the index of each customer's current AML check, which a screening
+// job walks in customer ID order
+const amlLatestKeyPrefix = "AML_LATEST_"
+
+// BatchScreenEntity is one customer to screen in a batch. Without customer data the data screened
+// by the customer's current check is reused.
+type BatchScreenEntity struct {
+	CustomerID   string           `json:"customerID"`
+	CustomerData *CustomerAMLData `json:"customerData,omitempty"`
+}
+
+// BatchScreenRequest screens either the given entities or, with a job ID, the job's next batch of
+// customers
+type BatchScreenRequest struct {
+	JobID     string              `json:"jobID,omitempty"`
+	Entities  []BatchScreenEntity `json:"entities,omitempty"`
+	BatchSize int                 `json:"batchSize,omitempty"` // Customers per job batch; defaults to the maximum
+	CheckType AMLCheckType        `json:"checkType,omitempty"` // Defaults to RISK_REASSESSMENT
+	ActorID   string              `json:"actorID"`
+}
+
+// BatchScreenEntityResult records the screening of one entity in a batch
+type BatchScreenEntityResult struct {
+	CustomerID        string    `json:"customerID"`
+	CheckID           string    `json:"checkID,omitempty"`
+	Status            string    `json:"status,omitempty"`
+	RiskLevel         RiskLevel `json:"riskLevel,omitempty"`
+	SanctionMatch     bool      `json:"sanctionMatch"`
+	SuppressedMatches int       `json:"suppressedMatches,omitempty"`
+	Error             string    `json:"error,omitempty"`
+}
+
+// ScreeningBatch is the summary record of one batch screening
+type ScreeningBatch struct {
+	BatchID       string                    `json:"batchID"`
+	JobID         string                    `json:"jobID,omitempty"`
+	CheckType     AMLCheckType              `json:"checkType"`
+	Results       []BatchScreenEntityResult `json:"results"`
+	Screened      int                       `json:"screened"`
+	Matched       int                       `json:"matched"` // Screened with a sanction match
+	Flagged       int                       `json:"flagged"` // Screened as high or critical risk
+	Failed        int                       `json:"failed"`
+	ScreenedBy    string                    `json:"screenedBy"`
+	ScreeningDate time.Time                 `json:"screeningDate"`
+}
+
+// ScreeningJobRequest represents a request to start re-screening every customer with a check on file
+type ScreeningJobRequest struct {
+	Reason  string `json:"reason"`
+	ActorID string `json:"actorID"`
+}
+
+// ScreeningJob tracks the re-screening of the whole customer base in batches. Cursor is the last
+// customer screened; each batch resumes after it, so a job survives failed or interrupted batches.
+type ScreeningJob struct {
+	JobID         string     `json:"jobID"`
+	Reason        string     `json:"reason"`
+	Status        string     `json:"status"`
+	Cursor        string     `json:"cursor,omitempty"`
+	Batches       int        `json:"batches"`
+	LastBatchID   string     `json:"lastBatchID,omitempty"`
+	Screened      int        `json:"screened"`
+	Matched       int        `json:"matched"`
+	Flagged       int        `json:"flagged"`
+	Failed        int        `json:"failed"`
+	CreatedBy     string     `json:"createdBy"`
+	CreatedDate   time.Time  `json:"createdDate"`
+	UpdatedDate   time.Time  `json:"updatedDate"`
+	CompletedDate *time.Time `json:"completedDate,omitempty"`
+}
+
+// StartScreeningJob opens a job re-screening every customer with an AML check on file, typically
+// after a sanction list update. Its batches are run with BatchScreenEntities.
+func (h *AMLCheckHandler) StartScreeningJob(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req ScreeningJobRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse screening job request: %v", err)
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "reason", "reason is required")
+	}
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	job := &ScreeningJob{
+		JobID:       services.GenerateDeterministicID(stub, config.ScreeningJobPrefix),
+		Reason:      req.Reason,
+		Status:      ScreeningJobRunning,
+		CreatedBy:   req.ActorID,
+		CreatedDate: now,
+		UpdatedDate: now,
+	}
+	if err := h.putScreeningJob(stub, job); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(job)
+}
+
+// BatchScreenEntities screens up to MaxBatchScreenSize customers in one transaction and stores a
+// summary record of the batch. Each customer's check is recorded as PerformAMLCheck records it;
+// one customer failing does not fail the batch. With a job ID the job's next customers are
+// screened and the job advanced, completing once every customer has been screened.
+func (h *AMLCheckHandler) BatchScreenEntities(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req BatchScreenRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse batch screen request: %v", err)
+	}
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+	if req.CheckType == "" {
+		req.CheckType = AMLCheckTypeRiskReassessment
+	}
+	switch req.CheckType {
+	case AMLCheckTypeCustomerOnboarding, AMLCheckTypePeriodicReview, AMLCheckTypeTransactionBased, AMLCheckTypeRiskReassessment:
+	default:
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid check type: %s", req.CheckType)
+	}
+	if (req.JobID == "") == (len(req.Entities) == 0) {
+		return nil, fmt.Errorf("either entities or a jobID is required")
+	}
+	if len(req.Entities) > config.MaxBatchScreenSize {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "batch of %d entities exceeds maximum of %d", len(req.Entities), config.MaxBatchScreenSize)
+	}
+	seen := map[string]bool{}
+	for _, entity := range req.Entities {
+		if seen[entity.CustomerID] {
+			return nil, fmt.Errorf("customer %s appears more than once in the batch", entity.CustomerID)
+		}
+		seen[entity.CustomerID] = true
+	}
+
+	var job *ScreeningJob
+	entities := req.Entities
+	more := false
+	if req.JobID != "" {
+		var err error
+		if job, err = h.getScreeningJob(stub, req.JobID); err != nil {
+			return nil, err
+		}
+		if job.Status != ScreeningJobRunning {
+			return nil, fmt.Errorf("screening job %s is %s", job.JobID, job.Status)
+		}
+		batchSize := req.BatchSize
+		if batchSize == 0 {
+			batchSize = config.MaxBatchScreenSize
+		}
+		if batchSize < 1 || batchSize > config.MaxBatchScreenSize {
+			return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "batchSize must be between 1 and %d, got %d", config.MaxBatchScreenSize, req.BatchSize)
+		}
+		if entities, more, err = h.nextScreeningCustomers(stub, job.Cursor, batchSize); err != nil {
+			return nil, err
+		}
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	batch := &ScreeningBatch{
+		BatchID:       services.GenerateDeterministicID(stub, config.ScreeningBatchPrefix),
+		JobID:         req.JobID,
+		CheckType:     req.CheckType,
+		Results:       []BatchScreenEntityResult{},
+		ScreenedBy:    req.ActorID,
+		ScreeningDate: now,
+	}
+
+	for _, entity := range entities {
+		outcome := BatchScreenEntityResult{CustomerID: entity.CustomerID}
+		result, err := h.screenBatchEntity(stub, &entity, req.CheckType, req.ActorID)
+		if err != nil {
+			outcome.Error = err.Error()
+			batch.Failed++
+			batch.Results = append(batch.Results, outcome)
+			continue
+		}
+
+		outcome.CheckID = result.CheckID
+		outcome.Status = string(result.Status)
+		outcome.RiskLevel = result.RiskLevel
+		outcome.SanctionMatch = result.SanctionScreenResult.IsMatch
+		outcome.SuppressedMatches = result.SanctionScreenResult.SuppressedMatches
+		batch.Screened++
+		if outcome.SanctionMatch {
+			batch.Matched++
+		}
+		if result.RiskLevel == RiskLevelHigh || result.RiskLevel == RiskLevelCritical {
+			batch.Flagged++
+		}
+		batch.Results = append(batch.Results, outcome)
+	}
+	batchKey, err := stub.CreateCompositeKey(config.ScreeningBatchPrefix, []string{batch.BatchID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create screening batch key: %w", err)
+	}
+	if err := h.persistenceService.Put(stub, batchKey, batch); err != nil {
+		return nil, fmt.Errorf("failed to store screening batch: %w", err)
+	}
+
+	if job != nil {
+		if len(entities) > 0 {
+			job.Cursor = entities[len(entities)-1].CustomerID
+		}
+		job.Batches++
+		job.LastBatchID = batch.BatchID
+		job.Screened += batch.Screened
+		job.Matched += batch.Matched
+		job.Flagged += batch.Flagged
+		job.Failed += batch.Failed
+		job.UpdatedDate = now
+		if !more {
+			job.Status = ScreeningJobCompleted
+			job.CompletedDate = &now
+		}
+		if err := h.putScreeningJob(stub, job); err != nil {
+			return nil, err
+		}
+	}
+
+	return json.Marshal(batch)
+}
+
+// GetScreeningJob returns a screening job with its progress
+func (h *AMLCheckHandler) GetScreeningJob(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	job, err := h.getScreeningJob(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(job)
+}
+
+// GetScreeningBatch returns the summary record of a batch screening with its per-entity results
+func (h *AMLCheckHandler) GetScreeningBatch(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	batchKey, err := stub.CreateCompositeKey(config.ScreeningBatchPrefix, []string{args[0]})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create screening batch key: %w", err)
+	}
+	var batch ScreeningBatch
+	if err := h.persistenceService.Get(stub, batchKey, &batch); err != nil {
+		return nil, fmt.Errorf("screening batch %s not found: %w", args[0], err)
+	}
+	return json.Marshal(&batch)
+}
+
+// screenBatchEntity runs and records one entity's check
+func (h *AMLCheckHandler) screenBatchEntity(stub shim.ChaincodeStubInterface, entity *BatchScreenEntity, checkType AMLCheckType, actorID string) (*AMLCheckResult, error) {
+	if strings.TrimSpace(entity.CustomerID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "customerID", "customerID is required")
+	}
+
+	req := &AMLCheckRequest{
+		CustomerID: entity.CustomerID,
+		CheckType:  checkType,
+		ActorID:    actorID,
+	}
+	if entity.CustomerData != nil {
+		req.CustomerData = *entity.CustomerData
+		if err := h.validateAMLCheckRequest(req); err != nil {
+			return nil, fmt.Errorf("invalid AML check request: %w", err)
+		}
+	} else {
+		previous, err := h.getLatestCheck(stub, entity.CustomerID)
+		if err != nil {
+			return nil, err
+		}
+		if previous.CustomerData == nil {
+			return nil, fmt.Errorf("check %s holds no screening data; run PerformAMLCheck for customer %s", previous.CheckID, previous.CustomerID)
+		}
+		req.CustomerData = *previous.CustomerData
+	}
+
+	result, err := h.performComprehensiveAMLCheck(stub, services.GenerateDeterministicID(stub, config.AMLCheckPrefix), req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to screen customer %s: %w", entity.CustomerID, err)
+	}
+	if err := h.recordCheck(stub, result, actorID); err != nil {
+		return nil, err
+	}
+	return result, nil
+}
+
+// nextScreeningCustomers returns up to batchSize customers with a check on file after the cursor,
+// in customer ID order, and whether more follow
+func (h *AMLCheckHandler) nextScreeningCustomers(stub shim.ChaincodeStubInterface, cursor string, batchSize int) ([]BatchScreenEntity, bool, error) {
+	startKey := amlLatestKeyPrefix
+	if cursor != "" {
+		startKey = amlLatestKeyPrefix + cursor + "\x00" // Exclusive of the cursor
+	}
+	iterator, err := stub.GetStateByRange(startKey, amlLatestKeyPrefix+"\xff")
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get customers to screen: %w", err)
+	}
+	defer iterator.Close()
+
+	entities := []BatchScreenEntity{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to iterate customers to screen: %w", err)
+		}
+		if len(entities) == batchSize {
+			return entities, true, nil
+		}
+		entities = append(entities, BatchScreenEntity{CustomerID: strings.TrimPrefix(response.Key, amlLatestKeyPrefix)})
+	}
+	return entities, false, nil
+}
+
+func (h *AMLCheckHandler) getScreeningJob(stub shim.ChaincodeStubInterface, jobID string) (*ScreeningJob, error) {
+	jobKey, err := stub.CreateCompositeKey(config.ScreeningJobPrefix, []string{jobID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create screening job key: %w", err)
+	}
+	var job ScreeningJob
+	if err := h.persistenceService.Get(stub, jobKey, &job); err != nil {
+		return nil, fmt.Errorf("screening job %s not found: %w", jobID, err)
+	}
+	return &job, nil
+}
+
+func (h *AMLCheckHandler) putScreeningJob(stub shim.ChaincodeStubInterface, job *ScreeningJob) error {
+	jobKey, err := stub.CreateCompositeKey(config.ScreeningJobPrefix, []string{job.JobID})
+	if err != nil {
+		return fmt.Errorf("failed to create screening job key: %w", err)
+	}
+	if err := h.persistenceService.Put(stub, jobKey, job); err != nil {
+		return fmt.Errorf("failed to store screening job: %w", err)
+	}
+	return nil
+}
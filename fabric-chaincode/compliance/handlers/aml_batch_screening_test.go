@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+func TestAMLCheckHandler_BatchScreening(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	clock := services.NewFixedClock(now)
+	defer services.SetClock(clock)()
+	stub := shimtest.NewMockStub("batch_screening_test", nil)
+	// Each batch screens several entities in one transaction, numbering the check IDs it generates
+	invocation := services.NewInvocation(stub)
+	seedSanctionList(t, stub, "OFAC_SDN", johnDoeSanctionEntry())
+	handler := NewAMLCheckHandler(&MockEventEmitter{})
+
+	invoke := func(txID string, fn func(args []string) ([]byte, error), request interface{}) ([]byte, error) {
+		requestBytes, err := json.Marshal(request)
+		require.NoError(t, err)
+		stub.MockTransactionStart(txID)
+		defer stub.MockTransactionEnd(txID)
+		return fn([]string{string(requestBytes)})
+	}
+	batchScreen := func(args []string) ([]byte, error) { return handler.BatchScreenEntities(invocation, args) }
+	startJob := func(args []string) ([]byte, error) { return handler.StartScreeningJob(invocation, args) }
+	batchOf := func(batchBytes []byte, err error) *ScreeningBatch {
+		require.NoError(t, err)
+		var batch ScreeningBatch
+		require.NoError(t, json.Unmarshal(batchBytes, &batch))
+		return &batch
+	}
+	getJob := func(txID, jobID string) *ScreeningJob {
+		stub.MockTransactionStart(txID)
+		defer stub.MockTransactionEnd(txID)
+		jobBytes, err := handler.GetScreeningJob(invocation, []string{jobID})
+		require.NoError(t, err)
+		var job ScreeningJob
+		require.NoError(t, json.Unmarshal(jobBytes, &job))
+		return &job
+	}
+	customer := func(firstName, lastName, nationalID string) *CustomerAMLData {
+		return &CustomerAMLData{FirstName: firstName, LastName: lastName, NationalID: nationalID, Nationality: "US", Country: "US"}
+	}
+
+	_, err := invoke("batch_0", batchScreen, BatchScreenRequest{ActorID: "ACTOR_001"})
+	assert.Contains(t, err.Error(), "either entities or a jobID is required")
+	_, err = invoke("batch_1", batchScreen, BatchScreenRequest{Entities: []BatchScreenEntity{{CustomerID: "CUST_001"}, {CustomerID: "CUST_001"}}, ActorID: "ACTOR_001"})
+	assert.Contains(t, err.Error(), "more than once")
+	_, err = invoke("batch_2", batchScreen, BatchScreenRequest{Entities: make([]BatchScreenEntity, 51), ActorID: "ACTOR_001"})
+	assert.Contains(t, err.Error(), "exceeds maximum of 50")
+
+	// One failing entity does not fail the batch
+	batch := batchOf(invoke("batch_3", batchScreen, BatchScreenRequest{
+		Entities: []BatchScreenEntity{
+			{CustomerID: "CUST_001", CustomerData: customer("Ana", "Silva", "ID001")},
+			{CustomerID: "CUST_002", CustomerData: customer("John", "Doe", "ID002")},
+			{CustomerID: "CUST_003", CustomerData: customer("Wei", "Chen", "")},
+			{CustomerID: "CUST_004", CustomerData: customer("Omar", "Haddad", "ID004")},
+			{CustomerID: "CUST_005"},
+		},
+		ActorID: "ACTOR_001",
+	}))
+	assert.Equal(t, AMLCheckTypeRiskReassessment, batch.CheckType)
+	assert.Equal(t, 3, batch.Screened)
+	assert.Equal(t, 1, batch.Matched)
+	assert.Equal(t, 2, batch.Failed)
+	require.Len(t, batch.Results, 5)
+	assert.True(t, batch.Results[1].SanctionMatch)
+	assert.NotEmpty(t, batch.Results[1].CheckID)
+	assert.Contains(t, batch.Results[2].Error, "national ID is required")
+	assert.Contains(t, batch.Results[4].Error, "no AML check on file")
+
+	stub.MockTransactionStart("get_batch")
+	storedBytes, err := handler.GetScreeningBatch(invocation, []string{batch.BatchID})
+	stub.MockTransactionEnd("get_batch")
+	require.NoError(t, err)
+	assert.Equal(t, batch, batchOf(storedBytes, nil))
+
+	// A job walks every customer with a check on file, resuming after its cursor
+	jobBytes, err := invoke("job_1", startJob, ScreeningJobRequest{Reason: "OFAC SDN update", ActorID: "ACTOR_002"})
+	require.NoError(t, err)
+	var job ScreeningJob
+	require.NoError(t, json.Unmarshal(jobBytes, &job))
+	assert.Equal(t, ScreeningJobRunning, job.Status)
+
+	first := batchOf(invoke("job_batch_1", batchScreen, BatchScreenRequest{JobID: job.JobID, BatchSize: 2, ActorID: "ACTOR_002"}))
+	assert.Equal(t, job.JobID, first.JobID)
+	require.Len(t, first.Results, 2)
+	assert.Equal(t, "CUST_001", first.Results[0].CustomerID)
+	assert.Equal(t, "CUST_002", first.Results[1].CustomerID)
+	progress := getJob("job_get_1", job.JobID)
+	assert.Equal(t, ScreeningJobRunning, progress.Status)
+	assert.Equal(t, "CUST_002", progress.Cursor)
+	assert.Equal(t, 2, progress.Screened)
+
+	second := batchOf(invoke("job_batch_2", batchScreen, BatchScreenRequest{JobID: job.JobID, BatchSize: 2, ActorID: "ACTOR_002"}))
+	require.Len(t, second.Results, 1)
+	assert.Equal(t, "CUST_004", second.Results[0].CustomerID)
+	progress = getJob("job_get_2", job.JobID)
+	assert.Equal(t, ScreeningJobCompleted, progress.Status)
+	assert.Equal(t, 2, progress.Batches)
+	assert.Equal(t, 3, progress.Screened)
+	assert.Equal(t, 1, progress.Matched)
+	assert.Equal(t, second.BatchID, progress.LastBatchID)
+
+	_, err = invoke("job_batch_3", batchScreen, BatchScreenRequest{JobID: job.JobID, ActorID: "ACTOR_002"})
+	assert.Contains(t, err.Error(), "is COMPLETED")
+}
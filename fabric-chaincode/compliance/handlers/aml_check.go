@@ -3,14 +3,16 @@ package handlers
 import (
 	"encoding/json"
 	"fmt"
+	"sort"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/hyperledger/fabric-chaincode-go/shim"
 	"github.com/brycemacchaveli/origin.block/fabric-chaincode/compliance/domain"
 	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/interfaces"
 	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
-	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
 	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
 )
 
@@ -18,6 +20,9 @@ import (
 type AMLCheckHandler struct {
 	persistenceService *services.PersistenceService
 	eventEmitter       domain.EventEmitter
+	pepListManager     *PEPListManager
+	sanctionListManager *SanctionListManager
+	adverseMediaManager *AdverseMediaManager
 }
 
 // NewAMLCheckHandler creates a new AML check handler
@@ -25,6 +30,9 @@ func NewAMLCheckHandler(eventEmitter domain.EventEmitter) *AMLCheckHandler {
 	return &AMLCheckHandler{
 		persistenceService: services.NewPersistenceService(),
 		eventEmitter:       eventEmitter,
+		pepListManager:     NewPEPListManager(eventEmitter),
+		sanctionListManager: NewSanctionListManager(eventEmitter),
+		adverseMediaManager: NewAdverseMediaManager(eventEmitter),
 	}
 }
 
@@ -48,6 +56,7 @@ type CustomerAMLData struct {
 	Address       string    `json:"address"`
 	Country       string    `json:"country"`
 	Occupation    string    `json:"occupation,omitempty"`
+	Industry      string    `json:"industry,omitempty"`
 	EmployerName  string    `json:"employerName,omitempty"`
 }
 
@@ -61,6 +70,7 @@ type TransactionAMLData struct {
 	CounterpartyCountry string  `json:"counterpartyCountry,omitempty"`
 	Purpose           string    `json:"purpose,omitempty"`
 	TransactionDate   time.Time `json:"transactionDate"`
+	PaymentReference  *PaymentMessageReference `json:"paymentReference,omitempty"` // Set for data mapped from a payment message
 }
 
 // AMLCheckType represents the type of AML check to perform
@@ -80,14 +90,19 @@ type AMLCheckResult struct {
 	CheckType            AMLCheckType           `json:"checkType"`
 	OverallRiskScore     float64                `json:"overallRiskScore"`
 	RiskLevel            RiskLevel              `json:"riskLevel"`
+	RiskModelVersion     int                    `json:"riskModelVersion"` // Risk model version the score was calculated with
+	RiskModelName        string                 `json:"riskModelName"`
 	Status               validation.AMLStatus  `json:"status"`
 	SanctionScreenResult SanctionScreenResult   `json:"sanctionScreenResult"`
 	PEPScreenResult      PEPScreenResult        `json:"pepScreenResult"`
+	AdverseMediaResult   AdverseMediaScreenResult `json:"adverseMediaResult"`
+	CounterpartyScreenResult *CounterpartyScreenResult `json:"counterpartyScreenResult,omitempty"` // Set when the transaction names a counterparty
 	RiskFactors          []RiskFactor           `json:"riskFactors"`
 	Recommendations      []string               `json:"recommendations"`
 	RequiredActions      []RequiredAction       `json:"requiredActions"`
 	CheckDate            time.Time              `json:"checkDate"`
 	ExpiryDate           time.Time              `json:"expiryDate"`
+	CustomerData         *CustomerAMLData       `json:"customerData,omitempty"` // Screened data, reused by periodic re-screening
 	CheckedBy            string                 `json:"checkedBy"`
 	ReviewedBy           string                 `json:"reviewedBy,omitempty"`
 	ReviewDate           *time.Time             `json:"reviewDate,omitempty"`
@@ -109,6 +124,7 @@ type SanctionScreenResult struct {
 	IsMatch          bool              `json:"isMatch"`
 	MatchConfidence  float64           `json:"matchConfidence"`
 	Matches          []SanctionMatch   `json:"matches"`
+	SuppressedMatches int              `json:"suppressedMatches,omitempty"`
 	ListsScreened    []string          `json:"listsScreened"`
 	ScreeningDate    time.Time         `json:"screeningDate"`
 }
@@ -123,6 +139,9 @@ type SanctionMatch struct {
 	MatchedFields   []string  `json:"matchedFields"`
 	ListEntryID     string    `json:"listEntryID"`
 	AdditionalInfo  string    `json:"additionalInfo,omitempty"`
+	Suppressed      bool      `json:"suppressed,omitempty"` // Whitelisted as a false positive; logged but not counted
+	SuppressedBy    string    `json:"suppressedBy,omitempty"`
+	SuppressionExpiry *time.Time `json:"suppressionExpiry,omitempty"`
 }
 
 // PEPScreenResult represents Politically Exposed Person screening results
@@ -136,6 +155,7 @@ type PEPScreenResult struct {
 // PEPMatch represents a potential PEP match
 type PEPMatch struct {
 	MatchID        string    `json:"matchID"`
+	ListEntryID    string    `json:"listEntryID"`
 	MatchedName    string    `json:"matchedName"`
 	Position       string    `json:"position"`
 	Country        string    `json:"country"`
@@ -144,6 +164,21 @@ type PEPMatch struct {
 	LastUpdated    time.Time `json:"lastUpdated"`
 }
 
+// CounterpartyScreenResult represents sanction and PEP screening of a transaction's counterparty,
+// kept apart from the customer's own screening results
+type CounterpartyScreenResult struct {
+	TransactionID       string          `json:"transactionID"`
+	CounterpartyName    string          `json:"counterpartyName"`
+	CounterpartyCountry string          `json:"counterpartyCountry,omitempty"`
+	IsMatch             bool            `json:"isMatch"`
+	SanctionMatch       bool            `json:"sanctionMatch"`
+	PEPMatch            bool            `json:"pepMatch"`
+	MatchConfidence     float64         `json:"matchConfidence"`
+	SanctionMatches     []SanctionMatch `json:"sanctionMatches"`
+	PEPMatches          []PEPMatch      `json:"pepMatches"`
+	ScreeningDate       time.Time       `json:"screeningDate"`
+}
+
 // RiskFactor represents an identified risk factor
 type RiskFactor struct {
 	FactorID     string    `json:"factorID"`
@@ -153,6 +188,7 @@ type RiskFactor struct {
 	Severity     string    `json:"severity"`
 	Evidence     string    `json:"evidence,omitempty"`
 	DetectedDate time.Time `json:"detectedDate"`
+	CatalogReference *RiskCatalogReference `json:"catalogReference,omitempty"` // Catalog version the score came from
 }
 
 // RequiredAction represents an action required based on AML check results
@@ -169,50 +205,54 @@ type RequiredAction struct {
 // PerformAMLCheck performs a comprehensive AML check with real screening logic
 func (h *AMLCheckHandler) PerformAMLCheck(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
 	if len(args) != 1 {
-		return nil, fmt.Errorf("incorrect number of arguments. Expected 1, got %d", len(args))
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
 	}
 
 	var req AMLCheckRequest
 	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
-		return nil, fmt.Errorf("failed to parse AML check request: %v", err)
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse AML check request: %v", err)
 	}
 
 	// Validate request
 	if err := h.validateAMLCheckRequest(&req); err != nil {
-		return nil, fmt.Errorf("invalid AML check request: %v", err)
+		return nil, fmt.Errorf("invalid AML check request: %w", err)
 	}
 
 	// Generate check ID
-	checkID := utils.GenerateID(config.AMLCheckPrefix)
+	checkID := services.GenerateDeterministicID(stub, config.AMLCheckPrefix)
 
 	// Perform comprehensive AML screening
 	result, err := h.performComprehensiveAMLCheck(stub, checkID, &req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to perform AML check: %v", err)
+		return nil, fmt.Errorf("failed to perform AML check: %w", err)
 	}
 
-	// Store AML check result
-	resultKey := fmt.Sprintf("AML_RESULT_%s", checkID)
-	if err := h.persistenceService.Put(stub, resultKey, result); err != nil {
-		return nil, fmt.Errorf("failed to store AML check result: %v", err)
+	// Store the result with its indexes, compliance event and any escalation
+	if err := h.recordCheck(stub, result, req.ActorID); err != nil {
+		return nil, err
 	}
 
-	// Create customer AML index
-	customerAMLKey := fmt.Sprintf("CUSTOMER_AML_%s_%s", req.CustomerID, checkID)
-	if err := stub.PutState(customerAMLKey, []byte(checkID)); err != nil {
-		return nil, fmt.Errorf("failed to create customer AML index: %v", err)
+	return json.Marshal(result)
+}
+
+// ScreenPEP screens a name against the PEP list without running a full AML check. Other
+// chaincodes call it from their own AML workflows.
+func (h *AMLCheckHandler) ScreenPEP(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
 	}
 
-	// Record compliance event
-	if err := h.recordComplianceEvent(stub, result, req.ActorID); err != nil {
-		return nil, fmt.Errorf("failed to record compliance event: %v", err)
+	var req interfaces.PEPScreeningRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse PEP screening request: %v", err)
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "name", "name is required")
 	}
 
-	// Handle escalation if needed
-	if result.RiskLevel == RiskLevelHigh || result.RiskLevel == RiskLevelCritical {
-		if err := h.handleRiskEscalation(stub, result, req.ActorID); err != nil {
-			return nil, fmt.Errorf("failed to handle risk escalation: %v", err)
-		}
+	result, err := h.performPEPScreening(stub, &CustomerAMLData{FirstName: req.Name})
+	if err != nil {
+		return nil, err
 	}
 
 	return json.Marshal(result)
@@ -220,11 +260,17 @@ func (h *AMLCheckHandler) PerformAMLCheck(stub shim.ChaincodeStubInterface, args
 
 // performComprehensiveAMLCheck performs the actual AML screening logic
 func (h *AMLCheckHandler) performComprehensiveAMLCheck(stub shim.ChaincodeStubInterface, checkID string, req *AMLCheckRequest) (*AMLCheckResult, error) {
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
 	result := &AMLCheckResult{
 		CheckID:         checkID,
 		CustomerID:      req.CustomerID,
 		CheckType:       req.CheckType,
-		CheckDate:       time.Now(),
+		CheckDate:       now,
+		CustomerData:    &req.CustomerData,
 		CheckedBy:       req.ActorID,
 		RiskFactors:     []RiskFactor{},
 		Recommendations: []string{},
@@ -232,101 +278,141 @@ func (h *AMLCheckHandler) performComprehensiveAMLCheck(stub shim.ChaincodeStubIn
 	}
 
 	// Set expiry date based on check type
-	result.ExpiryDate = h.calculateExpiryDate(req.CheckType)
+	result.ExpiryDate = h.calculateExpiryDate(req.CheckType, now)
 
 	// 1. Perform sanction list screening
-	sanctionResult, err := h.performSanctionScreening(stub, &req.CustomerData, req.TransactionData)
+	sanctionResult, err := h.performSanctionScreening(stub, req.CustomerID, &req.CustomerData, req.TransactionData)
 	if err != nil {
-		return nil, fmt.Errorf("sanction screening failed: %v", err)
+		return nil, fmt.Errorf("sanction screening failed: %w", err)
 	}
 	result.SanctionScreenResult = sanctionResult
 
 	// 2. Perform PEP screening
 	pepResult, err := h.performPEPScreening(stub, &req.CustomerData)
 	if err != nil {
-		return nil, fmt.Errorf("PEP screening failed: %v", err)
+		return nil, fmt.Errorf("PEP screening failed: %w", err)
 	}
 	result.PEPScreenResult = pepResult
 
-	// 3. Assess risk factors
+	// 2a. Perform adverse media screening
+	adverseMediaResult, err := h.performAdverseMediaScreening(stub, req.CustomerID, &req.CustomerData)
+	if err != nil {
+		return nil, fmt.Errorf("adverse media screening failed: %w", err)
+	}
+	result.AdverseMediaResult = adverseMediaResult
+
+	// 3. Screen the transaction counterparty, if any
+	counterpartyResult, err := h.performCounterpartyScreening(stub, req.TransactionData)
+	if err != nil {
+		return nil, fmt.Errorf("counterparty screening failed: %w", err)
+	}
+	result.CounterpartyScreenResult = counterpartyResult
+
+	// 4. Assess risk factors
 	riskFactors, err := h.assessRiskFactors(stub, &req.CustomerData, req.TransactionData)
 	if err != nil {
-		return nil, fmt.Errorf("risk assessment failed: %v", err)
+		return nil, fmt.Errorf("risk assessment failed: %w", err)
 	}
 	result.RiskFactors = riskFactors
 
-	// 4. Calculate overall risk score
-	result.OverallRiskScore = h.calculateOverallRiskScore(result)
-	result.RiskLevel = h.determineRiskLevel(result.OverallRiskScore)
+	// 5. Calculate overall risk score with the risk model in force
+	model, err := activeRiskModel(stub)
+	if err != nil {
+		return nil, err
+	}
+	result.RiskModelVersion = model.Version
+	result.RiskModelName = model.Name
+	result.OverallRiskScore = h.calculateOverallRiskScore(result, model)
+	result.RiskLevel = h.determineRiskLevel(result.OverallRiskScore, model)
 
-	// 5. Determine AML status
+	// 6. Determine AML status
 	result.Status = h.determineAMLStatus(result)
 
-	// 6. Generate recommendations and required actions
+	// 7. Generate recommendations and required actions
 	result.Recommendations = h.generateRecommendations(result)
-	result.RequiredActions = h.generateRequiredActions(result, req.ActorID)
+	result.RequiredActions = h.generateRequiredActions(stub, result, req.ActorID)
 
 	return result, nil
 }
 
-// performSanctionScreening performs comprehensive sanction list screening
-func (h *AMLCheckHandler) performSanctionScreening(stub shim.ChaincodeStubInterface, customerData *CustomerAMLData, transactionData *TransactionAMLData) (SanctionScreenResult, error) {
+// performSanctionScreening performs comprehensive sanction list screening. Matches whitelisted as
+// false positives for the customer are kept in the result but suppressed.
+func (h *AMLCheckHandler) performSanctionScreening(stub shim.ChaincodeStubInterface, customerID string, customerData *CustomerAMLData, transactionData *TransactionAMLData) (SanctionScreenResult, error) {
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return SanctionScreenResult{}, err
+	}
+
 	result := SanctionScreenResult{
 		IsMatch:       false,
 		Matches:       []SanctionMatch{},
-		ListsScreened: []string{"OFAC_SDN", "UN_SANCTIONS", "EU_SANCTIONS", "HMT_SANCTIONS"},
-		ScreeningDate: time.Now(),
+		ScreeningDate: now,
 	}
 
-	// Get active sanction lists
-	sanctionLists, err := h.getActiveSanctionLists(stub)
+	// Screen against the ledger entries sharing a name prefix with the customer
+	allMatches, listsScreened, err := h.screenAgainstSanctionEntries(stub, customerData)
 	if err != nil {
-		return result, fmt.Errorf("failed to get sanction lists: %v", err)
+		return result, err
 	}
-
-	var allMatches []SanctionMatch
+	result.ListsScreened = listsScreened
 	maxConfidence := 0.0
 
-	// Screen against each sanction list
-	for _, list := range sanctionLists {
-		matches, err := h.screenAgainstSanctionList(stub, customerData, list)
-		if err != nil {
-			continue // Log error but continue with other lists
-		}
-
-		for _, match := range matches {
-			if match.Confidence > maxConfidence {
-				maxConfidence = match.Confidence
-			}
-			allMatches = append(allMatches, match)
+	suppressed, err := suppressWhitelistedMatches(stub, customerID, allMatches, now)
+	if err != nil {
+		return result, err
+	}
+	for _, match := range allMatches {
+		if !match.Suppressed && match.Confidence > maxConfidence {
+			maxConfidence = match.Confidence
 		}
 	}
 
 	result.Matches = allMatches
+	result.SuppressedMatches = suppressed
 	result.MatchConfidence = maxConfidence
-	result.IsMatch = len(allMatches) > 0 && maxConfidence >= 0.8 // 80% confidence threshold
+	result.IsMatch = maxConfidence >= 0.8 // 80% confidence threshold
+
+	if err := services.IncrementCounter(stub, config.MetricSanctionScreenings); err != nil {
+		return result, err
+	}
+	if result.IsMatch {
+		if err := services.IncrementCounter(stub, config.MetricSanctionMatches); err != nil {
+			return result, err
+		}
+	}
+	if suppressed > 0 {
+		if err := services.IncrementCounter(stub, config.MetricSanctionSuppressions); err != nil {
+			return result, err
+		}
+	}
 
 	return result, nil
 }
 
 // performPEPScreening performs Politically Exposed Person screening
 func (h *AMLCheckHandler) performPEPScreening(stub shim.ChaincodeStubInterface, customerData *CustomerAMLData) (PEPScreenResult, error) {
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return PEPScreenResult{}, err
+	}
+
 	result := PEPScreenResult{
 		IsMatch:       false,
 		Matches:       []PEPMatch{},
-		ScreeningDate: time.Now(),
+		ScreeningDate: now,
 	}
 
-	// Get PEP database
-	pepDatabase, err := h.getPEPDatabase(stub)
+	// Load the PEP list entries sharing a name prefix with the customer
+	fullName := fmt.Sprintf("%s %s", customerData.FirstName, customerData.LastName)
+	pepDatabase, err := h.pepListManager.candidateEntries(stub, fullName)
 	if err != nil {
-		return result, fmt.Errorf("failed to get PEP database: %v", err)
+		return result, fmt.Errorf("failed to get PEP entries: %w", err)
 	}
 
 	// Screen against PEP database
-	matches, err := h.screenAgainstPEPDatabase(customerData, pepDatabase)
+	matches, err := h.screenAgainstPEPDatabase(stub, customerData, pepDatabase)
 	if err != nil {
-		return result, fmt.Errorf("PEP screening failed: %v", err)
+		return result, fmt.Errorf("PEP screening failed: %w", err)
 	}
 
 	maxConfidence := 0.0
@@ -343,29 +429,90 @@ func (h *AMLCheckHandler) performPEPScreening(stub shim.ChaincodeStubInterface,
 	return result, nil
 }
 
+// performCounterpartyScreening screens a transaction's counterparty against the sanction lists and
+// the PEP list with the thresholds applied to customers. It returns nil when the transaction names
+// no counterparty.
+func (h *AMLCheckHandler) performCounterpartyScreening(stub shim.ChaincodeStubInterface, transactionData *TransactionAMLData) (*CounterpartyScreenResult, error) {
+	if transactionData == nil || strings.TrimSpace(transactionData.CounterpartyName) == "" {
+		return nil, nil
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &CounterpartyScreenResult{
+		TransactionID:       transactionData.TransactionID,
+		CounterpartyName:    strings.TrimSpace(transactionData.CounterpartyName),
+		CounterpartyCountry: transactionData.CounterpartyCountry,
+		SanctionMatches:     []SanctionMatch{},
+		PEPMatches:          []PEPMatch{},
+		ScreeningDate:       now,
+	}
+	counterparty := &CustomerAMLData{FirstName: result.CounterpartyName, Nationality: result.CounterpartyCountry}
+
+	matches, _, err := h.screenAgainstSanctionEntries(stub, counterparty)
+	if err != nil {
+		return nil, err
+	}
+	sanctionConfidence := 0.0
+	for _, match := range matches {
+		if match.Confidence > sanctionConfidence {
+			sanctionConfidence = match.Confidence
+		}
+		result.SanctionMatches = append(result.SanctionMatches, match)
+	}
+	result.SanctionMatch = len(result.SanctionMatches) > 0 && sanctionConfidence >= 0.8
+
+	pepResult, err := h.performPEPScreening(stub, counterparty)
+	if err != nil {
+		return nil, err
+	}
+	result.PEPMatches = pepResult.Matches
+	result.PEPMatch = pepResult.IsMatch
+
+	result.MatchConfidence = sanctionConfidence
+	if pepResult.MatchConfidence > result.MatchConfidence {
+		result.MatchConfidence = pepResult.MatchConfidence
+	}
+	result.IsMatch = result.SanctionMatch || result.PEPMatch
+
+	return result, nil
+}
+
 // assessRiskFactors identifies and assesses various risk factors
 func (h *AMLCheckHandler) assessRiskFactors(stub shim.ChaincodeStubInterface, customerData *CustomerAMLData, transactionData *TransactionAMLData) ([]RiskFactor, error) {
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
 	var riskFactors []RiskFactor
 
 	// 1. Geographic risk assessment
-	geoRisk := h.assessGeographicRisk(customerData)
+	geoRisk, err := h.assessGeographicRisk(stub, customerData, now)
+	if err != nil {
+		return nil, err
+	}
 	if geoRisk != nil {
 		riskFactors = append(riskFactors, *geoRisk)
 	}
 
 	// 2. Transaction pattern risk (if transaction data provided)
 	if transactionData != nil {
-		transactionRisk := h.assessTransactionRisk(transactionData)
+		transactionRisk := h.assessTransactionRisk(stub, transactionData, now)
 		if transactionRisk != nil {
 			riskFactors = append(riskFactors, *transactionRisk)
 		}
 	}
 
 	// 3. Customer profile risk
-	profileRisk := h.assessCustomerProfileRisk(customerData)
-	if profileRisk != nil {
-		riskFactors = append(riskFactors, *profileRisk)
+	profileRisks, err := h.assessCustomerProfileRisk(stub, customerData, now)
+	if err != nil {
+		return nil, err
 	}
+	riskFactors = append(riskFactors, profileRisks...)
 
 	// 4. Historical risk assessment
 	historicalRisk, err := h.assessHistoricalRisk(stub, customerData.NationalID)
@@ -376,48 +523,16 @@ func (h *AMLCheckHandler) assessRiskFactors(stub shim.ChaincodeStubInterface, cu
 	return riskFactors, nil
 }
 
-// calculateOverallRiskScore calculates the overall risk score based on all factors
-func (h *AMLCheckHandler) calculateOverallRiskScore(result *AMLCheckResult) float64 {
-	score := 0.0
-
-	// Sanction screening weight (40%)
-	if result.SanctionScreenResult.IsMatch {
-		score += result.SanctionScreenResult.MatchConfidence * 0.4
-	}
-
-	// PEP screening weight (30%)
-	if result.PEPScreenResult.IsMatch {
-		score += result.PEPScreenResult.MatchConfidence * 0.3
-	}
-
-	// Risk factors weight (30%)
-	if len(result.RiskFactors) > 0 {
-		totalRiskScore := 0.0
-		for _, factor := range result.RiskFactors {
-			totalRiskScore += factor.RiskScore
-		}
-		avgRiskScore := totalRiskScore / float64(len(result.RiskFactors))
-		score += (avgRiskScore / 100.0) * 0.3 // Normalize to 0-1 range
-	}
-
-	// Ensure score is between 0 and 1
-	if score > 1.0 {
-		score = 1.0
-	}
-
-	return score
+// calculateOverallRiskScore calculates the overall risk score based on all factors, weighted by
+// the risk model
+func (h *AMLCheckHandler) calculateOverallRiskScore(result *AMLCheckResult, model *RiskModel) float64 {
+	return model.overallRiskScore(result)
 }
 
-// determineRiskLevel determines the risk level based on the overall risk score
-func (h *AMLCheckHandler) determineRiskLevel(riskScore float64) RiskLevel {
-	if riskScore >= 0.8 {
-		return RiskLevelCritical
-	} else if riskScore >= 0.6 {
-		return RiskLevelHigh
-	} else if riskScore >= 0.3 {
-		return RiskLevelMedium
-	}
-	return RiskLevelLow
+// determineRiskLevel determines the risk level based on the overall risk score and the risk
+// model's thresholds
+func (h *AMLCheckHandler) determineRiskLevel(riskScore float64, model *RiskModel) RiskLevel {
+	return model.riskLevel(riskScore)
 }
 
 // determineAMLStatus determines the AML status based on screening results
@@ -427,7 +542,9 @@ func (h *AMLCheckHandler) determineAMLStatus(result *AMLCheckResult) validation.
 		return validation.AMLStatusBlocked
 	} else if result.RiskLevel == RiskLevelHigh {
 		return validation.AMLStatusFlagged
-	} else if result.SanctionScreenResult.IsMatch || result.PEPScreenResult.IsMatch {
+	} else if result.SanctionScreenResult.IsMatch || result.PEPScreenResult.IsMatch || result.AdverseMediaResult.IsMatch {
+		return validation.AMLStatusReviewing
+	} else if result.CounterpartyScreenResult != nil && result.CounterpartyScreenResult.IsMatch {
 		return validation.AMLStatusReviewing
 	}
 	return validation.AMLStatusClear
@@ -435,29 +552,38 @@ func (h *AMLCheckHandler) determineAMLStatus(result *AMLCheckResult) validation.
 
 // Helper methods for risk assessment
 
-func (h *AMLCheckHandler) assessGeographicRisk(customerData *CustomerAMLData) *RiskFactor {
-	// High-risk countries list (simplified)
-	highRiskCountries := map[string]float64{
-		"AF": 90, "IR": 85, "KP": 95, "SY": 90, "YE": 80,
-		"SO": 85, "LY": 80, "IQ": 75, "MM": 70, "VE": 65,
+func (h *AMLCheckHandler) assessGeographicRisk(stub shim.ChaincodeStubInterface, customerData *CustomerAMLData, now time.Time) (*RiskFactor, error) {
+	// High-risk countries from the country risk table
+	countryCode, err := normalizeCountryCode(customerData.Country)
+	if err != nil {
+		return nil, nil
+	}
+	risk, err := countryRisk(stub, countryCode)
+	if err != nil {
+		return nil, err
 	}
 
-	if riskScore, isHighRisk := highRiskCountries[customerData.Country]; isHighRisk {
+	if risk.RiskScore > 0 {
 		return &RiskFactor{
-			FactorID:     utils.GenerateID("RISK_GEO"),
+			FactorID:     services.GenerateDeterministicID(stub, "RISK_GEO"),
 			Category:     "GEOGRAPHIC",
 			Description:  fmt.Sprintf("Customer from high-risk country: %s", customerData.Country),
-			RiskScore:    riskScore,
+			RiskScore:    risk.RiskScore,
 			Severity:     "HIGH",
-			Evidence:     fmt.Sprintf("Country code: %s", customerData.Country),
-			DetectedDate: time.Now(),
-		}
+			Evidence:     fmt.Sprintf("Country code: %s, country risk version: %d", countryCode, risk.Version),
+			DetectedDate: now,
+			CatalogReference: &RiskCatalogReference{
+				Catalog: riskCatalogCountry,
+				Code:    countryCode,
+				Version: risk.Version,
+			},
+		}, nil
 	}
 
-	return nil
+	return nil, nil
 }
 
-func (h *AMLCheckHandler) assessTransactionRisk(transactionData *TransactionAMLData) *RiskFactor {
+func (h *AMLCheckHandler) assessTransactionRisk(stub shim.ChaincodeStubInterface, transactionData *TransactionAMLData, now time.Time) *RiskFactor {
 	// High-value transaction threshold
 	if transactionData.Amount > 10000 {
 		severity := "MEDIUM"
@@ -474,43 +600,62 @@ func (h *AMLCheckHandler) assessTransactionRisk(transactionData *TransactionAMLD
 		}
 
 		return &RiskFactor{
-			FactorID:     utils.GenerateID("RISK_TXN"),
+			FactorID:     services.GenerateDeterministicID(stub, "RISK_TXN"),
 			Category:     "TRANSACTION",
 			Description:  fmt.Sprintf("High-value transaction: %.2f %s", transactionData.Amount, transactionData.Currency),
 			RiskScore:    riskScore,
 			Severity:     severity,
 			Evidence:     fmt.Sprintf("Transaction ID: %s, Amount: %.2f", transactionData.TransactionID, transactionData.Amount),
-			DetectedDate: time.Now(),
+			DetectedDate: now,
 		}
 	}
 
 	return nil
 }
 
-func (h *AMLCheckHandler) assessCustomerProfileRisk(customerData *CustomerAMLData) *RiskFactor {
-	// High-risk occupations
-	highRiskOccupations := map[string]float64{
-		"POLITICIAN":     80,
-		"ARMS_DEALER":    95,
-		"CASINO_OWNER":   70,
-		"MONEY_CHANGER":  65,
-		"DIPLOMAT":       60,
+func (h *AMLCheckHandler) assessCustomerProfileRisk(stub shim.ChaincodeStubInterface, customerData *CustomerAMLData, now time.Time) ([]RiskFactor, error) {
+	// High-risk occupations and industries from the risk catalogs in force
+	profiles := []struct {
+		catalogType RiskCatalogType
+		value       string
+		idPrefix    string
+		label       string
+	}{
+		{RiskCatalogOccupation, customerData.Occupation, "RISK_PROF", "Occupation"},
+		{RiskCatalogIndustry, customerData.Industry, "RISK_IND", "Industry"},
 	}
 
-	occupation := strings.ToUpper(customerData.Occupation)
-	if riskScore, isHighRisk := highRiskOccupations[occupation]; isHighRisk {
-		return &RiskFactor{
-			FactorID:     utils.GenerateID("RISK_PROF"),
+	var riskFactors []RiskFactor
+	for _, profile := range profiles {
+		code := normalizeCatalogCode(profile.value)
+		if code == "" {
+			continue
+		}
+		entry, err := riskCatalogEntryInForce(stub, profile.catalogType, code, now)
+		if err != nil {
+			return nil, err
+		}
+		if entry == nil || !entry.IsActive {
+			continue
+		}
+		riskFactors = append(riskFactors, RiskFactor{
+			FactorID:     services.GenerateDeterministicID(stub, profile.idPrefix),
 			Category:     "PROFILE",
-			Description:  fmt.Sprintf("High-risk occupation: %s", customerData.Occupation),
-			RiskScore:    riskScore,
+			Description:  fmt.Sprintf("High-risk %s: %s", strings.ToLower(profile.label), profile.value),
+			RiskScore:    entry.RiskScore,
 			Severity:     "HIGH",
-			Evidence:     fmt.Sprintf("Occupation: %s", customerData.Occupation),
-			DetectedDate: time.Now(),
-		}
+			Evidence:     fmt.Sprintf("%s: %s, catalog version: %d", profile.label, profile.value, entry.Version),
+			DetectedDate: now,
+			CatalogReference: &RiskCatalogReference{
+				Catalog:       string(entry.CatalogType),
+				Code:          entry.Code,
+				Version:       entry.Version,
+				EffectiveDate: entry.EffectiveDate,
+			},
+		})
 	}
 
-	return nil
+	return riskFactors, nil
 }
 
 func (h *AMLCheckHandler) assessHistoricalRisk(stub shim.ChaincodeStubInterface, nationalID string) (*RiskFactor, error) {
@@ -519,66 +664,55 @@ func (h *AMLCheckHandler) assessHistoricalRisk(stub shim.ChaincodeStubInterface,
 	return nil, nil
 }
 
-// Sanction list management methods
-
-func (h *AMLCheckHandler) getActiveSanctionLists(stub shim.ChaincodeStubInterface) ([]SanctionList, error) {
-	// In a real implementation, this would query the blockchain for active sanction lists
-	// For now, return mock data
-	return []SanctionList{
-		{
-			ListID:      "OFAC_SDN",
-			ListName:    "OFAC Specially Designated Nationals",
-			Source:      "US Treasury OFAC",
-			LastUpdated: time.Now().AddDate(0, 0, -1),
-			IsActive:    true,
-		},
-		{
-			ListID:      "UN_SANCTIONS",
-			ListName:    "UN Security Council Sanctions",
-			Source:      "United Nations",
-			LastUpdated: time.Now().AddDate(0, 0, -2),
-			IsActive:    true,
-		},
-	}, nil
-}
+// Sanction list screening methods
 
-func (h *AMLCheckHandler) screenAgainstSanctionList(stub shim.ChaincodeStubInterface, customerData *CustomerAMLData, list SanctionList) ([]SanctionMatch, error) {
-	// In a real implementation, this would perform fuzzy matching against the sanction list
-	// For demonstration, we'll check against some mock entries
-	
-	mockSanctionEntries := []SanctionEntry{
-		{
-			EntryID:     "SDN_001",
-			Name:        "John Doe",
-			Aliases:     []string{"Johnny Doe", "J. Doe"},
-			DateOfBirth: time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC),
-			Nationality: "US",
-		},
+// screenAgainstSanctionEntries matches a name against the active sanction entries on the ledger,
+// both those added one by one and those imported into managed lists. It also returns the lists
+// screened: every active managed list and the list of each candidate entry.
+func (h *AMLCheckHandler) screenAgainstSanctionEntries(stub shim.ChaincodeStubInterface, customerData *CustomerAMLData) ([]SanctionMatch, []string, error) {
+	fullName := strings.TrimSpace(fmt.Sprintf("%s %s", customerData.FirstName, customerData.LastName))
+
+	listsScreened, err := h.sanctionListManager.activeListIDs(stub)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get sanction lists: %w", err)
+	}
+	candidates, err := CandidateSanctionEntries(stub, fullName)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get sanction entries: %w", err)
 	}
 
 	var matches []SanctionMatch
+	for _, entry := range candidates {
+		if !entry.IsActive {
+			continue
+		}
+		listsScreened = appendUnique(listsScreened, entry.listKey())
+
+		// Match on the closest of the entry's name and aliases
+		matchedName, confidence := entry.EntityName, h.calculateNameMatchConfidence(fullName, entry.EntityName)
+		for _, alias := range entry.Aliases {
+			if aliasConfidence := h.calculateNameMatchConfidence(fullName, alias); aliasConfidence > confidence {
+				matchedName, confidence = alias, aliasConfidence
+			}
+		}
 
-	fullName := fmt.Sprintf("%s %s", customerData.FirstName, customerData.LastName)
-	
-	for _, entry := range mockSanctionEntries {
-		// Simple name matching (in reality, this would use sophisticated fuzzy matching)
-		confidence := h.calculateNameMatchConfidence(fullName, entry.Name)
-		
 		if confidence >= 0.7 { // 70% threshold for potential match
+			dobMatch := entry.DateOfBirth != "" && entry.DateOfBirth == customerData.DateOfBirth.Format("2006-01-02")
 			matches = append(matches, SanctionMatch{
-				MatchID:        utils.GenerateID("MATCH"),
-				ListName:       list.ListName,
-				MatchedName:    entry.Name,
+				MatchID:        services.GenerateDeterministicID(stub, "MATCH"),
+				ListName:       entry.ListName,
+				MatchedName:    matchedName,
 				MatchType:      "FUZZY",
 				Confidence:     confidence,
 				MatchedFields:  []string{"name"},
 				ListEntryID:    entry.EntryID,
-				AdditionalInfo: fmt.Sprintf("DOB match: %v", entry.DateOfBirth.Equal(customerData.DateOfBirth)),
+				AdditionalInfo: fmt.Sprintf("DOB match: %v", dobMatch),
 			})
 		}
 	}
 
-	return matches, nil
+	sort.Strings(listsScreened)
+	return matches, listsScreened, nil
 }
 
 func (h *AMLCheckHandler) calculateNameMatchConfidence(name1, name2 string) float64 {
@@ -651,63 +785,24 @@ func min(a, b, c int) int {
 	return c
 }
 
-// Additional helper methods and structures will be added in the next part...// Sa
-nctionList represents a sanction list
-type SanctionList struct {
-	ListID      string    `json:"listID"`
-	ListName    string    `json:"listName"`
-	Source      string    `json:"source"`
-	LastUpdated time.Time `json:"lastUpdated"`
-	IsActive    bool      `json:"isActive"`
-}
-
-// SanctionEntry represents an entry in a sanction list
-type SanctionEntry struct {
-	EntryID     string    `json:"entryID"`
-	Name        string    `json:"name"`
-	Aliases     []string  `json:"aliases"`
-	DateOfBirth time.Time `json:"dateOfBirth"`
-	Nationality string    `json:"nationality"`
-	Address     string    `json:"address,omitempty"`
-	Reason      string    `json:"reason,omitempty"`
-}
-
-// PEP database methods
-func (h *AMLCheckHandler) getPEPDatabase(stub shim.ChaincodeStubInterface) ([]PEPEntry, error) {
-	// Mock PEP database - in reality, this would be loaded from external sources
-	return []PEPEntry{
-		{
-			EntryID:     "PEP_001",
-			Name:        "Jane Smith",
-			Position:    "Minister of Finance",
-			Country:     "US",
-			RiskCategory: "HIGH",
-			LastUpdated: time.Now().AddDate(0, -1, 0),
-		},
-	}, nil
-}
-
-// PEPEntry represents a Politically Exposed Person entry
-type PEPEntry struct {
-	EntryID      string    `json:"entryID"`
-	Name         string    `json:"name"`
-	Position     string    `json:"position"`
-	Country      string    `json:"country"`
-	RiskCategory string    `json:"riskCategory"`
-	LastUpdated  time.Time `json:"lastUpdated"`
-}
-
-func (h *AMLCheckHandler) screenAgainstPEPDatabase(customerData *CustomerAMLData, pepDatabase []PEPEntry) ([]PEPMatch, error) {
+func (h *AMLCheckHandler) screenAgainstPEPDatabase(stub shim.ChaincodeStubInterface, customerData *CustomerAMLData, pepDatabase []PEPEntry) ([]PEPMatch, error) {
 	var matches []PEPMatch
 	
 	fullName := fmt.Sprintf("%s %s", customerData.FirstName, customerData.LastName)
 	
 	for _, entry := range pepDatabase {
+		// Match on the closest of the entry's name and aliases
 		confidence := h.calculateNameMatchConfidence(fullName, entry.Name)
+		for _, alias := range entry.Aliases {
+			if aliasConfidence := h.calculateNameMatchConfidence(fullName, alias); aliasConfidence > confidence {
+				confidence = aliasConfidence
+			}
+		}
 		
 		if confidence >= 0.8 { // 80% threshold for PEP match
 			matches = append(matches, PEPMatch{
-				MatchID:      utils.GenerateID("PEP_MATCH"),
+				MatchID:      services.GenerateDeterministicID(stub, "PEP_MATCH"),
+				ListEntryID:  entry.EntryID,
 				MatchedName:  entry.Name,
 				Position:     entry.Position,
 				Country:      entry.Country,
@@ -735,6 +830,14 @@ func (h *AMLCheckHandler) generateRecommendations(result *AMLCheckResult) []stri
 		recommendations = append(recommendations, "Obtain senior management approval for relationship")
 	}
 	
+	if result.AdverseMediaResult.IsMatch {
+		recommendations = append(recommendations, fmt.Sprintf("Review %d adverse media report(s), highest severity %s", len(result.AdverseMediaResult.Matches), result.AdverseMediaResult.HighestSeverity))
+	}
+	
+	if result.CounterpartyScreenResult != nil && result.CounterpartyScreenResult.IsMatch {
+		recommendations = append(recommendations, "Hold the transaction until the counterparty watchlist match is reviewed")
+	}
+	
 	if result.RiskLevel == RiskLevelHigh || result.RiskLevel == RiskLevelCritical {
 		recommendations = append(recommendations, "Conduct enhanced monitoring of all transactions")
 		recommendations = append(recommendations, "Consider relationship termination if risks cannot be mitigated")
@@ -747,38 +850,53 @@ func (h *AMLCheckHandler) generateRecommendations(result *AMLCheckResult) []stri
 	return recommendations
 }
 
-func (h *AMLCheckHandler) generateRequiredActions(result *AMLCheckResult, actorID string) []RequiredAction {
+func (h *AMLCheckHandler) generateRequiredActions(stub shim.ChaincodeStubInterface, result *AMLCheckResult, actorID string) []RequiredAction {
 	var actions []RequiredAction
 	
 	if result.SanctionScreenResult.IsMatch {
 		actions = append(actions, RequiredAction{
-			ActionID:    utils.GenerateID("ACTION"),
+			ActionID:    services.GenerateDeterministicID(stub, "ACTION"),
 			ActionType:  "SANCTION_REVIEW",
 			Description: "Review and investigate sanction list match",
 			Priority:    "CRITICAL",
-			DueDate:     time.Now().Add(24 * time.Hour), // 24 hours
+			DueDate:     result.CheckDate.Add(24 * time.Hour), // 24 hours
 			Status:      "PENDING",
 		})
 	}
 	
 	if result.PEPScreenResult.IsMatch {
 		actions = append(actions, RequiredAction{
-			ActionID:    utils.GenerateID("ACTION"),
+			ActionID:    services.GenerateDeterministicID(stub, "ACTION"),
 			ActionType:  "PEP_APPROVAL",
 			Description: "Obtain senior management approval for PEP relationship",
 			Priority:    "HIGH",
-			DueDate:     time.Now().Add(72 * time.Hour), // 72 hours
+			DueDate:     result.CheckDate.Add(72 * time.Hour), // 72 hours
+			Status:      "PENDING",
+		})
+	}
+	
+	if result.CounterpartyScreenResult != nil && result.CounterpartyScreenResult.IsMatch {
+		priority := "HIGH"
+		if result.CounterpartyScreenResult.SanctionMatch {
+			priority = "CRITICAL"
+		}
+		actions = append(actions, RequiredAction{
+			ActionID:    services.GenerateDeterministicID(stub, "ACTION"),
+			ActionType:  "COUNTERPARTY_REVIEW",
+			Description: fmt.Sprintf("Review watchlist match on counterparty %s", result.CounterpartyScreenResult.CounterpartyName),
+			Priority:    priority,
+			DueDate:     result.CheckDate.Add(24 * time.Hour), // 24 hours
 			Status:      "PENDING",
 		})
 	}
 	
 	if result.RiskLevel == RiskLevelHigh || result.RiskLevel == RiskLevelCritical {
 		actions = append(actions, RequiredAction{
-			ActionID:    utils.GenerateID("ACTION"),
+			ActionID:    services.GenerateDeterministicID(stub, "ACTION"),
 			ActionType:  "ENHANCED_MONITORING",
 			Description: "Implement enhanced transaction monitoring",
 			Priority:    "HIGH",
-			DueDate:     time.Now().Add(48 * time.Hour), // 48 hours
+			DueDate:     result.CheckDate.Add(48 * time.Hour), // 48 hours
 			Status:      "PENDING",
 		})
 	}
@@ -787,9 +905,7 @@ func (h *AMLCheckHandler) generateRequiredActions(result *AMLCheckResult, actorI
 }
 
 // Utility methods
-func (h *AMLCheckHandler) calculateExpiryDate(checkType AMLCheckType) time.Time {
-	now := time.Now()
-	
+func (h *AMLCheckHandler) calculateExpiryDate(checkType AMLCheckType, now time.Time) time.Time {
 	switch checkType {
 	case AMLCheckTypeCustomerOnboarding:
 		return now.AddDate(1, 0, 0) // 1 year
@@ -806,11 +922,11 @@ func (h *AMLCheckHandler) calculateExpiryDate(checkType AMLCheckType) time.Time
 
 func (h *AMLCheckHandler) validateAMLCheckRequest(req *AMLCheckRequest) error {
 	if req.CustomerID == "" {
-		return fmt.Errorf("customerID is required")
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "customerID", "customerID is required")
 	}
 	
 	if req.ActorID == "" {
-		return fmt.Errorf("actorID is required")
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
 	}
 	
 	if req.CustomerData.FirstName == "" || req.CustomerData.LastName == "" {
@@ -838,19 +954,58 @@ func (h *AMLCheckHandler) validateAMLCheckRequest(req *AMLCheckRequest) error {
 	}
 	
 	if !validType {
-		return fmt.Errorf("invalid check type: %s", req.CheckType)
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid check type: %s", req.CheckType)
 	}
 	
 	return nil
 }
 
+// recordCheck stores a check result, its compliance event and, for high-risk results, an
+// escalation as one write batch, so a failure in any step leaves none of them behind
+func (h *AMLCheckHandler) recordCheck(stub shim.ChaincodeStubInterface, result *AMLCheckResult, actorID string) error {
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return err
+	}
+	batch := services.NewWriteBatch()
+
+	if err := h.storeCheckResult(stub, batch, result); err != nil {
+		return err
+	}
+
+	if err := h.recordComplianceEvent(stub, batch, result, actorID, now); err != nil {
+		return fmt.Errorf("failed to record compliance event: %w", err)
+	}
+	events := int64(1)
+
+	if result.CounterpartyScreenResult != nil && result.CounterpartyScreenResult.IsMatch {
+		if err := h.recordCounterpartyMatchEvent(stub, batch, result, actorID, now); err != nil {
+			return fmt.Errorf("failed to record counterparty match event: %w", err)
+		}
+		events++
+	}
+
+	// The check's events are counted together so the transaction increments the metric once
+	if err := services.IncrementCounterBy(stub, config.MetricComplianceEvents, events); err != nil {
+		return err
+	}
+
+	if result.RiskLevel == RiskLevelHigh || result.RiskLevel == RiskLevelCritical {
+		if err := h.handleRiskEscalation(stub, batch, result, actorID, now); err != nil {
+			return fmt.Errorf("failed to handle risk escalation: %w", err)
+		}
+	}
+
+	return batch.Flush(stub)
+}
+
 // Compliance event recording
-func (h *AMLCheckHandler) recordComplianceEvent(stub shim.ChaincodeStubInterface, result *AMLCheckResult, actorID string) error {
-	eventID := utils.GenerateID(config.ComplianceEventPrefix)
+func (h *AMLCheckHandler) recordComplianceEvent(stub shim.ChaincodeStubInterface, batch *services.WriteBatch, result *AMLCheckResult, actorID string, now time.Time) error {
+	eventID := services.GenerateDeterministicID(stub, config.ComplianceEventPrefix)
 	
 	event := &domain.ComplianceEvent{
 		EventID:            eventID,
-		Timestamp:          time.Now(),
+		Timestamp:          now,
 		RuleID:             "AML_SCREENING_RULE",
 		RuleVersion:        "1.0",
 		AffectedEntityID:   result.CustomerID,
@@ -864,12 +1019,14 @@ func (h *AMLCheckHandler) recordComplianceEvent(stub shim.ChaincodeStubInterface
 			"riskLevel":       result.RiskLevel,
 			"sanctionMatch":   result.SanctionScreenResult.IsMatch,
 			"pepMatch":        result.PEPScreenResult.IsMatch,
+			"adverseMediaMatch": result.AdverseMediaResult.IsMatch,
+			"counterpartyMatch": result.CounterpartyScreenResult != nil && result.CounterpartyScreenResult.IsMatch,
 			"riskFactorCount": len(result.RiskFactors),
 		},
 		ExecutionResult: domain.RuleExecutionResult{
 			RuleID:        "AML_SCREENING_RULE",
-			ExecutionID:   utils.GenerateID("EXEC"),
-			Timestamp:     time.Now(),
+			ExecutionID:   services.GenerateDeterministicID(stub, "EXEC"),
+			Timestamp:     now,
 			Success:       true,
 			Passed:        result.Status == validation.AMLStatusClear,
 			Score:         result.OverallRiskScore,
@@ -882,18 +1039,78 @@ func (h *AMLCheckHandler) recordComplianceEvent(stub shim.ChaincodeStubInterface
 	
 	// Store compliance event
 	eventKey := fmt.Sprintf("COMPLIANCE_EVENT_%s", eventID)
-	if err := h.persistenceService.Put(stub, eventKey, event); err != nil {
-		return fmt.Errorf("failed to store compliance event: %v", err)
+	if err := batch.Put(eventKey, event); err != nil {
+		return fmt.Errorf("failed to store compliance event: %w", err)
 	}
 	
 	// Emit event if emitter is available
-	if h.eventEmitter != nil {
-		return h.eventEmitter.EmitComplianceEvent(stub, event)
-	}
+	h.emitComplianceEvent(batch, event)
 	
 	return nil
 }
 
+// recordCounterpartyMatchEvent records an alerted compliance event of its own for a counterparty
+// watchlist hit, so counterparty hits can be monitored apart from customer screening outcomes
+func (h *AMLCheckHandler) recordCounterpartyMatchEvent(stub shim.ChaincodeStubInterface, batch *services.WriteBatch, result *AMLCheckResult, actorID string, now time.Time) error {
+	counterparty := result.CounterpartyScreenResult
+	severity := domain.PriorityHigh
+	if counterparty.SanctionMatch {
+		severity = domain.PriorityCritical
+	}
+
+	eventID := services.GenerateDeterministicID(stub, config.ComplianceEventPrefix)
+	event := &domain.ComplianceEvent{
+		EventID:            eventID,
+		Timestamp:          now,
+		RuleID:             "AML_COUNTERPARTY_SCREENING_RULE",
+		RuleVersion:        "1.0",
+		AffectedEntityID:   result.CustomerID,
+		AffectedEntityType: "Customer",
+		EventType:          "AML_COUNTERPARTY_MATCH",
+		Severity:           severity,
+		Details: map[string]interface{}{
+			"checkID":             result.CheckID,
+			"transactionID":       counterparty.TransactionID,
+			"counterpartyName":    counterparty.CounterpartyName,
+			"counterpartyCountry": counterparty.CounterpartyCountry,
+			"sanctionMatch":       counterparty.SanctionMatch,
+			"pepMatch":            counterparty.PEPMatch,
+			"matchConfidence":     counterparty.MatchConfidence,
+		},
+		ExecutionResult: domain.RuleExecutionResult{
+			RuleID:      "AML_COUNTERPARTY_SCREENING_RULE",
+			ExecutionID: services.GenerateDeterministicID(stub, "EXEC"),
+			Timestamp:   now,
+			Success:     true,
+			Passed:      false,
+			Score:       counterparty.MatchConfidence,
+			Details:     map[string]interface{}{"counterpartyResult": counterparty},
+		},
+		ActorID:          actorID,
+		IsAlerted:        true,
+		ResolutionStatus: "OPEN",
+	}
+
+	eventKey := fmt.Sprintf("COMPLIANCE_EVENT_%s", eventID)
+	if err := batch.Put(eventKey, event); err != nil {
+		return fmt.Errorf("failed to store compliance event: %w", err)
+	}
+
+	h.emitComplianceEvent(batch, event)
+
+	return nil
+}
+
+// emitComplianceEvent queues a compliance event for emission once the batch is flushed
+func (h *AMLCheckHandler) emitComplianceEvent(batch *services.WriteBatch, event *domain.ComplianceEvent) {
+	if h.eventEmitter == nil {
+		return
+	}
+	batch.Emit(func(stub shim.ChaincodeStubInterface) error {
+		return h.eventEmitter.EmitComplianceEvent(stub, event)
+	})
+}
+
 func (h *AMLCheckHandler) mapRiskLevelToSeverity(riskLevel RiskLevel) domain.ComplianceRulePriority {
 	switch riskLevel {
 	case RiskLevelCritical:
@@ -908,8 +1125,8 @@ func (h *AMLCheckHandler) mapRiskLevelToSeverity(riskLevel RiskLevel) domain.Com
 }
 
 // Risk escalation handling
-func (h *AMLCheckHandler) handleRiskEscalation(stub shim.ChaincodeStubInterface, result *AMLCheckResult, actorID string) error {
-	escalationID := utils.GenerateID("ESCALATION")
+func (h *AMLCheckHandler) handleRiskEscalation(stub shim.ChaincodeStubInterface, batch *services.WriteBatch, result *AMLCheckResult, actorID string, now time.Time) error {
+	escalationID := services.GenerateDeterministicID(stub, "ESCALATION")
 	
 	escalation := map[string]interface{}{
 		"escalationID":   escalationID,
@@ -918,7 +1135,7 @@ func (h *AMLCheckHandler) handleRiskEscalation(stub shim.ChaincodeStubInterface,
 		"riskLevel":      result.RiskLevel,
 		"riskScore":      result.OverallRiskScore,
 		"escalatedBy":    actorID,
-		"escalationDate": time.Now(),
+		"escalationDate": now,
 		"status":         "OPEN",
 		"priority":       "HIGH",
 		"assignedTo":     "COMPLIANCE_TEAM",
@@ -928,15 +1145,13 @@ func (h *AMLCheckHandler) handleRiskEscalation(stub shim.ChaincodeStubInterface,
 	
 	// Store escalation
 	escalationKey := fmt.Sprintf("AML_ESCALATION_%s", escalationID)
-	if err := h.persistenceService.Put(stub, escalationKey, escalation); err != nil {
-		return fmt.Errorf("failed to store escalation: %v", err)
+	if err := batch.Put(escalationKey, escalation); err != nil {
+		return fmt.Errorf("failed to store escalation: %w", err)
 	}
 	
 	// Create escalation index
 	customerEscalationKey := fmt.Sprintf("CUSTOMER_ESCALATION_%s_%s", result.CustomerID, escalationID)
-	if err := stub.PutState(customerEscalationKey, []byte(escalationID)); err != nil {
-		return fmt.Errorf("failed to create escalation index: %v", err)
-	}
+	batch.PutRaw(customerEscalationKey, []byte(escalationID))
 	
 	return nil
 }
@@ -944,7 +1159,7 @@ func (h *AMLCheckHandler) handleRiskEscalation(stub shim.ChaincodeStubInterface,
 // UpdateAMLStatus updates AML status with enhanced validation
 func (h *AMLCheckHandler) UpdateAMLStatus(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
 	if len(args) != 1 {
-		return nil, fmt.Errorf("incorrect number of arguments. Expected 1, got %d", len(args))
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
 	}
 
 	var req struct {
@@ -955,36 +1170,39 @@ func (h *AMLCheckHandler) UpdateAMLStatus(stub shim.ChaincodeStubInterface, args
 	}
 
 	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
-		return nil, fmt.Errorf("failed to parse AML status update request: %v", err)
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse AML status update request: %v", err)
 	}
 
 	// Get existing AML result
 	resultKey := fmt.Sprintf("AML_RESULT_%s", req.CheckID)
 	var result AMLCheckResult
 	if err := h.persistenceService.Get(stub, resultKey, &result); err != nil {
-		return nil, fmt.Errorf("AML check result not found: %v", err)
+		return nil, fmt.Errorf("AML check result not found: %w", err)
 	}
 
 	// Validate status transition
 	if err := h.validateAMLStatusTransition(result.Status, req.NewStatus); err != nil {
-		return nil, fmt.Errorf("invalid status transition: %v", err)
+		return nil, fmt.Errorf("invalid status transition: %w", err)
 	}
 
 	// Update result
 	result.Status = req.NewStatus
 	result.Notes = req.Notes
 	result.ReviewedBy = req.ActorID
-	now := time.Now()
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
 	result.ReviewDate = &now
 
 	// Store updated result
 	if err := h.persistenceService.Put(stub, resultKey, &result); err != nil {
-		return nil, fmt.Errorf("failed to update AML result: %v", err)
+		return nil, fmt.Errorf("failed to update AML result: %w", err)
 	}
 
 	// Record compliance event for status change
 	if err := h.recordStatusChangeEvent(stub, &result, req.ActorID); err != nil {
-		return nil, fmt.Errorf("failed to record status change event: %v", err)
+		return nil, fmt.Errorf("failed to record status change event: %w", err)
 	}
 
 	return json.Marshal(&result)
@@ -1000,7 +1218,7 @@ func (h *AMLCheckHandler) validateAMLStatusTransition(currentStatus, newStatus v
 
 	allowedTransitions, exists := validTransitions[currentStatus]
 	if !exists {
-		return fmt.Errorf("unknown current status: %s", currentStatus)
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "unknown current status: %s", currentStatus)
 	}
 
 	for _, allowed := range allowedTransitions {
@@ -1009,15 +1227,20 @@ func (h *AMLCheckHandler) validateAMLStatusTransition(currentStatus, newStatus v
 		}
 	}
 
-	return fmt.Errorf("invalid transition from %s to %s", currentStatus, newStatus)
+	return services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid transition from %s to %s", currentStatus, newStatus)
 }
 
 func (h *AMLCheckHandler) recordStatusChangeEvent(stub shim.ChaincodeStubInterface, result *AMLCheckResult, actorID string) error {
-	eventID := utils.GenerateID(config.ComplianceEventPrefix)
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return err
+	}
+
+	eventID := services.GenerateDeterministicID(stub, config.ComplianceEventPrefix)
 	
 	event := &domain.ComplianceEvent{
 		EventID:            eventID,
-		Timestamp:          time.Now(),
+		Timestamp:          now,
 		RuleID:             "AML_STATUS_UPDATE_RULE",
 		RuleVersion:        "1.0",
 		AffectedEntityID:   result.CustomerID,
@@ -1034,6 +1257,10 @@ func (h *AMLCheckHandler) recordStatusChangeEvent(stub shim.ChaincodeStubInterfa
 		ResolutionStatus: "OPEN",
 	}
 	
+	if err := services.IncrementCounter(stub, config.MetricComplianceEvents); err != nil {
+		return err
+	}
+
 	// Store compliance event
 	eventKey := fmt.Sprintf("COMPLIANCE_EVENT_%s", eventID)
 	return h.persistenceService.Put(stub, eventKey, event)
@@ -1042,7 +1269,7 @@ func (h *AMLCheckHandler) recordStatusChangeEvent(stub shim.ChaincodeStubInterfa
 // GetAMLReport retrieves comprehensive AML report
 func (h *AMLCheckHandler) GetAMLReport(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
 	if len(args) != 1 {
-		return nil, fmt.Errorf("incorrect number of arguments. Expected 1, got %d", len(args))
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
 	}
 
 	var req struct {
@@ -1053,11 +1280,11 @@ func (h *AMLCheckHandler) GetAMLReport(stub shim.ChaincodeStubInterface, args []
 	}
 
 	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
-		return nil, fmt.Errorf("failed to parse AML report request: %v", err)
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse AML report request: %v", err)
 	}
 
 	if req.CustomerID == "" && req.CheckID == "" {
-		return nil, fmt.Errorf("either customerID or checkID must be provided")
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "either customerID or checkID must be provided")
 	}
 
 	var results []AMLCheckResult
@@ -1067,22 +1294,27 @@ func (h *AMLCheckHandler) GetAMLReport(stub shim.ChaincodeStubInterface, args []
 		resultKey := fmt.Sprintf("AML_RESULT_%s", req.CheckID)
 		var result AMLCheckResult
 		if err := h.persistenceService.Get(stub, resultKey, &result); err != nil {
-			return nil, fmt.Errorf("AML check result not found: %v", err)
+			return nil, fmt.Errorf("AML check result not found: %w", err)
 		}
 		results = append(results, result)
 	} else {
 		// Get all results for customer
 		customerResults, err := h.getCustomerAMLResults(stub, req.CustomerID)
 		if err != nil {
-			return nil, fmt.Errorf("failed to get customer AML results: %v", err)
+			return nil, fmt.Errorf("failed to get customer AML results: %w", err)
 		}
 		results = customerResults
 	}
 
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
 	// Generate comprehensive report
 	report := map[string]interface{}{
-		"reportID":      utils.GenerateID("AML_REPORT"),
-		"generatedDate": time.Now(),
+		"reportID":      services.GenerateDeterministicID(stub, "AML_REPORT"),
+		"generatedDate": now,
 		"customerID":    req.CustomerID,
 		"checkID":       req.CheckID,
 		"totalChecks":   len(results),
@@ -1094,10 +1326,11 @@ func (h *AMLCheckHandler) GetAMLReport(stub shim.ChaincodeStubInterface, args []
 }
 
 func (h *AMLCheckHandler) getCustomerAMLResults(stub shim.ChaincodeStubInterface, customerID string) ([]AMLCheckResult, error) {
-	// Query customer AML results using composite key
-	iterator, err := stub.GetStateByPartialCompositeKey("CUSTOMER_AML", []string{customerID})
+	// Query customer AML results through the CUSTOMER_AML_<customerID>_<checkID> index
+	prefix := fmt.Sprintf("CUSTOMER_AML_%s_", customerID)
+	iterator, err := stub.GetStateByRange(prefix, prefix+string(utf8.MaxRune))
 	if err != nil {
-		return nil, fmt.Errorf("failed to get customer AML results: %v", err)
+		return nil, fmt.Errorf("failed to get customer AML results: %w", err)
 	}
 	defer iterator.Close()
 
@@ -1106,7 +1339,7 @@ func (h *AMLCheckHandler) getCustomerAMLResults(stub shim.ChaincodeStubInterface
 	for iterator.HasNext() {
 		response, err := iterator.Next()
 		if err != nil {
-			return nil, fmt.Errorf("failed to iterate AML results: %v", err)
+			return nil, fmt.Errorf("failed to iterate AML results: %w", err)
 		}
 
 		checkID := string(response.Value)
@@ -1132,6 +1365,7 @@ func (h *AMLCheckHandler) generateAMLSummary(results []AMLCheckResult) map[strin
 		"blockedCount":     0,
 		"sanctionMatches":  0,
 		"pepMatches":       0,
+		"counterpartyMatches": 0,
 		"avgRiskScore":     0.0,
 		"highestRiskScore": 0.0,
 		"latestCheck":      time.Time{},
@@ -1165,6 +1399,9 @@ func (h *AMLCheckHandler) generateAMLSummary(results []AMLCheckResult) map[strin
 		if result.PEPScreenResult.IsMatch {
 			summary["pepMatches"] = summary["pepMatches"].(int) + 1
 		}
+		if result.CounterpartyScreenResult != nil && result.CounterpartyScreenResult.IsMatch {
+			summary["counterpartyMatches"] = summary["counterpartyMatches"].(int) + 1
+		}
 
 		// Risk score calculations
 		totalRiskScore += result.OverallRiskScore
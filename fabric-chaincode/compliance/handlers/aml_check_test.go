@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"testing"
 	"time"
 
@@ -9,6 +10,9 @@ import (
 	"github.com/hyperledger/fabric-chaincode-go/shimtest"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/compliance/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
 	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
 )
 
@@ -17,25 +21,77 @@ type MockEventEmitter struct {
 	EmittedEvents []interface{}
 }
 
-func (m *MockEventEmitter) EmitComplianceEvent(stub shim.ChaincodeStubInterface, event interface{}) error {
+func (m *MockEventEmitter) EmitComplianceEvent(stub shim.ChaincodeStubInterface, event *domain.ComplianceEvent) error {
 	m.EmittedEvents = append(m.EmittedEvents, event)
 	return nil
 }
 
-func (m *MockEventEmitter) EmitRuleExecutionEvent(stub shim.ChaincodeStubInterface, result interface{}) error {
+func (m *MockEventEmitter) EmitRuleExecutionEvent(stub shim.ChaincodeStubInterface, result *domain.RuleExecutionResult) error {
 	m.EmittedEvents = append(m.EmittedEvents, result)
 	return nil
 }
 
+// johnDoeSanctionEntry is the OFAC SDN entry the screening tests match against
+func johnDoeSanctionEntry() ComprehensiveSanctionEntry {
+	dateOfBirth := time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC)
+	return ComprehensiveSanctionEntry{
+		EntryID:     "SDN_001",
+		PrimaryName: "John Doe",
+		Aliases:     []string{"Johnny Doe", "J. Doe"},
+		EntityType:  EntityTypeIndividual,
+		FirstName:   "John",
+		LastName:    "Doe",
+		DateOfBirth: &dateOfBirth,
+		Nationality: []string{"US"},
+	}
+}
+
+// seedSanctionList creates a managed sanction list and adds entries to it through list maintenance
+func seedSanctionList(t *testing.T, stub *shimtest.MockStub, listID string, entries ...ComprehensiveSanctionEntry) {
+	t.Helper()
+	manager := NewSanctionListManager(nil)
+	txID := "seed_" + listID
+	stub.MockTransactionStart(txID)
+	defer stub.MockTransactionEnd(txID)
+
+	listBytes, err := json.Marshal(SanctionListDefinition{
+		ListID:       listID,
+		ListName:     listID,
+		Source:       "Test Source",
+		ListType:     SanctionListTypeSDN,
+		Jurisdiction: "US",
+		IsActive:     true,
+		CreatedBy:    "ACTOR_001",
+	})
+	require.NoError(t, err)
+	_, err = manager.CreateSanctionList(stub, []string{string(listBytes)})
+	require.NoError(t, err)
+
+	updateBytes, err := json.Marshal(SanctionListUpdateRequest{
+		ListID:     listID,
+		UpdateType: UpdateTypeAdditions,
+		Entries:    entries,
+		Version:    "1",
+		UpdatedBy:  "ACTOR_001",
+	})
+	require.NoError(t, err)
+	_, err = manager.UpdateSanctionList(stub, []string{string(updateBytes)})
+	require.NoError(t, err)
+}
+
 func TestAMLCheckHandler_PerformAMLCheck(t *testing.T) {
 	stub := shimtest.NewMockStub("aml_test", nil)
+	stub.MockTransactionStart("txid")
 	mockEmitter := &MockEventEmitter{}
 	handler := NewAMLCheckHandler(mockEmitter)
 
+	// Without a sanction or PEP match a single risk factor stays below the baseline model's medium
+	// threshold, so high-risk profiles clear with the factor recorded
 	tests := []struct {
 		name           string
 		request        AMLCheckRequest
 		expectedStatus validation.AMLStatus
+		expectedFactor string // Category of the risk factor the check must record, if any
 		expectedError  bool
 	}{
 		{
@@ -59,7 +115,7 @@ func TestAMLCheckHandler_PerformAMLCheck(t *testing.T) {
 			expectedError:  false,
 		},
 		{
-			name: "High-risk country customer - should be flagged",
+			name: "High-risk country customer - geographic risk recorded",
 			request: AMLCheckRequest{
 				CustomerID: "CUST_002",
 				CustomerData: CustomerAMLData{
@@ -75,11 +131,12 @@ func TestAMLCheckHandler_PerformAMLCheck(t *testing.T) {
 				CheckType: AMLCheckTypeCustomerOnboarding,
 				ActorID:   "ACTOR_001",
 			},
-			expectedStatus: validation.AMLStatusFlagged,
+			expectedStatus: validation.AMLStatusClear,
+			expectedFactor: "GEOGRAPHIC",
 			expectedError:  false,
 		},
 		{
-			name: "High-risk occupation - should be flagged",
+			name: "High-risk occupation - profile risk recorded",
 			request: AMLCheckRequest{
 				CustomerID: "CUST_003",
 				CustomerData: CustomerAMLData{
@@ -95,11 +152,12 @@ func TestAMLCheckHandler_PerformAMLCheck(t *testing.T) {
 				CheckType: AMLCheckTypeCustomerOnboarding,
 				ActorID:   "ACTOR_001",
 			},
-			expectedStatus: validation.AMLStatusFlagged,
+			expectedStatus: validation.AMLStatusClear,
+			expectedFactor: "PROFILE",
 			expectedError:  false,
 		},
 		{
-			name: "High-value transaction - should trigger review",
+			name: "High-value transaction - transaction risk recorded",
 			request: AMLCheckRequest{
 				CustomerID: "CUST_004",
 				CustomerData: CustomerAMLData{
@@ -122,7 +180,8 @@ func TestAMLCheckHandler_PerformAMLCheck(t *testing.T) {
 				CheckType: AMLCheckTypeTransactionBased,
 				ActorID:   "ACTOR_001",
 			},
-			expectedStatus: validation.AMLStatusReviewing,
+			expectedStatus: validation.AMLStatusClear,
+			expectedFactor: "TRANSACTION",
 			expectedError:  false,
 		},
 		{
@@ -172,6 +231,13 @@ func TestAMLCheckHandler_PerformAMLCheck(t *testing.T) {
 			require.NoError(t, err)
 
 			assert.Equal(t, tt.expectedStatus, amlResult.Status)
+			if tt.expectedFactor != "" {
+				categories := []string{}
+				for _, factor := range amlResult.RiskFactors {
+					categories = append(categories, factor.Category)
+				}
+				assert.Contains(t, categories, tt.expectedFactor)
+			}
 			assert.Equal(t, tt.request.CustomerID, amlResult.CustomerID)
 			assert.Equal(t, tt.request.CheckType, amlResult.CheckType)
 			assert.NotEmpty(t, amlResult.CheckID)
@@ -201,6 +267,7 @@ func TestAMLCheckHandler_PerformAMLCheck(t *testing.T) {
 
 func TestAMLCheckHandler_UpdateAMLStatus(t *testing.T) {
 	stub := shimtest.NewMockStub("aml_test", nil)
+	stub.MockTransactionStart("txid")
 	mockEmitter := &MockEventEmitter{}
 	handler := NewAMLCheckHandler(mockEmitter)
 
@@ -311,6 +378,7 @@ func TestAMLCheckHandler_UpdateAMLStatus(t *testing.T) {
 
 func TestAMLCheckHandler_GetAMLReport(t *testing.T) {
 	stub := shimtest.NewMockStub("aml_test", nil)
+	stub.MockTransactionStart("txid")
 	mockEmitter := &MockEventEmitter{}
 	handler := NewAMLCheckHandler(mockEmitter)
 
@@ -400,7 +468,8 @@ func TestAMLCheckHandler_RiskAssessment(t *testing.T) {
 		name             string
 		customerData     CustomerAMLData
 		transactionData  *TransactionAMLData
-		expectedRiskLevel RiskLevel
+		expectedCategory string  // Category of the risk factor raised, if any
+		expectedScore    float64 // Score of that factor in the built-in tables
 	}{
 		{
 			name: "Low risk customer",
@@ -410,7 +479,6 @@ func TestAMLCheckHandler_RiskAssessment(t *testing.T) {
 				Country:     "US",
 				Occupation:  "Engineer",
 			},
-			expectedRiskLevel: RiskLevelLow,
 		},
 		{
 			name: "High risk country",
@@ -420,7 +488,8 @@ func TestAMLCheckHandler_RiskAssessment(t *testing.T) {
 				Country:     "AF", // Afghanistan - high risk
 				Occupation:  "Businessman",
 			},
-			expectedRiskLevel: RiskLevelHigh,
+			expectedCategory: "GEOGRAPHIC",
+			expectedScore:    90,
 		},
 		{
 			name: "High risk occupation",
@@ -430,7 +499,8 @@ func TestAMLCheckHandler_RiskAssessment(t *testing.T) {
 				Country:     "ES",
 				Occupation:  "POLITICIAN",
 			},
-			expectedRiskLevel: RiskLevelHigh,
+			expectedCategory: "PROFILE",
+			expectedScore:    80,
 		},
 		{
 			name: "High value transaction",
@@ -444,13 +514,15 @@ func TestAMLCheckHandler_RiskAssessment(t *testing.T) {
 				Amount:   150000.00,
 				Currency: "USD",
 			},
-			expectedRiskLevel: RiskLevelHigh,
+			expectedCategory: "TRANSACTION",
+			expectedScore:    90,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
 			stub := shimtest.NewMockStub("risk_test", nil)
+			stub.MockTransactionStart("txid")
 			
 			riskFactors, err := handler.assessRiskFactors(stub, &tt.customerData, tt.transactionData)
 			require.NoError(t, err)
@@ -462,17 +534,24 @@ func TestAMLCheckHandler_RiskAssessment(t *testing.T) {
 				PEPScreenResult:      PEPScreenResult{IsMatch: false, MatchConfidence: 0.0},
 			}
 
-			overallScore := handler.calculateOverallRiskScore(result)
-			riskLevel := handler.determineRiskLevel(overallScore)
+			overallScore := handler.calculateOverallRiskScore(result, &defaultRiskModel)
+			riskLevel := handler.determineRiskLevel(overallScore, &defaultRiskModel)
 
 			assert.GreaterOrEqual(t, overallScore, 0.0)
 			assert.LessOrEqual(t, overallScore, 1.0)
 
-			// For high-risk cases, verify the risk level is appropriate
-			if tt.expectedRiskLevel == RiskLevelHigh {
-				assert.True(t, riskLevel == RiskLevelHigh || riskLevel == RiskLevelCritical,
-					"Expected high or critical risk level, got %s", riskLevel)
+			if tt.expectedCategory == "" {
+				assert.Empty(t, riskFactors)
+				assert.Equal(t, RiskLevelLow, riskLevel)
+				return
 			}
+
+			// A lone risk factor contributes its score weighted by the model's risk factor weight
+			require.Len(t, riskFactors, 1)
+			assert.Equal(t, tt.expectedCategory, riskFactors[0].Category)
+			assert.Equal(t, tt.expectedScore, riskFactors[0].RiskScore)
+			assert.InDelta(t, tt.expectedScore/100*defaultRiskModel.RiskFactorWeight, overallScore, 1e-9)
+			assert.Equal(t, defaultRiskModel.riskLevel(overallScore), riskLevel)
 		})
 	}
 }
@@ -480,6 +559,8 @@ func TestAMLCheckHandler_RiskAssessment(t *testing.T) {
 func TestAMLCheckHandler_SanctionScreening(t *testing.T) {
 	handler := NewAMLCheckHandler(nil)
 	stub := shimtest.NewMockStub("sanction_test", nil)
+	seedSanctionList(t, stub, "OFAC_SDN", johnDoeSanctionEntry())
+	stub.MockTransactionStart("txid")
 
 	tests := []struct {
 		name         string
@@ -504,13 +585,13 @@ func TestAMLCheckHandler_SanctionScreening(t *testing.T) {
 				DateOfBirth: time.Date(1970, 1, 1, 0, 0, 0, 0, time.UTC),
 				Nationality: "US",
 			},
-			expectMatch: true, // This matches our mock data
+			expectMatch: true, // Matches the seeded OFAC SDN entry
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := handler.performSanctionScreening(stub, &tt.customerData, nil)
+			result, err := handler.performSanctionScreening(stub, "", &tt.customerData, nil)
 			require.NoError(t, err)
 
 			assert.Equal(t, tt.expectMatch, result.IsMatch)
@@ -593,33 +674,165 @@ func TestAMLCheckHandler_ExpiryDateCalculation(t *testing.T) {
 	handler := NewAMLCheckHandler(nil)
 
 	tests := []struct {
-		checkType    AMLCheckType
-		expectedDays int
+		checkType AMLCheckType
+		expected  time.Time
 	}{
-		{AMLCheckTypeCustomerOnboarding, 365},
-		{AMLCheckTypePeriodicReview, 365},
-		{AMLCheckTypeTransactionBased, 180}, // 6 months
-		{AMLCheckTypeRiskReassessment, 90},  // 3 months
+		{AMLCheckTypeCustomerOnboarding, time.Date(2025, 1, 15, 9, 30, 0, 0, time.UTC)},
+		{AMLCheckTypePeriodicReview, time.Date(2025, 1, 15, 9, 30, 0, 0, time.UTC)},
+		{AMLCheckTypeTransactionBased, time.Date(2024, 7, 15, 9, 30, 0, 0, time.UTC)}, // 6 months
+		{AMLCheckTypeRiskReassessment, time.Date(2024, 4, 15, 9, 30, 0, 0, time.UTC)}, // 3 months
 	}
 
-	baseTime := time.Now()
+	// Expiry is derived from the transaction time, so it is the same on every endorsing peer
+	baseTime := time.Date(2024, 1, 15, 9, 30, 0, 0, time.UTC)
 
 	for _, tt := range tests {
 		t.Run(string(tt.checkType), func(t *testing.T) {
-			expiryDate := handler.calculateExpiryDate(tt.checkType)
-			expectedDate := baseTime.AddDate(0, 0, tt.expectedDays)
-			
-			// Allow for small time differences due to execution time
-			diff := expiryDate.Sub(expectedDate)
-			assert.Less(t, diff.Abs(), time.Minute)
+			assert.Equal(t, tt.expected, handler.calculateExpiryDate(tt.checkType, baseTime))
 		})
 	}
 }
 
+func TestAMLCheckHandler_CounterpartyScreening(t *testing.T) {
+	stub := shimtest.NewMockStub("aml_counterparty_test", nil)
+	seedSanctionList(t, stub, "OFAC_SDN", johnDoeSanctionEntry())
+	stub.MockTransactionStart("txid")
+	mockEmitter := &MockEventEmitter{}
+	handler := NewAMLCheckHandler(mockEmitter)
+
+	stub.MockTransactionStart("pep_setup")
+	_, _, err := handler.pepListManager.putPEPEntry(stub, &PEPEntryRequest{
+		EntryID:      "PEP_CP_001",
+		Name:         "Viktor Minister",
+		Position:     "Minister of Finance",
+		Country:      "XX",
+		RiskCategory: "HIGH",
+		Source:       "TEST",
+	}, "ACTOR_001")
+	stub.MockTransactionEnd("pep_setup")
+	require.NoError(t, err)
+
+	check := func(txID, counterpartyName string) AMLCheckResult {
+		request := AMLCheckRequest{
+			CustomerID: "CUST_CP_001",
+			CustomerData: CustomerAMLData{
+				FirstName:   "Alice",
+				LastName:    "Walker",
+				DateOfBirth: time.Date(1985, 5, 5, 0, 0, 0, 0, time.UTC),
+				NationalID:  "ID555",
+				Nationality: "US",
+				Country:     "US",
+			},
+			TransactionData: &TransactionAMLData{
+				TransactionID:       "TXN_" + txID,
+				Amount:              500,
+				Currency:            "USD",
+				TransactionType:     "TRANSFER",
+				CounterpartyName:    counterpartyName,
+				CounterpartyCountry: "XX",
+			},
+			CheckType: AMLCheckTypeTransactionBased,
+			ActorID:   "ACTOR_001",
+		}
+		requestBytes, err := json.Marshal(request)
+		require.NoError(t, err)
+		stub.MockTransactionStart(txID)
+		defer stub.MockTransactionEnd(txID)
+		resultBytes, err := handler.PerformAMLCheck(stub, []string{string(requestBytes)})
+		require.NoError(t, err)
+		var result AMLCheckResult
+		require.NoError(t, json.Unmarshal(resultBytes, &result))
+		return result
+	}
+	counterpartyEvents := func() []*domain.ComplianceEvent {
+		events := []*domain.ComplianceEvent{}
+		for _, emitted := range mockEmitter.EmittedEvents {
+			if event, ok := emitted.(*domain.ComplianceEvent); ok && event.EventType == "AML_COUNTERPARTY_MATCH" {
+				events = append(events, event)
+			}
+		}
+		return events
+	}
+
+	// A clean counterparty is screened but raises nothing
+	result := check("cp_1", "Harbour Supplies Ltd")
+	require.NotNil(t, result.CounterpartyScreenResult)
+	assert.False(t, result.CounterpartyScreenResult.IsMatch)
+	assert.Equal(t, validation.AMLStatusClear, result.Status)
+	assert.Empty(t, counterpartyEvents())
+
+	// A sanctioned counterparty is recorded apart from the customer's own screening
+	result = check("cp_2", "John Doe")
+	require.NotNil(t, result.CounterpartyScreenResult)
+	assert.True(t, result.CounterpartyScreenResult.SanctionMatch)
+	assert.NotEmpty(t, result.CounterpartyScreenResult.SanctionMatches)
+	assert.Equal(t, "TXN_cp_2", result.CounterpartyScreenResult.TransactionID)
+	assert.False(t, result.SanctionScreenResult.IsMatch)
+	assert.Equal(t, validation.AMLStatusReviewing, result.Status)
+	actionTypes := []string{}
+	for _, action := range result.RequiredActions {
+		actionTypes = append(actionTypes, action.ActionType)
+	}
+	assert.Contains(t, actionTypes, "COUNTERPARTY_REVIEW")
+	events := counterpartyEvents()
+	require.Len(t, events, 1)
+	assert.Equal(t, domain.PriorityCritical, events[0].Severity)
+	assert.True(t, events[0].IsAlerted)
+	assert.Equal(t, "John Doe", events[0].Details["counterpartyName"])
+
+	// The check and its counterparty hit are counted in one increment of the transaction's deltas
+	iterator, err := stub.GetStateByPartialCompositeKey(config.CounterPrefix, []string{config.MetricComplianceEvents})
+	require.NoError(t, err)
+	deltas := 0
+	for iterator.HasNext() {
+		kv, err := iterator.Next()
+		require.NoError(t, err)
+		_, attributes, err := stub.SplitCompositeKey(kv.Key)
+		require.NoError(t, err)
+		if attributes[2] != "cp_2" {
+			continue
+		}
+		var delta services.Counter
+		require.NoError(t, json.Unmarshal(kv.Value, &delta))
+		assert.Equal(t, int64(2), delta.Count)
+		deltas++
+	}
+	iterator.Close()
+	assert.Equal(t, 2, deltas) // day and month
+
+	// A PEP counterparty raises a high severity event
+	result = check("cp_3", "Viktor Minister")
+	assert.True(t, result.CounterpartyScreenResult.PEPMatch)
+	assert.False(t, result.CounterpartyScreenResult.SanctionMatch)
+	assert.False(t, result.PEPScreenResult.IsMatch)
+	events = counterpartyEvents()
+	require.Len(t, events, 2)
+	assert.Equal(t, domain.PriorityHigh, events[1].Severity)
+
+	// Transactions without a counterparty skip counterparty screening
+	request := AMLCheckRequest{
+		CustomerID:   "CUST_CP_001",
+		CustomerData: CustomerAMLData{FirstName: "Alice", LastName: "Walker", NationalID: "ID555", Country: "US"},
+		TransactionData: &TransactionAMLData{TransactionID: "TXN_cp_4", Amount: 500, Currency: "USD"},
+		CheckType:    AMLCheckTypeTransactionBased,
+		ActorID:      "ACTOR_001",
+	}
+	requestBytes, err := json.Marshal(request)
+	require.NoError(t, err)
+	stub.MockTransactionStart("cp_4")
+	resultBytes, err := handler.PerformAMLCheck(stub, []string{string(requestBytes)})
+	stub.MockTransactionEnd("cp_4")
+	require.NoError(t, err)
+	var noCounterparty AMLCheckResult
+	require.NoError(t, json.Unmarshal(resultBytes, &noCounterparty))
+	assert.Nil(t, noCounterparty.CounterpartyScreenResult)
+}
+
 // Benchmark tests for performance validation
 
 func BenchmarkAMLCheckHandler_PerformAMLCheck(b *testing.B) {
 	stub := shimtest.NewMockStub("aml_benchmark", nil)
+	stub.MockTransactionStart("txid")
 	handler := NewAMLCheckHandler(nil)
 
 	request := AMLCheckRequest{
@@ -651,6 +864,7 @@ func BenchmarkAMLCheckHandler_PerformAMLCheck(b *testing.B) {
 func BenchmarkAMLCheckHandler_SanctionScreening(b *testing.B) {
 	handler := NewAMLCheckHandler(nil)
 	stub := shimtest.NewMockStub("sanction_benchmark", nil)
+	stub.MockTransactionStart("txid")
 
 	customerData := CustomerAMLData{
 		FirstName:   "Benchmark",
@@ -661,7 +875,7 @@ func BenchmarkAMLCheckHandler_SanctionScreening(b *testing.B) {
 
 	b.ResetTimer()
 	for i := 0; i < b.N; i++ {
-		_, err := handler.performSanctionScreening(stub, &customerData, nil)
+		_, err := handler.performSanctionScreening(stub, "", &customerData, nil)
 		if err != nil {
 			b.Fatal(err)
 		}
@@ -0,0 +1,269 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/compliance/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// amlExpiryDateFormat is the day bucket used by the AML_EXPIRY_ index; keys sort by expiry day
+const amlExpiryDateFormat = "2006-01-02"
+
+// PeriodicReviewRequest represents a batch of customers to re-screen
+type PeriodicReviewRequest struct {
+	CustomerIDs []string `json:"customerIDs"`
+	ActorID     string   `json:"actorID"`
+}
+
+// PeriodicReviewOutcome records the re-screening of one customer in a periodic review batch
+type PeriodicReviewOutcome struct {
+	CustomerID      string     `json:"customerID"`
+	PreviousCheckID string     `json:"previousCheckID,omitempty"`
+	PreviousStatus  string     `json:"previousStatus,omitempty"`
+	CheckID         string     `json:"checkID,omitempty"` // New check superseding the previous one
+	Status          string     `json:"status,omitempty"`
+	ExpiryDate      *time.Time `json:"expiryDate,omitempty"`
+	Lapsed          bool       `json:"lapsed"` // Previous check expired and could not be renewed
+	Error           string     `json:"error,omitempty"`
+}
+
+// PeriodicReviewResult summarises a periodic review batch
+type PeriodicReviewResult struct {
+	Outcomes   []PeriodicReviewOutcome `json:"outcomes"`
+	Rescreened int                     `json:"rescreened"`
+	Lapsed     int                     `json:"lapsed"`
+	Failed     int                     `json:"failed"`
+	ReviewedBy string                  `json:"reviewedBy"`
+	ReviewDate time.Time               `json:"reviewDate"`
+}
+
+// ExpiringChecksResult lists the current AML checks expiring before a date
+type ExpiringChecksResult struct {
+	BeforeDate string           `json:"beforeDate"`
+	Checks     []AMLCheckResult `json:"checks"`
+	Count      int              `json:"count"`
+}
+
+// GetExpiringChecks returns each customer's current AML check expiring before the given date
+// (exclusive). Checks already superseded by a newer check are not included.
+func (h *AMLCheckHandler) GetExpiringChecks(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	beforeDate, err := time.Parse(amlExpiryDateFormat, args[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid before date %s: %w", args[0], err)
+	}
+
+	iterator, err := stub.GetStateByRange("AML_EXPIRY_", "AML_EXPIRY_"+beforeDate.Format(amlExpiryDateFormat))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get expiring AML checks: %w", err)
+	}
+	defer iterator.Close()
+
+	checks := []AMLCheckResult{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate expiring AML checks: %w", err)
+		}
+
+		var result AMLCheckResult
+		if err := h.persistenceService.Get(stub, fmt.Sprintf("AML_RESULT_%s", string(response.Value)), &result); err != nil {
+			continue // Skip if result not found
+		}
+		checks = append(checks, result)
+	}
+
+	return json.Marshal(&ExpiringChecksResult{
+		BeforeDate: args[0],
+		Checks:     checks,
+		Count:      len(checks),
+	})
+}
+
+// TriggerPeriodicReview re-screens a batch of customers against the data of their current AML
+// check. Each new PERIODIC_REVIEW check supersedes the previous one; customers whose check has
+// expired and cannot be re-screened lapse and raise a compliance event.
+func (h *AMLCheckHandler) TriggerPeriodicReview(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req PeriodicReviewRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse periodic review request: %v", err)
+	}
+
+	if len(req.CustomerIDs) == 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "at least one customerID is required")
+	}
+	if len(req.CustomerIDs) > config.MaxPageSize {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "batch of %d customers exceeds maximum of %d", len(req.CustomerIDs), config.MaxPageSize)
+	}
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	summary := &PeriodicReviewResult{
+		Outcomes:   []PeriodicReviewOutcome{},
+		ReviewedBy: req.ActorID,
+		ReviewDate: now,
+	}
+
+	for _, customerID := range req.CustomerIDs {
+		outcome := PeriodicReviewOutcome{CustomerID: customerID}
+
+		previous, err := h.getLatestCheck(stub, customerID)
+		if err != nil {
+			outcome.Error = err.Error()
+			summary.Failed++
+			summary.Outcomes = append(summary.Outcomes, outcome)
+			continue
+		}
+		outcome.PreviousCheckID = previous.CheckID
+		outcome.PreviousStatus = string(previous.Status)
+
+		result, err := h.rescreen(stub, previous, req.ActorID)
+		if err != nil {
+			outcome.Error = err.Error()
+			summary.Failed++
+			if now.After(previous.ExpiryDate) {
+				if err := h.recordLapseEvent(stub, previous, err.Error(), req.ActorID); err != nil {
+					return nil, fmt.Errorf("failed to record lapse event: %w", err)
+				}
+				outcome.Lapsed = true
+				summary.Lapsed++
+			}
+			summary.Outcomes = append(summary.Outcomes, outcome)
+			continue
+		}
+
+		outcome.CheckID = result.CheckID
+		outcome.Status = string(result.Status)
+		outcome.ExpiryDate = &result.ExpiryDate
+		summary.Rescreened++
+		summary.Outcomes = append(summary.Outcomes, outcome)
+	}
+
+	return json.Marshal(summary)
+}
+
+// rescreen runs a PERIODIC_REVIEW check with the data screened by the previous check
+func (h *AMLCheckHandler) rescreen(stub shim.ChaincodeStubInterface, previous *AMLCheckResult, actorID string) (*AMLCheckResult, error) {
+	if previous.CustomerData == nil {
+		return nil, fmt.Errorf("check %s holds no screening data; run PerformAMLCheck for customer %s", previous.CheckID, previous.CustomerID)
+	}
+
+	req := &AMLCheckRequest{
+		CustomerID:   previous.CustomerID,
+		CustomerData: *previous.CustomerData,
+		CheckType:    AMLCheckTypePeriodicReview,
+		ActorID:      actorID,
+	}
+	result, err := h.performComprehensiveAMLCheck(stub, services.GenerateDeterministicID(stub, config.AMLCheckPrefix), req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to re-screen customer %s: %w", previous.CustomerID, err)
+	}
+
+	if err := h.recordCheck(stub, result, actorID); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// storeCheckResult adds a check to the batch as the customer's current one, replacing the previous
+// check's entry in the expiry index
+func (h *AMLCheckHandler) storeCheckResult(stub shim.ChaincodeStubInterface, batch *services.WriteBatch, result *AMLCheckResult) error {
+	resultKey := fmt.Sprintf("AML_RESULT_%s", result.CheckID)
+	if err := batch.Put(resultKey, result); err != nil {
+		return fmt.Errorf("failed to store AML check result: %w", err)
+	}
+
+	// Create customer AML index
+	customerAMLKey := fmt.Sprintf("CUSTOMER_AML_%s_%s", result.CustomerID, result.CheckID)
+	batch.PutRaw(customerAMLKey, []byte(result.CheckID))
+
+	// Only the current check is due for review
+	if previous, err := h.getLatestCheck(stub, result.CustomerID); err == nil {
+		batch.Delete(amlExpiryKey(previous))
+	}
+
+	batch.PutRaw(fmt.Sprintf("AML_LATEST_%s", result.CustomerID), []byte(result.CheckID))
+	batch.PutRaw(amlExpiryKey(result), []byte(result.CheckID))
+
+	return nil
+}
+
+// getLatestCheck retrieves a customer's current AML check
+func (h *AMLCheckHandler) getLatestCheck(stub shim.ChaincodeStubInterface, customerID string) (*AMLCheckResult, error) {
+	checkID, err := stub.GetState(fmt.Sprintf("AML_LATEST_%s", customerID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get latest AML check: %w", err)
+	}
+	if checkID == nil {
+		return nil, fmt.Errorf("no AML check on file for customer %s", customerID)
+	}
+
+	var result AMLCheckResult
+	if err := h.persistenceService.Get(stub, fmt.Sprintf("AML_RESULT_%s", string(checkID)), &result); err != nil {
+		return nil, fmt.Errorf("AML check result not found: %w", err)
+	}
+	return &result, nil
+}
+
+// recordLapseEvent raises an alerted compliance event for a customer whose AML check expired
+// without being renewed
+func (h *AMLCheckHandler) recordLapseEvent(stub shim.ChaincodeStubInterface, result *AMLCheckResult, reason, actorID string) error {
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return err
+	}
+
+	eventID := services.GenerateDeterministicID(stub, config.ComplianceEventPrefix)
+
+	event := &domain.ComplianceEvent{
+		EventID:            eventID,
+		Timestamp:          now,
+		RuleID:             "AML_PERIODIC_REVIEW_RULE",
+		RuleVersion:        "1.0",
+		AffectedEntityID:   result.CustomerID,
+		AffectedEntityType: "Customer",
+		EventType:          "AML_CHECK_LAPSED",
+		Severity:           domain.PriorityHigh,
+		Details: map[string]interface{}{
+			"checkID":    result.CheckID,
+			"status":     result.Status,
+			"expiryDate": result.ExpiryDate,
+			"reason":     reason,
+		},
+		ActorID:          actorID,
+		IsAlerted:        true,
+		ResolutionStatus: "OPEN",
+	}
+
+	batch := services.NewWriteBatch()
+	eventKey := fmt.Sprintf("COMPLIANCE_EVENT_%s", eventID)
+	if err := batch.Put(eventKey, event); err != nil {
+		return fmt.Errorf("failed to store compliance event: %w", err)
+	}
+	h.emitComplianceEvent(batch, event)
+
+	return batch.Flush(stub)
+}
+
+func amlExpiryKey(result *AMLCheckResult) string {
+	return fmt.Sprintf("AML_EXPIRY_%s_%s", result.ExpiryDate.UTC().Format(amlExpiryDateFormat), result.CheckID)
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/compliance/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+func TestAMLCheckHandler_PeriodicReview(t *testing.T) {
+	stub := shimtest.NewMockStub("aml_periodic_test", nil)
+	mockEmitter := &MockEventEmitter{}
+	handler := NewAMLCheckHandler(mockEmitter)
+
+	request := AMLCheckRequest{
+		CustomerID: "CUST_PR_001",
+		CustomerData: CustomerAMLData{
+			FirstName:   "Jane",
+			LastName:    "Periodic",
+			DateOfBirth: time.Date(1982, 4, 12, 0, 0, 0, 0, time.UTC),
+			NationalID:  "ID555000111",
+			Nationality: "US",
+			Address:     "1 Review Road, Boston, MA",
+			Country:     "US",
+			Occupation:  "Teacher",
+		},
+		CheckType: AMLCheckTypeCustomerOnboarding,
+		ActorID:   "ACTOR_001",
+	}
+	requestBytes, err := json.Marshal(request)
+	require.NoError(t, err)
+
+	stub.MockTransactionStart("tx1")
+	resultBytes, err := handler.PerformAMLCheck(stub, []string{string(requestBytes)})
+	stub.MockTransactionEnd("tx1")
+	require.NoError(t, err)
+	var onboarding AMLCheckResult
+	require.NoError(t, json.Unmarshal(resultBytes, &onboarding))
+
+	getExpiring := func(beforeDate time.Time) ExpiringChecksResult {
+		resultBytes, err := handler.GetExpiringChecks(stub, []string{beforeDate.Format("2006-01-02")})
+		require.NoError(t, err)
+		var expiring ExpiringChecksResult
+		require.NoError(t, json.Unmarshal(resultBytes, &expiring))
+		return expiring
+	}
+
+	t.Run("Checks are listed once their expiry falls before the date", func(t *testing.T) {
+		assert.Equal(t, 0, getExpiring(time.Now()).Count)
+		expiring := getExpiring(time.Now().AddDate(2, 0, 0))
+		require.Equal(t, 1, expiring.Count)
+		assert.Equal(t, onboarding.CheckID, expiring.Checks[0].CheckID)
+	})
+
+	t.Run("Re-screening supersedes the current check", func(t *testing.T) {
+		reviewBytes, err := json.Marshal(PeriodicReviewRequest{CustomerIDs: []string{"CUST_PR_001", "CUST_PR_UNKNOWN"}, ActorID: "ACTOR_001"})
+		require.NoError(t, err)
+
+		stub.MockTransactionStart("tx2")
+		resultBytes, err := handler.TriggerPeriodicReview(stub, []string{string(reviewBytes)})
+		stub.MockTransactionEnd("tx2")
+		require.NoError(t, err)
+
+		var review PeriodicReviewResult
+		require.NoError(t, json.Unmarshal(resultBytes, &review))
+		assert.Equal(t, 1, review.Rescreened)
+		assert.Equal(t, 1, review.Failed)
+		assert.Equal(t, 0, review.Lapsed)
+		require.Len(t, review.Outcomes, 2)
+		assert.Equal(t, onboarding.CheckID, review.Outcomes[0].PreviousCheckID)
+		assert.NotEqual(t, onboarding.CheckID, review.Outcomes[0].CheckID)
+		assert.Equal(t, string(validation.AMLStatusClear), review.Outcomes[0].Status)
+		assert.Contains(t, review.Outcomes[1].Error, "no AML check on file")
+
+		expiring := getExpiring(time.Now().AddDate(2, 0, 0))
+		require.Equal(t, 1, expiring.Count)
+		assert.Equal(t, review.Outcomes[0].CheckID, expiring.Checks[0].CheckID)
+		assert.Equal(t, AMLCheckTypePeriodicReview, expiring.Checks[0].CheckType)
+	})
+
+	t.Run("Expired checks that cannot be re-screened lapse", func(t *testing.T) {
+		// Checks stored before screening data was kept cannot be re-screened
+		legacy := &AMLCheckResult{
+			CheckID:    "AML_LEGACY_001",
+			CustomerID: "CUST_PR_002",
+			CheckType:  AMLCheckTypeCustomerOnboarding,
+			Status:     validation.AMLStatusClear,
+			CheckDate:  time.Now().AddDate(-1, -1, 0),
+			ExpiryDate: time.Now().AddDate(0, -1, 0),
+			CheckedBy:  "ACTOR_001",
+		}
+		stub.MockTransactionStart("tx3")
+		batch := services.NewWriteBatch()
+		require.NoError(t, handler.storeCheckResult(stub, batch, legacy))
+		require.NoError(t, batch.Flush(stub))
+		stub.MockTransactionEnd("tx3")
+
+		assert.Equal(t, 1, getExpiring(time.Now()).Count)
+
+		reviewBytes, err := json.Marshal(PeriodicReviewRequest{CustomerIDs: []string{"CUST_PR_002"}, ActorID: "ACTOR_001"})
+		require.NoError(t, err)
+		emitted := len(mockEmitter.EmittedEvents)
+
+		stub.MockTransactionStart("tx4")
+		resultBytes, err := handler.TriggerPeriodicReview(stub, []string{string(reviewBytes)})
+		stub.MockTransactionEnd("tx4")
+		require.NoError(t, err)
+
+		var review PeriodicReviewResult
+		require.NoError(t, json.Unmarshal(resultBytes, &review))
+		assert.Equal(t, 1, review.Lapsed)
+		assert.True(t, review.Outcomes[0].Lapsed)
+
+		require.Len(t, mockEmitter.EmittedEvents, emitted+1)
+		event, ok := mockEmitter.EmittedEvents[emitted].(*domain.ComplianceEvent)
+		require.True(t, ok)
+		assert.Equal(t, "AML_CHECK_LAPSED", event.EventType)
+		assert.True(t, event.IsAlerted)
+	})
+	t.Run("Re-screening matches entries imported since the last check", func(t *testing.T) {
+		seedSanctionList(t, stub, "UN_SANCTIONS", ComprehensiveSanctionEntry{
+			EntryID:     "UN_042",
+			PrimaryName: "Jane Periodic",
+			EntityType:  EntityTypeIndividual,
+			LastName:    "Periodic",
+		})
+
+		reviewBytes, err := json.Marshal(PeriodicReviewRequest{CustomerIDs: []string{"CUST_PR_001"}, ActorID: "ACTOR_001"})
+		require.NoError(t, err)
+
+		stub.MockTransactionStart("tx5")
+		resultBytes, err := handler.TriggerPeriodicReview(stub, []string{string(reviewBytes)})
+		stub.MockTransactionEnd("tx5")
+		require.NoError(t, err)
+
+		var review PeriodicReviewResult
+		require.NoError(t, json.Unmarshal(resultBytes, &review))
+		require.Len(t, review.Outcomes, 1)
+		assert.NotEqual(t, string(validation.AMLStatusClear), review.Outcomes[0].Status)
+
+		latest, err := handler.getLatestCheck(stub, "CUST_PR_001")
+		require.NoError(t, err)
+		assert.True(t, latest.SanctionScreenResult.IsMatch)
+		assert.Contains(t, latest.SanctionScreenResult.ListsScreened, "UN_SANCTIONS")
+		require.Len(t, latest.SanctionScreenResult.Matches, 1)
+		assert.Equal(t, "UN_SANCTIONS_UN_042", latest.SanctionScreenResult.Matches[0].ListEntryID)
+	})
+}
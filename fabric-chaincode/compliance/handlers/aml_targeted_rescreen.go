@@ -0,0 +1,335 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/compliance/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/interfaces"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// Name match thresholds for targeted re-screening, matching screenAgainstSanctionEntries and
+// performSanctionScreening
+const (
+	targetedCandidateThreshold = 0.7 // Plausible enough to re-screen
+	targetedMatchThreshold     = 0.8 // Counted as a sanction match and alerted
+)
+
+// TargetedRescreenRequest asks for the customers resembling one newly added sanction entry to be
+// re-screened straight away
+type TargetedRescreenRequest struct {
+	ListID  string `json:"listID"`
+	EntryID string `json:"entryID"`
+	ActorID string `json:"actorID"`
+}
+
+// TargetedRescreenOutcome records the re-screening of one plausible candidate
+type TargetedRescreenOutcome struct {
+	CustomerID  string  `json:"customerID"`
+	MatchedName string  `json:"matchedName"`
+	Confidence  float64 `json:"confidence"`
+	CheckID     string  `json:"checkID,omitempty"`
+	Status      string  `json:"status,omitempty"`
+	Alerted     bool    `json:"alerted"`
+	Suppressed  bool    `json:"suppressed,omitempty"` // Whitelisted as a false positive, so not alerted
+	Error       string  `json:"error,omitempty"`
+}
+
+// TargetedRescreenResult summarises a targeted re-screen
+type TargetedRescreenResult struct {
+	ListID        string                    `json:"listID"`
+	EntryID       string                    `json:"entryID"`
+	SearchedNames []string                  `json:"searchedNames"`
+	Candidates    int                       `json:"candidates"` // Customers found by the name search
+	Rescreened    int                       `json:"rescreened"`
+	Alerted       int                       `json:"alerted"`
+	Suppressed    int                       `json:"suppressed"`
+	Failed        int                       `json:"failed"`
+	Outcomes      []TargetedRescreenOutcome `json:"outcomes"`
+	ScreenedBy    string                    `json:"screenedBy"`
+	ScreeningDate time.Time                 `json:"screeningDate"`
+}
+
+// TargetedRescreen screens existing customers against a single sanction entry as soon as it is
+// added, rather than waiting for the periodic sweep. It searches the customer chaincode's last name
+// index for every name the entry could match and re-screens only the customers whose full name
+// resembles the entry, raising an alerted compliance event for each match in the same transaction.
+// Customers with a residency are not in the name index and are left to the sweep. Full list
+// reloads should go through the sweep as well.
+func (h *AMLCheckHandler) TargetedRescreen(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req TargetedRescreenRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse targeted rescreen request: %v", err)
+	}
+	if strings.TrimSpace(req.ListID) == "" || strings.TrimSpace(req.EntryID) == "" {
+		return nil, fmt.Errorf("listID and entryID are required")
+	}
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	entry, err := h.sanctionListManager.getSanctionEntry(stub, req.ListID, req.EntryID)
+	if err != nil {
+		return nil, fmt.Errorf("sanction entry %s not found in list %s: %w", req.EntryID, req.ListID, err)
+	}
+	if !entry.IsActive {
+		return nil, fmt.Errorf("sanction entry %s is not active", req.EntryID)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	summary := &TargetedRescreenResult{
+		ListID:        req.ListID,
+		EntryID:       req.EntryID,
+		SearchedNames: sanctionEntryLastNames(entry),
+		Outcomes:      []TargetedRescreenOutcome{},
+		ScreenedBy:    req.ActorID,
+		ScreeningDate: now,
+	}
+
+	candidates, err := h.findScreeningCandidates(stub, summary.SearchedNames)
+	if err != nil {
+		return nil, err
+	}
+	summary.Candidates = len(candidates)
+
+	// Only candidates whose full name resembles the entry are re-screened
+	type plausibleCandidate struct {
+		candidate   interfaces.ScreeningCandidate
+		matchedName string
+		confidence  float64
+	}
+	plausible := []plausibleCandidate{}
+	for _, candidate := range candidates {
+		matchedName, confidence := h.bestSanctionNameMatch(candidate.FirstName+" "+candidate.LastName, entry)
+		if confidence >= targetedCandidateThreshold {
+			plausible = append(plausible, plausibleCandidate{candidate, matchedName, confidence})
+		}
+	}
+	if len(plausible) > config.MaxPageSize {
+		return nil, fmt.Errorf("sanction entry %s resembles %d customers, more than the maximum of %d for a targeted rescreen; leave it to the periodic sweep", req.EntryID, len(plausible), config.MaxPageSize)
+	}
+
+	for _, p := range plausible {
+		outcome := TargetedRescreenOutcome{
+			CustomerID:  p.candidate.CustomerID,
+			MatchedName: p.matchedName,
+			Confidence:  p.confidence,
+		}
+
+		result, err := h.rescreenAgainstEntry(stub, &p.candidate, req.ActorID)
+		if err != nil {
+			outcome.Error = err.Error()
+			summary.Failed++
+			summary.Outcomes = append(summary.Outcomes, outcome)
+			continue
+		}
+		outcome.CheckID = result.CheckID
+		outcome.Status = string(result.Status)
+		summary.Rescreened++
+
+		// A match whitelisted as a false positive for the customer is logged on the check only
+		match := sanctionMatchForEntry(result, managedSanctionEntryID(req.ListID, req.EntryID))
+		if match == nil {
+			summary.Outcomes = append(summary.Outcomes, outcome)
+			continue
+		}
+		if match.Suppressed {
+			outcome.Suppressed = true
+			summary.Suppressed++
+		} else if p.confidence >= targetedMatchThreshold {
+			if err := h.recordTargetedMatchEvent(stub, result, entry, p.matchedName, p.confidence, req.ActorID); err != nil {
+				return nil, fmt.Errorf("failed to record sanction match event: %w", err)
+			}
+			outcome.Alerted = true
+			summary.Alerted++
+		}
+		summary.Outcomes = append(summary.Outcomes, outcome)
+	}
+
+	return json.Marshal(summary)
+}
+
+// findScreeningCandidates searches the customer chaincode's last name index for each name and
+// returns the customers found, once each, in customer ID order
+func (h *AMLCheckHandler) findScreeningCandidates(stub shim.ChaincodeStubInterface, lastNames []string) ([]interfaces.ScreeningCandidate, error) {
+	found := make(map[string]interfaces.ScreeningCandidate)
+	for _, lastName := range lastNames {
+		bookmark := ""
+		for {
+			response := stub.InvokeChaincode(config.CustomerChaincodeName, [][]byte{
+				[]byte("SearchCustomersByName"),
+				[]byte(lastName),
+				[]byte(strconv.Itoa(config.MaxPageSize)),
+				[]byte(bookmark),
+			}, "")
+			if response.Status != shim.OK {
+				return nil, fmt.Errorf("failed to search customers named %s with %s chaincode: %s", lastName, config.CustomerChaincodeName, response.Message)
+			}
+
+			var page interfaces.ScreeningCandidatePage
+			if err := json.Unmarshal(response.Payload, &page); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal customer search results: %w", err)
+			}
+			for _, candidate := range page.Customers {
+				found[candidate.CustomerID] = candidate
+			}
+
+			if page.Bookmark == "" {
+				break
+			}
+			bookmark = page.Bookmark
+		}
+	}
+
+	candidates := make([]interfaces.ScreeningCandidate, 0, len(found))
+	for _, candidate := range found {
+		candidates = append(candidates, candidate)
+	}
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].CustomerID < candidates[j].CustomerID })
+	return candidates, nil
+}
+
+// bestSanctionNameMatch returns the entry name, primary or alias, closest to a full name
+func (h *AMLCheckHandler) bestSanctionNameMatch(fullName string, entry *ComprehensiveSanctionEntry) (string, float64) {
+	bestName, bestConfidence := "", 0.0
+	for _, name := range append([]string{entry.PrimaryName}, entry.Aliases...) {
+		if confidence := h.calculateNameMatchConfidence(fullName, name); confidence > bestConfidence {
+			bestName, bestConfidence = name, confidence
+		}
+	}
+	return bestName, bestConfidence
+}
+
+// rescreenAgainstEntry runs a RISK_REASSESSMENT check for a candidate, which screens the ledger
+// sanction entries and so picks up the new entry. The data screened by the customer's current
+// check is reused; customers never checked by this chaincode are screened with the data the
+// customer chaincode returned.
+func (h *AMLCheckHandler) rescreenAgainstEntry(stub shim.ChaincodeStubInterface, candidate *interfaces.ScreeningCandidate, actorID string) (*AMLCheckResult, error) {
+	customerData := CustomerAMLData{
+		FirstName:   candidate.FirstName,
+		LastName:    candidate.LastName,
+		DateOfBirth: candidate.DateOfBirth,
+		NationalID:  candidate.NationalID,
+		Address:     candidate.Address,
+	}
+	if previous, err := h.getLatestCheck(stub, candidate.CustomerID); err == nil && previous.CustomerData != nil {
+		customerData = *previous.CustomerData
+	}
+
+	req := &AMLCheckRequest{
+		CustomerID:   candidate.CustomerID,
+		CustomerData: customerData,
+		CheckType:    AMLCheckTypeRiskReassessment,
+		ActorID:      actorID,
+	}
+	result, err := h.performComprehensiveAMLCheck(stub, services.GenerateDeterministicID(stub, config.AMLCheckPrefix), req)
+	if err != nil {
+		return nil, fmt.Errorf("failed to re-screen customer %s: %w", candidate.CustomerID, err)
+	}
+
+	if err := h.recordCheck(stub, result, actorID); err != nil {
+		return nil, err
+	}
+
+	return result, nil
+}
+
+// sanctionMatchForEntry returns a check's sanction match on a stored entry, if any
+func sanctionMatchForEntry(result *AMLCheckResult, storedEntryID string) *SanctionMatch {
+	for i := range result.SanctionScreenResult.Matches {
+		if result.SanctionScreenResult.Matches[i].ListEntryID == storedEntryID {
+			return &result.SanctionScreenResult.Matches[i]
+		}
+	}
+	return nil
+}
+
+// recordTargetedMatchEvent raises an alerted compliance event for a customer matching a newly
+// added sanction entry
+func (h *AMLCheckHandler) recordTargetedMatchEvent(stub shim.ChaincodeStubInterface, result *AMLCheckResult, entry *ComprehensiveSanctionEntry, matchedName string, confidence float64, actorID string) error {
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return err
+	}
+
+	eventID := services.GenerateDeterministicID(stub, config.ComplianceEventPrefix)
+
+	event := &domain.ComplianceEvent{
+		EventID:            eventID,
+		Timestamp:          now,
+		RuleID:             "AML_SCREENING_RULE",
+		RuleVersion:        "1.0",
+		AffectedEntityID:   result.CustomerID,
+		AffectedEntityType: "Customer",
+		EventType:          "SANCTION_TARGETED_MATCH",
+		Severity:           domain.PriorityCritical,
+		Details: map[string]interface{}{
+			"checkID":     result.CheckID,
+			"listID":      entry.ListID,
+			"entryID":     entry.EntryID,
+			"matchedName": matchedName,
+			"confidence":  confidence,
+			"status":      result.Status,
+		},
+		ActorID:          actorID,
+		IsAlerted:        true,
+		ResolutionStatus: "OPEN",
+	}
+
+	batch := services.NewWriteBatch()
+	eventKey := fmt.Sprintf("COMPLIANCE_EVENT_%s", eventID)
+	if err := batch.Put(eventKey, event); err != nil {
+		return fmt.Errorf("failed to store compliance event: %w", err)
+	}
+	h.emitComplianceEvent(batch, event)
+
+	return batch.Flush(stub)
+}
+
+// sanctionEntryLastNames lists the last names a customer resembling the entry could be indexed
+// under: the entry's last name, every trailing run of words of its names and aliases (covering
+// multi-word surnames) and every single word (covering names given surname first). Initials are
+// skipped.
+func sanctionEntryLastNames(entry *ComprehensiveSanctionEntry) []string {
+	seen := make(map[string]bool)
+	add := func(name string) {
+		if len([]rune(name)) >= 2 {
+			seen[name] = true
+		}
+	}
+
+	if lastName := strings.ToUpper(strings.TrimSpace(entry.LastName)); lastName != "" {
+		add(lastName)
+	}
+	for _, name := range append([]string{entry.PrimaryName}, entry.Aliases...) {
+		words := strings.FieldsFunc(strings.ToUpper(name), func(r rune) bool {
+			return !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '-' && r != '\''
+		})
+		for i, word := range words {
+			add(word)
+			add(strings.Join(words[i:], " "))
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
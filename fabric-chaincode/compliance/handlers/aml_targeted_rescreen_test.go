@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/compliance/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/interfaces"
+)
+
+// fakeCustomerChaincode answers SearchCustomersByName from a fixed set of customers
+type fakeCustomerChaincode struct {
+	customers []interfaces.ScreeningCandidate
+	searched  []string
+}
+
+func (f *fakeCustomerChaincode) Init(stub shim.ChaincodeStubInterface) peer.Response {
+	return shim.Success(nil)
+}
+
+func (f *fakeCustomerChaincode) Invoke(stub shim.ChaincodeStubInterface) peer.Response {
+	function, args := stub.GetFunctionAndParameters()
+	if function != "SearchCustomersByName" {
+		return shim.Error("unknown function " + function)
+	}
+	f.searched = append(f.searched, args[0])
+
+	page := interfaces.ScreeningCandidatePage{Customers: []interfaces.ScreeningCandidate{}}
+	for _, customer := range f.customers {
+		if strings.ToUpper(customer.LastName) == strings.ToUpper(args[0]) {
+			page.Customers = append(page.Customers, customer)
+		}
+	}
+	page.Count = len(page.Customers)
+	pageBytes, _ := json.Marshal(page)
+	return shim.Success(pageBytes)
+}
+
+func TestAMLCheckHandler_TargetedRescreen(t *testing.T) {
+	stub := shimtest.NewMockStub("aml_targeted_test", nil)
+	customerChaincode := &fakeCustomerChaincode{customers: []interfaces.ScreeningCandidate{
+		{CustomerID: "CUST_TR_001", FirstName: "Viktor", LastName: "Petrov"},
+		{CustomerID: "CUST_TR_002", FirstName: "Anna", LastName: "Petrov"},
+		{CustomerID: "CUST_TR_003", FirstName: "Ivan", LastName: "Ivanov"},
+	}}
+	stub.MockPeerChaincode("customer", shimtest.NewMockStub("customer", customerChaincode), "")
+	mockEmitter := &MockEventEmitter{}
+	handler := NewAMLCheckHandler(mockEmitter)
+
+	// Viktor Petrov was cleared at onboarding, before the entry existed
+	requestBytes, err := json.Marshal(AMLCheckRequest{
+		CustomerID: "CUST_TR_001",
+		CustomerData: CustomerAMLData{
+			FirstName:   "Viktor",
+			LastName:    "Petrov",
+			DateOfBirth: time.Date(1975, 3, 9, 0, 0, 0, 0, time.UTC),
+			NationalID:  "ID777000111",
+			Nationality: "US",
+			Address:     "9 Harbour Street, Boston, MA",
+			Country:     "US",
+		},
+		CheckType: AMLCheckTypeCustomerOnboarding,
+		ActorID:   "ACTOR_001",
+	})
+	require.NoError(t, err)
+	stub.MockTransactionStart("tx1")
+	resultBytes, err := handler.PerformAMLCheck(stub, []string{string(requestBytes)})
+	stub.MockTransactionEnd("tx1")
+	require.NoError(t, err)
+	var onboarding AMLCheckResult
+	require.NoError(t, json.Unmarshal(resultBytes, &onboarding))
+	require.False(t, onboarding.SanctionScreenResult.IsMatch)
+
+	// The entry reaches the ledger through list maintenance
+	sanctionLists := NewSanctionListManager(mockEmitter)
+	updateList := func(txID string, updateType SanctionUpdateType, entry ComprehensiveSanctionEntry) {
+		reqBytes, err := json.Marshal(SanctionListUpdateRequest{
+			ListID:     "OFAC_SDN",
+			UpdateType: updateType,
+			Entries:    []ComprehensiveSanctionEntry{entry},
+			Version:    txID,
+			UpdatedBy:  "ACTOR_001",
+		})
+		require.NoError(t, err)
+		stub.MockTransactionStart(txID)
+		defer stub.MockTransactionEnd(txID)
+		_, err = sanctionLists.UpdateSanctionList(stub, []string{string(reqBytes)})
+		require.NoError(t, err)
+	}
+	listBytes, err := json.Marshal(SanctionListDefinition{
+		ListID:       "OFAC_SDN",
+		ListName:     "OFAC SDN",
+		Source:       "US Treasury OFAC",
+		ListType:     SanctionListTypeSDN,
+		Jurisdiction: "US",
+		IsActive:     true,
+		CreatedBy:    "ACTOR_001",
+	})
+	require.NoError(t, err)
+	stub.MockTransactionStart("tx2a")
+	_, err = sanctionLists.CreateSanctionList(stub, []string{string(listBytes)})
+	stub.MockTransactionEnd("tx2a")
+	require.NoError(t, err)
+
+	petrov := ComprehensiveSanctionEntry{
+		EntryID:     "ENTRY_001",
+		PrimaryName: "Viktor Petrov",
+		Aliases:     []string{"V. Petrov"},
+		EntityType:  "INDIVIDUAL",
+		LastName:    "Petrov",
+		DateOfBirth: &onboarding.CustomerData.DateOfBirth,
+	}
+	updateList("tx2", UpdateTypeAdditions, petrov)
+
+	rescreen := func(txID string, req TargetedRescreenRequest) (*TargetedRescreenResult, error) {
+		reqBytes, err := json.Marshal(req)
+		require.NoError(t, err)
+		stub.MockTransactionStart(txID)
+		defer stub.MockTransactionEnd(txID)
+		resultBytes, err := handler.TargetedRescreen(stub, []string{string(reqBytes)})
+		if err != nil {
+			return nil, err
+		}
+		var result TargetedRescreenResult
+		require.NoError(t, json.Unmarshal(resultBytes, &result))
+		return &result, nil
+	}
+
+	t.Run("Only customers resembling the entry are re-screened and alerted", func(t *testing.T) {
+		mockEmitter.EmittedEvents = nil
+		result, err := rescreen("tx3", TargetedRescreenRequest{ListID: "OFAC_SDN", EntryID: "ENTRY_001", ActorID: "ACTOR_001"})
+		require.NoError(t, err)
+
+		assert.Equal(t, []string{"PETROV", "V PETROV", "VIKTOR", "VIKTOR PETROV"}, result.SearchedNames)
+		assert.Equal(t, result.SearchedNames, customerChaincode.searched)
+		assert.Equal(t, 2, result.Candidates) // Both Petrovs; Anna does not resemble the entry
+		assert.Equal(t, 1, result.Rescreened)
+		assert.Equal(t, 1, result.Alerted)
+		require.Len(t, result.Outcomes, 1)
+		outcome := result.Outcomes[0]
+		assert.Equal(t, "CUST_TR_001", outcome.CustomerID)
+		assert.Equal(t, "Viktor Petrov", outcome.MatchedName)
+		assert.Equal(t, 1.0, outcome.Confidence)
+		assert.True(t, outcome.Alerted)
+
+		// The new check supersedes the onboarding check and carries the entry's match
+		latest, err := handler.getLatestCheck(stub, "CUST_TR_001")
+		require.NoError(t, err)
+		assert.Equal(t, outcome.CheckID, latest.CheckID)
+		assert.NotEqual(t, onboarding.CheckID, latest.CheckID)
+		assert.Equal(t, AMLCheckTypeRiskReassessment, latest.CheckType)
+		assert.True(t, latest.SanctionScreenResult.IsMatch)
+		assert.Contains(t, latest.SanctionScreenResult.ListsScreened, "OFAC_SDN")
+		require.NotEmpty(t, latest.SanctionScreenResult.Matches)
+		match := latest.SanctionScreenResult.Matches[len(latest.SanctionScreenResult.Matches)-1]
+		assert.Equal(t, "OFAC_SDN_ENTRY_001", match.ListEntryID)
+		assert.Equal(t, "DOB match: true", match.AdditionalInfo)
+		assert.NotEqual(t, "CLEAR", outcome.Status)
+
+		alerted := []string{}
+		for _, emitted := range mockEmitter.EmittedEvents {
+			if event, ok := emitted.(*domain.ComplianceEvent); ok && event.IsAlerted {
+				alerted = append(alerted, event.EventType)
+			}
+		}
+		assert.Contains(t, alerted, "SANCTION_TARGETED_MATCH")
+	})
+
+	t.Run("Unknown and inactive entries are rejected", func(t *testing.T) {
+		_, err := rescreen("tx4", TargetedRescreenRequest{ListID: "OFAC_SDN", EntryID: "ENTRY_404", ActorID: "ACTOR_001"})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "not found")
+
+		petrov.IsActive = false
+		updateList("tx5", UpdateTypeIncremental, petrov)
+
+		_, err = rescreen("tx6", TargetedRescreenRequest{ListID: "OFAC_SDN", EntryID: "ENTRY_001", ActorID: "ACTOR_001"})
+		assert.Error(t, err)
+		assert.Contains(t, err.Error(), "not active")
+	})
+}
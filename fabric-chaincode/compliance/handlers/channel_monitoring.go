@@ -0,0 +1,392 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/compliance/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/interfaces"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// ChannelRuleAny is the channel of the fraud rule applied to channels without a rule of their own
+const ChannelRuleAny = "*"
+
+// deviceApplicationDateFormat is the DEVICE_APPLICATION key timestamp; a device's applications
+// sort by submission time
+const deviceApplicationDateFormat = "20060102T150405Z"
+
+// ChannelMonitoringHandler applies channel-specific fraud rules to the loan applications the loan
+// chaincode reports, and raises compliance events for applications that trip them
+type ChannelMonitoringHandler struct {
+	persistenceService *services.PersistenceService
+	eventEmitter       domain.EventEmitter
+}
+
+// NewChannelMonitoringHandler creates a new channel monitoring handler
+func NewChannelMonitoringHandler(eventEmitter domain.EventEmitter) *ChannelMonitoringHandler {
+	return &ChannelMonitoringHandler{
+		persistenceService: services.NewPersistenceService(),
+		eventEmitter:       eventEmitter,
+	}
+}
+
+// ChannelFraudRule sets the device checks for applications submitted through a channel. Limits of
+// zero are not checked. Channels without a rule of their own use the ChannelRuleAny rule, if any.
+type ChannelFraudRule struct {
+	Channel                  string                        `json:"channel"`
+	VelocityWindowHours      int                           `json:"velocityWindowHours"`
+	MaxApplicationsPerDevice int                           `json:"maxApplicationsPerDevice"` // Within the window, including the new application
+	MaxCustomersPerDevice    int                           `json:"maxCustomersPerDevice"`    // Distinct applicants within the window
+	RequireDeviceHash        bool                          `json:"requireDeviceHash"`
+	Severity                 domain.ComplianceRulePriority `json:"severity"`
+	UpdatedBy                string                        `json:"updatedBy"`
+	UpdatedDate              time.Time                     `json:"updatedDate"`
+}
+
+// ChannelFraudRuleRequest represents a request to add or replace a channel fraud rule
+type ChannelFraudRuleRequest struct {
+	Channel                  string `json:"channel"`
+	VelocityWindowHours      int    `json:"velocityWindowHours"`
+	MaxApplicationsPerDevice int    `json:"maxApplicationsPerDevice"`
+	MaxCustomersPerDevice    int    `json:"maxCustomersPerDevice"`
+	RequireDeviceHash        bool   `json:"requireDeviceHash"`
+	Severity                 string `json:"severity"` // LOW, MEDIUM, HIGH or CRITICAL
+	ActorID                  string `json:"actorID"`
+}
+
+// DeviceApplication records a loan application submitted from a device
+type DeviceApplication struct {
+	DeviceHash    string    `json:"deviceHash"`
+	LoanID        string    `json:"loanID"`
+	CustomerID    string    `json:"customerID"`
+	Channel       string    `json:"channel"`
+	SubmittedDate time.Time `json:"submittedDate"`
+}
+
+// SetChannelFraudRule adds or replaces the fraud rule for a channel
+func (h *ChannelMonitoringHandler) SetChannelFraudRule(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req ChannelFraudRuleRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse channel fraud rule request: %v", err)
+	}
+	if err := h.requireRuleMaintainer(stub); err != nil {
+		return nil, err
+	}
+	if req.Channel != ChannelRuleAny {
+		if err := validation.ValidateOriginationChannel(req.Channel); err != nil {
+			return nil, fmt.Errorf("invalid channel: %w", err)
+		}
+	}
+	if req.MaxApplicationsPerDevice < 0 || req.MaxCustomersPerDevice < 0 {
+		return nil, fmt.Errorf("device limits may not be negative")
+	}
+	if (req.MaxApplicationsPerDevice > 0 || req.MaxCustomersPerDevice > 0) && req.VelocityWindowHours <= 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "velocityWindowHours must be positive when a device limit is set")
+	}
+	severity := domain.ComplianceRulePriority(strings.ToUpper(req.Severity))
+	if _, ok := screeningSeverityRank[severity]; !ok {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid severity: %s", req.Severity)
+	}
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	rule := &ChannelFraudRule{
+		Channel:                  req.Channel,
+		VelocityWindowHours:      req.VelocityWindowHours,
+		MaxApplicationsPerDevice: req.MaxApplicationsPerDevice,
+		MaxCustomersPerDevice:    req.MaxCustomersPerDevice,
+		RequireDeviceHash:        req.RequireDeviceHash,
+		Severity:                 severity,
+		UpdatedBy:                req.ActorID,
+		UpdatedDate:              now,
+	}
+
+	ruleKey, err := stub.CreateCompositeKey(config.ChannelFraudRulePrefix, []string{rule.Channel})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create channel fraud rule key: %w", err)
+	}
+	if err := h.persistenceService.Put(stub, ruleKey, rule); err != nil {
+		return nil, fmt.Errorf("failed to store channel fraud rule: %w", err)
+	}
+
+	return json.Marshal(rule)
+}
+
+// GetChannelFraudRules returns every channel fraud rule
+func (h *ChannelMonitoringHandler) GetChannelFraudRules(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 0, got %d", len(args))
+	}
+
+	iterator, err := stub.GetStateByPartialCompositeKey(config.ChannelFraudRulePrefix, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get channel fraud rules: %w", err)
+	}
+	defer iterator.Close()
+
+	rules := []ChannelFraudRule{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate channel fraud rules: %w", err)
+		}
+		var rule ChannelFraudRule
+		if err := json.Unmarshal(response.Value, &rule); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal channel fraud rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	return json.Marshal(rules)
+}
+
+// MonitorApplicationChannel records the device a loan application was submitted from and applies
+// the channel's fraud rule to it. Alerts are raised as one compliance event for the application.
+// It is invoked cross-chaincode when the application is submitted.
+func (h *ChannelMonitoringHandler) MonitorApplicationChannel(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var report interfaces.ApplicationChannelReport
+	if err := json.Unmarshal([]byte(args[0]), &report); err != nil {
+		return nil, fmt.Errorf("failed to parse application channel report: %w", err)
+	}
+	if strings.TrimSpace(report.LoanID) == "" || strings.TrimSpace(report.CustomerID) == "" {
+		return nil, fmt.Errorf("loanID and customerID are required")
+	}
+	if err := validation.ValidateOriginationChannel(report.Channel); err != nil {
+		return nil, fmt.Errorf("invalid channel: %w", err)
+	}
+	if report.DeviceHash != "" {
+		if err := validation.ValidateDeviceHash(report.DeviceHash); err != nil {
+			return nil, err
+		}
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	rule, err := h.findChannelFraudRule(stub, report.Channel)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &interfaces.ApplicationChannelResult{Alerts: []interfaces.ApplicationChannelAlert{}}
+	if rule != nil && report.DeviceHash == "" && rule.RequireDeviceHash {
+		result.Alerts = append(result.Alerts, interfaces.ApplicationChannelAlert{
+			Code:   "DEVICE_HASH_MISSING",
+			Detail: fmt.Sprintf("%s applications must carry a device hash", report.Channel),
+		})
+	}
+
+	if report.DeviceHash != "" {
+		if rule != nil && rule.VelocityWindowHours > 0 {
+			applications, customers, err := h.deviceActivity(stub, report.DeviceHash, now.Add(-time.Duration(rule.VelocityWindowHours)*time.Hour))
+			if err != nil {
+				return nil, err
+			}
+			// Count the new application with the device's earlier ones
+			applications++
+			customers[report.CustomerID] = true
+
+			if rule.MaxApplicationsPerDevice > 0 && applications > rule.MaxApplicationsPerDevice {
+				result.Alerts = append(result.Alerts, interfaces.ApplicationChannelAlert{
+					Code:   "DEVICE_VELOCITY",
+					Detail: fmt.Sprintf("%d applications from the device within %d hours, limit %d", applications, rule.VelocityWindowHours, rule.MaxApplicationsPerDevice),
+				})
+			}
+			if rule.MaxCustomersPerDevice > 0 && len(customers) > rule.MaxCustomersPerDevice {
+				result.Alerts = append(result.Alerts, interfaces.ApplicationChannelAlert{
+					Code:   "DEVICE_SHARED_ACROSS_CUSTOMERS",
+					Detail: fmt.Sprintf("%d customers applied from the device within %d hours, limit %d", len(customers), rule.VelocityWindowHours, rule.MaxCustomersPerDevice),
+				})
+			}
+		}
+
+		if err := h.putDeviceApplication(stub, &DeviceApplication{
+			DeviceHash:    report.DeviceHash,
+			LoanID:        report.LoanID,
+			CustomerID:    report.CustomerID,
+			Channel:       report.Channel,
+			SubmittedDate: now,
+		}); err != nil {
+			return nil, err
+		}
+	}
+
+	if len(result.Alerts) == 0 {
+		return json.Marshal(result)
+	}
+
+	result.Flagged = true
+	result.Severity = string(rule.Severity)
+
+	if h.eventEmitter != nil {
+		result.EventID = services.GenerateDeterministicID(stub, config.EventPrefix)
+		event := &domain.ComplianceEvent{
+			EventID:            result.EventID,
+			Timestamp:          now,
+			RuleID:             "CHANNEL_FRAUD_RULE",
+			RuleVersion:        "1.0",
+			AffectedEntityID:   report.LoanID,
+			AffectedEntityType: "LoanApplication",
+			EventType:          "CHANNEL_FRAUD_ALERT",
+			Severity:           rule.Severity,
+			Details: map[string]interface{}{
+				"customerID":      report.CustomerID,
+				"channel":         report.Channel,
+				"deviceHash":      report.DeviceHash,
+				"loanType":        report.LoanType,
+				"requestedAmount": report.RequestedAmount,
+				"introducerID":    report.IntroducerID,
+				"alerts":          result.Alerts,
+			},
+			ExecutionResult: domain.RuleExecutionResult{
+				RuleID:      "CHANNEL_FRAUD_RULE",
+				ExecutionID: services.GenerateDeterministicID(stub, "EXEC"),
+				Timestamp:   now,
+				Success:     true,
+				Passed:      false,
+			},
+			ActorID:          report.ActorID,
+			IsAlerted:        true,
+			ResolutionStatus: "OPEN",
+		}
+		if err := h.eventEmitter.EmitComplianceEvent(stub, event); err != nil {
+			return nil, fmt.Errorf("failed to record channel fraud event: %w", err)
+		}
+	}
+
+	return json.Marshal(result)
+}
+
+// GetDeviceApplications returns the loan applications submitted from a device, oldest first
+func (h *ChannelMonitoringHandler) GetDeviceApplications(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+	if err := validation.ValidateDeviceHash(args[0]); err != nil {
+		return nil, err
+	}
+
+	iterator, err := stub.GetStateByPartialCompositeKey(config.DeviceApplicationPrefix, []string{args[0]})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get device applications: %w", err)
+	}
+	defer iterator.Close()
+
+	applications := []DeviceApplication{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate device applications: %w", err)
+		}
+		var application DeviceApplication
+		if err := json.Unmarshal(response.Value, &application); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal device application: %w", err)
+		}
+		applications = append(applications, application)
+	}
+
+	return json.Marshal(applications)
+}
+
+// Helper methods
+
+// findChannelFraudRule returns the channel's fraud rule, falling back to the ChannelRuleAny rule,
+// or nil when neither exists
+func (h *ChannelMonitoringHandler) findChannelFraudRule(stub shim.ChaincodeStubInterface, channel string) (*ChannelFraudRule, error) {
+	for _, candidate := range []string{channel, ChannelRuleAny} {
+		ruleKey, err := stub.CreateCompositeKey(config.ChannelFraudRulePrefix, []string{candidate})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create channel fraud rule key: %w", err)
+		}
+		ruleBytes, err := stub.GetState(ruleKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read channel fraud rule: %w", err)
+		}
+		if ruleBytes == nil {
+			continue
+		}
+		var rule ChannelFraudRule
+		if err := json.Unmarshal(ruleBytes, &rule); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal channel fraud rule: %w", err)
+		}
+		return &rule, nil
+	}
+	return nil, nil
+}
+
+// deviceActivity counts a device's applications submitted after since and the customers behind them
+func (h *ChannelMonitoringHandler) deviceActivity(stub shim.ChaincodeStubInterface, deviceHash string, since time.Time) (int, map[string]bool, error) {
+	iterator, err := stub.GetStateByPartialCompositeKey(config.DeviceApplicationPrefix, []string{deviceHash})
+	if err != nil {
+		return 0, nil, fmt.Errorf("failed to get device applications: %w", err)
+	}
+	defer iterator.Close()
+
+	applications := 0
+	customers := map[string]bool{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return 0, nil, fmt.Errorf("failed to iterate device applications: %w", err)
+		}
+		var application DeviceApplication
+		if err := json.Unmarshal(response.Value, &application); err != nil {
+			return 0, nil, fmt.Errorf("failed to unmarshal device application: %w", err)
+		}
+		if !application.SubmittedDate.After(since) {
+			continue
+		}
+		applications++
+		customers[application.CustomerID] = true
+	}
+	return applications, customers, nil
+}
+
+// putDeviceApplication indexes an application under its device and submission time
+func (h *ChannelMonitoringHandler) putDeviceApplication(stub shim.ChaincodeStubInterface, application *DeviceApplication) error {
+	key, err := stub.CreateCompositeKey(config.DeviceApplicationPrefix, []string{
+		application.DeviceHash,
+		application.SubmittedDate.UTC().Format(deviceApplicationDateFormat),
+		application.LoanID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create device application key: %w", err)
+	}
+	if err := h.persistenceService.Put(stub, key, application); err != nil {
+		return fmt.Errorf("failed to store device application: %w", err)
+	}
+	return nil
+}
+
+// requireRuleMaintainer restricts channel fraud rule changes to compliance officers
+func (h *ChannelMonitoringHandler) requireRuleMaintainer(stub shim.ChaincodeStubInterface) error {
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return err
+	}
+	if role != string(validation.ActorRoleComplianceOfficer) && role != string(validation.ActorRoleChiefComplianceOfficer) {
+		return services.NewChaincodeError(services.ErrCodeAccessDenied, "", "channel fraud rules may only be maintained by a %s or %s", validation.ActorRoleComplianceOfficer, validation.ActorRoleChiefComplianceOfficer)
+	}
+	return nil
+}
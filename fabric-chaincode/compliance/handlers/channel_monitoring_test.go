@@ -0,0 +1,123 @@
+package handlers
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/compliance/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/interfaces"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+func TestChannelMonitoringHandler_DeviceVelocity(t *testing.T) {
+	start := time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC)
+	clock := services.NewFixedClock(start)
+	defer services.SetClock(clock)()
+	stub := shimtest.NewMockStub("channel_monitoring_test", nil)
+	mockEmitter := &MockEventEmitter{}
+	handler := NewChannelMonitoringHandler(mockEmitter)
+
+	invoke := func(txID string, fn func([]string) ([]byte, error), request interface{}) ([]byte, error) {
+		requestBytes, err := json.Marshal(request)
+		require.NoError(t, err)
+		stub.MockTransactionStart(txID)
+		defer stub.MockTransactionEnd(txID)
+		return fn([]string{string(requestBytes)})
+	}
+	setRule := func(txID string, req ChannelFraudRuleRequest) error {
+		_, err := invoke(txID, func(args []string) ([]byte, error) { return handler.SetChannelFraudRule(stub, args) }, req)
+		return err
+	}
+	monitor := func(txID, loanID, customerID, channel, deviceHash string) interfaces.ApplicationChannelResult {
+		resultBytes, err := invoke(txID, func(args []string) ([]byte, error) { return handler.MonitorApplicationChannel(stub, args) }, interfaces.ApplicationChannelReport{
+			LoanID:          loanID,
+			CustomerID:      customerID,
+			Channel:         channel,
+			DeviceHash:      deviceHash,
+			LoanType:        "PERSONAL",
+			RequestedAmount: 5000,
+			ActorID:         "ACTOR_001",
+		})
+		require.NoError(t, err)
+		var result interfaces.ApplicationChannelResult
+		require.NoError(t, json.Unmarshal(resultBytes, &result))
+		return result
+	}
+	alertCodes := func(result interfaces.ApplicationChannelResult) []string {
+		codes := []string{}
+		for _, alert := range result.Alerts {
+			codes = append(codes, alert.Code)
+		}
+		return codes
+	}
+	device := strings.Repeat("ab", 32)
+
+	// Only compliance officers maintain the rules, and limits need a window
+	stub.Creator = newRoleIdentity(t, "Underwriter")
+	err := setRule("rule_1", ChannelFraudRuleRequest{Channel: "MOBILE", VelocityWindowHours: 24, MaxApplicationsPerDevice: 2, Severity: "HIGH", ActorID: "ACTOR_001"})
+	assert.Contains(t, err.Error(), "may only be maintained by")
+
+	stub.Creator = newRoleIdentity(t, "Compliance_Officer")
+	err = setRule("rule_2", ChannelFraudRuleRequest{Channel: "FAX", VelocityWindowHours: 24, Severity: "HIGH", ActorID: "ACTOR_001"})
+	assert.Contains(t, err.Error(), "invalid channel")
+	err = setRule("rule_3", ChannelFraudRuleRequest{Channel: "MOBILE", MaxApplicationsPerDevice: 2, Severity: "HIGH", ActorID: "ACTOR_001"})
+	assert.Contains(t, err.Error(), "velocityWindowHours must be positive")
+
+	require.NoError(t, setRule("rule_4", ChannelFraudRuleRequest{Channel: "MOBILE", VelocityWindowHours: 24, MaxApplicationsPerDevice: 2, MaxCustomersPerDevice: 1, RequireDeviceHash: true, Severity: "high", ActorID: "ACTOR_001"}))
+	require.NoError(t, setRule("rule_5", ChannelFraudRuleRequest{Channel: ChannelRuleAny, Severity: "LOW", ActorID: "ACTOR_001"}))
+
+	// Within the limits nothing is raised
+	result := monitor("mon_1", "LOAN_1", "CUST_1", "MOBILE", device)
+	assert.False(t, result.Flagged)
+	assert.Empty(t, result.Alerts)
+	assert.Empty(t, mockEmitter.EmittedEvents)
+
+	// A second customer on the same device trips the customer limit
+	result = monitor("mon_2", "LOAN_2", "CUST_2", "MOBILE", device)
+	assert.True(t, result.Flagged)
+	assert.Equal(t, []string{"DEVICE_SHARED_ACROSS_CUSTOMERS"}, alertCodes(result))
+	assert.Equal(t, string(domain.PriorityHigh), result.Severity)
+	require.Len(t, mockEmitter.EmittedEvents, 1)
+	event := mockEmitter.EmittedEvents[0].(*domain.ComplianceEvent)
+	assert.Equal(t, result.EventID, event.EventID)
+	assert.Equal(t, "CHANNEL_FRAUD_ALERT", event.EventType)
+	assert.Equal(t, "LOAN_2", event.AffectedEntityID)
+	assert.True(t, event.IsAlerted)
+
+	// A third application within the window also trips the velocity limit
+	clock.Advance(2 * time.Hour)
+	result = monitor("mon_3", "LOAN_3", "CUST_1", "MOBILE", device)
+	assert.ElementsMatch(t, []string{"DEVICE_VELOCITY", "DEVICE_SHARED_ACROSS_CUSTOMERS"}, alertCodes(result))
+
+	// Once the earlier applications leave the window the device is clean again
+	clock.Advance(48 * time.Hour)
+	result = monitor("mon_4", "LOAN_4", "CUST_1", "MOBILE", device)
+	assert.False(t, result.Flagged)
+
+	// Mobile applications must carry a device hash; the catch-all rule for other channels does not
+	result = monitor("mon_5", "LOAN_5", "CUST_3", "MOBILE", "")
+	assert.Equal(t, []string{"DEVICE_HASH_MISSING"}, alertCodes(result))
+	result = monitor("mon_6", "LOAN_6", "CUST_3", "BRANCH", "")
+	assert.False(t, result.Flagged)
+
+	_, err = invoke("mon_7", func(args []string) ([]byte, error) { return handler.MonitorApplicationChannel(stub, args) }, interfaces.ApplicationChannelReport{
+		LoanID: "LOAN_7", CustomerID: "CUST_1", Channel: "MOBILE", DeviceHash: "not-a-hash", ActorID: "ACTOR_001",
+	})
+	assert.Contains(t, err.Error(), "device hash must be")
+
+	// Every application from the device is recorded, oldest first
+	stub.MockTransactionStart("device")
+	applicationsBytes, err := handler.GetDeviceApplications(stub, []string{device})
+	stub.MockTransactionEnd("device")
+	require.NoError(t, err)
+	var applications []DeviceApplication
+	require.NoError(t, json.Unmarshal(applicationsBytes, &applications))
+	require.Len(t, applications, 4)
+	assert.Equal(t, "LOAN_1", applications[0].LoanID)
+	assert.Equal(t, "LOAN_4", applications[3].LoanID)
+}
@@ -0,0 +1,131 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/compliance/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// complianceEventKeyPrefix is the ledger key prefix of compliance events written by the event
+// emitter. AML handlers also keep a COMPLIANCE_EVENT_ copy, which queries skip so events are not
+// returned twice.
+const complianceEventKeyPrefix = "compliance_event~"
+
+// ComplianceEventSelector filters compliance events in a rich query. Empty fields do not filter;
+// DateFrom and DateTo are RFC3339 timestamps bounding the event timestamp inclusively.
+type ComplianceEventSelector struct {
+	EventType        string `json:"eventType,omitempty"`
+	IsAlerted        *bool  `json:"isAlerted,omitempty"`
+	ActorID          string `json:"actorID,omitempty"`
+	RuleID           string `json:"ruleID,omitempty"`
+	AffectedEntityID string `json:"affectedEntityID,omitempty"`
+	Severity         string `json:"severity,omitempty"`
+	ResolutionStatus string `json:"resolutionStatus,omitempty"`
+	DateFrom         string `json:"dateFrom,omitempty"`
+	DateTo           string `json:"dateTo,omitempty"`
+}
+
+// ComplianceEventQueryResult is a page of compliance events matching a selector
+type ComplianceEventQueryResult struct {
+	Events   []domain.ComplianceEvent `json:"events"`
+	Count    int                      `json:"count"`
+	Bookmark string                   `json:"bookmark"`
+}
+
+// ComplianceEventQueryHandler runs CouchDB rich queries over compliance events
+type ComplianceEventQueryHandler struct {
+	persistenceService *services.PersistenceService
+}
+
+// NewComplianceEventQueryHandler creates a new compliance event query handler
+func NewComplianceEventQueryHandler() *ComplianceEventQueryHandler {
+	return &ComplianceEventQueryHandler{
+		persistenceService: services.NewPersistenceService(),
+	}
+}
+
+// QueryComplianceEvents returns a page of compliance events matching a selector. It requires a
+// CouchDB state database; the indexes it relies on are in META-INF/statedb/couchdb/indexes.
+// Args: selectorJSON, [pageSize], [bookmark]
+func (h *ComplianceEventQueryHandler) QueryComplianceEvents(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) < 1 || len(args) > 3 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1 to 3, got %d", len(args))
+	}
+
+	var selector ComplianceEventSelector
+	if err := json.Unmarshal([]byte(args[0]), &selector); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal selector: %w", err)
+	}
+	pageSize, bookmark, err := services.ParsePageArgs(args[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	query, err := buildComplianceEventQuery(&selector)
+	if err != nil {
+		return nil, err
+	}
+	entries, nextBookmark, err := h.persistenceService.GetPageByQuery(stub, query, pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+
+	events := []domain.ComplianceEvent{}
+	for _, entry := range entries {
+		var event domain.ComplianceEvent
+		if err := json.Unmarshal(entry.Value, &event); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal compliance event %s: %w", entry.Key, err)
+		}
+		events = append(events, event)
+	}
+
+	return json.Marshal(&ComplianceEventQueryResult{Events: events, Count: len(events), Bookmark: nextBookmark})
+}
+
+// buildComplianceEventQuery turns a selector into a CouchDB query over compliance events
+func buildComplianceEventQuery(selector *ComplianceEventSelector) (string, error) {
+	query := services.NewSelector().KeyPrefix(complianceEventKeyPrefix)
+
+	fields := map[string]string{
+		"eventType":        selector.EventType,
+		"actorID":          selector.ActorID,
+		"ruleID":           selector.RuleID,
+		"affectedEntityID": selector.AffectedEntityID,
+		"severity":         selector.Severity,
+		"resolutionStatus": selector.ResolutionStatus,
+	}
+	for field, value := range fields {
+		if value != "" {
+			query.Eq(field, value)
+		}
+	}
+	if selector.IsAlerted != nil {
+		query.Eq("isAlerted", *selector.IsAlerted)
+	}
+
+	// Timestamps are stored as RFC3339 strings, so the bounds compare as strings
+	var from, to interface{}
+	if selector.DateFrom != "" {
+		dateFrom, err := time.Parse(time.RFC3339, selector.DateFrom)
+		if err != nil {
+			return "", fmt.Errorf("invalid dateFrom %s: %w", selector.DateFrom, err)
+		}
+		from = dateFrom.UTC().Format(time.RFC3339Nano)
+	}
+	if selector.DateTo != "" {
+		dateTo, err := time.Parse(time.RFC3339, selector.DateTo)
+		if err != nil {
+			return "", fmt.Errorf("invalid dateTo %s: %w", selector.DateTo, err)
+		}
+		to = dateTo.UTC().Format(time.RFC3339Nano)
+	}
+	if from != nil || to != nil {
+		query.Range("timestamp", from, to)
+	}
+
+	return query.Query()
+}
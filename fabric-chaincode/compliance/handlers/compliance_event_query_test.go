@@ -0,0 +1,58 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBuildComplianceEventQuery(t *testing.T) {
+	t.Run("Only compliance events emitted by the event emitter are selected", func(t *testing.T) {
+		query, err := buildComplianceEventQuery(&ComplianceEventSelector{})
+		require.NoError(t, err)
+		assert.JSONEq(t, `{"selector": {"_id": {"$gte": "compliance_event~", "$lt": "compliance_event~\uffff"}}}`, query)
+	})
+
+	t.Run("Selector fields become CouchDB conditions", func(t *testing.T) {
+		alerted := true
+		query, err := buildComplianceEventQuery(&ComplianceEventSelector{
+			EventType: "AML_CHECK_COMPLETED",
+			IsAlerted: &alerted,
+			ActorID:   "ACTOR_001",
+			DateFrom:  "2024-01-01T00:00:00Z",
+			DateTo:    "2024-01-31T23:59:59+02:00",
+		})
+		require.NoError(t, err)
+
+		var parsed struct {
+			Selector map[string]map[string]interface{} `json:"selector"`
+		}
+		require.NoError(t, json.Unmarshal([]byte(query), &parsed))
+		assert.Equal(t, "AML_CHECK_COMPLETED", parsed.Selector["eventType"]["$eq"])
+		assert.Equal(t, true, parsed.Selector["isAlerted"]["$eq"])
+		assert.Equal(t, "ACTOR_001", parsed.Selector["actorID"]["$eq"])
+		assert.Equal(t, "2024-01-01T00:00:00Z", parsed.Selector["timestamp"]["$gte"])
+		assert.Equal(t, "2024-01-31T21:59:59Z", parsed.Selector["timestamp"]["$lte"])
+		assert.NotContains(t, parsed.Selector, "ruleID")
+	})
+
+	t.Run("Malformed dates are rejected", func(t *testing.T) {
+		_, err := buildComplianceEventQuery(&ComplianceEventSelector{DateFrom: "2024-01-01"})
+		assert.Error(t, err)
+	})
+}
+
+func TestQueryComplianceEvents_RequiresCouchDB(t *testing.T) {
+	stub := shimtest.NewMockStub("event_query_test", nil)
+	handler := NewComplianceEventQueryHandler()
+
+	_, err := handler.QueryComplianceEvents(stub, []string{`{"eventType": "AML_CHECK_COMPLETED"}`, "10"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "CouchDB")
+
+	_, err = handler.QueryComplianceEvents(stub, []string{`{"eventType": `})
+	assert.Error(t, err)
+}
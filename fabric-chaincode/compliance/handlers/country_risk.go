@@ -0,0 +1,244 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// FATF list a country is on, if any
+const (
+	FATFStatusCallForAction       = "CALL_FOR_ACTION"      // "Black list": high-risk jurisdictions subject to a call for action
+	FATFStatusIncreasedMonitoring = "INCREASED_MONITORING" // "Grey list": jurisdictions under increased monitoring
+)
+
+// countryRiskVersionFormat keeps a country's history keys in version order
+const countryRiskVersionFormat = "%06d"
+
+// defaultCountryRisks apply to countries compliance has not scored on the ledger
+var defaultCountryRisks = map[string]float64{
+	"AF": 90, "IR": 85, "KP": 95, "SY": 90, "YE": 80,
+	"SO": 85, "LY": 80, "IQ": 75, "MM": 70, "VE": 65,
+}
+
+// CountryRiskHandler maintains the country risk table used for geographic AML risk, so the model
+// can follow FATF list updates without redeploying chaincode
+type CountryRiskHandler struct {
+	persistenceService *services.PersistenceService
+}
+
+// NewCountryRiskHandler creates a new country risk handler
+func NewCountryRiskHandler() *CountryRiskHandler {
+	return &CountryRiskHandler{
+		persistenceService: services.NewPersistenceService(),
+	}
+}
+
+// CountryRisk is the geographic risk score of customers resident in a country. A score of zero
+// means no elevated risk, which is how a country leaving the FATF lists is taken off the table.
+// Version counts the country's changes on the ledger and is 0 for a built-in default.
+type CountryRisk struct {
+	CountryCode string    `json:"countryCode"` // ISO 3166-1 alpha-2
+	RiskScore   float64   `json:"riskScore"`   // 0 to 100
+	FATFStatus  string    `json:"fatfStatus,omitempty"`
+	Reason      string    `json:"reason,omitempty"`
+	Version     int       `json:"version"`
+	UpdatedBy   string    `json:"updatedBy,omitempty"`
+	UpdatedDate time.Time `json:"updatedDate"`
+}
+
+// CountryRiskRequest represents a request to set a country's risk score
+type CountryRiskRequest struct {
+	CountryCode string  `json:"countryCode"`
+	RiskScore   float64 `json:"riskScore"`
+	FATFStatus  string  `json:"fatfStatus,omitempty"`
+	Reason      string  `json:"reason"`
+	ActorID     string  `json:"actorID"`
+}
+
+// SetCountryRisk sets a country's risk score. Each change is kept in the country's history.
+func (h *CountryRiskHandler) SetCountryRisk(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req CountryRiskRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse country risk request: %v", err)
+	}
+	if err := h.requireRiskMaintainer(stub); err != nil {
+		return nil, err
+	}
+	countryCode, err := normalizeCountryCode(req.CountryCode)
+	if err != nil {
+		return nil, err
+	}
+	if req.RiskScore < 0 || req.RiskScore > 100 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "riskScore must be between 0 and 100, got %.2f", req.RiskScore)
+	}
+	fatfStatus := strings.ToUpper(strings.TrimSpace(req.FATFStatus))
+	if fatfStatus != "" && fatfStatus != FATFStatusCallForAction && fatfStatus != FATFStatusIncreasedMonitoring {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid fatfStatus: %s", req.FATFStatus)
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "reason", "reason is required")
+	}
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	current, err := countryRisk(stub, countryCode)
+	if err != nil {
+		return nil, err
+	}
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	risk := &CountryRisk{
+		CountryCode: countryCode,
+		RiskScore:   req.RiskScore,
+		FATFStatus:  fatfStatus,
+		Reason:      req.Reason,
+		Version:     current.Version + 1,
+		UpdatedBy:   req.ActorID,
+		UpdatedDate: now,
+	}
+
+	riskKey, err := stub.CreateCompositeKey(config.CountryRiskPrefix, []string{countryCode})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create country risk key: %w", err)
+	}
+	if err := h.persistenceService.Put(stub, riskKey, risk); err != nil {
+		return nil, fmt.Errorf("failed to store country risk: %w", err)
+	}
+	historyKey, err := stub.CreateCompositeKey(config.CountryRiskHistoryPrefix, []string{countryCode, fmt.Sprintf(countryRiskVersionFormat, risk.Version)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create country risk history key: %w", err)
+	}
+	if err := h.persistenceService.Put(stub, historyKey, risk); err != nil {
+		return nil, fmt.Errorf("failed to store country risk history: %w", err)
+	}
+
+	return json.Marshal(risk)
+}
+
+// GetCountryRiskTable returns the country risk table in force, by country code: the ledger's
+// scores and the built-in defaults for countries it has not scored
+func (h *CountryRiskHandler) GetCountryRiskTable(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 0, got %d", len(args))
+	}
+
+	table := map[string]CountryRisk{}
+	for countryCode, riskScore := range defaultCountryRisks {
+		table[countryCode] = CountryRisk{CountryCode: countryCode, RiskScore: riskScore}
+	}
+
+	iterator, err := stub.GetStateByPartialCompositeKey(config.CountryRiskPrefix, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get country risks: %w", err)
+	}
+	defer iterator.Close()
+
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate country risks: %w", err)
+		}
+		var risk CountryRisk
+		if err := json.Unmarshal(response.Value, &risk); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal country risk: %w", err)
+		}
+		table[risk.CountryCode] = risk
+	}
+
+	risks := []CountryRisk{}
+	for _, risk := range table {
+		risks = append(risks, risk)
+	}
+	sort.Slice(risks, func(i, j int) bool { return risks[i].CountryCode < risks[j].CountryCode })
+
+	return json.Marshal(risks)
+}
+
+// GetCountryRiskHistory returns every change to a country's risk score, oldest first
+func (h *CountryRiskHandler) GetCountryRiskHistory(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	countryCode, err := normalizeCountryCode(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	iterator, err := stub.GetStateByPartialCompositeKey(config.CountryRiskHistoryPrefix, []string{countryCode})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get country risk history: %w", err)
+	}
+	defer iterator.Close()
+
+	history := []CountryRisk{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate country risk history: %w", err)
+		}
+		var risk CountryRisk
+		if err := json.Unmarshal(response.Value, &risk); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal country risk: %w", err)
+		}
+		history = append(history, risk)
+	}
+
+	return json.Marshal(history)
+}
+
+// requireRiskMaintainer allows compliance officers only to change the country risk table
+func (h *CountryRiskHandler) requireRiskMaintainer(stub shim.ChaincodeStubInterface) error {
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return err
+	}
+	if role != string(validation.ActorRoleComplianceOfficer) && role != string(validation.ActorRoleChiefComplianceOfficer) {
+		return services.NewChaincodeError(services.ErrCodeAccessDenied, "", "country risks may only be maintained by a %s or %s", validation.ActorRoleComplianceOfficer, validation.ActorRoleChiefComplianceOfficer)
+	}
+	return nil
+}
+
+// countryRisk returns the risk in force for a country: the ledger's score, else the built-in
+// default, else a zero score
+func countryRisk(stub shim.ChaincodeStubInterface, countryCode string) (*CountryRisk, error) {
+	riskKey, err := stub.CreateCompositeKey(config.CountryRiskPrefix, []string{countryCode})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create country risk key: %w", err)
+	}
+	riskBytes, err := stub.GetState(riskKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read country risk: %w", err)
+	}
+	if riskBytes != nil {
+		var risk CountryRisk
+		if err := json.Unmarshal(riskBytes, &risk); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal country risk: %w", err)
+		}
+		return &risk, nil
+	}
+	return &CountryRisk{CountryCode: countryCode, RiskScore: defaultCountryRisks[countryCode]}, nil
+}
+
+func normalizeCountryCode(countryCode string) (string, error) {
+	code := strings.ToUpper(strings.TrimSpace(countryCode))
+	if len(code) != 2 || code[0] < 'A' || code[0] > 'Z' || code[1] < 'A' || code[1] > 'Z' {
+		return "", services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid country code %q: expected ISO 3166-1 alpha-2", countryCode)
+	}
+	return code, nil
+}
@@ -0,0 +1,109 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+func TestCountryRiskHandler_Table(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	clock := services.NewFixedClock(now)
+	defer services.SetClock(clock)()
+	stub := shimtest.NewMockStub("country_risk_test", nil)
+	handler := NewCountryRiskHandler()
+	amlHandler := NewAMLCheckHandler(&MockEventEmitter{})
+
+	setRisk := func(txID string, request CountryRiskRequest) (*CountryRisk, error) {
+		requestBytes, err := json.Marshal(request)
+		require.NoError(t, err)
+		stub.MockTransactionStart(txID)
+		defer stub.MockTransactionEnd(txID)
+		riskBytes, err := handler.SetCountryRisk(stub, []string{string(requestBytes)})
+		if err != nil {
+			return nil, err
+		}
+		var risk CountryRisk
+		require.NoError(t, json.Unmarshal(riskBytes, &risk))
+		return &risk, nil
+	}
+	geographicRisk := func(txID, country string) *RiskFactor {
+		stub.MockTransactionStart(txID)
+		defer stub.MockTransactionEnd(txID)
+		factor, err := amlHandler.assessGeographicRisk(stub, &CustomerAMLData{Country: country}, now)
+		require.NoError(t, err)
+		return factor
+	}
+
+	// Built-in defaults apply until compliance scores a country
+	factor := geographicRisk("geo_1", "IR")
+	require.NotNil(t, factor)
+	assert.Equal(t, 85.0, factor.RiskScore)
+	assert.Contains(t, factor.Evidence, "country risk version: 0")
+	assert.Nil(t, geographicRisk("geo_2", "HR"))
+
+	// Only compliance officers maintain the table
+	stub.Creator = newRoleIdentity(t, "Underwriter")
+	_, err := setRisk("set_1", CountryRiskRequest{CountryCode: "HR", RiskScore: 40, Reason: "FATF grey list", ActorID: "ACTOR_001"})
+	assert.Contains(t, err.Error(), "may only be maintained by")
+
+	stub.Creator = newRoleIdentity(t, "Compliance_Officer")
+	_, err = setRisk("set_2", CountryRiskRequest{CountryCode: "HRV", RiskScore: 40, Reason: "FATF grey list", ActorID: "ACTOR_001"})
+	assert.Contains(t, err.Error(), "invalid country code")
+	_, err = setRisk("set_3", CountryRiskRequest{CountryCode: "HR", RiskScore: 140, Reason: "FATF grey list", ActorID: "ACTOR_001"})
+	assert.Contains(t, err.Error(), "riskScore must be between 0 and 100")
+	_, err = setRisk("set_4", CountryRiskRequest{CountryCode: "HR", RiskScore: 40, FATFStatus: "WATCH", Reason: "FATF grey list", ActorID: "ACTOR_001"})
+	assert.Contains(t, err.Error(), "invalid fatfStatus")
+
+	// A country added to the grey list
+	risk, err := setRisk("set_5", CountryRiskRequest{CountryCode: "hr", RiskScore: 40, FATFStatus: "increased_monitoring", Reason: "FATF grey list", ActorID: "ACTOR_001"})
+	require.NoError(t, err)
+	assert.Equal(t, "HR", risk.CountryCode)
+	assert.Equal(t, FATFStatusIncreasedMonitoring, risk.FATFStatus)
+	assert.Equal(t, 1, risk.Version)
+
+	factor = geographicRisk("geo_3", "HR")
+	require.NotNil(t, factor)
+	assert.Equal(t, 40.0, factor.RiskScore)
+	assert.Contains(t, factor.Evidence, "country risk version: 1")
+
+	// A country leaving the lists is scored zero and no longer raises geographic risk
+	_, err = setRisk("set_6", CountryRiskRequest{CountryCode: "IR", RiskScore: 95, FATFStatus: FATFStatusCallForAction, Reason: "FATF call for action", ActorID: "ACTOR_001"})
+	require.NoError(t, err)
+	risk, err = setRisk("set_7", CountryRiskRequest{CountryCode: "IR", RiskScore: 0, Reason: "Delisted by FATF", ActorID: "ACTOR_002"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, risk.Version)
+	assert.Nil(t, geographicRisk("geo_4", "IR"))
+
+	stub.MockTransactionStart("table")
+	tableBytes, err := handler.GetCountryRiskTable(stub, []string{})
+	stub.MockTransactionEnd("table")
+	require.NoError(t, err)
+	var table []CountryRisk
+	require.NoError(t, json.Unmarshal(tableBytes, &table))
+	require.Len(t, table, len(defaultCountryRisks)+1)
+	assert.Equal(t, "AF", table[0].CountryCode)
+	assert.Equal(t, 0, table[0].Version)
+	for _, entry := range table {
+		if entry.CountryCode == "IR" {
+			assert.Equal(t, 0.0, entry.RiskScore)
+			assert.Equal(t, "ACTOR_002", entry.UpdatedBy)
+		}
+	}
+
+	stub.MockTransactionStart("history")
+	historyBytes, err := handler.GetCountryRiskHistory(stub, []string{"ir"})
+	stub.MockTransactionEnd("history")
+	require.NoError(t, err)
+	var history []CountryRisk
+	require.NoError(t, json.Unmarshal(historyBytes, &history))
+	require.Len(t, history, 2)
+	assert.Equal(t, 95.0, history[0].RiskScore)
+	assert.Equal(t, FATFStatusCallForAction, history[0].FATFStatus)
+	assert.Equal(t, "Delisted by FATF", history[1].Reason)
+}
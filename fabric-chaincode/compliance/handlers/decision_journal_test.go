@@ -0,0 +1,99 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/pkg/statebased"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sharedChaincode "github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/chaincode"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+func TestDecisionJournalHandler_DualSignOff(t *testing.T) {
+	stub := shimtest.NewMockStub("decision_journal_test", nil)
+	handler := sharedChaincode.NewDecisionJournalHandler()
+
+	invoke := func(txID string, creator []byte, fn func(args []string) ([]byte, error), request interface{}) ([]byte, error) {
+		requestBytes, err := json.Marshal(request)
+		require.NoError(t, err)
+		stub.Creator = creator
+		stub.MockTransactionStart(txID)
+		defer stub.MockTransactionEnd(txID)
+		return fn([]string{string(requestBytes)})
+	}
+	record := func(args []string) ([]byte, error) { return handler.RecordDecision(stub, args) }
+	cosign := func(args []string) ([]byte, error) { return handler.CosignDecision(stub, args) }
+	entryOf := func(entryBytes []byte, err error) *services.DecisionJournalEntry {
+		require.NoError(t, err)
+		var entry services.DecisionJournalEntry
+		require.NoError(t, json.Unmarshal(entryBytes, &entry))
+		return &entry
+	}
+
+	bank := newMemberIdentity(t, config.BankMSPID, "Compliance_Officer")
+	regulator := newMemberIdentity(t, config.RegulatorMSPID, "Regulator")
+	writeOff := sharedChaincode.DecisionRecordRequest{
+		DecisionType: "WRITE_OFF", EntityID: "LOAN_001", EntityType: "LoanApplication",
+		Decision: "Write off 4,200.00", Rationale: "Borrower deceased, estate insolvent", ActorID: "ACTOR_001",
+	}
+
+	// Only the bank journals decisions, and only the sensitive kinds
+	_, err := invoke("record_regulator", regulator, record, writeOff)
+	assert.Contains(t, err.Error(), "must be recorded by "+config.BankMSPID)
+	invalid := writeOff
+	invalid.DecisionType = "LOAN_APPROVAL"
+	_, err = invoke("record_invalid", bank, record, invalid)
+	assert.Error(t, err)
+	unexplained := writeOff
+	unexplained.Rationale = " "
+	_, err = invoke("record_unexplained", bank, record, unexplained)
+	assert.Error(t, err)
+
+	entry := entryOf(invoke("record", bank, record, writeOff))
+	assert.Equal(t, "record", entry.EntryID)
+	assert.Equal(t, services.JournalEntryPendingCosign, entry.Status)
+	assert.Equal(t, config.BankMSPID, entry.RecordedByMSP)
+
+	// The entry's key demands endorsement from peers of both organisations
+	stub.MockTransactionStart("policy")
+	entryKey, err := stub.CreateCompositeKey(config.DecisionJournalPrefix, []string{"LOAN_001", entry.EntryID})
+	require.NoError(t, err)
+	policyBytes, err := stub.GetStateValidationParameter(entryKey)
+	require.NoError(t, err)
+	stub.MockTransactionEnd("policy")
+	policy, err := statebased.NewStateEP(policyBytes)
+	require.NoError(t, err)
+	assert.ElementsMatch(t, []string{config.BankMSPID, config.RegulatorMSPID}, policy.ListOrgs())
+
+	// The bank cannot co-sign its own decision; the regulator's co-signature is final
+	cosignRequest := sharedChaincode.DecisionCosignRequest{EntityID: "LOAN_001", EntryID: entry.EntryID, Comment: "Reviewed estate papers", ActorID: "REGULATOR_001"}
+	_, err = invoke("cosign_bank", bank, cosign, cosignRequest)
+	assert.Contains(t, err.Error(), "must be co-signed by "+config.RegulatorMSPID)
+	cosigned := entryOf(invoke("cosign", regulator, cosign, cosignRequest))
+	assert.Equal(t, services.JournalEntryCosigned, cosigned.Status)
+	assert.Equal(t, config.RegulatorMSPID, cosigned.CosignedByMSP)
+	assert.Equal(t, "cosign", cosigned.CosignTxID)
+	_, err = invoke("cosign_again", regulator, cosign, cosignRequest)
+	require.Error(t, err)
+	assert.Equal(t, services.ErrCodeInvalidTransition, services.ClassifyError(err).Code)
+
+	// Later decisions on the entity are appended beside it
+	writeOff.Decision = "Recovery of 300.00 from estate"
+	entryOf(invoke("record_recovery", bank, record, writeOff))
+	stub.MockTransactionStart("journal")
+	journalBytes, err := handler.GetDecisionJournal(stub, []string{"LOAN_001"})
+	stub.MockTransactionEnd("journal")
+	require.NoError(t, err)
+	var journal []services.DecisionJournalEntry
+	require.NoError(t, json.Unmarshal(journalBytes, &journal))
+	require.Len(t, journal, 2)
+	statuses := map[string]string{}
+	for _, journaled := range journal {
+		statuses[journaled.EntryID] = journaled.Status
+	}
+	assert.Equal(t, map[string]string{"record": services.JournalEntryCosigned, "record_recovery": services.JournalEntryPendingCosign}, statuses)
+}
@@ -0,0 +1,360 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/compliance/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// EscalationRouteAny matches any violation type or severity in a routing rule
+const EscalationRouteAny = "*"
+
+// escalationDueDateFormat is the ESCALATION_DUE_ index timestamp; keys sort by SLA due date
+const escalationDueDateFormat = "20060102T150405Z"
+
+// EscalationRoutingRule sends new escalations of a violation type and severity to a level and team.
+// The most specific rule wins: type and severity, then type alone, then severity alone, then the
+// catch-all rule.
+type EscalationRoutingRule struct {
+	ViolationType string                        `json:"violationType"` // EscalationRouteAny matches any type
+	Severity      domain.ComplianceRulePriority `json:"severity"`      // EscalationRouteAny matches any severity
+	Level         EscalationLevel               `json:"level"`
+	Team          string                        `json:"team"`
+	UpdatedBy     string                        `json:"updatedBy"`
+	UpdatedDate   time.Time                     `json:"updatedDate"`
+}
+
+// EscalationRoutingRuleRequest represents a request to add or replace a routing rule
+type EscalationRoutingRuleRequest struct {
+	ViolationType string                        `json:"violationType"`
+	Severity      domain.ComplianceRulePriority `json:"severity"`
+	Level         EscalationLevel               `json:"level"`
+	Team          string                        `json:"team"`
+	ActorID       string                        `json:"actorID"`
+}
+
+// EscalateOverdueRequest represents a sweep of escalations past their SLA due date
+type EscalateOverdueRequest struct {
+	ActorID string `json:"actorID"`
+}
+
+// OverdueEscalationOutcome records what the sweep did with one breached escalation
+type OverdueEscalationOutcome struct {
+	EscalationID string          `json:"escalationID"`
+	DueDate      time.Time       `json:"dueDate"`
+	FromLevel    EscalationLevel `json:"fromLevel"`
+	ToLevel      EscalationLevel `json:"toLevel,omitempty"` // Empty when already at the highest level
+	NewDueDate   *time.Time      `json:"newDueDate,omitempty"`
+}
+
+// EscalateOverdueResult summarises an overdue escalation sweep
+type EscalateOverdueResult struct {
+	Outcomes  []OverdueEscalationOutcome `json:"outcomes"`
+	Escalated int                        `json:"escalated"`
+	AtHighest int                        `json:"atHighest"`
+	Remaining bool                       `json:"remaining"` // More breached escalations wait for the next sweep
+	SweptBy   string                     `json:"sweptBy"`
+	SweepDate time.Time                  `json:"sweepDate"`
+}
+
+// SetEscalationRoutingRule adds or replaces the routing rule for a violation type and severity
+func (h *ViolationEscalationHandler) SetEscalationRoutingRule(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req EscalationRoutingRuleRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse routing rule request: %v", err)
+	}
+	if err := h.requireRoutingMaintainer(stub); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.ViolationType) == "" {
+		return nil, fmt.Errorf("violationType is required, or %s for any type", EscalationRouteAny)
+	}
+	if req.Severity == "" {
+		return nil, fmt.Errorf("severity is required, or %s for any severity", EscalationRouteAny)
+	}
+	if !isEscalationLevel(req.Level) {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid level: %s", req.Level)
+	}
+	if strings.TrimSpace(req.Team) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "team", "team is required")
+	}
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	rule := &EscalationRoutingRule{
+		ViolationType: req.ViolationType,
+		Severity:      req.Severity,
+		Level:         req.Level,
+		Team:          req.Team,
+		UpdatedBy:     req.ActorID,
+		UpdatedDate:   now,
+	}
+
+	ruleKey, err := stub.CreateCompositeKey(config.EscalationRoutePrefix, []string{rule.ViolationType, string(rule.Severity)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create routing rule key: %w", err)
+	}
+	if err := h.persistenceService.Put(stub, ruleKey, rule); err != nil {
+		return nil, fmt.Errorf("failed to store routing rule: %w", err)
+	}
+
+	return json.Marshal(rule)
+}
+
+// GetEscalationRoutingRules returns every escalation routing rule
+func (h *ViolationEscalationHandler) GetEscalationRoutingRules(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 0, got %d", len(args))
+	}
+
+	iterator, err := stub.GetStateByPartialCompositeKey(config.EscalationRoutePrefix, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get routing rules: %w", err)
+	}
+	defer iterator.Close()
+
+	rules := []EscalationRoutingRule{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate routing rules: %w", err)
+		}
+		var rule EscalationRoutingRule
+		if err := json.Unmarshal(response.Value, &rule); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal routing rule: %w", err)
+		}
+		rules = append(rules, rule)
+	}
+
+	return json.Marshal(rules)
+}
+
+// EscalateOverdue bumps escalations past their SLA due date to the next level, with a new due date
+// for that level, and raises an alert for each. Escalations already at the highest level are
+// flagged as breached and alerted once. Each call handles up to config.MaxPageSize escalations;
+// call again while the result reports more remaining.
+func (h *ViolationEscalationHandler) EscalateOverdue(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req EscalateOverdueRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse overdue escalation request: %v", err)
+	}
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	result := &EscalateOverdueResult{
+		Outcomes:  []OverdueEscalationOutcome{},
+		SweptBy:   req.ActorID,
+		SweepDate: now,
+	}
+
+	// Due index keys before the current time are the breached escalations, oldest first
+	iterator, err := stub.GetStateByRange("ESCALATION_DUE_", "ESCALATION_DUE_"+now.UTC().Format(escalationDueDateFormat))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get overdue escalations: %w", err)
+	}
+	defer iterator.Close()
+
+	for iterator.HasNext() {
+		if len(result.Outcomes) == config.MaxPageSize {
+			result.Remaining = true
+			break
+		}
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate overdue escalations: %w", err)
+		}
+
+		escalationKey := fmt.Sprintf("ESCALATION_%s", string(response.Value))
+		var escalation ComplianceViolationEscalation
+		if err := h.persistenceService.Get(stub, escalationKey, &escalation); err != nil {
+			return nil, fmt.Errorf("escalation not found: %w", err)
+		}
+
+		outcome, err := h.escalateOverdue(stub, &escalation, req.ActorID, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to escalate %s: %w", escalation.EscalationID, err)
+		}
+		if outcome.ToLevel == "" {
+			result.AtHighest++
+		} else {
+			result.Escalated++
+		}
+		result.Outcomes = append(result.Outcomes, *outcome)
+	}
+
+	return json.Marshal(result)
+}
+
+// escalateOverdue moves one breached escalation to the next level, or flags it as breached at the
+// highest level, and records the alert
+func (h *ViolationEscalationHandler) escalateOverdue(stub shim.ChaincodeStubInterface, escalation *ComplianceViolationEscalation, actorID string, now time.Time) (*OverdueEscalationOutcome, error) {
+	previous := *escalation
+	previousDue := escalation.DueDate
+	outcome := &OverdueEscalationOutcome{
+		EscalationID: escalation.EscalationID,
+		DueDate:      previousDue,
+		FromLevel:    escalation.CurrentLevel,
+	}
+	escalation.SLABreached = true
+
+	historyEntry := EscalationHistoryEntry{
+		HistoryID:  services.GenerateDeterministicID(stub, "HIST"),
+		Timestamp:  now,
+		Action:     "ESCALATION_SLA_BREACHED",
+		FromLevel:  escalation.CurrentLevel,
+		FromStatus: escalation.Status,
+		ActorID:    actorID,
+		Reason:     fmt.Sprintf("SLA due %s breached", previousDue.UTC().Format(time.RFC3339)),
+	}
+
+	nextLevel, err := h.getNextEscalationLevel(escalation.CurrentLevel)
+	if err == nil {
+		escalation.CurrentLevel = nextLevel
+		escalation.Status = EscalationStatusEscalated
+		escalation.AssignedTo = "" // Clear assignment for reassignment at new level
+		escalation.AssignmentDate = nil
+		escalation.DueDate = h.calculateSLADueDate(nextLevel, escalation.Priority, now)
+
+		historyEntry.ToLevel = nextLevel
+		historyEntry.ToStatus = EscalationStatusEscalated
+		outcome.ToLevel = nextLevel
+		outcome.NewDueDate = &escalation.DueDate
+	}
+	escalation.EscalationHistory = append(escalation.EscalationHistory, historyEntry)
+
+	// Escalations at the highest level have no further timer once breached
+	if err := stub.DelState(escalationDueKey(escalation.EscalationID, previousDue)); err != nil {
+		return nil, fmt.Errorf("failed to clear due index: %w", err)
+	}
+	if outcome.ToLevel != "" {
+		if err := stub.PutState(escalationDueKey(escalation.EscalationID, escalation.DueDate), []byte(escalation.EscalationID)); err != nil {
+			return nil, fmt.Errorf("failed to create due index: %w", err)
+		}
+	}
+
+	if err := h.sendEscalationNotifications(stub, escalation, "ESCALATION_SLA_BREACHED"); err != nil {
+		return nil, fmt.Errorf("failed to send notifications: %w", err)
+	}
+	escalationKey := fmt.Sprintf("ESCALATION_%s", escalation.EscalationID)
+	if err := h.persistenceService.Put(stub, escalationKey, escalation); err != nil {
+		return nil, fmt.Errorf("failed to update escalation: %w", err)
+	}
+	if err := h.moveEscalationIndexes(stub, &previous, escalation); err != nil {
+		return nil, err
+	}
+
+	if err := h.recordBreachAlert(stub, escalation, outcome, actorID, now); err != nil {
+		return nil, fmt.Errorf("failed to record alert: %w", err)
+	}
+	return outcome, nil
+}
+
+// routeEscalation returns the level and team of the most specific routing rule matching a new
+// escalation, falling back to the severity and priority defaults when no rule matches
+func (h *ViolationEscalationHandler) routeEscalation(stub shim.ChaincodeStubInterface, violationType string, severity domain.ComplianceRulePriority, priority EscalationPriority) (EscalationLevel, string, string, error) {
+	candidates := [][]string{
+		{violationType, string(severity)},
+		{violationType, EscalationRouteAny},
+		{EscalationRouteAny, string(severity)},
+		{EscalationRouteAny, EscalationRouteAny},
+	}
+	for _, attributes := range candidates {
+		ruleKey, err := stub.CreateCompositeKey(config.EscalationRoutePrefix, attributes)
+		if err != nil {
+			return "", "", "", fmt.Errorf("failed to create routing rule key: %w", err)
+		}
+		ruleBytes, err := stub.GetState(ruleKey)
+		if err != nil {
+			return "", "", "", fmt.Errorf("failed to read routing rule: %w", err)
+		}
+		if ruleBytes == nil {
+			continue
+		}
+		var rule EscalationRoutingRule
+		if err := json.Unmarshal(ruleBytes, &rule); err != nil {
+			return "", "", "", fmt.Errorf("failed to unmarshal routing rule: %w", err)
+		}
+		return rule.Level, rule.Team, strings.Join(attributes, "/"), nil
+	}
+	return h.determineInitialEscalationLevel(severity, priority), "", "", nil
+}
+
+// recordBreachAlert raises an alerted compliance event for an escalation that breached its SLA
+func (h *ViolationEscalationHandler) recordBreachAlert(stub shim.ChaincodeStubInterface, escalation *ComplianceViolationEscalation, outcome *OverdueEscalationOutcome, actorID string, now time.Time) error {
+	if h.eventEmitter == nil {
+		return nil // No event emitter configured
+	}
+
+	event := &domain.ComplianceEvent{
+		EventID:            services.GenerateDeterministicID(stub, config.ComplianceEventPrefix),
+		Timestamp:          now,
+		RuleID:             "ESCALATION_SLA_RULE",
+		RuleVersion:        "1.0",
+		AffectedEntityID:   escalation.AffectedEntityID,
+		AffectedEntityType: escalation.AffectedEntityType,
+		EventType:          "ESCALATION_SLA_BREACHED",
+		Severity:           h.mapPriorityToSeverity(escalation.Priority),
+		Details: map[string]interface{}{
+			"escalationID": escalation.EscalationID,
+			"violationID":  escalation.ViolationID,
+			"team":         escalation.Team,
+			"dueDate":      outcome.DueDate,
+			"fromLevel":    outcome.FromLevel,
+			"toLevel":      outcome.ToLevel,
+		},
+		ActorID:          actorID,
+		IsAlerted:        true,
+		ResolutionStatus: string(escalation.Status),
+	}
+	return h.eventEmitter.EmitComplianceEvent(stub, event)
+}
+
+// requireRoutingMaintainer restricts routing rule changes to compliance officers
+func (h *ViolationEscalationHandler) requireRoutingMaintainer(stub shim.ChaincodeStubInterface) error {
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return err
+	}
+	if role != string(validation.ActorRoleComplianceOfficer) && role != string(validation.ActorRoleChiefComplianceOfficer) {
+		return services.NewChaincodeError(services.ErrCodeAccessDenied, "", "escalation routing may only be maintained by a %s or %s", validation.ActorRoleComplianceOfficer, validation.ActorRoleChiefComplianceOfficer)
+	}
+	return nil
+}
+
+func isEscalationLevel(level EscalationLevel) bool {
+	switch level {
+	case EscalationLevelL1, EscalationLevelL2, EscalationLevelL3, EscalationLevelL4, EscalationLevelL5:
+		return true
+	}
+	return false
+}
+
+// escalationDueKey is the ESCALATION_DUE_ index entry of an open escalation's SLA due date
+func escalationDueKey(escalationID string, dueDate time.Time) string {
+	return fmt.Sprintf("ESCALATION_DUE_%s_%s", dueDate.UTC().Format(escalationDueDateFormat), escalationID)
+}
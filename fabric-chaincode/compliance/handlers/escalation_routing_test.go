@@ -0,0 +1,178 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/compliance/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+func TestViolationEscalationHandler_RoutingRules(t *testing.T) {
+	stub := shimtest.NewMockStub("escalation_routing_test", nil)
+	handler := NewViolationEscalationHandler(&MockEventEmitter{})
+
+	invoke := func(txID string, fn func([]string) ([]byte, error), request interface{}) ([]byte, error) {
+		requestBytes, err := json.Marshal(request)
+		require.NoError(t, err)
+		stub.MockTransactionStart(txID)
+		defer stub.MockTransactionEnd(txID)
+		return fn([]string{string(requestBytes)})
+	}
+	setRule := func(txID string, req EscalationRoutingRuleRequest) error {
+		_, err := invoke(txID, func(args []string) ([]byte, error) { return handler.SetEscalationRoutingRule(stub, args) }, req)
+		return err
+	}
+	create := func(txID, violationType string, severity domain.ComplianceRulePriority) ComplianceViolationEscalation {
+		resultBytes, err := invoke(txID, func(args []string) ([]byte, error) { return handler.CreateEscalation(stub, args) }, EscalationRequest{
+			ViolationID:        "VIOL_" + txID,
+			ComplianceEventID:  "EVENT_" + txID,
+			ViolationType:      violationType,
+			ViolationSeverity:  severity,
+			AffectedEntityID:   "CUST_001",
+			AffectedEntityType: "Customer",
+			Priority:           EscalationPriorityMedium,
+			CreatedBy:          "COMPLIANCE_001",
+		})
+		require.NoError(t, err)
+		var escalation ComplianceViolationEscalation
+		require.NoError(t, json.Unmarshal(resultBytes, &escalation))
+		return escalation
+	}
+
+	// Only compliance officers maintain routing
+	stub.Creator = newRoleIdentity(t, "Underwriter")
+	err := setRule("route_1", EscalationRoutingRuleRequest{ViolationType: "SANCTION_MATCH", Severity: domain.PriorityHigh, Level: EscalationLevelL4, Team: "sanctions", ActorID: "ACTOR_001"})
+	assert.Contains(t, err.Error(), "may only be maintained by")
+
+	stub.Creator = newRoleIdentity(t, "Compliance_Officer")
+	err = setRule("route_2", EscalationRoutingRuleRequest{ViolationType: "SANCTION_MATCH", Severity: domain.PriorityHigh, Level: "L9_NOBODY", Team: "sanctions", ActorID: "ACTOR_001"})
+	assert.Contains(t, err.Error(), "invalid level")
+
+	require.NoError(t, setRule("route_3", EscalationRoutingRuleRequest{ViolationType: "SANCTION_MATCH", Severity: domain.PriorityHigh, Level: EscalationLevelL4, Team: "sanctions", ActorID: "ACTOR_001"}))
+	require.NoError(t, setRule("route_4", EscalationRoutingRuleRequest{ViolationType: "SANCTION_MATCH", Severity: EscalationRouteAny, Level: EscalationLevelL2, Team: "sanctions", ActorID: "ACTOR_001"}))
+	require.NoError(t, setRule("route_5", EscalationRoutingRuleRequest{ViolationType: EscalationRouteAny, Severity: EscalationRouteAny, Level: EscalationLevelL1, Team: "triage", ActorID: "ACTOR_001"}))
+
+	// The most specific rule wins
+	escalation := create("esc_1", "SANCTION_MATCH", domain.PriorityHigh)
+	assert.Equal(t, EscalationLevelL4, escalation.CurrentLevel)
+	assert.Equal(t, "sanctions", escalation.Team)
+	assert.Equal(t, "SANCTION_MATCH/HIGH", escalation.RoutingRule)
+
+	escalation = create("esc_2", "SANCTION_MATCH", domain.PriorityLow)
+	assert.Equal(t, EscalationLevelL2, escalation.CurrentLevel)
+	assert.Equal(t, "SANCTION_MATCH/*", escalation.RoutingRule)
+
+	// The catch-all rule overrides the severity defaults
+	escalation = create("esc_3", "AML_VIOLATION", domain.PriorityCritical)
+	assert.Equal(t, EscalationLevelL1, escalation.CurrentLevel)
+	assert.Equal(t, "triage", escalation.Team)
+
+	stub.MockTransactionStart("list")
+	rulesBytes, err := handler.GetEscalationRoutingRules(stub, []string{})
+	stub.MockTransactionEnd("list")
+	require.NoError(t, err)
+	var rules []EscalationRoutingRule
+	require.NoError(t, json.Unmarshal(rulesBytes, &rules))
+	assert.Len(t, rules, 3)
+}
+
+func TestViolationEscalationHandler_EscalateOverdue(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	clock := services.NewFixedClock(start)
+	defer services.SetClock(clock)()
+	stub := shimtest.NewMockStub("escalation_overdue_test", nil)
+	mockEmitter := &MockEventEmitter{}
+	handler := NewViolationEscalationHandler(mockEmitter)
+
+	invoke := func(txID string, fn func([]string) ([]byte, error), request interface{}) []byte {
+		requestBytes, err := json.Marshal(request)
+		require.NoError(t, err)
+		stub.MockTransactionStart(txID)
+		defer stub.MockTransactionEnd(txID)
+		resultBytes, err := fn([]string{string(requestBytes)})
+		require.NoError(t, err)
+		return resultBytes
+	}
+	create := func(txID string, severity domain.ComplianceRulePriority, priority EscalationPriority) ComplianceViolationEscalation {
+		resultBytes := invoke(txID, func(args []string) ([]byte, error) { return handler.CreateEscalation(stub, args) }, EscalationRequest{
+			ViolationID:        "VIOL_" + txID,
+			ComplianceEventID:  "EVENT_" + txID,
+			ViolationType:      "AML_VIOLATION",
+			ViolationSeverity:  severity,
+			AffectedEntityID:   "CUST_001",
+			AffectedEntityType: "Customer",
+			Priority:           priority,
+			CreatedBy:          "COMPLIANCE_001",
+		})
+		var escalation ComplianceViolationEscalation
+		require.NoError(t, json.Unmarshal(resultBytes, &escalation))
+		return escalation
+	}
+	sweep := func(txID string) EscalateOverdueResult {
+		resultBytes := invoke(txID, func(args []string) ([]byte, error) { return handler.EscalateOverdue(stub, args) }, EscalateOverdueRequest{ActorID: "SCHEDULER"})
+		var result EscalateOverdueResult
+		require.NoError(t, json.Unmarshal(resultBytes, &result))
+		return result
+	}
+	get := func(escalationID string) ComplianceViolationEscalation {
+		stub.MockTransactionStart("get_" + escalationID)
+		defer stub.MockTransactionEnd("get_" + escalationID)
+		resultBytes, err := handler.GetEscalation(stub, []string{escalationID})
+		require.NoError(t, err)
+		var escalation ComplianceViolationEscalation
+		require.NoError(t, json.Unmarshal(resultBytes, &escalation))
+		return escalation
+	}
+
+	analyst := create("esc_1", domain.PriorityLow, EscalationPriorityMedium) // L1, due in 24 hours
+	resolved := create("esc_2", domain.PriorityLow, EscalationPriorityMedium)
+	lowPriority := create("esc_3", domain.PriorityMedium, EscalationPriorityLow) // L1, due in 36 hours
+	assert.Equal(t, start.Add(24*time.Hour), analyst.DueDate)
+
+	invoke("resolve_2", func(args []string) ([]byte, error) { return handler.ResolveEscalation(stub, args) }, map[string]string{
+		"escalationID":      resolved.EscalationID,
+		"resolutionSummary": "False positive",
+		"resolvedBy":        "COMPLIANCE_001",
+	})
+
+	// Nothing is due yet
+	result := sweep("sweep_1")
+	assert.Empty(t, result.Outcomes)
+
+	// Past the first escalation's SLA only it moves up, with a fresh SLA for the new level
+	clock.Advance(25 * time.Hour)
+	emitted := len(mockEmitter.EmittedEvents)
+	result = sweep("sweep_2")
+	require.Len(t, result.Outcomes, 1)
+	assert.Equal(t, 1, result.Escalated)
+	outcome := result.Outcomes[0]
+	assert.Equal(t, analyst.EscalationID, outcome.EscalationID)
+	assert.Equal(t, EscalationLevelL1, outcome.FromLevel)
+	assert.Equal(t, EscalationLevelL2, outcome.ToLevel)
+	require.NotNil(t, outcome.NewDueDate)
+	assert.Equal(t, start.Add(25*time.Hour+48*time.Hour), *outcome.NewDueDate)
+	require.Len(t, mockEmitter.EmittedEvents, emitted+1)
+
+	escalated := get(analyst.EscalationID)
+	assert.Equal(t, EscalationLevelL2, escalated.CurrentLevel)
+	assert.Equal(t, EscalationStatusEscalated, escalated.Status)
+	assert.True(t, escalated.SLABreached)
+	assert.Equal(t, "ESCALATION_SLA_BREACHED", escalated.EscalationHistory[len(escalated.EscalationHistory)-1].Action)
+
+	// The same breach is not escalated twice
+	result = sweep("sweep_3")
+	assert.Empty(t, result.Outcomes)
+
+	// Later both remaining open escalations are overdue; the resolved one never is
+	clock.Advance(72 * time.Hour)
+	result = sweep("sweep_4")
+	require.Len(t, result.Outcomes, 2)
+	ids := []string{result.Outcomes[0].EscalationID, result.Outcomes[1].EscalationID}
+	assert.ElementsMatch(t, []string{analyst.EscalationID, lowPriority.EscalationID}, ids)
+	assert.Equal(t, EscalationStatusResolved, get(resolved.EscalationID).Status)
+}
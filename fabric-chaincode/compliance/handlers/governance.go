@@ -0,0 +1,753 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// How member organizations' votes are counted
+const (
+	GovernanceVotingOneOrgOneVote = "ONE_ORG_ONE_VOTE"
+	GovernanceVotingWeighted      = "WEIGHTED" // Each organization casts its member weight
+)
+
+// Governance proposal statuses
+const (
+	GovernanceProposalOpen       = "OPEN"
+	GovernanceProposalEnacted    = "ENACTED"
+	GovernanceProposalRejected   = "REJECTED"
+	GovernanceProposalSuperseded = "SUPERSEDED" // Another change to the parameter was enacted first
+)
+
+// Governance votes. Abstentions count towards the quorum but not the approval.
+const (
+	GovernanceVoteFor     = "FOR"
+	GovernanceVoteAgainst = "AGAINST"
+	GovernanceVoteAbstain = "ABSTAIN"
+)
+
+// Platform-wide parameters changed by consortium vote
+const (
+	PlatformParameterGlobalMaxLoanAmount = "GLOBAL_MAX_LOAN_AMOUNT"
+	PlatformParameterMandatoryRuleSets   = "MANDATORY_RULE_SETS"
+)
+
+const (
+	governanceVersionFormat     = "%06d"
+	defaultGovernanceVotingDays = 7
+	maxGovernanceVotingDays     = 30
+	maxGovernanceMemberWeight   = 100
+)
+
+// GovernedParameter defines a platform parameter and the vote needed to change it. The voting rules
+// belong to the parameter so a proposer cannot pick the rules their proposal is decided by.
+type GovernedParameter struct {
+	Name            string
+	Description     string
+	DefaultValue    string
+	VotingMode      string
+	QuorumPercent   float64 // Share of the electorate's votes that must be cast
+	ApprovalPercent float64 // Share of FOR among FOR and AGAINST votes needed to enact
+	normalize       func(value string) (string, error)
+}
+
+// governedParameters are the parameters affecting every member, which no single member may change
+var governedParameters = map[string]GovernedParameter{
+	PlatformParameterGlobalMaxLoanAmount: {
+		Name:            PlatformParameterGlobalMaxLoanAmount,
+		Description:     "Largest loan any member may originate",
+		DefaultValue:    formatGovernanceAmount(config.MaxLoanAmount),
+		VotingMode:      GovernanceVotingWeighted,
+		QuorumPercent:   50,
+		ApprovalPercent: 60,
+		normalize:       normalizeGlobalMaxLoanAmount,
+	},
+	PlatformParameterMandatoryRuleSets: {
+		Name:            PlatformParameterMandatoryRuleSets,
+		Description:     "Compliance rule sets every member must run, as a JSON array of rule set IDs",
+		DefaultValue:    "[]",
+		VotingMode:      GovernanceVotingOneOrgOneVote,
+		QuorumPercent:   60,
+		ApprovalPercent: 75,
+		normalize:       normalizeMandatoryRuleSets,
+	},
+}
+
+// GovernanceHandler runs consortium votes on platform-wide parameters and enacts approved changes
+// into the ledger config store. Proposals and their votes are readable by every member, so the
+// record of how each organization voted is public.
+type GovernanceHandler struct {
+	persistenceService *services.PersistenceService
+}
+
+// NewGovernanceHandler creates a new governance handler
+func NewGovernanceHandler() *GovernanceHandler {
+	return &GovernanceHandler{
+		persistenceService: services.NewPersistenceService(),
+	}
+}
+
+// GovernanceMember is a consortium organization entitled to vote. Weight is its vote under
+// weighted voting.
+type GovernanceMember struct {
+	MSPID       string    `json:"mspID"`
+	Name        string    `json:"name"`
+	Weight      int       `json:"weight"`
+	Active      bool      `json:"active"`
+	UpdatedBy   string    `json:"updatedBy"`
+	UpdatedDate time.Time `json:"updatedDate"`
+}
+
+// GovernanceMemberRequest represents a request to admit, reweight or suspend a member organization
+type GovernanceMemberRequest struct {
+	MSPID   string `json:"mspID"`
+	Name    string `json:"name"`
+	Weight  int    `json:"weight"`
+	Active  bool   `json:"active"`
+	ActorID string `json:"actorID"`
+}
+
+// PlatformParameter is the value of a governed parameter in force. Version counts the enacted
+// changes and is 0 for the built-in default.
+type PlatformParameter struct {
+	Name        string     `json:"name"`
+	Value       string     `json:"value"`
+	Version     int        `json:"version"`
+	ProposalID  string     `json:"proposalID,omitempty"`
+	EnactedDate *time.Time `json:"enactedDate,omitempty"`
+}
+
+// GovernanceVote is one organization's vote on a proposal
+type GovernanceVote struct {
+	MSPID     string    `json:"mspID"`
+	Vote      string    `json:"vote"`
+	Weight    int       `json:"weight"`
+	Comment   string    `json:"comment,omitempty"`
+	ActorID   string    `json:"actorID"`
+	VotedDate time.Time `json:"votedDate"`
+}
+
+// GovernanceTally counts a proposal's votes
+type GovernanceTally struct {
+	EligibleVotes int  `json:"eligibleVotes"`
+	VotesFor      int  `json:"votesFor"`
+	VotesAgainst  int  `json:"votesAgainst"`
+	VotesAbstain  int  `json:"votesAbstain"`
+	QuorumReached bool `json:"quorumReached"`
+	Approved      bool `json:"approved"`
+}
+
+// GovernanceProposal is a proposed change to a platform parameter. The electorate and voting rules
+// are fixed when it is proposed, so later membership changes do not alter an open vote.
+type GovernanceProposal struct {
+	ProposalID      string           `json:"proposalID"`
+	ParameterName   string           `json:"parameterName"`
+	CurrentValue    string           `json:"currentValue"`
+	ProposedValue   string           `json:"proposedValue"`
+	BaseVersion     int              `json:"baseVersion"`
+	Rationale       string           `json:"rationale"`
+	ProposedBy      string           `json:"proposedBy"`
+	ProposerMSPID   string           `json:"proposerMSPID"`
+	VotingMode      string           `json:"votingMode"`
+	QuorumPercent   float64          `json:"quorumPercent"`
+	ApprovalPercent float64          `json:"approvalPercent"`
+	Electorate      map[string]int   `json:"electorate"` // Member MSP ID to votes
+	Votes           []GovernanceVote `json:"votes"`
+	Tally           GovernanceTally  `json:"tally"`
+	Status          string           `json:"status"`
+	CreatedDate     time.Time        `json:"createdDate"`
+	VotingDeadline  time.Time        `json:"votingDeadline"`
+	ClosedDate      *time.Time       `json:"closedDate,omitempty"`
+	Outcome         string           `json:"outcome,omitempty"`
+}
+
+// GovernanceProposalRequest represents a request to propose a parameter change
+type GovernanceProposalRequest struct {
+	ParameterName string `json:"parameterName"`
+	ProposedValue string `json:"proposedValue"`
+	Rationale     string `json:"rationale"`
+	VotingDays    int    `json:"votingDays,omitempty"` // Defaults to 7
+	ActorID       string `json:"actorID"`
+}
+
+// GovernanceVoteRequest represents an organization's vote on a proposal
+type GovernanceVoteRequest struct {
+	ProposalID string `json:"proposalID"`
+	Vote       string `json:"vote"`
+	Comment    string `json:"comment,omitempty"`
+	ActorID    string `json:"actorID"`
+}
+
+// SetGovernanceMember admits a member organization or changes its weight or standing. Only system
+// administrators manage membership.
+func (h *GovernanceHandler) SetGovernanceMember(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req GovernanceMemberRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse governance member request: %v", err)
+	}
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return nil, err
+	}
+	if role != string(validation.ActorRoleSystemAdministrator) {
+		return nil, services.NewChaincodeError(services.ErrCodeAccessDenied, "", "governance members may only be managed by a %s", validation.ActorRoleSystemAdministrator)
+	}
+	req.MSPID = strings.TrimSpace(req.MSPID)
+	if req.MSPID == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "mspID", "mspID is required")
+	}
+	if strings.TrimSpace(req.Name) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "name", "name is required")
+	}
+	if req.Weight < 1 || req.Weight > maxGovernanceMemberWeight {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "weight must be between 1 and %d, got %d", maxGovernanceMemberWeight, req.Weight)
+	}
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	member := &GovernanceMember{
+		MSPID:       req.MSPID,
+		Name:        req.Name,
+		Weight:      req.Weight,
+		Active:      req.Active,
+		UpdatedBy:   req.ActorID,
+		UpdatedDate: now,
+	}
+	memberKey, err := stub.CreateCompositeKey(config.GovernanceMemberPrefix, []string{member.MSPID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create governance member key: %w", err)
+	}
+	if err := h.persistenceService.Put(stub, memberKey, member); err != nil {
+		return nil, fmt.Errorf("failed to store governance member: %w", err)
+	}
+
+	return json.Marshal(member)
+}
+
+// GetGovernanceMembers returns the consortium's member organizations by MSP ID
+func (h *GovernanceHandler) GetGovernanceMembers(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 0, got %d", len(args))
+	}
+
+	members, err := h.members(stub)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(members)
+}
+
+// ProposeParameterChange opens a vote on a change to a platform parameter. The proposer's
+// organization must be an active member.
+func (h *GovernanceHandler) ProposeParameterChange(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req GovernanceProposalRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse governance proposal request: %v", err)
+	}
+	parameter, ok := governedParameters[strings.ToUpper(strings.TrimSpace(req.ParameterName))]
+	if !ok {
+		return nil, fmt.Errorf("%s is not a governed parameter", req.ParameterName)
+	}
+	proposedValue, err := parameter.normalize(req.ProposedValue)
+	if err != nil {
+		return nil, fmt.Errorf("invalid value for %s: %w", parameter.Name, err)
+	}
+	if strings.TrimSpace(req.Rationale) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "rationale", "rationale is required")
+	}
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+	votingDays := req.VotingDays
+	if votingDays == 0 {
+		votingDays = defaultGovernanceVotingDays
+	}
+	if votingDays < 1 || votingDays > maxGovernanceVotingDays {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "votingDays must be between 1 and %d, got %d", maxGovernanceVotingDays, req.VotingDays)
+	}
+
+	mspID, err := h.requireDelegate(stub)
+	if err != nil {
+		return nil, err
+	}
+	members, err := h.members(stub)
+	if err != nil {
+		return nil, err
+	}
+	electorate := map[string]int{}
+	for _, member := range members {
+		if !member.Active {
+			continue
+		}
+		electorate[member.MSPID] = 1
+		if parameter.VotingMode == GovernanceVotingWeighted {
+			electorate[member.MSPID] = member.Weight
+		}
+	}
+	if _, ok := electorate[mspID]; !ok {
+		return nil, fmt.Errorf("organization %s is not an active governance member", mspID)
+	}
+
+	current, err := platformParameter(stub, parameter.Name)
+	if err != nil {
+		return nil, err
+	}
+	if proposedValue == current.Value {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "%s is already %s", parameter.Name, proposedValue)
+	}
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	proposal := &GovernanceProposal{
+		ProposalID:      services.GenerateDeterministicID(stub, config.GovernanceProposalPrefix),
+		ParameterName:   parameter.Name,
+		CurrentValue:    current.Value,
+		ProposedValue:   proposedValue,
+		BaseVersion:     current.Version,
+		Rationale:       req.Rationale,
+		ProposedBy:      req.ActorID,
+		ProposerMSPID:   mspID,
+		VotingMode:      parameter.VotingMode,
+		QuorumPercent:   parameter.QuorumPercent,
+		ApprovalPercent: parameter.ApprovalPercent,
+		Electorate:      electorate,
+		Votes:           []GovernanceVote{},
+		Status:          GovernanceProposalOpen,
+		CreatedDate:     now,
+		VotingDeadline:  now.AddDate(0, 0, votingDays),
+	}
+	proposal.Tally = proposal.tally()
+	if err := h.putProposal(stub, proposal); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(proposal)
+}
+
+// CastGovernanceVote records the invoking organization's vote. A proposal is decided as soon as the
+// remaining votes can no longer change the outcome, and enacted at once if approved.
+func (h *GovernanceHandler) CastGovernanceVote(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req GovernanceVoteRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse governance vote request: %v", err)
+	}
+	vote := strings.ToUpper(strings.TrimSpace(req.Vote))
+	if vote != GovernanceVoteFor && vote != GovernanceVoteAgainst && vote != GovernanceVoteAbstain {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid vote %q: expected %s, %s or %s", req.Vote, GovernanceVoteFor, GovernanceVoteAgainst, GovernanceVoteAbstain)
+	}
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	mspID, err := h.requireDelegate(stub)
+	if err != nil {
+		return nil, err
+	}
+	proposal, err := h.getProposal(stub, req.ProposalID)
+	if err != nil {
+		return nil, err
+	}
+	if proposal.Status != GovernanceProposalOpen {
+		return nil, fmt.Errorf("proposal %s is %s", proposal.ProposalID, proposal.Status)
+	}
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	if !now.Before(proposal.VotingDeadline) {
+		return nil, fmt.Errorf("voting on proposal %s closed at %s", proposal.ProposalID, proposal.VotingDeadline.Format(time.RFC3339))
+	}
+	weight, eligible := proposal.Electorate[mspID]
+	if !eligible {
+		return nil, fmt.Errorf("organization %s is not in the electorate of proposal %s", mspID, proposal.ProposalID)
+	}
+	for _, cast := range proposal.Votes {
+		if cast.MSPID == mspID {
+			return nil, fmt.Errorf("organization %s has already voted on proposal %s", mspID, proposal.ProposalID)
+		}
+	}
+
+	proposal.Votes = append(proposal.Votes, GovernanceVote{
+		MSPID:     mspID,
+		Vote:      vote,
+		Weight:    weight,
+		Comment:   req.Comment,
+		ActorID:   req.ActorID,
+		VotedDate: now,
+	})
+	proposal.Tally = proposal.tally()
+	if decided, approved := proposal.decided(); decided {
+		if err := h.close(stub, proposal, approved, now); err != nil {
+			return nil, err
+		}
+	}
+	if err := h.putProposal(stub, proposal); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(proposal)
+}
+
+// CloseGovernanceProposal decides a proposal whose voting period has ended, enacting it if the
+// quorum was reached and it was approved. Any member may close an expired proposal.
+func (h *GovernanceHandler) CloseGovernanceProposal(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	proposal, err := h.getProposal(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	if proposal.Status != GovernanceProposalOpen {
+		return nil, fmt.Errorf("proposal %s is %s", proposal.ProposalID, proposal.Status)
+	}
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	if now.Before(proposal.VotingDeadline) {
+		return nil, fmt.Errorf("voting on proposal %s is open until %s", proposal.ProposalID, proposal.VotingDeadline.Format(time.RFC3339))
+	}
+
+	if err := h.close(stub, proposal, proposal.Tally.Approved, now); err != nil {
+		return nil, err
+	}
+	if err := h.putProposal(stub, proposal); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(proposal)
+}
+
+// GetGovernanceProposal returns a proposal with every organization's vote
+func (h *GovernanceHandler) GetGovernanceProposal(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	proposal, err := h.getProposal(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(proposal)
+}
+
+// GetGovernanceProposals returns the proposals, optionally only those in a status, oldest first.
+// Args: [status]
+func (h *GovernanceHandler) GetGovernanceProposals(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) > 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 0 or 1, got %d", len(args))
+	}
+	status := ""
+	if len(args) == 1 {
+		status = strings.ToUpper(strings.TrimSpace(args[0]))
+	}
+
+	iterator, err := stub.GetStateByPartialCompositeKey(config.GovernanceProposalPrefix, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get governance proposals: %w", err)
+	}
+	defer iterator.Close()
+
+	proposals := []GovernanceProposal{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate governance proposals: %w", err)
+		}
+		var proposal GovernanceProposal
+		if err := json.Unmarshal(response.Value, &proposal); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal governance proposal: %w", err)
+		}
+		if status == "" || proposal.Status == status {
+			proposals = append(proposals, proposal)
+		}
+	}
+	sort.SliceStable(proposals, func(i, j int) bool { return proposals[i].CreatedDate.Before(proposals[j].CreatedDate) })
+
+	return json.Marshal(proposals)
+}
+
+// GetPlatformParameter returns the value of a governed parameter in force
+func (h *GovernanceHandler) GetPlatformParameter(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	name := strings.ToUpper(strings.TrimSpace(args[0]))
+	if _, ok := governedParameters[name]; !ok {
+		return nil, fmt.Errorf("%s is not a governed parameter", args[0])
+	}
+	parameter, err := platformParameter(stub, name)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(parameter)
+}
+
+// GetPlatformParameters returns every governed parameter in force, by name
+func (h *GovernanceHandler) GetPlatformParameters(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 0, got %d", len(args))
+	}
+
+	names := make([]string, 0, len(governedParameters))
+	for name := range governedParameters {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	parameters := []PlatformParameter{}
+	for _, name := range names {
+		parameter, err := platformParameter(stub, name)
+		if err != nil {
+			return nil, err
+		}
+		parameters = append(parameters, *parameter)
+	}
+	return json.Marshal(parameters)
+}
+
+// Helper methods
+
+// requireDelegate returns the MSP ID of an invoker entitled to act for their organization in
+// governance: a system administrator or chief compliance officer
+func (h *GovernanceHandler) requireDelegate(stub shim.ChaincodeStubInterface) (string, error) {
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return "", err
+	}
+	if role != string(validation.ActorRoleSystemAdministrator) && role != string(validation.ActorRoleChiefComplianceOfficer) {
+		return "", services.NewChaincodeError(services.ErrCodeAccessDenied, "", "organizations may only be represented in governance by a %s or %s", validation.ActorRoleSystemAdministrator, validation.ActorRoleChiefComplianceOfficer)
+	}
+	return services.InvokerMSPID(stub)
+}
+
+func (h *GovernanceHandler) members(stub shim.ChaincodeStubInterface) ([]GovernanceMember, error) {
+	iterator, err := stub.GetStateByPartialCompositeKey(config.GovernanceMemberPrefix, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get governance members: %w", err)
+	}
+	defer iterator.Close()
+
+	members := []GovernanceMember{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate governance members: %w", err)
+		}
+		var member GovernanceMember
+		if err := json.Unmarshal(response.Value, &member); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal governance member: %w", err)
+		}
+		members = append(members, member)
+	}
+	return members, nil
+}
+
+func (h *GovernanceHandler) getProposal(stub shim.ChaincodeStubInterface, proposalID string) (*GovernanceProposal, error) {
+	proposalKey, err := stub.CreateCompositeKey(config.GovernanceProposalPrefix, []string{proposalID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create governance proposal key: %w", err)
+	}
+	var proposal GovernanceProposal
+	if err := h.persistenceService.Get(stub, proposalKey, &proposal); err != nil {
+		return nil, fmt.Errorf("governance proposal %s not found: %w", proposalID, err)
+	}
+	return &proposal, nil
+}
+
+func (h *GovernanceHandler) putProposal(stub shim.ChaincodeStubInterface, proposal *GovernanceProposal) error {
+	proposalKey, err := stub.CreateCompositeKey(config.GovernanceProposalPrefix, []string{proposal.ProposalID})
+	if err != nil {
+		return fmt.Errorf("failed to create governance proposal key: %w", err)
+	}
+	if err := h.persistenceService.Put(stub, proposalKey, proposal); err != nil {
+		return fmt.Errorf("failed to store governance proposal: %w", err)
+	}
+	return nil
+}
+
+// close decides a proposal, enacting it into the config store if approved. An approved change is
+// superseded if another change to the parameter was enacted while it was open.
+func (h *GovernanceHandler) close(stub shim.ChaincodeStubInterface, proposal *GovernanceProposal, approved bool, now time.Time) error {
+	proposal.ClosedDate = &now
+	if !approved {
+		proposal.Status = GovernanceProposalRejected
+		proposal.Outcome = fmt.Sprintf("%d for, %d against, %d abstaining of %d eligible votes", proposal.Tally.VotesFor, proposal.Tally.VotesAgainst, proposal.Tally.VotesAbstain, proposal.Tally.EligibleVotes)
+		if !proposal.Tally.QuorumReached {
+			proposal.Outcome = "quorum not reached: " + proposal.Outcome
+		}
+		return nil
+	}
+
+	current, err := platformParameter(stub, proposal.ParameterName)
+	if err != nil {
+		return err
+	}
+	if current.Version != proposal.BaseVersion {
+		proposal.Status = GovernanceProposalSuperseded
+		proposal.Outcome = fmt.Sprintf("%s changed to version %d by proposal %s while voting was open", proposal.ParameterName, current.Version, current.ProposalID)
+		return nil
+	}
+
+	parameter := &PlatformParameter{
+		Name:        proposal.ParameterName,
+		Value:       proposal.ProposedValue,
+		Version:     current.Version + 1,
+		ProposalID:  proposal.ProposalID,
+		EnactedDate: &now,
+	}
+	parameterKey, err := stub.CreateCompositeKey(config.PlatformParameterPrefix, []string{parameter.Name})
+	if err != nil {
+		return fmt.Errorf("failed to create platform parameter key: %w", err)
+	}
+	if err := h.persistenceService.Put(stub, parameterKey, parameter); err != nil {
+		return fmt.Errorf("failed to store platform parameter: %w", err)
+	}
+	historyKey, err := stub.CreateCompositeKey(config.PlatformParameterHistoryPrefix, []string{parameter.Name, fmt.Sprintf(governanceVersionFormat, parameter.Version)})
+	if err != nil {
+		return fmt.Errorf("failed to create platform parameter history key: %w", err)
+	}
+	if err := h.persistenceService.Put(stub, historyKey, parameter); err != nil {
+		return fmt.Errorf("failed to store platform parameter history: %w", err)
+	}
+
+	proposal.Status = GovernanceProposalEnacted
+	proposal.Outcome = fmt.Sprintf("%s enacted as version %d", parameter.Name, parameter.Version)
+	return nil
+}
+
+// tally counts the votes cast so far
+func (p *GovernanceProposal) tally() GovernanceTally {
+	tally := GovernanceTally{}
+	for _, weight := range p.Electorate {
+		tally.EligibleVotes += weight
+	}
+	for _, vote := range p.Votes {
+		switch vote.Vote {
+		case GovernanceVoteFor:
+			tally.VotesFor += vote.Weight
+		case GovernanceVoteAgainst:
+			tally.VotesAgainst += vote.Weight
+		case GovernanceVoteAbstain:
+			tally.VotesAbstain += vote.Weight
+		}
+	}
+	cast := tally.VotesFor + tally.VotesAgainst + tally.VotesAbstain
+	tally.QuorumReached = float64(cast)*100 >= p.QuorumPercent*float64(tally.EligibleVotes)
+	tally.Approved = tally.QuorumReached && p.approves(tally.VotesFor, tally.VotesAgainst)
+	return tally
+}
+
+// decided reports whether the outcome is settled whatever the remaining votes: approved even if
+// they are all against, or rejected even if they are all for
+func (p *GovernanceProposal) decided() (bool, bool) {
+	remaining := p.Tally.EligibleVotes - p.Tally.VotesFor - p.Tally.VotesAgainst - p.Tally.VotesAbstain
+	if p.Tally.QuorumReached && p.approves(p.Tally.VotesFor, p.Tally.VotesAgainst+remaining) {
+		return true, true
+	}
+	if !p.approves(p.Tally.VotesFor+remaining, p.Tally.VotesAgainst) {
+		return true, false
+	}
+	return false, false
+}
+
+func (p *GovernanceProposal) approves(votesFor, votesAgainst int) bool {
+	return votesFor > 0 && float64(votesFor)*100 >= p.ApprovalPercent*float64(votesFor+votesAgainst)
+}
+
+// platformParameter returns the value of a governed parameter in force: the last enacted value,
+// else the built-in default
+func platformParameter(stub shim.ChaincodeStubInterface, name string) (*PlatformParameter, error) {
+	parameterKey, err := stub.CreateCompositeKey(config.PlatformParameterPrefix, []string{name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create platform parameter key: %w", err)
+	}
+	parameterBytes, err := stub.GetState(parameterKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read platform parameter: %w", err)
+	}
+	if parameterBytes != nil {
+		var parameter PlatformParameter
+		if err := json.Unmarshal(parameterBytes, &parameter); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal platform parameter: %w", err)
+		}
+		return &parameter, nil
+	}
+	return &PlatformParameter{Name: name, Value: governedParameters[name].DefaultValue}, nil
+}
+
+// normalizeGlobalMaxLoanAmount accepts a cap up to the platform's hard ceiling
+func normalizeGlobalMaxLoanAmount(value string) (string, error) {
+	amount, err := strconv.ParseFloat(strings.TrimSpace(value), 64)
+	if err != nil {
+		return "", fmt.Errorf("expected an amount, got %q", value)
+	}
+	if amount <= 0 || amount > config.MaxLoanAmount {
+		return "", services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "amount must be greater than 0 and at most %.2f, got %.2f", config.MaxLoanAmount, amount)
+	}
+	return formatGovernanceAmount(amount), nil
+}
+
+// normalizeMandatoryRuleSets accepts a JSON array of rule set IDs, returned upper-cased, sorted
+// and without duplicates
+func normalizeMandatoryRuleSets(value string) (string, error) {
+	var ruleSets []string
+	if err := json.Unmarshal([]byte(value), &ruleSets); err != nil {
+		return "", fmt.Errorf("expected a JSON array of rule set IDs: %w", err)
+	}
+
+	seen := map[string]bool{}
+	normalized := []string{}
+	for _, ruleSet := range ruleSets {
+		ruleSet = strings.ToUpper(strings.TrimSpace(ruleSet))
+		if ruleSet == "" {
+			return "", services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "rule set IDs must not be empty")
+		}
+		if !seen[ruleSet] {
+			seen[ruleSet] = true
+			normalized = append(normalized, ruleSet)
+		}
+	}
+	sort.Strings(normalized)
+
+	normalizedBytes, err := json.Marshal(normalized)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal rule sets: %w", err)
+	}
+	return string(normalizedBytes), nil
+}
+
+func formatGovernanceAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
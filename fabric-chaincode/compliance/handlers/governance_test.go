@@ -0,0 +1,164 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// newMemberIdentity returns a role identity issued by a member organization's MSP
+func newMemberIdentity(t *testing.T, mspID, role string) []byte {
+	var identity msp.SerializedIdentity
+	require.NoError(t, proto.Unmarshal(newRoleIdentity(t, role), &identity))
+	identity.Mspid = mspID
+	identityBytes, err := proto.Marshal(&identity)
+	require.NoError(t, err)
+	return identityBytes
+}
+
+func TestGovernanceHandler_Voting(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	clock := services.NewFixedClock(now)
+	defer services.SetClock(clock)()
+	stub := shimtest.NewMockStub("governance_test", nil)
+	handler := NewGovernanceHandler()
+
+	invoke := func(txID string, creator []byte, fn func(args []string) ([]byte, error), request interface{}) ([]byte, error) {
+		requestBytes, err := json.Marshal(request)
+		require.NoError(t, err)
+		stub.Creator = creator
+		stub.MockTransactionStart(txID)
+		defer stub.MockTransactionEnd(txID)
+		return fn([]string{string(requestBytes)})
+	}
+	setMember := func(args []string) ([]byte, error) { return handler.SetGovernanceMember(stub, args) }
+	propose := func(args []string) ([]byte, error) { return handler.ProposeParameterChange(stub, args) }
+	vote := func(args []string) ([]byte, error) { return handler.CastGovernanceVote(stub, args) }
+	proposalOf := func(resultBytes []byte, err error) *GovernanceProposal {
+		require.NoError(t, err)
+		var proposal GovernanceProposal
+		require.NoError(t, json.Unmarshal(resultBytes, &proposal))
+		return &proposal
+	}
+	parameter := func(txID, name string) *PlatformParameter {
+		stub.MockTransactionStart(txID)
+		defer stub.MockTransactionEnd(txID)
+		parameterBytes, err := handler.GetPlatformParameter(stub, []string{name})
+		require.NoError(t, err)
+		var parameter PlatformParameter
+		require.NoError(t, json.Unmarshal(parameterBytes, &parameter))
+		return &parameter
+	}
+
+	admin := newMemberIdentity(t, "Org1MSP", "System_Administrator")
+	org1 := newMemberIdentity(t, "Org1MSP", "Chief_Compliance_Officer")
+	org2 := newMemberIdentity(t, "Org2MSP", "Chief_Compliance_Officer")
+	org3 := newMemberIdentity(t, "Org3MSP", "System_Administrator")
+	outsider := newMemberIdentity(t, "Org4MSP", "Chief_Compliance_Officer")
+
+	// Only system administrators manage membership
+	_, err := invoke("member_0", org1, setMember, GovernanceMemberRequest{MSPID: "Org1MSP", Name: "First Bank", Weight: 50, Active: true, ActorID: "ACTOR_001"})
+	assert.Contains(t, err.Error(), "may only be managed by")
+	_, err = invoke("member_1", admin, setMember, GovernanceMemberRequest{MSPID: "Org1MSP", Name: "First Bank", Weight: 0, Active: true, ActorID: "ACTOR_001"})
+	assert.Contains(t, err.Error(), "weight must be between")
+	for i, member := range []GovernanceMemberRequest{
+		{MSPID: "Org1MSP", Name: "First Bank", Weight: 50, Active: true, ActorID: "ACTOR_001"},
+		{MSPID: "Org2MSP", Name: "Second Bank", Weight: 30, Active: true, ActorID: "ACTOR_001"},
+		{MSPID: "Org3MSP", Name: "Credit Union", Weight: 20, Active: true, ActorID: "ACTOR_001"},
+		{MSPID: "Org4MSP", Name: "Observer", Weight: 10, Active: false, ActorID: "ACTOR_001"},
+	} {
+		_, err = invoke("member_set_"+string(rune('a'+i)), admin, setMember, member)
+		require.NoError(t, err)
+	}
+
+	// Built-in defaults are in force as version 0
+	assert.Equal(t, PlatformParameter{Name: PlatformParameterGlobalMaxLoanAmount, Value: "10000000.00"}, *parameter("param_0", PlatformParameterGlobalMaxLoanAmount))
+
+	_, err = invoke("propose_0", outsider, propose, GovernanceProposalRequest{ParameterName: PlatformParameterGlobalMaxLoanAmount, ProposedValue: "5000000", Rationale: "Prudential limit", ActorID: "ACTOR_004"})
+	assert.Contains(t, err.Error(), "not an active governance member")
+	_, err = invoke("propose_1", org1, propose, GovernanceProposalRequest{ParameterName: PlatformParameterGlobalMaxLoanAmount, ProposedValue: "20000000", Rationale: "Raise the cap", ActorID: "ACTOR_002"})
+	assert.Contains(t, err.Error(), "at most")
+	_, err = invoke("propose_2", org1, propose, GovernanceProposalRequest{ParameterName: "INTEREST_RATE_FLOOR", ProposedValue: "2", Rationale: "Not governed", ActorID: "ACTOR_002"})
+	assert.Contains(t, err.Error(), "is not a governed parameter")
+
+	// Weighted vote: 60% of FOR and AGAINST weight approves once half the weight has voted
+	capProposal := proposalOf(invoke("propose_3", org1, propose, GovernanceProposalRequest{ParameterName: "global_max_loan_amount", ProposedValue: "5000000", Rationale: "Prudential limit", ActorID: "ACTOR_002"}))
+	assert.Equal(t, "5000000.00", capProposal.ProposedValue)
+	assert.Equal(t, GovernanceVotingWeighted, capProposal.VotingMode)
+	assert.Equal(t, map[string]int{"Org1MSP": 50, "Org2MSP": 30, "Org3MSP": 20}, capProposal.Electorate)
+	assert.Equal(t, now.AddDate(0, 0, 7), capProposal.VotingDeadline)
+
+	capProposal = proposalOf(invoke("vote_1", org1, vote, GovernanceVoteRequest{ProposalID: capProposal.ProposalID, Vote: "for", ActorID: "ACTOR_002"}))
+	assert.Equal(t, GovernanceProposalOpen, capProposal.Status)
+	assert.True(t, capProposal.Tally.QuorumReached)
+	_, err = invoke("vote_2", org1, vote, GovernanceVoteRequest{ProposalID: capProposal.ProposalID, Vote: "AGAINST", ActorID: "ACTOR_002"})
+	assert.Contains(t, err.Error(), "has already voted")
+	_, err = invoke("vote_3", outsider, vote, GovernanceVoteRequest{ProposalID: capProposal.ProposalID, Vote: "FOR", ActorID: "ACTOR_004"})
+	assert.Contains(t, err.Error(), "is not in the electorate")
+
+	capProposal = proposalOf(invoke("vote_4", org2, vote, GovernanceVoteRequest{ProposalID: capProposal.ProposalID, Vote: "AGAINST", Comment: "Too restrictive", ActorID: "ACTOR_003"}))
+	assert.Equal(t, GovernanceProposalOpen, capProposal.Status)
+	capProposal = proposalOf(invoke("vote_5", org3, vote, GovernanceVoteRequest{ProposalID: capProposal.ProposalID, Vote: "FOR", ActorID: "ACTOR_005"}))
+	assert.Equal(t, GovernanceProposalEnacted, capProposal.Status)
+	assert.Equal(t, GovernanceTally{EligibleVotes: 100, VotesFor: 70, VotesAgainst: 30, QuorumReached: true, Approved: true}, capProposal.Tally)
+	require.Len(t, capProposal.Votes, 3)
+	assert.Equal(t, GovernanceVote{MSPID: "Org2MSP", Vote: "AGAINST", Weight: 30, Comment: "Too restrictive", ActorID: "ACTOR_003", VotedDate: now}, capProposal.Votes[1])
+
+	enacted := parameter("param_1", PlatformParameterGlobalMaxLoanAmount)
+	assert.Equal(t, "5000000.00", enacted.Value)
+	assert.Equal(t, 1, enacted.Version)
+	assert.Equal(t, capProposal.ProposalID, enacted.ProposalID)
+
+	// One org, one vote: the proposal fails as soon as 75% approval is out of reach
+	rulesProposal := proposalOf(invoke("propose_4", org2, propose, GovernanceProposalRequest{ParameterName: PlatformParameterMandatoryRuleSets, ProposedValue: `["sanctions", "AML_CORE", "aml_core"]`, Rationale: "Common baseline", ActorID: "ACTOR_003"}))
+	assert.Equal(t, `["AML_CORE","SANCTIONS"]`, rulesProposal.ProposedValue)
+	assert.Equal(t, map[string]int{"Org1MSP": 1, "Org2MSP": 1, "Org3MSP": 1}, rulesProposal.Electorate)
+	proposalOf(invoke("vote_6", org2, vote, GovernanceVoteRequest{ProposalID: rulesProposal.ProposalID, Vote: "FOR", ActorID: "ACTOR_003"}))
+	rulesProposal = proposalOf(invoke("vote_7", org1, vote, GovernanceVoteRequest{ProposalID: rulesProposal.ProposalID, Vote: "AGAINST", ActorID: "ACTOR_002"}))
+	assert.Equal(t, GovernanceProposalRejected, rulesProposal.Status)
+	assert.Equal(t, "[]", parameter("param_2", PlatformParameterMandatoryRuleSets).Value)
+
+	// A proposal short of quorum at its deadline is rejected when closed
+	rulesProposal = proposalOf(invoke("propose_5", org2, propose, GovernanceProposalRequest{ParameterName: PlatformParameterMandatoryRuleSets, ProposedValue: `["AML_CORE"]`, Rationale: "Narrower baseline", VotingDays: 3, ActorID: "ACTOR_003"}))
+	proposalOf(invoke("vote_8", org2, vote, GovernanceVoteRequest{ProposalID: rulesProposal.ProposalID, Vote: "FOR", ActorID: "ACTOR_003"}))
+	stub.MockTransactionStart("close_1")
+	_, err = handler.CloseGovernanceProposal(stub, []string{rulesProposal.ProposalID})
+	stub.MockTransactionEnd("close_1")
+	assert.Contains(t, err.Error(), "is open until")
+
+	later := now.AddDate(0, 0, 4)
+	defer services.SetClock(services.NewFixedClock(later))()
+	_, err = invoke("vote_9", org1, vote, GovernanceVoteRequest{ProposalID: rulesProposal.ProposalID, Vote: "FOR", ActorID: "ACTOR_002"})
+	assert.Contains(t, err.Error(), "closed at")
+	stub.MockTransactionStart("close_2")
+	rulesProposal = proposalOf(handler.CloseGovernanceProposal(stub, []string{rulesProposal.ProposalID}))
+	stub.MockTransactionEnd("close_2")
+	assert.Equal(t, GovernanceProposalRejected, rulesProposal.Status)
+	assert.Contains(t, rulesProposal.Outcome, "quorum not reached")
+
+	// Of two open changes to the same parameter, the later approved one is superseded
+	first := proposalOf(invoke("propose_6", org1, propose, GovernanceProposalRequest{ParameterName: PlatformParameterGlobalMaxLoanAmount, ProposedValue: "4000000", Rationale: "Tighter", ActorID: "ACTOR_002"}))
+	second := proposalOf(invoke("propose_7", org2, propose, GovernanceProposalRequest{ParameterName: PlatformParameterGlobalMaxLoanAmount, ProposedValue: "3000000", Rationale: "Tighter still", ActorID: "ACTOR_003"}))
+	proposalOf(invoke("vote_10", org1, vote, GovernanceVoteRequest{ProposalID: first.ProposalID, Vote: "FOR", ActorID: "ACTOR_002"}))
+	first = proposalOf(invoke("vote_11", org2, vote, GovernanceVoteRequest{ProposalID: first.ProposalID, Vote: "FOR", ActorID: "ACTOR_003"}))
+	assert.Equal(t, GovernanceProposalEnacted, first.Status)
+	proposalOf(invoke("vote_12", org1, vote, GovernanceVoteRequest{ProposalID: second.ProposalID, Vote: "FOR", ActorID: "ACTOR_002"}))
+	second = proposalOf(invoke("vote_13", org2, vote, GovernanceVoteRequest{ProposalID: second.ProposalID, Vote: "FOR", ActorID: "ACTOR_003"}))
+	assert.Equal(t, GovernanceProposalSuperseded, second.Status)
+	assert.Equal(t, "4000000.00", parameter("param_3", PlatformParameterGlobalMaxLoanAmount).Value)
+
+	stub.MockTransactionStart("list")
+	proposalsBytes, err := handler.GetGovernanceProposals(stub, []string{"rejected"})
+	stub.MockTransactionEnd("list")
+	require.NoError(t, err)
+	var rejected []GovernanceProposal
+	require.NoError(t, json.Unmarshal(proposalsBytes, &rejected))
+	assert.Len(t, rejected, 2)
+}
@@ -0,0 +1,102 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/compliance/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/interfaces"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// LoanDefaultHandler raises loan defaults reported by the loan chaincode as compliance events, so
+// they are reviewed alongside other alerts and carried into regulatory reporting
+type LoanDefaultHandler struct {
+	eventEmitter domain.EventEmitter
+}
+
+// NewLoanDefaultHandler creates a new loan default handler
+func NewLoanDefaultHandler(eventEmitter domain.EventEmitter) *LoanDefaultHandler {
+	return &LoanDefaultHandler{
+		eventEmitter: eventEmitter,
+	}
+}
+
+// RecordLoanDefault records an alerted compliance event for a loan the loan chaincode has marked
+// defaulted. It is invoked cross-chaincode under the credit officer's identity.
+func (h *LoanDefaultHandler) RecordLoanDefault(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var report interfaces.LoanDefaultReport
+	if err := json.Unmarshal([]byte(args[0]), &report); err != nil {
+		return nil, fmt.Errorf("failed to parse loan default report: %w", err)
+	}
+	if report.LoanID == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "loanID", "loanID is required")
+	}
+	if report.ActorID == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+	if report.DaysPastDue < config.DefaultDaysPastDue {
+		return nil, fmt.Errorf("loan %s is %d days past due; defaults are reported from %d days", report.LoanID, report.DaysPastDue, config.DefaultDaysPastDue)
+	}
+
+	// Only the role that may mark a loan defaulted may report one
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return nil, err
+	}
+	if role != string(validation.ActorRoleCreditOfficer) {
+		return nil, services.NewChaincodeError(services.ErrCodeAccessDenied, "", "loan defaults may only be reported by a %s", validation.ActorRoleCreditOfficer)
+	}
+
+	result := &interfaces.LoanDefaultReportResult{}
+	if h.eventEmitter == nil {
+		return json.Marshal(result)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	result.EventID = services.GenerateDeterministicID(stub, config.EventPrefix)
+	event := &domain.ComplianceEvent{
+		EventID:            result.EventID,
+		Timestamp:          now,
+		RuleID:             "LOAN_DEFAULT_RULE",
+		RuleVersion:        "1.0",
+		AffectedEntityID:   report.LoanID,
+		AffectedEntityType: "LoanApplication",
+		EventType:          "LOAN_DEFAULTED",
+		Severity:           domain.PriorityHigh,
+		Details: map[string]interface{}{
+			"customerIDs":        report.CustomerIDs,
+			"loanType":           report.LoanType,
+			"outstandingBalance": report.OutstandingBalance,
+			"amountOverdue":      report.AmountOverdue,
+			"daysPastDue":        report.DaysPastDue,
+			"notes":              report.Notes,
+		},
+		ExecutionResult: domain.RuleExecutionResult{
+			RuleID:      "LOAN_DEFAULT_RULE",
+			ExecutionID: services.GenerateDeterministicID(stub, "EXEC"),
+			Timestamp:   now,
+			Success:     true,
+			Passed:      false,
+		},
+		ActorID:          report.ActorID,
+		IsAlerted:        true,
+		ResolutionStatus: "OPEN",
+	}
+	if err := h.eventEmitter.EmitComplianceEvent(stub, event); err != nil {
+		return nil, fmt.Errorf("failed to record loan default event: %w", err)
+	}
+
+	return json.Marshal(result)
+}
@@ -0,0 +1,68 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/compliance/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/interfaces"
+)
+
+func TestLoanDefaultHandler_RecordLoanDefault(t *testing.T) {
+	stub := shimtest.NewMockStub("loan_default_test", nil)
+	mockEmitter := &MockEventEmitter{}
+	handler := NewLoanDefaultHandler(mockEmitter)
+
+	record := func(txID string, report interfaces.LoanDefaultReport) ([]byte, error) {
+		reportBytes, err := json.Marshal(report)
+		require.NoError(t, err)
+		stub.MockTransactionStart(txID)
+		defer stub.MockTransactionEnd(txID)
+		return handler.RecordLoanDefault(stub, []string{string(reportBytes)})
+	}
+	report := interfaces.LoanDefaultReport{
+		LoanID:             "LOAN_001",
+		CustomerIDs:        []string{"CUST_001"},
+		LoanType:           "PERSONAL",
+		OutstandingBalance: 8200,
+		AmountOverdue:      1350.75,
+		DaysPastDue:        95,
+		ActorID:            "ACTOR_001",
+	}
+
+	// Only credit officers may report a default
+	stub.Creator = newRoleIdentity(t, "Underwriter")
+	_, err := record("tx1", report)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "may only be reported by a Credit_Officer")
+
+	// Loans short of the default threshold are refused
+	stub.Creator = newRoleIdentity(t, "Credit_Officer")
+	early := report
+	early.DaysPastDue = 45
+	_, err = record("tx2", early)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "defaults are reported from 90 days")
+	assert.Empty(t, mockEmitter.EmittedEvents)
+
+	resultBytes, err := record("tx3", report)
+	require.NoError(t, err)
+	var result interfaces.LoanDefaultReportResult
+	require.NoError(t, json.Unmarshal(resultBytes, &result))
+	require.NotEmpty(t, result.EventID)
+
+	require.Len(t, mockEmitter.EmittedEvents, 1)
+	event, ok := mockEmitter.EmittedEvents[0].(*domain.ComplianceEvent)
+	require.True(t, ok)
+	assert.Equal(t, result.EventID, event.EventID)
+	assert.Equal(t, "LOAN_DEFAULTED", event.EventType)
+	assert.Equal(t, "LOAN_001", event.AffectedEntityID)
+	assert.Equal(t, "LoanApplication", event.AffectedEntityType)
+	assert.Equal(t, domain.PriorityHigh, event.Severity)
+	assert.True(t, event.IsAlerted)
+	assert.Equal(t, "OPEN", event.ResolutionStatus)
+	assert.Equal(t, 95, event.Details["daysPastDue"])
+}
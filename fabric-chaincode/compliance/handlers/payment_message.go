@@ -0,0 +1,441 @@
+package handlers
+
+import (
+	"encoding/json"
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// ISO 20022 payment message types that can be mapped to compliance inputs
+const (
+	PaymentMessageTypePacs008 = "pacs.008" // FI to FI customer credit transfer
+	PaymentMessageTypePain001 = "pain.001" // Customer credit transfer initiation
+)
+
+// iso20022Namespace prefixes the XML namespace of every ISO 20022 message definition
+const iso20022Namespace = "urn:iso:std:iso:20022:tech:xsd:"
+
+// paymentTransactionType is the transaction type credit transfers are monitored under
+const paymentTransactionType = "TRANSFER"
+
+// notProvidedReference is the ISO 20022 placeholder for an end-to-end ID the debtor did not give
+const notProvidedReference = "NOTPROVIDED"
+
+// PaymentMessageReference preserves the identifiers a credit transfer carried in its payment
+// message, so alerts and reports can be traced back to the bank's payment flow
+type PaymentMessageReference struct {
+	MessageType          string `json:"messageType"`
+	MessageID            string `json:"messageID"`                      // GrpHdr/MsgId
+	PaymentInformationID string `json:"paymentInformationID,omitempty"` // PmtInf/PmtInfId, pain.001 only
+	InstructionID        string `json:"instructionID,omitempty"`
+	EndToEndID           string `json:"endToEndID,omitempty"`
+	TransactionID        string `json:"transactionID,omitempty"` // PmtId/TxId, pacs.008 only
+	UETR                 string `json:"uetr,omitempty"`
+}
+
+// PaymentMessageRequest carries an ISO 20022 payment message with the bank's customer accounts,
+// which attribute each credit transfer to the customers on either side of it
+type PaymentMessageRequest struct {
+	Message  string            `json:"message"`  // pacs.008 or pain.001 XML document
+	Accounts map[string]string `json:"accounts"` // IBAN or other account identification to customer ID
+	ActorID  string            `json:"actorID"`
+}
+
+// MappedPayment is one customer's side of a credit transfer, as AML screening data and as a
+// monitoring transaction
+type MappedPayment struct {
+	Reference   PaymentMessageReference `json:"reference"`
+	CustomerID  string                  `json:"customerID"`
+	Direction   string                  `json:"direction"` // DEBIT for the debtor's side, CREDIT for the creditor's
+	AMLData     TransactionAMLData      `json:"amlData"`
+	Transaction TransactionSummary      `json:"transaction"`
+}
+
+// PaymentMessageMapping is a payment message converted to compliance inputs. A credit transfer
+// with customer accounts on both sides is mapped once for each side; one with none is unmatched.
+type PaymentMessageMapping struct {
+	MessageType string                    `json:"messageType"`
+	MessageID   string                    `json:"messageID"`
+	Payments    []MappedPayment           `json:"payments"`
+	Unmatched   []PaymentMessageReference `json:"unmatched"`
+}
+
+// PaymentMessageIngestResult summarises the monitoring ingest of a payment message
+type PaymentMessageIngestResult struct {
+	Mapping *PaymentMessageMapping `json:"mapping"`
+	TransactionIngestResult
+}
+
+// isoDocument is the Document root of a pacs.008 or pain.001 message. Elements are matched by
+// local name, so any version of either message parses.
+type isoDocument struct {
+	XMLName                    xml.Name
+	FIToFICustomerTransfer     *isoCustomerTransfer `xml:"FIToFICstmrCdtTrf"`
+	CustomerTransferInitiation *isoCustomerTransfer `xml:"CstmrCdtTrfInitn"`
+}
+
+type isoCustomerTransfer struct {
+	GroupHeader  isoGroupHeader          `xml:"GrpHdr"`
+	Payments     []isoPaymentInformation `xml:"PmtInf"`      // pain.001
+	Transactions []isoCreditTransfer     `xml:"CdtTrfTxInf"` // pacs.008
+}
+
+type isoGroupHeader struct {
+	MessageID      string `xml:"MsgId"`
+	CreationTime   string `xml:"CreDtTm"`
+	SettlementDate string `xml:"IntrBkSttlmDt"`
+}
+
+type isoPaymentInformation struct {
+	PaymentInformationID string              `xml:"PmtInfId"`
+	Debtor               isoParty            `xml:"Dbtr"`
+	DebtorAccount        isoAccount          `xml:"DbtrAcct"`
+	Transactions         []isoCreditTransfer `xml:"CdtTrfTxInf"`
+}
+
+type isoCreditTransfer struct {
+	PaymentID        isoPaymentID `xml:"PmtId"`
+	SettlementAmount *isoAmount   `xml:"IntrBkSttlmAmt"` // pacs.008
+	SettlementDate   string       `xml:"IntrBkSttlmDt"`  // pacs.008
+	InstructedAmount *isoAmount   `xml:"Amt>InstdAmt"`   // pain.001
+	Debtor           isoParty     `xml:"Dbtr"`
+	DebtorAccount    isoAccount   `xml:"DbtrAcct"`
+	Creditor         isoParty     `xml:"Cdtr"`
+	CreditorAccount  isoAccount   `xml:"CdtrAcct"`
+	PurposeCode      string       `xml:"Purp>Cd"`
+	Remittance       []string     `xml:"RmtInf>Ustrd"`
+}
+
+type isoPaymentID struct {
+	InstructionID string `xml:"InstrId"`
+	EndToEndID    string `xml:"EndToEndId"`
+	TransactionID string `xml:"TxId"`
+	UETR          string `xml:"UETR"`
+}
+
+type isoAmount struct {
+	Value    string `xml:",chardata"`
+	Currency string `xml:"Ccy,attr"`
+}
+
+type isoParty struct {
+	Name      string `xml:"Nm"`
+	Country   string `xml:"PstlAdr>Ctry"`
+	Residence string `xml:"CtryOfRes"`
+}
+
+type isoAccount struct {
+	IBAN  string `xml:"Id>IBAN"`
+	Other string `xml:"Id>Othr>Id"`
+}
+
+// paymentCredit is a credit transfer with the message-level details it inherits
+type paymentCredit struct {
+	reference     PaymentMessageReference
+	transfer      isoCreditTransfer
+	debtor        isoParty
+	debtorAccount isoAccount
+	amount        *isoAmount
+	date          string
+	sequence      int
+}
+
+// MapPaymentMessage converts a pacs.008 or pain.001 message to AML screening data and monitoring
+// transactions without recording anything
+func (h *TransactionMonitoringHandler) MapPaymentMessage(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req PaymentMessageRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse payment message request: %v", err)
+	}
+	mapping, err := mapPaymentMessage(&req)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(mapping)
+}
+
+// IngestPaymentMessage maps a pacs.008 or pain.001 message and ingests each customer's side of its
+// credit transfers for monitoring, as IngestTransactions does. A message resent after a failure
+// skips the transactions already ingested.
+func (h *TransactionMonitoringHandler) IngestPaymentMessage(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req PaymentMessageRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse payment message request: %v", err)
+	}
+	mapping, err := mapPaymentMessage(&req)
+	if err != nil {
+		return nil, err
+	}
+	if len(mapping.Payments) == 0 {
+		return nil, fmt.Errorf("no credit transfer in message %s involves a listed customer account", mapping.MessageID)
+	}
+
+	ingestRequest := &TransactionIngestRequest{ActorID: req.ActorID}
+	for _, payment := range mapping.Payments {
+		ingestRequest.Transactions = append(ingestRequest.Transactions, payment.Transaction)
+	}
+	ingestResult, err := h.ingestTransactions(stub, ingestRequest)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(&PaymentMessageIngestResult{Mapping: mapping, TransactionIngestResult: *ingestResult})
+}
+
+// Helper functions
+
+// mapPaymentMessage parses a payment message and maps each credit transfer to the customers
+// holding its debtor and creditor accounts
+func mapPaymentMessage(req *PaymentMessageRequest) (*PaymentMessageMapping, error) {
+	if strings.TrimSpace(req.Message) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "message", "message is required")
+	}
+	if len(req.Message) > config.MaxPaymentMessageBytes {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "message exceeds %d bytes", config.MaxPaymentMessageBytes)
+	}
+	if len(req.Accounts) == 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "at least one customer account is required")
+	}
+	accounts := make(map[string]string, len(req.Accounts))
+	for account, customerID := range req.Accounts {
+		if normalizeAccount(account) == "" || strings.TrimSpace(customerID) == "" {
+			return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "accounts must map account identifications to customer IDs")
+		}
+		accounts[normalizeAccount(account)] = customerID
+	}
+
+	var document isoDocument
+	if err := xml.Unmarshal([]byte(req.Message), &document); err != nil {
+		return nil, fmt.Errorf("failed to parse payment message: %w", err)
+	}
+
+	var messageType string
+	var transfer *isoCustomerTransfer
+	switch {
+	case document.FIToFICustomerTransfer != nil:
+		messageType, transfer = PaymentMessageTypePacs008, document.FIToFICustomerTransfer
+	case document.CustomerTransferInitiation != nil:
+		messageType, transfer = PaymentMessageTypePain001, document.CustomerTransferInitiation
+	default:
+		return nil, fmt.Errorf("unsupported payment message: expected %s or %s", PaymentMessageTypePacs008, PaymentMessageTypePain001)
+	}
+	namespace := document.XMLName.Space
+	if namespace != "" && !strings.HasPrefix(namespace, iso20022Namespace+messageType) {
+		return nil, fmt.Errorf("payment message namespace %s does not match %s", namespace, messageType)
+	}
+
+	header := transfer.GroupHeader
+	if strings.TrimSpace(header.MessageID) == "" {
+		return nil, fmt.Errorf("message ID (GrpHdr/MsgId) is required")
+	}
+
+	credits := []paymentCredit{}
+	if messageType == PaymentMessageTypePacs008 {
+		for _, txn := range transfer.Transactions {
+			date := txn.SettlementDate
+			if date == "" {
+				date = header.SettlementDate
+			}
+			credits = append(credits, paymentCredit{transfer: txn, debtor: txn.Debtor, debtorAccount: txn.DebtorAccount, amount: txn.SettlementAmount, date: date})
+		}
+	} else {
+		// An initiation is not settled yet; the customer instructed it when the message was created
+		for _, payment := range transfer.Payments {
+			for _, txn := range payment.Transactions {
+				credit := paymentCredit{transfer: txn, debtor: payment.Debtor, debtorAccount: payment.DebtorAccount, amount: txn.InstructedAmount}
+				credit.reference.PaymentInformationID = strings.TrimSpace(payment.PaymentInformationID)
+				credits = append(credits, credit)
+			}
+		}
+	}
+	if len(credits) == 0 {
+		return nil, fmt.Errorf("payment message %s has no credit transfers", header.MessageID)
+	}
+
+	mapping := &PaymentMessageMapping{
+		MessageType: messageType,
+		MessageID:   strings.TrimSpace(header.MessageID),
+		Payments:    []MappedPayment{},
+		Unmatched:   []PaymentMessageReference{},
+	}
+	for i := range credits {
+		credit := &credits[i]
+		credit.sequence = i + 1
+		credit.reference.MessageType = messageType
+		credit.reference.MessageID = mapping.MessageID
+		credit.reference.InstructionID = strings.TrimSpace(credit.transfer.PaymentID.InstructionID)
+		credit.reference.EndToEndID = strings.TrimSpace(credit.transfer.PaymentID.EndToEndID)
+		credit.reference.TransactionID = strings.TrimSpace(credit.transfer.PaymentID.TransactionID)
+		credit.reference.UETR = strings.ToLower(strings.TrimSpace(credit.transfer.PaymentID.UETR))
+
+		payments, err := mapPaymentCredit(credit, header, accounts)
+		if err != nil {
+			return nil, fmt.Errorf("invalid credit transfer %d: %w", credit.sequence, err)
+		}
+		if len(payments) == 0 {
+			mapping.Unmatched = append(mapping.Unmatched, credit.reference)
+			continue
+		}
+		mapping.Payments = append(mapping.Payments, payments...)
+	}
+	return mapping, nil
+}
+
+// mapPaymentCredit maps a credit transfer for the customer on each side of it that holds a
+// listed account
+func mapPaymentCredit(credit *paymentCredit, header isoGroupHeader, accounts map[string]string) ([]MappedPayment, error) {
+	if credit.amount == nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "amount", "amount is required")
+	}
+	amount, err := strconv.ParseFloat(strings.TrimSpace(credit.amount.Value), 64)
+	if err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid amount %s", credit.amount.Value)
+	}
+	currency := strings.ToUpper(strings.TrimSpace(credit.amount.Currency))
+
+	date := credit.date
+	if date == "" {
+		date = header.CreationTime
+	}
+	transactionDate, err := parseISODateTime(date)
+	if err != nil {
+		return nil, err
+	}
+
+	purpose := strings.TrimSpace(strings.Join(credit.transfer.Remittance, " "))
+	if purpose == "" {
+		purpose = strings.TrimSpace(credit.transfer.PurposeCode)
+	}
+
+	type side struct {
+		account      isoAccount
+		direction    string
+		counterparty isoParty
+	}
+	sides := []side{
+		{credit.debtorAccount, TransactionDirectionDebit, credit.transfer.Creditor},
+		{credit.transfer.CreditorAccount, TransactionDirectionCredit, credit.debtor},
+	}
+
+	payments := []MappedPayment{}
+	for _, s := range sides {
+		customerID, ok := accounts[s.account.identification()]
+		if !ok {
+			continue
+		}
+		reference := credit.reference
+		payments = append(payments, MappedPayment{
+			Reference:  reference,
+			CustomerID: customerID,
+			Direction:  s.direction,
+			AMLData: TransactionAMLData{
+				Amount:              amount,
+				Currency:            currency,
+				TransactionType:     paymentTransactionType,
+				CounterpartyName:    strings.TrimSpace(s.counterparty.Name),
+				CounterpartyCountry: s.counterparty.country(),
+				Purpose:             purpose,
+				TransactionDate:     transactionDate,
+				PaymentReference:    &reference,
+			},
+			Transaction: TransactionSummary{
+				CustomerID:       customerID,
+				AccountRef:       s.account.identification(),
+				Amount:           amount,
+				Currency:         currency,
+				Direction:        s.direction,
+				TransactionType:  paymentTransactionType,
+				CounterpartyName: strings.TrimSpace(s.counterparty.Name),
+				TransactionDate:  transactionDate,
+				PaymentReference: &reference,
+			},
+		})
+	}
+
+	// Both sides of a transfer between two customers are monitored, under distinct IDs
+	transactionID := paymentTransactionID(credit)
+	for i := range payments {
+		payments[i].AMLData.TransactionID = transactionID
+		payments[i].Transaction.TransactionID = transactionID
+		if len(payments) > 1 {
+			payments[i].Transaction.TransactionID = transactionID + "/" + payments[i].Direction
+		}
+	}
+	return payments, nil
+}
+
+// paymentTransactionID identifies a credit transfer by its UETR, which is unique across the
+// payment's whole journey, or else by its message and its references within the message
+func paymentTransactionID(credit *paymentCredit) string {
+	reference := credit.reference
+	if reference.UETR != "" {
+		return reference.UETR
+	}
+
+	parts := []string{reference.MessageID}
+	if reference.PaymentInformationID != "" {
+		parts = append(parts, reference.PaymentInformationID)
+	}
+	switch {
+	case reference.TransactionID != "":
+		parts = append(parts, reference.TransactionID)
+	case reference.InstructionID != "":
+		parts = append(parts, reference.InstructionID)
+	case reference.EndToEndID != "" && reference.EndToEndID != notProvidedReference:
+		parts = append(parts, reference.EndToEndID)
+	default:
+		parts = append(parts, strconv.Itoa(credit.sequence))
+	}
+	return strings.Join(parts, "/")
+}
+
+// identification returns an account's IBAN or other identification, normalised for lookup
+func (a isoAccount) identification() string {
+	if a.IBAN != "" {
+		return normalizeAccount(a.IBAN)
+	}
+	return normalizeAccount(a.Other)
+}
+
+// country returns the country of a party's postal address, or else its country of residence
+func (p isoParty) country() string {
+	if p.Country != "" {
+		return strings.ToUpper(strings.TrimSpace(p.Country))
+	}
+	return strings.ToUpper(strings.TrimSpace(p.Residence))
+}
+
+// normalizeAccount drops the spaces IBANs are often printed with and ignores case
+func normalizeAccount(account string) string {
+	return strings.ToUpper(strings.Join(strings.Fields(account), ""))
+}
+
+// parseISODateTime parses an ISO 20022 date or date-time. A date-time without an offset is taken
+// as UTC.
+func parseISODateTime(value string) (time.Time, error) {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999", "2006-01-02"} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return parsed.UTC(), nil
+		}
+	}
+	if value == "" {
+		return time.Time{}, fmt.Errorf("settlement date or creation time is required")
+	}
+	return time.Time{}, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid date %s", value)
+}
@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+const testPacs008Message = `<?xml version="1.0" encoding="UTF-8"?>
+<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pacs.008.001.08">
+  <FIToFICstmrCdtTrf>
+    <GrpHdr>
+      <MsgId>MSG-20260601-01</MsgId>
+      <CreDtTm>2026-06-01T09:30:00Z</CreDtTm>
+      <NbOfTxs>2</NbOfTxs>
+      <IntrBkSttlmDt>2026-06-01</IntrBkSttlmDt>
+    </GrpHdr>
+    <CdtTrfTxInf>
+      <PmtId>
+        <InstrId>INSTR-1</InstrId>
+        <EndToEndId>INV-4471</EndToEndId>
+        <TxId>TX-1</TxId>
+        <UETR>8A562C67-CA16-48BA-B074-65581BE6F001</UETR>
+      </PmtId>
+      <IntrBkSttlmAmt Ccy="EUR">9500.00</IntrBkSttlmAmt>
+      <Dbtr><Nm>Acme Trading Ltd</Nm><PstlAdr><Ctry>GB</Ctry></PstlAdr></Dbtr>
+      <DbtrAcct><Id><IBAN>GB29NWBK60161331926819</IBAN></Id></DbtrAcct>
+      <Cdtr><Nm>Ada Lovelace</Nm></Cdtr>
+      <CdtrAcct><Id><IBAN>DE89 3704 0044 0532 0130 00</IBAN></Id></CdtrAcct>
+      <RmtInf><Ustrd>Invoice 4471</Ustrd></RmtInf>
+    </CdtTrfTxInf>
+    <CdtTrfTxInf>
+      <PmtId>
+        <EndToEndId>NOTPROVIDED</EndToEndId>
+        <TxId>TX-2</TxId>
+      </PmtId>
+      <IntrBkSttlmAmt Ccy="EUR">120.00</IntrBkSttlmAmt>
+      <Dbtr><Nm>Someone Else</Nm></Dbtr>
+      <DbtrAcct><Id><IBAN>FR1420041010050500013M02606</IBAN></Id></DbtrAcct>
+      <Cdtr><Nm>Another Party</Nm></Cdtr>
+      <CdtrAcct><Id><Othr><Id>ACC-999</Id></Othr></Id></CdtrAcct>
+    </CdtTrfTxInf>
+  </FIToFICstmrCdtTrf>
+</Document>`
+
+const testPain001Message = `<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.001.001.09">
+  <CstmrCdtTrfInitn>
+    <GrpHdr>
+      <MsgId>PAIN-77</MsgId>
+      <CreDtTm>2026-06-01T08:00:00</CreDtTm>
+    </GrpHdr>
+    <PmtInf>
+      <PmtInfId>BATCH-1</PmtInfId>
+      <ReqdExctnDt><Dt>2026-06-03</Dt></ReqdExctnDt>
+      <Dbtr><Nm>Ada Lovelace</Nm></Dbtr>
+      <DbtrAcct><Id><IBAN>DE89370400440532013000</IBAN></Id></DbtrAcct>
+      <CdtTrfTxInf>
+        <PmtId><EndToEndId>RENT-JUNE</EndToEndId></PmtId>
+        <Amt><InstdAmt Ccy="eur">1500</InstdAmt></Amt>
+        <Cdtr><Nm>Grace Hopper</Nm><CtryOfRes>US</CtryOfRes></Cdtr>
+        <CdtrAcct><Id><Othr><Id>acc-500</Id></Othr></Id></CdtrAcct>
+        <Purp><Cd>RENT</Cd></Purp>
+      </CdtTrfTxInf>
+    </PmtInf>
+  </CstmrCdtTrfInitn>
+</Document>`
+
+func TestPaymentMessageMapping(t *testing.T) {
+	accounts := map[string]string{"DE89370400440532013000": "CUST_ADA", "ACC-500": "CUST_GRACE"}
+
+	mapping, err := mapPaymentMessage(&PaymentMessageRequest{Message: testPacs008Message, Accounts: accounts})
+	require.NoError(t, err)
+	assert.Equal(t, PaymentMessageTypePacs008, mapping.MessageType)
+	assert.Equal(t, "MSG-20260601-01", mapping.MessageID)
+
+	// Only the creditor holds a listed account, so the transfer is a credit for her
+	require.Len(t, mapping.Payments, 1)
+	payment := mapping.Payments[0]
+	assert.Equal(t, "CUST_ADA", payment.CustomerID)
+	assert.Equal(t, TransactionDirectionCredit, payment.Direction)
+	assert.Equal(t, PaymentMessageReference{
+		MessageType:   PaymentMessageTypePacs008,
+		MessageID:     "MSG-20260601-01",
+		InstructionID: "INSTR-1",
+		EndToEndID:    "INV-4471",
+		TransactionID: "TX-1",
+		UETR:          "8a562c67-ca16-48ba-b074-65581be6f001",
+	}, payment.Reference)
+
+	settled := time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC)
+	assert.Equal(t, "8a562c67-ca16-48ba-b074-65581be6f001", payment.Transaction.TransactionID)
+	assert.Equal(t, "DE89370400440532013000", payment.Transaction.AccountRef)
+	assert.Equal(t, 9500.0, payment.Transaction.Amount)
+	assert.Equal(t, "EUR", payment.Transaction.Currency)
+	assert.Equal(t, "Acme Trading Ltd", payment.Transaction.CounterpartyName)
+	assert.Equal(t, settled, payment.Transaction.TransactionDate)
+	assert.Equal(t, "MSG-20260601-01", payment.Transaction.PaymentReference.MessageID)
+
+	assert.Equal(t, payment.Transaction.TransactionID, payment.AMLData.TransactionID)
+	assert.Equal(t, "GB", payment.AMLData.CounterpartyCountry)
+	assert.Equal(t, "Invoice 4471", payment.AMLData.Purpose)
+	assert.Equal(t, "TRANSFER", payment.AMLData.TransactionType)
+	assert.Equal(t, "INV-4471", payment.AMLData.PaymentReference.EndToEndID)
+
+	// The other transfer involves no listed account
+	require.Len(t, mapping.Unmatched, 1)
+	assert.Equal(t, "TX-2", mapping.Unmatched[0].TransactionID)
+
+	// An initiation between two customers maps both sides, dated when it was instructed
+	mapping, err = mapPaymentMessage(&PaymentMessageRequest{Message: testPain001Message, Accounts: accounts})
+	require.NoError(t, err)
+	assert.Equal(t, PaymentMessageTypePain001, mapping.MessageType)
+	require.Len(t, mapping.Payments, 2)
+	debit, credit := mapping.Payments[0], mapping.Payments[1]
+	assert.Equal(t, "CUST_ADA", debit.CustomerID)
+	assert.Equal(t, TransactionDirectionDebit, debit.Direction)
+	assert.Equal(t, "Grace Hopper", debit.Transaction.CounterpartyName)
+	assert.Equal(t, "US", debit.AMLData.CounterpartyCountry)
+	assert.Equal(t, "RENT", debit.AMLData.Purpose)
+	assert.Equal(t, "CUST_GRACE", credit.CustomerID)
+	assert.Equal(t, TransactionDirectionCredit, credit.Direction)
+	assert.Equal(t, "Ada Lovelace", credit.Transaction.CounterpartyName)
+	assert.Equal(t, "BATCH-1", debit.Reference.PaymentInformationID)
+	assert.Equal(t, "PAIN-77/BATCH-1/RENT-JUNE", debit.AMLData.TransactionID)
+	assert.Equal(t, "PAIN-77/BATCH-1/RENT-JUNE/DEBIT", debit.Transaction.TransactionID)
+	assert.Equal(t, "PAIN-77/BATCH-1/RENT-JUNE/CREDIT", credit.Transaction.TransactionID)
+	assert.Equal(t, time.Date(2026, 6, 1, 8, 0, 0, 0, time.UTC), debit.Transaction.TransactionDate)
+	assert.Equal(t, "EUR", debit.Transaction.Currency)
+
+	tests := []struct {
+		name     string
+		req      PaymentMessageRequest
+		expected string
+	}{
+		{"no message", PaymentMessageRequest{Accounts: accounts}, "message is required"},
+		{"no accounts", PaymentMessageRequest{Message: testPacs008Message}, "at least one customer account is required"},
+		{"not XML", PaymentMessageRequest{Message: "{}", Accounts: accounts}, "failed to parse payment message"},
+		{"other message", PaymentMessageRequest{Message: `<Document><CstmrPmtStsRpt/></Document>`, Accounts: accounts}, "unsupported payment message"},
+		{"wrong namespace", PaymentMessageRequest{Message: `<Document xmlns="urn:iso:std:iso:20022:tech:xsd:pain.001.001.09"><FIToFICstmrCdtTrf/></Document>`, Accounts: accounts}, "does not match pacs.008"},
+		{"no message ID", PaymentMessageRequest{Message: `<Document><FIToFICstmrCdtTrf><GrpHdr/></FIToFICstmrCdtTrf></Document>`, Accounts: accounts}, "MsgId"},
+		{"bad amount", PaymentMessageRequest{Message: `<Document><FIToFICstmrCdtTrf><GrpHdr><MsgId>M</MsgId></GrpHdr><CdtTrfTxInf><IntrBkSttlmAmt Ccy="EUR">lots</IntrBkSttlmAmt></CdtTrfTxInf></FIToFICstmrCdtTrf></Document>`, Accounts: accounts}, "invalid credit transfer 1: invalid amount"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := mapPaymentMessage(&tt.req)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
+
+func TestTransactionMonitoringHandler_IngestPaymentMessage(t *testing.T) {
+	clock := services.NewFixedClock(time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC))
+	defer services.SetClock(clock)()
+	stub := shimtest.NewMockStub("payment_message_test", nil)
+	handler := NewTransactionMonitoringHandler(&MockEventEmitter{})
+	stub.Creator = newRoleIdentity(t, "System_Administrator")
+
+	invoke := func(txID string, fn func([]string) ([]byte, error), request interface{}) ([]byte, error) {
+		requestBytes, err := json.Marshal(request)
+		require.NoError(t, err)
+		stub.MockTransactionStart(txID)
+		defer stub.MockTransactionEnd(txID)
+		return fn([]string{string(requestBytes)})
+	}
+	ingestMessage := func(args []string) ([]byte, error) { return handler.IngestPaymentMessage(stub, args) }
+	request := PaymentMessageRequest{Message: testPacs008Message, Accounts: map[string]string{"DE89370400440532013000": "CUST_ADA"}, ActorID: "CORE_BANKING"}
+
+	// Mapping records nothing
+	mappingBytes, err := invoke("map_1", func(args []string) ([]byte, error) { return handler.MapPaymentMessage(stub, args) }, request)
+	require.NoError(t, err)
+	var mapping PaymentMessageMapping
+	require.NoError(t, json.Unmarshal(mappingBytes, &mapping))
+	require.Len(t, mapping.Payments, 1)
+	exists, err := handler.persistenceService.Exists(stub, monitoredTransactionIDKey(mapping.Payments[0].Transaction.TransactionID))
+	require.NoError(t, err)
+	assert.False(t, exists)
+
+	resultBytes, err := invoke("ingest_1", ingestMessage, request)
+	require.NoError(t, err)
+	var result PaymentMessageIngestResult
+	require.NoError(t, json.Unmarshal(resultBytes, &result))
+	assert.Equal(t, 1, result.Ingested)
+	assert.Equal(t, "MSG-20260601-01", result.Mapping.MessageID)
+
+	// The monitored transaction keeps the message references
+	transactions, err := handler.customerTransactions(stub, "CUST_ADA")
+	require.NoError(t, err)
+	require.Len(t, transactions, 1)
+	require.NotNil(t, transactions[0].PaymentReference)
+	assert.Equal(t, "INV-4471", transactions[0].PaymentReference.EndToEndID)
+
+	// Resending the message skips what was already ingested
+	resultBytes, err = invoke("ingest_2", ingestMessage, request)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(resultBytes, &result))
+	assert.Equal(t, 0, result.Ingested)
+	assert.Equal(t, []string{"8a562c67-ca16-48ba-b074-65581be6f001"}, result.Duplicates)
+
+	// A message for no listed account has nothing to monitor
+	request.Accounts = map[string]string{"GB00UNKNOWN": "CUST_X"}
+	_, err = invoke("ingest_3", ingestMessage, request)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "involves a listed customer account")
+
+	// Ingest keeps the monitoring role checks
+	stub.Creator = newRoleIdentity(t, "Underwriter")
+	request.Accounts = map[string]string{"DE89370400440532013000": "CUST_ADA"}
+	_, err = invoke("ingest_4", ingestMessage, request)
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "may only be ingested by")
+}
@@ -0,0 +1,517 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/compliance/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// pepNamePrefixLength is the number of leading characters (runes) of each name token used as
+// the PEP_BY_NAME index key
+const pepNamePrefixLength = 3
+
+// PEPListManager maintains the Politically Exposed Person list screened by AML checks
+type PEPListManager struct {
+	persistenceService *services.PersistenceService
+	eventEmitter       domain.EventEmitter
+}
+
+// NewPEPListManager creates a new PEP list manager
+func NewPEPListManager(eventEmitter domain.EventEmitter) *PEPListManager {
+	return &PEPListManager{
+		persistenceService: services.NewPersistenceService(),
+		eventEmitter:       eventEmitter,
+	}
+}
+
+// PEPEntry represents a Politically Exposed Person entry
+type PEPEntry struct {
+	EntryID            string    `json:"entryID"`
+	Name               string    `json:"name"`
+	Aliases            []string  `json:"aliases,omitempty"`
+	Position           string    `json:"position"`
+	Country            string    `json:"country"`
+	RiskCategory       string    `json:"riskCategory"` // LOW, MEDIUM, HIGH
+	Source             string    `json:"source"`
+	IsActive           bool      `json:"isActive"`
+	DeactivationReason string    `json:"deactivationReason,omitempty"`
+	CreatedBy          string    `json:"createdBy"`
+	CreatedDate        time.Time `json:"createdDate"`
+	LastUpdatedBy      string    `json:"lastUpdatedBy"`
+	LastUpdated        time.Time `json:"lastUpdated"`
+}
+
+// PEPEntryRequest represents a request to add or update a PEP entry
+type PEPEntryRequest struct {
+	EntryID      string   `json:"entryID,omitempty"` // Source identifier; generated when empty
+	Name         string   `json:"name"`
+	Aliases      []string `json:"aliases,omitempty"`
+	Position     string   `json:"position"`
+	Country      string   `json:"country"`
+	RiskCategory string   `json:"riskCategory"`
+	Source       string   `json:"source"`
+	ActorID      string   `json:"actorID"`
+}
+
+// PEPBulkImportRequest represents a request to import a PEP list from one source
+type PEPBulkImportRequest struct {
+	Source  string            `json:"source"`
+	Entries []PEPEntryRequest `json:"entries"`
+	ActorID string            `json:"actorID"`
+}
+
+// PEPImportResult reports the outcome of a bulk PEP list import
+type PEPImportResult struct {
+	Source     string               `json:"source"`
+	Imported   int                  `json:"imported"`
+	Updated    int                  `json:"updated"`
+	Rejected   []PEPImportRejection `json:"rejected"`
+	ImportDate time.Time            `json:"importDate"`
+	ImportedBy string               `json:"importedBy"`
+}
+
+// PEPImportRejection records an entry a bulk import could not accept
+type PEPImportRejection struct {
+	Index   int    `json:"index"`
+	EntryID string `json:"entryID,omitempty"`
+	Reason  string `json:"reason"`
+}
+
+// PEPDeactivationRequest represents a request to deactivate a PEP entry
+type PEPDeactivationRequest struct {
+	EntryID string `json:"entryID"`
+	Reason  string `json:"reason"`
+	ActorID string `json:"actorID"`
+}
+
+// AddPEPEntry adds a PEP entry or replaces an existing entry with the same ID
+func (m *PEPListManager) AddPEPEntry(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req PEPEntryRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse PEP entry request: %v", err)
+	}
+
+	if err := m.requireListMaintainer(stub); err != nil {
+		return nil, err
+	}
+
+	entry, _, err := m.putPEPEntry(stub, &req, req.ActorID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := m.recordPEPListEvent(stub, "PEP_ENTRY_ADDED", entry.EntryID, map[string]interface{}{
+		"entryID":      entry.EntryID,
+		"country":      entry.Country,
+		"riskCategory": entry.RiskCategory,
+		"source":       entry.Source,
+	}, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to record PEP list event: %w", err)
+	}
+
+	return json.Marshal(entry)
+}
+
+// BulkImportPEPList adds or replaces every entry of a PEP list from one source. Invalid entries
+// are reported and skipped rather than failing the import.
+func (m *PEPListManager) BulkImportPEPList(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req PEPBulkImportRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse PEP import request: %v", err)
+	}
+	if strings.TrimSpace(req.Source) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "source", "source is required")
+	}
+	if len(req.Entries) == 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "at least one entry is required")
+	}
+
+	if err := m.requireListMaintainer(stub); err != nil {
+		return nil, err
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &PEPImportResult{
+		Source:     req.Source,
+		Rejected:   []PEPImportRejection{},
+		ImportDate: now,
+		ImportedBy: req.ActorID,
+	}
+
+	seen := map[string]bool{}
+	for i := range req.Entries {
+		entryReq := req.Entries[i]
+		entryReq.Source = req.Source
+
+		if entryReq.EntryID != "" {
+			if seen[entryReq.EntryID] {
+				result.Rejected = append(result.Rejected, PEPImportRejection{Index: i, EntryID: entryReq.EntryID, Reason: "duplicate entryID in import"})
+				continue
+			}
+			seen[entryReq.EntryID] = true
+		}
+
+		_, existed, err := m.putPEPEntry(stub, &entryReq, req.ActorID)
+		if err != nil {
+			result.Rejected = append(result.Rejected, PEPImportRejection{Index: i, EntryID: entryReq.EntryID, Reason: err.Error()})
+			continue
+		}
+		if existed {
+			result.Updated++
+		} else {
+			result.Imported++
+		}
+	}
+
+	if err := m.recordPEPListEvent(stub, "PEP_LIST_IMPORTED", req.Source, map[string]interface{}{
+		"source":   result.Source,
+		"imported": result.Imported,
+		"updated":  result.Updated,
+		"rejected": len(result.Rejected),
+	}, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to record PEP list event: %w", err)
+	}
+
+	return json.Marshal(result)
+}
+
+// DeactivatePEPEntry withdraws a PEP entry from screening. The entry is kept for the audit trail.
+func (m *PEPListManager) DeactivatePEPEntry(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req PEPDeactivationRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse PEP deactivation request: %v", err)
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "reason", "reason is required")
+	}
+
+	if err := m.requireListMaintainer(stub); err != nil {
+		return nil, err
+	}
+
+	var entry PEPEntry
+	if err := m.persistenceService.Get(stub, fmt.Sprintf("PEP_%s", req.EntryID), &entry); err != nil {
+		return nil, fmt.Errorf("PEP entry not found: %w", err)
+	}
+	if !entry.IsActive {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "PEP entry %s is already inactive", req.EntryID)
+	}
+
+	// Inactive entries drop out of the name index so screening no longer finds them
+	if err := m.removePEPNameIndex(stub, &entry); err != nil {
+		return nil, err
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.IsActive = false
+	entry.DeactivationReason = req.Reason
+	entry.LastUpdatedBy = req.ActorID
+	entry.LastUpdated = now
+	if err := m.persistenceService.Put(stub, fmt.Sprintf("PEP_%s", entry.EntryID), &entry); err != nil {
+		return nil, fmt.Errorf("failed to update PEP entry: %w", err)
+	}
+
+	if err := m.recordPEPListEvent(stub, "PEP_ENTRY_DEACTIVATED", entry.EntryID, map[string]interface{}{
+		"entryID": entry.EntryID,
+		"reason":  req.Reason,
+	}, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to record PEP list event: %w", err)
+	}
+
+	return json.Marshal(&entry)
+}
+
+// GetPEPEntry retrieves a PEP entry by ID
+func (m *PEPListManager) GetPEPEntry(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var entry PEPEntry
+	if err := m.persistenceService.Get(stub, fmt.Sprintf("PEP_%s", args[0]), &entry); err != nil {
+		return nil, fmt.Errorf("PEP entry not found: %w", err)
+	}
+
+	return json.Marshal(&entry)
+}
+
+// QueryPEPEntriesByCountry retrieves the PEP entries for a country, active and inactive
+func (m *PEPListManager) QueryPEPEntriesByCountry(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	iterator, err := stub.GetStateByPartialCompositeKey("PEP_BY_COUNTRY", []string{strings.ToUpper(strings.TrimSpace(args[0]))})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query PEP entries by country: %w", err)
+	}
+	defer iterator.Close()
+
+	entries := []PEPEntry{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate PEP entries: %w", err)
+		}
+
+		var entry PEPEntry
+		if err := m.persistenceService.Get(stub, fmt.Sprintf("PEP_%s", string(response.Value)), &entry); err != nil {
+			continue // Skip if entry not found
+		}
+		entries = append(entries, entry)
+	}
+
+	return json.Marshal(entries)
+}
+
+// Helper methods
+
+// putPEPEntry validates and stores an entry, replacing its index keys. It reports whether an
+// entry with the same ID already existed.
+func (m *PEPListManager) putPEPEntry(stub shim.ChaincodeStubInterface, req *PEPEntryRequest, actorID string) (*PEPEntry, bool, error) {
+	if err := m.validatePEPEntryRequest(req); err != nil {
+		return nil, false, err
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, false, err
+	}
+	entry := &PEPEntry{
+		EntryID:       req.EntryID,
+		Name:          strings.TrimSpace(req.Name),
+		Aliases:       req.Aliases,
+		Position:      req.Position,
+		Country:       strings.ToUpper(strings.TrimSpace(req.Country)),
+		RiskCategory:  strings.ToUpper(req.RiskCategory),
+		Source:        req.Source,
+		IsActive:      true,
+		CreatedBy:     actorID,
+		CreatedDate:   now,
+		LastUpdatedBy: actorID,
+		LastUpdated:   now,
+	}
+	if entry.EntryID == "" {
+		entry.EntryID = services.GenerateDeterministicID(stub, config.PEPEntryPrefix)
+	}
+
+	// Drop index keys of the previous version so renamed entries and removed aliases no longer
+	// surface during screening
+	var existing PEPEntry
+	existed := false
+	if err := m.persistenceService.Get(stub, fmt.Sprintf("PEP_%s", entry.EntryID), &existing); err == nil {
+		existed = true
+		entry.CreatedBy = existing.CreatedBy
+		entry.CreatedDate = existing.CreatedDate
+		if err := m.removePEPNameIndex(stub, &existing); err != nil {
+			return nil, false, err
+		}
+		if err := m.deletePEPIndex(stub, "PEP_BY_COUNTRY", existing.Country, existing.EntryID); err != nil {
+			return nil, false, err
+		}
+	}
+
+	if err := m.persistenceService.Put(stub, fmt.Sprintf("PEP_%s", entry.EntryID), entry); err != nil {
+		return nil, false, fmt.Errorf("failed to store PEP entry: %w", err)
+	}
+	for _, prefix := range pepEntryPrefixes(entry) {
+		if err := m.putPEPIndex(stub, "PEP_BY_NAME", prefix, entry.EntryID); err != nil {
+			return nil, false, err
+		}
+	}
+	if err := m.putPEPIndex(stub, "PEP_BY_COUNTRY", entry.Country, entry.EntryID); err != nil {
+		return nil, false, err
+	}
+
+	return entry, existed, nil
+}
+
+// candidateEntries returns the active entries sharing a name prefix with the screened name
+func (m *PEPListManager) candidateEntries(stub shim.ChaincodeStubInterface, name string) ([]PEPEntry, error) {
+	seen := map[string]bool{}
+	candidates := []PEPEntry{}
+
+	for _, prefix := range pepNamePrefixes(name) {
+		iterator, err := stub.GetStateByPartialCompositeKey("PEP_BY_NAME", []string{prefix})
+		if err != nil {
+			return nil, fmt.Errorf("failed to query PEP name index: %w", err)
+		}
+
+		for iterator.HasNext() {
+			response, err := iterator.Next()
+			if err != nil {
+				iterator.Close()
+				return nil, fmt.Errorf("failed to iterate PEP name index: %w", err)
+			}
+
+			entryID := string(response.Value)
+			if seen[entryID] {
+				continue
+			}
+			seen[entryID] = true
+
+			var entry PEPEntry
+			if err := m.persistenceService.Get(stub, fmt.Sprintf("PEP_%s", entryID), &entry); err != nil {
+				continue // Skip if entry not found
+			}
+			if entry.IsActive {
+				candidates = append(candidates, entry)
+			}
+		}
+		iterator.Close()
+	}
+
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].EntryID < candidates[j].EntryID })
+	return candidates, nil
+}
+
+func (m *PEPListManager) removePEPNameIndex(stub shim.ChaincodeStubInterface, entry *PEPEntry) error {
+	for _, prefix := range pepEntryPrefixes(entry) {
+		if err := m.deletePEPIndex(stub, "PEP_BY_NAME", prefix, entry.EntryID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (m *PEPListManager) putPEPIndex(stub shim.ChaincodeStubInterface, objectType, value, entryID string) error {
+	indexKey, err := stub.CreateCompositeKey(objectType, []string{value, entryID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := stub.PutState(indexKey, []byte(entryID)); err != nil {
+		return fmt.Errorf("failed to create %s index: %w", objectType, err)
+	}
+	return nil
+}
+
+func (m *PEPListManager) deletePEPIndex(stub shim.ChaincodeStubInterface, objectType, value, entryID string) error {
+	indexKey, err := stub.CreateCompositeKey(objectType, []string{value, entryID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := stub.DelState(indexKey); err != nil {
+		return fmt.Errorf("failed to remove %s index: %w", objectType, err)
+	}
+	return nil
+}
+
+// pepEntryPrefixes returns the name index keys of an entry's name and aliases
+func pepEntryPrefixes(entry *PEPEntry) []string {
+	seen := map[string]bool{}
+	prefixes := []string{}
+	for _, name := range append([]string{entry.Name}, entry.Aliases...) {
+		for _, prefix := range pepNamePrefixes(name) {
+			if !seen[prefix] {
+				seen[prefix] = true
+				prefixes = append(prefixes, prefix)
+			}
+		}
+	}
+	return prefixes
+}
+
+// pepNamePrefixes returns the leading characters of each token of a name, lowercased. Tokens
+// are cut by rune rather than byte so names outside ASCII still produce valid UTF-8 keys.
+func pepNamePrefixes(name string) []string {
+	prefixes := []string{}
+	for _, token := range strings.Fields(strings.ToLower(name)) {
+		runes := []rune(token)
+		if len(runes) > pepNamePrefixLength {
+			runes = runes[:pepNamePrefixLength]
+		}
+		prefixes = append(prefixes, string(runes))
+	}
+	return prefixes
+}
+
+// requireListMaintainer restricts PEP list changes to compliance officers
+func (m *PEPListManager) requireListMaintainer(stub shim.ChaincodeStubInterface) error {
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return err
+	}
+	if role != string(validation.ActorRoleComplianceOfficer) && role != string(validation.ActorRoleChiefComplianceOfficer) {
+		return services.NewChaincodeError(services.ErrCodeAccessDenied, "", "the PEP list may only be maintained by a %s or %s", validation.ActorRoleComplianceOfficer, validation.ActorRoleChiefComplianceOfficer)
+	}
+	return nil
+}
+
+func (m *PEPListManager) validatePEPEntryRequest(req *PEPEntryRequest) error {
+	if strings.TrimSpace(req.Name) == "" {
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "name", "name is required")
+	}
+	if strings.TrimSpace(req.Country) == "" {
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "country", "country is required")
+	}
+	if strings.TrimSpace(req.Position) == "" {
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "position", "position is required")
+	}
+	if strings.TrimSpace(req.Source) == "" {
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "source", "source is required")
+	}
+
+	switch RiskLevel(strings.ToUpper(req.RiskCategory)) {
+	case RiskLevelLow, RiskLevelMedium, RiskLevelHigh:
+	default:
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid risk category %q: expected LOW, MEDIUM or HIGH", req.RiskCategory)
+	}
+
+	return nil
+}
+
+func (m *PEPListManager) recordPEPListEvent(stub shim.ChaincodeStubInterface, eventType, entityID string, details map[string]interface{}, actorID string) error {
+	if m.eventEmitter == nil {
+		return nil // No event emitter configured
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return err
+	}
+
+	event := &domain.ComplianceEvent{
+		EventID:            services.GenerateDeterministicID(stub, config.EventPrefix),
+		Timestamp:          now,
+		RuleID:             "PEP_LIST_MANAGEMENT_RULE",
+		RuleVersion:        "1.0",
+		AffectedEntityID:   entityID,
+		AffectedEntityType: "PEPList",
+		EventType:          eventType,
+		Severity:           domain.PriorityMedium,
+		Details:            details,
+		ActorID:            actorID,
+		IsAlerted:          false,
+		ResolutionStatus:   "CLOSED",
+	}
+
+	return m.eventEmitter.EmitComplianceEvent(stub, event)
+}
@@ -1,30 +1,581 @@
 package handlers
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
 
 	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
 )
 
-// ReportGenerationHandler handles compliance report generation (placeholder implementation)
-type ReportGenerationHandler struct{}
+// RegulatoryReportType names an extract filed with the regulator for a reporting period
+type RegulatoryReportType string
+
+const (
+	// ReportTypeLargeTransaction lists the monitored transactions dated in the period at or above
+	// config.LargeTransactionReportThreshold (the currency transaction report)
+	ReportTypeLargeTransaction RegulatoryReportType = "LARGE_TRANSACTION"
+	// ReportTypeThresholdBreach lists the transaction monitoring alerts raised in the period
+	ReportTypeThresholdBreach RegulatoryReportType = "THRESHOLD_BREACH"
+)
+
+// regulatoryReportTypes are the reports a reporting period needs before it can be finalized
+var regulatoryReportTypes = []RegulatoryReportType{ReportTypeLargeTransaction, ReportTypeThresholdBreach}
+
+// Reporting period statuses
+const (
+	ReportingPeriodOpen      = "OPEN"
+	ReportingPeriodFinalized = "FINALIZED"
+)
+
+// reportingPeriodFormat is how reporting periods are named; a period is a calendar month in UTC
+const reportingPeriodFormat = "2006-01"
+
+// RegulatoryReportEntry references one ledger record included in a report
+type RegulatoryReportEntry struct {
+	RecordType      string                `json:"recordType"` // MONITORED_TRANSACTION or TRANSACTION_ALERT
+	RecordID        string                `json:"recordID"`
+	CustomerID      string                `json:"customerID"`
+	TransactionID   string                `json:"transactionID"`
+	Amount          float64               `json:"amount,omitempty"`
+	Currency        string                `json:"currency,omitempty"`
+	Direction       string                `json:"direction,omitempty"`
+	TransactionType string                `json:"transactionType,omitempty"`
+	Score           float64               `json:"score,omitempty"`
+	Typologies      []TransactionTypology `json:"typologies,omitempty"`
+	Date            time.Time             `json:"date"` // Transaction date, or when the alert was raised
+}
+
+// RegulatoryReport is an extract assembled for a reporting period. It is written once and never
+// changed. ContentHash is the hex SHA-256 of the entries encoded as a JSON array; ReportHash is the
+// hex SHA-256 of the report encoded as JSON with reportHash empty, and the report is signed by the
+// endorsements of the transaction that recorded it.
+type RegulatoryReport struct {
+	ReportID         string                  `json:"reportID"`
+	ReportType       RegulatoryReportType    `json:"reportType"`
+	Period           string                  `json:"period"`
+	PeriodStart      time.Time               `json:"periodStart"`
+	PeriodEnd        time.Time               `json:"periodEnd"` // Exclusive
+	Threshold        float64                 `json:"threshold,omitempty"`
+	Entries          []RegulatoryReportEntry `json:"entries"`
+	RecordCount      int                     `json:"recordCount"`
+	TotalsByCurrency map[string]float64      `json:"totalsByCurrency,omitempty"`
+	ContentHash      string                  `json:"contentHash"`
+	GeneratedBy      string                  `json:"generatedBy"`
+	GeneratedByMSP   string                  `json:"generatedByMSP"`
+	GeneratedDate    time.Time               `json:"generatedDate"`
+	TransactionID    string                  `json:"transactionID"`
+	ReportHash       string                  `json:"reportHash"`
+}
+
+// ReportingPeriod records the finalization of a period and the report of each type filed for it
+type ReportingPeriod struct {
+	Period         string                          `json:"period"`
+	Status         string                          `json:"status"`
+	Reports        map[RegulatoryReportType]string `json:"reports,omitempty"` // Report ID by type
+	FinalizedBy    string                          `json:"finalizedBy,omitempty"`
+	FinalizedByMSP string                          `json:"finalizedByMSP,omitempty"`
+	FinalizedDate  *time.Time                      `json:"finalizedDate,omitempty"`
+	TransactionID  string                          `json:"transactionID,omitempty"`
+}
+
+// RegulatoryReportRequest represents a request to assemble a report for a period (YYYY-MM)
+type RegulatoryReportRequest struct {
+	ReportType string `json:"reportType"`
+	Period     string `json:"period"`
+	ActorID    string `json:"actorID"`
+}
+
+// ReportingPeriodFinalizationRequest represents a request to lock a reporting period
+type ReportingPeriodFinalizationRequest struct {
+	Period  string `json:"period"`
+	ActorID string `json:"actorID"`
+}
+
+// ReportGenerationHandler assembles regulatory reporting extracts from transaction monitoring
+// records. Reports for a period may be generated again while it is open, as late transactions
+// arrive; finalizing the period fixes the report of each type filed for it and locks the period
+// against further reports and transactions.
+type ReportGenerationHandler struct {
+	persistenceService *services.PersistenceService
+}
 
 // NewReportGenerationHandler creates a new report generation handler
 func NewReportGenerationHandler() *ReportGenerationHandler {
-	return &ReportGenerationHandler{}
+	return &ReportGenerationHandler{
+		persistenceService: services.NewPersistenceService(),
+	}
 }
 
-// GenerateComplianceReport generates a compliance report (placeholder)
+// GenerateComplianceReport assembles a report of the given type for a reporting period that has
+// started and is not finalized, and records it
 func (h *ReportGenerationHandler) GenerateComplianceReport(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
-	return json.Marshal(map[string]string{"message": "Compliance report generation functionality to be implemented"})
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req RegulatoryReportRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse regulatory report request: %v", err)
+	}
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return nil, err
+	}
+	if role != string(validation.ActorRoleComplianceOfficer) && role != string(validation.ActorRoleChiefComplianceOfficer) {
+		return nil, services.NewChaincodeError(services.ErrCodeAccessDenied, "", "regulatory reports may only be generated by a %s or %s", validation.ActorRoleComplianceOfficer, validation.ActorRoleChiefComplianceOfficer)
+	}
+	reportType, err := parseRegulatoryReportType(req.ReportType)
+	if err != nil {
+		return nil, err
+	}
+	periodStart, err := parseReportingPeriod(req.Period)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	if now.Before(periodStart) {
+		return nil, fmt.Errorf("reporting period %s has not started", req.Period)
+	}
+	period, err := h.reportingPeriod(stub, req.Period)
+	if err != nil {
+		return nil, err
+	}
+	if period.Status == ReportingPeriodFinalized {
+		return nil, fmt.Errorf("reporting period %s is finalized", req.Period)
+	}
+	mspID, err := services.InvokerMSPID(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	report, err := h.assembleReport(stub, reportType, req.Period, periodStart)
+	if err != nil {
+		return nil, err
+	}
+	report.ReportID = services.GenerateDeterministicID(stub, config.ComplianceReportPrefix)
+	report.GeneratedBy = req.ActorID
+	report.GeneratedByMSP = mspID
+	report.GeneratedDate = now
+	report.TransactionID = stub.GetTxID()
+	if report.ReportHash, err = hashRegulatoryReport(*report); err != nil {
+		return nil, err
+	}
+
+	reportKey, err := stub.CreateCompositeKey(config.ComplianceReportPrefix, []string{report.ReportID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create report key: %w", err)
+	}
+	if err := h.persistenceService.Put(stub, reportKey, report); err != nil {
+		return nil, fmt.Errorf("failed to store regulatory report: %w", err)
+	}
+	indexKey, err := stub.CreateCompositeKey(config.ComplianceReportIndexPrefix, []string{string(report.ReportType), report.Period, report.ReportID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create report index key: %w", err)
+	}
+	if err := stub.PutState(indexKey, []byte(report.ReportID)); err != nil {
+		return nil, fmt.Errorf("failed to index regulatory report: %w", err)
+	}
+
+	return json.Marshal(report)
 }
 
-// GetComplianceReport retrieves a compliance report (placeholder)
+// GetComplianceReport returns a regulatory report with its included record references
 func (h *ReportGenerationHandler) GetComplianceReport(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
-	return json.Marshal(map[string]string{"message": "Compliance report retrieval functionality to be implemented"})
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+	if err := requireReportReader(stub); err != nil {
+		return nil, err
+	}
+
+	report, err := h.getReport(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(report)
 }
 
-// QueryReportsByType queries reports by type (placeholder)
+// QueryReportsByType returns the reports of a type, optionally for one period, oldest first
 func (h *ReportGenerationHandler) QueryReportsByType(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
-	return json.Marshal(map[string]string{"message": "Report query functionality to be implemented"})
-}
\ No newline at end of file
+	if len(args) < 1 || len(args) > 2 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1 or 2, got %d", len(args))
+	}
+	if err := requireReportReader(stub); err != nil {
+		return nil, err
+	}
+	reportType, err := parseRegulatoryReportType(args[0])
+	if err != nil {
+		return nil, err
+	}
+	attributes := []string{string(reportType)}
+	if len(args) == 2 {
+		if _, err := parseReportingPeriod(args[1]); err != nil {
+			return nil, err
+		}
+		attributes = append(attributes, args[1])
+	}
+
+	reports, err := h.reportsByType(stub, attributes)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(reports)
+}
+
+// FinalizeReportingPeriod locks a period that has ended. Each report type must have been generated
+// for the period, and the latest report of each type must still match the ledger; that report is
+// the one filed for the period.
+func (h *ReportGenerationHandler) FinalizeReportingPeriod(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req ReportingPeriodFinalizationRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse reporting period finalization request: %v", err)
+	}
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return nil, err
+	}
+	if role != string(validation.ActorRoleChiefComplianceOfficer) {
+		return nil, services.NewChaincodeError(services.ErrCodeAccessDenied, "", "reporting periods may only be finalized by a %s", validation.ActorRoleChiefComplianceOfficer)
+	}
+	periodStart, err := parseReportingPeriod(req.Period)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	if now.Before(periodStart.AddDate(0, 1, 0)) {
+		return nil, fmt.Errorf("reporting period %s has not ended", req.Period)
+	}
+	period, err := h.reportingPeriod(stub, req.Period)
+	if err != nil {
+		return nil, err
+	}
+	if period.Status == ReportingPeriodFinalized {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "reporting period %s is already finalized", req.Period)
+	}
+	mspID, err := services.InvokerMSPID(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	period.Reports = map[RegulatoryReportType]string{}
+	for _, reportType := range regulatoryReportTypes {
+		reports, err := h.reportsByType(stub, []string{string(reportType), req.Period})
+		if err != nil {
+			return nil, err
+		}
+		if len(reports) == 0 {
+			return nil, fmt.Errorf("no %s report has been generated for %s", reportType, req.Period)
+		}
+		latest := reports[len(reports)-1]
+		current, err := h.assembleReport(stub, reportType, req.Period, periodStart)
+		if err != nil {
+			return nil, err
+		}
+		if current.ContentHash != latest.ContentHash {
+			return nil, fmt.Errorf("%s report %s no longer matches the ledger; generate it again before finalizing", reportType, latest.ReportID)
+		}
+		period.Reports[reportType] = latest.ReportID
+	}
+
+	period.Status = ReportingPeriodFinalized
+	period.FinalizedBy = req.ActorID
+	period.FinalizedByMSP = mspID
+	period.FinalizedDate = &now
+	period.TransactionID = stub.GetTxID()
+
+	periodKey, err := stub.CreateCompositeKey(config.ReportingPeriodPrefix, []string{period.Period})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create reporting period key: %w", err)
+	}
+	if err := h.persistenceService.Put(stub, periodKey, period); err != nil {
+		return nil, fmt.Errorf("failed to store reporting period: %w", err)
+	}
+
+	return json.Marshal(period)
+}
+
+// GetReportingPeriod returns a reporting period's status and, once finalized, its filed reports
+func (h *ReportGenerationHandler) GetReportingPeriod(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+	if err := requireReportReader(stub); err != nil {
+		return nil, err
+	}
+	if _, err := parseReportingPeriod(args[0]); err != nil {
+		return nil, err
+	}
+
+	period, err := h.reportingPeriod(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(period)
+}
+
+// Helper methods
+
+// assembleReport collects a report's entries for a period from the ledger, in key order
+func (h *ReportGenerationHandler) assembleReport(stub shim.ChaincodeStubInterface, reportType RegulatoryReportType, period string, periodStart time.Time) (*RegulatoryReport, error) {
+	periodEnd := periodStart.AddDate(0, 1, 0)
+	report := &RegulatoryReport{
+		ReportType:  reportType,
+		Period:      period,
+		PeriodStart: periodStart,
+		PeriodEnd:   periodEnd,
+		Entries:     []RegulatoryReportEntry{},
+	}
+
+	var err error
+	switch reportType {
+	case ReportTypeLargeTransaction:
+		report.Threshold = config.LargeTransactionReportThreshold
+		report.Entries, err = largeTransactionEntries(stub, periodStart, periodEnd)
+		if err != nil {
+			return nil, err
+		}
+		// Totals are summed in each currency's minor units so they match the entries exactly
+		totals := map[string]int64{}
+		for _, entry := range report.Entries {
+			totals[entry.Currency] += utils.NewMoney(entry.Amount, entry.Currency).MinorUnits()
+		}
+		report.TotalsByCurrency = map[string]float64{}
+		for currency, minorUnits := range totals {
+			report.TotalsByCurrency[currency] = utils.MoneyFromMinorUnits(minorUnits, currency).Float64()
+		}
+	case ReportTypeThresholdBreach:
+		report.Entries, err = h.thresholdBreachEntries(stub, periodStart, periodEnd)
+		if err != nil {
+			return nil, err
+		}
+	}
+	report.RecordCount = len(report.Entries)
+
+	entriesBytes, err := json.Marshal(report.Entries)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode report entries: %w", err)
+	}
+	contentHash := sha256.Sum256(entriesBytes)
+	report.ContentHash = hex.EncodeToString(contentHash[:])
+
+	return report, nil
+}
+
+// largeTransactionEntries returns the monitored transactions dated in [start, end) at or above the
+// reporting threshold
+func largeTransactionEntries(stub shim.ChaincodeStubInterface, start, end time.Time) ([]RegulatoryReportEntry, error) {
+	iterator, err := stub.GetStateByPartialCompositeKey(config.MonitoredTransactionPrefix, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get monitored transactions: %w", err)
+	}
+	defer iterator.Close()
+
+	entries := []RegulatoryReportEntry{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate monitored transactions: %w", err)
+		}
+		var txn MonitoredTransaction
+		if err := json.Unmarshal(response.Value, &txn); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal monitored transaction: %w", err)
+		}
+		if txn.TransactionDate.Before(start) || !txn.TransactionDate.Before(end) || txn.Amount < config.LargeTransactionReportThreshold {
+			continue
+		}
+		entries = append(entries, RegulatoryReportEntry{
+			RecordType:      "MONITORED_TRANSACTION",
+			RecordID:        txn.TransactionID,
+			CustomerID:      txn.CustomerID,
+			TransactionID:   txn.TransactionID,
+			Amount:          txn.Amount,
+			Currency:        txn.Currency,
+			Direction:       txn.Direction,
+			TransactionType: txn.TransactionType,
+			Date:            txn.TransactionDate.UTC(),
+		})
+	}
+	return entries, nil
+}
+
+// thresholdBreachEntries returns the transaction monitoring alerts raised in [start, end)
+func (h *ReportGenerationHandler) thresholdBreachEntries(stub shim.ChaincodeStubInterface, start, end time.Time) ([]RegulatoryReportEntry, error) {
+	iterator, err := stub.GetStateByPartialCompositeKey("CUSTOMER_TXN_ALERT", []string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get transaction alerts: %w", err)
+	}
+	defer iterator.Close()
+
+	entries := []RegulatoryReportEntry{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate transaction alerts: %w", err)
+		}
+		var alert TransactionAlert
+		if err := h.persistenceService.Get(stub, transactionAlertKey(string(response.Value)), &alert); err != nil {
+			return nil, fmt.Errorf("failed to read transaction alert %s: %w", string(response.Value), err)
+		}
+		if alert.CreatedDate.Before(start) || !alert.CreatedDate.Before(end) {
+			continue
+		}
+		typologies := []TransactionTypology{}
+		for _, hit := range alert.Hits {
+			typologies = append(typologies, hit.Typology)
+		}
+		entries = append(entries, RegulatoryReportEntry{
+			RecordType:    "TRANSACTION_ALERT",
+			RecordID:      alert.AlertID,
+			CustomerID:    alert.CustomerID,
+			TransactionID: alert.TransactionID,
+			Score:         alert.Score,
+			Typologies:    typologies,
+			Date:          alert.CreatedDate.UTC(),
+		})
+	}
+	return entries, nil
+}
+
+// reportsByType returns the reports indexed under a type and optionally a period, oldest first
+func (h *ReportGenerationHandler) reportsByType(stub shim.ChaincodeStubInterface, attributes []string) ([]RegulatoryReport, error) {
+	iterator, err := stub.GetStateByPartialCompositeKey(config.ComplianceReportIndexPrefix, attributes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get regulatory reports: %w", err)
+	}
+	defer iterator.Close()
+
+	reports := []RegulatoryReport{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate regulatory reports: %w", err)
+		}
+		report, err := h.getReport(stub, string(response.Value))
+		if err != nil {
+			return nil, err
+		}
+		reports = append(reports, *report)
+	}
+	sort.SliceStable(reports, func(i, j int) bool {
+		if !reports[i].GeneratedDate.Equal(reports[j].GeneratedDate) {
+			return reports[i].GeneratedDate.Before(reports[j].GeneratedDate)
+		}
+		return reports[i].ReportID < reports[j].ReportID
+	})
+	return reports, nil
+}
+
+func (h *ReportGenerationHandler) getReport(stub shim.ChaincodeStubInterface, reportID string) (*RegulatoryReport, error) {
+	reportKey, err := stub.CreateCompositeKey(config.ComplianceReportPrefix, []string{reportID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create report key: %w", err)
+	}
+	var report RegulatoryReport
+	if err := h.persistenceService.Get(stub, reportKey, &report); err != nil {
+		return nil, fmt.Errorf("regulatory report %s not found: %w", reportID, err)
+	}
+	return &report, nil
+}
+
+// reportingPeriod returns a period's record, or an open period when it has not been finalized
+func (h *ReportGenerationHandler) reportingPeriod(stub shim.ChaincodeStubInterface, period string) (*ReportingPeriod, error) {
+	periodKey, err := stub.CreateCompositeKey(config.ReportingPeriodPrefix, []string{period})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create reporting period key: %w", err)
+	}
+	periodBytes, err := stub.GetState(periodKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read reporting period: %w", err)
+	}
+	if periodBytes == nil {
+		return &ReportingPeriod{Period: period, Status: ReportingPeriodOpen}, nil
+	}
+
+	var record ReportingPeriod
+	if err := json.Unmarshal(periodBytes, &record); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal reporting period: %w", err)
+	}
+	return &record, nil
+}
+
+// reportingPeriodFinalized reports whether the period containing a time has been finalized
+func reportingPeriodFinalized(stub shim.ChaincodeStubInterface, at time.Time) (bool, error) {
+	periodKey, err := stub.CreateCompositeKey(config.ReportingPeriodPrefix, []string{at.UTC().Format(reportingPeriodFormat)})
+	if err != nil {
+		return false, fmt.Errorf("failed to create reporting period key: %w", err)
+	}
+	periodBytes, err := stub.GetState(periodKey)
+	if err != nil {
+		return false, fmt.Errorf("failed to read reporting period: %w", err)
+	}
+	return periodBytes != nil, nil
+}
+
+// requireReportReader allows compliance officers and regulators to read regulatory reports
+func requireReportReader(stub shim.ChaincodeStubInterface) error {
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return err
+	}
+	switch validation.ActorRole(role) {
+	case validation.ActorRoleComplianceOfficer, validation.ActorRoleChiefComplianceOfficer, validation.ActorRoleRegulator:
+		return nil
+	}
+	return services.NewChaincodeError(services.ErrCodeAccessDenied, "", "regulatory reports may only be read by a %s, %s or %s", validation.ActorRoleComplianceOfficer, validation.ActorRoleChiefComplianceOfficer, validation.ActorRoleRegulator)
+}
+
+func parseRegulatoryReportType(value string) (RegulatoryReportType, error) {
+	reportType := RegulatoryReportType(strings.ToUpper(strings.TrimSpace(value)))
+	for _, known := range regulatoryReportTypes {
+		if reportType == known {
+			return reportType, nil
+		}
+	}
+	return "", services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid report type: %s", value)
+}
+
+// parseReportingPeriod returns the start of a YYYY-MM reporting period
+func parseReportingPeriod(period string) (time.Time, error) {
+	start, err := time.Parse(reportingPeriodFormat, period)
+	if err != nil {
+		return time.Time{}, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "period must be YYYY-MM, got %s", period)
+	}
+	return start, nil
+}
+
+func hashRegulatoryReport(report RegulatoryReport) (string, error) {
+	report.ReportHash = ""
+	reportBytes, err := json.Marshal(report)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode regulatory report: %w", err)
+	}
+	hash := sha256.Sum256(reportBytes)
+	return hex.EncodeToString(hash[:]), nil
+}
@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+func TestReportGenerationHandler_PeriodFinalization(t *testing.T) {
+	may := time.Date(2026, 5, 20, 12, 0, 0, 0, time.UTC)
+	restore := services.SetClock(services.NewFixedClock(may))
+	stub := shimtest.NewMockStub("report_generation_test", nil)
+	handler := NewReportGenerationHandler()
+	monitoring := NewTransactionMonitoringHandler(&MockEventEmitter{})
+
+	invoke := func(txID string, fn func(args []string) ([]byte, error), request interface{}) ([]byte, error) {
+		requestBytes, err := json.Marshal(request)
+		require.NoError(t, err)
+		stub.MockTransactionStart(txID)
+		defer stub.MockTransactionEnd(txID)
+		return fn([]string{string(requestBytes)})
+	}
+	ingest := func(args []string) ([]byte, error) { return monitoring.IngestTransactions(stub, args) }
+	generate := func(args []string) ([]byte, error) { return handler.GenerateComplianceReport(stub, args) }
+	finalize := func(args []string) ([]byte, error) { return handler.FinalizeReportingPeriod(stub, args) }
+	reportOf := func(reportBytes []byte, err error) *RegulatoryReport {
+		require.NoError(t, err)
+		var report RegulatoryReport
+		require.NoError(t, json.Unmarshal(reportBytes, &report))
+		return &report
+	}
+	txn := func(id, customerID string, amount float64, date time.Time) TransactionSummary {
+		return TransactionSummary{TransactionID: id, CustomerID: customerID, Amount: amount, Currency: "USD", Direction: "CREDIT", TransactionType: "CASH", TransactionDate: date}
+	}
+
+	// One large cash deposit, and three just under the threshold that trip the structuring typology
+	stub.Creator = newRoleIdentity(t, "Compliance_Officer")
+	_, err := invoke("ingest_1", ingest, TransactionIngestRequest{
+		Transactions: []TransactionSummary{
+			txn("T0", "CUST_1", 15000, time.Date(2026, 4, 30, 9, 0, 0, 0, time.UTC)),
+			txn("T1", "CUST_1", 12500, time.Date(2026, 5, 10, 9, 0, 0, 0, time.UTC)),
+			txn("T2", "CUST_2", 9100, time.Date(2026, 5, 18, 9, 0, 0, 0, time.UTC)),
+			txn("T3", "CUST_2", 9300, time.Date(2026, 5, 19, 9, 0, 0, 0, time.UTC)),
+			txn("T4", "CUST_2", 9700, time.Date(2026, 5, 20, 9, 0, 0, 0, time.UTC)),
+		},
+		ActorID: "CORE_BANKING",
+	})
+	require.NoError(t, err)
+	restore()
+
+	now := time.Date(2026, 6, 2, 9, 0, 0, 0, time.UTC)
+	clock := services.NewFixedClock(now)
+	defer services.SetClock(clock)()
+
+	stub.Creator = newRoleIdentity(t, "Underwriter")
+	_, err = invoke("generate_1", generate, RegulatoryReportRequest{ReportType: "LARGE_TRANSACTION", Period: "2026-05", ActorID: "ACTOR_001"})
+	assert.Contains(t, err.Error(), "may only be generated by")
+
+	stub.Creator = newRoleIdentity(t, "Compliance_Officer")
+	_, err = invoke("generate_2", generate, RegulatoryReportRequest{ReportType: "LARGE_TRANSACTION", Period: "May 2026", ActorID: "ACTOR_001"})
+	assert.Contains(t, err.Error(), "period must be YYYY-MM")
+	_, err = invoke("generate_3", generate, RegulatoryReportRequest{ReportType: "LARGE_TRANSACTION", Period: "2026-07", ActorID: "ACTOR_001"})
+	assert.Contains(t, err.Error(), "has not started")
+
+	large := reportOf(invoke("generate_4", generate, RegulatoryReportRequest{ReportType: "large_transaction", Period: "2026-05", ActorID: "ACTOR_001"}))
+	assert.Equal(t, ReportTypeLargeTransaction, large.ReportType)
+	assert.Equal(t, 10000.0, large.Threshold)
+	require.Len(t, large.Entries, 1)
+	assert.Equal(t, "T1", large.Entries[0].TransactionID)
+	assert.Equal(t, map[string]float64{"USD": 12500}, large.TotalsByCurrency)
+	assert.Equal(t, "Org1MSP", large.GeneratedByMSP)
+	reportHash, err := hashRegulatoryReport(*large)
+	require.NoError(t, err)
+	assert.Equal(t, reportHash, large.ReportHash)
+
+	// Regulators read reports; other roles do not
+	stub.Creator = newRoleIdentity(t, "Underwriter")
+	stub.MockTransactionStart("get_1")
+	_, err = handler.GetComplianceReport(stub, []string{large.ReportID})
+	stub.MockTransactionEnd("get_1")
+	assert.Contains(t, err.Error(), "may only be read by")
+	stub.Creator = newRoleIdentity(t, "Regulator")
+	stub.MockTransactionStart("get_2")
+	stored := reportOf(handler.GetComplianceReport(stub, []string{large.ReportID}))
+	stub.MockTransactionEnd("get_2")
+	assert.Equal(t, large, stored)
+
+	stub.Creator = newRoleIdentity(t, "Compliance_Officer")
+	_, err = invoke("finalize_1", finalize, ReportingPeriodFinalizationRequest{Period: "2026-05", ActorID: "ACTOR_002"})
+	assert.Contains(t, err.Error(), "may only be finalized by")
+	stub.Creator = newRoleIdentity(t, "Chief_Compliance_Officer")
+	_, err = invoke("finalize_2", finalize, ReportingPeriodFinalizationRequest{Period: "2026-06", ActorID: "ACTOR_002"})
+	assert.Contains(t, err.Error(), "has not ended")
+	_, err = invoke("finalize_3", finalize, ReportingPeriodFinalizationRequest{Period: "2026-05", ActorID: "ACTOR_002"})
+	assert.Contains(t, err.Error(), "no THRESHOLD_BREACH report")
+
+	breaches := reportOf(invoke("generate_5", generate, RegulatoryReportRequest{ReportType: "THRESHOLD_BREACH", Period: "2026-05", ActorID: "ACTOR_002"}))
+	require.NotEmpty(t, breaches.Entries)
+	for _, entry := range breaches.Entries {
+		assert.Equal(t, "CUST_2", entry.CustomerID)
+	}
+	assert.Equal(t, "T4", breaches.Entries[len(breaches.Entries)-1].TransactionID)
+	assert.Contains(t, breaches.Entries[len(breaches.Entries)-1].Typologies, TypologyStructuring)
+
+	// A late transaction makes the filed report stale until it is generated again
+	clock.Advance(time.Hour)
+	_, err = invoke("ingest_2", ingest, TransactionIngestRequest{Transactions: []TransactionSummary{txn("T5", "CUST_3", 20000, time.Date(2026, 5, 31, 16, 0, 0, 0, time.UTC))}, ActorID: "CORE_BANKING"})
+	require.NoError(t, err)
+	_, err = invoke("finalize_4", finalize, ReportingPeriodFinalizationRequest{Period: "2026-05", ActorID: "ACTOR_002"})
+	assert.Contains(t, err.Error(), "no longer matches the ledger")
+
+	regenerated := reportOf(invoke("generate_6", generate, RegulatoryReportRequest{ReportType: "LARGE_TRANSACTION", Period: "2026-05", ActorID: "ACTOR_002"}))
+	assert.Len(t, regenerated.Entries, 2)
+	periodBytes, err := invoke("finalize_5", finalize, ReportingPeriodFinalizationRequest{Period: "2026-05", ActorID: "ACTOR_002"})
+	require.NoError(t, err)
+	var period ReportingPeriod
+	require.NoError(t, json.Unmarshal(periodBytes, &period))
+	assert.Equal(t, ReportingPeriodFinalized, period.Status)
+	assert.Equal(t, map[RegulatoryReportType]string{ReportTypeLargeTransaction: regenerated.ReportID, ReportTypeThresholdBreach: breaches.ReportID}, period.Reports)
+
+	// The period is locked
+	_, err = invoke("finalize_6", finalize, ReportingPeriodFinalizationRequest{Period: "2026-05", ActorID: "ACTOR_002"})
+	assert.Contains(t, err.Error(), "already finalized")
+	_, err = invoke("generate_7", generate, RegulatoryReportRequest{ReportType: "LARGE_TRANSACTION", Period: "2026-05", ActorID: "ACTOR_002"})
+	assert.Contains(t, err.Error(), "reporting period 2026-05 is finalized")
+	_, err = invoke("ingest_3", ingest, TransactionIngestRequest{Transactions: []TransactionSummary{txn("T6", "CUST_3", 500, time.Date(2026, 5, 31, 17, 0, 0, 0, time.UTC))}, ActorID: "CORE_BANKING"})
+	assert.Contains(t, err.Error(), "reporting period 2026-05 is finalized")
+	_, err = invoke("ingest_4", ingest, TransactionIngestRequest{Transactions: []TransactionSummary{txn("T7", "CUST_3", 500, time.Date(2026, 6, 1, 17, 0, 0, 0, time.UTC))}, ActorID: "CORE_BANKING"})
+	require.NoError(t, err)
+
+	stub.Creator = newRoleIdentity(t, "Regulator")
+	stub.MockTransactionStart("query")
+	reportsBytes, err := handler.QueryReportsByType(stub, []string{"LARGE_TRANSACTION", "2026-05"})
+	stub.MockTransactionEnd("query")
+	require.NoError(t, err)
+	var reports []RegulatoryReport
+	require.NoError(t, json.Unmarshal(reportsBytes, &reports))
+	require.Len(t, reports, 2)
+	assert.Equal(t, large.ReportID, reports[0].ReportID)
+	assert.Equal(t, regenerated.ReportID, reports[1].ReportID)
+
+	stub.MockTransactionStart("period")
+	periodBytes, err = handler.GetReportingPeriod(stub, []string{"2026-05"})
+	stub.MockTransactionEnd("period")
+	require.NoError(t, err)
+	var filed ReportingPeriod
+	require.NoError(t, json.Unmarshal(periodBytes, &filed))
+	assert.Equal(t, "ACTOR_002", filed.FinalizedBy)
+}
@@ -0,0 +1,370 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// RiskCatalogType names a catalog of customer profile risks
+type RiskCatalogType string
+
+const (
+	RiskCatalogOccupation RiskCatalogType = "OCCUPATION"
+	RiskCatalogIndustry   RiskCatalogType = "INDUSTRY"
+)
+
+// Catalog named by the references on geographic risk factors
+const riskCatalogCountry = "COUNTRY_RISK"
+
+// riskCatalogVersionFormat keeps an entry's versions in key order
+const riskCatalogVersionFormat = "%06d"
+
+// defaultRiskCatalogs apply, as version 0, to codes compliance has not catalogued on the ledger
+var defaultRiskCatalogs = map[RiskCatalogType]map[string]float64{
+	RiskCatalogOccupation: {
+		"POLITICIAN":    80,
+		"ARMS_DEALER":   95,
+		"CASINO_OWNER":  70,
+		"MONEY_CHANGER": 65,
+		"DIPLOMAT":      60,
+	},
+	RiskCatalogIndustry: {},
+}
+
+// RiskCatalogHandler maintains the occupation and industry risk catalogs. Every change is a new
+// version of the entry taking effect on its effective date, so the version behind any score can be
+// looked up later.
+type RiskCatalogHandler struct {
+	persistenceService *services.PersistenceService
+}
+
+// NewRiskCatalogHandler creates a new risk catalog handler
+func NewRiskCatalogHandler() *RiskCatalogHandler {
+	return &RiskCatalogHandler{
+		persistenceService: services.NewPersistenceService(),
+	}
+}
+
+// RiskCatalogEntry is one version of an occupation or industry's risk. Retired versions carry no
+// risk. Version 0 is a built-in default.
+type RiskCatalogEntry struct {
+	CatalogType   RiskCatalogType `json:"catalogType"`
+	Code          string          `json:"code"`
+	Description   string          `json:"description,omitempty"`
+	RiskScore     float64         `json:"riskScore"` // 0 to 100
+	Version       int             `json:"version"`
+	EffectiveDate time.Time       `json:"effectiveDate"`
+	IsActive      bool            `json:"isActive"`
+	Reason        string          `json:"reason,omitempty"`
+	UpdatedBy     string          `json:"updatedBy,omitempty"`
+	UpdatedDate   time.Time       `json:"updatedDate"`
+}
+
+// RiskCatalogEntryRequest represents a request to create, update or retire a catalog entry.
+// EffectiveDate is an RFC 3339 timestamp or a date meaning the start of that UTC day; it defaults
+// to the transaction time and may be in the future.
+type RiskCatalogEntryRequest struct {
+	CatalogType   string  `json:"catalogType"`
+	Code          string  `json:"code"`
+	Description   string  `json:"description,omitempty"`
+	RiskScore     float64 `json:"riskScore"`
+	EffectiveDate string  `json:"effectiveDate,omitempty"`
+	Reason        string  `json:"reason"`
+	ActorID       string  `json:"actorID"`
+}
+
+// RiskCatalogReference records the catalog version a risk factor was scored from
+type RiskCatalogReference struct {
+	Catalog       string    `json:"catalog"` // OCCUPATION, INDUSTRY or COUNTRY_RISK
+	Code          string    `json:"code"`
+	Version       int       `json:"version"`
+	EffectiveDate time.Time `json:"effectiveDate,omitempty"`
+}
+
+// CreateRiskCatalogEntry adds an occupation or industry to its catalog, or brings back a retired one
+func (h *RiskCatalogHandler) CreateRiskCatalogEntry(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	return h.putVersion(stub, args, func(latest *RiskCatalogEntry, req *RiskCatalogEntryRequest) error {
+		if latest != nil && latest.IsActive {
+			return services.NewChaincodeError(services.ErrCodeConflict, "", "%s %s is already catalogued", latest.CatalogType, latest.Code)
+		}
+		return nil
+	}, true)
+}
+
+// UpdateRiskCatalogEntry adds a version of a catalogued occupation or industry
+func (h *RiskCatalogHandler) UpdateRiskCatalogEntry(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	return h.putVersion(stub, args, func(latest *RiskCatalogEntry, req *RiskCatalogEntryRequest) error {
+		if latest == nil || !latest.IsActive {
+			return fmt.Errorf("%s %s is not catalogued", strings.ToUpper(req.CatalogType), req.Code)
+		}
+		return nil
+	}, true)
+}
+
+// RetireRiskCatalogEntry adds a version that takes an occupation or industry out of the catalog
+func (h *RiskCatalogHandler) RetireRiskCatalogEntry(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	return h.putVersion(stub, args, func(latest *RiskCatalogEntry, req *RiskCatalogEntryRequest) error {
+		if latest == nil || !latest.IsActive {
+			return fmt.Errorf("%s %s is not catalogued", strings.ToUpper(req.CatalogType), req.Code)
+		}
+		return nil
+	}, false)
+}
+
+// GetRiskCatalogEntry returns every version of a catalog entry, oldest first, including the
+// built-in default it replaced, if any
+func (h *RiskCatalogHandler) GetRiskCatalogEntry(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 2, got %d", len(args))
+	}
+
+	catalogType, err := parseRiskCatalogType(args[0])
+	if err != nil {
+		return nil, err
+	}
+	versions, err := riskCatalogVersions(stub, catalogType, normalizeCatalogCode(args[1]))
+	if err != nil {
+		return nil, err
+	}
+	if len(versions) == 0 {
+		return nil, fmt.Errorf("%s %s is not catalogued", catalogType, normalizeCatalogCode(args[1]))
+	}
+
+	return json.Marshal(versions)
+}
+
+// GetRiskCatalog returns the entries of a catalog in force at asOf, an optional RFC 3339
+// timestamp or date defaulting to the transaction time, by code
+func (h *RiskCatalogHandler) GetRiskCatalog(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1 to 2, got %d", len(args))
+	}
+
+	catalogType, err := parseRiskCatalogType(args[0])
+	if err != nil {
+		return nil, err
+	}
+	asOf, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) == 2 && args[1] != "" {
+		if asOf, err = parseCatalogDate(args[1]); err != nil {
+			return nil, err
+		}
+	}
+
+	codes := map[string]bool{}
+	for code := range defaultRiskCatalogs[catalogType] {
+		codes[code] = true
+	}
+	iterator, err := stub.GetStateByPartialCompositeKey(config.RiskCatalogEntryPrefix, []string{string(catalogType)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get risk catalog: %w", err)
+	}
+	defer iterator.Close()
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate risk catalog: %w", err)
+		}
+		_, attributes, err := stub.SplitCompositeKey(response.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split risk catalog key: %w", err)
+		}
+		codes[attributes[1]] = true
+	}
+
+	entries := []RiskCatalogEntry{}
+	for code := range codes {
+		entry, err := riskCatalogEntryInForce(stub, catalogType, code, asOf)
+		if err != nil {
+			return nil, err
+		}
+		if entry != nil && entry.IsActive {
+			entries = append(entries, *entry)
+		}
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].Code < entries[j].Code })
+
+	return json.Marshal(entries)
+}
+
+// putVersion validates a request and stores it as the entry's next version
+func (h *RiskCatalogHandler) putVersion(stub shim.ChaincodeStubInterface, args []string, check func(*RiskCatalogEntry, *RiskCatalogEntryRequest) error, active bool) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req RiskCatalogEntryRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse risk catalog entry request: %v", err)
+	}
+	if err := h.requireCatalogMaintainer(stub); err != nil {
+		return nil, err
+	}
+	catalogType, err := parseRiskCatalogType(req.CatalogType)
+	if err != nil {
+		return nil, err
+	}
+	req.Code = normalizeCatalogCode(req.Code)
+	if req.Code == "" || strings.ContainsRune(req.Code, 0) {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid code %q", req.Code)
+	}
+	if active && (req.RiskScore <= 0 || req.RiskScore > 100) {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "riskScore must be above 0 and at most 100, got %.2f", req.RiskScore)
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "reason", "reason is required")
+	}
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	effectiveDate := now
+	if req.EffectiveDate != "" {
+		if effectiveDate, err = parseCatalogDate(req.EffectiveDate); err != nil {
+			return nil, err
+		}
+	}
+
+	versions, err := riskCatalogVersions(stub, catalogType, req.Code)
+	if err != nil {
+		return nil, err
+	}
+	var latest *RiskCatalogEntry
+	if len(versions) > 0 {
+		latest = &versions[len(versions)-1]
+	}
+	if err := check(latest, &req); err != nil {
+		return nil, err
+	}
+
+	entry := &RiskCatalogEntry{
+		CatalogType:   catalogType,
+		Code:          req.Code,
+		Description:   req.Description,
+		RiskScore:     req.RiskScore,
+		Version:       1,
+		EffectiveDate: effectiveDate,
+		IsActive:      active,
+		Reason:        req.Reason,
+		UpdatedBy:     req.ActorID,
+		UpdatedDate:   now,
+	}
+	if latest != nil {
+		if latest.Version > 0 && effectiveDate.Before(latest.EffectiveDate) {
+			return nil, fmt.Errorf("effective date %s precedes version %d's effective date %s", effectiveDate.Format(time.RFC3339), latest.Version, latest.EffectiveDate.Format(time.RFC3339))
+		}
+		entry.Version = latest.Version + 1
+		if entry.Description == "" {
+			entry.Description = latest.Description
+		}
+	}
+	if !active {
+		entry.RiskScore = 0
+	}
+
+	entryKey, err := stub.CreateCompositeKey(config.RiskCatalogEntryPrefix, []string{string(catalogType), entry.Code, fmt.Sprintf(riskCatalogVersionFormat, entry.Version)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create risk catalog entry key: %w", err)
+	}
+	if err := h.persistenceService.Put(stub, entryKey, entry); err != nil {
+		return nil, fmt.Errorf("failed to store risk catalog entry: %w", err)
+	}
+
+	return json.Marshal(entry)
+}
+
+// requireCatalogMaintainer allows compliance officers only to change the risk catalogs
+func (h *RiskCatalogHandler) requireCatalogMaintainer(stub shim.ChaincodeStubInterface) error {
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return err
+	}
+	if role != string(validation.ActorRoleComplianceOfficer) && role != string(validation.ActorRoleChiefComplianceOfficer) {
+		return services.NewChaincodeError(services.ErrCodeAccessDenied, "", "risk catalogs may only be maintained by a %s or %s", validation.ActorRoleComplianceOfficer, validation.ActorRoleChiefComplianceOfficer)
+	}
+	return nil
+}
+
+// riskCatalogVersions returns an entry's versions, oldest first, starting with its built-in
+// default, if any
+func riskCatalogVersions(stub shim.ChaincodeStubInterface, catalogType RiskCatalogType, code string) ([]RiskCatalogEntry, error) {
+	versions := []RiskCatalogEntry{}
+	if riskScore, ok := defaultRiskCatalogs[catalogType][code]; ok {
+		versions = append(versions, RiskCatalogEntry{CatalogType: catalogType, Code: code, RiskScore: riskScore, IsActive: true})
+	}
+
+	iterator, err := stub.GetStateByPartialCompositeKey(config.RiskCatalogEntryPrefix, []string{string(catalogType), code})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get risk catalog entry: %w", err)
+	}
+	defer iterator.Close()
+
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate risk catalog entry: %w", err)
+		}
+		var entry RiskCatalogEntry
+		if err := json.Unmarshal(response.Value, &entry); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal risk catalog entry: %w", err)
+		}
+		versions = append(versions, entry)
+	}
+	return versions, nil
+}
+
+// riskCatalogEntryInForce returns the latest version of an entry in effect at a time, or nil when
+// the code is not catalogued then
+func riskCatalogEntryInForce(stub shim.ChaincodeStubInterface, catalogType RiskCatalogType, code string, at time.Time) (*RiskCatalogEntry, error) {
+	versions, err := riskCatalogVersions(stub, catalogType, code)
+	if err != nil {
+		return nil, err
+	}
+	for i := len(versions) - 1; i >= 0; i-- {
+		if !versions[i].EffectiveDate.After(at) {
+			return &versions[i], nil
+		}
+	}
+	return nil, nil
+}
+
+func parseRiskCatalogType(catalogType string) (RiskCatalogType, error) {
+	switch RiskCatalogType(strings.ToUpper(strings.TrimSpace(catalogType))) {
+	case RiskCatalogOccupation:
+		return RiskCatalogOccupation, nil
+	case RiskCatalogIndustry:
+		return RiskCatalogIndustry, nil
+	}
+	return "", services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid catalog type %s: expected %s or %s", catalogType, RiskCatalogOccupation, RiskCatalogIndustry)
+}
+
+// normalizeCatalogCode upper-cases a code and joins its words with underscores, so "Money changer"
+// matches MONEY_CHANGER
+func normalizeCatalogCode(code string) string {
+	return strings.Join(strings.Fields(strings.ToUpper(code)), "_")
+}
+
+func parseCatalogDate(value string) (time.Time, error) {
+	date, err := time.Parse(time.RFC3339, value)
+	if err != nil {
+		if date, err = time.Parse("2006-01-02", value); err != nil {
+			return time.Time{}, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid date %s: expected RFC 3339 or YYYY-MM-DD", value)
+		}
+	}
+	return date.UTC(), nil
+}
@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+func TestRiskCatalogHandler_Versions(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	clock := services.NewFixedClock(now)
+	defer services.SetClock(clock)()
+	stub := shimtest.NewMockStub("risk_catalog_test", nil)
+	handler := NewRiskCatalogHandler()
+	amlHandler := NewAMLCheckHandler(&MockEventEmitter{})
+
+	put := func(txID string, fn func([]string) ([]byte, error), request RiskCatalogEntryRequest) (*RiskCatalogEntry, error) {
+		requestBytes, err := json.Marshal(request)
+		require.NoError(t, err)
+		stub.MockTransactionStart(txID)
+		defer stub.MockTransactionEnd(txID)
+		entryBytes, err := fn([]string{string(requestBytes)})
+		if err != nil {
+			return nil, err
+		}
+		var entry RiskCatalogEntry
+		require.NoError(t, json.Unmarshal(entryBytes, &entry))
+		return &entry, nil
+	}
+	create := func(args []string) ([]byte, error) { return handler.CreateRiskCatalogEntry(stub, args) }
+	update := func(args []string) ([]byte, error) { return handler.UpdateRiskCatalogEntry(stub, args) }
+	retire := func(args []string) ([]byte, error) { return handler.RetireRiskCatalogEntry(stub, args) }
+	profileRisks := func(txID string, customer CustomerAMLData) []RiskFactor {
+		stub.MockTransactionStart(txID)
+		defer stub.MockTransactionEnd(txID)
+		factors, err := amlHandler.assessCustomerProfileRisk(stub, &customer, now)
+		require.NoError(t, err)
+		return factors
+	}
+	catalog := func(txID string, args ...string) []RiskCatalogEntry {
+		stub.MockTransactionStart(txID)
+		defer stub.MockTransactionEnd(txID)
+		catalogBytes, err := handler.GetRiskCatalog(stub, args)
+		require.NoError(t, err)
+		var entries []RiskCatalogEntry
+		require.NoError(t, json.Unmarshal(catalogBytes, &entries))
+		return entries
+	}
+
+	// Built-in occupations score as version 0
+	factors := profileRisks("risk_1", CustomerAMLData{Occupation: "Money changer"})
+	require.Len(t, factors, 1)
+	assert.Equal(t, 65.0, factors[0].RiskScore)
+	require.NotNil(t, factors[0].CatalogReference)
+	assert.Equal(t, RiskCatalogReference{Catalog: "OCCUPATION", Code: "MONEY_CHANGER", Version: 0}, *factors[0].CatalogReference)
+
+	// Only compliance officers maintain the catalogs
+	stub.Creator = newRoleIdentity(t, "Underwriter")
+	_, err := put("create_1", create, RiskCatalogEntryRequest{CatalogType: "INDUSTRY", Code: "Gambling", RiskScore: 70, Reason: "Cash intensive", ActorID: "ACTOR_001"})
+	assert.Contains(t, err.Error(), "may only be maintained by")
+
+	stub.Creator = newRoleIdentity(t, "Compliance_Officer")
+	_, err = put("create_2", create, RiskCatalogEntryRequest{CatalogType: "SECTOR", Code: "Gambling", RiskScore: 70, Reason: "Cash intensive", ActorID: "ACTOR_001"})
+	assert.Contains(t, err.Error(), "invalid catalog type")
+	_, err = put("create_3", create, RiskCatalogEntryRequest{CatalogType: "OCCUPATION", Code: "diplomat", RiskScore: 50, Reason: "Duplicate", ActorID: "ACTOR_001"})
+	assert.Contains(t, err.Error(), "already catalogued")
+	_, err = put("update_1", update, RiskCatalogEntryRequest{CatalogType: "INDUSTRY", Code: "Gambling", RiskScore: 70, Reason: "Not yet catalogued", ActorID: "ACTOR_001"})
+	assert.Contains(t, err.Error(), "is not catalogued")
+
+	entry, err := put("create_4", create, RiskCatalogEntryRequest{CatalogType: "industry", Code: "Online gambling", Description: "Online betting operators", RiskScore: 70, Reason: "Cash intensive", ActorID: "ACTOR_001"})
+	require.NoError(t, err)
+	assert.Equal(t, "ONLINE_GAMBLING", entry.Code)
+	assert.Equal(t, 1, entry.Version)
+	assert.Equal(t, now, entry.EffectiveDate)
+
+	// A rescoring scheduled for next month leaves today's score in force
+	entry, err = put("update_2", update, RiskCatalogEntryRequest{CatalogType: "INDUSTRY", Code: "ONLINE_GAMBLING", RiskScore: 85, EffectiveDate: "2026-07-01", Reason: "Supervisory guidance", ActorID: "ACTOR_002"})
+	require.NoError(t, err)
+	assert.Equal(t, 2, entry.Version)
+	assert.Equal(t, "Online betting operators", entry.Description)
+	_, err = put("update_3", update, RiskCatalogEntryRequest{CatalogType: "INDUSTRY", Code: "ONLINE_GAMBLING", RiskScore: 60, EffectiveDate: "2026-06-15", Reason: "Back-dated", ActorID: "ACTOR_002"})
+	assert.Contains(t, err.Error(), "precedes version 2")
+
+	factors = profileRisks("risk_2", CustomerAMLData{Occupation: "Teacher", Industry: "online gambling"})
+	require.Len(t, factors, 1)
+	assert.Equal(t, 70.0, factors[0].RiskScore)
+	assert.Equal(t, 1, factors[0].CatalogReference.Version)
+	assert.Contains(t, factors[0].Evidence, "catalog version: 1")
+
+	// The catalog as of next month shows the new score
+	future := catalog("catalog_1", "INDUSTRY", "2026-07-02")
+	require.Len(t, future, 1)
+	assert.Equal(t, 85.0, future[0].RiskScore)
+	assert.Equal(t, 70.0, catalog("catalog_2", "INDUSTRY")[0].RiskScore)
+
+	// Retiring a built-in occupation stops it scoring
+	entry, err = put("retire_1", retire, RiskCatalogEntryRequest{CatalogType: "OCCUPATION", Code: "DIPLOMAT", Reason: "Covered by PEP screening", ActorID: "ACTOR_001"})
+	require.NoError(t, err)
+	assert.False(t, entry.IsActive)
+	assert.Equal(t, 1, entry.Version)
+	assert.Empty(t, profileRisks("risk_3", CustomerAMLData{Occupation: "Diplomat"}))
+	assert.Len(t, catalog("catalog_3", "OCCUPATION"), len(defaultRiskCatalogs[RiskCatalogOccupation])-1)
+
+	stub.MockTransactionStart("versions")
+	versionsBytes, err := handler.GetRiskCatalogEntry(stub, []string{"OCCUPATION", "diplomat"})
+	stub.MockTransactionEnd("versions")
+	require.NoError(t, err)
+	var versions []RiskCatalogEntry
+	require.NoError(t, json.Unmarshal(versionsBytes, &versions))
+	require.Len(t, versions, 2)
+	assert.Equal(t, 60.0, versions[0].RiskScore)
+	assert.Equal(t, "Covered by PEP screening", versions[1].Reason)
+}
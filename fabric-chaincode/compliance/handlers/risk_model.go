@@ -0,0 +1,382 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// RiskModelStatus is the stage of a risk model version in its activation workflow
+type RiskModelStatus string
+
+const (
+	RiskModelDraft      RiskModelStatus = "DRAFT"      // Proposed, awaiting activation
+	RiskModelActive     RiskModelStatus = "ACTIVE"     // Scores every AML check
+	RiskModelSuperseded RiskModelStatus = "SUPERSEDED" // Replaced by a later activation
+)
+
+// riskModelVersionFormat keeps the model's versions in key order
+const riskModelVersionFormat = "%06d"
+
+// defaultRiskModel scores AML checks, as version 0, until compliance activates a model on the ledger
+var defaultRiskModel = RiskModel{
+	Version:           0,
+	Name:              "BASELINE",
+	SanctionWeight:    0.4,
+	PEPWeight:         0.3,
+	RiskFactorWeight:  0.3,
+	AdverseMediaWeight: 0.2,
+	MediumThreshold:   0.3,
+	HighThreshold:     0.6,
+	CriticalThreshold: 0.8,
+	Status:            RiskModelActive,
+}
+
+// RiskModelHandler maintains the versions of the AML risk model. A version is drafted by compliance
+// or risk staff and scores checks only once a chief compliance officer other than its author
+// activates it; earlier versions are kept so the model behind any past score can be looked up.
+type RiskModelHandler struct {
+	persistenceService *services.PersistenceService
+}
+
+// NewRiskModelHandler creates a new risk model handler
+func NewRiskModelHandler() *RiskModelHandler {
+	return &RiskModelHandler{
+		persistenceService: services.NewPersistenceService(),
+	}
+}
+
+// RiskModel is one version of the weights combining the screening results and risk factors of an
+// AML check into its overall score, and of the score thresholds of each risk level. The sanction,
+// PEP and risk factor weights sum to 1; adverse media adds to them, the score capping at 1. The
+// thresholds are in (0, 1].
+type RiskModel struct {
+	Version           int             `json:"version"`
+	Name              string          `json:"name"`
+	SanctionWeight    float64         `json:"sanctionWeight"`
+	PEPWeight         float64         `json:"pepWeight"`
+	RiskFactorWeight  float64         `json:"riskFactorWeight"`
+	AdverseMediaWeight float64        `json:"adverseMediaWeight"`
+	MediumThreshold   float64         `json:"mediumThreshold"`
+	HighThreshold     float64         `json:"highThreshold"`
+	CriticalThreshold float64         `json:"criticalThreshold"`
+	Status            RiskModelStatus `json:"status"`
+	Rationale         string          `json:"rationale,omitempty"`
+	CreatedBy         string          `json:"createdBy,omitempty"`
+	CreatedDate       time.Time       `json:"createdDate"`
+	ActivatedBy       string          `json:"activatedBy,omitempty"`
+	ActivatedDate     *time.Time      `json:"activatedDate,omitempty"`
+	SupersededDate    *time.Time      `json:"supersededDate,omitempty"`
+}
+
+// RiskModelRequest represents a request to draft a risk model version
+type RiskModelRequest struct {
+	Name              string  `json:"name"`
+	SanctionWeight    float64 `json:"sanctionWeight"`
+	PEPWeight         float64 `json:"pepWeight"`
+	RiskFactorWeight  float64 `json:"riskFactorWeight"`
+	AdverseMediaWeight float64 `json:"adverseMediaWeight"`
+	MediumThreshold   float64 `json:"mediumThreshold"`
+	HighThreshold     float64 `json:"highThreshold"`
+	CriticalThreshold float64 `json:"criticalThreshold"`
+	Rationale         string  `json:"rationale"`
+	ActorID           string  `json:"actorID"`
+}
+
+// CreateRiskModelVersion drafts the next version of the risk model
+func (h *RiskModelHandler) CreateRiskModelVersion(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req RiskModelRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse risk model request: %v", err)
+	}
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return nil, err
+	}
+	if role != string(validation.ActorRoleComplianceOfficer) && role != string(validation.ActorRoleRiskAnalyst) && role != string(validation.ActorRoleChiefComplianceOfficer) {
+		return nil, services.NewChaincodeError(services.ErrCodeAccessDenied, "", "risk models may only be drafted by a %s, %s or %s", validation.ActorRoleComplianceOfficer, validation.ActorRoleRiskAnalyst, validation.ActorRoleChiefComplianceOfficer)
+	}
+	req.Name = strings.ToUpper(strings.TrimSpace(req.Name))
+	if req.Name == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "name", "name is required")
+	}
+	if strings.TrimSpace(req.Rationale) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "rationale", "rationale is required")
+	}
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	model := &RiskModel{
+		Name:              req.Name,
+		SanctionWeight:    req.SanctionWeight,
+		PEPWeight:         req.PEPWeight,
+		RiskFactorWeight:  req.RiskFactorWeight,
+		AdverseMediaWeight: req.AdverseMediaWeight,
+		MediumThreshold:   req.MediumThreshold,
+		HighThreshold:     req.HighThreshold,
+		CriticalThreshold: req.CriticalThreshold,
+		Status:            RiskModelDraft,
+		Rationale:         req.Rationale,
+		CreatedBy:         req.ActorID,
+	}
+	if err := model.validate(); err != nil {
+		return nil, err
+	}
+
+	versions, err := riskModelVersions(stub)
+	if err != nil {
+		return nil, err
+	}
+	for _, version := range versions {
+		if version.Name == model.Name {
+			return nil, services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "risk model %s is already version %d", model.Name, version.Version)
+		}
+	}
+	model.Version = versions[len(versions)-1].Version + 1
+	if model.CreatedDate, err = services.TxTime(stub); err != nil {
+		return nil, err
+	}
+	if err := h.putVersion(stub, model); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(model)
+}
+
+// ActivateRiskModelVersion puts a drafted version in force, superseding the active one. Args:
+// version, actorID. The activating chief compliance officer may not be the version's author.
+func (h *RiskModelHandler) ActivateRiskModelVersion(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 2, got %d", len(args))
+	}
+
+	version, err := parseRiskModelVersion(args[0])
+	if err != nil {
+		return nil, err
+	}
+	actorID := strings.TrimSpace(args[1])
+	if actorID == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return nil, err
+	}
+	if role != string(validation.ActorRoleChiefComplianceOfficer) {
+		return nil, services.NewChaincodeError(services.ErrCodeAccessDenied, "", "risk models may only be activated by a %s", validation.ActorRoleChiefComplianceOfficer)
+	}
+
+	versions, err := riskModelVersions(stub)
+	if err != nil {
+		return nil, err
+	}
+	if version < 1 || version >= len(versions) {
+		return nil, services.NewChaincodeError(services.ErrCodeNotFound, "", "risk model version %d not found", version)
+	}
+	model := versions[version]
+	if model.Status != RiskModelDraft {
+		return nil, fmt.Errorf("risk model version %d is %s", version, model.Status)
+	}
+	if model.CreatedBy == actorID {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "risk model version %d must be activated by someone other than its author %s", version, actorID)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	active, err := activeRiskModel(stub)
+	if err != nil {
+		return nil, err
+	}
+	if active.Version > 0 {
+		active.Status = RiskModelSuperseded
+		active.SupersededDate = &now
+		if err := h.putVersion(stub, active); err != nil {
+			return nil, err
+		}
+	}
+
+	model.Status = RiskModelActive
+	model.ActivatedBy = actorID
+	model.ActivatedDate = &now
+	if err := h.putVersion(stub, &model); err != nil {
+		return nil, err
+	}
+	activeKey, err := stub.CreateCompositeKey(config.RiskModelActivePrefix, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create active risk model key: %w", err)
+	}
+	if err := h.persistenceService.Put(stub, activeKey, &model); err != nil {
+		return nil, fmt.Errorf("failed to store active risk model: %w", err)
+	}
+
+	return json.Marshal(model)
+}
+
+// GetActiveRiskModel returns the risk model version scoring AML checks
+func (h *RiskModelHandler) GetActiveRiskModel(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 0, got %d", len(args))
+	}
+
+	model, err := activeRiskModel(stub)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(model)
+}
+
+// GetRiskModelVersions returns every version of the risk model, oldest first, starting with the
+// built-in baseline
+func (h *RiskModelHandler) GetRiskModelVersions(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 0, got %d", len(args))
+	}
+
+	versions, err := riskModelVersions(stub)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(versions)
+}
+
+func (h *RiskModelHandler) putVersion(stub shim.ChaincodeStubInterface, model *RiskModel) error {
+	modelKey, err := stub.CreateCompositeKey(config.RiskModelPrefix, []string{fmt.Sprintf(riskModelVersionFormat, model.Version)})
+	if err != nil {
+		return fmt.Errorf("failed to create risk model key: %w", err)
+	}
+	if err := h.persistenceService.Put(stub, modelKey, model); err != nil {
+		return fmt.Errorf("failed to store risk model: %w", err)
+	}
+	return nil
+}
+
+// validate checks the weights sum to 1 and the thresholds rise from medium to critical
+func (m *RiskModel) validate() error {
+	for name, weight := range map[string]float64{"sanctionWeight": m.SanctionWeight, "pepWeight": m.PEPWeight, "riskFactorWeight": m.RiskFactorWeight, "adverseMediaWeight": m.AdverseMediaWeight} {
+		if weight < 0 || weight > 1 {
+			return services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "%s must be between 0 and 1, got %.4f", name, weight)
+		}
+	}
+	if total := m.SanctionWeight + m.PEPWeight + m.RiskFactorWeight; math.Abs(total-1) > 1e-9 {
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "weights must sum to 1, got %.4f", total)
+	}
+	if m.MediumThreshold <= 0 || m.MediumThreshold >= m.HighThreshold || m.HighThreshold >= m.CriticalThreshold || m.CriticalThreshold > 1 {
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "thresholds must satisfy 0 < medium < high < critical <= 1, got %.4f, %.4f, %.4f", m.MediumThreshold, m.HighThreshold, m.CriticalThreshold)
+	}
+	return nil
+}
+
+// overallRiskScore combines the sanction and PEP match confidences, the average risk factor score
+// and the severity-weighted adverse media contribution into a score between 0 and 1
+func (m *RiskModel) overallRiskScore(result *AMLCheckResult) float64 {
+	score := 0.0
+
+	if result.SanctionScreenResult.IsMatch {
+		score += result.SanctionScreenResult.MatchConfidence * m.SanctionWeight
+	}
+	if result.PEPScreenResult.IsMatch {
+		score += result.PEPScreenResult.MatchConfidence * m.PEPWeight
+	}
+	if result.AdverseMediaResult.IsMatch {
+		score += result.AdverseMediaResult.RiskContribution * m.AdverseMediaWeight
+	}
+	if len(result.RiskFactors) > 0 {
+		totalRiskScore := 0.0
+		for _, factor := range result.RiskFactors {
+			totalRiskScore += factor.RiskScore
+		}
+		avgRiskScore := totalRiskScore / float64(len(result.RiskFactors))
+		score += (avgRiskScore / 100.0) * m.RiskFactorWeight // Normalize to 0-1 range
+	}
+
+	if score > 1.0 {
+		score = 1.0
+	}
+	return score
+}
+
+// riskLevel maps an overall risk score to its risk level
+func (m *RiskModel) riskLevel(riskScore float64) RiskLevel {
+	if riskScore >= m.CriticalThreshold {
+		return RiskLevelCritical
+	} else if riskScore >= m.HighThreshold {
+		return RiskLevelHigh
+	} else if riskScore >= m.MediumThreshold {
+		return RiskLevelMedium
+	}
+	return RiskLevelLow
+}
+
+// activeRiskModel returns the risk model version in force, else the built-in baseline
+func activeRiskModel(stub shim.ChaincodeStubInterface) (*RiskModel, error) {
+	activeKey, err := stub.CreateCompositeKey(config.RiskModelActivePrefix, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create active risk model key: %w", err)
+	}
+	modelBytes, err := stub.GetState(activeKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read active risk model: %w", err)
+	}
+	if modelBytes == nil {
+		model := defaultRiskModel
+		return &model, nil
+	}
+
+	var model RiskModel
+	if err := json.Unmarshal(modelBytes, &model); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal active risk model: %w", err)
+	}
+	return &model, nil
+}
+
+// riskModelVersions returns the model's versions, indexed by version, starting with the built-in
+// baseline. The baseline shows as superseded once another version has been activated.
+func riskModelVersions(stub shim.ChaincodeStubInterface) ([]RiskModel, error) {
+	baseline := defaultRiskModel
+	versions := []RiskModel{baseline}
+
+	iterator, err := stub.GetStateByPartialCompositeKey(config.RiskModelPrefix, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get risk model versions: %w", err)
+	}
+	defer iterator.Close()
+
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate risk model versions: %w", err)
+		}
+		var model RiskModel
+		if err := json.Unmarshal(response.Value, &model); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal risk model: %w", err)
+		}
+		if model.ActivatedDate != nil && versions[0].Status == RiskModelActive {
+			versions[0].Status = RiskModelSuperseded
+		}
+		versions = append(versions, model)
+	}
+	return versions, nil
+}
+
+func parseRiskModelVersion(value string) (int, error) {
+	version, err := strconv.Atoi(strings.TrimSpace(value))
+	if err != nil {
+		return 0, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid risk model version %q", value)
+	}
+	return version, nil
+}
@@ -0,0 +1,129 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+func TestRiskModelHandler_Activation(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	clock := services.NewFixedClock(now)
+	defer services.SetClock(clock)()
+	stub := shimtest.NewMockStub("risk_model_test", nil)
+	handler := NewRiskModelHandler()
+	amlHandler := NewAMLCheckHandler(&MockEventEmitter{})
+
+	create := func(txID string, request RiskModelRequest) (*RiskModel, error) {
+		requestBytes, err := json.Marshal(request)
+		require.NoError(t, err)
+		stub.MockTransactionStart(txID)
+		defer stub.MockTransactionEnd(txID)
+		modelBytes, err := handler.CreateRiskModelVersion(stub, []string{string(requestBytes)})
+		if err != nil {
+			return nil, err
+		}
+		var model RiskModel
+		require.NoError(t, json.Unmarshal(modelBytes, &model))
+		return &model, nil
+	}
+	activate := func(txID string, args ...string) (*RiskModel, error) {
+		stub.MockTransactionStart(txID)
+		defer stub.MockTransactionEnd(txID)
+		modelBytes, err := handler.ActivateRiskModelVersion(stub, args)
+		if err != nil {
+			return nil, err
+		}
+		var model RiskModel
+		require.NoError(t, json.Unmarshal(modelBytes, &model))
+		return &model, nil
+	}
+	check := func(txID string) *AMLCheckResult {
+		stub.MockTransactionStart(txID)
+		defer stub.MockTransactionEnd(txID)
+		result, err := amlHandler.performComprehensiveAMLCheck(stub, txID, &AMLCheckRequest{
+			CustomerID:   "CUST_001",
+			CustomerData: CustomerAMLData{FirstName: "Ana", LastName: "Silva", Country: "US", Occupation: "Money changer"},
+			CheckType:    AMLCheckTypeCustomerOnboarding,
+			ActorID:      "ACTOR_001",
+		})
+		require.NoError(t, err)
+		return result
+	}
+
+	// Checks are scored with the built-in baseline until a model is activated
+	baseline := check("check_1")
+	assert.Equal(t, 0, baseline.RiskModelVersion)
+	assert.Equal(t, "BASELINE", baseline.RiskModelName)
+	assert.InDelta(t, 0.195, baseline.OverallRiskScore, 1e-9)
+	assert.Equal(t, RiskLevelLow, baseline.RiskLevel)
+
+	stub.Creator = newRoleIdentity(t, "Underwriter")
+	_, err := create("create_1", RiskModelRequest{Name: "2026-H2", SanctionWeight: 0.3, PEPWeight: 0.2, RiskFactorWeight: 0.5, MediumThreshold: 0.25, HighThreshold: 0.5, CriticalThreshold: 0.75, Rationale: "Profile risk underweighted", ActorID: "ACTOR_002"})
+	assert.Contains(t, err.Error(), "may only be drafted by")
+
+	stub.Creator = newRoleIdentity(t, "Risk_Analyst")
+	_, err = create("create_2", RiskModelRequest{Name: "2026-H2", SanctionWeight: 0.4, PEPWeight: 0.2, RiskFactorWeight: 0.5, MediumThreshold: 0.25, HighThreshold: 0.5, CriticalThreshold: 0.75, Rationale: "Profile risk underweighted", ActorID: "ACTOR_002"})
+	assert.Contains(t, err.Error(), "weights must sum to 1")
+	_, err = create("create_3", RiskModelRequest{Name: "2026-H2", SanctionWeight: 0.3, PEPWeight: 0.2, RiskFactorWeight: 0.5, MediumThreshold: 0.5, HighThreshold: 0.5, CriticalThreshold: 0.75, Rationale: "Profile risk underweighted", ActorID: "ACTOR_002"})
+	assert.Contains(t, err.Error(), "thresholds must satisfy")
+	_, err = create("create_4", RiskModelRequest{Name: "baseline", SanctionWeight: 0.3, PEPWeight: 0.2, RiskFactorWeight: 0.5, MediumThreshold: 0.25, HighThreshold: 0.5, CriticalThreshold: 0.75, Rationale: "Duplicate name", ActorID: "ACTOR_002"})
+	assert.Contains(t, err.Error(), "is already version 0")
+
+	draft, err := create("create_5", RiskModelRequest{Name: "2026-h2", SanctionWeight: 0.3, PEPWeight: 0.2, RiskFactorWeight: 0.5, MediumThreshold: 0.25, HighThreshold: 0.5, CriticalThreshold: 0.75, Rationale: "Profile risk underweighted", ActorID: "ACTOR_002"})
+	require.NoError(t, err)
+	assert.Equal(t, 1, draft.Version)
+	assert.Equal(t, "2026-H2", draft.Name)
+	assert.Equal(t, RiskModelDraft, draft.Status)
+
+	// A draft does not score checks
+	assert.Equal(t, 0, check("check_2").RiskModelVersion)
+
+	// Only a chief compliance officer other than the author activates a version
+	_, err = activate("activate_1", "1", "ACTOR_003")
+	assert.Contains(t, err.Error(), "may only be activated by")
+	stub.Creator = newRoleIdentity(t, "Chief_Compliance_Officer")
+	_, err = activate("activate_2", "1", "ACTOR_002")
+	assert.Contains(t, err.Error(), "someone other than its author")
+	_, err = activate("activate_3", "2", "ACTOR_003")
+	assert.Contains(t, err.Error(), "not found")
+
+	active, err := activate("activate_4", "1", "ACTOR_003")
+	require.NoError(t, err)
+	assert.Equal(t, RiskModelActive, active.Status)
+	assert.Equal(t, "ACTOR_003", active.ActivatedBy)
+	_, err = activate("activate_5", "1", "ACTOR_003")
+	assert.Contains(t, err.Error(), "is ACTIVE")
+
+	reweighted := check("check_3")
+	assert.Equal(t, 1, reweighted.RiskModelVersion)
+	assert.Equal(t, "2026-H2", reweighted.RiskModelName)
+	assert.InDelta(t, 0.325, reweighted.OverallRiskScore, 1e-9)
+	assert.Equal(t, RiskLevelMedium, reweighted.RiskLevel)
+
+	// Activating the next version supersedes the active one
+	stub.Creator = newRoleIdentity(t, "Compliance_Officer")
+	_, err = create("create_6", RiskModelRequest{Name: "2027-H1", SanctionWeight: 0.5, PEPWeight: 0.25, RiskFactorWeight: 0.25, MediumThreshold: 0.3, HighThreshold: 0.6, CriticalThreshold: 0.8, Rationale: "Sanctions emphasis", ActorID: "ACTOR_004"})
+	require.NoError(t, err)
+	stub.Creator = newRoleIdentity(t, "Chief_Compliance_Officer")
+	_, err = activate("activate_6", "2", "ACTOR_003")
+	require.NoError(t, err)
+
+	stub.MockTransactionStart("versions")
+	versionsBytes, err := handler.GetRiskModelVersions(stub, []string{})
+	stub.MockTransactionEnd("versions")
+	require.NoError(t, err)
+	var versions []RiskModel
+	require.NoError(t, json.Unmarshal(versionsBytes, &versions))
+	require.Len(t, versions, 3)
+	assert.Equal(t, RiskModelSuperseded, versions[0].Status)
+	assert.Equal(t, RiskModelSuperseded, versions[1].Status)
+	assert.Equal(t, &now, versions[1].SupersededDate)
+	assert.Equal(t, RiskModelActive, versions[2].Status)
+	assert.Equal(t, 2, check("check_4").RiskModelVersion)
+}
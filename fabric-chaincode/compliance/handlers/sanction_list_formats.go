@@ -0,0 +1,532 @@
+package handlers
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strings"
+	"time"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// Official sanction list formats a sanction list update can carry instead of entries
+const (
+	SanctionListFormatOFACSDN        = "OFAC_SDN_XML"        // US Treasury OFAC SDN list, sdn.xml
+	SanctionListFormatEUConsolidated = "EU_CONSOLIDATED_XML" // EU consolidated financial sanctions list (CFSP)
+)
+
+// ParseSanctionListDocument parses an official list document into entries of a sanction list
+func ParseSanctionListDocument(format string, document []byte, listID string) ([]ComprehensiveSanctionEntry, error) {
+	switch strings.ToUpper(format) {
+	case SanctionListFormatOFACSDN:
+		return ParseOFACSDNList(document, listID)
+	case SanctionListFormatEUConsolidated:
+		return ParseEUConsolidatedList(document, listID)
+	default:
+		return nil, fmt.Errorf("unsupported sanction list format: %s", format)
+	}
+}
+
+// ============================================================================
+// OFAC SDN XML
+// ============================================================================
+
+type ofacSDNList struct {
+	PublishDate string         `xml:"publshInformation>Publish_Date"`
+	Entries     []ofacSDNEntry `xml:"sdnEntry"`
+}
+
+type ofacSDNEntry struct {
+	UID           string             `xml:"uid"`
+	FirstName     string             `xml:"firstName"`
+	LastName      string             `xml:"lastName"`
+	Title         string             `xml:"title"`
+	SDNType       string             `xml:"sdnType"`
+	Remarks       string             `xml:"remarks"`
+	Programs      []string           `xml:"programList>program"`
+	IDs           []ofacID           `xml:"idList>id"`
+	Akas          []ofacAka          `xml:"akaList>aka"`
+	Addresses     []ofacAddress      `xml:"addressList>address"`
+	Nationalities []ofacCountry      `xml:"nationalityList>nationality"`
+	Citizenships  []ofacCountry      `xml:"citizenshipList>citizenship"`
+	DatesOfBirth  []ofacDateOfBirth  `xml:"dateOfBirthList>dateOfBirthItem"`
+	PlacesOfBirth []ofacPlaceOfBirth `xml:"placeOfBirthList>placeOfBirthItem"`
+}
+
+type ofacID struct {
+	UID            string `xml:"uid"`
+	IDType         string `xml:"idType"`
+	IDNumber       string `xml:"idNumber"`
+	IDCountry      string `xml:"idCountry"`
+	IssueDate      string `xml:"issueDate"`
+	ExpirationDate string `xml:"expirationDate"`
+}
+
+type ofacAka struct {
+	FirstName string `xml:"firstName"`
+	LastName  string `xml:"lastName"`
+}
+
+type ofacAddress struct {
+	UID             string `xml:"uid"`
+	Address1        string `xml:"address1"`
+	Address2        string `xml:"address2"`
+	Address3        string `xml:"address3"`
+	City            string `xml:"city"`
+	StateOrProvince string `xml:"stateOrProvince"`
+	PostalCode      string `xml:"postalCode"`
+	Country         string `xml:"country"`
+}
+
+type ofacCountry struct {
+	Country string `xml:"country"`
+}
+
+type ofacDateOfBirth struct {
+	DateOfBirth string `xml:"dateOfBirth"`
+	MainEntry   bool   `xml:"mainEntry"`
+}
+
+type ofacPlaceOfBirth struct {
+	PlaceOfBirth string `xml:"placeOfBirth"`
+	MainEntry    bool   `xml:"mainEntry"`
+}
+
+// ofacEntityTypes maps OFAC sdnType values to sanction entity types
+var ofacEntityTypes = map[string]SanctionEntityType{
+	"INDIVIDUAL": EntityTypeIndividual,
+	"ENTITY":     EntityTypeOrganization,
+	"VESSEL":     EntityTypeVessel,
+	"AIRCRAFT":   EntityTypeAircraft,
+}
+
+// ofacDocumentTypes maps the OFAC idType values of identity documents to document types; other
+// identifiers, such as registration numbers, keep their OFAC type
+var ofacDocumentTypes = map[string]string{
+	"PASSPORT":             "PASSPORT",
+	"NATIONAL ID NO.":      "NATIONAL_ID",
+	"CEDULA NO.":           "NATIONAL_ID",
+	"DRIVER'S LICENSE NO.": "DRIVER_LICENSE",
+	"TAX ID NO.":           "TAX_ID",
+	"SSN":                  "SSN",
+}
+
+// ParseOFACSDNList parses the OFAC SDN list XML. Names are joined first name first, every a.k.a.
+// becomes an alias, and each entry is dated from the list's publish date. Dates of birth OFAC
+// only gives in part, such as a year or "circa 1960", are kept in the entry's metadata.
+func ParseOFACSDNList(document []byte, listID string) ([]ComprehensiveSanctionEntry, error) {
+	var list ofacSDNList
+	if err := xml.Unmarshal(document, &list); err != nil {
+		return nil, fmt.Errorf("failed to parse OFAC SDN list: %w", err)
+	}
+	if len(list.Entries) == 0 {
+		return nil, fmt.Errorf("OFAC SDN list has no entries")
+	}
+
+	var published time.Time
+	if strings.TrimSpace(list.PublishDate) != "" {
+		parsed, err := time.Parse("01/02/2006", strings.TrimSpace(list.PublishDate))
+		if err != nil {
+			return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid OFAC publish date: %s", list.PublishDate)
+		}
+		published = parsed
+	}
+
+	entries := make([]ComprehensiveSanctionEntry, 0, len(list.Entries))
+	for _, sdn := range list.Entries {
+		uid := strings.TrimSpace(sdn.UID)
+		entityType, ok := ofacEntityTypes[strings.ToUpper(strings.TrimSpace(sdn.SDNType))]
+		if !ok {
+			return nil, fmt.Errorf("OFAC entry %s has unknown sdnType: %s", uid, sdn.SDNType)
+		}
+
+		entry := ComprehensiveSanctionEntry{
+			EntryID:            uid,
+			ListID:             listID,
+			PrimaryName:        joinName(sdn.FirstName, sdn.LastName),
+			Aliases:            []string{},
+			EntityType:         entityType,
+			Addresses:          []SanctionAddress{},
+			IdentificationDocs: []IdentificationDoc{},
+			SanctionType:       string(SanctionListTypeSDN),
+			SanctionDate:       published,
+			IsActive:           true,
+			SourceReference:    uid,
+			Programs:           trimAll(sdn.Programs),
+			Remarks:            strings.TrimSpace(sdn.Remarks),
+		}
+		if entityType == EntityTypeIndividual {
+			entry.FirstName = strings.TrimSpace(sdn.FirstName)
+			entry.LastName = strings.TrimSpace(sdn.LastName)
+		}
+		if title := strings.TrimSpace(sdn.Title); title != "" {
+			entry.Metadata = map[string]interface{}{"title": title}
+		}
+
+		for _, aka := range sdn.Akas {
+			entry.Aliases = appendUnique(entry.Aliases, joinName(aka.FirstName, aka.LastName))
+		}
+		for _, country := range append(sdn.Nationalities, sdn.Citizenships...) {
+			entry.Nationality = appendUnique(entry.Nationality, strings.TrimSpace(country.Country))
+		}
+		for _, address := range sdn.Addresses {
+			entry.Addresses = append(entry.Addresses, SanctionAddress{
+				AddressID:  strings.TrimSpace(address.UID),
+				Street:     joinNonEmpty(", ", address.Address1, address.Address2, address.Address3),
+				City:       strings.TrimSpace(address.City),
+				State:      strings.TrimSpace(address.StateOrProvince),
+				PostalCode: strings.TrimSpace(address.PostalCode),
+				Country:    strings.TrimSpace(address.Country),
+				IsActive:   true,
+			})
+		}
+		for _, id := range sdn.IDs {
+			idType := strings.ToUpper(strings.TrimSpace(id.IDType))
+			if idType == "GENDER" {
+				entry.Gender = strings.ToUpper(strings.TrimSpace(id.IDNumber))
+				continue
+			}
+			docType, ok := ofacDocumentTypes[idType]
+			if !ok {
+				docType = idType
+			}
+			entry.IdentificationDocs = append(entry.IdentificationDocs, IdentificationDoc{
+				DocID:          strings.TrimSpace(id.UID),
+				DocType:        docType,
+				DocNumber:      strings.TrimSpace(id.IDNumber),
+				IssuingCountry: strings.TrimSpace(id.IDCountry),
+				IssueDate:      parseOFACDate(id.IssueDate),
+				ExpiryDate:     parseOFACDate(id.ExpirationDate),
+				IsActive:       true,
+			})
+		}
+
+		datesOfBirth := []string{}
+		for _, dob := range sdn.DatesOfBirth {
+			value := strings.TrimSpace(dob.DateOfBirth)
+			if value == "" {
+				continue
+			}
+			datesOfBirth = append(datesOfBirth, value)
+			if parsed := parseOFACDate(value); parsed != nil && (entry.DateOfBirth == nil || dob.MainEntry) {
+				entry.DateOfBirth = parsed
+			}
+		}
+		setDatesOfBirth(&entry, datesOfBirth)
+		for _, place := range sdn.PlacesOfBirth {
+			if value := strings.TrimSpace(place.PlaceOfBirth); value != "" && (entry.PlaceOfBirth == "" || place.MainEntry) {
+				entry.PlaceOfBirth = value
+			}
+		}
+
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// parseOFACDate parses a full OFAC date, such as "12 Mar 1964"; partial dates give nil
+func parseOFACDate(value string) *time.Time {
+	value = strings.TrimSpace(value)
+	for _, layout := range []string{"02 Jan 2006", "2 Jan 2006"} {
+		if parsed, err := time.Parse(layout, value); err == nil {
+			return &parsed
+		}
+	}
+	return nil
+}
+
+// ============================================================================
+// EU consolidated financial sanctions list
+// ============================================================================
+
+type euSanctionsExport struct {
+	GenerationDate string             `xml:"generationDate,attr"`
+	Entities       []euSanctionEntity `xml:"sanctionEntity"`
+}
+
+type euSanctionEntity struct {
+	LogicalID         string             `xml:"logicalId,attr"`
+	EUReferenceNumber string             `xml:"euReferenceNumber,attr"`
+	UnitedNationID    string             `xml:"unitedNationId,attr"`
+	Remarks           []string           `xml:"remark"`
+	Regulations       []euRegulation     `xml:"regulation"`
+	SubjectType       euSubjectType      `xml:"subjectType"`
+	NameAliases       []euNameAlias      `xml:"nameAlias"`
+	Citizenships      []euCountry        `xml:"citizenship"`
+	Birthdates        []euBirthdate      `xml:"birthdate"`
+	Identifications   []euIdentification `xml:"identification"`
+	Addresses         []euAddress        `xml:"address"`
+}
+
+type euRegulation struct {
+	Programme       string `xml:"programme,attr"`
+	PublicationDate string `xml:"publicationDate,attr"`
+	NumberTitle     string `xml:"numberTitle,attr"`
+}
+
+type euSubjectType struct {
+	Code               string `xml:"code,attr"`
+	ClassificationCode string `xml:"classificationCode,attr"`
+}
+
+type euNameAlias struct {
+	FirstName  string `xml:"firstName,attr"`
+	MiddleName string `xml:"middleName,attr"`
+	LastName   string `xml:"lastName,attr"`
+	WholeName  string `xml:"wholeName,attr"`
+	Gender     string `xml:"gender,attr"`
+	Title      string `xml:"title,attr"`
+	Strong     string `xml:"strong,attr"`
+}
+
+type euCountry struct {
+	CountryISO2Code    string `xml:"countryIso2Code,attr"`
+	CountryDescription string `xml:"countryDescription,attr"`
+}
+
+type euBirthdate struct {
+	Birthdate          string `xml:"birthdate,attr"`
+	Year               string `xml:"year,attr"`
+	Circa              bool   `xml:"circa,attr"`
+	City               string `xml:"city,attr"`
+	Place              string `xml:"place,attr"`
+	CountryDescription string `xml:"countryDescription,attr"`
+}
+
+type euIdentification struct {
+	LogicalID              string `xml:"logicalId,attr"`
+	IdentificationTypeCode string `xml:"identificationTypeCode,attr"`
+	Number                 string `xml:"number,attr"`
+	CountryISO2Code        string `xml:"countryIso2Code,attr"`
+	CountryDescription     string `xml:"countryDescription,attr"`
+	IssuedDate             string `xml:"issuedDate,attr"`
+	ValidTo                string `xml:"validTo,attr"`
+	KnownExpired           bool   `xml:"knownExpired,attr"`
+	KnownFalse             bool   `xml:"knownFalse,attr"`
+	ReportedLost           bool   `xml:"reportedLost,attr"`
+	RevokedByIssuer        bool   `xml:"revokedByIssuer,attr"`
+}
+
+type euAddress struct {
+	LogicalID          string `xml:"logicalId,attr"`
+	Street             string `xml:"street,attr"`
+	PoBox              string `xml:"poBox,attr"`
+	City               string `xml:"city,attr"`
+	Region             string `xml:"region,attr"`
+	ZipCode            string `xml:"zipCode,attr"`
+	CountryISO2Code    string `xml:"countryIso2Code,attr"`
+	CountryDescription string `xml:"countryDescription,attr"`
+}
+
+// euEntityTypes maps EU subject type codes to sanction entity types
+var euEntityTypes = map[string]SanctionEntityType{
+	"PERSON":     EntityTypeIndividual,
+	"ENTERPRISE": EntityTypeOrganization,
+}
+
+// euDocumentTypes maps EU identification type codes to document types; other identifiers keep
+// their EU code
+var euDocumentTypes = map[string]string{
+	"PASSPORT": "PASSPORT",
+	"ID":       "NATIONAL_ID",
+	"SSN":      "SSN",
+	"TAXID":    "TAX_ID",
+}
+
+// ParseEUConsolidatedList parses the EU consolidated financial sanctions list XML. The first name
+// alias is the primary name and the others become aliases. Each entry is dated from its earliest
+// regulation, and documents known to be expired, false, lost or revoked are kept but inactive.
+func ParseEUConsolidatedList(document []byte, listID string) ([]ComprehensiveSanctionEntry, error) {
+	var export euSanctionsExport
+	if err := xml.Unmarshal(document, &export); err != nil {
+		return nil, fmt.Errorf("failed to parse EU consolidated list: %w", err)
+	}
+	if len(export.Entities) == 0 {
+		return nil, fmt.Errorf("EU consolidated list has no entries")
+	}
+	generated := parseEUDate(export.GenerationDate)
+
+	entries := make([]ComprehensiveSanctionEntry, 0, len(export.Entities))
+	for _, entity := range export.Entities {
+		entryID := strings.TrimSpace(entity.EUReferenceNumber)
+		if entryID == "" {
+			entryID = strings.TrimSpace(entity.LogicalID)
+		}
+		entityType, ok := euEntityTypes[strings.ToUpper(strings.TrimSpace(entity.SubjectType.Code))]
+		if !ok {
+			return nil, fmt.Errorf("EU entry %s has unknown subject type: %s", entryID, entity.SubjectType.Code)
+		}
+
+		entry := ComprehensiveSanctionEntry{
+			EntryID:            entryID,
+			ListID:             listID,
+			Aliases:            []string{},
+			EntityType:         entityType,
+			Addresses:          []SanctionAddress{},
+			IdentificationDocs: []IdentificationDoc{},
+			SanctionType:       string(SanctionListTypeEU),
+			IsActive:           true,
+			SourceReference:    entryID,
+			Remarks:            joinNonEmpty("; ", entity.Remarks...),
+		}
+		if un := strings.TrimSpace(entity.UnitedNationID); un != "" {
+			entry.Metadata = map[string]interface{}{"unitedNationID": un}
+		}
+
+		for i, alias := range entity.NameAliases {
+			name := strings.TrimSpace(alias.WholeName)
+			if name == "" {
+				name = joinNonEmpty(" ", alias.FirstName, alias.MiddleName, alias.LastName)
+			}
+			if i == 0 || entry.PrimaryName == "" {
+				entry.PrimaryName = name
+				if entityType == EntityTypeIndividual {
+					entry.FirstName = strings.TrimSpace(alias.FirstName)
+					entry.MiddleName = strings.TrimSpace(alias.MiddleName)
+					entry.LastName = strings.TrimSpace(alias.LastName)
+					entry.Gender = strings.ToUpper(strings.TrimSpace(alias.Gender))
+				}
+				continue
+			}
+			if name != entry.PrimaryName {
+				entry.Aliases = appendUnique(entry.Aliases, name)
+			}
+		}
+
+		for _, regulation := range entity.Regulations {
+			entry.Programs = appendUnique(entry.Programs, strings.TrimSpace(regulation.Programme))
+			if published := parseEUDate(regulation.PublicationDate); published != nil && (entry.SanctionDate.IsZero() || published.Before(entry.SanctionDate)) {
+				entry.SanctionDate = *published
+				entry.SanctionReason = strings.TrimSpace(regulation.NumberTitle)
+			}
+		}
+		if entry.SanctionDate.IsZero() && generated != nil {
+			entry.SanctionDate = *generated
+		}
+
+		for _, citizenship := range entity.Citizenships {
+			entry.Nationality = appendUnique(entry.Nationality, euCountryName(citizenship.CountryISO2Code, citizenship.CountryDescription))
+		}
+
+		datesOfBirth := []string{}
+		for _, birth := range entity.Birthdates {
+			value := strings.TrimSpace(birth.Birthdate)
+			if value == "" {
+				value = strings.TrimSpace(birth.Year)
+			}
+			if birth.Circa && value != "" {
+				value = "circa " + value
+			}
+			if value != "" {
+				datesOfBirth = append(datesOfBirth, value)
+			}
+			if parsed := parseEUDate(birth.Birthdate); parsed != nil && !birth.Circa && entry.DateOfBirth == nil {
+				entry.DateOfBirth = parsed
+			}
+			if entry.PlaceOfBirth == "" {
+				entry.PlaceOfBirth = joinNonEmpty(", ", birth.City, birth.Place, birth.CountryDescription)
+			}
+		}
+		setDatesOfBirth(&entry, datesOfBirth)
+
+		for _, identification := range entity.Identifications {
+			code := strings.ToUpper(strings.TrimSpace(identification.IdentificationTypeCode))
+			docType, ok := euDocumentTypes[code]
+			if !ok {
+				docType = code
+			}
+			entry.IdentificationDocs = append(entry.IdentificationDocs, IdentificationDoc{
+				DocID:          strings.TrimSpace(identification.LogicalID),
+				DocType:        docType,
+				DocNumber:      strings.TrimSpace(identification.Number),
+				IssuingCountry: euCountryName(identification.CountryISO2Code, identification.CountryDescription),
+				IssueDate:      parseEUDate(identification.IssuedDate),
+				ExpiryDate:     parseEUDate(identification.ValidTo),
+				IsActive:       !identification.KnownExpired && !identification.KnownFalse && !identification.ReportedLost && !identification.RevokedByIssuer,
+			})
+		}
+
+		for _, address := range entity.Addresses {
+			entry.Addresses = append(entry.Addresses, SanctionAddress{
+				AddressID:  strings.TrimSpace(address.LogicalID),
+				Street:     joinNonEmpty(", ", address.Street, address.PoBox),
+				City:       strings.TrimSpace(address.City),
+				State:      strings.TrimSpace(address.Region),
+				PostalCode: strings.TrimSpace(address.ZipCode),
+				Country:    euCountryName(address.CountryISO2Code, address.CountryDescription),
+				IsActive:   true,
+			})
+		}
+
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// parseEUDate parses an EU list date, such as "2012-03-23"; an empty or partial date gives nil
+func parseEUDate(value string) *time.Time {
+	value = strings.TrimSpace(value)
+	if len(value) < len("2006-01-02") {
+		return nil
+	}
+	parsed, err := time.Parse("2006-01-02", value[:len("2006-01-02")])
+	if err != nil {
+		return nil
+	}
+	return &parsed
+}
+
+// euCountryName prefers the ISO 3166 code the EU list gives, falling back to its description
+func euCountryName(iso2Code, description string) string {
+	if code := strings.ToUpper(strings.TrimSpace(iso2Code)); code != "" && code != "00" {
+		return code
+	}
+	return strings.TrimSpace(description)
+}
+
+// Helper functions
+
+// setDatesOfBirth keeps every date of birth a list gives as published, partial dates included
+func setDatesOfBirth(entry *ComprehensiveSanctionEntry, datesOfBirth []string) {
+	if len(datesOfBirth) == 0 {
+		return
+	}
+	if entry.Metadata == nil {
+		entry.Metadata = map[string]interface{}{}
+	}
+	entry.Metadata["datesOfBirth"] = datesOfBirth
+}
+
+func joinName(firstName, lastName string) string {
+	return joinNonEmpty(" ", firstName, lastName)
+}
+
+func joinNonEmpty(separator string, parts ...string) string {
+	values := []string{}
+	for _, part := range parts {
+		if part = strings.TrimSpace(part); part != "" {
+			values = append(values, part)
+		}
+	}
+	return strings.Join(values, separator)
+}
+
+func trimAll(values []string) []string {
+	trimmed := []string{}
+	for _, value := range values {
+		trimmed = appendUnique(trimmed, strings.TrimSpace(value))
+	}
+	return trimmed
+}
+
+// appendUnique appends a non-empty value not already present
+func appendUnique(values []string, value string) []string {
+	if value == "" {
+		return values
+	}
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
@@ -0,0 +1,220 @@
+package handlers
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const testOFACSDNDocument = `<?xml version="1.0" standalone="yes"?>
+<sdnList xmlns:xsi="http://www.w3.org/2001/XMLSchema-instance" xmlns="https://sanctionslistservice.ofac.treas.gov/api/PublicationPreview/exports/XML">
+  <publshInformation>
+    <Publish_Date>06/01/2026</Publish_Date>
+    <Record_Count>2</Record_Count>
+  </publshInformation>
+  <sdnEntry>
+    <uid>7157</uid>
+    <firstName>Viktor</firstName>
+    <lastName>PETROV</lastName>
+    <sdnType>Individual</sdnType>
+    <remarks>Linked to: PETROV HOLDINGS</remarks>
+    <programList><program>SDGT</program><program>CYBER2</program></programList>
+    <idList>
+      <id><uid>101</uid><idType>Passport</idType><idNumber>C1234567</idNumber><idCountry>Russia</idCountry><issueDate>05 Mar 2015</issueDate></id>
+      <id><uid>102</uid><idType>Gender</idType><idNumber>Male</idNumber></id>
+      <id><uid>103</uid><idType>Email Address</idType><idNumber>vp@example.com</idNumber></id>
+    </idList>
+    <akaList>
+      <aka><uid>201</uid><type>a.k.a.</type><category>strong</category><lastName>PETROFF</lastName><firstName>Victor</firstName></aka>
+      <aka><uid>202</uid><type>a.k.a.</type><category>weak</category><lastName>THE BANKER</lastName></aka>
+    </akaList>
+    <addressList>
+      <address><uid>301</uid><address1>12 Tverskaya Street</address1><address2>Office 4</address2><city>Moscow</city><country>Russia</country></address>
+    </addressList>
+    <nationalityList><nationality><uid>401</uid><country>Russia</country><mainEntry>true</mainEntry></nationality></nationalityList>
+    <citizenshipList><citizenship><uid>402</uid><country>Cyprus</country><mainEntry>false</mainEntry></citizenship></citizenshipList>
+    <dateOfBirthList>
+      <dateOfBirthItem><uid>501</uid><dateOfBirth>circa 1970</dateOfBirth><mainEntry>false</mainEntry></dateOfBirthItem>
+      <dateOfBirthItem><uid>502</uid><dateOfBirth>12 Mar 1971</dateOfBirth><mainEntry>true</mainEntry></dateOfBirthItem>
+    </dateOfBirthList>
+    <placeOfBirthList><placeOfBirthItem><uid>601</uid><placeOfBirth>Leningrad, Russia</placeOfBirth><mainEntry>true</mainEntry></placeOfBirthItem></placeOfBirthList>
+  </sdnEntry>
+  <sdnEntry>
+    <uid>9001</uid>
+    <lastName>PETROV HOLDINGS LIMITED</lastName>
+    <sdnType>Entity</sdnType>
+    <programList><program>SDGT</program></programList>
+    <idList><id><uid>103</uid><idType>Registration ID</idType><idNumber>HE123456</idNumber><idCountry>Cyprus</idCountry></id></idList>
+  </sdnEntry>
+</sdnList>`
+
+const testEUConsolidatedDocument = `<?xml version="1.0" encoding="UTF-8"?>
+<export xmlns="http://eu.europa.ec/fpi/fsd/export" generationDate="2026-06-01T10:00:00.000+02:00" globalFileId="123">
+  <sanctionEntity designationDetails="" unitedNationId="QDi.001" euReferenceNumber="EU.27.28" logicalId="13">
+    <remark>Head of a designated network.</remark>
+    <regulation regulationType="amendment" organisationType="commission" publicationDate="2014-05-20" entryIntoForceDate="2014-05-21" numberTitle="2014/512 (OJ L229)" programme="UKR" logicalId="200"/>
+    <regulation regulationType="regulation" organisationType="council" publicationDate="2012-03-23" entryIntoForceDate="2012-03-24" numberTitle="267/2012 (OJ L88)" programme="IRN" logicalId="201"/>
+    <subjectType code="person" classificationCode="P"/>
+    <nameAlias firstName="Ivan" middleName="Petrovich" lastName="Sidorov" wholeName="Ivan Petrovich Sidorov" gender="M" title="" strong="true" logicalId="17"/>
+    <nameAlias firstName="" middleName="" lastName="" wholeName="Иван Петрович Сидоров" gender="M" strong="true" logicalId="18"/>
+    <citizenship region="" countryIso2Code="RU" countryDescription="RUSSIAN FEDERATION" logicalId="19"/>
+    <birthdate circa="false" calendarType="GREGORIAN" city="Omsk" birthdate="1965-07-14" dayOfMonth="14" monthOfYear="7" year="1965" countryIso2Code="RU" countryDescription="RUSSIAN FEDERATION" logicalId="20"/>
+    <birthdate circa="true" calendarType="GREGORIAN" year="1966" logicalId="21"/>
+    <identification diplomatic="false" knownExpired="false" knownFalse="false" reportedLost="false" revokedByIssuer="false" issuedDate="2010-01-15" identificationTypeCode="passport" number="751234567" countryIso2Code="RU" countryDescription="RUSSIAN FEDERATION" logicalId="22"/>
+    <identification diplomatic="false" knownExpired="true" knownFalse="false" reportedLost="false" revokedByIssuer="false" identificationTypeCode="id" number="4509 123456" countryIso2Code="RU" logicalId="23"/>
+    <address city="Moscow" street="Arbat 10" poBox="" zipCode="119002" region="" countryIso2Code="RU" countryDescription="RUSSIAN FEDERATION" logicalId="24"/>
+  </sanctionEntity>
+  <sanctionEntity designationDetails="" unitedNationId="" euReferenceNumber="" logicalId="99">
+    <regulation publicationDate="2022-02-25" numberTitle="2022/330" programme="RUS" logicalId="300"/>
+    <subjectType code="enterprise" classificationCode="E"/>
+    <nameAlias wholeName="Example Export Bank JSC" strong="true" logicalId="30"/>
+    <nameAlias wholeName="EEB" strong="false" logicalId="31"/>
+  </sanctionEntity>
+</export>`
+
+func TestParseOFACSDNList(t *testing.T) {
+	entries, err := ParseOFACSDNList([]byte(testOFACSDNDocument), "OFAC_SDN")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	person := entries[0]
+	assert.Equal(t, "7157", person.EntryID)
+	assert.Equal(t, "OFAC_SDN", person.ListID)
+	assert.Equal(t, "Viktor PETROV", person.PrimaryName)
+	assert.Equal(t, EntityTypeIndividual, person.EntityType)
+	assert.Equal(t, "Viktor", person.FirstName)
+	assert.Equal(t, "PETROV", person.LastName)
+	assert.Equal(t, []string{"Victor PETROFF", "THE BANKER"}, person.Aliases)
+	assert.Equal(t, []string{"Russia", "Cyprus"}, person.Nationality)
+	assert.Equal(t, []string{"SDGT", "CYBER2"}, person.Programs)
+	assert.Equal(t, "MALE", person.Gender)
+	assert.Equal(t, "Leningrad, Russia", person.PlaceOfBirth)
+	assert.Equal(t, time.Date(2026, 6, 1, 0, 0, 0, 0, time.UTC), person.SanctionDate)
+	assert.Equal(t, "SDN", person.SanctionType)
+
+	// The main full date of birth is the entry's; every published date is kept
+	require.NotNil(t, person.DateOfBirth)
+	assert.Equal(t, time.Date(1971, 3, 12, 0, 0, 0, 0, time.UTC), *person.DateOfBirth)
+	assert.Equal(t, []string{"circa 1970", "12 Mar 1971"}, person.Metadata["datesOfBirth"])
+
+	require.Len(t, person.IdentificationDocs, 2)
+	passport := person.IdentificationDocs[0]
+	assert.Equal(t, "PASSPORT", passport.DocType)
+	assert.Equal(t, "C1234567", passport.DocNumber)
+	assert.Equal(t, "Russia", passport.IssuingCountry)
+	require.NotNil(t, passport.IssueDate)
+	assert.Equal(t, time.Date(2015, 3, 5, 0, 0, 0, 0, time.UTC), *passport.IssueDate)
+	assert.Equal(t, "EMAIL ADDRESS", person.IdentificationDocs[1].DocType)
+
+	require.Len(t, person.Addresses, 1)
+	assert.Equal(t, "12 Tverskaya Street, Office 4", person.Addresses[0].Street)
+	assert.Equal(t, "Moscow", person.Addresses[0].City)
+	assert.Equal(t, "Russia", person.Addresses[0].Country)
+
+	company := entries[1]
+	assert.Equal(t, "PETROV HOLDINGS LIMITED", company.PrimaryName)
+	assert.Equal(t, EntityTypeOrganization, company.EntityType)
+	assert.Empty(t, company.FirstName)
+	assert.Nil(t, company.DateOfBirth)
+	assert.Equal(t, "REGISTRATION ID", company.IdentificationDocs[0].DocType)
+
+	manager := NewSanctionListManager(&MockEventEmitter{})
+	for _, entry := range entries {
+		assert.NoError(t, manager.validateSanctionEntry(&entry))
+	}
+
+	_, err = ParseOFACSDNList([]byte(`<sdnList><sdnEntry><uid>1</uid><lastName>X</lastName><sdnType>Planet</sdnType></sdnEntry></sdnList>`), "OFAC_SDN")
+	assert.Contains(t, err.Error(), "unknown sdnType")
+	_, err = ParseOFACSDNList([]byte(`<sdnList></sdnList>`), "OFAC_SDN")
+	assert.Contains(t, err.Error(), "no entries")
+}
+
+func TestParseEUConsolidatedList(t *testing.T) {
+	entries, err := ParseEUConsolidatedList([]byte(testEUConsolidatedDocument), "EU_CFSP")
+	require.NoError(t, err)
+	require.Len(t, entries, 2)
+
+	person := entries[0]
+	assert.Equal(t, "EU.27.28", person.EntryID)
+	assert.Equal(t, "Ivan Petrovich Sidorov", person.PrimaryName)
+	assert.Equal(t, []string{"Иван Петрович Сидоров"}, person.Aliases)
+	assert.Equal(t, "Ivan", person.FirstName)
+	assert.Equal(t, "Petrovich", person.MiddleName)
+	assert.Equal(t, "Sidorov", person.LastName)
+	assert.Equal(t, "M", person.Gender)
+	assert.Equal(t, []string{"RU"}, person.Nationality)
+	assert.Equal(t, []string{"UKR", "IRN"}, person.Programs)
+	assert.Equal(t, "EU", person.SanctionType)
+	assert.Equal(t, "QDi.001", person.Metadata["unitedNationID"])
+	assert.Equal(t, "Head of a designated network.", person.Remarks)
+
+	// Dated from the earliest regulation listing the person
+	assert.Equal(t, time.Date(2012, 3, 23, 0, 0, 0, 0, time.UTC), person.SanctionDate)
+	assert.Equal(t, "267/2012 (OJ L88)", person.SanctionReason)
+
+	require.NotNil(t, person.DateOfBirth)
+	assert.Equal(t, time.Date(1965, 7, 14, 0, 0, 0, 0, time.UTC), *person.DateOfBirth)
+	assert.Equal(t, []string{"1965-07-14", "circa 1966"}, person.Metadata["datesOfBirth"])
+	assert.Equal(t, "Omsk, RUSSIAN FEDERATION", person.PlaceOfBirth)
+
+	require.Len(t, person.IdentificationDocs, 2)
+	assert.Equal(t, "PASSPORT", person.IdentificationDocs[0].DocType)
+	assert.Equal(t, "751234567", person.IdentificationDocs[0].DocNumber)
+	assert.Equal(t, "RU", person.IdentificationDocs[0].IssuingCountry)
+	assert.True(t, person.IdentificationDocs[0].IsActive)
+	assert.Equal(t, "NATIONAL_ID", person.IdentificationDocs[1].DocType)
+	assert.False(t, person.IdentificationDocs[1].IsActive)
+
+	require.Len(t, person.Addresses, 1)
+	assert.Equal(t, "Arbat 10", person.Addresses[0].Street)
+	assert.Equal(t, "119002", person.Addresses[0].PostalCode)
+
+	// An entity without an EU reference number is identified by its logical ID
+	company := entries[1]
+	assert.Equal(t, "99", company.EntryID)
+	assert.Equal(t, EntityTypeOrganization, company.EntityType)
+	assert.Equal(t, "Example Export Bank JSC", company.PrimaryName)
+	assert.Equal(t, []string{"EEB"}, company.Aliases)
+	assert.Empty(t, company.Gender)
+	assert.Nil(t, company.Metadata)
+
+	_, err = ParseEUConsolidatedList([]byte(`<export><sanctionEntity logicalId="1"><subjectType code="planet"/></sanctionEntity></export>`), "EU_CFSP")
+	assert.Contains(t, err.Error(), "unknown subject type")
+}
+
+func TestResolveSanctionListDocument(t *testing.T) {
+	digest := sha256.Sum256([]byte(testOFACSDNDocument))
+
+	updateReq := &SanctionListUpdateRequest{ListID: "OFAC_SDN", Format: "ofac_sdn_xml", Document: testOFACSDNDocument, Checksum: hex.EncodeToString(digest[:])}
+	require.NoError(t, resolveSanctionListDocument(updateReq))
+	assert.Len(t, updateReq.Entries, 2)
+	assert.Empty(t, updateReq.Document)
+
+	// Updates carrying entries pass through unchanged
+	updateReq = &SanctionListUpdateRequest{ListID: "OFAC_SDN", Entries: []ComprehensiveSanctionEntry{{EntryID: "1"}}, Checksum: "abc123"}
+	require.NoError(t, resolveSanctionListDocument(updateReq))
+	assert.Len(t, updateReq.Entries, 1)
+
+	tests := []struct {
+		name      string
+		updateReq SanctionListUpdateRequest
+		expected  string
+	}{
+		{"format without document", SanctionListUpdateRequest{Format: SanctionListFormatOFACSDN}, "requires a document"},
+		{"entries and document", SanctionListUpdateRequest{Format: SanctionListFormatOFACSDN, Document: testOFACSDNDocument, Entries: []ComprehensiveSanctionEntry{{EntryID: "1"}}}, "not both"},
+		{"checksum mismatch", SanctionListUpdateRequest{Format: SanctionListFormatOFACSDN, Document: testOFACSDNDocument, Checksum: "abc123"}, "does not match checksum"},
+		{"unknown format", SanctionListUpdateRequest{Format: "UN_XML", Document: testOFACSDNDocument}, "unsupported sanction list format"},
+		{"wrong format", SanctionListUpdateRequest{Format: SanctionListFormatEUConsolidated, Document: testOFACSDNDocument}, "EU consolidated list has no entries"},
+	}
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			err := resolveSanctionListDocument(&tt.updateReq)
+			require.Error(t, err)
+			assert.Contains(t, err.Error(), tt.expected)
+		})
+	}
+}
@@ -1,16 +1,18 @@
 package handlers
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
 	"fmt"
 	"strings"
 	"time"
+	"unicode/utf8"
 
 	"github.com/hyperledger/fabric-chaincode-go/shim"
 	"github.com/brycemacchaveli/origin.block/fabric-chaincode/compliance/domain"
 	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
 	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
-	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
 )
 
 // SanctionListManager handles comprehensive sanction list management
@@ -150,6 +152,8 @@ type SanctionListUpdateRequest struct {
 	ListID      string                        `json:"listID"`
 	UpdateType  SanctionUpdateType            `json:"updateType"`
 	Entries     []ComprehensiveSanctionEntry  `json:"entries,omitempty"`
+	Format      string                        `json:"format,omitempty"`   // OFAC_SDN_XML or EU_CONSOLIDATED_XML when Document is set
+	Document    string                        `json:"document,omitempty"` // Official list document, parsed into the entries
 	Version     string                        `json:"version"`
 	Checksum    string                        `json:"checksum"`
 	UpdatedBy   string                        `json:"updatedBy"`
@@ -169,26 +173,29 @@ const (
 // CreateSanctionList creates a new sanction list definition
 func (m *SanctionListManager) CreateSanctionList(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
 	if len(args) != 1 {
-		return nil, fmt.Errorf("incorrect number of arguments. Expected 1, got %d", len(args))
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
 	}
 
 	var listDef SanctionListDefinition
 	if err := json.Unmarshal([]byte(args[0]), &listDef); err != nil {
-		return nil, fmt.Errorf("failed to parse sanction list definition: %v", err)
+		return nil, fmt.Errorf("failed to parse sanction list definition: %w", err)
 	}
 
 	// Validate list definition
 	if err := m.validateSanctionListDefinition(&listDef); err != nil {
-		return nil, fmt.Errorf("invalid sanction list definition: %v", err)
+		return nil, fmt.Errorf("invalid sanction list definition: %w", err)
 	}
 
 	// Generate list ID if not provided
 	if listDef.ListID == "" {
-		listDef.ListID = utils.GenerateID(config.SanctionListPrefix)
+		listDef.ListID = services.GenerateDeterministicID(stub, config.SanctionListPrefix)
 	}
 
 	// Set timestamps
-	now := time.Now()
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
 	listDef.CreatedDate = now
 	listDef.LastModifiedDate = now
 	listDef.LastUpdated = now
@@ -197,17 +204,17 @@ func (m *SanctionListManager) CreateSanctionList(stub shim.ChaincodeStubInterfac
 	// Store sanction list definition
 	listKey := fmt.Sprintf("SANCTION_LIST_%s", listDef.ListID)
 	if err := m.persistenceService.Put(stub, listKey, &listDef); err != nil {
-		return nil, fmt.Errorf("failed to store sanction list definition: %v", err)
+		return nil, fmt.Errorf("failed to store sanction list definition: %w", err)
 	}
 
 	// Create indexes
 	if err := m.createSanctionListIndexes(stub, &listDef); err != nil {
-		return nil, fmt.Errorf("failed to create indexes: %v", err)
+		return nil, fmt.Errorf("failed to create indexes: %w", err)
 	}
 
 	// Record compliance event
 	if err := m.recordSanctionListEvent(stub, "SANCTION_LIST_CREATED", &listDef, listDef.CreatedBy); err != nil {
-		return nil, fmt.Errorf("failed to record event: %v", err)
+		return nil, fmt.Errorf("failed to record event: %w", err)
 	}
 
 	return json.Marshal(&listDef)
@@ -216,49 +223,57 @@ func (m *SanctionListManager) CreateSanctionList(stub shim.ChaincodeStubInterfac
 // UpdateSanctionList updates an existing sanction list with new entries
 func (m *SanctionListManager) UpdateSanctionList(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
 	if len(args) != 1 {
-		return nil, fmt.Errorf("incorrect number of arguments. Expected 1, got %d", len(args))
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
 	}
 
 	var updateReq SanctionListUpdateRequest
 	if err := json.Unmarshal([]byte(args[0]), &updateReq); err != nil {
-		return nil, fmt.Errorf("failed to parse sanction list update request: %v", err)
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse sanction list update request: %v", err)
+	}
+	if err := resolveSanctionListDocument(&updateReq); err != nil {
+		return nil, err
 	}
 
 	// Get existing list definition
 	listKey := fmt.Sprintf("SANCTION_LIST_%s", updateReq.ListID)
 	var listDef SanctionListDefinition
 	if err := m.persistenceService.Get(stub, listKey, &listDef); err != nil {
-		return nil, fmt.Errorf("sanction list not found: %v", err)
+		return nil, fmt.Errorf("sanction list not found: %w", err)
 	}
 
 	// Validate update request
 	if err := m.validateSanctionListUpdate(&updateReq, &listDef); err != nil {
-		return nil, fmt.Errorf("invalid update request: %v", err)
+		return nil, fmt.Errorf("invalid update request: %w", err)
 	}
 
 	// Process update based on type
 	updateResult, err := m.processSanctionListUpdate(stub, &updateReq, &listDef)
 	if err != nil {
-		return nil, fmt.Errorf("failed to process update: %v", err)
+		return nil, fmt.Errorf("failed to process update: %w", err)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
 	}
 
 	// Update list definition
 	listDef.Version = updateReq.Version
 	listDef.Checksum = updateReq.Checksum
-	listDef.LastUpdated = time.Now()
+	listDef.LastUpdated = now
 	listDef.LastModifiedBy = updateReq.UpdatedBy
-	listDef.LastModifiedDate = time.Now()
-	listDef.NextUpdate = m.calculateNextUpdate(listDef.UpdateFrequency, time.Now())
+	listDef.LastModifiedDate = now
+	listDef.NextUpdate = m.calculateNextUpdate(listDef.UpdateFrequency, now)
 	listDef.EntryCount = updateResult.TotalEntries
 
 	// Store updated list definition
 	if err := m.persistenceService.Put(stub, listKey, &listDef); err != nil {
-		return nil, fmt.Errorf("failed to update sanction list definition: %v", err)
+		return nil, fmt.Errorf("failed to update sanction list definition: %w", err)
 	}
 
 	// Record compliance event
 	if err := m.recordSanctionListEvent(stub, "SANCTION_LIST_UPDATED", &listDef, updateReq.UpdatedBy); err != nil {
-		return nil, fmt.Errorf("failed to record event: %v", err)
+		return nil, fmt.Errorf("failed to record event: %w", err)
 	}
 
 	return json.Marshal(updateResult)
@@ -273,49 +288,48 @@ type SanctionListUpdateResult struct {
 	UpdatedEntries int       `json:"updatedEntries"`
 	RemovedEntries int       `json:"removedEntries"`
 	UpdateDate     time.Time `json:"updateDate"`
-	ProcessingTime int64     `json:"processingTimeMs"`
 	Errors         []string  `json:"errors,omitempty"`
 }
 
 // processSanctionListUpdate processes the sanction list update
 func (m *SanctionListManager) processSanctionListUpdate(stub shim.ChaincodeStubInterface, updateReq *SanctionListUpdateRequest, listDef *SanctionListDefinition) (*SanctionListUpdateResult, error) {
-	startTime := time.Now()
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
 	
 	result := &SanctionListUpdateResult{
 		ListID:     updateReq.ListID,
 		UpdateType: updateReq.UpdateType,
-		UpdateDate: time.Now(),
+		UpdateDate: now,
 		Errors:     []string{},
 	}
 
 	switch updateReq.UpdateType {
 	case UpdateTypeFull:
-		return m.processFullReplaceUpdate(stub, updateReq, result)
+		return m.processFullReplaceUpdate(stub, updateReq, listDef, result)
 	case UpdateTypeIncremental:
-		return m.processIncrementalUpdate(stub, updateReq, result)
+		return m.processIncrementalUpdate(stub, updateReq, listDef, result)
 	case UpdateTypeAdditions:
-		return m.processAdditionsOnlyUpdate(stub, updateReq, result)
+		return m.processAdditionsOnlyUpdate(stub, updateReq, listDef, result)
 	case UpdateTypeRemovals:
-		return m.processRemovalsOnlyUpdate(stub, updateReq, result)
+		return m.processRemovalsOnlyUpdate(stub, updateReq, listDef, result)
 	default:
 		return nil, fmt.Errorf("unsupported update type: %s", updateReq.UpdateType)
 	}
-
-	result.ProcessingTime = time.Since(startTime).Milliseconds()
-	return result, nil
 }
 
 // processFullReplaceUpdate replaces all entries in the sanction list
-func (m *SanctionListManager) processFullReplaceUpdate(stub shim.ChaincodeStubInterface, updateReq *SanctionListUpdateRequest, result *SanctionListUpdateResult) (*SanctionListUpdateResult, error) {
+func (m *SanctionListManager) processFullReplaceUpdate(stub shim.ChaincodeStubInterface, updateReq *SanctionListUpdateRequest, listDef *SanctionListDefinition, result *SanctionListUpdateResult) (*SanctionListUpdateResult, error) {
 	// Remove all existing entries
 	if err := m.removeAllSanctionEntries(stub, updateReq.ListID); err != nil {
-		return nil, fmt.Errorf("failed to remove existing entries: %v", err)
+		return nil, fmt.Errorf("failed to remove existing entries: %w", err)
 	}
 
 	// Add all new entries
 	addedCount := 0
 	for _, entry := range updateReq.Entries {
-		if err := m.addSanctionEntry(stub, &entry); err != nil {
+		if err := m.addSanctionEntry(stub, listDef, &entry); err != nil {
 			result.Errors = append(result.Errors, fmt.Sprintf("Failed to add entry %s: %v", entry.EntryID, err))
 			continue
 		}
@@ -330,14 +344,13 @@ func (m *SanctionListManager) processFullReplaceUpdate(stub shim.ChaincodeStubIn
 }
 
 // processIncrementalUpdate processes incremental updates (adds, updates, removes)
-func (m *SanctionListManager) processIncrementalUpdate(stub shim.ChaincodeStubInterface, updateReq *SanctionListUpdateRequest, result *SanctionListUpdateResult) (*SanctionListUpdateResult, error) {
+func (m *SanctionListManager) processIncrementalUpdate(stub shim.ChaincodeStubInterface, updateReq *SanctionListUpdateRequest, listDef *SanctionListDefinition, result *SanctionListUpdateResult) (*SanctionListUpdateResult, error) {
 	addedCount := 0
 	updatedCount := 0
 
 	for _, entry := range updateReq.Entries {
 		// Check if entry exists
-		entryKey := fmt.Sprintf("SANCTION_ENTRY_%s_%s", updateReq.ListID, entry.EntryID)
-		existingEntryBytes, err := stub.GetState(entryKey)
+		existingEntryBytes, err := stub.GetState(sanctionEntryKey(managedSanctionEntryID(updateReq.ListID, entry.EntryID)))
 		
 		if err != nil {
 			result.Errors = append(result.Errors, fmt.Sprintf("Error checking entry %s: %v", entry.EntryID, err))
@@ -346,14 +359,14 @@ func (m *SanctionListManager) processIncrementalUpdate(stub shim.ChaincodeStubIn
 
 		if existingEntryBytes == nil {
 			// New entry - add it
-			if err := m.addSanctionEntry(stub, &entry); err != nil {
+			if err := m.addSanctionEntry(stub, listDef, &entry); err != nil {
 				result.Errors = append(result.Errors, fmt.Sprintf("Failed to add entry %s: %v", entry.EntryID, err))
 				continue
 			}
 			addedCount++
 		} else {
 			// Existing entry - update it
-			if err := m.updateSanctionEntry(stub, &entry); err != nil {
+			if err := m.updateSanctionEntry(stub, listDef, &entry); err != nil {
 				result.Errors = append(result.Errors, fmt.Sprintf("Failed to update entry %s: %v", entry.EntryID, err))
 				continue
 			}
@@ -364,7 +377,7 @@ func (m *SanctionListManager) processIncrementalUpdate(stub shim.ChaincodeStubIn
 	// Get total count
 	totalCount, err := m.getSanctionEntryCount(stub, updateReq.ListID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get total entry count: %v", err)
+		return nil, fmt.Errorf("failed to get total entry count: %w", err)
 	}
 
 	result.TotalEntries = totalCount
@@ -375,13 +388,12 @@ func (m *SanctionListManager) processIncrementalUpdate(stub shim.ChaincodeStubIn
 }
 
 // processAdditionsOnlyUpdate processes additions-only updates
-func (m *SanctionListManager) processAdditionsOnlyUpdate(stub shim.ChaincodeStubInterface, updateReq *SanctionListUpdateRequest, result *SanctionListUpdateResult) (*SanctionListUpdateResult, error) {
+func (m *SanctionListManager) processAdditionsOnlyUpdate(stub shim.ChaincodeStubInterface, updateReq *SanctionListUpdateRequest, listDef *SanctionListDefinition, result *SanctionListUpdateResult) (*SanctionListUpdateResult, error) {
 	addedCount := 0
 
 	for _, entry := range updateReq.Entries {
 		// Check if entry already exists
-		entryKey := fmt.Sprintf("SANCTION_ENTRY_%s_%s", updateReq.ListID, entry.EntryID)
-		existingEntryBytes, err := stub.GetState(entryKey)
+		existingEntryBytes, err := stub.GetState(sanctionEntryKey(managedSanctionEntryID(updateReq.ListID, entry.EntryID)))
 		
 		if err != nil {
 			result.Errors = append(result.Errors, fmt.Sprintf("Error checking entry %s: %v", entry.EntryID, err))
@@ -394,7 +406,7 @@ func (m *SanctionListManager) processAdditionsOnlyUpdate(stub shim.ChaincodeStub
 		}
 
 		// Add new entry
-		if err := m.addSanctionEntry(stub, &entry); err != nil {
+		if err := m.addSanctionEntry(stub, listDef, &entry); err != nil {
 			result.Errors = append(result.Errors, fmt.Sprintf("Failed to add entry %s: %v", entry.EntryID, err))
 			continue
 		}
@@ -404,7 +416,7 @@ func (m *SanctionListManager) processAdditionsOnlyUpdate(stub shim.ChaincodeStub
 	// Get total count
 	totalCount, err := m.getSanctionEntryCount(stub, updateReq.ListID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get total entry count: %v", err)
+		return nil, fmt.Errorf("failed to get total entry count: %w", err)
 	}
 
 	result.TotalEntries = totalCount
@@ -414,7 +426,7 @@ func (m *SanctionListManager) processAdditionsOnlyUpdate(stub shim.ChaincodeStub
 }
 
 // processRemovalsOnlyUpdate processes removals-only updates
-func (m *SanctionListManager) processRemovalsOnlyUpdate(stub shim.ChaincodeStubInterface, updateReq *SanctionListUpdateRequest, result *SanctionListUpdateResult) (*SanctionListUpdateResult, error) {
+func (m *SanctionListManager) processRemovalsOnlyUpdate(stub shim.ChaincodeStubInterface, updateReq *SanctionListUpdateRequest, listDef *SanctionListDefinition, result *SanctionListUpdateResult) (*SanctionListUpdateResult, error) {
 	removedCount := 0
 
 	for _, entry := range updateReq.Entries {
@@ -428,7 +440,7 @@ func (m *SanctionListManager) processRemovalsOnlyUpdate(stub shim.ChaincodeStubI
 	// Get total count
 	totalCount, err := m.getSanctionEntryCount(stub, updateReq.ListID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get total entry count: %v", err)
+		return nil, fmt.Errorf("failed to get total entry count: %w", err)
 	}
 
 	result.TotalEntries = totalCount
@@ -439,60 +451,82 @@ func (m *SanctionListManager) processRemovalsOnlyUpdate(stub shim.ChaincodeStubI
 
 // Helper methods for sanction entry management
 
-func (m *SanctionListManager) addSanctionEntry(stub shim.ChaincodeStubInterface, entry *ComprehensiveSanctionEntry) error {
+func (m *SanctionListManager) addSanctionEntry(stub shim.ChaincodeStubInterface, listDef *SanctionListDefinition, entry *ComprehensiveSanctionEntry) error {
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return err
+	}
+
 	// Set timestamps
-	entry.LastUpdated = time.Now()
+	entry.LastUpdated = now
 	entry.IsActive = true
 
-	// Store entry
-	entryKey := fmt.Sprintf("SANCTION_ENTRY_%s_%s", entry.ListID, entry.EntryID)
-	if err := m.persistenceService.Put(stub, entryKey, entry); err != nil {
-		return fmt.Errorf("failed to store sanction entry: %v", err)
-	}
+	return m.putSanctionEntry(stub, listDef, entry)
+}
 
-	// Create search indexes
-	if err := m.createSanctionEntryIndexes(stub, entry); err != nil {
-		return fmt.Errorf("failed to create entry indexes: %v", err)
+func (m *SanctionListManager) updateSanctionEntry(stub shim.ChaincodeStubInterface, listDef *SanctionListDefinition, entry *ComprehensiveSanctionEntry) error {
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return err
 	}
 
-	return nil
+	// Update timestamp
+	entry.LastUpdated = now
+
+	return m.putSanctionEntry(stub, listDef, entry)
 }
 
-func (m *SanctionListManager) updateSanctionEntry(stub shim.ChaincodeStubInterface, entry *ComprehensiveSanctionEntry) error {
-	// Update timestamp
-	entry.LastUpdated = time.Now()
+// putSanctionEntry writes an imported entry to the sanction store screening reads, keeping the
+// full record alongside, and refreshes the search indexes of the list
+func (m *SanctionListManager) putSanctionEntry(stub shim.ChaincodeStubInterface, listDef *SanctionListDefinition, entry *ComprehensiveSanctionEntry) error {
+	entry.ListID = listDef.ListID
+
+	// Drop search indexes of the previous version
+	if existing, err := m.getSanctionEntry(stub, entry.ListID, entry.EntryID); err == nil {
+		if err := m.removeSanctionEntryIndexes(stub, existing); err != nil {
+			return fmt.Errorf("failed to remove entry indexes: %w", err)
+		}
+	}
 
-	// Store updated entry
-	entryKey := fmt.Sprintf("SANCTION_ENTRY_%s_%s", entry.ListID, entry.EntryID)
-	if err := m.persistenceService.Put(stub, entryKey, entry); err != nil {
-		return fmt.Errorf("failed to update sanction entry: %v", err)
+	if err := PutSanctionListEntry(stub, screeningSanctionEntry(listDef, entry)); err != nil {
+		return err
 	}
 
-	// Update search indexes
-	if err := m.updateSanctionEntryIndexes(stub, entry); err != nil {
-		return fmt.Errorf("failed to update entry indexes: %v", err)
+	// Create search indexes
+	if err := m.createSanctionEntryIndexes(stub, entry); err != nil {
+		return fmt.Errorf("failed to create entry indexes: %w", err)
 	}
 
 	return nil
 }
 
+// getSanctionEntry loads the full record of an entry imported into a list
+func (m *SanctionListManager) getSanctionEntry(stub shim.ChaincodeStubInterface, listID, entryID string) (*ComprehensiveSanctionEntry, error) {
+	stored, err := GetSanctionListEntry(stub, managedSanctionEntryID(listID, entryID))
+	if err != nil {
+		return nil, err
+	}
+	if stored.Details == nil {
+		return nil, fmt.Errorf("sanction entry %s was not imported into list %s", entryID, listID)
+	}
+	return stored.Details, nil
+}
+
 func (m *SanctionListManager) removeSanctionEntry(stub shim.ChaincodeStubInterface, listID, entryID string) error {
-	entryKey := fmt.Sprintf("SANCTION_ENTRY_%s_%s", listID, entryID)
-	
 	// Get entry before deletion for index cleanup
-	var entry ComprehensiveSanctionEntry
-	if err := m.persistenceService.Get(stub, entryKey, &entry); err != nil {
-		return fmt.Errorf("sanction entry not found: %v", err)
+	entry, err := m.getSanctionEntry(stub, listID, entryID)
+	if err != nil {
+		return fmt.Errorf("sanction entry not found: %w", err)
 	}
 
 	// Remove entry
-	if err := stub.DelState(entryKey); err != nil {
-		return fmt.Errorf("failed to delete sanction entry: %v", err)
+	if err := DeleteSanctionListEntry(stub, managedSanctionEntryID(listID, entryID)); err != nil {
+		return fmt.Errorf("failed to delete sanction entry: %w", err)
 	}
 
 	// Remove indexes
-	if err := m.removeSanctionEntryIndexes(stub, &entry); err != nil {
-		return fmt.Errorf("failed to remove entry indexes: %v", err)
+	if err := m.removeSanctionEntryIndexes(stub, entry); err != nil {
+		return fmt.Errorf("failed to remove entry indexes: %w", err)
 	}
 
 	return nil
@@ -500,21 +534,25 @@ func (m *SanctionListManager) removeSanctionEntry(stub shim.ChaincodeStubInterfa
 
 func (m *SanctionListManager) removeAllSanctionEntries(stub shim.ChaincodeStubInterface, listID string) error {
 	// Get all entries for the list
-	iterator, err := stub.GetStateByPartialCompositeKey("SANCTION_ENTRY", []string{listID})
+	entryIDs, err := SanctionListEntryIDs(stub, listID)
 	if err != nil {
-		return fmt.Errorf("failed to get sanction entries: %v", err)
+		return err
 	}
-	defer iterator.Close()
 
-	for iterator.HasNext() {
-		response, err := iterator.Next()
+	for _, storedID := range entryIDs {
+		stored, err := GetSanctionListEntry(stub, storedID)
 		if err != nil {
-			return fmt.Errorf("failed to iterate sanction entries: %v", err)
+			return fmt.Errorf("failed to get sanction entry %s: %w", storedID, err)
+		}
+		if stored.Details != nil {
+			if err := m.removeSanctionEntryIndexes(stub, stored.Details); err != nil {
+				return fmt.Errorf("failed to remove entry indexes: %w", err)
+			}
 		}
 
 		// Delete entry
-		if err := stub.DelState(response.Key); err != nil {
-			return fmt.Errorf("failed to delete entry %s: %v", response.Key, err)
+		if err := DeleteSanctionListEntry(stub, storedID); err != nil {
+			return fmt.Errorf("failed to delete entry %s: %w", storedID, err)
 		}
 	}
 
@@ -522,22 +560,41 @@ func (m *SanctionListManager) removeAllSanctionEntries(stub shim.ChaincodeStubIn
 }
 
 func (m *SanctionListManager) getSanctionEntryCount(stub shim.ChaincodeStubInterface, listID string) (int, error) {
-	iterator, err := stub.GetStateByPartialCompositeKey("SANCTION_ENTRY", []string{listID})
+	entryIDs, err := SanctionListEntryIDs(stub, listID)
 	if err != nil {
-		return 0, fmt.Errorf("failed to get sanction entries: %v", err)
-	}
-	defer iterator.Close()
-
-	count := 0
-	for iterator.HasNext() {
-		_, err := iterator.Next()
-		if err != nil {
-			return 0, fmt.Errorf("failed to iterate sanction entries: %v", err)
-		}
-		count++
+		return 0, err
 	}
+	return len(entryIDs), nil
+}
 
-	return count, nil
+// screeningSanctionEntry projects an imported entry onto the record screening matches against
+func screeningSanctionEntry(listDef *SanctionListDefinition, entry *ComprehensiveSanctionEntry) *SanctionListEntry {
+	screened := &SanctionListEntry{
+		EntryID:      managedSanctionEntryID(listDef.ListID, entry.EntryID),
+		ListID:       listDef.ListID,
+		ListName:     listDef.ListName,
+		EntityName:   entry.PrimaryName,
+		EntityType:   string(entry.EntityType),
+		Aliases:      entry.Aliases,
+		PlaceOfBirth: entry.PlaceOfBirth,
+		Nationality:  strings.Join(entry.Nationality, ", "),
+		SanctionType: entry.SanctionType,
+		ListingDate:  entry.SanctionDate,
+		LastUpdated:  entry.LastUpdated,
+		IsActive:     entry.IsActive,
+		Source:       listDef.Source,
+		Details:      entry,
+	}
+	if entry.DateOfBirth != nil {
+		screened.DateOfBirth = entry.DateOfBirth.Format("2006-01-02")
+	}
+	if len(entry.IdentificationDocs) > 0 {
+		screened.IdentificationNo = entry.IdentificationDocs[0].DocNumber
+	}
+	if screened.ListingDate.IsZero() {
+		screened.ListingDate = entry.LastUpdated
+	}
+	return screened
 }
 
 // Index management methods
@@ -546,28 +603,28 @@ func (m *SanctionListManager) createSanctionListIndexes(stub shim.ChaincodeStubI
 	// Create index by source
 	sourceKey, err := stub.CreateCompositeKey("SANCTION_LIST_BY_SOURCE", []string{listDef.Source, listDef.ListID})
 	if err != nil {
-		return fmt.Errorf("failed to create source index key: %v", err)
+		return fmt.Errorf("failed to create source index key: %w", err)
 	}
 	if err := stub.PutState(sourceKey, []byte(listDef.ListID)); err != nil {
-		return fmt.Errorf("failed to create source index: %v", err)
+		return fmt.Errorf("failed to create source index: %w", err)
 	}
 
 	// Create index by type
 	typeKey, err := stub.CreateCompositeKey("SANCTION_LIST_BY_TYPE", []string{string(listDef.ListType), listDef.ListID})
 	if err != nil {
-		return fmt.Errorf("failed to create type index key: %v", err)
+		return fmt.Errorf("failed to create type index key: %w", err)
 	}
 	if err := stub.PutState(typeKey, []byte(listDef.ListID)); err != nil {
-		return fmt.Errorf("failed to create type index: %v", err)
+		return fmt.Errorf("failed to create type index: %w", err)
 	}
 
 	// Create index by jurisdiction
 	jurisdictionKey, err := stub.CreateCompositeKey("SANCTION_LIST_BY_JURISDICTION", []string{listDef.Jurisdiction, listDef.ListID})
 	if err != nil {
-		return fmt.Errorf("failed to create jurisdiction index key: %v", err)
+		return fmt.Errorf("failed to create jurisdiction index key: %w", err)
 	}
 	if err := stub.PutState(jurisdictionKey, []byte(listDef.ListID)); err != nil {
-		return fmt.Errorf("failed to create jurisdiction index: %v", err)
+		return fmt.Errorf("failed to create jurisdiction index: %w", err)
 	}
 
 	return nil
@@ -577,10 +634,10 @@ func (m *SanctionListManager) createSanctionEntryIndexes(stub shim.ChaincodeStub
 	// Create index by primary name
 	nameKey, err := stub.CreateCompositeKey("SANCTION_ENTRY_BY_NAME", []string{strings.ToUpper(entry.PrimaryName), entry.ListID, entry.EntryID})
 	if err != nil {
-		return fmt.Errorf("failed to create name index key: %v", err)
+		return fmt.Errorf("failed to create name index key: %w", err)
 	}
 	if err := stub.PutState(nameKey, []byte(entry.EntryID)); err != nil {
-		return fmt.Errorf("failed to create name index: %v", err)
+		return fmt.Errorf("failed to create name index: %w", err)
 	}
 
 	// Create indexes for aliases
@@ -595,24 +652,15 @@ func (m *SanctionListManager) createSanctionEntryIndexes(stub shim.ChaincodeStub
 	// Create index by entity type
 	entityTypeKey, err := stub.CreateCompositeKey("SANCTION_ENTRY_BY_ENTITY_TYPE", []string{string(entry.EntityType), entry.ListID, entry.EntryID})
 	if err != nil {
-		return fmt.Errorf("failed to create entity type index key: %v", err)
+		return fmt.Errorf("failed to create entity type index key: %w", err)
 	}
 	if err := stub.PutState(entityTypeKey, []byte(entry.EntryID)); err != nil {
-		return fmt.Errorf("failed to create entity type index: %v", err)
+		return fmt.Errorf("failed to create entity type index: %w", err)
 	}
 
 	return nil
 }
 
-func (m *SanctionListManager) updateSanctionEntryIndexes(stub shim.ChaincodeStubInterface, entry *ComprehensiveSanctionEntry) error {
-	// For simplicity, remove old indexes and create new ones
-	// In a production system, you might want to be more selective
-	if err := m.removeSanctionEntryIndexes(stub, entry); err != nil {
-		return err
-	}
-	return m.createSanctionEntryIndexes(stub, entry)
-}
-
 func (m *SanctionListManager) removeSanctionEntryIndexes(stub shim.ChaincodeStubInterface, entry *ComprehensiveSanctionEntry) error {
 	// Remove name index
 	nameKey, err := stub.CreateCompositeKey("SANCTION_ENTRY_BY_NAME", []string{strings.ToUpper(entry.PrimaryName), entry.ListID, entry.EntryID})
@@ -645,15 +693,15 @@ func (m *SanctionListManager) validateSanctionListDefinition(listDef *SanctionLi
 	}
 
 	if listDef.Source == "" {
-		return fmt.Errorf("source is required")
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "source", "source is required")
 	}
 
 	if listDef.Jurisdiction == "" {
-		return fmt.Errorf("jurisdiction is required")
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "jurisdiction", "jurisdiction is required")
 	}
 
 	if listDef.CreatedBy == "" {
-		return fmt.Errorf("createdBy is required")
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "createdBy", "createdBy is required")
 	}
 
 	// Validate list type
@@ -672,7 +720,7 @@ func (m *SanctionListManager) validateSanctionListDefinition(listDef *SanctionLi
 	}
 
 	if !validType {
-		return fmt.Errorf("invalid list type: %s", listDef.ListType)
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid list type: %s", listDef.ListType)
 	}
 
 	return nil
@@ -680,15 +728,15 @@ func (m *SanctionListManager) validateSanctionListDefinition(listDef *SanctionLi
 
 func (m *SanctionListManager) validateSanctionListUpdate(updateReq *SanctionListUpdateRequest, listDef *SanctionListDefinition) error {
 	if updateReq.ListID == "" {
-		return fmt.Errorf("listID is required")
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "listID", "listID is required")
 	}
 
 	if updateReq.UpdatedBy == "" {
-		return fmt.Errorf("updatedBy is required")
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "updatedBy", "updatedBy is required")
 	}
 
 	if updateReq.Version == "" {
-		return fmt.Errorf("version is required")
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "version", "version is required")
 	}
 
 	// Validate update type
@@ -705,13 +753,13 @@ func (m *SanctionListManager) validateSanctionListUpdate(updateReq *SanctionList
 	}
 
 	if !validUpdateType {
-		return fmt.Errorf("invalid update type: %s", updateReq.UpdateType)
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid update type: %s", updateReq.UpdateType)
 	}
 
 	// Validate entries if provided
 	for i, entry := range updateReq.Entries {
 		if err := m.validateSanctionEntry(&entry); err != nil {
-			return fmt.Errorf("invalid entry at index %d: %v", i, err)
+			return fmt.Errorf("invalid entry at index %d: %w", i, err)
 		}
 	}
 
@@ -720,15 +768,15 @@ func (m *SanctionListManager) validateSanctionListUpdate(updateReq *SanctionList
 
 func (m *SanctionListManager) validateSanctionEntry(entry *ComprehensiveSanctionEntry) error {
 	if entry.EntryID == "" {
-		return fmt.Errorf("entryID is required")
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "entryID", "entryID is required")
 	}
 
 	if entry.PrimaryName == "" {
-		return fmt.Errorf("primaryName is required")
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "primaryName", "primaryName is required")
 	}
 
 	if entry.EntityType == "" {
-		return fmt.Errorf("entityType is required")
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "entityType", "entityType is required")
 	}
 
 	// Validate entity type
@@ -745,12 +793,44 @@ func (m *SanctionListManager) validateSanctionEntry(entry *ComprehensiveSanction
 	}
 
 	if !validEntityType {
-		return fmt.Errorf("invalid entity type: %s", entry.EntityType)
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid entity type: %s", entry.EntityType)
 	}
 
 	return nil
 }
 
+// resolveSanctionListDocument parses an official list document carried by an update into its
+// entries. A checksum sent with a document must be the document's SHA-256, so a truncated or
+// altered download is refused.
+func resolveSanctionListDocument(updateReq *SanctionListUpdateRequest) error {
+	if updateReq.Document == "" {
+		if updateReq.Format != "" {
+			return fmt.Errorf("format %s requires a document", updateReq.Format)
+		}
+		return nil
+	}
+	if len(updateReq.Entries) > 0 {
+		return fmt.Errorf("an update carries either entries or a document, not both")
+	}
+	if len(updateReq.Document) > config.MaxSanctionListDocumentBytes {
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "sanction list document exceeds %d bytes", config.MaxSanctionListDocumentBytes)
+	}
+	if updateReq.Checksum != "" {
+		digest := sha256.Sum256([]byte(updateReq.Document))
+		if !strings.EqualFold(updateReq.Checksum, hex.EncodeToString(digest[:])) {
+			return fmt.Errorf("sanction list document does not match checksum %s", updateReq.Checksum)
+		}
+	}
+
+	entries, err := ParseSanctionListDocument(updateReq.Format, []byte(updateReq.Document), updateReq.ListID)
+	if err != nil {
+		return err
+	}
+	updateReq.Entries = entries
+	updateReq.Document = ""
+	return nil
+}
+
 // Utility methods
 
 func (m *SanctionListManager) calculateNextUpdate(frequency string, lastUpdate time.Time) time.Time {
@@ -771,11 +851,16 @@ func (m *SanctionListManager) recordSanctionListEvent(stub shim.ChaincodeStubInt
 		return nil // No event emitter configured
 	}
 
-	eventID := utils.GenerateID(config.ComplianceEventPrefix)
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return err
+	}
+
+	eventID := services.GenerateDeterministicID(stub, config.ComplianceEventPrefix)
 	
 	event := &domain.ComplianceEvent{
 		EventID:            eventID,
-		Timestamp:          time.Now(),
+		Timestamp:          now,
 		RuleID:             "SANCTION_LIST_MANAGEMENT_RULE",
 		RuleVersion:        "1.0",
 		AffectedEntityID:   listDef.ListID,
@@ -802,7 +887,7 @@ func (m *SanctionListManager) recordSanctionListEvent(stub shim.ChaincodeStubInt
 // GetSanctionList retrieves a sanction list definition
 func (m *SanctionListManager) GetSanctionList(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
 	if len(args) != 1 {
-		return nil, fmt.Errorf("incorrect number of arguments. Expected 1, got %d", len(args))
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
 	}
 
 	listID := args[0]
@@ -810,7 +895,7 @@ func (m *SanctionListManager) GetSanctionList(stub shim.ChaincodeStubInterface,
 
 	var listDef SanctionListDefinition
 	if err := m.persistenceService.Get(stub, listKey, &listDef); err != nil {
-		return nil, fmt.Errorf("sanction list not found: %v", err)
+		return nil, fmt.Errorf("sanction list not found: %w", err)
 	}
 
 	return json.Marshal(&listDef)
@@ -818,9 +903,34 @@ func (m *SanctionListManager) GetSanctionList(stub shim.ChaincodeStubInterface,
 
 // GetActiveSanctionLists retrieves all active sanction lists
 func (m *SanctionListManager) GetActiveSanctionLists(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
-	iterator, err := stub.GetStateByPartialCompositeKey("SANCTION_LIST", []string{})
+	activeLists, err := m.activeSanctionLists(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(activeLists)
+}
+
+// activeListIDs returns the IDs of the active sanction lists
+func (m *SanctionListManager) activeListIDs(stub shim.ChaincodeStubInterface) ([]string, error) {
+	activeLists, err := m.activeSanctionLists(stub)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get sanction lists: %v", err)
+		return nil, err
+	}
+
+	listIDs := []string{}
+	for _, listDef := range activeLists {
+		listIDs = append(listIDs, listDef.ListID)
+	}
+	return listIDs, nil
+}
+
+func (m *SanctionListManager) activeSanctionLists(stub shim.ChaincodeStubInterface) ([]SanctionListDefinition, error) {
+	// List definitions are kept under SANCTION_LIST_<listID>; the list indexes are composite keys
+	// and fall outside the range
+	iterator, err := stub.GetStateByRange("SANCTION_LIST_", "SANCTION_LIST_"+string(utf8.MaxRune))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sanction lists: %w", err)
 	}
 	defer iterator.Close()
 
@@ -829,26 +939,29 @@ func (m *SanctionListManager) GetActiveSanctionLists(stub shim.ChaincodeStubInte
 	for iterator.HasNext() {
 		response, err := iterator.Next()
 		if err != nil {
-			return nil, fmt.Errorf("failed to iterate sanction lists: %v", err)
+			return nil, fmt.Errorf("failed to iterate sanction lists: %w", err)
 		}
 
 		var listDef SanctionListDefinition
 		if err := json.Unmarshal(response.Value, &listDef); err != nil {
 			continue // Skip invalid entries
 		}
+		if response.Key != fmt.Sprintf("SANCTION_LIST_%s", listDef.ListID) {
+			continue // Sanction entries of a list whose ID starts with LIST_ share the range
+		}
 
 		if listDef.IsActive {
 			activeLists = append(activeLists, listDef)
 		}
 	}
 
-	return json.Marshal(activeLists)
+	return activeLists, nil
 }
 
 // SearchSanctionEntries searches for sanction entries by name
 func (m *SanctionListManager) SearchSanctionEntries(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
 	if len(args) != 1 {
-		return nil, fmt.Errorf("incorrect number of arguments. Expected 1, got %d", len(args))
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
 	}
 
 	var searchReq struct {
@@ -859,19 +972,19 @@ func (m *SanctionListManager) SearchSanctionEntries(stub shim.ChaincodeStubInter
 	}
 
 	if err := json.Unmarshal([]byte(args[0]), &searchReq); err != nil {
-		return nil, fmt.Errorf("failed to parse search request: %v", err)
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse search request: %v", err)
 	}
 
 	if searchReq.Limit == 0 {
 		searchReq.Limit = 100 // Default limit
 	}
 
-	// Search by name (simplified implementation)
+	// Search by name prefix (simplified implementation: scans the name index)
 	searchTerm := strings.ToUpper(searchReq.SearchTerm)
 	
-	iterator, err := stub.GetStateByPartialCompositeKey("SANCTION_ENTRY_BY_NAME", []string{searchTerm})
+	iterator, err := stub.GetStateByPartialCompositeKey("SANCTION_ENTRY_BY_NAME", []string{})
 	if err != nil {
-		return nil, fmt.Errorf("failed to search sanction entries: %v", err)
+		return nil, fmt.Errorf("failed to search sanction entries: %w", err)
 	}
 	defer iterator.Close()
 
@@ -881,7 +994,7 @@ func (m *SanctionListManager) SearchSanctionEntries(stub shim.ChaincodeStubInter
 	for iterator.HasNext() && count < searchReq.Limit {
 		response, err := iterator.Next()
 		if err != nil {
-			return nil, fmt.Errorf("failed to iterate search results: %v", err)
+			return nil, fmt.Errorf("failed to iterate search results: %w", err)
 		}
 
 		entryID := string(response.Value)
@@ -891,6 +1004,9 @@ func (m *SanctionListManager) SearchSanctionEntries(stub shim.ChaincodeStubInter
 		if err != nil || len(keyParts) < 2 {
 			continue
 		}
+		if !strings.HasPrefix(keyParts[0], searchTerm) {
+			continue
+		}
 		
 		listID := keyParts[1]
 		
@@ -900,9 +1016,8 @@ func (m *SanctionListManager) SearchSanctionEntries(stub shim.ChaincodeStubInter
 		}
 
 		// Get full entry
-		entryKey := fmt.Sprintf("SANCTION_ENTRY_%s_%s", listID, entryID)
-		var entry ComprehensiveSanctionEntry
-		if err := m.persistenceService.Get(stub, entryKey, &entry); err != nil {
+		entry, err := m.getSanctionEntry(stub, listID, entryID)
+		if err != nil {
 			continue // Skip if entry not found
 		}
 
@@ -911,7 +1026,7 @@ func (m *SanctionListManager) SearchSanctionEntries(stub shim.ChaincodeStubInter
 			continue
 		}
 
-		results = append(results, entry)
+		results = append(results, *entry)
 		count++
 	}
 
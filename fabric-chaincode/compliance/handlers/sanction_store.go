@@ -0,0 +1,231 @@
+package handlers
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// sanctionNamePrefixLength is the number of leading characters (runes) of each
+// name token used as the SANCTION_BY_NAME index key
+const sanctionNamePrefixLength = 3
+
+// SanctionListEntry is the screening record of a sanctioned party. Entries added one by one and
+// entries imported into a managed list are both kept here, so every screening path sees them.
+type SanctionListEntry struct {
+	EntryID          string    `json:"entryID"`
+	ListID           string    `json:"listID,omitempty"` // Managed list the entry was imported into
+	ListName         string    `json:"listName"`
+	EntityName       string    `json:"entityName"`
+	EntityType       string    `json:"entityType"` // Individual, Organization, Vessel, etc.
+	Aliases          []string  `json:"aliases"`
+	DateOfBirth      string    `json:"dateOfBirth,omitempty"`
+	PlaceOfBirth     string    `json:"placeOfBirth,omitempty"`
+	Nationality      string    `json:"nationality,omitempty"`
+	Address          string    `json:"address,omitempty"`
+	IdentificationNo string    `json:"identificationNo,omitempty"`
+	SanctionType     string    `json:"sanctionType"`
+	ListingDate      time.Time `json:"listingDate"`
+	LastUpdated      time.Time `json:"lastUpdated"`
+	IsActive         bool      `json:"isActive"`
+	Source           string    `json:"source"`
+
+	// Details keeps the full record of an entry imported into a managed list
+	Details *ComprehensiveSanctionEntry `json:"details,omitempty"`
+}
+
+// PutSanctionListEntry stores an entry and indexes it by name prefix and by list. Index keys of
+// a previous version are dropped first, so renamed entries and removed aliases stop surfacing.
+func PutSanctionListEntry(stub shim.ChaincodeStubInterface, entry *SanctionListEntry) error {
+	persistence := services.NewPersistenceService()
+
+	var existing SanctionListEntry
+	if err := persistence.Get(stub, sanctionEntryKey(entry.EntryID), &existing); err == nil {
+		if err := removeSanctionEntryIndexKeys(stub, &existing); err != nil {
+			return fmt.Errorf("failed to remove stale sanction indexes: %w", err)
+		}
+	}
+
+	if err := persistence.Put(stub, sanctionEntryKey(entry.EntryID), entry); err != nil {
+		return fmt.Errorf("failed to store sanction list entry: %w", err)
+	}
+
+	for _, prefix := range sanctionEntryPrefixes(entry) {
+		if err := putSanctionIndexKey(stub, "SANCTION_BY_NAME", prefix, entry.EntryID); err != nil {
+			return err
+		}
+	}
+	return putSanctionIndexKey(stub, "SANCTION_BY_LIST", entry.listKey(), entry.EntryID)
+}
+
+// GetSanctionListEntry loads a stored entry by ID
+func GetSanctionListEntry(stub shim.ChaincodeStubInterface, entryID string) (*SanctionListEntry, error) {
+	var entry SanctionListEntry
+	if err := services.NewPersistenceService().Get(stub, sanctionEntryKey(entryID), &entry); err != nil {
+		return nil, err
+	}
+	return &entry, nil
+}
+
+// DeleteSanctionListEntry removes a stored entry together with its index keys
+func DeleteSanctionListEntry(stub shim.ChaincodeStubInterface, entryID string) error {
+	entry, err := GetSanctionListEntry(stub, entryID)
+	if err != nil {
+		return err
+	}
+	if err := removeSanctionEntryIndexKeys(stub, entry); err != nil {
+		return err
+	}
+	return services.NewPersistenceService().Delete(stub, sanctionEntryKey(entryID))
+}
+
+// SanctionListEntryIDs returns the IDs of the entries stored for a list, in key order
+func SanctionListEntryIDs(stub shim.ChaincodeStubInterface, list string) ([]string, error) {
+	iterator, err := stub.GetStateByPartialCompositeKey("SANCTION_BY_LIST", []string{list})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query sanction list index: %w", err)
+	}
+	defer iterator.Close()
+
+	entryIDs := []string{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate sanction list index: %w", err)
+		}
+		entryIDs = append(entryIDs, string(response.Value))
+	}
+	return entryIDs, nil
+}
+
+// CandidateSanctionEntries loads the stored entries whose name or aliases share a token prefix
+// with the screened name
+func CandidateSanctionEntries(stub shim.ChaincodeStubInterface, name string) ([]SanctionListEntry, error) {
+	seen := map[string]bool{}
+	candidates := []SanctionListEntry{}
+
+	for _, prefix := range sanctionNamePrefixes(name) {
+		iterator, err := stub.GetStateByPartialCompositeKey("SANCTION_BY_NAME", []string{prefix})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get name index iterator: %w", err)
+		}
+
+		for iterator.HasNext() {
+			response, err := iterator.Next()
+			if err != nil {
+				iterator.Close()
+				return nil, fmt.Errorf("failed to iterate name index: %w", err)
+			}
+
+			entryID := string(response.Value)
+			if seen[entryID] {
+				continue
+			}
+			seen[entryID] = true
+
+			entry, err := GetSanctionListEntry(stub, entryID)
+			if err != nil {
+				iterator.Close()
+				return nil, fmt.Errorf("failed to get sanction list entry %s: %w", entryID, err)
+			}
+			candidates = append(candidates, *entry)
+		}
+		iterator.Close()
+	}
+
+	sort.Slice(candidates, func(i, j int) bool { return candidates[i].EntryID < candidates[j].EntryID })
+	return candidates, nil
+}
+
+// NormalizeSanctionName lowercases a name and collapses its whitespace for comparison
+func NormalizeSanctionName(name string) string {
+	return strings.Join(strings.Fields(strings.ToLower(name)), " ")
+}
+
+// managedSanctionEntryID scopes the source ID of an imported entry to its list, so entries of
+// different official lists sharing a source ID do not overwrite each other
+func managedSanctionEntryID(listID, entryID string) string {
+	return fmt.Sprintf("%s_%s", listID, entryID)
+}
+
+func sanctionEntryKey(entryID string) string {
+	return "SANCTION_" + entryID
+}
+
+// listKey is the SANCTION_BY_LIST value of an entry: the managed list ID for imported entries
+// and the list name for entries added one by one
+func (e *SanctionListEntry) listKey() string {
+	if e.ListID != "" {
+		return e.ListID
+	}
+	return e.ListName
+}
+
+func removeSanctionEntryIndexKeys(stub shim.ChaincodeStubInterface, entry *SanctionListEntry) error {
+	for _, prefix := range sanctionEntryPrefixes(entry) {
+		if err := deleteSanctionIndexKey(stub, "SANCTION_BY_NAME", prefix, entry.EntryID); err != nil {
+			return err
+		}
+	}
+	return deleteSanctionIndexKey(stub, "SANCTION_BY_LIST", entry.listKey(), entry.EntryID)
+}
+
+func putSanctionIndexKey(stub shim.ChaincodeStubInterface, objectType, value, entryID string) error {
+	indexKey, err := stub.CreateCompositeKey(objectType, []string{value, entryID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := stub.PutState(indexKey, []byte(entryID)); err != nil {
+		return fmt.Errorf("failed to create %s index: %w", objectType, err)
+	}
+	return nil
+}
+
+func deleteSanctionIndexKey(stub shim.ChaincodeStubInterface, objectType, value, entryID string) error {
+	indexKey, err := stub.CreateCompositeKey(objectType, []string{value, entryID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := stub.DelState(indexKey); err != nil {
+		return fmt.Errorf("failed to remove %s index: %w", objectType, err)
+	}
+	return nil
+}
+
+// sanctionEntryPrefixes returns the distinct name prefixes of an entry and its aliases
+func sanctionEntryPrefixes(entry *SanctionListEntry) []string {
+	seen := map[string]bool{}
+	prefixes := []string{}
+	for _, name := range append([]string{entry.EntityName}, entry.Aliases...) {
+		for _, prefix := range sanctionNamePrefixes(name) {
+			if !seen[prefix] {
+				seen[prefix] = true
+				prefixes = append(prefixes, prefix)
+			}
+		}
+	}
+	return prefixes
+}
+
+// sanctionNamePrefixes returns the index prefix of each token in a normalized name. Tokens are cut
+// by character, not byte, so names outside ASCII still produce valid UTF-8 composite keys.
+func sanctionNamePrefixes(name string) []string {
+	seen := map[string]bool{}
+	prefixes := []string{}
+	for _, token := range strings.Fields(NormalizeSanctionName(name)) {
+		runes := []rune(token)
+		if len(runes) > sanctionNamePrefixLength {
+			runes = runes[:sanctionNamePrefixLength]
+		}
+		prefix := string(runes)
+		if !seen[prefix] {
+			seen[prefix] = true
+			prefixes = append(prefixes, prefix)
+		}
+	}
+	return prefixes
+}
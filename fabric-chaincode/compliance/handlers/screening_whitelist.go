@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// maxWhitelistDays bounds how long a false positive disposition lasts before it must be reviewed again
+const maxWhitelistDays = 365
+
+// ScreeningWhitelistHandler records sanction matches a compliance officer has dispositioned as false
+// positives for a customer. Screening keeps a whitelisted match in the check result, marked
+// suppressed, but no longer counts it, so the same benign match is not re-flagged on every screening.
+type ScreeningWhitelistHandler struct {
+	persistenceService *services.PersistenceService
+}
+
+// NewScreeningWhitelistHandler creates a new screening whitelist handler
+func NewScreeningWhitelistHandler() *ScreeningWhitelistHandler {
+	return &ScreeningWhitelistHandler{
+		persistenceService: services.NewPersistenceService(),
+	}
+}
+
+// ScreeningWhitelistEntry dispositions a sanction entry's matches against a customer as a false
+// positive until its expiry date
+type ScreeningWhitelistEntry struct {
+	CustomerID       string     `json:"customerID"`
+	ListEntryID      string     `json:"listEntryID"`
+	MatchedName      string     `json:"matchedName,omitempty"`
+	Justification    string     `json:"justification"`
+	ReviewedBy       string     `json:"reviewedBy"`
+	ReviewDate       time.Time  `json:"reviewDate"`
+	ExpiryDate       time.Time  `json:"expiryDate"`
+	IsActive         bool       `json:"isActive"`
+	RevokedBy        string     `json:"revokedBy,omitempty"`
+	RevokedDate      *time.Time `json:"revokedDate,omitempty"`
+	RevocationReason string     `json:"revocationReason,omitempty"`
+}
+
+// ScreeningWhitelistRequest represents a request to whitelist a false positive. ExpiryDate is an
+// RFC 3339 timestamp or a date meaning the start of that UTC day, at most a year ahead.
+type ScreeningWhitelistRequest struct {
+	CustomerID    string `json:"customerID"`
+	ListEntryID   string `json:"listEntryID"`
+	MatchedName   string `json:"matchedName,omitempty"`
+	Justification string `json:"justification"`
+	ExpiryDate    string `json:"expiryDate"`
+	ActorID       string `json:"actorID"`
+}
+
+// ScreeningWhitelistRevocationRequest represents a request to withdraw a false positive disposition
+type ScreeningWhitelistRevocationRequest struct {
+	CustomerID  string `json:"customerID"`
+	ListEntryID string `json:"listEntryID"`
+	Reason      string `json:"reason"`
+	ActorID     string `json:"actorID"`
+}
+
+// AddScreeningWhitelistEntry records a reviewed false positive. Whitelisting the same match again
+// renews the disposition.
+func (h *ScreeningWhitelistHandler) AddScreeningWhitelistEntry(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req ScreeningWhitelistRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse screening whitelist request: %v", err)
+	}
+	if err := h.requireReviewer(stub); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.CustomerID) == "" || strings.TrimSpace(req.ListEntryID) == "" {
+		return nil, fmt.Errorf("customerID and listEntryID are required")
+	}
+	if strings.TrimSpace(req.Justification) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "justification", "justification is required")
+	}
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+	if req.ExpiryDate == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "expiryDate", "expiryDate is required")
+	}
+	expiryDate, err := parseCatalogDate(req.ExpiryDate)
+	if err != nil {
+		return nil, err
+	}
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	if !expiryDate.After(now) || expiryDate.After(now.AddDate(0, 0, maxWhitelistDays)) {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "expiryDate must be in the next %d days, got %s", maxWhitelistDays, expiryDate.Format(time.RFC3339))
+	}
+
+	entry := &ScreeningWhitelistEntry{
+		CustomerID:    req.CustomerID,
+		ListEntryID:   req.ListEntryID,
+		MatchedName:   req.MatchedName,
+		Justification: req.Justification,
+		ReviewedBy:    req.ActorID,
+		ReviewDate:    now,
+		ExpiryDate:    expiryDate,
+		IsActive:      true,
+	}
+	if err := h.putEntry(stub, entry); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(entry)
+}
+
+// RevokeScreeningWhitelistEntry withdraws a false positive disposition, so the match counts again
+// from the next screening
+func (h *ScreeningWhitelistHandler) RevokeScreeningWhitelistEntry(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req ScreeningWhitelistRevocationRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse screening whitelist revocation request: %v", err)
+	}
+	if err := h.requireReviewer(stub); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "reason", "reason is required")
+	}
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	entry, err := screeningWhitelistEntry(stub, req.CustomerID, req.ListEntryID)
+	if err != nil {
+		return nil, err
+	}
+	if entry == nil || !entry.IsActive {
+		return nil, fmt.Errorf("sanction entry %s is not whitelisted for customer %s", req.ListEntryID, req.CustomerID)
+	}
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.IsActive = false
+	entry.RevokedBy = req.ActorID
+	entry.RevokedDate = &now
+	entry.RevocationReason = req.Reason
+	if err := h.putEntry(stub, entry); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(entry)
+}
+
+// GetScreeningWhitelist returns a customer's whitelisted matches, including expired and revoked
+// ones, by sanction entry
+func (h *ScreeningWhitelistHandler) GetScreeningWhitelist(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	iterator, err := stub.GetStateByPartialCompositeKey(config.ScreeningWhitelistPrefix, []string{args[0]})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get screening whitelist: %w", err)
+	}
+	defer iterator.Close()
+
+	entries := []ScreeningWhitelistEntry{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate screening whitelist: %w", err)
+		}
+		var entry ScreeningWhitelistEntry
+		if err := json.Unmarshal(response.Value, &entry); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal screening whitelist entry: %w", err)
+		}
+		entries = append(entries, entry)
+	}
+	sort.Slice(entries, func(i, j int) bool { return entries[i].ListEntryID < entries[j].ListEntryID })
+
+	return json.Marshal(entries)
+}
+
+// requireReviewer allows compliance officers only to disposition screening matches
+func (h *ScreeningWhitelistHandler) requireReviewer(stub shim.ChaincodeStubInterface) error {
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return err
+	}
+	if role != string(validation.ActorRoleComplianceOfficer) && role != string(validation.ActorRoleChiefComplianceOfficer) {
+		return services.NewChaincodeError(services.ErrCodeAccessDenied, "", "screening matches may only be whitelisted by a %s or %s", validation.ActorRoleComplianceOfficer, validation.ActorRoleChiefComplianceOfficer)
+	}
+	return nil
+}
+
+func (h *ScreeningWhitelistHandler) putEntry(stub shim.ChaincodeStubInterface, entry *ScreeningWhitelistEntry) error {
+	entryKey, err := stub.CreateCompositeKey(config.ScreeningWhitelistPrefix, []string{entry.CustomerID, entry.ListEntryID})
+	if err != nil {
+		return fmt.Errorf("failed to create screening whitelist key: %w", err)
+	}
+	if err := h.persistenceService.Put(stub, entryKey, entry); err != nil {
+		return fmt.Errorf("failed to store screening whitelist entry: %w", err)
+	}
+	return nil
+}
+
+// screeningWhitelistEntry returns the whitelisting of a sanction entry for a customer, or nil
+func screeningWhitelistEntry(stub shim.ChaincodeStubInterface, customerID, listEntryID string) (*ScreeningWhitelistEntry, error) {
+	entryKey, err := stub.CreateCompositeKey(config.ScreeningWhitelistPrefix, []string{customerID, listEntryID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create screening whitelist key: %w", err)
+	}
+	entryBytes, err := stub.GetState(entryKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read screening whitelist entry: %w", err)
+	}
+	if entryBytes == nil {
+		return nil, nil
+	}
+
+	var entry ScreeningWhitelistEntry
+	if err := json.Unmarshal(entryBytes, &entry); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal screening whitelist entry: %w", err)
+	}
+	return &entry, nil
+}
+
+// suppressWhitelistedMatches marks the matches whitelisted for a customer at a time as suppressed
+// and returns how many it marked. Matches already suppressed are left as they are.
+func suppressWhitelistedMatches(stub shim.ChaincodeStubInterface, customerID string, matches []SanctionMatch, at time.Time) (int, error) {
+	if customerID == "" {
+		return 0, nil
+	}
+
+	suppressed := 0
+	for i := range matches {
+		if matches[i].Suppressed {
+			continue
+		}
+		entry, err := screeningWhitelistEntry(stub, customerID, matches[i].ListEntryID)
+		if err != nil {
+			return 0, err
+		}
+		if entry == nil || !entry.IsActive || !at.Before(entry.ExpiryDate) {
+			continue
+		}
+		matches[i].Suppressed = true
+		matches[i].SuppressedBy = entry.ReviewedBy
+		matches[i].SuppressionExpiry = &entry.ExpiryDate
+		suppressed++
+	}
+	return suppressed, nil
+}
@@ -0,0 +1,103 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+func TestScreeningWhitelistHandler_Suppression(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	clock := services.NewFixedClock(now)
+	defer services.SetClock(clock)()
+	stub := shimtest.NewMockStub("screening_whitelist_test", nil)
+	seedSanctionList(t, stub, "OFAC_SDN", johnDoeSanctionEntry())
+	handler := NewScreeningWhitelistHandler()
+	amlHandler := NewAMLCheckHandler(&MockEventEmitter{})
+
+	invoke := func(txID string, fn func(args []string) ([]byte, error), request interface{}) (*ScreeningWhitelistEntry, error) {
+		requestBytes, err := json.Marshal(request)
+		require.NoError(t, err)
+		stub.MockTransactionStart(txID)
+		defer stub.MockTransactionEnd(txID)
+		entryBytes, err := fn([]string{string(requestBytes)})
+		if err != nil {
+			return nil, err
+		}
+		var entry ScreeningWhitelistEntry
+		require.NoError(t, json.Unmarshal(entryBytes, &entry))
+		return &entry, nil
+	}
+	add := func(args []string) ([]byte, error) { return handler.AddScreeningWhitelistEntry(stub, args) }
+	revoke := func(args []string) ([]byte, error) { return handler.RevokeScreeningWhitelistEntry(stub, args) }
+	screen := func(txID, customerID string) SanctionScreenResult {
+		stub.MockTransactionStart(txID)
+		defer stub.MockTransactionEnd(txID)
+		result, err := amlHandler.performSanctionScreening(stub, customerID, &CustomerAMLData{FirstName: "John", LastName: "Doe", Country: "US"}, nil)
+		require.NoError(t, err)
+		return result
+	}
+
+	// The benign namesake is flagged until a reviewer dispositions the match
+	flagged := screen("screen_1", "CUST_001")
+	assert.True(t, flagged.IsMatch)
+	require.NotEmpty(t, flagged.Matches)
+	assert.Equal(t, "OFAC_SDN_SDN_001", flagged.Matches[0].ListEntryID)
+
+	stub.Creator = newRoleIdentity(t, "Underwriter")
+	_, err := invoke("add_1", add, ScreeningWhitelistRequest{CustomerID: "CUST_001", ListEntryID: "OFAC_SDN_SDN_001", Justification: "Different date of birth and nationality", ExpiryDate: "2026-12-01", ActorID: "ACTOR_001"})
+	assert.Contains(t, err.Error(), "may only be whitelisted by")
+
+	stub.Creator = newRoleIdentity(t, "Compliance_Officer")
+	_, err = invoke("add_2", add, ScreeningWhitelistRequest{CustomerID: "CUST_001", ListEntryID: "OFAC_SDN_SDN_001", ExpiryDate: "2026-12-01", ActorID: "ACTOR_001"})
+	assert.Contains(t, err.Error(), "justification is required")
+	_, err = invoke("add_3", add, ScreeningWhitelistRequest{CustomerID: "CUST_001", ListEntryID: "OFAC_SDN_SDN_001", Justification: "Different date of birth", ExpiryDate: "2028-01-01", ActorID: "ACTOR_001"})
+	assert.Contains(t, err.Error(), "expiryDate must be in the next 365 days")
+
+	entry, err := invoke("add_4", add, ScreeningWhitelistRequest{CustomerID: "CUST_001", ListEntryID: "OFAC_SDN_SDN_001", MatchedName: "John Doe", Justification: "Different date of birth and nationality", ExpiryDate: "2026-12-01", ActorID: "ACTOR_001"})
+	require.NoError(t, err)
+	assert.Equal(t, "ACTOR_001", entry.ReviewedBy)
+	assert.True(t, entry.IsActive)
+
+	// Whitelisted matches stay on the result, suppressed, and no longer count
+	suppressed := screen("screen_2", "CUST_001")
+	assert.False(t, suppressed.IsMatch)
+	assert.Zero(t, suppressed.MatchConfidence)
+	assert.Equal(t, len(flagged.Matches), suppressed.SuppressedMatches)
+	require.Len(t, suppressed.Matches, len(flagged.Matches))
+	for _, match := range suppressed.Matches {
+		assert.True(t, match.Suppressed)
+		assert.Equal(t, "ACTOR_001", match.SuppressedBy)
+		assert.Equal(t, time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC), *match.SuppressionExpiry)
+	}
+
+	// The disposition is specific to the customer
+	assert.True(t, screen("screen_3", "CUST_002").IsMatch)
+
+	// And lapses at its expiry
+	restore := services.SetClock(services.NewFixedClock(time.Date(2026, 12, 1, 0, 0, 0, 0, time.UTC)))
+	assert.True(t, screen("screen_4", "CUST_001").IsMatch)
+	restore()
+
+	_, err = invoke("revoke_1", revoke, ScreeningWhitelistRevocationRequest{CustomerID: "CUST_001", ListEntryID: "SDN_002", Reason: "Not whitelisted", ActorID: "ACTOR_002"})
+	assert.Contains(t, err.Error(), "is not whitelisted")
+	entry, err = invoke("revoke_2", revoke, ScreeningWhitelistRevocationRequest{CustomerID: "CUST_001", ListEntryID: "OFAC_SDN_SDN_001", Reason: "Entry updated with matching date of birth", ActorID: "ACTOR_002"})
+	require.NoError(t, err)
+	assert.False(t, entry.IsActive)
+	assert.Equal(t, "ACTOR_002", entry.RevokedBy)
+	assert.True(t, screen("screen_5", "CUST_001").IsMatch)
+
+	stub.MockTransactionStart("list")
+	whitelistBytes, err := handler.GetScreeningWhitelist(stub, []string{"CUST_001"})
+	stub.MockTransactionEnd("list")
+	require.NoError(t, err)
+	var whitelist []ScreeningWhitelistEntry
+	require.NoError(t, json.Unmarshal(whitelistBytes, &whitelist))
+	require.Len(t, whitelist, 1)
+	assert.Equal(t, "Entry updated with matching date of birth", whitelist[0].RevocationReason)
+}
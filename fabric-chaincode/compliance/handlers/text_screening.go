@@ -0,0 +1,414 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/compliance/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/interfaces"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// screeningSeverityRank orders term severities; a match at domain.PriorityCritical blocks the
+// screened entity, lower severities flag it for review
+var screeningSeverityRank = map[domain.ComplianceRulePriority]int{
+	domain.PriorityLow:      1,
+	domain.PriorityMedium:   2,
+	domain.PriorityHigh:     3,
+	domain.PriorityCritical: 4,
+}
+
+// TextScreeningManager maintains the prohibited activity terms that loan purposes and other
+// free-text fields are screened against, and raises compliance events for matches
+type TextScreeningManager struct {
+	persistenceService *services.PersistenceService
+	eventEmitter       domain.EventEmitter
+}
+
+// NewTextScreeningManager creates a new text screening manager
+func NewTextScreeningManager(eventEmitter domain.EventEmitter) *TextScreeningManager {
+	return &TextScreeningManager{
+		persistenceService: services.NewPersistenceService(),
+		eventEmitter:       eventEmitter,
+	}
+}
+
+// ScreeningTerm is a keyword or phrase describing a prohibited or restricted activity
+type ScreeningTerm struct {
+	TermID             string                        `json:"termID"`
+	Term               string                        `json:"term"`     // Normalised: lower case words separated by single spaces
+	Category           string                        `json:"category"` // e.g. ARMS, CRYPTO_MIXING, GAMBLING
+	Severity           domain.ComplianceRulePriority `json:"severity"`
+	Description        string                        `json:"description,omitempty"`
+	IsActive           bool                          `json:"isActive"`
+	DeactivationReason string                        `json:"deactivationReason,omitempty"`
+	CreatedBy          string                        `json:"createdBy"`
+	CreatedDate        time.Time                     `json:"createdDate"`
+	LastUpdatedBy      string                        `json:"lastUpdatedBy"`
+	LastUpdated        time.Time                     `json:"lastUpdated"`
+}
+
+// ScreeningTermRequest represents a request to add or replace a screening term
+type ScreeningTermRequest struct {
+	TermID      string `json:"termID,omitempty"` // Generated when empty
+	Term        string `json:"term"`
+	Category    string `json:"category"`
+	Severity    string `json:"severity"` // LOW, MEDIUM, HIGH or CRITICAL
+	Description string `json:"description,omitempty"`
+	ActorID     string `json:"actorID"`
+}
+
+// ScreeningTermDeactivationRequest represents a request to withdraw a screening term
+type ScreeningTermDeactivationRequest struct {
+	TermID  string `json:"termID"`
+	Reason  string `json:"reason"`
+	ActorID string `json:"actorID"`
+}
+
+// AddScreeningTerm adds a screening term or replaces an existing term with the same ID
+func (m *TextScreeningManager) AddScreeningTerm(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req ScreeningTermRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse screening term request: %v", err)
+	}
+
+	if err := m.requireTermMaintainer(stub); err != nil {
+		return nil, err
+	}
+
+	term := &ScreeningTerm{
+		TermID:      req.TermID,
+		Term:        normaliseScreeningText(req.Term),
+		Category:    strings.ToUpper(strings.TrimSpace(req.Category)),
+		Severity:    domain.ComplianceRulePriority(strings.ToUpper(req.Severity)),
+		Description: req.Description,
+		IsActive:    true,
+	}
+	if term.Term == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "term must contain at least one letter or digit")
+	}
+	if term.Category == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "category", "category is required")
+	}
+	if _, ok := screeningSeverityRank[term.Severity]; !ok {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid severity %q: expected LOW, MEDIUM, HIGH or CRITICAL", req.Severity)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	term.CreatedBy = req.ActorID
+	term.CreatedDate = now
+	term.LastUpdatedBy = req.ActorID
+	term.LastUpdated = now
+	if term.TermID == "" {
+		term.TermID = services.GenerateDeterministicID(stub, config.ScreeningTermPrefix)
+	}
+
+	// A replaced term may have moved category, so its old index entry is dropped
+	var existing ScreeningTerm
+	if err := m.persistenceService.Get(stub, fmt.Sprintf("SCREEN_TERM_%s", term.TermID), &existing); err == nil {
+		term.CreatedBy = existing.CreatedBy
+		term.CreatedDate = existing.CreatedDate
+		if err := m.deleteCategoryIndex(stub, &existing); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := m.persistenceService.Put(stub, fmt.Sprintf("SCREEN_TERM_%s", term.TermID), term); err != nil {
+		return nil, fmt.Errorf("failed to store screening term: %w", err)
+	}
+	if err := m.putCategoryIndex(stub, term); err != nil {
+		return nil, err
+	}
+
+	if err := m.recordTermEvent(stub, "SCREENING_TERM_ADDED", term.TermID, map[string]interface{}{
+		"termID":   term.TermID,
+		"term":     term.Term,
+		"category": term.Category,
+		"severity": term.Severity,
+	}, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to record screening term event: %w", err)
+	}
+
+	return json.Marshal(term)
+}
+
+// DeactivateScreeningTerm withdraws a term from screening. The term is kept for the audit trail.
+func (m *TextScreeningManager) DeactivateScreeningTerm(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req ScreeningTermDeactivationRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse screening term deactivation request: %v", err)
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "reason", "reason is required")
+	}
+
+	if err := m.requireTermMaintainer(stub); err != nil {
+		return nil, err
+	}
+
+	var term ScreeningTerm
+	if err := m.persistenceService.Get(stub, fmt.Sprintf("SCREEN_TERM_%s", req.TermID), &term); err != nil {
+		return nil, fmt.Errorf("screening term not found: %w", err)
+	}
+	if !term.IsActive {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "screening term %s is already inactive", req.TermID)
+	}
+
+	// Inactive terms drop out of the category index so screening no longer finds them
+	if err := m.deleteCategoryIndex(stub, &term); err != nil {
+		return nil, err
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	term.IsActive = false
+	term.DeactivationReason = req.Reason
+	term.LastUpdatedBy = req.ActorID
+	term.LastUpdated = now
+	if err := m.persistenceService.Put(stub, fmt.Sprintf("SCREEN_TERM_%s", term.TermID), &term); err != nil {
+		return nil, fmt.Errorf("failed to update screening term: %w", err)
+	}
+
+	if err := m.recordTermEvent(stub, "SCREENING_TERM_DEACTIVATED", term.TermID, map[string]interface{}{
+		"termID": term.TermID,
+		"reason": req.Reason,
+	}, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to record screening term event: %w", err)
+	}
+
+	return json.Marshal(&term)
+}
+
+// GetScreeningTerms lists the active screening terms of a category, or of every category when the
+// category is empty
+func (m *TextScreeningManager) GetScreeningTerms(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	terms, err := m.activeTerms(stub, strings.ToUpper(strings.TrimSpace(args[0])))
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(terms)
+}
+
+// ScreenText screens an entity's free-text fields against the active screening terms. Matches are
+// raised as one compliance event for the entity so they are reviewed like any other violation.
+func (m *TextScreeningManager) ScreenText(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req interfaces.TextScreeningRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse text screening request: %v", err)
+	}
+	if strings.TrimSpace(req.EntityID) == "" || strings.TrimSpace(req.EntityType) == "" {
+		return nil, fmt.Errorf("entityID and entityType are required")
+	}
+
+	terms, err := m.activeTerms(stub, "")
+	if err != nil {
+		return nil, err
+	}
+
+	// Fields are screened in name order so results are identical on every endorser
+	fields := make([]string, 0, len(req.Fields))
+	for field := range req.Fields {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+
+	result := &interfaces.TextScreeningResult{Matches: []interfaces.TextScreeningMatch{}}
+	var highest domain.ComplianceRulePriority
+	for _, field := range fields {
+		text := " " + normaliseScreeningText(req.Fields[field]) + " "
+		for _, term := range terms {
+			if !strings.Contains(text, " "+term.Term+" ") {
+				continue
+			}
+			result.Matches = append(result.Matches, interfaces.TextScreeningMatch{
+				Field:    field,
+				TermID:   term.TermID,
+				Term:     term.Term,
+				Category: term.Category,
+				Severity: string(term.Severity),
+			})
+			if screeningSeverityRank[term.Severity] > screeningSeverityRank[highest] {
+				highest = term.Severity
+			}
+		}
+	}
+	if len(result.Matches) == 0 {
+		return json.Marshal(result)
+	}
+
+	result.Flagged = true
+	result.Blocked = highest == domain.PriorityCritical
+	result.Severity = string(highest)
+
+	if m.eventEmitter != nil {
+		now, err := services.TxTime(stub)
+		if err != nil {
+			return nil, err
+		}
+
+		result.EventID = services.GenerateDeterministicID(stub, config.EventPrefix)
+		event := &domain.ComplianceEvent{
+			EventID:            result.EventID,
+			Timestamp:          now,
+			RuleID:             "TEXT_SCREENING_RULE",
+			RuleVersion:        "1.0",
+			AffectedEntityID:   req.EntityID,
+			AffectedEntityType: req.EntityType,
+			EventType:          "TEXT_SCREENING_MATCH",
+			Severity:           highest,
+			Details: map[string]interface{}{
+				"matches": result.Matches,
+				"blocked": result.Blocked,
+			},
+			ExecutionResult: domain.RuleExecutionResult{
+				RuleID:      "TEXT_SCREENING_RULE",
+				ExecutionID: services.GenerateDeterministicID(stub, "EXEC"),
+				Timestamp:   now,
+				Success:     true,
+				Passed:      false,
+			},
+			ActorID:          req.ActorID,
+			IsAlerted:        true,
+			ResolutionStatus: "OPEN",
+		}
+		if err := m.eventEmitter.EmitComplianceEvent(stub, event); err != nil {
+			return nil, fmt.Errorf("failed to record text screening event: %w", err)
+		}
+	}
+
+	return json.Marshal(result)
+}
+
+// Helper methods
+
+// activeTerms returns the active terms of a category, or of all categories when it is empty
+func (m *TextScreeningManager) activeTerms(stub shim.ChaincodeStubInterface, category string) ([]ScreeningTerm, error) {
+	attributes := []string{}
+	if category != "" {
+		attributes = append(attributes, category)
+	}
+
+	iterator, err := stub.GetStateByPartialCompositeKey("SCREEN_TERM_BY_CATEGORY", attributes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query screening terms: %w", err)
+	}
+	defer iterator.Close()
+
+	terms := []ScreeningTerm{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate screening terms: %w", err)
+		}
+
+		var term ScreeningTerm
+		if err := m.persistenceService.Get(stub, fmt.Sprintf("SCREEN_TERM_%s", string(response.Value)), &term); err != nil {
+			continue // Skip if term not found
+		}
+		if term.IsActive {
+			terms = append(terms, term)
+		}
+	}
+
+	return terms, nil
+}
+
+func (m *TextScreeningManager) putCategoryIndex(stub shim.ChaincodeStubInterface, term *ScreeningTerm) error {
+	indexKey, err := stub.CreateCompositeKey("SCREEN_TERM_BY_CATEGORY", []string{term.Category, term.TermID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := stub.PutState(indexKey, []byte(term.TermID)); err != nil {
+		return fmt.Errorf("failed to create SCREEN_TERM_BY_CATEGORY index: %w", err)
+	}
+	return nil
+}
+
+func (m *TextScreeningManager) deleteCategoryIndex(stub shim.ChaincodeStubInterface, term *ScreeningTerm) error {
+	indexKey, err := stub.CreateCompositeKey("SCREEN_TERM_BY_CATEGORY", []string{term.Category, term.TermID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := stub.DelState(indexKey); err != nil {
+		return fmt.Errorf("failed to remove SCREEN_TERM_BY_CATEGORY index: %w", err)
+	}
+	return nil
+}
+
+// normaliseScreeningText lower-cases text and reduces every run of characters other than letters
+// and digits to a single space, so "Arms-dealing" matches the term "arms dealing"
+func normaliseScreeningText(text string) string {
+	return strings.Join(strings.FieldsFunc(strings.ToLower(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	}), " ")
+}
+
+// requireTermMaintainer restricts screening term changes to compliance officers
+func (m *TextScreeningManager) requireTermMaintainer(stub shim.ChaincodeStubInterface) error {
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return err
+	}
+	if role != string(validation.ActorRoleComplianceOfficer) && role != string(validation.ActorRoleChiefComplianceOfficer) {
+		return services.NewChaincodeError(services.ErrCodeAccessDenied, "", "screening terms may only be maintained by a %s or %s", validation.ActorRoleComplianceOfficer, validation.ActorRoleChiefComplianceOfficer)
+	}
+	return nil
+}
+
+func (m *TextScreeningManager) recordTermEvent(stub shim.ChaincodeStubInterface, eventType, entityID string, details map[string]interface{}, actorID string) error {
+	if m.eventEmitter == nil {
+		return nil // No event emitter configured
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return err
+	}
+
+	event := &domain.ComplianceEvent{
+		EventID:            services.GenerateDeterministicID(stub, config.EventPrefix),
+		Timestamp:          now,
+		RuleID:             "TEXT_SCREENING_RULE",
+		RuleVersion:        "1.0",
+		AffectedEntityID:   entityID,
+		AffectedEntityType: "ScreeningTerm",
+		EventType:          eventType,
+		Severity:           domain.PriorityMedium,
+		Details:            details,
+		ActorID:            actorID,
+		IsAlerted:          false,
+		ResolutionStatus:   "CLOSED",
+	}
+
+	return m.eventEmitter.EmitComplianceEvent(stub, event)
+}
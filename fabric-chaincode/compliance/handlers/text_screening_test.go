@@ -0,0 +1,139 @@
+package handlers
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/golang/protobuf/proto"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/compliance/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/interfaces"
+)
+
+// newRoleIdentity returns a serialized identity carrying a role attribute, as issued by the Fabric CA
+func newRoleIdentity(t *testing.T, role string) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	attrs, err := json.Marshal(map[string]map[string]string{"attrs": {"role": role}})
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:    big.NewInt(time.Now().UnixNano()),
+		Subject:         pkix.Name{CommonName: role},
+		NotBefore:       time.Now().Add(-time.Hour),
+		NotAfter:        time.Now().Add(time.Hour),
+		ExtraExtensions: []pkix.Extension{{Id: asn1.ObjectIdentifier{1, 2, 3, 4, 5, 6, 7, 8, 1}, Value: attrs}},
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	identity, err := proto.Marshal(&msp.SerializedIdentity{
+		Mspid:   "Org1MSP",
+		IdBytes: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}),
+	})
+	require.NoError(t, err)
+	return identity
+}
+
+func TestTextScreeningManager_ScreenText(t *testing.T) {
+	stub := shimtest.NewMockStub("text_screening_test", nil)
+	mockEmitter := &MockEventEmitter{}
+	manager := NewTextScreeningManager(mockEmitter)
+
+	invoke := func(txID string, fn func([]string) ([]byte, error), request interface{}) ([]byte, error) {
+		requestBytes, err := json.Marshal(request)
+		require.NoError(t, err)
+		stub.MockTransactionStart(txID)
+		defer stub.MockTransactionEnd(txID)
+		return fn([]string{string(requestBytes)})
+	}
+	screen := func(txID, purpose string) interfaces.TextScreeningResult {
+		resultBytes, err := invoke(txID, func(args []string) ([]byte, error) { return manager.ScreenText(stub, args) }, interfaces.TextScreeningRequest{
+			EntityID:   "LOAN_001",
+			EntityType: "LoanApplication",
+			Fields:     map[string]string{"purpose": purpose},
+			ActorID:    "ACTOR_001",
+		})
+		require.NoError(t, err)
+		var result interfaces.TextScreeningResult
+		require.NoError(t, json.Unmarshal(resultBytes, &result))
+		return result
+	}
+	addTerm := func(txID string, request ScreeningTermRequest) error {
+		_, err := invoke(txID, func(args []string) ([]byte, error) { return manager.AddScreeningTerm(stub, args) }, request)
+		return err
+	}
+
+	// Only compliance officers maintain the terms
+	stub.Creator = newRoleIdentity(t, "Underwriter")
+	err := addTerm("tx1", ScreeningTermRequest{TermID: "ARMS_1", Term: "arms dealing", Category: "arms", Severity: "CRITICAL", ActorID: "ACTOR_001"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "may only be maintained")
+
+	stub.Creator = newRoleIdentity(t, "Compliance_Officer")
+	require.NoError(t, addTerm("tx2", ScreeningTermRequest{TermID: "ARMS_1", Term: "Arms Dealing", Category: "arms", Severity: "CRITICAL", ActorID: "ACTOR_001"}))
+	require.NoError(t, addTerm("tx3", ScreeningTermRequest{TermID: "MIX_1", Term: "mixer", Category: "CRYPTO_MIXING", Severity: "HIGH", ActorID: "ACTOR_001"}))
+	require.NoError(t, addTerm("tx4", ScreeningTermRequest{TermID: "GAMB_1", Term: "casino", Category: "GAMBLING", Severity: "MEDIUM", ActorID: "ACTOR_001"}))
+	err = addTerm("tx5", ScreeningTermRequest{Term: "lottery", Category: "GAMBLING", Severity: "SEVERE", ActorID: "ACTOR_001"})
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "invalid severity")
+
+	// A critical term blocks and is raised as an alerted compliance event
+	mockEmitter.EmittedEvents = nil
+	result := screen("tx6", "Working capital for an Arms-Dealing business and a casino")
+	assert.True(t, result.Flagged)
+	assert.True(t, result.Blocked)
+	assert.Equal(t, "CRITICAL", result.Severity)
+	require.Len(t, result.Matches, 2)
+	assert.Equal(t, "ARMS", result.Matches[0].Category)
+	assert.Equal(t, "GAMBLING", result.Matches[1].Category)
+	require.Len(t, mockEmitter.EmittedEvents, 1)
+	event, ok := mockEmitter.EmittedEvents[0].(*domain.ComplianceEvent)
+	require.True(t, ok)
+	assert.Equal(t, "TEXT_SCREENING_MATCH", event.EventType)
+	assert.Equal(t, "LOAN_001", event.AffectedEntityID)
+	assert.Equal(t, domain.PriorityCritical, event.Severity)
+	assert.True(t, event.IsAlerted)
+	assert.Equal(t, result.EventID, event.EventID)
+
+	// Lower severities flag without blocking, and only whole words match
+	result = screen("tx7", "Refit of the casino kitchen")
+	assert.True(t, result.Flagged)
+	assert.False(t, result.Blocked)
+	assert.Equal(t, "MEDIUM", result.Severity)
+
+	mockEmitter.EmittedEvents = nil
+	result = screen("tx8", "Purchase of charms and cement mixers")
+	assert.False(t, result.Flagged)
+	assert.Empty(t, result.Matches)
+	assert.Empty(t, mockEmitter.EmittedEvents)
+
+	// Deactivated terms no longer match
+	_, err = invoke("tx9", func(args []string) ([]byte, error) { return manager.DeactivateScreeningTerm(stub, args) }, ScreeningTermDeactivationRequest{TermID: "GAMB_1", Reason: "Licensed venues permitted", ActorID: "ACTOR_001"})
+	require.NoError(t, err)
+	result = screen("tx10", "Refit of the casino kitchen")
+	assert.False(t, result.Flagged)
+
+	stub.MockTransactionStart("tx11")
+	termsBytes, err := manager.GetScreeningTerms(stub, []string{""})
+	stub.MockTransactionEnd("tx11")
+	require.NoError(t, err)
+	var terms []ScreeningTerm
+	require.NoError(t, json.Unmarshal(termsBytes, &terms))
+	require.Len(t, terms, 2)
+	assert.Equal(t, "arms dealing", terms[0].Term)
+	assert.Equal(t, "CRYPTO_MIXING", terms[1].Category)
+}
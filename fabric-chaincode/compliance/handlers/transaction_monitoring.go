@@ -0,0 +1,740 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/compliance/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// TransactionTypology names a money-laundering pattern transaction monitoring looks for
+type TransactionTypology string
+
+const (
+	TypologyStructuring         TransactionTypology = "STRUCTURING"
+	TypologyRapidMovement       TransactionTypology = "RAPID_MOVEMENT"
+	TypologyRoundAmounts        TransactionTypology = "ROUND_AMOUNTS"
+	TypologyDormantReactivation TransactionTypology = "DORMANT_REACTIVATION"
+)
+
+// Transaction directions, from the customer's side
+const (
+	TransactionDirectionCredit = "CREDIT" // Funds in
+	TransactionDirectionDebit  = "DEBIT"  // Funds out
+)
+
+// monitoredTransactionDateFormat is the MONITORED_TRANSACTION key timestamp; a customer's
+// transactions sort by transaction time
+const monitoredTransactionDateFormat = "20060102T150405Z"
+
+// TransactionTypologyRule tunes one typology. The parameters mean:
+//   - STRUCTURING: at least MinCount same-direction transactions within WindowHours, each between
+//     Ratio x Threshold and Threshold (the reporting threshold), together reaching Threshold
+//   - RAPID_MOVEMENT: debits within WindowHours moving out at least Ratio of the credits received
+//     in the same window, when those credits reach Threshold
+//   - ROUND_AMOUNTS: at least MinCount transactions within WindowHours that are whole multiples of
+//     Threshold
+//   - DORMANT_REACTIVATION: a transaction of at least Threshold after WindowHours without any
+//     activity on the customer's accounts
+//
+// Score (0-100) is added to an alert's score when the typology is hit.
+type TransactionTypologyRule struct {
+	Typology    TransactionTypology `json:"typology"`
+	Enabled     bool                `json:"enabled"`
+	WindowHours int                 `json:"windowHours"`
+	Threshold   float64             `json:"threshold"`
+	MinCount    int                 `json:"minCount,omitempty"`
+	Ratio       float64             `json:"ratio,omitempty"`
+	Score       float64             `json:"score"`
+	UpdatedBy   string              `json:"updatedBy,omitempty"` // Empty for the built-in default
+	UpdatedDate *time.Time          `json:"updatedDate,omitempty"`
+}
+
+// defaultTypologyRules apply to typologies compliance has not tuned on the ledger
+var defaultTypologyRules = []TransactionTypologyRule{
+	{Typology: TypologyStructuring, Enabled: true, WindowHours: 72, Threshold: 10000, MinCount: 3, Ratio: 0.8, Score: 40},
+	{Typology: TypologyRapidMovement, Enabled: true, WindowHours: 48, Threshold: 5000, Ratio: 0.9, Score: 35},
+	{Typology: TypologyRoundAmounts, Enabled: true, WindowHours: 30 * 24, Threshold: 1000, MinCount: 3, Score: 15},
+	{Typology: TypologyDormantReactivation, Enabled: true, WindowHours: 180 * 24, Threshold: 5000, Score: 25},
+}
+
+// TransactionTypologyRuleRequest represents a request to replace the rule for a typology
+type TransactionTypologyRuleRequest struct {
+	Typology    string  `json:"typology"`
+	Enabled     bool    `json:"enabled"`
+	WindowHours int     `json:"windowHours"`
+	Threshold   float64 `json:"threshold"`
+	MinCount    int     `json:"minCount,omitempty"`
+	Ratio       float64 `json:"ratio,omitempty"`
+	Score       float64 `json:"score"`
+	ActorID     string  `json:"actorID"`
+}
+
+// TransactionSummary is a customer transaction as reported by core banking for monitoring
+type TransactionSummary struct {
+	TransactionID    string                   `json:"transactionID"`
+	CustomerID       string                   `json:"customerID"`
+	AccountRef       string                   `json:"accountRef,omitempty"`
+	Amount           float64                  `json:"amount"`
+	Currency         string                   `json:"currency"`
+	Direction        string                   `json:"direction"`                 // CREDIT or DEBIT
+	TransactionType  string                   `json:"transactionType,omitempty"` // e.g. CASH, TRANSFER, CARD
+	CounterpartyName string                   `json:"counterpartyName,omitempty"`
+	TransactionDate  time.Time                `json:"transactionDate"`
+	PaymentReference *PaymentMessageReference `json:"paymentReference,omitempty"` // Set for transactions ingested from a payment message
+}
+
+// MonitoredTransaction is an ingested transaction summary
+type MonitoredTransaction struct {
+	TransactionSummary
+	IngestedBy   string    `json:"ingestedBy"`
+	IngestedDate time.Time `json:"ingestedDate"`
+}
+
+// TransactionIngestRequest represents a batch of transaction summaries to monitor
+type TransactionIngestRequest struct {
+	Transactions []TransactionSummary `json:"transactions"`
+	ActorID      string               `json:"actorID"`
+}
+
+// TransactionIngestResult summarises a monitoring ingest
+type TransactionIngestResult struct {
+	Ingested   int                `json:"ingested"`
+	Duplicates []string           `json:"duplicates"` // Transaction IDs already ingested, skipped
+	Alerts     []TransactionAlert `json:"alerts"`
+}
+
+// TypologyHit records a typology a transaction tripped and the transactions that show it
+type TypologyHit struct {
+	Typology TransactionTypology  `json:"typology"`
+	Score    float64              `json:"score"`
+	Detail   string               `json:"detail"`
+	Evidence []TransactionSummary `json:"evidence"`
+}
+
+// TransactionAlert is raised for a transaction that tripped one or more typologies. Its score is
+// the sum of the typology scores, capped at 100.
+type TransactionAlert struct {
+	AlertID       string                        `json:"alertID"`
+	CustomerID    string                        `json:"customerID"`
+	TransactionID string                        `json:"transactionID"`
+	Score         float64                       `json:"score"`
+	Severity      domain.ComplianceRulePriority `json:"severity"`
+	Hits          []TypologyHit                 `json:"hits"`
+	EventID       string                        `json:"eventID,omitempty"` // Compliance event raised for the alert
+	CreatedBy     string                        `json:"createdBy"`
+	CreatedDate   time.Time                     `json:"createdDate"`
+}
+
+// TransactionMonitoringHandler ingests customer transaction summaries, evaluates them against
+// money-laundering typologies and raises scored alerts
+type TransactionMonitoringHandler struct {
+	persistenceService *services.PersistenceService
+	eventEmitter       domain.EventEmitter
+}
+
+// NewTransactionMonitoringHandler creates a new transaction monitoring handler
+func NewTransactionMonitoringHandler(eventEmitter domain.EventEmitter) *TransactionMonitoringHandler {
+	return &TransactionMonitoringHandler{
+		persistenceService: services.NewPersistenceService(),
+		eventEmitter:       eventEmitter,
+	}
+}
+
+// SetTransactionTypologyRule replaces the rule for a typology, overriding its built-in default
+func (h *TransactionMonitoringHandler) SetTransactionTypologyRule(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req TransactionTypologyRuleRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse typology rule request: %v", err)
+	}
+	if err := h.requireRuleMaintainer(stub); err != nil {
+		return nil, err
+	}
+
+	typology := TransactionTypology(strings.ToUpper(req.Typology))
+	if defaultTypologyRule(typology) == nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid typology: %s", req.Typology)
+	}
+	if req.WindowHours <= 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "windowHours must be positive")
+	}
+	if req.Threshold <= 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "threshold must be positive")
+	}
+	if (typology == TypologyStructuring || typology == TypologyRoundAmounts) && req.MinCount < 2 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "minCount must be at least 2 for %s", typology)
+	}
+	if (typology == TypologyStructuring || typology == TypologyRapidMovement) && (req.Ratio <= 0 || req.Ratio > 1) {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "ratio must be above 0 and at most 1 for %s", typology)
+	}
+	if req.Score < 0 || req.Score > 100 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "score must be between 0 and 100, got %.2f", req.Score)
+	}
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	rule := &TransactionTypologyRule{
+		Typology:    typology,
+		Enabled:     req.Enabled,
+		WindowHours: req.WindowHours,
+		Threshold:   req.Threshold,
+		MinCount:    req.MinCount,
+		Ratio:       req.Ratio,
+		Score:       req.Score,
+		UpdatedBy:   req.ActorID,
+		UpdatedDate: &now,
+	}
+
+	ruleKey, err := stub.CreateCompositeKey(config.TransactionTypologyRulePrefix, []string{string(typology)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create typology rule key: %w", err)
+	}
+	if err := h.persistenceService.Put(stub, ruleKey, rule); err != nil {
+		return nil, fmt.Errorf("failed to store typology rule: %w", err)
+	}
+
+	return json.Marshal(rule)
+}
+
+// GetTransactionTypologyRules returns the rule in force for every typology
+func (h *TransactionMonitoringHandler) GetTransactionTypologyRules(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 0, got %d", len(args))
+	}
+
+	rules, err := h.typologyRules(stub)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(rules)
+}
+
+// IngestTransactions records a batch of transaction summaries and evaluates each against the
+// typology rules, in transaction time order. Transactions already ingested are skipped, so a
+// batch may be resent safely. Each transaction that trips a typology raises one alert, persisted
+// with its evidence and raised as a compliance event.
+func (h *TransactionMonitoringHandler) IngestTransactions(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req TransactionIngestRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse transaction ingest request: %v", err)
+	}
+
+	result, err := h.ingestTransactions(stub, &req)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(result)
+}
+
+// ingestTransactions records and evaluates a batch of transaction summaries
+func (h *TransactionMonitoringHandler) ingestTransactions(stub shim.ChaincodeStubInterface, req *TransactionIngestRequest) (*TransactionIngestResult, error) {
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+	if len(req.Transactions) == 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "at least one transaction is required")
+	}
+	if len(req.Transactions) > config.MaxTransactionIngestBatchSize {
+		return nil, fmt.Errorf("at most %d transactions may be ingested at once, got %d", config.MaxTransactionIngestBatchSize, len(req.Transactions))
+	}
+
+	// Transaction summaries come from core banking or compliance staff
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return nil, err
+	}
+	if role != string(validation.ActorRoleComplianceOfficer) && role != string(validation.ActorRoleChiefComplianceOfficer) && role != string(validation.ActorRoleSystemAdministrator) {
+		return nil, services.NewChaincodeError(services.ErrCodeAccessDenied, "", "transactions may only be ingested by a %s, %s or %s", validation.ActorRoleComplianceOfficer, validation.ActorRoleChiefComplianceOfficer, validation.ActorRoleSystemAdministrator)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	// A finalized reporting period is locked; its filed reports must stay complete
+	finalized := map[string]bool{}
+	for i := range req.Transactions {
+		if err := validateTransactionSummary(&req.Transactions[i], now); err != nil {
+			return nil, fmt.Errorf("invalid transaction %d: %w", i, err)
+		}
+		period := req.Transactions[i].TransactionDate.UTC().Format(reportingPeriodFormat)
+		locked, ok := finalized[period]
+		if !ok {
+			if locked, err = reportingPeriodFinalized(stub, req.Transactions[i].TransactionDate); err != nil {
+				return nil, err
+			}
+			finalized[period] = locked
+		}
+		if locked {
+			return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid transaction %d: reporting period %s is finalized", i, period)
+		}
+	}
+
+	rules, err := h.typologyRules(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	// Evaluate in transaction time order so each transaction sees the ones before it
+	transactions := append([]TransactionSummary{}, req.Transactions...)
+	sort.SliceStable(transactions, func(i, j int) bool {
+		if !transactions[i].TransactionDate.Equal(transactions[j].TransactionDate) {
+			return transactions[i].TransactionDate.Before(transactions[j].TransactionDate)
+		}
+		return transactions[i].TransactionID < transactions[j].TransactionID
+	})
+
+	result := &TransactionIngestResult{Duplicates: []string{}, Alerts: []TransactionAlert{}}
+	seen := map[string]bool{}
+	// Writes in this transaction are not visible to later reads, so each customer's history is
+	// read once and extended with the batch as it is processed
+	histories := map[string][]TransactionSummary{}
+	for _, txn := range transactions {
+		ingested, err := h.persistenceService.Exists(stub, monitoredTransactionIDKey(txn.TransactionID))
+		if err != nil {
+			return nil, fmt.Errorf("failed to check transaction %s: %w", txn.TransactionID, err)
+		}
+		if ingested || seen[txn.TransactionID] {
+			result.Duplicates = append(result.Duplicates, txn.TransactionID)
+			continue
+		}
+		seen[txn.TransactionID] = true
+
+		history, ok := histories[txn.CustomerID]
+		if !ok {
+			history, err = h.customerTransactions(stub, txn.CustomerID)
+			if err != nil {
+				return nil, err
+			}
+		}
+
+		hits := evaluateTypologies(rules, txn, history)
+		histories[txn.CustomerID] = insertTransaction(history, txn)
+
+		if err := h.putMonitoredTransaction(stub, &MonitoredTransaction{TransactionSummary: txn, IngestedBy: req.ActorID, IngestedDate: now}); err != nil {
+			return nil, err
+		}
+		result.Ingested++
+
+		if len(hits) == 0 {
+			continue
+		}
+		alert, err := h.raiseTransactionAlert(stub, txn, hits, req.ActorID, now)
+		if err != nil {
+			return nil, err
+		}
+		result.Alerts = append(result.Alerts, *alert)
+	}
+
+	return result, nil
+}
+
+// GetTransactionAlert returns a transaction monitoring alert with its evidence
+func (h *TransactionMonitoringHandler) GetTransactionAlert(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var alert TransactionAlert
+	if err := h.persistenceService.Get(stub, transactionAlertKey(args[0]), &alert); err != nil {
+		return nil, fmt.Errorf("transaction alert not found: %w", err)
+	}
+	return json.Marshal(&alert)
+}
+
+// GetTransactionAlertsByCustomer returns a customer's transaction monitoring alerts
+func (h *TransactionMonitoringHandler) GetTransactionAlertsByCustomer(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	iterator, err := stub.GetStateByPartialCompositeKey("CUSTOMER_TXN_ALERT", []string{args[0]})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer transaction alerts: %w", err)
+	}
+	defer iterator.Close()
+
+	alerts := []TransactionAlert{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate customer transaction alerts: %w", err)
+		}
+		var alert TransactionAlert
+		if err := h.persistenceService.Get(stub, transactionAlertKey(string(response.Value)), &alert); err != nil {
+			continue // Skip if alert not found
+		}
+		alerts = append(alerts, alert)
+	}
+
+	return json.Marshal(alerts)
+}
+
+// Helper methods
+
+// typologyRules returns the rule in force for each typology: the ledger rule where compliance has
+// set one, otherwise the built-in default
+func (h *TransactionMonitoringHandler) typologyRules(stub shim.ChaincodeStubInterface) ([]TransactionTypologyRule, error) {
+	rules := []TransactionTypologyRule{}
+	for _, rule := range defaultTypologyRules {
+		ruleKey, err := stub.CreateCompositeKey(config.TransactionTypologyRulePrefix, []string{string(rule.Typology)})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create typology rule key: %w", err)
+		}
+		ruleBytes, err := stub.GetState(ruleKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read typology rule: %w", err)
+		}
+		if ruleBytes != nil {
+			if err := json.Unmarshal(ruleBytes, &rule); err != nil {
+				return nil, fmt.Errorf("failed to unmarshal typology rule: %w", err)
+			}
+		}
+		rules = append(rules, rule)
+	}
+	return rules, nil
+}
+
+// customerTransactions returns a customer's ingested transactions, oldest first
+func (h *TransactionMonitoringHandler) customerTransactions(stub shim.ChaincodeStubInterface, customerID string) ([]TransactionSummary, error) {
+	iterator, err := stub.GetStateByPartialCompositeKey(config.MonitoredTransactionPrefix, []string{customerID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer transactions: %w", err)
+	}
+	defer iterator.Close()
+
+	transactions := []TransactionSummary{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate customer transactions: %w", err)
+		}
+		var txn MonitoredTransaction
+		if err := json.Unmarshal(response.Value, &txn); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal monitored transaction: %w", err)
+		}
+		transactions = append(transactions, txn.TransactionSummary)
+	}
+	return transactions, nil
+}
+
+// putMonitoredTransaction stores a transaction under its customer and time, and marks its ID as
+// ingested
+func (h *TransactionMonitoringHandler) putMonitoredTransaction(stub shim.ChaincodeStubInterface, txn *MonitoredTransaction) error {
+	key, err := stub.CreateCompositeKey(config.MonitoredTransactionPrefix, []string{
+		txn.CustomerID,
+		txn.TransactionDate.UTC().Format(monitoredTransactionDateFormat),
+		txn.TransactionID,
+	})
+	if err != nil {
+		return fmt.Errorf("failed to create monitored transaction key: %w", err)
+	}
+	if err := h.persistenceService.Put(stub, key, txn); err != nil {
+		return fmt.Errorf("failed to store monitored transaction: %w", err)
+	}
+	if err := stub.PutState(monitoredTransactionIDKey(txn.TransactionID), []byte(txn.CustomerID)); err != nil {
+		return fmt.Errorf("failed to index monitored transaction: %w", err)
+	}
+	return nil
+}
+
+// raiseTransactionAlert persists an alert for a transaction's typology hits and raises it as a
+// compliance event
+func (h *TransactionMonitoringHandler) raiseTransactionAlert(stub shim.ChaincodeStubInterface, txn TransactionSummary, hits []TypologyHit, actorID string, now time.Time) (*TransactionAlert, error) {
+	score := 0.0
+	typologies := []TransactionTypology{}
+	for _, hit := range hits {
+		score += hit.Score
+		typologies = append(typologies, hit.Typology)
+	}
+	score = math.Min(score, 100)
+
+	alert := &TransactionAlert{
+		AlertID:       services.GenerateDeterministicID(stub, config.TransactionAlertPrefix),
+		CustomerID:    txn.CustomerID,
+		TransactionID: txn.TransactionID,
+		Score:         score,
+		Severity:      transactionAlertSeverity(score),
+		Hits:          hits,
+		CreatedBy:     actorID,
+		CreatedDate:   now,
+	}
+
+	if h.eventEmitter != nil {
+		alert.EventID = services.GenerateDeterministicID(stub, config.EventPrefix)
+		event := &domain.ComplianceEvent{
+			EventID:            alert.EventID,
+			Timestamp:          now,
+			RuleID:             "TRANSACTION_MONITORING_RULE",
+			RuleVersion:        "1.0",
+			AffectedEntityID:   txn.CustomerID,
+			AffectedEntityType: "Customer",
+			EventType:          "TRANSACTION_MONITORING_ALERT",
+			Severity:           alert.Severity,
+			Details: map[string]interface{}{
+				"alertID":       alert.AlertID,
+				"transactionID": txn.TransactionID,
+				"amount":        txn.Amount,
+				"currency":      txn.Currency,
+				"typologies":    typologies,
+				"score":         score,
+			},
+			ExecutionResult: domain.RuleExecutionResult{
+				RuleID:      "TRANSACTION_MONITORING_RULE",
+				ExecutionID: services.GenerateDeterministicID(stub, "EXEC"),
+				Timestamp:   now,
+				Success:     true,
+				Passed:      false,
+			},
+			ActorID:          actorID,
+			IsAlerted:        true,
+			ResolutionStatus: "OPEN",
+		}
+		if err := h.eventEmitter.EmitComplianceEvent(stub, event); err != nil {
+			return nil, fmt.Errorf("failed to record transaction monitoring event: %w", err)
+		}
+	}
+
+	if err := h.persistenceService.Put(stub, transactionAlertKey(alert.AlertID), alert); err != nil {
+		return nil, fmt.Errorf("failed to store transaction alert: %w", err)
+	}
+	indexKey, err := stub.CreateCompositeKey("CUSTOMER_TXN_ALERT", []string{alert.CustomerID, alert.AlertID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := stub.PutState(indexKey, []byte(alert.AlertID)); err != nil {
+		return nil, fmt.Errorf("failed to index transaction alert: %w", err)
+	}
+
+	return alert, nil
+}
+
+// requireRuleMaintainer restricts typology rule changes to compliance officers
+func (h *TransactionMonitoringHandler) requireRuleMaintainer(stub shim.ChaincodeStubInterface) error {
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return err
+	}
+	if role != string(validation.ActorRoleComplianceOfficer) && role != string(validation.ActorRoleChiefComplianceOfficer) {
+		return services.NewChaincodeError(services.ErrCodeAccessDenied, "", "typology rules may only be maintained by a %s or %s", validation.ActorRoleComplianceOfficer, validation.ActorRoleChiefComplianceOfficer)
+	}
+	return nil
+}
+
+// evaluateTypologies checks a transaction against each enabled typology rule. history holds the
+// customer's earlier transactions, oldest first.
+func evaluateTypologies(rules []TransactionTypologyRule, txn TransactionSummary, history []TransactionSummary) []TypologyHit {
+	hits := []TypologyHit{}
+	for _, rule := range rules {
+		if !rule.Enabled {
+			continue
+		}
+		window := time.Duration(rule.WindowHours) * time.Hour
+		recent := transactionsWithin(history, txn, window)
+
+		switch rule.Typology {
+		case TypologyStructuring:
+			floor := rule.Ratio * rule.Threshold
+			if txn.Amount < floor || txn.Amount >= rule.Threshold {
+				continue
+			}
+			evidence := []TransactionSummary{}
+			for _, earlier := range append(recent, txn) {
+				if earlier.Direction == txn.Direction && earlier.Currency == txn.Currency && earlier.Amount >= floor && earlier.Amount < rule.Threshold {
+					evidence = append(evidence, earlier)
+				}
+			}
+			total := sumAmounts(evidence, txn.Currency).Float64()
+			if len(evidence) >= rule.MinCount && total >= rule.Threshold {
+				hits = append(hits, TypologyHit{
+					Typology: rule.Typology,
+					Score:    rule.Score,
+					Detail:   fmt.Sprintf("%d %s transactions of %.2f in total within %d hours, each just below %.2f", len(evidence), strings.ToLower(txn.Direction), total, rule.WindowHours, rule.Threshold),
+					Evidence: evidence,
+				})
+			}
+
+		case TypologyRapidMovement:
+			if txn.Direction != TransactionDirectionDebit {
+				continue
+			}
+			inflows, outflows := []TransactionSummary{}, []TransactionSummary{}
+			for _, earlier := range append(recent, txn) {
+				if earlier.Currency != txn.Currency {
+					continue
+				}
+				if earlier.Direction == TransactionDirectionCredit {
+					inflows = append(inflows, earlier)
+				} else {
+					outflows = append(outflows, earlier)
+				}
+			}
+			credits, debits := sumAmounts(inflows, txn.Currency).Float64(), sumAmounts(outflows, txn.Currency).Float64()
+			if credits >= rule.Threshold && debits >= rule.Ratio*credits {
+				hits = append(hits, TypologyHit{
+					Typology: rule.Typology,
+					Score:    rule.Score,
+					Detail:   fmt.Sprintf("%.2f moved out against %.2f received within %d hours", debits, credits, rule.WindowHours),
+					Evidence: append(recent, txn),
+				})
+			}
+
+		case TypologyRoundAmounts:
+			if !isRoundAmount(txn.Amount, rule.Threshold) {
+				continue
+			}
+			evidence := []TransactionSummary{}
+			for _, earlier := range append(recent, txn) {
+				if isRoundAmount(earlier.Amount, rule.Threshold) {
+					evidence = append(evidence, earlier)
+				}
+			}
+			if len(evidence) >= rule.MinCount {
+				hits = append(hits, TypologyHit{
+					Typology: rule.Typology,
+					Score:    rule.Score,
+					Detail:   fmt.Sprintf("%d transactions in whole multiples of %.2f within %d hours", len(evidence), rule.Threshold, rule.WindowHours),
+					Evidence: evidence,
+				})
+			}
+
+		case TypologyDormantReactivation:
+			// Customers with no earlier activity are new rather than dormant
+			if txn.Amount < rule.Threshold || len(recent) > 0 {
+				continue
+			}
+			var last *TransactionSummary
+			for i := range history {
+				if history[i].TransactionDate.After(txn.TransactionDate) {
+					break
+				}
+				last = &history[i]
+			}
+			if last == nil {
+				continue
+			}
+			hits = append(hits, TypologyHit{
+				Typology: rule.Typology,
+				Score:    rule.Score,
+				Detail:   fmt.Sprintf("%.2f after %d days without activity", txn.Amount, int(txn.TransactionDate.Sub(last.TransactionDate).Hours()/24)),
+				Evidence: []TransactionSummary{*last, txn},
+			})
+		}
+	}
+	return hits
+}
+
+// transactionsWithin returns the earlier transactions in the window ending at txn, oldest first
+func transactionsWithin(history []TransactionSummary, txn TransactionSummary, window time.Duration) []TransactionSummary {
+	since := txn.TransactionDate.Add(-window)
+	recent := []TransactionSummary{}
+	for _, earlier := range history {
+		if earlier.TransactionDate.After(since) && !earlier.TransactionDate.After(txn.TransactionDate) {
+			recent = append(recent, earlier)
+		}
+	}
+	return recent
+}
+
+// insertTransaction adds a transaction to a history, keeping it in time order
+func insertTransaction(history []TransactionSummary, txn TransactionSummary) []TransactionSummary {
+	i := sort.Search(len(history), func(i int) bool { return history[i].TransactionDate.After(txn.TransactionDate) })
+	history = append(history, TransactionSummary{})
+	copy(history[i+1:], history[i:])
+	history[i] = txn
+	return history
+}
+
+// sumAmounts totals transaction amounts in minor units of a currency, so a run of small amounts
+// adds up exactly. The transactions are expected to be in that currency.
+func sumAmounts(txns []TransactionSummary, currency string) utils.Money {
+	var minorUnits int64
+	for _, txn := range txns {
+		minorUnits += utils.NewMoney(txn.Amount, currency).MinorUnits()
+	}
+	return utils.MoneyFromMinorUnits(minorUnits, currency)
+}
+
+// isRoundAmount reports whether an amount is a whole multiple of unit
+func isRoundAmount(amount, unit float64) bool {
+	return amount >= unit && math.Abs(math.Remainder(amount, unit)) < 0.005
+}
+
+// transactionAlertSeverity maps an alert score to the severity its compliance event carries
+func transactionAlertSeverity(score float64) domain.ComplianceRulePriority {
+	switch {
+	case score >= 75:
+		return domain.PriorityCritical
+	case score >= 50:
+		return domain.PriorityHigh
+	case score >= 25:
+		return domain.PriorityMedium
+	default:
+		return domain.PriorityLow
+	}
+}
+
+// validateTransactionSummary checks an ingested transaction summary
+func validateTransactionSummary(txn *TransactionSummary, now time.Time) error {
+	if strings.TrimSpace(txn.TransactionID) == "" || strings.TrimSpace(txn.CustomerID) == "" {
+		return fmt.Errorf("transactionID and customerID are required")
+	}
+	if err := validation.ValidateAmount(txn.Amount); err != nil {
+		return err
+	}
+	if len(txn.Currency) != 3 {
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "currency must be an ISO 4217 code, got %s", txn.Currency)
+	}
+	txn.Direction = strings.ToUpper(txn.Direction)
+	if txn.Direction != TransactionDirectionCredit && txn.Direction != TransactionDirectionDebit {
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "direction must be %s or %s, got %s", TransactionDirectionCredit, TransactionDirectionDebit, txn.Direction)
+	}
+	if txn.TransactionDate.IsZero() {
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "transactionDate", "transactionDate is required")
+	}
+	if txn.TransactionDate.After(now) {
+		return fmt.Errorf("transactionDate %s is in the future", txn.TransactionDate.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// defaultTypologyRule returns a typology's built-in rule, or nil for an unknown typology
+func defaultTypologyRule(typology TransactionTypology) *TransactionTypologyRule {
+	for i := range defaultTypologyRules {
+		if defaultTypologyRules[i].Typology == typology {
+			return &defaultTypologyRules[i]
+		}
+	}
+	return nil
+}
+
+func monitoredTransactionIDKey(transactionID string) string {
+	return fmt.Sprintf("MONITORED_TXN_%s", transactionID)
+}
+
+func transactionAlertKey(alertID string) string {
+	return fmt.Sprintf("TXN_ALERT_%s", alertID)
+}
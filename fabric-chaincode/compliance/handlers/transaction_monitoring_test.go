@@ -0,0 +1,163 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/compliance/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+func TestTransactionMonitoringHandler_Typologies(t *testing.T) {
+	now := time.Date(2026, 6, 1, 12, 0, 0, 0, time.UTC)
+	clock := services.NewFixedClock(now)
+	defer services.SetClock(clock)()
+	stub := shimtest.NewMockStub("transaction_monitoring_test", nil)
+	mockEmitter := &MockEventEmitter{}
+	handler := NewTransactionMonitoringHandler(mockEmitter)
+
+	invoke := func(txID string, fn func([]string) ([]byte, error), request interface{}) ([]byte, error) {
+		requestBytes, err := json.Marshal(request)
+		require.NoError(t, err)
+		stub.MockTransactionStart(txID)
+		defer stub.MockTransactionEnd(txID)
+		return fn([]string{string(requestBytes)})
+	}
+	ingest := func(txID string, transactions ...TransactionSummary) TransactionIngestResult {
+		resultBytes, err := invoke(txID, func(args []string) ([]byte, error) { return handler.IngestTransactions(stub, args) }, TransactionIngestRequest{Transactions: transactions, ActorID: "CORE_BANKING"})
+		require.NoError(t, err)
+		var result TransactionIngestResult
+		require.NoError(t, json.Unmarshal(resultBytes, &result))
+		return result
+	}
+	txn := func(id, customerID, direction string, amount float64, hoursAgo int) TransactionSummary {
+		return TransactionSummary{
+			TransactionID:   id,
+			CustomerID:      customerID,
+			Amount:          amount,
+			Currency:        "USD",
+			Direction:       direction,
+			TransactionType: "CASH",
+			TransactionDate: now.Add(-time.Duration(hoursAgo) * time.Hour),
+		}
+	}
+	typologies := func(alert TransactionAlert) []TransactionTypology {
+		names := []TransactionTypology{}
+		for _, hit := range alert.Hits {
+			names = append(names, hit.Typology)
+		}
+		return names
+	}
+
+	// Only compliance staff and core banking ingest transactions
+	stub.Creator = newRoleIdentity(t, "Underwriter")
+	_, err := invoke("ingest_1", func(args []string) ([]byte, error) { return handler.IngestTransactions(stub, args) }, TransactionIngestRequest{
+		Transactions: []TransactionSummary{txn("T0", "CUST_1", "CREDIT", 100, 1)},
+		ActorID:      "ACTOR_001",
+	})
+	assert.Contains(t, err.Error(), "may only be ingested by")
+
+	stub.Creator = newRoleIdentity(t, "System_Administrator")
+	_, err = invoke("ingest_2", func(args []string) ([]byte, error) { return handler.IngestTransactions(stub, args) }, TransactionIngestRequest{
+		Transactions: []TransactionSummary{txn("T0", "CUST_1", "CREDIT", 100, -1)},
+		ActorID:      "CORE_BANKING",
+	})
+	assert.Contains(t, err.Error(), "in the future")
+
+	// Deposits just below the reporting threshold, sent out of order in one batch
+	result := ingest("ingest_3",
+		txn("S3", "CUST_1", "CREDIT", 8800, 2),
+		txn("S1", "CUST_1", "CREDIT", 9000, 40),
+		txn("S2", "CUST_1", "credit", 9500, 20),
+	)
+	assert.Equal(t, 3, result.Ingested)
+	require.Len(t, result.Alerts, 1)
+	structuring := result.Alerts[0]
+	assert.Equal(t, "S3", structuring.TransactionID)
+	assert.Equal(t, []TransactionTypology{TypologyStructuring}, typologies(structuring))
+	assert.Equal(t, 40.0, structuring.Score)
+	assert.Equal(t, domain.PriorityMedium, structuring.Severity)
+	require.Len(t, structuring.Hits[0].Evidence, 3)
+	assert.Equal(t, "S1", structuring.Hits[0].Evidence[0].TransactionID)
+
+	require.Len(t, mockEmitter.EmittedEvents, 1)
+	event := mockEmitter.EmittedEvents[0].(*domain.ComplianceEvent)
+	assert.Equal(t, structuring.EventID, event.EventID)
+	assert.Equal(t, "TRANSACTION_MONITORING_ALERT", event.EventType)
+	assert.Equal(t, "CUST_1", event.AffectedEntityID)
+	assert.True(t, event.IsAlerted)
+
+	// Resending the batch ingests nothing and raises nothing
+	result = ingest("ingest_4", txn("S1", "CUST_1", "CREDIT", 9000, 40), txn("S2", "CUST_1", "CREDIT", 9500, 20))
+	assert.Equal(t, 0, result.Ingested)
+	assert.ElementsMatch(t, []string{"S1", "S2"}, result.Duplicates)
+	assert.Empty(t, result.Alerts)
+
+	// Funds received and moved straight out
+	result = ingest("ingest_5", txn("R1", "CUST_2", "CREDIT", 20250, 10), txn("R2", "CUST_2", "DEBIT", 19100.5, 5))
+	require.Len(t, result.Alerts, 1)
+	assert.Equal(t, []TransactionTypology{TypologyRapidMovement}, typologies(result.Alerts[0]))
+	assert.Len(t, result.Alerts[0].Hits[0].Evidence, 2)
+
+	// Repeated round amounts
+	result = ingest("ingest_6", txn("A1", "CUST_3", "DEBIT", 2000, 240), txn("A2", "CUST_3", "DEBIT", 3000, 120), txn("A3", "CUST_3", "DEBIT", 5000, 1))
+	require.Len(t, result.Alerts, 1)
+	assert.Equal(t, []TransactionTypology{TypologyRoundAmounts}, typologies(result.Alerts[0]))
+	assert.Equal(t, domain.PriorityLow, result.Alerts[0].Severity)
+
+	// A large transaction after months of silence; a first transaction is never dormant
+	result = ingest("ingest_7", txn("D1", "CUST_4", "CREDIT", 7500.55, 200*24))
+	assert.Empty(t, result.Alerts)
+	result = ingest("ingest_8", txn("D2", "CUST_4", "CREDIT", 7500.55, 1))
+	require.Len(t, result.Alerts, 1)
+	assert.Equal(t, []TransactionTypology{TypologyDormantReactivation}, typologies(result.Alerts[0]))
+	assert.Equal(t, "D1", result.Alerts[0].Hits[0].Evidence[0].TransactionID)
+
+	// Typologies add up: round deposits just below the threshold
+	result = ingest("ingest_9", txn("C1", "CUST_5", "CREDIT", 9000, 30), txn("C2", "CUST_5", "CREDIT", 9000, 20), txn("C3", "CUST_5", "CREDIT", 9000, 10))
+	require.Len(t, result.Alerts, 1)
+	assert.ElementsMatch(t, []TransactionTypology{TypologyStructuring, TypologyRoundAmounts}, typologies(result.Alerts[0]))
+	assert.Equal(t, 55.0, result.Alerts[0].Score)
+	assert.Equal(t, domain.PriorityHigh, result.Alerts[0].Severity)
+
+	// Compliance can switch a typology off
+	stub.Creator = newRoleIdentity(t, "Compliance_Officer")
+	_, err = invoke("rule_1", func(args []string) ([]byte, error) { return handler.SetTransactionTypologyRule(stub, args) }, TransactionTypologyRuleRequest{
+		Typology: "ROUND_AMOUNTS", WindowHours: 720, Threshold: 1000, MinCount: 1, Score: 15, ActorID: "ACTOR_001",
+	})
+	assert.Contains(t, err.Error(), "minCount must be at least 2")
+	_, err = invoke("rule_2", func(args []string) ([]byte, error) { return handler.SetTransactionTypologyRule(stub, args) }, TransactionTypologyRuleRequest{
+		Typology: "round_amounts", Enabled: false, WindowHours: 720, Threshold: 1000, MinCount: 3, Score: 15, ActorID: "ACTOR_001",
+	})
+	require.NoError(t, err)
+
+	result = ingest("ingest_10", txn("B1", "CUST_6", "DEBIT", 2000, 3), txn("B2", "CUST_6", "DEBIT", 3000, 2), txn("B3", "CUST_6", "DEBIT", 4000, 1))
+	assert.Empty(t, result.Alerts)
+
+	stub.MockTransactionStart("rules")
+	rulesBytes, err := handler.GetTransactionTypologyRules(stub, []string{})
+	stub.MockTransactionEnd("rules")
+	require.NoError(t, err)
+	var rules []TransactionTypologyRule
+	require.NoError(t, json.Unmarshal(rulesBytes, &rules))
+	require.Len(t, rules, 4)
+	assert.Equal(t, TypologyRoundAmounts, rules[2].Typology)
+	assert.False(t, rules[2].Enabled)
+	assert.Equal(t, "ACTOR_001", rules[2].UpdatedBy)
+	assert.True(t, rules[0].Enabled)
+
+	// Alerts are kept with their evidence under the customer
+	stub.MockTransactionStart("alerts")
+	alertsBytes, err := handler.GetTransactionAlertsByCustomer(stub, []string{"CUST_1"})
+	stub.MockTransactionEnd("alerts")
+	require.NoError(t, err)
+	var alerts []TransactionAlert
+	require.NoError(t, json.Unmarshal(alertsBytes, &alerts))
+	require.Len(t, alerts, 1)
+	assert.Equal(t, structuring.AlertID, alerts[0].AlertID)
+	assert.Len(t, alerts[0].Hits[0].Evidence, 3)
+}
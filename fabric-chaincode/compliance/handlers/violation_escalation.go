@@ -10,7 +10,6 @@ import (
 	"github.com/brycemacchaveli/origin.block/fabric-chaincode/compliance/domain"
 	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
 	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
-	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
 )
 
 // ViolationEscalationHandler handles compliance violation escalation workflows
@@ -81,6 +80,8 @@ type ComplianceViolationEscalation struct {
 	AssignedTo         string                 `json:"assignedTo,omitempty"`
 	AssignedBy         string                 `json:"assignedBy,omitempty"`
 	AssignmentDate     *time.Time             `json:"assignmentDate,omitempty"`
+	Team               string                 `json:"team,omitempty"`        // Set by the matching routing rule
+	RoutingRule        string                 `json:"routingRule,omitempty"` // violationType/severity of the matching routing rule
 	
 	// Timing and SLA
 	CreatedDate        time.Time              `json:"createdDate"`
@@ -180,25 +181,33 @@ type EscalationRequest struct {
 // CreateEscalation creates a new compliance violation escalation
 func (h *ViolationEscalationHandler) CreateEscalation(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
 	if len(args) != 1 {
-		return nil, fmt.Errorf("incorrect number of arguments. Expected 1, got %d", len(args))
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
 	}
 
 	var req EscalationRequest
 	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
-		return nil, fmt.Errorf("failed to parse escalation request: %v", err)
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse escalation request: %v", err)
 	}
 
 	// Validate request
 	if err := h.validateEscalationRequest(&req); err != nil {
-		return nil, fmt.Errorf("invalid escalation request: %v", err)
+		return nil, fmt.Errorf("invalid escalation request: %w", err)
 	}
 
 	// Generate escalation ID
-	escalationID := utils.GenerateID(config.EscalationPrefix)
+	escalationID := services.GenerateDeterministicID(stub, config.EscalationPrefix)
 
-	// Determine initial escalation level and SLA
-	initialLevel := h.determineInitialEscalationLevel(req.ViolationSeverity, req.Priority)
-	dueDate := h.calculateSLADueDate(initialLevel, req.Priority)
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	// Route to the initial escalation level and team, and set the SLA
+	initialLevel, team, routingRule, err := h.routeEscalation(stub, req.ViolationType, req.ViolationSeverity, req.Priority)
+	if err != nil {
+		return nil, err
+	}
+	dueDate := h.calculateSLADueDate(initialLevel, req.Priority, now)
 
 	// Calculate risk score
 	riskScore := h.calculateRiskScore(req.ViolationSeverity, req.Priority, req.BusinessImpact, req.RegulatoryImpact)
@@ -215,7 +224,9 @@ func (h *ViolationEscalationHandler) CreateEscalation(stub shim.ChaincodeStubInt
 		CurrentLevel:       initialLevel,
 		Status:             EscalationStatusOpen,
 		Priority:           req.Priority,
-		CreatedDate:        time.Now(),
+		Team:               team,
+		RoutingRule:        routingRule,
+		CreatedDate:        now,
 		CreatedBy:          req.CreatedBy,
 		DueDate:            dueDate,
 		SLABreached:        false,
@@ -232,8 +243,8 @@ func (h *ViolationEscalationHandler) CreateEscalation(stub shim.ChaincodeStubInt
 
 	// Add initial history entry
 	initialHistory := EscalationHistoryEntry{
-		HistoryID: utils.GenerateID("HIST"),
-		Timestamp: time.Now(),
+		HistoryID: services.GenerateDeterministicID(stub, "HIST"),
+		Timestamp: now,
 		Action:    "ESCALATION_CREATED",
 		ToLevel:   initialLevel,
 		ToStatus:  EscalationStatusOpen,
@@ -246,9 +257,9 @@ func (h *ViolationEscalationHandler) CreateEscalation(stub shim.ChaincodeStubInt
 	// Add initial comment if provided
 	if req.InitialNotes != "" {
 		initialComment := EscalationComment{
-			CommentID:  utils.GenerateID("COMMENT"),
+			CommentID:  services.GenerateDeterministicID(stub, "COMMENT"),
 			AuthorID:   req.CreatedBy,
-			Timestamp:  time.Now(),
+			Timestamp:  now,
 			Comment:    req.InitialNotes,
 			IsInternal: true,
 		}
@@ -258,22 +269,22 @@ func (h *ViolationEscalationHandler) CreateEscalation(stub shim.ChaincodeStubInt
 	// Store escalation
 	escalationKey := fmt.Sprintf("ESCALATION_%s", escalationID)
 	if err := h.persistenceService.Put(stub, escalationKey, escalation); err != nil {
-		return nil, fmt.Errorf("failed to store escalation: %v", err)
+		return nil, fmt.Errorf("failed to store escalation: %w", err)
 	}
 
 	// Create indexes
 	if err := h.createEscalationIndexes(stub, escalation); err != nil {
-		return nil, fmt.Errorf("failed to create indexes: %v", err)
+		return nil, fmt.Errorf("failed to create indexes: %w", err)
 	}
 
 	// Send initial notifications
 	if err := h.sendEscalationNotifications(stub, escalation, "ESCALATION_CREATED"); err != nil {
-		return nil, fmt.Errorf("failed to send notifications: %v", err)
+		return nil, fmt.Errorf("failed to send notifications: %w", err)
 	}
 
 	// Record compliance event
 	if err := h.recordEscalationEvent(stub, escalation, "ESCALATION_CREATED", req.CreatedBy); err != nil {
-		return nil, fmt.Errorf("failed to record event: %v", err)
+		return nil, fmt.Errorf("failed to record event: %w", err)
 	}
 
 	return json.Marshal(escalation)
@@ -282,7 +293,7 @@ func (h *ViolationEscalationHandler) CreateEscalation(stub shim.ChaincodeStubInt
 // AssignEscalation assigns an escalation to a specific person
 func (h *ViolationEscalationHandler) AssignEscalation(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
 	if len(args) != 1 {
-		return nil, fmt.Errorf("incorrect number of arguments. Expected 1, got %d", len(args))
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
 	}
 
 	var req struct {
@@ -293,14 +304,14 @@ func (h *ViolationEscalationHandler) AssignEscalation(stub shim.ChaincodeStubInt
 	}
 
 	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
-		return nil, fmt.Errorf("failed to parse assignment request: %v", err)
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse assignment request: %v", err)
 	}
 
 	// Get existing escalation
 	escalationKey := fmt.Sprintf("ESCALATION_%s", req.EscalationID)
 	var escalation ComplianceViolationEscalation
 	if err := h.persistenceService.Get(stub, escalationKey, &escalation); err != nil {
-		return nil, fmt.Errorf("escalation not found: %v", err)
+		return nil, fmt.Errorf("escalation not found: %w", err)
 	}
 
 	// Validate assignment
@@ -309,7 +320,11 @@ func (h *ViolationEscalationHandler) AssignEscalation(stub shim.ChaincodeStubInt
 	}
 
 	// Update escalation
-	now := time.Now()
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	previous := escalation
 	previousAssignee := escalation.AssignedTo
 	escalation.AssignedTo = req.AssignedTo
 	escalation.AssignedBy = req.AssignedBy
@@ -318,10 +333,10 @@ func (h *ViolationEscalationHandler) AssignEscalation(stub shim.ChaincodeStubInt
 
 	// Add history entry
 	historyEntry := EscalationHistoryEntry{
-		HistoryID:  utils.GenerateID("HIST"),
+		HistoryID:  services.GenerateDeterministicID(stub, "HIST"),
 		Timestamp:  now,
 		Action:     "ESCALATION_ASSIGNED",
-		FromStatus: escalation.Status,
+		FromStatus: previous.Status,
 		ToStatus:   EscalationStatusAssigned,
 		ActorID:    req.AssignedBy,
 		Reason:     fmt.Sprintf("Assigned to %s", req.AssignedTo),
@@ -331,17 +346,20 @@ func (h *ViolationEscalationHandler) AssignEscalation(stub shim.ChaincodeStubInt
 
 	// Store updated escalation
 	if err := h.persistenceService.Put(stub, escalationKey, &escalation); err != nil {
-		return nil, fmt.Errorf("failed to update escalation: %v", err)
+		return nil, fmt.Errorf("failed to update escalation: %w", err)
+	}
+	if err := h.moveEscalationIndexes(stub, &previous, &escalation); err != nil {
+		return nil, err
 	}
 
 	// Send assignment notifications
 	if err := h.sendAssignmentNotifications(stub, &escalation, previousAssignee); err != nil {
-		return nil, fmt.Errorf("failed to send notifications: %v", err)
+		return nil, fmt.Errorf("failed to send notifications: %w", err)
 	}
 
 	// Record compliance event
 	if err := h.recordEscalationEvent(stub, &escalation, "ESCALATION_ASSIGNED", req.AssignedBy); err != nil {
-		return nil, fmt.Errorf("failed to record event: %v", err)
+		return nil, fmt.Errorf("failed to record event: %w", err)
 	}
 
 	return json.Marshal(&escalation)
@@ -350,7 +368,7 @@ func (h *ViolationEscalationHandler) AssignEscalation(stub shim.ChaincodeStubInt
 // EscalateToNextLevel escalates the violation to the next level
 func (h *ViolationEscalationHandler) EscalateToNextLevel(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
 	if len(args) != 1 {
-		return nil, fmt.Errorf("incorrect number of arguments. Expected 1, got %d", len(args))
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
 	}
 
 	var req struct {
@@ -361,14 +379,14 @@ func (h *ViolationEscalationHandler) EscalateToNextLevel(stub shim.ChaincodeStub
 	}
 
 	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
-		return nil, fmt.Errorf("failed to parse escalation request: %v", err)
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse escalation request: %v", err)
 	}
 
 	// Get existing escalation
 	escalationKey := fmt.Sprintf("ESCALATION_%s", req.EscalationID)
 	var escalation ComplianceViolationEscalation
 	if err := h.persistenceService.Get(stub, escalationKey, &escalation); err != nil {
-		return nil, fmt.Errorf("escalation not found: %v", err)
+		return nil, fmt.Errorf("escalation not found: %w", err)
 	}
 
 	// Validate escalation
@@ -379,28 +397,39 @@ func (h *ViolationEscalationHandler) EscalateToNextLevel(stub shim.ChaincodeStub
 	// Determine next level
 	nextLevel, err := h.getNextEscalationLevel(escalation.CurrentLevel)
 	if err != nil {
-		return nil, fmt.Errorf("cannot escalate further: %v", err)
+		return nil, fmt.Errorf("cannot escalate further: %w", err)
 	}
 
 	// Update escalation
-	now := time.Now()
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	previous := escalation
 	previousLevel := escalation.CurrentLevel
+	previousDue := escalation.DueDate
 	escalation.CurrentLevel = nextLevel
 	escalation.Status = EscalationStatusEscalated
 	escalation.AssignedTo = "" // Clear assignment for reassignment at new level
 	escalation.AssignmentDate = nil
 	
 	// Update due date based on new level
-	escalation.DueDate = h.calculateSLADueDate(nextLevel, escalation.Priority)
+	escalation.DueDate = h.calculateSLADueDate(nextLevel, escalation.Priority, now)
+	if err := stub.DelState(escalationDueKey(escalation.EscalationID, previousDue)); err != nil {
+		return nil, fmt.Errorf("failed to clear due index: %w", err)
+	}
+	if err := stub.PutState(escalationDueKey(escalation.EscalationID, escalation.DueDate), []byte(escalation.EscalationID)); err != nil {
+		return nil, fmt.Errorf("failed to create due index: %w", err)
+	}
 
 	// Add history entry
 	historyEntry := EscalationHistoryEntry{
-		HistoryID:  utils.GenerateID("HIST"),
+		HistoryID:  services.GenerateDeterministicID(stub, "HIST"),
 		Timestamp:  now,
 		Action:     "ESCALATION_LEVEL_INCREASED",
 		FromLevel:  previousLevel,
 		ToLevel:    nextLevel,
-		FromStatus: escalation.Status,
+		FromStatus: previous.Status,
 		ToStatus:   EscalationStatusEscalated,
 		ActorID:    req.EscalatedBy,
 		Reason:     req.Reason,
@@ -410,17 +439,20 @@ func (h *ViolationEscalationHandler) EscalateToNextLevel(stub shim.ChaincodeStub
 
 	// Store updated escalation
 	if err := h.persistenceService.Put(stub, escalationKey, &escalation); err != nil {
-		return nil, fmt.Errorf("failed to update escalation: %v", err)
+		return nil, fmt.Errorf("failed to update escalation: %w", err)
+	}
+	if err := h.moveEscalationIndexes(stub, &previous, &escalation); err != nil {
+		return nil, err
 	}
 
 	// Send escalation notifications
 	if err := h.sendEscalationNotifications(stub, &escalation, "ESCALATION_LEVEL_INCREASED"); err != nil {
-		return nil, fmt.Errorf("failed to send notifications: %v", err)
+		return nil, fmt.Errorf("failed to send notifications: %w", err)
 	}
 
 	// Record compliance event
 	if err := h.recordEscalationEvent(stub, &escalation, "ESCALATION_LEVEL_INCREASED", req.EscalatedBy); err != nil {
-		return nil, fmt.Errorf("failed to record event: %v", err)
+		return nil, fmt.Errorf("failed to record event: %w", err)
 	}
 
 	return json.Marshal(&escalation)
@@ -429,7 +461,7 @@ func (h *ViolationEscalationHandler) EscalateToNextLevel(stub shim.ChaincodeStub
 // ResolveEscalation resolves an escalation with resolution details
 func (h *ViolationEscalationHandler) ResolveEscalation(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
 	if len(args) != 1 {
-		return nil, fmt.Errorf("incorrect number of arguments. Expected 1, got %d", len(args))
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
 	}
 
 	var req struct {
@@ -441,23 +473,27 @@ func (h *ViolationEscalationHandler) ResolveEscalation(stub shim.ChaincodeStubIn
 	}
 
 	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
-		return nil, fmt.Errorf("failed to parse resolution request: %v", err)
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse resolution request: %v", err)
 	}
 
 	// Get existing escalation
 	escalationKey := fmt.Sprintf("ESCALATION_%s", req.EscalationID)
 	var escalation ComplianceViolationEscalation
 	if err := h.persistenceService.Get(stub, escalationKey, &escalation); err != nil {
-		return nil, fmt.Errorf("escalation not found: %v", err)
+		return nil, fmt.Errorf("escalation not found: %w", err)
 	}
 
 	// Validate resolution
 	if escalation.Status == EscalationStatusClosed || escalation.Status == EscalationStatusResolved {
-		return nil, fmt.Errorf("escalation is already resolved or closed")
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "escalation is already resolved or closed")
 	}
 
 	// Update escalation
-	now := time.Now()
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	previous := escalation
 	escalation.Status = EscalationStatusResolved
 	escalation.ResolutionDate = &now
 	escalation.ResolutionSummary = req.ResolutionSummary
@@ -465,10 +501,15 @@ func (h *ViolationEscalationHandler) ResolveEscalation(stub shim.ChaincodeStubIn
 	escalation.ResolutionNotes = req.ResolutionNotes
 	escalation.ResolvedBy = req.ResolvedBy
 
+	// Resolved escalations no longer run against their SLA
+	if err := stub.DelState(escalationDueKey(escalation.EscalationID, escalation.DueDate)); err != nil {
+		return nil, fmt.Errorf("failed to clear due index: %w", err)
+	}
+
 	// Add resolution action IDs if not provided
 	for i := range escalation.ResolutionActions {
 		if escalation.ResolutionActions[i].ActionID == "" {
-			escalation.ResolutionActions[i].ActionID = utils.GenerateID("ACTION")
+			escalation.ResolutionActions[i].ActionID = services.GenerateDeterministicID(stub, "ACTION")
 		}
 		if escalation.ResolutionActions[i].TakenDate.IsZero() {
 			escalation.ResolutionActions[i].TakenDate = now
@@ -480,10 +521,10 @@ func (h *ViolationEscalationHandler) ResolveEscalation(stub shim.ChaincodeStubIn
 
 	// Add history entry
 	historyEntry := EscalationHistoryEntry{
-		HistoryID:  utils.GenerateID("HIST"),
+		HistoryID:  services.GenerateDeterministicID(stub, "HIST"),
 		Timestamp:  now,
 		Action:     "ESCALATION_RESOLVED",
-		FromStatus: escalation.Status,
+		FromStatus: previous.Status,
 		ToStatus:   EscalationStatusResolved,
 		ActorID:    req.ResolvedBy,
 		Reason:     "Escalation resolved",
@@ -493,17 +534,20 @@ func (h *ViolationEscalationHandler) ResolveEscalation(stub shim.ChaincodeStubIn
 
 	// Store updated escalation
 	if err := h.persistenceService.Put(stub, escalationKey, &escalation); err != nil {
-		return nil, fmt.Errorf("failed to update escalation: %v", err)
+		return nil, fmt.Errorf("failed to update escalation: %w", err)
+	}
+	if err := h.moveEscalationIndexes(stub, &previous, &escalation); err != nil {
+		return nil, err
 	}
 
 	// Send resolution notifications
 	if err := h.sendResolutionNotifications(stub, &escalation); err != nil {
-		return nil, fmt.Errorf("failed to send notifications: %v", err)
+		return nil, fmt.Errorf("failed to send notifications: %w", err)
 	}
 
 	// Record compliance event
 	if err := h.recordEscalationEvent(stub, &escalation, "ESCALATION_RESOLVED", req.ResolvedBy); err != nil {
-		return nil, fmt.Errorf("failed to record event: %v", err)
+		return nil, fmt.Errorf("failed to record event: %w", err)
 	}
 
 	return json.Marshal(&escalation)
@@ -512,7 +556,7 @@ func (h *ViolationEscalationHandler) ResolveEscalation(stub shim.ChaincodeStubIn
 // AddComment adds a comment to an escalation
 func (h *ViolationEscalationHandler) AddComment(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
 	if len(args) != 1 {
-		return nil, fmt.Errorf("incorrect number of arguments. Expected 1, got %d", len(args))
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
 	}
 
 	var req struct {
@@ -524,21 +568,26 @@ func (h *ViolationEscalationHandler) AddComment(stub shim.ChaincodeStubInterface
 	}
 
 	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
-		return nil, fmt.Errorf("failed to parse comment request: %v", err)
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse comment request: %v", err)
 	}
 
 	// Get existing escalation
 	escalationKey := fmt.Sprintf("ESCALATION_%s", req.EscalationID)
 	var escalation ComplianceViolationEscalation
 	if err := h.persistenceService.Get(stub, escalationKey, &escalation); err != nil {
-		return nil, fmt.Errorf("escalation not found: %v", err)
+		return nil, fmt.Errorf("escalation not found: %w", err)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create comment
 	comment := EscalationComment{
-		CommentID:   utils.GenerateID("COMMENT"),
+		CommentID:   services.GenerateDeterministicID(stub, "COMMENT"),
 		AuthorID:    req.AuthorID,
-		Timestamp:   time.Now(),
+		Timestamp:   now,
 		Comment:     req.Comment,
 		IsInternal:  req.IsInternal,
 		Attachments: req.Attachments,
@@ -549,13 +598,13 @@ func (h *ViolationEscalationHandler) AddComment(stub shim.ChaincodeStubInterface
 
 	// Store updated escalation
 	if err := h.persistenceService.Put(stub, escalationKey, &escalation); err != nil {
-		return nil, fmt.Errorf("failed to update escalation: %v", err)
+		return nil, fmt.Errorf("failed to update escalation: %w", err)
 	}
 
 	// Send comment notifications if not internal
 	if !req.IsInternal {
 		if err := h.sendCommentNotifications(stub, &escalation, &comment); err != nil {
-			return nil, fmt.Errorf("failed to send notifications: %v", err)
+			return nil, fmt.Errorf("failed to send notifications: %w", err)
 		}
 	}
 
@@ -566,22 +615,22 @@ func (h *ViolationEscalationHandler) AddComment(stub shim.ChaincodeStubInterface
 
 func (h *ViolationEscalationHandler) validateEscalationRequest(req *EscalationRequest) error {
 	if req.ViolationID == "" {
-		return fmt.Errorf("violationID is required")
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "violationID", "violationID is required")
 	}
 	if req.ComplianceEventID == "" {
-		return fmt.Errorf("complianceEventID is required")
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "complianceEventID", "complianceEventID is required")
 	}
 	if req.ViolationType == "" {
-		return fmt.Errorf("violationType is required")
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "violationType", "violationType is required")
 	}
 	if req.AffectedEntityID == "" {
-		return fmt.Errorf("affectedEntityID is required")
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "affectedEntityID", "affectedEntityID is required")
 	}
 	if req.AffectedEntityType == "" {
-		return fmt.Errorf("affectedEntityType is required")
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "affectedEntityType", "affectedEntityType is required")
 	}
 	if req.CreatedBy == "" {
-		return fmt.Errorf("createdBy is required")
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "createdBy", "createdBy is required")
 	}
 
 	// Validate priority
@@ -596,7 +645,7 @@ func (h *ViolationEscalationHandler) validateEscalationRequest(req *EscalationRe
 		}
 	}
 	if !validPriority {
-		return fmt.Errorf("invalid priority: %s", req.Priority)
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid priority: %s", req.Priority)
 	}
 
 	return nil
@@ -612,9 +661,7 @@ func (h *ViolationEscalationHandler) determineInitialEscalationLevel(severity do
 	return EscalationLevelL1 // Start at analyst level for medium/low priority
 }
 
-func (h *ViolationEscalationHandler) calculateSLADueDate(level EscalationLevel, priority EscalationPriority) time.Time {
-	now := time.Now()
-	
+func (h *ViolationEscalationHandler) calculateSLADueDate(level EscalationLevel, priority EscalationPriority, now time.Time) time.Time {
 	// Base SLA hours by level
 	baseSLA := map[EscalationLevel]int{
 		EscalationLevelL1: 24,  // 24 hours
@@ -699,63 +746,109 @@ func (h *ViolationEscalationHandler) getNextEscalationLevel(currentLevel Escalat
 		}
 	}
 	
-	return "", fmt.Errorf("invalid current level: %s", currentLevel)
+	return "", services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid current level: %s", currentLevel)
 }
 
 func (h *ViolationEscalationHandler) createEscalationIndexes(stub shim.ChaincodeStubInterface, escalation *ComplianceViolationEscalation) error {
 	// Create index by status
 	statusKey, err := stub.CreateCompositeKey("ESCALATION_BY_STATUS", []string{string(escalation.Status), escalation.EscalationID})
 	if err != nil {
-		return fmt.Errorf("failed to create status index key: %v", err)
+		return fmt.Errorf("failed to create status index key: %w", err)
 	}
 	if err := stub.PutState(statusKey, []byte(escalation.EscalationID)); err != nil {
-		return fmt.Errorf("failed to create status index: %v", err)
+		return fmt.Errorf("failed to create status index: %w", err)
 	}
 
 	// Create index by level
 	levelKey, err := stub.CreateCompositeKey("ESCALATION_BY_LEVEL", []string{string(escalation.CurrentLevel), escalation.EscalationID})
 	if err != nil {
-		return fmt.Errorf("failed to create level index key: %v", err)
+		return fmt.Errorf("failed to create level index key: %w", err)
 	}
 	if err := stub.PutState(levelKey, []byte(escalation.EscalationID)); err != nil {
-		return fmt.Errorf("failed to create level index: %v", err)
+		return fmt.Errorf("failed to create level index: %w", err)
 	}
 
 	// Create index by assignee
 	if escalation.AssignedTo != "" {
 		assigneeKey, err := stub.CreateCompositeKey("ESCALATION_BY_ASSIGNEE", []string{escalation.AssignedTo, escalation.EscalationID})
 		if err != nil {
-			return fmt.Errorf("failed to create assignee index key: %v", err)
+			return fmt.Errorf("failed to create assignee index key: %w", err)
 		}
 		if err := stub.PutState(assigneeKey, []byte(escalation.EscalationID)); err != nil {
-			return fmt.Errorf("failed to create assignee index: %v", err)
+			return fmt.Errorf("failed to create assignee index: %w", err)
 		}
 	}
 
 	// Create index by affected entity
 	entityKey, err := stub.CreateCompositeKey("ESCALATION_BY_ENTITY", []string{escalation.AffectedEntityID, escalation.EscalationID})
 	if err != nil {
-		return fmt.Errorf("failed to create entity index key: %v", err)
+		return fmt.Errorf("failed to create entity index key: %w", err)
 	}
 	if err := stub.PutState(entityKey, []byte(escalation.EscalationID)); err != nil {
-		return fmt.Errorf("failed to create entity index: %v", err)
+		return fmt.Errorf("failed to create entity index: %w", err)
+	}
+
+	// Create index by SLA due date, swept by EscalateOverdue
+	if err := stub.PutState(escalationDueKey(escalation.EscalationID, escalation.DueDate), []byte(escalation.EscalationID)); err != nil {
+		return fmt.Errorf("failed to create due index: %w", err)
 	}
 
 	return nil
 }
 
+// moveEscalationIndexes moves an escalation's status, level and assignee index entries from their
+// previous values to the current ones
+func (h *ViolationEscalationHandler) moveEscalationIndexes(stub shim.ChaincodeStubInterface, previous, escalation *ComplianceViolationEscalation) error {
+	indexes := []struct {
+		name     string
+		from, to string
+	}{
+		{"ESCALATION_BY_STATUS", string(previous.Status), string(escalation.Status)},
+		{"ESCALATION_BY_LEVEL", string(previous.CurrentLevel), string(escalation.CurrentLevel)},
+		{"ESCALATION_BY_ASSIGNEE", previous.AssignedTo, escalation.AssignedTo},
+	}
+	for _, index := range indexes {
+		if index.from == index.to {
+			continue
+		}
+		if index.from != "" {
+			fromKey, err := stub.CreateCompositeKey(index.name, []string{index.from, escalation.EscalationID})
+			if err != nil {
+				return fmt.Errorf("failed to create %s index key: %w", index.name, err)
+			}
+			if err := stub.DelState(fromKey); err != nil {
+				return fmt.Errorf("failed to clear %s index: %w", index.name, err)
+			}
+		}
+		if index.to != "" {
+			toKey, err := stub.CreateCompositeKey(index.name, []string{index.to, escalation.EscalationID})
+			if err != nil {
+				return fmt.Errorf("failed to create %s index key: %w", index.name, err)
+			}
+			if err := stub.PutState(toKey, []byte(escalation.EscalationID)); err != nil {
+				return fmt.Errorf("failed to update %s index: %w", index.name, err)
+			}
+		}
+	}
+	return nil
+}
+
 // Notification methods (simplified implementations)
 
 func (h *ViolationEscalationHandler) sendEscalationNotifications(stub shim.ChaincodeStubInterface, escalation *ComplianceViolationEscalation, notificationType string) error {
 	// In a real implementation, this would send actual notifications
 	// For now, we'll just record the notification in the escalation
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return err
+	}
 	
 	notification := EscalationNotification{
-		NotificationID:   utils.GenerateID("NOTIF"),
+		NotificationID:   services.GenerateDeterministicID(stub, "NOTIF"),
 		NotificationType: notificationType,
 		Recipient:        h.getNotificationRecipient(escalation.CurrentLevel),
 		Channel:          "EMAIL",
-		SentDate:         time.Now(),
+		SentDate:         now,
 		Status:           "SENT",
 		Message:          fmt.Sprintf("Escalation %s: %s", escalation.EscalationID, notificationType),
 	}
@@ -765,14 +858,19 @@ func (h *ViolationEscalationHandler) sendEscalationNotifications(stub shim.Chain
 }
 
 func (h *ViolationEscalationHandler) sendAssignmentNotifications(stub shim.ChaincodeStubInterface, escalation *ComplianceViolationEscalation, previousAssignee string) error {
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return err
+	}
+
 	// Send notification to new assignee
 	if escalation.AssignedTo != "" {
 		notification := EscalationNotification{
-			NotificationID:   utils.GenerateID("NOTIF"),
+			NotificationID:   services.GenerateDeterministicID(stub, "NOTIF"),
 			NotificationType: "ESCALATION_ASSIGNED",
 			Recipient:        escalation.AssignedTo,
 			Channel:          "EMAIL",
-			SentDate:         time.Now(),
+			SentDate:         now,
 			Status:           "SENT",
 			Message:          fmt.Sprintf("You have been assigned escalation %s", escalation.EscalationID),
 		}
@@ -782,11 +880,11 @@ func (h *ViolationEscalationHandler) sendAssignmentNotifications(stub shim.Chain
 	// Send notification to previous assignee if different
 	if previousAssignee != "" && previousAssignee != escalation.AssignedTo {
 		notification := EscalationNotification{
-			NotificationID:   utils.GenerateID("NOTIF"),
+			NotificationID:   services.GenerateDeterministicID(stub, "NOTIF"),
 			NotificationType: "ESCALATION_REASSIGNED",
 			Recipient:        previousAssignee,
 			Channel:          "EMAIL",
-			SentDate:         time.Now(),
+			SentDate:         now,
 			Status:           "SENT",
 			Message:          fmt.Sprintf("Escalation %s has been reassigned", escalation.EscalationID),
 		}
@@ -797,6 +895,11 @@ func (h *ViolationEscalationHandler) sendAssignmentNotifications(stub shim.Chain
 }
 
 func (h *ViolationEscalationHandler) sendResolutionNotifications(stub shim.ChaincodeStubInterface, escalation *ComplianceViolationEscalation) error {
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return err
+	}
+
 	// Send notification to creator and assignee
 	recipients := []string{escalation.CreatedBy}
 	if escalation.AssignedTo != "" && escalation.AssignedTo != escalation.CreatedBy {
@@ -805,11 +908,11 @@ func (h *ViolationEscalationHandler) sendResolutionNotifications(stub shim.Chain
 	
 	for _, recipient := range recipients {
 		notification := EscalationNotification{
-			NotificationID:   utils.GenerateID("NOTIF"),
+			NotificationID:   services.GenerateDeterministicID(stub, "NOTIF"),
 			NotificationType: "ESCALATION_RESOLVED",
 			Recipient:        recipient,
 			Channel:          "EMAIL",
-			SentDate:         time.Now(),
+			SentDate:         now,
 			Status:           "SENT",
 			Message:          fmt.Sprintf("Escalation %s has been resolved", escalation.EscalationID),
 		}
@@ -820,14 +923,19 @@ func (h *ViolationEscalationHandler) sendResolutionNotifications(stub shim.Chain
 }
 
 func (h *ViolationEscalationHandler) sendCommentNotifications(stub shim.ChaincodeStubInterface, escalation *ComplianceViolationEscalation, comment *EscalationComment) error {
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return err
+	}
+
 	// Send notification to assignee if different from comment author
 	if escalation.AssignedTo != "" && escalation.AssignedTo != comment.AuthorID {
 		notification := EscalationNotification{
-			NotificationID:   utils.GenerateID("NOTIF"),
+			NotificationID:   services.GenerateDeterministicID(stub, "NOTIF"),
 			NotificationType: "ESCALATION_COMMENT_ADDED",
 			Recipient:        escalation.AssignedTo,
 			Channel:          "EMAIL",
-			SentDate:         time.Now(),
+			SentDate:         now,
 			Status:           "SENT",
 			Message:          fmt.Sprintf("New comment added to escalation %s", escalation.EscalationID),
 		}
@@ -852,15 +960,20 @@ func (h *ViolationEscalationHandler) getNotificationRecipient(level EscalationLe
 }
 
 func (h *ViolationEscalationHandler) recordEscalationEvent(stub shim.ChaincodeStubInterface, escalation *ComplianceViolationEscalation, eventType, actorID string) error {
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return err
+	}
+
 	if h.eventEmitter == nil {
 		return nil // No event emitter configured
 	}
 
-	eventID := utils.GenerateID(config.ComplianceEventPrefix)
+	eventID := services.GenerateDeterministicID(stub, config.ComplianceEventPrefix)
 	
 	event := &domain.ComplianceEvent{
 		EventID:            eventID,
-		Timestamp:          time.Now(),
+		Timestamp:          now,
 		RuleID:             "ESCALATION_MANAGEMENT_RULE",
 		RuleVersion:        "1.0",
 		AffectedEntityID:   escalation.AffectedEntityID,
@@ -901,7 +1014,7 @@ func (h *ViolationEscalationHandler) mapPriorityToSeverity(priority EscalationPr
 // GetEscalation retrieves an escalation by ID
 func (h *ViolationEscalationHandler) GetEscalation(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
 	if len(args) != 1 {
-		return nil, fmt.Errorf("incorrect number of arguments. Expected 1, got %d", len(args))
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
 	}
 
 	escalationID := args[0]
@@ -909,7 +1022,7 @@ func (h *ViolationEscalationHandler) GetEscalation(stub shim.ChaincodeStubInterf
 
 	var escalation ComplianceViolationEscalation
 	if err := h.persistenceService.Get(stub, escalationKey, &escalation); err != nil {
-		return nil, fmt.Errorf("escalation not found: %v", err)
+		return nil, fmt.Errorf("escalation not found: %w", err)
 	}
 
 	return json.Marshal(&escalation)
@@ -918,14 +1031,14 @@ func (h *ViolationEscalationHandler) GetEscalation(stub shim.ChaincodeStubInterf
 // GetEscalationsByStatus retrieves escalations by status
 func (h *ViolationEscalationHandler) GetEscalationsByStatus(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
 	if len(args) != 1 {
-		return nil, fmt.Errorf("incorrect number of arguments. Expected 1, got %d", len(args))
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
 	}
 
 	status := args[0]
 	
 	iterator, err := stub.GetStateByPartialCompositeKey("ESCALATION_BY_STATUS", []string{status})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get escalations by status: %v", err)
+		return nil, fmt.Errorf("failed to get escalations by status: %w", err)
 	}
 	defer iterator.Close()
 
@@ -934,7 +1047,7 @@ func (h *ViolationEscalationHandler) GetEscalationsByStatus(stub shim.ChaincodeS
 	for iterator.HasNext() {
 		response, err := iterator.Next()
 		if err != nil {
-			return nil, fmt.Errorf("failed to iterate escalations: %v", err)
+			return nil, fmt.Errorf("failed to iterate escalations: %w", err)
 		}
 
 		escalationID := string(response.Value)
@@ -954,14 +1067,14 @@ func (h *ViolationEscalationHandler) GetEscalationsByStatus(stub shim.ChaincodeS
 // GetEscalationsByAssignee retrieves escalations assigned to a specific person
 func (h *ViolationEscalationHandler) GetEscalationsByAssignee(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
 	if len(args) != 1 {
-		return nil, fmt.Errorf("incorrect number of arguments. Expected 1, got %d", len(args))
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
 	}
 
 	assignee := args[0]
 	
 	iterator, err := stub.GetStateByPartialCompositeKey("ESCALATION_BY_ASSIGNEE", []string{assignee})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get escalations by assignee: %v", err)
+		return nil, fmt.Errorf("failed to get escalations by assignee: %w", err)
 	}
 	defer iterator.Close()
 
@@ -970,7 +1083,7 @@ func (h *ViolationEscalationHandler) GetEscalationsByAssignee(stub shim.Chaincod
 	for iterator.HasNext() {
 		response, err := iterator.Next()
 		if err != nil {
-			return nil, fmt.Errorf("failed to iterate escalations: %v", err)
+			return nil, fmt.Errorf("failed to iterate escalations: %w", err)
 		}
 
 		escalationID := string(response.Value)
@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"encoding/json"
+	"fmt"
 	"testing"
 	"time"
 
@@ -9,10 +10,12 @@ import (
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/require"
 	"github.com/brycemacchaveli/origin.block/fabric-chaincode/compliance/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
 )
 
 func TestViolationEscalationHandler_CreateEscalation(t *testing.T) {
 	stub := shimtest.NewMockStub("escalation_test", nil)
+	stub.MockTransactionStart("txid")
 	mockEmitter := &MockEventEmitter{}
 	handler := NewViolationEscalationHandler(mockEmitter)
 
@@ -181,6 +184,7 @@ func TestViolationEscalationHandler_CreateEscalation(t *testing.T) {
 
 func TestViolationEscalationHandler_AssignEscalation(t *testing.T) {
 	stub := shimtest.NewMockStub("escalation_test", nil)
+	stub.MockTransactionStart("txid")
 	mockEmitter := &MockEventEmitter{}
 	handler := NewViolationEscalationHandler(mockEmitter)
 
@@ -286,6 +290,7 @@ func TestViolationEscalationHandler_AssignEscalation(t *testing.T) {
 
 func TestViolationEscalationHandler_EscalateToNextLevel(t *testing.T) {
 	stub := shimtest.NewMockStub("escalation_test", nil)
+	stub.MockTransactionStart("txid")
 	mockEmitter := &MockEventEmitter{}
 	handler := NewViolationEscalationHandler(mockEmitter)
 
@@ -397,6 +402,7 @@ func TestViolationEscalationHandler_EscalateToNextLevel(t *testing.T) {
 
 func TestViolationEscalationHandler_ResolveEscalation(t *testing.T) {
 	stub := shimtest.NewMockStub("escalation_test", nil)
+	stub.MockTransactionStart("txid")
 	mockEmitter := &MockEventEmitter{}
 	handler := NewViolationEscalationHandler(mockEmitter)
 
@@ -519,6 +525,7 @@ func TestViolationEscalationHandler_ResolveEscalation(t *testing.T) {
 
 func TestViolationEscalationHandler_AddComment(t *testing.T) {
 	stub := shimtest.NewMockStub("escalation_test", nil)
+	stub.MockTransactionStart("txid")
 	mockEmitter := &MockEventEmitter{}
 	handler := NewViolationEscalationHandler(mockEmitter)
 
@@ -634,6 +641,7 @@ func TestViolationEscalationHandler_AddComment(t *testing.T) {
 
 func TestViolationEscalationHandler_GetEscalation(t *testing.T) {
 	stub := shimtest.NewMockStub("escalation_test", nil)
+	stub.MockTransactionStart("txid")
 	mockEmitter := &MockEventEmitter{}
 	handler := NewViolationEscalationHandler(mockEmitter)
 
@@ -702,6 +710,9 @@ func TestViolationEscalationHandler_GetEscalation(t *testing.T) {
 
 func TestViolationEscalationHandler_GetEscalationsByStatus(t *testing.T) {
 	stub := shimtest.NewMockStub("escalation_test", nil)
+	// The escalations are created in one transaction, which numbers the IDs generated in it
+	invocation := services.NewInvocation(stub)
+	stub.MockTransactionStart("txid")
 	mockEmitter := &MockEventEmitter{}
 	handler := NewViolationEscalationHandler(mockEmitter)
 
@@ -734,7 +745,7 @@ func TestViolationEscalationHandler_GetEscalationsByStatus(t *testing.T) {
 		createBytes, err := json.Marshal(createRequest)
 		require.NoError(t, err)
 
-		createResult, err := handler.CreateEscalation(stub, []string{string(createBytes)})
+		createResult, err := handler.CreateEscalation(invocation, []string{string(createBytes)})
 		require.NoError(t, err)
 
 		var createdEscalation ComplianceViolationEscalation
@@ -756,7 +767,7 @@ func TestViolationEscalationHandler_GetEscalationsByStatus(t *testing.T) {
 			assignBytes, err := json.Marshal(assignRequest)
 			require.NoError(t, err)
 
-			assignResult, err := handler.AssignEscalation(stub, []string{string(assignBytes)})
+			assignResult, err := handler.AssignEscalation(invocation, []string{string(assignBytes)})
 			require.NoError(t, err)
 
 			err = json.Unmarshal(assignResult, &createdEscalation)
@@ -790,7 +801,7 @@ func TestViolationEscalationHandler_GetEscalationsByStatus(t *testing.T) {
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			result, err := handler.GetEscalationsByStatus(stub, []string{tt.status})
+			result, err := handler.GetEscalationsByStatus(invocation, []string{tt.status})
 			require.NoError(t, err)
 			require.NotNil(t, result)
 
@@ -847,7 +858,7 @@ func TestViolationEscalationHandler_HelperMethods(t *testing.T) {
 		}
 
 		for _, tt := range tests {
-			dueDate := handler.calculateSLADueDate(tt.level, tt.priority)
+			dueDate := handler.calculateSLADueDate(tt.level, tt.priority, baseTime)
 			hoursDiff := dueDate.Sub(baseTime).Hours()
 			
 			assert.GreaterOrEqual(t, hoursDiff, float64(tt.minHours),
@@ -911,6 +922,7 @@ func TestViolationEscalationHandler_HelperMethods(t *testing.T) {
 
 func BenchmarkViolationEscalationHandler_CreateEscalation(b *testing.B) {
 	stub := shimtest.NewMockStub("escalation_benchmark", nil)
+	stub.MockTransactionStart("txid")
 	handler := NewViolationEscalationHandler(nil)
 
 	request := EscalationRequest{
@@ -944,6 +956,7 @@ func BenchmarkViolationEscalationHandler_CreateEscalation(b *testing.B) {
 
 func BenchmarkViolationEscalationHandler_AssignEscalation(b *testing.B) {
 	stub := shimtest.NewMockStub("escalation_benchmark", nil)
+	stub.MockTransactionStart("txid")
 	handler := NewViolationEscalationHandler(nil)
 
 	// Create a base escalation
@@ -1,15 +1,26 @@
 package main
 
 import (
+	"crypto/sha256"
+	"encoding/hex"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"log"
+	"sort"
+	"strconv"
 	"strings"
+	"sync"
 	"time"
 
 	"github.com/hyperledger/fabric-chaincode-go/shim"
 	"github.com/hyperledger/fabric-protos-go/peer"
-	"github.com/blockchain-financial-platform/fabric-chaincode/shared"
+	sharedChaincode "github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/chaincode"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/compliance/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/compliance/handlers"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
 )
 
 // ComplianceRule represents a compliance rule with logic and metadata
@@ -24,6 +35,7 @@ type ComplianceRule struct {
 	LastModifiedDate   time.Time `json:"lastModifiedDate"`
 	CreatedBy          string    `json:"createdBy"`
 	CreatedDate        time.Time `json:"createdDate"`
+	Version            int       `json:"version"`
 }
 
 // ComplianceEvent represents a compliance check event
@@ -44,6 +56,7 @@ type ComplianceEvent struct {
 
 // ComplianceChaincode implements the fabric Contract interface
 type ComplianceChaincode struct {
+	guard transactionGuard // Validations, screenings and events already performed per invocation
 }
 
 // Init is called during chaincode instantiation to initialize any data
@@ -51,10 +64,61 @@ func (t *ComplianceChaincode) Init(stub shim.ChaincodeStubInterface) peer.Respon
 	return shim.Success(nil)
 }
 
+// legacyActorArguments gives the argument naming the acting actor of each function taking one
+var legacyActorArguments = sharedChaincode.ActorArguments{
+	"UpdateComplianceRule":       {Index: 5},
+	"RecordComplianceEvent":      {Index: 5},
+	"AddSanctionListEntry":       {Index: 9},
+	"ScreenAgainstSanctionLists": {Index: 3},
+	"ReviewScreeningResult":      {Index: 3},
+	"CreateSanctionList":         {Index: 0, Field: "createdBy"},
+	"UpdateSanctionList":         {Index: 0, Field: "updatedBy"},
+}
+
+// legacyFreezeArguments exempts the state-changing functions from entity freezes, all of them
+// compliance actions, see config.FreezeExemptFunctions
+var legacyFreezeArguments = sharedChaincode.FreezeExempt(
+	"UpdateComplianceRule",
+	"RecordComplianceEvent",
+	"ValidateComplianceRules",
+	"ValidateLoanApplication",
+	"ValidateCustomer",
+	"AddSanctionListEntry",
+	"ScreenAgainstSanctionLists",
+	"ReviewScreeningResult",
+	"CreateSanctionList",
+	"UpdateSanctionList",
+)
+
+// legacyPIIFunctions are the functions whose arguments carry customer PII, which is never
+// retained in the payload archive
+var legacyPIIFunctions = map[string]bool{
+	"ValidateCustomer":           true,
+	"ValidateLoanApplication":    true,
+	"ScreenAgainstSanctionLists": true,
+}
+
 // Invoke is called per transaction on the chaincode
 func (t *ComplianceChaincode) Invoke(stub shim.ChaincodeStubInterface) peer.Response {
+	// Handlers share the invocation's state, such as the IDs generated so far, through its stub
+	stub = services.NewInvocation(stub)
 	function, args := stub.GetFunctionAndParameters()
 	
+	// Run the kill switch, binding, freeze, rotation, signature and archive checks of every entry point
+	if err := sharedChaincode.GuardInvocation(stub, function, args, legacyActorArguments, legacyFreezeArguments, legacyPIIFunctions[function]); err != nil {
+		return sharedChaincode.ErrorResponse(err)
+	}
+
+	// Failures leave as the shared error envelope, see services.ChaincodeError
+	response := t.dispatch(stub, function, args)
+	if response.Status >= shim.ERRORTHRESHOLD && !services.IsErrorEnvelope(response.Message) {
+		return sharedChaincode.ErrorResponse(errors.New(response.Message))
+	}
+	return response
+}
+
+// dispatch invokes the chaincode function named by the transaction
+func (t *ComplianceChaincode) dispatch(stub shim.ChaincodeStubInterface, function string, args []string) peer.Response {
 	switch function {
 	case "ping":
 		return shim.Success([]byte("pong"))
@@ -84,12 +148,22 @@ func (t *ComplianceChaincode) Invoke(stub shim.ChaincodeStubInterface) peer.Resp
 		return t.GetSanctionListEntry(stub, args)
 	case "ScreenAgainstSanctionLists":
 		return t.ScreenAgainstSanctionLists(stub, args)
+	case "CreateSanctionList":
+		return handlerResponse(t.sanctionLists().CreateSanctionList(stub, args))
+	case "UpdateSanctionList":
+		return handlerResponse(t.sanctionLists().UpdateSanctionList(stub, args))
+	case "GetSanctionList":
+		return handlerResponse(t.sanctionLists().GetSanctionList(stub, args))
+	case "GetActiveSanctionLists":
+		return handlerResponse(t.sanctionLists().GetActiveSanctionLists(stub, args))
 	case "GetScreeningResult":
 		return t.GetScreeningResult(stub, args)
 	case "GetScreeningResultsByEntity":
 		return t.GetScreeningResultsByEntity(stub, args)
+	case "ReviewScreeningResult":
+		return t.ReviewScreeningResult(stub, args)
 	default:
-		return shim.Error("Invalid function name: " + function)
+		return sharedChaincode.ErrorResponse(services.NewChaincodeError(services.ErrCodeUnknownFunction, "", "function %s not found", function))
 	}
 }
 
@@ -106,7 +180,7 @@ func (t *ComplianceChaincode) GetComplianceRule(stub shim.ChaincodeStubInterface
 
 	// Get rule from ledger
 	var rule ComplianceRule
-	err := shared.GetStateAsJSON(stub, "RULE_"+ruleID, &rule)
+	err := getStateAsJSON(stub, "RULE_"+ruleID, &rule)
 	if err != nil {
 		return shim.Error(fmt.Sprintf("Failed to get compliance rule %s: %v", ruleID, err))
 	}
@@ -120,10 +194,12 @@ func (t *ComplianceChaincode) GetComplianceRule(stub shim.ChaincodeStubInterface
 	return shim.Success(ruleJSON)
 }
 
-// UpdateComplianceRule creates or updates a compliance rule
+// UpdateComplianceRule creates or updates a compliance rule. An optional seventh argument names
+// the rule version the update was made against; the update is rejected with CONFLICT if the rule
+// has changed since.
 func (t *ComplianceChaincode) UpdateComplianceRule(stub shim.ChaincodeStubInterface, args []string) peer.Response {
-	if len(args) != 6 {
-		return shim.Error("Incorrect number of arguments. Expecting 6: ruleID, ruleName, ruleDescription, ruleLogic, appliesToDomain, actorID")
+	if len(args) != 6 && len(args) != 7 {
+		return shim.Error("Incorrect number of arguments. Expecting 6 or 7: ruleID, ruleName, ruleDescription, ruleLogic, appliesToDomain, actorID[, expectedVersion]")
 	}
 
 	ruleID := args[0]
@@ -143,33 +219,46 @@ func (t *ComplianceChaincode) UpdateComplianceRule(stub shim.ChaincodeStubInterf
 		"actorID":          actorID,
 	}
 
-	if err := shared.ValidateRequired(requiredFields); err != nil {
+	if err := validateRequired(requiredFields); err != nil {
 		return shim.Error(fmt.Sprintf("Validation failed: %v", err))
 	}
 
 	// Validate actor access
-	_, err := shared.ValidateActorAccess(stub, actorID, shared.PermissionUpdateCompliance)
+	_, err := validateComplianceActor(stub, actorID)
 	if err != nil {
 		return shim.Error(fmt.Sprintf("Access denied: %v", err))
 	}
 
 	// Validate domain
 	allowedDomains := []string{"Customer", "Loan", "Compliance", "All"}
-	if err := shared.ValidateStatus(appliesToDomain, allowedDomains); err != nil {
+	if err := validation.ValidateStatus(appliesToDomain, allowedDomains); err != nil {
 		return shim.Error(fmt.Sprintf("Invalid domain: %v", err))
 	}
 
 	// Check if rule exists
 	var existingRule ComplianceRule
 	ruleExists := true
-	err = shared.GetStateAsJSON(stub, "RULE_"+ruleID, &existingRule)
+	err = getStateAsJSON(stub, "RULE_"+ruleID, &existingRule)
 	if err != nil {
 		ruleExists = false
 	}
 
+	if len(args) == 7 {
+		expectedVersion, err := strconv.Atoi(args[6])
+		if err != nil {
+			return shim.Error(fmt.Sprintf("Invalid expectedVersion: %s", args[6]))
+		}
+		if err := services.CheckExpectedVersion("ComplianceRule", ruleID, &expectedVersion, existingRule.Version); err != nil {
+			return sharedChaincode.ErrorResponse(err)
+		}
+	}
+
 	// Create or update rule
 	var rule ComplianceRule
-	now := time.Now()
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to get transaction time: %v", err))
+	}
 
 	if ruleExists {
 		// Update existing rule
@@ -181,6 +270,7 @@ func (t *ComplianceChaincode) UpdateComplianceRule(stub shim.ChaincodeStubInterf
 		rule.LastModifiedBy = actorID
 		rule.LastModifiedDate = now
 		rule.Status = "Active"
+		rule.Version++
 	} else {
 		// Create new rule
 		rule = ComplianceRule{
@@ -194,11 +284,12 @@ func (t *ComplianceChaincode) UpdateComplianceRule(stub shim.ChaincodeStubInterf
 			LastModifiedDate:   now,
 			CreatedBy:          actorID,
 			CreatedDate:        now,
+			Version:            1,
 		}
 	}
 
 	// Store rule
-	err = shared.PutStateAsJSON(stub, "RULE_"+ruleID, rule)
+	err = putStateAsJSON(stub, "RULE_"+ruleID, rule)
 	if err != nil {
 		return shim.Error(fmt.Sprintf("Failed to store compliance rule: %v", err))
 	}
@@ -209,7 +300,7 @@ func (t *ComplianceChaincode) UpdateComplianceRule(stub shim.ChaincodeStubInterf
 		changeType = "CREATE"
 	}
 	
-	err = shared.RecordHistoryEntry(stub, ruleID, "ComplianceRule", changeType, "rule", "", "", actorID)
+	err = recordHistoryEntry(stub, ruleID, "ComplianceRule", changeType, "rule", "", "", actorID)
 	if err != nil {
 		return shim.Error(fmt.Sprintf("Failed to record history: %v", err))
 	}
@@ -223,7 +314,7 @@ func (t *ComplianceChaincode) UpdateComplianceRule(stub shim.ChaincodeStubInterf
 		"timestamp": now,
 	}
 	
-	err = shared.EmitEvent(stub, "ComplianceRuleUpdated", eventPayload)
+	err = emitEvent(stub, "ComplianceRuleUpdated", eventPayload)
 	if err != nil {
 		return shim.Error(fmt.Sprintf("Failed to emit event: %v", err))
 	}
@@ -259,32 +350,35 @@ func (t *ComplianceChaincode) RecordComplianceEvent(stub shim.ChaincodeStubInter
 		"actorID":            actorID,
 	}
 
-	if err := shared.ValidateRequired(requiredFields); err != nil {
+	if err := validateRequired(requiredFields); err != nil {
 		return shim.Error(fmt.Sprintf("Validation failed: %v", err))
 	}
 
 	// Validate event type
 	allowedEventTypes := []string{"RULE_EVALUATION", "VIOLATION", "COMPLIANCE_CHECK", "ALERT", "ACKNOWLEDGMENT"}
-	if err := shared.ValidateStatus(eventType, allowedEventTypes); err != nil {
+	if err := validation.ValidateStatus(eventType, allowedEventTypes); err != nil {
 		return shim.Error(fmt.Sprintf("Invalid event type: %v", err))
 	}
 
 	// Validate entity type
 	allowedEntityTypes := []string{"Customer", "LoanApplication", "ComplianceRule", "Actor"}
-	if err := shared.ValidateStatus(affectedEntityType, allowedEntityTypes); err != nil {
+	if err := validation.ValidateStatus(affectedEntityType, allowedEntityTypes); err != nil {
 		return shim.Error(fmt.Sprintf("Invalid entity type: %v", err))
 	}
 
 	// Verify rule exists
 	var rule ComplianceRule
-	err := shared.GetStateAsJSON(stub, "RULE_"+ruleID, &rule)
+	err := getStateAsJSON(stub, "RULE_"+ruleID, &rule)
 	if err != nil {
 		return shim.Error(fmt.Sprintf("Compliance rule %s not found: %v", ruleID, err))
 	}
 
 	// Generate event ID
-	eventID := shared.GenerateID("EVENT")
-	now := time.Now()
+	eventID := services.GenerateDeterministicID(stub, "EVENT")
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to get transaction time: %v", err))
+	}
 	txID := stub.GetTxID()
 
 	// Determine if this should trigger an alert
@@ -305,10 +399,13 @@ func (t *ComplianceChaincode) RecordComplianceEvent(stub shim.ChaincodeStubInter
 	}
 
 	// Store event
-	err = shared.PutStateAsJSON(stub, "EVENT_"+eventID, event)
+	err = putStateAsJSON(stub, "EVENT_"+eventID, event)
 	if err != nil {
 		return shim.Error(fmt.Sprintf("Failed to store compliance event: %v", err))
 	}
+	if err := services.IncrementCounter(stub, config.MetricComplianceEvents); err != nil {
+		return shim.Error(fmt.Sprintf("Failed to count compliance event: %v", err))
+	}
 
 	// Create composite keys for efficient querying
 	// By rule ID
@@ -343,7 +440,7 @@ func (t *ComplianceChaincode) RecordComplianceEvent(stub shim.ChaincodeStubInter
 		"timestamp":          now,
 	}
 	
-	err = shared.EmitEvent(stub, "ComplianceEventRecorded", eventPayload)
+	err = emitEvent(stub, "ComplianceEventRecorded", eventPayload)
 	if err != nil {
 		return shim.Error(fmt.Sprintf("Failed to emit event: %v", err))
 	}
@@ -370,7 +467,7 @@ func (t *ComplianceChaincode) GetComplianceEvent(stub shim.ChaincodeStubInterfac
 
 	// Get event from ledger
 	var event ComplianceEvent
-	err := shared.GetStateAsJSON(stub, "EVENT_"+eventID, &event)
+	err := getStateAsJSON(stub, "EVENT_"+eventID, &event)
 	if err != nil {
 		return shim.Error(fmt.Sprintf("Failed to get compliance event %s: %v", eventID, err))
 	}
@@ -411,7 +508,7 @@ func (t *ComplianceChaincode) GetComplianceEventsByRule(stub shim.ChaincodeStubI
 
 		eventID := string(response.Value)
 		var event ComplianceEvent
-		err = shared.GetStateAsJSON(stub, "EVENT_"+eventID, &event)
+		err = getStateAsJSON(stub, "EVENT_"+eventID, &event)
 		if err != nil {
 			return shim.Error(fmt.Sprintf("Failed to get event %s: %v", eventID, err))
 		}
@@ -455,7 +552,7 @@ func (t *ComplianceChaincode) GetComplianceEventsByEntity(stub shim.ChaincodeStu
 
 		eventID := string(response.Value)
 		var event ComplianceEvent
-		err = shared.GetStateAsJSON(stub, "EVENT_"+eventID, &event)
+		err = getStateAsJSON(stub, "EVENT_"+eventID, &event)
 		if err != nil {
 			return shim.Error(fmt.Sprintf("Failed to get event %s: %v", eventID, err))
 		}
@@ -607,7 +704,7 @@ func (t *ComplianceChaincode) ValidateComplianceRules(stub shim.ChaincodeStubInt
 		"dataJSON":   dataJSON,
 	}
 
-	if err := shared.ValidateRequired(requiredFields); err != nil {
+	if err := validateRequired(requiredFields); err != nil {
 		return shim.Error(fmt.Sprintf("Validation failed: %v", err))
 	}
 
@@ -618,11 +715,24 @@ func (t *ComplianceChaincode) ValidateComplianceRules(stub shim.ChaincodeStubInt
 		return shim.Error(fmt.Sprintf("Failed to parse data JSON: %v", err))
 	}
 
+	// Return the earlier result if this entity was already validated in this invocation,
+	// e.g. by ValidateLoanApplication delegating here
+	canonicalData, _ := json.Marshal(data)
+	validationKey := guardKey("VALIDATE", domain, entityID, entityType, string(canonicalData))
+	if cached, ok := t.guard.lookup(stub, validationKey); ok {
+		return cached
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to get transaction time: %v", err))
+	}
+
 	// Initialize rule engine
 	ruleEngine := t.InitializeHardcodedRules()
 
 	// Get caller identity for event recording
-	callerID, err := shared.GetCallerIdentity(stub)
+	callerID, err := services.InvokerID(stub)
 	if err != nil {
 		return shim.Error(fmt.Sprintf("Failed to get caller identity: %v", err))
 	}
@@ -677,7 +787,7 @@ func (t *ComplianceChaincode) ValidateComplianceRules(stub shim.ChaincodeStubInt
 		"checkCount":       len(complianceChecks),
 		"violations":       violations,
 		"complianceChecks": complianceChecks,
-		"timestamp":        time.Now(),
+		"timestamp":        now,
 	}
 
 	// Marshal response to JSON
@@ -686,16 +796,29 @@ func (t *ComplianceChaincode) ValidateComplianceRules(stub shim.ChaincodeStubInt
 		return shim.Error(fmt.Sprintf("Failed to marshal response: %v", err))
 	}
 
-	return shim.Success(responseJSON)
+	validationResponse := shim.Success(responseJSON)
+	t.guard.record(stub, validationKey, validationResponse)
+
+	return validationResponse
 }
 
 // recordAutomatedComplianceEvent is a helper function to record compliance events
 func (t *ComplianceChaincode) recordAutomatedComplianceEvent(stub shim.ChaincodeStubInterface, ruleID, entityID, entityType, eventType, details, actorID string) error {
-	// Generate event ID
-	eventID := shared.GenerateID("EVENT")
-	now := time.Now()
 	txID := stub.GetTxID()
 
+	// Record each rule outcome for an entity once per invocation
+	eventKey := guardKey("EVENT", ruleID, entityID, entityType, eventType)
+	if _, recorded := t.guard.lookup(stub, eventKey); recorded {
+		return nil
+	}
+
+	// Generate event ID
+	eventID := services.GenerateDeterministicID(stub, "EVENT")
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return err
+	}
+
 	// Determine if this should trigger an alert
 	isAlerted := (eventType == "VIOLATION" || eventType == "ALERT")
 
@@ -714,30 +837,33 @@ func (t *ComplianceChaincode) recordAutomatedComplianceEvent(stub shim.Chaincode
 	}
 
 	// Store event
-	err := shared.PutStateAsJSON(stub, "EVENT_"+eventID, event)
+	err = putStateAsJSON(stub, "EVENT_"+eventID, event)
 	if err != nil {
-		return fmt.Errorf("failed to store compliance event: %v", err)
+		return fmt.Errorf("failed to store compliance event: %w", err)
+	}
+	if err := services.IncrementCounter(stub, config.MetricComplianceEvents); err != nil {
+		return fmt.Errorf("failed to count compliance event: %w", err)
 	}
 
 	// Create composite keys for efficient querying
 	// By rule ID
 	ruleCompositeKey, err := stub.CreateCompositeKey("EVENT_BY_RULE", []string{ruleID, eventID})
 	if err != nil {
-		return fmt.Errorf("failed to create rule composite key: %v", err)
+		return fmt.Errorf("failed to create rule composite key: %w", err)
 	}
 	err = stub.PutState(ruleCompositeKey, []byte(eventID))
 	if err != nil {
-		return fmt.Errorf("failed to store rule composite key: %v", err)
+		return fmt.Errorf("failed to store rule composite key: %w", err)
 	}
 
 	// By entity ID
 	entityCompositeKey, err := stub.CreateCompositeKey("EVENT_BY_ENTITY", []string{entityID, eventID})
 	if err != nil {
-		return fmt.Errorf("failed to create entity composite key: %v", err)
+		return fmt.Errorf("failed to create entity composite key: %w", err)
 	}
 	err = stub.PutState(entityCompositeKey, []byte(eventID))
 	if err != nil {
-		return fmt.Errorf("failed to store entity composite key: %v", err)
+		return fmt.Errorf("failed to store entity composite key: %w", err)
 	}
 
 	// Emit event
@@ -752,11 +878,13 @@ func (t *ComplianceChaincode) recordAutomatedComplianceEvent(stub shim.Chaincode
 		"timestamp":          now,
 	}
 	
-	err = shared.EmitEvent(stub, "ComplianceEventRecorded", eventPayload)
+	err = emitEvent(stub, "ComplianceEventRecorded", eventPayload)
 	if err != nil {
-		return fmt.Errorf("failed to emit event: %v", err)
+		return fmt.Errorf("failed to emit event: %w", err)
 	}
 
+	t.guard.record(stub, eventKey, shim.Success(nil))
+
 	return nil
 }
 
@@ -842,23 +970,22 @@ func (t *ComplianceChaincode) GetHardcodedRules(stub shim.ChaincodeStubInterface
 // SANCTION LIST SCREENING INTEGRATION
 // ============================================================================
 
-// SanctionListEntry represents an entry in a sanction list
-type SanctionListEntry struct {
-	EntryID          string    `json:"entryID"`
-	ListName         string    `json:"listName"`
-	EntityName       string    `json:"entityName"`
-	EntityType       string    `json:"entityType"` // Individual, Organization, Vessel, etc.
-	Aliases          []string  `json:"aliases"`
-	DateOfBirth      string    `json:"dateOfBirth,omitempty"`
-	PlaceOfBirth     string    `json:"placeOfBirth,omitempty"`
-	Nationality      string    `json:"nationality,omitempty"`
-	Address          string    `json:"address,omitempty"`
-	IdentificationNo string    `json:"identificationNo,omitempty"`
-	SanctionType     string    `json:"sanctionType"`
-	ListingDate      time.Time `json:"listingDate"`
-	LastUpdated      time.Time `json:"lastUpdated"`
-	IsActive         bool      `json:"isActive"`
-	Source           string    `json:"source"`
+// SanctionListEntry represents an entry in a sanction list. The store is shared with the
+// managed sanction lists, so imported entries are screened here too.
+type SanctionListEntry = handlers.SanctionListEntry
+
+// sanctionLists manages the official lists whose imported entries are screened alongside the
+// entries added through AddSanctionListEntry
+func (t *ComplianceChaincode) sanctionLists() *handlers.SanctionListManager {
+	return handlers.NewSanctionListManager(domain.NewFabricEventEmitter())
+}
+
+// handlerResponse wraps the result of a handler package function
+func handlerResponse(payload []byte, err error) peer.Response {
+	if err != nil {
+		return sharedChaincode.ErrorResponse(err)
+	}
+	return shim.Success(payload)
 }
 
 // SanctionScreeningResult represents the result of a sanction screening
@@ -871,7 +998,7 @@ type SanctionScreeningResult struct {
 	IsMatch          bool                   `json:"isMatch"`
 	MatchScore       float64                `json:"matchScore"`
 	Matches          []SanctionMatch        `json:"matches"`
-	Status           string                 `json:"status"` // CLEARED, FLAGGED, REQUIRES_REVIEW
+	Status           string                 `json:"status"` // CLEARED, FLAGGED, REQUIRES_REVIEW, ESCALATED, CONFIRMED_MATCH
 	ReviewedBy       string                 `json:"reviewedBy,omitempty"`
 	ReviewDate       time.Time              `json:"reviewDate,omitempty"`
 	ReviewComments   string                 `json:"reviewComments,omitempty"`
@@ -917,12 +1044,12 @@ func (t *ComplianceChaincode) AddSanctionListEntry(stub shim.ChaincodeStubInterf
 		"actorID":      actorID,
 	}
 
-	if err := shared.ValidateRequired(requiredFields); err != nil {
+	if err := validateRequired(requiredFields); err != nil {
 		return shim.Error(fmt.Sprintf("Validation failed: %v", err))
 	}
 
 	// Validate actor access
-	_, err := shared.ValidateActorAccess(stub, actorID, shared.PermissionUpdateCompliance)
+	_, err := validateComplianceActor(stub, actorID)
 	if err != nil {
 		return shim.Error(fmt.Sprintf("Access denied: %v", err))
 	}
@@ -937,7 +1064,10 @@ func (t *ComplianceChaincode) AddSanctionListEntry(stub shim.ChaincodeStubInterf
 	}
 
 	// Create sanction list entry
-	now := time.Now()
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to get transaction time: %v", err))
+	}
 	entry := SanctionListEntry{
 		EntryID:       entryID,
 		ListName:      listName,
@@ -953,22 +1083,11 @@ func (t *ComplianceChaincode) AddSanctionListEntry(stub shim.ChaincodeStubInterf
 		Source:        source,
 	}
 
-	// Store entry
-	err = shared.PutStateAsJSON(stub, "SANCTION_"+entryID, entry)
-	if err != nil {
+	// Store entry and index its name, aliases and list for screening lookups
+	if err := handlers.PutSanctionListEntry(stub, &entry); err != nil {
 		return shim.Error(fmt.Sprintf("Failed to store sanction list entry: %v", err))
 	}
 
-	// Create composite key for efficient querying by list name
-	listCompositeKey, err := stub.CreateCompositeKey("SANCTION_BY_LIST", []string{listName, entryID})
-	if err != nil {
-		return shim.Error(fmt.Sprintf("Failed to create list composite key: %v", err))
-	}
-	err = stub.PutState(listCompositeKey, []byte(entryID))
-	if err != nil {
-		return shim.Error(fmt.Sprintf("Failed to store list composite key: %v", err))
-	}
-
 	// Emit event
 	eventPayload := map[string]interface{}{
 		"entryID":    entryID,
@@ -979,7 +1098,7 @@ func (t *ComplianceChaincode) AddSanctionListEntry(stub shim.ChaincodeStubInterf
 		"timestamp":  now,
 	}
 	
-	err = shared.EmitEvent(stub, "SanctionListEntryAdded", eventPayload)
+	err = emitEvent(stub, "SanctionListEntryAdded", eventPayload)
 	if err != nil {
 		return shim.Error(fmt.Sprintf("Failed to emit event: %v", err))
 	}
@@ -1005,8 +1124,7 @@ func (t *ComplianceChaincode) GetSanctionListEntry(stub shim.ChaincodeStubInterf
 	}
 
 	// Get entry from ledger
-	var entry SanctionListEntry
-	err := shared.GetStateAsJSON(stub, "SANCTION_"+entryID, &entry)
+	entry, err := handlers.GetSanctionListEntry(stub, entryID)
 	if err != nil {
 		return shim.Error(fmt.Sprintf("Failed to get sanction list entry %s: %v", entryID, err))
 	}
@@ -1039,7 +1157,7 @@ func (t *ComplianceChaincode) ScreenAgainstSanctionLists(stub shim.ChaincodeStub
 		"actorID":        actorID,
 	}
 
-	if err := shared.ValidateRequired(requiredFields); err != nil {
+	if err := validateRequired(requiredFields); err != nil {
 		return shim.Error(fmt.Sprintf("Validation failed: %v", err))
 	}
 
@@ -1065,13 +1183,26 @@ func (t *ComplianceChaincode) ScreenAgainstSanctionLists(stub shim.ChaincodeStub
 		}
 	}
 
-	// Generate screening ID
-	screeningID := shared.GenerateID("SCREEN")
-	now := time.Now()
+	// Return the earlier result if this entity was already screened in this invocation
 	txID := stub.GetTxID()
+	canonicalData, _ := json.Marshal(entityData)
+	screeningKey := guardKey("SCREEN", entityID, entityType, string(canonicalData))
+	if cached, ok := t.guard.lookup(stub, screeningKey); ok {
+		return cached
+	}
 
-	// Perform screening against hardcoded sanction list entries
-	matches := t.performSanctionScreening(stub, entityName, entityData)
+	// Generate screening ID
+	screeningID := services.GenerateDeterministicID(stub, "SCREEN")
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to get transaction time: %v", err))
+	}
+
+	// Perform screening against the sanction list entries stored on the ledger
+	matches, err := t.performSanctionScreening(stub, entityName, entityData)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to perform sanction screening: %v", err))
+	}
 
 	// Determine screening result
 	isMatch := len(matches) > 0
@@ -1110,10 +1241,18 @@ func (t *ComplianceChaincode) ScreenAgainstSanctionLists(stub shim.ChaincodeStub
 	}
 
 	// Store screening result
-	err = shared.PutStateAsJSON(stub, "SCREENING_"+screeningID, result)
+	err = putStateAsJSON(stub, "SCREENING_"+screeningID, result)
 	if err != nil {
 		return shim.Error(fmt.Sprintf("Failed to store screening result: %v", err))
 	}
+	if err := services.IncrementCounter(stub, config.MetricSanctionScreenings); err != nil {
+		return shim.Error(fmt.Sprintf("Failed to count screening: %v", err))
+	}
+	if isMatch {
+		if err := services.IncrementCounter(stub, config.MetricSanctionMatches); err != nil {
+			return shim.Error(fmt.Sprintf("Failed to count screening match: %v", err))
+		}
+	}
 
 	// Create composite key for efficient querying by entity
 	entityCompositeKey, err := stub.CreateCompositeKey("SCREENING_BY_ENTITY", []string{entityID, screeningID})
@@ -1152,7 +1291,7 @@ func (t *ComplianceChaincode) ScreenAgainstSanctionLists(stub shim.ChaincodeStub
 		"timestamp":   now,
 	}
 	
-	err = shared.EmitEvent(stub, "SanctionScreeningCompleted", eventPayload)
+	err = emitEvent(stub, "SanctionScreeningCompleted", eventPayload)
 	if err != nil {
 		return shim.Error(fmt.Sprintf("Failed to emit event: %v", err))
 	}
@@ -1163,15 +1302,21 @@ func (t *ComplianceChaincode) ScreenAgainstSanctionLists(stub shim.ChaincodeStub
 		return shim.Error(fmt.Sprintf("Failed to marshal screening result: %v", err))
 	}
 
-	return shim.Success(resultJSON)
+	screeningResponse := shim.Success(resultJSON)
+	t.guard.record(stub, screeningKey, screeningResponse)
+
+	return screeningResponse
 }
 
 // performSanctionScreening performs the actual screening logic
-func (t *ComplianceChaincode) performSanctionScreening(stub shim.ChaincodeStubInterface, entityName string, entityData map[string]interface{}) []SanctionMatch {
+func (t *ComplianceChaincode) performSanctionScreening(stub shim.ChaincodeStubInterface, entityName string, entityData map[string]interface{}) ([]SanctionMatch, error) {
 	var matches []SanctionMatch
 
-	// Get hardcoded sanction list entries for demonstration
-	sanctionEntries := t.getHardcodedSanctionEntries()
+	// Load candidate entries sharing a name prefix with the screened entity
+	sanctionEntries, err := handlers.CandidateSanctionEntries(stub, entityName)
+	if err != nil {
+		return nil, err
+	}
 
 	for _, entry := range sanctionEntries {
 		if !entry.IsActive {
@@ -1239,53 +1384,7 @@ func (t *ComplianceChaincode) performSanctionScreening(stub shim.ChaincodeStubIn
 		}
 	}
 
-	return matches
-}
-
-// getHardcodedSanctionEntries returns hardcoded sanction list entries for demonstration
-func (t *ComplianceChaincode) getHardcodedSanctionEntries() []SanctionListEntry {
-	return []SanctionListEntry{
-		{
-			EntryID:      "SANCTION_001",
-			ListName:     "OFAC_SDN",
-			EntityName:   "John Doe Sanctioned",
-			EntityType:   "Individual",
-			Aliases:      []string{"J. Doe", "Johnny Doe"},
-			DateOfBirth:  "1980-01-01",
-			Nationality:  "Unknown",
-			SanctionType: "Financial",
-			ListingDate:  time.Now().AddDate(-1, 0, 0),
-			LastUpdated:  time.Now().AddDate(-1, 0, 0),
-			IsActive:     true,
-			Source:       "OFAC",
-		},
-		{
-			EntryID:      "SANCTION_002",
-			ListName:     "UN_SANCTIONS",
-			EntityName:   "Bad Company Ltd",
-			EntityType:   "Organization",
-			Aliases:      []string{"Bad Co", "BC Ltd"},
-			SanctionType: "Trade",
-			ListingDate:  time.Now().AddDate(-2, 0, 0),
-			LastUpdated:  time.Now().AddDate(-2, 0, 0),
-			IsActive:     true,
-			Source:       "UN",
-		},
-		{
-			EntryID:      "SANCTION_003",
-			ListName:     "EU_SANCTIONS",
-			EntityName:   "Jane Smith Criminal",
-			EntityType:   "Individual",
-			Aliases:      []string{"J. Smith", "Jane S."},
-			DateOfBirth:  "1975-05-15",
-			Nationality:  "Unknown",
-			SanctionType: "Asset_Freeze",
-			ListingDate:  time.Now().AddDate(-3, 0, 0),
-			LastUpdated:  time.Now().AddDate(-3, 0, 0),
-			IsActive:     true,
-			Source:       "EU",
-		},
-	}
+	return matches, nil
 }
 
 // normalizeString normalizes a string for comparison
@@ -1381,7 +1480,7 @@ func (t *ComplianceChaincode) GetScreeningResult(stub shim.ChaincodeStubInterfac
 
 	// Get screening result from ledger
 	var result SanctionScreeningResult
-	err := shared.GetStateAsJSON(stub, "SCREENING_"+screeningID, &result)
+	err := getStateAsJSON(stub, "SCREENING_"+screeningID, &result)
 	if err != nil {
 		return shim.Error(fmt.Sprintf("Failed to get screening result %s: %v", screeningID, err))
 	}
@@ -1422,7 +1521,7 @@ func (t *ComplianceChaincode) GetScreeningResultsByEntity(stub shim.ChaincodeStu
 
 		screeningID := string(response.Value)
 		var result SanctionScreeningResult
-		err = shared.GetStateAsJSON(stub, "SCREENING_"+screeningID, &result)
+		err = getStateAsJSON(stub, "SCREENING_"+screeningID, &result)
 		if err != nil {
 			return shim.Error(fmt.Sprintf("Failed to get screening result %s: %v", screeningID, err))
 		}
@@ -1439,6 +1538,298 @@ func (t *ComplianceChaincode) GetScreeningResultsByEntity(stub shim.ChaincodeStu
 	return shim.Success(resultsJSON)
 }
 
+// validScreeningReviewTransitions lists the dispositions allowed from each screening status
+var validScreeningReviewTransitions = map[string][]string{
+	"REQUIRES_REVIEW": {"CLEARED", "CONFIRMED_MATCH", "ESCALATED"},
+	"FLAGGED":         {"CLEARED", "CONFIRMED_MATCH", "ESCALATED"},
+	"ESCALATED":       {"CLEARED", "CONFIRMED_MATCH"},
+}
+
+// ReviewScreeningResult records a reviewer's disposition of a flagged screening result
+func (t *ComplianceChaincode) ReviewScreeningResult(stub shim.ChaincodeStubInterface, args []string) peer.Response {
+	if len(args) != 4 {
+		return shim.Error("Incorrect number of arguments. Expecting 4: screeningID, decision, reviewComments, actorID")
+	}
+
+	screeningID := args[0]
+	decision := args[1]
+	reviewComments := strings.TrimSpace(args[2])
+	actorID := args[3]
+
+	// Validate required fields
+	requiredFields := map[string]string{
+		"screeningID":    screeningID,
+		"decision":       decision,
+		"reviewComments": reviewComments,
+		"actorID":        actorID,
+	}
+
+	if err := validateRequired(requiredFields); err != nil {
+		return shim.Error(fmt.Sprintf("Validation failed: %v", err))
+	}
+
+	// Validate actor access
+	_, err := validateComplianceActor(stub, actorID)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Access denied: %v", err))
+	}
+
+	// Validate decision
+	allowedDecisions := []string{"CLEARED", "CONFIRMED_MATCH", "ESCALATED"}
+	if err := validation.ValidateStatus(decision, allowedDecisions); err != nil {
+		return shim.Error(fmt.Sprintf("Invalid decision: %v", err))
+	}
+
+	// Get screening result from ledger
+	var result SanctionScreeningResult
+	err = getStateAsJSON(stub, "SCREENING_"+screeningID, &result)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to get screening result %s: %v", screeningID, err))
+	}
+
+	// Validate status transition
+	previousStatus := result.Status
+	allowedNext, reviewable := validScreeningReviewTransitions[previousStatus]
+	if !reviewable {
+		return shim.Error(fmt.Sprintf("Screening result %s with status %s cannot be reviewed", screeningID, previousStatus))
+	}
+	if err := validation.ValidateStatus(decision, allowedNext); err != nil {
+		return shim.Error(fmt.Sprintf("Invalid status transition from %s to %s", previousStatus, decision))
+	}
+
+	// Apply review
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to get transaction time: %v", err))
+	}
+	result.Status = decision
+	result.ReviewedBy = actorID
+	result.ReviewDate = now
+	result.ReviewComments = reviewComments
+
+	err = putStateAsJSON(stub, "SCREENING_"+screeningID, result)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to store screening result: %v", err))
+	}
+
+	// Record history
+	err = recordHistoryEntry(stub, screeningID, "SanctionScreeningResult", "REVIEW", "status", previousStatus, decision, actorID)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to record history: %v", err))
+	}
+
+	// Record compliance event for the disposition
+	eventType := "ACKNOWLEDGMENT"
+	switch decision {
+	case "CONFIRMED_MATCH":
+		eventType = "VIOLATION"
+	case "ESCALATED":
+		eventType = "ALERT"
+	}
+
+	details := fmt.Sprintf("Sanction screening %s reviewed: %s -> %s. Comments: %s", screeningID, previousStatus, decision, reviewComments)
+	err = t.recordAutomatedComplianceEvent(stub, "SANCTION_SCREENING", result.EntityID, result.EntityType, eventType, details, actorID)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to record compliance event: %v", err))
+	}
+
+	// Emit event
+	eventPayload := map[string]interface{}{
+		"screeningID":    screeningID,
+		"entityID":       result.EntityID,
+		"previousStatus": previousStatus,
+		"status":         decision,
+		"reviewedBy":     actorID,
+		"timestamp":      now,
+	}
+
+	err = emitEvent(stub, "SanctionScreeningReviewed", eventPayload)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to emit event: %v", err))
+	}
+
+	// Marshal result to JSON for response
+	resultJSON, err := json.Marshal(result)
+	if err != nil {
+		return shim.Error(fmt.Sprintf("Failed to marshal screening result: %v", err))
+	}
+
+	return shim.Success(resultJSON)
+}
+
+// ============================================================================
+// PER-TRANSACTION GUARDS
+// ============================================================================
+
+// maxGuardedTransactions bounds how many invocations the guard remembers. Entries are only
+// needed while an invocation runs, so the oldest are dropped first.
+const maxGuardedTransactions = 1000
+
+// transactionGuard remembers the validations, screenings and compliance events each
+// invocation has already performed. When the same entity is validated or screened again in
+// one invocation, for example by ValidateComplianceRules repeating an earlier
+// ValidateLoanApplication, the first result is returned rather than emitting events and
+// writing composite keys a second time, which would otherwise conflict at commit. Fabric does
+// not expose a transaction's own writes to GetState, so the guard is held in memory. Entries are
+// scoped to the stub as well as the transaction ID, so a proposal the peer is asked to endorse
+// again is evaluated afresh against current state rather than answered from an earlier
+// simulation. The zero value is ready to use.
+type transactionGuard struct {
+	mutex   sync.Mutex
+	entries map[guardInvocation]map[string]peer.Response
+	order   []guardInvocation
+}
+
+// guardInvocation identifies one execution of the chaincode. The stub distinguishes a proposal
+// endorsed twice, the transaction ID distinguishes transactions on a reused mock stub.
+type guardInvocation struct {
+	stub shim.ChaincodeStubInterface
+	txID string
+}
+
+// guardInvocationOf identifies the invocation a stub belongs to by the peer's stub underneath the
+// services.Invocation wrapper, which is created afresh for every call to Invoke
+func guardInvocationOf(stub shim.ChaincodeStubInterface) guardInvocation {
+	if invocation, ok := stub.(*services.Invocation); ok {
+		stub = invocation.Stub()
+	}
+	return guardInvocation{stub: stub, txID: stub.GetTxID()}
+}
+
+// lookup returns the response recorded under a key by the invocation
+func (g *transactionGuard) lookup(stub shim.ChaincodeStubInterface, key string) (peer.Response, bool) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	response, ok := g.entries[guardInvocationOf(stub)][key]
+	return response, ok
+}
+
+// record stores the response for a key in the invocation, forgetting the oldest invocation
+// once maxGuardedTransactions are held
+func (g *transactionGuard) record(stub shim.ChaincodeStubInterface, key string, response peer.Response) {
+	g.mutex.Lock()
+	defer g.mutex.Unlock()
+
+	invocation := guardInvocationOf(stub)
+	if g.entries == nil {
+		g.entries = make(map[guardInvocation]map[string]peer.Response)
+	}
+	if _, exists := g.entries[invocation]; !exists {
+		if len(g.order) >= maxGuardedTransactions {
+			delete(g.entries, g.order[0])
+			g.order = g.order[1:]
+		}
+		g.entries[invocation] = make(map[string]peer.Response)
+		g.order = append(g.order, invocation)
+	}
+	g.entries[invocation][key] = response
+}
+
+// guardKey identifies an operation on an entity for the transaction guard
+func guardKey(operation string, parts ...string) string {
+	hash := sha256.Sum256([]byte(strings.Join(parts, "\x00")))
+	return operation + "_" + hex.EncodeToString(hash[:])
+}
+
+// complianceManagerRoles are the actor roles the chaincode lets maintain compliance rules,
+// sanction list entries and screening reviews
+var complianceManagerRoles = map[validation.ActorRole]bool{
+	validation.ActorRoleComplianceOfficer:      true,
+	validation.ActorRoleChiefComplianceOfficer: true,
+}
+
+// LegacyHistoryEntry records a change to a compliance rule or screening result
+type LegacyHistoryEntry struct {
+	EntityID      string    `json:"entityID"`
+	EntityType    string    `json:"entityType"`
+	ChangeType    string    `json:"changeType"`
+	FieldName     string    `json:"fieldName"`
+	PreviousValue string    `json:"previousValue"`
+	NewValue      string    `json:"newValue"`
+	ActorID       string    `json:"actorID"`
+	Timestamp     time.Time `json:"timestamp"`
+	TransactionID string    `json:"transactionID"`
+}
+
+// getStateAsJSON reads the JSON record at a key
+func getStateAsJSON(stub shim.ChaincodeStubInterface, key string, result interface{}) error {
+	return services.NewPersistenceService().Get(stub, key, result)
+}
+
+// putStateAsJSON writes a record as JSON at a key
+func putStateAsJSON(stub shim.ChaincodeStubInterface, key string, value interface{}) error {
+	return services.NewPersistenceService().Put(stub, key, value)
+}
+
+// validateRequired rejects the first empty field, in field name order
+func validateRequired(fields map[string]string) error {
+	names := make([]string, 0, len(fields))
+	for name := range fields {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		if strings.TrimSpace(fields[name]) == "" {
+			return services.NewChaincodeError(services.ErrCodeInvalidArgument, name, "%s is required", name)
+		}
+	}
+	return nil
+}
+
+// validateComplianceActor returns the registration of an active actor whose role may maintain
+// compliance records
+func validateComplianceActor(stub shim.ChaincodeStubInterface, actorID string) (*services.ActorIdentity, error) {
+	actor, err := services.NewIdentityService().GetActor(stub, actorID)
+	if err != nil {
+		return nil, err
+	}
+	if !actor.Active {
+		return nil, services.NewChaincodeError(services.ErrCodeAccessDenied, "", "actor %s is inactive", actorID)
+	}
+	if !complianceManagerRoles[validation.ActorRole(actor.Role)] {
+		return nil, services.NewChaincodeError(services.ErrCodeAccessDenied, "", "actor %s with role %s may not maintain compliance records", actorID, actor.Role)
+	}
+	return actor, nil
+}
+
+// recordHistoryEntry appends a change to an entity's history, keyed by the transaction
+func recordHistoryEntry(stub shim.ChaincodeStubInterface, entityID, entityType, changeType, fieldName, previousValue, newValue, actorID string) error {
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return err
+	}
+	entry := LegacyHistoryEntry{
+		EntityID:      entityID,
+		EntityType:    entityType,
+		ChangeType:    changeType,
+		FieldName:     fieldName,
+		PreviousValue: previousValue,
+		NewValue:      newValue,
+		ActorID:       actorID,
+		Timestamp:     now,
+		TransactionID: stub.GetTxID(),
+	}
+	historyKey, err := stub.CreateCompositeKey("HISTORY", []string{entityType, entityID, stub.GetTxID()})
+	if err != nil {
+		return fmt.Errorf("failed to create history key: %w", err)
+	}
+	return putStateAsJSON(stub, historyKey, entry)
+}
+
+// emitEvent sets the transaction's chaincode event. A transaction carries one event, so a later
+// call replaces an earlier one.
+func emitEvent(stub shim.ChaincodeStubInterface, eventName string, payload interface{}) error {
+	payloadBytes, err := json.Marshal(payload)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event payload: %w", err)
+	}
+	if err := stub.SetEvent(eventName, payloadBytes); err != nil {
+		return fmt.Errorf("failed to emit event %s: %w", eventName, err)
+	}
+	return nil
+}
+
 func main() {
 	if err := shim.Start(new(ComplianceChaincode)); err != nil {
 		log.Fatalf("Error starting Compliance chaincode: %v", err)
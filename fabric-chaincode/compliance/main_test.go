@@ -1,19 +1,68 @@
 package main
 
 import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
 	"encoding/json"
+	"encoding/pem"
+	"math/big"
 	"testing"
 	"time"
 
+	"github.com/golang/protobuf/proto"
 	"github.com/hyperledger/fabric-chaincode-go/shim"
 	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric-protos-go/peer"
 	"github.com/stretchr/testify/assert"
-	"github.com/blockchain-financial-platform/fabric-chaincode/shared"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
 )
 
+// testActorIDs are the actors the tests act as, all bound to the stub's creator identity
+var testActorIDs = []string{"test-actor-1", "system"}
+
+// bindTestActors gives the stub a creator identity and registers every test actor to it
+func bindTestActors(t *testing.T, stub *shimtest.MockStub) {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: "compliance-officer"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	stub.Creator, err = proto.Marshal(&msp.SerializedIdentity{
+		Mspid:   "Org1MSP",
+		IdBytes: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}),
+	})
+	require.NoError(t, err)
+
+	stub.MockTransactionStart("bind-actors")
+	defer stub.MockTransactionEnd("bind-actors")
+	invokerID, err := services.InvokerID(stub)
+	require.NoError(t, err)
+	identityService := services.NewIdentityService()
+	for _, actorID := range testActorIDs {
+		require.NoError(t, identityService.PutActor(stub, &services.ActorIdentity{
+			ActorID:            actorID,
+			BlockchainIdentity: invokerID,
+			MSPID:              "Org1MSP",
+			Role:               "Compliance_Officer",
+			Active:             true,
+		}))
+	}
+}
+
 func TestComplianceChaincode_Init(t *testing.T) {
 	cc := new(ComplianceChaincode)
 	stub := shimtest.NewMockStub("compliance", cc)
+	bindTestActors(t, stub)
 
 	// Test successful initialization
 	response := stub.MockInit("1", [][]byte{})
@@ -23,6 +72,7 @@ func TestComplianceChaincode_Init(t *testing.T) {
 func TestComplianceChaincode_Ping(t *testing.T) {
 	cc := new(ComplianceChaincode)
 	stub := shimtest.NewMockStub("compliance", cc)
+	bindTestActors(t, stub)
 
 	// Initialize chaincode
 	stub.MockInit("1", [][]byte{})
@@ -36,26 +86,9 @@ func TestComplianceChaincode_Ping(t *testing.T) {
 func TestComplianceChaincode_UpdateComplianceRule(t *testing.T) {
 	cc := new(ComplianceChaincode)
 	stub := shimtest.NewMockStub("compliance", cc)
+	bindTestActors(t, stub)
 	stub.MockInit("1", [][]byte{})
 
-	// Create a test actor first
-	testActor := shared.Actor{
-		ActorID:           "test-actor-1",
-		ActorType:         shared.ActorTypeInternalUser,
-		ActorName:         "Test Compliance Officer",
-		Role:              shared.RoleComplianceOfficer,
-		BlockchainIdentity: "test-identity",
-		Permissions:       []shared.Permission{shared.PermissionUpdateCompliance},
-		IsActive:          true,
-		CreatedDate:       time.Now(),
-		LastUpdated:       time.Now(),
-	}
-
-	actorJSON, _ := json.Marshal(testActor)
-	stub.MockTransactionStart("create-actor")
-	stub.PutState("ACTOR_test-actor-1", actorJSON)
-	stub.MockTransactionEnd("create-actor")
-
 	t.Run("Create new compliance rule", func(t *testing.T) {
 		args := [][]byte{
 			[]byte("UpdateComplianceRule"),
@@ -148,26 +181,9 @@ func TestComplianceChaincode_UpdateComplianceRule(t *testing.T) {
 func TestComplianceChaincode_GetComplianceRule(t *testing.T) {
 	cc := new(ComplianceChaincode)
 	stub := shimtest.NewMockStub("compliance", cc)
+	bindTestActors(t, stub)
 	stub.MockInit("1", [][]byte{})
 
-	// Create a test actor and rule first
-	testActor := shared.Actor{
-		ActorID:           "test-actor-1",
-		ActorType:         shared.ActorTypeInternalUser,
-		ActorName:         "Test Compliance Officer",
-		Role:              shared.RoleComplianceOfficer,
-		BlockchainIdentity: "test-identity",
-		Permissions:       []shared.Permission{shared.PermissionUpdateCompliance},
-		IsActive:          true,
-		CreatedDate:       time.Now(),
-		LastUpdated:       time.Now(),
-	}
-
-	actorJSON, _ := json.Marshal(testActor)
-	stub.MockTransactionStart("create-actor")
-	stub.PutState("ACTOR_test-actor-1", actorJSON)
-	stub.MockTransactionEnd("create-actor")
-
 	// Create a rule
 	createArgs := [][]byte{
 		[]byte("UpdateComplianceRule"),
@@ -220,26 +236,9 @@ func TestComplianceChaincode_GetComplianceRule(t *testing.T) {
 func TestComplianceChaincode_RecordComplianceEvent(t *testing.T) {
 	cc := new(ComplianceChaincode)
 	stub := shimtest.NewMockStub("compliance", cc)
+	bindTestActors(t, stub)
 	stub.MockInit("1", [][]byte{})
 
-	// Create a test actor and rule first
-	testActor := shared.Actor{
-		ActorID:           "test-actor-1",
-		ActorType:         shared.ActorTypeInternalUser,
-		ActorName:         "Test Compliance Officer",
-		Role:              shared.RoleComplianceOfficer,
-		BlockchainIdentity: "test-identity",
-		Permissions:       []shared.Permission{shared.PermissionUpdateCompliance},
-		IsActive:          true,
-		CreatedDate:       time.Now(),
-		LastUpdated:       time.Now(),
-	}
-
-	actorJSON, _ := json.Marshal(testActor)
-	stub.MockTransactionStart("create-actor")
-	stub.PutState("ACTOR_test-actor-1", actorJSON)
-	stub.MockTransactionEnd("create-actor")
-
 	// Create a rule
 	createRuleArgs := [][]byte{
 		[]byte("UpdateComplianceRule"),
@@ -332,6 +331,7 @@ func TestComplianceChaincode_RecordComplianceEvent(t *testing.T) {
 func TestComplianceChaincode_GetComplianceEvent(t *testing.T) {
 	cc := new(ComplianceChaincode)
 	stub := shimtest.NewMockStub("compliance", cc)
+	bindTestActors(t, stub)
 	stub.MockInit("1", [][]byte{})
 
 	// Setup test data
@@ -383,6 +383,7 @@ func TestComplianceChaincode_GetComplianceEvent(t *testing.T) {
 func TestComplianceChaincode_GetComplianceEventsByRule(t *testing.T) {
 	cc := new(ComplianceChaincode)
 	stub := shimtest.NewMockStub("compliance", cc)
+	bindTestActors(t, stub)
 	stub.MockInit("1", [][]byte{})
 
 	// Setup test data
@@ -450,6 +451,7 @@ func TestComplianceChaincode_GetComplianceEventsByRule(t *testing.T) {
 func TestComplianceChaincode_GetComplianceEventsByEntity(t *testing.T) {
 	cc := new(ComplianceChaincode)
 	stub := shimtest.NewMockStub("compliance", cc)
+	bindTestActors(t, stub)
 	stub.MockInit("1", [][]byte{})
 
 	// Setup test data
@@ -517,16 +519,56 @@ func TestComplianceChaincode_GetComplianceEventsByEntity(t *testing.T) {
 func TestComplianceChaincode_InvalidFunction(t *testing.T) {
 	cc := new(ComplianceChaincode)
 	stub := shimtest.NewMockStub("compliance", cc)
+	bindTestActors(t, stub)
 	stub.MockInit("1", [][]byte{})
 
 	response := stub.MockInvoke("1", [][]byte{[]byte("InvalidFunction")})
 	assert.Equal(t, int32(shim.ERROR), response.Status, "Invalid function should return error")
-	assert.Contains(t, response.Message, "Invalid function name", "Error message should mention invalid function")
+	var envelope services.ChaincodeError
+	require.NoError(t, json.Unmarshal([]byte(response.Message), &envelope), response.Message)
+	assert.Equal(t, services.ErrCodeUnknownFunction, envelope.Code, "Invalid function should carry the unknown function code")
+	assert.Equal(t, "function InvalidFunction not found", envelope.Message)
+}
+
+func TestComplianceChaincode_DisabledFunction(t *testing.T) {
+	cc := new(ComplianceChaincode)
+	stub := shimtest.NewMockStub("compliance", cc)
+	bindTestActors(t, stub)
+	stub.MockInit("1", [][]byte{})
+
+	// Operators switch screening off through the shared kill switch
+	stub.MockTransactionStart("disable")
+	require.NoError(t, services.NewFunctionFlagService().PutFunctionFlag(stub, &services.FunctionFlag{
+		FunctionName:    "ScreenAgainstSanctionLists",
+		Disabled:        true,
+		OperatorMessage: "Screening paused during list reload",
+		LastUpdatedBy:   "system",
+	}))
+	stub.MockTransactionEnd("disable")
+
+	response := stub.MockInvoke("2", [][]byte{
+		[]byte("ScreenAgainstSanctionLists"),
+		[]byte("CUST_001"),
+		[]byte("Customer"),
+		[]byte(`{"name":"John Doe"}`),
+		[]byte("test-actor-1"),
+	})
+	assert.Equal(t, int32(shim.ERROR), response.Status, "Disabled function should be rejected")
+	var disabledErr map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(response.Message), &disabledErr), response.Message)
+	assert.Equal(t, services.ErrorCodeServiceDisabled, disabledErr["code"])
+	assert.Equal(t, "function ScreenAgainstSanctionLists is disabled", disabledErr["message"])
+	assert.Equal(t, "Screening paused during list reload", disabledErr["operatorMessage"])
+
+	// Other functions keep running
+	response = stub.MockInvoke("3", [][]byte{[]byte("GetHardcodedRules")})
+	assert.Equal(t, int32(shim.OK), response.Status, response.Message)
 }
 
 func TestComplianceChaincode_GetHardcodedRules(t *testing.T) {
 	cc := new(ComplianceChaincode)
 	stub := shimtest.NewMockStub("compliance", cc)
+	bindTestActors(t, stub)
 	stub.MockInit("1", [][]byte{})
 
 	t.Run("Get all hardcoded rules", func(t *testing.T) {
@@ -555,6 +597,7 @@ func TestComplianceChaincode_GetHardcodedRules(t *testing.T) {
 func TestComplianceChaincode_ValidateLoanApplication(t *testing.T) {
 	cc := new(ComplianceChaincode)
 	stub := shimtest.NewMockStub("compliance", cc)
+	bindTestActors(t, stub)
 	stub.MockInit("1", [][]byte{})
 
 	t.Run("Validate compliant loan application", func(t *testing.T) {
@@ -636,6 +679,7 @@ func TestComplianceChaincode_ValidateLoanApplication(t *testing.T) {
 func TestComplianceChaincode_ValidateCustomer(t *testing.T) {
 	cc := new(ComplianceChaincode)
 	stub := shimtest.NewMockStub("compliance", cc)
+	bindTestActors(t, stub)
 	stub.MockInit("1", [][]byte{})
 
 	t.Run("Validate compliant customer", func(t *testing.T) {
@@ -714,6 +758,7 @@ func TestComplianceChaincode_ValidateCustomer(t *testing.T) {
 func TestComplianceChaincode_ValidateComplianceRules(t *testing.T) {
 	cc := new(ComplianceChaincode)
 	stub := shimtest.NewMockStub("compliance", cc)
+	bindTestActors(t, stub)
 	stub.MockInit("1", [][]byte{})
 
 	t.Run("Validate loan status transition - valid", func(t *testing.T) {
@@ -782,6 +827,7 @@ func TestComplianceChaincode_ValidateComplianceRules(t *testing.T) {
 func TestComplianceChaincode_AddSanctionListEntry(t *testing.T) {
 	cc := new(ComplianceChaincode)
 	stub := shimtest.NewMockStub("compliance", cc)
+	bindTestActors(t, stub)
 	stub.MockInit("1", [][]byte{})
 
 	// Setup test actor
@@ -832,6 +878,7 @@ func TestComplianceChaincode_AddSanctionListEntry(t *testing.T) {
 func TestComplianceChaincode_GetSanctionListEntry(t *testing.T) {
 	cc := new(ComplianceChaincode)
 	stub := shimtest.NewMockStub("compliance", cc)
+	bindTestActors(t, stub)
 	stub.MockInit("1", [][]byte{})
 
 	// Setup test data
@@ -886,8 +933,13 @@ func TestComplianceChaincode_GetSanctionListEntry(t *testing.T) {
 func TestComplianceChaincode_ScreenAgainstSanctionLists(t *testing.T) {
 	cc := new(ComplianceChaincode)
 	stub := shimtest.NewMockStub("compliance", cc)
+	bindTestActors(t, stub)
 	stub.MockInit("1", [][]byte{})
 
+	// Setup test actor and sanction list entries
+	setupTestData(t, stub)
+	setupSanctionEntries(t, stub)
+
 	t.Run("Screen entity with no matches", func(t *testing.T) {
 		entityData := map[string]interface{}{
 			"name":        "Clean Customer",
@@ -1066,9 +1118,148 @@ func TestComplianceChaincode_ScreenAgainstSanctionLists(t *testing.T) {
 	})
 }
 
+func TestComplianceChaincode_ScreenAgainstImportedSanctionEntries(t *testing.T) {
+	cc := new(ComplianceChaincode)
+	stub := shimtest.NewMockStub("compliance", cc)
+	bindTestActors(t, stub)
+	stub.MockInit("1", [][]byte{})
+
+	setupTestData(t, stub)
+
+	screen := func(txID, entityID, name string) SanctionScreeningResult {
+		entityDataJSON, _ := json.Marshal(map[string]interface{}{"name": name})
+		response := stub.MockInvoke(txID, [][]byte{
+			[]byte("ScreenAgainstSanctionLists"),
+			[]byte(entityID),
+			[]byte("Customer"),
+			entityDataJSON,
+			[]byte("system"),
+		})
+		require.Equal(t, int32(shim.OK), response.Status, response.Message)
+
+		var result SanctionScreeningResult
+		require.NoError(t, json.Unmarshal(response.Payload, &result))
+		return result
+	}
+
+	t.Run("Unlisted name is cleared before import", func(t *testing.T) {
+		result := screen("10", "CUSTOMER_100", "Ivan Importedname")
+		assert.False(t, result.IsMatch)
+		assert.Equal(t, "CLEARED", result.Status)
+	})
+
+	t.Run("Imported entry is matched", func(t *testing.T) {
+		addSanctionEntry(t, stub, "11", "SANCTION_IMPORT_001", "OFAC_SDN", "Ivan Importedname", []string{"Vanya I."}, "")
+
+		result := screen("12", "CUSTOMER_101", "Ivan Importedname")
+		assert.True(t, result.IsMatch)
+		assert.Equal(t, "FLAGGED", result.Status)
+		require.Len(t, result.Matches, 1)
+		assert.Equal(t, "SANCTION_IMPORT_001", result.Matches[0].EntryID)
+	})
+
+	t.Run("Removed alias no longer matches", func(t *testing.T) {
+		result := screen("13", "CUSTOMER_102", "Vanya I.")
+		assert.True(t, result.IsMatch, "Alias should match before the entry is updated")
+
+		addSanctionEntry(t, stub, "14", "SANCTION_IMPORT_001", "OFAC_SDN", "Ivan Importedname", []string{}, "")
+
+		result = screen("15", "CUSTOMER_102", "Vanya I.")
+		assert.False(t, result.IsMatch, "Alias removed by update should not match")
+	})
+
+	t.Run("Non-ASCII name is indexed and matched", func(t *testing.T) {
+		addSanctionEntry(t, stub, "16", "SANCTION_IMPORT_002", "OFAC_SDN", "Дмитрий Ёлкин", []string{"Ólafur Þórsson"}, "")
+
+		result := screen("17", "CUSTOMER_103", "дмитрий ёлкин")
+		assert.True(t, result.IsMatch)
+		require.Len(t, result.Matches, 1)
+		assert.Equal(t, "SANCTION_IMPORT_002", result.Matches[0].EntryID)
+
+		result = screen("18", "CUSTOMER_104", "Ólafur Þórsson")
+		assert.True(t, result.IsMatch, "Non-ASCII alias should match")
+	})
+}
+
+func TestComplianceChaincode_ScreenAgainstManagedSanctionList(t *testing.T) {
+	cc := new(ComplianceChaincode)
+	stub := shimtest.NewMockStub("compliance", cc)
+	bindTestActors(t, stub)
+	stub.MockInit("1", [][]byte{})
+
+	invoke := func(txID string, args ...string) peer.Response {
+		argBytes := [][]byte{}
+		for _, arg := range args {
+			argBytes = append(argBytes, []byte(arg))
+		}
+		return stub.MockInvoke(txID, argBytes)
+	}
+	screen := func(txID, entityID, name string) SanctionScreeningResult {
+		entityDataJSON, _ := json.Marshal(map[string]interface{}{"name": name})
+		response := invoke(txID, "ScreenAgainstSanctionLists", entityID, "Customer", string(entityDataJSON), "system")
+		require.Equal(t, int32(shim.OK), response.Status, response.Message)
+
+		var result SanctionScreeningResult
+		require.NoError(t, json.Unmarshal(response.Payload, &result))
+		return result
+	}
+
+	listJSON, _ := json.Marshal(map[string]interface{}{
+		"listID":       "OFAC_SDN",
+		"listName":     "OFAC SDN",
+		"source":       "US Treasury OFAC",
+		"listType":     "SDN",
+		"jurisdiction": "US",
+		"isActive":     true,
+		"createdBy":    "system",
+	})
+	response := invoke("2", "CreateSanctionList", string(listJSON))
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+
+	assert.False(t, screen("3", "CUSTOMER_200", "Viktor Petrov").IsMatch)
+
+	updateJSON, _ := json.Marshal(map[string]interface{}{
+		"listID":     "OFAC_SDN",
+		"updateType": "FULL_REPLACE",
+		"format":     "OFAC_SDN_XML",
+		"document": `<sdnList><sdnEntry><uid>7157</uid><firstName>Viktor</firstName><lastName>PETROV</lastName><sdnType>Individual</sdnType>` +
+			`<akaList><aka><uid>201</uid><type>a.k.a.</type><category>strong</category><lastName>PETROFF</lastName><firstName>Victor</firstName></aka></akaList></sdnEntry></sdnList>`,
+		"version":   "2026-06-01",
+		"updatedBy": "system",
+	})
+	response = invoke("4", "UpdateSanctionList", string(updateJSON))
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+
+	result := screen("5", "CUSTOMER_201", "Viktor Petrov")
+	assert.True(t, result.IsMatch)
+	assert.Equal(t, "FLAGGED", result.Status)
+	require.Len(t, result.Matches, 1)
+	assert.Equal(t, "OFAC_SDN_7157", result.Matches[0].EntryID)
+	assert.Equal(t, "OFAC SDN", result.Matches[0].ListName)
+	assert.Equal(t, "EXACT", result.Matches[0].MatchType)
+
+	result = screen("6", "CUSTOMER_202", "Victor Petroff")
+	assert.True(t, result.IsMatch, "Imported alias should match")
+
+	// Entries dropped by a full replace are no longer screened
+	updateJSON, _ = json.Marshal(map[string]interface{}{
+		"listID":     "OFAC_SDN",
+		"updateType": "FULL_REPLACE",
+		"format":     "OFAC_SDN_XML",
+		"document":   `<sdnList><sdnEntry><uid>9001</uid><lastName>PETROV HOLDINGS LIMITED</lastName><sdnType>Entity</sdnType></sdnEntry></sdnList>`,
+		"version":    "2026-06-02",
+		"updatedBy":  "system",
+	})
+	response = invoke("7", "UpdateSanctionList", string(updateJSON))
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+
+	assert.False(t, screen("8", "CUSTOMER_203", "Viktor Petrov").IsMatch)
+}
+
 func TestComplianceChaincode_GetScreeningResult(t *testing.T) {
 	cc := new(ComplianceChaincode)
 	stub := shimtest.NewMockStub("compliance", cc)
+	bindTestActors(t, stub)
 	stub.MockInit("1", [][]byte{})
 
 	// Perform a screening first
@@ -1120,6 +1311,7 @@ func TestComplianceChaincode_GetScreeningResult(t *testing.T) {
 func TestComplianceChaincode_GetScreeningResultsByEntity(t *testing.T) {
 	cc := new(ComplianceChaincode)
 	stub := shimtest.NewMockStub("compliance", cc)
+	bindTestActors(t, stub)
 	stub.MockInit("1", [][]byte{})
 
 	// Perform multiple screenings for the same entity
@@ -1184,26 +1376,219 @@ func TestComplianceChaincode_GetScreeningResultsByEntity(t *testing.T) {
 	})
 }
 
-// Helper function to setup test data
-func setupTestData(t *testing.T, stub *shimtest.MockStub) {
-	// Create a test actor
-	testActor := shared.Actor{
-		ActorID:           "test-actor-1",
-		ActorType:         shared.ActorTypeInternalUser,
-		ActorName:         "Test Compliance Officer",
-		Role:              shared.RoleComplianceOfficer,
-		BlockchainIdentity: "test-identity",
-		Permissions:       []shared.Permission{shared.PermissionUpdateCompliance},
-		IsActive:          true,
-		CreatedDate:       time.Now(),
-		LastUpdated:       time.Now(),
+func TestComplianceChaincode_ReviewScreeningResult(t *testing.T) {
+	cc := new(ComplianceChaincode)
+	stub := shimtest.NewMockStub("compliance", cc)
+	bindTestActors(t, stub)
+	stub.MockInit("1", [][]byte{})
+
+	setupTestData(t, stub)
+	setupSanctionEntries(t, stub)
+
+	screen := func(txID, entityID, name string) SanctionScreeningResult {
+		entityDataJSON, _ := json.Marshal(map[string]interface{}{"name": name})
+		response := stub.MockInvoke(txID, [][]byte{
+			[]byte("ScreenAgainstSanctionLists"),
+			[]byte(entityID),
+			[]byte("Customer"),
+			entityDataJSON,
+			[]byte("system"),
+		})
+		require.Equal(t, int32(shim.OK), response.Status, response.Message)
+
+		var result SanctionScreeningResult
+		require.NoError(t, json.Unmarshal(response.Payload, &result))
+		return result
 	}
 
-	actorJSON, _ := json.Marshal(testActor)
-	stub.MockTransactionStart("create-actor")
-	stub.PutState("ACTOR_test-actor-1", actorJSON)
-	stub.MockTransactionEnd("create-actor")
+	review := func(txID, screeningID, decision, comments, actorID string) peer.Response {
+		return stub.MockInvoke(txID, [][]byte{
+			[]byte("ReviewScreeningResult"),
+			[]byte(screeningID),
+			[]byte(decision),
+			[]byte(comments),
+			[]byte(actorID),
+		})
+	}
+
+	partial := screen("2", "CUSTOMER_001", "John Doe Sanction")
+	require.Equal(t, "REQUIRES_REVIEW", partial.Status)
+
+	t.Run("Fail without review comments", func(t *testing.T) {
+		response := review("3", partial.ScreeningID, "CLEARED", "  ", "test-actor-1")
+		assert.Equal(t, int32(shim.ERROR), response.Status, "Review should require comments")
+	})
+
+	t.Run("Fail with unauthorized actor", func(t *testing.T) {
+		response := review("4", partial.ScreeningID, "CLEARED", "False positive", "unknown-actor")
+		assert.Equal(t, int32(shim.ERROR), response.Status, "Review should fail for unknown actor")
+	})
+
+	t.Run("Fail with invalid decision", func(t *testing.T) {
+		response := review("5", partial.ScreeningID, "REQUIRES_REVIEW", "Not a disposition", "test-actor-1")
+		assert.Equal(t, int32(shim.ERROR), response.Status, "Review should reject invalid decision")
+	})
+
+	t.Run("Escalate then confirm match", func(t *testing.T) {
+		response := review("6", partial.ScreeningID, "ESCALATED", "Date of birth needs verification", "test-actor-1")
+		require.Equal(t, int32(shim.OK), response.Status, response.Message)
 
+		var result SanctionScreeningResult
+		require.NoError(t, json.Unmarshal(response.Payload, &result))
+		assert.Equal(t, "ESCALATED", result.Status)
+
+		response = review("7", partial.ScreeningID, "CONFIRMED_MATCH", "Date of birth matches listed entry", "test-actor-1")
+		require.Equal(t, int32(shim.OK), response.Status, response.Message)
+
+		require.NoError(t, json.Unmarshal(response.Payload, &result))
+		assert.Equal(t, "CONFIRMED_MATCH", result.Status)
+		assert.Equal(t, "test-actor-1", result.ReviewedBy)
+		assert.Equal(t, "Date of birth matches listed entry", result.ReviewComments)
+		assert.False(t, result.ReviewDate.IsZero())
+	})
+
+	t.Run("Fail to review a dispositioned result", func(t *testing.T) {
+		response := review("8", partial.ScreeningID, "CLEARED", "Reopening", "test-actor-1")
+		assert.Equal(t, int32(shim.ERROR), response.Status, "Confirmed match should not be reviewable")
+	})
+
+	t.Run("Clear a flagged result", func(t *testing.T) {
+		flagged := screen("9", "CUSTOMER_002", "John Doe Sanctioned")
+		require.Equal(t, "FLAGGED", flagged.Status)
+
+		response := review("10", flagged.ScreeningID, "CLEARED", "Different individual, verified passport", "test-actor-1")
+		require.Equal(t, int32(shim.OK), response.Status, response.Message)
+
+		getResponse := stub.MockInvoke("11", [][]byte{
+			[]byte("GetScreeningResult"),
+			[]byte(flagged.ScreeningID),
+		})
+		require.Equal(t, int32(shim.OK), getResponse.Status)
+
+		var stored SanctionScreeningResult
+		require.NoError(t, json.Unmarshal(getResponse.Payload, &stored))
+		assert.Equal(t, "CLEARED", stored.Status)
+		assert.Equal(t, "test-actor-1", stored.ReviewedBy)
+	})
+
+	t.Run("Fail to review a cleared screening", func(t *testing.T) {
+		clean := screen("12", "CUSTOMER_003", "Completely Unrelated Person")
+		require.Equal(t, "CLEARED", clean.Status)
+
+		response := review("13", clean.ScreeningID, "CONFIRMED_MATCH", "Should not apply", "test-actor-1")
+		assert.Equal(t, int32(shim.ERROR), response.Status, "Cleared screening should not be reviewable")
+	})
+}
+
+func TestComplianceChaincode_DuplicateChecksWithinTransaction(t *testing.T) {
+	cc := new(ComplianceChaincode)
+	stub := shimtest.NewMockStub("compliance", cc)
+	bindTestActors(t, stub)
+	stub.MockInit("1", [][]byte{})
+
+	setupTestData(t, stub)
+	setupSanctionEntries(t, stub)
+
+	countKeys := func(objectType, entityID string) int {
+		stub.MockTransactionStart("count")
+		defer stub.MockTransactionEnd("count")
+		iterator, err := stub.GetStateByPartialCompositeKey(objectType, []string{entityID})
+		require.NoError(t, err)
+		defer iterator.Close()
+		count := 0
+		for iterator.HasNext() {
+			_, err := iterator.Next()
+			require.NoError(t, err)
+			count++
+		}
+		return count
+	}
+
+	t.Run("Loan validated once when ValidateComplianceRules repeats ValidateLoanApplication", func(t *testing.T) {
+		loanDataJSON := `{"requestedAmount": 50000.0, "loanType": "Personal", "currentStatus": "Submitted"}`
+
+		first := stub.MockInvoke("3", [][]byte{
+			[]byte("ValidateLoanApplication"),
+			[]byte("LOAN_200"),
+			[]byte(loanDataJSON),
+		})
+		require.Equal(t, int32(shim.OK), first.Status)
+		recorded := countKeys("EVENT_BY_ENTITY", "LOAN_200")
+		require.Greater(t, recorded, 0)
+
+		// Same entity and data in the same transaction, with the keys in a different order
+		second := stub.MockInvoke("3", [][]byte{
+			[]byte("ValidateComplianceRules"),
+			[]byte("Loan"),
+			[]byte("LOAN_200"),
+			[]byte("LoanApplication"),
+			[]byte(`{"currentStatus":"Submitted","loanType":"Personal","requestedAmount":50000}`),
+		})
+		require.Equal(t, int32(shim.OK), second.Status)
+		assert.Equal(t, first.Payload, second.Payload, "Repeat validation should return the first result")
+		assert.Equal(t, recorded, countKeys("EVENT_BY_ENTITY", "LOAN_200"), "Repeat validation should not record events")
+
+		// A new transaction validates again
+		third := stub.MockInvoke("4", [][]byte{
+			[]byte("ValidateLoanApplication"),
+			[]byte("LOAN_200"),
+			[]byte(loanDataJSON),
+		})
+		require.Equal(t, int32(shim.OK), third.Status)
+		assert.Equal(t, 2*recorded, countKeys("EVENT_BY_ENTITY", "LOAN_200"))
+	})
+
+	t.Run("Entity screened once per transaction", func(t *testing.T) {
+		screen := func(txID string) SanctionScreeningResult {
+			response := stub.MockInvoke(txID, [][]byte{
+				[]byte("ScreenAgainstSanctionLists"),
+				[]byte("CUSTOMER_200"),
+				[]byte("Customer"),
+				[]byte(`{"name": "John Doe Sanctioned"}`),
+				[]byte("system"),
+			})
+			require.Equal(t, int32(shim.OK), response.Status)
+			var result SanctionScreeningResult
+			require.NoError(t, json.Unmarshal(response.Payload, &result))
+			return result
+		}
+
+		first := screen("5")
+		second := screen("5")
+		assert.Equal(t, first.ScreeningID, second.ScreeningID, "Repeat screening should return the first result")
+		assert.Equal(t, 1, countKeys("SCREENING_BY_ENTITY", "CUSTOMER_200"))
+
+		third := screen("6")
+		assert.NotEqual(t, first.ScreeningID, third.ScreeningID)
+		assert.Equal(t, 2, countKeys("SCREENING_BY_ENTITY", "CUSTOMER_200"))
+	})
+
+	t.Run("Proposal endorsed again is screened afresh", func(t *testing.T) {
+		// A second endorsement of transaction 5 runs against its own stub
+		endorsement := shimtest.NewMockStub("compliance", cc)
+		bindTestActors(t, endorsement)
+		setupSanctionEntries(t, endorsement)
+
+		response := endorsement.MockInvoke("5", [][]byte{
+			[]byte("ScreenAgainstSanctionLists"),
+			[]byte("CUSTOMER_200"),
+			[]byte("Customer"),
+			[]byte(`{"name": "John Doe Sanctioned"}`),
+			[]byte("system"),
+		})
+		require.Equal(t, int32(shim.OK), response.Status, response.Message)
+
+		endorsement.MockTransactionStart("count")
+		defer endorsement.MockTransactionEnd("count")
+		iterator, err := endorsement.GetStateByPartialCompositeKey("SCREENING_BY_ENTITY", []string{"CUSTOMER_200"})
+		require.NoError(t, err)
+		defer iterator.Close()
+		assert.True(t, iterator.HasNext(), "Screening should be recorded rather than served from the first endorsement")
+	})
+}
+
+// Helper function to setup test data
+func setupTestData(t *testing.T, stub *shimtest.MockStub) {
 	// Create a test rule
 	createRuleArgs := [][]byte{
 		[]byte("UpdateComplianceRule"),
@@ -1216,4 +1601,30 @@ func setupTestData(t *testing.T, stub *shimtest.MockStub) {
 	}
 	response := stub.MockInvoke("2", createRuleArgs)
 	assert.Equal(t, int32(shim.OK), response.Status, "Rule creation should succeed")
-}
\ No newline at end of file
+}
+
+// Helper function to store the sanction list entries used by screening tests
+func setupSanctionEntries(t *testing.T, stub *shimtest.MockStub) {
+	addSanctionEntry(t, stub, "sanction-1", "SANCTION_001", "OFAC_SDN", "John Doe Sanctioned", []string{"J. Doe", "Johnny Doe"}, "1980-01-01")
+	addSanctionEntry(t, stub, "sanction-2", "SANCTION_002", "UN_SANCTIONS", "Bad Company Ltd", []string{"Bad Co", "BC Ltd"}, "")
+	addSanctionEntry(t, stub, "sanction-3", "SANCTION_003", "EU_SANCTIONS", "Jane Smith Criminal", []string{"J. Smith", "Jane S."}, "1975-05-15")
+}
+
+// Helper function to add a sanction list entry through the chaincode
+func addSanctionEntry(t *testing.T, stub *shimtest.MockStub, txID, entryID, listName, entityName string, aliases []string, dateOfBirth string) {
+	aliasesJSON, _ := json.Marshal(aliases)
+	response := stub.MockInvoke(txID, [][]byte{
+		[]byte("AddSanctionListEntry"),
+		[]byte(entryID),
+		[]byte(listName),
+		[]byte(entityName),
+		[]byte("Individual"),
+		aliasesJSON,
+		[]byte(dateOfBirth),
+		[]byte("Unknown"),
+		[]byte("Financial"),
+		[]byte("TEST_SOURCE"),
+		[]byte("test-actor-1"),
+	})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+}
@@ -0,0 +1,141 @@
+package chaincode
+
+import (
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/chaincode"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/interfaces"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+)
+
+// APISchemaFile is the published schema of the customer chaincode's responses, relative to this
+// package. Regenerate it with `make api-schema` after an intended change to a response type.
+const APISchemaFile = "api_schema.json"
+
+// CustomerAPI maps every customer chaincode function to its response type, from which the
+// published API schema is generated
+func CustomerAPI() chaincode.APICatalog {
+	return chaincode.APICatalog{
+		// Customer management functions
+		"RegisterCustomer":          domain.Customer{},
+		"UpdateCustomer":            domain.Customer{},
+		"GetCustomer":               domain.Customer{},
+		"GetCustomerCrossResidency": domain.Customer{},
+		"GetCustomer360":            domain.Customer360{},
+		"RecordAccess":              domain.AccessLogEntry{},
+		"GetCustomerAccessLog":      domain.CustomerAccessLogResult{},
+		"GetCustomerHistory":        []interface{}{},
+		"UpdateCustomerStatus":      domain.Customer{},
+		"PurgeSandboxCustomer":      map[string]interface{}{},
+
+		// KYC/AML functions
+		"InitiateKYC":                 domain.KYCRecord{},
+		"UpdateKYCStatus":             domain.KYCRecord{},
+		"GetKYCRecord":                domain.KYCRecord{},
+		"InitiateAMLCheck":            domain.AMLRecord{},
+		"UpdateAMLStatus":             domain.AMLRecord{},
+		"GetAMLRecord":                domain.AMLRecord{},
+		"GetCustomerComplianceStatus": interfaces.CustomerComplianceStatus{},
+
+		// Beneficial ownership functions
+		"RecordOwnership":             domain.OwnershipLink{},
+		"RemoveOwnership":             domain.OwnershipLink{},
+		"GetOwnershipStructure":       domain.OwnershipStructure{},
+		"GetUltimateBeneficialOwners": domain.BeneficialOwnership{},
+		"GetControlledEntities":       domain.ControlledEntitiesResult{},
+
+		// Customer relationship functions
+		"CreateRelationship":       domain.CustomerRelationship{},
+		"TerminateRelationship":    domain.CustomerRelationship{},
+		"GetRelationship":          domain.CustomerRelationship{},
+		"GetCustomerRelationships": domain.CustomerRelationshipsResult{},
+		"GetRelatedParties":        domain.RelatedPartiesResult{},
+
+		// Query functions
+		"QueryCustomersByStatus":    domain.CustomerQueryResult{},
+		"QueryCustomersByKYCStatus": domain.CustomerQueryResult{},
+		"QueryCustomersByAMLStatus": domain.CustomerQueryResult{},
+		"SearchCustomersByName":     domain.CustomerQueryResult{},
+		"SearchCustomersByEmail":    domain.CustomerQueryResult{},
+		"QueryKYCByStatus":          []domain.KYCRecord{},
+
+		// Data-sharing functions
+		"CreateDataSharingAgreement": domain.DataSharingAgreement{},
+		"GetDataSharingAgreement":    domain.DataSharingAgreement{},
+		"RecordDisclosure":           domain.DisclosureRecord{},
+		"GetDisclosureLog":           []domain.DisclosureRecord{},
+		"GetClauseSuspensions":       []domain.ClauseSuspension{},
+
+		// Consent functions
+		"RecordConsent":          domain.ConsentRecord{},
+		"RenewConsent":           domain.ConsentRecord{},
+		"GetConsentHistory":      domain.ConsentHistoryResult{},
+		"GetConsentAt":           domain.ConsentStatus{},
+		"GetExpiringConsents":    domain.ExpiringConsentsResult{},
+		"ProcessConsentExpiries": domain.ConsentExpiryReport{},
+
+		// Risk rating functions
+		"RecalculateCustomerRisk":     domain.CustomerRiskProfile{},
+		"GetCustomerRiskProfile":      domain.CustomerRiskProfile{},
+		"QueryCustomersAboveRiskTier": domain.RiskProfileQueryResult{},
+
+		// Event stream functions
+		"GetCustomerEventStream": domain.CustomerEventStream{},
+
+		// Quality review functions
+		"SetQASamplingRate":  services.QASamplingRate{},
+		"GetQASamplingRates": []services.QASamplingRate{},
+		"GetQAReviewQueue":   chaincode.QAReviewQueueResult{},
+		"GetQAReviewItem":    services.QAReviewItem{},
+		"RecordQAReview":     services.QAReviewItem{},
+		"GetQADefectReport":  chaincode.QADefectReport{},
+
+		// Entity note functions
+		"AddNote":        services.EntityNote{},
+		"EditNote":       services.EntityNote{},
+		"GetEntityNotes": chaincode.EntityNotePageResult{},
+		"GetNoteHistory": []services.EntityNoteRevision{},
+
+		// Emergency freeze functions
+		"FreezeCustomer":  services.EntityFreeze{},
+		"RequestUnfreeze": services.EntityFreeze{},
+		"ApproveUnfreeze": services.EntityFreeze{},
+		"GetEntityFreeze": services.EntityFreeze{},
+
+		// Audit package functions
+		"ExportAuditPackage": services.AuditPackage{},
+		"GetAuditManifest":   services.AuditManifest{},
+		"VerifyAuditPackage": services.AuditPackageVerification{},
+		"ExportEntityAudit":  services.EntityAudit{},
+		"VerifyEntityAudit":  services.EntityAuditVerification{},
+
+		// Data quality functions
+		"RunDataQualitySweep":    services.DataQualityReport{},
+		"GetDataQualityReport":   services.DataQualityReport{},
+		"GetRemediationTasks":    chaincode.RemediationTaskResult{},
+		"ResolveRemediationTask": services.RemediationTask{},
+
+		// Operational functions
+		"SetFunctionFlag":            services.FunctionFlag{},
+		"GetFunctionFlags":           []services.FunctionFlag{},
+		"SetSandboxActor":            services.SandboxActor{},
+		"GetSandboxActors":           []services.SandboxActor{},
+		"RegisterActor":              services.ActorIdentity{},
+		"GetActor":                   services.ActorIdentity{},
+		"AttestCredentialRotation":   services.CredentialRotation{},
+		"GetCredentialRotation":      services.CredentialRotation{},
+		"GetInvokerIdentity":         chaincode.InvokerIdentity{},
+		"SetPayloadArchivePolicy":    services.PayloadArchivePolicy{},
+		"GetPayloadArchivePolicies":  []services.PayloadArchivePolicy{},
+		"GetArchivedPayload":         services.ArchivedPayload{},
+		"GetCounter":                 services.Counter{},
+		"CompactCounters":            []services.Counter{},
+		"ValidateStateCompatibility": services.CompatibilityReport{},
+		"RecordTimestampCutover":     services.TimestampCutover{},
+		"GetTimestampCutover":        services.TimestampCutover{},
+	}
+}
+
+// CustomerAPISchema generates the customer chaincode's API schema document from its catalog
+func CustomerAPISchema() *chaincode.APISchemaDocument {
+	return CustomerAPI().Document("customer", NewRouter())
+}
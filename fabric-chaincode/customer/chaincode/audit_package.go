@@ -0,0 +1,24 @@
+package chaincode
+
+import (
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// customerEntityAuditKeys are the customer records whose ledger history ExportEntityAudit exports
+var customerEntityAuditKeys = []string{"CUSTOMER_%s", "CUSTOMER_RISK_PROFILE_%s"}
+
+// customerAuditCollectors lists the evidence the customer chaincode holds about a customer, for
+// ExportAuditPackage. KYC records carry the hashes of the identity documents checked.
+func customerAuditCollectors() []services.AuditCollector {
+	return []services.AuditCollector{
+		services.RecordCollector("customer", "Current customer record", "CUSTOMER_%s"),
+		services.HistoryCollector(),
+		services.PointerCollector("kyc", "Latest KYC record, including document hashes", "CUSTOMER_KYC_%s", "KYC_%s"),
+		services.PointerCollector("aml", "Latest AML screening record", "CUSTOMER_AML_%s", "AML_%s"),
+		services.RecordCollector("riskProfile", "Current customer risk profile", "CUSTOMER_RISK_PROFILE_%s"),
+		services.CompositeKeyCollector("consents", "Every consent granted or withdrawn, by purpose and version", "CONSENT"),
+		services.CompositeKeyCollector("disclosures", "Disclosures of customer data to third parties", "DISCLOSURE"),
+		services.CompositeKeyCollector("accessLog", "Reads of the customer's PII, with purpose and legal basis", "CUSTOMER_ACCESS_LOG"),
+		services.CompositeKeyCollector("clauseSuspensions", "Data-sharing clauses suspended after consent was withdrawn", "DATA_SHARING_SUSPENSION"),
+	}
+}
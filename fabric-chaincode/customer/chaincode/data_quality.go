@@ -0,0 +1,112 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// customerDataQualityRules lists the invariants RunDataQualitySweep holds customer records to.
+// Customers with a residency keep their PII off the public ledger, so only the fields the public
+// record carries are checked for them.
+func customerDataQualityRules() map[string][]services.DataQualityRule {
+	return map[string][]services.DataQualityRule{
+		"CUSTOMER_": {
+			{Name: "requiredFields", Description: "Identifying fields are present", Check: checkCustomerRequiredFields},
+			{Name: "indexes", Description: "Status and national ID indexes point at the customer", Check: checkCustomerIndexes},
+			{Name: "consentPreferences", Description: "Consent preferences parse as a JSON object", Check: checkCustomerConsent},
+		},
+	}
+}
+
+func checkCustomerRequiredFields(stub shim.ChaincodeStubInterface, record interface{}) (string, error) {
+	customer := record.(*domain.Customer)
+
+	var missing []string
+	if customer.CustomerID == "" {
+		missing = append(missing, "customerID")
+	}
+	if customer.Status == "" {
+		missing = append(missing, "status")
+	}
+	if customer.CreatedDate.IsZero() {
+		missing = append(missing, "createdDate")
+	}
+	if customer.IsOrganization() {
+		if strings.TrimSpace(customer.LegalName) == "" {
+			missing = append(missing, "legalName")
+		}
+		if strings.TrimSpace(customer.RegistrationNumber) == "" {
+			missing = append(missing, "registrationNumber")
+		}
+	} else if customer.Residency == "" {
+		if strings.TrimSpace(customer.FirstName) == "" {
+			missing = append(missing, "firstName")
+		}
+		if strings.TrimSpace(customer.LastName) == "" {
+			missing = append(missing, "lastName")
+		}
+		if strings.TrimSpace(customer.NationalID) == "" {
+			missing = append(missing, "nationalID")
+		}
+		if customer.DateOfBirth.IsZero() {
+			missing = append(missing, "dateOfBirth")
+		}
+	}
+
+	if len(missing) > 0 {
+		return fmt.Sprintf("missing %s", strings.Join(missing, ", ")), nil
+	}
+	return "", nil
+}
+
+func checkCustomerIndexes(stub shim.ChaincodeStubInterface, record interface{}) (string, error) {
+	customer := record.(*domain.Customer)
+
+	indexed, err := services.IndexEntryExists(stub, "CUSTOMER_BY_STATUS", string(customer.Status), customer.CustomerID)
+	if err != nil {
+		return "", err
+	}
+	if !indexed {
+		return fmt.Sprintf("not in the CUSTOMER_BY_STATUS index under %s", customer.Status), nil
+	}
+
+	if customer.IsOrganization() && customer.RegistrationNumber != "" {
+		indexedID, err := stub.GetState(fmt.Sprintf("CUSTOMER_BY_REGISTRATION_NUMBER_%s", customer.RegistrationNumber))
+		if err != nil {
+			return "", fmt.Errorf("failed to read registration number index: %w", err)
+		}
+		if string(indexedID) != customer.CustomerID {
+			return fmt.Sprintf("registration number index points at %q", string(indexedID)), nil
+		}
+	}
+
+	// National IDs of customers with a residency are indexed by a hash of PII the public record lacks
+	if customer.Residency == "" && customer.NationalID != "" {
+		indexedID, err := stub.GetState(fmt.Sprintf("CUSTOMER_BY_NATIONAL_ID_%s", customer.NationalID))
+		if err != nil {
+			return "", fmt.Errorf("failed to read national ID index: %w", err)
+		}
+		if string(indexedID) != customer.CustomerID {
+			return fmt.Sprintf("national ID index points at %q", string(indexedID)), nil
+		}
+	}
+	return "", nil
+}
+
+func checkCustomerConsent(stub shim.ChaincodeStubInterface, record interface{}) (string, error) {
+	customer := record.(*domain.Customer)
+	if strings.TrimSpace(customer.ConsentPreferences) == "" {
+		return "", nil
+	}
+
+	var consent map[string]interface{}
+	if err := json.Unmarshal([]byte(customer.ConsentPreferences), &consent); err != nil {
+		return fmt.Sprintf("consent preferences do not parse: %v", err), nil
+	}
+	return "", nil
+}
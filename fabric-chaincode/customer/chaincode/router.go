@@ -1,21 +1,43 @@
 package chaincode
 
 import (
-	"fmt"
+	"sort"
 
 	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/chaincode"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/masking"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
 	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/handlers"
 )
 
 // Router handles function routing for the customer chaincode
 type Router struct {
 	handlers map[string]func(shim.ChaincodeStubInterface, []string) ([]byte, error)
+	masking  map[string]*masking.PolicySet
+	pii      map[string]bool
+	freeze   chaincode.FreezeArguments
 }
 
 // NewRouter creates a new router with all handler mappings
 func NewRouter() *Router {
 	customerHandler := handlers.NewCustomerHandler()
 	kycHandler := handlers.NewKYCHandler()
+	flagHandler := chaincode.NewFunctionFlagHandler()
+	sandboxHandler := chaincode.NewSandboxHandler()
+	identityHandler := chaincode.NewIdentityHandler()
+	archiveHandler := chaincode.NewPayloadArchiveHandler()
+	counterHandler := chaincode.NewCounterHandler()
+	compatibilityHandler := chaincode.NewCompatibilityHandler(newCustomerSchemaRegistry())
+	qaHandler := chaincode.NewQAReviewHandler()
+	noteHandler := chaincode.NewEntityNoteHandler()
+	freezeHandler := chaincode.NewEntityFreezeHandler(services.FreezeEntityCustomer, "CUSTOMER_%s")
+	auditPackageHandler := chaincode.NewAuditPackageHandler(customerAuditCollectors()...)
+	entityAuditHandler := chaincode.NewEntityAuditHandler(customerEntityAuditKeys...)
+	dataQualityHandler := chaincode.NewDataQualityHandler(newCustomerSchemaRegistry(), customerDataQualityRules())
+	dataSharingHandler := handlers.NewDataSharingHandler()
+	riskRatingHandler := handlers.NewRiskRatingHandler()
+	eventStreamHandler := handlers.NewEventStreamHandler()
 	
 	return &Router{
 		handlers: map[string]func(shim.ChaincodeStubInterface, []string) ([]byte, error){
@@ -23,8 +45,13 @@ func NewRouter() *Router {
 			"RegisterCustomer":    customerHandler.RegisterCustomer,
 			"UpdateCustomer":      customerHandler.UpdateCustomer,
 			"GetCustomer":         customerHandler.GetCustomer,
+			"GetCustomerCrossResidency": customerHandler.GetCustomerCrossResidency,
+			"GetCustomer360":      customerHandler.GetCustomer360,
+			"RecordAccess":        customerHandler.RecordAccess,
+			"GetCustomerAccessLog": customerHandler.GetCustomerAccessLog,
 			"GetCustomerHistory":  customerHandler.GetCustomerHistory,
 			"UpdateCustomerStatus": customerHandler.UpdateCustomerStatus,
+			"PurgeSandboxCustomer": customerHandler.PurgeSandboxCustomer,
 			
 			// KYC/AML functions
 			"InitiateKYC":         kycHandler.InitiateKYC,
@@ -33,10 +60,127 @@ func NewRouter() *Router {
 			"InitiateAMLCheck":    kycHandler.InitiateAMLCheck,
 			"UpdateAMLStatus":     kycHandler.UpdateAMLStatus,
 			"GetAMLRecord":        kycHandler.GetAMLRecord,
+			"GetCustomerComplianceStatus": kycHandler.GetCustomerComplianceStatus,
+			
+			// Beneficial ownership functions
+			"RecordOwnership":             customerHandler.RecordOwnership,
+			"RemoveOwnership":             customerHandler.RemoveOwnership,
+			"GetOwnershipStructure":       customerHandler.GetOwnershipStructure,
+			"GetUltimateBeneficialOwners": customerHandler.GetUltimateBeneficialOwners,
+			"GetControlledEntities":       customerHandler.GetControlledEntities,
+			
+			// Customer relationship functions
+			"CreateRelationship":       customerHandler.CreateRelationship,
+			"TerminateRelationship":    customerHandler.TerminateRelationship,
+			"GetRelationship":          customerHandler.GetRelationship,
+			"GetCustomerRelationships": customerHandler.GetCustomerRelationships,
+			"GetRelatedParties":        customerHandler.GetRelatedParties,
 			
 			// Query functions
 			"QueryCustomersByStatus": customerHandler.QueryCustomersByStatus,
+			"QueryCustomersByKYCStatus": customerHandler.QueryCustomersByKYCStatus,
+			"QueryCustomersByAMLStatus": customerHandler.QueryCustomersByAMLStatus,
+			"SearchCustomersByName":  customerHandler.SearchCustomersByName,
+			"SearchCustomersByEmail": customerHandler.SearchCustomersByEmail,
 			"QueryKYCByStatus":       kycHandler.QueryKYCByStatus,
+			
+			// Data-sharing functions
+			"CreateDataSharingAgreement": dataSharingHandler.CreateDataSharingAgreement,
+			"GetDataSharingAgreement":    dataSharingHandler.GetDataSharingAgreement,
+			"RecordDisclosure":           dataSharingHandler.RecordDisclosure,
+			"GetDisclosureLog":           dataSharingHandler.GetDisclosureLog,
+			"GetClauseSuspensions":       dataSharingHandler.GetClauseSuspensions,
+			
+			// Consent functions
+			"RecordConsent":          customerHandler.RecordConsent,
+			"RenewConsent":           customerHandler.RenewConsent,
+			"GetConsentHistory":      customerHandler.GetConsentHistory,
+			"GetConsentAt":           customerHandler.GetConsentAt,
+			"GetExpiringConsents":    customerHandler.GetExpiringConsents,
+			"ProcessConsentExpiries": customerHandler.ProcessConsentExpiries,
+			
+			// Risk rating functions
+			"RecalculateCustomerRisk":     riskRatingHandler.RecalculateCustomerRisk,
+			"GetCustomerRiskProfile":      riskRatingHandler.GetCustomerRiskProfile,
+			"QueryCustomersAboveRiskTier": riskRatingHandler.QueryCustomersAboveRiskTier,
+			
+			// Event stream functions
+			"GetCustomerEventStream": eventStreamHandler.GetCustomerEventStream,
+			
+			// Quality review functions
+			"SetQASamplingRate":  qaHandler.SetQASamplingRate,
+			"GetQASamplingRates": qaHandler.GetQASamplingRates,
+			"GetQAReviewQueue":   qaHandler.GetQAReviewQueue,
+			"GetQAReviewItem":    qaHandler.GetQAReviewItem,
+			"RecordQAReview":     qaHandler.RecordQAReview,
+			"GetQADefectReport":  qaHandler.GetQADefectReport,
+			
+			// Entity note functions
+			"AddNote":        noteHandler.AddNote,
+			"EditNote":       noteHandler.EditNote,
+			"GetEntityNotes": noteHandler.GetEntityNotes,
+			"GetNoteHistory": noteHandler.GetNoteHistory,
+			
+			// Emergency freeze functions
+			"FreezeCustomer":  freezeHandler.Freeze,
+			"RequestUnfreeze": freezeHandler.RequestUnfreeze,
+			"ApproveUnfreeze": freezeHandler.ApproveUnfreeze,
+			"GetEntityFreeze": freezeHandler.GetEntityFreeze,
+			
+			// Audit package functions
+			"ExportAuditPackage": auditPackageHandler.ExportAuditPackage,
+			"GetAuditManifest":   auditPackageHandler.GetAuditManifest,
+			"VerifyAuditPackage": auditPackageHandler.VerifyAuditPackage,
+			"ExportEntityAudit":  entityAuditHandler.ExportEntityAudit,
+			"VerifyEntityAudit":  entityAuditHandler.VerifyEntityAudit,
+			
+			// Data quality functions
+			"RunDataQualitySweep":    dataQualityHandler.RunDataQualitySweep,
+			"GetDataQualityReport":   dataQualityHandler.GetDataQualityReport,
+			"GetRemediationTasks":    dataQualityHandler.GetRemediationTasks,
+			"ResolveRemediationTask": dataQualityHandler.ResolveRemediationTask,
+			
+			// Operational functions
+			"SetFunctionFlag":  flagHandler.SetFunctionFlag,
+			"GetFunctionFlags": flagHandler.GetFunctionFlags,
+			"SetSandboxActor":  sandboxHandler.SetSandboxActor,
+			"GetSandboxActors": sandboxHandler.GetSandboxActors,
+			"RegisterActor":      identityHandler.RegisterActor,
+			"GetActor":           identityHandler.GetActor,
+			"AttestCredentialRotation": identityHandler.AttestCredentialRotation,
+			"GetCredentialRotation":    identityHandler.GetCredentialRotation,
+			"GetInvokerIdentity": identityHandler.GetInvokerIdentity,
+			"SetPayloadArchivePolicy":   archiveHandler.SetPayloadArchivePolicy,
+			"GetPayloadArchivePolicies": archiveHandler.GetPayloadArchivePolicies,
+			"GetArchivedPayload":        archiveHandler.GetArchivedPayload,
+			"GetCounter":                counterHandler.GetCounter,
+			"CompactCounters":           counterHandler.CompactCounters,
+			"ValidateStateCompatibility": compatibilityHandler.ValidateStateCompatibility,
+			"RecordTimestampCutover":     compatibilityHandler.RecordTimestampCutover,
+			"GetTimestampCutover":        compatibilityHandler.GetTimestampCutover,
+		},
+		// Responses masked for the invoker's role
+		masking: map[string]*masking.PolicySet{
+			"QueryCustomersByStatus":    domain.CustomerMaskingPolicies,
+			"QueryCustomersByKYCStatus": domain.CustomerMaskingPolicies,
+			"QueryCustomersByAMLStatus": domain.CustomerMaskingPolicies,
+			"SearchCustomersByName":     domain.CustomerMaskingPolicies,
+			"SearchCustomersByEmail":    domain.CustomerMaskingPolicies,
+			"GetCustomer360":            domain.Customer360MaskingPolicies,
+		},
+		// Functions whose arguments carry customer PII, never retained in the payload archive
+		pii: map[string]bool{
+			"RegisterCustomer":       true,
+			"UpdateCustomer":         true,
+			"SearchCustomersByName":  true,
+			"SearchCustomersByEmail": true,
+		},
+		// Functions naming their customer other than as the customerID of a JSON request, or taking
+		// positional arguments that name no single customer
+		freeze: chaincode.FreezeArguments{
+			"PurgeSandboxCustomer": {Entity: services.FreezeEntityCustomer, Field: "entityID"},
+			"CompactCounters":      {},
+			"RecordAccess":         {},
 		},
 	}
 }
@@ -45,8 +189,32 @@ func NewRouter() *Router {
 func (r *Router) Route(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
 	handler, exists := r.handlers[function]
 	if !exists {
-		return nil, fmt.Errorf("function %s not found", function)
+		return nil, services.NewChaincodeError(services.ErrCodeUnknownFunction, "", "function %s not found", function)
 	}
 	
 	return handler(stub, args)
-}
\ No newline at end of file
+}
+
+// MaskingPolicy returns the masking policies for a function's response, or nil if it is not masked
+func (r *Router) MaskingPolicy(function string) *masking.PolicySet {
+	return r.masking[function]
+}
+
+// CarriesPII reports whether a function's arguments carry customer PII
+func (r *Router) CarriesPII(function string) bool {
+	return r.pii[function]
+}
+
+// FreezeArguments locates the customer of functions not naming it in a JSON request's customerID
+func (r *Router) FreezeArguments() chaincode.FreezeArguments {
+	return r.freeze
+}
+// Functions returns the names of the routed functions in name order
+func (r *Router) Functions() []string {
+	functions := make([]string, 0, len(r.handlers))
+	for function := range r.handlers {
+		functions = append(functions, function)
+	}
+	sort.Strings(functions)
+	return functions
+}
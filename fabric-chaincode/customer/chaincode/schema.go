@@ -0,0 +1,37 @@
+package chaincode
+
+import (
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// newCustomerSchemaRegistry registers every namespace the customer chaincode writes. Keep this in
+// step with new entities so ValidateStateCompatibility covers them before an upgrade.
+func newCustomerSchemaRegistry() *services.SchemaRegistry {
+	registry := services.NewSchemaRegistry()
+
+	// Entities keyed by prefix
+	registry.RegisterPrefix("CUSTOMER_", "Customer", func() interface{} { return &domain.Customer{} })
+	registry.RegisterPrefix("KYC_", "KYCRecord", func() interface{} { return &domain.KYCRecord{} })
+	registry.RegisterPrefix("AML_", "AMLRecord", func() interface{} { return &domain.AMLRecord{} })
+	registry.RegisterPrefix("DATA_SHARING_AGREEMENT_", "DataSharingAgreement", func() interface{} { return &domain.DataSharingAgreement{} })
+	registry.RegisterPrefix("CUSTOMER_RISK_PROFILE_", "CustomerRiskProfile", func() interface{} { return &domain.CustomerRiskProfile{} })
+	registry.RegisterPrefix("CONSENT_EXPIRY_", "ConsentExpiryEntry", func() interface{} { return &domain.ConsentExpiryEntry{} })
+	registry.RegisterPrefix("RELATIONSHIP_", "CustomerRelationship", func() interface{} { return &domain.CustomerRelationship{} })
+
+	// Raw ID indexes sharing an entity prefix
+	registry.RegisterIndexPrefix("CUSTOMER_BY_NATIONAL_ID_")
+	registry.RegisterIndexPrefix("CUSTOMER_BY_REGISTRATION_NUMBER_")
+	registry.RegisterIndexPrefix("CUSTOMER_KYC_")
+	registry.RegisterIndexPrefix("CUSTOMER_AML_")
+
+	// Entities keyed by composite key
+	registry.RegisterCompositeKey("CROSS_RESIDENCY_ACCESS", "CrossResidencyAccess", func() interface{} { return &domain.CrossResidencyAccess{} })
+	registry.RegisterCompositeKey("CUSTOMER_ACCESS_LOG", "AccessLogEntry", func() interface{} { return &domain.AccessLogEntry{} })
+	registry.RegisterCompositeKey("DATA_SHARING_SUSPENSION", "ClauseSuspension", func() interface{} { return &domain.ClauseSuspension{} })
+	registry.RegisterCompositeKey("CONSENT", "ConsentRecord", func() interface{} { return &domain.ConsentRecord{} })
+	registry.RegisterCompositeKey("DISCLOSURE", "DisclosureRecord", func() interface{} { return &domain.DisclosureRecord{} })
+	registry.RegisterCompositeKey("OWNERSHIP", "OwnershipLink", func() interface{} { return &domain.OwnershipLink{} })
+
+	return registry
+}
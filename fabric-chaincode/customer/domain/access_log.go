@@ -0,0 +1,100 @@
+package domain
+
+import (
+	"sort"
+	"time"
+)
+
+// AccessLogEntry records one read of a customer's PII: who read it, through which function, for
+// what purpose and on what legal basis. Reads relying on the customer's consent carry the version
+// of the consent record in force.
+type AccessLogEntry struct {
+	CustomerID     string    `json:"customerID"`
+	Function       string    `json:"function"`
+	Purpose        string    `json:"purpose"`
+	LegalBasis     string    `json:"legalBasis,omitempty"`
+	ConsentPurpose string    `json:"consentPurpose,omitempty"`
+	ConsentVersion int       `json:"consentVersion,omitempty"`
+	ActorID        string    `json:"actorID,omitempty"` // Set when the client named a registered actor
+	InvokerID      string    `json:"invokerID"`
+	InvokerMSPID   string    `json:"invokerMSPID"`
+	InvokerRole    string    `json:"invokerRole,omitempty"`
+	AccessDate     time.Time `json:"accessDate"`
+	TransactionID  string    `json:"transactionID"`
+}
+
+// Accessor summarises the reads of a customer's PII by one identity in a period
+type Accessor struct {
+	InvokerID   string    `json:"invokerID"`
+	MSPID       string    `json:"mspID"`
+	ActorIDs    []string  `json:"actorIDs"`
+	Purposes    []string  `json:"purposes"`
+	Reads       int       `json:"reads"`
+	FirstAccess time.Time `json:"firstAccess"`
+	LastAccess  time.Time `json:"lastAccess"`
+}
+
+// CustomerAccessLogResult answers who read a customer's PII in a period. From and To are unset
+// when the period is open at that end.
+type CustomerAccessLogResult struct {
+	CustomerID string           `json:"customerID"`
+	From       *time.Time       `json:"from,omitempty"`
+	To         *time.Time       `json:"to,omitempty"`
+	Entries    []AccessLogEntry `json:"entries"`
+	Accessors  []Accessor       `json:"accessors"`
+	Count      int              `json:"count"`
+}
+
+// SummariseAccessors groups access log entries by the identity that made them, most reads first
+func SummariseAccessors(entries []AccessLogEntry) []Accessor {
+	byInvoker := make(map[string]*Accessor)
+	order := []string{}
+	for _, entry := range entries {
+		key := entry.InvokerMSPID + "\x00" + entry.InvokerID
+		accessor, ok := byInvoker[key]
+		if !ok {
+			accessor = &Accessor{
+				InvokerID:   entry.InvokerID,
+				MSPID:       entry.InvokerMSPID,
+				ActorIDs:    []string{},
+				Purposes:    []string{},
+				FirstAccess: entry.AccessDate,
+				LastAccess:  entry.AccessDate,
+			}
+			byInvoker[key] = accessor
+			order = append(order, key)
+		}
+		accessor.Reads++
+		if entry.AccessDate.Before(accessor.FirstAccess) {
+			accessor.FirstAccess = entry.AccessDate
+		}
+		if entry.AccessDate.After(accessor.LastAccess) {
+			accessor.LastAccess = entry.AccessDate
+		}
+		if entry.ActorID != "" {
+			accessor.ActorIDs = appendUnique(accessor.ActorIDs, entry.ActorID)
+		}
+		accessor.Purposes = appendUnique(accessor.Purposes, entry.Purpose)
+	}
+
+	accessors := make([]Accessor, 0, len(order))
+	for _, key := range order {
+		accessor := byInvoker[key]
+		sort.Strings(accessor.ActorIDs)
+		sort.Strings(accessor.Purposes)
+		accessors = append(accessors, *accessor)
+	}
+	sort.SliceStable(accessors, func(i, j int) bool {
+		return accessors[i].Reads > accessors[j].Reads
+	})
+	return accessors
+}
+
+func appendUnique(values []string, value string) []string {
+	for _, existing := range values {
+		if existing == value {
+			return values
+		}
+	}
+	return append(values, value)
+}
@@ -0,0 +1,128 @@
+package domain
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// Sources of a consent record
+const (
+	ConsentSourceRegistration = "REGISTRATION"
+	ConsentSourceUpdate       = "CUSTOMER_UPDATE"
+	ConsentSourceDirect       = "CONSENT_RECORD"
+	ConsentSourceRenewal      = "CONSENT_RENEWAL"
+	ConsentSourceExpiry       = "CONSENT_EXPIRY"
+)
+
+// ConsentRecord is one grant or withdrawal of consent to a purpose. A change of consent adds the
+// next version rather than overwriting the last, so earlier consent stays on the ledger as
+// evidence.
+type ConsentRecord struct {
+	CustomerID    string     `json:"customerID"`
+	Purpose       string     `json:"purpose"`
+	Version       int        `json:"version"`
+	Granted       bool       `json:"granted"`
+	Source        string     `json:"source"`
+	Evidence      string     `json:"evidence,omitempty"` // Reference to the signed form, call recording or channel
+	EffectiveDate time.Time  `json:"effectiveDate"`
+	ExpiryDate    *time.Time `json:"expiryDate,omitempty"` // Grants lapse after config.ConsentValidityPeriod unless renewed
+	RecordedBy    string     `json:"recordedBy"`
+	TransactionID string     `json:"transactionID"`
+}
+
+// ConsentRequest represents a request to grant or withdraw one consent purpose
+type ConsentRequest struct {
+	CustomerID string `json:"customerID"`
+	Purpose    string `json:"purpose"`
+	Granted    bool   `json:"granted"`
+	Evidence   string `json:"evidence"`
+	ActorID    string `json:"actorID"`
+}
+
+// ConsentStatus answers whether a purpose was consented to at an instant. Record is the consent
+// record in force then, or nil when none had been recorded.
+type ConsentStatus struct {
+	CustomerID string         `json:"customerID"`
+	Purpose    string         `json:"purpose"`
+	AsOf       time.Time      `json:"asOf"`
+	Granted    bool           `json:"granted"`
+	Record     *ConsentRecord `json:"record,omitempty"`
+}
+
+// ConsentHistoryResult is one page of a customer's consent records
+type ConsentHistoryResult struct {
+	Records  []ConsentRecord `json:"records"`
+	Count    int             `json:"count"`
+	Bookmark string          `json:"bookmark"`
+}
+
+// ConsentExpiryEntry indexes a customer's current grant of a purpose under the day it expires
+type ConsentExpiryEntry struct {
+	CustomerID   string     `json:"customerID"`
+	Purpose      string     `json:"purpose"`
+	Version      int        `json:"version"`
+	ExpiryDate   time.Time  `json:"expiryDate"`
+	NotifiedDate *time.Time `json:"notifiedDate,omitempty"` // Set once a ConsentExpiring event has announced it
+}
+
+// ExpiringConsentsResult lists the current grants expiring by a date, including those already
+// past expiry that have not yet been lapsed
+type ExpiringConsentsResult struct {
+	Until    time.Time            `json:"until"`
+	Consents []ConsentExpiryEntry `json:"consents"`
+	Count    int                  `json:"count"`
+}
+
+// ConsentExpiryRequest represents a run of the consent expiry process
+type ConsentExpiryRequest struct {
+	NoticeDays int    `json:"noticeDays"` // Announce grants expiring within this many days
+	ActorID    string `json:"actorID"`
+}
+
+// ConsentExpiryReport is the outcome of a run of the consent expiry process and the data of its
+// ConsentExpired or ConsentExpiring event
+type ConsentExpiryReport struct {
+	Expired       []ConsentExpiryEntry `json:"expired"`
+	Expiring      []ConsentExpiryEntry `json:"expiring"`
+	Suspensions   []ClauseSuspension   `json:"suspensions"`
+	Complete      bool                 `json:"complete"` // False when a batch limit was reached; run again
+	ProcessedBy   string               `json:"processedBy"`
+	ProcessedDate time.Time            `json:"processedDate"`
+}
+
+// ConsentPurposes returns the purposes a consent preferences document sets, true or false, in
+// order
+func ConsentPurposes(consentJSON string) []string {
+	var consent map[string]interface{}
+	if err := json.Unmarshal([]byte(consentJSON), &consent); err != nil {
+		return nil
+	}
+	purposes := []string{}
+	for purpose, value := range consent {
+		if _, ok := value.(bool); ok {
+			purposes = append(purposes, purpose)
+		}
+	}
+	sort.Strings(purposes)
+	return purposes
+}
+
+// SetConsent returns a consent preferences document with one purpose granted or withdrawn,
+// leaving the rest of the document as it was
+func SetConsent(consentJSON, purpose string, granted bool) (string, error) {
+	consent := map[string]interface{}{}
+	if consentJSON != "" {
+		if err := json.Unmarshal([]byte(consentJSON), &consent); err != nil {
+			return "", fmt.Errorf("failed to parse consent preferences: %w", err)
+		}
+	}
+	consent[purpose] = granted
+
+	consentBytes, err := json.Marshal(consent)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal consent preferences: %w", err)
+	}
+	return string(consentBytes), nil
+}
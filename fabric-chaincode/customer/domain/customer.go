@@ -1,14 +1,27 @@
 package domain
 
 import (
+	"strings"
 	"time"
 	
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/encryption"
 	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
 )
 
+// CustomerType distinguishes individual customers from organizations
+type CustomerType string
+
+const (
+	CustomerTypeIndividual   CustomerType = "INDIVIDUAL"
+	CustomerTypeOrganization CustomerType = "ORGANIZATION"
+)
+
 // Customer represents a customer entity
 type Customer struct {
 	CustomerID      string                     `json:"customerID"`
+	CustomerType    CustomerType               `json:"customerType,omitempty"` // Empty on individuals registered before organizations were supported
+	LegalName       string                     `json:"legalName,omitempty"` // Organizations only
+	RegistrationNumber string                  `json:"registrationNumber,omitempty"` // Organizations only; company registry number
 	FirstName       string                     `json:"firstName"`
 	LastName        string                     `json:"lastName"`
 	Email           string                     `json:"email"`
@@ -18,14 +31,37 @@ type Customer struct {
 	Address         string                     `json:"address"`
 	Status          validation.CustomerStatus `json:"status"`
 	ConsentPreferences string                  `json:"consentPreferences"`
+	Residency       string                     `json:"residency,omitempty"` // ISO 3166-1 alpha-2; PII kept in the residency's private data collection
+	Sandbox         bool                       `json:"sandbox,omitempty"` // Registered by a sandbox actor; excluded from production reporting
+	EncryptedFields map[string]encryption.EncryptedField `json:"encryptedFields,omitempty"` // Email, phone and address sealed under an off-chain data key, by JSON field name
+	EmailHash       string                     `json:"emailHash,omitempty"` // Set once the email is sealed, so it stays indexed without the plaintext
 	CreatedDate     time.Time                  `json:"createdDate"`
 	LastUpdated     time.Time                  `json:"lastUpdated"`
 	CreatedBy       string                     `json:"createdBy"`
 	LastUpdatedBy   string                     `json:"lastUpdatedBy"`
+	Version         int                        `json:"version"` // Advanced on every write; updates may name it as expectedVersion
 }
 
-// CustomerRegistrationRequest represents a customer registration request
+// IsOrganization reports whether the customer is an organization rather than an individual
+func (c *Customer) IsOrganization() bool {
+	return c.CustomerType == CustomerTypeOrganization
+}
+
+// Name returns the name the customer is screened and listed under: an organization's legal name
+// or an individual's full name
+func (c *Customer) Name() string {
+	if c.IsOrganization() {
+		return strings.TrimSpace(c.LegalName)
+	}
+	return strings.TrimSpace(c.FirstName + " " + c.LastName)
+}
+
+// CustomerRegistrationRequest represents a customer registration request. Organizations give a
+// legal name and registration number in place of an individual's name, birth date and national ID.
 type CustomerRegistrationRequest struct {
+	CustomerType       CustomerType `json:"customerType,omitempty"` // Defaults to INDIVIDUAL
+	LegalName          string    `json:"legalName,omitempty"`
+	RegistrationNumber string    `json:"registrationNumber,omitempty"`
 	FirstName          string    `json:"firstName"`
 	LastName           string    `json:"lastName"`
 	Email              string    `json:"email"`
@@ -34,6 +70,8 @@ type CustomerRegistrationRequest struct {
 	NationalID         string    `json:"nationalID"`
 	Address            string    `json:"address"`
 	ConsentPreferences string    `json:"consentPreferences"`
+	Residency          string    `json:"residency,omitempty"` // Omit to keep PII on the public ledger
+	IdempotencyKey     string    `json:"idempotencyKey,omitempty"` // Retries carrying the same key return the original customer
 	ActorID            string    `json:"actorID"`
 }
 
@@ -46,6 +84,7 @@ type CustomerUpdateRequest struct {
 	Phone              *string   `json:"phone,omitempty"`
 	Address            *string   `json:"address,omitempty"`
 	ConsentPreferences *string   `json:"consentPreferences,omitempty"`
+	ExpectedVersion    *int      `json:"expectedVersion,omitempty"` // Rejected with CONFLICT unless the customer is still at this version
 	ActorID            string    `json:"actorID"`
 }
 
@@ -54,5 +93,19 @@ type CustomerStatusUpdateRequest struct {
 	CustomerID string                     `json:"customerID"`
 	NewStatus  validation.CustomerStatus `json:"newStatus"`
 	Reason     string                     `json:"reason"`
+	ExpectedVersion *int                  `json:"expectedVersion,omitempty"` // Rejected with CONFLICT unless the customer is still at this version
 	ActorID    string                     `json:"actorID"`
+}
+
+// SandboxCustomerPurgeRequest represents a request to purge a sandbox customer
+type SandboxCustomerPurgeRequest struct {
+	CustomerID string `json:"customerID"`
+	ActorID    string `json:"actorID"`
+}
+
+// CustomerQueryResult is one page of a customer listing or search
+type CustomerQueryResult struct {
+	Customers []Customer `json:"customers"`
+	Count     int        `json:"count"`
+	Bookmark  string     `json:"bookmark"`
 }
\ No newline at end of file
@@ -0,0 +1,22 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/interfaces"
+)
+
+// Customer360 gathers what the customer, compliance and loan chaincodes hold on a customer into
+// one view. Sections the invoker's role may not see are omitted from the response.
+type Customer360 struct {
+	CustomerID           string                                `json:"customerID"`
+	Profile              Customer                              `json:"profile"`
+	Consents             []ConsentStatus                       `json:"consents"` // Current consent to each purpose the customer has set
+	LatestKYC            *KYCRecord                            `json:"latestKYC,omitempty"`
+	LatestAMLCheck       *AMLRecord                            `json:"latestAMLCheck,omitempty"`
+	RiskTier             string                                `json:"riskTier,omitempty"`
+	OpenComplianceEvents []interfaces.ComplianceEventSummary   `json:"openComplianceEvents"`
+	Screenings           []interfaces.CustomerScreeningSummary `json:"screenings"`
+	LoanApplications     []interfaces.CustomerLoanSummary      `json:"loanApplications"`
+	GeneratedDate        time.Time                             `json:"generatedDate"`
+}
@@ -0,0 +1,140 @@
+package domain
+
+import (
+	"encoding/json"
+	"sort"
+	"time"
+
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// DataSharingClause permits disclosing categories of customer data to the partner for one
+// purpose. The purpose is the consent key the clause relies on.
+type DataSharingClause struct {
+	ClauseID       string   `json:"clauseID"`
+	Purpose        string   `json:"purpose"`
+	DataCategories []string `json:"dataCategories"`
+	Description    string   `json:"description"`
+}
+
+// DataSharingAgreement is an agreement under which the bank discloses customer data to a partner
+// organisation
+type DataSharingAgreement struct {
+	AgreementID string              `json:"agreementID"`
+	PartnerMSP  string              `json:"partnerMSP"`
+	PartnerName string              `json:"partnerName"`
+	Clauses     []DataSharingClause `json:"clauses"`
+	CreatedDate time.Time           `json:"createdDate"`
+	CreatedBy   string              `json:"createdBy"`
+}
+
+// Clause returns the agreement's clause with the given ID, or nil
+func (a *DataSharingAgreement) Clause(clauseID string) *DataSharingClause {
+	for i := range a.Clauses {
+		if a.Clauses[i].ClauseID == clauseID {
+			return &a.Clauses[i]
+		}
+	}
+	return nil
+}
+
+// DataSharingAgreementRequest represents a request to record a data-sharing agreement
+type DataSharingAgreementRequest struct {
+	PartnerMSP  string              `json:"partnerMSP"`
+	PartnerName string              `json:"partnerName"`
+	Clauses     []DataSharingClause `json:"clauses"`
+	ActorID     string              `json:"actorID"`
+}
+
+// ClauseSuspension records that a customer withdrew the consent a clause relies on. While it is
+// active nothing may be disclosed about the customer under the clause.
+type ClauseSuspension struct {
+	CustomerID     string     `json:"customerID"`
+	AgreementID    string     `json:"agreementID"`
+	ClauseID       string     `json:"clauseID"`
+	Purpose        string     `json:"purpose"`
+	PartnerMSP     string     `json:"partnerMSP"`
+	SuspendedDate  time.Time  `json:"suspendedDate"`
+	SuspendedTxID  string     `json:"suspendedTxID"`
+	SuspendedBy    string     `json:"suspendedBy"`
+	ReinstatedDate *time.Time `json:"reinstatedDate,omitempty"` // Set when the consent is granted again
+	ReinstatedTxID string     `json:"reinstatedTxID,omitempty"`
+}
+
+// Active reports whether the suspension still blocks disclosures
+func (s *ClauseSuspension) Active() bool {
+	return s.ReinstatedDate == nil
+}
+
+// DisclosureRequest represents a request to log a disclosure of customer data to a partner
+type DisclosureRequest struct {
+	CustomerID     string   `json:"customerID"`
+	AgreementID    string   `json:"agreementID"`
+	ClauseID       string   `json:"clauseID"`
+	DataCategories []string `json:"dataCategories"`
+	ActorID        string   `json:"actorID"`
+}
+
+// DisclosureRecord is an entry in a customer's disclosure log
+type DisclosureRecord struct {
+	DisclosureID   string    `json:"disclosureID"`
+	CustomerID     string    `json:"customerID"`
+	AgreementID    string    `json:"agreementID"`
+	ClauseID       string    `json:"clauseID"`
+	Purpose        string    `json:"purpose"`
+	PartnerMSP     string    `json:"partnerMSP"`
+	DataCategories []string  `json:"dataCategories"`
+	DisclosedBy    string    `json:"disclosedBy"`
+	DisclosedDate  time.Time `json:"disclosedDate"`
+	TransactionID  string    `json:"transactionID"`
+
+	// Evidence of the partner's signature when the partner requested the data through the gateway
+	RequestSignature *services.RequestSignature `json:"requestSignature,omitempty"`
+}
+
+// ConsentWithdrawal is the data of a ConsentWithdrawn event. Partners find the clauses suspended
+// for them by their MSP ID.
+type ConsentWithdrawal struct {
+	CustomerID  string             `json:"customerID"`
+	Purposes    []string           `json:"purposes"`
+	Suspensions []ClauseSuspension `json:"suspensions"`
+}
+
+// ConsentGranted reports whether a consent preferences document grants a purpose
+func ConsentGranted(consentJSON, purpose string) bool {
+	return grantedConsents(consentJSON)[purpose]
+}
+
+// ConsentChanges returns the purposes a consent update withdraws and those it grants, in order
+func ConsentChanges(previousJSON, currentJSON string) (withdrawn, granted []string) {
+	previous := grantedConsents(previousJSON)
+	current := grantedConsents(currentJSON)
+	for purpose := range previous {
+		if !current[purpose] {
+			withdrawn = append(withdrawn, purpose)
+		}
+	}
+	for purpose := range current {
+		if !previous[purpose] {
+			granted = append(granted, purpose)
+		}
+	}
+	sort.Strings(withdrawn)
+	sort.Strings(granted)
+	return withdrawn, granted
+}
+
+// grantedConsents returns the purposes a consent preferences document sets to true
+func grantedConsents(consentJSON string) map[string]bool {
+	var consent map[string]interface{}
+	if err := json.Unmarshal([]byte(consentJSON), &consent); err != nil {
+		return map[string]bool{}
+	}
+	granted := make(map[string]bool)
+	for purpose, value := range consent {
+		if allowed, ok := value.(bool); ok && allowed {
+			granted[purpose] = true
+		}
+	}
+	return granted
+}
@@ -0,0 +1,35 @@
+package domain
+
+import "time"
+
+// Types of entry in a customer's event stream
+const (
+	StreamEntryCustomerChange = "CUSTOMER_CHANGE" // Registration, profile and status changes
+	StreamEntryConsent        = "CONSENT"
+	StreamEntryKYC            = "KYC"
+	StreamEntryAMLCheck       = "AML_CHECK"
+	StreamEntryScreening      = "SCREENING" // Compliance screenings and alerts raised against the customer
+	StreamEntryLoanMilestone  = "LOAN_MILESTONE"
+)
+
+// CustomerStreamEntry is one dated event in a customer's lifetime. ReferenceID names the KYC record,
+// AML check, compliance event or loan the entry concerns; Details carries type-specific values.
+type CustomerStreamEntry struct {
+	EntryID     string            `json:"entryID"`
+	EntryType   string            `json:"entryType"`
+	Timestamp   time.Time         `json:"timestamp"`
+	Summary     string            `json:"summary"`
+	ReferenceID string            `json:"referenceID,omitempty"`
+	ActorID     string            `json:"actorID,omitempty"`
+	Details     map[string]string `json:"details,omitempty"`
+}
+
+// CustomerEventStream is one page of a customer's event stream, oldest first. Bookmark resumes the
+// stream after the last entry returned and is empty on the final page.
+type CustomerEventStream struct {
+	CustomerID string                `json:"customerID"`
+	Since      *time.Time            `json:"since,omitempty"`
+	Entries    []CustomerStreamEntry `json:"entries"`
+	Count      int                   `json:"count"`
+	Bookmark   string                `json:"bookmark"`
+}
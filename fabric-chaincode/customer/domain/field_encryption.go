@@ -0,0 +1,40 @@
+package domain
+
+// EncryptedCustomerFields are the customer fields sealed with field-level encryption, by JSON
+// field name. The national ID is kept out of indexes by hashing instead.
+var EncryptedCustomerFields = []string{"email", "phone", "address"}
+
+// CustomerContact holds the email, phone and address a client passes in transient data under
+// config.CustomerContactTransientKey, keeping them out of the request arguments recorded on the
+// ledger. Fields left out are not changed.
+type CustomerContact struct {
+	Email   *string `json:"email,omitempty"`
+	Phone   *string `json:"phone,omitempty"`
+	Address *string `json:"address,omitempty"`
+}
+
+// IsEmpty reports whether the contact sets no field
+func (c CustomerContact) IsEmpty() bool {
+	return c.Email == nil && c.Phone == nil && c.Address == nil
+}
+
+// EncryptableField returns the customer's plaintext value of an encrypted field, or nil for a field
+// that is not encrypted
+func (c *Customer) EncryptableField(field string) *string {
+	switch field {
+	case "email":
+		return &c.Email
+	case "phone":
+		return &c.Phone
+	case "address":
+		return &c.Address
+	default:
+		return nil
+	}
+}
+
+// IsSealed reports whether a field is stored encrypted
+func (c *Customer) IsSealed(field string) bool {
+	_, ok := c.EncryptedFields[field]
+	return ok
+}
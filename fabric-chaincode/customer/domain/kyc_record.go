@@ -18,6 +18,7 @@ type KYCRecord struct {
 	VerifiedBy      string                `json:"verifiedBy"`
 	CreatedDate     time.Time             `json:"createdDate"`
 	LastUpdated     time.Time             `json:"lastUpdated"`
+	Version         int                   `json:"version"` // Advanced on every write; status updates may name it as expectedVersion
 }
 
 // AMLRecord represents an AML check record
@@ -30,8 +31,20 @@ type AMLRecord struct {
 	Flags           []string             `json:"flags"`
 	CheckedBy       string               `json:"checkedBy"`
 	Notes           string               `json:"notes"`
+	ScreenedOwners  []OwnerScreening     `json:"screenedOwners,omitempty"` // Organizations only
 	CreatedDate     time.Time            `json:"createdDate"`
 	LastUpdated     time.Time            `json:"lastUpdated"`
+	Version         int                  `json:"version"` // Advanced on every write; status updates may name it as expectedVersion
+}
+
+// OwnerScreening is the PEP screening of one beneficial owner during an organization's AML check.
+// Owners are named by customer ID only, keeping their PII off the AML record.
+type OwnerScreening struct {
+	CustomerID          string  `json:"customerID"`
+	EffectivePercentage float64 `json:"effectivePercentage"`
+	HasControl          bool    `json:"hasControl"`
+	IsMatch             bool    `json:"isMatch"`
+	MatchConfidence     float64 `json:"matchConfidence"`
 }
 
 // KYCInitiationRequest represents a KYC initiation request
@@ -46,6 +59,7 @@ type KYCStatusUpdateRequest struct {
 	KYCID             string               `json:"kycID"`
 	NewStatus         validation.KYCStatus `json:"newStatus"`
 	VerificationNotes string               `json:"verificationNotes"`
+	ExpectedVersion   *int                 `json:"expectedVersion,omitempty"` // Rejected with CONFLICT unless the record is still at this version
 	ActorID           string               `json:"actorID"`
 }
 
@@ -61,6 +75,7 @@ type AMLStatusUpdateRequest struct {
 	NewStatus validation.AMLStatus `json:"newStatus"`
 	RiskScore float64              `json:"riskScore"`
 	Flags     []string             `json:"flags"`
-	Notes     string               `json:"notes"`
-	ActorID   string               `json:"actorID"`
+	Notes           string               `json:"notes"`
+	ExpectedVersion *int                 `json:"expectedVersion,omitempty"` // Rejected with CONFLICT unless the record is still at this version
+	ActorID         string               `json:"actorID"`
 }
\ No newline at end of file
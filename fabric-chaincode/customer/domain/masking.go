@@ -0,0 +1,66 @@
+package domain
+
+import (
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/masking"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// customerContactPolicy shows contact details in full and masks the national ID
+var customerContactPolicy = masking.Policy{
+	"nationalID": {Strategy: masking.StrategyNationalID, Reveal: 4},
+}
+
+// customerSummaryPolicy shows name, status and masked contact details only
+var customerSummaryPolicy = masking.Policy{
+	"nationalID":         {Strategy: masking.StrategyNationalID, Reveal: 4},
+	"email":              {Strategy: masking.StrategyEmail, Reveal: 1},
+	"phone":              {Strategy: masking.StrategyPhone, Reveal: 4},
+	"address":            {Strategy: masking.StrategyOmit},
+	"dateOfBirth":        {Strategy: masking.StrategyOmit},
+	"consentPreferences": {Strategy: masking.StrategyOmit},
+}
+
+// CustomerMaskingPolicies masks customer listings and searches by actor role; roles not listed get
+// the summary policy
+var CustomerMaskingPolicies = masking.NewPolicySet(customerSummaryPolicy, map[validation.ActorRole]masking.Policy{
+	validation.ActorRoleComplianceOfficer:      {},
+	validation.ActorRoleChiefComplianceOfficer: {},
+	validation.ActorRoleRegulator:              {},
+	validation.ActorRoleUnderwriter:            customerContactPolicy,
+	validation.ActorRoleCreditOfficer:          customerContactPolicy,
+	validation.ActorRoleCustomerServiceRep:     customerContactPolicy,
+	validation.ActorRoleLoanOperationsManager:  customerContactPolicy,
+})
+
+// customer360ComplianceSections are the sections of the customer 360 view that tip off an AML or
+// sanctions investigation, seen only by compliance, risk and regulators
+var customer360ComplianceSections = []string{"latestAMLCheck", "riskTier", "openComplianceEvents", "screenings"}
+
+// Customer360MaskingPolicies redacts the customer 360 view by actor role. Lending staff see
+// screening outcomes but not the alerts under investigation; customer service sees contact,
+// consent, KYC and loan details only; roles not listed get the summary profile alone.
+var Customer360MaskingPolicies = masking.NewPolicySet(
+	withOmitted(customerSummaryPolicy, append(customer360ComplianceSections, "consents", "latestKYC", "loanApplications")...),
+	map[validation.ActorRole]masking.Policy{
+		validation.ActorRoleComplianceOfficer:      {},
+		validation.ActorRoleChiefComplianceOfficer: {},
+		validation.ActorRoleRegulator:              {},
+		validation.ActorRoleRiskAnalyst:            customerContactPolicy,
+		validation.ActorRoleUnderwriter:            withOmitted(customerContactPolicy, "openComplianceEvents"),
+		validation.ActorRoleCreditOfficer:          withOmitted(customerContactPolicy, "openComplianceEvents"),
+		validation.ActorRoleLoanOperationsManager:  withOmitted(customerContactPolicy, "openComplianceEvents"),
+		validation.ActorRoleCustomerServiceRep:     withOmitted(customerContactPolicy, customer360ComplianceSections...),
+	},
+)
+
+// withOmitted returns a copy of a policy that also omits the given fields
+func withOmitted(policy masking.Policy, fields ...string) masking.Policy {
+	combined := masking.Policy{}
+	for field, rule := range policy {
+		combined[field] = rule
+	}
+	for _, field := range fields {
+		combined[field] = masking.Rule{Strategy: masking.StrategyOmit}
+	}
+	return combined
+}
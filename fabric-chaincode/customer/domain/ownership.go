@@ -0,0 +1,160 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// ControlType is how an owner holds its interest in, or control of, an organization
+type ControlType string
+
+const (
+	ControlTypeShareholding     ControlType = "SHAREHOLDING"
+	ControlTypeVotingRights     ControlType = "VOTING_RIGHTS"
+	ControlTypeBoardAppointment ControlType = "BOARD_APPOINTMENT" // Right to appoint or remove a majority of the board
+	ControlTypeOtherMeans       ControlType = "OTHER_MEANS"       // Control by agreement, trust or other arrangement
+)
+
+// ValidControlTypes lists the control types an ownership link may record
+var ValidControlTypes = map[ControlType]bool{
+	ControlTypeShareholding:     true,
+	ControlTypeVotingRights:     true,
+	ControlTypeBoardAppointment: true,
+	ControlTypeOtherMeans:       true,
+}
+
+// HasPercentage reports whether the control type is held as a percentage of the organization.
+// Board appointment and other means confer control outright and carry no percentage.
+func (ct ControlType) HasPercentage() bool {
+	return ct == ControlTypeShareholding || ct == ControlTypeVotingRights
+}
+
+// OwnershipLink records that one customer, an individual or another organization, owns or
+// controls an organization. An owner holds at most one link to each organization.
+type OwnershipLink struct {
+	OrganizationID string       `json:"organizationID"`
+	OwnerID        string       `json:"ownerID"`
+	OwnerType      CustomerType `json:"ownerType"`
+	ControlType    ControlType  `json:"controlType"`
+	Percentage     float64      `json:"percentage"` // Shareholding and voting rights only
+	RecordedBy     string       `json:"recordedBy"`
+	RecordedDate   time.Time    `json:"recordedDate"`
+	TransactionID  string       `json:"transactionID"`
+}
+
+// Interest returns the fraction of the organization the link carries, 0 for control types held
+// without a percentage
+func (l *OwnershipLink) Interest() float64 {
+	if !l.ControlType.HasPercentage() {
+		return 0
+	}
+	return l.Percentage / 100
+}
+
+// ConfersControl reports whether the owner controls the organization through this link alone: by
+// holding a majority of its shares or votes, or by a control type that confers control outright
+func (l *OwnershipLink) ConfersControl() bool {
+	if l.ControlType.HasPercentage() {
+		return l.Percentage > 50
+	}
+	return true
+}
+
+// OwnershipRequest represents a request to record an owner of an organization
+type OwnershipRequest struct {
+	CustomerID  string      `json:"customerID"` // The organization owned
+	OwnerID     string      `json:"ownerID"`
+	ControlType ControlType `json:"controlType"`
+	Percentage  float64     `json:"percentage,omitempty"`
+	ActorID     string      `json:"actorID"`
+}
+
+// OwnershipRemovalRequest represents a request to remove an owner of an organization
+type OwnershipRemovalRequest struct {
+	CustomerID string `json:"customerID"`
+	OwnerID    string `json:"ownerID"`
+	Reason     string `json:"reason"`
+	ActorID    string `json:"actorID"`
+}
+
+// OwnershipStructure lists an organization's direct owners
+type OwnershipStructure struct {
+	CustomerID        string          `json:"customerID"`
+	LegalName         string          `json:"legalName"`
+	Owners            []OwnershipLink `json:"owners"`
+	TotalShareholding float64         `json:"totalShareholding"` // Percentage of shares with a recorded owner
+	TotalVotingRights float64         `json:"totalVotingRights"`
+}
+
+// BeneficialOwner is an individual who ultimately owns or controls an organization
+type BeneficialOwner struct {
+	CustomerID          string        `json:"customerID"`
+	Name                string        `json:"name,omitempty"`
+	EffectivePercentage float64       `json:"effectivePercentage"` // Interest held through every chain, multiplied down each
+	ControlTypes        []ControlType `json:"controlTypes"`        // How the individual holds their own links
+	HasControl          bool          `json:"hasControl"`          // Controls the organization through a chain of controlling links
+	Chains              [][]string    `json:"chains"`              // Customer IDs from the individual down to the organization
+}
+
+// BeneficialOwnership is the resolution of an organization's ownership graph to the individuals
+// at its top. Beneficial owners hold an effective interest at or above the threshold or control
+// the organization through a chain of controlling links.
+type BeneficialOwnership struct {
+	CustomerID       string            `json:"customerID"`
+	Threshold        float64           `json:"threshold"`
+	BeneficialOwners []BeneficialOwner `json:"beneficialOwners"`
+	// Individuals found in the graph below the threshold and without control
+	OtherOwners []BeneficialOwner `json:"otherOwners"`
+	// Organizations in the graph with no recorded owners, whose own owners are unknown
+	UnresolvedOrganizations []string `json:"unresolvedOrganizations"`
+}
+
+// ControlledEntity is an organization an individual ultimately owns or controls
+type ControlledEntity struct {
+	CustomerID          string        `json:"customerID"`
+	LegalName           string        `json:"legalName"`
+	EffectivePercentage float64       `json:"effectivePercentage"`
+	ControlTypes        []ControlType `json:"controlTypes"`
+	HasControl          bool          `json:"hasControl"`
+}
+
+// ControlledEntitiesResult lists the organizations an individual is a beneficial owner of
+type ControlledEntitiesResult struct {
+	CustomerID string             `json:"customerID"`
+	Entities   []ControlledEntity `json:"entities"`
+	Count      int                `json:"count"`
+}
+
+// ValidateOwnershipRequest validates a request to record an owner of an organization
+func ValidateOwnershipRequest(req *OwnershipRequest) error {
+	var errors []string
+
+	if strings.TrimSpace(req.CustomerID) == "" {
+		errors = append(errors, "customerID is required")
+	}
+	if strings.TrimSpace(req.OwnerID) == "" {
+		errors = append(errors, "ownerID is required")
+	}
+	if strings.TrimSpace(req.ActorID) == "" {
+		errors = append(errors, "actorID is required")
+	}
+	if req.CustomerID != "" && req.CustomerID == req.OwnerID {
+		errors = append(errors, "an organization cannot own itself")
+	}
+
+	if !ValidControlTypes[req.ControlType] {
+		errors = append(errors, fmt.Sprintf("controlType: invalid control type %s", req.ControlType))
+	} else if req.ControlType.HasPercentage() {
+		if req.Percentage <= 0 || req.Percentage > 100 {
+			errors = append(errors, "percentage must be greater than 0 and at most 100")
+		}
+	} else if req.Percentage != 0 {
+		errors = append(errors, fmt.Sprintf("percentage does not apply to %s", req.ControlType))
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("validation errors: %s", strings.Join(errors, ", "))
+	}
+	return nil
+}
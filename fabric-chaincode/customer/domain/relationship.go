@@ -0,0 +1,169 @@
+package domain
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// RelationshipType is the kind of link between two customers
+type RelationshipType string
+
+const (
+	RelationshipJointAccountHolder  RelationshipType = "JOINT_ACCOUNT_HOLDER"
+	RelationshipHouseholdMember     RelationshipType = "HOUSEHOLD_MEMBER"
+	RelationshipSpouse              RelationshipType = "SPOUSE"
+	RelationshipGuardian            RelationshipType = "GUARDIAN"             // The customer is guardian of the related customer
+	RelationshipPowerOfAttorney     RelationshipType = "POWER_OF_ATTORNEY"    // The customer holds power of attorney for the related customer
+	RelationshipAuthorizedSignatory RelationshipType = "AUTHORIZED_SIGNATORY" // The customer signs for the related customer, e.g. an organization
+)
+
+// RelationshipDirection says whether a relationship reads the same from both customers
+type RelationshipDirection string
+
+const (
+	RelationshipBidirectional RelationshipDirection = "BIDIRECTIONAL"
+	RelationshipDirected      RelationshipDirection = "DIRECTED" // From the customer to the related customer
+)
+
+// RelationshipDirections gives the direction of each relationship type
+var RelationshipDirections = map[RelationshipType]RelationshipDirection{
+	RelationshipJointAccountHolder:  RelationshipBidirectional,
+	RelationshipHouseholdMember:     RelationshipBidirectional,
+	RelationshipSpouse:              RelationshipBidirectional,
+	RelationshipGuardian:            RelationshipDirected,
+	RelationshipPowerOfAttorney:     RelationshipDirected,
+	RelationshipAuthorizedSignatory: RelationshipDirected,
+}
+
+// RelationshipStatus is the state of a customer relationship
+type RelationshipStatus string
+
+const (
+	RelationshipStatusActive     RelationshipStatus = "ACTIVE"
+	RelationshipStatusTerminated RelationshipStatus = "TERMINATED"
+)
+
+// CustomerRelationship links two customers, such as the holders of a joint account or members of
+// a household. Terminated relationships are kept with the date they ended.
+type CustomerRelationship struct {
+	RelationshipID    string                `json:"relationshipID"`
+	CustomerID        string                `json:"customerID"`
+	RelatedCustomerID string                `json:"relatedCustomerID"`
+	RelationshipType  RelationshipType      `json:"relationshipType"`
+	Direction         RelationshipDirection `json:"direction"`
+	Status            RelationshipStatus    `json:"status"`
+	EffectiveFrom     time.Time             `json:"effectiveFrom"`
+	EffectiveTo       *time.Time            `json:"effectiveTo,omitempty"` // Set on termination, or up front for fixed-term relationships
+	CreatedBy         string                `json:"createdBy"`
+	CreatedDate       time.Time             `json:"createdDate"`
+	TerminatedBy      string                `json:"terminatedBy,omitempty"`
+	TerminationReason string                `json:"terminationReason,omitempty"`
+	TransactionID     string                `json:"transactionID"`
+}
+
+// InEffect reports whether the relationship held at an instant
+func (r *CustomerRelationship) InEffect(at time.Time) bool {
+	if at.Before(r.EffectiveFrom) {
+		return false
+	}
+	return r.EffectiveTo == nil || at.Before(*r.EffectiveTo)
+}
+
+// Counterparty returns the other customer in the relationship
+func (r *CustomerRelationship) Counterparty(customerID string) string {
+	if r.CustomerID == customerID {
+		return r.RelatedCustomerID
+	}
+	return r.CustomerID
+}
+
+// Links reports whether the relationship joins the same two customers under the same type,
+// reading bidirectional relationships either way round
+func (r *CustomerRelationship) Links(customerID, relatedCustomerID string, relationshipType RelationshipType) bool {
+	if r.RelationshipType != relationshipType {
+		return false
+	}
+	if r.CustomerID == customerID && r.RelatedCustomerID == relatedCustomerID {
+		return true
+	}
+	return r.Direction == RelationshipBidirectional && r.CustomerID == relatedCustomerID && r.RelatedCustomerID == customerID
+}
+
+// RelationshipRequest represents a request to link two customers
+type RelationshipRequest struct {
+	CustomerID        string           `json:"customerID"`
+	RelatedCustomerID string           `json:"relatedCustomerID"`
+	RelationshipType  RelationshipType `json:"relationshipType"`
+	EffectiveFrom     *time.Time       `json:"effectiveFrom,omitempty"` // Defaults to now
+	EffectiveTo       *time.Time       `json:"effectiveTo,omitempty"`
+	ActorID           string           `json:"actorID"`
+}
+
+// RelationshipTerminationRequest represents a request to end a customer relationship
+type RelationshipTerminationRequest struct {
+	RelationshipID string     `json:"relationshipID"`
+	Reason         string     `json:"reason"`
+	EffectiveTo    *time.Time `json:"effectiveTo,omitempty"` // Defaults to now
+	ActorID        string     `json:"actorID"`
+}
+
+// CustomerRelationshipsResult lists every relationship a customer is party to, current or ended
+type CustomerRelationshipsResult struct {
+	CustomerID    string                 `json:"customerID"`
+	Relationships []CustomerRelationship `json:"relationships"`
+	Count         int                    `json:"count"`
+}
+
+// RelatedParty is a customer reached from another through relationships in effect, with the
+// compliance standing an investigation or risk assessment weighs
+type RelatedParty struct {
+	CustomerID        string               `json:"customerID"`
+	Depth             int                  `json:"depth"` // 1 for direct relationships
+	RelationshipIDs   []string             `json:"relationshipIDs"` // Path from the customer, shortest first found
+	RelationshipTypes []RelationshipType   `json:"relationshipTypes"`
+	AMLStatus         validation.AMLStatus `json:"amlStatus,omitempty"` // Latest AML check; empty when never checked
+	RiskTier          CustomerRiskTier     `json:"riskTier,omitempty"`
+}
+
+// Flagged reports whether the party's latest AML check is flagged or blocked
+func (p *RelatedParty) Flagged() bool {
+	return p.AMLStatus == validation.AMLStatusFlagged || p.AMLStatus == validation.AMLStatusBlocked
+}
+
+// RelatedPartiesResult lists the parties related to a customer as of an instant
+type RelatedPartiesResult struct {
+	CustomerID string         `json:"customerID"`
+	AsOf       time.Time      `json:"asOf"`
+	MaxDepth   int            `json:"maxDepth"`
+	Parties    []RelatedParty `json:"parties"`
+	Count      int            `json:"count"`
+}
+
+// ValidateRelationship validates a customer relationship
+func ValidateRelationship(relationship *CustomerRelationship) error {
+	var errors []string
+
+	if strings.TrimSpace(relationship.CustomerID) == "" {
+		errors = append(errors, "customerID is required")
+	}
+	if strings.TrimSpace(relationship.RelatedCustomerID) == "" {
+		errors = append(errors, "relatedCustomerID is required")
+	}
+	if relationship.CustomerID != "" && relationship.CustomerID == relationship.RelatedCustomerID {
+		errors = append(errors, "a customer cannot be related to themselves")
+	}
+	if _, ok := RelationshipDirections[relationship.RelationshipType]; !ok {
+		errors = append(errors, fmt.Sprintf("relationshipType: invalid relationship type %s", relationship.RelationshipType))
+	}
+	if relationship.EffectiveTo != nil && !relationship.EffectiveTo.After(relationship.EffectiveFrom) {
+		errors = append(errors, "effectiveTo must be after effectiveFrom")
+	}
+
+	if len(errors) > 0 {
+		return fmt.Errorf("validation errors: %s", strings.Join(errors, ", "))
+	}
+	return nil
+}
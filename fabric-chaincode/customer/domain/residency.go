@@ -0,0 +1,75 @@
+package domain
+
+import (
+	"time"
+)
+
+// CustomerPII holds the personal data of a customer with a residency. It is stored in the
+// residency's private data collection; the public customer record carries none of it.
+type CustomerPII struct {
+	FirstName   string    `json:"firstName"`
+	LastName    string    `json:"lastName"`
+	Email       string    `json:"email"`
+	Phone       string    `json:"phone"`
+	DateOfBirth time.Time `json:"dateOfBirth"`
+	NationalID  string    `json:"nationalID"`
+	Address     string    `json:"address"`
+}
+
+// PII returns the customer's personal data
+func (c *Customer) PII() *CustomerPII {
+	return &CustomerPII{
+		FirstName:   c.FirstName,
+		LastName:    c.LastName,
+		Email:       c.Email,
+		Phone:       c.Phone,
+		DateOfBirth: c.DateOfBirth,
+		NationalID:  c.NationalID,
+		Address:     c.Address,
+	}
+}
+
+// ApplyPII fills in the customer's personal data
+func (c *Customer) ApplyPII(pii *CustomerPII) {
+	c.FirstName = pii.FirstName
+	c.LastName = pii.LastName
+	c.Email = pii.Email
+	c.Phone = pii.Phone
+	c.DateOfBirth = pii.DateOfBirth
+	c.NationalID = pii.NationalID
+	c.Address = pii.Address
+}
+
+// Public returns the record written to the public ledger: the customer itself, or a copy without
+// personal data when the customer has a residency
+func (c *Customer) Public() *Customer {
+	if c.Residency == "" {
+		return c
+	}
+	public := *c
+	public.ApplyPII(&CustomerPII{})
+	return &public
+}
+
+// CrossResidencyAccessRequest represents a request to read a customer's PII from outside their
+// residency under a documented legal basis
+type CrossResidencyAccessRequest struct {
+	CustomerID    string `json:"customerID"`
+	LegalBasis    string `json:"legalBasis"`
+	Justification string `json:"justification"`
+	ActorID       string `json:"actorID"`
+}
+
+// CrossResidencyAccess records a read of a customer's PII from outside their residency
+type CrossResidencyAccess struct {
+	CustomerID      string    `json:"customerID"`
+	Residency       string    `json:"residency"`
+	ReaderMSPID     string    `json:"readerMSPID"`
+	ReaderResidency string    `json:"readerResidency,omitempty"`
+	LegalBasis      string    `json:"legalBasis"`
+	Justification   string    `json:"justification"`
+	AccessedBy      string    `json:"accessedBy"`
+	InvokerID       string    `json:"invokerID"`
+	AccessDate      time.Time `json:"accessDate"`
+	TransactionID   string    `json:"transactionID"`
+}
@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"time"
+)
+
+// CustomerRiskTier is a band of customer risk scores
+type CustomerRiskTier string
+
+// Customer risk tiers, lowest first. Scores run 0-100, higher is riskier.
+const (
+	CustomerRiskLow      CustomerRiskTier = "LOW"       // Below 30
+	CustomerRiskMedium   CustomerRiskTier = "MEDIUM"    // 30 to below 60
+	CustomerRiskHigh     CustomerRiskTier = "HIGH"      // 60 to below 80
+	CustomerRiskVeryHigh CustomerRiskTier = "VERY_HIGH" // 80 and above
+)
+
+// CustomerRiskTiers lists the tiers in ascending order of risk
+var CustomerRiskTiers = []CustomerRiskTier{CustomerRiskLow, CustomerRiskMedium, CustomerRiskHigh, CustomerRiskVeryHigh}
+
+// riskReviewIntervalMonths is how soon each tier is due for its next periodic recalculation
+var riskReviewIntervalMonths = map[CustomerRiskTier]int{
+	CustomerRiskLow:      24,
+	CustomerRiskMedium:   12,
+	CustomerRiskHigh:     6,
+	CustomerRiskVeryHigh: 3,
+}
+
+// Rank returns the tier's position in CustomerRiskTiers, or -1 for an unknown tier
+func (t CustomerRiskTier) Rank() int {
+	for i, tier := range CustomerRiskTiers {
+		if tier == t {
+			return i
+		}
+	}
+	return -1
+}
+
+// NextReviewDate returns when a customer assessed at the given time in this tier is next due for
+// recalculation
+func (t CustomerRiskTier) NextReviewDate(assessed time.Time) time.Time {
+	return assessed.AddDate(0, riskReviewIntervalMonths[t], 0)
+}
+
+// CustomerRiskTierFor returns the tier a risk score falls in
+func CustomerRiskTierFor(score float64) CustomerRiskTier {
+	switch {
+	case score < 30:
+		return CustomerRiskLow
+	case score < 60:
+		return CustomerRiskMedium
+	case score < 80:
+		return CustomerRiskHigh
+	default:
+		return CustomerRiskVeryHigh
+	}
+}
+
+// RiskDriver is one factor contributing to a customer's risk score
+type RiskDriver struct {
+	Factor string  `json:"factor"` // AML, GEOGRAPHY, PRODUCT_HOLDINGS, TRANSACTIONS or RELATIONSHIPS
+	Code   string  `json:"code"`
+	Score  float64 `json:"score"` // Points added to the risk score
+	Detail string  `json:"detail"`
+}
+
+// CustomerRiskProfile is a customer's current risk rating and the drivers behind it
+type CustomerRiskProfile struct {
+	CustomerID       string           `json:"customerID"`
+	RiskScore        float64          `json:"riskScore"`
+	RiskTier         CustomerRiskTier `json:"riskTier"`
+	PreviousRiskTier CustomerRiskTier `json:"previousRiskTier,omitempty"` // Tier before the last recalculation; empty on first assessment
+	Drivers          []RiskDriver     `json:"drivers"`
+	AMLID            string           `json:"amlID,omitempty"` // AML check the rating was based on
+	LastAssessed     time.Time        `json:"lastAssessed"`
+	NextReviewDate   time.Time        `json:"nextReviewDate"`
+	AssessedBy       string           `json:"assessedBy"`
+	TransactionID    string           `json:"transactionID"`
+}
+
+// TierChanged reports whether the last recalculation moved the customer to a different tier
+func (p *CustomerRiskProfile) TierChanged() bool {
+	return p.PreviousRiskTier != p.RiskTier
+}
+
+// RiskRecalculationRequest represents a request to recalculate a customer's risk rating
+type RiskRecalculationRequest struct {
+	CustomerID string `json:"customerID"`
+	ActorID    string `json:"actorID"`
+}
+
+// RiskProfileQueryResult is one page of customers listed by risk tier
+type RiskProfileQueryResult struct {
+	Profiles []CustomerRiskProfile `json:"profiles"`
+	Count    int                   `json:"count"`
+	Bookmark string                `json:"bookmark"`
+}
@@ -3,27 +3,21 @@ package domain
 import (
 	"fmt"
 	"strings"
+	"time"
 	
 	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
 )
 
-// ValidateCustomer validates a customer entity
-func ValidateCustomer(customer *Customer) error {
+// ValidateCustomer validates a customer entity as of a time, which decides whether the customer
+// is of age
+func ValidateCustomer(customer *Customer, asOf time.Time) error {
 	var errors []string
 	
 	// Validate required fields
-	if strings.TrimSpace(customer.FirstName) == "" {
-		errors = append(errors, "firstName is required")
-	}
-	if strings.TrimSpace(customer.LastName) == "" {
-		errors = append(errors, "lastName is required")
-	}
-	if strings.TrimSpace(customer.Email) == "" {
+	errors = append(errors, identityFieldErrors(customer.CustomerType, customer.FirstName, customer.LastName, customer.NationalID, customer.LegalName, customer.RegistrationNumber)...)
+	if strings.TrimSpace(customer.Email) == "" && !customer.IsSealed("email") {
 		errors = append(errors, "email is required")
 	}
-	if strings.TrimSpace(customer.NationalID) == "" {
-		errors = append(errors, "nationalID is required")
-	}
 	
 	// Validate email format
 	if customer.Email != "" {
@@ -48,7 +42,7 @@ func ValidateCustomer(customer *Customer) error {
 	
 	// Validate date of birth
 	if !customer.DateOfBirth.IsZero() {
-		if err := validation.ValidateDateOfBirth(customer.DateOfBirth); err != nil {
+		if err := validation.ValidateDateOfBirth(customer.DateOfBirth, asOf); err != nil {
 			errors = append(errors, fmt.Sprintf("dateOfBirth: %v", err))
 		}
 	}
@@ -79,23 +73,15 @@ func ValidateCustomer(customer *Customer) error {
 	return nil
 }
 
-// ValidateCustomerRegistrationRequest validates a customer registration request
-func ValidateCustomerRegistrationRequest(req *CustomerRegistrationRequest) error {
+// ValidateCustomerRegistrationRequest validates a customer registration request as of a time
+func ValidateCustomerRegistrationRequest(req *CustomerRegistrationRequest, asOf time.Time) error {
 	var errors []string
 	
 	// Validate required fields
-	if strings.TrimSpace(req.FirstName) == "" {
-		errors = append(errors, "firstName is required")
-	}
-	if strings.TrimSpace(req.LastName) == "" {
-		errors = append(errors, "lastName is required")
-	}
+	errors = append(errors, identityFieldErrors(req.CustomerType, req.FirstName, req.LastName, req.NationalID, req.LegalName, req.RegistrationNumber)...)
 	if strings.TrimSpace(req.Email) == "" {
 		errors = append(errors, "email is required")
 	}
-	if strings.TrimSpace(req.NationalID) == "" {
-		errors = append(errors, "nationalID is required")
-	}
 	if strings.TrimSpace(req.ActorID) == "" {
 		errors = append(errors, "actorID is required")
 	}
@@ -120,7 +106,7 @@ func ValidateCustomerRegistrationRequest(req *CustomerRegistrationRequest) error
 	}
 	
 	if !req.DateOfBirth.IsZero() {
-		if err := validation.ValidateDateOfBirth(req.DateOfBirth); err != nil {
+		if err := validation.ValidateDateOfBirth(req.DateOfBirth, asOf); err != nil {
 			errors = append(errors, fmt.Sprintf("dateOfBirth: %v", err))
 		}
 	}
@@ -138,6 +124,35 @@ func ValidateCustomerRegistrationRequest(req *CustomerRegistrationRequest) error
 	return nil
 }
 
+// identityFieldErrors checks the fields identifying a customer of a type: the legal name and
+// registration number of an organization, otherwise the name and national ID of an individual.
+// An empty type is an individual.
+func identityFieldErrors(customerType CustomerType, firstName, lastName, nationalID, legalName, registrationNumber string) []string {
+	var errors []string
+	switch customerType {
+	case CustomerTypeOrganization:
+		if strings.TrimSpace(legalName) == "" {
+			errors = append(errors, "legalName is required")
+		}
+		if strings.TrimSpace(registrationNumber) == "" {
+			errors = append(errors, "registrationNumber is required")
+		}
+	case "", CustomerTypeIndividual:
+		if strings.TrimSpace(firstName) == "" {
+			errors = append(errors, "firstName is required")
+		}
+		if strings.TrimSpace(lastName) == "" {
+			errors = append(errors, "lastName is required")
+		}
+		if strings.TrimSpace(nationalID) == "" {
+			errors = append(errors, "nationalID is required")
+		}
+	default:
+		errors = append(errors, fmt.Sprintf("customerType: invalid customer type %s", customerType))
+	}
+	return errors
+}
+
 // ValidateKYCRecord validates a KYC record
 func ValidateKYCRecord(record *KYCRecord) error {
 	var errors []string
@@ -0,0 +1,112 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// GetCustomerAccessLog answers who read a customer's PII in a period, with each read and a summary
+// per identity. Args: customerID [, from [, to]], each bound an RFC 3339 timestamp or a date, to
+// meaning the end of that UTC day; an empty or absent bound leaves the period open. Compliance
+// officers and regulators only.
+func (h *CustomerHandler) GetCustomerAccessLog(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) < 1 || len(args) > 3 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1 to 3, got %d", len(args))
+	}
+
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return nil, err
+	}
+	switch validation.ActorRole(role) {
+	case validation.ActorRoleComplianceOfficer, validation.ActorRoleChiefComplianceOfficer, validation.ActorRoleRegulator:
+	default:
+		return nil, services.NewChaincodeError(services.ErrCodeAccessDenied, "", "the customer access log may only be read by a %s, %s or %s", validation.ActorRoleComplianceOfficer, validation.ActorRoleChiefComplianceOfficer, validation.ActorRoleRegulator)
+	}
+
+	result := &domain.CustomerAccessLogResult{CustomerID: args[0]}
+	if len(args) > 1 && args[1] != "" {
+		from, err := parseAccessLogBound(args[1], false)
+		if err != nil {
+			return nil, err
+		}
+		result.From = &from
+	}
+	if len(args) > 2 && args[2] != "" {
+		to, err := parseAccessLogBound(args[2], true)
+		if err != nil {
+			return nil, err
+		}
+		result.To = &to
+	}
+	if result.From != nil && result.To != nil && result.To.Before(*result.From) {
+		return nil, fmt.Errorf("period ends before it starts")
+	}
+
+	result.Entries, err = h.accessLogService.ForCustomer(stub, args[0], result.From, result.To)
+	if err != nil {
+		return nil, err
+	}
+	result.Accessors = domain.SummariseAccessors(result.Entries)
+	result.Count = len(result.Entries)
+
+	return json.Marshal(result)
+}
+
+// RecordAccess logs a read of a customer's PII through one of config.AccessLoggedFunctions, with
+// the purpose and actor in transient data as for the read itself. Those functions return the PII,
+// so clients evaluate them, which discards the entry each writes; submitting RecordAccess for the
+// read first commits the entry, and its response carries no PII. Args: customerID, function.
+func (h *CustomerHandler) RecordAccess(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 2, got %d", len(args))
+	}
+	customerID, function := args[0], args[1]
+	if !config.AccessLoggedFunctions[function] {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "function", "function %s does not read a customer's PII", function)
+	}
+
+	exists, err := h.persistenceService.Exists(stub, fmt.Sprintf("CUSTOMER_%s", customerID))
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, services.NewChaincodeError(services.ErrCodeNotFound, "", "customer %s not found", customerID)
+	}
+
+	entry, err := h.accessLogService.Record(stub, customerID, function)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(entry)
+}
+
+// recordAccess logs a read of a customer's PII through a function
+func (h *CustomerHandler) recordAccess(stub shim.ChaincodeStubInterface, customerID, function string) error {
+	_, err := h.accessLogService.Record(stub, customerID, function)
+	return err
+}
+
+// parseAccessLogBound parses a bound of an access log period. A date starts the period at the
+// start of the day, or ends it at the end of the day.
+func parseAccessLogBound(value string, end bool) (time.Time, error) {
+	bound, err := time.Parse(time.RFC3339, value)
+	if err == nil {
+		return bound, nil
+	}
+	date, err := time.Parse("2006-01-02", value)
+	if err != nil {
+		return time.Time{}, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid period bound %s: expected RFC 3339 or YYYY-MM-DD", value)
+	}
+	if end {
+		return date.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+	}
+	return date, nil
+}
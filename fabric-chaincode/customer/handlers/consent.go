@@ -0,0 +1,361 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// RecordConsent grants or withdraws a customer's consent to one purpose. The change is added as
+// the purpose's next consent record and mirrored into the customer's consent preferences.
+func (h *CustomerHandler) RecordConsent(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.ConsentRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse consent request: %v", err)
+	}
+	if strings.TrimSpace(req.Purpose) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "purpose", "purpose is required")
+	}
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	existingCustomer, err := h.getCustomer(stub, req.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+
+	latest, err := h.consentService.Latest(stub, req.CustomerID, req.Purpose)
+	if err != nil {
+		return nil, err
+	}
+	if latest != nil && latest.Granted == req.Granted {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "consent %s is already %s for customer %s", req.Purpose, consentState(req.Granted), req.CustomerID)
+	}
+
+	if err := h.setConsentPreferences(stub, existingCustomer, []string{req.Purpose}, req.Granted, req.ActorID); err != nil {
+		return nil, err
+	}
+
+	records, suspensions, err := h.applyConsent(stub, req.CustomerID, []string{req.Purpose}, req.Granted, domain.ConsentSourceDirect, req.Evidence, req.ActorID)
+	if err != nil {
+		return nil, err
+	}
+	record := &records[0]
+
+	if len(suspensions) > 0 {
+		withdrawal := &domain.ConsentWithdrawal{CustomerID: req.CustomerID, Purposes: []string{req.Purpose}, Suspensions: suspensions}
+		if err := h.eventService.EmitConsentWithdrawn(stub, withdrawal, req.ActorID); err != nil {
+			return nil, fmt.Errorf("failed to emit event: %w", err)
+		}
+	} else if err := h.eventService.EmitConsentRecorded(stub, record, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(record)
+}
+
+// RenewConsent extends a customer's current grant of a purpose for another validity period. The
+// renewal is added as the next consent record, so the grant it renews stays on the ledger.
+func (h *CustomerHandler) RenewConsent(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.ConsentRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse consent request: %v", err)
+	}
+	if strings.TrimSpace(req.Purpose) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "purpose", "purpose is required")
+	}
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	if _, err := h.getCustomer(stub, req.CustomerID); err != nil {
+		return nil, err
+	}
+
+	latest, err := h.consentService.Latest(stub, req.CustomerID, req.Purpose)
+	if err != nil {
+		return nil, err
+	}
+	if latest == nil || !latest.Granted {
+		return nil, fmt.Errorf("consent %s is not granted for customer %s; grant it with RecordConsent", req.Purpose, req.CustomerID)
+	}
+
+	record, err := h.consentService.Record(stub, req.CustomerID, req.Purpose, true, domain.ConsentSourceRenewal, req.Evidence, req.ActorID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.eventService.EmitConsentRecorded(stub, record, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(record)
+}
+
+// GetExpiringConsents returns the consent grants expiring within a number of days, soonest
+// first, including any already past expiry that the expiry process has not yet lapsed
+func (h *CustomerHandler) GetExpiringConsents(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	withinDays, err := strconv.Atoi(args[0])
+	if err != nil || withinDays < 0 || withinDays > config.MaxQueryRangeDays {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid withinDays %s: must be between 0 and %d", args[0], config.MaxQueryRangeDays)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	until := now.AddDate(0, 0, withinDays)
+
+	entries, err := h.consentService.GetExpiring(stub, until)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&domain.ExpiringConsentsResult{Until: until, Consents: entries, Count: len(entries)})
+}
+
+// ProcessConsentExpiries lapses the consent grants past expiry and announces those expiring
+// within the notice period that have not been announced before. A lapse is recorded as a
+// withdrawal, mirrored into the customer's consent preferences, and suspends the data-sharing
+// clauses relying on it. At most config.MaxPageSize grants are lapsed or announced per run; the
+// report says whether another run is needed.
+func (h *CustomerHandler) ProcessConsentExpiries(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.ConsentExpiryRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse consent expiry request: %v", err)
+	}
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+	if req.NoticeDays < 0 || req.NoticeDays > config.MaxQueryRangeDays {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "noticeDays must be between 0 and %d", config.MaxQueryRangeDays)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	entries, err := h.consentService.GetExpiring(stub, now.AddDate(0, 0, req.NoticeDays))
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.ConsentExpiryReport{
+		Expired:       []domain.ConsentExpiryEntry{},
+		Expiring:      []domain.ConsentExpiryEntry{},
+		Suspensions:   []domain.ClauseSuspension{},
+		Complete:      true,
+		ProcessedBy:   req.ActorID,
+		ProcessedDate: now,
+	}
+
+	// A customer's lapsed purposes are applied together so their consent preferences are
+	// rewritten once
+	lapsed := make(map[string][]string)
+	customerIDs := []string{}
+	processed := 0
+	for _, entry := range entries {
+		expired := !entry.ExpiryDate.After(now)
+		if !expired && entry.NotifiedDate != nil {
+			continue
+		}
+		if processed == config.MaxPageSize {
+			report.Complete = false
+			break
+		}
+		processed++
+
+		if !expired {
+			entry.NotifiedDate = &now
+			if err := h.consentService.PutExpiryEntry(stub, &entry); err != nil {
+				return nil, err
+			}
+			report.Expiring = append(report.Expiring, entry)
+			continue
+		}
+
+		if _, ok := lapsed[entry.CustomerID]; !ok {
+			customerIDs = append(customerIDs, entry.CustomerID)
+		}
+		lapsed[entry.CustomerID] = append(lapsed[entry.CustomerID], entry.Purpose)
+		report.Expired = append(report.Expired, entry)
+	}
+
+	for _, customerID := range customerIDs {
+		customer, err := h.getCustomer(stub, customerID)
+		if err != nil {
+			return nil, err
+		}
+		if err := h.setConsentPreferences(stub, customer, lapsed[customerID], false, req.ActorID); err != nil {
+			return nil, err
+		}
+		_, suspensions, err := h.applyConsent(stub, customerID, lapsed[customerID], false, domain.ConsentSourceExpiry, "", req.ActorID)
+		if err != nil {
+			return nil, err
+		}
+		report.Suspensions = append(report.Suspensions, suspensions...)
+	}
+
+	if processed > 0 {
+		if err := h.eventService.EmitConsentExpiry(stub, report, req.ActorID); err != nil {
+			return nil, fmt.Errorf("failed to emit event: %w", err)
+		}
+	}
+
+	return json.Marshal(report)
+}
+
+// GetConsentHistory returns a page of a customer's consent records, oldest first within each
+// purpose. An optional purpose narrows the history to that purpose.
+func (h *CustomerHandler) GetConsentHistory(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) < 1 || len(args) > 4 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1 to 4, got %d", len(args))
+	}
+
+	purpose := ""
+	if len(args) > 1 {
+		purpose = args[1]
+	}
+	pageArgs := []string{}
+	if len(args) > 2 {
+		pageArgs = args[2:]
+	}
+	pageSize, bookmark, err := services.ParsePageArgs(pageArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.recordAccess(stub, args[0], "GetConsentHistory"); err != nil {
+		return nil, err
+	}
+
+	history, err := h.consentService.GetHistory(stub, args[0], purpose, pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(history)
+}
+
+// GetConsentAt reports whether a customer had consented to a purpose at an instant, given as an
+// RFC 3339 timestamp or as a date meaning the end of that UTC day
+func (h *CustomerHandler) GetConsentAt(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 3 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 3, got %d", len(args))
+	}
+
+	asOf, err := time.Parse(time.RFC3339, args[2])
+	if err != nil {
+		date, dateErr := time.Parse("2006-01-02", args[2])
+		if dateErr != nil {
+			return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid as-of time %s: expected RFC 3339 or YYYY-MM-DD", args[2])
+		}
+		asOf = date.AddDate(0, 0, 1).Add(-time.Nanosecond)
+	}
+
+	if err := h.recordAccess(stub, args[0], "GetConsentAt"); err != nil {
+		return nil, err
+	}
+
+	record, err := h.consentService.At(stub, args[0], args[1], asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &domain.ConsentStatus{
+		CustomerID: args[0],
+		Purpose:    args[1],
+		AsOf:       asOf,
+		Granted:    record != nil && record.Granted,
+		Record:     record,
+	}
+	return json.Marshal(status)
+}
+
+// applyConsent records a consent record for each purpose and suspends or reinstates the
+// data-sharing clauses relying on them. It returns the records and the clauses a withdrawal
+// suspended.
+func (h *CustomerHandler) applyConsent(stub shim.ChaincodeStubInterface, customerID string, purposes []string, granted bool, source, evidence, actorID string) ([]domain.ConsentRecord, []domain.ClauseSuspension, error) {
+	records := []domain.ConsentRecord{}
+	for _, purpose := range purposes {
+		record, err := h.consentService.Record(stub, customerID, purpose, granted, source, evidence, actorID)
+		if err != nil {
+			return nil, nil, err
+		}
+		records = append(records, *record)
+	}
+
+	if granted {
+		return records, nil, h.dataSharingService.ReinstateClauses(stub, customerID, purposes)
+	}
+	suspensions, err := h.dataSharingService.SuspendClauses(stub, customerID, purposes, actorID)
+	return records, suspensions, err
+}
+
+// setConsentPreferences grants or withdraws purposes in a customer's consent preferences,
+// recording the change in the customer's history
+func (h *CustomerHandler) setConsentPreferences(stub shim.ChaincodeStubInterface, existingCustomer *domain.Customer, purposes []string, granted bool, actorID string) error {
+	consentPreferences := existingCustomer.ConsentPreferences
+	for _, purpose := range purposes {
+		var err error
+		consentPreferences, err = domain.SetConsent(consentPreferences, purpose, granted)
+		if err != nil {
+			return err
+		}
+	}
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return err
+	}
+
+	updatedCustomer := *existingCustomer
+	updatedCustomer.ConsentPreferences = consentPreferences
+	updatedCustomer.LastUpdated = now
+	updatedCustomer.LastUpdatedBy = actorID
+	if err := domain.ValidateCustomer(&updatedCustomer, now); err != nil {
+		return fmt.Errorf("updated customer validation failed: %w", err)
+	}
+
+	if err := h.recordCustomerHistory(stub, existingCustomer.CustomerID, "UPDATE", "consentPreferences", existingCustomer.ConsentPreferences, consentPreferences, actorID); err != nil {
+		return err
+	}
+	if err := putCustomer(stub, h.persistenceService, h.residencyService, &updatedCustomer); err != nil {
+		return fmt.Errorf("failed to update customer: %w", err)
+	}
+	if updatedCustomer.Residency != "" {
+		if err := h.residencyService.PutPII(stub, &updatedCustomer); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// consentState names a consent state for messages
+func consentState(granted bool) string {
+	if granted {
+		return "granted"
+	}
+	return "withdrawn"
+}
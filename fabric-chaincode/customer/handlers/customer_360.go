@@ -0,0 +1,118 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/interfaces"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// closedEventStatuses are the resolution statuses of compliance events no longer open
+var closedEventStatuses = map[string]bool{
+	"RESOLVED": true,
+	"CLOSED":   true,
+}
+
+// GetCustomer360 returns a customer's profile, current consents, latest KYC record and AML check,
+// risk tier, open compliance events, screenings and loan applications in one response. The
+// compliance and loan sections are read from those chaincodes; the router redacts the response
+// for the invoker's role.
+func (h *CustomerHandler) GetCustomer360(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	customer, err := h.getCustomer(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	if err := h.recordAccess(stub, customer.CustomerID, "GetCustomer360"); err != nil {
+		return nil, err
+	}
+	if err := h.openCustomerPII(stub, customer); err != nil {
+		return nil, err
+	}
+	customerID := customer.CustomerID
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	view := &domain.Customer360{
+		CustomerID:           customerID,
+		Profile:              *customer,
+		Consents:             []domain.ConsentStatus{},
+		OpenComplianceEvents: []interfaces.ComplianceEventSummary{},
+		GeneratedDate:        now,
+	}
+
+	for _, purpose := range domain.ConsentPurposes(customer.ConsentPreferences) {
+		record, err := h.consentService.Latest(stub, customerID, purpose)
+		if err != nil {
+			return nil, err
+		}
+		view.Consents = append(view.Consents, domain.ConsentStatus{
+			CustomerID: customerID,
+			Purpose:    purpose,
+			AsOf:       now,
+			Granted:    record != nil && record.Granted,
+			Record:     record,
+		})
+	}
+
+	kycID, err := stub.GetState(fmt.Sprintf("CUSTOMER_KYC_%s", customerID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer KYC index: %w", err)
+	}
+	if kycID != nil {
+		var kycRecord domain.KYCRecord
+		if err := h.persistenceService.Get(stub, fmt.Sprintf("KYC_%s", string(kycID)), &kycRecord); err != nil {
+			return nil, fmt.Errorf("KYC record not found: %w", err)
+		}
+		view.LatestKYC = &kycRecord
+	}
+
+	amlID, err := stub.GetState(fmt.Sprintf("CUSTOMER_AML_%s", customerID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer AML index: %w", err)
+	}
+	if amlID != nil {
+		var amlRecord domain.AMLRecord
+		if err := h.persistenceService.Get(stub, fmt.Sprintf("AML_%s", string(amlID)), &amlRecord); err != nil {
+			return nil, fmt.Errorf("AML record not found: %w", err)
+		}
+		view.LatestAMLCheck = &amlRecord
+	}
+
+	profile, err := h.riskRatingService.GetProfile(stub, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if profile != nil {
+		view.RiskTier = string(profile.RiskTier)
+	}
+
+	// Cross-chaincode reads
+	events, err := h.activityService.GetComplianceEvents(stub, customerID)
+	if err != nil {
+		return nil, err
+	}
+	for _, event := range events {
+		if !closedEventStatuses[event.ResolutionStatus] {
+			view.OpenComplianceEvents = append(view.OpenComplianceEvents, event)
+		}
+	}
+
+	if view.Screenings, err = h.activityService.GetScreenings(stub, customerID); err != nil {
+		return nil, err
+	}
+	if view.LoanApplications, err = h.activityService.GetLoanApplications(stub, customerID); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(view)
+}
@@ -0,0 +1,257 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	customerServices "github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// DataSharingHandler handles data-sharing agreements with partner organisations and the log of
+// customer data disclosed under them
+type DataSharingHandler struct {
+	persistenceService *services.PersistenceService
+	dataSharingService *customerServices.DataSharingService
+	consentService     *customerServices.ConsentService
+	eventService       *customerServices.EventService
+}
+
+// NewDataSharingHandler creates a new data-sharing handler
+func NewDataSharingHandler() *DataSharingHandler {
+	return &DataSharingHandler{
+		persistenceService: services.NewPersistenceService(),
+		dataSharingService: customerServices.NewDataSharingService(),
+		consentService:     customerServices.NewConsentService(),
+		eventService:       customerServices.NewEventService(),
+	}
+}
+
+// CreateDataSharingAgreement records an agreement with a partner organisation. Each clause names
+// the consent purpose it relies on, which customers must have granted before data is disclosed
+// under it.
+func (h *DataSharingHandler) CreateDataSharingAgreement(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.DataSharingAgreementRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse data-sharing agreement request: %v", err)
+	}
+
+	if strings.TrimSpace(req.PartnerMSP) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "partnerMSP", "partnerMSP is required")
+	}
+	if req.PartnerMSP == config.BankMSPID {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "partnerMSP must be an organisation other than %s", config.BankMSPID)
+	}
+	if len(req.Clauses) == 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "at least one clause is required")
+	}
+	clauseIDs := make(map[string]bool)
+	for _, clause := range req.Clauses {
+		if strings.TrimSpace(clause.ClauseID) == "" {
+			return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "clauseID", "clauseID is required")
+		}
+		if clauseIDs[clause.ClauseID] {
+			return nil, fmt.Errorf("duplicate clause %s", clause.ClauseID)
+		}
+		clauseIDs[clause.ClauseID] = true
+		if strings.TrimSpace(clause.Purpose) == "" {
+			return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "clause %s must name the consent purpose it relies on", clause.ClauseID)
+		}
+		if len(clause.DataCategories) == 0 {
+			return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "clause %s must list the data categories it covers", clause.ClauseID)
+		}
+	}
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return nil, err
+	}
+	switch validation.ActorRole(role) {
+	case validation.ActorRoleComplianceOfficer, validation.ActorRoleChiefComplianceOfficer:
+	default:
+		return nil, services.NewChaincodeError(services.ErrCodeAccessDenied, "", "data-sharing agreements may only be recorded by a %s or %s", validation.ActorRoleComplianceOfficer, validation.ActorRoleChiefComplianceOfficer)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	agreement := &domain.DataSharingAgreement{
+		AgreementID: services.GenerateDeterministicID(stub, config.DataSharingAgreementPrefix),
+		PartnerMSP:  req.PartnerMSP,
+		PartnerName: req.PartnerName,
+		Clauses:     req.Clauses,
+		CreatedDate: now,
+		CreatedBy:   req.ActorID,
+	}
+	if err := h.dataSharingService.PutAgreement(stub, agreement); err != nil {
+		return nil, err
+	}
+
+	if err := h.eventService.EmitDataSharingAgreementRecorded(stub, agreement, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(agreement)
+}
+
+// GetDataSharingAgreement retrieves a data-sharing agreement
+func (h *DataSharingHandler) GetDataSharingAgreement(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	agreement, err := h.dataSharingService.GetAgreement(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(agreement)
+}
+
+// RecordDisclosure logs customer data disclosed to a partner under an agreement clause. It is
+// refused while the customer has not granted the clause's purpose, including after they withdraw it.
+func (h *DataSharingHandler) RecordDisclosure(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.DisclosureRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse disclosure request: %v", err)
+	}
+
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	mspID, err := services.InvokerMSPID(stub)
+	if err != nil {
+		return nil, err
+	}
+	if mspID != config.BankMSPID {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "disclosures must be recorded by %s, invoker is %s", config.BankMSPID, mspID)
+	}
+
+	agreement, err := h.dataSharingService.GetAgreement(stub, req.AgreementID)
+	if err != nil {
+		return nil, err
+	}
+	clause := agreement.Clause(req.ClauseID)
+	if clause == nil {
+		return nil, fmt.Errorf("agreement %s has no clause %s", req.AgreementID, req.ClauseID)
+	}
+	covered := make(map[string]bool)
+	for _, category := range clause.DataCategories {
+		covered[category] = true
+	}
+	if len(req.DataCategories) == 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "dataCategories", "dataCategories is required")
+	}
+	for _, category := range req.DataCategories {
+		if !covered[category] {
+			return nil, fmt.Errorf("clause %s does not cover data category %s", clause.ClauseID, category)
+		}
+	}
+
+	var customer domain.Customer
+	if err := h.persistenceService.Get(stub, fmt.Sprintf("CUSTOMER_%s", req.CustomerID), &customer); err != nil {
+		return nil, fmt.Errorf("customer not found: %w", err)
+	}
+
+	suspension, err := h.dataSharingService.GetSuspension(stub, req.CustomerID, agreement.AgreementID, clause.ClauseID)
+	if err != nil {
+		return nil, err
+	}
+	if suspension != nil && suspension.Active() {
+		return nil, fmt.Errorf("clause %s of agreement %s is suspended for customer %s since consent %s was withdrawn", clause.ClauseID, agreement.AgreementID, req.CustomerID, clause.Purpose)
+	}
+	if !domain.ConsentGranted(customer.ConsentPreferences, clause.Purpose) {
+		return nil, fmt.Errorf("customer %s has not granted consent %s", req.CustomerID, clause.Purpose)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	// A grant past expiry no longer covers disclosure, even before the expiry process lapses it
+	consent, err := h.consentService.Latest(stub, req.CustomerID, clause.Purpose)
+	if err != nil {
+		return nil, err
+	}
+	if consent != nil && consent.ExpiryDate != nil && now.After(*consent.ExpiryDate) {
+		return nil, fmt.Errorf("consent %s of customer %s expired on %s", clause.Purpose, req.CustomerID, consent.ExpiryDate.Format("2006-01-02"))
+	}
+
+	signature, err := services.GetRequestSignature(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	disclosure := &domain.DisclosureRecord{
+		DisclosureID:   stub.GetTxID(),
+		CustomerID:     req.CustomerID,
+		AgreementID:    agreement.AgreementID,
+		ClauseID:       clause.ClauseID,
+		Purpose:        clause.Purpose,
+		PartnerMSP:     agreement.PartnerMSP,
+		DataCategories: req.DataCategories,
+		DisclosedBy:    req.ActorID,
+		DisclosedDate:  now,
+		TransactionID:  stub.GetTxID(),
+
+		RequestSignature: signature,
+	}
+	if err := h.dataSharingService.PutDisclosure(stub, disclosure); err != nil {
+		return nil, err
+	}
+
+	if err := h.eventService.EmitDisclosureRecorded(stub, disclosure, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(disclosure)
+}
+
+// GetDisclosureLog returns every disclosure recorded for a customer
+func (h *DataSharingHandler) GetDisclosureLog(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	disclosures, err := h.dataSharingService.GetDisclosures(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(disclosures)
+}
+
+// GetClauseSuspensions returns the agreement clauses suspended for a customer, including those
+// since reinstated
+func (h *DataSharingHandler) GetClauseSuspensions(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	suspensions, err := h.dataSharingService.GetSuspensions(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(suspensions)
+}
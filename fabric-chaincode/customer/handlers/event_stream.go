@@ -0,0 +1,411 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	customerServices "github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+)
+
+// streamSortFormat renders entry timestamps at fixed width so sort keys order as strings
+const streamSortFormat = "2006-01-02T15:04:05.000000000Z"
+
+// streamEntryRanks orders entries recorded at the same time, so a registration precedes the
+// consents given with it
+var streamEntryRanks = map[string]int{
+	domain.StreamEntryCustomerChange: 1,
+	domain.StreamEntryConsent:        2,
+	domain.StreamEntryKYC:            3,
+	domain.StreamEntryAMLCheck:       4,
+	domain.StreamEntryScreening:      5,
+	domain.StreamEntryLoanMilestone:  6,
+}
+
+// EventStreamHandler merges a customer's records across the customer, compliance and loan
+// chaincodes into a single timeline for relationship managers
+type EventStreamHandler struct {
+	persistenceService *services.PersistenceService
+	activityService    *customerServices.CustomerActivityService
+}
+
+// NewEventStreamHandler creates a new event stream handler
+func NewEventStreamHandler() *EventStreamHandler {
+	return &EventStreamHandler{
+		persistenceService: services.NewPersistenceService(),
+		activityService:    customerServices.NewCustomerActivityService(),
+	}
+}
+
+// GetCustomerEventStream returns a page of a customer's lifetime events, oldest first: profile and
+// status changes, consent grants and withdrawals, KYC and AML checks, compliance screenings and
+// loan milestones. since is an RFC 3339 timestamp or a date meaning the start of that UTC day, or
+// empty for the whole lifetime.
+func (h *EventStreamHandler) GetCustomerEventStream(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) < 2 || len(args) > 4 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 2 to 4, got %d", len(args))
+	}
+
+	customerID := args[0]
+	var since *time.Time
+	if args[1] != "" {
+		sinceTime, err := time.Parse(time.RFC3339, args[1])
+		if err != nil {
+			date, dateErr := time.Parse("2006-01-02", args[1])
+			if dateErr != nil {
+				return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid since time %s: expected RFC 3339 or YYYY-MM-DD", args[1])
+			}
+			sinceTime = date
+		}
+		since = &sinceTime
+	}
+	pageSize, bookmark, err := services.ParsePageArgs(args[2:])
+	if err != nil {
+		return nil, err
+	}
+
+	var customer domain.Customer
+	if err := h.persistenceService.Get(stub, fmt.Sprintf("CUSTOMER_%s", customerID), &customer); err != nil {
+		return nil, fmt.Errorf("customer not found: %w", err)
+	}
+
+	entries, err := h.customerChangeEntries(stub, customerID)
+	if err != nil {
+		return nil, err
+	}
+	for _, collect := range []func(shim.ChaincodeStubInterface, string) ([]domain.CustomerStreamEntry, error){
+		h.consentEntries,
+		h.kycEntries,
+		h.amlEntries,
+		h.screeningEntries,
+		h.loanMilestoneEntries,
+	} {
+		collected, err := collect(stub, customerID)
+		if err != nil {
+			return nil, err
+		}
+		entries = append(entries, collected...)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return streamSortKey(&entries[i]) < streamSortKey(&entries[j])
+	})
+
+	stream := &domain.CustomerEventStream{
+		CustomerID: customerID,
+		Since:      since,
+		Entries:    []domain.CustomerStreamEntry{},
+	}
+	for i := range entries {
+		if since != nil && entries[i].Timestamp.Before(*since) {
+			continue
+		}
+		sortKey := streamSortKey(&entries[i])
+		if bookmark != "" && sortKey <= bookmark {
+			continue
+		}
+		if len(stream.Entries) == pageSize {
+			stream.Bookmark = streamSortKey(&stream.Entries[len(stream.Entries)-1])
+			break
+		}
+		stream.Entries = append(stream.Entries, entries[i])
+	}
+	stream.Count = len(stream.Entries)
+
+	return json.Marshal(stream)
+}
+
+// Helper methods
+
+// streamSortKey orders entries by time, then by type and ID for entries recorded together. It
+// doubles as the page bookmark.
+func streamSortKey(entry *domain.CustomerStreamEntry) string {
+	return fmt.Sprintf("%s|%d|%s", entry.Timestamp.UTC().Format(streamSortFormat), streamEntryRanks[entry.EntryType], entry.EntryID)
+}
+
+// customerChangeEntries lists registration, profile and status changes from the customer's history.
+// Consent preference changes are left to the consent records, and the PII values held hashed in the
+// history are not repeated.
+func (h *EventStreamHandler) customerChangeEntries(stub shim.ChaincodeStubInterface, customerID string) ([]domain.CustomerStreamEntry, error) {
+	history, err := h.historyEntries(stub, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []domain.CustomerStreamEntry{}
+	for _, record := range history {
+		if record.fieldName == "consentPreferences" {
+			continue
+		}
+		entry := record.streamEntry(domain.StreamEntryCustomerChange)
+		switch record.changeType {
+		case "CREATE":
+			entry.Summary = "Customer registered"
+		case "STATUS_UPDATE":
+			entry.Summary = fmt.Sprintf("Customer status changed from %s to %s", record.previousValue, record.newValue)
+			entry.Details = map[string]string{"previousStatus": record.previousValue, "newStatus": record.newValue}
+		default:
+			entry.Summary = fmt.Sprintf("Customer %s updated", record.fieldName)
+			entry.Details = map[string]string{"field": record.fieldName}
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// consentEntries lists every consent record of the customer across purposes
+func (h *EventStreamHandler) consentEntries(stub shim.ChaincodeStubInterface, customerID string) ([]domain.CustomerStreamEntry, error) {
+	iterator, err := stub.GetStateByPartialCompositeKey("CONSENT", []string{customerID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query consent records: %w", err)
+	}
+	defer iterator.Close()
+
+	entries := []domain.CustomerStreamEntry{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate consent records: %w", err)
+		}
+		var record domain.ConsentRecord
+		if err := json.Unmarshal(response.Value, &record); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal consent record: %w", err)
+		}
+
+		summary := fmt.Sprintf("Consent to %s %s", record.Purpose, consentState(record.Granted))
+		if record.Source == domain.ConsentSourceExpiry {
+			summary = fmt.Sprintf("Consent to %s lapsed", record.Purpose)
+		}
+		details := map[string]string{
+			"purpose": record.Purpose,
+			"version": strconv.Itoa(record.Version),
+			"source":  record.Source,
+		}
+		if record.ExpiryDate != nil {
+			details["expiryDate"] = utils.FormatTime(*record.ExpiryDate)
+		}
+		entries = append(entries, domain.CustomerStreamEntry{
+			EntryID:   fmt.Sprintf("CONSENT_%s_%06d", record.Purpose, record.Version),
+			EntryType: domain.StreamEntryConsent,
+			Timestamp: record.EffectiveDate,
+			Summary:   summary,
+			ActorID:   record.RecordedBy,
+			Details:   details,
+		})
+	}
+	return entries, nil
+}
+
+// kycEntries lists the initiation and status changes of each of the customer's KYC records
+func (h *EventStreamHandler) kycEntries(stub shim.ChaincodeStubInterface, customerID string) ([]domain.CustomerStreamEntry, error) {
+	kycIDs, err := customerRecordIDs(stub, "CUSTOMER_KYC_RECORD", fmt.Sprintf("CUSTOMER_KYC_%s", customerID), customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []domain.CustomerStreamEntry{}
+	for _, kycID := range kycIDs {
+		history, err := h.historyEntries(stub, kycID)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range history {
+			entry := record.streamEntry(domain.StreamEntryKYC)
+			switch record.changeType {
+			case "CREATE":
+				entry.Summary = "KYC verification initiated"
+			default:
+				entry.Summary = fmt.Sprintf("KYC status changed from %s to %s", record.previousValue, record.newValue)
+				entry.Details = map[string]string{"previousStatus": record.previousValue, "newStatus": record.newValue}
+			}
+			entry.ReferenceID = kycID
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// amlEntries lists the initiation, with its result, and status changes of each of the customer's
+// AML checks
+func (h *EventStreamHandler) amlEntries(stub shim.ChaincodeStubInterface, customerID string) ([]domain.CustomerStreamEntry, error) {
+	amlIDs, err := customerRecordIDs(stub, "CUSTOMER_AML_RECORD", fmt.Sprintf("CUSTOMER_AML_%s", customerID), customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []domain.CustomerStreamEntry{}
+	for _, amlID := range amlIDs {
+		history, err := h.historyEntries(stub, amlID)
+		if err != nil {
+			return nil, err
+		}
+		for _, record := range history {
+			entry := record.streamEntry(domain.StreamEntryAMLCheck)
+			switch record.changeType {
+			case "CREATE":
+				entry.Summary = "AML check initiated"
+				var amlRecord domain.AMLRecord
+				if err := json.Unmarshal([]byte(record.newValue), &amlRecord); err == nil {
+					entry.Details = map[string]string{
+						"status":    string(amlRecord.Status),
+						"riskScore": strconv.FormatFloat(amlRecord.RiskScore, 'f', 1, 64),
+					}
+					if len(amlRecord.Flags) > 0 {
+						entry.Details["flags"] = strings.Join(amlRecord.Flags, ",")
+					}
+				}
+			default:
+				entry.Summary = fmt.Sprintf("AML status changed from %s to %s", record.previousValue, record.newValue)
+				entry.Details = map[string]string{"previousStatus": record.previousValue, "newStatus": record.newValue}
+			}
+			entry.ReferenceID = amlID
+			entries = append(entries, entry)
+		}
+	}
+	return entries, nil
+}
+
+// screeningEntries lists the compliance events raised against the customer
+func (h *EventStreamHandler) screeningEntries(stub shim.ChaincodeStubInterface, customerID string) ([]domain.CustomerStreamEntry, error) {
+	events, err := h.activityService.GetComplianceEvents(stub, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []domain.CustomerStreamEntry{}
+	for _, event := range events {
+		entries = append(entries, domain.CustomerStreamEntry{
+			EntryID:     event.EventID,
+			EntryType:   domain.StreamEntryScreening,
+			Timestamp:   event.Timestamp,
+			Summary:     fmt.Sprintf("%s raised by %s", event.EventType, event.RuleID),
+			ReferenceID: event.EventID,
+			Details: map[string]string{
+				"ruleID":           event.RuleID,
+				"severity":         event.Severity,
+				"alerted":          strconv.FormatBool(event.IsAlerted),
+				"resolutionStatus": event.ResolutionStatus,
+			},
+		})
+	}
+	return entries, nil
+}
+
+// loanMilestoneEntries lists the statuses reached by the customer's loans
+func (h *EventStreamHandler) loanMilestoneEntries(stub shim.ChaincodeStubInterface, customerID string) ([]domain.CustomerStreamEntry, error) {
+	milestones, err := h.activityService.GetLoanMilestones(stub, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	entries := []domain.CustomerStreamEntry{}
+	for _, milestone := range milestones {
+		entries = append(entries, domain.CustomerStreamEntry{
+			EntryID:     fmt.Sprintf("%s_%s", milestone.LoanID, milestone.Sequence),
+			EntryType:   domain.StreamEntryLoanMilestone,
+			Timestamp:   milestone.Timestamp,
+			Summary:     fmt.Sprintf("Loan %s: %s", milestone.LoanID, milestone.Label),
+			ReferenceID: milestone.LoanID,
+			Details: map[string]string{
+				"loanType": milestone.LoanType,
+				"status":   milestone.Status,
+			},
+		})
+	}
+	return entries, nil
+}
+
+// streamHistoryRecord is a history entry read for the event stream
+type streamHistoryRecord struct {
+	historyID     string
+	timestamp     time.Time
+	changeType    string
+	fieldName     string
+	previousValue string
+	newValue      string
+	actorID       string
+}
+
+// streamEntry starts a stream entry of the given type from the history entry
+func (r *streamHistoryRecord) streamEntry(entryType string) domain.CustomerStreamEntry {
+	return domain.CustomerStreamEntry{
+		EntryID:   r.historyID,
+		EntryType: entryType,
+		Timestamp: r.timestamp,
+		ActorID:   r.actorID,
+	}
+}
+
+// historyEntries reads an entity's history entries, skipping any with an unreadable timestamp
+func (h *EventStreamHandler) historyEntries(stub shim.ChaincodeStubInterface, entityID string) ([]streamHistoryRecord, error) {
+	iterator, err := stub.GetStateByPartialCompositeKey("HISTORY", []string{entityID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get history iterator: %w", err)
+	}
+	defer iterator.Close()
+
+	records := []streamHistoryRecord{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate history: %w", err)
+		}
+
+		var entry map[string]interface{}
+		if err := json.Unmarshal(response.Value, &entry); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal history entry: %w", err)
+		}
+		timestamp, _ := entry["timestamp"].(string)
+		recordedAt, err := utils.ParseTime(timestamp)
+		if err != nil {
+			continue
+		}
+
+		record := streamHistoryRecord{timestamp: recordedAt}
+		record.historyID, _ = entry["historyID"].(string)
+		record.changeType, _ = entry["changeType"].(string)
+		record.fieldName, _ = entry["fieldName"].(string)
+		record.previousValue, _ = entry["previousValue"].(string)
+		record.newValue, _ = entry["newValue"].(string)
+		record.actorID, _ = entry["actorID"].(string)
+		records = append(records, record)
+	}
+	return records, nil
+}
+
+// customerRecordIDs lists a customer's KYC or AML record IDs from the per-customer index, adding
+// the latest record from its pointer for records created before the index was kept
+func customerRecordIDs(stub shim.ChaincodeStubInterface, indexName, pointerKey, customerID string) ([]string, error) {
+	iterator, err := stub.GetStateByPartialCompositeKey(indexName, []string{customerID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s index: %w", indexName, err)
+	}
+	defer iterator.Close()
+
+	recordIDs := []string{}
+	seen := make(map[string]bool)
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate %s index: %w", indexName, err)
+		}
+		recordIDs = append(recordIDs, string(response.Value))
+		seen[string(response.Value)] = true
+	}
+
+	latestID, err := stub.GetState(pointerKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", pointerKey, err)
+	}
+	if latestID != nil && !seen[string(latestID)] {
+		recordIDs = append(recordIDs, string(latestID))
+	}
+	return recordIDs, nil
+}
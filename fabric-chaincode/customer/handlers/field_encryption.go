@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/encryption"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// customerContact returns the email, phone and address a registration or update sets: those passed
+// in transient data under config.CustomerContactTransientKey, else those of the request. Request
+// arguments are recorded on the ledger, so while the client supplies a data key the request may
+// not carry them.
+func (h *CustomerHandler) customerContact(stub shim.ChaincodeStubInterface, fromRequest domain.CustomerContact) (domain.CustomerContact, error) {
+	transient, err := stub.GetTransient()
+	if err != nil {
+		return domain.CustomerContact{}, fmt.Errorf("failed to read transient data: %w", err)
+	}
+	key, err := h.fieldEncryptionService.ActiveKey(stub)
+	if err != nil {
+		return domain.CustomerContact{}, err
+	}
+
+	contactBytes, ok := transient[config.CustomerContactTransientKey]
+	if !fromRequest.IsEmpty() {
+		if ok {
+			return domain.CustomerContact{}, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "email, phone and address must be passed in transient data under %s or in the request, not both", config.CustomerContactTransientKey)
+		}
+		if key != nil {
+			return domain.CustomerContact{}, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "email, phone and address sealed under data key %s must be passed in transient data under %s, not in the request", key.Ref, config.CustomerContactTransientKey)
+		}
+		return fromRequest, nil
+	}
+	if !ok {
+		return domain.CustomerContact{}, nil
+	}
+
+	var contact domain.CustomerContact
+	if err := json.Unmarshal(contactBytes, &contact); err != nil {
+		return domain.CustomerContact{}, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse transient customer contact: %v", err)
+	}
+	return contact, nil
+}
+
+// contactOf returns the email, phone and address of a registration request that are set
+func contactOf(email, phone, address string) domain.CustomerContact {
+	var contact domain.CustomerContact
+	if email != "" {
+		contact.Email = &email
+	}
+	if phone != "" {
+		contact.Phone = &phone
+	}
+	if address != "" {
+		contact.Address = &address
+	}
+	return contact
+}
+
+// applyContact sets the email, phone and address a contact sets, leaving the others as they are
+func applyContact(contact domain.CustomerContact, email, phone, address *string) {
+	if contact.Email != nil {
+		*email = *contact.Email
+	}
+	if contact.Phone != nil {
+		*phone = *contact.Phone
+	}
+	if contact.Address != nil {
+		*address = *contact.Address
+	}
+}
+
+// sealCustomerPII encrypts each email, phone and address value a customer holds in plaintext under
+// the data key the client supplied, clearing the plaintext, so the customer is left with sealed
+// values only. Values already sealed and not changed stay as they are. Without a key, customers
+// never sealed keep their values in plaintext, but a customer with sealed fields may not be given
+// plaintext ones.
+func (h *CustomerHandler) sealCustomerPII(stub shim.ChaincodeStubInterface, customer *domain.Customer) error {
+	key, err := h.fieldEncryptionService.ActiveKey(stub)
+	if err != nil {
+		return err
+	}
+
+	for _, field := range domain.EncryptedCustomerFields {
+		value := customer.EncryptableField(field)
+		if *value == "" {
+			continue
+		}
+		if key == nil {
+			if len(customer.EncryptedFields) > 0 {
+				return fmt.Errorf("customer %s has encrypted PII; a data key is required to change %s", customer.CustomerID, field)
+			}
+			continue
+		}
+
+		sealed, err := h.fieldEncryptionService.Seal(stub, key, customer.CustomerID, field, *value)
+		if err != nil {
+			return err
+		}
+		if customer.EncryptedFields == nil {
+			customer.EncryptedFields = map[string]encryption.EncryptedField{}
+		}
+		customer.EncryptedFields[field] = *sealed
+		if field == "email" {
+			customer.EmailHash = customerEmailHash(*value)
+		}
+		*value = ""
+	}
+	return nil
+}
+
+// openCustomerPII decrypts a customer's sealed fields for a read response, where the invoker's role
+// holds PermissionViewPII and the client supplied the keys they are sealed under. Fields it may
+// not open are returned sealed, with no plaintext.
+func (h *CustomerHandler) openCustomerPII(stub shim.ChaincodeStubInterface, customer *domain.Customer) error {
+	for field, sealed := range customer.EncryptedFields {
+		value := customer.EncryptableField(field)
+		if value == nil {
+			continue
+		}
+		sealed := sealed
+		plaintext, ok, err := h.fieldEncryptionService.Open(stub, customer.CustomerID, field, &sealed)
+		if err != nil {
+			return err
+		}
+		if ok {
+			*value = plaintext
+		}
+	}
+	return nil
+}
+
+// customerEmailIndexValue returns the value a customer is indexed under by email: the hash kept
+// once the email is sealed, or a hash of the plaintext
+func customerEmailIndexValue(customer *domain.Customer) string {
+	if customer.Email == "" && customer.EmailHash != "" {
+		return customer.EmailHash
+	}
+	return customerEmailHash(customer.Email)
+}
@@ -3,12 +3,14 @@ package handlers
 import (
 	"encoding/json"
 	"fmt"
-	"time"
+	"math"
+	"strings"
 
 	"github.com/hyperledger/fabric-chaincode-go/shim"
 	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
 	customerServices "github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/services"
 	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/interfaces"
 	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
 	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
 	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
@@ -18,6 +20,12 @@ import (
 type KYCHandler struct {
 	persistenceService *services.PersistenceService
 	eventService      *customerServices.EventService
+	pepScreeningService *customerServices.PEPScreeningService
+	qaService         *services.QAService
+	residencyService  *customerServices.ResidencyService
+	riskRatingService *customerServices.RiskRatingService
+	freezeService     *services.EntityFreezeService
+	ownershipService  *customerServices.OwnershipService
 }
 
 // NewKYCHandler creates a new KYC handler
@@ -25,29 +33,40 @@ func NewKYCHandler() *KYCHandler {
 	return &KYCHandler{
 		persistenceService: services.NewPersistenceService(),
 		eventService:      customerServices.NewEventService(),
+		pepScreeningService: customerServices.NewPEPScreeningService(),
+		qaService:         services.NewQAService(),
+		residencyService:  customerServices.NewResidencyService(),
+		riskRatingService: customerServices.NewRiskRatingService(),
+		freezeService:     services.NewEntityFreezeService(),
+		ownershipService:  customerServices.NewOwnershipService(),
 	}
 }
 
 // InitiateKYC initiates KYC verification for a customer
 func (h *KYCHandler) InitiateKYC(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
 	if len(args) != 1 {
-		return nil, fmt.Errorf("incorrect number of arguments. Expected 1, got %d", len(args))
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
 	}
 
 	var req domain.KYCInitiationRequest
 	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
-		return nil, fmt.Errorf("failed to parse KYC initiation request: %v", err)
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse KYC initiation request: %v", err)
 	}
 
 	// Validate customer exists
 	customerKey := fmt.Sprintf("CUSTOMER_%s", req.CustomerID)
 	var customer domain.Customer
 	if err := h.persistenceService.Get(stub, customerKey, &customer); err != nil {
-		return nil, fmt.Errorf("customer not found: %v", err)
+		return nil, fmt.Errorf("customer not found: %w", err)
 	}
 
 	// Generate KYC ID
-	kycID := utils.GenerateID(config.KYCRecordPrefix)
+	kycID := services.GenerateDeterministicID(stub, config.KYCRecordPrefix)
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
 
 	// Create KYC record
 	kycRecord := &domain.KYCRecord{
@@ -57,36 +76,49 @@ func (h *KYCHandler) InitiateKYC(stub shim.ChaincodeStubInterface, args []string
 		DocumentHashes:  req.DocumentHashes,
 		VerificationNotes: "",
 		VerifiedBy:      "",
-		CreatedDate:     time.Now(),
-		LastUpdated:     time.Now(),
+		CreatedDate:     now,
+		LastUpdated:     now,
+		Version:         1,
 	}
 
 	// Validate KYC record
 	if err := domain.ValidateKYCRecord(kycRecord); err != nil {
-		return nil, fmt.Errorf("KYC record validation failed: %v", err)
+		return nil, fmt.Errorf("KYC record validation failed: %w", err)
 	}
 
 	// Store KYC record
 	kycKey := fmt.Sprintf("KYC_%s", kycID)
 	if err := h.persistenceService.Put(stub, kycKey, kycRecord); err != nil {
-		return nil, fmt.Errorf("failed to store KYC record: %v", err)
+		return nil, fmt.Errorf("failed to store KYC record: %w", err)
 	}
 
-	// Create index by customer ID
+	// The new record supersedes the customer's previous KYC status
 	customerKYCKey := fmt.Sprintf("CUSTOMER_KYC_%s", req.CustomerID)
+	previousStatus, err := h.currentRecordStatus(stub, customerKYCKey, "KYC_")
+	if err != nil {
+		return nil, err
+	}
+	if err := moveCustomerIndex(stub, "CUSTOMER_BY_KYC_STATUS", previousStatus, string(kycRecord.Status), req.CustomerID); err != nil {
+		return nil, err
+	}
+
+	// Create index by customer ID
 	if err := stub.PutState(customerKYCKey, []byte(kycID)); err != nil {
-		return nil, fmt.Errorf("failed to create customer KYC index: %v", err)
+		return nil, fmt.Errorf("failed to create customer KYC index: %w", err)
+	}
+	if err := putCustomerRecordIndex(stub, "CUSTOMER_KYC_RECORD", req.CustomerID, kycID); err != nil {
+		return nil, err
 	}
 
 	// Record history
 	kycJSON, _ := utils.MarshalJSONString(kycRecord)
 	if err := h.recordKYCHistory(stub, kycID, "CREATE", "kyc_record", "", kycJSON, req.ActorID); err != nil {
-		return nil, fmt.Errorf("failed to record history: %v", err)
+		return nil, fmt.Errorf("failed to record history: %w", err)
 	}
 
 	// Emit event
 	if err := h.eventService.EmitKYCInitiated(stub, kycRecord, req.ActorID); err != nil {
-		return nil, fmt.Errorf("failed to emit event: %v", err)
+		return nil, fmt.Errorf("failed to emit event: %w", err)
 	}
 
 	return json.Marshal(kycRecord)
@@ -95,19 +127,22 @@ func (h *KYCHandler) InitiateKYC(stub shim.ChaincodeStubInterface, args []string
 // UpdateKYCStatus updates the status of a KYC record
 func (h *KYCHandler) UpdateKYCStatus(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
 	if len(args) != 1 {
-		return nil, fmt.Errorf("incorrect number of arguments. Expected 1, got %d", len(args))
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
 	}
 
 	var req domain.KYCStatusUpdateRequest
 	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
-		return nil, fmt.Errorf("failed to parse KYC status update request: %v", err)
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse KYC status update request: %v", err)
 	}
 
 	// Get existing KYC record
 	kycKey := fmt.Sprintf("KYC_%s", req.KYCID)
 	var kycRecord domain.KYCRecord
 	if err := h.persistenceService.Get(stub, kycKey, &kycRecord); err != nil {
-		return nil, fmt.Errorf("KYC record not found: %v", err)
+		return nil, fmt.Errorf("KYC record not found: %w", err)
+	}
+	if err := services.CheckExpectedVersion("KYCRecord", req.KYCID, req.ExpectedVersion, kycRecord.Version); err != nil {
+		return nil, err
 	}
 
 	// Record history for status change
@@ -115,15 +150,21 @@ func (h *KYCHandler) UpdateKYCStatus(stub shim.ChaincodeStubInterface, args []st
 		return nil, err
 	}
 
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
 	// Update KYC record
+	previousStatus := kycRecord.Status
 	kycRecord.Status = req.NewStatus
 	kycRecord.VerificationNotes = req.VerificationNotes
 	kycRecord.VerifiedBy = req.ActorID
-	kycRecord.LastUpdated = time.Now()
+	kycRecord.LastUpdated = now
+	kycRecord.Version++
 
 	// Set verification and expiry dates for verified status
 	if req.NewStatus == validation.KYCStatusVerified {
-		now := time.Now()
 		kycRecord.VerificationDate = &now
 		expiryDate := now.AddDate(1, 0, 0) // 1 year from now
 		kycRecord.ExpiryDate = &expiryDate
@@ -131,17 +172,22 @@ func (h *KYCHandler) UpdateKYCStatus(stub shim.ChaincodeStubInterface, args []st
 
 	// Store updated KYC record
 	if err := h.persistenceService.Put(stub, kycKey, &kycRecord); err != nil {
-		return nil, fmt.Errorf("failed to update KYC record: %v", err)
+		return nil, fmt.Errorf("failed to update KYC record: %w", err)
+	}
+
+	// Only the customer's latest KYC record drives the status index
+	if err := h.moveLatestRecordIndex(stub, "CUSTOMER_BY_KYC_STATUS", fmt.Sprintf("CUSTOMER_KYC_%s", kycRecord.CustomerID), kycRecord.KYCID, string(previousStatus), string(kycRecord.Status), kycRecord.CustomerID); err != nil {
+		return nil, err
 	}
 
 	// Emit appropriate event
 	if req.NewStatus == validation.KYCStatusVerified {
 		if err := h.eventService.EmitKYCVerified(stub, &kycRecord, req.ActorID); err != nil {
-			return nil, fmt.Errorf("failed to emit KYC verified event: %v", err)
+			return nil, fmt.Errorf("failed to emit KYC verified event: %w", err)
 		}
 	} else if req.NewStatus == validation.KYCStatusFailed {
 		if err := h.eventService.EmitKYCFailed(stub, &kycRecord, req.ActorID); err != nil {
-			return nil, fmt.Errorf("failed to emit KYC failed event: %v", err)
+			return nil, fmt.Errorf("failed to emit KYC failed event: %w", err)
 		}
 	}
 
@@ -151,7 +197,7 @@ func (h *KYCHandler) UpdateKYCStatus(stub shim.ChaincodeStubInterface, args []st
 // GetKYCRecord retrieves a KYC record by ID
 func (h *KYCHandler) GetKYCRecord(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
 	if len(args) != 1 {
-		return nil, fmt.Errorf("incorrect number of arguments. Expected 1, got %d", len(args))
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
 	}
 
 	kycID := args[0]
@@ -159,73 +205,117 @@ func (h *KYCHandler) GetKYCRecord(stub shim.ChaincodeStubInterface, args []strin
 
 	var kycRecord domain.KYCRecord
 	if err := h.persistenceService.Get(stub, kycKey, &kycRecord); err != nil {
-		return nil, fmt.Errorf("KYC record not found: %v", err)
+		return nil, fmt.Errorf("KYC record not found: %w", err)
 	}
 
 	return json.Marshal(&kycRecord)
 }
 
-// InitiateAMLCheck initiates an AML check for a customer
+// InitiateAMLCheck initiates an AML check for a customer. An organization's check also screens
+// every beneficial owner its ownership graph resolves to.
 func (h *KYCHandler) InitiateAMLCheck(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
 	if len(args) != 1 {
-		return nil, fmt.Errorf("incorrect number of arguments. Expected 1, got %d", len(args))
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
 	}
 
 	var req domain.AMLCheckRequest
 	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
-		return nil, fmt.Errorf("failed to parse AML check request: %v", err)
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse AML check request: %v", err)
 	}
 
 	// Validate customer exists
 	customerKey := fmt.Sprintf("CUSTOMER_%s", req.CustomerID)
 	var customer domain.Customer
 	if err := h.persistenceService.Get(stub, customerKey, &customer); err != nil {
-		return nil, fmt.Errorf("customer not found: %v", err)
+		return nil, fmt.Errorf("customer not found: %w", err)
+	}
+
+	// Screening a resident customer reads their name from the residency's collection
+	if err := h.residencyService.CheckAccess(stub, &customer); err != nil {
+		return nil, err
+	}
+	if err := h.residencyService.LoadPII(stub, &customer); err != nil {
+		return nil, err
 	}
 
 	// Generate AML ID
-	amlID := utils.GenerateID(config.AMLCheckPrefix)
+	amlID := services.GenerateDeterministicID(stub, config.AMLCheckPrefix)
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
 
 	// Create AML record
 	amlRecord := &domain.AMLRecord{
 		AMLID:       amlID,
 		CustomerID:  req.CustomerID,
 		Status:      validation.AMLStatusClear, // Default to clear, will be updated by compliance checks
-		CheckDate:   time.Now(),
+		CheckDate:   now,
 		RiskScore:   0.0,
 		Flags:       []string{},
 		CheckedBy:   req.ActorID,
 		Notes:       "AML check initiated",
-		CreatedDate: time.Now(),
-		LastUpdated: time.Now(),
+		CreatedDate: now,
+		LastUpdated: now,
+		Version:     1,
+	}
+
+	// Screen against the compliance chaincode's PEP list; matches need manual review
+	pepResult, err := h.pepScreeningService.ScreenCustomer(stub, &customer)
+	if err != nil {
+		return nil, fmt.Errorf("PEP screening failed: %w", err)
+	}
+	if pepResult.IsMatch {
+		amlRecord.Status = validation.AMLStatusReviewing
+		amlRecord.Flags = append(amlRecord.Flags, "PEP_MATCH")
+		amlRecord.RiskScore = math.Min(pepResult.MatchConfidence*100, 100)
+		amlRecord.Notes = fmt.Sprintf("AML check initiated; %d potential PEP match(es) found", len(pepResult.Matches))
+	}
+	if customer.IsOrganization() {
+		if err := h.screenBeneficialOwners(stub, &customer, amlRecord); err != nil {
+			return nil, err
+		}
 	}
 
 	// Validate AML record
 	if err := domain.ValidateAMLRecord(amlRecord); err != nil {
-		return nil, fmt.Errorf("AML record validation failed: %v", err)
+		return nil, fmt.Errorf("AML record validation failed: %w", err)
 	}
 
 	// Store AML record
 	amlKey := fmt.Sprintf("AML_%s", amlID)
 	if err := h.persistenceService.Put(stub, amlKey, amlRecord); err != nil {
-		return nil, fmt.Errorf("failed to store AML record: %v", err)
+		return nil, fmt.Errorf("failed to store AML record: %w", err)
 	}
 
-	// Create index by customer ID
+	// The new record supersedes the customer's previous AML status
 	customerAMLKey := fmt.Sprintf("CUSTOMER_AML_%s", req.CustomerID)
+	previousStatus, err := h.currentRecordStatus(stub, customerAMLKey, "AML_")
+	if err != nil {
+		return nil, err
+	}
+	if err := moveCustomerIndex(stub, "CUSTOMER_BY_AML_STATUS", previousStatus, string(amlRecord.Status), req.CustomerID); err != nil {
+		return nil, err
+	}
+
+	// Create index by customer ID
 	if err := stub.PutState(customerAMLKey, []byte(amlID)); err != nil {
-		return nil, fmt.Errorf("failed to create customer AML index: %v", err)
+		return nil, fmt.Errorf("failed to create customer AML index: %w", err)
+	}
+	if err := putCustomerRecordIndex(stub, "CUSTOMER_AML_RECORD", req.CustomerID, amlID); err != nil {
+		return nil, err
 	}
 
 	// Record history
 	amlJSON, _ := utils.MarshalJSONString(amlRecord)
 	if err := h.recordAMLHistory(stub, amlID, "CREATE", "aml_record", "", amlJSON, req.ActorID); err != nil {
-		return nil, fmt.Errorf("failed to record history: %v", err)
+		return nil, fmt.Errorf("failed to record history: %w", err)
 	}
 
 	// Emit event
 	if err := h.eventService.EmitAMLCheckInitiated(stub, amlRecord, req.ActorID); err != nil {
-		return nil, fmt.Errorf("failed to emit event: %v", err)
+		return nil, fmt.Errorf("failed to emit event: %w", err)
 	}
 
 	return json.Marshal(amlRecord)
@@ -234,19 +324,22 @@ func (h *KYCHandler) InitiateAMLCheck(stub shim.ChaincodeStubInterface, args []s
 // UpdateAMLStatus updates the status of an AML record
 func (h *KYCHandler) UpdateAMLStatus(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
 	if len(args) != 1 {
-		return nil, fmt.Errorf("incorrect number of arguments. Expected 1, got %d", len(args))
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
 	}
 
 	var req domain.AMLStatusUpdateRequest
 	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
-		return nil, fmt.Errorf("failed to parse AML status update request: %v", err)
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse AML status update request: %v", err)
 	}
 
 	// Get existing AML record
 	amlKey := fmt.Sprintf("AML_%s", req.AMLID)
 	var amlRecord domain.AMLRecord
 	if err := h.persistenceService.Get(stub, amlKey, &amlRecord); err != nil {
-		return nil, fmt.Errorf("AML record not found: %v", err)
+		return nil, fmt.Errorf("AML record not found: %w", err)
+	}
+	if err := services.CheckExpectedVersion("AMLRecord", req.AMLID, req.ExpectedVersion, amlRecord.Version); err != nil {
+		return nil, err
 	}
 
 	// Record history for status change
@@ -254,32 +347,54 @@ func (h *KYCHandler) UpdateAMLStatus(stub shim.ChaincodeStubInterface, args []st
 		return nil, err
 	}
 
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
 	// Update AML record
+	previousStatus := amlRecord.Status
 	amlRecord.Status = req.NewStatus
 	amlRecord.RiskScore = req.RiskScore
 	amlRecord.Flags = req.Flags
 	amlRecord.Notes = req.Notes
 	amlRecord.CheckedBy = req.ActorID
-	amlRecord.LastUpdated = time.Now()
+	amlRecord.LastUpdated = now
+	amlRecord.Version++
 
 	// Validate updated record
 	if err := domain.ValidateAMLRecord(&amlRecord); err != nil {
-		return nil, fmt.Errorf("updated AML record validation failed: %v", err)
+		return nil, fmt.Errorf("updated AML record validation failed: %w", err)
 	}
 
 	// Store updated AML record
 	if err := h.persistenceService.Put(stub, amlKey, &amlRecord); err != nil {
-		return nil, fmt.Errorf("failed to update AML record: %v", err)
+		return nil, fmt.Errorf("failed to update AML record: %w", err)
+	}
+
+	// Only the customer's latest AML record drives the status index
+	if err := h.moveLatestRecordIndex(stub, "CUSTOMER_BY_AML_STATUS", fmt.Sprintf("CUSTOMER_AML_%s", amlRecord.CustomerID), amlRecord.AMLID, string(previousStatus), string(amlRecord.Status), amlRecord.CustomerID); err != nil {
+		return nil, err
 	}
 
 	// Emit appropriate event
 	if req.NewStatus == validation.AMLStatusFlagged {
 		if err := h.eventService.EmitAMLFlagged(stub, &amlRecord, req.ActorID); err != nil {
-			return nil, fmt.Errorf("failed to emit AML flagged event: %v", err)
+			return nil, fmt.Errorf("failed to emit AML flagged event: %w", err)
 		}
 	} else {
 		if err := h.eventService.EmitAMLCheckCompleted(stub, &amlRecord, req.ActorID); err != nil {
-			return nil, fmt.Errorf("failed to emit AML completed event: %v", err)
+			return nil, fmt.Errorf("failed to emit AML completed event: %w", err)
+		}
+	}
+
+	// Queue a share of screening clearances for quality review; sandbox customers are not sampled
+	if req.NewStatus == validation.AMLStatusClear && previousStatus != validation.AMLStatusClear {
+		var customer domain.Customer
+		if err := h.persistenceService.Get(stub, fmt.Sprintf("CUSTOMER_%s", amlRecord.CustomerID), &customer); err == nil && !customer.Sandbox {
+			if _, err := h.qaService.SampleDecision(stub, string(validation.QADecisionScreeningClearance), amlRecord.AMLID, "AMLRecord", string(amlRecord.Status), req.ActorID, amlRecord.LastUpdated); err != nil {
+				return nil, fmt.Errorf("failed to sample decision for quality review: %w", err)
+			}
 		}
 	}
 
@@ -289,7 +404,7 @@ func (h *KYCHandler) UpdateAMLStatus(stub shim.ChaincodeStubInterface, args []st
 // GetAMLRecord retrieves an AML record by ID
 func (h *KYCHandler) GetAMLRecord(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
 	if len(args) != 1 {
-		return nil, fmt.Errorf("incorrect number of arguments. Expected 1, got %d", len(args))
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
 	}
 
 	amlID := args[0]
@@ -297,29 +412,95 @@ func (h *KYCHandler) GetAMLRecord(stub shim.ChaincodeStubInterface, args []strin
 
 	var amlRecord domain.AMLRecord
 	if err := h.persistenceService.Get(stub, amlKey, &amlRecord); err != nil {
-		return nil, fmt.Errorf("AML record not found: %v", err)
+		return nil, fmt.Errorf("AML record not found: %w", err)
 	}
 
 	return json.Marshal(&amlRecord)
 }
 
+// GetCustomerComplianceStatus returns a customer's status, latest KYC and AML outcomes, risk tier and consent for other chaincodes
+func (h *KYCHandler) GetCustomerComplianceStatus(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	customerID := args[0]
+	customerKey := fmt.Sprintf("CUSTOMER_%s", customerID)
+	var customer domain.Customer
+	if err := h.persistenceService.Get(stub, customerKey, &customer); err != nil {
+		return nil, fmt.Errorf("customer not found: %w", err)
+	}
+
+	complianceStatus := &interfaces.CustomerComplianceStatus{
+		CustomerID:         customer.CustomerID,
+		Status:             string(customer.Status),
+		ConsentPreferences: customer.ConsentPreferences,
+		Residency:          customer.Residency,
+	}
+
+	// Latest KYC record, if any
+	kycID, err := stub.GetState(fmt.Sprintf("CUSTOMER_KYC_%s", customerID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer KYC index: %w", err)
+	}
+	if kycID != nil {
+		var kycRecord domain.KYCRecord
+		if err := h.persistenceService.Get(stub, fmt.Sprintf("KYC_%s", string(kycID)), &kycRecord); err != nil {
+			return nil, fmt.Errorf("KYC record not found: %w", err)
+		}
+		complianceStatus.KYCStatus = string(kycRecord.Status)
+		complianceStatus.KYCExpiryDate = kycRecord.ExpiryDate
+	}
+
+	// Latest AML record, if any
+	amlID, err := stub.GetState(fmt.Sprintf("CUSTOMER_AML_%s", customerID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer AML index: %w", err)
+	}
+	if amlID != nil {
+		var amlRecord domain.AMLRecord
+		if err := h.persistenceService.Get(stub, fmt.Sprintf("AML_%s", string(amlID)), &amlRecord); err != nil {
+			return nil, fmt.Errorf("AML record not found: %w", err)
+		}
+		complianceStatus.AMLStatus = string(amlRecord.Status)
+		complianceStatus.AMLRiskScore = amlRecord.RiskScore
+	}
+
+	// Risk tier, once the customer has been rated
+	profile, err := h.riskRatingService.GetProfile(stub, customerID)
+	if err != nil {
+		return nil, err
+	}
+	if profile != nil {
+		complianceStatus.RiskTier = string(profile.RiskTier)
+	}
+
+	frozen, err := h.freezeService.IsFrozen(stub, services.FreezeEntityCustomer, customerID)
+	if err != nil {
+		return nil, err
+	}
+	complianceStatus.Frozen = frozen
+
+	return json.Marshal(complianceStatus)
+}
+
 // QueryKYCByStatus queries KYC records by status
 func (h *KYCHandler) QueryKYCByStatus(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
 	if len(args) != 1 {
-		return nil, fmt.Errorf("incorrect number of arguments. Expected 1, got %d", len(args))
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
 	}
 
 	status := args[0]
 	
 	// Validate status
 	if err := validation.ValidateKYCStatus(status); err != nil {
-		return nil, fmt.Errorf("invalid KYC status: %v", err)
+		return nil, fmt.Errorf("invalid KYC status: %w", err)
 	}
 
 	// Query KYC records by status using composite key
 	iterator, err := stub.GetStateByPartialCompositeKey("KYC_STATUS", []string{status})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get KYC records by status: %v", err)
+		return nil, fmt.Errorf("failed to get KYC records by status: %w", err)
 	}
 	defer iterator.Close()
 
@@ -327,12 +508,12 @@ func (h *KYCHandler) QueryKYCByStatus(stub shim.ChaincodeStubInterface, args []s
 	for iterator.HasNext() {
 		response, err := iterator.Next()
 		if err != nil {
-			return nil, fmt.Errorf("failed to iterate KYC records: %v", err)
+			return nil, fmt.Errorf("failed to iterate KYC records: %w", err)
 		}
 
 		var kycRecord domain.KYCRecord
 		if err := json.Unmarshal(response.Value, &kycRecord); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal KYC record: %v", err)
+			return nil, fmt.Errorf("failed to unmarshal KYC record: %w", err)
 		}
 
 		kycRecords = append(kycRecords, kycRecord)
@@ -343,15 +524,124 @@ func (h *KYCHandler) QueryKYCByStatus(stub shim.ChaincodeStubInterface, args []s
 
 // Helper methods
 
+// screenBeneficialOwners screens each beneficial owner of an organization against the PEP list.
+// A matching owner, or an ownership graph that does not resolve to its beneficial owners, puts the
+// check under review.
+func (h *KYCHandler) screenBeneficialOwners(stub shim.ChaincodeStubInterface, organization *domain.Customer, amlRecord *domain.AMLRecord) error {
+	resolution, err := h.ownershipService.ResolveBeneficialOwners(stub, organization.CustomerID)
+	if err != nil {
+		return fmt.Errorf("beneficial owner resolution failed: %w", err)
+	}
+
+	matched := 0
+	amlRecord.ScreenedOwners = []domain.OwnerScreening{}
+	for _, owner := range resolution.BeneficialOwners {
+		var customer domain.Customer
+		if err := h.persistenceService.Get(stub, fmt.Sprintf("CUSTOMER_%s", owner.CustomerID), &customer); err != nil {
+			return fmt.Errorf("beneficial owner not found: %w", err)
+		}
+		if err := h.residencyService.CheckAccess(stub, &customer); err != nil {
+			return err
+		}
+		if err := h.residencyService.LoadPII(stub, &customer); err != nil {
+			return err
+		}
+
+		pepResult, err := h.pepScreeningService.ScreenCustomer(stub, &customer)
+		if err != nil {
+			return fmt.Errorf("PEP screening of beneficial owner %s failed: %w", owner.CustomerID, err)
+		}
+		amlRecord.ScreenedOwners = append(amlRecord.ScreenedOwners, domain.OwnerScreening{
+			CustomerID:          owner.CustomerID,
+			EffectivePercentage: owner.EffectivePercentage,
+			HasControl:          owner.HasControl,
+			IsMatch:             pepResult.IsMatch,
+			MatchConfidence:     pepResult.MatchConfidence,
+		})
+		if pepResult.IsMatch {
+			matched++
+			amlRecord.RiskScore = math.Max(amlRecord.RiskScore, math.Min(pepResult.MatchConfidence*100, 100))
+		}
+	}
+
+	if matched > 0 {
+		amlRecord.Status = validation.AMLStatusReviewing
+		amlRecord.Flags = append(amlRecord.Flags, "UBO_PEP_MATCH")
+	}
+	if len(resolution.BeneficialOwners) == 0 || len(resolution.UnresolvedOrganizations) > 0 {
+		amlRecord.Status = validation.AMLStatusReviewing
+		amlRecord.Flags = append(amlRecord.Flags, "UBO_INCOMPLETE")
+	}
+	amlRecord.Notes += fmt.Sprintf("; %d of %d beneficial owner(s) matched the PEP list", matched, len(resolution.BeneficialOwners))
+	if len(resolution.UnresolvedOrganizations) > 0 {
+		amlRecord.Notes += fmt.Sprintf("; owners of %s not recorded", strings.Join(resolution.UnresolvedOrganizations, ", "))
+	}
+	return nil
+}
+
+// currentRecordStatus returns the status of the KYC or AML record a customer pointer references,
+// or "" when the customer has none
+func (h *KYCHandler) currentRecordStatus(stub shim.ChaincodeStubInterface, pointerKey, recordPrefix string) (string, error) {
+	recordID, err := stub.GetState(pointerKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to read %s: %w", pointerKey, err)
+	}
+	if recordID == nil {
+		return "", nil
+	}
+
+	var record struct {
+		Status string `json:"status"`
+	}
+	if err := h.persistenceService.Get(stub, recordPrefix+string(recordID), &record); err != nil {
+		return "", err
+	}
+	return record.Status, nil
+}
+
+// moveLatestRecordIndex moves a customer's KYC or AML status index entry when the updated record
+// is the one the customer pointer references
+func (h *KYCHandler) moveLatestRecordIndex(stub shim.ChaincodeStubInterface, objectType, pointerKey, recordID, previousStatus, newStatus, customerID string) error {
+	latestID, err := stub.GetState(pointerKey)
+	if err != nil {
+		return fmt.Errorf("failed to read %s: %w", pointerKey, err)
+	}
+	if string(latestID) != recordID {
+		return nil
+	}
+	return moveCustomerIndex(stub, objectType, previousStatus, newStatus, customerID)
+}
+
+// putCustomerRecordIndex lists a KYC or AML record under its customer, so records superseded by a
+// later check stay reachable from the customer
+func putCustomerRecordIndex(stub shim.ChaincodeStubInterface, objectType, customerID, recordID string) error {
+	indexKey, err := stub.CreateCompositeKey(objectType, []string{customerID, recordID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := stub.PutState(indexKey, []byte(recordID)); err != nil {
+		return fmt.Errorf("failed to create %s index: %w", objectType, err)
+	}
+	return nil
+}
+
 func (h *KYCHandler) recordKYCHistory(stub shim.ChaincodeStubInterface, kycID, changeType, fieldName, previousValue, newValue, actorID string) error {
-	historyID := utils.GenerateID(config.HistoryPrefix)
+	historyID, compositeKey, err := services.NewHistoryKey(stub, kycID)
+	if err != nil {
+		return err
+	}
 	txID := stub.GetTxID()
 
+	timestamp, err := services.TxTimeString(stub)
+	if err != nil {
+		return err
+	}
+
 	historyEntry := map[string]interface{}{
 		"historyID":     historyID,
 		"entityID":      kycID,
 		"entityType":    "KYCRecord",
-		"timestamp":     utils.GetCurrentTimeString(),
+		"timestamp":     timestamp,
 		"changeType":    changeType,
 		"fieldName":     fieldName,
 		"previousValue": previousValue,
@@ -360,23 +650,26 @@ func (h *KYCHandler) recordKYCHistory(stub shim.ChaincodeStubInterface, kycID, c
 		"transactionID": txID,
 	}
 
-	compositeKey, err := stub.CreateCompositeKey("HISTORY", []string{kycID, historyID})
-	if err != nil {
-		return fmt.Errorf("failed to create composite key: %v", err)
-	}
-
 	return h.persistenceService.Put(stub, compositeKey, historyEntry)
 }
 
 func (h *KYCHandler) recordAMLHistory(stub shim.ChaincodeStubInterface, amlID, changeType, fieldName, previousValue, newValue, actorID string) error {
-	historyID := utils.GenerateID(config.HistoryPrefix)
+	historyID, compositeKey, err := services.NewHistoryKey(stub, amlID)
+	if err != nil {
+		return err
+	}
 	txID := stub.GetTxID()
 
+	timestamp, err := services.TxTimeString(stub)
+	if err != nil {
+		return err
+	}
+
 	historyEntry := map[string]interface{}{
 		"historyID":     historyID,
 		"entityID":      amlID,
 		"entityType":    "AMLRecord",
-		"timestamp":     utils.GetCurrentTimeString(),
+		"timestamp":     timestamp,
 		"changeType":    changeType,
 		"fieldName":     fieldName,
 		"previousValue": previousValue,
@@ -385,10 +678,5 @@ func (h *KYCHandler) recordAMLHistory(stub shim.ChaincodeStubInterface, amlID, c
 		"transactionID": txID,
 	}
 
-	compositeKey, err := stub.CreateCompositeKey("HISTORY", []string{amlID, historyID})
-	if err != nil {
-		return fmt.Errorf("failed to create composite key: %v", err)
-	}
-
 	return h.persistenceService.Put(stub, compositeKey, historyEntry)
 }
\ No newline at end of file
@@ -0,0 +1,245 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+)
+
+// RecordOwnership records that a customer, an individual or another organization, owns or controls
+// an organization, replacing any earlier link between the two. Links may not make ownership
+// circular, and the shareholdings or voting rights recorded for an organization may not exceed 100%.
+func (h *CustomerHandler) RecordOwnership(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.OwnershipRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse ownership request: %v", err)
+	}
+	if err := domain.ValidateOwnershipRequest(&req); err != nil {
+		return nil, err
+	}
+
+	if _, err := h.getOrganization(stub, req.CustomerID); err != nil {
+		return nil, err
+	}
+	var owner domain.Customer
+	if err := h.persistenceService.Get(stub, fmt.Sprintf("CUSTOMER_%s", req.OwnerID), &owner); err != nil {
+		return nil, fmt.Errorf("owner not found: %w", err)
+	}
+	ownerType := domain.CustomerTypeIndividual
+	if owner.IsOrganization() {
+		ownerType = domain.CustomerTypeOrganization
+	}
+
+	if err := h.ownershipService.CheckNoCycle(stub, req.CustomerID, req.OwnerID); err != nil {
+		return nil, err
+	}
+
+	// The other owners' holdings of the same kind, with this owner's replaced by the new one
+	owners, err := h.ownershipService.Owners(stub, req.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	var previous *domain.OwnershipLink
+	total := req.Percentage
+	for i := range owners {
+		if owners[i].OwnerID == req.OwnerID {
+			previous = &owners[i]
+			continue
+		}
+		if owners[i].ControlType == req.ControlType {
+			total += owners[i].Percentage
+		}
+	}
+	if req.ControlType.HasPercentage() && total > 100 {
+		return nil, fmt.Errorf("%s of %s would total %.2f%%; it may not exceed 100%%", strings.ToLower(string(req.ControlType)), req.CustomerID, total)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	link := &domain.OwnershipLink{
+		OrganizationID: req.CustomerID,
+		OwnerID:        req.OwnerID,
+		OwnerType:      ownerType,
+		ControlType:    req.ControlType,
+		Percentage:     req.Percentage,
+		RecordedBy:     req.ActorID,
+		RecordedDate:   now,
+		TransactionID:  stub.GetTxID(),
+	}
+	if err := h.ownershipService.PutLink(stub, link); err != nil {
+		return nil, err
+	}
+
+	previousJSON := ""
+	if previous != nil {
+		previousJSON, _ = utils.MarshalJSONString(previous)
+	}
+	linkJSON, _ := utils.MarshalJSONString(link)
+	if err := h.recordCustomerHistory(stub, req.CustomerID, "UPDATE", "ownership", previousJSON, linkJSON, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to record history: %w", err)
+	}
+
+	if err := h.eventService.EmitOwnershipRecorded(stub, link, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(link)
+}
+
+// RemoveOwnership removes an owner of an organization, such as after the owner sells their stake
+func (h *CustomerHandler) RemoveOwnership(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.OwnershipRemovalRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse ownership removal request: %v", err)
+	}
+	if strings.TrimSpace(req.CustomerID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "customerID", "customerID is required")
+	}
+	if strings.TrimSpace(req.OwnerID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "ownerID", "ownerID is required")
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "reason", "reason is required")
+	}
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	link, err := h.ownershipService.GetLink(stub, req.CustomerID, req.OwnerID)
+	if err != nil {
+		return nil, err
+	}
+	if link == nil {
+		return nil, services.NewChaincodeError(services.ErrCodeNotFound, "", "ownership of %s by %s not found", req.CustomerID, req.OwnerID)
+	}
+	if err := h.ownershipService.DeleteLink(stub, req.CustomerID, req.OwnerID); err != nil {
+		return nil, err
+	}
+
+	linkJSON, _ := utils.MarshalJSONString(link)
+	if err := h.recordCustomerHistory(stub, req.CustomerID, "UPDATE", "ownership", linkJSON, "", req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to record history: %w", err)
+	}
+
+	if err := h.eventService.EmitOwnershipRemoved(stub, link, req.Reason, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(link)
+}
+
+// GetOwnershipStructure returns an organization's direct owners. Args: customerID
+func (h *CustomerHandler) GetOwnershipStructure(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	organization, err := h.getOrganization(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	owners, err := h.ownershipService.Owners(stub, organization.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+
+	structure := &domain.OwnershipStructure{
+		CustomerID: organization.CustomerID,
+		LegalName:  organization.LegalName,
+		Owners:     owners,
+	}
+	for _, link := range owners {
+		switch link.ControlType {
+		case domain.ControlTypeShareholding:
+			structure.TotalShareholding += link.Percentage
+		case domain.ControlTypeVotingRights:
+			structure.TotalVotingRights += link.Percentage
+		}
+	}
+	return json.Marshal(structure)
+}
+
+// GetUltimateBeneficialOwners resolves an organization's ownership graph to the individuals who
+// ultimately own or control it. Owners are named where the invoker may read their PII.
+// Args: customerID
+func (h *CustomerHandler) GetUltimateBeneficialOwners(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	if _, err := h.getOrganization(stub, args[0]); err != nil {
+		return nil, err
+	}
+	resolution, err := h.ownershipService.ResolveBeneficialOwners(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	for _, owners := range [][]domain.BeneficialOwner{resolution.BeneficialOwners, resolution.OtherOwners} {
+		for i := range owners {
+			owners[i].Name = h.ownerName(stub, owners[i].CustomerID)
+		}
+	}
+	return json.Marshal(resolution)
+}
+
+// GetControlledEntities returns every organization an individual is a beneficial owner of,
+// however many organizations they hold it through. Args: customerID
+func (h *CustomerHandler) GetControlledEntities(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var customer domain.Customer
+	if err := h.persistenceService.Get(stub, fmt.Sprintf("CUSTOMER_%s", args[0]), &customer); err != nil {
+		return nil, fmt.Errorf("customer not found: %w", err)
+	}
+	if customer.IsOrganization() {
+		return nil, fmt.Errorf("customer %s is an organization; controlled entities are resolved for individuals", args[0])
+	}
+
+	entities, err := h.ownershipService.ControlledEntities(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&domain.ControlledEntitiesResult{CustomerID: args[0], Entities: entities, Count: len(entities)})
+}
+
+// getOrganization retrieves the public record of an organization customer
+func (h *CustomerHandler) getOrganization(stub shim.ChaincodeStubInterface, customerID string) (*domain.Customer, error) {
+	var organization domain.Customer
+	if err := h.persistenceService.Get(stub, fmt.Sprintf("CUSTOMER_%s", customerID), &organization); err != nil {
+		return nil, fmt.Errorf("customer not found: %w", err)
+	}
+	if !organization.IsOrganization() {
+		return nil, fmt.Errorf("customer %s is not an organization", customerID)
+	}
+	return &organization, nil
+}
+
+// ownerName returns an owner's name, or "" when it is PII the invoker's residency may not read
+func (h *CustomerHandler) ownerName(stub shim.ChaincodeStubInterface, customerID string) string {
+	var owner domain.Customer
+	if err := h.persistenceService.Get(stub, fmt.Sprintf("CUSTOMER_%s", customerID), &owner); err != nil {
+		return ""
+	}
+	if h.residencyService.CheckAccess(stub, &owner) != nil || h.residencyService.LoadPII(stub, &owner) != nil {
+		return ""
+	}
+	return owner.Name()
+}
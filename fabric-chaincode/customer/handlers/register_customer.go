@@ -3,7 +3,7 @@ package handlers
 import (
 	"encoding/json"
 	"fmt"
-	"time"
+	"strings"
 
 	"github.com/hyperledger/fabric-chaincode-go/shim"
 	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
@@ -18,6 +18,17 @@ import (
 type CustomerHandler struct {
 	persistenceService *services.PersistenceService
 	eventService      *customerServices.EventService
+	sandboxService    *services.SandboxService
+	residencyService  *customerServices.ResidencyService
+	idempotencyService *services.IdempotencyService
+	dataSharingService *customerServices.DataSharingService
+	consentService     *customerServices.ConsentService
+	activityService    *customerServices.CustomerActivityService
+	riskRatingService  *customerServices.RiskRatingService
+	ownershipService   *customerServices.OwnershipService
+	relationshipService *customerServices.RelationshipService
+	fieldEncryptionService *services.FieldEncryptionService
+	accessLogService   *customerServices.AccessLogService
 }
 
 // NewCustomerHandler creates a new customer handler
@@ -25,42 +36,111 @@ func NewCustomerHandler() *CustomerHandler {
 	return &CustomerHandler{
 		persistenceService: services.NewPersistenceService(),
 		eventService:      customerServices.NewEventService(),
+		sandboxService:    services.NewSandboxService(),
+		residencyService:  customerServices.NewResidencyService(),
+		idempotencyService: services.NewIdempotencyService(),
+		dataSharingService: customerServices.NewDataSharingService(),
+		consentService:     customerServices.NewConsentService(),
+		activityService:    customerServices.NewCustomerActivityService(),
+		riskRatingService:  customerServices.NewRiskRatingService(),
+		ownershipService:   customerServices.NewOwnershipService(),
+		relationshipService: customerServices.NewRelationshipService(),
+		fieldEncryptionService: services.NewFieldEncryptionService(),
+		accessLogService:   customerServices.NewAccessLogService(),
 	}
 }
 
-// RegisterCustomer registers a new customer
+// RegisterCustomer registers a new customer, an individual or an organization
 func (h *CustomerHandler) RegisterCustomer(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
 	if len(args) != 1 {
-		return nil, fmt.Errorf("incorrect number of arguments. Expected 1, got %d", len(args))
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
 	}
 
 	// Parse the registration request
 	var req domain.CustomerRegistrationRequest
 	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
-		return nil, fmt.Errorf("failed to parse registration request: %v", err)
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse registration request: %v", err)
+	}
+	requestContact := contactOf(req.Email, req.Phone, req.Address)
+	if err := applyTransientPII(stub, &req); err != nil {
+		return nil, err
+	}
+	contact, err := h.customerContact(stub, requestContact)
+	if err != nil {
+		return nil, err
+	}
+	applyContact(contact, &req.Email, &req.Phone, &req.Address)
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
 	}
 
 	// Validate the request
-	if err := domain.ValidateCustomerRegistrationRequest(&req); err != nil {
-		return nil, fmt.Errorf("validation failed: %v", err)
+	if req.CustomerType == "" {
+		req.CustomerType = domain.CustomerTypeIndividual
+	}
+	if err := domain.ValidateCustomerRegistrationRequest(&req, now); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
 	}
 
-	// Check if customer with same national ID already exists
-	existingCustomerKey := fmt.Sprintf("CUSTOMER_BY_NATIONAL_ID_%s", req.NationalID)
-	existingData, err := stub.GetState(existingCustomerKey)
+	// A retried submission returns the customer the original created
+	existingID, err := h.idempotencyService.Replay(stub, "RegisterCustomer", req.IdempotencyKey, &req)
 	if err != nil {
-		return nil, fmt.Errorf("failed to check existing customer: %v", err)
+		return nil, err
+	}
+	if existingID != "" {
+		existing, err := h.getCustomer(stub, existingID)
+		if err != nil {
+			return nil, err
+		}
+		return json.Marshal(existing.Public())
+	}
+
+	// Customers with a residency keep their PII in that residency's private data collection
+	if req.Residency != "" {
+		if _, err := h.residencyService.CollectionFor(req.Residency); err != nil {
+			return nil, err
+		}
 	}
-	if existingData != nil {
-		return nil, fmt.Errorf("customer with national ID %s already exists", req.NationalID)
+
+	// Check if customer with same national ID already exists, whichever residency they hold;
+	// organizations are unique by registration number
+	if req.CustomerType == domain.CustomerTypeOrganization {
+		existingData, err := stub.GetState(registrationNumberIndexKey(req.RegistrationNumber))
+		if err != nil {
+			return nil, fmt.Errorf("failed to check existing customer: %w", err)
+		}
+		if existingData != nil {
+			return nil, fmt.Errorf("organization with registration number %s already exists", req.RegistrationNumber)
+		}
+	} else {
+		for _, residency := range []string{"", req.Residency} {
+			existingData, err := stub.GetState(nationalIDIndexKey(residency, req.NationalID))
+			if err != nil {
+				return nil, fmt.Errorf("failed to check existing customer: %w", err)
+			}
+			if existingData != nil {
+				return nil, fmt.Errorf("customer with national ID %s already exists", req.NationalID)
+			}
+		}
+	}
+
+	// Customers registered by sandbox actors are UAT data
+	sandbox, err := h.sandboxService.IsSandboxActor(stub, req.ActorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check sandbox actor: %w", err)
 	}
 
 	// Generate customer ID
-	customerID := utils.GenerateID(config.CustomerPrefix)
+	customerID := services.GenerateDeterministicID(stub, config.CustomerPrefix)
 
 	// Create customer entity
 	customer := &domain.Customer{
 		CustomerID:         customerID,
+		CustomerType:       req.CustomerType,
+		LegalName:          req.LegalName,
+		RegistrationNumber: req.RegistrationNumber,
 		FirstName:          req.FirstName,
 		LastName:           req.LastName,
 		Email:              req.Email,
@@ -70,94 +150,197 @@ func (h *CustomerHandler) RegisterCustomer(stub shim.ChaincodeStubInterface, arg
 		Address:            req.Address,
 		Status:             validation.CustomerStatusActive,
 		ConsentPreferences: req.ConsentPreferences,
-		CreatedDate:        time.Now(),
-		LastUpdated:        time.Now(),
+		Residency:          req.Residency,
+		Sandbox:            sandbox,
+		CreatedDate:        now,
+		LastUpdated:        now,
 		CreatedBy:          req.ActorID,
 		LastUpdatedBy:      req.ActorID,
 	}
 
 	// Validate the customer entity
-	if err := domain.ValidateCustomer(customer); err != nil {
-		return nil, fmt.Errorf("customer validation failed: %v", err)
+	if err := domain.ValidateCustomer(customer, now); err != nil {
+		return nil, fmt.Errorf("customer validation failed: %w", err)
 	}
 
-	// Store the customer
-	customerKey := fmt.Sprintf("CUSTOMER_%s", customerID)
-	if err := h.persistenceService.Put(stub, customerKey, customer); err != nil {
-		return nil, fmt.Errorf("failed to store customer: %v", err)
+	// Email, phone and address are sealed when the client supplies a data key
+	if err := h.sealCustomerPII(stub, customer); err != nil {
+		return nil, err
 	}
 
-	// Create index by national ID
-	if err := stub.PutState(existingCustomerKey, []byte(customerID)); err != nil {
-		return nil, fmt.Errorf("failed to create national ID index: %v", err)
+	// Store the customer with its status, name and email indexes
+	if err := putCustomer(stub, h.persistenceService, h.residencyService, customer); err != nil {
+		return nil, fmt.Errorf("failed to store customer: %w", err)
+	}
+	if customer.Residency != "" {
+		if err := h.residencyService.PutPII(stub, customer); err != nil {
+			return nil, err
+		}
+	}
+
+	// Create index by national ID, or registration number for an organization
+	if err := stub.PutState(identityIndexKey(customer), []byte(customerID)); err != nil {
+		return nil, fmt.Errorf("failed to create national ID index: %w", err)
+	}
+
+	if err := h.idempotencyService.Record(stub, "RegisterCustomer", req.IdempotencyKey, &req, customerID); err != nil {
+		return nil, err
 	}
 
 	// Record history
-	customerJSON, _ := utils.MarshalJSONString(customer)
+	customerJSON, _ := utils.MarshalJSONString(customer.Public())
 	if err := h.recordCustomerHistory(stub, customerID, "CREATE", "customer", "", customerJSON, req.ActorID); err != nil {
-		return nil, fmt.Errorf("failed to record history: %v", err)
+		return nil, fmt.Errorf("failed to record history: %w", err)
+	}
+
+	// Sandbox registrations are UAT data and stay out of the production totals
+	if !customer.Sandbox {
+		if err := services.IncrementCounter(stub, config.MetricCustomersRegistered); err != nil {
+			return nil, err
+		}
+	}
+
+	// Each consent given at registration is the first version of its purpose's consent record
+	for _, purpose := range domain.ConsentPurposes(customer.ConsentPreferences) {
+		if _, err := h.consentService.Record(stub, customerID, purpose, domain.ConsentGranted(customer.ConsentPreferences, purpose), domain.ConsentSourceRegistration, "", req.ActorID); err != nil {
+			return nil, err
+		}
 	}
 
 	// Emit event
-	if err := h.eventService.EmitCustomerCreated(stub, customer, req.ActorID); err != nil {
-		return nil, fmt.Errorf("failed to emit event: %v", err)
+	if err := h.eventService.EmitCustomerCreated(stub, customer.Public(), req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
 	}
 
-	// Return the created customer
-	return json.Marshal(customer)
+	// Return the created customer; the response is recorded with the transaction, so a resident
+	// customer's PII is left out
+	return json.Marshal(customer.Public())
+}
+
+// applyTransientPII fills a registration's personal data from transient data. A customer with a
+// residency must be registered this way, since the request arguments reach the public ledger.
+func applyTransientPII(stub shim.ChaincodeStubInterface, req *domain.CustomerRegistrationRequest) error {
+	transient, err := stub.GetTransient()
+	if err != nil {
+		return fmt.Errorf("failed to read transient data: %w", err)
+	}
+
+	requestPII := domain.CustomerPII{
+		FirstName:   req.FirstName,
+		LastName:    req.LastName,
+		Email:       req.Email,
+		Phone:       req.Phone,
+		DateOfBirth: req.DateOfBirth,
+		NationalID:  req.NationalID,
+		Address:     req.Address,
+	}
+	piiBytes, ok := transient[config.CustomerPIITransientKey]
+	if !ok {
+		if req.Residency != "" && requestPII != (domain.CustomerPII{}) {
+			return services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "personal data of a customer with a residency must be passed in transient data under %s", config.CustomerPIITransientKey)
+		}
+		return nil
+	}
+	if requestPII != (domain.CustomerPII{}) {
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "personal data must be passed in transient data under %s or in the request, not both", config.CustomerPIITransientKey)
+	}
+
+	var pii domain.CustomerPII
+	if err := json.Unmarshal(piiBytes, &pii); err != nil {
+		return fmt.Errorf("failed to parse transient customer PII: %w", err)
+	}
+	req.FirstName = pii.FirstName
+	req.LastName = pii.LastName
+	req.Email = pii.Email
+	req.Phone = pii.Phone
+	req.DateOfBirth = pii.DateOfBirth
+	req.NationalID = pii.NationalID
+	req.Address = pii.Address
+	return nil
 }
 
 // UpdateCustomer updates an existing customer
 func (h *CustomerHandler) UpdateCustomer(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
 	if len(args) != 1 {
-		return nil, fmt.Errorf("incorrect number of arguments. Expected 1, got %d", len(args))
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
 	}
 
 	// Parse the update request
 	var req domain.CustomerUpdateRequest
 	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
-		return nil, fmt.Errorf("failed to parse update request: %v", err)
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse update request: %v", err)
 	}
+	contact, err := h.customerContact(stub, domain.CustomerContact{Email: req.Email, Phone: req.Phone, Address: req.Address})
+	if err != nil {
+		return nil, err
+	}
+	req.Email, req.Phone, req.Address = contact.Email, contact.Phone, contact.Address
 
 	// Get existing customer
-	customerKey := fmt.Sprintf("CUSTOMER_%s", req.CustomerID)
-	var existingCustomer domain.Customer
-	if err := h.persistenceService.Get(stub, customerKey, &existingCustomer); err != nil {
-		return nil, fmt.Errorf("customer not found: %v", err)
+	existingCustomer, err := h.getCustomer(stub, req.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := services.CheckExpectedVersion("Customer", req.CustomerID, req.ExpectedVersion, existingCustomer.Version); err != nil {
+		return nil, err
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
 	}
 
 	// Create updated customer
-	updatedCustomer := existingCustomer
-	updatedCustomer.LastUpdated = time.Now()
+	updatedCustomer := *existingCustomer
+	updatedCustomer.LastUpdated = now
 	updatedCustomer.LastUpdatedBy = req.ActorID
 
+	// History of a resident customer's PII stays off the public ledger, as do the values of fields
+	// sealed with field-level encryption
+	piiHistory := func(value string) string {
+		if existingCustomer.Residency != "" {
+			return ""
+		}
+		return value
+	}
+	activeKey, err := h.fieldEncryptionService.ActiveKey(stub)
+	if err != nil {
+		return nil, err
+	}
+	encryptedHistory := func(value string) string {
+		if activeKey != nil || len(existingCustomer.EncryptedFields) > 0 {
+			return ""
+		}
+		return piiHistory(value)
+	}
+
 	// Apply updates
 	if req.FirstName != nil {
-		if err := h.recordCustomerHistory(stub, req.CustomerID, "UPDATE", "firstName", updatedCustomer.FirstName, *req.FirstName, req.ActorID); err != nil {
+		if err := h.recordCustomerHistory(stub, req.CustomerID, "UPDATE", "firstName", piiHistory(updatedCustomer.FirstName), piiHistory(*req.FirstName), req.ActorID); err != nil {
 			return nil, err
 		}
 		updatedCustomer.FirstName = *req.FirstName
 	}
 	if req.LastName != nil {
-		if err := h.recordCustomerHistory(stub, req.CustomerID, "UPDATE", "lastName", updatedCustomer.LastName, *req.LastName, req.ActorID); err != nil {
+		if err := h.recordCustomerHistory(stub, req.CustomerID, "UPDATE", "lastName", piiHistory(updatedCustomer.LastName), piiHistory(*req.LastName), req.ActorID); err != nil {
 			return nil, err
 		}
 		updatedCustomer.LastName = *req.LastName
 	}
 	if req.Email != nil {
-		if err := h.recordCustomerHistory(stub, req.CustomerID, "UPDATE", "email", updatedCustomer.Email, *req.Email, req.ActorID); err != nil {
+		if err := h.recordCustomerHistory(stub, req.CustomerID, "UPDATE", "email", encryptedHistory(updatedCustomer.Email), encryptedHistory(*req.Email), req.ActorID); err != nil {
 			return nil, err
 		}
 		updatedCustomer.Email = *req.Email
 	}
 	if req.Phone != nil {
-		if err := h.recordCustomerHistory(stub, req.CustomerID, "UPDATE", "phone", updatedCustomer.Phone, *req.Phone, req.ActorID); err != nil {
+		if err := h.recordCustomerHistory(stub, req.CustomerID, "UPDATE", "phone", encryptedHistory(updatedCustomer.Phone), encryptedHistory(*req.Phone), req.ActorID); err != nil {
 			return nil, err
 		}
 		updatedCustomer.Phone = *req.Phone
 	}
 	if req.Address != nil {
-		if err := h.recordCustomerHistory(stub, req.CustomerID, "UPDATE", "address", updatedCustomer.Address, *req.Address, req.ActorID); err != nil {
+		if err := h.recordCustomerHistory(stub, req.CustomerID, "UPDATE", "address", encryptedHistory(updatedCustomer.Address), encryptedHistory(*req.Address), req.ActorID); err != nil {
 			return nil, err
 		}
 		updatedCustomer.Address = *req.Address
@@ -170,50 +353,128 @@ func (h *CustomerHandler) UpdateCustomer(stub shim.ChaincodeStubInterface, args
 	}
 
 	// Validate the updated customer
-	if err := domain.ValidateCustomer(&updatedCustomer); err != nil {
-		return nil, fmt.Errorf("updated customer validation failed: %v", err)
+	if err := domain.ValidateCustomer(&updatedCustomer, now); err != nil {
+		return nil, fmt.Errorf("updated customer validation failed: %w", err)
+	}
+	if err := h.sealCustomerPII(stub, &updatedCustomer); err != nil {
+		return nil, err
 	}
 
 	// Store the updated customer
-	if err := h.persistenceService.Put(stub, customerKey, &updatedCustomer); err != nil {
-		return nil, fmt.Errorf("failed to update customer: %v", err)
+	if err := putCustomer(stub, h.persistenceService, h.residencyService, &updatedCustomer); err != nil {
+		return nil, fmt.Errorf("failed to update customer: %w", err)
+	}
+	if updatedCustomer.Residency != "" {
+		if err := h.residencyService.PutPII(stub, &updatedCustomer); err != nil {
+			return nil, err
+		}
 	}
 
-	// Emit event
-	if err := h.eventService.EmitCustomerUpdated(stub, &updatedCustomer, req.ActorID); err != nil {
-		return nil, fmt.Errorf("failed to emit event: %v", err)
+	// Withdrawn consent suspends the data-sharing clauses relying on it until it is granted again
+	withdrawn, granted := domain.ConsentChanges(existingCustomer.ConsentPreferences, updatedCustomer.ConsentPreferences)
+	_, suspensions, err := h.applyConsent(stub, req.CustomerID, withdrawn, false, domain.ConsentSourceUpdate, "", req.ActorID)
+	if err != nil {
+		return nil, err
+	}
+	if _, _, err := h.applyConsent(stub, req.CustomerID, granted, true, domain.ConsentSourceUpdate, "", req.ActorID); err != nil {
+		return nil, err
 	}
 
-	return json.Marshal(&updatedCustomer)
+	// A transaction carries one event, so partners are told of suspended clauses in place of the
+	// update event; the withdrawal event names the customer
+	if len(suspensions) > 0 {
+		withdrawal := &domain.ConsentWithdrawal{CustomerID: req.CustomerID, Purposes: withdrawn, Suspensions: suspensions}
+		if err := h.eventService.EmitConsentWithdrawn(stub, withdrawal, req.ActorID); err != nil {
+			return nil, fmt.Errorf("failed to emit event: %w", err)
+		}
+	} else if err := h.eventService.EmitCustomerUpdated(stub, updatedCustomer.Public(), req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	// The response is recorded with the transaction, so a resident customer's PII is left out
+	return json.Marshal(updatedCustomer.Public())
 }
 
-// GetCustomer retrieves a customer by ID
+// GetCustomer retrieves a customer by ID, recording the read in the customer's access log
 func (h *CustomerHandler) GetCustomer(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
 	if len(args) != 1 {
-		return nil, fmt.Errorf("incorrect number of arguments. Expected 1, got %d", len(args))
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
 	}
 
-	customerID := args[0]
-	customerKey := fmt.Sprintf("CUSTOMER_%s", customerID)
+	customer, err := h.getCustomer(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	if err := h.recordAccess(stub, customer.CustomerID, "GetCustomer"); err != nil {
+		return nil, err
+	}
+	if err := h.openCustomerPII(stub, customer); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(customer)
+}
+
+// GetCustomerCrossResidency retrieves a customer's PII from outside their residency under a
+// documented legal basis. Each read is recorded against the customer.
+func (h *CustomerHandler) GetCustomerCrossResidency(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.CrossResidencyAccessRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse cross-residency access request: %v", err)
+	}
 
 	var customer domain.Customer
-	if err := h.persistenceService.Get(stub, customerKey, &customer); err != nil {
-		return nil, fmt.Errorf("customer not found: %v", err)
+	if err := h.persistenceService.Get(stub, fmt.Sprintf("CUSTOMER_%s", req.CustomerID), &customer); err != nil {
+		return nil, fmt.Errorf("customer not found: %w", err)
+	}
+	if customer.Residency == "" {
+		return nil, fmt.Errorf("customer %s has no residency; use GetCustomer", req.CustomerID)
+	}
+
+	if _, err := h.residencyService.RecordCrossResidencyAccess(stub, &customer, &req); err != nil {
+		return nil, err
+	}
+	if err := h.recordAccess(stub, customer.CustomerID, "GetCustomerCrossResidency"); err != nil {
+		return nil, err
+	}
+	if err := h.residencyService.LoadPII(stub, &customer); err != nil {
+		return nil, err
+	}
+	if err := h.openCustomerPII(stub, &customer); err != nil {
+		return nil, err
 	}
 
 	return json.Marshal(&customer)
 }
 
-// GetCustomerHistory retrieves the history of a customer
+// GetCustomerHistory retrieves the history of a customer. With the RECONCILE mode as a second
+// argument it returns a services.HistoryReconciliation instead, merging the recorded history with
+// the ledger history of the customer record and flagging where they disagree.
 func (h *CustomerHandler) GetCustomerHistory(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
-	if len(args) != 1 {
-		return nil, fmt.Errorf("incorrect number of arguments. Expected 1, got %d", len(args))
+	if len(args) != 1 && len(args) != 2 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1 or 2, got %d", len(args))
+	}
+	reconcile := len(args) == 2
+	if reconcile && args[1] != services.HistoryModeReconcile {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid history mode: %s", args[1])
 	}
 
 	customerID := args[0]
 	history, err := h.getEntityHistory(stub, customerID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get customer history: %v", err)
+		return nil, fmt.Errorf("failed to get customer history: %w", err)
+	}
+
+	if reconcile {
+		reconciliation, err := services.ReconcileHistory(stub, customerID, fmt.Sprintf("CUSTOMER_%s", customerID), history)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reconcile customer history: %w", err)
+		}
+		return json.Marshal(reconciliation)
 	}
 
 	return json.Marshal(history)
@@ -222,24 +483,32 @@ func (h *CustomerHandler) GetCustomerHistory(stub shim.ChaincodeStubInterface, a
 // UpdateCustomerStatus updates a customer's status
 func (h *CustomerHandler) UpdateCustomerStatus(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
 	if len(args) != 1 {
-		return nil, fmt.Errorf("incorrect number of arguments. Expected 1, got %d", len(args))
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
 	}
 
 	var req domain.CustomerStatusUpdateRequest
 	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
-		return nil, fmt.Errorf("failed to parse status update request: %v", err)
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse status update request: %v", err)
 	}
 
 	// Get existing customer
 	customerKey := fmt.Sprintf("CUSTOMER_%s", req.CustomerID)
 	var customer domain.Customer
 	if err := h.persistenceService.Get(stub, customerKey, &customer); err != nil {
-		return nil, fmt.Errorf("customer not found: %v", err)
+		return nil, fmt.Errorf("customer not found: %w", err)
+	}
+	if err := services.CheckExpectedVersion("Customer", req.CustomerID, req.ExpectedVersion, customer.Version); err != nil {
+		return nil, err
+	}
+
+	// The indexes are rebuilt from the full record; the response carries only the public one
+	if err := h.residencyService.LoadPII(stub, &customer); err != nil {
+		return nil, err
 	}
 
 	// Validate status transition
 	if err := validation.ValidateStatusTransition(string(customer.Status), string(req.NewStatus), "Customer"); err != nil {
-		return nil, fmt.Errorf("invalid status transition: %v", err)
+		return nil, fmt.Errorf("invalid status transition: %w", err)
 	}
 
 	// Record history
@@ -247,73 +516,455 @@ func (h *CustomerHandler) UpdateCustomerStatus(stub shim.ChaincodeStubInterface,
 		return nil, err
 	}
 
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
 	// Update status
 	customer.Status = req.NewStatus
-	customer.LastUpdated = time.Now()
+	customer.LastUpdated = now
 	customer.LastUpdatedBy = req.ActorID
 
-	// Store updated customer
-	if err := h.persistenceService.Put(stub, customerKey, &customer); err != nil {
-		return nil, fmt.Errorf("failed to update customer status: %v", err)
+	// Store updated customer; a resident customer's PII is left untouched in its collection
+	if err := putCustomer(stub, h.persistenceService, h.residencyService, &customer); err != nil {
+		return nil, fmt.Errorf("failed to update customer status: %w", err)
 	}
 
 	// Emit event
-	if err := h.eventService.EmitCustomerUpdated(stub, &customer, req.ActorID); err != nil {
-		return nil, fmt.Errorf("failed to emit event: %v", err)
+	if err := h.eventService.EmitCustomerUpdated(stub, customer.Public(), req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
 	}
 
-	return json.Marshal(&customer)
+	return json.Marshal(customer.Public())
 }
 
-// QueryCustomersByStatus queries customers by status
+// QueryCustomersByStatus returns a page of production customers in a status, redacted for the
+// invoker's role. Args: status [, pageSize [, bookmark]]
 func (h *CustomerHandler) QueryCustomersByStatus(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
-	if len(args) != 1 {
-		return nil, fmt.Errorf("incorrect number of arguments. Expected 1, got %d", len(args))
+	if len(args) < 1 || len(args) > 3 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1 to 3, got %d", len(args))
 	}
 
 	status := args[0]
 	
 	// Validate status
 	if err := validation.ValidateCustomerStatus(status); err != nil {
-		return nil, fmt.Errorf("invalid status: %v", err)
+		return nil, fmt.Errorf("invalid status: %w", err)
 	}
 
-	// Query customers by status using composite key
-	iterator, err := stub.GetStateByPartialCompositeKey("CUSTOMER_STATUS", []string{status})
-	if err != nil {
-		return nil, fmt.Errorf("failed to get customers by status: %v", err)
+	return h.queryCustomers(stub, "CUSTOMER_BY_STATUS", status, args[1:], true, func(customer *domain.Customer) bool {
+		return string(customer.Status) == status
+	})
+}
+
+// QueryCustomersByKYCStatus returns a page of customers whose latest KYC record is in a status.
+// Args: status [, pageSize [, bookmark]]
+func (h *CustomerHandler) QueryCustomersByKYCStatus(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) < 1 || len(args) > 3 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1 to 3, got %d", len(args))
 	}
-	defer iterator.Close()
 
-	var customers []domain.Customer
-	for iterator.HasNext() {
-		response, err := iterator.Next()
+	if err := validation.ValidateKYCStatus(args[0]); err != nil {
+		return nil, fmt.Errorf("invalid KYC status: %w", err)
+	}
+
+	return h.queryCustomers(stub, "CUSTOMER_BY_KYC_STATUS", args[0], args[1:], true, nil)
+}
+
+// QueryCustomersByAMLStatus returns a page of customers whose latest AML record is in a status.
+// Args: status [, pageSize [, bookmark]]
+func (h *CustomerHandler) QueryCustomersByAMLStatus(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) < 1 || len(args) > 3 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1 to 3, got %d", len(args))
+	}
+
+	if err := validation.ValidateAMLStatus(args[0]); err != nil {
+		return nil, fmt.Errorf("invalid AML status: %w", err)
+	}
+
+	return h.queryCustomers(stub, "CUSTOMER_BY_AML_STATUS", args[0], args[1:], true, nil)
+}
+
+// SearchCustomersByName returns a page of customers with a last name, or organizations with a
+// legal name, matched case-insensitively. Args: lastName [, pageSize [, bookmark]]
+func (h *CustomerHandler) SearchCustomersByName(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) < 1 || len(args) > 3 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1 to 3, got %d", len(args))
+	}
+
+	lastName := normalizeCustomerName(args[0])
+	if lastName == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "lastName", "lastName is required")
+	}
+
+	return h.queryCustomers(stub, "CUSTOMER_BY_LAST_NAME", lastName, args[1:], false, func(customer *domain.Customer) bool {
+		return customerNameIndexValue(customer) == lastName
+	})
+}
+
+// SearchCustomersByEmail returns customers registered with an email address. The index holds only
+// a hash of the address. Args: email [, pageSize [, bookmark]]
+func (h *CustomerHandler) SearchCustomersByEmail(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) < 1 || len(args) > 3 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1 to 3, got %d", len(args))
+	}
+
+	if strings.TrimSpace(args[0]) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "email", "email is required")
+	}
+	emailHash := customerEmailHash(args[0])
+
+	return h.queryCustomers(stub, "CUSTOMER_BY_EMAIL_HASH", emailHash, args[1:], false, func(customer *domain.Customer) bool {
+		return customerEmailIndexValue(customer) == emailHash
+	})
+}
+
+// PurgeSandboxCustomer deletes a sandbox customer with its KYC/AML records and history
+func (h *CustomerHandler) PurgeSandboxCustomer(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.SandboxCustomerPurgeRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse sandbox purge request: %v", err)
+	}
+	if req.CustomerID == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "customerID", "customerID is required")
+	}
+	if req.ActorID == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	customerKey := fmt.Sprintf("CUSTOMER_%s", req.CustomerID)
+	var customer domain.Customer
+	if err := h.persistenceService.Get(stub, customerKey, &customer); err != nil {
+		return nil, fmt.Errorf("customer not found: %w", err)
+	}
+	if !customer.Sandbox {
+		return nil, fmt.Errorf("customer %s is not a sandbox record", req.CustomerID)
+	}
+	if err := h.residencyService.LoadPII(stub, &customer); err != nil {
+		return nil, err
+	}
+
+	deleted := 0
+	deleteKey := func(key string) error {
+		if err := stub.DelState(key); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", key, err)
+		}
+		deleted++
+		return nil
+	}
+	deleteHistory := func(entityID string) error {
+		values, err := h.sandboxService.DeleteByPartialCompositeKey(stub, "HISTORY", []string{entityID})
+		deleted += len(values)
+		return err
+	}
+
+	// KYC and AML records referenced from the customer pointers, and those they superseded
+	for _, ref := range []struct{ pointer, prefix, index string }{
+		{fmt.Sprintf("CUSTOMER_KYC_%s", req.CustomerID), "KYC_", "CUSTOMER_KYC_RECORD"},
+		{fmt.Sprintf("CUSTOMER_AML_%s", req.CustomerID), "AML_", "CUSTOMER_AML_RECORD"},
+	} {
+		recordID, err := stub.GetState(ref.pointer)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s: %w", ref.pointer, err)
+		}
+		recordIDs, err := h.sandboxService.DeleteByPartialCompositeKey(stub, ref.index, []string{req.CustomerID})
+		deleted += len(recordIDs)
+		if err != nil {
+			return nil, err
+		}
+		for _, supersededID := range recordIDs {
+			if string(supersededID) == string(recordID) {
+				continue
+			}
+			if err := deleteKey(ref.prefix + string(supersededID)); err != nil {
+				return nil, err
+			}
+			if err := deleteHistory(string(supersededID)); err != nil {
+				return nil, err
+			}
+		}
+		if recordID == nil {
+			continue
+		}
+		if err := deleteKey(ref.prefix + string(recordID)); err != nil {
+			return nil, err
+		}
+		if err := deleteHistory(string(recordID)); err != nil {
+			return nil, err
+		}
+		if err := deleteKey(ref.pointer); err != nil {
+			return nil, err
+		}
+	}
+
+	// Listing indexes, including KYC/AML status entries under whichever status they hold
+	indexes := [][]string{
+		{"CUSTOMER_BY_STATUS", string(customer.Status)},
+		{"CUSTOMER_BY_EMAIL_HASH", customerEmailIndexValue(&customer)},
+	}
+	if nameIndexValue := customerNameIndexValue(&customer); nameIndexValue != "" {
+		indexes = append(indexes, []string{"CUSTOMER_BY_LAST_NAME", nameIndexValue})
+	}
+	for _, status := range []validation.KYCStatus{validation.KYCStatusPending, validation.KYCStatusVerified, validation.KYCStatusFailed, validation.KYCStatusExpired} {
+		indexes = append(indexes, []string{"CUSTOMER_BY_KYC_STATUS", string(status)})
+	}
+	for _, status := range []validation.AMLStatus{validation.AMLStatusClear, validation.AMLStatusFlagged, validation.AMLStatusReviewing, validation.AMLStatusBlocked} {
+		indexes = append(indexes, []string{"CUSTOMER_BY_AML_STATUS", string(status)})
+	}
+	for _, index := range indexes {
+		values, err := h.sandboxService.DeleteByPartialCompositeKey(stub, index[0], []string{index[1], req.CustomerID})
+		deleted += len(values)
 		if err != nil {
-			return nil, fmt.Errorf("failed to iterate customers: %v", err)
+			return nil, err
 		}
+	}
+
+	// Ownership links to and from the customer
+	removedLinks, err := h.ownershipService.DeleteLinks(stub, req.CustomerID)
+	deleted += 2 * removedLinks
+	if err != nil {
+		return nil, err
+	}
+
+	// Relationships the customer is party to, with their index entries under both customers
+	removedRelationships, err := h.relationshipService.DeleteForCustomer(stub, req.CustomerID)
+	deleted += 3 * removedRelationships
+	if err != nil {
+		return nil, err
+	}
+
+	// Reads of the customer recorded in its access log
+	accessEntries, err := h.sandboxService.DeleteByPartialCompositeKey(stub, "CUSTOMER_ACCESS_LOG", []string{req.CustomerID})
+	deleted += len(accessEntries)
+	if err != nil {
+		return nil, err
+	}
+
+	// The customer, its national ID index and history
+	if err := deleteHistory(req.CustomerID); err != nil {
+		return nil, err
+	}
+	if err := deleteKey(identityIndexKey(&customer)); err != nil {
+		return nil, err
+	}
+	if err := deleteKey(customerKey); err != nil {
+		return nil, err
+	}
+	if customer.Residency != "" {
+		if err := h.residencyService.DeletePII(stub, &customer); err != nil {
+			return nil, err
+		}
+		deleted++
+	}
+
+	result := map[string]interface{}{
+		"entityID":       req.CustomerID,
+		"entityType":     "Customer",
+		"recordsDeleted": deleted,
+	}
+
+	metadata := map[string]string{
+		"sandbox":        "true",
+		"recordsDeleted": fmt.Sprintf("%d", deleted),
+	}
+	payload := h.eventService.CreateEventPayloadWithMetadata(
+		config.EventSandboxRecordsPurged,
+		req.CustomerID,
+		"Customer",
+		req.ActorID,
+		result,
+		metadata,
+	)
+	if err := h.eventService.EmitEvent(stub, config.EventSandboxRecordsPurged, payload); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(result)
+}
+
+// Helper methods
 
+// queryCustomers reads a page of a customer index. The router masks the result for the invoker's
+// role. keep, when set, drops index entries that no longer match the customer.
+func (h *CustomerHandler) queryCustomers(stub shim.ChaincodeStubInterface, objectType, attribute string, pageArgs []string, excludeSandbox bool, keep func(*domain.Customer) bool) ([]byte, error) {
+	pageSize, bookmark, err := services.ParsePageArgs(pageArgs)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, nextBookmark, err := h.persistenceService.GetPageByPartialCompositeKeys(stub, objectType, [][]string{{attribute}}, pageSize, bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query customers: %w", err)
+	}
+
+	customers := []domain.Customer{}
+	for _, entry := range entries {
 		var customer domain.Customer
-		if err := json.Unmarshal(response.Value, &customer); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal customer: %v", err)
+		if err := h.persistenceService.Get(stub, fmt.Sprintf("CUSTOMER_%s", string(entry.Value)), &customer); err != nil {
+			continue // Skip if customer not found
+		}
+		if keep != nil && !keep(&customer) {
+			continue
+		}
+
+		if excludeSandbox && customer.Sandbox {
+			continue
 		}
 
 		customers = append(customers, customer)
 	}
 
-	return json.Marshal(customers)
+	return json.Marshal(&domain.CustomerQueryResult{Customers: customers, Count: len(customers), Bookmark: nextBookmark})
 }
 
-// Helper methods
+// putCustomer stores a customer and keeps the CUSTOMER_BY_STATUS, CUSTOMER_BY_LAST_NAME and
+// CUSTOMER_BY_EMAIL_HASH indexes in step with it, and advances the customer's version. Every write
+// of a customer must go through here.
+// A customer with a residency is stored without PII and left out of the last name index; callers
+// changing their PII write it with ResidencyService.PutPII.
+func putCustomer(stub shim.ChaincodeStubInterface, ps *services.PersistenceService, rs *customerServices.ResidencyService, customer *domain.Customer) error {
+	customerKey := fmt.Sprintf("CUSTOMER_%s", customer.CustomerID)
+
+	var previous domain.Customer
+	existing := ps.Get(stub, customerKey, &previous) == nil
+	if existing {
+		if err := rs.LoadPII(stub, &previous); err != nil {
+			return err
+		}
+	}
+	customer.Version = previous.Version + 1
+
+	if err := ps.Put(stub, customerKey, customer.Public()); err != nil {
+		return err
+	}
+
+	previousStatus, previousName, previousEmail := "", "", ""
+	if existing {
+		previousStatus = string(previous.Status)
+		previousName = customerNameIndexValue(&previous)
+		previousEmail = customerEmailIndexValue(&previous)
+	}
+
+	if err := moveCustomerIndex(stub, "CUSTOMER_BY_STATUS", previousStatus, string(customer.Status), customer.CustomerID); err != nil {
+		return err
+	}
+	if err := moveCustomerIndex(stub, "CUSTOMER_BY_LAST_NAME", previousName, customerNameIndexValue(customer), customer.CustomerID); err != nil {
+		return err
+	}
+	return moveCustomerIndex(stub, "CUSTOMER_BY_EMAIL_HASH", previousEmail, customerEmailIndexValue(customer), customer.CustomerID)
+}
+
+// getCustomer retrieves a customer with their PII, rejecting reads from outside their residency
+func (h *CustomerHandler) getCustomer(stub shim.ChaincodeStubInterface, customerID string) (*domain.Customer, error) {
+	var customer domain.Customer
+	if err := h.persistenceService.Get(stub, fmt.Sprintf("CUSTOMER_%s", customerID), &customer); err != nil {
+		return nil, fmt.Errorf("customer not found: %w", err)
+	}
+
+	if err := h.residencyService.CheckAccess(stub, &customer); err != nil {
+		return nil, err
+	}
+	if err := h.residencyService.LoadPII(stub, &customer); err != nil {
+		return nil, err
+	}
+	return &customer, nil
+}
+
+// moveCustomerIndex moves a customer's entry in a two-part composite index from one value to
+// another. An empty previous value only adds the new entry.
+func moveCustomerIndex(stub shim.ChaincodeStubInterface, objectType, previousValue, newValue, customerID string) error {
+	if previousValue == newValue {
+		return nil
+	}
+
+	if previousValue != "" {
+		previousKey, err := stub.CreateCompositeKey(objectType, []string{previousValue, customerID})
+		if err != nil {
+			return fmt.Errorf("failed to create composite key: %w", err)
+		}
+		if err := stub.DelState(previousKey); err != nil {
+			return fmt.Errorf("failed to remove %s index: %w", objectType, err)
+		}
+	}
+
+	indexKey, err := stub.CreateCompositeKey(objectType, []string{newValue, customerID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := stub.PutState(indexKey, []byte(customerID)); err != nil {
+		return fmt.Errorf("failed to create %s index: %w", objectType, err)
+	}
+	return nil
+}
+
+// normalizeCustomerName folds a name for case-insensitive index lookups
+func normalizeCustomerName(name string) string {
+	return strings.ToUpper(strings.TrimSpace(name))
+}
+
+// customerNameIndexValue returns the value a customer is indexed under by last name, or an
+// organization by legal name. Names of individuals with a residency are PII kept off the public
+// ledger, so they are not indexed.
+func customerNameIndexValue(customer *domain.Customer) string {
+	if customer.IsOrganization() {
+		return normalizeCustomerName(customer.LegalName)
+	}
+	if customer.Residency != "" {
+		return ""
+	}
+	return normalizeCustomerName(customer.LastName)
+}
+
+// nationalIDIndexKey returns the national ID index key. Customers with a residency are indexed
+// under a hash of their national ID.
+func nationalIDIndexKey(residency, nationalID string) string {
+	if residency != "" {
+		return fmt.Sprintf("CUSTOMER_BY_NATIONAL_ID_%s", utils.HashValue(nationalID))
+	}
+	return fmt.Sprintf("CUSTOMER_BY_NATIONAL_ID_%s", nationalID)
+}
+
+// registrationNumberIndexKey returns the key an organization is indexed under by registration number
+func registrationNumberIndexKey(registrationNumber string) string {
+	return fmt.Sprintf("CUSTOMER_BY_REGISTRATION_NUMBER_%s", registrationNumber)
+}
+
+// identityIndexKey returns the unique index key of a customer: their national ID, or an
+// organization's registration number
+func identityIndexKey(customer *domain.Customer) string {
+	if customer.IsOrganization() {
+		return registrationNumberIndexKey(customer.RegistrationNumber)
+	}
+	return nationalIDIndexKey(customer.Residency, customer.NationalID)
+}
+
+// customerEmailHash hashes a normalised email address so the index key holds no plain PII
+func customerEmailHash(email string) string {
+	return utils.HashValue(strings.ToLower(strings.TrimSpace(email)))
+}
 
 func (h *CustomerHandler) recordCustomerHistory(stub shim.ChaincodeStubInterface, customerID, changeType, fieldName, previousValue, newValue, actorID string) error {
-	historyID := utils.GenerateID(config.HistoryPrefix)
+	historyID, compositeKey, err := services.NewHistoryKey(stub, customerID)
+	if err != nil {
+		return err
+	}
 	txID := stub.GetTxID()
 
+	timestamp, err := services.TxTimeString(stub)
+	if err != nil {
+		return err
+	}
+
 	historyEntry := map[string]interface{}{
 		"historyID":     historyID,
 		"entityID":      customerID,
 		"entityType":    "Customer",
-		"timestamp":     utils.GetCurrentTimeString(),
+		"timestamp":     timestamp,
 		"changeType":    changeType,
 		"fieldName":     fieldName,
 		"previousValue": previousValue,
@@ -322,18 +973,13 @@ func (h *CustomerHandler) recordCustomerHistory(stub shim.ChaincodeStubInterface
 		"transactionID": txID,
 	}
 
-	compositeKey, err := stub.CreateCompositeKey("HISTORY", []string{customerID, historyID})
-	if err != nil {
-		return fmt.Errorf("failed to create composite key: %v", err)
-	}
-
 	return h.persistenceService.Put(stub, compositeKey, historyEntry)
 }
 
 func (h *CustomerHandler) getEntityHistory(stub shim.ChaincodeStubInterface, entityID string) ([]interface{}, error) {
 	iterator, err := stub.GetStateByPartialCompositeKey("HISTORY", []string{entityID})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get history iterator: %v", err)
+		return nil, fmt.Errorf("failed to get history iterator: %w", err)
 	}
 	defer iterator.Close()
 
@@ -341,12 +987,12 @@ func (h *CustomerHandler) getEntityHistory(stub shim.ChaincodeStubInterface, ent
 	for iterator.HasNext() {
 		response, err := iterator.Next()
 		if err != nil {
-			return nil, fmt.Errorf("failed to iterate history: %v", err)
+			return nil, fmt.Errorf("failed to iterate history: %w", err)
 		}
 
 		var entry interface{}
 		if err := json.Unmarshal(response.Value, &entry); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal history entry: %v", err)
+			return nil, fmt.Errorf("failed to unmarshal history entry: %w", err)
 		}
 
 		history = append(history, entry)
@@ -0,0 +1,239 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+)
+
+// CreateRelationship links two customers, such as the holders of a joint account or members of a
+// household. The same two customers may hold only one relationship of each type at a time.
+func (h *CustomerHandler) CreateRelationship(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.RelationshipRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse relationship request: %v", err)
+	}
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	relationship := &domain.CustomerRelationship{
+		CustomerID:        req.CustomerID,
+		RelatedCustomerID: req.RelatedCustomerID,
+		RelationshipType:  req.RelationshipType,
+		Direction:         domain.RelationshipDirections[req.RelationshipType],
+		Status:            domain.RelationshipStatusActive,
+		EffectiveFrom:     now,
+		EffectiveTo:       req.EffectiveTo,
+		CreatedBy:         req.ActorID,
+		CreatedDate:       now,
+		TransactionID:     stub.GetTxID(),
+	}
+	if req.EffectiveFrom != nil {
+		relationship.EffectiveFrom = *req.EffectiveFrom
+	}
+	if err := domain.ValidateRelationship(relationship); err != nil {
+		return nil, err
+	}
+
+	for _, customerID := range []string{req.CustomerID, req.RelatedCustomerID} {
+		var customer domain.Customer
+		if err := h.persistenceService.Get(stub, fmt.Sprintf("CUSTOMER_%s", customerID), &customer); err != nil {
+			return nil, fmt.Errorf("customer %s not found: %w", customerID, err)
+		}
+	}
+
+	existing, err := h.relationshipService.ForCustomer(stub, req.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	for _, other := range existing {
+		if other.Status != domain.RelationshipStatusActive || !other.Links(req.CustomerID, req.RelatedCustomerID, req.RelationshipType) {
+			continue
+		}
+		// A fixed-term relationship may be followed by another once it has run its course
+		if other.EffectiveTo == nil || other.EffectiveTo.After(relationship.EffectiveFrom) {
+			return nil, fmt.Errorf("%s and %s are already related as %s by relationship %s", req.CustomerID, req.RelatedCustomerID, req.RelationshipType, other.RelationshipID)
+		}
+	}
+
+	relationship.RelationshipID = services.GenerateDeterministicID(stub, config.CustomerRelationshipPrefix)
+	if err := h.relationshipService.Put(stub, relationship); err != nil {
+		return nil, err
+	}
+
+	relationshipJSON, _ := utils.MarshalJSONString(relationship)
+	for _, customerID := range []string{relationship.CustomerID, relationship.RelatedCustomerID} {
+		if err := h.recordCustomerHistory(stub, customerID, "UPDATE", "relationship", "", relationshipJSON, req.ActorID); err != nil {
+			return nil, fmt.Errorf("failed to record history: %w", err)
+		}
+	}
+
+	if err := h.eventService.EmitRelationshipCreated(stub, relationship, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(relationship)
+}
+
+// TerminateRelationship ends a customer relationship, such as when a joint account is closed or a
+// household member moves out. The relationship is kept, in effect until its end date.
+func (h *CustomerHandler) TerminateRelationship(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.RelationshipTerminationRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse relationship termination request: %v", err)
+	}
+	if strings.TrimSpace(req.RelationshipID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "relationshipID", "relationshipID is required")
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "reason", "reason is required")
+	}
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	relationship, err := h.relationshipService.Get(stub, req.RelationshipID)
+	if err != nil {
+		return nil, err
+	}
+	if relationship.Status == domain.RelationshipStatusTerminated {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "relationship %s is already terminated", req.RelationshipID)
+	}
+	previousJSON, _ := utils.MarshalJSONString(relationship)
+
+	effectiveTo, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	if req.EffectiveTo != nil {
+		effectiveTo = *req.EffectiveTo
+	}
+	// Ending a relationship at its start, such as one recorded in error, leaves it never in effect
+	if effectiveTo.Before(relationship.EffectiveFrom) {
+		return nil, fmt.Errorf("effectiveTo cannot be before the relationship's effectiveFrom %s", relationship.EffectiveFrom.Format(time.RFC3339))
+	}
+	// A fixed-term relationship already due to end sooner keeps its earlier end
+	if relationship.EffectiveTo == nil || effectiveTo.Before(*relationship.EffectiveTo) {
+		relationship.EffectiveTo = &effectiveTo
+	}
+	relationship.Status = domain.RelationshipStatusTerminated
+	relationship.TerminatedBy = req.ActorID
+	relationship.TerminationReason = req.Reason
+	relationship.TransactionID = stub.GetTxID()
+
+	if err := h.relationshipService.Put(stub, relationship); err != nil {
+		return nil, err
+	}
+
+	relationshipJSON, _ := utils.MarshalJSONString(relationship)
+	for _, customerID := range []string{relationship.CustomerID, relationship.RelatedCustomerID} {
+		if err := h.recordCustomerHistory(stub, customerID, "UPDATE", "relationship", previousJSON, relationshipJSON, req.ActorID); err != nil {
+			return nil, fmt.Errorf("failed to record history: %w", err)
+		}
+	}
+
+	if err := h.eventService.EmitRelationshipTerminated(stub, relationship, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(relationship)
+}
+
+// GetRelationship retrieves a customer relationship by ID
+func (h *CustomerHandler) GetRelationship(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	relationship, err := h.relationshipService.Get(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(relationship)
+}
+
+// GetCustomerRelationships returns every relationship a customer is party to, whichever side of
+// it they are on, including terminated ones. Args: customerID
+func (h *CustomerHandler) GetCustomerRelationships(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var customer domain.Customer
+	if err := h.persistenceService.Get(stub, fmt.Sprintf("CUSTOMER_%s", args[0]), &customer); err != nil {
+		return nil, fmt.Errorf("customer not found: %w", err)
+	}
+
+	relationships, err := h.relationshipService.ForCustomer(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&domain.CustomerRelationshipsResult{CustomerID: args[0], Relationships: relationships, Count: len(relationships)})
+}
+
+// GetRelatedParties returns the customers related to a customer through relationships in effect,
+// directly or through other customers, with each party's latest AML status and risk tier, for
+// risk assessments and investigations of a flagged customer. The as-of time is an RFC 3339
+// timestamp or a date meaning the end of that UTC day, and defaults to now.
+// Args: customerID [, maxDepth [, asOf]]
+func (h *CustomerHandler) GetRelatedParties(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) < 1 || len(args) > 3 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1 to 3, got %d", len(args))
+	}
+
+	maxDepth := config.DefaultRelationshipDepth
+	if len(args) > 1 && args[1] != "" {
+		depth, err := strconv.Atoi(args[1])
+		if err != nil || depth < 1 || depth > config.MaxRelationshipDepth {
+			return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid maxDepth %s: must be between 1 and %d", args[1], config.MaxRelationshipDepth)
+		}
+		maxDepth = depth
+	}
+
+	asOf, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	if len(args) > 2 && args[2] != "" {
+		asOf, err = time.Parse(time.RFC3339, args[2])
+		if err != nil {
+			date, dateErr := time.Parse("2006-01-02", args[2])
+			if dateErr != nil {
+				return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid as-of time %s: expected RFC 3339 or YYYY-MM-DD", args[2])
+			}
+			asOf = date.AddDate(0, 0, 1).Add(-time.Nanosecond)
+		}
+	}
+
+	var customer domain.Customer
+	if err := h.persistenceService.Get(stub, fmt.Sprintf("CUSTOMER_%s", args[0]), &customer); err != nil {
+		return nil, fmt.Errorf("customer not found: %w", err)
+	}
+
+	parties, err := h.relationshipService.RelatedParties(stub, args[0], maxDepth, asOf)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(&domain.RelatedPartiesResult{CustomerID: args[0], AsOf: asOf, MaxDepth: maxDepth, Parties: parties, Count: len(parties)})
+}
@@ -0,0 +1,188 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	customerServices "github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// RiskRatingHandler handles customer risk ratings and the enhanced due diligence queue built on them
+type RiskRatingHandler struct {
+	persistenceService *services.PersistenceService
+	riskRatingService  *customerServices.RiskRatingService
+	relationshipService *customerServices.RelationshipService
+	eventService       *customerServices.EventService
+}
+
+// NewRiskRatingHandler creates a new risk rating handler
+func NewRiskRatingHandler() *RiskRatingHandler {
+	return &RiskRatingHandler{
+		persistenceService: services.NewPersistenceService(),
+		riskRatingService:  customerServices.NewRiskRatingService(),
+		relationshipService: customerServices.NewRelationshipService(),
+		eventService:       customerServices.NewEventService(),
+	}
+}
+
+// RecalculateCustomerRisk rates a customer from their latest AML check, residency, loan holdings,
+// recent loan transactions and directly related customers. It is run on onboarding, after
+// material changes and, for periodic review, by a scheduler once a profile's nextReviewDate has
+// passed. A tier change emits CustomerRiskTierChanged.
+func (h *RiskRatingHandler) RecalculateCustomerRisk(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.RiskRecalculationRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse risk recalculation request: %v", err)
+	}
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	var customer domain.Customer
+	if err := h.persistenceService.Get(stub, fmt.Sprintf("CUSTOMER_%s", req.CustomerID), &customer); err != nil {
+		return nil, fmt.Errorf("customer not found: %w", err)
+	}
+
+	// Latest AML record, if any
+	var amlRecord *domain.AMLRecord
+	amlID, err := stub.GetState(fmt.Sprintf("CUSTOMER_AML_%s", req.CustomerID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customer AML index: %w", err)
+	}
+	if amlID != nil {
+		amlRecord = &domain.AMLRecord{}
+		if err := h.persistenceService.Get(stub, fmt.Sprintf("AML_%s", string(amlID)), amlRecord); err != nil {
+			return nil, fmt.Errorf("AML record not found: %w", err)
+		}
+	}
+
+	holdings, err := h.riskRatingService.GetHoldings(stub, req.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+
+	previous, err := h.riskRatingService.GetProfile(stub, req.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	relatedParties, err := h.relationshipService.RelatedParties(stub, req.CustomerID, 1, now)
+	if err != nil {
+		return nil, err
+	}
+
+	score, drivers := h.riskRatingService.Score(&customer, amlRecord, holdings, relatedParties)
+	tier := domain.CustomerRiskTierFor(score)
+	profile := &domain.CustomerRiskProfile{
+		CustomerID:     req.CustomerID,
+		RiskScore:      score,
+		RiskTier:       tier,
+		Drivers:        drivers,
+		LastAssessed:   now,
+		NextReviewDate: tier.NextReviewDate(now),
+		AssessedBy:     req.ActorID,
+		TransactionID:  stub.GetTxID(),
+	}
+	if previous != nil {
+		profile.PreviousRiskTier = previous.RiskTier
+	}
+	if amlRecord != nil {
+		profile.AMLID = amlRecord.AMLID
+	}
+
+	if err := h.riskRatingService.PutProfile(stub, profile); err != nil {
+		return nil, err
+	}
+	if err := moveCustomerIndex(stub, "CUSTOMER_BY_RISK_TIER", string(profile.PreviousRiskTier), string(profile.RiskTier), req.CustomerID); err != nil {
+		return nil, err
+	}
+
+	if profile.TierChanged() {
+		if err := h.eventService.EmitCustomerRiskTierChanged(stub, profile, req.ActorID); err != nil {
+			return nil, fmt.Errorf("failed to emit event: %w", err)
+		}
+	}
+
+	return json.Marshal(profile)
+}
+
+// GetCustomerRiskProfile retrieves a customer's current risk profile
+func (h *RiskRatingHandler) GetCustomerRiskProfile(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	profile, err := h.riskRatingService.GetProfile(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+	if profile == nil {
+		return nil, fmt.Errorf("customer %s has no risk profile", args[0])
+	}
+
+	return json.Marshal(profile)
+}
+
+// QueryCustomersAboveRiskTier returns a page of risk profiles of customers rated strictly above a
+// tier, e.g. MEDIUM lists the HIGH and VERY_HIGH customers due enhanced due diligence.
+// Args: riskTier [, pageSize [, bookmark]]
+func (h *RiskRatingHandler) QueryCustomersAboveRiskTier(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) < 1 || len(args) > 3 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1 to 3, got %d", len(args))
+	}
+
+	floor := domain.CustomerRiskTier(args[0])
+	if floor.Rank() < 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid risk tier %s", args[0])
+	}
+
+	pageSize, bookmark, err := services.ParsePageArgs(args[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	// Partial keys must be in ascending order for the bookmark to work
+	tiers := []string{}
+	for _, tier := range domain.CustomerRiskTiers[floor.Rank()+1:] {
+		tiers = append(tiers, string(tier))
+	}
+	sort.Strings(tiers)
+	partialKeys := [][]string{}
+	for _, tier := range tiers {
+		partialKeys = append(partialKeys, []string{tier})
+	}
+
+	entries, nextBookmark, err := h.persistenceService.GetPageByPartialCompositeKeys(stub, "CUSTOMER_BY_RISK_TIER", partialKeys, pageSize, bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get customers by risk tier: %w", err)
+	}
+
+	profiles := []domain.CustomerRiskProfile{}
+	for _, entry := range entries {
+		profile, err := h.riskRatingService.GetProfile(stub, string(entry.Value))
+		if err != nil {
+			return nil, err
+		}
+		// Skip entries left behind by a tier change earlier in the same transaction
+		if profile == nil || profile.RiskTier.Rank() <= floor.Rank() {
+			continue
+		}
+		profiles = append(profiles, *profile)
+	}
+
+	return json.Marshal(&domain.RiskProfileQueryResult{Profiles: profiles, Count: len(profiles), Bookmark: nextBookmark})
+}
@@ -0,0 +1,141 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// accessLogObjectType namespaces access log entries, keyed CUSTOMER_ACCESS_LOG~customerID~txID
+const accessLogObjectType = "CUSTOMER_ACCESS_LOG"
+
+// AccessLogService records each read of a customer's PII against the customer, with the purpose
+// the client declared and the legal basis for it
+type AccessLogService struct {
+	persistenceService *services.PersistenceService
+	identityService    *services.IdentityService
+	consentService     *ConsentService
+}
+
+// NewAccessLogService creates a new access log service
+func NewAccessLogService() *AccessLogService {
+	return &AccessLogService{
+		persistenceService: services.NewPersistenceService(),
+		identityService:    services.NewIdentityService(),
+		consentService:     NewConsentService(),
+	}
+}
+
+// Record logs a read of a customer's PII by the invoker through a function. The purpose comes
+// from transient data; a purpose relying on consent is refused unless the customer's current
+// grant of it is in force.
+func (s *AccessLogService) Record(stub shim.ChaincodeStubInterface, customerID, function string) (*domain.AccessLogEntry, error) {
+	transient, err := stub.GetTransient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transient data: %w", err)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &domain.AccessLogEntry{
+		CustomerID:    customerID,
+		Function:      function,
+		Purpose:       config.AccessPurposeUnspecified,
+		AccessDate:    now,
+		TransactionID: stub.GetTxID(),
+	}
+
+	if purpose, ok := transient[config.AccessPurposeTransientKey]; ok {
+		basis, known := config.AccessPurposes[string(purpose)]
+		if !known {
+			return nil, fmt.Errorf("access purpose %s is not recognised", string(purpose))
+		}
+		entry.Purpose = string(purpose)
+		entry.LegalBasis = basis.LegalBasis
+
+		if basis.Consent != "" {
+			consent, err := s.consentService.Latest(stub, customerID, basis.Consent)
+			if err != nil {
+				return nil, err
+			}
+			if consent == nil || !consent.Granted {
+				return nil, fmt.Errorf("customer %s has not granted consent %s required for %s access", customerID, basis.Consent, entry.Purpose)
+			}
+			if consent.ExpiryDate != nil && now.After(*consent.ExpiryDate) {
+				return nil, fmt.Errorf("consent %s of customer %s expired on %s", basis.Consent, customerID, consent.ExpiryDate.Format("2006-01-02"))
+			}
+			entry.ConsentPurpose = basis.Consent
+			entry.ConsentVersion = consent.Version
+		}
+	}
+
+	if actorID, ok := transient[config.AccessActorTransientKey]; ok {
+		if err := s.identityService.VerifyActor(stub, string(actorID)); err != nil {
+			return nil, err
+		}
+		entry.ActorID = string(actorID)
+	}
+
+	if entry.InvokerID, err = services.InvokerID(stub); err != nil {
+		return nil, err
+	}
+	if entry.InvokerMSPID, err = services.InvokerMSPID(stub); err != nil {
+		return nil, err
+	}
+	if entry.InvokerRole, err = services.InvokerRole(stub); err != nil {
+		return nil, err
+	}
+
+	entryKey, err := stub.CreateCompositeKey(accessLogObjectType, []string{customerID, entry.TransactionID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := s.persistenceService.Put(stub, entryKey, entry); err != nil {
+		return nil, fmt.Errorf("failed to record access: %w", err)
+	}
+
+	return entry, nil
+}
+
+// ForCustomer returns the reads of a customer's PII made within a period, oldest first. A nil
+// bound leaves the period open at that end.
+func (s *AccessLogService) ForCustomer(stub shim.ChaincodeStubInterface, customerID string, from, to *time.Time) ([]domain.AccessLogEntry, error) {
+	iterator, err := stub.GetStateByPartialCompositeKey(accessLogObjectType, []string{customerID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query access log: %w", err)
+	}
+	defer iterator.Close()
+
+	entries := []domain.AccessLogEntry{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate access log: %w", err)
+		}
+		var entry domain.AccessLogEntry
+		if err := json.Unmarshal(response.Value, &entry); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal access log entry: %w", err)
+		}
+		if from != nil && entry.AccessDate.Before(*from) {
+			continue
+		}
+		if to != nil && entry.AccessDate.After(*to) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		return entries[i].AccessDate.Before(entries[j].AccessDate)
+	})
+	return entries, nil
+}
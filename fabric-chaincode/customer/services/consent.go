@@ -0,0 +1,195 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// consentExpiryDateFormat is the day bucket used by the CONSENT_EXPIRY_ index; keys sort by expiry day
+const consentExpiryDateFormat = "2006-01-02"
+
+// ConsentService stores consent records under CONSENT~customerID~purpose~version. Versions are
+// zero-padded so a purpose's records iterate in the order they were made. The current grant of
+// each purpose is also indexed under CONSENT_EXPIRY_<day>_customerID_purpose until it expires.
+type ConsentService struct {
+	persistenceService *services.PersistenceService
+}
+
+// NewConsentService creates a new consent service
+func NewConsentService() *ConsentService {
+	return &ConsentService{
+		persistenceService: services.NewPersistenceService(),
+	}
+}
+
+// Record appends the next version of a customer's consent to a purpose. A grant runs for
+// config.ConsentValidityPeriod from when it is recorded and replaces the previous one in the
+// expiry index.
+func (s *ConsentService) Record(stub shim.ChaincodeStubInterface, customerID, purpose string, granted bool, source, evidence, actorID string) (*domain.ConsentRecord, error) {
+	latest, err := s.Latest(stub, customerID, purpose)
+	if err != nil {
+		return nil, err
+	}
+	version := 1
+	if latest != nil {
+		version = latest.Version + 1
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	record := &domain.ConsentRecord{
+		CustomerID:    customerID,
+		Purpose:       purpose,
+		Version:       version,
+		Granted:       granted,
+		Source:        source,
+		Evidence:      evidence,
+		EffectiveDate: now,
+		RecordedBy:    actorID,
+		TransactionID: stub.GetTxID(),
+	}
+	if granted {
+		expiryDate := now.Add(config.ConsentValidityPeriod)
+		record.ExpiryDate = &expiryDate
+	}
+	consentKey, err := stub.CreateCompositeKey("CONSENT", []string{customerID, purpose, fmt.Sprintf("%06d", version)})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := s.persistenceService.Put(stub, consentKey, record); err != nil {
+		return nil, fmt.Errorf("failed to store consent record: %w", err)
+	}
+
+	if latest != nil && latest.ExpiryDate != nil {
+		if err := stub.DelState(consentExpiryKey(customerID, purpose, *latest.ExpiryDate)); err != nil {
+			return nil, fmt.Errorf("failed to remove consent expiry entry: %w", err)
+		}
+	}
+	if record.ExpiryDate != nil {
+		entry := &domain.ConsentExpiryEntry{CustomerID: customerID, Purpose: purpose, Version: version, ExpiryDate: *record.ExpiryDate}
+		if err := s.PutExpiryEntry(stub, entry); err != nil {
+			return nil, err
+		}
+	}
+	return record, nil
+}
+
+// GetExpiring returns the expiry entries of grants expiring at or before until, soonest first.
+// Grants already past expiry stay in the index until the expiry process lapses them.
+func (s *ConsentService) GetExpiring(stub shim.ChaincodeStubInterface, until time.Time) ([]domain.ConsentExpiryEntry, error) {
+	endKey := "CONSENT_EXPIRY_" + until.UTC().AddDate(0, 0, 1).Format(consentExpiryDateFormat)
+	iterator, err := stub.GetStateByRange("CONSENT_EXPIRY_", endKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query consent expiry index: %w", err)
+	}
+	defer iterator.Close()
+
+	entries := []domain.ConsentExpiryEntry{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate consent expiry index: %w", err)
+		}
+		var entry domain.ConsentExpiryEntry
+		if err := json.Unmarshal(response.Value, &entry); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal consent expiry entry: %w", err)
+		}
+		if entry.ExpiryDate.After(until) {
+			continue
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// PutExpiryEntry stores a grant's entry in the expiry index
+func (s *ConsentService) PutExpiryEntry(stub shim.ChaincodeStubInterface, entry *domain.ConsentExpiryEntry) error {
+	if err := s.persistenceService.Put(stub, consentExpiryKey(entry.CustomerID, entry.Purpose, entry.ExpiryDate), entry); err != nil {
+		return fmt.Errorf("failed to store consent expiry entry: %w", err)
+	}
+	return nil
+}
+
+// Latest returns a customer's current consent record for a purpose, or nil when none was recorded
+func (s *ConsentService) Latest(stub shim.ChaincodeStubInterface, customerID, purpose string) (*domain.ConsentRecord, error) {
+	var latest *domain.ConsentRecord
+	err := s.scan(stub, customerID, purpose, func(record *domain.ConsentRecord) bool {
+		latest = record
+		return true
+	})
+	return latest, err
+}
+
+// At returns the consent record for a purpose that was in force at an instant, or nil when none
+// had been recorded by then
+func (s *ConsentService) At(stub shim.ChaincodeStubInterface, customerID, purpose string, asOf time.Time) (*domain.ConsentRecord, error) {
+	var inForce *domain.ConsentRecord
+	err := s.scan(stub, customerID, purpose, func(record *domain.ConsentRecord) bool {
+		if record.EffectiveDate.After(asOf) {
+			return false
+		}
+		inForce = record
+		return true
+	})
+	return inForce, err
+}
+
+// GetHistory returns a page of a customer's consent records, for every purpose or for one
+func (s *ConsentService) GetHistory(stub shim.ChaincodeStubInterface, customerID, purpose string, pageSize int, bookmark string) (*domain.ConsentHistoryResult, error) {
+	attributes := []string{customerID}
+	if purpose != "" {
+		attributes = append(attributes, purpose)
+	}
+	entries, nextBookmark, err := s.persistenceService.GetPageByPartialCompositeKeys(stub, "CONSENT", [][]string{attributes}, pageSize, bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query consent records: %w", err)
+	}
+
+	records := []domain.ConsentRecord{}
+	for _, entry := range entries {
+		var record domain.ConsentRecord
+		if err := json.Unmarshal(entry.Value, &record); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal consent record: %w", err)
+		}
+		records = append(records, record)
+	}
+	return &domain.ConsentHistoryResult{Records: records, Count: len(records), Bookmark: nextBookmark}, nil
+}
+
+// scan calls fn with a purpose's consent records, oldest first, until it returns false
+func (s *ConsentService) scan(stub shim.ChaincodeStubInterface, customerID, purpose string, fn func(*domain.ConsentRecord) bool) error {
+	iterator, err := stub.GetStateByPartialCompositeKey("CONSENT", []string{customerID, purpose})
+	if err != nil {
+		return fmt.Errorf("failed to query consent records: %w", err)
+	}
+	defer iterator.Close()
+
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return fmt.Errorf("failed to iterate consent records: %w", err)
+		}
+		var record domain.ConsentRecord
+		if err := json.Unmarshal(response.Value, &record); err != nil {
+			return fmt.Errorf("failed to unmarshal consent record: %w", err)
+		}
+		if !fn(&record) {
+			return nil
+		}
+	}
+	return nil
+}
+
+// consentExpiryKey returns the expiry index key of a grant
+func consentExpiryKey(customerID, purpose string, expiryDate time.Time) string {
+	return fmt.Sprintf("CONSENT_EXPIRY_%s_%s_%s", expiryDate.UTC().Format(consentExpiryDateFormat), customerID, purpose)
+}
@@ -0,0 +1,144 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/interfaces"
+)
+
+// CustomerActivityService reads a customer's activity held by the loan and compliance chaincodes
+type CustomerActivityService struct {
+	loanChaincodeName       string
+	complianceChaincodeName string
+}
+
+// NewCustomerActivityService creates a new customer activity service
+func NewCustomerActivityService() *CustomerActivityService {
+	return &CustomerActivityService{
+		loanChaincodeName:       config.LoanChaincodeName,
+		complianceChaincodeName: config.ComplianceChaincodeName,
+	}
+}
+
+// GetLoanMilestones fetches the statuses reached by the customer's loans from the loan chaincode
+func (s *CustomerActivityService) GetLoanMilestones(stub shim.ChaincodeStubInterface, customerID string) ([]interfaces.CustomerLoanMilestone, error) {
+	response := stub.InvokeChaincode(s.loanChaincodeName, [][]byte{
+		[]byte("GetCustomerLoanMilestones"),
+		[]byte(customerID),
+	}, "")
+	if response.Status != shim.OK {
+		return nil, fmt.Errorf("failed to get loan milestones of customer %s from %s chaincode: %s", customerID, s.loanChaincodeName, response.Message)
+	}
+
+	var milestones []interfaces.CustomerLoanMilestone
+	if err := json.Unmarshal(response.Payload, &milestones); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal loan milestones: %w", err)
+	}
+	return milestones, nil
+}
+
+// GetComplianceEvents fetches the compliance events raised against the customer
+func (s *CustomerActivityService) GetComplianceEvents(stub shim.ChaincodeStubInterface, customerID string) ([]interfaces.ComplianceEventSummary, error) {
+	response := stub.InvokeChaincode(s.complianceChaincodeName, [][]byte{
+		[]byte("GetEventsByEntity"),
+		[]byte(customerID),
+	}, "")
+	if response.Status != shim.OK {
+		return nil, fmt.Errorf("failed to get compliance events of customer %s from %s chaincode: %s", customerID, s.complianceChaincodeName, response.Message)
+	}
+
+	var events []interfaces.ComplianceEventSummary
+	if err := json.Unmarshal(response.Payload, &events); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal compliance events: %w", err)
+	}
+	return events, nil
+}
+
+// GetLoanApplications fetches every loan application the customer made as primary borrower from
+// the loan chaincode, reading its pages in turn
+func (s *CustomerActivityService) GetLoanApplications(stub shim.ChaincodeStubInterface, customerID string) ([]interfaces.CustomerLoanSummary, error) {
+	loans := []interfaces.CustomerLoanSummary{}
+	bookmark := ""
+	for {
+		response := stub.InvokeChaincode(s.loanChaincodeName, [][]byte{
+			[]byte("QueryLoansByCustomer"),
+			[]byte(customerID),
+			[]byte(strconv.Itoa(config.MaxPageSize)),
+			[]byte(bookmark),
+		}, "")
+		if response.Status != shim.OK {
+			return nil, fmt.Errorf("failed to get loan applications of customer %s from %s chaincode: %s", customerID, s.loanChaincodeName, response.Message)
+		}
+
+		var page struct {
+			Loans    []interfaces.CustomerLoanSummary `json:"loans"`
+			Bookmark string                           `json:"bookmark"`
+		}
+		if err := json.Unmarshal(response.Payload, &page); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal loan applications: %w", err)
+		}
+		loans = append(loans, page.Loans...)
+		if page.Bookmark == "" || page.Bookmark == bookmark {
+			return loans, nil
+		}
+		bookmark = page.Bookmark
+	}
+}
+
+// GetScreenings fetches the outcomes of the compliance chaincode's AML screenings of the customer
+func (s *CustomerActivityService) GetScreenings(stub shim.ChaincodeStubInterface, customerID string) ([]interfaces.CustomerScreeningSummary, error) {
+	reqBytes, err := json.Marshal(map[string]string{"customerID": customerID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal AML report request: %w", err)
+	}
+
+	response := stub.InvokeChaincode(s.complianceChaincodeName, [][]byte{
+		[]byte("GetAMLReport"),
+		reqBytes,
+	}, "")
+	if response.Status != shim.OK {
+		return nil, fmt.Errorf("failed to get screenings of customer %s from %s chaincode: %s", customerID, s.complianceChaincodeName, response.Message)
+	}
+
+	var report struct {
+		Results []struct {
+			CheckID              string    `json:"checkID"`
+			CheckType            string    `json:"checkType"`
+			Status               string    `json:"status"`
+			RiskLevel            string    `json:"riskLevel"`
+			OverallRiskScore     float64   `json:"overallRiskScore"`
+			CheckDate            time.Time `json:"checkDate"`
+			ExpiryDate           time.Time `json:"expiryDate"`
+			SanctionScreenResult struct {
+				IsMatch bool `json:"isMatch"`
+			} `json:"sanctionScreenResult"`
+			PEPScreenResult struct {
+				IsMatch bool `json:"isMatch"`
+			} `json:"pepScreenResult"`
+		} `json:"results"`
+	}
+	if err := json.Unmarshal(response.Payload, &report); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal AML report: %w", err)
+	}
+
+	screenings := []interfaces.CustomerScreeningSummary{}
+	for _, result := range report.Results {
+		screenings = append(screenings, interfaces.CustomerScreeningSummary{
+			CheckID:       result.CheckID,
+			CheckType:     result.CheckType,
+			Status:        result.Status,
+			RiskLevel:     result.RiskLevel,
+			RiskScore:     result.OverallRiskScore,
+			SanctionMatch: result.SanctionScreenResult.IsMatch,
+			PEPMatch:      result.PEPScreenResult.IsMatch,
+			CheckDate:     result.CheckDate,
+			ExpiryDate:    result.ExpiryDate,
+		})
+	}
+	return screenings, nil
+}
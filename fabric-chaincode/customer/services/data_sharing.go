@@ -0,0 +1,241 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// DataSharingService stores data-sharing agreements, the per-customer clause suspensions that
+// follow a consent withdrawal, and the disclosure log
+type DataSharingService struct {
+	persistenceService *services.PersistenceService
+}
+
+// NewDataSharingService creates a new data-sharing service
+func NewDataSharingService() *DataSharingService {
+	return &DataSharingService{
+		persistenceService: services.NewPersistenceService(),
+	}
+}
+
+// PutAgreement stores an agreement and indexes its clauses by the consent purpose they rely on
+func (s *DataSharingService) PutAgreement(stub shim.ChaincodeStubInterface, agreement *domain.DataSharingAgreement) error {
+	if err := s.persistenceService.Put(stub, agreementKey(agreement.AgreementID), agreement); err != nil {
+		return fmt.Errorf("failed to store data-sharing agreement: %w", err)
+	}
+
+	for _, clause := range agreement.Clauses {
+		indexKey, err := stub.CreateCompositeKey("DATA_SHARING_CLAUSE_BY_PURPOSE", []string{clause.Purpose, agreement.AgreementID, clause.ClauseID})
+		if err != nil {
+			return fmt.Errorf("failed to create composite key: %w", err)
+		}
+		if err := stub.PutState(indexKey, []byte(agreement.AgreementID)); err != nil {
+			return fmt.Errorf("failed to index clause %s: %w", clause.ClauseID, err)
+		}
+	}
+	return nil
+}
+
+// GetAgreement retrieves a data-sharing agreement
+func (s *DataSharingService) GetAgreement(stub shim.ChaincodeStubInterface, agreementID string) (*domain.DataSharingAgreement, error) {
+	var agreement domain.DataSharingAgreement
+	if err := s.persistenceService.Get(stub, agreementKey(agreementID), &agreement); err != nil {
+		return nil, fmt.Errorf("data-sharing agreement not found: %w", err)
+	}
+	return &agreement, nil
+}
+
+// SuspendClauses suspends, for one customer, every clause relying on a withdrawn consent purpose.
+// Clauses already suspended for the customer are left as they are.
+func (s *DataSharingService) SuspendClauses(stub shim.ChaincodeStubInterface, customerID string, purposes []string, actorID string) ([]domain.ClauseSuspension, error) {
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	suspensions := []domain.ClauseSuspension{}
+	for _, purpose := range purposes {
+		iterator, err := stub.GetStateByPartialCompositeKey("DATA_SHARING_CLAUSE_BY_PURPOSE", []string{purpose})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get clauses for purpose %s: %w", purpose, err)
+		}
+
+		type clauseRef struct{ agreementID, clauseID string }
+		refs := []clauseRef{}
+		for iterator.HasNext() {
+			response, err := iterator.Next()
+			if err != nil {
+				iterator.Close()
+				return nil, fmt.Errorf("failed to iterate clauses: %w", err)
+			}
+			_, attributes, err := stub.SplitCompositeKey(response.Key)
+			if err != nil || len(attributes) != 3 {
+				continue // Skip malformed index entries
+			}
+			refs = append(refs, clauseRef{agreementID: attributes[1], clauseID: attributes[2]})
+		}
+		iterator.Close()
+
+		for _, ref := range refs {
+			existing, err := s.GetSuspension(stub, customerID, ref.agreementID, ref.clauseID)
+			if err != nil {
+				return nil, err
+			}
+			if existing != nil && existing.Active() {
+				continue
+			}
+
+			agreement, err := s.GetAgreement(stub, ref.agreementID)
+			if err != nil {
+				return nil, err
+			}
+			suspension := domain.ClauseSuspension{
+				CustomerID:    customerID,
+				AgreementID:   ref.agreementID,
+				ClauseID:      ref.clauseID,
+				Purpose:       purpose,
+				PartnerMSP:    agreement.PartnerMSP,
+				SuspendedDate: now,
+				SuspendedTxID: stub.GetTxID(),
+				SuspendedBy:   actorID,
+			}
+			if err := s.putSuspension(stub, &suspension); err != nil {
+				return nil, err
+			}
+			suspensions = append(suspensions, suspension)
+		}
+	}
+	return suspensions, nil
+}
+
+// ReinstateClauses lifts a customer's active suspensions of clauses relying on re-granted purposes
+func (s *DataSharingService) ReinstateClauses(stub shim.ChaincodeStubInterface, customerID string, purposes []string) error {
+	if len(purposes) == 0 {
+		return nil
+	}
+	granted := make(map[string]bool)
+	for _, purpose := range purposes {
+		granted[purpose] = true
+	}
+
+	suspensions, err := s.GetSuspensions(stub, customerID)
+	if err != nil {
+		return err
+	}
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return err
+	}
+	for i := range suspensions {
+		suspension := &suspensions[i]
+		if !suspension.Active() || !granted[suspension.Purpose] {
+			continue
+		}
+		suspension.ReinstatedDate = &now
+		suspension.ReinstatedTxID = stub.GetTxID()
+		if err := s.putSuspension(stub, suspension); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// GetSuspension retrieves a customer's suspension of a clause, or nil when it was never suspended
+func (s *DataSharingService) GetSuspension(stub shim.ChaincodeStubInterface, customerID, agreementID, clauseID string) (*domain.ClauseSuspension, error) {
+	suspensionKey, err := stub.CreateCompositeKey("DATA_SHARING_SUSPENSION", []string{customerID, agreementID, clauseID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %w", err)
+	}
+	suspensionBytes, err := stub.GetState(suspensionKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read clause suspension: %w", err)
+	}
+	if suspensionBytes == nil {
+		return nil, nil
+	}
+
+	var suspension domain.ClauseSuspension
+	if err := json.Unmarshal(suspensionBytes, &suspension); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal clause suspension: %w", err)
+	}
+	return &suspension, nil
+}
+
+// GetSuspensions returns every clause suspension recorded for a customer
+func (s *DataSharingService) GetSuspensions(stub shim.ChaincodeStubInterface, customerID string) ([]domain.ClauseSuspension, error) {
+	suspensions := []domain.ClauseSuspension{}
+	err := s.scan(stub, "DATA_SHARING_SUSPENSION", customerID, func(value []byte) error {
+		var suspension domain.ClauseSuspension
+		if err := json.Unmarshal(value, &suspension); err != nil {
+			return fmt.Errorf("failed to unmarshal clause suspension: %w", err)
+		}
+		suspensions = append(suspensions, suspension)
+		return nil
+	})
+	return suspensions, err
+}
+
+// PutDisclosure appends an entry to a customer's disclosure log
+func (s *DataSharingService) PutDisclosure(stub shim.ChaincodeStubInterface, disclosure *domain.DisclosureRecord) error {
+	disclosureKey, err := stub.CreateCompositeKey("DISCLOSURE", []string{disclosure.CustomerID, disclosure.DisclosureID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := s.persistenceService.Put(stub, disclosureKey, disclosure); err != nil {
+		return fmt.Errorf("failed to store disclosure: %w", err)
+	}
+	return nil
+}
+
+// GetDisclosures returns a customer's disclosure log
+func (s *DataSharingService) GetDisclosures(stub shim.ChaincodeStubInterface, customerID string) ([]domain.DisclosureRecord, error) {
+	disclosures := []domain.DisclosureRecord{}
+	err := s.scan(stub, "DISCLOSURE", customerID, func(value []byte) error {
+		var disclosure domain.DisclosureRecord
+		if err := json.Unmarshal(value, &disclosure); err != nil {
+			return fmt.Errorf("failed to unmarshal disclosure: %w", err)
+		}
+		disclosures = append(disclosures, disclosure)
+		return nil
+	})
+	return disclosures, err
+}
+
+func (s *DataSharingService) putSuspension(stub shim.ChaincodeStubInterface, suspension *domain.ClauseSuspension) error {
+	suspensionKey, err := stub.CreateCompositeKey("DATA_SHARING_SUSPENSION", []string{suspension.CustomerID, suspension.AgreementID, suspension.ClauseID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := s.persistenceService.Put(stub, suspensionKey, suspension); err != nil {
+		return fmt.Errorf("failed to store clause suspension: %w", err)
+	}
+	return nil
+}
+
+// scan calls fn with each value stored under a customer in a composite key namespace
+func (s *DataSharingService) scan(stub shim.ChaincodeStubInterface, objectType, customerID string, fn func([]byte) error) error {
+	iterator, err := stub.GetStateByPartialCompositeKey(objectType, []string{customerID})
+	if err != nil {
+		return fmt.Errorf("failed to query %s: %w", objectType, err)
+	}
+	defer iterator.Close()
+
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return fmt.Errorf("failed to iterate %s: %w", objectType, err)
+		}
+		if err := fn(response.Value); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func agreementKey(agreementID string) string {
+	return fmt.Sprintf("DATA_SHARING_AGREEMENT_%s", agreementID)
+}
@@ -2,6 +2,8 @@ package services
 
 import (
 	"fmt"
+	"sort"
+	"strings"
 
 	"github.com/hyperledger/fabric-chaincode-go/shim"
 	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
@@ -176,3 +178,218 @@ func (es *EventService) EmitAMLFlagged(stub shim.ChaincodeStubInterface, amlReco
 	return es.EmitEvent(stub, config.EventAMLFlagged, payload)
 }
 
+
+// EmitDataSharingAgreementRecorded emits a data-sharing agreement recorded event
+func (es *EventService) EmitDataSharingAgreementRecorded(stub shim.ChaincodeStubInterface, agreement *domain.DataSharingAgreement, actorID string) error {
+	metadata := map[string]string{
+		"partnerMSP":  agreement.PartnerMSP,
+		"clauseCount": fmt.Sprintf("%d", len(agreement.Clauses)),
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		config.EventDataSharingAgreementRecorded,
+		agreement.AgreementID,
+		"DataSharingAgreement",
+		actorID,
+		agreement,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, config.EventDataSharingAgreementRecorded, payload)
+}
+
+// EmitConsentWithdrawn emits a consent withdrawn event naming the partner organisations whose
+// clauses were suspended, so each partner's listener can pick out the suspensions addressed to it
+func (es *EventService) EmitConsentWithdrawn(stub shim.ChaincodeStubInterface, withdrawal *domain.ConsentWithdrawal, actorID string) error {
+	partners := make(map[string]bool)
+	for _, suspension := range withdrawal.Suspensions {
+		partners[suspension.PartnerMSP] = true
+	}
+	partnerMSPs := make([]string, 0, len(partners))
+	for partnerMSP := range partners {
+		partnerMSPs = append(partnerMSPs, partnerMSP)
+	}
+	sort.Strings(partnerMSPs)
+
+	metadata := map[string]string{
+		"purposes":    strings.Join(withdrawal.Purposes, ","),
+		"partnerMSPs": strings.Join(partnerMSPs, ","),
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		config.EventConsentWithdrawn,
+		withdrawal.CustomerID,
+		"Customer",
+		actorID,
+		withdrawal,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, config.EventConsentWithdrawn, payload)
+}
+
+// EmitConsentRecorded emits a consent recorded event
+func (es *EventService) EmitConsentRecorded(stub shim.ChaincodeStubInterface, record *domain.ConsentRecord, actorID string) error {
+	metadata := map[string]string{
+		"purpose": record.Purpose,
+		"granted": fmt.Sprintf("%t", record.Granted),
+		"version": fmt.Sprintf("%d", record.Version),
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		config.EventConsentRecorded,
+		record.CustomerID,
+		"ConsentRecord",
+		actorID,
+		record,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, config.EventConsentRecorded, payload)
+}
+
+// EmitConsentExpiry emits a consent expired event when a run of the consent expiry process lapsed
+// any consent, and otherwise a consent expiring event announcing the grants about to lapse
+func (es *EventService) EmitConsentExpiry(stub shim.ChaincodeStubInterface, report *domain.ConsentExpiryReport, actorID string) error {
+	eventName := config.EventConsentExpiring
+	if len(report.Expired) > 0 {
+		eventName = config.EventConsentExpired
+	}
+	metadata := map[string]string{
+		"expired":     fmt.Sprintf("%d", len(report.Expired)),
+		"expiring":    fmt.Sprintf("%d", len(report.Expiring)),
+		"suspensions": fmt.Sprintf("%d", len(report.Suspensions)),
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		eventName,
+		stub.GetTxID(),
+		"ConsentExpiryReport",
+		actorID,
+		report,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, eventName, payload)
+}
+
+// EmitDisclosureRecorded emits a disclosure recorded event
+func (es *EventService) EmitDisclosureRecorded(stub shim.ChaincodeStubInterface, disclosure *domain.DisclosureRecord, actorID string) error {
+	metadata := map[string]string{
+		"customerID": disclosure.CustomerID,
+		"partnerMSP": disclosure.PartnerMSP,
+		"purpose":    disclosure.Purpose,
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		config.EventDisclosureRecorded,
+		disclosure.DisclosureID,
+		"DisclosureRecord",
+		actorID,
+		disclosure,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, config.EventDisclosureRecorded, payload)
+}
+
+// EmitCustomerRiskTierChanged emits an event when a recalculation moves a customer to a different
+// risk tier
+func (es *EventService) EmitCustomerRiskTierChanged(stub shim.ChaincodeStubInterface, profile *domain.CustomerRiskProfile, actorID string) error {
+	metadata := map[string]string{
+		"previousRiskTier": string(profile.PreviousRiskTier),
+		"riskTier":         string(profile.RiskTier),
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		config.EventCustomerRiskTierChanged,
+		profile.CustomerID,
+		"CustomerRiskProfile",
+		actorID,
+		profile,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, config.EventCustomerRiskTierChanged, payload)
+}
+
+// EmitOwnershipRecorded emits an event when an owner of an organization is recorded or changed
+func (es *EventService) EmitOwnershipRecorded(stub shim.ChaincodeStubInterface, link *domain.OwnershipLink, actorID string) error {
+	metadata := map[string]string{
+		"ownerID":     link.OwnerID,
+		"controlType": string(link.ControlType),
+		"percentage":  fmt.Sprintf("%g", link.Percentage),
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		config.EventOwnershipRecorded,
+		link.OrganizationID,
+		"OwnershipLink",
+		actorID,
+		link,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, config.EventOwnershipRecorded, payload)
+}
+
+// EmitOwnershipRemoved emits an event when an owner of an organization is removed
+func (es *EventService) EmitOwnershipRemoved(stub shim.ChaincodeStubInterface, link *domain.OwnershipLink, reason, actorID string) error {
+	metadata := map[string]string{
+		"ownerID":     link.OwnerID,
+		"controlType": string(link.ControlType),
+		"reason":      reason,
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		config.EventOwnershipRemoved,
+		link.OrganizationID,
+		"OwnershipLink",
+		actorID,
+		link,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, config.EventOwnershipRemoved, payload)
+}
+
+// EmitRelationshipCreated emits an event when two customers are linked
+func (es *EventService) EmitRelationshipCreated(stub shim.ChaincodeStubInterface, relationship *domain.CustomerRelationship, actorID string) error {
+	metadata := map[string]string{
+		"customerID":        relationship.CustomerID,
+		"relatedCustomerID": relationship.RelatedCustomerID,
+		"relationshipType":  string(relationship.RelationshipType),
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		config.EventRelationshipCreated,
+		relationship.RelationshipID,
+		"CustomerRelationship",
+		actorID,
+		relationship,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, config.EventRelationshipCreated, payload)
+}
+
+// EmitRelationshipTerminated emits an event when a customer relationship ends
+func (es *EventService) EmitRelationshipTerminated(stub shim.ChaincodeStubInterface, relationship *domain.CustomerRelationship, actorID string) error {
+	metadata := map[string]string{
+		"customerID":        relationship.CustomerID,
+		"relatedCustomerID": relationship.RelatedCustomerID,
+		"relationshipType":  string(relationship.RelationshipType),
+		"reason":            relationship.TerminationReason,
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		config.EventRelationshipTerminated,
+		relationship.RelationshipID,
+		"CustomerRelationship",
+		actorID,
+		relationship,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, config.EventRelationshipTerminated, payload)
+}
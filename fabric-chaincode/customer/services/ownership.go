@@ -0,0 +1,343 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// OwnershipService stores the ownership graph of organization customers under
+// OWNERSHIP~organizationID~ownerID, indexed the other way under
+// OWNERSHIP_BY_OWNER~ownerID~organizationID, and resolves it to beneficial owners
+type OwnershipService struct {
+	persistenceService *services.PersistenceService
+}
+
+// NewOwnershipService creates a new ownership service
+func NewOwnershipService() *OwnershipService {
+	return &OwnershipService{
+		persistenceService: services.NewPersistenceService(),
+	}
+}
+
+// GetLink returns an owner's link to an organization, or nil when none is recorded
+func (s *OwnershipService) GetLink(stub shim.ChaincodeStubInterface, organizationID, ownerID string) (*domain.OwnershipLink, error) {
+	linkKey, err := stub.CreateCompositeKey("OWNERSHIP", []string{organizationID, ownerID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %w", err)
+	}
+	linkBytes, err := stub.GetState(linkKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read ownership link: %w", err)
+	}
+	if linkBytes == nil {
+		return nil, nil
+	}
+
+	var link domain.OwnershipLink
+	if err := json.Unmarshal(linkBytes, &link); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal ownership link: %w", err)
+	}
+	return &link, nil
+}
+
+// PutLink stores a link, replacing any earlier link between the same owner and organization
+func (s *OwnershipService) PutLink(stub shim.ChaincodeStubInterface, link *domain.OwnershipLink) error {
+	linkKey, err := stub.CreateCompositeKey("OWNERSHIP", []string{link.OrganizationID, link.OwnerID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := s.persistenceService.Put(stub, linkKey, link); err != nil {
+		return fmt.Errorf("failed to store ownership link: %w", err)
+	}
+
+	indexKey, err := stub.CreateCompositeKey("OWNERSHIP_BY_OWNER", []string{link.OwnerID, link.OrganizationID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := stub.PutState(indexKey, []byte(link.OrganizationID)); err != nil {
+		return fmt.Errorf("failed to create ownership index: %w", err)
+	}
+	return nil
+}
+
+// DeleteLink removes an owner's link to an organization
+func (s *OwnershipService) DeleteLink(stub shim.ChaincodeStubInterface, organizationID, ownerID string) error {
+	linkKey, err := stub.CreateCompositeKey("OWNERSHIP", []string{organizationID, ownerID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %w", err)
+	}
+	indexKey, err := stub.CreateCompositeKey("OWNERSHIP_BY_OWNER", []string{ownerID, organizationID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %w", err)
+	}
+	for _, key := range []string{linkKey, indexKey} {
+		if err := stub.DelState(key); err != nil {
+			return fmt.Errorf("failed to remove ownership link: %w", err)
+		}
+	}
+	return nil
+}
+
+// DeleteLinks removes every link a customer is party to, as organization or owner, returning the
+// number of links removed
+func (s *OwnershipService) DeleteLinks(stub shim.ChaincodeStubInterface, customerID string) (int, error) {
+	owners, err := s.Owners(stub, customerID)
+	if err != nil {
+		return 0, err
+	}
+	organizationIDs, err := s.OwnedOrganizations(stub, customerID)
+	if err != nil {
+		return 0, err
+	}
+
+	removed := 0
+	for _, link := range owners {
+		if err := s.DeleteLink(stub, customerID, link.OwnerID); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	for _, organizationID := range organizationIDs {
+		if err := s.DeleteLink(stub, organizationID, customerID); err != nil {
+			return removed, err
+		}
+		removed++
+	}
+	return removed, nil
+}
+
+// Owners returns the direct owners of an organization in owner ID order
+func (s *OwnershipService) Owners(stub shim.ChaincodeStubInterface, organizationID string) ([]domain.OwnershipLink, error) {
+	iterator, err := stub.GetStateByPartialCompositeKey("OWNERSHIP", []string{organizationID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ownership links: %w", err)
+	}
+	defer iterator.Close()
+
+	links := []domain.OwnershipLink{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate ownership links: %w", err)
+		}
+		var link domain.OwnershipLink
+		if err := json.Unmarshal(response.Value, &link); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal ownership link: %w", err)
+		}
+		links = append(links, link)
+	}
+	return links, nil
+}
+
+// OwnedOrganizations returns the IDs of the organizations a customer directly owns or controls
+func (s *OwnershipService) OwnedOrganizations(stub shim.ChaincodeStubInterface, ownerID string) ([]string, error) {
+	iterator, err := stub.GetStateByPartialCompositeKey("OWNERSHIP_BY_OWNER", []string{ownerID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query ownership index: %w", err)
+	}
+	defer iterator.Close()
+
+	organizationIDs := []string{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate ownership index: %w", err)
+		}
+		organizationIDs = append(organizationIDs, string(response.Value))
+	}
+	return organizationIDs, nil
+}
+
+// CheckNoCycle rejects a link from an owner to an organization that already owns the owner,
+// directly or through other organizations, so every chain of owners ends at the top
+func (s *OwnershipService) CheckNoCycle(stub shim.ChaincodeStubInterface, organizationID, ownerID string) error {
+	visited := map[string]bool{}
+	pending := []string{ownerID}
+	for len(pending) > 0 {
+		entityID := pending[len(pending)-1]
+		pending = pending[:len(pending)-1]
+		if entityID == organizationID {
+			return fmt.Errorf("%s already owns %s, directly or indirectly; ownership may not be circular", organizationID, ownerID)
+		}
+		if visited[entityID] {
+			continue
+		}
+		visited[entityID] = true
+
+		links, err := s.Owners(stub, entityID)
+		if err != nil {
+			return err
+		}
+		for _, link := range links {
+			pending = append(pending, link.OwnerID)
+		}
+	}
+	return nil
+}
+
+// ResolveBeneficialOwners follows every chain of owners above an organization to the individuals
+// at the top. An individual's effective percentage is the product of the percentages down each
+// chain, summed over their chains; they control the organization when every link of one chain
+// confers control. Individuals at or above config.BeneficialOwnershipThreshold, or with control,
+// are its beneficial owners. Names are left for the caller to fill in.
+func (s *OwnershipService) ResolveBeneficialOwners(stub shim.ChaincodeStubInterface, organizationID string) (*domain.BeneficialOwnership, error) {
+	owners := map[string]*domain.BeneficialOwner{}
+	var ownerIDs []string
+	unresolved := map[string]bool{}
+
+	var walk func(entityID string, interest float64, controlled bool, chain []string, depth int) error
+	walk = func(entityID string, interest float64, controlled bool, chain []string, depth int) error {
+		if depth > config.MaxOwnershipDepth {
+			return fmt.Errorf("ownership of %s runs deeper than %d levels", organizationID, config.MaxOwnershipDepth)
+		}
+
+		links, err := s.Owners(stub, entityID)
+		if err != nil {
+			return err
+		}
+		if len(links) == 0 {
+			unresolved[entityID] = true
+			return nil
+		}
+
+		for i := range links {
+			link := &links[i]
+			linkInterest := interest * link.Interest()
+			linkControlled := controlled && link.ConfersControl()
+			linkChain := append([]string{link.OwnerID}, chain...)
+
+			if link.OwnerType == domain.CustomerTypeOrganization {
+				if err := walk(link.OwnerID, linkInterest, linkControlled, linkChain, depth+1); err != nil {
+					return err
+				}
+				continue
+			}
+
+			owner, ok := owners[link.OwnerID]
+			if !ok {
+				owner = &domain.BeneficialOwner{CustomerID: link.OwnerID, ControlTypes: []domain.ControlType{}}
+				owners[link.OwnerID] = owner
+				ownerIDs = append(ownerIDs, link.OwnerID)
+			}
+			owner.EffectivePercentage += linkInterest * 100
+			owner.HasControl = owner.HasControl || linkControlled
+			owner.Chains = append(owner.Chains, linkChain)
+			if !hasControlType(owner.ControlTypes, link.ControlType) {
+				owner.ControlTypes = append(owner.ControlTypes, link.ControlType)
+			}
+		}
+		return nil
+	}
+	if err := walk(organizationID, 1, true, []string{organizationID}, 0); err != nil {
+		return nil, err
+	}
+
+	resolution := &domain.BeneficialOwnership{
+		CustomerID:              organizationID,
+		Threshold:               config.BeneficialOwnershipThreshold,
+		BeneficialOwners:        []domain.BeneficialOwner{},
+		OtherOwners:             []domain.BeneficialOwner{},
+		UnresolvedOrganizations: []string{},
+	}
+	for _, ownerID := range ownerIDs {
+		owner := owners[ownerID]
+		// Rounded so the threshold test does not turn on float error in the products
+		owner.EffectivePercentage = math.Round(owner.EffectivePercentage*10000) / 10000
+		if owner.HasControl || owner.EffectivePercentage >= config.BeneficialOwnershipThreshold {
+			resolution.BeneficialOwners = append(resolution.BeneficialOwners, *owner)
+		} else {
+			resolution.OtherOwners = append(resolution.OtherOwners, *owner)
+		}
+	}
+	sortBeneficialOwners(resolution.BeneficialOwners)
+	sortBeneficialOwners(resolution.OtherOwners)
+	for entityID := range unresolved {
+		resolution.UnresolvedOrganizations = append(resolution.UnresolvedOrganizations, entityID)
+	}
+	sort.Strings(resolution.UnresolvedOrganizations)
+
+	return resolution, nil
+}
+
+// ControlledEntities returns the organizations an individual is a beneficial owner of, found by
+// following the organizations they own down through those each of them owns
+func (s *OwnershipService) ControlledEntities(stub shim.ChaincodeStubInterface, individualID string) ([]domain.ControlledEntity, error) {
+	type pendingEntity struct {
+		id    string
+		depth int
+	}
+	visited := map[string]bool{}
+	var organizationIDs []string
+	pending := []pendingEntity{{id: individualID}}
+	for len(pending) > 0 {
+		entity := pending[0]
+		pending = pending[1:]
+		if entity.depth > config.MaxOwnershipDepth {
+			continue
+		}
+		owned, err := s.OwnedOrganizations(stub, entity.id)
+		if err != nil {
+			return nil, err
+		}
+		for _, organizationID := range owned {
+			if visited[organizationID] {
+				continue
+			}
+			visited[organizationID] = true
+			organizationIDs = append(organizationIDs, organizationID)
+			pending = append(pending, pendingEntity{id: organizationID, depth: entity.depth + 1})
+		}
+	}
+	sort.Strings(organizationIDs)
+
+	entities := []domain.ControlledEntity{}
+	for _, organizationID := range organizationIDs {
+		resolution, err := s.ResolveBeneficialOwners(stub, organizationID)
+		if err != nil {
+			return nil, err
+		}
+		for _, owner := range resolution.BeneficialOwners {
+			if owner.CustomerID != individualID {
+				continue
+			}
+			var organization domain.Customer
+			if err := s.persistenceService.Get(stub, fmt.Sprintf("CUSTOMER_%s", organizationID), &organization); err != nil {
+				return nil, fmt.Errorf("customer not found: %w", err)
+			}
+			entities = append(entities, domain.ControlledEntity{
+				CustomerID:          organizationID,
+				LegalName:           organization.LegalName,
+				EffectivePercentage: owner.EffectivePercentage,
+				ControlTypes:        owner.ControlTypes,
+				HasControl:          owner.HasControl,
+			})
+		}
+	}
+	return entities, nil
+}
+
+// sortBeneficialOwners orders owners by effective percentage, largest first
+func sortBeneficialOwners(owners []domain.BeneficialOwner) {
+	sort.SliceStable(owners, func(i, j int) bool {
+		if owners[i].EffectivePercentage != owners[j].EffectivePercentage {
+			return owners[i].EffectivePercentage > owners[j].EffectivePercentage
+		}
+		return owners[i].CustomerID < owners[j].CustomerID
+	})
+}
+
+func hasControlType(controlTypes []domain.ControlType, controlType domain.ControlType) bool {
+	for _, existing := range controlTypes {
+		if existing == controlType {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,51 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/interfaces"
+)
+
+// PEPScreeningService screens customers against the compliance chaincode's PEP list
+type PEPScreeningService struct {
+	chaincodeName string
+}
+
+// NewPEPScreeningService creates a new PEP screening service
+func NewPEPScreeningService() *PEPScreeningService {
+	return &PEPScreeningService{
+		chaincodeName: config.ComplianceChaincodeName,
+	}
+}
+
+// ScreenCustomer screens the customer's full name, or an organization's legal name, against the
+// PEP list
+func (s *PEPScreeningService) ScreenCustomer(stub shim.ChaincodeStubInterface, customer *domain.Customer) (*interfaces.PEPScreeningResult, error) {
+	name := customer.Name()
+	if name == "" {
+		return nil, fmt.Errorf("customer %s has no name to screen", customer.CustomerID)
+	}
+
+	reqBytes, err := json.Marshal(interfaces.PEPScreeningRequest{Name: name})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal PEP screening request: %w", err)
+	}
+
+	response := stub.InvokeChaincode(s.chaincodeName, [][]byte{
+		[]byte("ScreenPEP"),
+		reqBytes,
+	}, "")
+	if response.Status != shim.OK {
+		return nil, fmt.Errorf("failed to screen customer %s with %s chaincode: %s", customer.CustomerID, s.chaincodeName, response.Message)
+	}
+
+	var result interfaces.PEPScreeningResult
+	if err := json.Unmarshal(response.Payload, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal PEP screening result: %w", err)
+	}
+	return &result, nil
+}
@@ -0,0 +1,192 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// RelationshipService stores customer relationships under RELATIONSHIP_<relationshipID>, indexed
+// for both customers under RELATIONSHIP_BY_CUSTOMER~customerID~relationshipID, and walks them to
+// the parties related to a customer
+type RelationshipService struct {
+	persistenceService *services.PersistenceService
+}
+
+// NewRelationshipService creates a new relationship service
+func NewRelationshipService() *RelationshipService {
+	return &RelationshipService{
+		persistenceService: services.NewPersistenceService(),
+	}
+}
+
+// Get retrieves a relationship by ID
+func (s *RelationshipService) Get(stub shim.ChaincodeStubInterface, relationshipID string) (*domain.CustomerRelationship, error) {
+	var relationship domain.CustomerRelationship
+	if err := s.persistenceService.Get(stub, relationshipKey(relationshipID), &relationship); err != nil {
+		return nil, fmt.Errorf("relationship not found: %w", err)
+	}
+	return &relationship, nil
+}
+
+// Put stores a relationship and indexes it under both customers
+func (s *RelationshipService) Put(stub shim.ChaincodeStubInterface, relationship *domain.CustomerRelationship) error {
+	if err := s.persistenceService.Put(stub, relationshipKey(relationship.RelationshipID), relationship); err != nil {
+		return fmt.Errorf("failed to store relationship: %w", err)
+	}
+
+	for _, customerID := range []string{relationship.CustomerID, relationship.RelatedCustomerID} {
+		indexKey, err := stub.CreateCompositeKey("RELATIONSHIP_BY_CUSTOMER", []string{customerID, relationship.RelationshipID})
+		if err != nil {
+			return fmt.Errorf("failed to create composite key: %w", err)
+		}
+		if err := stub.PutState(indexKey, []byte(relationship.RelationshipID)); err != nil {
+			return fmt.Errorf("failed to create relationship index: %w", err)
+		}
+	}
+	return nil
+}
+
+// ForCustomer returns every relationship a customer is party to, current or ended, in
+// relationship ID order
+func (s *RelationshipService) ForCustomer(stub shim.ChaincodeStubInterface, customerID string) ([]domain.CustomerRelationship, error) {
+	iterator, err := stub.GetStateByPartialCompositeKey("RELATIONSHIP_BY_CUSTOMER", []string{customerID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query relationship index: %w", err)
+	}
+	defer iterator.Close()
+
+	relationships := []domain.CustomerRelationship{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate relationship index: %w", err)
+		}
+		relationship, err := s.Get(stub, string(response.Value))
+		if err != nil {
+			return nil, err
+		}
+		relationships = append(relationships, *relationship)
+	}
+	return relationships, nil
+}
+
+// DeleteForCustomer removes every relationship a customer is party to, returning the number removed
+func (s *RelationshipService) DeleteForCustomer(stub shim.ChaincodeStubInterface, customerID string) (int, error) {
+	relationships, err := s.ForCustomer(stub, customerID)
+	if err != nil {
+		return 0, err
+	}
+
+	for i, relationship := range relationships {
+		keys := []string{relationshipKey(relationship.RelationshipID)}
+		for _, partyID := range []string{relationship.CustomerID, relationship.RelatedCustomerID} {
+			indexKey, err := stub.CreateCompositeKey("RELATIONSHIP_BY_CUSTOMER", []string{partyID, relationship.RelationshipID})
+			if err != nil {
+				return i, fmt.Errorf("failed to create composite key: %w", err)
+			}
+			keys = append(keys, indexKey)
+		}
+		for _, key := range keys {
+			if err := stub.DelState(key); err != nil {
+				return i, fmt.Errorf("failed to delete relationship: %w", err)
+			}
+		}
+	}
+	return len(relationships), nil
+}
+
+// RelatedParties walks the relationships in effect at an instant outward from a customer, up to
+// maxDepth hops, returning each customer reached once at the depth it was first reached. Parties
+// carry their latest AML status and risk tier. Bidirectional and directed relationships are both
+// followed either way: a guardian is as much a related party of their ward as the ward is of them.
+func (s *RelationshipService) RelatedParties(stub shim.ChaincodeStubInterface, customerID string, maxDepth int, asOf time.Time) ([]domain.RelatedParty, error) {
+	type visit struct {
+		customerID        string
+		relationshipIDs   []string
+		relationshipTypes []domain.RelationshipType
+	}
+
+	reached := map[string]bool{customerID: true}
+	parties := []domain.RelatedParty{}
+	frontier := []visit{{customerID: customerID}}
+	for depth := 1; depth <= maxDepth && len(frontier) > 0; depth++ {
+		next := []visit{}
+		for _, current := range frontier {
+			relationships, err := s.ForCustomer(stub, current.customerID)
+			if err != nil {
+				return nil, err
+			}
+			for i := range relationships {
+				if !relationships[i].InEffect(asOf) {
+					continue
+				}
+				counterpartyID := relationships[i].Counterparty(current.customerID)
+				if reached[counterpartyID] {
+					continue
+				}
+				reached[counterpartyID] = true
+
+				party := domain.RelatedParty{
+					CustomerID:        counterpartyID,
+					Depth:             depth,
+					RelationshipIDs:   append(append([]string{}, current.relationshipIDs...), relationships[i].RelationshipID),
+					RelationshipTypes: append(append([]domain.RelationshipType{}, current.relationshipTypes...), relationships[i].RelationshipType),
+				}
+				if err := s.enrich(stub, &party); err != nil {
+					return nil, err
+				}
+
+				parties = append(parties, party)
+				next = append(next, visit{customerID: counterpartyID, relationshipIDs: party.RelationshipIDs, relationshipTypes: party.RelationshipTypes})
+			}
+		}
+		frontier = next
+	}
+
+	sort.SliceStable(parties, func(i, j int) bool {
+		if parties[i].Depth != parties[j].Depth {
+			return parties[i].Depth < parties[j].Depth
+		}
+		return parties[i].CustomerID < parties[j].CustomerID
+	})
+	return parties, nil
+}
+
+// enrich adds a related party's latest AML status and risk tier, where they have them
+func (s *RelationshipService) enrich(stub shim.ChaincodeStubInterface, party *domain.RelatedParty) error {
+	amlID, err := stub.GetState(fmt.Sprintf("CUSTOMER_AML_%s", party.CustomerID))
+	if err != nil {
+		return fmt.Errorf("failed to get customer AML index: %w", err)
+	}
+	if amlID != nil {
+		var amlRecord domain.AMLRecord
+		if err := s.persistenceService.Get(stub, fmt.Sprintf("AML_%s", string(amlID)), &amlRecord); err != nil {
+			return fmt.Errorf("AML record not found: %w", err)
+		}
+		party.AMLStatus = amlRecord.Status
+	}
+
+	profileBytes, err := stub.GetState(riskProfileKey(party.CustomerID))
+	if err != nil {
+		return fmt.Errorf("failed to read risk profile: %w", err)
+	}
+	if profileBytes != nil {
+		var profile domain.CustomerRiskProfile
+		if err := json.Unmarshal(profileBytes, &profile); err != nil {
+			return fmt.Errorf("failed to unmarshal risk profile: %w", err)
+		}
+		party.RiskTier = profile.RiskTier
+	}
+	return nil
+}
+
+// relationshipKey returns the state key of a relationship
+func relationshipKey(relationshipID string) string {
+	return fmt.Sprintf("RELATIONSHIP_%s", relationshipID)
+}
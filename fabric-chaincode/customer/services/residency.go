@@ -0,0 +1,166 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// ResidencyService keeps the PII of customers with a residency in that residency's private data
+// collection and guards reads of it from other residencies
+type ResidencyService struct{}
+
+// NewResidencyService creates a new residency service
+func NewResidencyService() *ResidencyService {
+	return &ResidencyService{}
+}
+
+// CollectionFor returns the private data collection for a residency
+func (s *ResidencyService) CollectionFor(residency string) (string, error) {
+	collection, ok := config.ResidencyCollections[residency]
+	if !ok {
+		return "", fmt.Errorf("no private data collection configured for residency %s", residency)
+	}
+	return collection, nil
+}
+
+// PutPII writes a customer's personal data to their residency's collection
+func (s *ResidencyService) PutPII(stub shim.ChaincodeStubInterface, customer *domain.Customer) error {
+	collection, err := s.CollectionFor(customer.Residency)
+	if err != nil {
+		return err
+	}
+
+	piiBytes, err := json.Marshal(customer.PII())
+	if err != nil {
+		return fmt.Errorf("failed to marshal customer PII: %w", err)
+	}
+	if err := stub.PutPrivateData(collection, piiKey(customer.CustomerID), piiBytes); err != nil {
+		return fmt.Errorf("failed to store customer PII in %s: %w", collection, err)
+	}
+	return nil
+}
+
+// LoadPII fills in a customer's personal data from their residency's collection. Callers reading
+// on behalf of the invoker must call CheckAccess first.
+func (s *ResidencyService) LoadPII(stub shim.ChaincodeStubInterface, customer *domain.Customer) error {
+	if customer.Residency == "" {
+		return nil
+	}
+
+	collection, err := s.CollectionFor(customer.Residency)
+	if err != nil {
+		return err
+	}
+	piiBytes, err := stub.GetPrivateData(collection, piiKey(customer.CustomerID))
+	if err != nil {
+		return fmt.Errorf("failed to read customer PII from %s: %w", collection, err)
+	}
+	if piiBytes == nil {
+		return fmt.Errorf("no PII for customer %s in %s", customer.CustomerID, collection)
+	}
+
+	var pii domain.CustomerPII
+	if err := json.Unmarshal(piiBytes, &pii); err != nil {
+		return fmt.Errorf("failed to unmarshal customer PII: %w", err)
+	}
+	customer.ApplyPII(&pii)
+	return nil
+}
+
+// DeletePII removes a customer's personal data from their residency's collection
+func (s *ResidencyService) DeletePII(stub shim.ChaincodeStubInterface, customer *domain.Customer) error {
+	collection, err := s.CollectionFor(customer.Residency)
+	if err != nil {
+		return err
+	}
+	if err := stub.DelPrivateData(collection, piiKey(customer.CustomerID)); err != nil {
+		return fmt.Errorf("failed to delete customer PII from %s: %w", collection, err)
+	}
+	return nil
+}
+
+// InvokerResidency returns the residency of the invoker's organization, or "" when it has none
+func (s *ResidencyService) InvokerResidency(stub shim.ChaincodeStubInterface) (string, string, error) {
+	mspID, err := services.InvokerMSPID(stub)
+	if err != nil {
+		return "", "", err
+	}
+	return mspID, config.MSPResidencies[mspID], nil
+}
+
+// CheckAccess rejects reads of a customer's PII by organizations outside the customer's
+// residency. Such reads must go through GetCustomerCrossResidency with a legal basis.
+func (s *ResidencyService) CheckAccess(stub shim.ChaincodeStubInterface, customer *domain.Customer) error {
+	if customer.Residency == "" {
+		return nil
+	}
+
+	mspID, residency, err := s.InvokerResidency(stub)
+	if err != nil {
+		return err
+	}
+	if residency != customer.Residency {
+		return services.NewChaincodeError(services.ErrCodeAccessDenied, "", "customer %s is resident in %s; %s may only read their PII under a documented legal basis", customer.CustomerID, customer.Residency, mspID)
+	}
+	return nil
+}
+
+// RecordCrossResidencyAccess validates the legal basis of a cross-residency read and records it
+func (s *ResidencyService) RecordCrossResidencyAccess(stub shim.ChaincodeStubInterface, customer *domain.Customer, req *domain.CrossResidencyAccessRequest) (*domain.CrossResidencyAccess, error) {
+	if !config.CrossResidencyLegalBases[req.LegalBasis] {
+		return nil, fmt.Errorf("legal basis %s is not accepted for cross-residency access", req.LegalBasis)
+	}
+	if strings.TrimSpace(req.Justification) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "justification", "justification is required")
+	}
+
+	mspID, residency, err := s.InvokerResidency(stub)
+	if err != nil {
+		return nil, err
+	}
+	invokerID, err := services.InvokerID(stub)
+	if err != nil {
+		return nil, err
+	}
+	accessDate, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	access := &domain.CrossResidencyAccess{
+		CustomerID:      customer.CustomerID,
+		Residency:       customer.Residency,
+		ReaderMSPID:     mspID,
+		ReaderResidency: residency,
+		LegalBasis:      req.LegalBasis,
+		Justification:   req.Justification,
+		AccessedBy:      req.ActorID,
+		InvokerID:       invokerID,
+		AccessDate:      accessDate,
+		TransactionID:   stub.GetTxID(),
+	}
+
+	accessKey, err := stub.CreateCompositeKey("CROSS_RESIDENCY_ACCESS", []string{customer.CustomerID, access.TransactionID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %w", err)
+	}
+	accessBytes, err := json.Marshal(access)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal cross-residency access: %w", err)
+	}
+	if err := stub.PutState(accessKey, accessBytes); err != nil {
+		return nil, fmt.Errorf("failed to record cross-residency access: %w", err)
+	}
+
+	return access, nil
+}
+
+func piiKey(customerID string) string {
+	return fmt.Sprintf("CUSTOMER_PII_%s", customerID)
+}
@@ -0,0 +1,158 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/interfaces"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// Risk score weights. Each driver adds points to the score, which is capped at 100.
+const (
+	amlScoreWeight            = 0.4  // Share of the latest AML check's risk score carried over
+	amlUncheckedScore         = 20.0 // No AML check on record
+	amlReviewingScore         = 15.0
+	amlFlaggedScore           = 30.0 // FLAGGED or BLOCKED
+	geographyScoreWeight      = 0.3  // Share of the residency's HighRiskJurisdictions score
+	defaultedLoanScore        = 20.0
+	multipleLoansScore        = 5.0
+	multipleLoansThreshold    = 3
+	transactionVolumeHigh     = 100000.0
+	transactionVolumeVeryHigh = 500000.0
+	transactionVolumeScore    = 10.0 // Doubled above transactionVolumeVeryHigh
+	flaggedRelatedPartyScore  = 10.0 // Any directly related customer whose latest AML check is FLAGGED or BLOCKED
+)
+
+// RiskRatingService computes and stores customer risk profiles
+type RiskRatingService struct {
+	persistenceService *services.PersistenceService
+	chaincodeName      string
+}
+
+// NewRiskRatingService creates a new risk rating service
+func NewRiskRatingService() *RiskRatingService {
+	return &RiskRatingService{
+		persistenceService: services.NewPersistenceService(),
+		chaincodeName:      config.LoanChaincodeName,
+	}
+}
+
+// GetHoldings fetches the customer's loan holdings and recent transactions from the loan chaincode
+func (s *RiskRatingService) GetHoldings(stub shim.ChaincodeStubInterface, customerID string) (*interfaces.CustomerHoldings, error) {
+	response := stub.InvokeChaincode(s.chaincodeName, [][]byte{
+		[]byte("GetCustomerHoldings"),
+		[]byte(customerID),
+	}, "")
+	if response.Status != shim.OK {
+		return nil, fmt.Errorf("failed to get holdings of customer %s from %s chaincode: %s", customerID, s.chaincodeName, response.Message)
+	}
+
+	var holdings interfaces.CustomerHoldings
+	if err := json.Unmarshal(response.Payload, &holdings); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal customer holdings: %w", err)
+	}
+	return &holdings, nil
+}
+
+// Score rates a customer from their latest AML check (nil when never checked), residency, loan
+// holdings and directly related parties, returning the capped score and the drivers that
+// contributed to it
+func (s *RiskRatingService) Score(customer *domain.Customer, amlRecord *domain.AMLRecord, holdings *interfaces.CustomerHoldings, relatedParties []domain.RelatedParty) (float64, []domain.RiskDriver) {
+	drivers := []domain.RiskDriver{}
+	add := func(factor, code string, score float64, detail string) {
+		if score > 0 {
+			drivers = append(drivers, domain.RiskDriver{Factor: factor, Code: code, Score: score, Detail: detail})
+		}
+	}
+
+	// AML results
+	if amlRecord == nil {
+		add("AML", "AML_NOT_CHECKED", amlUncheckedScore, "No AML check on record")
+	} else {
+		detail := fmt.Sprintf("AML check %s scored %.1f", amlRecord.AMLID, amlRecord.RiskScore)
+		if len(amlRecord.Flags) > 0 {
+			detail += fmt.Sprintf(" (%s)", strings.Join(amlRecord.Flags, ", "))
+		}
+		add("AML", "AML_RISK_SCORE", amlRecord.RiskScore*amlScoreWeight, detail)
+		switch amlRecord.Status {
+		case validation.AMLStatusReviewing:
+			add("AML", "AML_UNDER_REVIEW", amlReviewingScore, fmt.Sprintf("AML check %s is under review", amlRecord.AMLID))
+		case validation.AMLStatusFlagged, validation.AMLStatusBlocked:
+			add("AML", "AML_"+string(amlRecord.Status), amlFlaggedScore, fmt.Sprintf("AML check %s is %s", amlRecord.AMLID, amlRecord.Status))
+		}
+	}
+
+	// Geography
+	if jurisdictionScore, ok := config.HighRiskJurisdictions[customer.Residency]; ok {
+		add("GEOGRAPHY", "HIGH_RISK_JURISDICTION", jurisdictionScore*geographyScoreWeight, fmt.Sprintf("Resident in %s", customer.Residency))
+	}
+
+	// Product holdings
+	if holdings.DefaultedLoans > 0 {
+		add("PRODUCT_HOLDINGS", "DEFAULTED_LOANS", defaultedLoanScore, fmt.Sprintf("%d defaulted loan(s)", holdings.DefaultedLoans))
+	}
+	if holdings.ActiveLoans+holdings.DefaultedLoans >= multipleLoansThreshold {
+		add("PRODUCT_HOLDINGS", "MULTIPLE_LOANS", multipleLoansScore, fmt.Sprintf("%d loans held", holdings.ActiveLoans+holdings.DefaultedLoans))
+	}
+
+	// Transaction data
+	detail := fmt.Sprintf("%d loan transaction(s) totalling %.2f in %d days", holdings.RecentTransactionCount, holdings.RecentTransactionVolume, holdings.WindowDays)
+	if holdings.RecentTransactionVolume > transactionVolumeVeryHigh {
+		add("TRANSACTIONS", "VERY_HIGH_TRANSACTION_VOLUME", transactionVolumeScore*2, detail)
+	} else if holdings.RecentTransactionVolume > transactionVolumeHigh {
+		add("TRANSACTIONS", "HIGH_TRANSACTION_VOLUME", transactionVolumeScore, detail)
+	}
+
+	// Related parties
+	flagged := []string{}
+	for _, party := range relatedParties {
+		if party.Flagged() {
+			flagged = append(flagged, party.CustomerID)
+		}
+	}
+	if len(flagged) > 0 {
+		add("RELATIONSHIPS", "FLAGGED_RELATED_PARTY", flaggedRelatedPartyScore, fmt.Sprintf("Related to flagged customer(s) %s", strings.Join(flagged, ", ")))
+	}
+
+	score := 0.0
+	for _, driver := range drivers {
+		score += driver.Score
+	}
+	return math.Min(math.Round(score*100)/100, 100), drivers
+}
+
+// GetProfile retrieves a customer's risk profile, or nil when they have never been assessed
+func (s *RiskRatingService) GetProfile(stub shim.ChaincodeStubInterface, customerID string) (*domain.CustomerRiskProfile, error) {
+	profileBytes, err := stub.GetState(riskProfileKey(customerID))
+	if err != nil {
+		return nil, fmt.Errorf("failed to read risk profile: %w", err)
+	}
+	if profileBytes == nil {
+		return nil, nil
+	}
+
+	var profile domain.CustomerRiskProfile
+	if err := json.Unmarshal(profileBytes, &profile); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal risk profile: %w", err)
+	}
+	return &profile, nil
+}
+
+// PutProfile stores a customer's risk profile
+func (s *RiskRatingService) PutProfile(stub shim.ChaincodeStubInterface, profile *domain.CustomerRiskProfile) error {
+	if err := s.persistenceService.Put(stub, riskProfileKey(profile.CustomerID), profile); err != nil {
+		return fmt.Errorf("failed to store risk profile: %w", err)
+	}
+	return nil
+}
+
+func riskProfileKey(customerID string) string {
+	return fmt.Sprintf("CUSTOMER_RISK_PROFILE_%s", customerID)
+}
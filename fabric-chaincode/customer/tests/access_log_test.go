@@ -0,0 +1,164 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+func TestCustomerReadsAreLoggedWithPurposeAndBasis(t *testing.T) {
+	clock := services.NewFixedClock(time.Date(2026, 9, 1, 9, 0, 0, 0, time.UTC))
+	defer services.SetClock(clock)()
+
+	stub := newCustomerStub(t)
+	adminIdentity := stub.Creator
+	serviceRepIdentity := bindRoleActor(t, stub, "log_csr", "ACTOR_LOG_CSR", "Customer_Service_Rep")
+	officerIdentity := bindRoleActor(t, stub, "log_co", "ACTOR_LOG_CO", "Compliance_Officer")
+	customer := createTestCustomer(t, stub, "ACCESSLOG001")
+
+	read := func(txID, function, purpose, actorID string, args ...string) (int32, string) {
+		transient := map[string][]byte{}
+		if purpose != "" {
+			transient[config.AccessPurposeTransientKey] = []byte(purpose)
+		}
+		if actorID != "" {
+			transient[config.AccessActorTransientKey] = []byte(actorID)
+		}
+		stub.TransientMap = transient
+		defer func() { stub.TransientMap = nil }()
+
+		invokeArgs := [][]byte{[]byte(function)}
+		for _, arg := range append([]string{customer.CustomerID}, args...) {
+			invokeArgs = append(invokeArgs, []byte(arg))
+		}
+		response := stub.MockInvoke(txID, invokeArgs)
+		return response.Status, response.Message
+	}
+
+	// A service rep reads the customer for a declared purpose under their registered actor
+	stub.Creator = serviceRepIdentity
+	status, message := read("log_1", "GetCustomer", config.AccessPurposeCustomerService, "ACTOR_LOG_CSR")
+	require.Equal(t, int32(shim.OK), status, message)
+
+	// Purposes must be recognised and a named actor must be the invoker's
+	status, message = read("log_2", "GetCustomer", "CURIOSITY", "")
+	assert.Equal(t, int32(shim.ERROR), status)
+	assert.Contains(t, message, "not recognised")
+	status, message = read("log_3", "GetCustomer", config.AccessPurposeCustomerService, "ACTOR_LOG_CO")
+	assert.Equal(t, int32(shim.ERROR), status)
+	assert.Contains(t, message, "not bound to actor")
+
+	// A purpose relying on consent is refused until the customer grants it
+	status, message = read("log_4", "GetConsentHistory", config.AccessPurposeCreditAssessment, "")
+	assert.Equal(t, int32(shim.ERROR), status)
+	assert.Contains(t, message, "has not granted consent creditCheck")
+
+	stub.Creator = adminIdentity
+	consentBytes, _ := json.Marshal(domain.ConsentRequest{CustomerID: customer.CustomerID, Purpose: config.ConsentCreditCheck, Granted: true, Evidence: "WEB_FORM_7", ActorID: "ACTOR_TEST"})
+	response := stub.MockInvoke("log_5", [][]byte{[]byte("RecordConsent"), consentBytes})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+
+	clock.Advance(24 * time.Hour)
+	stub.Creator = serviceRepIdentity
+	status, message = read("log_6", "GetConsentHistory", config.AccessPurposeCreditAssessment, "")
+	require.Equal(t, int32(shim.OK), status, message)
+
+	// Consent reads are logged too, and reads declaring no purpose are logged as unspecified
+	status, message = read("log_7", "GetConsentAt", config.AccessPurposeCustomerService, "", config.ConsentCreditCheck, "2026-09-02")
+	require.Equal(t, int32(shim.OK), status, message)
+	stub.Creator = adminIdentity
+	status, message = read("log_8", "GetCustomer", "", "")
+	require.Equal(t, int32(shim.OK), status, message)
+
+	// Only compliance officers and regulators may see who read the customer
+	stub.Creator = serviceRepIdentity
+	response = stub.MockInvoke("log_9", [][]byte{[]byte("GetCustomerAccessLog"), []byte(customer.CustomerID)})
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	assert.Contains(t, response.Message, "may only be read")
+
+	accessLog := func(txID string, args ...string) domain.CustomerAccessLogResult {
+		invokeArgs := [][]byte{[]byte("GetCustomerAccessLog"), []byte(customer.CustomerID)}
+		for _, arg := range args {
+			invokeArgs = append(invokeArgs, []byte(arg))
+		}
+		response := stub.MockInvoke(txID, invokeArgs)
+		require.Equal(t, int32(shim.OK), response.Status, response.Message)
+		var result domain.CustomerAccessLogResult
+		require.NoError(t, json.Unmarshal(response.Payload, &result))
+		return result
+	}
+
+	stub.Creator = officerIdentity
+	result := accessLog("log_10")
+	require.Equal(t, 4, result.Count)
+	assert.Equal(t, []string{"GetCustomer", "GetConsentHistory", "GetConsentAt", "GetCustomer"},
+		[]string{result.Entries[0].Function, result.Entries[1].Function, result.Entries[2].Function, result.Entries[3].Function})
+
+	first := result.Entries[0]
+	assert.Equal(t, config.AccessPurposeCustomerService, first.Purpose)
+	assert.Equal(t, config.LegalBasisContractPerformance, first.LegalBasis)
+	assert.Equal(t, "ACTOR_LOG_CSR", first.ActorID)
+	assert.Equal(t, "Customer_Service_Rep", first.InvokerRole)
+	assert.Equal(t, "log_1", first.TransactionID)
+
+	credit := result.Entries[1]
+	assert.Equal(t, config.LegalBasisExplicitConsent, credit.LegalBasis)
+	assert.Equal(t, config.ConsentCreditCheck, credit.ConsentPurpose)
+	assert.Equal(t, 1, credit.ConsentVersion)
+
+	assert.Equal(t, config.AccessPurposeUnspecified, result.Entries[3].Purpose)
+	assert.Empty(t, result.Entries[3].LegalBasis)
+
+	// The service rep made three reads, the administrator one
+	require.Len(t, result.Accessors, 2)
+	assert.Equal(t, 3, result.Accessors[0].Reads)
+	assert.Equal(t, []string{"ACTOR_LOG_CSR"}, result.Accessors[0].ActorIDs)
+	assert.Equal(t, []string{config.AccessPurposeCreditAssessment, config.AccessPurposeCustomerService}, result.Accessors[0].Purposes)
+	assert.Equal(t, 1, result.Accessors[1].Reads)
+
+	// A period narrows the log to the reads made within it
+	result = accessLog("log_11", "2026-09-02", "2026-09-02")
+	assert.Equal(t, 3, result.Count)
+	result = accessLog("log_12", "", "2026-09-01")
+	require.Equal(t, 1, result.Count)
+	assert.Equal(t, "log_1", result.Entries[0].TransactionID)
+
+	response = stub.MockInvoke("log_13", [][]byte{[]byte("GetCustomerAccessLog"), []byte(customer.CustomerID), []byte("2026-09-02"), []byte("2026-09-01")})
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	assert.Contains(t, response.Message, "ends before it starts")
+}
+
+func TestRecordAccessLogsReadWithoutPII(t *testing.T) {
+	stub := newCustomerStub(t)
+	customer := createTestCustomer(t, stub, "ACCESSLOG002")
+
+	stub.TransientMap = map[string][]byte{config.AccessPurposeTransientKey: []byte(config.AccessPurposeKYCReview)}
+	response := stub.MockInvoke("record_1", [][]byte{[]byte("RecordAccess"), []byte(customer.CustomerID), []byte("GetCustomer")})
+	stub.TransientMap = nil
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+
+	// The response is committed with the transaction, so it names the read but carries no PII
+	var entry domain.AccessLogEntry
+	require.NoError(t, json.Unmarshal(response.Payload, &entry))
+	assert.Equal(t, customer.CustomerID, entry.CustomerID)
+	assert.Equal(t, "GetCustomer", entry.Function)
+	assert.Equal(t, config.AccessPurposeKYCReview, entry.Purpose)
+	for _, personal := range []string{`"Test"`, `"Customer"`, "test@example.com", "+1234567890", "ACCESSLOG002", "Test Street"} {
+		assert.NotContains(t, string(response.Payload), personal)
+	}
+
+	// Only reads of PII are logged, and only of customers that exist
+	response = stub.MockInvoke("record_2", [][]byte{[]byte("RecordAccess"), []byte(customer.CustomerID), []byte("GetKYCRecord")})
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	assert.Contains(t, response.Message, "does not read a customer's PII")
+	response = stub.MockInvoke("record_3", [][]byte{[]byte("RecordAccess"), []byte("CUST_MISSING"), []byte("GetCustomer")})
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	assert.Contains(t, response.Message, services.ErrCodeNotFound)
+}
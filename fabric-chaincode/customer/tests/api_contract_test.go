@@ -0,0 +1,328 @@
+package tests
+
+import (
+	"encoding/json"
+	"flag"
+	"fmt"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/chaincode"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/interfaces"
+	sharedChaincode "github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/chaincode"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+var updateAPISchema = flag.Bool("update-api-schema", false, "regenerate the published customer API schema")
+
+var apiSchemaPath = filepath.Join("..", "chaincode", chaincode.APISchemaFile)
+
+// loadPublishedAPISchema reads the published schema the contract tests check responses against
+func loadPublishedAPISchema(t *testing.T) *sharedChaincode.APISchemaDocument {
+	documentBytes, err := os.ReadFile(apiSchemaPath)
+	require.NoError(t, err, "run `make api-schema` to publish the customer API schema")
+	var document sharedChaincode.APISchemaDocument
+	require.NoError(t, json.Unmarshal(documentBytes, &document))
+	return &document
+}
+
+func TestPublishedAPISchemaIsCurrent(t *testing.T) {
+	generated := chaincode.CustomerAPISchema()
+	generatedBytes, err := json.MarshalIndent(generated, "", "  ")
+	require.NoError(t, err)
+	generatedBytes = append(generatedBytes, '\n')
+
+	if *updateAPISchema {
+		require.NoError(t, os.WriteFile(apiSchemaPath, generatedBytes, 0644))
+	}
+
+	// A response type change must be published deliberately, never by accident
+	published := loadPublishedAPISchema(t)
+	for _, function := range chaincode.CustomerAPI().Functions() {
+		generatedSchema, err := json.Marshal(generated.Functions[function])
+		require.NoError(t, err)
+		publishedSchema, err := json.Marshal(published.Functions[function])
+		require.NoError(t, err)
+		assert.JSONEq(t, string(generatedSchema), string(publishedSchema),
+			"response of %s no longer matches the published schema; run `make api-schema` if the change is intended", function)
+	}
+	for function := range published.Functions {
+		assert.Contains(t, generated.Functions, function, "published function %s has been removed", function)
+	}
+}
+
+func TestAPICatalogCoversEveryFunction(t *testing.T) {
+	assert.Equal(t, chaincode.NewRouter().Functions(), chaincode.CustomerAPI().Functions(),
+		"every routed function needs a response type in CustomerAPI")
+}
+
+func TestAPISchemaValidation(t *testing.T) {
+	type nested struct {
+		Code string `json:"code"`
+	}
+	type response struct {
+		ID       string            `json:"id"`
+		Score    float64           `json:"score"`
+		Count    int               `json:"count"`
+		Created  time.Time         `json:"created"`
+		Closed   *time.Time        `json:"closed,omitempty"`
+		Items    []nested          `json:"items"`
+		Metadata map[string]string `json:"metadata,omitempty"`
+	}
+	schema := sharedChaincode.GenerateAPISchema(response{})
+	assert.Equal(t, []string{"count", "created", "id", "items", "score"}, schema.Required)
+
+	valid := `{"id":"A","score":1.5,"count":2,"created":"2026-06-01T00:00:00Z","items":null,"metadata":{"k":"v"}}`
+	assert.NoError(t, sharedChaincode.ValidateAPIResponse(schema, []byte(valid)))
+
+	err := sharedChaincode.ValidateAPIResponse(schema, []byte(`{"id":7,"score":1,"count":2.5,"created":"yesterday","items":[{"code":"X","extra":true}]}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "$.id: expected string, got number")
+	assert.Contains(t, err.Error(), "$.count: expected integer, got number")
+	assert.Contains(t, err.Error(), `$.created: "yesterday" is not a date-time`)
+	assert.Contains(t, err.Error(), "$.items[0].extra: property is not in the published schema")
+
+	err = sharedChaincode.ValidateAPIResponse(schema, []byte(`{"id":"A","score":1,"created":"2026-06-01T00:00:00Z","items":[]}`))
+	require.Error(t, err)
+	assert.Contains(t, err.Error(), "$.count: required property is missing")
+}
+
+// TestResponsesMatchPublishedAPISchema invokes every customer chaincode function against the mock
+// stub and checks each successful response against the published schema. Functions whose success
+// needs state the contract scenario does not build are invoked to check they fail cleanly.
+func TestResponsesMatchPublishedAPISchema(t *testing.T) {
+	clock := services.NewFixedClock(time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC))
+	defer services.SetClock(clock)()
+	published := loadPublishedAPISchema(t)
+	stub := newCustomerStub(t)
+	stub.MockPeerChaincode("loan", shimtest.NewMockStub("loan", &fakeLoanChaincode{holdings: map[string]interfaces.CustomerHoldings{}}), "")
+	adminIdentity := stub.Creator
+	officerIdentity := bindRoleActor(t, stub, "contract_officer", "ACTOR_CONTRACT_CO", "Compliance_Officer")
+	secondOfficerIdentity := bindRoleActor(t, stub, "contract_officer_2", "ACTOR_CONTRACT_CO2", "Compliance_Officer")
+	invoked := map[string]bool{}
+	txCount := 0
+
+	call := func(function string, args ...interface{}) ([]byte, string) {
+		invokeArgs := [][]byte{[]byte(function)}
+		for _, arg := range args {
+			if text, ok := arg.(string); ok {
+				invokeArgs = append(invokeArgs, []byte(text))
+				continue
+			}
+			argBytes, err := json.Marshal(arg)
+			require.NoError(t, err)
+			invokeArgs = append(invokeArgs, argBytes)
+		}
+		txCount++
+		invoked[function] = true
+		response := stub.MockInvoke(fmt.Sprintf("contract_%d", txCount), invokeArgs)
+		if response.Status != shim.OK {
+			require.NotEmpty(t, response.Message, "%s failed without a message", function)
+			return nil, response.Message
+		}
+		schema, ok := published.Functions[function]
+		require.True(t, ok, "%s is not in the published schema", function)
+		assert.NoError(t, sharedChaincode.ValidateAPIResponse(schema, response.Payload), function)
+		return response.Payload, ""
+	}
+	invoke := func(target interface{}, function string, args ...interface{}) {
+		payload, message := call(function, args...)
+		require.Empty(t, message, function)
+		if target != nil {
+			require.NoError(t, json.Unmarshal(payload, target), function)
+		}
+	}
+	rejected := func(function string, args ...interface{}) {
+		_, message := call(function, args...)
+		assert.NotEmpty(t, message, "%s was expected to fail", function)
+	}
+
+	// Customer management
+	var customer domain.Customer
+	invoke(&customer, "RegisterCustomer", domain.CustomerRegistrationRequest{
+		FirstName:          "Connie",
+		LastName:           "Contract",
+		Email:              "connie@example.com",
+		Phone:              "+1234567890",
+		DateOfBirth:        time.Date(1988, 2, 3, 0, 0, 0, 0, time.UTC),
+		NationalID:         "CONTRACT001",
+		Address:            "1 Contract Street, Test City, Test Country",
+		ConsentPreferences: `{"marketing": true}`,
+		ActorID:            "ACTOR_001",
+	})
+	newPhone := "+1555666777"
+	invoke(nil, "UpdateCustomer", domain.CustomerUpdateRequest{CustomerID: customer.CustomerID, Phone: &newPhone, ActorID: "ACTOR_001"})
+	invoke(nil, "GetCustomer", customer.CustomerID)
+	invoke(nil, "GetCustomerHistory", customer.CustomerID)
+	rejected("GetCustomerCrossResidency", domain.CrossResidencyAccessRequest{CustomerID: customer.CustomerID, LegalBasis: config.LegalBasisRegulatoryRequest, Justification: "Contract test", ActorID: "ACTOR_001"})
+	rejected("PurgeSandboxCustomer", domain.SandboxCustomerPurgeRequest{CustomerID: customer.CustomerID, ActorID: "ACTOR_001"})
+
+	// KYC/AML
+	var kycRecord domain.KYCRecord
+	invoke(&kycRecord, "InitiateKYC", domain.KYCInitiationRequest{CustomerID: customer.CustomerID, DocumentHashes: []string{"hash1"}, ActorID: "ACTOR_KYC_001"})
+	invoke(nil, "UpdateKYCStatus", domain.KYCStatusUpdateRequest{KYCID: kycRecord.KYCID, NewStatus: validation.KYCStatusVerified, VerificationNotes: "Verified", ActorID: "ACTOR_KYC_001"})
+	invoke(nil, "GetKYCRecord", kycRecord.KYCID)
+	var amlRecord domain.AMLRecord
+	invoke(&amlRecord, "InitiateAMLCheck", domain.AMLCheckRequest{CustomerID: customer.CustomerID, ActorID: "ACTOR_AML_001"})
+	invoke(nil, "UpdateAMLStatus", domain.AMLStatusUpdateRequest{AMLID: amlRecord.AMLID, NewStatus: validation.AMLStatusFlagged, RiskScore: 60, Flags: []string{"NAME_MATCH"}, Notes: "Review", ActorID: "ACTOR_AML_001"})
+	invoke(nil, "GetAMLRecord", amlRecord.AMLID)
+	invoke(nil, "GetCustomerComplianceStatus", customer.CustomerID)
+
+	// Beneficial ownership of an organization customer
+	var organization domain.Customer
+	invoke(&organization, "RegisterCustomer", domain.CustomerRegistrationRequest{
+		CustomerType:       domain.CustomerTypeOrganization,
+		LegalName:          "Contract Holdings Ltd",
+		RegistrationNumber: "CONTRACTREG001",
+		Email:              "holdings@example.com",
+		ActorID:            "ACTOR_001",
+	})
+	invoke(nil, "RecordOwnership", domain.OwnershipRequest{CustomerID: organization.CustomerID, OwnerID: customer.CustomerID, ControlType: domain.ControlTypeShareholding, Percentage: 80, ActorID: "ACTOR_001"})
+	rejected("RecordOwnership", domain.OwnershipRequest{CustomerID: customer.CustomerID, OwnerID: organization.CustomerID, ControlType: domain.ControlTypeShareholding, Percentage: 10, ActorID: "ACTOR_001"})
+	invoke(nil, "GetOwnershipStructure", organization.CustomerID)
+	invoke(nil, "GetUltimateBeneficialOwners", organization.CustomerID)
+	invoke(nil, "GetControlledEntities", customer.CustomerID)
+	invoke(nil, "InitiateAMLCheck", domain.AMLCheckRequest{CustomerID: organization.CustomerID, ActorID: "ACTOR_AML_001"})
+	invoke(nil, "RemoveOwnership", domain.OwnershipRemovalRequest{CustomerID: organization.CustomerID, OwnerID: customer.CustomerID, Reason: "Stake sold", ActorID: "ACTOR_001"})
+
+	// Relationships between customers
+	var spouse domain.Customer
+	invoke(&spouse, "RegisterCustomer", domain.CustomerRegistrationRequest{
+		FirstName:   "Carl",
+		LastName:    "Contract",
+		Email:       "carl@example.com",
+		Phone:       "+1234567891",
+		DateOfBirth: time.Date(1987, 5, 6, 0, 0, 0, 0, time.UTC),
+		NationalID:  "CONTRACT002",
+		Address:     "1 Contract Street, Test City, Test Country",
+		ActorID:     "ACTOR_001",
+	})
+	var relationship domain.CustomerRelationship
+	invoke(&relationship, "CreateRelationship", domain.RelationshipRequest{CustomerID: spouse.CustomerID, RelatedCustomerID: customer.CustomerID, RelationshipType: domain.RelationshipJointAccountHolder, ActorID: "ACTOR_001"})
+	rejected("CreateRelationship", domain.RelationshipRequest{CustomerID: customer.CustomerID, RelatedCustomerID: spouse.CustomerID, RelationshipType: domain.RelationshipJointAccountHolder, ActorID: "ACTOR_001"})
+	invoke(nil, "GetRelationship", relationship.RelationshipID)
+	invoke(nil, "GetCustomerRelationships", spouse.CustomerID)
+	invoke(nil, "GetRelatedParties", spouse.CustomerID, "2")
+	invoke(nil, "TerminateRelationship", domain.RelationshipTerminationRequest{RelationshipID: relationship.RelationshipID, Reason: "Account closed", ActorID: "ACTOR_001"})
+
+	// Queries, masked for the administrator's role
+	invoke(nil, "QueryCustomersByStatus", string(customer.Status))
+	invoke(nil, "QueryCustomersByKYCStatus", string(validation.KYCStatusVerified))
+	invoke(nil, "QueryCustomersByAMLStatus", string(validation.AMLStatusFlagged))
+	invoke(nil, "SearchCustomersByName", "contract")
+	invoke(nil, "SearchCustomersByEmail", "connie@example.com")
+	invoke(nil, "QueryKYCByStatus", string(validation.KYCStatusVerified))
+
+	// Consent
+	invoke(nil, "RecordConsent", domain.ConsentRequest{CustomerID: customer.CustomerID, Purpose: "analytics", Granted: true, Evidence: "WEB_FORM_1", ActorID: "ACTOR_001"})
+	invoke(nil, "RenewConsent", domain.ConsentRequest{CustomerID: customer.CustomerID, Purpose: "analytics", Evidence: "EMAIL_RENEWAL_1", ActorID: "ACTOR_001"})
+	invoke(nil, "GetConsentHistory", customer.CustomerID, "marketing")
+	invoke(nil, "GetConsentAt", customer.CustomerID, "marketing", "2026-06-01")
+	invoke(nil, "GetExpiringConsents", "366")
+	invoke(nil, "ProcessConsentExpiries", domain.ConsentExpiryRequest{NoticeDays: 30, ActorID: "ACTOR_001"})
+
+	// Data sharing
+	stub.Creator = officerIdentity
+	var agreement domain.DataSharingAgreement
+	invoke(&agreement, "CreateDataSharingAgreement", domain.DataSharingAgreementRequest{
+		PartnerMSP:  "Org3MSP",
+		PartnerName: "Partner Insurer",
+		Clauses:     []domain.DataSharingClause{{ClauseID: "4.1", Purpose: "marketing", DataCategories: []string{"contact"}}},
+		ActorID:     "ACTOR_CONTRACT_CO",
+	})
+	stub.Creator = adminIdentity
+	invoke(nil, "GetDataSharingAgreement", agreement.AgreementID)
+	invoke(nil, "RecordDisclosure", domain.DisclosureRequest{CustomerID: customer.CustomerID, AgreementID: agreement.AgreementID, ClauseID: "4.1", DataCategories: []string{"contact"}, ActorID: "ACTOR_001"})
+	invoke(nil, "GetDisclosureLog", customer.CustomerID)
+	invoke(nil, "GetClauseSuspensions", customer.CustomerID)
+
+	// Risk rating and the event stream
+	invoke(nil, "RecalculateCustomerRisk", domain.RiskRecalculationRequest{CustomerID: customer.CustomerID, ActorID: "ACTOR_001"})
+	invoke(nil, "GetCustomerRiskProfile", customer.CustomerID)
+	invoke(nil, "QueryCustomersAboveRiskTier", "LOW")
+	invoke(nil, "GetCustomerEventStream", customer.CustomerID, "")
+	invoke(nil, "GetCustomer360", customer.CustomerID)
+	invoke(nil, "RecordAccess", customer.CustomerID, "GetCustomer360")
+	rejected("GetCustomerAccessLog", customer.CustomerID)
+	stub.Creator = officerIdentity
+	invoke(nil, "GetCustomerAccessLog", customer.CustomerID, "2026-01-01", "")
+	stub.Creator = adminIdentity
+
+	// Quality review
+	stub.Creator = bindRoleActor(t, stub, "contract_reviewer", "ACTOR_CONTRACT_CCO", "Chief_Compliance_Officer")
+	invoke(nil, "SetQASamplingRate", sharedChaincode.QASamplingRateRequest{DecisionType: "SCREENING_CLEARANCE", Rate: 1, ActorID: "ACTOR_CONTRACT_CCO"})
+	stub.Creator = adminIdentity
+	invoke(nil, "GetQASamplingRates")
+	invoke(nil, "GetQAReviewQueue", "SCREENING_CLEARANCE")
+	rejected("GetQAReviewItem", "QA_UNKNOWN")
+	rejected("RecordQAReview", sharedChaincode.QAReviewRequest{ItemID: "QA_UNKNOWN", Outcome: "PASS", Findings: "None", ActorID: "ACTOR_001"})
+	invoke(nil, "GetQADefectReport", "2026-06-01", "2026-06-01")
+
+	// Entity notes
+	var note services.EntityNote
+	invoke(&note, "AddNote", sharedChaincode.EntityNoteRequest{EntityID: customer.CustomerID, EntityType: "Customer", Text: "Called the customer", Visibility: "INTERNAL", ActorID: "ACTOR_001"})
+	invoke(nil, "EditNote", sharedChaincode.EntityNoteEditRequest{EntityID: customer.CustomerID, NoteID: note.NoteID, Text: "Called the customer twice", ActorID: "ACTOR_001"})
+	invoke(nil, "GetEntityNotes", customer.CustomerID)
+	invoke(nil, "GetNoteHistory", customer.CustomerID, note.NoteID)
+
+	// Audit packages
+	stub.Creator = officerIdentity
+	var pkg services.AuditPackage
+	invoke(&pkg, "ExportAuditPackage", sharedChaincode.AuditPackageRequest{SubjectID: customer.CustomerID, ActorID: "ACTOR_CONTRACT_CO"})
+	invoke(nil, "GetAuditManifest", pkg.Manifest.PackageID)
+	invoke(nil, "VerifyAuditPackage", pkg)
+	// Ledger key history is not available on the mock stub
+	rejected("ExportEntityAudit", customer.CustomerID)
+	rejected("VerifyEntityAudit", services.EntityAudit{EntityID: customer.CustomerID})
+
+	// Emergency freeze: changes are blocked until a second officer approves the unfreeze
+	invoke(nil, "FreezeCustomer", sharedChaincode.EntityFreezeRequest{CustomerID: customer.CustomerID, ReasonCode: services.FreezeReasonLegalHold, Reason: "Preservation order", CaseReference: "CASE-2026-01", ActorID: "ACTOR_CONTRACT_CO"})
+	rejected("UpdateCustomer", domain.CustomerUpdateRequest{CustomerID: customer.CustomerID, Phone: &newPhone, ActorID: "ACTOR_CONTRACT_CO"})
+	invoke(nil, "RequestUnfreeze", sharedChaincode.EntityFreezeRequest{CustomerID: customer.CustomerID, Reason: "Order discharged", ActorID: "ACTOR_CONTRACT_CO"})
+	rejected("ApproveUnfreeze", sharedChaincode.EntityFreezeRequest{CustomerID: customer.CustomerID, Reason: "Order discharged", ActorID: "ACTOR_CONTRACT_CO"})
+	stub.Creator = secondOfficerIdentity
+	invoke(nil, "ApproveUnfreeze", sharedChaincode.EntityFreezeRequest{CustomerID: customer.CustomerID, Reason: "Order discharged", ActorID: "ACTOR_CONTRACT_CO2"})
+	invoke(nil, "GetEntityFreeze", customer.CustomerID)
+
+	// Data quality
+	stub.Creator = adminIdentity
+	invoke(nil, "RunDataQualitySweep", sharedChaincode.DataQualitySweepRequest{Namespace: "CUSTOMER_", ActorID: "ACTOR_001"})
+	invoke(nil, "GetDataQualityReport", "CUSTOMER_", "2026-06-01")
+	invoke(nil, "GetRemediationTasks", "CUSTOMER_")
+	rejected("ResolveRemediationTask", sharedChaincode.RemediationTaskResolution{Namespace: "CUSTOMER_", TaskID: "TASK_UNKNOWN", Notes: "Fixed", ActorID: "ACTOR_001"})
+
+	// Operational functions
+	invoke(nil, "SetFunctionFlag", sharedChaincode.FunctionFlagRequest{FunctionName: "PurgeSandboxCustomer", Disabled: false, OperatorMessage: "Enabled", ActorID: "ACTOR_OPS"})
+	invoke(nil, "GetFunctionFlags")
+	invoke(nil, "SetSandboxActor", sharedChaincode.SandboxActorRequest{SandboxActorID: "ACTOR_005", Sandbox: true, Reason: "Partner testing", ActorID: "ACTOR_001"})
+	invoke(nil, "GetSandboxActors")
+	invoke(nil, "GetActor", "ACTOR_001")
+	invoke(nil, "GetInvokerIdentity")
+	invoke(nil, "RegisterActor", sharedChaincode.ActorRegistrationRequest{RegisteredActorID: "ACTOR_CONTRACT_OPS", BlockchainIdentity: "contract-ops", MSPID: "Org1MSP", Role: "System_Administrator", Active: true, ActorID: "ACTOR_001"})
+	invoke(nil, "AttestCredentialRotation", sharedChaincode.CredentialRotationRequest{RotatedActorID: "ACTOR_OPS", RotationDate: time.Date(2026, 5, 31, 0, 0, 0, 0, time.UTC), Method: "PASSWORD_RESET", ActorID: "ACTOR_001"})
+	invoke(nil, "GetCredentialRotation", "ACTOR_OPS")
+	invoke(nil, "SetPayloadArchivePolicy", sharedChaincode.PayloadArchivePolicyRequest{FunctionName: "RegisterCustomer", RetainPayload: true, ActorID: "ACTOR_001"})
+	invoke(nil, "GetPayloadArchivePolicies")
+	stub.Creator = officerIdentity
+	invoke(nil, "GetArchivedPayload", "contract_officer")
+	stub.Creator = adminIdentity
+	invoke(nil, "CompactCounters", config.MetricCustomersRegistered)
+	invoke(nil, "GetCounter", config.MetricCustomersRegistered, "2026-06")
+	invoke(nil, "ValidateStateCompatibility")
+	invoke(nil, "RecordTimestampCutover", sharedChaincode.TimestampCutoverRequest{ActorID: "ACTOR_001"})
+	invoke(nil, "GetTimestampCutover")
+	invoke(nil, "UpdateCustomerStatus", domain.CustomerStatusUpdateRequest{CustomerID: customer.CustomerID, NewStatus: "SUSPENDED", Reason: "Contract test", ActorID: "ACTOR_001"})
+
+	for _, function := range chaincode.CustomerAPI().Functions() {
+		assert.True(t, invoked[function], "%s is not invoked by the contract test", function)
+	}
+}
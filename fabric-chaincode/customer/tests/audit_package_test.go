@@ -0,0 +1,134 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	sharedChaincode "github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/chaincode"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+func TestExportAuditPackageRecordsVerifiableManifest(t *testing.T) {
+	stub := newCustomerStub(t)
+	adminIdentity := stub.Creator
+
+	customer := registerSearchCustomer(t, stub, "audit1", "Ada", "Audit", "ada@example.com")
+	amlBytes, err := json.Marshal(domain.AMLCheckRequest{CustomerID: customer.CustomerID, ActorID: "ACTOR_001"})
+	require.NoError(t, err)
+	response := stub.MockInvoke("audit_2", [][]byte{[]byte("InitiateAMLCheck"), amlBytes})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+
+	reqBytes, err := json.Marshal(sharedChaincode.AuditPackageRequest{SubjectID: customer.CustomerID, ActorID: "ACTOR_001"})
+	require.NoError(t, err)
+
+	// Only compliance officers and regulators may export
+	response = stub.MockInvoke("audit_3", [][]byte{[]byte("ExportAuditPackage"), reqBytes})
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	assert.Contains(t, response.Message, "may only be exported by")
+
+	officerIdentity := newTestIdentity(t, "Org1MSP", "officer", "Compliance_Officer")
+	stub.Creator = officerIdentity
+	registerTestActorAs(t, stub, "audit_4", "ACTOR_CO", "Compliance_Officer", adminIdentity)
+
+	reqBytes, err = json.Marshal(sharedChaincode.AuditPackageRequest{SubjectID: customer.CustomerID, ActorID: "ACTOR_CO"})
+	require.NoError(t, err)
+	for len(stub.ChaincodeEventsChannel) > 0 {
+		<-stub.ChaincodeEventsChannel
+	}
+	response = stub.MockInvoke("audit_5", [][]byte{[]byte("ExportAuditPackage"), reqBytes})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	var pkg services.AuditPackage
+	require.NoError(t, json.Unmarshal(response.Payload, &pkg))
+	assert.Equal(t, services.AuditPackageFormat, pkg.Manifest.Format)
+	assert.Equal(t, "ACTOR_CO", pkg.Manifest.ExportedBy)
+	assert.Equal(t, "Org1MSP", pkg.Manifest.ExportedByMSP)
+	assert.NotEmpty(t, pkg.Manifest.ManifestHash)
+	require.Equal(t, len(pkg.Sections), len(pkg.Manifest.Sections))
+	counts := map[string]int{}
+	for _, section := range pkg.Manifest.Sections {
+		counts[section.Name] = section.RecordCount
+	}
+	assert.Equal(t, 1, counts["customer"])
+	assert.Equal(t, 1, counts["aml"])
+	assert.Equal(t, 0, counts["kyc"]) // Searched but empty
+	assert.Greater(t, counts["history"], 0)
+	require.Len(t, stub.ChaincodeEventsChannel, 1)
+	assert.Equal(t, config.EventAuditPackageExported, (<-stub.ChaincodeEventsChannel).EventName)
+
+	// The manifest is on the ledger
+	response = stub.MockInvoke("audit_6", [][]byte{[]byte("GetAuditManifest"), []byte(pkg.Manifest.PackageID)})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	var manifest services.AuditManifest
+	require.NoError(t, json.Unmarshal(response.Payload, &manifest))
+	assert.Equal(t, pkg.Manifest.ManifestHash, manifest.ManifestHash)
+
+	verify := func(txID string, pkg services.AuditPackage) services.AuditPackageVerification {
+		pkgBytes, err := json.Marshal(pkg)
+		require.NoError(t, err)
+		response := stub.MockInvoke(txID, [][]byte{[]byte("VerifyAuditPackage"), pkgBytes})
+		require.Equal(t, int32(shim.OK), response.Status, response.Message)
+		var verification services.AuditPackageVerification
+		require.NoError(t, json.Unmarshal(response.Payload, &verification))
+		return verification
+	}
+	verification := verify("audit_7", pkg)
+	assert.True(t, verification.Valid, verification.Problems)
+
+	// A tampered record is caught even though the manifest handed over is unchanged
+	var tampered services.AuditPackage
+	pkgBytes, err := json.Marshal(pkg)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(pkgBytes, &tampered))
+	for i, section := range tampered.Sections {
+		if section.Name == "customer" {
+			tampered.Sections[i].Records[0] = json.RawMessage(`{"customerID":"` + customer.CustomerID + `","status":"ACTIVE"}`)
+		}
+	}
+	verification = verify("audit_8", tampered)
+	assert.False(t, verification.Valid)
+	assert.Equal(t, []string{"section customer has been altered"}, verification.Problems)
+
+	// So is a manifest rewritten to match
+	tampered.Manifest.ExportedBy = "ACTOR_001"
+	verification = verify("audit_9", tampered)
+	assert.False(t, verification.Valid)
+	assert.Contains(t, verification.Problems, "manifest does not match the manifest recorded on the ledger")
+
+	// Nothing to export for an unknown subject
+	reqBytes, err = json.Marshal(sharedChaincode.AuditPackageRequest{SubjectID: "CUST_UNKNOWN", ActorID: "ACTOR_CO"})
+	require.NoError(t, err)
+	response = stub.MockInvoke("audit_10", [][]byte{[]byte("ExportAuditPackage"), reqBytes})
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	assert.Contains(t, response.Message, "no audit records found")
+}
+
+// registerTestActorAs binds an actor to the stub's current creator, registering it with the
+// administrator identity
+func registerTestActorAs(t *testing.T, stub *shimtest.MockStub, txID, actorID, role string, adminIdentity []byte) {
+	response := stub.MockInvoke(txID+"_whoami", [][]byte{[]byte("GetInvokerIdentity")})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	var invoker sharedChaincode.InvokerIdentity
+	require.NoError(t, json.Unmarshal(response.Payload, &invoker))
+
+	creator := stub.Creator
+	stub.Creator = adminIdentity
+	defer func() { stub.Creator = creator }()
+
+	reqBytes, err := json.Marshal(sharedChaincode.ActorRegistrationRequest{
+		RegisteredActorID:  actorID,
+		BlockchainIdentity: invoker.BlockchainIdentity,
+		MSPID:              invoker.MSPID,
+		Role:               role,
+		Active:             true,
+		ActorID:            "ACTOR_ADMIN",
+	})
+	require.NoError(t, err)
+	response = stub.MockInvoke(txID, [][]byte{[]byte("RegisterActor"), reqBytes})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+}
@@ -0,0 +1,269 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+func registerIndividual(t *testing.T, stub *shimtest.MockStub, txID, firstName, lastName, nationalID string) domain.Customer {
+	return registerCustomerRequest(t, stub, txID, domain.CustomerRegistrationRequest{
+		FirstName:   firstName,
+		LastName:    lastName,
+		Email:       "owner@example.com",
+		DateOfBirth: time.Date(1975, 6, 1, 0, 0, 0, 0, time.UTC),
+		NationalID:  nationalID,
+		ActorID:     "ACTOR_TEST",
+	})
+}
+
+func registerOrganization(t *testing.T, stub *shimtest.MockStub, txID, legalName, registrationNumber string) domain.Customer {
+	return registerCustomerRequest(t, stub, txID, domain.CustomerRegistrationRequest{
+		CustomerType:       domain.CustomerTypeOrganization,
+		LegalName:          legalName,
+		RegistrationNumber: registrationNumber,
+		Email:              "registry@example.com",
+		ActorID:            "ACTOR_TEST",
+	})
+}
+
+func registerCustomerRequest(t *testing.T, stub *shimtest.MockStub, txID string, req domain.CustomerRegistrationRequest) domain.Customer {
+	reqBytes, err := json.Marshal(req)
+	require.NoError(t, err)
+	response := stub.MockInvoke(txID, [][]byte{[]byte("RegisterCustomer"), reqBytes})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+
+	var customer domain.Customer
+	require.NoError(t, json.Unmarshal(response.Payload, &customer))
+	return customer
+}
+
+func recordOwnership(stub *shimtest.MockStub, txID, organizationID, ownerID string, controlType domain.ControlType, percentage float64) peer.Response {
+	reqBytes, _ := json.Marshal(domain.OwnershipRequest{
+		CustomerID:  organizationID,
+		OwnerID:     ownerID,
+		ControlType: controlType,
+		Percentage:  percentage,
+		ActorID:     "ACTOR_TEST",
+	})
+	return stub.MockInvoke(txID, [][]byte{[]byte("RecordOwnership"), reqBytes})
+}
+
+// ownershipFixture is a holding company owned 60/40 by two individuals, which holds half of an
+// operating company whose remaining recorded shares belong to a third individual
+type ownershipFixture struct {
+	holdco, opco               domain.Customer
+	majority, minority, direct domain.Customer
+}
+
+func newOwnershipFixture(t *testing.T, stub *shimtest.MockStub) ownershipFixture {
+	f := ownershipFixture{
+		holdco:   registerOrganization(t, stub, "ubo_org_1", "Holding Company Ltd", "REG1001"),
+		opco:     registerOrganization(t, stub, "ubo_org_2", "Operating Company Ltd", "REG1002"),
+		majority: registerIndividual(t, stub, "ubo_ind_1", "Mara", "Majority", "UBOTEST001"),
+		minority: registerIndividual(t, stub, "ubo_ind_2", "Milo", "Minority", "UBOTEST002"),
+		direct:   registerIndividual(t, stub, "ubo_ind_3", "Dana", "Direct", "UBOTEST003"),
+	}
+
+	for i, link := range []struct {
+		organization, owner domain.Customer
+		percentage          float64
+	}{
+		{f.holdco, f.majority, 60},
+		{f.holdco, f.minority, 40},
+		{f.opco, f.holdco, 50},
+		{f.opco, f.direct, 30},
+	} {
+		response := recordOwnership(stub, "ubo_link_"+string(rune('a'+i)), link.organization.CustomerID, link.owner.CustomerID, domain.ControlTypeShareholding, link.percentage)
+		require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	}
+	return f
+}
+
+func TestUltimateBeneficialOwnersResolveThroughIntermediateOrganizations(t *testing.T) {
+	stub := newCustomerStub(t)
+	f := newOwnershipFixture(t, stub)
+
+	response := stub.MockInvoke("ubo_get", [][]byte{[]byte("GetUltimateBeneficialOwners"), []byte(f.opco.CustomerID)})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	var resolution domain.BeneficialOwnership
+	require.NoError(t, json.Unmarshal(response.Payload, &resolution))
+
+	// 60% of 50% through the holding company, and a direct 30%; 40% of 50% falls below the threshold
+	require.Len(t, resolution.BeneficialOwners, 2)
+	assert.Equal(t, f.majority.CustomerID, resolution.BeneficialOwners[0].CustomerID)
+	assert.InDelta(t, 30.0, resolution.BeneficialOwners[0].EffectivePercentage, 0.0001)
+	assert.Equal(t, "Mara Majority", resolution.BeneficialOwners[0].Name)
+	assert.Equal(t, [][]string{{f.majority.CustomerID, f.holdco.CustomerID, f.opco.CustomerID}}, resolution.BeneficialOwners[0].Chains)
+	assert.False(t, resolution.BeneficialOwners[0].HasControl)
+	assert.Equal(t, f.direct.CustomerID, resolution.BeneficialOwners[1].CustomerID)
+	require.Len(t, resolution.OtherOwners, 1)
+	assert.Equal(t, f.minority.CustomerID, resolution.OtherOwners[0].CustomerID)
+	assert.InDelta(t, 20.0, resolution.OtherOwners[0].EffectivePercentage, 0.0001)
+	assert.Empty(t, resolution.UnresolvedOrganizations)
+
+	// A majority holding controls the holding company outright
+	response = stub.MockInvoke("ubo_get_holdco", [][]byte{[]byte("GetUltimateBeneficialOwners"), []byte(f.holdco.CustomerID)})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	require.NoError(t, json.Unmarshal(response.Payload, &resolution))
+	require.Len(t, resolution.BeneficialOwners, 2)
+	assert.True(t, resolution.BeneficialOwners[0].HasControl)
+	assert.False(t, resolution.BeneficialOwners[1].HasControl)
+
+	// Board appointment through a controlled holding company controls the operating company too
+	response = recordOwnership(stub, "ubo_board", f.opco.CustomerID, f.holdco.CustomerID, domain.ControlTypeBoardAppointment, 0)
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	response = stub.MockInvoke("ubo_get_board", [][]byte{[]byte("GetUltimateBeneficialOwners"), []byte(f.opco.CustomerID)})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	require.NoError(t, json.Unmarshal(response.Payload, &resolution))
+	require.Len(t, resolution.BeneficialOwners, 2)
+	assert.Equal(t, f.direct.CustomerID, resolution.BeneficialOwners[0].CustomerID)
+	assert.Equal(t, f.majority.CustomerID, resolution.BeneficialOwners[1].CustomerID)
+	assert.True(t, resolution.BeneficialOwners[1].HasControl)
+	assert.Zero(t, resolution.BeneficialOwners[1].EffectivePercentage)
+}
+
+func TestControlledEntitiesListEveryOrganizationAnIndividualUltimatelyOwns(t *testing.T) {
+	stub := newCustomerStub(t)
+	f := newOwnershipFixture(t, stub)
+
+	controlled := func(txID, customerID string) domain.ControlledEntitiesResult {
+		response := stub.MockInvoke(txID, [][]byte{[]byte("GetControlledEntities"), []byte(customerID)})
+		require.Equal(t, int32(shim.OK), response.Status, response.Message)
+		var result domain.ControlledEntitiesResult
+		require.NoError(t, json.Unmarshal(response.Payload, &result))
+		return result
+	}
+
+	majority := controlled("ubo_ctl_1", f.majority.CustomerID)
+	require.Equal(t, 2, majority.Count)
+	byID := map[string]domain.ControlledEntity{}
+	for _, entity := range majority.Entities {
+		byID[entity.CustomerID] = entity
+	}
+	assert.InDelta(t, 60.0, byID[f.holdco.CustomerID].EffectivePercentage, 0.0001)
+	assert.True(t, byID[f.holdco.CustomerID].HasControl)
+	assert.Equal(t, "Operating Company Ltd", byID[f.opco.CustomerID].LegalName)
+	assert.InDelta(t, 30.0, byID[f.opco.CustomerID].EffectivePercentage, 0.0001)
+
+	minority := controlled("ubo_ctl_2", f.minority.CustomerID)
+	require.Equal(t, 1, minority.Count)
+	assert.Equal(t, f.holdco.CustomerID, minority.Entities[0].CustomerID)
+
+	response := stub.MockInvoke("ubo_ctl_org", [][]byte{[]byte("GetControlledEntities"), []byte(f.holdco.CustomerID)})
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+}
+
+func TestRecordOwnershipRejectsCyclesAndOverallocation(t *testing.T) {
+	stub := newCustomerStub(t)
+	f := newOwnershipFixture(t, stub)
+
+	response := recordOwnership(stub, "ubo_cycle", f.holdco.CustomerID, f.opco.CustomerID, domain.ControlTypeShareholding, 10)
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	assert.Contains(t, response.Message, "circular")
+
+	response = recordOwnership(stub, "ubo_over", f.opco.CustomerID, f.minority.CustomerID, domain.ControlTypeShareholding, 25)
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	assert.Contains(t, response.Message, "may not exceed 100%")
+
+	// Only organizations have owners, and board control carries no percentage
+	response = recordOwnership(stub, "ubo_individual", f.direct.CustomerID, f.majority.CustomerID, domain.ControlTypeShareholding, 10)
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	response = recordOwnership(stub, "ubo_board_pct", f.opco.CustomerID, f.minority.CustomerID, domain.ControlTypeBoardAppointment, 10)
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+
+	// Removing an owner frees their allocation
+	removeBytes, _ := json.Marshal(domain.OwnershipRemovalRequest{
+		CustomerID: f.opco.CustomerID,
+		OwnerID:    f.direct.CustomerID,
+		Reason:     "Stake sold",
+		ActorID:    "ACTOR_TEST",
+	})
+	removed := stub.MockInvoke("ubo_remove", [][]byte{[]byte("RemoveOwnership"), removeBytes})
+	require.Equal(t, int32(shim.OK), removed.Status, removed.Message)
+	response = recordOwnership(stub, "ubo_rebuy", f.opco.CustomerID, f.minority.CustomerID, domain.ControlTypeShareholding, 25)
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+
+	structureResponse := stub.MockInvoke("ubo_structure", [][]byte{[]byte("GetOwnershipStructure"), []byte(f.opco.CustomerID)})
+	require.Equal(t, int32(shim.OK), structureResponse.Status, structureResponse.Message)
+	var structure domain.OwnershipStructure
+	require.NoError(t, json.Unmarshal(structureResponse.Payload, &structure))
+	assert.Len(t, structure.Owners, 2)
+	assert.InDelta(t, 75.0, structure.TotalShareholding, 0.0001)
+}
+
+func TestOrganizationRegistrationIsUniqueByRegistrationNumber(t *testing.T) {
+	stub := newCustomerStub(t)
+	organization := registerOrganization(t, stub, "ubo_reg_1", "Registered Company Ltd", "REG2001")
+	assert.Equal(t, domain.CustomerTypeOrganization, organization.CustomerType)
+	assert.Empty(t, organization.NationalID)
+
+	reqBytes, _ := json.Marshal(domain.CustomerRegistrationRequest{
+		CustomerType:       domain.CustomerTypeOrganization,
+		LegalName:          "Another Company Ltd",
+		RegistrationNumber: "REG2001",
+		Email:              "other@example.com",
+		ActorID:            "ACTOR_TEST",
+	})
+	response := stub.MockInvoke("ubo_reg_2", [][]byte{[]byte("RegisterCustomer"), reqBytes})
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	assert.Contains(t, response.Message, "already exists")
+
+	reqBytes, _ = json.Marshal(domain.CustomerRegistrationRequest{
+		CustomerType: domain.CustomerTypeOrganization,
+		Email:        "missing@example.com",
+		ActorID:      "ACTOR_TEST",
+	})
+	response = stub.MockInvoke("ubo_reg_3", [][]byte{[]byte("RegisterCustomer"), reqBytes})
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	assert.Contains(t, response.Message, "legalName is required")
+}
+
+func TestOrganizationAMLCheckScreensEveryBeneficialOwner(t *testing.T) {
+	stub := newCustomerStub(t)
+	stub.MockPeerChaincode("compliance", shimtest.NewMockStub("compliance", &fakeComplianceChaincode{
+		pepNames: []string{"Dana Direct"},
+	}), "")
+	f := newOwnershipFixture(t, stub)
+
+	amlCheck := func(txID, customerID string) domain.AMLRecord {
+		amlBytes, _ := json.Marshal(domain.AMLCheckRequest{CustomerID: customerID, ActorID: "ACTOR_AML_001"})
+		response := stub.MockInvoke(txID, [][]byte{[]byte("InitiateAMLCheck"), amlBytes})
+		require.Equal(t, int32(shim.OK), response.Status, response.Message)
+		var amlRecord domain.AMLRecord
+		require.NoError(t, json.Unmarshal(response.Payload, &amlRecord))
+		return amlRecord
+	}
+
+	amlRecord := amlCheck("ubo_aml_1", f.opco.CustomerID)
+	assert.Equal(t, validation.AMLStatusReviewing, amlRecord.Status)
+	assert.Contains(t, amlRecord.Flags, "UBO_PEP_MATCH")
+	assert.NotContains(t, amlRecord.Flags, "PEP_MATCH")
+	assert.InDelta(t, 95.0, amlRecord.RiskScore, 0.001)
+	require.Len(t, amlRecord.ScreenedOwners, 2)
+	matches := map[string]bool{}
+	for _, screening := range amlRecord.ScreenedOwners {
+		matches[screening.CustomerID] = screening.IsMatch
+	}
+	assert.Equal(t, map[string]bool{f.majority.CustomerID: false, f.direct.CustomerID: true}, matches)
+
+	// The holding company's owners are not politically exposed
+	amlRecord = amlCheck("ubo_aml_2", f.holdco.CustomerID)
+	assert.Equal(t, validation.AMLStatusClear, amlRecord.Status)
+	assert.Len(t, amlRecord.ScreenedOwners, 2)
+
+	// An organization without recorded owners cannot be cleared
+	unowned := registerOrganization(t, stub, "ubo_org_3", "Unowned Company Ltd", "REG1003")
+	amlRecord = amlCheck("ubo_aml_3", unowned.CustomerID)
+	assert.Equal(t, validation.AMLStatusReviewing, amlRecord.Status)
+	assert.Contains(t, amlRecord.Flags, "UBO_INCOMPLETE")
+	assert.Empty(t, amlRecord.ScreenedOwners)
+}
@@ -0,0 +1,212 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+func TestConsentRecordsKeepEvidenceOfEveryChange(t *testing.T) {
+	stub := newCustomerStub(t)
+	customer := registerSearchCustomer(t, stub, "consent1", "Cleo", "Consent", "cleo@example.com")
+
+	recordConsent := func(txID string, req domain.ConsentRequest) (*domain.ConsentRecord, string) {
+		reqBytes, err := json.Marshal(req)
+		require.NoError(t, err)
+		response := stub.MockInvoke(txID, [][]byte{[]byte("RecordConsent"), reqBytes})
+		if response.Status != shim.OK {
+			return nil, response.Message
+		}
+		var record domain.ConsentRecord
+		require.NoError(t, json.Unmarshal(response.Payload, &record))
+		return &record, ""
+	}
+	consentAt := func(txID, purpose, asOf string) domain.ConsentStatus {
+		response := stub.MockInvoke(txID, [][]byte{[]byte("GetConsentAt"), []byte(customer.CustomerID), []byte(purpose), []byte(asOf)})
+		require.Equal(t, int32(shim.OK), response.Status, response.Message)
+		var status domain.ConsentStatus
+		require.NoError(t, json.Unmarshal(response.Payload, &status))
+		return status
+	}
+	history := func(txID string, args ...string) domain.ConsentHistoryResult {
+		invokeArgs := [][]byte{[]byte("GetConsentHistory"), []byte(customer.CustomerID)}
+		for _, arg := range args {
+			invokeArgs = append(invokeArgs, []byte(arg))
+		}
+		response := stub.MockInvoke(txID, invokeArgs)
+		require.Equal(t, int32(shim.OK), response.Status, response.Message)
+		var result domain.ConsentHistoryResult
+		require.NoError(t, json.Unmarshal(response.Payload, &result))
+		return result
+	}
+
+	// Registration records the first version of each consent given
+	result := history("consent_2", "marketing")
+	require.Equal(t, 1, result.Count)
+	registered := result.Records[0]
+	assert.Equal(t, 1, registered.Version)
+	assert.True(t, registered.Granted)
+	assert.Equal(t, domain.ConsentSourceRegistration, registered.Source)
+
+	// Withdrawing adds a version rather than overwriting the grant
+	for len(stub.ChaincodeEventsChannel) > 0 {
+		<-stub.ChaincodeEventsChannel
+	}
+	withdrawal, message := recordConsent("consent_3", domain.ConsentRequest{CustomerID: customer.CustomerID, Purpose: "marketing", Granted: false, Evidence: "CALL_20260301_118", ActorID: "ACTOR_001"})
+	require.Empty(t, message)
+	assert.Equal(t, 2, withdrawal.Version)
+	assert.False(t, withdrawal.Granted)
+	assert.Equal(t, "CALL_20260301_118", withdrawal.Evidence)
+	require.Len(t, stub.ChaincodeEventsChannel, 1)
+	assert.Equal(t, config.EventConsentRecorded, (<-stub.ChaincodeEventsChannel).EventName)
+
+	_, message = recordConsent("consent_4", domain.ConsentRequest{CustomerID: customer.CustomerID, Purpose: "marketing", Granted: false, ActorID: "ACTOR_001"})
+	assert.Contains(t, message, "already withdrawn")
+
+	// The customer's consent preferences follow the latest record
+	response := stub.MockInvoke("consent_5", [][]byte{[]byte("GetCustomer"), []byte(customer.CustomerID)})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	var updated domain.Customer
+	require.NoError(t, json.Unmarshal(response.Payload, &updated))
+	assert.False(t, domain.ConsentGranted(updated.ConsentPreferences, "marketing"))
+
+	// A consent granted by customer update is recorded too
+	consent := `{"marketing": false, "creditCheck": true}`
+	updateBytes, err := json.Marshal(domain.CustomerUpdateRequest{CustomerID: customer.CustomerID, ConsentPreferences: &consent, ActorID: "ACTOR_001"})
+	require.NoError(t, err)
+	response = stub.MockInvoke("consent_6", [][]byte{[]byte("UpdateCustomer"), updateBytes})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+
+	result = history("consent_7")
+	require.Equal(t, 3, result.Count)
+	assert.Equal(t, config.ConsentCreditCheck, result.Records[0].Purpose)
+	assert.Equal(t, domain.ConsentSourceUpdate, result.Records[0].Source)
+	assert.Equal(t, []int{1, 2}, []int{result.Records[1].Version, result.Records[2].Version})
+
+	result = history("consent_8", "", "1")
+	require.Equal(t, 1, result.Count)
+	assert.NotEmpty(t, result.Bookmark)
+
+	// Point-in-time lookups see the consent in force at the time, not the current one
+	status := consentAt("consent_9", "marketing", registered.EffectiveDate.Format(time.RFC3339Nano))
+	assert.True(t, status.Granted)
+	require.NotNil(t, status.Record)
+	assert.Equal(t, 1, status.Record.Version)
+
+	status = consentAt("consent_10", "marketing", withdrawal.EffectiveDate.Format(time.RFC3339Nano))
+	assert.False(t, status.Granted)
+	assert.Equal(t, 2, status.Record.Version)
+
+	status = consentAt("consent_11", "marketing", "2000-01-01")
+	assert.False(t, status.Granted)
+	assert.Nil(t, status.Record)
+	assert.Equal(t, time.Date(2000, 1, 1, 23, 59, 59, 999999999, time.UTC), status.AsOf)
+
+	response = stub.MockInvoke("consent_12", [][]byte{[]byte("GetConsentAt"), []byte(customer.CustomerID), []byte("marketing"), []byte("yesterday")})
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+}
+
+func TestConsentExpiryIsAnnouncedRenewableAndLapses(t *testing.T) {
+	clock := services.NewFixedClock(time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC))
+	defer services.SetClock(clock)()
+
+	stub := newCustomerStub(t)
+	customer := registerSearchCustomer(t, stub, "expiry1", "Ezra", "Expiry", "ezra@example.com")
+
+	expiring := func(txID, withinDays string) domain.ExpiringConsentsResult {
+		response := stub.MockInvoke(txID, [][]byte{[]byte("GetExpiringConsents"), []byte(withinDays)})
+		require.Equal(t, int32(shim.OK), response.Status, response.Message)
+		var result domain.ExpiringConsentsResult
+		require.NoError(t, json.Unmarshal(response.Payload, &result))
+		return result
+	}
+	process := func(txID string, noticeDays int) domain.ConsentExpiryReport {
+		for len(stub.ChaincodeEventsChannel) > 0 {
+			<-stub.ChaincodeEventsChannel
+		}
+		reqBytes, err := json.Marshal(domain.ConsentExpiryRequest{NoticeDays: noticeDays, ActorID: "ACTOR_001"})
+		require.NoError(t, err)
+		response := stub.MockInvoke(txID, [][]byte{[]byte("ProcessConsentExpiries"), reqBytes})
+		require.Equal(t, int32(shim.OK), response.Status, response.Message)
+		var report domain.ConsentExpiryReport
+		require.NoError(t, json.Unmarshal(response.Payload, &report))
+		return report
+	}
+	renew := func(txID, purpose string) (*domain.ConsentRecord, string) {
+		reqBytes, err := json.Marshal(domain.ConsentRequest{CustomerID: customer.CustomerID, Purpose: purpose, Evidence: "EMAIL_RENEWAL_77", ActorID: "ACTOR_001"})
+		require.NoError(t, err)
+		response := stub.MockInvoke(txID, [][]byte{[]byte("RenewConsent"), reqBytes})
+		if response.Status != shim.OK {
+			return nil, response.Message
+		}
+		var record domain.ConsentRecord
+		require.NoError(t, json.Unmarshal(response.Payload, &record))
+		return &record, ""
+	}
+
+	// A grant runs for a year from registration
+	assert.Equal(t, 0, expiring("expiry_2", "30").Count)
+	result := expiring("expiry_3", "366")
+	require.Equal(t, 1, result.Count)
+	assert.Equal(t, "marketing", result.Consents[0].Purpose)
+	assert.Equal(t, 1, result.Consents[0].Version)
+
+	// Renewing adds a version and keeps the grant it renews
+	renewal, message := renew("expiry_4", "marketing")
+	require.Empty(t, message)
+	assert.Equal(t, 2, renewal.Version)
+	assert.Equal(t, domain.ConsentSourceRenewal, renewal.Source)
+	require.NotNil(t, renewal.ExpiryDate)
+
+	_, message = renew("expiry_5", config.ConsentCreditCheck)
+	assert.Contains(t, message, "not granted")
+
+	historyResponse := stub.MockInvoke("expiry_6", [][]byte{[]byte("GetConsentHistory"), []byte(customer.CustomerID), []byte("marketing")})
+	require.Equal(t, int32(shim.OK), historyResponse.Status, historyResponse.Message)
+	var history domain.ConsentHistoryResult
+	require.NoError(t, json.Unmarshal(historyResponse.Payload, &history))
+	require.Equal(t, 2, history.Count)
+	assert.Equal(t, domain.ConsentSourceRegistration, history.Records[0].Source)
+
+	result = expiring("expiry_7", "366")
+	require.Equal(t, 1, result.Count)
+	assert.Equal(t, 2, result.Consents[0].Version)
+
+	// Grants within the notice period are announced once
+	report := process("expiry_8", 366)
+	assert.Len(t, report.Expiring, 1)
+	assert.Empty(t, report.Expired)
+	assert.True(t, report.Complete)
+	require.Len(t, stub.ChaincodeEventsChannel, 1)
+	assert.Equal(t, config.EventConsentExpiring, (<-stub.ChaincodeEventsChannel).EventName)
+
+	report = process("expiry_9", 366)
+	assert.Empty(t, report.Expiring)
+	assert.Len(t, stub.ChaincodeEventsChannel, 0)
+
+	// A grant past expiry lapses into a withdrawal
+	clock.Advance(config.ConsentValidityPeriod + time.Hour)
+	report = process("expiry_11", 0)
+	require.Len(t, report.Expired, 1)
+	assert.Equal(t, "marketing", report.Expired[0].Purpose)
+	require.Len(t, stub.ChaincodeEventsChannel, 1)
+	assert.Equal(t, config.EventConsentExpired, (<-stub.ChaincodeEventsChannel).EventName)
+
+	assert.Equal(t, 0, expiring("expiry_12", "366").Count)
+	status := stub.MockInvoke("expiry_13", [][]byte{[]byte("GetConsentAt"), []byte(customer.CustomerID), []byte("marketing"), []byte("2027-03-02")})
+	require.Equal(t, int32(shim.OK), status.Status, status.Message)
+	var consentStatus domain.ConsentStatus
+	require.NoError(t, json.Unmarshal(status.Payload, &consentStatus))
+	assert.False(t, consentStatus.Granted)
+	assert.Equal(t, domain.ConsentSourceExpiry, consentStatus.Record.Source)
+
+	_, message = renew("expiry_14", "marketing")
+	assert.Contains(t, message, "not granted")
+}
@@ -0,0 +1,139 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+func TestRegisterCustomerMaintainsDailyAndMonthlyCounters(t *testing.T) {
+	clock := services.NewFixedClock(time.Date(2026, 4, 30, 9, 0, 0, 0, time.UTC))
+	defer services.SetClock(clock)()
+	stub := newCustomerStub(t)
+
+	register := func(txID, nationalID, idempotencyKey string) {
+		req := domain.CustomerRegistrationRequest{
+			FirstName:          "Counted",
+			LastName:           "Customer",
+			Email:              "counted@example.com",
+			Phone:              "+1234567890",
+			DateOfBirth:        time.Date(1985, 6, 1, 0, 0, 0, 0, time.UTC),
+			NationalID:         nationalID,
+			Address:            "1 Count Road, Test City, Test Country",
+			ConsentPreferences: `{"marketing": false}`,
+			IdempotencyKey:     idempotencyKey,
+			ActorID:            "ACTOR_001",
+		}
+		reqBytes, err := json.Marshal(req)
+		require.NoError(t, err)
+		response := stub.MockInvoke(txID, [][]byte{[]byte("RegisterCustomer"), reqBytes})
+		require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	}
+	count := func(period string) int64 {
+		response := stub.MockInvoke(fmt.Sprintf("count_%s", period), [][]byte{[]byte("GetCounter"), []byte(config.MetricCustomersRegistered), []byte(period)})
+		require.Equal(t, int32(shim.OK), response.Status, response.Message)
+		var counter services.Counter
+		require.NoError(t, json.Unmarshal(response.Payload, &counter))
+		return counter.Count
+	}
+
+	compact := func(txID string) {
+		response := stub.MockInvoke(txID, [][]byte{[]byte("CompactCounters"), []byte(config.MetricCustomersRegistered)})
+		require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	}
+
+	register("count_1", "COUNT001", "count-request-1")
+	register("count_2", "COUNT002", "")
+
+	// A replayed registration returns the original customer without counting it again
+	register("count_3", "COUNT001", "count-request-1")
+
+	// Totals are read from one key, which compaction brings up to date
+	assert.Equal(t, int64(0), count("2026-04-30"))
+	compact("compact_1")
+	assert.Equal(t, int64(2), count("2026-04-30"))
+
+	clock.Advance(24 * time.Hour)
+	register("count_4", "COUNT003", "")
+	compact("compact_2")
+	compact("compact_3")
+	assert.Equal(t, int64(2), count("2026-04-30"))
+	assert.Equal(t, int64(1), count("2026-05-01"))
+	assert.Equal(t, int64(2), count("2026-04"))
+	assert.Equal(t, int64(1), count("2026-05"))
+
+	// Periods with nothing counted read as zero
+	assert.Equal(t, int64(0), count("2026-06"))
+
+	response := stub.MockInvoke("count_bad", [][]byte{[]byte("GetCounter"), []byte(config.MetricCustomersRegistered), []byte("2026")})
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	assert.Contains(t, response.Message, "invalid period")
+}
+
+func TestCounterIncrementsWriteOneDeltaPerTransaction(t *testing.T) {
+	clock := services.NewFixedClock(time.Date(2026, 7, 14, 9, 0, 0, 0, time.UTC))
+	defer services.SetClock(clock)()
+	stub := newCustomerStub(t)
+
+	increment := func(txID string, amounts ...int64) {
+		stub.MockTransactionStart(txID)
+		defer stub.MockTransactionEnd(txID)
+		invocation := services.NewInvocation(stub)
+		for _, n := range amounts {
+			require.NoError(t, services.IncrementCounterBy(invocation, config.MetricComplianceEvents, n))
+		}
+	}
+	deltaKeys := func(period string) int {
+		iterator, err := stub.GetStateByPartialCompositeKey(config.CounterPrefix, []string{config.MetricComplianceEvents, period})
+		require.NoError(t, err)
+		defer iterator.Close()
+		keys := 0
+		for iterator.HasNext() {
+			_, err := iterator.Next()
+			require.NoError(t, err)
+			keys++
+		}
+		return keys
+	}
+
+	// Increments within one transaction accumulate in that transaction's delta key
+	increment("delta_1", 1, 2)
+	increment("delta_2", 4)
+
+	// Each transaction writes its own key, so concurrent transactions never share a write
+	assert.Equal(t, 2, deltaKeys("2026-07-14"))
+	assert.Equal(t, 2, deltaKeys("2026-07"))
+
+	// Compaction folds the deltas into the totals and removes them
+	stub.MockTransactionStart("delta_compact")
+	compacted, err := services.CompactCounters(stub, config.MetricComplianceEvents, "2026-07-14")
+	stub.MockTransactionEnd("delta_compact")
+	require.NoError(t, err)
+	require.Len(t, compacted, 1)
+	assert.Equal(t, int64(7), compacted[0].Count)
+	assert.Equal(t, 0, deltaKeys("2026-07-14"))
+	assert.Equal(t, 2, deltaKeys("2026-07"), "Compacting a day leaves the month's deltas alone")
+
+	increment("delta_3", 3)
+	stub.MockTransactionStart("delta_compact_2")
+	_, err = services.CompactCounters(stub, config.MetricComplianceEvents, "")
+	stub.MockTransactionEnd("delta_compact_2")
+	require.NoError(t, err)
+
+	stub.MockTransactionStart("delta_read")
+	defer stub.MockTransactionEnd("delta_read")
+	counter, err := services.GetCounter(stub, config.MetricComplianceEvents, "2026-07-14")
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), counter.Count)
+	counter, err = services.GetCounter(stub, config.MetricComplianceEvents, "2026-07")
+	require.NoError(t, err)
+	assert.Equal(t, int64(10), counter.Count)
+}
@@ -0,0 +1,123 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	sharedChaincode "github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/chaincode"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// backdateActor makes an actor's registration look older than it is
+func backdateActor(t *testing.T, stub *shimtest.MockStub, actorID string, age time.Duration) {
+	stub.MockTransactionStart("backdate_" + actorID)
+	defer stub.MockTransactionEnd("backdate_" + actorID)
+
+	actorKey, err := stub.CreateCompositeKey(config.ActorPrefix, []string{actorID})
+	require.NoError(t, err)
+	actorBytes, err := stub.GetState(actorKey)
+	require.NoError(t, err)
+
+	var actor services.ActorIdentity
+	require.NoError(t, json.Unmarshal(actorBytes, &actor))
+	actor.LastUpdated = time.Now().Add(-age)
+	actorBytes, err = json.Marshal(actor)
+	require.NoError(t, err)
+	require.NoError(t, stub.PutState(actorKey, actorBytes))
+}
+
+func TestStaleCredentialsBlockHighPrivilegeFunctions(t *testing.T) {
+	stub := newCustomerStub(t)
+	customer := createTestCustomer(t, stub, "ROTATION001")
+	backdateActor(t, stub, "ACTOR_OPS", config.CredentialMaxAge+24*time.Hour)
+
+	suspend := func(txID string) string {
+		updateBytes, err := json.Marshal(domain.CustomerStatusUpdateRequest{
+			CustomerID: customer.CustomerID,
+			NewStatus:  "SUSPENDED",
+			Reason:     "Review",
+			ActorID:    "ACTOR_OPS",
+		})
+		require.NoError(t, err)
+		response := stub.MockInvoke(txID, [][]byte{[]byte("UpdateCustomerStatus"), updateBytes})
+		if response.Status != shim.OK {
+			return response.Message
+		}
+		return ""
+	}
+	attest := func(txID string, req sharedChaincode.CredentialRotationRequest) string {
+		reqBytes, err := json.Marshal(req)
+		require.NoError(t, err)
+		response := stub.MockInvoke(txID, [][]byte{[]byte("AttestCredentialRotation"), reqBytes})
+		if response.Status != shim.OK {
+			return response.Message
+		}
+		return ""
+	}
+
+	// Overdue credentials block high-privilege functions but not ordinary ones
+	assert.Contains(t, suspend("2"), "attest a credential rotation first")
+	response := stub.MockInvoke("3", [][]byte{[]byte("GetCustomer"), []byte(customer.CustomerID)})
+	assert.Equal(t, int32(shim.OK), response.Status, response.Message)
+
+	rotation := sharedChaincode.CredentialRotationRequest{
+		RotatedActorID: "ACTOR_OPS",
+		RotationDate:   time.Now().Add(-time.Hour),
+		Method:         "PASSWORD_RESET",
+		ActorID:        "ACTOR_OPS",
+	}
+	assert.Contains(t, attest("4", rotation), "may not attest their own")
+
+	rotation.ActorID = "ACTOR_001"
+	rotation.Method = "SMOKE_SIGNAL"
+	assert.Contains(t, attest("5", rotation), "invalid method")
+
+	rotation.Method = "PASSWORD_RESET"
+	require.Empty(t, attest("6", rotation))
+
+	// Attestations must move forward in time
+	assert.Contains(t, attest("7", rotation), "must be after the previously attested rotation")
+
+	response = stub.MockInvoke("8", [][]byte{[]byte("GetCredentialRotation"), []byte("ACTOR_OPS")})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	var attested services.CredentialRotation
+	require.NoError(t, json.Unmarshal(response.Payload, &attested))
+	assert.Equal(t, "PASSWORD_RESET", attested.Method)
+	assert.Equal(t, "ACTOR_001", attested.AttestedBy)
+
+	assert.Empty(t, suspend("9"))
+
+	// Only administrators may attest
+	adminIdentity := stub.Creator
+	stub.Creator = newTestIdentity(t, "Org1MSP", "teller", "Customer_Service_Rep")
+	response = stub.MockInvoke("10", [][]byte{[]byte("GetInvokerIdentity")})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	var teller sharedChaincode.InvokerIdentity
+	require.NoError(t, json.Unmarshal(response.Payload, &teller))
+
+	tellerIdentity := stub.Creator
+	stub.Creator = adminIdentity
+	registerBytes, err := json.Marshal(sharedChaincode.ActorRegistrationRequest{
+		RegisteredActorID:  "ACTOR_TELLER",
+		BlockchainIdentity: teller.BlockchainIdentity,
+		MSPID:              teller.MSPID,
+		Role:               "Customer_Service_Rep",
+		Active:             true,
+		ActorID:            "ACTOR_001",
+	})
+	require.NoError(t, err)
+	response = stub.MockInvoke("11", [][]byte{[]byte("RegisterActor"), registerBytes})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+
+	stub.Creator = tellerIdentity
+	rotation.RotationDate = time.Now()
+	rotation.ActorID = "ACTOR_TELLER"
+	assert.Contains(t, attest("12", rotation), "may only be attested by")
+}
@@ -0,0 +1,141 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/interfaces"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+func TestCustomer360ComposesCustomerComplianceAndLoanData(t *testing.T) {
+	stub := newCustomerStub(t)
+	compliance := &fakeComplianceChaincode{
+		events:  map[string][]interfaces.ComplianceEventSummary{},
+		reports: map[string][]map[string]interface{}{},
+	}
+	loan := &fakeLoanChaincode{loans: map[string][]interfaces.CustomerLoanSummary{}}
+	stub.MockPeerChaincode("compliance", shimtest.NewMockStub("compliance", compliance), "")
+	stub.MockPeerChaincode("loan", shimtest.NewMockStub("loan", loan), "")
+
+	customer := registerSearchCustomer(t, stub, "c3601", "Cara", "Whole", "cara@example.com")
+	adminIdentity := stub.Creator
+
+	invoke := func(txID, function string, req interface{}) []byte {
+		reqBytes, err := json.Marshal(req)
+		require.NoError(t, err)
+		response := stub.MockInvoke(txID, [][]byte{[]byte(function), reqBytes})
+		require.Equal(t, int32(shim.OK), response.Status, response.Message)
+		return response.Payload
+	}
+	var kycRecord domain.KYCRecord
+	require.NoError(t, json.Unmarshal(invoke("c360_2", "InitiateKYC", domain.KYCInitiationRequest{
+		CustomerID:     customer.CustomerID,
+		DocumentHashes: []string{"hash1"},
+		ActorID:        "ACTOR_KYC_001",
+	}), &kycRecord))
+	invoke("c360_3", "UpdateKYCStatus", domain.KYCStatusUpdateRequest{KYCID: kycRecord.KYCID, NewStatus: validation.KYCStatusVerified, ActorID: "ACTOR_KYC_001"})
+	var amlRecord domain.AMLRecord
+	require.NoError(t, json.Unmarshal(invoke("c360_4", "InitiateAMLCheck", domain.AMLCheckRequest{CustomerID: customer.CustomerID, ActorID: "ACTOR_AML_001"}), &amlRecord))
+	invoke("c360_5", "RecordConsent", domain.ConsentRequest{CustomerID: customer.CustomerID, Purpose: "credit_bureau", Granted: true, ActorID: "ACTOR_001"})
+
+	eventTime := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	compliance.events[customer.CustomerID] = []interfaces.ComplianceEventSummary{
+		{EventID: "EVENT_OPEN", Timestamp: eventTime, RuleID: "AML_RESCREEN_RULE", EventType: "SANCTIONS_RESCREEN_MATCH", Severity: "HIGH", IsAlerted: true, ResolutionStatus: "OPEN"},
+		{EventID: "EVENT_RESOLVED", Timestamp: eventTime, RuleID: "AML_RESCREEN_RULE", EventType: "SANCTIONS_RESCREEN_MATCH", Severity: "HIGH", IsAlerted: true, ResolutionStatus: "RESOLVED"},
+	}
+	compliance.reports[customer.CustomerID] = []map[string]interface{}{{
+		"checkID":              "AML_CHECK_001",
+		"customerID":           customer.CustomerID,
+		"checkType":            "ONBOARDING",
+		"status":               "FLAGGED",
+		"riskLevel":            "HIGH",
+		"overallRiskScore":     72.5,
+		"checkDate":            eventTime,
+		"expiryDate":           eventTime.AddDate(1, 0, 0),
+		"sanctionScreenResult": map[string]interface{}{"isMatch": true, "matches": []interface{}{map[string]interface{}{"listName": "OFAC_SDN"}}},
+		"pepScreenResult":      map[string]interface{}{"isMatch": false},
+	}}
+	approved := 15000.0
+	loan.loans[customer.CustomerID] = []interfaces.CustomerLoanSummary{
+		{LoanID: "LOAN_001", LoanType: "PERSONAL", Status: "APPROVED", RequestedAmount: 20000, ApprovedAmount: &approved, TermMonths: 36, ApplicationDate: eventTime},
+	}
+
+	view := func(txID string) domain.Customer360 {
+		response := stub.MockInvoke(txID, [][]byte{[]byte("GetCustomer360"), []byte(customer.CustomerID)})
+		require.Equal(t, int32(shim.OK), response.Status, response.Message)
+		var result domain.Customer360
+		require.NoError(t, json.Unmarshal(response.Payload, &result))
+		return result
+	}
+
+	// Compliance officers see every section unredacted
+	stub.Creator = newTestIdentity(t, "Org1MSP", "compliance", "Compliance_Officer")
+	full := view("c360_6")
+	assert.Equal(t, customer.CustomerID, full.CustomerID)
+	assert.Equal(t, customer.NationalID, full.Profile.NationalID)
+	require.Len(t, full.Consents, 2)
+	assert.Equal(t, "credit_bureau", full.Consents[0].Purpose)
+	assert.True(t, full.Consents[0].Granted)
+	assert.Equal(t, "marketing", full.Consents[1].Purpose)
+	require.NotNil(t, full.LatestKYC)
+	assert.Equal(t, validation.KYCStatusVerified, full.LatestKYC.Status)
+	require.NotNil(t, full.LatestAMLCheck)
+	assert.Equal(t, amlRecord.AMLID, full.LatestAMLCheck.AMLID)
+	require.Len(t, full.OpenComplianceEvents, 1)
+	assert.Equal(t, "EVENT_OPEN", full.OpenComplianceEvents[0].EventID)
+	require.Len(t, full.Screenings, 1)
+	assert.Equal(t, interfaces.CustomerScreeningSummary{
+		CheckID:       "AML_CHECK_001",
+		CheckType:     "ONBOARDING",
+		Status:        "FLAGGED",
+		RiskLevel:     "HIGH",
+		RiskScore:     72.5,
+		SanctionMatch: true,
+		CheckDate:     eventTime,
+		ExpiryDate:    eventTime.AddDate(1, 0, 0),
+	}, full.Screenings[0])
+	require.Len(t, full.LoanApplications, 1)
+	assert.Equal(t, 15000.0, *full.LoanApplications[0].ApprovedAmount)
+
+	// Underwriters see screening outcomes and loans but not the alerts under investigation
+	stub.Creator = newTestIdentity(t, "Org1MSP", "underwriter", "Underwriter")
+	lending := view("c360_7")
+	assert.Equal(t, "***********6789", lending.Profile.NationalID)
+	assert.Equal(t, customer.Email, lending.Profile.Email)
+	assert.Len(t, lending.Screenings, 1)
+	assert.NotNil(t, lending.LatestAMLCheck)
+	assert.Len(t, lending.LoanApplications, 1)
+	assert.Empty(t, lending.OpenComplianceEvents)
+
+	// Customer service sees no AML or screening section, which would tip off the customer
+	stub.Creator = newTestIdentity(t, "Org1MSP", "service", "Customer_Service_Rep")
+	service := view("c360_8")
+	assert.NotNil(t, service.LatestKYC)
+	assert.Len(t, service.Consents, 2)
+	assert.Len(t, service.LoanApplications, 1)
+	assert.Nil(t, service.LatestAMLCheck)
+	assert.Empty(t, service.Screenings)
+	assert.Empty(t, service.OpenComplianceEvents)
+
+	// Roles not listed get the summary profile alone
+	stub.Creator = adminIdentity
+	summary := view("c360_9")
+	assert.Equal(t, customer.CustomerID, summary.Profile.CustomerID)
+	assert.Equal(t, "c***@example.com", summary.Profile.Email)
+	assert.Empty(t, summary.Profile.Address)
+	assert.Empty(t, summary.Consents)
+	assert.Nil(t, summary.LatestKYC)
+	assert.Nil(t, summary.LatestAMLCheck)
+	assert.Empty(t, summary.Screenings)
+	assert.Empty(t, summary.LoanApplications)
+
+	response := stub.MockInvoke("c360_10", [][]byte{[]byte("GetCustomer360"), []byte("CUST_MISSING")})
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+}
@@ -6,15 +6,13 @@ import (
 	"time"
 
 	"github.com/hyperledger/fabric-chaincode-go/shim"
-	"github.com/hyperledger/fabric-chaincode-go/shimtest"
 	"github.com/stretchr/testify/assert"
-	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/chaincode"
 	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
 )
 
 func TestCustomerRegistrationFlow(t *testing.T) {
 	// Create a new mock stub
-	stub := shimtest.NewMockStub("customer", &chaincode.CustomerContract{})
+	stub := newCustomerStub(t)
 	
 	// Test customer registration
 	registrationReq := domain.CustomerRegistrationRequest{
@@ -66,7 +64,7 @@ func TestCustomerRegistrationFlow(t *testing.T) {
 }
 
 func TestCustomerUpdateFlow(t *testing.T) {
-	stub := shimtest.NewMockStub("customer", &chaincode.CustomerContract{})
+	stub := newCustomerStub(t)
 	
 	// First register a customer
 	registrationReq := domain.CustomerRegistrationRequest{
@@ -125,7 +123,7 @@ func TestCustomerUpdateFlow(t *testing.T) {
 }
 
 func TestCustomerValidation(t *testing.T) {
-	stub := shimtest.NewMockStub("customer", &chaincode.CustomerContract{})
+	stub := newCustomerStub(t)
 	
 	// Test registration with invalid email
 	registrationReq := domain.CustomerRegistrationRequest{
@@ -154,7 +152,7 @@ func TestCustomerValidation(t *testing.T) {
 }
 
 func TestDuplicateCustomerRegistration(t *testing.T) {
-	stub := shimtest.NewMockStub("customer", &chaincode.CustomerContract{})
+	stub := newCustomerStub(t)
 	
 	registrationReq := domain.CustomerRegistrationRequest{
 		FirstName:          "Duplicate",
@@ -185,4 +183,72 @@ func TestDuplicateCustomerRegistration(t *testing.T) {
 	})
 	assert.Equal(t, int32(shim.ERROR), response2.Status)
 	assert.Contains(t, response2.Message, "already exists")
-}
\ No newline at end of file
+}
+func TestFunctionKillSwitch(t *testing.T) {
+	stub := newCustomerStub(t)
+	
+	restoration := time.Date(2030, 1, 1, 12, 0, 0, 0, time.UTC)
+	flagReq := map[string]interface{}{
+		"functionName":        "RegisterCustomer",
+		"disabled":            true,
+		"operatorMessage":     "Onboarding paused during KYC provider outage",
+		"expectedRestoration": restoration,
+		"actorID":             "ACTOR_OPS",
+	}
+	flagBytes, err := json.Marshal(flagReq)
+	assert.NoError(t, err)
+	
+	// Disable RegisterCustomer
+	response := stub.MockInvoke("1", [][]byte{
+		[]byte("SetFunctionFlag"),
+		flagBytes,
+	})
+	assert.Equal(t, int32(shim.OK), response.Status)
+	
+	registrationReq := domain.CustomerRegistrationRequest{
+		FirstName:          "Paused",
+		LastName:           "Customer",
+		Email:              "paused@example.com",
+		Phone:              "+1234567890",
+		DateOfBirth:        time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC),
+		NationalID:         "PAUSED123",
+		Address:            "123 Main Street, City, Country",
+		ConsentPreferences: `{"marketing": true}`,
+		ActorID:            "ACTOR_005",
+	}
+	reqBytes, err := json.Marshal(registrationReq)
+	assert.NoError(t, err)
+	
+	// Disabled function returns a structured SERVICE_DISABLED error
+	response = stub.MockInvoke("2", [][]byte{
+		[]byte("RegisterCustomer"),
+		reqBytes,
+	})
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	
+	var disabledErr map[string]interface{}
+	err = json.Unmarshal([]byte(response.Message), &disabledErr)
+	assert.NoError(t, err)
+	assert.Equal(t, "SERVICE_DISABLED", disabledErr["code"])
+	assert.Equal(t, "function RegisterCustomer is disabled", disabledErr["message"])
+	assert.Equal(t, true, disabledErr["retryable"])
+	assert.Equal(t, "RegisterCustomer", disabledErr["functionName"])
+	assert.Equal(t, "Onboarding paused during KYC provider outage", disabledErr["operatorMessage"])
+	assert.NotEmpty(t, disabledErr["expectedRestoration"])
+	
+	// Restore the function
+	flagReq["disabled"] = false
+	flagBytes, err = json.Marshal(flagReq)
+	assert.NoError(t, err)
+	response = stub.MockInvoke("3", [][]byte{
+		[]byte("SetFunctionFlag"),
+		flagBytes,
+	})
+	assert.Equal(t, int32(shim.OK), response.Status)
+	
+	response = stub.MockInvoke("4", [][]byte{
+		[]byte("RegisterCustomer"),
+		reqBytes,
+	})
+	assert.Equal(t, int32(shim.OK), response.Status)
+}
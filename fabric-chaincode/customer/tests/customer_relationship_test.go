@@ -0,0 +1,234 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/interfaces"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+func createRelationship(stub *shimtest.MockStub, txID, customerID, relatedCustomerID string, relationshipType domain.RelationshipType) peer.Response {
+	reqBytes, _ := json.Marshal(domain.RelationshipRequest{
+		CustomerID:        customerID,
+		RelatedCustomerID: relatedCustomerID,
+		RelationshipType:  relationshipType,
+		ActorID:           "ACTOR_TEST",
+	})
+	return stub.MockInvoke(txID, [][]byte{[]byte("CreateRelationship"), reqBytes})
+}
+
+func getRelatedParties(t *testing.T, stub *shimtest.MockStub, txID string, args ...string) domain.RelatedPartiesResult {
+	invokeArgs := [][]byte{[]byte("GetRelatedParties")}
+	for _, arg := range args {
+		invokeArgs = append(invokeArgs, []byte(arg))
+	}
+	response := stub.MockInvoke(txID, invokeArgs)
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+
+	var result domain.RelatedPartiesResult
+	require.NoError(t, json.Unmarshal(response.Payload, &result))
+	return result
+}
+
+func TestCustomerRelationshipLifecycle(t *testing.T) {
+	clock := services.NewFixedClock(time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC))
+	defer services.SetClock(clock)()
+	stub := newCustomerStub(t)
+
+	alice := registerIndividual(t, stub, "rel_ind_1", "Alice", "Joint", "RELTEST001")
+	bob := registerIndividual(t, stub, "rel_ind_2", "Bob", "Joint", "RELTEST002")
+
+	for len(stub.ChaincodeEventsChannel) > 0 {
+		<-stub.ChaincodeEventsChannel
+	}
+	response := createRelationship(stub, "rel_1", alice.CustomerID, bob.CustomerID, domain.RelationshipJointAccountHolder)
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	var relationship domain.CustomerRelationship
+	require.NoError(t, json.Unmarshal(response.Payload, &relationship))
+	assert.Equal(t, domain.RelationshipBidirectional, relationship.Direction)
+	assert.Equal(t, domain.RelationshipStatusActive, relationship.Status)
+	assert.True(t, relationship.EffectiveFrom.Equal(time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)))
+	assert.Nil(t, relationship.EffectiveTo)
+	require.Greater(t, len(stub.ChaincodeEventsChannel), 0)
+	assert.Equal(t, config.EventRelationshipCreated, (<-stub.ChaincodeEventsChannel).EventName)
+
+	// A bidirectional relationship is the same whichever customer it is recorded from
+	response = createRelationship(stub, "rel_2", bob.CustomerID, alice.CustomerID, domain.RelationshipJointAccountHolder)
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	assert.Contains(t, response.Message, "already related")
+
+	response = createRelationship(stub, "rel_3", alice.CustomerID, alice.CustomerID, domain.RelationshipSpouse)
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	assert.Contains(t, response.Message, "cannot be related to themselves")
+
+	response = createRelationship(stub, "rel_4", alice.CustomerID, "CUST_MISSING", domain.RelationshipSpouse)
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	assert.Contains(t, response.Message, "not found")
+
+	// Both customers list the relationship
+	for i, customerID := range []string{alice.CustomerID, bob.CustomerID} {
+		response = stub.MockInvoke("rel_list_"+string(rune('a'+i)), [][]byte{[]byte("GetCustomerRelationships"), []byte(customerID)})
+		require.Equal(t, int32(shim.OK), response.Status, response.Message)
+		var result domain.CustomerRelationshipsResult
+		require.NoError(t, json.Unmarshal(response.Payload, &result))
+		require.Equal(t, 1, result.Count)
+		assert.Equal(t, relationship.RelationshipID, result.Relationships[0].RelationshipID)
+	}
+
+	// Termination keeps the relationship with the date it ended
+	clock.Advance(24 * time.Hour)
+	terminateBytes, _ := json.Marshal(domain.RelationshipTerminationRequest{RelationshipID: relationship.RelationshipID, Reason: "Joint account closed", ActorID: "ACTOR_TEST"})
+	response = stub.MockInvoke("rel_5", [][]byte{[]byte("TerminateRelationship"), terminateBytes})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	var terminated domain.CustomerRelationship
+	require.NoError(t, json.Unmarshal(response.Payload, &terminated))
+	assert.Equal(t, domain.RelationshipStatusTerminated, terminated.Status)
+	require.NotNil(t, terminated.EffectiveTo)
+	assert.True(t, terminated.EffectiveTo.Equal(time.Date(2026, 6, 2, 9, 0, 0, 0, time.UTC)))
+	assert.Equal(t, "Joint account closed", terminated.TerminationReason)
+
+	response = stub.MockInvoke("rel_6", [][]byte{[]byte("TerminateRelationship"), terminateBytes})
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	assert.Contains(t, response.Message, "already terminated")
+
+	// The same customers may be related again once the earlier relationship has ended
+	response = createRelationship(stub, "rel_7", bob.CustomerID, alice.CustomerID, domain.RelationshipJointAccountHolder)
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+
+	// Both customers' histories record the relationship
+	response = stub.MockInvoke("rel_8", [][]byte{[]byte("GetCustomerHistory"), []byte(bob.CustomerID)})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	assert.Contains(t, string(response.Payload), relationship.RelationshipID)
+}
+
+func TestGetRelatedPartiesTraversesRelationshipsInEffect(t *testing.T) {
+	clock := services.NewFixedClock(time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC))
+	defer services.SetClock(clock)()
+	stub := newCustomerStub(t)
+
+	// A household of three, the last of whom holds power of attorney for an elderly parent
+	alice := registerIndividual(t, stub, "rel_ind_1", "Alice", "House", "RELTEST011")
+	bob := registerIndividual(t, stub, "rel_ind_2", "Bob", "House", "RELTEST012")
+	carol := registerIndividual(t, stub, "rel_ind_3", "Carol", "House", "RELTEST013")
+	dora := registerIndividual(t, stub, "rel_ind_4", "Dora", "Elder", "RELTEST014")
+
+	var relationshipIDs []string
+	for i, link := range []struct {
+		customer, related domain.Customer
+		relationshipType  domain.RelationshipType
+	}{
+		{alice, bob, domain.RelationshipSpouse},
+		{bob, carol, domain.RelationshipHouseholdMember},
+		{carol, dora, domain.RelationshipPowerOfAttorney},
+	} {
+		response := createRelationship(stub, "rel_link_"+string(rune('a'+i)), link.customer.CustomerID, link.related.CustomerID, link.relationshipType)
+		require.Equal(t, int32(shim.OK), response.Status, response.Message)
+		var relationship domain.CustomerRelationship
+		require.NoError(t, json.Unmarshal(response.Payload, &relationship))
+		relationshipIDs = append(relationshipIDs, relationship.RelationshipID)
+	}
+
+	// Two hops by default
+	result := getRelatedParties(t, stub, "rel_q_1", alice.CustomerID)
+	assert.Equal(t, config.DefaultRelationshipDepth, result.MaxDepth)
+	require.Equal(t, 2, result.Count)
+	assert.Equal(t, bob.CustomerID, result.Parties[0].CustomerID)
+	assert.Equal(t, 1, result.Parties[0].Depth)
+	assert.Equal(t, carol.CustomerID, result.Parties[1].CustomerID)
+	assert.Equal(t, 2, result.Parties[1].Depth)
+	assert.Equal(t, relationshipIDs[:2], result.Parties[1].RelationshipIDs)
+	assert.Equal(t, []domain.RelationshipType{domain.RelationshipSpouse, domain.RelationshipHouseholdMember}, result.Parties[1].RelationshipTypes)
+
+	// Directed relationships are followed from either end
+	result = getRelatedParties(t, stub, "rel_q_2", dora.CustomerID, "3")
+	require.Equal(t, 3, result.Count)
+	assert.Equal(t, carol.CustomerID, result.Parties[0].CustomerID)
+	assert.Equal(t, alice.CustomerID, result.Parties[2].CustomerID)
+	assert.Equal(t, 3, result.Parties[2].Depth)
+
+	response := stub.MockInvoke("rel_q_3", [][]byte{[]byte("GetRelatedParties"), []byte(alice.CustomerID), []byte("4")})
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	assert.Contains(t, response.Message, "invalid maxDepth")
+
+	// Once Bob leaves the household, Carol is no longer reached, but an as-of date before his
+	// departure still finds her
+	clock.Advance(48 * time.Hour)
+	terminateBytes, _ := json.Marshal(domain.RelationshipTerminationRequest{RelationshipID: relationshipIDs[1], Reason: "Moved out", ActorID: "ACTOR_TEST"})
+	response = stub.MockInvoke("rel_end", [][]byte{[]byte("TerminateRelationship"), terminateBytes})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+
+	result = getRelatedParties(t, stub, "rel_q_4", alice.CustomerID, "3")
+	require.Equal(t, 1, result.Count)
+	assert.Equal(t, bob.CustomerID, result.Parties[0].CustomerID)
+
+	result = getRelatedParties(t, stub, "rel_q_5", alice.CustomerID, "3", "2026-06-02")
+	assert.Equal(t, 3, result.Count)
+}
+
+func TestFlaggedRelatedPartyRaisesRiskRating(t *testing.T) {
+	stub := newCustomerStub(t)
+	stub.MockPeerChaincode("loan", shimtest.NewMockStub("loan", &fakeLoanChaincode{holdings: map[string]interfaces.CustomerHoldings{}}), "")
+	stub.MockPeerChaincode("compliance", shimtest.NewMockStub("compliance", &fakeComplianceChaincode{}), "")
+
+	alice := registerIndividual(t, stub, "rel_ind_1", "Alice", "Account", "RELTEST021")
+	bob := registerIndividual(t, stub, "rel_ind_2", "Bob", "Account", "RELTEST022")
+	response := createRelationship(stub, "rel_1", alice.CustomerID, bob.CustomerID, domain.RelationshipJointAccountHolder)
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+
+	recalculate := func(txID, customerID string) domain.CustomerRiskProfile {
+		reqBytes, _ := json.Marshal(domain.RiskRecalculationRequest{CustomerID: customerID, ActorID: "ACTOR_TEST"})
+		response := stub.MockInvoke(txID, [][]byte{[]byte("RecalculateCustomerRisk"), reqBytes})
+		require.Equal(t, int32(shim.OK), response.Status, response.Message)
+		var profile domain.CustomerRiskProfile
+		require.NoError(t, json.Unmarshal(response.Payload, &profile))
+		return profile
+	}
+	codes := func(profile domain.CustomerRiskProfile) []string {
+		result := []string{}
+		for _, driver := range profile.Drivers {
+			result = append(result, driver.Code)
+		}
+		return result
+	}
+
+	assert.NotContains(t, codes(recalculate("rel_risk_1", alice.CustomerID)), "FLAGGED_RELATED_PARTY")
+
+	// Bob's AML check is flagged
+	amlBytes, _ := json.Marshal(domain.AMLCheckRequest{CustomerID: bob.CustomerID, ActorID: "ACTOR_TEST"})
+	response = stub.MockInvoke("rel_aml_1", [][]byte{[]byte("InitiateAMLCheck"), amlBytes})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	var amlRecord domain.AMLRecord
+	require.NoError(t, json.Unmarshal(response.Payload, &amlRecord))
+	statusBytes, _ := json.Marshal(domain.AMLStatusUpdateRequest{AMLID: amlRecord.AMLID, NewStatus: validation.AMLStatusFlagged, RiskScore: 70, Notes: "Adverse media", ActorID: "ACTOR_TEST"})
+	response = stub.MockInvoke("rel_aml_2", [][]byte{[]byte("UpdateAMLStatus"), statusBytes})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+
+	// Investigators see Bob's standing among Alice's related parties
+	result := getRelatedParties(t, stub, "rel_q_1", alice.CustomerID, "1")
+	require.Equal(t, 1, result.Count)
+	assert.Equal(t, validation.AMLStatusFlagged, result.Parties[0].AMLStatus)
+
+	profile := recalculate("rel_risk_2", alice.CustomerID)
+	assert.Contains(t, codes(profile), "FLAGGED_RELATED_PARTY")
+	for _, driver := range profile.Drivers {
+		if driver.Code == "FLAGGED_RELATED_PARTY" {
+			assert.Equal(t, "RELATIONSHIPS", driver.Factor)
+			assert.Contains(t, driver.Detail, bob.CustomerID)
+		}
+	}
+
+	// The risk profile is carried back into the related-party view
+	recalculate("rel_risk_3", bob.CustomerID)
+	result = getRelatedParties(t, stub, "rel_q_2", alice.CustomerID, "1")
+	assert.NotEmpty(t, result.Parties[0].RiskTier)
+}
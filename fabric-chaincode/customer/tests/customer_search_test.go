@@ -0,0 +1,171 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+)
+
+func registerSearchCustomer(t *testing.T, stub *shimtest.MockStub, txID, firstName, lastName, email string) domain.Customer {
+	reqBytes, err := json.Marshal(domain.CustomerRegistrationRequest{
+		FirstName:          firstName,
+		LastName:           lastName,
+		Email:              email,
+		Phone:              "+1234567890",
+		DateOfBirth:        time.Date(1990, 1, 1, 0, 0, 0, 0, time.UTC),
+		NationalID:         "ID" + txID + "23456789",
+		Address:            "123 Main Street, City, Country",
+		ConsentPreferences: `{"marketing": true}`,
+		ActorID:            "ACTOR_001",
+	})
+	require.NoError(t, err)
+
+	response := stub.MockInvoke(txID, [][]byte{[]byte("RegisterCustomer"), reqBytes})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+
+	var customer domain.Customer
+	require.NoError(t, json.Unmarshal(response.Payload, &customer))
+	return customer
+}
+
+func queryCustomerPage(t *testing.T, stub *shimtest.MockStub, txID string, args ...string) domain.CustomerQueryResult {
+	invokeArgs := [][]byte{}
+	for _, arg := range args {
+		invokeArgs = append(invokeArgs, []byte(arg))
+	}
+
+	response := stub.MockInvoke(txID, invokeArgs)
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+
+	var result domain.CustomerQueryResult
+	require.NoError(t, json.Unmarshal(response.Payload, &result))
+	return result
+}
+
+func TestSearchCustomersByNamePagination(t *testing.T) {
+	stub := newCustomerStub(t)
+
+	for i := 1; i <= 3; i++ {
+		registerSearchCustomer(t, stub, fmt.Sprintf("%d", i), "Ann", "Doe", fmt.Sprintf("ann%d@example.com", i))
+	}
+	registerSearchCustomer(t, stub, "4", "Bob", "Smith", "bob@example.com")
+
+	firstPage := queryCustomerPage(t, stub, "5", "SearchCustomersByName", "  doe ", "2")
+	assert.Equal(t, 2, firstPage.Count)
+	assert.NotEmpty(t, firstPage.Bookmark)
+
+	secondPage := queryCustomerPage(t, stub, "6", "SearchCustomersByName", "DOE", "2", firstPage.Bookmark)
+	assert.Equal(t, 1, secondPage.Count)
+	assert.Empty(t, secondPage.Bookmark)
+
+	seen := map[string]bool{}
+	for _, customer := range append(firstPage.Customers, secondPage.Customers...) {
+		assert.Equal(t, "Doe", customer.LastName)
+		seen[customer.CustomerID] = true
+	}
+	assert.Len(t, seen, 3)
+}
+
+func TestSearchCustomersRedactsForUnknownRole(t *testing.T) {
+	stub := newCustomerStub(t)
+	customer := registerSearchCustomer(t, stub, "1", "Ann", "Doe", "Ann.Doe@Example.com")
+
+	// Email search is case-insensitive, and the test administrator role only gets the summary view
+	result := queryCustomerPage(t, stub, "2", "SearchCustomersByEmail", "ann.doe@example.com")
+	require.Equal(t, 1, result.Count)
+
+	found := result.Customers[0]
+	assert.Equal(t, customer.CustomerID, found.CustomerID)
+	assert.Equal(t, "A******@Example.com", found.Email)
+	assert.Equal(t, "*******6789", found.NationalID)
+	assert.Equal(t, "*******7890", found.Phone)
+	assert.Empty(t, found.Address)
+	assert.True(t, found.DateOfBirth.IsZero())
+}
+
+func TestSearchCustomersMasksByRole(t *testing.T) {
+	stub := newCustomerStub(t)
+	customer := registerSearchCustomer(t, stub, "1", "Ann", "Doe", "ann@example.com")
+
+	// Underwriters see contact details with the national ID masked
+	stub.Creator = newTestIdentity(t, "Org1MSP", "underwriter", "Underwriter")
+	result := queryCustomerPage(t, stub, "2", "SearchCustomersByName", "Doe")
+	require.Equal(t, 1, result.Count)
+	assert.Equal(t, "ann@example.com", result.Customers[0].Email)
+	assert.Equal(t, customer.Address, result.Customers[0].Address)
+	assert.Equal(t, "*******6789", result.Customers[0].NationalID)
+
+	// Compliance officers see every field
+	stub.Creator = newTestIdentity(t, "Org1MSP", "compliance", "Compliance_Officer")
+	result = queryCustomerPage(t, stub, "3", "SearchCustomersByName", "Doe")
+	require.Equal(t, 1, result.Count)
+	assert.Equal(t, customer.NationalID, result.Customers[0].NationalID)
+	assert.Equal(t, customer.ConsentPreferences, result.Customers[0].ConsentPreferences)
+}
+
+func TestQueryCustomersByStatusFollowsStatusChanges(t *testing.T) {
+	stub := newCustomerStub(t)
+	customer := registerSearchCustomer(t, stub, "1", "Ann", "Doe", "ann@example.com")
+	registerSearchCustomer(t, stub, "2", "Bob", "Smith", "bob@example.com")
+
+	assert.Equal(t, 2, queryCustomerPage(t, stub, "3", "QueryCustomersByStatus", "ACTIVE").Count)
+
+	updateBytes, err := json.Marshal(domain.CustomerStatusUpdateRequest{
+		CustomerID: customer.CustomerID,
+		NewStatus:  "SUSPENDED",
+		Reason:     "Review",
+		ActorID:    "ACTOR_001",
+	})
+	require.NoError(t, err)
+	response := stub.MockInvoke("4", [][]byte{[]byte("UpdateCustomerStatus"), updateBytes})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+
+	assert.Equal(t, 1, queryCustomerPage(t, stub, "5", "QueryCustomersByStatus", "ACTIVE").Count)
+	suspended := queryCustomerPage(t, stub, "6", "QueryCustomersByStatus", "SUSPENDED")
+	require.Equal(t, 1, suspended.Count)
+	assert.Equal(t, customer.CustomerID, suspended.Customers[0].CustomerID)
+
+	response = stub.MockInvoke("7", [][]byte{[]byte("QueryCustomersByStatus"), []byte("UNKNOWN")})
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+}
+
+func TestQueryCustomersByKYCStatus(t *testing.T) {
+	stub := newCustomerStub(t)
+	customer := registerSearchCustomer(t, stub, "1", "Ann", "Doe", "ann@example.com")
+	registerSearchCustomer(t, stub, "2", "Bob", "Smith", "bob@example.com")
+
+	kycBytes, err := json.Marshal(domain.KYCInitiationRequest{
+		CustomerID:     customer.CustomerID,
+		DocumentHashes: []string{"hash1"},
+		ActorID:        "ACTOR_001",
+	})
+	require.NoError(t, err)
+	response := stub.MockInvoke("3", [][]byte{[]byte("InitiateKYC"), kycBytes})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+
+	pending := queryCustomerPage(t, stub, "4", "QueryCustomersByKYCStatus", "PENDING")
+	require.Equal(t, 1, pending.Count)
+	assert.Equal(t, customer.CustomerID, pending.Customers[0].CustomerID)
+
+	var kycRecord domain.KYCRecord
+	require.NoError(t, json.Unmarshal(response.Payload, &kycRecord))
+	updateBytes, err := json.Marshal(domain.KYCStatusUpdateRequest{
+		KYCID:             kycRecord.KYCID,
+		NewStatus:         "VERIFIED",
+		VerificationNotes: "Documents verified",
+		ActorID:           "ACTOR_001",
+	})
+	require.NoError(t, err)
+	response = stub.MockInvoke("5", [][]byte{[]byte("UpdateKYCStatus"), updateBytes})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+
+	assert.Equal(t, 0, queryCustomerPage(t, stub, "6", "QueryCustomersByKYCStatus", "PENDING").Count)
+	assert.Equal(t, 1, queryCustomerPage(t, stub, "7", "QueryCustomersByKYCStatus", "VERIFIED").Count)
+}
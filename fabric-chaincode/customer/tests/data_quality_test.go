@@ -0,0 +1,124 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sharedChaincode "github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/chaincode"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+func TestDataQualitySweepScoresNamespaceAndRaisesTasks(t *testing.T) {
+	clock := services.NewFixedClock(time.Date(2026, 6, 1, 2, 0, 0, 0, time.UTC))
+	defer services.SetClock(clock)()
+	stub := newCustomerStub(t)
+
+	registerSearchCustomer(t, stub, "dq1", "Clean", "Record", "clean@example.com")
+	broken := registerSearchCustomer(t, stub, "dq2", "Broken", "Record", "broken@example.com")
+
+	// Corrupt the second customer's consent preferences behind the chaincode's back
+	broken.ConsentPreferences = "{not json"
+	brokenBytes, err := json.Marshal(broken)
+	require.NoError(t, err)
+	stub.MockTransactionStart("corrupt_1")
+	require.NoError(t, stub.PutState("CUSTOMER_"+broken.CustomerID, brokenBytes))
+	stub.MockTransactionEnd("corrupt_1")
+
+	// One record per batch until the sweep completes
+	var report services.DataQualityReport
+	for i := 0; i < 10 && report.Status != services.DataQualitySweepCompleted; i++ {
+		report = runDataQualitySweep(t, stub, fmt.Sprintf("dq_sweep_%d", i), 1)
+	}
+	require.Equal(t, services.DataQualitySweepCompleted, report.Status)
+	assert.Equal(t, "2026-06-01", report.SweepDate)
+	assert.Equal(t, 2, report.RecordsChecked)
+	assert.Equal(t, 1, report.RecordsFailed)
+	assert.Equal(t, float64(50), report.Score)
+	assert.Equal(t, 1, report.TasksRaised)
+	assert.Greater(t, report.Batches, 1)
+	require.Len(t, stub.ChaincodeEventsChannel, 1)
+	assert.Equal(t, config.EventDataQualitySweepCompleted, (<-stub.ChaincodeEventsChannel).EventName)
+
+	// The day's sweep runs once
+	reqBytes, err := json.Marshal(sharedChaincode.DataQualitySweepRequest{Namespace: "CUSTOMER_", ActorID: "ACTOR_001"})
+	require.NoError(t, err)
+	response := stub.MockInvoke("dq_again", [][]byte{[]byte("RunDataQualitySweep"), reqBytes})
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	assert.Contains(t, response.Message, "already completed")
+
+	response = stub.MockInvoke("dq_report", [][]byte{[]byte("GetDataQualityReport"), []byte("CUSTOMER_"), []byte("2026-06-01")})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+
+	tasks := getRemediationTasks(t, stub, "dq_tasks_1")
+	require.Len(t, tasks, 1)
+	task := tasks[0]
+	assert.Equal(t, broken.CustomerID, task.EntityID)
+	assert.Equal(t, "consentPreferences", task.Rule)
+	assert.Equal(t, services.RemediationTaskOpen, task.Status)
+
+	// Resolving needs notes
+	resolution := sharedChaincode.RemediationTaskResolution{Namespace: "CUSTOMER_", TaskID: task.TaskID, ActorID: "ACTOR_001"}
+	reqBytes, err = json.Marshal(resolution)
+	require.NoError(t, err)
+	response = stub.MockInvoke("dq_resolve_1", [][]byte{[]byte("ResolveRemediationTask"), reqBytes})
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	assert.Contains(t, response.Message, "notes are required")
+
+	resolution.Notes = "Consent re-captured from the signed form"
+	reqBytes, err = json.Marshal(resolution)
+	require.NoError(t, err)
+	response = stub.MockInvoke("dq_resolve_2", [][]byte{[]byte("ResolveRemediationTask"), reqBytes})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	tasks = getRemediationTasks(t, stub, "dq_tasks_2")
+	require.Len(t, tasks, 1)
+	assert.Equal(t, services.RemediationTaskResolved, tasks[0].Status)
+
+	// The record was never fixed, so the next day's sweep reopens the task
+	clock.Advance(24 * time.Hour)
+	report = runDataQualitySweep(t, stub, "dq_next_day", 0)
+	require.Equal(t, services.DataQualitySweepCompleted, report.Status)
+	assert.Equal(t, 1, report.RecordsFailed)
+	assert.Equal(t, 1, report.TasksRaised)
+	tasks = getRemediationTasks(t, stub, "dq_tasks_3")
+	require.Len(t, tasks, 1)
+	assert.Equal(t, services.RemediationTaskOpen, tasks[0].Status)
+	assert.Equal(t, "2026-06-02", tasks[0].LastSweepDate)
+}
+
+func TestDataQualitySweepRejectsNamespaceWithoutRules(t *testing.T) {
+	stub := newCustomerStub(t)
+
+	reqBytes, err := json.Marshal(sharedChaincode.DataQualitySweepRequest{Namespace: "KYC_", ActorID: "ACTOR_001"})
+	require.NoError(t, err)
+	response := stub.MockInvoke("dq_unknown", [][]byte{[]byte("RunDataQualitySweep"), reqBytes})
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	assert.Contains(t, response.Message, "has no data quality rules")
+}
+
+func runDataQualitySweep(t *testing.T, stub *shimtest.MockStub, txID string, batchSize int) services.DataQualityReport {
+	for len(stub.ChaincodeEventsChannel) > 0 {
+		<-stub.ChaincodeEventsChannel
+	}
+	reqBytes, err := json.Marshal(sharedChaincode.DataQualitySweepRequest{Namespace: "CUSTOMER_", BatchSize: batchSize, ActorID: "ACTOR_001"})
+	require.NoError(t, err)
+	response := stub.MockInvoke(txID, [][]byte{[]byte("RunDataQualitySweep"), reqBytes})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	var report services.DataQualityReport
+	require.NoError(t, json.Unmarshal(response.Payload, &report))
+	return report
+}
+
+func getRemediationTasks(t *testing.T, stub *shimtest.MockStub, txID string) []services.RemediationTask {
+	response := stub.MockInvoke(txID, [][]byte{[]byte("GetRemediationTasks"), []byte("CUSTOMER_")})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	var result sharedChaincode.RemediationTaskResult
+	require.NoError(t, json.Unmarshal(response.Payload, &result))
+	return result.Tasks
+}
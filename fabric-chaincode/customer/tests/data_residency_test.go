@@ -0,0 +1,175 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+)
+
+// invokeWithTransientPII registers a customer whose personal data is passed in transient data
+func invokeWithTransientPII(t *testing.T, stub *shimtest.MockStub, txID string, req domain.CustomerRegistrationRequest, pii domain.CustomerPII) peer.Response {
+	reqBytes, err := json.Marshal(req)
+	require.NoError(t, err)
+	piiBytes, err := json.Marshal(pii)
+	require.NoError(t, err)
+
+	stub.TransientMap = map[string][]byte{config.CustomerPIITransientKey: piiBytes}
+	defer func() { stub.TransientMap = nil }()
+	return stub.MockInvoke(txID, [][]byte{[]byte("RegisterCustomer"), reqBytes})
+}
+
+func registerResidentCustomer(t *testing.T, stub *shimtest.MockStub, txID, nationalID, residency string) domain.Customer {
+	response := invokeWithTransientPII(t, stub, txID, domain.CustomerRegistrationRequest{
+		ConsentPreferences: `{"marketing": false}`,
+		Residency:          residency,
+		ActorID:            "ACTOR_TEST",
+	}, domain.CustomerPII{
+		FirstName:   "Resident",
+		LastName:    "Customer",
+		Email:       "resident@example.com",
+		Phone:       "+1234567890",
+		DateOfBirth: time.Date(1988, 3, 4, 0, 0, 0, 0, time.UTC),
+		NationalID:  nationalID,
+		Address:     "1 Residency Road, Test City, Test Country",
+	})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+
+	// The response is recorded with the transaction, so it carries no PII either
+	var customer domain.Customer
+	require.NoError(t, json.Unmarshal(response.Payload, &customer))
+	assert.Equal(t, residency, customer.Residency)
+	assert.Empty(t, customer.FirstName)
+	assert.Empty(t, customer.NationalID)
+	return customer
+}
+
+func TestResidentCustomerPIIKeptInResidencyCollection(t *testing.T) {
+	stub := newCustomerStub(t)
+	customer := registerResidentCustomer(t, stub, "residency_1", "RESIDENT001", "US")
+
+	// The public record carries no PII
+	var public domain.Customer
+	require.NoError(t, json.Unmarshal(stub.State["CUSTOMER_"+customer.CustomerID], &public))
+	assert.Equal(t, "US", public.Residency)
+	assert.Empty(t, public.FirstName)
+	assert.Empty(t, public.NationalID)
+	assert.Nil(t, stub.State["CUSTOMER_BY_NATIONAL_ID_RESIDENT001"])
+
+	var pii domain.CustomerPII
+	require.NoError(t, json.Unmarshal(stub.PvtState[config.ResidencyCollections["US"]]["CUSTOMER_PII_"+customer.CustomerID], &pii))
+	assert.Equal(t, "Resident", pii.FirstName)
+	assert.Equal(t, "RESIDENT001", pii.NationalID)
+
+	// Readers within the residency see the full customer
+	response := stub.MockInvoke("residency_2", [][]byte{[]byte("GetCustomer"), []byte(customer.CustomerID)})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	var read domain.Customer
+	require.NoError(t, json.Unmarshal(response.Payload, &read))
+	assert.Equal(t, "Resident", read.FirstName)
+	assert.Equal(t, "RESIDENT001", read.NationalID)
+
+	// The hashed national ID index still rejects duplicates
+	response = invokeWithTransientPII(t, stub, "residency_3", domain.CustomerRegistrationRequest{
+		Residency: "US",
+		ActorID:   "ACTOR_TEST",
+	}, domain.CustomerPII{
+		FirstName:   "Duplicate",
+		LastName:    "Customer",
+		Email:       "duplicate@example.com",
+		DateOfBirth: time.Date(1988, 3, 4, 0, 0, 0, 0, time.UTC),
+		NationalID:  "RESIDENT001",
+		Address:     "2 Residency Road, Test City, Test Country",
+	})
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	assert.Contains(t, response.Message, "already exists")
+}
+
+func TestResidentCustomerPIIRejectedInArguments(t *testing.T) {
+	stub := newCustomerStub(t)
+
+	request := domain.CustomerRegistrationRequest{
+		FirstName:   "Argument",
+		LastName:    "Customer",
+		Email:       "argument@example.com",
+		DateOfBirth: time.Date(1988, 3, 4, 0, 0, 0, 0, time.UTC),
+		NationalID:  "RESIDENT004",
+		Address:     "4 Residency Road, Test City, Test Country",
+		Residency:   "US",
+		ActorID:     "ACTOR_TEST",
+	}
+	reqBytes, err := json.Marshal(request)
+	require.NoError(t, err)
+	response := stub.MockInvoke("residency_1", [][]byte{[]byte("RegisterCustomer"), reqBytes})
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	assert.Contains(t, response.Message, "must be passed in transient data")
+
+	// Personal data given in both places is ambiguous
+	response = invokeWithTransientPII(t, stub, "residency_2", request, domain.CustomerPII{FirstName: "Transient"})
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	assert.Contains(t, response.Message, "not both")
+}
+
+func TestCrossResidencyReadsRequireLegalBasis(t *testing.T) {
+	stub := newCustomerStub(t)
+	customer := registerResidentCustomer(t, stub, "residency_1", "RESIDENT002", "GB")
+
+	response := stub.MockInvoke("residency_2", [][]byte{[]byte("GetCustomer"), []byte(customer.CustomerID)})
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	assert.Contains(t, response.Message, "documented legal basis")
+
+	accessRequest := func(legalBasis string) []byte {
+		reqBytes, err := json.Marshal(domain.CrossResidencyAccessRequest{
+			CustomerID:    customer.CustomerID,
+			LegalBasis:    legalBasis,
+			Justification: "Supervisory request 2024-17",
+			ActorID:       "ACTOR_001",
+		})
+		require.NoError(t, err)
+		return reqBytes
+	}
+
+	response = stub.MockInvoke("residency_3", [][]byte{[]byte("GetCustomerCrossResidency"), accessRequest("CURIOSITY")})
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	assert.Contains(t, response.Message, "not accepted")
+
+	response = stub.MockInvoke("residency_4", [][]byte{[]byte("GetCustomerCrossResidency"), accessRequest(config.LegalBasisRegulatoryRequest)})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	var read domain.Customer
+	require.NoError(t, json.Unmarshal(response.Payload, &read))
+	assert.Equal(t, "RESIDENT002", read.NationalID)
+
+	accessKey, err := stub.CreateCompositeKey("CROSS_RESIDENCY_ACCESS", []string{customer.CustomerID, "residency_4"})
+	require.NoError(t, err)
+	var access domain.CrossResidencyAccess
+	require.NoError(t, json.Unmarshal(stub.State[accessKey], &access))
+	assert.Equal(t, "GB", access.Residency)
+	assert.Equal(t, "Org1MSP", access.ReaderMSPID)
+	assert.Equal(t, config.LegalBasisRegulatoryRequest, access.LegalBasis)
+	assert.Equal(t, "ACTOR_001", access.AccessedBy)
+}
+
+func TestUnconfiguredResidencyRejected(t *testing.T) {
+	stub := newCustomerStub(t)
+
+	response := invokeWithTransientPII(t, stub, "residency_1", domain.CustomerRegistrationRequest{
+		Residency: "ZZ",
+		ActorID:   "ACTOR_TEST",
+	}, domain.CustomerPII{
+		FirstName:   "Nowhere",
+		LastName:    "Customer",
+		Email:       "nowhere@example.com",
+		DateOfBirth: time.Date(1988, 3, 4, 0, 0, 0, 0, time.UTC),
+		NationalID:  "RESIDENT003",
+		Address:     "3 Residency Road, Test City, Test Country",
+	})
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	assert.Contains(t, response.Message, "no private data collection configured")
+}
@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+func TestConsentWithdrawalSuspendsDataSharingClauses(t *testing.T) {
+	stub := newCustomerStub(t)
+	adminIdentity := stub.Creator
+	customer := registerSearchCustomer(t, stub, "share1", "Ann", "Doe", "ann@example.com")
+	officerIdentity := bindRoleActor(t, stub, "share_2", "ACTOR_DSA", "Compliance_Officer")
+
+	invoke := func(txID, function string, req interface{}) ([]byte, string) {
+		reqBytes, err := json.Marshal(req)
+		require.NoError(t, err)
+		response := stub.MockInvoke(txID, [][]byte{[]byte(function), reqBytes})
+		if response.Status != shim.OK {
+			return nil, response.Message
+		}
+		return response.Payload, ""
+	}
+
+	agreementReq := domain.DataSharingAgreementRequest{
+		PartnerMSP:  "Org3MSP",
+		PartnerName: "Partner Insurer",
+		Clauses: []domain.DataSharingClause{
+			{ClauseID: "4.1", Purpose: "marketing", DataCategories: []string{"contact"}},
+			{ClauseID: "4.2", Purpose: config.ConsentCreditCheck, DataCategories: []string{"credit"}},
+		},
+		ActorID: "ACTOR_001",
+	}
+	_, message := invoke("share_3", "CreateDataSharingAgreement", agreementReq)
+	assert.Contains(t, message, "may only be recorded by")
+
+	stub.Creator = officerIdentity
+	agreementReq.ActorID = "ACTOR_DSA"
+	payload, message := invoke("share_4", "CreateDataSharingAgreement", agreementReq)
+	require.Empty(t, message)
+	var agreement domain.DataSharingAgreement
+	require.NoError(t, json.Unmarshal(payload, &agreement))
+
+	// The customer granted marketing but not credit check consent
+	stub.Creator = adminIdentity
+	disclosureReq := domain.DisclosureRequest{
+		CustomerID:     customer.CustomerID,
+		AgreementID:    agreement.AgreementID,
+		ClauseID:       "4.1",
+		DataCategories: []string{"contact"},
+		ActorID:        "ACTOR_001",
+	}
+	_, message = invoke("share_5", "RecordDisclosure", disclosureReq)
+	require.Empty(t, message)
+	_, message = invoke("share_6", "RecordDisclosure", domain.DisclosureRequest{
+		CustomerID:     customer.CustomerID,
+		AgreementID:    agreement.AgreementID,
+		ClauseID:       "4.2",
+		DataCategories: []string{"credit"},
+		ActorID:        "ACTOR_001",
+	})
+	assert.Contains(t, message, "has not granted consent")
+
+	// Withdrawing marketing consent suspends clause 4.1 and tells the partner
+	for len(stub.ChaincodeEventsChannel) > 0 {
+		<-stub.ChaincodeEventsChannel
+	}
+	withdrawn := `{"marketing": false}`
+	_, message = invoke("share_7", "UpdateCustomer", domain.CustomerUpdateRequest{CustomerID: customer.CustomerID, ConsentPreferences: &withdrawn, ActorID: "ACTOR_001"})
+	require.Empty(t, message)
+
+	var eventBytes []byte
+	for len(stub.ChaincodeEventsChannel) > 0 {
+		event := <-stub.ChaincodeEventsChannel
+		if event.EventName == config.EventConsentWithdrawn {
+			eventBytes = event.Payload
+		}
+	}
+	require.NotNil(t, eventBytes)
+	var withdrawal domain.ConsentWithdrawal
+	event, err := services.DecodeEvent(eventBytes, &withdrawal)
+	require.NoError(t, err)
+	assert.Equal(t, "Org3MSP", event.Metadata["partnerMSPs"])
+	assert.Equal(t, []string{"marketing"}, withdrawal.Purposes)
+	require.Len(t, withdrawal.Suspensions, 1)
+	assert.Equal(t, "4.1", withdrawal.Suspensions[0].ClauseID)
+
+	_, message = invoke("share_8", "RecordDisclosure", disclosureReq)
+	assert.Contains(t, message, "is suspended")
+
+	// Granting the consent again lifts the suspension
+	regranted := `{"marketing": true}`
+	_, message = invoke("share_9", "UpdateCustomer", domain.CustomerUpdateRequest{CustomerID: customer.CustomerID, ConsentPreferences: &regranted, ActorID: "ACTOR_001"})
+	require.Empty(t, message)
+	_, message = invoke("share_10", "RecordDisclosure", disclosureReq)
+	require.Empty(t, message)
+
+	response := stub.MockInvoke("share_11", [][]byte{[]byte("GetClauseSuspensions"), []byte(customer.CustomerID)})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	var suspensions []domain.ClauseSuspension
+	require.NoError(t, json.Unmarshal(response.Payload, &suspensions))
+	require.Len(t, suspensions, 1)
+	assert.False(t, suspensions[0].Active())
+	assert.Equal(t, "share_9", suspensions[0].ReinstatedTxID)
+
+	response = stub.MockInvoke("share_12", [][]byte{[]byte("GetDisclosureLog"), []byte(customer.CustomerID)})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	var disclosures []domain.DisclosureRecord
+	require.NoError(t, json.Unmarshal(response.Payload, &disclosures))
+	require.Len(t, disclosures, 2)
+	assert.Equal(t, "Org3MSP", disclosures[0].PartnerMSP)
+}
@@ -0,0 +1,38 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+func TestDeterministicIDsAreNumberedPerInvocation(t *testing.T) {
+	stub := shimtest.NewMockStub("deterministic_id", nil)
+
+	generate := func(invocation *services.Invocation) []string {
+		return []string{
+			services.GenerateDeterministicID(invocation, "CUST"),
+			services.GenerateDeterministicID(invocation, "CUST"),
+			services.GenerateDeterministicID(invocation, "EVENT"),
+		}
+	}
+
+	stub.MockTransactionStart("ids_1")
+	ids := generate(services.NewInvocation(stub))
+	assert.NotEqual(t, ids[0], ids[1], "IDs with the same prefix in one invocation should differ")
+	assert.Regexp(t, `^EVENT_[0-9a-f]{16}$`, ids[2])
+
+	// Endorsing the same transaction again starts a new invocation that derives the same IDs
+	assert.Equal(t, ids, generate(services.NewInvocation(stub)))
+	stub.MockTransactionEnd("ids_1")
+
+	// A wrapper reused for another transaction numbers that transaction's IDs afresh
+	invocation := services.NewInvocation(stub)
+	stub.MockTransactionStart("ids_2")
+	next := generate(invocation)
+	stub.MockTransactionEnd("ids_2")
+	assert.NotEqual(t, ids[0], next[0])
+	assert.NotEqual(t, next[0], next[1])
+}
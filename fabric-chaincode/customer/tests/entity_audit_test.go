@@ -0,0 +1,169 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/golang/protobuf/ptypes/timestamp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	sharedChaincode "github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/chaincode"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// historyStub serves the key history the mock stub does not implement, from the writes captured
+// after each transaction
+type historyStub struct {
+	*shimtest.MockStub
+	history map[string][]*queryresult.KeyModification
+}
+
+func (s *historyStub) capture(txID string, at time.Time, keys ...string) {
+	for _, key := range keys {
+		value := s.State[key]
+		modifications := s.history[key]
+		if len(modifications) > 0 && string(modifications[len(modifications)-1].Value) == string(value) {
+			continue
+		}
+		s.history[key] = append(modifications, &queryresult.KeyModification{
+			TxId:      txID,
+			Value:     value,
+			Timestamp: &timestamp.Timestamp{Seconds: at.Unix(), Nanos: int32(at.Nanosecond())},
+		})
+	}
+}
+
+func (s *historyStub) GetHistoryForKey(key string) (shim.HistoryQueryIteratorInterface, error) {
+	return &historyIterator{modifications: s.history[key]}, nil
+}
+
+type historyIterator struct {
+	modifications []*queryresult.KeyModification
+}
+
+func (it *historyIterator) HasNext() bool { return len(it.modifications) > 0 }
+func (it *historyIterator) Close() error  { return nil }
+func (it *historyIterator) Next() (*queryresult.KeyModification, error) {
+	next := it.modifications[0]
+	it.modifications = it.modifications[1:]
+	return next, nil
+}
+
+func TestExportEntityAuditChainsFullHistory(t *testing.T) {
+	clock := services.NewFixedClock(time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC))
+	defer services.SetClock(clock)()
+	stub := &historyStub{MockStub: newCustomerStub(t), history: map[string][]*queryresult.KeyModification{}}
+	adminIdentity := stub.Creator
+	handler := sharedChaincode.NewEntityAuditHandler("CUSTOMER_%s")
+
+	customer := registerSearchCustomer(t, stub.MockStub, "eaudit1", "Ada", "Audit", "ada.audit@example.com")
+	customerKey := "CUSTOMER_" + customer.CustomerID
+	now, err := services.TxTime(stub)
+	require.NoError(t, err)
+	stub.capture("eaudit1", now, customerKey)
+
+	update := func(txID, phone string) {
+		clock.Advance(time.Hour)
+		updateBytes, err := json.Marshal(domain.CustomerUpdateRequest{CustomerID: customer.CustomerID, Phone: &phone, ActorID: "ACTOR_001"})
+		require.NoError(t, err)
+		response := stub.MockInvoke(txID, [][]byte{[]byte("UpdateCustomer"), updateBytes})
+		require.Equal(t, int32(shim.OK), response.Status, response.Message)
+		now, err := services.TxTime(stub)
+		require.NoError(t, err)
+		stub.capture(txID, now, customerKey)
+	}
+	update("eaudit2", "+1555000111")
+
+	export := func(txID string) (*services.EntityAudit, error) {
+		stub.MockTransactionStart(txID)
+		defer stub.MockTransactionEnd(txID)
+		auditBytes, err := handler.ExportEntityAudit(stub, []string{customer.CustomerID})
+		if err != nil {
+			return nil, err
+		}
+		var audit services.EntityAudit
+		require.NoError(t, json.Unmarshal(auditBytes, &audit))
+		return &audit, nil
+	}
+	verify := func(txID string, audit services.EntityAudit) *services.EntityAuditVerification {
+		auditBytes, err := json.Marshal(audit)
+		require.NoError(t, err)
+		stub.MockTransactionStart(txID)
+		defer stub.MockTransactionEnd(txID)
+		verificationBytes, err := handler.VerifyEntityAudit(stub, []string{string(auditBytes)})
+		require.NoError(t, err)
+		var verification services.EntityAuditVerification
+		require.NoError(t, json.Unmarshal(verificationBytes, &verification))
+		return &verification
+	}
+
+	// Only compliance officers and regulators export
+	_, err = export("eaudit3")
+	assert.Contains(t, err.Error(), "may only be exported by")
+
+	stub.Creator = newTestIdentity(t, "Org1MSP", "regulator", "Regulator")
+	audit, err := export("eaudit4")
+	require.NoError(t, err)
+	assert.Equal(t, services.EntityAuditFormat, audit.Format)
+	assert.Equal(t, "Org1MSP", audit.ExportedByMSP)
+	assert.Equal(t, len(audit.Entries), audit.EntryCount)
+	assert.Empty(t, services.VerifyEntityAuditChain(audit))
+
+	// Both record writes and the recorded field changes, in order, each writing transaction's
+	// record write first
+	sources := map[string]int{}
+	for i, entry := range audit.Entries {
+		assert.Equal(t, i+1, entry.Sequence)
+		sources[entry.Source]++
+		if i > 0 {
+			assert.False(t, entry.Timestamp.Before(audit.Entries[i-1].Timestamp))
+			assert.Equal(t, audit.Entries[i-1].EntryHash, entry.PreviousHash)
+		}
+	}
+	assert.Equal(t, 2, sources[services.EntityAuditSourceLedger])
+	assert.Greater(t, sources[services.EntityAuditSourceHistory], 0)
+	assert.Equal(t, services.EntityAuditSourceLedger, audit.Entries[0].Source)
+	assert.Equal(t, customerKey, audit.Entries[0].Key)
+	assert.Equal(t, audit.Entries[len(audit.Entries)-1].EntryHash, audit.HeadHash)
+
+	// An export is reproducible, so its head hash can be checked against any peer
+	again, err := export("eaudit5")
+	require.NoError(t, err)
+	assert.Equal(t, audit.HeadHash, again.HeadHash)
+	assert.True(t, verify("eaudit6", *audit).Valid)
+
+	// Omitted, reordered and altered entries are all detected
+	omitted := *audit
+	omitted.Entries = append([]services.EntityAuditEntry{}, audit.Entries[1:]...)
+	omitted.EntryCount = len(omitted.Entries)
+	verification := verify("eaudit7", omitted)
+	assert.False(t, verification.Valid)
+	assert.Contains(t, verification.Problems, "entry 1 does not chain from the entry before it")
+	assert.Contains(t, verification.Problems, fmt.Sprintf("export has %d entries, the ledger has %d", audit.EntryCount-1, audit.EntryCount))
+
+	reordered := *audit
+	reordered.Entries = append([]services.EntityAuditEntry{}, audit.Entries...)
+	reordered.Entries[0], reordered.Entries[1] = reordered.Entries[1], reordered.Entries[0]
+	assert.Contains(t, services.VerifyEntityAuditChain(&reordered), "entry 1 is out of sequence (sequence 2)")
+
+	altered := *audit
+	altered.Entries = append([]services.EntityAuditEntry{}, audit.Entries...)
+	altered.Entries[0].Value = json.RawMessage(`{"customerID":"FORGED"}`)
+	assert.Contains(t, services.VerifyEntityAuditChain(&altered), "entry 1 has been altered")
+
+	// Later changes make an earlier export incomplete against the ledger
+	stub.Creator = adminIdentity
+	update("eaudit8", "+1555000222")
+	stub.Creator = newTestIdentity(t, "Org1MSP", "officer", "Compliance_Officer")
+	verification = verify("eaudit9", *audit)
+	assert.False(t, verification.Valid)
+	assert.Greater(t, verification.LedgerEntries, audit.EntryCount)
+	assert.Contains(t, verification.Problems, "head hash does not match the ledger")
+	assert.Empty(t, services.VerifyEntityAuditChain(audit))
+}
@@ -0,0 +1,112 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	sharedChaincode "github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/chaincode"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+func TestEntityFreezeNeedsASecondOfficerToRelease(t *testing.T) {
+	stub := newCustomerStub(t)
+	customer := createTestCustomer(t, stub, "FREEZE001")
+	adminIdentity := stub.Creator
+	officerIdentity := bindRoleActor(t, stub, "freeze_1", "ACTOR_FREEZE_CO", "Compliance_Officer")
+	secondOfficerIdentity := bindRoleActor(t, stub, "freeze_2", "ACTOR_FREEZE_CO2", "Compliance_Officer")
+
+	invoke := func(txID, function string, args ...string) (services.ChaincodeError, []byte) {
+		invokeArgs := [][]byte{[]byte(function)}
+		for _, arg := range args {
+			invokeArgs = append(invokeArgs, []byte(arg))
+		}
+		response := stub.MockInvoke(txID, invokeArgs)
+		var chaincodeErr services.ChaincodeError
+		if response.Status != shim.OK {
+			require.NoError(t, json.Unmarshal([]byte(response.Message), &chaincodeErr), response.Message)
+		}
+		return chaincodeErr, response.Payload
+	}
+	freezeRequest := func(reason, actorID string, reasonCode services.FreezeReasonCode) string {
+		reqBytes, err := json.Marshal(sharedChaincode.EntityFreezeRequest{
+			CustomerID: customer.CustomerID,
+			ReasonCode: reasonCode,
+			Reason:     reason,
+			ActorID:    actorID,
+		})
+		require.NoError(t, err)
+		return string(reqBytes)
+	}
+	update := func(txID, phone string) services.ChaincodeError {
+		reqBytes, err := json.Marshal(domain.CustomerUpdateRequest{CustomerID: customer.CustomerID, Phone: &phone, ActorID: "ACTOR_001"})
+		require.NoError(t, err)
+		chaincodeErr, _ := invoke(txID, "UpdateCustomer", string(reqBytes))
+		return chaincodeErr
+	}
+
+	// Only compliance officers may freeze
+	chaincodeErr, _ := invoke("freeze_3", "FreezeCustomer", freezeRequest("Preservation order", "ACTOR_001", services.FreezeReasonLegalHold))
+	assert.Equal(t, services.ErrCodeAccessDenied, chaincodeErr.Code)
+	assert.Contains(t, chaincodeErr.Message, "freezes may only be managed by a Compliance_Officer")
+
+	stub.Creator = officerIdentity
+	chaincodeErr, payload := invoke("freeze_4", "FreezeCustomer", freezeRequest("Preservation order", "ACTOR_FREEZE_CO", services.FreezeReasonLegalHold))
+	require.Empty(t, chaincodeErr.Code, chaincodeErr.Message)
+	var freeze services.EntityFreeze
+	require.NoError(t, json.Unmarshal(payload, &freeze))
+	assert.Equal(t, services.FreezeStatusActive, freeze.Status)
+	assert.Equal(t, "ACTOR_FREEZE_CO", freeze.FrozenBy)
+
+	// Changes to the frozen customer are blocked, reads are not
+	stub.Creator = adminIdentity
+	blocked := update("freeze_5", "+1987654321")
+	assert.Equal(t, services.ErrCodeInvalidTransition, blocked.Code)
+	assert.Contains(t, blocked.Message, "is frozen (LEGAL_HOLD)")
+	chaincodeErr, _ = invoke("freeze_6", "GetCustomer", customer.CustomerID)
+	assert.Empty(t, chaincodeErr.Code, chaincodeErr.Message)
+
+	// Nothing can be approved before an unfreeze is requested
+	stub.Creator = officerIdentity
+	chaincodeErr, _ = invoke("freeze_6a", "ApproveUnfreeze", freezeRequest("Order discharged", "ACTOR_FREEZE_CO", ""))
+	assert.Equal(t, services.ErrCodeInvalidTransition, chaincodeErr.Code)
+	assert.Contains(t, chaincodeErr.Message, "has been requested")
+
+	// The officer who asked for the unfreeze may not approve it
+	chaincodeErr, _ = invoke("freeze_7", "RequestUnfreeze", freezeRequest("Order discharged", "ACTOR_FREEZE_CO", ""))
+	require.Empty(t, chaincodeErr.Code, chaincodeErr.Message)
+	chaincodeErr, _ = invoke("freeze_8", "ApproveUnfreeze", freezeRequest("Order discharged", "ACTOR_FREEZE_CO", ""))
+	assert.Equal(t, services.ErrCodeAccessDenied, chaincodeErr.Code)
+	assert.Contains(t, chaincodeErr.Message, "other than the requester")
+
+	stub.Creator = adminIdentity
+	blocked = update("freeze_9", "+1987654321")
+	assert.Equal(t, services.ErrCodeInvalidTransition, blocked.Code)
+
+	// A second officer releases the freeze and changes go through again
+	stub.Creator = secondOfficerIdentity
+	chaincodeErr, payload = invoke("freeze_10", "ApproveUnfreeze", freezeRequest("Order discharged", "ACTOR_FREEZE_CO2", ""))
+	require.Empty(t, chaincodeErr.Code, chaincodeErr.Message)
+	require.NoError(t, json.Unmarshal(payload, &freeze))
+	assert.Equal(t, services.FreezeStatusReleased, freeze.Status)
+	assert.Equal(t, "ACTOR_FREEZE_CO2", freeze.ReleasedBy)
+
+	stub.Creator = adminIdentity
+	chaincodeErr = update("freeze_11", "+1987654321")
+	assert.Empty(t, chaincodeErr.Code, chaincodeErr.Message)
+}
+
+func TestEntityFreezeRejectsUnresolvableInvocations(t *testing.T) {
+	stub := newCustomerStub(t)
+
+	// A state-changing function whose entity cannot be read is refused rather than let through
+	response := stub.MockInvoke("freeze_unresolved", [][]byte{[]byte("UpdateCustomer"), []byte("CUST_001")})
+	require.Equal(t, int32(shim.ERROR), response.Status)
+	var chaincodeErr services.ChaincodeError
+	require.NoError(t, json.Unmarshal([]byte(response.Message), &chaincodeErr), response.Message)
+	assert.Equal(t, services.ErrCodeInvalidArgument, chaincodeErr.Code)
+	assert.Contains(t, chaincodeErr.Message, "entity freezes cannot be checked")
+}
@@ -0,0 +1,174 @@
+package tests
+
+import (
+	"encoding/json"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	sharedChaincode "github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/chaincode"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// bindRoleActor registers an actor to a new identity with the given role and returns that identity
+func bindRoleActor(t *testing.T, stub *shimtest.MockStub, txID, actorID, role string) []byte {
+	adminIdentity := stub.Creator
+	identity := newTestIdentity(t, "Org1MSP", actorID, role)
+	stub.Creator = identity
+	response := stub.MockInvoke(txID+"_whoami", [][]byte{[]byte("GetInvokerIdentity")})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	var invoker sharedChaincode.InvokerIdentity
+	require.NoError(t, json.Unmarshal(response.Payload, &invoker))
+
+	stub.Creator = adminIdentity
+	reqBytes, err := json.Marshal(sharedChaincode.ActorRegistrationRequest{
+		RegisteredActorID:  actorID,
+		BlockchainIdentity: invoker.BlockchainIdentity,
+		MSPID:              invoker.MSPID,
+		Role:               role,
+		Active:             true,
+		ActorID:            "ACTOR_001",
+	})
+	require.NoError(t, err)
+	response = stub.MockInvoke(txID, [][]byte{[]byte("RegisterActor"), reqBytes})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	return identity
+}
+
+func TestEntityNotesScopedByVisibility(t *testing.T) {
+	stub := newCustomerStub(t)
+	customer := createTestCustomer(t, stub, "NOTES001")
+	officerIdentity := bindRoleActor(t, stub, "notes_1", "ACTOR_NOTE_CO", "Compliance_Officer")
+	tellerIdentity := bindRoleActor(t, stub, "notes_2", "ACTOR_NOTE_CSR", "Customer_Service_Rep")
+
+	addNote := func(txID, text, visibility, actorID string) (services.EntityNote, string) {
+		reqBytes, err := json.Marshal(sharedChaincode.EntityNoteRequest{
+			EntityID:   customer.CustomerID,
+			EntityType: "Customer",
+			Text:       text,
+			Visibility: visibility,
+			ActorID:    actorID,
+		})
+		require.NoError(t, err)
+		response := stub.MockInvoke(txID, [][]byte{[]byte("AddNote"), reqBytes})
+		var note services.EntityNote
+		if response.Status != shim.OK {
+			return note, response.Message
+		}
+		require.NoError(t, json.Unmarshal(response.Payload, &note))
+		return note, ""
+	}
+
+	// Tellers may leave internal notes but not compliance-only ones
+	stub.Creator = tellerIdentity
+	internal, message := addNote("notes_3", "Customer asked for statements by post", "INTERNAL", "ACTOR_NOTE_CSR")
+	require.Empty(t, message)
+	assert.Equal(t, "notes_3", internal.NoteID)
+	assert.Equal(t, 1, internal.Revision)
+	_, message = addNote("notes_4", "Possible structuring", "COMPLIANCE_ONLY", "ACTOR_NOTE_CSR")
+	assert.Contains(t, message, "compliance-only notes may only be written by")
+
+	stub.Creator = officerIdentity
+	restricted, message := addNote("notes_5", "Possible structuring", "COMPLIANCE_ONLY", "ACTOR_NOTE_CO")
+	require.Empty(t, message)
+
+	listNotes := func(txID string, args ...string) sharedChaincode.EntityNotePageResult {
+		invokeArgs := [][]byte{[]byte("GetEntityNotes"), []byte(customer.CustomerID)}
+		for _, arg := range args {
+			invokeArgs = append(invokeArgs, []byte(arg))
+		}
+		response := stub.MockInvoke(txID, invokeArgs)
+		require.Equal(t, int32(shim.OK), response.Status, response.Message)
+		var page sharedChaincode.EntityNotePageResult
+		require.NoError(t, json.Unmarshal(response.Payload, &page))
+		return page
+	}
+
+	page := listNotes("notes_6")
+	require.Equal(t, 2, page.Count)
+	assert.Equal(t, internal.NoteID, page.Notes[0].NoteID)
+	assert.Equal(t, restricted.NoteID, page.Notes[1].NoteID)
+
+	stub.Creator = tellerIdentity
+	page = listNotes("notes_7")
+	require.Equal(t, 1, page.Count)
+	assert.Equal(t, internal.NoteID, page.Notes[0].NoteID)
+
+	response := stub.MockInvoke("notes_8", [][]byte{[]byte("GetNoteHistory"), []byte(customer.CustomerID), []byte(restricted.NoteID)})
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+
+	// Paging walks the notes oldest first
+	for i := 0; i < 3; i++ {
+		_, message = addNote(fmt.Sprintf("notes_page_%d", i), fmt.Sprintf("Follow-up call %d", i), "INTERNAL", "ACTOR_NOTE_CSR")
+		require.Empty(t, message)
+	}
+	page = listNotes("notes_9", "2")
+	require.Equal(t, 2, page.Count)
+	assert.Equal(t, internal.NoteID, page.Notes[0].NoteID)
+	require.NotEmpty(t, page.Bookmark)
+	page = listNotes("notes_10", "2", page.Bookmark)
+	require.Equal(t, 2, page.Count)
+	assert.Equal(t, "notes_page_1", page.Notes[0].NoteID)
+}
+
+func TestEntityNoteEditsKeepHistory(t *testing.T) {
+	stub := newCustomerStub(t)
+	customer := createTestCustomer(t, stub, "NOTES002")
+	adminIdentity := stub.Creator
+	tellerIdentity := bindRoleActor(t, stub, "edit_1", "ACTOR_NOTE_CSR", "Customer_Service_Rep")
+
+	stub.Creator = tellerIdentity
+	reqBytes, err := json.Marshal(sharedChaincode.EntityNoteRequest{
+		EntityID:   customer.CustomerID,
+		EntityType: "Customer",
+		Text:       "Prefers contact after 5pm",
+		Visibility: "INTERNAL",
+		ActorID:    "ACTOR_NOTE_CSR",
+	})
+	require.NoError(t, err)
+	response := stub.MockInvoke("edit_2", [][]byte{[]byte("AddNote"), reqBytes})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	var note services.EntityNote
+	require.NoError(t, json.Unmarshal(response.Payload, &note))
+
+	editReq := sharedChaincode.EntityNoteEditRequest{
+		EntityID: customer.CustomerID,
+		NoteID:   note.NoteID,
+		Text:     "Prefers contact after 6pm",
+		ActorID:  "ACTOR_001",
+	}
+
+	// Only the author may edit a note
+	stub.Creator = adminIdentity
+	editBytes, err := json.Marshal(editReq)
+	require.NoError(t, err)
+	response = stub.MockInvoke("edit_3", [][]byte{[]byte("EditNote"), editBytes})
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	assert.Contains(t, response.Message, "may only be edited by its author")
+
+	stub.Creator = tellerIdentity
+	editReq.ActorID = "ACTOR_NOTE_CSR"
+	editBytes, err = json.Marshal(editReq)
+	require.NoError(t, err)
+	response = stub.MockInvoke("edit_4", [][]byte{[]byte("EditNote"), editBytes})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	var edited services.EntityNote
+	require.NoError(t, json.Unmarshal(response.Payload, &edited))
+	assert.Equal(t, 2, edited.Revision)
+	assert.Equal(t, "Prefers contact after 6pm", edited.Text)
+	require.NotNil(t, edited.LastEditedDate)
+	assert.True(t, edited.CreatedDate.Equal(note.CreatedDate))
+
+	response = stub.MockInvoke("edit_5", [][]byte{[]byte("GetNoteHistory"), []byte(customer.CustomerID), []byte(note.NoteID)})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	var revisions []services.EntityNoteRevision
+	require.NoError(t, json.Unmarshal(response.Payload, &revisions))
+	require.Len(t, revisions, 2)
+	assert.Equal(t, "Prefers contact after 5pm", revisions[0].Text)
+	assert.Equal(t, "edit_2", revisions[0].TxID)
+	assert.Equal(t, "Prefers contact after 6pm", revisions[1].Text)
+	assert.Equal(t, "edit_4", revisions[1].TxID)
+}
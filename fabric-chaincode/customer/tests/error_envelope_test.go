@@ -0,0 +1,109 @@
+package tests
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+func TestClassifyErrorByWording(t *testing.T) {
+	tests := []struct {
+		message   string
+		code      string
+		field     string
+		retryable bool
+	}{
+		{"incorrect number of arguments. Expected 1, got 0", services.ErrCodeInvalidArgument, "", false},
+		{"actorID is required", services.ErrCodeInvalidArgument, "actorID", false},
+		{"customer not found: no data found for key CUSTOMER_X", services.ErrCodeNotFound, "", false},
+		{"entity audits may only be exported by a Compliance_Officer", services.ErrCodeAccessDenied, "", false},
+		{"invalid status transition: APPROVED -> SUBMITTED", services.ErrCodeInvalidTransition, "", false},
+		{"loan cannot be approved from current status: SUBMITTED", services.ErrCodeInvalidTransition, "", false},
+		{"customer with national ID already exists", services.ErrCodeConflict, "", false},
+		{"loan application not found: failed to get state for key LOAN_1: peer unavailable", services.ErrCodeLedger, "", true},
+		{"failed to emit event: broken pipe", services.ErrCodeInternal, "", false},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.message, func(t *testing.T) {
+			classified := services.ClassifyError(fmt.Errorf("%s", tt.message))
+			assert.Equal(t, tt.code, classified.Code)
+			assert.Equal(t, tt.message, classified.Message)
+			assert.Equal(t, tt.field, classified.Field)
+			assert.Equal(t, tt.retryable, classified.Retryable)
+		})
+	}
+
+	// An explicit code is kept however the message reads
+	explicit := services.NewChaincodeError(services.ErrCodeConflict, "email", "email is already registered")
+	assert.Equal(t, explicit, services.ClassifyError(explicit))
+}
+
+func TestClassifyErrorByType(t *testing.T) {
+	// A coded error keeps its code through wrapping, with the message as wrapped
+	notFound := fmt.Errorf("failed to get customer: %w", services.NewChaincodeError(services.ErrCodeNotFound, "", "no data found for key CUSTOMER_X"))
+	assert.True(t, errors.Is(notFound, services.ErrNotFound))
+	assert.False(t, errors.Is(notFound, services.ErrAccessDenied))
+	classified := services.ClassifyError(notFound)
+	assert.Equal(t, services.ErrCodeNotFound, classified.Code)
+	assert.Equal(t, "failed to get customer: no data found for key CUSTOMER_X", classified.Message)
+
+	// The code wins over wording that reads as another code
+	denied := fmt.Errorf("customer not found: %w", services.NewChaincodeError(services.ErrCodeAccessDenied, "", "invoking identity is not bound to actor ACTOR_X"))
+	assert.Equal(t, services.ErrCodeAccessDenied, services.ClassifyError(denied).Code)
+
+	// A stale write is a conflict whose envelope carries the current version
+	conflict := fmt.Errorf("failed to update customer: %w", services.CheckExpectedVersion("Customer", "CUST_1", intPtr(1), 2))
+	assert.Equal(t, services.ErrCodeConflict, services.ClassifyError(conflict).Code)
+	var envelope map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(services.ErrorEnvelope(conflict)), &envelope))
+	assert.Equal(t, services.ErrCodeConflict, envelope["code"])
+	assert.Equal(t, float64(2), envelope["currentVersion"])
+	assert.Equal(t, "Customer CUST_1 is at version 2, expected 1", envelope["message"])
+}
+
+func intPtr(i int) *int {
+	return &i
+}
+
+func TestFailedResponsesCarryErrorEnvelope(t *testing.T) {
+	stub := newCustomerStub(t)
+
+	envelope := func(txID string, args ...string) services.ChaincodeError {
+		invokeArgs := [][]byte{}
+		for _, arg := range args {
+			invokeArgs = append(invokeArgs, []byte(arg))
+		}
+		response := stub.MockInvoke(txID, invokeArgs)
+		require.Equal(t, int32(shim.ERROR), response.Status)
+		var chaincodeErr services.ChaincodeError
+		require.NoError(t, json.Unmarshal([]byte(response.Message), &chaincodeErr), response.Message)
+		return chaincodeErr
+	}
+
+	unknown := envelope("envelope1", "NoSuchFunction")
+	assert.Equal(t, services.ErrCodeUnknownFunction, unknown.Code)
+	assert.Equal(t, "function NoSuchFunction not found", unknown.Message)
+
+	missing := envelope("envelope2", "GetCustomer", "CUST_MISSING")
+	assert.Equal(t, services.ErrCodeNotFound, missing.Code)
+	assert.False(t, missing.Retryable)
+
+	malformed := envelope("envelope3", "RegisterCustomer", "{not json")
+	assert.Equal(t, services.ErrCodeInvalidArgument, malformed.Code)
+
+	arguments := envelope("envelope4", "GetCustomer")
+	assert.Equal(t, services.ErrCodeInvalidArgument, arguments.Code)
+	assert.Contains(t, arguments.Message, "incorrect number of arguments")
+
+	// An actor that was never registered is missing, not refused
+	unregistered := envelope("envelope5", "GetActor", "ACTOR_UNKNOWN")
+	assert.Equal(t, services.ErrCodeNotFound, unregistered.Code)
+	assert.Equal(t, "actor ACTOR_UNKNOWN is not registered", unregistered.Message)
+}
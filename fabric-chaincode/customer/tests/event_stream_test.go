@@ -0,0 +1,140 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/interfaces"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+func TestCustomerEventStreamMergesActivityInTimeOrder(t *testing.T) {
+	start := time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+	clock := services.NewFixedClock(start)
+	defer services.SetClock(clock)()
+
+	stub := newCustomerStub(t)
+	compliance := &fakeComplianceChaincode{events: map[string][]interfaces.ComplianceEventSummary{}}
+	loan := &fakeLoanChaincode{milestones: map[string][]interfaces.CustomerLoanMilestone{}}
+	stub.MockPeerChaincode("compliance", shimtest.NewMockStub("compliance", compliance), "")
+	stub.MockPeerChaincode("loan", shimtest.NewMockStub("loan", loan), "")
+
+	customer := registerSearchCustomer(t, stub, "stream1", "Sam", "Stream", "sam@example.com")
+
+	invoke := func(txID, function string, req interface{}) []byte {
+		reqBytes, err := json.Marshal(req)
+		require.NoError(t, err)
+		response := stub.MockInvoke(txID, [][]byte{[]byte(function), reqBytes})
+		require.Equal(t, int32(shim.OK), response.Status, response.Message)
+		return response.Payload
+	}
+	stream := func(txID string, args ...string) domain.CustomerEventStream {
+		invokeArgs := [][]byte{[]byte("GetCustomerEventStream"), []byte(customer.CustomerID)}
+		for _, arg := range args {
+			invokeArgs = append(invokeArgs, []byte(arg))
+		}
+		response := stub.MockInvoke(txID, invokeArgs)
+		require.Equal(t, int32(shim.OK), response.Status, response.Message)
+		var result domain.CustomerEventStream
+		require.NoError(t, json.Unmarshal(response.Payload, &result))
+		return result
+	}
+
+	clock.Advance(time.Hour)
+	var kycRecord domain.KYCRecord
+	require.NoError(t, json.Unmarshal(invoke("stream_2", "InitiateKYC", domain.KYCInitiationRequest{
+		CustomerID:     customer.CustomerID,
+		DocumentHashes: []string{"hash1"},
+		ActorID:        "ACTOR_KYC_001",
+	}), &kycRecord))
+
+	clock.Advance(time.Hour)
+	invoke("stream_3", "UpdateKYCStatus", domain.KYCStatusUpdateRequest{KYCID: kycRecord.KYCID, NewStatus: validation.KYCStatusVerified, ActorID: "ACTOR_KYC_001"})
+
+	clock.Advance(time.Hour)
+	var firstAML domain.AMLRecord
+	require.NoError(t, json.Unmarshal(invoke("stream_4", "InitiateAMLCheck", domain.AMLCheckRequest{CustomerID: customer.CustomerID, ActorID: "ACTOR_AML_001"}), &firstAML))
+
+	clock.Advance(time.Hour)
+	invoke("stream_5", "RecordConsent", domain.ConsentRequest{CustomerID: customer.CustomerID, Purpose: "marketing", Granted: false, ActorID: "ACTOR_001"})
+
+	clock.Advance(time.Hour)
+	var secondAML domain.AMLRecord
+	require.NoError(t, json.Unmarshal(invoke("stream_6", "InitiateAMLCheck", domain.AMLCheckRequest{CustomerID: customer.CustomerID, ActorID: "ACTOR_AML_001"}), &secondAML))
+
+	compliance.events[customer.CustomerID] = []interfaces.ComplianceEventSummary{{
+		EventID:          "EVENT_001",
+		Timestamp:        start.Add(150 * time.Minute),
+		RuleID:           "AML_RESCREEN_RULE",
+		EventType:        "SANCTIONS_RESCREEN_MATCH",
+		Severity:         "HIGH",
+		IsAlerted:        true,
+		ResolutionStatus: "OPEN",
+	}}
+	loan.milestones[customer.CustomerID] = []interfaces.CustomerLoanMilestone{
+		{LoanID: "LOAN_001", LoanType: "PERSONAL", Status: "SUBMITTED", Label: "Application received", Timestamp: start.Add(270 * time.Minute), Sequence: "SUBMITTED"},
+		{LoanID: "LOAN_001", LoanType: "PERSONAL", Status: "APPROVED", Label: "Application approved", Timestamp: start.Add(330 * time.Minute), Sequence: "HIST_001"},
+	}
+
+	// Every source is merged in time order, across pages
+	entries := []domain.CustomerStreamEntry{}
+	bookmark := ""
+	for page := 0; page < 5; page++ {
+		result := stream("stream_page", "", "3", bookmark)
+		assert.LessOrEqual(t, result.Count, 3)
+		entries = append(entries, result.Entries...)
+		bookmark = result.Bookmark
+		if bookmark == "" {
+			break
+		}
+	}
+	require.Empty(t, bookmark)
+
+	types := []string{}
+	for _, entry := range entries {
+		types = append(types, entry.EntryType)
+	}
+	assert.Equal(t, []string{
+		domain.StreamEntryCustomerChange, // Registered
+		domain.StreamEntryConsent,        // Marketing granted at registration
+		domain.StreamEntryKYC,
+		domain.StreamEntryKYC,
+		domain.StreamEntryScreening,
+		domain.StreamEntryAMLCheck,
+		domain.StreamEntryConsent,
+		domain.StreamEntryLoanMilestone,
+		domain.StreamEntryAMLCheck,
+		domain.StreamEntryLoanMilestone,
+	}, types)
+	for i := 1; i < len(entries); i++ {
+		assert.False(t, entries[i].Timestamp.Before(entries[i-1].Timestamp))
+	}
+
+	assert.Equal(t, "Customer registered", entries[0].Summary)
+	assert.Equal(t, "Consent to marketing granted", entries[1].Summary)
+	assert.Equal(t, kycRecord.KYCID, entries[3].ReferenceID)
+	assert.Equal(t, "VERIFIED", entries[3].Details["newStatus"])
+	assert.Equal(t, "EVENT_001", entries[4].ReferenceID)
+	assert.Equal(t, firstAML.AMLID, entries[5].ReferenceID)
+	assert.Equal(t, "Consent to marketing withdrawn", entries[6].Summary)
+	assert.Equal(t, "Loan LOAN_001: Application received", entries[7].Summary)
+	assert.Equal(t, secondAML.AMLID, entries[8].ReferenceID)
+
+	// A since time drops earlier entries
+	result := stream("stream_since", start.Add(3*time.Hour).Format(time.RFC3339))
+	require.Equal(t, 5, result.Count)
+	assert.Equal(t, domain.StreamEntryAMLCheck, result.Entries[0].EntryType)
+	assert.Empty(t, result.Bookmark)
+
+	response := stub.MockInvoke("stream_bad", [][]byte{[]byte("GetCustomerEventStream"), []byte(customer.CustomerID), []byte("last week")})
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	response = stub.MockInvoke("stream_missing", [][]byte{[]byte("GetCustomerEventStream"), []byte("CUST_MISSING"), []byte("")})
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+}
@@ -0,0 +1,59 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+func TestEventsCarrySchemaVersion(t *testing.T) {
+	stub := newCustomerStub(t)
+	for len(stub.ChaincodeEventsChannel) > 0 {
+		<-stub.ChaincodeEventsChannel
+	}
+
+	customer := createTestCustomer(t, stub, "EVENTS001")
+
+	var eventBytes []byte
+	for len(stub.ChaincodeEventsChannel) > 0 {
+		event := <-stub.ChaincodeEventsChannel
+		if event.EventName == config.EventCustomerCreated {
+			eventBytes = event.Payload
+		}
+	}
+	require.NotNil(t, eventBytes)
+
+	var emitted domain.Customer
+	event, err := services.DecodeEvent(eventBytes, &emitted)
+	require.NoError(t, err)
+	assert.Equal(t, config.EventCustomerCreated, event.EventName)
+	assert.Equal(t, config.DefaultEventSchemaVersion, event.SchemaVersion)
+	assert.Equal(t, customer.CustomerID, emitted.CustomerID)
+
+	// Listeners written before versioning read the payload fields at the top level
+	var legacy struct {
+		EventType string          `json:"eventType"`
+		EntityID  string          `json:"entityID"`
+		Data      domain.Customer `json:"data"`
+	}
+	require.NoError(t, json.Unmarshal(eventBytes, &legacy))
+	assert.Equal(t, config.EventCustomerCreated, legacy.EventType)
+	assert.Equal(t, customer.CustomerID, legacy.EntityID)
+	assert.Equal(t, customer.CustomerID, legacy.Data.CustomerID)
+}
+
+func TestDecodeEventAcceptsLegacyPayloads(t *testing.T) {
+	legacyBytes := []byte(`{"eventType":"CustomerUpdated","entityID":"CUST_1","entityType":"Customer","actorID":"ACTOR_001","timestamp":"2024-01-01T00:00:00Z","data":{"customerID":"CUST_1"}}`)
+
+	var data domain.Customer
+	event, err := services.DecodeEvent(legacyBytes, &data)
+	require.NoError(t, err)
+	assert.Equal(t, "CustomerUpdated", event.EventName)
+	assert.Equal(t, config.LegacyEventSchemaVersion, event.SchemaVersion)
+	assert.Equal(t, "CUST_1", data.CustomerID)
+}
@@ -0,0 +1,201 @@
+package tests
+
+import (
+	"bytes"
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+)
+
+// dataKeys returns transient data releasing data keys to a transaction, sealing new values under
+// the first
+func dataKeys(keys map[string][]byte, activeRef string) map[string][]byte {
+	transient := map[string][]byte{}
+	if activeRef != "" {
+		transient[config.FieldEncryptionKeyRefTransientKey] = []byte(activeRef)
+	}
+	for ref, key := range keys {
+		transient[config.FieldEncryptionKeyTransientPrefix+ref] = key
+	}
+	return transient
+}
+
+// withContact adds to transient data the contact details a registration or update sets
+func withContact(t *testing.T, transient map[string][]byte, contact domain.CustomerContact) map[string][]byte {
+	contactBytes, err := json.Marshal(contact)
+	require.NoError(t, err)
+	transient[config.CustomerContactTransientKey] = contactBytes
+	return transient
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
+
+func getCustomerWith(t *testing.T, stub *shimtest.MockStub, txID, customerID string, transient map[string][]byte) domain.Customer {
+	stub.TransientMap = transient
+	defer func() { stub.TransientMap = nil }()
+	response := stub.MockInvoke(txID, [][]byte{[]byte("GetCustomer"), []byte(customerID)})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+
+	var customer domain.Customer
+	require.NoError(t, json.Unmarshal(response.Payload, &customer))
+	return customer
+}
+
+func TestFieldEncryptionSealsContactDetails(t *testing.T) {
+	stub := newCustomerStub(t)
+	adminIdentity := stub.Creator
+	serviceRepIdentity := bindRoleActor(t, stub, "enc_csr", "ACTOR_ENC_CSR", "Customer_Service_Rep")
+
+	keyV1 := bytes.Repeat([]byte{0x11}, 32)
+	keyV2 := bytes.Repeat([]byte{0x22}, 32)
+
+	// Values to be sealed may not travel in the request arguments, which reach the ledger
+	registration := domain.CustomerRegistrationRequest{
+		FirstName:   "Sally",
+		LastName:    "Sealed",
+		Email:       "sally.sealed@example.com",
+		DateOfBirth: time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC),
+		NationalID:  "ENCTEST001",
+		ActorID:     "ACTOR_TEST",
+	}
+	reqBytes, err := json.Marshal(registration)
+	require.NoError(t, err)
+	stub.TransientMap = dataKeys(map[string][]byte{"pii-key-v1": keyV1}, "pii-key-v1")
+	response := stub.MockInvoke("enc_0", [][]byte{[]byte("RegisterCustomer"), reqBytes})
+	stub.TransientMap = nil
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	assert.Contains(t, response.Message, "must be passed in transient data under customerContact")
+
+	registration.Email = ""
+	stub.TransientMap = withContact(t, dataKeys(map[string][]byte{"pii-key-v1": keyV1}, "pii-key-v1"), domain.CustomerContact{
+		Email:   stringPtr("sally.sealed@example.com"),
+		Phone:   stringPtr("+1555000111"),
+		Address: stringPtr("7 Cipher Lane, Test City, Test Country"),
+	})
+	customer := registerCustomerRequest(t, stub, "enc_1", registration)
+	stub.TransientMap = nil
+
+	// Only key references and ciphertext reach the ledger, in the response as in the state
+	assert.Empty(t, customer.Email)
+	assert.Empty(t, customer.Phone)
+	assert.Empty(t, customer.Address)
+	require.Len(t, customer.EncryptedFields, 3)
+	assert.Equal(t, "pii-key-v1", customer.EncryptedFields["email"].KeyRef)
+	assert.NotEmpty(t, customer.EmailHash)
+	for key, value := range stub.State {
+		for _, plaintext := range []string{"sally.sealed@example.com", "+1555000111", "Cipher Lane"} {
+			assert.NotContains(t, string(value), plaintext, "plaintext PII under %s", key)
+		}
+	}
+
+	// Search by email still works from the hash
+	response = stub.MockInvoke("enc_2", [][]byte{[]byte("SearchCustomersByEmail"), []byte("Sally.Sealed@example.com")})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	var result domain.CustomerQueryResult
+	require.NoError(t, json.Unmarshal(response.Payload, &result))
+	require.Equal(t, 1, result.Count)
+	assert.Equal(t, customer.CustomerID, result.Customers[0].CustomerID)
+
+	// A role holding VIEW_PII reads the plaintext when the client supplies the key
+	stub.Creator = serviceRepIdentity
+	opened := getCustomerWith(t, stub, "enc_3", customer.CustomerID, dataKeys(map[string][]byte{"pii-key-v1": keyV1}, ""))
+	assert.Equal(t, "sally.sealed@example.com", opened.Email)
+	assert.Equal(t, "+1555000111", opened.Phone)
+	assert.Equal(t, "7 Cipher Lane, Test City, Test Country", opened.Address)
+
+	// Without the key, or without the permission, the fields stay sealed
+	assert.Empty(t, getCustomerWith(t, stub, "enc_4", customer.CustomerID, nil).Email)
+	stub.Creator = adminIdentity
+	assert.Empty(t, getCustomerWith(t, stub, "enc_5", customer.CustomerID, dataKeys(map[string][]byte{"pii-key-v1": keyV1}, "")).Email)
+
+	// A wrong key under the same reference fails authentication rather than returning garbage
+	stub.Creator = serviceRepIdentity
+	stub.TransientMap = dataKeys(map[string][]byte{"pii-key-v1": keyV2}, "")
+	response = stub.MockInvoke("enc_6", [][]byte{[]byte("GetCustomer"), []byte(customer.CustomerID)})
+	stub.TransientMap = nil
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	assert.Contains(t, response.Message, "failed to decrypt")
+	stub.Creator = adminIdentity
+
+	// A sealed customer's contact details may only change with a data key
+	newPhone := "+1555000222"
+	updateBytes, _ := json.Marshal(domain.CustomerUpdateRequest{CustomerID: customer.CustomerID, Phone: &newPhone, ActorID: "ACTOR_TEST"})
+	response = stub.MockInvoke("enc_7", [][]byte{[]byte("UpdateCustomer"), updateBytes})
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	assert.Contains(t, response.Message, "data key is required")
+
+	// Changed values are sealed under the key now in use; unchanged ones keep their old key
+	stub.TransientMap = dataKeys(map[string][]byte{"pii-key-v2": keyV2}, "pii-key-v2")
+	response = stub.MockInvoke("enc_8", [][]byte{[]byte("UpdateCustomer"), updateBytes})
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	assert.Contains(t, response.Message, "not in the request")
+
+	contactUpdateBytes, _ := json.Marshal(domain.CustomerUpdateRequest{CustomerID: customer.CustomerID, ActorID: "ACTOR_TEST"})
+	stub.TransientMap = withContact(t, stub.TransientMap, domain.CustomerContact{Phone: &newPhone})
+	response = stub.MockInvoke("enc_8b", [][]byte{[]byte("UpdateCustomer"), contactUpdateBytes})
+	stub.TransientMap = nil
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	assert.NotContains(t, string(response.Payload), newPhone)
+	var updated domain.Customer
+	require.NoError(t, json.Unmarshal(response.Payload, &updated))
+	assert.Empty(t, updated.Phone)
+	assert.Equal(t, "pii-key-v2", updated.EncryptedFields["phone"].KeyRef)
+	assert.Equal(t, "pii-key-v1", updated.EncryptedFields["email"].KeyRef)
+
+	stub.Creator = serviceRepIdentity
+	opened = getCustomerWith(t, stub, "enc_9", customer.CustomerID, dataKeys(map[string][]byte{"pii-key-v1": keyV1, "pii-key-v2": keyV2}, ""))
+	assert.Equal(t, newPhone, opened.Phone)
+	assert.Equal(t, "sally.sealed@example.com", opened.Email)
+	stub.Creator = adminIdentity
+
+	// The change history carries no plaintext
+	response = stub.MockInvoke("enc_10", [][]byte{[]byte("GetCustomerHistory"), []byte(customer.CustomerID)})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	assert.NotContains(t, string(response.Payload), newPhone)
+}
+
+func TestFieldEncryptionRejectsMalformedKeys(t *testing.T) {
+	stub := newCustomerStub(t)
+
+	reqBytes, _ := json.Marshal(domain.CustomerRegistrationRequest{
+		FirstName:   "Kim",
+		LastName:    "Shortkey",
+		Email:       "kim@example.com",
+		DateOfBirth: time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC),
+		NationalID:  "ENCTEST002",
+		ActorID:     "ACTOR_TEST",
+	})
+
+	// The active key must be supplied and be an AES-256 key
+	stub.TransientMap = dataKeys(nil, "pii-key-v1")
+	response := stub.MockInvoke("enc_bad_1", [][]byte{[]byte("RegisterCustomer"), reqBytes})
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	assert.Contains(t, response.Message, "not supplied")
+
+	stub.TransientMap = dataKeys(map[string][]byte{"pii-key-v1": []byte("too-short")}, "pii-key-v1")
+	response = stub.MockInvoke("enc_bad_2", [][]byte{[]byte("RegisterCustomer"), reqBytes})
+	stub.TransientMap = nil
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	assert.Contains(t, response.Message, "must be 32 bytes")
+
+	// Without a key the customer is stored as before
+	customer := registerCustomerRequest(t, stub, "enc_bad_3", domain.CustomerRegistrationRequest{
+		FirstName:   "Kim",
+		LastName:    "Shortkey",
+		Email:       "kim@example.com",
+		DateOfBirth: time.Date(1980, 1, 1, 0, 0, 0, 0, time.UTC),
+		NationalID:  "ENCTEST002",
+		ActorID:     "ACTOR_TEST",
+	})
+	assert.Equal(t, "kim@example.com", customer.Email)
+	assert.Empty(t, customer.EncryptedFields)
+}
@@ -0,0 +1,151 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-protos-go/ledger/queryresult"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/handlers"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+func TestGetCustomerHistoryReconcilesWithLedger(t *testing.T) {
+	clock := services.NewFixedClock(time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC))
+	defer services.SetClock(clock)()
+	stub := &historyStub{MockStub: newCustomerStub(t), history: map[string][]*queryresult.KeyModification{}}
+	handler := handlers.NewCustomerHandler()
+
+	customer := registerSearchCustomer(t, stub.MockStub, "recon1", "Rita", "Recon", "rita.recon@example.com")
+	customerKey := "CUSTOMER_" + customer.CustomerID
+	now, err := services.TxTime(stub)
+	require.NoError(t, err)
+	stub.capture("recon1", now, customerKey)
+
+	clock.Advance(time.Hour)
+	phone := "+1555000333"
+	updateBytes, err := json.Marshal(domain.CustomerUpdateRequest{CustomerID: customer.CustomerID, Phone: &phone, ActorID: "ACTOR_001"})
+	require.NoError(t, err)
+	response := stub.MockInvoke("recon2", [][]byte{[]byte("UpdateCustomer"), updateBytes})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	now, err = services.TxTime(stub)
+	require.NoError(t, err)
+	stub.capture("recon2", now, customerKey)
+
+	history := func(txID string, args ...string) ([]byte, error) {
+		stub.MockTransactionStart(txID)
+		defer stub.MockTransactionEnd(txID)
+		return handler.GetCustomerHistory(stub, append([]string{customer.CustomerID}, args...))
+	}
+	reconcile := func(txID string) *services.HistoryReconciliation {
+		reconciliationBytes, err := history(txID, services.HistoryModeReconcile)
+		require.NoError(t, err)
+		var reconciliation services.HistoryReconciliation
+		require.NoError(t, json.Unmarshal(reconciliationBytes, &reconciliation))
+		return &reconciliation
+	}
+
+	// The default mode is unchanged
+	entriesBytes, err := history("recon3")
+	require.NoError(t, err)
+	var entries []map[string]interface{}
+	require.NoError(t, json.Unmarshal(entriesBytes, &entries))
+	assert.Len(t, entries, 2)
+
+	_, err = history("recon4", "LEDGER")
+	assert.Contains(t, err.Error(), "invalid history mode")
+
+	// Every write to the record was recorded, in the same transaction
+	reconciliation := reconcile("recon5")
+	assert.True(t, reconciliation.Consistent)
+	assert.Empty(t, reconciliation.Warnings)
+	assert.Equal(t, customerKey, reconciliation.RecordKey)
+	assert.Equal(t, 2, reconciliation.LedgerVersions)
+	assert.Equal(t, 2, reconciliation.HistoryEntries)
+	require.Len(t, reconciliation.Entries, 2)
+	assert.Equal(t, "recon1", reconciliation.Entries[0].TransactionID)
+	assert.True(t, reconciliation.Entries[0].LedgerWrite)
+	assert.Len(t, reconciliation.Entries[0].Changes, 1)
+	assert.Equal(t, "recon2", reconciliation.Entries[1].TransactionID)
+
+	// A write that bypassed the history, and history whose transaction never wrote the record
+	clock.Advance(time.Hour)
+	stub.MockTransactionStart("recon6")
+	var stored map[string]interface{}
+	require.NoError(t, json.Unmarshal(stub.State[customerKey], &stored))
+	stored["status"] = "SUSPENDED"
+	storedBytes, err := json.Marshal(stored)
+	require.NoError(t, err)
+	require.NoError(t, stub.PutState(customerKey, storedBytes))
+	stub.MockTransactionEnd("recon6")
+	now, err = services.TxTime(stub)
+	require.NoError(t, err)
+	stub.capture("recon6", now, customerKey)
+
+	stub.MockTransactionStart("recon7")
+	orphanKey, err := stub.CreateCompositeKey("HISTORY", []string{customer.CustomerID, "HISTORY_ORPHAN"})
+	require.NoError(t, err)
+	orphanBytes, err := json.Marshal(map[string]interface{}{"historyID": "HISTORY_ORPHAN", "entityID": customer.CustomerID, "fieldName": "email", "transactionID": "recon7", "timestamp": now.Format(time.RFC3339)})
+	require.NoError(t, err)
+	require.NoError(t, stub.PutState(orphanKey, orphanBytes))
+	stub.MockTransactionEnd("recon7")
+
+	reconciliation = reconcile("recon8")
+	assert.False(t, reconciliation.Consistent)
+	assert.Equal(t, 3, reconciliation.LedgerVersions)
+	assert.Equal(t, 3, reconciliation.HistoryEntries)
+	require.Len(t, reconciliation.Warnings, 2)
+	warnings := map[string]services.HistoryIntegrityWarning{}
+	for _, warning := range reconciliation.Warnings {
+		warnings[warning.Type] = warning
+	}
+	assert.Equal(t, "recon6", warnings[services.HistoryWarningUnrecordedChange].TransactionID)
+	assert.Equal(t, "HISTORY_ORPHAN", warnings[services.HistoryWarningOrphanedEntry].HistoryID)
+	require.Len(t, reconciliation.Entries, 4)
+	for _, entry := range reconciliation.Entries {
+		if entry.TransactionID == "recon7" {
+			assert.False(t, entry.LedgerWrite)
+			assert.Len(t, entry.Changes, 1)
+		}
+	}
+}
+
+func TestGetCustomerHistoryReturnsRecordedOrder(t *testing.T) {
+	clock := services.NewFixedClock(time.Date(2026, 6, 2, 9, 0, 0, 0, time.UTC))
+	defer services.SetClock(clock)()
+	stub := newCustomerStub(t)
+	customer := registerSearchCustomer(t, stub, "order1", "Otto", "Order", "otto.order@example.com")
+
+	phones := []string{"+1555000101", "+1555000102", "+1555000103", "+1555000104"}
+	for i, phone := range phones {
+		clock.Advance(time.Hour)
+		updateBytes, err := json.Marshal(domain.CustomerUpdateRequest{CustomerID: customer.CustomerID, Phone: &phones[i], ActorID: "ACTOR_001"})
+		require.NoError(t, err)
+		response := stub.MockInvoke("order_update_"+phone, [][]byte{[]byte("UpdateCustomer"), updateBytes})
+		require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	}
+
+	stub.MockTransactionStart("order_history")
+	entriesBytes, err := handlers.NewCustomerHandler().GetCustomerHistory(stub, []string{customer.CustomerID})
+	stub.MockTransactionEnd("order_history")
+	require.NoError(t, err)
+	var entries []map[string]interface{}
+	require.NoError(t, json.Unmarshal(entriesBytes, &entries))
+
+	// Entries come back in the order they were recorded, not in the order of their hashed IDs
+	var recorded []string
+	previous := ""
+	for _, entry := range entries {
+		timestamp := entry["timestamp"].(string)
+		assert.GreaterOrEqual(t, timestamp, previous)
+		previous = timestamp
+		if entry["fieldName"] == "phone" {
+			recorded = append(recorded, entry["newValue"].(string))
+		}
+	}
+	assert.Equal(t, phones, recorded)
+}
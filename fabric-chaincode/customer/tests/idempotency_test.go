@@ -0,0 +1,61 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+)
+
+func TestRegisterCustomerReplaysIdempotencyKey(t *testing.T) {
+	stub := newCustomerStub(t)
+
+	req := domain.CustomerRegistrationRequest{
+		FirstName:          "Retry",
+		LastName:           "Customer",
+		Email:              "retry@example.com",
+		Phone:              "+1234567890",
+		DateOfBirth:        time.Date(1985, 6, 1, 0, 0, 0, 0, time.UTC),
+		NationalID:         "RETRY001",
+		Address:            "1 Retry Road, Test City, Test Country",
+		ConsentPreferences: `{"marketing": false}`,
+		IdempotencyKey:     "client-request-1",
+		ActorID:            "ACTOR_001",
+	}
+	reqBytes, err := json.Marshal(req)
+	require.NoError(t, err)
+
+	response := stub.MockInvoke("retry_1", [][]byte{[]byte("RegisterCustomer"), reqBytes})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	var original domain.Customer
+	require.NoError(t, json.Unmarshal(response.Payload, &original))
+
+	// The retry returns the original customer rather than failing on the duplicate national ID
+	response = stub.MockInvoke("retry_2", [][]byte{[]byte("RegisterCustomer"), reqBytes})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	var replayed domain.Customer
+	require.NoError(t, json.Unmarshal(response.Payload, &replayed))
+	assert.Equal(t, original.CustomerID, replayed.CustomerID)
+	assert.True(t, replayed.CreatedDate.Equal(original.CreatedDate))
+
+	// Reusing the key for a different request is rejected
+	req.NationalID = "RETRY002"
+	reqBytes, err = json.Marshal(req)
+	require.NoError(t, err)
+	response = stub.MockInvoke("retry_3", [][]byte{[]byte("RegisterCustomer"), reqBytes})
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	assert.Contains(t, response.Message, "already used for a different RegisterCustomer request")
+
+	// Without a key a resubmission is still a duplicate
+	req.NationalID = "RETRY001"
+	req.IdempotencyKey = ""
+	reqBytes, err = json.Marshal(req)
+	require.NoError(t, err)
+	response = stub.MockInvoke("retry_4", [][]byte{[]byte("RegisterCustomer"), reqBytes})
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	assert.Contains(t, response.Message, "already exists")
+}
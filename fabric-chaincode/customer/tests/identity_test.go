@@ -0,0 +1,147 @@
+package tests
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/json"
+	"encoding/pem"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/chaincode"
+	sharedChaincode "github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/chaincode"
+)
+
+// testActorIDs are the actors the customer tests act as, all bound to the test identity
+var testActorIDs = []string{
+	"ACTOR_001", "ACTOR_002", "ACTOR_003", "ACTOR_004", "ACTOR_005", "ACTOR_TEST", "ACTOR_OPS",
+	"ACTOR_KYC_001", "ACTOR_KYC_002", "ACTOR_AML_001", "ACTOR_AML_002",
+}
+
+// attrsExtensionOID is the certificate extension Fabric CA stores identity attributes under
+var attrsExtensionOID = asn1.ObjectIdentifier{1, 2, 3, 4, 5, 6, 7, 8, 1}
+
+// newTestIdentity returns a serialized identity whose certificate carries the given role attribute
+func newTestIdentity(t *testing.T, mspID, commonName, role string) []byte {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	attrs, err := json.Marshal(map[string]map[string]string{"attrs": {"role": role}})
+	require.NoError(t, err)
+
+	template := &x509.Certificate{
+		SerialNumber:    big.NewInt(time.Now().UnixNano()),
+		Subject:         pkix.Name{CommonName: commonName},
+		NotBefore:       time.Now().Add(-time.Hour),
+		NotAfter:        time.Now().Add(time.Hour),
+		ExtraExtensions: []pkix.Extension{{Id: attrsExtensionOID, Value: attrs}},
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+
+	identity, err := proto.Marshal(&msp.SerializedIdentity{
+		Mspid:   mspID,
+		IdBytes: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}),
+	})
+	require.NoError(t, err)
+	return identity
+}
+
+// registerTestActor binds an actor to the stub's current creator
+func registerTestActor(t *testing.T, stub *shimtest.MockStub, txID, actorID, role string) {
+	response := stub.MockInvoke(txID+"_whoami", [][]byte{[]byte("GetInvokerIdentity")})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+
+	var invoker sharedChaincode.InvokerIdentity
+	require.NoError(t, json.Unmarshal(response.Payload, &invoker))
+
+	reqBytes, err := json.Marshal(sharedChaincode.ActorRegistrationRequest{
+		RegisteredActorID:  actorID,
+		BlockchainIdentity: invoker.BlockchainIdentity,
+		MSPID:              invoker.MSPID,
+		Role:               role,
+		Active:             true,
+		ActorID:            "ACTOR_ADMIN",
+	})
+	require.NoError(t, err)
+
+	response = stub.MockInvoke(txID, [][]byte{[]byte("RegisterActor"), reqBytes})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+}
+
+// newCustomerStub returns a customer chaincode stub invoked by an administrator identity that
+// every test actor is registered to
+func newCustomerStub(t *testing.T) *shimtest.MockStub {
+	stub := shimtest.NewMockStub("customer", &chaincode.CustomerContract{})
+	stub.Creator = newTestIdentity(t, "Org1MSP", "admin", "System_Administrator")
+	stub.MockPeerChaincode("compliance", shimtest.NewMockStub("compliance", &fakeComplianceChaincode{}), "")
+
+	for i, actorID := range testActorIDs {
+		registerTestActor(t, stub, fmt.Sprintf("register_%d", i), actorID, "System_Administrator")
+	}
+	return stub
+}
+
+func TestActorBindingRejectsSpoofedActor(t *testing.T) {
+	stub := newCustomerStub(t)
+	adminIdentity := stub.Creator
+
+	// A second identity registered as its own actor cannot act as one bound to the admin
+	tellerIdentity := newTestIdentity(t, "Org1MSP", "teller", "Customer_Service_Rep")
+	stub.Creator = tellerIdentity
+	response := stub.MockInvoke("1", [][]byte{[]byte("GetInvokerIdentity")})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	var teller sharedChaincode.InvokerIdentity
+	require.NoError(t, json.Unmarshal(response.Payload, &teller))
+	assert.Equal(t, "Customer_Service_Rep", teller.Role)
+
+	stub.Creator = adminIdentity
+	reqBytes, err := json.Marshal(sharedChaincode.ActorRegistrationRequest{
+		RegisteredActorID:  "ACTOR_TELLER",
+		BlockchainIdentity: teller.BlockchainIdentity,
+		MSPID:              teller.MSPID,
+		Role:               "Customer_Service_Rep",
+		Active:             true,
+		ActorID:            "ACTOR_001",
+	})
+	require.NoError(t, err)
+	response = stub.MockInvoke("2", [][]byte{[]byte("RegisterActor"), reqBytes})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+
+	stub.Creator = tellerIdentity
+	response = stub.MockInvoke("3", [][]byte{[]byte("RegisterActor"), reqBytes})
+	assert.Equal(t, int32(shim.ERROR), response.Status, "only administrators may register actors")
+
+	registerBytes, err := json.Marshal(map[string]string{
+		"firstName":   "Eve",
+		"lastName":    "Spoof",
+		"email":       "eve@example.com",
+		"phone":       "+1234567890",
+		"dateOfBirth": "1990-01-01T00:00:00Z",
+		"nationalID":  "ID123456789",
+		"address":     "1 Main Street, City, Country",
+		"actorID":     "ACTOR_001",
+	})
+	require.NoError(t, err)
+	response = stub.MockInvoke("4", [][]byte{[]byte("RegisterCustomer"), registerBytes})
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	assert.Contains(t, response.Message, "not bound to actor ACTOR_001")
+
+	unregisteredBytes, err := json.Marshal(map[string]string{"customerID": "CUST_1", "actorID": "ACTOR_UNKNOWN"})
+	require.NoError(t, err)
+	response = stub.MockInvoke("5", [][]byte{[]byte("UpdateCustomer"), unregisteredBytes})
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	assert.Contains(t, response.Message, "actor ACTOR_UNKNOWN is not registered")
+}
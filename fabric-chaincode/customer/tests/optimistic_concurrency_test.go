@@ -0,0 +1,141 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+func TestStaleExpectedVersionRejectedWithConflict(t *testing.T) {
+	stub := newCustomerStub(t)
+
+	reqBytes, _ := json.Marshal(domain.CustomerRegistrationRequest{
+		FirstName:          "Nora",
+		LastName:           "Vance",
+		Email:              "nora.vance@example.com",
+		Phone:              "+1222333444",
+		DateOfBirth:        time.Date(1988, 2, 2, 0, 0, 0, 0, time.UTC),
+		NationalID:         "ID222333444",
+		Address:            "9 Elm Street, City, Country",
+		ConsentPreferences: `{"marketing": false}`,
+		ActorID:            "ACTOR_001",
+	})
+	response := stub.MockInvoke("1", [][]byte{[]byte("RegisterCustomer"), reqBytes})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+
+	var customer domain.Customer
+	require.NoError(t, json.Unmarshal(response.Payload, &customer))
+	assert.Equal(t, 1, customer.Version)
+
+	update := func(txID string, expectedVersion int, phone string) (int32, string, domain.Customer) {
+		updateBytes, _ := json.Marshal(domain.CustomerUpdateRequest{
+			CustomerID:      customer.CustomerID,
+			Phone:           &phone,
+			ExpectedVersion: &expectedVersion,
+			ActorID:         "ACTOR_001",
+		})
+		response := stub.MockInvoke(txID, [][]byte{[]byte("UpdateCustomer"), updateBytes})
+		var updated domain.Customer
+		if response.Status == shim.OK {
+			require.NoError(t, json.Unmarshal(response.Payload, &updated))
+		}
+		return response.Status, response.Message, updated
+	}
+
+	// Two clients read version 1; the first update wins and advances the version
+	status, message, updated := update("2", 1, "+1555000111")
+	require.Equal(t, int32(shim.OK), status, message)
+	assert.Equal(t, 2, updated.Version)
+
+	// The second update was made against the stale read and is refused
+	status, message, _ = update("3", 1, "+1555000222")
+	assert.NotEqual(t, int32(shim.OK), status)
+	assert.Contains(t, message, "CONFLICT")
+	assert.Contains(t, message, `"currentVersion":2`)
+
+	// Retrying against the current version succeeds
+	status, message, updated = update("4", 2, "+1555000222")
+	require.Equal(t, int32(shim.OK), status, message)
+	assert.Equal(t, "+1555000222", updated.Phone)
+	assert.Equal(t, 3, updated.Version)
+}
+
+func TestStaleKYCStatusUpdateRejectedWithConflict(t *testing.T) {
+	stub := newCustomerStub(t)
+	customer := createTestCustomer(t, stub, "KYCVERSION001")
+
+	kycBytes, _ := json.Marshal(domain.KYCInitiationRequest{CustomerID: customer.CustomerID, DocumentHashes: []string{"hash1"}, ActorID: "ACTOR_001"})
+	response := stub.MockInvoke("2", [][]byte{[]byte("InitiateKYC"), kycBytes})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	var kycRecord domain.KYCRecord
+	require.NoError(t, json.Unmarshal(response.Payload, &kycRecord))
+	assert.Equal(t, 1, kycRecord.Version)
+
+	update := func(txID string, expectedVersion int, status validation.KYCStatus) (int32, string) {
+		updateBytes, _ := json.Marshal(domain.KYCStatusUpdateRequest{
+			KYCID:           kycRecord.KYCID,
+			NewStatus:       status,
+			ExpectedVersion: &expectedVersion,
+			ActorID:         "ACTOR_001",
+		})
+		response := stub.MockInvoke(txID, [][]byte{[]byte("UpdateKYCStatus"), updateBytes})
+		return response.Status, response.Message
+	}
+
+	// A reviewer fails the record; a second reviewer's verification, made against the same read, is refused
+	status, message := update("3", 1, validation.KYCStatusFailed)
+	require.Equal(t, int32(shim.OK), status, message)
+	status, message = update("4", 1, validation.KYCStatusVerified)
+	assert.NotEqual(t, int32(shim.OK), status)
+	assert.Contains(t, message, "CONFLICT")
+	assert.Contains(t, message, `"currentVersion":2`)
+
+	response = stub.MockInvoke("5", [][]byte{[]byte("GetKYCRecord"), []byte(kycRecord.KYCID)})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	require.NoError(t, json.Unmarshal(response.Payload, &kycRecord))
+	assert.Equal(t, validation.KYCStatusFailed, kycRecord.Status)
+	assert.Equal(t, 2, kycRecord.Version)
+}
+
+func TestStaleAMLStatusUpdateRejectedWithConflict(t *testing.T) {
+	stub := newCustomerStub(t)
+	customer := createTestCustomer(t, stub, "AMLVERSION001")
+
+	amlBytes, _ := json.Marshal(domain.AMLCheckRequest{CustomerID: customer.CustomerID, ActorID: "ACTOR_001"})
+	response := stub.MockInvoke("2", [][]byte{[]byte("InitiateAMLCheck"), amlBytes})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	var amlRecord domain.AMLRecord
+	require.NoError(t, json.Unmarshal(response.Payload, &amlRecord))
+	assert.Equal(t, 1, amlRecord.Version)
+
+	update := func(txID string, expectedVersion int, status validation.AMLStatus) (int32, string) {
+		updateBytes, _ := json.Marshal(domain.AMLStatusUpdateRequest{
+			AMLID:           amlRecord.AMLID,
+			NewStatus:       status,
+			RiskScore:       40,
+			Flags:           []string{},
+			ExpectedVersion: &expectedVersion,
+			ActorID:         "ACTOR_001",
+		})
+		response := stub.MockInvoke(txID, [][]byte{[]byte("UpdateAMLStatus"), updateBytes})
+		return response.Status, response.Message
+	}
+
+	// An analyst flags the check; a clearance made against the earlier read is refused
+	status, message := update("3", 1, validation.AMLStatusFlagged)
+	require.Equal(t, int32(shim.OK), status, message)
+	status, message = update("4", 1, validation.AMLStatusClear)
+	assert.NotEqual(t, int32(shim.OK), status)
+	assert.Contains(t, message, "CONFLICT")
+	assert.Contains(t, message, `"currentVersion":2`)
+
+	// Retrying against the current version succeeds
+	status, message = update("5", 2, validation.AMLStatusClear)
+	require.Equal(t, int32(shim.OK), status, message)
+}
@@ -0,0 +1,96 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	sharedChaincode "github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/chaincode"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+func TestRequestPayloadsArchivedByTransaction(t *testing.T) {
+	stub := newCustomerStub(t)
+
+	policyBytes, err := json.Marshal(sharedChaincode.PayloadArchivePolicyRequest{
+		FunctionName:  "RegisterCustomer",
+		RetainPayload: true,
+		ActorID:       "ACTOR_001",
+	})
+	require.NoError(t, err)
+	response := stub.MockInvoke("archive_1", [][]byte{[]byte("SetPayloadArchivePolicy"), policyBytes})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	policyBytes, err = json.Marshal(sharedChaincode.PayloadArchivePolicyRequest{
+		FunctionName:  "GetCustomer",
+		RetainPayload: true,
+		ActorID:       "ACTOR_001",
+	})
+	require.NoError(t, err)
+	response = stub.MockInvoke("archive_1b", [][]byte{[]byte("SetPayloadArchivePolicy"), policyBytes})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+
+	registrationBytes, err := json.Marshal(domain.CustomerRegistrationRequest{
+		FirstName:          "Archive",
+		LastName:           "Customer",
+		Email:              "archive@example.com",
+		Phone:              "+1234567890",
+		DateOfBirth:        time.Date(1985, 6, 1, 0, 0, 0, 0, time.UTC),
+		NationalID:         "ARCHIVE001",
+		Address:            "1 Archive Street, Test City, Test Country",
+		ConsentPreferences: `{"marketing": false}`,
+		ActorID:            "ACTOR_TEST",
+	})
+	require.NoError(t, err)
+	response = stub.MockInvoke("archive_2", [][]byte{[]byte("RegisterCustomer"), registrationBytes})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	var customer domain.Customer
+	require.NoError(t, json.Unmarshal(response.Payload, &customer))
+
+	// Flagged functions carrying no PII have their arguments retained
+	response = stub.MockInvoke("archive_3", [][]byte{[]byte("GetCustomer"), []byte(customer.CustomerID)})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+
+	// Unflagged functions only have their hash archived
+	response = stub.MockInvoke("archive_3b", [][]byte{[]byte("GetCustomerHistory"), []byte(customer.CustomerID)})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+
+	// Archived requests are restricted to compliance investigators and the regulator
+	response = stub.MockInvoke("archive_4", [][]byte{[]byte("GetArchivedPayload"), []byte("archive_2")})
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	assert.Contains(t, response.Message, "may only be retrieved by")
+
+	stub.Creator = newTestIdentity(t, "Org1MSP", "investigator", "Compliance_Officer")
+	response = stub.MockInvoke("archive_5", [][]byte{[]byte("GetArchivedPayload"), []byte("archive_2")})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	var archived services.ArchivedPayload
+	require.NoError(t, json.Unmarshal(response.Payload, &archived))
+	assert.Equal(t, "RegisterCustomer", archived.FunctionName)
+	assert.Equal(t, "ACTOR_TEST", archived.ActorID)
+	// The registration carries PII, so only its hash is kept despite the policy
+	assert.Empty(t, archived.Payload)
+	assert.True(t, archived.PayloadWithheld)
+	expectedHash, err := services.PayloadHash("RegisterCustomer", []string{string(registrationBytes)})
+	require.NoError(t, err)
+	assert.Equal(t, expectedHash, archived.PayloadHash)
+
+	response = stub.MockInvoke("archive_6", [][]byte{[]byte("GetArchivedPayload"), []byte("archive_3")})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	var retained services.ArchivedPayload
+	require.NoError(t, json.Unmarshal(response.Payload, &retained))
+	assert.Equal(t, "GetCustomer", retained.FunctionName)
+	assert.Equal(t, []string{customer.CustomerID}, retained.Payload)
+	assert.False(t, retained.PayloadWithheld)
+
+	response = stub.MockInvoke("archive_7", [][]byte{[]byte("GetArchivedPayload"), []byte("archive_3b")})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	var hashOnly services.ArchivedPayload
+	require.NoError(t, json.Unmarshal(response.Payload, &hashOnly))
+	assert.Equal(t, "GetCustomerHistory", hashOnly.FunctionName)
+	assert.Empty(t, hashOnly.Payload)
+	assert.False(t, hashOnly.PayloadWithheld)
+	assert.NotEmpty(t, hashOnly.PayloadHash)
+}
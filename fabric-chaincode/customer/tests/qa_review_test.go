@@ -0,0 +1,117 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	sharedChaincode "github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/chaincode"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+func TestScreeningClearancesSampledForQAReview(t *testing.T) {
+	stub := newCustomerStub(t)
+	customer := createTestCustomer(t, stub, "QAREVIEW001")
+	adminIdentity := stub.Creator
+
+	invoke := func(txID, function string, req interface{}) ([]byte, string) {
+		reqBytes, err := json.Marshal(req)
+		require.NoError(t, err)
+		response := stub.MockInvoke(txID, [][]byte{[]byte(function), reqBytes})
+		if response.Status != shim.OK {
+			return nil, response.Message
+		}
+		return response.Payload, ""
+	}
+
+	payload, message := invoke("qa_1", "InitiateAMLCheck", domain.AMLCheckRequest{CustomerID: customer.CustomerID, ActorID: "ACTOR_AML_001"})
+	require.Empty(t, message)
+	var amlRecord domain.AMLRecord
+	require.NoError(t, json.Unmarshal(payload, &amlRecord))
+
+	// Bind a compliance reviewer to its own identity
+	reviewerIdentity := newTestIdentity(t, "Org1MSP", "reviewer", "Chief_Compliance_Officer")
+	stub.Creator = reviewerIdentity
+	response := stub.MockInvoke("qa_2", [][]byte{[]byte("GetInvokerIdentity")})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	var reviewer sharedChaincode.InvokerIdentity
+	require.NoError(t, json.Unmarshal(response.Payload, &reviewer))
+
+	stub.Creator = adminIdentity
+	_, message = invoke("qa_3", "RegisterActor", sharedChaincode.ActorRegistrationRequest{
+		RegisteredActorID:  "ACTOR_QA",
+		BlockchainIdentity: reviewer.BlockchainIdentity,
+		MSPID:              reviewer.MSPID,
+		Role:               "Chief_Compliance_Officer",
+		Active:             true,
+		ActorID:            "ACTOR_001",
+	})
+	require.Empty(t, message)
+
+	// Only compliance leadership or loan operations set sampling rates
+	rateReq := sharedChaincode.QASamplingRateRequest{DecisionType: "SCREENING_CLEARANCE", Rate: 1, ActorID: "ACTOR_001"}
+	_, message = invoke("qa_4", "SetQASamplingRate", rateReq)
+	assert.Contains(t, message, "may only be set by")
+
+	stub.Creator = reviewerIdentity
+	rateReq.ActorID = "ACTOR_QA"
+	_, message = invoke("qa_5", "SetQASamplingRate", rateReq)
+	require.Empty(t, message)
+
+	// Flagging is not a clearance; clearing the flag is
+	stub.Creator = adminIdentity
+	statusReq := domain.AMLStatusUpdateRequest{AMLID: amlRecord.AMLID, NewStatus: validation.AMLStatusFlagged, RiskScore: 60, Notes: "Name match", ActorID: "ACTOR_AML_001"}
+	_, message = invoke("qa_6", "UpdateAMLStatus", statusReq)
+	require.Empty(t, message)
+	statusReq.NewStatus = validation.AMLStatusClear
+	statusReq.RiskScore = 10
+	statusReq.Notes = "False positive"
+	_, message = invoke("qa_7", "UpdateAMLStatus", statusReq)
+	require.Empty(t, message)
+
+	stub.Creator = reviewerIdentity
+	response = stub.MockInvoke("qa_8", [][]byte{[]byte("GetQAReviewQueue"), []byte("SCREENING_CLEARANCE")})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	var queue sharedChaincode.QAReviewQueueResult
+	require.NoError(t, json.Unmarshal(response.Payload, &queue))
+	require.Equal(t, 1, queue.Count)
+	item := queue.Items[0]
+	assert.Equal(t, amlRecord.AMLID, item.EntityID)
+	assert.Equal(t, "ACTOR_AML_001", item.DecidedBy)
+	assert.Equal(t, "System_Administrator", item.Team)
+
+	// Defects need a code
+	reviewReq := sharedChaincode.QAReviewRequest{ItemID: item.ItemID, Outcome: "DEFECT", Findings: "Match dismissed without evidence", ActorID: "ACTOR_QA"}
+	_, message = invoke("qa_9", "RecordQAReview", reviewReq)
+	assert.Contains(t, message, "at least one defect code")
+
+	reviewReq.DefectCodes = []string{"MISSING_RATIONALE"}
+	payload, message = invoke("qa_10", "RecordQAReview", reviewReq)
+	require.Empty(t, message)
+	var reviewed services.QAReviewItem
+	require.NoError(t, json.Unmarshal(payload, &reviewed))
+	assert.Equal(t, services.QAReviewItemReviewed, reviewed.Status)
+
+	_, message = invoke("qa_11", "RecordQAReview", reviewReq)
+	assert.Contains(t, message, "already REVIEWED")
+
+	response = stub.MockInvoke("qa_12", [][]byte{[]byte("GetQAReviewQueue"), []byte("SCREENING_CLEARANCE")})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	require.NoError(t, json.Unmarshal(response.Payload, &queue))
+	assert.Equal(t, 0, queue.Count)
+
+	today := time.Now().UTC().Format("2006-01-02")
+	response = stub.MockInvoke("qa_13", [][]byte{[]byte("GetQADefectReport"), []byte(today), []byte(today)})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	var report sharedChaincode.QADefectReport
+	require.NoError(t, json.Unmarshal(response.Payload, &report))
+	require.Contains(t, report.ByTeam, "System_Administrator")
+	assert.Equal(t, 1, report.ByTeam["System_Administrator"].Defects)
+	assert.Equal(t, 100.0, report.ByTeam["System_Administrator"].DefectRate)
+	assert.Equal(t, 1, report.ByDecisionType["SCREENING_CLEARANCE"].DefectCodes["MISSING_RATIONALE"])
+}
@@ -0,0 +1,118 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+func TestPartnerRequestSignaturesAreRecordedAsEvidence(t *testing.T) {
+	now := time.Date(2026, 5, 4, 12, 0, 0, 0, time.UTC)
+	defer services.SetClock(services.NewFixedClock(now))()
+
+	stub := newCustomerStub(t)
+	adminIdentity := stub.Creator
+	customer := registerSearchCustomer(t, stub, "sig1", "Pia", "Partner", "pia@example.com")
+	officerIdentity := bindRoleActor(t, stub, "sig_2", "ACTOR_SIG", "Compliance_Officer")
+	introducerIdentity := newTestIdentity(t, "Org1MSP", "ACTOR_INTRO", "Introducer")
+
+	stub.Creator = officerIdentity
+	agreementBytes, err := json.Marshal(domain.DataSharingAgreementRequest{
+		PartnerMSP:  "Org3MSP",
+		PartnerName: "Partner Insurer",
+		Clauses:     []domain.DataSharingClause{{ClauseID: "2.1", Purpose: "marketing", DataCategories: []string{"contact"}}},
+		ActorID:     "ACTOR_SIG",
+	})
+	require.NoError(t, err)
+	response := stub.MockInvoke("sig_4", [][]byte{[]byte("CreateDataSharingAgreement"), agreementBytes})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	var agreement domain.DataSharingAgreement
+	require.NoError(t, json.Unmarshal(response.Payload, &agreement))
+
+	disclosureBytes, err := json.Marshal(domain.DisclosureRequest{
+		CustomerID:     customer.CustomerID,
+		AgreementID:    agreement.AgreementID,
+		ClauseID:       "2.1",
+		DataCategories: []string{"contact"},
+		ActorID:        "ACTOR_001",
+	})
+	require.NoError(t, err)
+	payloadHash, err := services.PayloadHash("RecordDisclosure", []string{string(disclosureBytes)})
+	require.NoError(t, err)
+
+	signature := services.RequestSignature{
+		PartnerID:   "PARTNER_INSURER",
+		KeyID:       "insurer-2026-05",
+		Algorithm:   services.RequestSignatureHMAC,
+		Signature:   "9f2c4e0d1b7a",
+		Method:      "POST",
+		Path:        "/api/v1/partners/disclosures",
+		Timestamp:   now.Add(-30 * time.Second).Format(time.RFC3339),
+		Nonce:       "n-5518",
+		BodyHash:    "5d41402abc4b2a76b9719d911017c592",
+		PayloadHash: payloadHash,
+	}
+	recordDisclosure := func(txID string, signature *services.RequestSignature) ([]byte, string) {
+		stub.TransientMap = nil
+		if signature != nil {
+			signatureBytes, err := json.Marshal(signature)
+			require.NoError(t, err)
+			stub.TransientMap = map[string][]byte{config.RequestSignatureTransientKey: signatureBytes}
+		}
+		defer func() { stub.TransientMap = nil }()
+
+		response := stub.MockInvoke(txID, [][]byte{[]byte("RecordDisclosure"), disclosureBytes})
+		if response.Status != shim.OK {
+			return nil, response.Message
+		}
+		return response.Payload, ""
+	}
+
+	// The signature evidence is kept on the disclosure and the archived request
+	stub.Creator = adminIdentity
+	payload, message := recordDisclosure("sig_5", &signature)
+	require.Empty(t, message)
+	var disclosure domain.DisclosureRecord
+	require.NoError(t, json.Unmarshal(payload, &disclosure))
+	require.NotNil(t, disclosure.RequestSignature)
+	assert.Equal(t, "PARTNER_INSURER", disclosure.RequestSignature.PartnerID)
+	assert.Equal(t, "9f2c4e0d1b7a", disclosure.RequestSignature.Signature)
+
+	stub.Creator = officerIdentity
+	response = stub.MockInvoke("sig_6", [][]byte{[]byte("GetArchivedPayload"), []byte("sig_5")})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	var archived services.ArchivedPayload
+	require.NoError(t, json.Unmarshal(response.Payload, &archived))
+	require.NotNil(t, archived.RequestSignature)
+	assert.Equal(t, payloadHash, archived.RequestSignature.PayloadHash)
+
+	// Evidence for another payload, signed too long ago or incomplete is rejected
+	stub.Creator = adminIdentity
+	otherPayload := signature
+	otherPayload.PayloadHash = "0000"
+	_, message = recordDisclosure("sig_7", &otherPayload)
+	assert.Contains(t, message, "different payload")
+
+	stale := signature
+	stale.Timestamp = now.Add(-config.TransactionTimeout - time.Minute).Format(time.RFC3339)
+	_, message = recordDisclosure("sig_8", &stale)
+	assert.Contains(t, message, "from the transaction time")
+
+	unsigned := signature
+	unsigned.Signature = ""
+	_, message = recordDisclosure("sig_9", &unsigned)
+	assert.Contains(t, message, "missing signature")
+
+	// Requests from partner identities must be signed
+	stub.Creator = introducerIdentity
+	response = stub.MockInvoke("sig_10", [][]byte{[]byte("GetCustomer"), []byte(customer.CustomerID)})
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	assert.Contains(t, response.Message, "must carry a request signature")
+}
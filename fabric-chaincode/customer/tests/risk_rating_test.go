@@ -0,0 +1,146 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/interfaces"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+)
+
+// fakeLoanChaincode stands in for the loan chaincode's customer holdings summary and loan milestones
+type fakeLoanChaincode struct {
+	holdings   map[string]interfaces.CustomerHoldings
+	milestones map[string][]interfaces.CustomerLoanMilestone
+	loans      map[string][]interfaces.CustomerLoanSummary
+}
+
+func (f *fakeLoanChaincode) Init(stub shim.ChaincodeStubInterface) peer.Response {
+	return shim.Success(nil)
+}
+
+func (f *fakeLoanChaincode) Invoke(stub shim.ChaincodeStubInterface) peer.Response {
+	function, args := stub.GetFunctionAndParameters()
+	if function == "GetCustomerLoanMilestones" {
+		milestonesBytes, _ := json.Marshal(f.milestones[args[0]])
+		return shim.Success(milestonesBytes)
+	}
+	if function == "QueryLoansByCustomer" {
+		loansBytes, _ := json.Marshal(map[string]interface{}{"loans": f.loans[args[0]], "bookmark": ""})
+		return shim.Success(loansBytes)
+	}
+	if function != "GetCustomerHoldings" {
+		return shim.Error("unknown function " + function)
+	}
+
+	holdings, ok := f.holdings[args[0]]
+	if !ok {
+		holdings = interfaces.CustomerHoldings{CustomerID: args[0], LoanTypes: []string{}, WindowDays: config.CustomerRiskTransactionWindowDays}
+	}
+	holdingsBytes, _ := json.Marshal(holdings)
+	return shim.Success(holdingsBytes)
+}
+
+func TestRecalculateCustomerRiskMovesTierAndListsForEDD(t *testing.T) {
+	stub := newCustomerStub(t)
+	loanChaincode := &fakeLoanChaincode{holdings: map[string]interfaces.CustomerHoldings{}}
+	stub.MockPeerChaincode("loan", shimtest.NewMockStub("loan", loanChaincode), "")
+	stub.MockPeerChaincode("compliance", shimtest.NewMockStub("compliance", &fakeComplianceChaincode{
+		pepNames: []string{"Rita Risky"},
+	}), "")
+
+	risky := registerSearchCustomer(t, stub, "risk1", "Rita", "Risky", "rita@example.com")
+	quiet := registerSearchCustomer(t, stub, "risk2", "Quinn", "Quiet", "quinn@example.com")
+
+	recalculate := func(txID, customerID string) domain.CustomerRiskProfile {
+		reqBytes, err := json.Marshal(domain.RiskRecalculationRequest{CustomerID: customerID, ActorID: "ACTOR_001"})
+		require.NoError(t, err)
+		response := stub.MockInvoke(txID, [][]byte{[]byte("RecalculateCustomerRisk"), reqBytes})
+		require.Equal(t, int32(shim.OK), response.Status, response.Message)
+		var profile domain.CustomerRiskProfile
+		require.NoError(t, json.Unmarshal(response.Payload, &profile))
+		return profile
+	}
+	drainEvents := func() []string {
+		names := []string{}
+		for len(stub.ChaincodeEventsChannel) > 0 {
+			names = append(names, (<-stub.ChaincodeEventsChannel).EventName)
+		}
+		return names
+	}
+
+	// Never AML-checked and holding nothing
+	drainEvents()
+	profile := recalculate("risk_3", risky.CustomerID)
+	assert.Equal(t, domain.CustomerRiskLow, profile.RiskTier)
+	assert.Empty(t, profile.PreviousRiskTier)
+	require.Len(t, profile.Drivers, 1)
+	assert.Equal(t, "AML_NOT_CHECKED", profile.Drivers[0].Code)
+	assert.True(t, profile.NextReviewDate.Equal(profile.LastAssessed.AddDate(2, 0, 0)))
+	assert.Contains(t, drainEvents(), config.EventCustomerRiskTierChanged)
+
+	// Recalculating with nothing changed keeps the tier and emits no event
+	recalculate("risk_4", risky.CustomerID)
+	assert.NotContains(t, drainEvents(), config.EventCustomerRiskTierChanged)
+
+	// A PEP match under review and a defaulted loan move the customer to HIGH
+	amlBytes, _ := json.Marshal(domain.AMLCheckRequest{CustomerID: risky.CustomerID, ActorID: "ACTOR_001"})
+	response := stub.MockInvoke("risk_5", [][]byte{[]byte("InitiateAMLCheck"), amlBytes})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	loanChaincode.holdings[risky.CustomerID] = interfaces.CustomerHoldings{
+		CustomerID:          risky.CustomerID,
+		DefaultedLoans:      1,
+		LoanTypes:           []string{"PERSONAL"},
+		OutstandingBalances: utils.CurrencyTotals{"USD": utils.NewMoney(12000, "USD")},
+		WindowDays:          config.CustomerRiskTransactionWindowDays,
+	}
+	drainEvents()
+	profile = recalculate("risk_6", risky.CustomerID)
+	assert.Equal(t, domain.CustomerRiskHigh, profile.RiskTier)
+	assert.Equal(t, domain.CustomerRiskLow, profile.PreviousRiskTier)
+	assert.InDelta(t, 73.0, profile.RiskScore, 0.001) // 95 * 0.4 + 15 + 20
+	codes := []string{}
+	for _, driver := range profile.Drivers {
+		codes = append(codes, driver.Code)
+	}
+	assert.ElementsMatch(t, []string{"AML_RISK_SCORE", "AML_UNDER_REVIEW", "DEFAULTED_LOANS"}, codes)
+	assert.Contains(t, drainEvents(), config.EventCustomerRiskTierChanged)
+
+	// Other chaincodes see the tier in the compliance status; unrated customers have none
+	complianceStatus := func(txID, customerID string) interfaces.CustomerComplianceStatus {
+		response := stub.MockInvoke(txID, [][]byte{[]byte("GetCustomerComplianceStatus"), []byte(customerID)})
+		require.Equal(t, int32(shim.OK), response.Status, response.Message)
+		var status interfaces.CustomerComplianceStatus
+		require.NoError(t, json.Unmarshal(response.Payload, &status))
+		return status
+	}
+	assert.Equal(t, string(domain.CustomerRiskHigh), complianceStatus("risk_status_1", risky.CustomerID).RiskTier)
+	assert.Empty(t, complianceStatus("risk_status_2", quiet.CustomerID).RiskTier)
+
+	recalculate("risk_7", quiet.CustomerID)
+
+	// Only the risky customer is above MEDIUM, and nobody is above HIGH
+	query := func(txID, tier string) domain.RiskProfileQueryResult {
+		response := stub.MockInvoke(txID, [][]byte{[]byte("QueryCustomersAboveRiskTier"), []byte(tier)})
+		require.Equal(t, int32(shim.OK), response.Status, response.Message)
+		var result domain.RiskProfileQueryResult
+		require.NoError(t, json.Unmarshal(response.Payload, &result))
+		return result
+	}
+	result := query("risk_8", "MEDIUM")
+	require.Equal(t, 1, result.Count)
+	assert.Equal(t, risky.CustomerID, result.Profiles[0].CustomerID)
+	assert.Equal(t, 0, query("risk_9", "HIGH").Count)
+	assert.Equal(t, 1, query("risk_10", "LOW").Count) // The quiet customer is rated LOW
+
+	response = stub.MockInvoke("risk_11", [][]byte{[]byte("QueryCustomersAboveRiskTier"), []byte("EXTREME")})
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	assert.Contains(t, response.Message, "invalid risk tier")
+}
@@ -0,0 +1,98 @@
+package tests
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	sharedChaincode "github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/chaincode"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+func TestRecordsCarryTransactionTimestamp(t *testing.T) {
+	stub := newCustomerStub(t)
+
+	registrationBytes, err := json.Marshal(domain.CustomerRegistrationRequest{
+		FirstName:          "Clock",
+		LastName:           "Customer",
+		Email:              "clock@example.com",
+		Phone:              "+1234567890",
+		DateOfBirth:        time.Date(1980, 3, 15, 0, 0, 0, 0, time.UTC),
+		NationalID:         "CLOCK001",
+		Address:            "1 Clock Street, Test City, Test Country",
+		ConsentPreferences: `{"marketing": false}`,
+		ActorID:            "ACTOR_TEST",
+	})
+	require.NoError(t, err)
+	response := stub.MockInvoke("clock_1", [][]byte{[]byte("RegisterCustomer"), registrationBytes})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+
+	var customer domain.Customer
+	require.NoError(t, json.Unmarshal(response.Payload, &customer))
+	txTime := time.Unix(stub.TxTimestamp.Seconds, int64(stub.TxTimestamp.Nanos)).UTC()
+	assert.True(t, customer.CreatedDate.Equal(txTime), "created %s, transaction %s", customer.CreatedDate, txTime)
+	assert.True(t, customer.LastUpdated.Equal(txTime))
+}
+
+func TestTimestampCutoverRecordedOnce(t *testing.T) {
+	stub := newCustomerStub(t)
+	adminIdentity := stub.Creator
+
+	response := stub.MockInvoke("cutover_1", [][]byte{[]byte("GetTimestampCutover")})
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	assert.Contains(t, response.Message, "no timestamp cutover")
+
+	// Only a system administrator may record the cutover
+	stub.Creator = newTestIdentity(t, "Org1MSP", "officer", "Compliance_Officer")
+	response = stub.MockInvoke("cutover_2", [][]byte{[]byte("GetInvokerIdentity")})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	var officer sharedChaincode.InvokerIdentity
+	require.NoError(t, json.Unmarshal(response.Payload, &officer))
+	officerIdentity := stub.Creator
+
+	stub.Creator = adminIdentity
+	registrationBytes, err := json.Marshal(sharedChaincode.ActorRegistrationRequest{
+		RegisteredActorID:  "ACTOR_OFFICER",
+		BlockchainIdentity: officer.BlockchainIdentity,
+		MSPID:              officer.MSPID,
+		Role:               "Compliance_Officer",
+		Active:             true,
+		ActorID:            "ACTOR_001",
+	})
+	require.NoError(t, err)
+	response = stub.MockInvoke("cutover_3", [][]byte{[]byte("RegisterActor"), registrationBytes})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+
+	officerBytes, err := json.Marshal(sharedChaincode.TimestampCutoverRequest{ActorID: "ACTOR_OFFICER"})
+	require.NoError(t, err)
+	stub.Creator = officerIdentity
+	response = stub.MockInvoke("cutover_4", [][]byte{[]byte("RecordTimestampCutover"), officerBytes})
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	assert.Contains(t, response.Message, "may only be recorded by")
+
+	reqBytes, err := json.Marshal(sharedChaincode.TimestampCutoverRequest{ActorID: "ACTOR_001"})
+	require.NoError(t, err)
+	stub.Creator = adminIdentity
+	response = stub.MockInvoke("cutover_5", [][]byte{[]byte("RecordTimestampCutover"), reqBytes})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	var cutover services.TimestampCutover
+	require.NoError(t, json.Unmarshal(response.Payload, &cutover))
+	assert.Equal(t, "cutover_5", cutover.TransactionID)
+	assert.Equal(t, "ACTOR_001", cutover.RecordedBy)
+	assert.True(t, cutover.IsLegacy(cutover.CutoverTime.Add(-time.Second)))
+	assert.False(t, cutover.IsLegacy(cutover.CutoverTime))
+
+	response = stub.MockInvoke("cutover_6", [][]byte{[]byte("RecordTimestampCutover"), reqBytes})
+	assert.Equal(t, int32(shim.ERROR), response.Status)
+	assert.Contains(t, response.Message, "already recorded")
+
+	response = stub.MockInvoke("cutover_7", [][]byte{[]byte("GetTimestampCutover")})
+	require.Equal(t, int32(shim.OK), response.Status, response.Message)
+	var stored services.TimestampCutover
+	require.NoError(t, json.Unmarshal(response.Payload, &stored))
+	assert.True(t, stored.CutoverTime.Equal(cutover.CutoverTime))
+}
@@ -0,0 +1,66 @@
+package tests
+
+import (
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+func TestWriteBatchAppliesNothingUntilFlushed(t *testing.T) {
+	stub := shimtest.NewMockStub("write_batch", nil)
+
+	stub.MockTransactionStart("batch_1")
+	require.NoError(t, stub.PutState("STALE_INDEX", []byte("OLD")))
+	stub.MockTransactionEnd("batch_1")
+
+	// A batch abandoned after a failed step leaves no writes behind
+	stub.MockTransactionStart("batch_2")
+	abandoned := services.NewWriteBatch()
+	require.NoError(t, abandoned.Put("ENTITY_1", map[string]string{"id": "1"}))
+	abandoned.Delete("STALE_INDEX")
+	assert.Error(t, abandoned.Put("ENTITY_2", func() {}))
+	stub.MockTransactionEnd("batch_2")
+
+	value, err := stub.GetState("ENTITY_1")
+	require.NoError(t, err)
+	assert.Nil(t, value)
+	value, err = stub.GetState("STALE_INDEX")
+	require.NoError(t, err)
+	assert.Equal(t, []byte("OLD"), value)
+
+	stub.MockTransactionStart("batch_3")
+	batch := services.NewWriteBatch()
+	require.NoError(t, batch.Put("ENTITY_1", map[string]string{"id": "1"}))
+	require.NoError(t, batch.PutIndex(stub, "ENTITY_BY_OWNER", []string{"OWNER_1", "1"}, "1"))
+	batch.Delete("STALE_INDEX")
+	var writtenBeforeEmit bool
+	batch.Emit(func(stub shim.ChaincodeStubInterface) error {
+		entity, err := stub.GetState("ENTITY_1")
+		writtenBeforeEmit = err == nil && entity != nil
+		return stub.SetEvent("EntityRecorded", []byte(`{"id":"1"}`))
+	})
+	assert.Equal(t, 3, batch.Len())
+	require.NoError(t, batch.Flush(stub))
+	stub.MockTransactionEnd("batch_3")
+
+	value, err = stub.GetState("ENTITY_1")
+	require.NoError(t, err)
+	assert.JSONEq(t, `{"id":"1"}`, string(value))
+	value, err = stub.GetState("STALE_INDEX")
+	require.NoError(t, err)
+	assert.Nil(t, value)
+	indexKey, err := stub.CreateCompositeKey("ENTITY_BY_OWNER", []string{"OWNER_1", "1"})
+	require.NoError(t, err)
+	value, err = stub.GetState(indexKey)
+	require.NoError(t, err)
+	assert.Equal(t, []byte("1"), value)
+
+	// Events are emitted after the writes have been applied
+	assert.True(t, writtenBeforeEmit)
+	event := <-stub.ChaincodeEventsChannel
+	assert.Equal(t, "EntityRecorded", event.EventName)
+}
@@ -0,0 +1,42 @@
+package main
+
+import (
+	"log"
+	"net/http"
+	"os"
+	"time"
+
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/gateway/fabric"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/gateway/server"
+)
+
+func main() {
+	configPath := os.Getenv("GATEWAY_CONFIG")
+	if configPath == "" {
+		configPath = "gateway.json"
+	}
+
+	config, err := server.LoadConfig(configPath)
+	if err != nil {
+		log.Fatalf("Error loading gateway config: %v", err)
+	}
+	schemas, err := server.LoadSchemas(config)
+	if err != nil {
+		log.Fatalf("Error loading API schemas: %v", err)
+	}
+	invoker, err := fabric.NewInvoker(config)
+	if err != nil {
+		log.Fatalf("Error connecting to Fabric: %v", err)
+	}
+	defer invoker.Close()
+
+	httpServer := &http.Server{
+		Addr:              config.ListenAddress,
+		Handler:           server.NewServer(config, invoker, schemas).Handler(),
+		ReadHeaderTimeout: 10 * time.Second,
+	}
+	log.Printf("Gateway listening on %s for channel %s", config.ListenAddress, config.Channel)
+	if err := httpServer.ListenAndServe(); err != nil {
+		log.Printf("Gateway stopped: %v", err)
+	}
+}
@@ -0,0 +1,199 @@
+package fabric
+
+import (
+	"context"
+	"crypto/x509"
+	"fmt"
+	"os"
+
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+	"github.com/hyperledger/fabric-gateway/pkg/identity"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	_ "github.com/brycemacchaveli/origin.block/fabric-chaincode/gateway/protoconflict"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/gateway/sdk"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/gateway/server"
+)
+
+// Invoker calls the chaincodes through a peer's Fabric Gateway service, with a gateway connection
+// for each configured identity over one gRPC connection
+type Invoker struct {
+	connection *grpc.ClientConn
+	gateways   map[string]*client.Gateway
+	channel    string
+}
+
+// NewInvoker connects to the peer as every identity in the configuration
+func NewInvoker(config *server.Config) (*Invoker, error) {
+	if config.PeerEndpoint == "" {
+		return nil, fmt.Errorf("peerEndpoint is required")
+	}
+	connection, err := newConnection(config)
+	if err != nil {
+		return nil, err
+	}
+
+	invoker := &Invoker{connection: connection, gateways: make(map[string]*client.Gateway), channel: config.Channel}
+	timeout := config.Timeout()
+	for name, identityConfig := range config.Identities {
+		id, sign, err := loadIdentity(identityConfig)
+		if err != nil {
+			invoker.Close()
+			return nil, fmt.Errorf("identity %s: %w", name, err)
+		}
+		gateway, err := client.Connect(id,
+			client.WithSign(sign),
+			client.WithClientConnection(connection),
+			client.WithEvaluateTimeout(timeout),
+			client.WithEndorseTimeout(timeout),
+			client.WithSubmitTimeout(timeout),
+			client.WithCommitStatusTimeout(timeout),
+		)
+		if err != nil {
+			invoker.Close()
+			return nil, fmt.Errorf("failed to connect as identity %s: %w", name, err)
+		}
+		invoker.gateways[name] = gateway
+	}
+	return invoker, nil
+}
+
+// Close closes the gateway connections and the gRPC connection they share
+func (i *Invoker) Close() error {
+	for _, gateway := range i.gateways {
+		gateway.Close()
+	}
+	return i.connection.Close()
+}
+
+// Submit endorses a transaction and waits for it to commit. A transaction the peers invalidated
+// fails with ERR_LEDGER, retryable when it lost a read conflict to another transaction.
+func (i *Invoker) Submit(ctx context.Context, identityName, chaincodeName, function string, args []string, transient map[string][]byte) (*server.Result, error) {
+	proposal, err := i.proposal(identityName, chaincodeName, function, args, transient)
+	if err != nil {
+		return nil, err
+	}
+	transaction, err := proposal.EndorseWithContext(ctx)
+	if err != nil {
+		return nil, sdk.ParseError(err)
+	}
+	commit, err := transaction.SubmitWithContext(ctx)
+	if err != nil {
+		return nil, sdk.ParseError(err)
+	}
+	commitStatus, err := commit.StatusWithContext(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if !commitStatus.Successful {
+		return nil, sdk.CommitFailure(commitStatus.TransactionID, commitStatus.Code)
+	}
+
+	return &server.Result{TransactionID: proposal.TransactionID(), Payload: transaction.Result()}, nil
+}
+
+// Evaluate runs a function on a peer without submitting a transaction
+func (i *Invoker) Evaluate(ctx context.Context, identityName, chaincodeName, function string, args []string, transient map[string][]byte) (*server.Result, error) {
+	proposal, err := i.proposal(identityName, chaincodeName, function, args, transient)
+	if err != nil {
+		return nil, err
+	}
+	payload, err := proposal.EvaluateWithContext(ctx)
+	if err != nil {
+		return nil, sdk.ParseError(err)
+	}
+	return &server.Result{TransactionID: proposal.TransactionID(), Payload: payload}, nil
+}
+
+// ChaincodeEvents streams the events of a chaincode's transactions as they commit, from the
+// current block, until the context is done
+func (i *Invoker) ChaincodeEvents(ctx context.Context, identityName, chaincodeName string) (<-chan *server.ChaincodeEvent, error) {
+	gateway, ok := i.gateways[identityName]
+	if !ok {
+		return nil, fmt.Errorf("no connection for identity %s", identityName)
+	}
+	events, err := gateway.GetNetwork(i.channel).ChaincodeEvents(ctx, chaincodeName)
+	if err != nil {
+		return nil, fmt.Errorf("failed to listen for %s events: %w", chaincodeName, err)
+	}
+
+	forwarded := make(chan *server.ChaincodeEvent)
+	go func() {
+		defer close(forwarded)
+		for event := range events {
+			select {
+			case forwarded <- &server.ChaincodeEvent{
+				BlockNumber:   event.BlockNumber,
+				TransactionID: event.TransactionID,
+				EventName:     event.EventName,
+				Payload:       event.Payload,
+			}:
+			case <-ctx.Done():
+				return
+			}
+		}
+	}()
+	return forwarded, nil
+}
+
+// proposal builds a proposal of a call, with its transient data kept out of the transaction
+func (i *Invoker) proposal(identityName, chaincodeName, function string, args []string, transient map[string][]byte) (*client.Proposal, error) {
+	gateway, ok := i.gateways[identityName]
+	if !ok {
+		return nil, fmt.Errorf("no connection for identity %s", identityName)
+	}
+	contract := gateway.GetNetwork(i.channel).GetContract(chaincodeName)
+	options := []client.ProposalOption{client.WithArguments(args...)}
+	if len(transient) > 0 {
+		options = append(options, client.WithTransient(transient))
+	}
+	return contract.NewProposal(function, options...)
+}
+
+func newConnection(config *server.Config) (*grpc.ClientConn, error) {
+	certificatePEM, err := os.ReadFile(config.TLSCertPath)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read peer TLS certificate: %w", err)
+	}
+	certificate, err := identity.CertificateFromPEM(certificatePEM)
+	if err != nil {
+		return nil, fmt.Errorf("failed to parse peer TLS certificate: %w", err)
+	}
+	pool := x509.NewCertPool()
+	pool.AddCert(certificate)
+
+	connection, err := grpc.NewClient(config.PeerEndpoint, grpc.WithTransportCredentials(credentials.NewClientTLSFromCert(pool, config.PeerHostOverride)))
+	if err != nil {
+		return nil, fmt.Errorf("failed to create connection to %s: %w", config.PeerEndpoint, err)
+	}
+	return connection, nil
+}
+
+func loadIdentity(config server.IdentityConfig) (*identity.X509Identity, identity.Sign, error) {
+	certificatePEM, err := os.ReadFile(config.CertPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read certificate: %w", err)
+	}
+	certificate, err := identity.CertificateFromPEM(certificatePEM)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse certificate: %w", err)
+	}
+	id, err := identity.NewX509Identity(config.MSPID, certificate)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create identity: %w", err)
+	}
+
+	keyPEM, err := os.ReadFile(config.KeyPath)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to read private key: %w", err)
+	}
+	key, err := identity.PrivateKeyFromPEM(keyPEM)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to parse private key: %w", err)
+	}
+	sign, err := identity.NewPrivateKeySign(key)
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to create signer: %w", err)
+	}
+	return id, sign, nil
+}
@@ -0,0 +1,36 @@
+module github.com/brycemacchaveli/origin.block/fabric-chaincode/gateway
+
+go 1.23
+
+require (
+	github.com/brycemacchaveli/origin.block/fabric-chaincode/customer v0.0.0
+	github.com/brycemacchaveli/origin.block/fabric-chaincode/loan v0.0.0
+	github.com/brycemacchaveli/origin.block/fabric-chaincode/shared v0.0.0
+	github.com/golang/protobuf v1.5.4
+	github.com/hyperledger/fabric-chaincode-go v0.0.0-20220920210243-7bc6fa0dd58b
+	github.com/hyperledger/fabric-gateway v1.5.0
+	github.com/hyperledger/fabric-protos-go v0.0.0-20220827195505-ce4c067a561d
+	github.com/stretchr/testify v1.9.0
+	google.golang.org/grpc v1.64.0
+)
+
+require (
+	github.com/davecgh/go-spew v1.1.1 // indirect
+	github.com/hyperledger/fabric-protos-go-apiv2 v0.3.3 // indirect
+	github.com/miekg/pkcs11 v1.1.1 // indirect
+	github.com/pmezard/go-difflib v1.0.0 // indirect
+	golang.org/x/crypto v0.21.0 // indirect
+	golang.org/x/net v0.22.0 // indirect
+	golang.org/x/sys v0.26.0 // indirect
+	golang.org/x/text v0.14.0 // indirect
+	google.golang.org/genproto v0.0.0-20240318140521-94a12d6c2237 // indirect
+	google.golang.org/genproto/googleapis/rpc v0.0.0-20240318140521-94a12d6c2237 // indirect
+	google.golang.org/protobuf v1.33.0 // indirect
+	gopkg.in/yaml.v3 v3.0.1 // indirect
+)
+
+replace github.com/brycemacchaveli/origin.block/fabric-chaincode/shared => ../shared
+
+replace github.com/brycemacchaveli/origin.block/fabric-chaincode/customer => ../customer
+
+replace github.com/brycemacchaveli/origin.block/fabric-chaincode/loan => ../loan
@@ -0,0 +1,142 @@
+// Package ledgertest runs the customer chaincode on a mock stub for the gateway's tests, the way
+// the peers would: transient data reaches the chaincode, submitted calls commit their writes and
+// evaluated calls discard them.
+package ledgertest
+
+import (
+	"container/list"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/stretchr/testify/require"
+	customerChaincode "github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/chaincode"
+	customerDomain "github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	sharedChaincode "github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/chaincode"
+)
+
+// Ledger is a customer chaincode on a mock stub. Recorded keeps the arguments of each call and
+// Committed the response of each submitted one, as the block would.
+type Ledger struct {
+	Stub      *shimtest.MockStub
+	Recorded  [][]string
+	Committed [][]byte
+	txs       int
+}
+
+// NewCustomerLedger returns the customer chaincode invoked by an administrator bound to ACTOR_001
+func NewCustomerLedger(t *testing.T) *Ledger {
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+	attrs, err := json.Marshal(map[string]map[string]string{"attrs": {"role": "System_Administrator"}})
+	require.NoError(t, err)
+	template := &x509.Certificate{
+		SerialNumber:    big.NewInt(time.Now().UnixNano()),
+		Subject:         pkix.Name{CommonName: "admin"},
+		NotBefore:       time.Now().Add(-time.Hour),
+		NotAfter:        time.Now().Add(time.Hour),
+		ExtraExtensions: []pkix.Extension{{Id: asn1.ObjectIdentifier{1, 2, 3, 4, 5, 6, 7, 8, 1}, Value: attrs}},
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	require.NoError(t, err)
+	identity, err := proto.Marshal(&msp.SerializedIdentity{
+		Mspid:   "Org1MSP",
+		IdBytes: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}),
+	})
+	require.NoError(t, err)
+
+	stub := shimtest.NewMockStub("customer", &customerChaincode.CustomerContract{})
+	stub.Creator = identity
+	ledger := &Ledger{Stub: stub}
+
+	var invoker sharedChaincode.InvokerIdentity
+	payload, err := ledger.Evaluate("GetInvokerIdentity", nil, nil)
+	require.NoError(t, err)
+	require.NoError(t, json.Unmarshal(payload, &invoker))
+	registration, err := json.Marshal(sharedChaincode.ActorRegistrationRequest{
+		RegisteredActorID:  "ACTOR_001",
+		BlockchainIdentity: invoker.BlockchainIdentity,
+		MSPID:              invoker.MSPID,
+		Role:               "System_Administrator",
+		Active:             true,
+		ActorID:            "ACTOR_ADMIN",
+	})
+	require.NoError(t, err)
+	_, err = ledger.Submit("RegisterActor", nil, []string{string(registration)})
+	require.NoError(t, err)
+
+	ledger.Recorded, ledger.Committed = nil, nil
+	return ledger
+}
+
+// Submit invokes a chaincode function and keeps its writes and response
+func (l *Ledger) Submit(name string, transient map[string][]byte, args []string) ([]byte, error) {
+	payload, err := l.invoke(name, transient, args)
+	if err == nil {
+		l.Committed = append(l.Committed, payload)
+	}
+	return payload, err
+}
+
+// Evaluate invokes a chaincode function and then restores the world state, as a peer evaluating a
+// proposal never commits its writes
+func (l *Ledger) Evaluate(name string, transient map[string][]byte, args []string) ([]byte, error) {
+	state := make(map[string][]byte, len(l.Stub.State))
+	for key, value := range l.Stub.State {
+		state[key] = value
+	}
+	keys := list.New()
+	keys.PushBackList(l.Stub.Keys)
+	defer func() { l.Stub.State, l.Stub.Keys = state, keys }()
+	return l.invoke(name, transient, args)
+}
+
+// AccessLog returns the access log entries committed for a customer
+func (l *Ledger) AccessLog(t *testing.T, customerID string) []customerDomain.AccessLogEntry {
+	l.Stub.MockTransactionStart("access_log")
+	defer l.Stub.MockTransactionEnd("access_log")
+	iterator, err := l.Stub.GetStateByPartialCompositeKey("CUSTOMER_ACCESS_LOG", []string{customerID})
+	require.NoError(t, err)
+	defer iterator.Close()
+
+	var entries []customerDomain.AccessLogEntry
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		require.NoError(t, err)
+		var entry customerDomain.AccessLogEntry
+		require.NoError(t, json.Unmarshal(response.Value, &entry))
+		entries = append(entries, entry)
+	}
+	return entries
+}
+
+func (l *Ledger) invoke(name string, transient map[string][]byte, args []string) ([]byte, error) {
+	l.txs++
+	l.Recorded = append(l.Recorded, args)
+	l.Stub.TransientMap = transient
+	defer func() { l.Stub.TransientMap = nil }()
+
+	input := [][]byte{[]byte(name)}
+	for _, arg := range args {
+		input = append(input, []byte(arg))
+	}
+	response := l.Stub.MockInvoke(fmt.Sprintf("tx%d", l.txs), input)
+	if response.Status != shim.OK {
+		return nil, errors.New(response.Message)
+	}
+	return response.Payload, nil
+}
@@ -0,0 +1,22 @@
+// Package protoconflict lets the gateway link the chaincode packages alongside the Fabric Gateway SDK.
+//
+// The gateway serves the chaincodes' request and response types, which links the chaincode shim's
+// protos (fabric-protos-go), and calls the peers through the Fabric Gateway SDK, whose protos
+// (fabric-protos-go-apiv2) register the same message names. The protobuf runtime panics on the
+// duplicate registration unless GOLANG_PROTOBUF_REGISTRATION_CONFLICT says otherwise. It reads the
+// variable when the conflict occurs, and this package has no dependencies beyond the standard library,
+// so its init runs before either set of protos registers. The gateway only puts the SDK's protos on
+// the wire, so which registration wins does not matter.
+//
+// Every gateway package that imports the Fabric Gateway SDK imports this package for its side effect.
+package protoconflict
+
+import "os"
+
+const registrationConflictEnv = "GOLANG_PROTOBUF_REGISTRATION_CONFLICT"
+
+func init() {
+	if os.Getenv(registrationConflictEnv) == "" {
+		os.Setenv(registrationConflictEnv, "ignore")
+	}
+}
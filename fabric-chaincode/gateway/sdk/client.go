@@ -0,0 +1,176 @@
+package sdk
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+)
+
+// Chaincode names the SDK calls the chaincodes by when they are deployed under their default names
+const (
+	ChaincodeCustomer   = "customer"
+	ChaincodeLoan       = "loan"
+	ChaincodeCompliance = "compliance"
+)
+
+// Contract submits and evaluates the functions of one chaincode. Transient data reaches the
+// endorsing peers but is not recorded on the ledger. NewFromNetwork and NewFromContracts adapt the
+// Fabric Gateway client's *client.Contract to it.
+type Contract interface {
+	SubmitTransaction(name string, args ...string) ([]byte, error)
+	EvaluateTransaction(name string, args ...string) ([]byte, error)
+	SubmitWithTransient(name string, transient map[string][]byte, args ...string) ([]byte, error)
+	EvaluateWithTransient(name string, transient map[string][]byte, args ...string) ([]byte, error)
+}
+
+// Client calls the customer, loan and compliance chaincodes with typed requests and results.
+// Requests are passed JSON encoded and results decoded into the chaincodes' own domain types;
+// a call the chaincode rejects fails with its *services.ChaincodeError.
+type Client struct {
+	contracts map[string]Contract
+}
+
+// New creates a client calling the given contracts
+func New(customer, loan, compliance Contract) *Client {
+	return &Client{contracts: map[string]Contract{
+		ChaincodeCustomer:   customer,
+		ChaincodeLoan:       loan,
+		ChaincodeCompliance: compliance,
+	}}
+}
+
+// Submit submits a function of a chaincode as a transaction and decodes its response into
+// result, which may be nil. String arguments are passed as they are and other arguments JSON
+// encoded, so functions without a typed method are called the same way. A submitted response is
+// committed to the block, so reads of a customer's PII are evaluated as by Evaluate.
+func (c *Client) Submit(chaincodeName, function string, result interface{}, args ...interface{}) error {
+	return c.call(true, chaincodeName, function, nil, result, args)
+}
+
+// SubmitTransient submits a function of a chaincode with transient data, which the chaincode reads
+// but the ledger does not record, such as personal data kept in private collections
+func (c *Client) SubmitTransient(chaincodeName, function string, transient map[string][]byte, result interface{}, args ...interface{}) error {
+	return c.call(true, chaincodeName, function, transient, result, args)
+}
+
+// Evaluate runs a function of a chaincode on a peer without recording anything on the ledger
+// and decodes its response into result, which may be nil. Before a read of a customer's PII, one
+// of config.AccessLoggedFunctions, RecordAccess is submitted so that the read's access log entry
+// is kept.
+func (c *Client) Evaluate(chaincodeName, function string, result interface{}, args ...interface{}) error {
+	return c.call(false, chaincodeName, function, nil, result, args)
+}
+
+// EvaluateTransient evaluates a function of a chaincode with transient data, such as the data key
+// a sealed field is opened with
+func (c *Client) EvaluateTransient(chaincodeName, function string, transient map[string][]byte, result interface{}, args ...interface{}) error {
+	return c.call(false, chaincodeName, function, transient, result, args)
+}
+
+func (c *Client) call(submit bool, chaincodeName, function string, transient map[string][]byte, result interface{}, args []interface{}) error {
+	contract, ok := c.contracts[chaincodeName]
+	if !ok || contract == nil {
+		return fmt.Errorf("no contract for chaincode %s", chaincodeName)
+	}
+	encodedArgs, err := encodeArguments(args)
+	if err != nil {
+		return err
+	}
+	if chaincodeName == ChaincodeCustomer && config.AccessLoggedFunctions[function] {
+		if err := recordAccess(contract, function, encodedArgs, transient); err != nil {
+			return err
+		}
+		submit = false
+	}
+
+	var payload []byte
+	switch {
+	case submit && len(transient) > 0:
+		payload, err = contract.SubmitWithTransient(function, transient, encodedArgs...)
+	case submit:
+		payload, err = contract.SubmitTransaction(function, encodedArgs...)
+	case len(transient) > 0:
+		payload, err = contract.EvaluateWithTransient(function, transient, encodedArgs...)
+	default:
+		payload, err = contract.EvaluateTransaction(function, encodedArgs...)
+	}
+	if err != nil {
+		return ParseError(err)
+	}
+
+	if result == nil || len(payload) == 0 {
+		return nil
+	}
+	if err := json.Unmarshal(payload, result); err != nil {
+		return fmt.Errorf("failed to decode %s response: %w", function, err)
+	}
+	return nil
+}
+
+// recordAccess submits the access log entry of a read of a customer's PII, under the purpose and
+// actor the read's transient data names
+func recordAccess(contract Contract, function string, args []string, transient map[string][]byte) error {
+	access := make(map[string][]byte, 2)
+	for _, key := range []string{config.AccessPurposeTransientKey, config.AccessActorTransientKey} {
+		if value, ok := transient[key]; ok {
+			access[key] = value
+		}
+	}
+	recordArgs := []string{AccessedCustomerID(args), function}
+
+	var err error
+	if len(access) > 0 {
+		_, err = contract.SubmitWithTransient(config.RecordAccessFunction, access, recordArgs...)
+	} else {
+		_, err = contract.SubmitTransaction(config.RecordAccessFunction, recordArgs...)
+	}
+	return ParseError(err)
+}
+
+// AccessedCustomerID returns the customer a read of PII names: the customerID of the JSON request
+// it takes, else its first argument
+func AccessedCustomerID(args []string) string {
+	if len(args) == 0 {
+		return ""
+	}
+	var req struct {
+		CustomerID string `json:"customerID"`
+	}
+	if err := json.Unmarshal([]byte(args[0]), &req); err == nil && req.CustomerID != "" {
+		return req.CustomerID
+	}
+	return args[0]
+}
+
+// transientJSON returns transient data holding a value JSON encoded under a field
+func transientJSON(field string, value interface{}) (map[string][]byte, error) {
+	encoded, err := json.Marshal(value)
+	if err != nil {
+		return nil, fmt.Errorf("failed to encode transient %s: %w", field, err)
+	}
+	return map[string][]byte{field: encoded}, nil
+}
+
+// encodeArguments converts call arguments to the strings chaincode functions receive
+func encodeArguments(args []interface{}) ([]string, error) {
+	encoded := make([]string, 0, len(args))
+	for i, arg := range args {
+		switch value := arg.(type) {
+		case nil:
+			return nil, fmt.Errorf("argument %d must not be nil", i)
+		case string:
+			encoded = append(encoded, value)
+		default:
+			argBytes, err := json.Marshal(value)
+			if err != nil {
+				return nil, fmt.Errorf("failed to encode argument %d: %w", i, err)
+			}
+			if string(argBytes) == "null" {
+				return nil, fmt.Errorf("argument %d must not be nil", i)
+			}
+			encoded = append(encoded, string(argBytes))
+		}
+	}
+	return encoded, nil
+}
@@ -0,0 +1,239 @@
+package sdk
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	customerDomain "github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	loanDomain "github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/interfaces"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+type recordedCall struct {
+	submit    bool
+	function  string
+	args      []string
+	transient map[string][]byte
+}
+
+// fakeContract records calls and answers them with a fixed payload or error
+type fakeContract struct {
+	calls   []recordedCall
+	payload []byte
+	err     error
+}
+
+func (f *fakeContract) call(submit bool, name string, args []string, transient map[string][]byte) ([]byte, error) {
+	f.calls = append(f.calls, recordedCall{submit: submit, function: name, args: args, transient: transient})
+	return f.payload, f.err
+}
+
+func (f *fakeContract) SubmitTransaction(name string, args ...string) ([]byte, error) {
+	return f.call(true, name, args, nil)
+}
+
+func (f *fakeContract) EvaluateTransaction(name string, args ...string) ([]byte, error) {
+	return f.call(false, name, args, nil)
+}
+
+func (f *fakeContract) SubmitWithTransient(name string, transient map[string][]byte, args ...string) ([]byte, error) {
+	return f.call(true, name, args, transient)
+}
+
+func (f *fakeContract) EvaluateWithTransient(name string, transient map[string][]byte, args ...string) ([]byte, error) {
+	return f.call(false, name, args, transient)
+}
+
+func newTestClient() (*Client, *fakeContract, *fakeContract, *fakeContract) {
+	customer, loan, compliance := &fakeContract{}, &fakeContract{}, &fakeContract{}
+	return New(customer, loan, compliance), customer, loan, compliance
+}
+
+func TestClientSubmitsTypedRequests(t *testing.T) {
+	client, customer, loan, _ := newTestClient()
+
+	customer.payload = []byte(`{"customerID":"CUST_1","firstName":"Ada","status":"ACTIVE"}`)
+	registered, err := client.RegisterCustomer(&customerDomain.CustomerRegistrationRequest{FirstName: "Ada", ActorID: "ACTOR_001"})
+	require.NoError(t, err)
+	assert.Equal(t, "CUST_1", registered.CustomerID)
+	assert.Equal(t, "Ada", registered.FirstName)
+
+	require.Len(t, customer.calls, 1)
+	call := customer.calls[0]
+	assert.True(t, call.submit)
+	assert.Equal(t, "RegisterCustomer", call.function)
+	require.Len(t, call.args, 1)
+	var request map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(call.args[0]), &request))
+	assert.Empty(t, request["firstName"])
+	assert.Equal(t, "ACTOR_001", request["actorID"])
+	var pii customerDomain.CustomerPII
+	require.NoError(t, json.Unmarshal(call.transient["customerPII"], &pii))
+	assert.Equal(t, "Ada", pii.FirstName)
+
+	loan.payload = []byte(`{"loanID":"LOAN_1","approvedAmount":5000}`)
+	approved, err := client.ApproveLoan(&loanDomain.LoanApprovalRequest{LoanID: "LOAN_1", ApprovedAmount: 5000, ActorID: "ACTOR_001"})
+	require.NoError(t, err)
+	assert.Equal(t, "LOAN_1", approved.LoanID)
+	require.NotNil(t, approved.ApprovedAmount)
+	assert.Equal(t, 5000.0, approved.ApprovedAmount.Float64())
+	assert.Equal(t, "ApproveLoan", loan.calls[0].function)
+	assert.True(t, loan.calls[0].submit)
+}
+
+func TestClientEvaluatesQueries(t *testing.T) {
+	client, customer, loan, compliance := newTestClient()
+
+	// Identifiers pass as they are rather than JSON encoded
+	customer.payload = []byte(`{"kycID":"KYC_1"}`)
+	_, err := client.GetKYCRecord("KYC_1")
+	require.NoError(t, err)
+	assert.Equal(t, recordedCall{submit: false, function: "GetKYCRecord", args: []string{"KYC_1"}}, customer.calls[0])
+
+	loan.payload = []byte(`{"loanID":"LOAN_1"}`)
+	_, err = client.GetLoanApplication("LOAN_1")
+	require.NoError(t, err)
+	assert.False(t, loan.calls[0].submit)
+	assert.Equal(t, []string{"LOAN_1"}, loan.calls[0].args)
+
+	compliance.payload = []byte(`{"isMatch":true,"matchConfidence":0.9,"matches":[{"matchedName":"Ada Lovelace"}]}`)
+	result, err := client.ScreenPEP(&interfaces.PEPScreeningRequest{Name: "Ada Lovelace"})
+	require.NoError(t, err)
+	assert.True(t, result.IsMatch)
+	require.Len(t, result.Matches, 1)
+	assert.Equal(t, "Ada Lovelace", result.Matches[0].MatchedName)
+	assert.Equal(t, []string{`{"name":"Ada Lovelace"}`}, compliance.calls[0].args)
+}
+
+func TestClientCallsUntypedFunctions(t *testing.T) {
+	client, _, loan, _ := newTestClient()
+
+	loan.payload = []byte(`{"count":2}`)
+	var result map[string]interface{}
+	err := client.Submit(ChaincodeLoan, "BulkUpdate", &result, "LOAN_1", map[string]int{"limit": 2}, 3)
+	require.NoError(t, err)
+	assert.Equal(t, 2.0, result["count"])
+	assert.Equal(t, []string{"LOAN_1", `{"limit":2}`, "3"}, loan.calls[0].args)
+
+	assert.Error(t, client.Submit(ChaincodeLoan, "BulkUpdate", nil, nil))
+	var missing *loanDomain.LoanApprovalRequest
+	_, err = client.ApproveLoan(missing)
+	assert.Error(t, err)
+	assert.Len(t, loan.calls, 1)
+
+	assert.Error(t, client.Evaluate("treasury", "GetBalance", nil))
+}
+
+func TestClientPassesTransientData(t *testing.T) {
+	client, customer, _, _ := newTestClient()
+
+	customer.payload = []byte(`{"customerID":"CUST_1"}`)
+	transient := map[string][]byte{"accessPurpose": []byte("KYC_REVIEW")}
+	require.NoError(t, client.SubmitTransient(ChaincodeCustomer, "UpdateCustomer", transient, nil, "CUST_1"))
+	require.NoError(t, client.EvaluateTransient(ChaincodeCustomer, "GetKYCRecord", transient, nil, "KYC_1"))
+	require.NoError(t, client.Evaluate(ChaincodeCustomer, "GetKYCRecord", nil, "KYC_1"))
+
+	require.Len(t, customer.calls, 3)
+	assert.Equal(t, recordedCall{submit: true, function: "UpdateCustomer", args: []string{"CUST_1"}, transient: transient}, customer.calls[0])
+	assert.Equal(t, recordedCall{submit: false, function: "GetKYCRecord", args: []string{"KYC_1"}, transient: transient}, customer.calls[1])
+	assert.Nil(t, customer.calls[2].transient)
+}
+
+func TestClientRecordsAccessBeforeEvaluatingPIIReads(t *testing.T) {
+	client, customer, _, _ := newTestClient()
+
+	// A submitted response is committed to the block, so reads of PII are evaluated and their
+	// access is recorded by a RecordAccess submitted first
+	customer.payload = []byte(`{"customerID":"CUST_1"}`)
+	_, err := client.GetCustomer("CUST_1")
+	require.NoError(t, err)
+	_, err = client.GetCustomer360("CUST_1")
+	require.NoError(t, err)
+	require.NoError(t, client.Evaluate(ChaincodeCustomer, "GetConsentHistory", nil, "CUST_1"))
+	require.NoError(t, client.EvaluateTransient(ChaincodeCustomer, "GetConsentAt", map[string][]byte{
+		"accessPurpose": []byte("AUDIT"),
+		"piiKey:k1":     []byte("key material"),
+	}, nil, "CUST_1", "2026-01-01"))
+	require.NoError(t, client.Evaluate(ChaincodeCustomer, "GetCustomerCrossResidency", nil, map[string]string{"customerID": "CUST_2"}))
+
+	require.Len(t, customer.calls, 10)
+	for i, function := range []string{"GetCustomer", "GetCustomer360", "GetConsentHistory", "GetConsentAt", "GetCustomerCrossResidency"} {
+		record, read := customer.calls[2*i], customer.calls[2*i+1]
+		assert.True(t, record.submit, function)
+		assert.Equal(t, "RecordAccess", record.function)
+		assert.Equal(t, function, record.args[1])
+		assert.False(t, read.submit, function)
+		assert.Equal(t, function, read.function)
+	}
+	assert.Equal(t, "CUST_1", customer.calls[0].args[0])
+	assert.Equal(t, "CUST_2", customer.calls[8].args[0])
+	assert.Nil(t, customer.calls[0].transient)
+
+	// Only the purpose and actor of the read go with its RecordAccess
+	assert.Equal(t, map[string][]byte{"accessPurpose": []byte("AUDIT")}, customer.calls[6].transient)
+	assert.Equal(t, []byte("key material"), customer.calls[7].transient["piiKey:k1"])
+}
+
+func TestClientSkipsReadWhenAccessIsNotRecorded(t *testing.T) {
+	client, customer, _, _ := newTestClient()
+
+	customer.err = errors.New("ERR_NOT_FOUND: customer CUST_9 not found")
+	_, err := client.GetCustomer("CUST_9")
+	require.Error(t, err)
+	require.Len(t, customer.calls, 1)
+	assert.Equal(t, "RecordAccess", customer.calls[0].function)
+}
+
+func TestSignRequestMatchesCanonicalForm(t *testing.T) {
+	body := []byte(`["LOAN_1"]`)
+	request := httptest.NewRequest(http.MethodPost, "/v1/loan/GetLoanApplication?x=1", bytes.NewReader(body))
+	require.NoError(t, SignRequest(request, body, "key-1", "shared-secret"))
+
+	timestamp, nonce := request.Header.Get(HeaderSignatureTimestamp), request.Header.Get(HeaderSignatureNonce)
+	assert.Equal(t, "key-1", request.Header.Get(HeaderPartnerKeyID))
+	assert.Len(t, nonce, 32)
+	assert.Equal(t, RequestSignature("shared-secret", "POST", "/v1/loan/GetLoanApplication?x=1", timestamp, nonce, body), request.Header.Get(HeaderSignature))
+	assert.Equal(t, "POST\n/v1/loan/GetLoanApplication?x=1\n"+timestamp+"\n"+nonce+"\n"+BodyHash(body), CanonicalRequest("post", "/v1/loan/GetLoanApplication?x=1", timestamp, nonce, body))
+}
+
+func TestClientReturnsChaincodeErrors(t *testing.T) {
+	client, customer, loan, _ := newTestClient()
+
+	// The peer prefixes the chaincode's envelope with the response status
+	customer.err = errors.New(`chaincode response 500, {"code":"ERR_NOT_FOUND","message":"customer not found","retryable":false}`)
+	_, err := client.GetCustomer("CUST_MISSING")
+	require.Error(t, err)
+	assert.Equal(t, services.ErrCodeNotFound, ErrorCode(err))
+	var chaincodeErr *services.ChaincodeError
+	require.True(t, errors.As(err, &chaincodeErr))
+	assert.Equal(t, "customer not found", chaincodeErr.Message)
+
+	loan.err = services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "loan cannot be approved from current status: SUBMITTED")
+	_, err = client.ApproveLoan(&loanDomain.LoanApprovalRequest{LoanID: "LOAN_1"})
+	assert.Equal(t, services.ErrCodeInvalidTransition, ErrorCode(err))
+
+	// Errors from outside a chaincode pass through unchanged
+	loan.err = errors.New("connection refused")
+	_, err = client.GetLoanApplication("LOAN_1")
+	assert.EqualError(t, err, "connection refused")
+	assert.Empty(t, ErrorCode(err))
+}
+
+func TestCommitFailureIsRetryableOnReadConflict(t *testing.T) {
+	conflict := CommitFailure("tx1", stringer("MVCC_READ_CONFLICT"))
+	assert.Equal(t, services.ErrCodeLedger, conflict.Code)
+	assert.True(t, conflict.Retryable)
+
+	assert.False(t, CommitFailure("tx2", stringer("ENDORSEMENT_POLICY_FAILURE")).Retryable)
+}
+
+type stringer string
+
+func (s stringer) String() string { return string(s) }
@@ -0,0 +1,24 @@
+package sdk
+
+import (
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/interfaces"
+)
+
+// ScreenPEP screens a name against the compliance chaincode's PEP list
+func (c *Client) ScreenPEP(req *interfaces.PEPScreeningRequest) (*interfaces.PEPScreeningResult, error) {
+	var result interfaces.PEPScreeningResult
+	if err := c.Evaluate(ChaincodeCompliance, "ScreenPEP", &result, req); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
+
+// ScreenText screens an entity's free-text fields against the prohibited activity terms. Matches
+// raise a compliance event, so the screening is submitted as a transaction.
+func (c *Client) ScreenText(req *interfaces.TextScreeningRequest) (*interfaces.TextScreeningResult, error) {
+	var result interfaces.TextScreeningResult
+	if err := c.Submit(ChaincodeCompliance, "ScreenText", &result, req); err != nil {
+		return nil, err
+	}
+	return &result, nil
+}
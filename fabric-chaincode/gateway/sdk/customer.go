@@ -0,0 +1,158 @@
+package sdk
+
+import (
+	"fmt"
+	"time"
+
+	customerDomain "github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/interfaces"
+)
+
+// RegisterCustomer registers a new customer. The personal data of the request is passed in
+// transient data rather than in the arguments, which are recorded on the ledger.
+func (c *Client) RegisterCustomer(req *customerDomain.CustomerRegistrationRequest) (*customerDomain.Customer, error) {
+	if req == nil {
+		return nil, fmt.Errorf("registration request must not be nil")
+	}
+	public := *req
+	pii := customerDomain.CustomerPII{
+		FirstName:   req.FirstName,
+		LastName:    req.LastName,
+		Email:       req.Email,
+		Phone:       req.Phone,
+		DateOfBirth: req.DateOfBirth,
+		NationalID:  req.NationalID,
+		Address:     req.Address,
+	}
+	public.FirstName, public.LastName, public.Email, public.Phone, public.NationalID, public.Address = "", "", "", "", "", ""
+	public.DateOfBirth = time.Time{}
+
+	transient, err := transientJSON(config.CustomerPIITransientKey, pii)
+	if err != nil {
+		return nil, err
+	}
+	var customer customerDomain.Customer
+	if err := c.SubmitTransient(ChaincodeCustomer, "RegisterCustomer", transient, &customer, &public); err != nil {
+		return nil, err
+	}
+	return &customer, nil
+}
+
+// UpdateCustomer updates a customer's details. The email, phone and address it sets are passed in
+// transient data rather than in the arguments, which are recorded on the ledger.
+func (c *Client) UpdateCustomer(req *customerDomain.CustomerUpdateRequest) (*customerDomain.Customer, error) {
+	if req == nil {
+		return nil, fmt.Errorf("update request must not be nil")
+	}
+	public := *req
+	contact := customerDomain.CustomerContact{Email: req.Email, Phone: req.Phone, Address: req.Address}
+	public.Email, public.Phone, public.Address = nil, nil, nil
+
+	var transient map[string][]byte
+	if !contact.IsEmpty() {
+		var err error
+		if transient, err = transientJSON(config.CustomerContactTransientKey, contact); err != nil {
+			return nil, err
+		}
+	}
+	var customer customerDomain.Customer
+	if err := c.SubmitTransient(ChaincodeCustomer, "UpdateCustomer", transient, &customer, &public); err != nil {
+		return nil, err
+	}
+	return &customer, nil
+}
+
+// GetCustomer retrieves a customer by ID. The read is logged by a RecordAccess transaction
+// submitted before it.
+func (c *Client) GetCustomer(customerID string) (*customerDomain.Customer, error) {
+	var customer customerDomain.Customer
+	if err := c.Evaluate(ChaincodeCustomer, "GetCustomer", &customer, customerID); err != nil {
+		return nil, err
+	}
+	return &customer, nil
+}
+
+// UpdateCustomerStatus changes a customer's status. The chaincode answers with the customer's
+// public fields only.
+func (c *Client) UpdateCustomerStatus(req *customerDomain.CustomerStatusUpdateRequest) (*customerDomain.Customer, error) {
+	var customer customerDomain.Customer
+	if err := c.Submit(ChaincodeCustomer, "UpdateCustomerStatus", &customer, req); err != nil {
+		return nil, err
+	}
+	return &customer, nil
+}
+
+// InitiateKYC starts a KYC verification for a customer
+func (c *Client) InitiateKYC(req *customerDomain.KYCInitiationRequest) (*customerDomain.KYCRecord, error) {
+	var record customerDomain.KYCRecord
+	if err := c.Submit(ChaincodeCustomer, "InitiateKYC", &record, req); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// UpdateKYCStatus records the outcome of a KYC verification
+func (c *Client) UpdateKYCStatus(req *customerDomain.KYCStatusUpdateRequest) (*customerDomain.KYCRecord, error) {
+	var record customerDomain.KYCRecord
+	if err := c.Submit(ChaincodeCustomer, "UpdateKYCStatus", &record, req); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// GetKYCRecord retrieves a KYC record by ID
+func (c *Client) GetKYCRecord(kycID string) (*customerDomain.KYCRecord, error) {
+	var record customerDomain.KYCRecord
+	if err := c.Evaluate(ChaincodeCustomer, "GetKYCRecord", &record, kycID); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// InitiateAMLCheck starts an AML check for a customer
+func (c *Client) InitiateAMLCheck(req *customerDomain.AMLCheckRequest) (*customerDomain.AMLRecord, error) {
+	var record customerDomain.AMLRecord
+	if err := c.Submit(ChaincodeCustomer, "InitiateAMLCheck", &record, req); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// UpdateAMLStatus records the outcome of an AML check
+func (c *Client) UpdateAMLStatus(req *customerDomain.AMLStatusUpdateRequest) (*customerDomain.AMLRecord, error) {
+	var record customerDomain.AMLRecord
+	if err := c.Submit(ChaincodeCustomer, "UpdateAMLStatus", &record, req); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// GetAMLRecord retrieves an AML record by ID
+func (c *Client) GetAMLRecord(amlID string) (*customerDomain.AMLRecord, error) {
+	var record customerDomain.AMLRecord
+	if err := c.Evaluate(ChaincodeCustomer, "GetAMLRecord", &record, amlID); err != nil {
+		return nil, err
+	}
+	return &record, nil
+}
+
+// GetCustomerComplianceStatus retrieves a customer's KYC and AML standing
+func (c *Client) GetCustomerComplianceStatus(customerID string) (*interfaces.CustomerComplianceStatus, error) {
+	var complianceStatus interfaces.CustomerComplianceStatus
+	if err := c.Evaluate(ChaincodeCustomer, "GetCustomerComplianceStatus", &complianceStatus, customerID); err != nil {
+		return nil, err
+	}
+	return &complianceStatus, nil
+}
+
+// GetCustomer360 retrieves a customer's profile, consents, compliance standing and loan
+// applications in one call, redacted for the invoker's role. The read is logged by a RecordAccess
+// transaction submitted before it.
+func (c *Client) GetCustomer360(customerID string) (*customerDomain.Customer360, error) {
+	var view customerDomain.Customer360
+	if err := c.Evaluate(ChaincodeCustomer, "GetCustomer360", &view, customerID); err != nil {
+		return nil, err
+	}
+	return &view, nil
+}
@@ -0,0 +1,135 @@
+package sdk
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	customerDomain "github.com/brycemacchaveli/origin.block/fabric-chaincode/customer/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/gateway/internal/ledgertest"
+)
+
+// stubContract is a Contract calling the customer chaincode on a mock ledger
+type stubContract struct {
+	*ledgertest.Ledger
+}
+
+func (s *stubContract) SubmitTransaction(name string, args ...string) ([]byte, error) {
+	return s.Submit(name, nil, args)
+}
+
+func (s *stubContract) EvaluateTransaction(name string, args ...string) ([]byte, error) {
+	return s.Evaluate(name, nil, args)
+}
+
+func (s *stubContract) SubmitWithTransient(name string, transient map[string][]byte, args ...string) ([]byte, error) {
+	return s.Submit(name, transient, args)
+}
+
+func (s *stubContract) EvaluateWithTransient(name string, transient map[string][]byte, args ...string) ([]byte, error) {
+	return s.Evaluate(name, transient, args)
+}
+
+// newCustomerContract returns the customer chaincode on a mock ledger, invoked by an administrator
+// bound to ACTOR_001
+func newCustomerContract(t *testing.T) *stubContract {
+	return &stubContract{ledgertest.NewCustomerLedger(t)}
+}
+
+func TestCustomerPersonalDataRoundTripsThroughTransientData(t *testing.T) {
+	contract := newCustomerContract(t)
+	client := New(contract, nil, nil)
+
+	registered, err := client.RegisterCustomer(&customerDomain.CustomerRegistrationRequest{
+		FirstName:          "Ada",
+		LastName:           "Lovelace",
+		Email:              "ada@example.com",
+		Phone:              "+441234567890",
+		DateOfBirth:        time.Date(1985, 12, 10, 0, 0, 0, 0, time.UTC),
+		NationalID:         "NIDADA001",
+		Address:            "12 St James's Square, London",
+		ConsentPreferences: `{"marketing":false}`,
+		ActorID:            "ACTOR_001",
+	})
+	require.NoError(t, err)
+	require.NotEmpty(t, registered.CustomerID)
+
+	updated, err := client.UpdateCustomer(&customerDomain.CustomerUpdateRequest{
+		CustomerID: registered.CustomerID,
+		Email:      stringPtr("ada.lovelace@example.com"),
+		ActorID:    "ACTOR_001",
+	})
+	require.NoError(t, err)
+	assert.Equal(t, "ada.lovelace@example.com", updated.Email)
+
+	// The chaincode stored what travelled in transient data
+	customer, err := client.GetCustomer(registered.CustomerID)
+	require.NoError(t, err)
+	assert.Equal(t, "Ada", customer.FirstName)
+	assert.Equal(t, "Lovelace", customer.LastName)
+	assert.Equal(t, "ada.lovelace@example.com", customer.Email)
+	assert.Equal(t, "+441234567890", customer.Phone)
+	assert.Equal(t, "12 St James's Square, London", customer.Address)
+	assert.True(t, customer.DateOfBirth.Equal(time.Date(1985, 12, 10, 0, 0, 0, 0, time.UTC)))
+
+	// while none of it reached the arguments the transactions record
+	require.GreaterOrEqual(t, len(contract.Recorded), 2)
+	for _, args := range contract.Recorded[:2] {
+		recorded := strings.Join(args, " ")
+		for _, personal := range []string{"Ada", "Lovelace", "ada@example.com", "ada.lovelace@example.com", "+441234567890", "NIDADA001", "St James"} {
+			assert.NotContains(t, recorded, personal)
+		}
+	}
+}
+
+func TestCustomerReadsKeepTheirAccessLog(t *testing.T) {
+	contract := newCustomerContract(t)
+	client := New(contract, nil, nil)
+
+	registered, err := client.RegisterCustomer(&customerDomain.CustomerRegistrationRequest{
+		FirstName:          "Grace",
+		LastName:           "Hopper",
+		Email:              "grace@example.com",
+		Phone:              "+12025550123",
+		DateOfBirth:        time.Date(1976, 12, 9, 0, 0, 0, 0, time.UTC),
+		NationalID:         "NIDGH001",
+		Address:            "1 Navy Yard, Washington",
+		ConsentPreferences: `{"marketing":false}`,
+		ActorID:            "ACTOR_001",
+	})
+	require.NoError(t, err)
+	customerID := registered.CustomerID
+
+	// An evaluated read's log entry is discarded with the rest of its writes
+	_, err = contract.EvaluateTransaction("GetCustomer", customerID)
+	require.NoError(t, err)
+	assert.Empty(t, contract.AccessLog(t, customerID))
+
+	// so the client submits RecordAccess for each read of PII and evaluates the read itself
+	contract.Committed = nil
+	customer, err := client.GetCustomer(customerID)
+	require.NoError(t, err)
+	assert.Equal(t, "Grace", customer.FirstName)
+	require.NoError(t, client.EvaluateTransient(ChaincodeCustomer, "GetConsentHistory", map[string][]byte{"accessPurpose": []byte("AUDIT")}, nil, customerID))
+
+	entries := contract.AccessLog(t, customerID)
+	require.Len(t, entries, 2)
+	functions := map[string]customerDomain.AccessLogEntry{entries[0].Function: entries[0], entries[1].Function: entries[1]}
+	require.Contains(t, functions, "GetCustomer")
+	require.Contains(t, functions, "GetConsentHistory")
+	assert.Equal(t, "AUDIT", functions["GetConsentHistory"].Purpose)
+
+	// and none of the PII reaches a committed response
+	require.Len(t, contract.Committed, 2)
+	for _, committed := range contract.Committed {
+		for _, personal := range []string{"Grace", "Hopper", "grace@example.com", "+12025550123", "NIDGH001", "Navy Yard"} {
+			assert.NotContains(t, string(committed), personal)
+		}
+	}
+}
+
+func stringPtr(s string) *string {
+	return &s
+}
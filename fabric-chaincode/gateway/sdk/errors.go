@@ -0,0 +1,68 @@
+package sdk
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"strings"
+
+	"google.golang.org/grpc/status"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// ParseError returns the error envelope a chaincode rejected a call with, carried in the details
+// of the gateway's gRPC status or in the error's message, or the error itself when no chaincode
+// answered
+func ParseError(err error) error {
+	if err == nil {
+		return nil
+	}
+	var chaincodeErr *services.ChaincodeError
+	if errors.As(err, &chaincodeErr) {
+		return chaincodeErr
+	}
+
+	for _, detail := range status.Convert(err).Details() {
+		if message, ok := detail.(interface{ GetMessage() string }); ok {
+			if envelope := ParseEnvelope(message.GetMessage()); envelope != nil {
+				return envelope
+			}
+		}
+	}
+	if envelope := ParseEnvelope(err.Error()); envelope != nil {
+		return envelope
+	}
+	return err
+}
+
+// ParseEnvelope finds the error envelope in a peer's message, which prefixes the chaincode's
+// response message with the response status
+func ParseEnvelope(message string) *services.ChaincodeError {
+	start := strings.Index(message, "{")
+	if start < 0 {
+		return nil
+	}
+	var envelope services.ChaincodeError
+	if err := json.Unmarshal([]byte(message[start:]), &envelope); err != nil || envelope.Code == "" {
+		return nil
+	}
+	return &envelope
+}
+
+// CommitFailure reports a transaction the peers invalidated as ERR_LEDGER, retryable when it lost
+// a read conflict to another transaction
+func CommitFailure(transactionID string, code fmt.Stringer) *services.ChaincodeError {
+	failure := services.NewChaincodeError(services.ErrCodeLedger, "", "transaction %s was not committed: %s", transactionID, code)
+	failure.Retryable = strings.HasSuffix(code.String(), "READ_CONFLICT")
+	return failure
+}
+
+// ErrorCode returns the code of the chaincode error a call failed with, or an empty string when
+// the call did not reach a chaincode
+func ErrorCode(err error) string {
+	var chaincodeErr *services.ChaincodeError
+	if errors.As(err, &chaincodeErr) {
+		return chaincodeErr.Code
+	}
+	return ""
+}
@@ -0,0 +1,49 @@
+package sdk
+
+import (
+	"errors"
+
+	_ "github.com/brycemacchaveli/origin.block/fabric-chaincode/gateway/protoconflict"
+	"github.com/hyperledger/fabric-gateway/pkg/client"
+)
+
+// NewFromNetwork creates a client calling the chaincodes deployed on a Fabric Gateway network
+// under their default names
+func NewFromNetwork(network *client.Network) *Client {
+	return New(
+		&fabricContract{network.GetContract(ChaincodeCustomer)},
+		&fabricContract{network.GetContract(ChaincodeLoan)},
+		&fabricContract{network.GetContract(ChaincodeCompliance)},
+	)
+}
+
+// NewFromContracts creates a client calling chaincodes deployed under other names
+func NewFromContracts(customer, loan, compliance *client.Contract) *Client {
+	return New(&fabricContract{customer}, &fabricContract{loan}, &fabricContract{compliance})
+}
+
+// fabricContract passes transient data as a proposal option and reports a transaction the peers
+// invalidated as a commit failure
+type fabricContract struct {
+	*client.Contract
+}
+
+func (f *fabricContract) SubmitTransaction(name string, args ...string) ([]byte, error) {
+	return commitResult(f.Contract.SubmitTransaction(name, args...))
+}
+
+func (f *fabricContract) SubmitWithTransient(name string, transient map[string][]byte, args ...string) ([]byte, error) {
+	return commitResult(f.Contract.Submit(name, client.WithArguments(args...), client.WithTransient(transient)))
+}
+
+func (f *fabricContract) EvaluateWithTransient(name string, transient map[string][]byte, args ...string) ([]byte, error) {
+	return f.Contract.Evaluate(name, client.WithArguments(args...), client.WithTransient(transient))
+}
+
+func commitResult(result []byte, err error) ([]byte, error) {
+	var commitErr *client.CommitError
+	if errors.As(err, &commitErr) {
+		return nil, CommitFailure(commitErr.TransactionID, commitErr.Code)
+	}
+	return result, err
+}
@@ -0,0 +1,39 @@
+package sdk
+
+import (
+	loanDomain "github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+)
+
+// SubmitLoanApplication submits a new loan application. A retry carrying the same idempotency
+// key returns the original application.
+func (c *Client) SubmitLoanApplication(req *loanDomain.LoanApplicationRequest) (*loanDomain.LoanApplication, error) {
+	return c.loanApplication(true, "SubmitLoanApplication", req)
+}
+
+// GetLoanApplication retrieves a loan application by ID
+func (c *Client) GetLoanApplication(loanID string) (*loanDomain.LoanApplication, error) {
+	return c.loanApplication(false, "GetLoanApplication", loanID)
+}
+
+// UpdateLoanStatus moves a loan application to a new status
+func (c *Client) UpdateLoanStatus(req *loanDomain.LoanStatusUpdateRequest) (*loanDomain.LoanApplication, error) {
+	return c.loanApplication(true, "UpdateLoanStatus", req)
+}
+
+// ApproveLoan approves a loan application
+func (c *Client) ApproveLoan(req *loanDomain.LoanApprovalRequest) (*loanDomain.LoanApplication, error) {
+	return c.loanApplication(true, "ApproveLoan", req)
+}
+
+// RejectLoan rejects a loan application
+func (c *Client) RejectLoan(req *loanDomain.LoanRejectionRequest) (*loanDomain.LoanApplication, error) {
+	return c.loanApplication(true, "RejectLoan", req)
+}
+
+func (c *Client) loanApplication(submit bool, function string, arg interface{}) (*loanDomain.LoanApplication, error) {
+	var loanApp loanDomain.LoanApplication
+	if err := c.call(submit, ChaincodeLoan, function, nil, &loanApp, []interface{}{arg}); err != nil {
+		return nil, err
+	}
+	return &loanApp, nil
+}
@@ -0,0 +1,57 @@
+package sdk
+
+import (
+	"crypto/hmac"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+	"net/http"
+	"strings"
+	"time"
+)
+
+// Headers a partner signs a gateway request with. The signature is the hex HMAC-SHA256, under the
+// partner's key, of the canonical request METHOD\nPATH\nTIMESTAMP\nNONCE\nHEX(SHA-256(BODY)),
+// where PATH is the request path with its query string and TIMESTAMP is RFC 3339.
+const (
+	HeaderPartnerKeyID       = "X-Partner-Key-Id"
+	HeaderSignatureTimestamp = "X-Signature-Timestamp"
+	HeaderSignatureNonce     = "X-Signature-Nonce"
+	HeaderSignature          = "X-Signature"
+)
+
+// BodyHash returns the hex SHA-256 of a request body
+func BodyHash(body []byte) string {
+	hash := sha256.Sum256(body)
+	return hex.EncodeToString(hash[:])
+}
+
+// CanonicalRequest returns the form of a request that partners sign
+func CanonicalRequest(method, path, timestamp, nonce string, body []byte) string {
+	return strings.Join([]string{strings.ToUpper(method), path, timestamp, nonce, BodyHash(body)}, "\n")
+}
+
+// RequestSignature returns the hex HMAC-SHA256 of a request's canonical form under a partner's key
+func RequestSignature(secret, method, path, timestamp, nonce string, body []byte) string {
+	mac := hmac.New(sha256.New, []byte(secret))
+	mac.Write([]byte(CanonicalRequest(method, path, timestamp, nonce, body)))
+	return hex.EncodeToString(mac.Sum(nil))
+}
+
+// SignRequest signs a partner's request to the gateway with one of its keys, under a fresh nonce
+// and the current time. The body must be the one the request sends.
+func SignRequest(r *http.Request, body []byte, keyID, secret string) error {
+	nonce := make([]byte, 16)
+	if _, err := rand.Read(nonce); err != nil {
+		return fmt.Errorf("failed to generate nonce: %w", err)
+	}
+	timestamp := time.Now().UTC().Format(time.RFC3339)
+	encodedNonce := hex.EncodeToString(nonce)
+
+	r.Header.Set(HeaderPartnerKeyID, keyID)
+	r.Header.Set(HeaderSignatureTimestamp, timestamp)
+	r.Header.Set(HeaderSignatureNonce, encodedNonce)
+	r.Header.Set(HeaderSignature, RequestSignature(secret, r.Method, r.URL.RequestURI(), timestamp, encodedNonce, body))
+	return nil
+}
@@ -0,0 +1,207 @@
+package server
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+// Chaincodes the gateway exposes
+const (
+	ChaincodeCustomer   = "customer"
+	ChaincodeLoan       = "loan"
+	ChaincodeCompliance = "compliance"
+)
+
+// Defaults applied to an unset configuration value
+const (
+	DefaultListenAddress = ":8080"
+	DefaultMaxBodyBytes  = 1 << 20
+	DefaultMaxArguments  = 16
+	DefaultTimeout       = 30 * time.Second
+	DefaultSignatureSkew = 5 * time.Minute // The chaincode accepts evidence within config.TransactionTimeout
+)
+
+// IdentityConfig is a Fabric identity the gateway transacts as
+type IdentityConfig struct {
+	MSPID    string `json:"mspID"`
+	CertPath string `json:"certPath"`
+	KeyPath  string `json:"keyPath"`
+}
+
+// ClientConfig maps an integrator's API key to the actor it acts as and the Fabric identity bound
+// to that actor. Only the SHA-256 of the key is configured.
+type ClientConfig struct {
+	Name       string `json:"name"`
+	APIKeyHash string `json:"apiKeyHash"`
+	ActorID    string `json:"actorID"`
+	Identity   string `json:"identity"`
+	PartnerID  string `json:"partnerID"` // Set for an external partner, whose requests must be signed with one of its keys
+}
+
+// PartnerKeyConfig is a key a partner signs its requests with. A key bound to a TLS client
+// certificate is only accepted over a connection presenting it; a partner that signs nothing
+// authenticates by the certificate alone.
+type PartnerKeyConfig struct {
+	KeyID                 string `json:"keyID"`
+	PartnerID             string `json:"partnerID"`
+	Secret                string `json:"secret"`
+	ClientCertFingerprint string `json:"clientCertFingerprint"` // Hex SHA-256 of the certificate, colons allowed
+}
+
+// Config configures the gateway
+type Config struct {
+	ListenAddress        string                    `json:"listenAddress"`
+	PeerEndpoint         string                    `json:"peerEndpoint"`
+	PeerHostOverride     string                    `json:"peerHostOverride"`
+	TLSCertPath          string                    `json:"tlsCertPath"`
+	Channel              string                    `json:"channel"`
+	ChaincodeNames       map[string]string         `json:"chaincodeNames"` // Deployed name of each chaincode, when it differs
+	Identities           map[string]IdentityConfig `json:"identities"`
+	Clients              []ClientConfig            `json:"clients"`
+	SchemaFiles          map[string]string         `json:"schemaFiles"` // Published API schema of each chaincode, see chaincode.APISchemaDocument
+	MaxBodyBytes         int64                     `json:"maxBodyBytes"`
+	MaxArguments         int                       `json:"maxArguments"`
+	TimeoutSeconds       int                       `json:"timeoutSeconds"`
+	PartnerKeys          []PartnerKeyConfig        `json:"partnerKeys"`
+	ClientCertHeader     string                    `json:"clientCertHeader"` // Header a TLS-terminating proxy passes the client certificate's fingerprint in
+	SignatureSkewSeconds int                       `json:"signatureSkewSeconds"`
+}
+
+// LoadConfig reads the gateway configuration from a JSON file. Relative paths in it are resolved
+// against the file's directory.
+func LoadConfig(path string) (*Config, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read gateway config: %w", err)
+	}
+	var config Config
+	if err := json.Unmarshal(data, &config); err != nil {
+		return nil, fmt.Errorf("failed to parse gateway config: %w", err)
+	}
+
+	base := filepath.Dir(path)
+	resolve := func(p string) string {
+		if p == "" || filepath.IsAbs(p) {
+			return p
+		}
+		return filepath.Join(base, p)
+	}
+	config.TLSCertPath = resolve(config.TLSCertPath)
+	for name, identity := range config.Identities {
+		identity.CertPath = resolve(identity.CertPath)
+		identity.KeyPath = resolve(identity.KeyPath)
+		config.Identities[name] = identity
+	}
+	for chaincode, schemaFile := range config.SchemaFiles {
+		config.SchemaFiles[chaincode] = resolve(schemaFile)
+	}
+
+	config.applyDefaults()
+	if err := config.Validate(); err != nil {
+		return nil, err
+	}
+	return &config, nil
+}
+
+func (c *Config) applyDefaults() {
+	if c.ListenAddress == "" {
+		c.ListenAddress = DefaultListenAddress
+	}
+	if c.MaxBodyBytes <= 0 {
+		c.MaxBodyBytes = DefaultMaxBodyBytes
+	}
+	if c.MaxArguments <= 0 {
+		c.MaxArguments = DefaultMaxArguments
+	}
+}
+
+// Validate checks that every client acts as a configured identity under an actor ID
+func (c *Config) Validate() error {
+	if c.Channel == "" {
+		return fmt.Errorf("channel is required")
+	}
+	if len(c.Clients) == 0 {
+		return fmt.Errorf("at least one client is required")
+	}
+	seen := make(map[string]bool)
+	for _, client := range c.Clients {
+		if client.Name == "" || client.ActorID == "" || client.APIKeyHash == "" {
+			return fmt.Errorf("client %q needs a name, actorID and apiKeyHash", client.Name)
+		}
+		if _, ok := c.Identities[client.Identity]; !ok {
+			return fmt.Errorf("client %s uses unknown identity %q", client.Name, client.Identity)
+		}
+		if seen[client.APIKeyHash] {
+			return fmt.Errorf("client %s shares its API key with another client", client.Name)
+		}
+		seen[client.APIKeyHash] = true
+	}
+	for chaincode := range c.SchemaFiles {
+		if !knownChaincodes[chaincode] {
+			return fmt.Errorf("schema file configured for unknown chaincode %s", chaincode)
+		}
+	}
+
+	partners := make(map[string]bool)
+	keyIDs := make(map[string]bool)
+	for _, key := range c.PartnerKeys {
+		if key.KeyID == "" || key.PartnerID == "" {
+			return fmt.Errorf("partner key %q needs a keyID and partnerID", key.KeyID)
+		}
+		if key.Secret == "" && key.ClientCertFingerprint == "" {
+			return fmt.Errorf("partner key %s needs a secret or clientCertFingerprint", key.KeyID)
+		}
+		if keyIDs[key.KeyID] {
+			return fmt.Errorf("partner key %s is configured twice", key.KeyID)
+		}
+		keyIDs[key.KeyID] = true
+		partners[key.PartnerID] = true
+	}
+	for _, client := range c.Clients {
+		if client.PartnerID != "" && !partners[client.PartnerID] {
+			return fmt.Errorf("client %s acts for partner %s, which has no keys", client.Name, client.PartnerID)
+		}
+	}
+	return nil
+}
+
+// Timeout bounds each request to the peer
+func (c *Config) Timeout() time.Duration {
+	if c.TimeoutSeconds <= 0 {
+		return DefaultTimeout
+	}
+	return time.Duration(c.TimeoutSeconds) * time.Second
+}
+
+// SignatureSkew bounds how far a partner's signature timestamp may be from the gateway's clock
+func (c *Config) SignatureSkew() time.Duration {
+	if c.SignatureSkewSeconds <= 0 {
+		return DefaultSignatureSkew
+	}
+	return time.Duration(c.SignatureSkewSeconds) * time.Second
+}
+
+// DeployedName returns the name a chaincode is deployed under on the channel
+func (c *Config) DeployedName(chaincode string) string {
+	if name, ok := c.ChaincodeNames[chaincode]; ok && name != "" {
+		return name
+	}
+	return chaincode
+}
+
+// HashAPIKey returns the hash an API key is configured by
+func HashAPIKey(apiKey string) string {
+	hash := sha256.Sum256([]byte(apiKey))
+	return hex.EncodeToString(hash[:])
+}
+
+var knownChaincodes = map[string]bool{
+	ChaincodeCustomer:   true,
+	ChaincodeLoan:       true,
+	ChaincodeCompliance: true,
+}
@@ -0,0 +1,256 @@
+package server
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/gateway/sdk"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/chaincode"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+)
+
+// OpenAPIVersion is the version of the OpenAPI specification the gateway documents itself in. The
+// published API schemas use its nullable keyword.
+const OpenAPIVersion = "3.0.3"
+
+// OpenAPIDocument generates the OpenAPI document of the gateway. Each function of a chaincode
+// with a published API schema gets its own endpoints and response schema; the functions of the
+// other chaincodes are documented by the generic endpoint.
+func (s *Server) OpenAPIDocument() map[string]interface{} {
+	paths := map[string]interface{}{}
+
+	chaincodes := make([]string, 0, len(s.schemas))
+	for name := range s.schemas {
+		chaincodes = append(chaincodes, name)
+	}
+	sort.Strings(chaincodes)
+	for _, name := range chaincodes {
+		document := s.schemas[name]
+		for function, schema := range document.Functions {
+			item := openAPIPathItem(name, name+function, function, schema)
+			if name == ChaincodeCustomer && config.AccessLoggedFunctions[function] {
+				documentAccessLogged(item)
+			}
+			paths[fmt.Sprintf("/v1/%s/%s", name, function)] = item
+		}
+	}
+
+	generic := openAPIPathItem("gateway", "Function", "a function", &chaincode.APISchema{})
+	for method, operation := range generic {
+		parameters := []interface{}{
+			map[string]interface{}{"name": "chaincode", "in": "path", "required": true, "schema": map[string]interface{}{"type": "string", "enum": []string{ChaincodeCustomer, ChaincodeLoan, ChaincodeCompliance}}},
+			map[string]interface{}{"name": "function", "in": "path", "required": true, "schema": map[string]interface{}{"type": "string", "pattern": functionNamePattern.String()}},
+		}
+		if method == "get" {
+			parameters = append(parameters, argParameter)
+		}
+		parameters = append(parameters, signatureParameters...)
+		parameters = append(parameters, accessPurposeParameter)
+		operation.(map[string]interface{})["parameters"] = parameters
+	}
+	paths["/v1/{chaincode}/{function}"] = generic
+	paths["/v1/customers/{customerID}/timeline/events"] = timelineEventsPathItem
+
+	return map[string]interface{}{
+		"openapi": OpenAPIVersion,
+		"info": map[string]interface{}{
+			"title":       "origin.block chaincode gateway",
+			"version":     "v1",
+			"description": "REST access to the customer, loan and compliance chaincodes. POST submits a transaction; GET evaluates a function without recording anything on the ledger, except that reads of a customer's PII are logged by a RecordAccess transaction submitted before the read is evaluated.",
+		},
+		"paths": paths,
+		"components": map[string]interface{}{
+			"securitySchemes": map[string]interface{}{
+				"apiKey": map[string]interface{}{"type": "apiKey", "in": "header", "name": APIKeyHeader},
+			},
+			"schemas": map[string]interface{}{
+				"Error": errorSchema,
+			},
+		},
+		"security": []interface{}{map[string]interface{}{"apiKey": []string{}}},
+	}
+}
+
+func (s *Server) handleOpenAPI(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, s.OpenAPIDocument())
+}
+
+var argParameter = map[string]interface{}{
+	"name":        "arg",
+	"in":          "query",
+	"description": "Function arguments in order",
+	"schema":      map[string]interface{}{"type": "array", "items": map[string]interface{}{"type": "string"}},
+	"style":       "form",
+	"explode":     true,
+}
+
+var argumentsSchema = map[string]interface{}{"type": "array", "items": map[string]interface{}{}}
+
+var callBodySchema = map[string]interface{}{
+	"type":                 "object",
+	"required":             []string{"args"},
+	"additionalProperties": false,
+	"properties": map[string]interface{}{
+		"args": argumentsSchema,
+		"transient": map[string]interface{}{
+			"type":                 "object",
+			"description":          "Transient data by field, base64 encoded",
+			"additionalProperties": map[string]interface{}{"type": "string", "format": "byte"},
+		},
+	},
+}
+
+// signatureParameters are the headers a partner's client signs its requests with, see
+// sdk.SignRequest
+var signatureParameters = []interface{}{
+	signatureHeader(sdk.HeaderPartnerKeyID, "Key the request is signed with; omitted when the partner authenticates by client certificate"),
+	signatureHeader(sdk.HeaderSignatureTimestamp, "RFC 3339 time the request was signed at"),
+	signatureHeader(sdk.HeaderSignatureNonce, "Value the partner uses once"),
+	signatureHeader(sdk.HeaderSignature, "Hex HMAC-SHA256 of METHOD\\nPATH\\nTIMESTAMP\\nNONCE\\nHEX(SHA-256(BODY)), where PATH includes the query string"),
+}
+
+// accessPurposeParameter declares the purpose a read of a customer's PII is logged under
+var accessPurposeParameter = map[string]interface{}{
+	"name":        AccessPurposeHeader,
+	"in":          "header",
+	"required":    false,
+	"description": "Reads of a customer's PII only. Purpose the access log records the read under, unless the transient data passes one.",
+	"schema":      map[string]interface{}{"type": "string"},
+}
+
+// documentAccessLogged documents that both endpoints of a function reading a customer's PII
+// evaluate it after submitting the read's access log entry
+func documentAccessLogged(item map[string]interface{}) {
+	for _, operation := range item {
+		operation := operation.(map[string]interface{})
+		parameters := append([]interface{}{}, operation["parameters"].([]interface{})...)
+		operation["parameters"] = append(parameters, accessPurposeParameter)
+		operation["description"] = "Reads a customer's PII. Both methods evaluate the read, so the PII is not committed to the ledger, after submitting a RecordAccess transaction that logs it against the client's actor."
+	}
+}
+
+func signatureHeader(name, description string) map[string]interface{} {
+	return map[string]interface{}{
+		"name":        name,
+		"in":          "header",
+		"required":    false,
+		"description": "Partner clients only. " + description,
+		"schema":      map[string]interface{}{"type": "string"},
+	}
+}
+
+// timelineEventsPathItem documents the server-sent event stream of a customer's timeline
+var timelineEventsPathItem = map[string]interface{}{
+	"get": map[string]interface{}{
+		"operationId": "streamCustomerTimeline",
+		"tags":        []string{"gateway"},
+		"summary":     "Stream a customer's application timeline events",
+		"description": "Server-sent events for each status change and document event of the loan applications the customer is party to, as the transactions commit. Events are not replayed; read GetApplicationTimeline on subscribing.",
+		"parameters": []interface{}{
+			map[string]interface{}{"name": "customerID", "in": "path", "required": true, "schema": map[string]interface{}{"type": "string", "pattern": customerIDPattern.String()}},
+			map[string]interface{}{"name": "loanID", "in": "query", "required": false, "description": "Only events of this application", "schema": map[string]interface{}{"type": "string"}},
+		},
+		"responses": map[string]interface{}{
+			"200": map[string]interface{}{
+				"description": "A text/event-stream whose events are named after the chaincode event and carry a TimelineEvent as data",
+				"content": map[string]interface{}{
+					"text/event-stream": map[string]interface{}{
+						"schema": map[string]interface{}{
+							"type":     "object",
+							"required": []string{"event", "loanID", "timestamp", "transactionID"},
+							"properties": map[string]interface{}{
+								"event":          map[string]interface{}{"type": "string"},
+								"loanID":         map[string]interface{}{"type": "string"},
+								"status":         map[string]interface{}{"type": "string"},
+								"previousStatus": map[string]interface{}{"type": "string"},
+								"documentType":   map[string]interface{}{"type": "string"},
+								"timestamp":      map[string]interface{}{"type": "string"},
+								"transactionID":  map[string]interface{}{"type": "string"},
+							},
+						},
+					},
+				},
+			},
+			"default": map[string]interface{}{
+				"description": "The error envelope of a refused subscription",
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{
+						"schema": map[string]interface{}{"$ref": "#/components/schemas/Error"},
+					},
+				},
+			},
+		},
+	},
+}
+
+var errorSchema = map[string]interface{}{
+	"type":     "object",
+	"required": []string{"error"},
+	"properties": map[string]interface{}{
+		"error": map[string]interface{}{
+			"type":     "object",
+			"required": []string{"code", "message", "retryable"},
+			"properties": map[string]interface{}{
+				"code":      map[string]interface{}{"type": "string"},
+				"message":   map[string]interface{}{"type": "string"},
+				"field":     map[string]interface{}{"type": "string"},
+				"retryable": map[string]interface{}{"type": "boolean"},
+			},
+		},
+	},
+}
+
+// openAPIPathItem documents the submit and evaluate endpoints of a function
+func openAPIPathItem(tag, operation, function string, result *chaincode.APISchema) map[string]interface{} {
+	responses := map[string]interface{}{
+		"200": map[string]interface{}{
+			"description": "The function's response",
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{
+					"schema": map[string]interface{}{
+						"type":     "object",
+						"required": []string{"transactionID", "result"},
+						"properties": map[string]interface{}{
+							"transactionID": map[string]interface{}{"type": "string"},
+							"result":        result,
+						},
+					},
+				},
+			},
+		},
+		"default": map[string]interface{}{
+			"description": "The error envelope of a rejected call",
+			"content": map[string]interface{}{
+				"application/json": map[string]interface{}{
+					"schema": map[string]interface{}{"$ref": "#/components/schemas/Error"},
+				},
+			},
+		},
+	}
+
+	return map[string]interface{}{
+		"post": map[string]interface{}{
+			"operationId": "submit" + operation,
+			"tags":        []string{tag},
+			"summary":     fmt.Sprintf("Submit %s", function),
+			"requestBody": map[string]interface{}{
+				"description": "Function arguments in order; objects are passed JSON encoded. A request object's actorID defaults to the client's actor. Transient data, which the chaincode reads but the ledger does not record, is sent with the arguments as an object of args and transient.",
+				"content": map[string]interface{}{
+					"application/json": map[string]interface{}{
+						"schema": map[string]interface{}{"oneOf": []interface{}{argumentsSchema, callBodySchema}},
+					},
+				},
+			},
+			"parameters": signatureParameters,
+			"responses":   responses,
+		},
+		"get": map[string]interface{}{
+			"operationId": "evaluate" + operation,
+			"tags":        []string{tag},
+			"summary":     fmt.Sprintf("Evaluate %s", function),
+			"parameters":  append([]interface{}{argParameter}, signatureParameters...),
+			"responses":   responses,
+		},
+	}
+}
@@ -0,0 +1,219 @@
+package server
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"regexp"
+
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// functionNamePattern matches chaincode function names, which are exported Go method names
+var functionNamePattern = regexp.MustCompile(`^[A-Z][A-Za-z0-9]{0,63}$`)
+
+// callBody is the object form of a submitted call's body, for calls passing transient data.
+// Transient values are base64, as the peer CLI takes them.
+type callBody struct {
+	Args      []json.RawMessage `json:"args"`
+	Transient map[string]string `json:"transient"`
+}
+
+// readBody reads the body of a call, up to maxBytes
+func readBody(r *http.Request, maxBytes int64) ([]byte, *services.ChaincodeError) {
+	body, err := io.ReadAll(http.MaxBytesReader(nil, r.Body, maxBytes))
+	if err != nil {
+		var tooLarge *http.MaxBytesError
+		if errors.As(err, &tooLarge) {
+			return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "request body exceeds %d bytes", maxBytes)
+		}
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to read request body: %v", err)
+	}
+	return body, nil
+}
+
+// parseBodyArguments reads the arguments of a submitted call from its body: a JSON array whose
+// strings are passed as they are and whose other values, usually request objects, are passed
+// JSON encoded. A call passing transient data sends the array under args of an object whose
+// transient field holds the transient data.
+func parseBodyArguments(body []byte, maxArguments int) ([]string, map[string][]byte, *services.ChaincodeError) {
+	trimmed := bytes.TrimSpace(body)
+	if len(trimmed) == 0 {
+		return []string{}, nil, nil
+	}
+
+	var call callBody
+	if trimmed[0] == '{' {
+		decoder := json.NewDecoder(bytes.NewReader(trimmed))
+		decoder.DisallowUnknownFields()
+		if err := decoder.Decode(&call); err != nil {
+			return nil, nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "request body must be a JSON array of arguments or an object of args and transient: %v", err)
+		}
+	} else if err := json.Unmarshal(trimmed, &call.Args); err != nil {
+		return nil, nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "request body must be a JSON array of arguments: %v", err)
+	}
+	if len(call.Args) > maxArguments {
+		return nil, nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "at most %d arguments are accepted, got %d", maxArguments, len(call.Args))
+	}
+
+	args := make([]string, 0, len(call.Args))
+	for _, value := range call.Args {
+		if bytes.Equal(bytes.TrimSpace(value), []byte("null")) {
+			return nil, nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "arguments must not be null")
+		}
+		var text string
+		if err := json.Unmarshal(value, &text); err == nil {
+			args = append(args, text)
+			continue
+		}
+		args = append(args, string(value))
+	}
+
+	var transient map[string][]byte
+	if len(call.Transient) > 0 {
+		transient = make(map[string][]byte, len(call.Transient))
+		for key, value := range call.Transient {
+			if key == config.RequestSignatureTransientKey {
+				return nil, nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "transient", "transient %s is set by the gateway for signed partner requests", key)
+			}
+			decoded, err := base64.StdEncoding.DecodeString(value)
+			if err != nil {
+				return nil, nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "transient", "transient %s must be base64: %v", key, err)
+			}
+			transient[key] = decoded
+		}
+	}
+	return args, transient, nil
+}
+
+// queryArguments reads the arguments of an evaluated call from its repeated arg query parameter
+func queryArguments(r *http.Request, maxArguments int) ([]string, *services.ChaincodeError) {
+	args := r.URL.Query()["arg"]
+	if len(args) > maxArguments {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "at most %d arguments are accepted, got %d", maxArguments, len(args))
+	}
+	if args == nil {
+		args = []string{}
+	}
+	return args, nil
+}
+
+// personalDataField is where a customer function takes the personal data of its request from
+// transient data, and which request fields carry it
+type personalDataField struct {
+	transientKey string
+	fields       []string
+}
+
+// personalDataFields are the customer functions whose requests carry personal data
+var personalDataFields = map[string]personalDataField{
+	"RegisterCustomer": {config.CustomerPIITransientKey, []string{"firstName", "lastName", "email", "phone", "dateOfBirth", "nationalID", "address"}},
+	"UpdateCustomer":   {config.CustomerContactTransientKey, []string{"email", "phone", "address"}},
+}
+
+// movePersonalData moves the personal data of a customer request into transient data, since the
+// arguments of a transaction are recorded on the ledger. A request whose client already passed
+// the transient field is left for the chaincode to judge.
+func movePersonalData(chaincodeName, function string, args []string, transient map[string][]byte) ([]string, map[string][]byte, *services.ChaincodeError) {
+	personal, ok := personalDataFields[function]
+	if chaincodeName != ChaincodeCustomer || !ok || len(args) == 0 {
+		return args, transient, nil
+	}
+	if _, passed := transient[personal.transientKey]; passed {
+		return args, transient, nil
+	}
+	var request map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(args[0]), &request); err != nil || request == nil {
+		return args, transient, nil
+	}
+
+	moved := make(map[string]json.RawMessage)
+	for _, field := range personal.fields {
+		if value, ok := request[field]; ok {
+			if !bytes.Equal(bytes.TrimSpace(value), []byte("null")) {
+				moved[field] = value
+			}
+			delete(request, field)
+		}
+	}
+	if len(moved) == 0 {
+		return args, transient, nil
+	}
+
+	encodedRequest, err := json.Marshal(request)
+	if err != nil {
+		return nil, nil, services.NewChaincodeError(services.ErrCodeInternal, "", "failed to encode request: %v", err)
+	}
+	encodedData, err := json.Marshal(moved)
+	if err != nil {
+		return nil, nil, services.NewChaincodeError(services.ErrCodeInternal, "", "failed to encode personal data: %v", err)
+	}
+	if transient == nil {
+		transient = make(map[string][]byte, 1)
+	}
+	transient[personal.transientKey] = encodedData
+	return append([]string{string(encodedRequest)}, args[1:]...), transient, nil
+}
+
+// bindAccess attributes a read of a customer's PII to the client's actor, and to the purpose the
+// request declared in the X-Access-Purpose header unless its transient data already carries one.
+// It reports whether the function logs the read, in which case the read is evaluated and its
+// access recorded by a separate RecordAccess transaction.
+func bindAccess(r *http.Request, chaincodeName, function, actorID string, transient map[string][]byte) (map[string][]byte, bool, *services.ChaincodeError) {
+	if chaincodeName != ChaincodeCustomer || !config.AccessLoggedFunctions[function] {
+		return transient, false, nil
+	}
+	if requested, ok := transient[config.AccessActorTransientKey]; ok && string(requested) != actorID {
+		return nil, false, services.NewChaincodeError(services.ErrCodeAccessDenied, config.AccessActorTransientKey, "this client acts as %s and may not act as %s", actorID, string(requested))
+	}
+
+	if transient == nil {
+		transient = make(map[string][]byte, 2)
+	}
+	transient[config.AccessActorTransientKey] = []byte(actorID)
+	if _, passed := transient[config.AccessPurposeTransientKey]; !passed {
+		if purpose := r.Header.Get(AccessPurposeHeader); purpose != "" {
+			transient[config.AccessPurposeTransientKey] = []byte(purpose)
+		}
+	}
+	return transient, true, nil
+}
+
+// bindActor makes a JSON request act as the client's actor. The chaincode checks the actorID of
+// the first argument against the invoking identity, so a request naming no actor is given the
+// client's, and one naming another actor is refused before it reaches the peer.
+func bindActor(args []string, actorID string) ([]string, *services.ChaincodeError) {
+	if len(args) == 0 {
+		return args, nil
+	}
+	var request map[string]json.RawMessage
+	if err := json.Unmarshal([]byte(args[0]), &request); err != nil || request == nil {
+		return args, nil
+	}
+
+	if raw, ok := request["actorID"]; ok {
+		var requested string
+		if err := json.Unmarshal(raw, &requested); err != nil {
+			return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID must be a string")
+		}
+		if requested == actorID {
+			return args, nil
+		}
+		if requested != "" {
+			return nil, services.NewChaincodeError(services.ErrCodeAccessDenied, "actorID", "this client acts as %s and may not act as %s", actorID, requested)
+		}
+	}
+
+	encodedActor, _ := json.Marshal(actorID)
+	request["actorID"] = encodedActor
+	encoded, err := json.Marshal(request)
+	if err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInternal, "", "failed to encode request: %v", err)
+	}
+	bound := append([]string{string(encoded)}, args[1:]...)
+	return bound, nil
+}
@@ -0,0 +1,71 @@
+package server
+
+import (
+	"encoding/json"
+	"net/http"
+
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// Error codes the gateway raises itself, alongside the chaincode codes in services
+const (
+	ErrCodeUnauthenticated = "ERR_UNAUTHENTICATED" // No API key, or one no client is configured with
+	ErrCodeUnavailable     = "ERR_UNAVAILABLE"     // The peer could not be reached; the request may be retried
+)
+
+// InvokeResponse is the body of a successful call. The result is the chaincode's response as JSON,
+// or as a string when the chaincode did not return JSON.
+type InvokeResponse struct {
+	TransactionID string          `json:"transactionID"`
+	Result        json.RawMessage `json:"result"`
+}
+
+// ErrorResponse is the body of a failed call, carrying the chaincode's error envelope or one the
+// gateway raised
+type ErrorResponse struct {
+	Error *services.ChaincodeError `json:"error"`
+}
+
+// errorStatuses map error codes to HTTP statuses
+var errorStatuses = map[string]int{
+	services.ErrCodeInvalidArgument:   http.StatusBadRequest,
+	services.ErrCodeNotFound:          http.StatusNotFound,
+	services.ErrCodeAccessDenied:      http.StatusForbidden,
+	services.ErrCodeInvalidTransition: http.StatusConflict,
+	services.ErrCodeConflict:          http.StatusConflict,
+	services.ErrCodeUnknownFunction:   http.StatusNotFound,
+	services.ErrCodeLedger:            http.StatusServiceUnavailable,
+	services.ErrCodeInternal:          http.StatusInternalServerError,
+	services.ErrorCodeServiceDisabled: http.StatusServiceUnavailable,
+	ErrCodeUnauthenticated:            http.StatusUnauthorized,
+	ErrCodeUnavailable:                http.StatusServiceUnavailable,
+}
+
+// StatusForCode returns the HTTP status a failed call is answered with
+func StatusForCode(code string) int {
+	if status, ok := errorStatuses[code]; ok {
+		return status
+	}
+	return http.StatusInternalServerError
+}
+
+func writeResult(w http.ResponseWriter, result *Result) {
+	body := json.RawMessage(result.Payload)
+	if len(result.Payload) == 0 {
+		body = json.RawMessage("null")
+	} else if !json.Valid(result.Payload) {
+		quoted, _ := json.Marshal(string(result.Payload))
+		body = json.RawMessage(quoted)
+	}
+	writeJSON(w, http.StatusOK, InvokeResponse{TransactionID: result.TransactionID, Result: body})
+}
+
+func writeError(w http.ResponseWriter, err *services.ChaincodeError) {
+	writeJSON(w, StatusForCode(err.Code), ErrorResponse{Error: err})
+}
+
+func writeJSON(w http.ResponseWriter, status int, body interface{}) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	json.NewEncoder(w).Encode(body)
+}
@@ -0,0 +1,252 @@
+package server
+
+import (
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log"
+	"net/http"
+	"os"
+	"strings"
+
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/gateway/sdk"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/chaincode"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// APIKeyHeader carries an integrator's API key
+const APIKeyHeader = "X-API-Key"
+
+// AccessPurposeHeader declares why a request reads a customer's PII, for the access log
+const AccessPurposeHeader = "X-Access-Purpose"
+
+// Result is what the peer returned for a call
+type Result struct {
+	TransactionID string
+	Payload       []byte
+}
+
+// Invoker calls chaincode functions on the channel as one of the configured identities, passing
+// transient data, which may be nil, to the peers without recording it. A call the chaincode
+// rejected fails with its *services.ChaincodeError; any other error means the peer could not be
+// reached.
+type Invoker interface {
+	// Submit endorses a transaction and waits for it to commit
+	Submit(ctx context.Context, identity, chaincode, function string, args []string, transient map[string][]byte) (*Result, error)
+	// Evaluate runs a function on a peer without submitting a transaction
+	Evaluate(ctx context.Context, identity, chaincode, function string, args []string, transient map[string][]byte) (*Result, error)
+}
+
+// Server exposes the customer, loan and compliance chaincode functions over REST:
+//
+//	POST /v1/{chaincode}/{function}  submits a transaction; the body is a JSON array of arguments,
+//	                                 or an object of args and base64 transient data
+//	GET  /v1/{chaincode}/{function}  evaluates a function; arguments are repeated arg parameters
+//	GET  /v1/customers/{customerID}/timeline/events
+//	                                 streams the customer's application timeline events as
+//	                                 server-sent events
+//	GET  /openapi.json               the OpenAPI document of the endpoints
+//	GET  /healthz                    liveness
+//
+// Evaluating does not record anything on the ledger, so functions that change state must be
+// submitted. Reads of a customer's PII are evaluated whichever method requests them, since a
+// submitted response is committed to the block; a RecordAccess transaction submitted first logs
+// the read against the client's actor and the purpose declared in the X-Access-Purpose header,
+// and its response carries no PII. The personal data of customer registrations and updates
+// is moved into transient data to keep it off the ledger. Requests of a partner's client must be
+// signed, and the gateway passes the verified signature to the chaincode in transient data.
+type Server struct {
+	config     *Config
+	invoker    Invoker
+	clients    map[string]ClientConfig
+	schemas    map[string]*chaincode.APISchemaDocument
+	signatures *signatureVerifier
+}
+
+// NewServer creates a gateway server calling the chaincodes through an invoker
+func NewServer(config *Config, invoker Invoker, schemas map[string]*chaincode.APISchemaDocument) *Server {
+	clients := make(map[string]ClientConfig, len(config.Clients))
+	for _, client := range config.Clients {
+		clients[strings.ToLower(client.APIKeyHash)] = client
+	}
+	return &Server{config: config, invoker: invoker, clients: clients, schemas: schemas, signatures: newSignatureVerifier(config)}
+}
+
+// LoadSchemas reads the published API schema document of each chaincode configured with one
+func LoadSchemas(config *Config) (map[string]*chaincode.APISchemaDocument, error) {
+	schemas := make(map[string]*chaincode.APISchemaDocument)
+	for name, path := range config.SchemaFiles {
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s API schema: %w", name, err)
+		}
+		var document chaincode.APISchemaDocument
+		if err := json.Unmarshal(data, &document); err != nil {
+			return nil, fmt.Errorf("failed to parse %s API schema: %w", name, err)
+		}
+		schemas[name] = &document
+	}
+	return schemas, nil
+}
+
+// Handler returns the gateway's HTTP handler
+func (s *Server) Handler() http.Handler {
+	mux := http.NewServeMux()
+	mux.HandleFunc("POST /v1/{chaincode}/{function}", s.handleSubmit)
+	mux.HandleFunc("GET /v1/{chaincode}/{function}", s.handleEvaluate)
+	mux.HandleFunc("GET /v1/customers/{customerID}/timeline/events", s.handleTimelineEvents)
+	mux.HandleFunc("GET /openapi.json", s.handleOpenAPI)
+	mux.HandleFunc("GET /healthz", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, map[string]string{"status": "ok"})
+	})
+	return mux
+}
+
+func (s *Server) handleSubmit(w http.ResponseWriter, r *http.Request) {
+	s.handleCall(w, r, true)
+}
+
+func (s *Server) handleEvaluate(w http.ResponseWriter, r *http.Request) {
+	s.handleCall(w, r, false)
+}
+
+func (s *Server) handleCall(w http.ResponseWriter, r *http.Request, submit bool) {
+	client, err := s.authenticate(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+
+	chaincodeName, function := r.PathValue("chaincode"), r.PathValue("function")
+	if !knownChaincodes[chaincodeName] {
+		writeError(w, services.NewChaincodeError(services.ErrCodeNotFound, "", "unknown chaincode %s", chaincodeName))
+		return
+	}
+	if !functionNamePattern.MatchString(function) {
+		writeError(w, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid function name %q", function))
+		return
+	}
+	if schema, ok := s.schemas[chaincodeName]; ok {
+		if _, published := schema.Functions[function]; !published {
+			writeError(w, services.NewChaincodeError(services.ErrCodeUnknownFunction, "", "function %s not found", function))
+			return
+		}
+	}
+
+	body, err := readBody(r, s.config.MaxBodyBytes)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	var args []string
+	var transient map[string][]byte
+	if submit {
+		args, transient, err = parseBodyArguments(body, s.config.MaxArguments)
+	} else {
+		args, err = queryArguments(r, s.config.MaxArguments)
+	}
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if args, err = bindActor(args, client.ActorID); err != nil {
+		writeError(w, err)
+		return
+	}
+	if args, transient, err = movePersonalData(chaincodeName, function, args, transient); err != nil {
+		writeError(w, err)
+		return
+	}
+	var logged bool
+	if transient, logged, err = bindAccess(r, chaincodeName, function, client.ActorID, transient); err != nil {
+		writeError(w, err)
+		return
+	}
+	var evidence *services.RequestSignature
+	if client.PartnerID != "" {
+		if evidence, err = s.signatures.verify(r, client, body); err != nil {
+			writeError(w, err)
+			return
+		}
+		if transient, err = bindSignature(evidence, function, args, transient); err != nil {
+			writeError(w, err)
+			return
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(r.Context(), s.config.Timeout())
+	defer cancel()
+	if logged {
+		// A submitted response is committed to the block, so a read of PII is evaluated and its
+		// access logged by a RecordAccess transaction whose response carries none
+		if err := s.recordAccess(ctx, r, client, function, args, transient, evidence); err != nil {
+			writeError(w, err)
+			return
+		}
+		submit = false
+	}
+	call := s.invoker.Evaluate
+	if submit {
+		call = s.invoker.Submit
+	}
+	result, callErr := call(ctx, client.Identity, s.config.DeployedName(chaincodeName), function, args, transient)
+	if callErr != nil {
+		writeError(w, callError(r, client, chaincodeName, function, callErr))
+		return
+	}
+	writeResult(w, result)
+}
+
+// recordAccess submits the access log entry of a read of a customer's PII, with the actor and
+// purpose bound to the read and, for a partner, the read's signature evidence bound to it
+func (s *Server) recordAccess(ctx context.Context, r *http.Request, client *ClientConfig, function string, args []string, transient map[string][]byte, evidence *services.RequestSignature) *services.ChaincodeError {
+	recordArgs := []string{sdk.AccessedCustomerID(args), function}
+	access := make(map[string][]byte, 3)
+	for _, key := range []string{config.AccessActorTransientKey, config.AccessPurposeTransientKey} {
+		if value, ok := transient[key]; ok {
+			access[key] = value
+		}
+	}
+	if evidence != nil {
+		recordEvidence := *evidence
+		var err *services.ChaincodeError
+		if access, err = bindSignature(&recordEvidence, config.RecordAccessFunction, recordArgs, access); err != nil {
+			return err
+		}
+	}
+
+	if _, err := s.invoker.Submit(ctx, client.Identity, s.config.DeployedName(ChaincodeCustomer), config.RecordAccessFunction, recordArgs, access); err != nil {
+		return callError(r, client, ChaincodeCustomer, config.RecordAccessFunction, err)
+	}
+	return nil
+}
+
+// callError returns the error the chaincode rejected a call with, or an unavailable error when
+// the peer could not be reached
+func callError(r *http.Request, client *ClientConfig, chaincodeName, function string, callErr error) *services.ChaincodeError {
+	var chaincodeErr *services.ChaincodeError
+	if !errors.As(callErr, &chaincodeErr) {
+		log.Printf("%s %s/%s for %s failed: %v", r.Method, chaincodeName, function, client.Name, callErr)
+		chaincodeErr = services.NewChaincodeError(ErrCodeUnavailable, "", "the peer could not complete the request")
+		chaincodeErr.Retryable = true
+	}
+	return chaincodeErr
+}
+
+// authenticate finds the client an API key was issued to
+func (s *Server) authenticate(r *http.Request) (*ClientConfig, *services.ChaincodeError) {
+	apiKey := r.Header.Get(APIKeyHeader)
+	if apiKey == "" {
+		return nil, services.NewChaincodeError(ErrCodeUnauthenticated, "", "the %s header is required", APIKeyHeader)
+	}
+	hash := HashAPIKey(apiKey)
+	for configured, client := range s.clients {
+		if subtle.ConstantTimeCompare([]byte(configured), []byte(hash)) == 1 {
+			return &client, nil
+		}
+	}
+	return nil, services.NewChaincodeError(ErrCodeUnauthenticated, "", "unknown API key")
+}
@@ -0,0 +1,590 @@
+package server
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/gateway/internal/ledgertest"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/gateway/sdk"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/chaincode"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/interfaces"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+type recordedCall struct {
+	submit    bool
+	identity  string
+	chaincode string
+	function  string
+	args      []string
+	transient map[string][]byte
+}
+
+// fakeInvoker records calls and answers them with a fixed payload or error
+type fakeInvoker struct {
+	calls   []recordedCall
+	payload []byte
+	err     error
+}
+
+func (f *fakeInvoker) call(submit bool, identity, chaincodeName, function string, args []string, transient map[string][]byte) (*Result, error) {
+	f.calls = append(f.calls, recordedCall{submit: submit, identity: identity, chaincode: chaincodeName, function: function, args: args, transient: transient})
+	if f.err != nil {
+		return nil, f.err
+	}
+	return &Result{TransactionID: fmt.Sprintf("tx%d", len(f.calls)), Payload: f.payload}, nil
+}
+
+func (f *fakeInvoker) Submit(ctx context.Context, identity, chaincodeName, function string, args []string, transient map[string][]byte) (*Result, error) {
+	return f.call(true, identity, chaincodeName, function, args, transient)
+}
+
+func (f *fakeInvoker) Evaluate(ctx context.Context, identity, chaincodeName, function string, args []string, transient map[string][]byte) (*Result, error) {
+	return f.call(false, identity, chaincodeName, function, args, transient)
+}
+
+func newTestServer(t *testing.T) (*fakeInvoker, http.Handler) {
+	config := &Config{
+		Channel:        "mychannel",
+		ChaincodeNames: map[string]string{ChaincodeLoan: "loan_v2"},
+		Identities:     map[string]IdentityConfig{"underwriting": {MSPID: "Org1MSP"}},
+		Clients:        []ClientConfig{{Name: "core-banking", APIKeyHash: HashAPIKey("secret-key"), ActorID: "ACTOR_001", Identity: "underwriting"}},
+	}
+	config.applyDefaults()
+	require.NoError(t, config.Validate())
+
+	schemas := map[string]*chaincode.APISchemaDocument{
+		ChaincodeCustomer: {Chaincode: ChaincodeCustomer, Functions: map[string]*chaincode.APISchema{
+			"GetCustomer":      {Type: chaincode.APITypeObject},
+			"RegisterCustomer": {Type: chaincode.APITypeObject},
+			"UpdateCustomer":   {Type: chaincode.APITypeObject},
+		}},
+	}
+	invoker := &fakeInvoker{payload: []byte(`{"customerID":"CUST_1"}`)}
+	return invoker, NewServer(config, invoker, schemas).Handler()
+}
+
+func serve(handler http.Handler, method, target, apiKey, body string) (*httptest.ResponseRecorder, map[string]interface{}) {
+	request := httptest.NewRequest(method, target, strings.NewReader(body))
+	if apiKey != "" {
+		request.Header.Set(APIKeyHeader, apiKey)
+	}
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	var decoded map[string]interface{}
+	json.Unmarshal(recorder.Body.Bytes(), &decoded)
+	return recorder, decoded
+}
+
+func errorCode(body map[string]interface{}) interface{} {
+	envelope, _ := body["error"].(map[string]interface{})
+	return envelope["code"]
+}
+
+func TestGatewaySubmitsAsTheClientsActor(t *testing.T) {
+	invoker, handler := newTestServer(t)
+
+	recorder, body := serve(handler, http.MethodPost, "/v1/customer/RegisterCustomer", "secret-key", `[{"firstName":"Ada","consent":{"marketing":true}}]`)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.Equal(t, "tx1", body["transactionID"])
+	assert.Equal(t, map[string]interface{}{"customerID": "CUST_1"}, body["result"])
+
+	require.Len(t, invoker.calls, 1)
+	call := invoker.calls[0]
+	assert.True(t, call.submit)
+	assert.Equal(t, "underwriting", call.identity)
+	assert.Equal(t, "customer", call.chaincode)
+	assert.Equal(t, "RegisterCustomer", call.function)
+	var request map[string]interface{}
+	require.NoError(t, json.Unmarshal([]byte(call.args[0]), &request))
+	assert.Equal(t, "ACTOR_001", request["actorID"])
+	assert.Equal(t, map[string]interface{}{"marketing": true}, request["consent"])
+
+	// A request naming another actor never reaches the peer
+	recorder, body = serve(handler, http.MethodPost, "/v1/customer/RegisterCustomer", "secret-key", `[{"firstName":"Ada","actorID":"ACTOR_002"}]`)
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	assert.Equal(t, services.ErrCodeAccessDenied, errorCode(body))
+	assert.Len(t, invoker.calls, 1)
+
+	// Chaincodes are called by their deployed names, and string arguments pass as they are
+	recorder, _ = serve(handler, http.MethodPost, "/v1/loan/ApproveLoan", "secret-key", `["LOAN_1", 2]`)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, "loan_v2", invoker.calls[1].chaincode)
+	assert.Equal(t, []string{"LOAN_1", "2"}, invoker.calls[1].args)
+}
+
+func TestGatewayEvaluatesWithQueryArguments(t *testing.T) {
+	invoker, handler := newTestServer(t)
+
+	recorder, _ := serve(handler, http.MethodGet, "/v1/loan/GetLoanApplication?arg=LOAN_1", "secret-key", "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	require.Len(t, invoker.calls, 1)
+	assert.False(t, invoker.calls[0].submit)
+	assert.Equal(t, []string{"LOAN_1"}, invoker.calls[0].args)
+}
+
+func TestGatewayEvaluatesAccessLoggedReads(t *testing.T) {
+	invoker, handler := newTestServer(t)
+
+	// A read of PII is evaluated, after a RecordAccess transaction logs it against the client's
+	// actor and the purpose the request declared
+	request := httptest.NewRequest(http.MethodGet, "/v1/customer/GetCustomer?arg=CUST_1", nil)
+	request.Header.Set(APIKeyHeader, "secret-key")
+	request.Header.Set(AccessPurposeHeader, "KYC_REVIEW")
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	require.Len(t, invoker.calls, 2)
+	access := map[string][]byte{
+		config.AccessActorTransientKey:   []byte("ACTOR_001"),
+		config.AccessPurposeTransientKey: []byte("KYC_REVIEW"),
+	}
+	assert.Equal(t, recordedCall{submit: true, identity: "underwriting", chaincode: "customer", function: "RecordAccess", args: []string{"CUST_1", "GetCustomer"}, transient: access}, invoker.calls[0])
+	assert.False(t, invoker.calls[1].submit)
+	assert.Equal(t, "GetCustomer", invoker.calls[1].function)
+	assert.Equal(t, []string{"CUST_1"}, invoker.calls[1].args)
+	assert.Equal(t, access, invoker.calls[1].transient)
+
+	// A POST is evaluated too, and a purpose passed in transient data stands, but the read is never
+	// attributed to another actor
+	purpose := base64.StdEncoding.EncodeToString([]byte("AUDIT"))
+	recorder, _ = serve(handler, http.MethodPost, "/v1/customer/GetCustomer", "secret-key", `{"args":["CUST_1"],"transient":{"accessPurpose":"`+purpose+`"}}`)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	require.Len(t, invoker.calls, 4)
+	assert.Equal(t, "RecordAccess", invoker.calls[2].function)
+	assert.Equal(t, []byte("AUDIT"), invoker.calls[2].transient[config.AccessPurposeTransientKey])
+	assert.False(t, invoker.calls[3].submit)
+	assert.Equal(t, []byte("ACTOR_001"), invoker.calls[3].transient[config.AccessActorTransientKey])
+
+	otherActor := base64.StdEncoding.EncodeToString([]byte("ACTOR_002"))
+	recorder, body := serve(handler, http.MethodPost, "/v1/customer/GetCustomer", "secret-key", `{"args":["CUST_1"],"transient":{"accessActorID":"`+otherActor+`"}}`)
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	assert.Equal(t, services.ErrCodeAccessDenied, errorCode(body))
+	assert.Len(t, invoker.calls, 4)
+
+	// A read whose access cannot be recorded is not made
+	invoker.err = services.NewChaincodeError(services.ErrCodeNotFound, "", "customer CUST_9 not found")
+	recorder, _ = serve(handler, http.MethodGet, "/v1/customer/GetCustomer?arg=CUST_9", "secret-key", "")
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	require.Len(t, invoker.calls, 5)
+	assert.Equal(t, "RecordAccess", invoker.calls[4].function)
+}
+
+// ledgerInvoker is an Invoker calling the customer chaincode on a mock ledger
+type ledgerInvoker struct {
+	*ledgertest.Ledger
+}
+
+func (l ledgerInvoker) Submit(ctx context.Context, identity, chaincodeName, function string, args []string, transient map[string][]byte) (*Result, error) {
+	payload, err := l.Ledger.Submit(function, transient, args)
+	if err != nil {
+		return nil, sdk.ParseError(err)
+	}
+	return &Result{TransactionID: "tx", Payload: payload}, nil
+}
+
+func (l ledgerInvoker) Evaluate(ctx context.Context, identity, chaincodeName, function string, args []string, transient map[string][]byte) (*Result, error) {
+	payload, err := l.Ledger.Evaluate(function, transient, args)
+	if err != nil {
+		return nil, sdk.ParseError(err)
+	}
+	return &Result{Payload: payload}, nil
+}
+
+func TestGatewayKeepsReadPIIOffTheLedger(t *testing.T) {
+	ledger := ledgertest.NewCustomerLedger(t)
+	config := &Config{
+		Channel:    "mychannel",
+		Identities: map[string]IdentityConfig{"underwriting": {MSPID: "Org1MSP"}},
+		Clients:    []ClientConfig{{Name: "core-banking", APIKeyHash: HashAPIKey("secret-key"), ActorID: "ACTOR_001", Identity: "underwriting"}},
+	}
+	config.applyDefaults()
+	require.NoError(t, config.Validate())
+	handler := NewServer(config, ledgerInvoker{ledger}, nil).Handler()
+
+	recorder, body := serve(handler, http.MethodPost, "/v1/customer/RegisterCustomer", "secret-key", `[{"firstName":"Katherine","lastName":"Johnson","email":"katherine@example.com","phone":"+17575550100","nationalID":"NIDKJ001","address":"3 Langley Field, Hampton","dateOfBirth":"1988-08-26T00:00:00Z","consentPreferences":"{\"marketing\":false}"}]`)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	customerID := body["result"].(map[string]interface{})["customerID"].(string)
+
+	ledger.Committed = nil
+	for _, method := range []string{http.MethodGet, http.MethodPost} {
+		request := httptest.NewRequest(method, "/v1/customer/GetCustomer?arg="+customerID, strings.NewReader(`["`+customerID+`"]`))
+		request.Header.Set(APIKeyHeader, "secret-key")
+		request.Header.Set(AccessPurposeHeader, "CUSTOMER_SERVICE")
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+		assert.Contains(t, recorder.Body.String(), "Katherine", method)
+	}
+
+	// The reads are logged, but only RecordAccess responses are committed and they carry no PII
+	entries := ledger.AccessLog(t, customerID)
+	require.Len(t, entries, 2)
+	for _, entry := range entries {
+		assert.Equal(t, "GetCustomer", entry.Function)
+		assert.Equal(t, "CUSTOMER_SERVICE", entry.Purpose)
+	}
+	require.Len(t, ledger.Committed, 2)
+	for _, committed := range ledger.Committed {
+		for _, personal := range []string{"Katherine", "Johnson", "katherine@example.com", "+17575550100", "NIDKJ001", "Langley Field"} {
+			assert.NotContains(t, string(committed), personal)
+		}
+	}
+}
+
+func TestGatewayMovesCustomerPersonalDataToTransient(t *testing.T) {
+	invoker, handler := newTestServer(t)
+
+	recorder, _ := serve(handler, http.MethodPost, "/v1/customer/RegisterCustomer", "secret-key", `[{"firstName":"Ada","lastName":"Lovelace","nationalID":"NID-1","dateOfBirth":"1990-01-01T00:00:00Z","consentPreferences":"{}"}]`)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	call := invoker.calls[0]
+	assert.NotContains(t, call.args[0], "Ada")
+	assert.NotContains(t, call.args[0], "NID-1")
+	assert.Contains(t, call.args[0], "consentPreferences")
+	var pii map[string]interface{}
+	require.NoError(t, json.Unmarshal(call.transient["customerPII"], &pii))
+	assert.Equal(t, map[string]interface{}{"firstName": "Ada", "lastName": "Lovelace", "nationalID": "NID-1", "dateOfBirth": "1990-01-01T00:00:00Z"}, pii)
+
+	// An update moves only the contact details the chaincode takes from transient data
+	recorder, _ = serve(handler, http.MethodPost, "/v1/customer/UpdateCustomer", "secret-key", `[{"customerID":"CUST_1","email":"ada@example.com","firstName":"Ada"}]`)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	call = invoker.calls[1]
+	assert.NotContains(t, call.args[0], "ada@example.com")
+	assert.JSONEq(t, `{"email":"ada@example.com"}`, string(call.transient["customerContact"]))
+
+	// Other functions, and requests without personal data, pass as they are
+	recorder, _ = serve(handler, http.MethodPost, "/v1/loan/ApproveLoan", "secret-key", `[{"loanID":"LOAN_1","email":"x"}]`)
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Contains(t, invoker.calls[2].args[0], "email")
+	assert.Nil(t, invoker.calls[2].transient)
+}
+
+func TestGatewayPassesTransientData(t *testing.T) {
+	invoker, handler := newTestServer(t)
+
+	pii := base64.StdEncoding.EncodeToString([]byte(`{"firstName":"Ada"}`))
+	recorder, _ := serve(handler, http.MethodPost, "/v1/customer/RegisterCustomer", "secret-key", `{"args":[{"residency":"DE"}],"transient":{"customerPII":"`+pii+`"}}`)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	require.Len(t, invoker.calls, 1)
+	assert.Equal(t, map[string][]byte{"customerPII": []byte(`{"firstName":"Ada"}`)}, invoker.calls[0].transient)
+	assert.NotContains(t, invoker.calls[0].args[0], "Ada")
+
+	// Transient values must be base64, and the signature evidence only ever comes from the gateway
+	recorder, body := serve(handler, http.MethodPost, "/v1/customer/RegisterCustomer", "secret-key", `{"args":[],"transient":{"customerPII":"not base64!"}}`)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Equal(t, services.ErrCodeInvalidArgument, errorCode(body))
+	recorder, _ = serve(handler, http.MethodPost, "/v1/customer/RegisterCustomer", "secret-key", `{"args":[],"transient":{"requestSignature":"e30="}}`)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	recorder, _ = serve(handler, http.MethodPost, "/v1/customer/RegisterCustomer", "secret-key", `{"arguments":[]}`)
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+	assert.Len(t, invoker.calls, 1)
+}
+
+// newPartnerServer serves a partner client whose requests must be signed with key-1, or sent over
+// the TLS client certificate bound to key-2
+func newPartnerServer(t *testing.T, now time.Time) (*fakeInvoker, http.Handler) {
+	config := &Config{
+		Channel:    "mychannel",
+		Identities: map[string]IdentityConfig{"introducer": {MSPID: "PartnerMSP"}},
+		Clients:    []ClientConfig{{Name: "broker", APIKeyHash: HashAPIKey("partner-key"), ActorID: "ACTOR_INTRO", Identity: "introducer", PartnerID: "PARTNER_1"}},
+		PartnerKeys: []PartnerKeyConfig{
+			{KeyID: "key-1", PartnerID: "PARTNER_1", Secret: "shared-secret"},
+			{KeyID: "key-2", PartnerID: "PARTNER_1", ClientCertFingerprint: "AB:CD:EF"},
+		},
+		ClientCertHeader: "X-Client-Cert-Fingerprint",
+	}
+	config.applyDefaults()
+	require.NoError(t, config.Validate())
+
+	invoker := &fakeInvoker{payload: []byte(`{}`)}
+	gateway := NewServer(config, invoker, nil)
+	gateway.signatures.now = func() time.Time { return now }
+	return invoker, gateway.Handler()
+}
+
+func TestGatewayVerifiesPartnerSignatures(t *testing.T) {
+	invoker, handler := newPartnerServer(t, time.Now())
+
+	body := `[{"customerID":"CUST_1","purpose":"LOAN_ORIGINATION"}]`
+	signed := func() *http.Request {
+		request := httptest.NewRequest(http.MethodPost, "/v1/customer/RecordDisclosure", strings.NewReader(body))
+		request.Header.Set(APIKeyHeader, "partner-key")
+		require.NoError(t, sdk.SignRequest(request, []byte(body), "key-1", "shared-secret"))
+		return request
+	}
+
+	request := signed()
+	recorder := httptest.NewRecorder()
+	handler.ServeHTTP(recorder, request)
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+
+	// The evidence travels in transient data, bound to the arguments as the gateway built them
+	require.Len(t, invoker.calls, 1)
+	var evidence services.RequestSignature
+	require.NoError(t, json.Unmarshal(invoker.calls[0].transient[config.RequestSignatureTransientKey], &evidence))
+	payloadHash, err := services.PayloadHash("RecordDisclosure", invoker.calls[0].args)
+	require.NoError(t, err)
+	assert.Equal(t, "PARTNER_1", evidence.PartnerID)
+	assert.Equal(t, services.RequestSignatureHMAC, evidence.Algorithm)
+	assert.Equal(t, "key-1", evidence.KeyID)
+	assert.Equal(t, payloadHash, evidence.PayloadHash)
+	assert.Equal(t, sdk.BodyHash([]byte(body)), evidence.BodyHash)
+	assert.Equal(t, request.Header.Get(sdk.HeaderSignature), evidence.Signature)
+
+	// A replayed request reuses its nonce
+	replay := httptest.NewRequest(http.MethodPost, "/v1/customer/RecordDisclosure", strings.NewReader(body))
+	replay.Header = request.Header.Clone()
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, replay)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	// A body changed after signing, or an unsigned request, never reaches the peer
+	tampered := signed()
+	tampered.Body = io.NopCloser(strings.NewReader(`[{"customerID":"CUST_2"}]`))
+	recorder = httptest.NewRecorder()
+	handler.ServeHTTP(recorder, tampered)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+
+	recorder, responseBody := serve(handler, http.MethodPost, "/v1/customer/RecordDisclosure", "partner-key", body)
+	assert.Equal(t, http.StatusUnauthorized, recorder.Code)
+	assert.Equal(t, ErrCodeUnauthenticated, errorCode(responseBody))
+	assert.Len(t, invoker.calls, 1)
+}
+
+func TestGatewayAcceptsPartnerClientCertificate(t *testing.T) {
+	now := time.Date(2026, 5, 4, 10, 0, 0, 0, time.UTC)
+	invoker, handler := newPartnerServer(t, now)
+
+	send := func(fingerprint, timestamp, nonce string) int {
+		request := httptest.NewRequest(http.MethodGet, "/v1/customer/GetCustomer?arg=CUST_1", nil)
+		request.Header.Set(APIKeyHeader, "partner-key")
+		request.Header.Set("X-Client-Cert-Fingerprint", fingerprint)
+		request.Header.Set(sdk.HeaderSignatureTimestamp, timestamp)
+		request.Header.Set(sdk.HeaderSignatureNonce, nonce)
+		recorder := httptest.NewRecorder()
+		handler.ServeHTTP(recorder, request)
+		return recorder.Code
+	}
+
+	require.Equal(t, http.StatusOK, send("abcdef", now.Format(time.RFC3339), "n1"))
+	require.Len(t, invoker.calls, 2)
+	var evidence services.RequestSignature
+	require.NoError(t, json.Unmarshal(invoker.calls[1].transient[config.RequestSignatureTransientKey], &evidence))
+	assert.Equal(t, services.RequestSignatureMTLS, evidence.Algorithm)
+	assert.Equal(t, "key-2", evidence.KeyID)
+	assert.Equal(t, "abcdef", evidence.ClientCertFingerprint)
+	assert.Equal(t, "/v1/customer/GetCustomer?arg=CUST_1", evidence.Path)
+	readHash, err := services.PayloadHash("GetCustomer", []string{"CUST_1"})
+	require.NoError(t, err)
+	assert.Equal(t, readHash, evidence.PayloadHash)
+
+	// The RecordAccess logging the read carries the same evidence, bound to its own payload
+	var recordEvidence services.RequestSignature
+	require.NoError(t, json.Unmarshal(invoker.calls[0].transient[config.RequestSignatureTransientKey], &recordEvidence))
+	recordHash, err := services.PayloadHash("RecordAccess", []string{"CUST_1", "GetCustomer"})
+	require.NoError(t, err)
+	assert.Equal(t, recordHash, recordEvidence.PayloadHash)
+	assert.Equal(t, evidence.Nonce, recordEvidence.Nonce)
+
+	assert.Equal(t, http.StatusUnauthorized, send("012345", now.Format(time.RFC3339), "n2"))
+	assert.Equal(t, http.StatusUnauthorized, send("abcdef", now.Add(-10*time.Minute).Format(time.RFC3339), "n3"))
+	assert.Len(t, invoker.calls, 2)
+}
+
+func TestGatewayValidatesRequests(t *testing.T) {
+	invoker, handler := newTestServer(t)
+
+	tests := []struct {
+		name   string
+		method string
+		target string
+		apiKey string
+		body   string
+		status int
+		code   string
+	}{
+		{"missing API key", http.MethodPost, "/v1/customer/RegisterCustomer", "", "[]", http.StatusUnauthorized, ErrCodeUnauthenticated},
+		{"unknown API key", http.MethodPost, "/v1/customer/RegisterCustomer", "wrong-key", "[]", http.StatusUnauthorized, ErrCodeUnauthenticated},
+		{"unknown chaincode", http.MethodPost, "/v1/treasury/GetBalance", "secret-key", "[]", http.StatusNotFound, services.ErrCodeNotFound},
+		{"invalid function name", http.MethodPost, "/v1/loan/approve_loan", "secret-key", "[]", http.StatusBadRequest, services.ErrCodeInvalidArgument},
+		{"unpublished function", http.MethodPost, "/v1/customer/DropCustomers", "secret-key", "[]", http.StatusNotFound, services.ErrCodeUnknownFunction},
+		{"body not an array", http.MethodPost, "/v1/customer/RegisterCustomer", "secret-key", `{"firstName":"Ada"}`, http.StatusBadRequest, services.ErrCodeInvalidArgument},
+		{"null argument", http.MethodPost, "/v1/customer/RegisterCustomer", "secret-key", `[null]`, http.StatusBadRequest, services.ErrCodeInvalidArgument},
+		{"too many arguments", http.MethodPost, "/v1/loan/ApproveLoan", "secret-key", "[" + strings.Repeat(`"a",`, DefaultMaxArguments) + `"a"]`, http.StatusBadRequest, services.ErrCodeInvalidArgument},
+		{"body too large", http.MethodPost, "/v1/loan/ApproveLoan", "secret-key", `["` + strings.Repeat("a", DefaultMaxBodyBytes) + `"]`, http.StatusBadRequest, services.ErrCodeInvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			recorder, body := serve(handler, tt.method, tt.target, tt.apiKey, tt.body)
+			assert.Equal(t, tt.status, recorder.Code, recorder.Body.String())
+			assert.Equal(t, tt.code, errorCode(body))
+		})
+	}
+	assert.Empty(t, invoker.calls)
+}
+
+func TestGatewayMapsChaincodeErrors(t *testing.T) {
+	invoker, handler := newTestServer(t)
+
+	invoker.err = services.NewChaincodeError(services.ErrCodeNotFound, "", "customer not found")
+	recorder, body := serve(handler, http.MethodGet, "/v1/customer/GetCustomer?arg=CUST_MISSING", "secret-key", "")
+	assert.Equal(t, http.StatusNotFound, recorder.Code)
+	assert.Equal(t, services.ErrCodeNotFound, errorCode(body))
+
+	invoker.err = services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "loan cannot be approved from current status: SUBMITTED")
+	recorder, _ = serve(handler, http.MethodPost, "/v1/loan/ApproveLoan", "secret-key", `[{"loanID":"LOAN_1"}]`)
+	assert.Equal(t, http.StatusConflict, recorder.Code)
+
+	// A peer that cannot be reached is retryable, without leaking the cause
+	invoker.err = fmt.Errorf("connection refused: peer0.org1.example.com:7051")
+	recorder, body = serve(handler, http.MethodGet, "/v1/customer/GetCustomer?arg=CUST_1", "secret-key", "")
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	envelope := body["error"].(map[string]interface{})
+	assert.Equal(t, ErrCodeUnavailable, envelope["code"])
+	assert.Equal(t, true, envelope["retryable"])
+	assert.NotContains(t, envelope["message"], "peer0")
+}
+
+// eventInvoker is a fakeInvoker that is also an event source, streaming the events queued on it
+type eventInvoker struct {
+	fakeInvoker
+	events    chan *ChaincodeEvent
+	chaincode string
+}
+
+func (e *eventInvoker) ChaincodeEvents(ctx context.Context, identity, chaincodeName string) (<-chan *ChaincodeEvent, error) {
+	e.chaincode = chaincodeName
+	return e.events, nil
+}
+
+// loanEvent encodes a loan chaincode event the way the chaincode emits it
+func loanEvent(t *testing.T, txID, name, entityID, entityType string, metadata map[string]string) *ChaincodeEvent {
+	payload, err := json.Marshal(interfaces.VersionedEvent{
+		EventHeader: services.NewEventHeader(name),
+		EventPayload: interfaces.EventPayload{
+			EventType:  name,
+			EntityID:   entityID,
+			EntityType: entityType,
+			ActorID:    "ACTOR_UW",
+			Timestamp:  "2026-05-04T10:00:00Z",
+			Data:       map[string]interface{}{"riskScore": 640, "notes": "internal"},
+			Metadata:   metadata,
+		},
+	})
+	require.NoError(t, err)
+	return &ChaincodeEvent{TransactionID: txID, EventName: name, Payload: payload}
+}
+
+// subscribe streams a customer's timeline from a source whose queued events end the stream
+func subscribe(t *testing.T, events []*ChaincodeEvent, target string) (*eventInvoker, *httptest.ResponseRecorder, []TimelineEvent) {
+	config := &Config{
+		Channel:        "mychannel",
+		ChaincodeNames: map[string]string{ChaincodeLoan: "loan_v2"},
+		Identities:     map[string]IdentityConfig{"portal": {MSPID: "Org1MSP"}},
+		Clients:        []ClientConfig{{Name: "borrower-portal", APIKeyHash: HashAPIKey("portal-key"), ActorID: "ACTOR_PORTAL", Identity: "portal"}},
+	}
+	config.applyDefaults()
+	require.NoError(t, config.Validate())
+
+	invoker := &eventInvoker{events: make(chan *ChaincodeEvent, len(events))}
+	for _, event := range events {
+		invoker.events <- event
+	}
+	close(invoker.events)
+
+	recorder, _ := serve(NewServer(config, invoker, nil).Handler(), http.MethodGet, target, "portal-key", "")
+	var received []TimelineEvent
+	for _, frame := range strings.Split(recorder.Body.String(), "\n\n") {
+		for _, line := range strings.Split(frame, "\n") {
+			if data, ok := strings.CutPrefix(line, "data: "); ok {
+				var event TimelineEvent
+				require.NoError(t, json.Unmarshal([]byte(data), &event))
+				received = append(received, event)
+			}
+		}
+	}
+	return invoker, recorder, received
+}
+
+func TestGatewayStreamsCustomerTimeline(t *testing.T) {
+	events := []*ChaincodeEvent{
+		loanEvent(t, "tx1", config.EventLoanSubmitted, "LOAN_1", "LoanApplication", map[string]string{"customerID": "CUST_1", "partyIDs": "CUST_1", "status": "SUBMITTED"}),
+		loanEvent(t, "tx2", config.EventLoanTransactionRecorded, "LOAN_1", "LoanApplication", map[string]string{"customerID": "CUST_1"}),
+		loanEvent(t, "tx3", config.EventLoanRejected, "LOAN_3", "LoanApplication", map[string]string{"customerID": "CUST_3", "partyIDs": "CUST_3", "status": "REJECTED", "reason": "Affordability"}),
+		loanEvent(t, "tx4", config.EventDocumentUploaded, "DOC_1", "LoanDocument", map[string]string{"loanID": "LOAN_1", "customerID": "CUST_1", "partyIDs": "CUST_1", "documentType": "IDENTITY", "status": "UPLOADED"}),
+		loanEvent(t, "tx5", config.EventLoanStatusUpdated, "LOAN_2", "LoanApplication", map[string]string{"customerID": "CUST_2", "partyIDs": "CUST_2,CUST_1", "previousStatus": "SUBMITTED", "status": "UNDERWRITING"}),
+	}
+
+	invoker, recorder, received := subscribe(t, events, "/v1/customers/CUST_1/timeline/events")
+	require.Equal(t, http.StatusOK, recorder.Code, recorder.Body.String())
+	assert.Equal(t, "text/event-stream", recorder.Header().Get("Content-Type"))
+	assert.Equal(t, "loan_v2", invoker.chaincode)
+
+	// Only timeline events of applications the customer is party to, in any role, reach the portal
+	assert.Equal(t, []TimelineEvent{
+		{Event: config.EventLoanSubmitted, LoanID: "LOAN_1", Status: "SUBMITTED", Timestamp: "2026-05-04T10:00:00Z", TransactionID: "tx1"},
+		{Event: config.EventDocumentUploaded, LoanID: "LOAN_1", Status: "UPLOADED", DocumentType: "IDENTITY", Timestamp: "2026-05-04T10:00:00Z", TransactionID: "tx4"},
+		{Event: config.EventLoanStatusUpdated, LoanID: "LOAN_2", Status: "UNDERWRITING", PreviousStatus: "SUBMITTED", Timestamp: "2026-05-04T10:00:00Z", TransactionID: "tx5"},
+	}, received)
+	assert.Contains(t, recorder.Body.String(), "id: tx5\nevent: LoanStatusUpdated\n")
+	assert.NotContains(t, recorder.Body.String(), "riskScore")
+	assert.NotContains(t, recorder.Body.String(), "ACTOR_UW")
+
+	// A loanID narrows the stream to one application
+	_, _, received = subscribe(t, events, "/v1/customers/CUST_1/timeline/events?loanID=LOAN_2")
+	require.Len(t, received, 1)
+	assert.Equal(t, "LOAN_2", received[0].LoanID)
+
+	_, recorder, _ = subscribe(t, events, "/v1/customers/CUST%201/timeline/events")
+	assert.Equal(t, http.StatusBadRequest, recorder.Code)
+}
+
+func TestGatewayRefusesTimelineWithoutEventSource(t *testing.T) {
+	// An invoker that cannot stream events leaves subscriptions unavailable
+	_, handler := newTestServer(t)
+	recorder, body := serve(handler, http.MethodGet, "/v1/customers/CUST_1/timeline/events", "secret-key", "")
+	assert.Equal(t, http.StatusServiceUnavailable, recorder.Code)
+	assert.Equal(t, services.ErrorCodeServiceDisabled, errorCode(body))
+
+	// and partners are never given one
+	_, handler = newPartnerServer(t, time.Now())
+	recorder, body = serve(handler, http.MethodGet, "/v1/customers/CUST_1/timeline/events", "partner-key", "")
+	assert.Equal(t, http.StatusForbidden, recorder.Code)
+	assert.Equal(t, services.ErrCodeAccessDenied, errorCode(body))
+}
+
+func TestGatewayOpenAPIDocument(t *testing.T) {
+	_, handler := newTestServer(t)
+
+	recorder, document := serve(handler, http.MethodGet, "/openapi.json", "", "")
+	require.Equal(t, http.StatusOK, recorder.Code)
+	assert.Equal(t, OpenAPIVersion, document["openapi"])
+
+	paths := document["paths"].(map[string]interface{})
+	assert.Contains(t, paths, "/v1/customer/GetCustomer")
+	assert.Contains(t, paths, "/v1/customer/RegisterCustomer")
+	assert.Contains(t, paths, "/v1/{chaincode}/{function}")
+	assert.Contains(t, paths, "/v1/customers/{customerID}/timeline/events")
+	operations := paths["/v1/customer/GetCustomer"].(map[string]interface{})
+	assert.Equal(t, "evaluatecustomerGetCustomer", operations["get"].(map[string]interface{})["operationId"])
+	assert.Equal(t, "submitcustomerGetCustomer", operations["post"].(map[string]interface{})["operationId"])
+
+	// Reads of PII document the purpose they are logged under
+	var headers []interface{}
+	for _, parameter := range operations["get"].(map[string]interface{})["parameters"].([]interface{}) {
+		headers = append(headers, parameter.(map[string]interface{})["name"])
+	}
+	assert.Contains(t, headers, AccessPurposeHeader)
+}
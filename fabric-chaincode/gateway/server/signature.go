@@ -0,0 +1,170 @@
+package server
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/gateway/sdk"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// signatureVerifier checks partner request signatures and refuses a nonce a partner reuses
+// within twice the allowed skew
+type signatureVerifier struct {
+	keys       map[string]PartnerKeyConfig
+	certHeader string
+	skew       time.Duration
+	now        func() time.Time
+
+	mu     sync.Mutex
+	nonces map[string]time.Time
+}
+
+func newSignatureVerifier(cfg *Config) *signatureVerifier {
+	keys := make(map[string]PartnerKeyConfig, len(cfg.PartnerKeys))
+	for _, key := range cfg.PartnerKeys {
+		key.ClientCertFingerprint = normaliseFingerprint(key.ClientCertFingerprint)
+		keys[key.KeyID] = key
+	}
+	return &signatureVerifier{
+		keys:       keys,
+		certHeader: cfg.ClientCertHeader,
+		skew:       cfg.SignatureSkew(),
+		now:        time.Now,
+		nonces:     make(map[string]time.Time),
+	}
+}
+
+// verify checks a partner client's request and returns the evidence the chaincode records. A
+// request with an HMAC signature is checked against the partner's key, and against the key's
+// client certificate when it is bound to one; an unsigned request is accepted only over a client
+// certificate registered to the partner.
+func (v *signatureVerifier) verify(r *http.Request, client *ClientConfig, body []byte) (*services.RequestSignature, *services.ChaincodeError) {
+	timestamp := r.Header.Get(sdk.HeaderSignatureTimestamp)
+	nonce := r.Header.Get(sdk.HeaderSignatureNonce)
+	if timestamp == "" || nonce == "" {
+		return nil, unauthenticated("%s and %s are required for partner %s", sdk.HeaderSignatureTimestamp, sdk.HeaderSignatureNonce, client.PartnerID)
+	}
+	signedAt, err := time.Parse(time.RFC3339, timestamp)
+	if err != nil {
+		return nil, unauthenticated("%s %s is not RFC 3339", sdk.HeaderSignatureTimestamp, timestamp)
+	}
+	if skew := v.now().Sub(signedAt); skew > v.skew || skew < -v.skew {
+		return nil, unauthenticated("request was signed at %s, more than %s from now", timestamp, v.skew)
+	}
+
+	evidence := &services.RequestSignature{
+		PartnerID:             client.PartnerID,
+		Method:                r.Method,
+		Path:                  r.URL.RequestURI(),
+		Timestamp:             timestamp,
+		Nonce:                 nonce,
+		BodyHash:              sdk.BodyHash(body),
+		ClientCertFingerprint: v.clientCertFingerprint(r),
+	}
+
+	keyID, signature := r.Header.Get(sdk.HeaderPartnerKeyID), strings.ToLower(r.Header.Get(sdk.HeaderSignature))
+	if keyID != "" || signature != "" {
+		key, ok := v.keys[keyID]
+		if !ok || key.PartnerID != client.PartnerID || key.Secret == "" {
+			return nil, unauthenticated("unknown partner key %s", keyID)
+		}
+		expected := sdk.RequestSignature(key.Secret, r.Method, evidence.Path, timestamp, nonce, body)
+		if !hmac.Equal([]byte(expected), []byte(signature)) {
+			return nil, unauthenticated("request signature does not match")
+		}
+		if key.ClientCertFingerprint != "" && key.ClientCertFingerprint != evidence.ClientCertFingerprint {
+			return nil, unauthenticated("partner key %s must be used over its mTLS connection", keyID)
+		}
+		evidence.Algorithm, evidence.KeyID, evidence.Signature = services.RequestSignatureHMAC, keyID, signature
+	} else {
+		key, ok := v.keyForCertificate(client.PartnerID, evidence.ClientCertFingerprint)
+		if !ok {
+			return nil, unauthenticated("request is neither signed nor sent with a client certificate of partner %s", client.PartnerID)
+		}
+		evidence.Algorithm, evidence.KeyID = services.RequestSignatureMTLS, key.KeyID
+	}
+
+	if err := v.useNonce(client.PartnerID, nonce); err != nil {
+		return nil, err
+	}
+	return evidence, nil
+}
+
+// bindSignature binds the evidence to the chaincode call the gateway built from the request and
+// adds it to the call's transient data
+func bindSignature(evidence *services.RequestSignature, function string, args []string, transient map[string][]byte) (map[string][]byte, *services.ChaincodeError) {
+	payloadHash, err := services.PayloadHash(function, args)
+	if err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInternal, "", "failed to hash payload: %v", err)
+	}
+	evidence.PayloadHash = payloadHash
+	encoded, err := json.Marshal(evidence)
+	if err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInternal, "", "failed to encode request signature: %v", err)
+	}
+
+	if transient == nil {
+		transient = make(map[string][]byte, 1)
+	}
+	transient[config.RequestSignatureTransientKey] = encoded
+	return transient, nil
+}
+
+// clientCertFingerprint returns the fingerprint of the client certificate the request was sent
+// with: the one on the gateway's own TLS connection, else the one a TLS-terminating proxy passed
+func (v *signatureVerifier) clientCertFingerprint(r *http.Request) string {
+	if r.TLS != nil && len(r.TLS.PeerCertificates) > 0 {
+		hash := sha256.Sum256(r.TLS.PeerCertificates[0].Raw)
+		return hex.EncodeToString(hash[:])
+	}
+	if v.certHeader == "" {
+		return ""
+	}
+	return normaliseFingerprint(r.Header.Get(v.certHeader))
+}
+
+func (v *signatureVerifier) keyForCertificate(partnerID, fingerprint string) (PartnerKeyConfig, bool) {
+	if fingerprint == "" {
+		return PartnerKeyConfig{}, false
+	}
+	for _, key := range v.keys {
+		if key.PartnerID == partnerID && key.ClientCertFingerprint == fingerprint {
+			return key, true
+		}
+	}
+	return PartnerKeyConfig{}, false
+}
+
+func (v *signatureVerifier) useNonce(partnerID, nonce string) *services.ChaincodeError {
+	now := v.now()
+	v.mu.Lock()
+	defer v.mu.Unlock()
+	for seen, seenAt := range v.nonces {
+		if now.Sub(seenAt) > 2*v.skew {
+			delete(v.nonces, seen)
+		}
+	}
+	scoped := partnerID + ":" + nonce
+	if _, seen := v.nonces[scoped]; seen {
+		return unauthenticated("nonce %s was already used", nonce)
+	}
+	v.nonces[scoped] = now
+	return nil
+}
+
+func unauthenticated(format string, args ...interface{}) *services.ChaincodeError {
+	return services.NewChaincodeError(ErrCodeUnauthenticated, "", format, args...)
+}
+
+// normaliseFingerprint returns a certificate fingerprint as lower-case hex without separators
+func normaliseFingerprint(fingerprint string) string {
+	return strings.ToLower(strings.ReplaceAll(fingerprint, ":", ""))
+}
@@ -0,0 +1,173 @@
+package server
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"log"
+	"net/http"
+	"regexp"
+	"strings"
+	"time"
+
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// ChaincodeEvent is an event a committed transaction emitted
+type ChaincodeEvent struct {
+	BlockNumber   uint64
+	TransactionID string
+	EventName     string
+	Payload       []byte
+}
+
+// EventSource streams the events of a chaincode's committed transactions, as one of the
+// configured identities, until the context is done. An Invoker that is also an EventSource serves
+// timeline subscriptions.
+type EventSource interface {
+	ChaincodeEvents(ctx context.Context, identity, chaincode string) (<-chan *ChaincodeEvent, error)
+}
+
+// TimelineEvent is the customer-safe form of a loan event that moves an application along its
+// timeline. The chaincode event carries the whole application; only its progress is passed on.
+type TimelineEvent struct {
+	Event          string `json:"event"`
+	LoanID         string `json:"loanID"`
+	Status         string `json:"status,omitempty"`
+	PreviousStatus string `json:"previousStatus,omitempty"`
+	DocumentType   string `json:"documentType,omitempty"`
+	Timestamp      string `json:"timestamp"`
+	TransactionID  string `json:"transactionID"`
+}
+
+// timelineEvents are the loan chaincode events that change what GetApplicationTimeline returns
+var timelineEvents = map[string]bool{
+	config.EventLoanSubmitted:            true,
+	config.EventLoanStatusUpdated:        true,
+	config.EventLoanApproved:             true,
+	config.EventLoanRejected:             true,
+	config.EventLoanApplicationCancelled: true,
+	config.EventLoanDisbursed:            true,
+	config.EventDocumentUploaded:         true,
+	config.EventDocumentVerified:         true,
+}
+
+// timelineHeartbeat is how often an idle subscription is sent a comment, so that proxies keep it open
+var timelineHeartbeat = 15 * time.Second
+
+// customerIDPattern matches the customer IDs a subscription may name
+var customerIDPattern = regexp.MustCompile(`^[A-Za-z0-9_-]{1,64}$`)
+
+// handleTimelineEvents streams the timeline events of a customer's loan applications, as a
+// co-borrower or guarantor as well as the applicant, as server-sent events. A loanID parameter
+// narrows the stream to one application. Events are not replayed: a portal reads
+// GetApplicationTimeline when it subscribes and applies the events that follow.
+func (s *Server) handleTimelineEvents(w http.ResponseWriter, r *http.Request) {
+	client, err := s.authenticate(r)
+	if err != nil {
+		writeError(w, err)
+		return
+	}
+	if client.PartnerID != "" {
+		writeError(w, services.NewChaincodeError(services.ErrCodeAccessDenied, "", "timeline subscriptions are not available to partner clients"))
+		return
+	}
+	customerID, loanID := r.PathValue("customerID"), r.URL.Query().Get("loanID")
+	if !customerIDPattern.MatchString(customerID) {
+		writeError(w, services.NewChaincodeError(services.ErrCodeInvalidArgument, "customerID", "invalid customer ID %q", customerID))
+		return
+	}
+	source, ok := s.invoker.(EventSource)
+	flusher, canFlush := w.(http.Flusher)
+	if !ok || !canFlush {
+		writeError(w, services.NewChaincodeError(services.ErrorCodeServiceDisabled, "", "timeline subscriptions are not available"))
+		return
+	}
+
+	ctx, cancel := context.WithCancel(r.Context())
+	defer cancel()
+	events, callErr := source.ChaincodeEvents(ctx, client.Identity, s.config.DeployedName(ChaincodeLoan))
+	if callErr != nil {
+		log.Printf("timeline subscription for %s failed: %v", client.Name, callErr)
+		chaincodeErr := services.NewChaincodeError(ErrCodeUnavailable, "", "the peer could not open the event stream")
+		chaincodeErr.Retryable = true
+		writeError(w, chaincodeErr)
+		return
+	}
+
+	w.Header().Set("Content-Type", "text/event-stream")
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	flusher.Flush()
+
+	heartbeat := time.NewTicker(timelineHeartbeat)
+	defer heartbeat.Stop()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-heartbeat.C:
+			fmt.Fprint(w, ": heartbeat\n\n")
+			flusher.Flush()
+		case event, open := <-events:
+			if !open {
+				return
+			}
+			timelineEvent, ok := customerTimelineEvent(event, customerID)
+			if !ok || (loanID != "" && timelineEvent.LoanID != loanID) {
+				continue
+			}
+			data, err := json.Marshal(timelineEvent)
+			if err != nil {
+				continue
+			}
+			fmt.Fprintf(w, "id: %s\nevent: %s\ndata: %s\n\n", event.TransactionID, timelineEvent.Event, data)
+			flusher.Flush()
+		}
+	}
+}
+
+// customerTimelineEvent returns the timeline form of a chaincode event when the event moves an
+// application the customer is party to
+func customerTimelineEvent(event *ChaincodeEvent, customerID string) (*TimelineEvent, bool) {
+	if !timelineEvents[event.EventName] {
+		return nil, false
+	}
+	decoded, err := services.DecodeEvent(event.Payload, nil)
+	if err != nil {
+		return nil, false
+	}
+	metadata := decoded.Metadata
+	if !isLoanParty(metadata, customerID) {
+		return nil, false
+	}
+
+	timelineEvent := &TimelineEvent{
+		Event:          decoded.EventName,
+		LoanID:         decoded.EntityID,
+		Status:         metadata["status"],
+		PreviousStatus: metadata["previousStatus"],
+		Timestamp:      decoded.Timestamp,
+		TransactionID:  event.TransactionID,
+	}
+	if decoded.EntityType == "LoanDocument" {
+		timelineEvent.LoanID = metadata["loanID"]
+		timelineEvent.DocumentType = metadata["documentType"]
+	}
+	return timelineEvent, true
+}
+
+// isLoanParty reports whether a customer is the applicant or another party of the loan an event's
+// metadata describes
+func isLoanParty(metadata map[string]string, customerID string) bool {
+	if metadata["customerID"] == customerID {
+		return true
+	}
+	for _, partyID := range strings.Split(metadata["partyIDs"], ",") {
+		if partyID == customerID {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,22 @@
+package chaincode
+
+import (
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// loanEntityAuditKeys are the loan records whose ledger history ExportEntityAudit exports
+var loanEntityAuditKeys = []string{"LOAN_%s"}
+
+// loanAuditCollectors lists the evidence the loan chaincode holds about a loan, for
+// ExportAuditPackage. Loan documents carry the hash of the uploaded file.
+func loanAuditCollectors() []services.AuditCollector {
+	return []services.AuditCollector{
+		services.RecordCollector("loan", "Current loan application", "LOAN_%s"),
+		services.HistoryCollector(),
+		services.IndexedRecordCollector("documents", "Loan documents, including document hashes", "LOAN_DOCUMENT", func(documentID string) string {
+			return "DOCUMENT_" + documentID
+		}),
+		services.CompositeKeyCollector("disbursements", "Disbursements of the loan", "LOAN_DISBURSEMENT"),
+		services.CompositeKeyCollector("transactions", "Loan transactions", "LOAN_TRANSACTION"),
+	}
+}
@@ -0,0 +1,97 @@
+package chaincode
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	loanServices "github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// loanDataQualityRules lists the invariants RunDataQualitySweep holds loan applications to. The
+// customer check calls the customer chaincode once per loan, so keep sweep batches modest.
+func loanDataQualityRules() map[string][]services.DataQualityRule {
+	customerVerification := loanServices.NewCustomerVerificationService()
+
+	return map[string][]services.DataQualityRule{
+		"LOAN_": {
+			{Name: "requiredFields", Description: "Application fields are present", Check: checkLoanRequiredFields},
+			{Name: "customerExists", Description: "The borrower is a registered customer", Check: func(stub shim.ChaincodeStubInterface, record interface{}) (string, error) {
+				loanApp := record.(*domain.LoanApplication)
+				if loanApp.CustomerID == "" {
+					return "", nil
+				}
+				exists, err := customerVerification.CustomerExists(stub, loanApp.CustomerID)
+				if err != nil {
+					return "", err
+				}
+				if !exists {
+					return fmt.Sprintf("customer %s not found", loanApp.CustomerID), nil
+				}
+				return "", nil
+			}},
+			{Name: "indexes", Description: "Status and customer indexes point at the loan", Check: checkLoanIndexes},
+		},
+	}
+}
+
+func checkLoanRequiredFields(stub shim.ChaincodeStubInterface, record interface{}) (string, error) {
+	loanApp := record.(*domain.LoanApplication)
+
+	var problems []string
+	if loanApp.LoanID == "" {
+		problems = append(problems, "missing loanID")
+	}
+	if loanApp.CustomerID == "" {
+		problems = append(problems, "missing customerID")
+	}
+	if strings.TrimSpace(loanApp.LoanType) == "" {
+		problems = append(problems, "missing loanType")
+	}
+	if loanApp.Status == "" {
+		problems = append(problems, "missing status")
+	}
+	if !loanApp.RequestedAmount.IsPositive() {
+		problems = append(problems, "requestedAmount is not positive")
+	}
+	if loanApp.ApplicationDate.IsZero() {
+		problems = append(problems, "missing applicationDate")
+	}
+
+	return strings.Join(problems, "; "), nil
+}
+
+func checkLoanIndexes(stub shim.ChaincodeStubInterface, record interface{}) (string, error) {
+	loanApp := record.(*domain.LoanApplication)
+
+	var problems []string
+	indexed, err := services.IndexEntryExists(stub, "LOAN_BY_STATUS", string(loanApp.Status), loanApp.LoanID)
+	if err != nil {
+		return "", err
+	}
+	if !indexed {
+		problems = append(problems, fmt.Sprintf("not in the LOAN_BY_STATUS index under %s", loanApp.Status))
+	}
+
+	indexed, err = services.IndexEntryExists(stub, "LOAN_BY_CUSTOMER", loanApp.CustomerID, loanApp.LoanID)
+	if err != nil {
+		return "", err
+	}
+	if !indexed {
+		problems = append(problems, fmt.Sprintf("not in the LOAN_BY_CUSTOMER index under %s", loanApp.CustomerID))
+	}
+
+	if loanApp.Channel != "" {
+		indexed, err = services.IndexEntryExists(stub, "LOAN_BY_CHANNEL", string(loanApp.Channel), loanApp.LoanID)
+		if err != nil {
+			return "", err
+		}
+		if !indexed {
+			problems = append(problems, fmt.Sprintf("not in the LOAN_BY_CHANNEL index under %s", loanApp.Channel))
+		}
+	}
+
+	return strings.Join(problems, "; "), nil
+}
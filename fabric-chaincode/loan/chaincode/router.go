@@ -1,21 +1,64 @@
 package chaincode
 
 import (
-	"fmt"
-
 	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/chaincode"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/masking"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
 	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/handlers"
 )
 
 // Router handles function routing for the loan chaincode
 type Router struct {
 	handlers map[string]func(shim.ChaincodeStubInterface, []string) ([]byte, error)
+	masking  map[string]*masking.PolicySet
+	freeze   chaincode.FreezeArguments
 }
 
 // NewRouter creates a new router with all handler mappings
 func NewRouter() *Router {
 	loanHandler := handlers.NewLoanApplicationHandler()
 	documentHandler := handlers.NewDocumentHandler()
+	reconciliationHandler := handlers.NewReconciliationHandler()
+	disbursementHandler := handlers.NewDisbursementHandler()
+	guaranteeHandler := handlers.NewGuaranteeHandler()
+	repaymentHandler := handlers.NewRepaymentHandler()
+	paymentMessageHandler := handlers.NewPaymentMessageHandler()
+	collateralHandler := handlers.NewCollateralHandler()
+	timelineHandler := handlers.NewTimelineHandler()
+	sandboxPurgeHandler := handlers.NewSandboxPurgeHandler()
+	counterpartyHandler := handlers.NewCounterpartyHandler()
+	indexRateHandler := handlers.NewIndexRateHandler()
+	facilityHandler := handlers.NewFacilityHandler()
+	withholdingHandler := handlers.NewWithholdingHandler()
+	inquiryHandler := handlers.NewCreditInquiryHandler()
+	openBankingHandler := handlers.NewOpenBankingHandler()
+	servicingHandler := handlers.NewServicingTransferHandler()
+	stpHandler := handlers.NewStraightThroughHandler()
+	stressTestHandler := handlers.NewStressTestHandler()
+	bulkHandler := handlers.NewBulkOperationHandler()
+	snapshotHandler := handlers.NewBalanceSnapshotHandler()
+	accrualHandler := handlers.NewInterestAccrualHandler()
+	introducerHandler := handlers.NewIntroducerHandler()
+	syncHandler := handlers.NewStatusSyncHandler()
+	crossBorderHandler := handlers.NewCrossBorderHandler()
+	amountPolicyHandler := handlers.NewAmountPolicyHandler()
+	checklistHandler := handlers.NewUnderwritingChecklistHandler()
+	ratePolicyHandler := handlers.NewRatePolicyHandler()
+	flagHandler := chaincode.NewFunctionFlagHandler()
+	sandboxHandler := chaincode.NewSandboxHandler()
+	identityHandler := chaincode.NewIdentityHandler()
+	archiveHandler := chaincode.NewPayloadArchiveHandler()
+	counterHandler := chaincode.NewCounterHandler()
+	compatibilityHandler := chaincode.NewCompatibilityHandler(newLoanSchemaRegistry())
+	qaHandler := chaincode.NewQAReviewHandler()
+	noteHandler := chaincode.NewEntityNoteHandler()
+	lockHandler := chaincode.NewEntityLockHandler()
+	freezeHandler := chaincode.NewEntityFreezeHandler(services.FreezeEntityLoan, "LOAN_%s")
+	auditPackageHandler := chaincode.NewAuditPackageHandler(loanAuditCollectors()...)
+	entityAuditHandler := chaincode.NewEntityAuditHandler(loanEntityAuditKeys...)
+	dataQualityHandler := chaincode.NewDataQualityHandler(newLoanSchemaRegistry(), loanDataQualityRules())
 	
 	return &Router{
 		handlers: map[string]func(shim.ChaincodeStubInterface, []string) ([]byte, error){
@@ -26,6 +69,16 @@ func NewRouter() *Router {
 			"GetLoanHistory":           loanHandler.GetLoanHistory,
 			"ApproveLoan":              loanHandler.ApproveLoan,
 			"RejectLoan":               loanHandler.RejectLoan,
+			"CancelApplication":        loanHandler.CancelApplication,
+			"RepriceLoan":              loanHandler.RepriceLoan,
+			
+			// Straight-through processing functions
+			"SetSTPPolicy":            stpHandler.SetSTPPolicy,
+			"GetSTPPolicy":            stpHandler.GetSTPPolicy,
+			"EvaluateStraightThrough": stpHandler.EvaluateStraightThrough,
+			"GetSTPDecision":          stpHandler.GetSTPDecision,
+			"GetSTPRateReport":        stpHandler.GetSTPRateReport,
+			"QuerySTPSampleQueue":     stpHandler.QuerySTPSampleQueue,
 			
 			// Document functions
 			"UploadDocument":           documentHandler.UploadDocument,
@@ -33,9 +86,243 @@ func NewRouter() *Router {
 			"GetDocument":              documentHandler.GetDocument,
 			"GetLoanDocuments":         documentHandler.GetLoanDocuments,
 			
+			// Disbursement functions
+			"DisburseLoan":             disbursementHandler.DisburseLoan,
+			"GetLoanDisbursements":     disbursementHandler.GetLoanDisbursements,
+			
+			// ISO 20022 payment message functions
+			"GetDisbursementPain001": paymentMessageHandler.GetDisbursementPain001,
+			"GetRepaymentCamt054":    paymentMessageHandler.GetRepaymentCamt054,
+			"ParsePain001":           paymentMessageHandler.ParsePain001,
+			"ParseCamt054":           paymentMessageHandler.ParseCamt054,
+			
+			// Collateral functions
+			"AddCollateral":             collateralHandler.AddCollateral,
+			"UpdateCollateralValuation": collateralHandler.UpdateCollateralValuation,
+			"ReleaseCollateral":         collateralHandler.ReleaseCollateral,
+			"GetCollateral":             collateralHandler.GetCollateral,
+			
+			// Repayment functions
+			"RecordRepayment":          repaymentHandler.RecordRepayment,
+			"GetRepaymentSchedule":     repaymentHandler.GetRepaymentSchedule,
+			
+			// Delinquency functions
+			"MarkLoanDelinquent":       repaymentHandler.MarkLoanDelinquent,
+			"MarkLoanDefaulted":        repaymentHandler.MarkLoanDefaulted,
+			
+			// Guarantee functions
+			"AddLoanGuarantee":         guaranteeHandler.AddLoanGuarantee,
+			"InvokeGuarantee":          guaranteeHandler.InvokeGuarantee,
+			"RecordGuarantorPayment":   guaranteeHandler.RecordGuarantorPayment,
+			"GetLoanGuarantees":        guaranteeHandler.GetLoanGuarantees,
+			"GetRecoveryObligation":    guaranteeHandler.GetRecoveryObligation,
+			"GetCustomerExposure":      guaranteeHandler.GetCustomerExposure,
+			
+			// Transaction and reconciliation functions
+			"RecordLoanTransaction":    reconciliationHandler.RecordLoanTransaction,
+			"GetLoanTransactions":      reconciliationHandler.GetLoanTransactions,
+			"ReconcileLoan":            reconciliationHandler.ReconcileLoan,
+			"GetReconciliation":        reconciliationHandler.GetReconciliation,
+			"RequestBalanceRepair":     reconciliationHandler.RequestBalanceRepair,
+			"ApproveBalanceRepair":     reconciliationHandler.ApproveBalanceRepair,
+			
+			// Credit inquiry functions
+			"RecordCreditInquiry":     inquiryHandler.RecordCreditInquiry,
+			"GetCreditInquiryHistory": inquiryHandler.GetCreditInquiryHistory,
+			"RequestCreditCheck":      inquiryHandler.RequestCreditCheck,
+			"RecordCreditReport":      inquiryHandler.RecordCreditReport,
+			"GetCreditReports":        inquiryHandler.GetCreditReports,
+			
+			// Open Banking and affordability functions
+			"GrantOpenBankingAuthorization":  openBankingHandler.GrantOpenBankingAuthorization,
+			"RevokeOpenBankingAuthorization": openBankingHandler.RevokeOpenBankingAuthorization,
+			"GetOpenBankingAuthorizations":   openBankingHandler.GetOpenBankingAuthorizations,
+			"IngestAccountSummary":           openBankingHandler.IngestAccountSummary,
+			"AssessAffordability":            openBankingHandler.AssessAffordability,
+			"GetAffordabilityAssessments":    openBankingHandler.GetAffordabilityAssessments,
+			
+			// Tax withholding functions
+			"GetLoanWithholding":             withholdingHandler.GetLoanWithholding,
+			"GetWithholdingRemittanceReport": withholdingHandler.GetWithholdingRemittanceReport,
+			
+			// Counterparty functions
+			"RegisterCounterparty":        counterpartyHandler.RegisterCounterparty,
+			"UpdateCounterpartyKYC":       counterpartyHandler.UpdateCounterpartyKYC,
+			"RecordCounterpartyScreening": counterpartyHandler.RecordCounterpartyScreening,
+			"UpdateCounterpartyStatus":    counterpartyHandler.UpdateCounterpartyStatus,
+			"GetCounterparty":             counterpartyHandler.GetCounterparty,
+			"GetCounterpartyHistory":      counterpartyHandler.GetCounterpartyHistory,
+			"AddLoanParticipation":        counterpartyHandler.AddLoanParticipation,
+			"GetLoanParticipations":       counterpartyHandler.GetLoanParticipations,
+			
+			// Credit facility functions
+			"OpenCreditFacility":        facilityHandler.OpenCreditFacility,
+			"DrawdownFacility":          facilityHandler.DrawdownFacility,
+			"RepayFacility":             facilityHandler.RepayFacility,
+			"ReviewCreditFacility":      facilityHandler.ReviewCreditFacility,
+			"ConvertFacilityToTermLoan": facilityHandler.ConvertFacilityToTermLoan,
+			"GetCreditFacility":         facilityHandler.GetCreditFacility,
+			"GetFacilityTransactions":   facilityHandler.GetFacilityTransactions,
+			
+			// Market data functions
+			"RegisterRateOracle":  indexRateHandler.RegisterRateOracle,
+			"PublishIndexRate":    indexRateHandler.PublishIndexRate,
+			"GetIndexRate":        indexRateHandler.GetIndexRate,
+			"GetIndexRateHistory": indexRateHandler.GetIndexRateHistory,
+			
+			// Servicing transfer functions
+			"ScheduleServicingTransfer":       servicingHandler.ScheduleServicingTransfer,
+			"CompleteServicingTransfer":       servicingHandler.CompleteServicingTransfer,
+			"CancelServicingTransfer":         servicingHandler.CancelServicingTransfer,
+			"GetServicingTransfer":            servicingHandler.GetServicingTransfer,
+			"GetServicingTransferManifest":    servicingHandler.GetServicingTransferManifest,
+			"VerifyServicingTransferManifest": servicingHandler.VerifyServicingTransferManifest,
+			
+			// Bulk operation functions
+			"BulkPlaceComplianceHold":   bulkHandler.BulkPlaceComplianceHold,
+			"BulkReleaseComplianceHold": bulkHandler.BulkReleaseComplianceHold,
+			"BulkReassignLoanOwner":     bulkHandler.BulkReassignLoanOwner,
+			"BulkRevalidateEligibility": bulkHandler.BulkRevalidateEligibility,
+			"GetBulkLoanOperation":      bulkHandler.GetBulkLoanOperation,
+			
+			// Balance snapshot functions
+			"RunBalanceSnapshot":        snapshotHandler.RunBalanceSnapshot,
+			"GetBalanceSnapshot":        snapshotHandler.GetBalanceSnapshot,
+			"GetCertifiedBalances":      snapshotHandler.GetCertifiedBalances,
+			
+			// Interest accrual functions
+			"GetPayoffQuote":            accrualHandler.GetPayoffQuote,
+			"AccrueInterest":            accrualHandler.AccrueInterest,
+			"GetInterestAccrualRun":     accrualHandler.GetInterestAccrualRun,
+			
+			// Introducer functions
+			"RegisterIntroducer":        introducerHandler.RegisterIntroducer,
+			"UpdateIntroducerStatus":    introducerHandler.UpdateIntroducerStatus,
+			"SetCommissionSchedule":     introducerHandler.SetCommissionSchedule,
+			"GetIntroducer":             introducerHandler.GetIntroducer,
+			"GetCommissionSchedule":     introducerHandler.GetCommissionSchedule,
+			"GetCommissionStatement":    introducerHandler.GetCommissionStatement,
+			
+			// Core banking status sync functions
+			"GetPendingSyncRecords":     syncHandler.GetPendingSyncRecords,
+			"AckSyncRecord":             syncHandler.AckSyncRecord,
+			"ResendSyncRecords":         syncHandler.ResendSyncRecords,
+			"GetSyncGaps":               syncHandler.GetSyncGaps,
+			"GetSyncState":              syncHandler.GetSyncState,
+			
+			// Cross-border corridor functions
+			"SetCorridorRule":           crossBorderHandler.SetCorridorRule,
+			"GetCorridorRule":           crossBorderHandler.GetCorridorRule,
+			"GetCorridorReport":         crossBorderHandler.GetCorridorReport,
+			
+			// Loan amount policy and exceptions register functions
+			"SetLoanAmountBound":            amountPolicyHandler.SetLoanAmountBound,
+			"GetLoanAmountBound":            amountPolicyHandler.GetLoanAmountBound,
+			"RequestPolicyException":        amountPolicyHandler.RequestPolicyException,
+			"DecidePolicyException":         amountPolicyHandler.DecidePolicyException,
+			"GetPolicyException":            amountPolicyHandler.GetPolicyException,
+			"GetPolicyExceptionsByCustomer": amountPolicyHandler.GetPolicyExceptionsByCustomer,
+			
+			// Underwriting checklist functions
+			"SetUnderwritingChecklist":  checklistHandler.SetUnderwritingChecklist,
+			"GetUnderwritingChecklists": checklistHandler.GetUnderwritingChecklists,
+			"CompleteChecklistItem":     checklistHandler.CompleteChecklistItem,
+			"GetLoanChecklist":          checklistHandler.GetLoanChecklist,
+			
+			// Interest rate policy functions
+			"SetInterestRatePolicy": ratePolicyHandler.SetInterestRatePolicy,
+			"GetInterestRatePolicy": ratePolicyHandler.GetInterestRatePolicy,
+			"GetInterestRateRange":  ratePolicyHandler.GetInterestRateRange,
+			
 			// Query functions
 			"QueryLoansByStatus":       loanHandler.QueryLoansByStatus,
 			"QueryLoansByCustomer":     loanHandler.QueryLoansByCustomer,
+			"QueryLoansByChannel":      loanHandler.QueryLoansByChannel,
+			"QueryLoansByDateRange":    loanHandler.QueryLoansByDateRange,
+			"QueryLoansByParty":        loanHandler.QueryLoansByParty,
+			"GetCustomerHoldings":      loanHandler.GetCustomerHoldings,
+			"GetApplicationTimeline":   timelineHandler.GetApplicationTimeline,
+			"GetCustomerLoanMilestones": timelineHandler.GetCustomerLoanMilestones,
+			"QueryCounterpartiesByType": counterpartyHandler.QueryCounterpartiesByType,
+			"QueryFacilitiesByCustomer": facilityHandler.QueryFacilitiesByCustomer,
+			"ExtractStressTestInputs":   stressTestHandler.ExtractStressTestInputs,
+			"QueryOverdueInstallments":  repaymentHandler.QueryOverdueInstallments,
+			"GetDelinquentPortfolio":    repaymentHandler.GetDelinquentPortfolio,
+			"QueryCollateralByLoan":     collateralHandler.QueryCollateralByLoan,
+			
+			// Quality review functions
+			"SetQASamplingRate":  qaHandler.SetQASamplingRate,
+			"GetQASamplingRates": qaHandler.GetQASamplingRates,
+			"GetQAReviewQueue":   qaHandler.GetQAReviewQueue,
+			"GetQAReviewItem":    qaHandler.GetQAReviewItem,
+			"RecordQAReview":     qaHandler.RecordQAReview,
+			"GetQADefectReport":  qaHandler.GetQADefectReport,
+			
+			// Entity note functions
+			"AddNote":        noteHandler.AddNote,
+			"EditNote":       noteHandler.EditNote,
+			"GetEntityNotes": noteHandler.GetEntityNotes,
+			"GetNoteHistory": noteHandler.GetNoteHistory,
+			
+			// Entity claim functions
+			"ClaimEntity":    lockHandler.ClaimEntity,
+			"ReleaseEntity":  lockHandler.ReleaseEntity,
+			"GetEntityLock":  lockHandler.GetEntityLock,
+			
+			// Emergency freeze functions
+			"FreezeLoan":      freezeHandler.Freeze,
+			"RequestUnfreeze": freezeHandler.RequestUnfreeze,
+			"ApproveUnfreeze": freezeHandler.ApproveUnfreeze,
+			"GetEntityFreeze": freezeHandler.GetEntityFreeze,
+			
+			// Audit package functions
+			"ExportAuditPackage": auditPackageHandler.ExportAuditPackage,
+			"GetAuditManifest":   auditPackageHandler.GetAuditManifest,
+			"VerifyAuditPackage": auditPackageHandler.VerifyAuditPackage,
+			"ExportEntityAudit":  entityAuditHandler.ExportEntityAudit,
+			"VerifyEntityAudit":  entityAuditHandler.VerifyEntityAudit,
+			
+			// Data quality functions
+			"RunDataQualitySweep":    dataQualityHandler.RunDataQualitySweep,
+			"GetDataQualityReport":   dataQualityHandler.GetDataQualityReport,
+			"GetRemediationTasks":    dataQualityHandler.GetRemediationTasks,
+			"ResolveRemediationTask": dataQualityHandler.ResolveRemediationTask,
+			
+			// Operational functions
+			"SetFunctionFlag":  flagHandler.SetFunctionFlag,
+			"GetFunctionFlags": flagHandler.GetFunctionFlags,
+			"SetSandboxActor":  sandboxHandler.SetSandboxActor,
+			"GetSandboxActors": sandboxHandler.GetSandboxActors,
+			"RegisterActor":      identityHandler.RegisterActor,
+			"GetActor":           identityHandler.GetActor,
+			"AttestCredentialRotation": identityHandler.AttestCredentialRotation,
+			"GetCredentialRotation":    identityHandler.GetCredentialRotation,
+			"GetInvokerIdentity": identityHandler.GetInvokerIdentity,
+			"SetPayloadArchivePolicy":   archiveHandler.SetPayloadArchivePolicy,
+			"GetPayloadArchivePolicies": archiveHandler.GetPayloadArchivePolicies,
+			"GetArchivedPayload":        archiveHandler.GetArchivedPayload,
+			"GetCounter":                counterHandler.GetCounter,
+			"CompactCounters":           counterHandler.CompactCounters,
+			"ValidateStateCompatibility": compatibilityHandler.ValidateStateCompatibility,
+			"RecordTimestampCutover":     compatibilityHandler.RecordTimestampCutover,
+			"GetTimestampCutover":        compatibilityHandler.GetTimestampCutover,
+			"PurgeSandboxLoan":     sandboxPurgeHandler.PurgeSandboxLoan,
+			"PurgeSandboxFacility": sandboxPurgeHandler.PurgeSandboxFacility,
+		},
+		// Responses masked for the invoker's role
+		masking: map[string]*masking.PolicySet{
+			"DisburseLoan":         domain.DisbursementMaskingPolicies,
+			"GetLoanDisbursements": domain.DisbursementMaskingPolicies,
+		},
+		// Functions naming their loan other than as the loanID of a JSON request, or taking
+		// positional arguments that name no single loan
+		freeze: chaincode.FreezeArguments{
+			"PurgeSandboxLoan":                {Entity: services.FreezeEntityLoan, Field: "entityID"},
+			"ParsePain001":                    {},
+			"ParseCamt054":                    {},
+			"VerifyServicingTransferManifest": {},
+			"ExtractStressTestInputs":         {},
+			"CompactCounters":                 {},
 		},
 	}
 }
@@ -44,8 +331,18 @@ func NewRouter() *Router {
 func (r *Router) Route(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error) {
 	handler, exists := r.handlers[function]
 	if !exists {
-		return nil, fmt.Errorf("function %s not found", function)
+		return nil, services.NewChaincodeError(services.ErrCodeUnknownFunction, "", "function %s not found", function)
 	}
 	
 	return handler(stub, args)
+}
+
+// MaskingPolicy returns the masking policies for a function's response, or nil if it is not masked
+func (r *Router) MaskingPolicy(function string) *masking.PolicySet {
+	return r.masking[function]
+}
+
+// FreezeArguments locates the loan of functions not naming it in a JSON request's loanID
+func (r *Router) FreezeArguments() chaincode.FreezeArguments {
+	return r.freeze
 }
\ No newline at end of file
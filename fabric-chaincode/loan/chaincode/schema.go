@@ -0,0 +1,69 @@
+package chaincode
+
+import (
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// newLoanSchemaRegistry registers every namespace the loan chaincode writes. Keep this in step
+// with new entities so ValidateStateCompatibility covers them before an upgrade.
+func newLoanSchemaRegistry() *services.SchemaRegistry {
+	registry := services.NewSchemaRegistry()
+
+	// Entities keyed by prefix
+	registry.RegisterPrefix("LOAN_", "LoanApplication", func() interface{} { return &domain.LoanApplication{} })
+	registry.RegisterPrefix("DOCUMENT_", "LoanDocument", func() interface{} { return &domain.LoanDocument{} })
+	registry.RegisterPrefix("COLLATERAL_", "Collateral", func() interface{} { return &domain.Collateral{} })
+	registry.RegisterPrefix("FACILITY_", "CreditFacility", func() interface{} { return &domain.CreditFacility{} })
+	registry.RegisterPrefix("GUARANTEE_", "LoanGuarantee", func() interface{} { return &domain.LoanGuarantee{} })
+	registry.RegisterPrefix("RECOVERY_OBLIGATION_", "RecoveryObligation", func() interface{} { return &domain.RecoveryObligation{} })
+	registry.RegisterPrefix("CUSTOMER_EXPOSURE_", "CustomerExposure", func() interface{} { return &domain.CustomerExposure{} })
+	registry.RegisterPrefix("RECONCILIATION_", "LoanReconciliation", func() interface{} { return &domain.LoanReconciliation{} })
+	registry.RegisterPrefix("COUNTERPARTY_", "Counterparty", func() interface{} { return &domain.Counterparty{} })
+	registry.RegisterPrefix("RATE_ORACLE_", "RateOracle", func() interface{} { return &domain.RateOracle{} })
+	registry.RegisterPrefix("INDEX_RATE_LATEST_", "IndexRate", func() interface{} { return &domain.IndexRate{} })
+	registry.RegisterPrefix("SERVICING_TRANSFER_", "ServicingTransfer", func() interface{} { return &domain.ServicingTransfer{} })
+	registry.RegisterPrefix("SERVICING_MANIFEST_", "ServicingTransferManifest", func() interface{} { return &domain.ServicingTransferManifest{} })
+	registry.RegisterPrefix("STP_POLICY", "STPPolicy", func() interface{} { return &domain.STPPolicy{} })
+	registry.RegisterPrefix("STP_DECISION_", "STPDecision", func() interface{} { return &domain.STPDecision{} })
+	registry.RegisterPrefix("BULK_LOAN_OPERATION_", "BulkLoanOperation", func() interface{} { return &domain.BulkLoanOperation{} })
+	registry.RegisterPrefix("OPEN_BANKING_AUTH_", "OpenBankingAuthorization", func() interface{} { return &domain.OpenBankingAuthorization{} })
+	registry.RegisterPrefix("BALANCE_SNAPSHOT_", "BalanceSnapshot", func() interface{} { return &domain.BalanceSnapshot{} })
+	registry.RegisterPrefix("INTEREST_ACCRUAL_RUN_", "InterestAccrualRun", func() interface{} { return &domain.InterestAccrualRun{} })
+	registry.RegisterPrefix("INTRODUCER_", "Introducer", func() interface{} { return &domain.Introducer{} })
+	registry.RegisterPrefix("COMMISSION_SCHEDULE_", "CommissionSchedule", func() interface{} { return &domain.CommissionSchedule{} })
+	registry.RegisterPrefix("CORRIDOR_RULE_", "CorridorRule", func() interface{} { return &domain.CorridorRule{} })
+	registry.RegisterPrefix("AMOUNT_BOUND_", "LoanAmountBound", func() interface{} { return &domain.LoanAmountBound{} })
+	registry.RegisterPrefix("POLICY_EXCEPTION_", "PolicyException", func() interface{} { return &domain.PolicyException{} })
+	registry.RegisterPrefix("RATE_POLICY_", "InterestRatePolicy", func() interface{} { return &domain.InterestRatePolicy{} })
+
+	// Raw ID indexes sharing an entity prefix
+	registry.RegisterIndexPrefix("CUSTOMER_LOAN_")
+	registry.RegisterIndexPrefix("COUNTERPARTY_BY_REGISTRATION_")
+
+	// Entities keyed by composite key
+	registry.RegisterCompositeKey("LOAN_TRANSACTION", "LoanTransaction", func() interface{} { return &domain.LoanTransaction{} })
+	registry.RegisterCompositeKey("LOAN_DISBURSEMENT", "LoanDisbursement", func() interface{} { return &domain.LoanDisbursement{} })
+	registry.RegisterCompositeKey("REPAYMENT_INSTALLMENT", "Installment", func() interface{} { return &domain.Installment{} })
+	registry.RegisterCompositeKey("LOAN_PARTICIPATION", "LoanParticipation", func() interface{} { return &domain.LoanParticipation{} })
+	registry.RegisterCompositeKey("CUSTOMER_LOAN_PARTY", "LoanParty", func() interface{} { return &domain.LoanParty{} })
+	registry.RegisterCompositeKey("FACILITY_TRANSACTION", "FacilityTransaction", func() interface{} { return &domain.FacilityTransaction{} })
+	registry.RegisterCompositeKey("GUARANTOR_PAYMENT", "GuarantorPayment", func() interface{} { return &domain.GuarantorPayment{} })
+	registry.RegisterCompositeKey("INDEX_RATE", "IndexRate", func() interface{} { return &domain.IndexRate{} })
+	registry.RegisterCompositeKey("CUSTOMER_CREDIT_INQUIRY", "CreditInquiry", func() interface{} { return &domain.CreditInquiry{} })
+	registry.RegisterCompositeKey("CUSTOMER_CREDIT_REPORT", "CreditReport", func() interface{} { return &domain.CreditReport{} })
+	registry.RegisterCompositeKey("LOAN_SYNC", "StatusSyncRecord", func() interface{} { return &domain.StatusSyncRecord{} })
+	registry.RegisterCompositeKey("LOAN_SYNC_STATE", "StatusSyncState", func() interface{} { return &domain.StatusSyncState{} })
+	registry.RegisterCompositeKey("LOAN_WITHHOLDING", "WithholdingRecord", func() interface{} { return &domain.WithholdingRecord{} })
+	registry.RegisterCompositeKey("OPEN_BANKING_SUMMARY", "AccountTransactionSummary", func() interface{} { return &domain.AccountTransactionSummary{} })
+	registry.RegisterCompositeKey("AFFORDABILITY_ASSESSMENT", "AffordabilityAssessment", func() interface{} { return &domain.AffordabilityAssessment{} })
+	registry.RegisterCompositeKey("CERTIFIED_BALANCE", "CertifiedLoanBalance", func() interface{} { return &domain.CertifiedLoanBalance{} })
+	registry.RegisterCompositeKey("INTEREST_ACCRUAL", "InterestAccrual", func() interface{} { return &domain.InterestAccrual{} })
+	registry.RegisterCompositeKey("COMMISSION_ENTRY", "CommissionEntry", func() interface{} { return &domain.CommissionEntry{} })
+	registry.RegisterCompositeKey("CORRIDOR_REPORT", "CorridorReportEntry", func() interface{} { return &domain.CorridorReportEntry{} })
+	registry.RegisterCompositeKey("UNDERWRITING_CHECKLIST", "UnderwritingChecklist", func() interface{} { return &domain.UnderwritingChecklist{} })
+	registry.RegisterCompositeKey("LOAN_CHECKLIST_ITEM", "ChecklistItemCompletion", func() interface{} { return &domain.ChecklistItemCompletion{} })
+	registry.RegisterCompositeKey("RATE_POLICY_VERSION", "InterestRatePolicy", func() interface{} { return &domain.InterestRatePolicy{} })
+
+	return registry
+}
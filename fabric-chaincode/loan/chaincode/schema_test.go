@@ -0,0 +1,101 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/chaincode"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+)
+
+func validateState(t *testing.T, stub *shimtest.MockStub, args ...string) (*services.CompatibilityReport, error) {
+	stub.MockTransactionStart("validate")
+	defer stub.MockTransactionEnd("validate")
+	payload, err := chaincode.NewCompatibilityHandler(newLoanSchemaRegistry()).ValidateStateCompatibility(stub, args)
+	if err != nil {
+		return nil, err
+	}
+	var report services.CompatibilityReport
+	if err := json.Unmarshal(payload, &report); err != nil {
+		t.Fatalf("failed to decode report: %v", err)
+	}
+	return &report, nil
+}
+
+func TestValidateStateCompatibilityReportsUndecodableKeys(t *testing.T) {
+	stub := shimtest.NewMockStub("loan", nil)
+	stub.MockTransactionStart("seed")
+	put := func(key string, value []byte) {
+		if err := stub.PutState(key, value); err != nil {
+			t.Fatalf("failed to store %s: %v", key, err)
+		}
+	}
+	current, _ := json.Marshal(domain.LoanApplication{LoanID: "L1", CustomerID: "CUST_001", LoanType: "PERSONAL", RequestedAmount: utils.NewMoney(12000, "USD"), TermMonths: 12})
+	put("LOAN_L1", current)
+	// A field the struct has dropped would be lost on the next write; a changed type would not load
+	put("LOAN_L2", []byte(`{"loanID":"L2","customerID":"CUST_001","legacyScore":712}`))
+	put("LOAN_L3", []byte(`{"loanID":"L3","customerID":"CUST_001","termMonths":"twelve"}`))
+	// Raw ID indexes under a longer prefix are neither decoded nor counted as loans
+	put("CUSTOMER_LOAN_CUST_001_L1", []byte("L1"))
+	transaction, _ := json.Marshal(domain.LoanTransaction{TransactionID: "TXN_1", LoanID: "L1", Amount: utils.NewMoney(100, "USD"), BalanceAfter: utils.NewMoney(11900, "USD")})
+	transactionKey, _ := stub.CreateCompositeKey("LOAN_TRANSACTION", []string{"L1", "TXN_1"})
+	put(transactionKey, transaction)
+	truncatedKey, _ := stub.CreateCompositeKey("LOAN_TRANSACTION", []string{"L1", "TXN_2"})
+	put(truncatedKey, []byte(`{"transactionID":"TXN_2"`))
+	stub.MockTransactionEnd("seed")
+
+	report, err := validateState(t, stub)
+	if err != nil {
+		t.Fatalf("ValidateStateCompatibility failed: %v", err)
+	}
+	if report.Compatible {
+		t.Fatalf("expected incompatible state to be reported")
+	}
+	issues := map[string]services.CompatibilityIssue{}
+	for _, issue := range report.Issues {
+		issues[issue.Key] = issue
+	}
+	for _, key := range []string{"LOAN_L2", "LOAN_L3", truncatedKey} {
+		if _, ok := issues[key]; !ok {
+			t.Errorf("expected %q reported, got %+v", key, report.Issues)
+		}
+	}
+	if len(issues) != 3 {
+		t.Errorf("expected only the three undecodable keys reported, got %+v", report.Issues)
+	}
+	if issue := issues["LOAN_L2"]; issue.EntityType != "LoanApplication" || issue.Error == "" {
+		t.Errorf("expected the LoanApplication decode error for LOAN_L2, got %+v", issue)
+	}
+	for _, namespace := range report.Namespaces {
+		switch namespace.Namespace {
+		case "LOAN_":
+			if namespace.KeysChecked != 3 || namespace.IncompatibleKeys != 2 {
+				t.Errorf("expected 2 of 3 loans incompatible, got %+v", namespace)
+			}
+		case "LOAN_TRANSACTION":
+			if namespace.KeysChecked != 2 || namespace.IncompatibleKeys != 1 {
+				t.Errorf("expected 1 of 2 transactions incompatible, got %+v", namespace)
+			}
+		case "CUSTOMER_LOAN_":
+			t.Errorf("expected the customer loan index skipped")
+		}
+	}
+
+	// A single namespace can be checked on its own
+	report, err = validateState(t, stub, "LOAN_TRANSACTION")
+	if err != nil {
+		t.Fatalf("ValidateStateCompatibility failed: %v", err)
+	}
+	if len(report.Namespaces) != 1 || len(report.Issues) != 1 || report.Issues[0].Key != truncatedKey {
+		t.Errorf("expected only the truncated transaction reported, got %+v", report)
+	}
+	if report, err := validateState(t, stub, "COLLATERAL_"); err != nil || !report.Compatible {
+		t.Errorf("expected the empty collateral namespace compatible, got %+v (%v)", report, err)
+	}
+	if _, err := validateState(t, stub, "LOAN_ARCHIVE_"); err == nil {
+		t.Errorf("expected an unregistered namespace to be refused")
+	}
+}
@@ -0,0 +1,89 @@
+package domain
+
+import "time"
+
+// LoanAmountBoundWildcard matches any risk tier or loan type in a loan amount bound
+const LoanAmountBoundWildcard = "*"
+
+// LoanAmountTierUnrated is the risk tier bounds apply to for customers who have not been risk rated
+const LoanAmountTierUnrated = "UNRATED"
+
+// LoanAmountTiers lists the customer risk tiers a loan amount bound may name, besides the wildcard
+var LoanAmountTiers = []string{"LOW", "MEDIUM", "HIGH", "VERY_HIGH", LoanAmountTierUnrated}
+
+// LoanAmountBound narrows the amounts a customer in a risk tier may borrow on a loan type, within
+// the product limits. Either the tier or the loan type may be LoanAmountBoundWildcard; the most
+// specific bound applies. Amounts matching no bound are limited by the product limits alone.
+type LoanAmountBound struct {
+	RiskTier      string    `json:"riskTier"`
+	LoanType      string    `json:"loanType"`
+	MinAmount     float64   `json:"minAmount"`
+	MaxAmount     float64   `json:"maxAmount"`
+	LastUpdated   time.Time `json:"lastUpdated"`
+	LastUpdatedBy string    `json:"lastUpdatedBy"`
+}
+
+// LoanAmountBoundRequest represents a request to create or replace a loan amount bound
+type LoanAmountBoundRequest struct {
+	RiskTier  string  `json:"riskTier"`
+	LoanType  string  `json:"loanType"`
+	MinAmount float64 `json:"minAmount"`
+	MaxAmount float64 `json:"maxAmount"`
+	ActorID   string  `json:"actorID"`
+}
+
+// PolicyExceptionType names the policy an exception departs from
+type PolicyExceptionType string
+
+const (
+	PolicyExceptionLoanAmount PolicyExceptionType = "LOAN_AMOUNT_BOUND"
+)
+
+// PolicyExceptionStatus tracks an exception through approval
+type PolicyExceptionStatus string
+
+const (
+	PolicyExceptionPending  PolicyExceptionStatus = "PENDING"
+	PolicyExceptionApproved PolicyExceptionStatus = "APPROVED"
+	PolicyExceptionRejected PolicyExceptionStatus = "REJECTED"
+)
+
+// PolicyException is an entry in the exceptions register: a departure from credit policy for one
+// customer and loan type, requested by one actor and approved by a credit officer who did not
+// request it. An approved loan amount exception replaces the customer's amount bound with its own
+// until it expires, and is bound to the first loan that relies on it.
+type PolicyException struct {
+	ExceptionID   string                `json:"exceptionID"`
+	Type          PolicyExceptionType   `json:"type"`
+	CustomerID    string                `json:"customerID"`
+	LoanType      string                `json:"loanType"`
+	LoanID        string                `json:"loanID,omitempty"` // Loan relying on the exception, once one does
+	MinAmount     float64               `json:"minAmount"`
+	MaxAmount     float64               `json:"maxAmount"`
+	Justification string                `json:"justification"`
+	Status        PolicyExceptionStatus `json:"status"`
+	RequestedBy   string                `json:"requestedBy"`
+	RequestedDate time.Time             `json:"requestedDate"`
+	DecidedBy     string                `json:"decidedBy,omitempty"`
+	DecidedDate   *time.Time            `json:"decidedDate,omitempty"`
+	DecisionNotes string                `json:"decisionNotes,omitempty"`
+	ExpiryDate    *time.Time            `json:"expiryDate,omitempty"` // Approved exceptions lapse unless relied on before this
+}
+
+// PolicyExceptionRequest represents a request to raise a loan amount exception
+type PolicyExceptionRequest struct {
+	CustomerID    string  `json:"customerID"`
+	LoanType      string  `json:"loanType"`
+	MinAmount     float64 `json:"minAmount"`
+	MaxAmount     float64 `json:"maxAmount"`
+	Justification string  `json:"justification"`
+	ActorID       string  `json:"actorID"`
+}
+
+// PolicyExceptionDecisionRequest represents a credit officer's decision on a pending exception
+type PolicyExceptionDecisionRequest struct {
+	ExceptionID string `json:"exceptionID"`
+	Approve     bool   `json:"approve"`
+	Notes       string `json:"notes"`
+	ActorID     string `json:"actorID"`
+}
@@ -0,0 +1,70 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// BalanceSnapshotStatus represents the progress of an end-of-day balance snapshot
+type BalanceSnapshotStatus string
+
+const (
+	BalanceSnapshotInProgress BalanceSnapshotStatus = "IN_PROGRESS"
+	BalanceSnapshotCertified  BalanceSnapshotStatus = "CERTIFIED"
+)
+
+// BalanceSnapshotRequest triggers the next batch of the end-of-day snapshot for a date. The
+// scheduler repeats the call until the snapshot comes back CERTIFIED.
+type BalanceSnapshotRequest struct {
+	SnapshotDate string `json:"snapshotDate"`        // YYYY-MM-DD; balances are certified as at the end of the day (UTC)
+	BatchSize    int    `json:"batchSize,omitempty"` // Loans read per call; defaults to and is capped at config.MaxSnapshotBatchSize
+	ActorID      string `json:"actorID"`
+}
+
+// BalanceAggregate totals the certified balances of a group of loans
+type BalanceAggregate struct {
+	LoanCount int     `json:"loanCount"`
+	Balance   float64 `json:"balance"`
+}
+
+// BalanceSnapshot is the on-ledger record of an end-of-day snapshot. While IN_PROGRESS it carries
+// the checkpoint the next batch resumes from; once CERTIFIED its totals and hash are fixed.
+type BalanceSnapshot struct {
+	SnapshotDate  string                      `json:"snapshotDate"`
+	CutoffTime    time.Time                   `json:"cutoffTime"` // Transactions before this instant are included
+	Status        BalanceSnapshotStatus       `json:"status"`
+	Checkpoint    string                      `json:"checkpoint,omitempty"` // Key of the last loan read; cleared on certification
+	LoansScanned  int                         `json:"loansScanned"`
+	LoanCount     int                         `json:"loanCount"` // Loans with a certified balance
+	TotalBalance  float64                     `json:"totalBalance"`
+	ByStatus      map[string]BalanceAggregate `json:"byStatus"`
+	ByLoanType    map[string]BalanceAggregate `json:"byLoanType"`
+	EntriesHash   string                      `json:"entriesHash"`            // SHA-256 chain over every certified balance's entry hash, in loan order
+	SnapshotHash  string                      `json:"snapshotHash,omitempty"` // SHA-256 over the header, totals and entries hash; set on certification
+	Batches       int                         `json:"batches"`
+	StartedBy     string                      `json:"startedBy"`
+	StartedDate   time.Time                   `json:"startedDate"`
+	CertifiedBy   string                      `json:"certifiedBy,omitempty"`
+	CertifiedDate *time.Time                  `json:"certifiedDate,omitempty"`
+}
+
+// CertifiedLoanBalance is one loan's balance as certified by an end-of-day snapshot
+type CertifiedLoanBalance struct {
+	SnapshotDate      string                           `json:"snapshotDate"`
+	LoanID            string                           `json:"loanID"`
+	CustomerID        string                           `json:"customerID"`
+	LoanType          string                           `json:"loanType"`
+	Status            validation.LoanApplicationStatus `json:"status"`  // Status when the snapshot read the loan
+	Balance           float64                          `json:"balance"` // Balance after the last loan transaction before the cutoff
+	LastTransactionID string                           `json:"lastTransactionID,omitempty"`
+	EntryHash         string                           `json:"entryHash"` // SHA-256 of the entry with this field empty
+}
+
+// CertifiedBalancesResult is a page of a certified snapshot's loan balances
+type CertifiedBalancesResult struct {
+	Snapshot BalanceSnapshot        `json:"snapshot"`
+	Balances []CertifiedLoanBalance `json:"balances"`
+	Count    int                    `json:"count"`
+	Bookmark string                 `json:"bookmark"`
+}
@@ -0,0 +1,89 @@
+package domain
+
+import (
+	"time"
+)
+
+// Bulk operations on the loan book
+const (
+	BulkOperationPlaceHold            = "PLACE_COMPLIANCE_HOLD"
+	BulkOperationReleaseHold          = "RELEASE_COMPLIANCE_HOLD"
+	BulkOperationReassignOwner        = "REASSIGN_OWNER"
+	BulkOperationRevalidateEligibility = "REVALIDATE_ELIGIBILITY"
+)
+
+// ComplianceHold stops a loan from progressing until it is released
+type ComplianceHold struct {
+	Reason      string    `json:"reason"`
+	PlacedBy    string    `json:"placedBy"`
+	PlacedDate  time.Time `json:"placedDate"`
+	OperationID string    `json:"operationID,omitempty"` // Bulk operation that placed the hold
+}
+
+// LoanEligibility records the outcome of re-validating a loan's parties against the current
+// KYC/AML/consent rules
+type LoanEligibility struct {
+	Eligible    bool      `json:"eligible"`
+	Problems    []string  `json:"problems,omitempty"`
+	CheckedDate time.Time `json:"checkedDate"`
+	CheckedBy   string    `json:"checkedBy"`
+}
+
+// Owner returns the staff member responsible for the loan. Loans submitted before owners were
+// recorded are owned by the actor that submitted them.
+func (l *LoanApplication) Owner() string {
+	if l.OwnerActorID != "" {
+		return l.OwnerActorID
+	}
+	return l.CreatedBy
+}
+
+// LoanBookFilter selects a segment of the loan book. A status or a date range is required so a
+// batch scans one index rather than the whole book.
+type LoanBookFilter struct {
+	LoanType string `json:"loanType,omitempty"`
+	Status   string `json:"status,omitempty"`
+	FromDate string `json:"fromDate,omitempty"` // Application date, YYYY-MM-DD inclusive
+	ToDate   string `json:"toDate,omitempty"`
+}
+
+// BulkLoanOperationRequest represents a bulk operation over one batch of a loan book segment.
+// A dry run reports what the batch would change without writing anything; the returned
+// bookmark continues with the next batch.
+type BulkLoanOperationRequest struct {
+	Filter      LoanBookFilter `json:"filter"`
+	Reason      string         `json:"reason,omitempty"`      // Required to place a hold
+	FromActorID string         `json:"fromActorID,omitempty"` // Owner being replaced, for reassignment
+	ToActorID   string         `json:"toActorID,omitempty"`
+	DryRun      bool           `json:"dryRun"`
+	BatchSize   int            `json:"batchSize,omitempty"` // Index entries scanned; defaults to and is capped at config.MaxBulkBatchSize
+	Bookmark    string         `json:"bookmark,omitempty"`
+	ActorID     string         `json:"actorID"`
+}
+
+// BulkLoanOutcome is what a bulk operation did, or would do, to one loan in the segment
+type BulkLoanOutcome struct {
+	LoanID        string `json:"loanID"`
+	Status        string `json:"status"`
+	Changed       bool   `json:"changed"`
+	PreviousValue string `json:"previousValue,omitempty"`
+	NewValue      string `json:"newValue,omitempty"`
+	Detail        string `json:"detail,omitempty"` // Why the loan was left unchanged, or the eligibility problems found
+}
+
+// BulkLoanOperation is the record of one batch of a bulk operation. Dry runs are returned but
+// not stored.
+type BulkLoanOperation struct {
+	OperationID   string            `json:"operationID,omitempty"`
+	Operation     string            `json:"operation"`
+	Filter        LoanBookFilter    `json:"filter"`
+	DryRun        bool              `json:"dryRun"`
+	Scanned       int               `json:"scanned"`
+	Matched       int               `json:"matched"`
+	Changed       int               `json:"changed"`
+	Outcomes      []BulkLoanOutcome `json:"outcomes"`
+	Bookmark      string            `json:"bookmark"` // Empty when the segment is exhausted
+	PerformedBy   string            `json:"performedBy"`
+	PerformedDate time.Time         `json:"performedDate"`
+	TransactionID string            `json:"transactionID"`
+}
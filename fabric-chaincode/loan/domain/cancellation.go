@@ -0,0 +1,59 @@
+package domain
+
+import (
+	"time"
+)
+
+// Parties that may withdraw or cancel an application before funds are released
+const (
+	CancellationByCustomer   = "CUSTOMER"   // Borrower withdrew; recorded by bank staff on their instruction
+	CancellationByIntroducer = "INTRODUCER" // Broker that submitted the application withdrew it
+	CancellationByBank       = "BANK"       // Bank cancelled the application
+)
+
+// CancellationReasonOther requires notes explaining the cancellation
+const CancellationReasonOther = "OTHER"
+
+// CancellationReasons lists the reason codes each initiator may give
+var CancellationReasons = map[string][]string{
+	CancellationByCustomer: {
+		"FOUND_ALTERNATIVE_FINANCE",
+		"NO_LONGER_REQUIRED",
+		"TERMS_NOT_ACCEPTABLE",
+		"PROCESSING_TIME",
+		CancellationReasonOther,
+	},
+	CancellationByIntroducer: {
+		"CLIENT_WITHDREW",
+		"SUBMITTED_IN_ERROR",
+		"DUPLICATE_APPLICATION",
+		CancellationReasonOther,
+	},
+	CancellationByBank: {
+		"DOCUMENTS_NOT_PROVIDED",
+		"OFFER_EXPIRED",
+		"DUPLICATE_APPLICATION",
+		"SUSPECTED_FRAUD",
+		CancellationReasonOther,
+	},
+}
+
+// LoanCancellationRequest represents a request to cancel an application that has not been funded
+type LoanCancellationRequest struct {
+	LoanID      string `json:"loanID"`
+	InitiatedBy string `json:"initiatedBy"` // CUSTOMER, INTRODUCER or BANK
+	ReasonCode  string `json:"reasonCode"`
+	Notes       string `json:"notes,omitempty"` // Required for reason OTHER
+	ExpectedVersion *int `json:"expectedVersion,omitempty"` // Rejected with CONFLICT unless the loan is still at this version
+	ActorID     string `json:"actorID"`
+}
+
+// LoanCancellation records who cancelled an application, why, and the status it was cancelled from
+type LoanCancellation struct {
+	InitiatedBy    string    `json:"initiatedBy"`
+	ReasonCode     string    `json:"reasonCode"`
+	Notes          string    `json:"notes,omitempty"`
+	PreviousStatus string    `json:"previousStatus"`
+	CancelledBy    string    `json:"cancelledBy"`
+	CancelledDate  time.Time `json:"cancelledDate"`
+}
@@ -0,0 +1,62 @@
+package domain
+
+import (
+	"time"
+)
+
+// LienStatus represents the status of the lender's lien over collateral
+type LienStatus string
+
+const (
+	LienStatusActive   LienStatus = "ACTIVE"
+	LienStatusReleased LienStatus = "RELEASED"
+)
+
+// Collateral represents an asset pledged against a loan application
+type Collateral struct {
+	CollateralID   string     `json:"collateralID"`
+	LoanID         string     `json:"loanID"`
+	CollateralType string     `json:"collateralType"`
+	Description    string     `json:"description"`
+	Valuation      float64    `json:"valuation"`
+	ValuationDate  time.Time  `json:"valuationDate"`
+	AppraiserID    string     `json:"appraiserID"`
+	LienStatus     LienStatus `json:"lienStatus"`
+	ReleaseDate    *time.Time `json:"releaseDate,omitempty"`
+	ReleaseReason  string     `json:"releaseReason,omitempty"`
+	CreatedDate    time.Time  `json:"createdDate"`
+	LastUpdated    time.Time  `json:"lastUpdated"`
+	CreatedBy      string     `json:"createdBy"`
+	LastUpdatedBy  string     `json:"lastUpdatedBy"`
+	Version        int        `json:"version"` // Advanced on every write; revaluations and releases may name it as expectedVersion
+}
+
+// CollateralRequest represents a request to pledge collateral against a loan
+type CollateralRequest struct {
+	LoanID         string    `json:"loanID"`
+	CollateralType string    `json:"collateralType"`
+	Description    string    `json:"description"`
+	Valuation      float64   `json:"valuation"`
+	ValuationDate  time.Time `json:"valuationDate"`
+	AppraiserID    string    `json:"appraiserID"`
+	ExpectedVersion *int     `json:"expectedVersion,omitempty"` // Rejected with CONFLICT unless the loan is still at this version
+	ActorID        string    `json:"actorID"`
+}
+
+// CollateralValuationRequest represents a revaluation of pledged collateral
+type CollateralValuationRequest struct {
+	CollateralID  string    `json:"collateralID"`
+	Valuation     float64   `json:"valuation"`
+	ValuationDate time.Time `json:"valuationDate"`
+	AppraiserID   string    `json:"appraiserID"`
+	ExpectedVersion *int    `json:"expectedVersion,omitempty"` // Rejected with CONFLICT unless the collateral is still at this version
+	ActorID       string    `json:"actorID"`
+}
+
+// CollateralReleaseRequest represents a request to release the lien over collateral
+type CollateralReleaseRequest struct {
+	CollateralID string `json:"collateralID"`
+	Reason       string `json:"reason"`
+	ExpectedVersion *int `json:"expectedVersion,omitempty"` // Rejected with CONFLICT unless the collateral is still at this version
+	ActorID      string `json:"actorID"`
+}
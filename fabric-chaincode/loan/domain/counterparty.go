@@ -0,0 +1,93 @@
+package domain
+
+import (
+	"time"
+	
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/interfaces"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// Counterparty represents an external party the platform moves money with (bank, investor, servicer)
+type Counterparty struct {
+	CounterpartyID        string                        `json:"counterpartyID"`
+	LegalName             string                        `json:"legalName"`
+	CounterpartyType      string                        `json:"counterpartyType"`
+	RegistrationNumber    string                        `json:"registrationNumber"`
+	Jurisdiction          string                        `json:"jurisdiction"`
+	BIC                   string                        `json:"bic,omitempty"`
+	Purpose               string                        `json:"purpose,omitempty"` // Business the counterparty does with the platform
+	PurposeScreening      *interfaces.TextScreeningResult `json:"purposeScreening,omitempty"` // Set when the purpose matched screening terms
+	Status                validation.CounterpartyStatus `json:"status"`
+	KYCStatus             validation.KYCStatus          `json:"kycStatus"`
+	KYCVerificationDate   *time.Time                    `json:"kycVerificationDate,omitempty"`
+	KYCExpiryDate         *time.Time                    `json:"kycExpiryDate,omitempty"`
+	DocumentHashes        []string                      `json:"documentHashes"`
+	SanctionsStatus       validation.AMLStatus          `json:"sanctionsStatus"`
+	SanctionsScreeningID  string                        `json:"sanctionsScreeningID,omitempty"`
+	SanctionsScreenedDate *time.Time                    `json:"sanctionsScreenedDate,omitempty"`
+	Notes                 string                        `json:"notes"`
+	CreatedDate           time.Time                     `json:"createdDate"`
+	LastUpdated           time.Time                     `json:"lastUpdated"`
+	CreatedBy             string                        `json:"createdBy"`
+	LastUpdatedBy         string                        `json:"lastUpdatedBy"`
+	Version               int                           `json:"version"` // Advanced on every write; status updates may name it as expectedVersion
+}
+
+// LoanParticipation links a counterparty to a loan as an investor or servicer
+type LoanParticipation struct {
+	ParticipationID string    `json:"participationID"`
+	LoanID          string    `json:"loanID"`
+	CounterpartyID  string    `json:"counterpartyID"`
+	Role            string    `json:"role"` // INVESTOR, SERVICER
+	SharePercentage float64   `json:"sharePercentage"`
+	CreatedDate     time.Time `json:"createdDate"`
+	CreatedBy       string    `json:"createdBy"`
+}
+
+// CounterpartyRegistrationRequest represents a counterparty onboarding request
+type CounterpartyRegistrationRequest struct {
+	LegalName          string   `json:"legalName"`
+	CounterpartyType   string   `json:"counterpartyType"`
+	RegistrationNumber string   `json:"registrationNumber"`
+	Jurisdiction       string   `json:"jurisdiction"`
+	BIC                string   `json:"bic"`
+	Purpose            string   `json:"purpose"`
+	DocumentHashes     []string `json:"documentHashes"`
+	ActorID            string   `json:"actorID"`
+}
+
+// CounterpartyKYCUpdateRequest represents a counterparty KYC review outcome
+type CounterpartyKYCUpdateRequest struct {
+	CounterpartyID    string               `json:"counterpartyID"`
+	NewStatus         validation.KYCStatus `json:"newStatus"`
+	DocumentHashes    []string             `json:"documentHashes"`
+	VerificationNotes string               `json:"verificationNotes"`
+	ActorID           string               `json:"actorID"`
+}
+
+// CounterpartyScreeningRequest records the outcome of a sanctions screening of a counterparty
+type CounterpartyScreeningRequest struct {
+	CounterpartyID string               `json:"counterpartyID"`
+	ScreeningID    string               `json:"screeningID"`
+	Result         validation.AMLStatus `json:"result"`
+	Notes          string               `json:"notes"`
+	ActorID        string               `json:"actorID"`
+}
+
+// CounterpartyStatusUpdateRequest represents a counterparty status update request
+type CounterpartyStatusUpdateRequest struct {
+	CounterpartyID  string                        `json:"counterpartyID"`
+	NewStatus       validation.CounterpartyStatus `json:"newStatus"`
+	Reason          string                        `json:"reason"`
+	ExpectedVersion *int                          `json:"expectedVersion,omitempty"` // Rejected with CONFLICT unless the counterparty is still at this version
+	ActorID         string                        `json:"actorID"`
+}
+
+// LoanParticipationRequest represents a request to add a counterparty to a loan
+type LoanParticipationRequest struct {
+	LoanID          string  `json:"loanID"`
+	CounterpartyID  string  `json:"counterpartyID"`
+	Role            string  `json:"role"`
+	SharePercentage float64 `json:"sharePercentage"`
+	ActorID         string  `json:"actorID"`
+}
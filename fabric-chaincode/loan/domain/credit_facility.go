@@ -0,0 +1,101 @@
+package domain
+
+import (
+	"time"
+)
+
+// FacilityStatus represents the lifecycle status of a credit facility
+type FacilityStatus string
+
+const (
+	FacilityStatusActive    FacilityStatus = "ACTIVE"
+	FacilityStatusFrozen    FacilityStatus = "FROZEN"    // No new drawdowns; repayments allowed
+	FacilityStatusConverted FacilityStatus = "CONVERTED" // Balance moved to a term loan
+	FacilityStatusClosed    FacilityStatus = "CLOSED"
+)
+
+// FacilityTransactionType represents a movement against a credit facility
+type FacilityTransactionType string
+
+const (
+	FacilityTransactionDrawdown  FacilityTransactionType = "DRAWDOWN"
+	FacilityTransactionRepayment FacilityTransactionType = "REPAYMENT"
+)
+
+// CreditFacility represents an approved revolving credit limit
+type CreditFacility struct {
+	FacilityID      string         `json:"facilityID"`
+	CustomerID      string         `json:"customerID"`
+	LoanType        string         `json:"loanType"`
+	CreditLimit     float64        `json:"creditLimit"`
+	DrawnBalance    float64        `json:"drawnBalance"`
+	AvailableAmount float64        `json:"availableAmount"`
+	Utilization     float64        `json:"utilization"` // Percentage of limit drawn
+	InterestRate    float64        `json:"interestRate"`
+	RateIndex       string         `json:"rateIndex,omitempty"`
+	RateMargin      *float64       `json:"rateMargin,omitempty"`
+	IndexRateDate   string         `json:"indexRateDate,omitempty"`
+	Status          FacilityStatus `json:"status"`
+	ApprovalDate    time.Time      `json:"approvalDate"`
+	ExpiryDate      time.Time      `json:"expiryDate"`
+	LastReviewDate  *time.Time     `json:"lastReviewDate,omitempty"`
+	LastReviewedBy  string         `json:"lastReviewedBy,omitempty"`
+	ConvertedLoanID string         `json:"convertedLoanID,omitempty"`
+	Sandbox         bool           `json:"sandbox,omitempty"`
+	Notes           string         `json:"notes"`
+	CreatedDate     time.Time      `json:"createdDate"`
+	LastUpdated     time.Time      `json:"lastUpdated"`
+	CreatedBy       string         `json:"createdBy"`
+	LastUpdatedBy   string         `json:"lastUpdatedBy"`
+}
+
+// FacilityTransaction represents a drawdown or repayment against a credit facility
+type FacilityTransaction struct {
+	TransactionID   string                  `json:"transactionID"`
+	FacilityID      string                  `json:"facilityID"`
+	TransactionType FacilityTransactionType `json:"transactionType"`
+	Amount          float64                 `json:"amount"`
+	Reference       string                  `json:"reference"`
+	CounterpartyID  string                  `json:"counterpartyID,omitempty"`
+	BalanceAfter    float64                 `json:"balanceAfter"`
+	CreatedDate     time.Time               `json:"createdDate"`
+	CreatedBy       string                  `json:"createdBy"`
+}
+
+// CreditFacilityRequest represents a request to open a credit facility
+type CreditFacilityRequest struct {
+	CustomerID   string  `json:"customerID"`
+	LoanType     string  `json:"loanType"`
+	CreditLimit  float64 `json:"creditLimit"`
+	InterestRate float64 `json:"interestRate"`
+	RateIndex    string  `json:"rateIndex,omitempty"`
+	RateMargin   float64 `json:"rateMargin,omitempty"`
+	TermMonths   int     `json:"termMonths"` // Months until the limit expires
+	ActorID      string  `json:"actorID"`
+}
+
+// FacilityTransactionRequest represents a drawdown or repayment request
+type FacilityTransactionRequest struct {
+	FacilityID     string  `json:"facilityID"`
+	Amount         float64 `json:"amount"`
+	Reference      string  `json:"reference"`
+	CounterpartyID string  `json:"counterpartyID"`
+	ActorID        string  `json:"actorID"`
+}
+
+// FacilityReviewRequest represents a periodic limit review outcome
+type FacilityReviewRequest struct {
+	FacilityID      string  `json:"facilityID"`
+	NewCreditLimit  float64 `json:"newCreditLimit"`
+	ExtensionMonths int     `json:"extensionMonths"`
+	Freeze          bool    `json:"freeze"`
+	Notes           string  `json:"notes"`
+	ActorID         string  `json:"actorID"`
+}
+
+// FacilityConversionRequest represents a request to term out a facility balance
+type FacilityConversionRequest struct {
+	FacilityID string `json:"facilityID"`
+	TermMonths int    `json:"termMonths"`
+	ActorID    string `json:"actorID"`
+}
@@ -0,0 +1,79 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// CreditInquiry records a credit check made against a customer
+type CreditInquiry struct {
+	InquiryID        string                       `json:"inquiryID"`
+	CustomerID       string                       `json:"customerID"`
+	InquiryType      validation.CreditInquiryType `json:"inquiryType"`
+	ReferenceID      string                       `json:"referenceID,omitempty"`   // Loan or facility whose application made the inquiry
+	ReferenceType    string                       `json:"referenceType,omitempty"` // LoanApplication or CreditFacility
+	Purpose          string                       `json:"purpose"`
+	InquiryDate      time.Time                    `json:"inquiryDate"`
+	RequestedBy      string                       `json:"requestedBy"`
+	BureauName       string                       `json:"bureauName,omitempty"`       // Set for checks requested from a credit bureau
+	ConsentReference string                       `json:"consentReference,omitempty"` // Transaction recording the bureau sharing consent relied on
+	ReportID         string                       `json:"reportID,omitempty"`         // Bureau report recorded against the inquiry
+}
+
+// CreditInquiryRequest represents a request to record a soft or hard credit check
+type CreditInquiryRequest struct {
+	CustomerID  string `json:"customerID"`
+	InquiryType string `json:"inquiryType"`
+	Purpose     string `json:"purpose"`
+	ActorID     string `json:"actorID"`
+}
+
+// CreditCheckRequest represents a request to pull a customer's file from a credit bureau
+type CreditCheckRequest struct {
+	CustomerID  string `json:"customerID"`
+	BureauName  string `json:"bureauName"`
+	InquiryType string `json:"inquiryType"`
+	Purpose     string `json:"purpose"`
+	LoanID      string `json:"loanID,omitempty"` // Loan application the check is for, if any
+	ActorID     string `json:"actorID"`
+}
+
+// CreditReport is the result a credit bureau returned for an inquiry. The report itself stays off
+// chain; its hash anchors the copy held by the bank.
+type CreditReport struct {
+	ReportID         string    `json:"reportID"`
+	CustomerID       string    `json:"customerID"`
+	InquiryID        string    `json:"inquiryID"`
+	BureauName       string    `json:"bureauName"`
+	ReportHash       string    `json:"reportHash"`
+	Score            int       `json:"score"`
+	InquiryDate      time.Time `json:"inquiryDate"`
+	ConsentReference string    `json:"consentReference"`
+	RecordedDate     time.Time `json:"recordedDate"`
+	RecordedBy       string    `json:"recordedBy"`
+}
+
+// CreditReportRequest records the report a bureau returned for a credit check
+type CreditReportRequest struct {
+	CustomerID string `json:"customerID"`
+	InquiryID  string `json:"inquiryID"`
+	ReportHash string `json:"reportHash"`
+	Score      int    `json:"score"`
+	ActorID    string `json:"actorID"`
+}
+
+// CreditInquiryWindow counts a customer's inquiries over a rolling window
+type CreditInquiryWindow struct {
+	Days      int `json:"days"`
+	SoftCount int `json:"softCount"`
+	HardCount int `json:"hardCount"`
+}
+
+// CreditInquiryHistory lists a customer's credit inquiries with rolling window counts
+type CreditInquiryHistory struct {
+	CustomerID string                `json:"customerID"`
+	Inquiries  []CreditInquiry       `json:"inquiries"`
+	Windows    []CreditInquiryWindow `json:"windows"`
+	AsOf       time.Time             `json:"asOf"`
+}
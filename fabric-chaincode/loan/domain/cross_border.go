@@ -0,0 +1,80 @@
+package domain
+
+import "time"
+
+// CorridorAction is what happens to a disbursement paid across a corridor
+type CorridorAction string
+
+const (
+	CorridorActionAllow CorridorAction = "ALLOW"
+	CorridorActionFlag  CorridorAction = "FLAG"  // Paid, but flagged for compliance review
+	CorridorActionBlock CorridorAction = "BLOCK" // Rejected
+)
+
+// CorridorWildcard matches any country in a corridor rule
+const CorridorWildcard = "*"
+
+// CorridorRule configures the checks on disbursements from customers resident in one country to
+// payees in another. Either country may be CorridorWildcard; the most specific rule applies.
+// Cross-border disbursements matching no rule are allowed and not reported.
+type CorridorRule struct {
+	OriginCountry      string         `json:"originCountry"`      // Customer residency, ISO 3166-1 alpha-2
+	DestinationCountry string         `json:"destinationCountry"` // Payee country, ISO 3166-1 alpha-2
+	Action             CorridorAction `json:"action"`
+	ReportingThreshold float64        `json:"reportingThreshold"`   // Disbursements of at least this amount are reported; 0 reports every one
+	ReportType         string         `json:"reportType,omitempty"` // Regulatory report the corridor's entries belong to; empty disables reporting
+	Reason             string         `json:"reason,omitempty"`
+	LastUpdated        time.Time      `json:"lastUpdated"`
+	LastUpdatedBy      string         `json:"lastUpdatedBy"`
+}
+
+// CrossBorderCheck records the corridor checks applied to a cross-border disbursement
+type CrossBorderCheck struct {
+	OriginCountry      string         `json:"originCountry"`
+	DestinationCountry string         `json:"destinationCountry"`
+	RuleOrigin         string         `json:"ruleOrigin,omitempty"` // Corridor of the rule applied; empty when no rule matched
+	RuleDestination    string         `json:"ruleDestination,omitempty"`
+	Action             CorridorAction `json:"action"`
+	Flagged            bool           `json:"flagged"`
+	ReportEntryID      string         `json:"reportEntryID,omitempty"` // Regulatory report entry generated for the disbursement
+}
+
+// CorridorReportEntry is one cross-border disbursement reported for a corridor
+type CorridorReportEntry struct {
+	EntryID            string    `json:"entryID"`
+	ReportType         string    `json:"reportType"`
+	OriginCountry      string    `json:"originCountry"`
+	DestinationCountry string    `json:"destinationCountry"`
+	Period             string    `json:"period"` // YYYY-MM the disbursement falls in
+	LoanID             string    `json:"loanID"`
+	CustomerID         string    `json:"customerID"`
+	DisbursementID     string    `json:"disbursementID"`
+	Amount             float64   `json:"amount"`
+	Threshold          float64   `json:"threshold"`
+	Flagged            bool      `json:"flagged"`
+	DisbursementDate   time.Time `json:"disbursementDate"`
+	RecordedBy         string    `json:"recordedBy"`
+}
+
+// CorridorReport lists the entries reported for a corridor in one month
+type CorridorReport struct {
+	OriginCountry      string                `json:"originCountry"`
+	DestinationCountry string                `json:"destinationCountry"`
+	Period             string                `json:"period"`
+	Entries            []CorridorReportEntry `json:"entries"`
+	EntryCount         int                   `json:"entryCount"`
+	FlaggedCount       int                   `json:"flaggedCount"`
+	TotalAmount        float64               `json:"totalAmount"`
+	GeneratedDate      time.Time             `json:"generatedDate"`
+}
+
+// CorridorRuleRequest creates or replaces the rule for a corridor
+type CorridorRuleRequest struct {
+	OriginCountry      string         `json:"originCountry"`
+	DestinationCountry string         `json:"destinationCountry"`
+	Action             CorridorAction `json:"action"`
+	ReportingThreshold float64        `json:"reportingThreshold"`
+	ReportType         string         `json:"reportType"`
+	Reason             string         `json:"reason"`
+	ActorID            string         `json:"actorID"`
+}
@@ -0,0 +1,17 @@
+package domain
+
+import "time"
+
+// CurrencyConversion snapshots the FX fixing a loan amount was converted to the base currency at,
+// so the limits checked at decision time can be reproduced later
+type CurrencyConversion struct {
+	FromCurrency    string    `json:"fromCurrency"`
+	ToCurrency      string    `json:"toCurrency"`
+	RateIndex       string    `json:"rateIndex"` // FX index the rate was read from, e.g. FX_EURUSD
+	Rate            float64   `json:"rate"`      // Units of ToCurrency per unit of FromCurrency
+	EffectiveDate   string    `json:"effectiveDate"`
+	OracleID        string    `json:"oracleID"`
+	Amount          float64   `json:"amount"`          // In FromCurrency
+	ConvertedAmount float64   `json:"convertedAmount"` // In ToCurrency, rounded to its minor units
+	CapturedDate    time.Time `json:"capturedDate"`
+}
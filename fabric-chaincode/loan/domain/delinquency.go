@@ -0,0 +1,78 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// DelinquencyBuckets lists the buckets of loans in arrears, least overdue first
+var DelinquencyBuckets = []string{
+	Delinquency1To29,
+	Delinquency30To59,
+	Delinquency60To89,
+	Delinquency90Plus,
+}
+
+// LoanDelinquency is an assessment of a loan's arrears against its repayment schedule
+type LoanDelinquency struct {
+	DaysPastDue         int        `json:"daysPastDue"` // Calendar days since the oldest unpaid installment fell due
+	Bucket              string     `json:"bucket"`
+	AmountOverdue       float64    `json:"amountOverdue"`
+	OverdueInstallments int        `json:"overdueInstallments"`
+	OldestDueDate       *time.Time `json:"oldestDueDate,omitempty"`
+	AssessedDate        time.Time  `json:"assessedDate"`
+	AssessedBy          string     `json:"assessedBy,omitempty"`
+}
+
+// LoanDelinquencyRequest represents a request to mark a disbursed loan delinquent
+type LoanDelinquencyRequest struct {
+	LoanID  string `json:"loanID"`
+	Notes   string `json:"notes,omitempty"`
+	ActorID string `json:"actorID"`
+}
+
+// LoanDefaultRequest represents a request to mark a loan in arrears defaulted
+type LoanDefaultRequest struct {
+	LoanID  string `json:"loanID"`
+	Notes   string `json:"notes,omitempty"`
+	ActorID string `json:"actorID"`
+}
+
+// LoanDefault records when and by whom a loan was marked defaulted, and the compliance event raised for it
+type LoanDefault struct {
+	DaysPastDue        int       `json:"daysPastDue"`
+	AmountOverdue      float64   `json:"amountOverdue"`
+	OutstandingBalance float64   `json:"outstandingBalance"`
+	PreviousStatus     string    `json:"previousStatus"`
+	Notes              string    `json:"notes,omitempty"`
+	DefaultedBy        string    `json:"defaultedBy"`
+	DefaultDate        time.Time `json:"defaultDate"`
+	ComplianceEventID  string    `json:"complianceEventID,omitempty"` // Empty for sandbox loans, which are not reported
+	CommissionClawedBack float64 `json:"commissionClawedBack,omitempty"` // Introducer commission clawed back for an early default
+}
+
+// DelinquentLoan is a loan in arrears as listed in the delinquent portfolio
+type DelinquentLoan struct {
+	LoanID             string                           `json:"loanID"`
+	CustomerID         string                           `json:"customerID"`
+	LoanType           string                           `json:"loanType"`
+	Status             validation.LoanApplicationStatus `json:"status"` // DISBURSED until the loan is marked delinquent
+	DaysPastDue        int                              `json:"daysPastDue"`
+	Bucket             string                           `json:"bucket"`
+	Currency           string                           `json:"currency"`
+	AmountOverdue      float64                          `json:"amountOverdue"`      // In Currency
+	OutstandingBalance float64                          `json:"outstandingBalance"` // In Currency
+}
+
+// DelinquentPortfolio is a page of the delinquent portfolio, optionally restricted to one bucket
+type DelinquentPortfolio struct {
+	Bucket              string               `json:"bucket,omitempty"`
+	AsOf                time.Time            `json:"asOf"`
+	Loans               []DelinquentLoan     `json:"loans"`
+	Count               int                  `json:"count"`
+	AmountsOverdue      utils.CurrencyTotals `json:"amountsOverdue"`      // Per loan currency
+	OutstandingBalances utils.CurrencyTotals `json:"outstandingBalances"` // Per loan currency
+	Bookmark            string               `json:"bookmark"`
+}
@@ -0,0 +1,58 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+)
+
+// LoanDisbursement represents a funding record for a full or tranche disbursement. Its amounts
+// other than the commission are in Currency, the loan's currency.
+type LoanDisbursement struct {
+	DisbursementID        string            `json:"disbursementID"`
+	LoanID                string            `json:"loanID"`
+	TrancheNumber         int               `json:"trancheNumber"`
+	Currency              string            `json:"currency,omitempty"`
+	Amount                utils.Money       `json:"amount"`
+	DisbursementDate      time.Time         `json:"disbursementDate"`
+	DestinationAccountRef string            `json:"destinationAccountRef"`
+	CounterpartyID        string            `json:"counterpartyID,omitempty"`
+	TransactionID         string            `json:"transactionID"` // Ledger transaction posted for this disbursement
+	DisbursedAmountAfter  utils.Money       `json:"disbursedAmountAfter"`
+	RemainingAmount       utils.Money       `json:"remainingAmount"`
+	DisbursedBy           string            `json:"disbursedBy"`
+	CommissionEntryID     string            `json:"commissionEntryID,omitempty"` // Introducer commission accrued on this tranche
+	CommissionAmount      float64           `json:"commissionAmount,omitempty"`
+	PayeeCountry          string            `json:"payeeCountry,omitempty"`
+	CrossBorder           *CrossBorderCheck `json:"crossBorder,omitempty"` // Corridor checks, when the payee country differs from the customer's residency
+}
+
+// UnmarshalJSON reads the disbursement's amounts in its own currency
+func (d *LoanDisbursement) UnmarshalJSON(data []byte) error {
+	var header struct {
+		Currency string `json:"currency"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return err
+	}
+
+	type loanDisbursementFields LoanDisbursement
+	zero := utils.ZeroMoney(header.Currency)
+	fields := loanDisbursementFields{Amount: zero, DisbursedAmountAfter: zero, RemainingAmount: zero}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	*d = LoanDisbursement(fields)
+	return nil
+}
+
+// LoanDisbursementRequest represents a request to disburse all or part of an approved loan
+type LoanDisbursementRequest struct {
+	LoanID                string  `json:"loanID"`
+	Amount                float64 `json:"amount"`
+	DestinationAccountRef string  `json:"destinationAccountRef"`
+	CounterpartyID        string  `json:"counterpartyID"`
+	PayeeCountry          string  `json:"payeeCountry"` // ISO 3166-1 alpha-2; defaults to the counterparty's jurisdiction
+	ActorID               string  `json:"actorID"`
+}
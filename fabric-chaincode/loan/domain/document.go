@@ -0,0 +1,48 @@
+package domain
+
+import (
+	"time"
+)
+
+// DocumentStatus represents the verification status of a loan document
+type DocumentStatus string
+
+const (
+	DocumentStatusPending  DocumentStatus = "PENDING"
+	DocumentStatusVerified DocumentStatus = "VERIFIED"
+	DocumentStatusRejected DocumentStatus = "REJECTED"
+)
+
+// LoanDocument represents a hash-anchored document supporting a loan application
+type LoanDocument struct {
+	DocumentID      string         `json:"documentID"`
+	LoanID          string         `json:"loanID"`
+	DocumentType    string         `json:"documentType"`
+	DocumentName    string         `json:"documentName"`
+	DocumentHash    string         `json:"documentHash"`
+	Status          DocumentStatus `json:"status"`
+	RejectionReason string         `json:"rejectionReason,omitempty"`
+	VerifiedDate    *time.Time     `json:"verifiedDate,omitempty"`
+	VerifiedBy      string         `json:"verifiedBy,omitempty"`
+	CreatedDate     time.Time      `json:"createdDate"`
+	LastUpdated     time.Time      `json:"lastUpdated"`
+	CreatedBy       string         `json:"createdBy"`
+	LastUpdatedBy   string         `json:"lastUpdatedBy"`
+}
+
+// DocumentUploadRequest represents a request to record a document against a loan
+type DocumentUploadRequest struct {
+	LoanID       string `json:"loanID"`
+	DocumentType string `json:"documentType"`
+	DocumentName string `json:"documentName"`
+	DocumentHash string `json:"documentHash"`
+	ActorID      string `json:"actorID"`
+}
+
+// DocumentVerificationRequest represents a reviewer's decision on an uploaded document
+type DocumentVerificationRequest struct {
+	DocumentID string         `json:"documentID"`
+	Status     DocumentStatus `json:"status"`
+	Reason     string         `json:"reason"`
+	ActorID    string         `json:"actorID"`
+}
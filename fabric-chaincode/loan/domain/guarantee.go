@@ -0,0 +1,99 @@
+package domain
+
+import (
+	"time"
+)
+
+// GuaranteeStatus represents the status of a loan guarantee
+type GuaranteeStatus string
+
+const (
+	GuaranteeStatusActive  GuaranteeStatus = "ACTIVE"
+	GuaranteeStatusInvoked GuaranteeStatus = "INVOKED"
+)
+
+// RecoveryObligationStatus represents the status of a guarantor's recovery obligation
+type RecoveryObligationStatus string
+
+const (
+	RecoveryObligationOpen    RecoveryObligationStatus = "OPEN"
+	RecoveryObligationSettled RecoveryObligationStatus = "SETTLED"
+)
+
+// LoanGuarantee represents a customer's guarantee of another customer's loan
+type LoanGuarantee struct {
+	GuaranteeID         string          `json:"guaranteeID"`
+	LoanID              string          `json:"loanID"`
+	BorrowerCustomerID  string          `json:"borrowerCustomerID"`
+	GuarantorCustomerID string          `json:"guarantorCustomerID"`
+	CoverageAmount      float64         `json:"coverageAmount"`
+	Status              GuaranteeStatus `json:"status"`
+	InvokedAmount       float64         `json:"invokedAmount"`
+	InvokedDate         *time.Time      `json:"invokedDate,omitempty"`
+	ObligationID        string          `json:"obligationID,omitempty"`
+	CreatedDate         time.Time       `json:"createdDate"`
+	LastUpdated         time.Time       `json:"lastUpdated"`
+	CreatedBy           string          `json:"createdBy"`
+	LastUpdatedBy       string          `json:"lastUpdatedBy"`
+}
+
+// RecoveryObligation represents the amount a guarantor owes after a guarantee is invoked
+type RecoveryObligation struct {
+	ObligationID        string                   `json:"obligationID"`
+	GuaranteeID         string                   `json:"guaranteeID"`
+	LoanID              string                   `json:"loanID"`
+	GuarantorCustomerID string                   `json:"guarantorCustomerID"`
+	Amount              float64                  `json:"amount"`
+	PaidAmount          float64                  `json:"paidAmount"`
+	OutstandingAmount   float64                  `json:"outstandingAmount"`
+	Status              RecoveryObligationStatus `json:"status"`
+	CreatedDate         time.Time                `json:"createdDate"`
+	LastUpdated         time.Time                `json:"lastUpdated"`
+	CreatedBy           string                   `json:"createdBy"`
+	LastUpdatedBy       string                   `json:"lastUpdatedBy"`
+}
+
+// GuarantorPayment represents a payment made by a guarantor against a recovery obligation
+type GuarantorPayment struct {
+	PaymentID        string    `json:"paymentID"`
+	ObligationID     string    `json:"obligationID"`
+	LoanID           string    `json:"loanID"`
+	Amount           float64   `json:"amount"`
+	Reference        string    `json:"reference"`
+	TransactionID    string    `json:"transactionID"` // Loan ledger repayment posted for this payment
+	OutstandingAfter float64   `json:"outstandingAfter"`
+	CreatedDate      time.Time `json:"createdDate"`
+	CreatedBy        string    `json:"createdBy"`
+}
+
+// CustomerExposure tracks a customer's guarantee-related exposure as borrower and guarantor
+type CustomerExposure struct {
+	CustomerID              string    `json:"customerID"`
+	ContingentLiability     float64   `json:"contingentLiability"`     // Uninvoked guarantees given
+	RecoveryObligations     float64   `json:"recoveryObligations"`     // Outstanding amounts owed as guarantor
+	GuaranteedBorrowing     float64   `json:"guaranteedBorrowing"`     // Uninvoked guarantee cover on own loans
+	RecoveredFromGuarantors float64   `json:"recoveredFromGuarantors"` // Own loan balances paid by guarantors
+	LastUpdated             time.Time `json:"lastUpdated"`
+}
+
+// LoanGuaranteeRequest represents a request to register a guarantee on a loan
+type LoanGuaranteeRequest struct {
+	LoanID              string  `json:"loanID"`
+	GuarantorCustomerID string  `json:"guarantorCustomerID"`
+	CoverageAmount      float64 `json:"coverageAmount"`
+	ActorID             string  `json:"actorID"`
+}
+
+// GuaranteeInvocationRequest represents a request to invoke a guarantee on a defaulted loan
+type GuaranteeInvocationRequest struct {
+	GuaranteeID string `json:"guaranteeID"`
+	ActorID     string `json:"actorID"`
+}
+
+// GuarantorPaymentRequest represents a guarantor payment against a recovery obligation
+type GuarantorPaymentRequest struct {
+	ObligationID string  `json:"obligationID"`
+	Amount       float64 `json:"amount"`
+	Reference    string  `json:"reference"`
+	ActorID      string  `json:"actorID"`
+}
@@ -0,0 +1,61 @@
+package domain
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+)
+
+// RateOracle represents a market-data source authorised to publish index rates and, where
+// OpenBankingFeed is listed in its indexes, Open Banking account summaries
+type RateOracle struct {
+	OracleID      string    `json:"oracleID"`
+	Name          string    `json:"name"`
+	PublicKey     string    `json:"publicKey"` // Base64-encoded ed25519 public key
+	Indexes       []string  `json:"indexes"`
+	IsActive      bool      `json:"isActive"`
+	CreatedDate   time.Time `json:"createdDate"`
+	CreatedBy     string    `json:"createdBy"`
+}
+
+// IndexRate represents a published reference rate fixing (e.g. SOFR, EURIBOR)
+type IndexRate struct {
+	IndexName       string    `json:"indexName"`
+	Rate            float64   `json:"rate"`
+	EffectiveDate   string    `json:"effectiveDate"` // YYYY-MM-DD
+	OracleID        string    `json:"oracleID"`
+	SourceSignature string    `json:"sourceSignature"`
+	PublishedDate   time.Time `json:"publishedDate"`
+	TransactionID   string    `json:"transactionID"`
+	IsStale         bool      `json:"isStale"`
+}
+
+// RateOracleRegistrationRequest represents a request to register a rate oracle
+type RateOracleRegistrationRequest struct {
+	OracleID  string   `json:"oracleID"`
+	Name      string   `json:"name"`
+	PublicKey string   `json:"publicKey"`
+	Indexes   []string `json:"indexes"`
+	ActorID   string   `json:"actorID"`
+}
+
+// IndexRatePublishRequest represents a signed index rate publication from an oracle
+type IndexRatePublishRequest struct {
+	OracleID        string  `json:"oracleID"`
+	IndexName       string  `json:"indexName"`
+	Rate            float64 `json:"rate"`
+	EffectiveDate   string  `json:"effectiveDate"`
+	SourceSignature string  `json:"sourceSignature"` // Base64-encoded signature over IndexRateSigningPayload
+}
+
+// LoanRepriceRequest represents a request to reprice a variable-rate loan from its index
+type LoanRepriceRequest struct {
+	LoanID          string `json:"loanID"`
+	ExpectedVersion *int   `json:"expectedVersion,omitempty"` // Rejected with CONFLICT unless the loan is still at this version
+	ActorID         string `json:"actorID"`
+}
+
+// IndexRateSigningPayload returns the canonical bytes an oracle signs for a rate publication
+func IndexRateSigningPayload(indexName string, rate float64, effectiveDate string) []byte {
+	return []byte(fmt.Sprintf("%s|%s|%s", indexName, strconv.FormatFloat(rate, 'f', -1, 64), effectiveDate))
+}
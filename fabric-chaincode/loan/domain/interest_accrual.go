@@ -0,0 +1,68 @@
+package domain
+
+import "time"
+
+// InterestAccrualRunStatus represents the progress of a daily interest accrual run
+type InterestAccrualRunStatus string
+
+const (
+	InterestAccrualInProgress InterestAccrualRunStatus = "IN_PROGRESS"
+	InterestAccrualCompleted  InterestAccrualRunStatus = "COMPLETED"
+)
+
+// InterestPosition is a loan's principal and interest position on a date, computed from its
+// repayment schedule. Interest on installments already due is owed in full; interest on the
+// current period accrues daily in proportion to the days elapsed.
+type InterestPosition struct {
+	PrincipalOutstanding float64    `json:"principalOutstanding"`
+	InterestDueUnpaid    float64    `json:"interestDueUnpaid"` // Scheduled interest of installments due by the date, not yet paid
+	InterestAccrued      float64    `json:"interestAccrued"`   // Interest earned in the current period, less any paid ahead
+	AccruedInterest      float64    `json:"accruedInterest"`   // InterestDueUnpaid plus InterestAccrued
+	PerDiemInterest      float64    `json:"perDiemInterest"`   // Interest accruing per day in the current period
+	PeriodStart          *time.Time `json:"periodStart,omitempty"`
+	PeriodEnd            *time.Time `json:"periodEnd,omitempty"` // Due date of the installment accruing; unset once every installment is due
+}
+
+// PayoffQuote is the amount that settles a loan in full on AsOfDate
+type PayoffQuote struct {
+	LoanID          string           `json:"loanID"`
+	AsOfDate        string           `json:"asOfDate"` // YYYY-MM-DD the quote is good through
+	Status          string           `json:"status"`
+	Position        InterestPosition `json:"position"`
+	FeesOutstanding float64          `json:"feesOutstanding"`
+	PayoffAmount    float64          `json:"payoffAmount"` // Principal, accrued interest and fees
+	QuotedDate      time.Time        `json:"quotedDate"`
+}
+
+// InterestAccrualRequest triggers the next batch of the interest accrual run for a date. The
+// scheduler repeats the call until the run comes back COMPLETED.
+type InterestAccrualRequest struct {
+	AccrualDate string `json:"accrualDate"`         // YYYY-MM-DD; interest is accrued through this day
+	BatchSize   int    `json:"batchSize,omitempty"` // Loans read per call; defaults to and is capped at config.MaxAccrualBatchSize
+	ActorID     string `json:"actorID"`
+}
+
+// InterestAccrual is the interest accrued on one loan by a run
+type InterestAccrual struct {
+	AccrualDate     string           `json:"accrualDate"`
+	LoanID          string           `json:"loanID"`
+	Position        InterestPosition `json:"position"`
+	PreviousAccrued float64          `json:"previousAccrued"` // Loan's accrued interest before the run
+	RecordedBy      string           `json:"recordedBy"`
+	RecordedDate    time.Time        `json:"recordedDate"`
+}
+
+// InterestAccrualRun is the on-ledger record of a daily accrual run. While IN_PROGRESS it carries
+// the checkpoint the next batch resumes from.
+type InterestAccrualRun struct {
+	AccrualDate          string                   `json:"accrualDate"`
+	Status               InterestAccrualRunStatus `json:"status"`
+	Checkpoint           string                   `json:"checkpoint,omitempty"` // Key of the last loan read; cleared on completion
+	LoansScanned         int                      `json:"loansScanned"`
+	LoansAccrued         int                      `json:"loansAccrued"`
+	TotalAccruedInterest float64                  `json:"totalAccruedInterest"`
+	Batches              int                      `json:"batches"`
+	StartedBy            string                   `json:"startedBy"`
+	StartedDate          time.Time                `json:"startedDate"`
+	CompletedDate        *time.Time               `json:"completedDate,omitempty"`
+}
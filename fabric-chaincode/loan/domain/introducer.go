@@ -0,0 +1,116 @@
+package domain
+
+import "time"
+
+// IntroducerStatus represents whether an introducer may earn commission
+type IntroducerStatus string
+
+const (
+	IntroducerStatusActive     IntroducerStatus = "ACTIVE"
+	IntroducerStatusSuspended  IntroducerStatus = "SUSPENDED"  // Loans already introduced keep their commission; new disbursements accrue none
+	IntroducerStatusTerminated IntroducerStatus = "TERMINATED" // Final
+)
+
+// Commission entry types
+const (
+	CommissionEntryAccrual  = "ACCRUAL"
+	CommissionEntryClawback = "CLAWBACK"
+)
+
+// Introducer is a broker that introduces loan applications in return for commission. The
+// introducer ID is the actor ID the broker submits applications under.
+type Introducer struct {
+	IntroducerID  string           `json:"introducerID"`
+	LegalName     string           `json:"legalName"`
+	ScheduleID    string           `json:"scheduleID"` // Commission schedule applied to disbursements of introduced loans
+	Status        IntroducerStatus `json:"status"`
+	StatusReason  string           `json:"statusReason,omitempty"`
+	CreatedDate   time.Time        `json:"createdDate"`
+	LastUpdated   time.Time        `json:"lastUpdated"`
+	CreatedBy     string           `json:"createdBy"`
+	LastUpdatedBy string           `json:"lastUpdatedBy"`
+	Version       int              `json:"version"` // Advanced on every write; status updates may name it as expectedVersion
+}
+
+// CommissionRate is the commission paid on disbursements of one loan type
+type CommissionRate struct {
+	LoanType    string  `json:"loanType"`
+	RatePercent float64 `json:"ratePercent"`          // Percent of each disbursed tranche
+	CapAmount   float64 `json:"capAmount,omitempty"` // Most commission one loan may earn; 0 means uncapped
+}
+
+// CommissionSchedule sets the commission introducers on it earn and the terms on which it is
+// clawed back when an introduced loan defaults early
+type CommissionSchedule struct {
+	ScheduleID           string           `json:"scheduleID"`
+	Rates                []CommissionRate `json:"rates"`
+	ClawbackWindowMonths int              `json:"clawbackWindowMonths"` // Defaults within this many months of first disbursement are clawed back; 0 disables clawback
+	ClawbackPercent      float64          `json:"clawbackPercent"`      // Percent of the loan's net commission clawed back
+	LastUpdated          time.Time        `json:"lastUpdated"`
+	LastUpdatedBy        string           `json:"lastUpdatedBy"`
+}
+
+// LoanCommission is the commission position of an introduced loan. The clawback terms are fixed
+// when commission first accrues so later schedule changes do not apply retroactively.
+type LoanCommission struct {
+	IntroducerID         string  `json:"introducerID"`
+	ScheduleID           string  `json:"scheduleID"`
+	Accrued              float64 `json:"accrued"`
+	ClawedBack           float64 `json:"clawedBack"`
+	ClawbackWindowMonths int     `json:"clawbackWindowMonths"`
+	ClawbackPercent      float64 `json:"clawbackPercent"`
+}
+
+// CommissionEntry is one accrual or clawback of commission against an introducer
+type CommissionEntry struct {
+	EntryID        string    `json:"entryID"`
+	IntroducerID   string    `json:"introducerID"`
+	Period         string    `json:"period"` // YYYY-MM the entry falls in
+	EntryType      string    `json:"entryType"`
+	LoanID         string    `json:"loanID"`
+	LoanType       string    `json:"loanType"`
+	ScheduleID     string    `json:"scheduleID"`
+	DisbursementID string    `json:"disbursementID,omitempty"` // Tranche an accrual was earned on
+	BaseAmount     float64   `json:"baseAmount"`               // Tranche amount, or net commission for a clawback
+	RatePercent    float64   `json:"ratePercent"`
+	Amount         float64   `json:"amount"` // Positive for accruals, negative for clawbacks
+	EntryDate      time.Time `json:"entryDate"`
+	RecordedBy     string    `json:"recordedBy"`
+}
+
+// CommissionStatement lists an introducer's commission entries for a range of periods
+type CommissionStatement struct {
+	IntroducerID    string            `json:"introducerID"`
+	FromPeriod      string            `json:"fromPeriod"`
+	ToPeriod        string            `json:"toPeriod"`
+	Entries         []CommissionEntry `json:"entries"`
+	TotalAccrued    float64           `json:"totalAccrued"`
+	TotalClawedBack float64           `json:"totalClawedBack"`
+	NetCommission   float64           `json:"netCommission"`
+}
+
+// IntroducerRegistrationRequest represents an introducer onboarding request
+type IntroducerRegistrationRequest struct {
+	IntroducerID string `json:"introducerID"`
+	LegalName    string `json:"legalName"`
+	ScheduleID   string `json:"scheduleID"`
+	ActorID      string `json:"actorID"`
+}
+
+// IntroducerStatusUpdateRequest suspends, reinstates or terminates an introducer
+type IntroducerStatusUpdateRequest struct {
+	IntroducerID    string           `json:"introducerID"`
+	NewStatus       IntroducerStatus `json:"newStatus"`
+	Reason          string           `json:"reason"`
+	ExpectedVersion *int             `json:"expectedVersion,omitempty"` // Rejected with CONFLICT unless the introducer is still at this version
+	ActorID         string           `json:"actorID"`
+}
+
+// CommissionScheduleRequest creates or replaces a commission schedule
+type CommissionScheduleRequest struct {
+	ScheduleID           string           `json:"scheduleID"`
+	Rates                []CommissionRate `json:"rates"`
+	ClawbackWindowMonths int              `json:"clawbackWindowMonths"`
+	ClawbackPercent      float64          `json:"clawbackPercent"`
+	ActorID              string           `json:"actorID"`
+}
@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"encoding/xml"
+)
+
+// ISO 20022 message types exchanged with core banking
+const (
+	MessageTypePain001 = "pain.001.001.09" // Customer credit transfer initiation
+	MessageTypeCamt054 = "camt.054.001.08" // Bank to customer debit/credit notification
+)
+
+// PaymentMessage wraps an ISO 20022 XML document for the gateway
+type PaymentMessage struct {
+	MessageType string `json:"messageType"`
+	MessageID   string `json:"messageID"`
+	Document    string `json:"document"` // XML
+}
+
+// The structs below cover the subset of pain.001 and camt.054 the loan chaincode produces and
+// consumes. Elements not listed are ignored when parsing.
+
+// ISOAmount is an amount with its currency; the value is kept as text so it round-trips exactly
+type ISOAmount struct {
+	Currency string `xml:"Ccy,attr"`
+	Value    string `xml:",chardata"`
+}
+
+// ISOAccount identifies an account by a proprietary reference
+type ISOAccount struct {
+	ID string `xml:"Id>Othr>Id"`
+}
+
+// ISOParty identifies a party by name and, optionally, a proprietary organisation ID
+type ISOParty struct {
+	Name  string `xml:"Nm,omitempty"`
+	OrgID string `xml:"Id>OrgId>Othr>Id,omitempty"`
+}
+
+// Pain001Document is a pain.001 customer credit transfer initiation
+type Pain001Document struct {
+	XMLName    xml.Name          `xml:"urn:iso:std:iso:20022:tech:xsd:pain.001.001.09 Document"`
+	Initiation Pain001Initiation `xml:"CstmrCdtTrfInitn"`
+}
+
+// Pain001Initiation holds the group header and payment instructions
+type Pain001Initiation struct {
+	GroupHeader        Pain001GroupHeader          `xml:"GrpHdr"`
+	PaymentInformation []Pain001PaymentInformation `xml:"PmtInf"`
+}
+
+// Pain001GroupHeader identifies the message
+type Pain001GroupHeader struct {
+	MessageID        string   `xml:"MsgId"`
+	CreationDateTime string   `xml:"CreDtTm"`
+	NumberOfTxs      int      `xml:"NbOfTxs"`
+	ControlSum       string   `xml:"CtrlSum"`
+	InitiatingParty  ISOParty `xml:"InitgPty"`
+}
+
+// Pain001PaymentInformation is a batch of credit transfers from one debtor account
+type Pain001PaymentInformation struct {
+	PaymentInfoID          string                  `xml:"PmtInfId"`
+	PaymentMethod          string                  `xml:"PmtMtd"`
+	RequestedExecutionDate string                  `xml:"ReqdExctnDt>Dt"`
+	Debtor                 ISOParty                `xml:"Dbtr"`
+	DebtorAccount          ISOAccount              `xml:"DbtrAcct"`
+	Transfers              []Pain001CreditTransfer `xml:"CdtTrfTxInf"`
+}
+
+// Pain001CreditTransfer is a single credit transfer
+type Pain001CreditTransfer struct {
+	InstructionID   string     `xml:"PmtId>InstrId,omitempty"`
+	EndToEndID      string     `xml:"PmtId>EndToEndId"`
+	Amount          ISOAmount  `xml:"Amt>InstdAmt"`
+	Creditor        ISOParty   `xml:"Cdtr"`
+	CreditorAccount ISOAccount `xml:"CdtrAcct"`
+	Remittance      string     `xml:"RmtInf>Ustrd,omitempty"`
+}
+
+// Camt054Document is a camt.054 bank to customer debit/credit notification
+type Camt054Document struct {
+	XMLName      xml.Name            `xml:"urn:iso:std:iso:20022:tech:xsd:camt.054.001.08 Document"`
+	Notification Camt054Notification `xml:"BkToCstmrDbtCdtNtfctn"`
+}
+
+// Camt054Notification holds the group header and account notifications
+type Camt054Notification struct {
+	GroupHeader   Camt054GroupHeader           `xml:"GrpHdr"`
+	Notifications []Camt054AccountNotification `xml:"Ntfctn"`
+}
+
+// Camt054GroupHeader identifies the message
+type Camt054GroupHeader struct {
+	MessageID        string `xml:"MsgId"`
+	CreationDateTime string `xml:"CreDtTm"`
+}
+
+// Camt054AccountNotification lists the entries booked to one account
+type Camt054AccountNotification struct {
+	ID               string         `xml:"Id"`
+	CreationDateTime string         `xml:"CreDtTm"`
+	Account          ISOAccount     `xml:"Acct"`
+	Entries          []Camt054Entry `xml:"Ntry"`
+}
+
+// Camt054Entry is a booked debit or credit
+type Camt054Entry struct {
+	EntryReference    string                     `xml:"NtryRef,omitempty"`
+	Amount            ISOAmount                  `xml:"Amt"`
+	CreditDebit       string                     `xml:"CdtDbtInd"` // CRDT or DBIT
+	Status            string                     `xml:"Sts>Cd"`    // BOOK, PDNG or INFO
+	BookingDate       string                     `xml:"BookgDt>Dt"`
+	ServicerReference string                     `xml:"AcctSvcrRef,omitempty"`
+	Details           []Camt054TransactionDetail `xml:"NtryDtls>TxDtls"`
+}
+
+// Camt054TransactionDetail describes one transaction within an entry
+type Camt054TransactionDetail struct {
+	EndToEndID string    `xml:"Refs>EndToEndId,omitempty"`
+	Amount     ISOAmount `xml:"Amt"`
+	Debtor     ISOParty  `xml:"RltdPties>Dbtr>Pty"`
+	Remittance string    `xml:"RmtInf>Ustrd,omitempty"`
+}
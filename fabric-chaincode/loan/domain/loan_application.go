@@ -1,8 +1,11 @@
 package domain
 
 import (
+	"encoding/json"
 	"time"
 	
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/interfaces"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
 	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
 )
 
@@ -10,24 +13,80 @@ import (
 type LoanApplication struct {
 	LoanID              string                            `json:"loanID"`
 	CustomerID          string                            `json:"customerID"`
+	Parties             []LoanParty                       `json:"parties,omitempty"` // Primary borrower first, then co-borrowers and guarantors
 	LoanType            string                            `json:"loanType"`
-	RequestedAmount     float64                           `json:"requestedAmount"`
-	ApprovedAmount      *float64                          `json:"approvedAmount,omitempty"`
+	Currency            string                            `json:"currency,omitempty"` // ISO 4217; empty on applications made before currencies were recorded, which are in the base currency
+	RequestedAmount     utils.Money                       `json:"requestedAmount"` // In Currency
+	ApprovedAmount      *utils.Money                      `json:"approvedAmount,omitempty"` // In Currency
+	Conversion          *CurrencyConversion               `json:"conversion,omitempty"` // FX fixing the approved amount was checked against the base-currency limits at
 	InterestRate        *float64                          `json:"interestRate,omitempty"`
+	RateIndex           string                            `json:"rateIndex,omitempty"`
+	RateMargin          *float64                          `json:"rateMargin,omitempty"`
+	IndexRateDate       string                            `json:"indexRateDate,omitempty"`
+	RatePolicyVersion   int                               `json:"ratePolicyVersion,omitempty"` // Version of the loan type's interest rate policy the approved rate was held to
 	TermMonths          int                               `json:"termMonths"`
 	Purpose             string                            `json:"purpose"`
 	Status              validation.LoanApplicationStatus `json:"status"`
 	ApplicationDate     time.Time                         `json:"applicationDate"`
 	DecisionDate        *time.Time                        `json:"decisionDate,omitempty"`
 	DisbursementDate    *time.Time                        `json:"disbursementDate,omitempty"`
+	DisbursedAmount     utils.Money                       `json:"disbursedAmount"` // In Currency
 	UnderwriterID       string                            `json:"underwriterID,omitempty"`
 	CreditOfficerID     string                            `json:"creditOfficerID,omitempty"`
+	OwnerActorID        string                            `json:"ownerActorID,omitempty"` // Staff member responsible for the loan; the submitting actor unless reassigned
+	IntroducerID        string                            `json:"introducerID,omitempty"` // Broker that introduced the application
+	Channel             validation.OriginationChannel     `json:"channel,omitempty"`    // Channel the application was submitted through
+	DeviceHash          string                            `json:"deviceHash,omitempty"` // SHA-256 of the submitting device's fingerprint
+	Commission          *LoanCommission                   `json:"commission,omitempty"`   // Set once commission accrues to the introducer
+	ComplianceHold      *ComplianceHold                   `json:"complianceHold,omitempty"` // While set the loan may not be approved, disbursed or change status
+	Cancellation        *LoanCancellation                 `json:"cancellation,omitempty"`   // Set when the application is cancelled
+	PurposeScreening    *interfaces.TextScreeningResult   `json:"purposeScreening,omitempty"` // Set when the purpose matched screening terms
+	ChannelScreening    *interfaces.ApplicationChannelResult `json:"channelScreening,omitempty"` // Set when the channel fraud rules raised alerts
+	Delinquency         *LoanDelinquency                  `json:"delinquency,omitempty"`    // Arrears when last marked delinquent or defaulted; cleared when cured
+	Default             *LoanDefault                      `json:"default,omitempty"`        // Set when the loan is marked defaulted
+	Eligibility         *LoanEligibility                  `json:"eligibility,omitempty"`    // Latest re-validation of the parties' eligibility
 	RiskScore           *float64                          `json:"riskScore,omitempty"`
-	Notes               string                            `json:"notes"`
+	AutoApproved        bool                              `json:"autoApproved,omitempty"` // Approved by straight-through processing without human action
+	LoanToValue         *float64                          `json:"loanToValue,omitempty"`
+	AmountExceptionID   string                            `json:"amountExceptionID,omitempty"` // Exceptions register entry the amount relies on
+	OutstandingBalance  utils.Money                       `json:"outstandingBalance"` // In Currency
+	AccruedInterest     utils.Money                       `json:"accruedInterest"`                  // In Currency; interest earned and unpaid as of InterestAccruedThrough
+	InterestAccruedThrough string                         `json:"interestAccruedThrough,omitempty"` // YYYY-MM-DD of the latest AccrueInterest run covering the loan
+	Jurisdiction        string                            `json:"jurisdiction,omitempty"` // Tax jurisdiction, ISO 3166-1 alpha-2
+	TaxWithheld         utils.Money                       `json:"taxWithheld"`            // In Currency; running total withheld on interest and fees
+	ServicerMSP         string                            `json:"servicerMSP,omitempty"`  // Organization servicing the loan; empty means the originating bank
+	Sandbox             bool                              `json:"sandbox,omitempty"` // Created by a sandbox actor; excluded from production reporting
+	Notes               string                            `json:"notes"` // Approval or rejection reason; other commentary is kept as entity notes
 	CreatedDate         time.Time                         `json:"createdDate"`
 	LastUpdated         time.Time                         `json:"lastUpdated"`
 	CreatedBy           string                            `json:"createdBy"`
 	LastUpdatedBy       string                            `json:"lastUpdatedBy"`
+	Version             int                               `json:"version"` // Advanced on every write; updates may name it as expectedVersion
+}
+
+// UnmarshalJSON reads the application's amounts in its own currency, so currencies with other
+// than two minor units load exactly
+func (l *LoanApplication) UnmarshalJSON(data []byte) error {
+	var header struct {
+		Currency       string          `json:"currency"`
+		ApprovedAmount json.RawMessage `json:"approvedAmount"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return err
+	}
+
+	type loanApplicationFields LoanApplication
+	zero := utils.ZeroMoney(header.Currency)
+	fields := loanApplicationFields{RequestedAmount: zero, DisbursedAmount: zero, OutstandingBalance: zero, AccruedInterest: zero, TaxWithheld: zero}
+	if len(header.ApprovedAmount) > 0 {
+		approved := utils.ZeroMoney(header.Currency)
+		fields.ApprovedAmount = &approved
+	}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	*l = LoanApplication(fields)
+	return nil
 }
 
 // LoanApplicationRequest represents a loan application submission request
@@ -35,16 +94,66 @@ type LoanApplicationRequest struct {
 	CustomerID      string  `json:"customerID"`
 	LoanType        string  `json:"loanType"`
 	RequestedAmount float64 `json:"requestedAmount"`
+	Currency        string  `json:"currency,omitempty"` // ISO 4217; defaults to the base currency
 	TermMonths      int     `json:"termMonths"`
 	Purpose         string  `json:"purpose"`
+	Parties         []LoanPartyRequest `json:"parties,omitempty"` // Co-borrowers and guarantors
+	Jurisdiction    string  `json:"jurisdiction,omitempty"` // Tax jurisdiction for interest and fee withholding
+	IntroducerID    string  `json:"introducerID,omitempty"` // Broker introducing an application staff submit; introducers submitting directly are recorded automatically
+	IdempotencyKey  string  `json:"idempotencyKey,omitempty"` // Retries carrying the same key return the original application
+	Channel         string  `json:"channel"` // BRANCH, MOBILE, WEB or BROKER_API
+	DeviceHash      string  `json:"deviceHash,omitempty"` // Hex SHA-256 of the device fingerprint; clients hash before submitting
+	AmountExceptionID string `json:"amountExceptionID,omitempty"` // Approved exception lifting the customer's loan amount bound
 	ActorID         string  `json:"actorID"`
 }
 
+// LoanParty represents a customer's role and liability on a loan application
+type LoanParty struct {
+	CustomerID     string                   `json:"customerID"`
+	Role           validation.LoanPartyRole `json:"role"`
+	LiabilityShare float64                  `json:"liabilityShare"` // Percent of the loan the party is liable for
+	ScreenedDate   *time.Time               `json:"screenedDate,omitempty"`
+}
+
+// LoanPartyRequest represents a co-borrower or guarantor named on a loan application
+type LoanPartyRequest struct {
+	CustomerID     string  `json:"customerID"`
+	Role           string  `json:"role"`
+	LiabilityShare float64 `json:"liabilityShare"`
+}
+
+// PartyLoan represents a loan application a customer participates in under a given role
+type PartyLoan struct {
+	LoanID          string                            `json:"loanID"`
+	CustomerID      string                            `json:"customerID"`
+	Role            validation.LoanPartyRole         `json:"role"`
+	LiabilityShare  float64                           `json:"liabilityShare"`
+	LoanType        string                            `json:"loanType"`
+	RequestedAmount float64                           `json:"requestedAmount"`
+	Status          validation.LoanApplicationStatus `json:"status"`
+}
+
+// LoanQueryResult is one page of a loan application listing
+type LoanQueryResult struct {
+	Loans    []LoanApplication    `json:"loans"`
+	Count    int                  `json:"count"`
+	Bookmark string               `json:"bookmark"`
+	Claims   map[string]LoanClaim `json:"claims,omitempty"` // Live claims on listed loans by loan ID, in work queue listings
+}
+
+// LoanClaim shows who is working a loan in a work queue listing
+type LoanClaim struct {
+	HolderID    string    `json:"holderID"`
+	ClaimedDate time.Time `json:"claimedDate"`
+	ExpiresDate time.Time `json:"expiresDate"`
+}
+
 // LoanStatusUpdateRequest represents a loan status update request
 type LoanStatusUpdateRequest struct {
 	LoanID    string                            `json:"loanID"`
 	NewStatus validation.LoanApplicationStatus `json:"newStatus"`
 	Notes     string                            `json:"notes"`
+	ExpectedVersion *int                        `json:"expectedVersion,omitempty"` // Rejected with CONFLICT unless the loan is still at this version
 	ActorID   string                            `json:"actorID"`
 }
 
@@ -53,14 +162,19 @@ type LoanApprovalRequest struct {
 	LoanID         string  `json:"loanID"`
 	ApprovedAmount float64 `json:"approvedAmount"`
 	InterestRate   float64 `json:"interestRate"`
+	RateIndex      string  `json:"rateIndex,omitempty"`  // Variable-rate loans are priced at index + margin
+	RateMargin     float64 `json:"rateMargin,omitempty"`
 	RiskScore      float64 `json:"riskScore"`
 	Notes          string  `json:"notes"`
+	AmountExceptionID string `json:"amountExceptionID,omitempty"` // Defaults to the exception the application was submitted under
+	ExpectedVersion *int   `json:"expectedVersion,omitempty"` // Rejected with CONFLICT unless the loan is still at this version
 	ActorID        string  `json:"actorID"`
 }
 
 // LoanRejectionRequest represents a loan rejection request
 type LoanRejectionRequest struct {
-	LoanID  string `json:"loanID"`
-	Reason  string `json:"reason"`
-	ActorID string `json:"actorID"`
+	LoanID          string `json:"loanID"`
+	Reason          string `json:"reason"`
+	ExpectedVersion *int   `json:"expectedVersion,omitempty"` // Rejected with CONFLICT unless the loan is still at this version
+	ActorID         string `json:"actorID"`
 }
\ No newline at end of file
@@ -0,0 +1,139 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+)
+
+// LoanTransactionType represents the kind of money movement posted against a loan
+type LoanTransactionType string
+
+const (
+	LoanTransactionDisbursement LoanTransactionType = "DISBURSEMENT"
+	LoanTransactionRepayment    LoanTransactionType = "REPAYMENT"
+	LoanTransactionFee          LoanTransactionType = "FEE"
+	LoanTransactionInterest     LoanTransactionType = "INTEREST" // Scheduled interest charge
+)
+
+// ReconciliationStatus represents the outcome of a loan balance reconciliation
+type ReconciliationStatus string
+
+const (
+	ReconciliationBalanced      ReconciliationStatus = "BALANCED"
+	ReconciliationDiscrepancy   ReconciliationStatus = "DISCREPANCY"
+	ReconciliationRepairPending ReconciliationStatus = "REPAIR_PENDING"
+	ReconciliationRepaired      ReconciliationStatus = "REPAIRED"
+)
+
+// LoanTransaction represents a disbursement, repayment, fee or scheduled interest charge. Its
+// amounts are in Currency, the loan's currency; transactions posted before they carried a currency
+// are in the base currency.
+type LoanTransaction struct {
+	TransactionID   string              `json:"transactionID"`
+	LoanID          string              `json:"loanID"`
+	TransactionType LoanTransactionType `json:"transactionType"`
+	Currency        string              `json:"currency,omitempty"`
+	Amount          utils.Money         `json:"amount"`
+	Reference       string              `json:"reference"`
+	CounterpartyID  string              `json:"counterpartyID,omitempty"`
+	BalanceAfter    utils.Money         `json:"balanceAfter"`
+	TaxWithheld     utils.Money         `json:"taxWithheld"` // Withholding on interest and fee income
+	CreatedDate     time.Time           `json:"createdDate"`
+	Sequence        int                 `json:"sequence,omitempty"` // Order among the transactions posted in the same ledger transaction
+	CreatedBy       string              `json:"createdBy"`
+}
+
+// UnmarshalJSON reads the transaction's amounts in its own currency, so currencies with other than
+// two minor units load exactly
+func (t *LoanTransaction) UnmarshalJSON(data []byte) error {
+	var header struct {
+		Currency string `json:"currency"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return err
+	}
+
+	type loanTransactionFields LoanTransaction
+	zero := utils.ZeroMoney(header.Currency)
+	fields := loanTransactionFields{Amount: zero, BalanceAfter: zero, TaxWithheld: zero}
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	*t = LoanTransaction(fields)
+	return nil
+}
+
+// LoanReconciliation records a comparison between the stored and the recomputed loan balance. Its
+// amounts are in Currency, the loan's currency.
+type LoanReconciliation struct {
+	ReconciliationID    string               `json:"reconciliationID"`
+	LoanID              string               `json:"loanID"`
+	Currency            string               `json:"currency,omitempty"`
+	StoredBalance       utils.Money          `json:"storedBalance"`
+	ExpectedBalance     utils.Money          `json:"expectedBalance"`
+	Discrepancy         utils.Money          `json:"discrepancy"`
+	TotalDisbursed      utils.Money          `json:"totalDisbursed"`
+	TotalRepaid         utils.Money          `json:"totalRepaid"`
+	TotalFees           utils.Money          `json:"totalFees"`
+	TotalInterest       utils.Money          `json:"totalInterest"`
+	TransactionCount    int                  `json:"transactionCount"`
+	Status              ReconciliationStatus `json:"status"`
+	ReconciledBy        string               `json:"reconciledBy"`
+	ReconciledDate      time.Time            `json:"reconciledDate"`
+	RepairRequestedBy   string               `json:"repairRequestedBy,omitempty"`
+	RepairReason        string               `json:"repairReason,omitempty"`
+	RepairRequestedDate *time.Time           `json:"repairRequestedDate,omitempty"`
+	RepairApprovedBy    string               `json:"repairApprovedBy,omitempty"`
+	RepairApprovedDate  *time.Time           `json:"repairApprovedDate,omitempty"`
+}
+
+// UnmarshalJSON reads the reconciliation's amounts in its own currency
+func (r *LoanReconciliation) UnmarshalJSON(data []byte) error {
+	var header struct {
+		Currency string `json:"currency"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return err
+	}
+
+	type loanReconciliationFields LoanReconciliation
+	zero := utils.ZeroMoney(header.Currency)
+	fields := loanReconciliationFields{StoredBalance: zero, ExpectedBalance: zero, Discrepancy: zero}
+	fields.TotalDisbursed, fields.TotalRepaid, fields.TotalFees, fields.TotalInterest = zero, zero, zero, zero
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	*r = LoanReconciliation(fields)
+	return nil
+}
+
+// LoanTransactionRequest represents a request to post a transaction against a loan
+type LoanTransactionRequest struct {
+	LoanID          string              `json:"loanID"`
+	TransactionType LoanTransactionType `json:"transactionType"`
+	Amount          float64             `json:"amount"`
+	Reference       string              `json:"reference"`
+	CounterpartyID  string              `json:"counterpartyID"`
+	ActorID         string              `json:"actorID"`
+}
+
+// ReconcileLoanRequest represents a loan balance reconciliation request
+type ReconcileLoanRequest struct {
+	LoanID  string `json:"loanID"`
+	ActorID string `json:"actorID"`
+}
+
+// BalanceRepairRequest represents a request to repair a reconciled balance discrepancy
+type BalanceRepairRequest struct {
+	ReconciliationID string `json:"reconciliationID"`
+	Reason           string `json:"reason"`
+	ActorID          string `json:"actorID"`
+}
+
+// BalanceRepairApprovalRequest represents an approver's sign-off on a balance repair
+type BalanceRepairApprovalRequest struct {
+	ReconciliationID string `json:"reconciliationID"`
+	ActorID          string `json:"actorID"`
+}
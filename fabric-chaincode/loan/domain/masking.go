@@ -0,0 +1,46 @@
+package domain
+
+import (
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/masking"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// DisbursementMaskingPolicies masks disbursement destination accounts for every role except those
+// that settle or oversee disbursements
+var DisbursementMaskingPolicies = masking.NewPolicySet(masking.Policy{
+	"destinationAccountRef": {Strategy: masking.StrategyAccountNumber, Reveal: 4},
+}, map[validation.ActorRole]masking.Policy{
+	validation.ActorRoleLoanOperationsManager:  {},
+	validation.ActorRoleComplianceOfficer:      {},
+	validation.ActorRoleChiefComplianceOfficer: {},
+	validation.ActorRoleRegulator:              {},
+})
+
+// LoanHistoryView selects how much of a loan's history a role may read
+type LoanHistoryView string
+
+const (
+	LoanHistoryViewFull       LoanHistoryView = "FULL"       // Every field-level change, including pricing and decision detail
+	LoanHistoryViewMilestones LoanHistoryView = "MILESTONES" // Status changes only
+)
+
+// LoanHistoryViews gives underwriting, credit and oversight roles the full loan history. Introducers,
+// customer-facing staff, other roles and identities whose role cannot be read see status
+// milestones only.
+var LoanHistoryViews = map[validation.ActorRole]LoanHistoryView{
+	validation.ActorRoleUnderwriter:            LoanHistoryViewFull,
+	validation.ActorRoleCreditOfficer:          LoanHistoryViewFull,
+	validation.ActorRoleRiskAnalyst:            LoanHistoryViewFull,
+	validation.ActorRoleLoanOperationsManager:  LoanHistoryViewFull,
+	validation.ActorRoleComplianceOfficer:      LoanHistoryViewFull,
+	validation.ActorRoleChiefComplianceOfficer: LoanHistoryViewFull,
+	validation.ActorRoleRegulator:              LoanHistoryViewFull,
+}
+
+// LoanHistoryViewForRole returns the loan history view for an actor role
+func LoanHistoryViewForRole(role string) LoanHistoryView {
+	if view, ok := LoanHistoryViews[validation.ActorRole(role)]; ok {
+		return view
+	}
+	return LoanHistoryViewMilestones
+}
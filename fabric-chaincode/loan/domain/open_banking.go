@@ -0,0 +1,144 @@
+package domain
+
+import (
+	"fmt"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Open Banking authorization statuses
+const (
+	OpenBankingAuthorizationActive  = "ACTIVE"
+	OpenBankingAuthorizationRevoked = "REVOKED"
+)
+
+// Directions of categorized transaction flows
+const (
+	TransactionFlowInflow  = "INFLOW"
+	TransactionFlowOutflow = "OUTFLOW"
+)
+
+// OpenBankingFeed is the feed name a registered oracle must be authorised for to publish account
+// transaction summaries
+const OpenBankingFeed = "OPEN_BANKING"
+
+// OpenBankingAuthorization records a customer's consent for the bank to access account
+// information held by a provider. Summaries are only accepted, and only used in affordability
+// assessments, while the authorization is active and unexpired.
+type OpenBankingAuthorization struct {
+	AuthorizationID  string     `json:"authorizationID"`
+	CustomerID       string     `json:"customerID"`
+	ProviderID       string     `json:"providerID"` // Oracle relaying the provider's account data
+	AccountRefs      []string   `json:"accountRefs"`
+	ConsentReference string     `json:"consentReference"` // Consent ID issued by the provider
+	Status           string     `json:"status"`
+	GrantedDate      time.Time  `json:"grantedDate"`
+	ExpiryDate       time.Time  `json:"expiryDate"`
+	RevokedDate      *time.Time `json:"revokedDate,omitempty"`
+	CreatedBy        string     `json:"createdBy"`
+	LastUpdatedBy    string     `json:"lastUpdatedBy"`
+}
+
+// IsEffective reports whether the authorization permits account access at the given time
+func (a *OpenBankingAuthorization) IsEffective(at time.Time) bool {
+	return a.Status == OpenBankingAuthorizationActive && at.Before(a.ExpiryDate)
+}
+
+// OpenBankingAuthorizationRequest represents a customer's grant of account-information access
+type OpenBankingAuthorizationRequest struct {
+	CustomerID       string    `json:"customerID"`
+	ProviderID       string    `json:"providerID"`
+	AccountRefs      []string  `json:"accountRefs"`
+	ConsentReference string    `json:"consentReference"`
+	ExpiryDate       time.Time `json:"expiryDate"`
+	ActorID          string    `json:"actorID"`
+}
+
+// OpenBankingRevocationRequest represents a request to withdraw an authorization
+type OpenBankingRevocationRequest struct {
+	AuthorizationID string `json:"authorizationID"`
+	ActorID         string `json:"actorID"`
+}
+
+// TransactionCategorySummary is the monthly average of one category of account transactions
+type TransactionCategorySummary struct {
+	Category       string  `json:"category"`  // e.g. SALARY, RENT, UTILITIES
+	Direction      string  `json:"direction"` // INFLOW or OUTFLOW
+	MonthlyAverage float64 `json:"monthlyAverage"`
+}
+
+// AccountTransactionSummary is a categorized summary of an account's transactions over a period,
+// published by an Open Banking oracle under a customer's authorization
+type AccountTransactionSummary struct {
+	CustomerID      string                       `json:"customerID"`
+	AuthorizationID string                       `json:"authorizationID"`
+	ProviderID      string                       `json:"providerID"`
+	AccountRef      string                       `json:"accountRef"`
+	PeriodStart     string                       `json:"periodStart"` // YYYY-MM-DD
+	PeriodEnd       string                       `json:"periodEnd"`   // YYYY-MM-DD
+	Categories      []TransactionCategorySummary `json:"categories"`
+	SourceSignature string                       `json:"sourceSignature"`
+	IngestedDate    time.Time                    `json:"ingestedDate"`
+	TransactionID   string                       `json:"transactionID"`
+}
+
+// AccountSummaryIngestRequest represents a signed account transaction summary from an oracle
+type AccountSummaryIngestRequest struct {
+	AuthorizationID string                       `json:"authorizationID"`
+	ProviderID      string                       `json:"providerID"`
+	AccountRef      string                       `json:"accountRef"`
+	PeriodStart     string                       `json:"periodStart"`
+	PeriodEnd       string                       `json:"periodEnd"`
+	Categories      []TransactionCategorySummary `json:"categories"`
+	SourceSignature string                       `json:"sourceSignature"` // Base64-encoded signature over AccountSummarySigningPayload
+}
+
+// AffordabilityAssessmentRequest represents a request to assess a loan's affordability from the
+// borrower's Open Banking data. InterestRate is required when the loan has not been priced.
+type AffordabilityAssessmentRequest struct {
+	LoanID       string   `json:"loanID"`
+	InterestRate *float64 `json:"interestRate,omitempty"`
+	ActorID      string   `json:"actorID"`
+}
+
+// AffordabilitySource records the provenance of a summary used in an affordability assessment
+type AffordabilitySource struct {
+	AuthorizationID     string    `json:"authorizationID"`
+	ProviderID          string    `json:"providerID"`
+	AccountRef          string    `json:"accountRef"`
+	PeriodStart         string    `json:"periodStart"`
+	PeriodEnd           string    `json:"periodEnd"`
+	TransactionID       string    `json:"transactionID"` // Transaction that ingested the summary
+	AuthorizationExpiry time.Time `json:"authorizationExpiry"`
+}
+
+// AffordabilityAssessment compares a borrower's observed income and outgoings with the proposed
+// loan payment
+type AffordabilityAssessment struct {
+	AssessmentID     string                `json:"assessmentID"`
+	LoanID           string                `json:"loanID"`
+	CustomerID       string                `json:"customerID"`
+	MonthlyIncome    float64               `json:"monthlyIncome"`
+	MonthlyOutgoings float64               `json:"monthlyOutgoings"`
+	ProposedPayment  float64               `json:"proposedPayment"`
+	DisposableIncome float64               `json:"disposableIncome"` // Income left after outgoings and the proposed payment
+	DebtServiceRatio float64               `json:"debtServiceRatio"` // (Outgoings + proposed payment) / income
+	Affordable       bool                  `json:"affordable"`
+	Sources          []AffordabilitySource `json:"sources"`
+	AssessedDate     time.Time             `json:"assessedDate"`
+	AssessedBy       string                `json:"assessedBy"`
+}
+
+// AccountSummarySigningPayload returns the canonical bytes an oracle signs for an account summary.
+// Categories are sorted so the payload does not depend on their order.
+func AccountSummarySigningPayload(authorizationID, accountRef, periodStart, periodEnd string, categories []TransactionCategorySummary) []byte {
+	parts := make([]string, 0, len(categories))
+	for _, category := range categories {
+		parts = append(parts, fmt.Sprintf("%s:%s:%s", category.Category, category.Direction, strconv.FormatFloat(category.MonthlyAverage, 'f', -1, 64)))
+	}
+	sort.Strings(parts)
+
+	return []byte(fmt.Sprintf("%s|%s|%s|%s|%s", authorizationID, accountRef, periodStart, periodEnd, strings.Join(parts, ",")))
+}
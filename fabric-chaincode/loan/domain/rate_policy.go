@@ -0,0 +1,42 @@
+package domain
+
+import "time"
+
+// InterestRatePolicy bounds the interest rates loans of a type may be approved at. The lowest
+// rate a customer may be offered is the policy's base rate plus its spread and the adjustment for
+// the customer's risk tier, but never below FloorRate; no loan may be priced above CapRate.
+// Replacing a policy increments its Version and every version is kept, so an approval can be
+// traced to the policy it was held to. Loan types without a policy are not rate checked.
+type InterestRatePolicy struct {
+	LoanType            string             `json:"loanType"`
+	Version             int                `json:"version"`
+	FloorRate           float64            `json:"floorRate"`
+	CapRate             float64            `json:"capRate"`
+	BaseRateIndex       string             `json:"baseRateIndex,omitempty"` // Index whose current fixing is the base rate; without one the base rate is 0
+	BaseRateSpread      float64            `json:"baseRateSpread"`
+	RiskTierAdjustments map[string]float64 `json:"riskTierAdjustments"` // Added to the minimum rate by customer risk tier, including LoanAmountTierUnrated; unlisted tiers are not adjusted
+	LastUpdated         time.Time          `json:"lastUpdated"`
+	LastUpdatedBy       string             `json:"lastUpdatedBy"`
+}
+
+// InterestRatePolicyRequest represents a request to replace a loan type's interest rate policy
+type InterestRatePolicyRequest struct {
+	LoanType            string             `json:"loanType"`
+	FloorRate           float64            `json:"floorRate"`
+	CapRate             float64            `json:"capRate"`
+	BaseRateIndex       string             `json:"baseRateIndex,omitempty"`
+	BaseRateSpread      float64            `json:"baseRateSpread"`
+	RiskTierAdjustments map[string]float64 `json:"riskTierAdjustments"`
+	ActorID             string             `json:"actorID"`
+}
+
+// InterestRateRange is the range of rates a policy allows for one customer risk tier, priced off
+// the base rate fixing current when it was worked out
+type InterestRateRange struct {
+	LoanType      string  `json:"loanType"`
+	PolicyVersion int     `json:"policyVersion"`
+	RiskTier      string  `json:"riskTier"`
+	BaseRate      float64 `json:"baseRate"`
+	MinRate       float64 `json:"minRate"`
+	MaxRate       float64 `json:"maxRate"`
+}
@@ -0,0 +1,97 @@
+package domain
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+)
+
+// InstallmentStatus represents the payment status of a scheduled installment
+type InstallmentStatus string
+
+const (
+	InstallmentStatusPending InstallmentStatus = "PENDING"
+	InstallmentStatusPartial InstallmentStatus = "PARTIAL"
+	InstallmentStatusPaid    InstallmentStatus = "PAID"
+)
+
+// Installment represents a single amortization schedule entry. Its amounts are all in Currency,
+// the loan's currency; installments stored before loans carried a currency are in the base currency.
+type Installment struct {
+	LoanID            string            `json:"loanID"`
+	InstallmentNumber int               `json:"installmentNumber"`
+	DueDate           time.Time         `json:"dueDate"`
+	Currency          string            `json:"currency,omitempty"`
+	PrincipalDue      utils.Money       `json:"principalDue"`
+	InterestDue       utils.Money       `json:"interestDue"`
+	TotalDue          utils.Money       `json:"totalDue"`
+	PrincipalPaid     utils.Money       `json:"principalPaid"`
+	InterestPaid      utils.Money       `json:"interestPaid"`
+	ClosingPrincipal  utils.Money       `json:"closingPrincipal"` // Scheduled principal remaining after this installment
+	Status            InstallmentStatus `json:"status"`
+	PaidDate          *time.Time        `json:"paidDate,omitempty"`
+}
+
+// UnmarshalJSON reads the installment's amounts in its own currency, so currencies with other
+// than two minor units load exactly
+func (i *Installment) UnmarshalJSON(data []byte) error {
+	var header struct {
+		Currency string `json:"currency"`
+	}
+	if err := json.Unmarshal(data, &header); err != nil {
+		return err
+	}
+
+	type installmentFields Installment
+	fields := installmentFields{Currency: header.Currency}
+	zero := utils.ZeroMoney(header.Currency)
+	fields.PrincipalDue, fields.InterestDue, fields.TotalDue = zero, zero, zero
+	fields.PrincipalPaid, fields.InterestPaid, fields.ClosingPrincipal = zero, zero, zero
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return err
+	}
+	*i = Installment(fields)
+	return nil
+}
+
+// PrincipalOutstanding returns the installment's unpaid principal
+func (i *Installment) PrincipalOutstanding() utils.Money {
+	return utils.MoneyFromMinorUnits(i.PrincipalDue.MinorUnits()-i.PrincipalPaid.MinorUnits(), i.Currency)
+}
+
+// InterestOutstanding returns the installment's unpaid interest
+func (i *Installment) InterestOutstanding() utils.Money {
+	return utils.MoneyFromMinorUnits(i.InterestDue.MinorUnits()-i.InterestPaid.MinorUnits(), i.Currency)
+}
+
+// AmountOutstanding returns the unpaid portion of the installment. UnmarshalJSON and the schedule
+// builder hold every amount of an installment in its Currency, so the minor units add up directly.
+func (i *Installment) AmountOutstanding() utils.Money {
+	return utils.MoneyFromMinorUnits(i.TotalDue.MinorUnits()-i.PrincipalPaid.MinorUnits()-i.InterestPaid.MinorUnits(), i.Currency)
+}
+
+// RepaymentRequest represents a borrower repayment against the schedule
+type RepaymentRequest struct {
+	LoanID         string  `json:"loanID"`
+	Amount         float64 `json:"amount"`
+	Reference      string  `json:"reference"`
+	CounterpartyID string  `json:"counterpartyID"`
+	ActorID        string  `json:"actorID"`
+}
+
+// RepaymentAllocation records how a repayment was applied to an installment
+type RepaymentAllocation struct {
+	InstallmentNumber int     `json:"installmentNumber"`
+	InterestPaid      float64 `json:"interestPaid"`
+	PrincipalPaid     float64 `json:"principalPaid"`
+}
+
+// RepaymentResult represents the outcome of a scheduled repayment
+type RepaymentResult struct {
+	LoanID             string                `json:"loanID"`
+	Amount             float64               `json:"amount"`
+	TransactionID      string                `json:"transactionID"`
+	Allocations        []RepaymentAllocation `json:"allocations"`
+	OutstandingBalance float64               `json:"outstandingBalance"`
+}
@@ -0,0 +1,14 @@
+package domain
+
+// SandboxPurgeRequest represents a request to purge a sandbox entity and its dependent records
+type SandboxPurgeRequest struct {
+	EntityID string `json:"entityID"`
+	ActorID  string `json:"actorID"`
+}
+
+// SandboxPurgeResult reports the records removed by a sandbox purge
+type SandboxPurgeResult struct {
+	EntityID       string `json:"entityID"`
+	EntityType     string `json:"entityType"`
+	RecordsDeleted int    `json:"recordsDeleted"`
+}
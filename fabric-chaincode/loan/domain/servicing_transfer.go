@@ -0,0 +1,124 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+)
+
+// ServicingTransferStatus represents the lifecycle state of a servicing transfer
+type ServicingTransferStatus string
+
+const (
+	ServicingTransferScheduled ServicingTransferStatus = "SCHEDULED"
+	ServicingTransferCompleted ServicingTransferStatus = "COMPLETED"
+	ServicingTransferCancelled ServicingTransferStatus = "CANCELLED"
+)
+
+// Open item types handed over with a loan in a servicing transfer
+const (
+	ServicingItemDocumentReview     = "DOCUMENT_REVIEW"
+	ServicingItemRecoveryObligation = "RECOVERY_OBLIGATION"
+	ServicingItemOverdueInstallment = "OVERDUE_INSTALLMENT"
+)
+
+// ServicingSegment selects the loans in a servicing transfer, either by ID or by status and
+// optionally loan type
+type ServicingSegment struct {
+	LoanIDs  []string `json:"loanIDs,omitempty"`
+	Status   string   `json:"status,omitempty"`
+	LoanType string   `json:"loanType,omitempty"`
+}
+
+// ServicingTransfer moves servicing of a loan book segment from one organization to another
+type ServicingTransfer struct {
+	TransferID         string                  `json:"transferID"`
+	FromServicerMSP    string                  `json:"fromServicerMSP"`
+	ToServicerMSP      string                  `json:"toServicerMSP"`
+	Segment            ServicingSegment        `json:"segment"`
+	LoanIDs            []string                `json:"loanIDs"` // Loans selected when the transfer was scheduled
+	EffectiveDate      time.Time               `json:"effectiveDate"`
+	Status             ServicingTransferStatus `json:"status"`
+	ManifestHash       string                  `json:"manifestHash,omitempty"` // Portfolio hash of the manifest written at cutover
+	ScheduledBy        string                  `json:"scheduledBy"`
+	ScheduledDate      time.Time               `json:"scheduledDate"`
+	CompletedBy        string                  `json:"completedBy,omitempty"`
+	CompletedDate      *time.Time              `json:"completedDate,omitempty"`
+	CancelledBy        string                  `json:"cancelledBy,omitempty"`
+	CancellationReason string                  `json:"cancellationReason,omitempty"`
+	LastUpdated        time.Time               `json:"lastUpdated"`
+}
+
+// ServicingTransferRequest represents a request to schedule a servicing transfer
+type ServicingTransferRequest struct {
+	ToServicerMSP string           `json:"toServicerMSP"`
+	Segment       ServicingSegment `json:"segment"`
+	EffectiveDate string           `json:"effectiveDate"` // YYYY-MM-DD; servicing cuts over from the start of this day (UTC)
+	ActorID       string           `json:"actorID"`
+}
+
+// ServicingTransferActionRequest represents a request to complete or cancel a servicing transfer
+type ServicingTransferActionRequest struct {
+	TransferID string `json:"transferID"`
+	Reason     string `json:"reason,omitempty"`
+	ActorID    string `json:"actorID"`
+}
+
+// ServicingOpenItem is an outstanding task or escalation handed over with a loan
+type ServicingOpenItem struct {
+	ItemType string     `json:"itemType"`
+	ItemID   string     `json:"itemID"`
+	Status   string     `json:"status"`
+	Amount   float64    `json:"amount,omitempty"`
+	DueDate  *time.Time `json:"dueDate,omitempty"`
+}
+
+// ServicingManifestEntry records one transferred loan as it stood at cutover
+type ServicingManifestEntry struct {
+	LoanID             string              `json:"loanID"`
+	CustomerIDs        []string            `json:"customerIDs"`
+	LoanType           string              `json:"loanType"`
+	Status             string              `json:"status"`
+	Currency           string              `json:"currency,omitempty"`
+	OutstandingBalance float64             `json:"outstandingBalance"` // In Currency
+	OpenItems          []ServicingOpenItem `json:"openItems"`
+	EntryHash          string              `json:"entryHash"` // SHA-256 of the entry with this field empty
+}
+
+// ServicingTransferManifest is the immutable record of the portfolio moved by a servicing transfer
+type ServicingTransferManifest struct {
+	TransferID       string                   `json:"transferID"`
+	FromServicerMSP  string                   `json:"fromServicerMSP"`
+	ToServicerMSP    string                   `json:"toServicerMSP"`
+	EffectiveDate    time.Time                `json:"effectiveDate"`
+	Entries          []ServicingManifestEntry `json:"entries"`
+	LoanCount        int                      `json:"loanCount"`
+	TotalsOutstanding utils.CurrencyTotals    `json:"totalsOutstanding"` // Per loan currency
+	OpenItemCount    int                      `json:"openItemCount"`
+	PortfolioHash    string                   `json:"portfolioHash"` // SHA-256 over the transfer header and every entry hash
+	CreatedDate      time.Time                `json:"createdDate"`
+	CreatedTxID      string                   `json:"createdTxID"`
+}
+
+// ServicingManifestVerification reports whether a stored manifest still matches its hashes
+type ServicingManifestVerification struct {
+	TransferID    string   `json:"transferID"`
+	Valid         bool     `json:"valid"`
+	PortfolioHash string   `json:"portfolioHash"`
+	Mismatches    []string `json:"mismatches"`
+}
+
+// ServicingTransferNotice tells a customer which of their loans change servicer and when
+type ServicingTransferNotice struct {
+	CustomerID      string    `json:"customerID"`
+	LoanIDs         []string  `json:"loanIDs"`
+	FromServicerMSP string    `json:"fromServicerMSP"`
+	ToServicerMSP   string    `json:"toServicerMSP"`
+	EffectiveDate   time.Time `json:"effectiveDate"`
+}
+
+// ServicingTransferEventData is the data of servicing transfer lifecycle events
+type ServicingTransferEventData struct {
+	Transfer *ServicingTransfer        `json:"transfer"`
+	Notices  []ServicingTransferNotice `json:"notices"`
+}
@@ -0,0 +1,70 @@
+package domain
+
+import "time"
+
+// StatusSyncRecord is one loan status change queued for the core banking adapter. Records are
+// numbered per loan from 1 and must be acknowledged in order; unacknowledged records stay pending
+// and may be resent, so delivery is at least once.
+type StatusSyncRecord struct {
+	LoanID         string     `json:"loanID"`
+	Sequence       int        `json:"sequence"`
+	CustomerID     string     `json:"customerID"`
+	LoanType       string     `json:"loanType"`
+	PreviousStatus string     `json:"previousStatus,omitempty"` // Empty for the record created with the loan
+	Status         string     `json:"status"`
+	ChangedDate    time.Time  `json:"changedDate"`
+	TransactionID  string     `json:"transactionID"` // Transaction that changed the status
+	SendCount      int        `json:"sendCount"`     // Times the record has been offered, counting resends
+	LastSentDate   time.Time  `json:"lastSentDate"`
+	AckedDate      *time.Time `json:"ackedDate,omitempty"`
+	AckedBy        string     `json:"ackedBy,omitempty"`
+	ConsumerRef    string     `json:"consumerRef,omitempty"` // Core banking reference returned with the acknowledgment
+}
+
+// StatusSyncState tracks how far a loan's status sync records have been produced and acknowledged
+type StatusSyncState struct {
+	LoanID        string    `json:"loanID"`
+	LastSequence  int       `json:"lastSequence"`
+	AckedSequence int       `json:"ackedSequence"`
+	LastUpdated   time.Time `json:"lastUpdated"`
+}
+
+// StatusSyncAckRequest acknowledges that core banking applied a status sync record
+type StatusSyncAckRequest struct {
+	LoanID      string `json:"loanID"`
+	Sequence    int    `json:"sequence"`
+	ConsumerRef string `json:"consumerRef"`
+	ActorID     string `json:"actorID"`
+}
+
+// StatusSyncResendRequest offers a loan's unacknowledged status sync records again
+type StatusSyncResendRequest struct {
+	LoanID       string `json:"loanID"`
+	FromSequence int    `json:"fromSequence,omitempty"` // Defaults to the first unacknowledged record
+	ActorID      string `json:"actorID"`
+}
+
+// StatusSyncPage is one page of pending status sync records, in sequence order within each loan
+type StatusSyncPage struct {
+	Records  []StatusSyncRecord `json:"records"`
+	Count    int                `json:"count"`
+	Bookmark string             `json:"bookmark"`
+}
+
+// StatusSyncGap is a loan with status sync records core banking has not acknowledged
+type StatusSyncGap struct {
+	LoanID        string    `json:"loanID"`
+	AckedSequence int       `json:"ackedSequence"`
+	LastSequence  int       `json:"lastSequence"`
+	Pending       int       `json:"pending"`
+	OldestSent    time.Time `json:"oldestSent"` // When the first unacknowledged record was last offered
+	Stale         bool      `json:"stale"`      // Unacknowledged for longer than config.StatusSyncAckTimeout; due a resend
+}
+
+// StatusSyncGapReport is one page of loans with unacknowledged status sync records
+type StatusSyncGapReport struct {
+	AsOf     time.Time       `json:"asOf"`
+	Gaps     []StatusSyncGap `json:"gaps"`
+	Count    int             `json:"count"`
+	Bookmark string          `json:"bookmark"`
+}
@@ -0,0 +1,85 @@
+package domain
+
+import (
+	"time"
+)
+
+// Reasons an application is referred to manual underwriting instead of auto-approved
+const (
+	STPReferralDisabled     = "STP_DISABLED"
+	STPReferralLoanType     = "LOAN_TYPE_NOT_ELIGIBLE"
+	STPReferralAmount       = "AMOUNT_ABOVE_LIMIT"
+	STPReferralVerification = "PARTY_NOT_VERIFIED"
+	STPReferralRiskScore    = "RISK_SCORE_ABOVE_LIMIT"
+	STPReferralDocuments    = "DOCUMENTS_OUTSTANDING"
+	STPReferralLoanToValue  = "LOAN_TO_VALUE_EXCEEDED"
+	STPReferralChecklist    = "CHECKLIST_INCOMPLETE"
+	STPReferralRatePolicy   = "RATE_OUTSIDE_POLICY"
+	STPReferralCurrency     = "CURRENCY_NOT_ELIGIBLE"
+)
+
+// STPPolicy configures straight-through processing: which applications the underwriting engine
+// may approve without human action. Only the current policy is kept in state; earlier versions
+// remain in the key's ledger history.
+type STPPolicy struct {
+	Enabled       bool               `json:"enabled"`
+	MaxAmount     float64            `json:"maxAmount"`     // Largest requested amount eligible for auto-approval
+	MaxRiskScore  float64            `json:"maxRiskScore"`  // Highest AML risk score (0-100) of any party treated as low risk
+	InterestRates map[string]float64 `json:"interestRates"` // Rate auto-approved loans are priced at, by loan type; unlisted types are never auto-approved
+	SampleRate    float64            `json:"sampleRate"`    // Share of auto-approvals (0-1) queued for quality review
+	LastUpdated   time.Time          `json:"lastUpdated"`
+	LastUpdatedBy string             `json:"lastUpdatedBy"`
+}
+
+// STPPolicyRequest represents a request to replace the straight-through processing policy
+type STPPolicyRequest struct {
+	Enabled       bool               `json:"enabled"`
+	MaxAmount     float64            `json:"maxAmount"`
+	MaxRiskScore  float64            `json:"maxRiskScore"`
+	InterestRates map[string]float64 `json:"interestRates"`
+	SampleRate    float64            `json:"sampleRate"`
+	ActorID       string             `json:"actorID"`
+}
+
+// STPEvaluationRequest represents the underwriting engine's request to evaluate an application
+// for straight-through processing
+type STPEvaluationRequest struct {
+	LoanID  string `json:"loanID"`
+	ActorID string `json:"actorID"`
+}
+
+// STPDecision records the outcome of a straight-through processing evaluation. Applications that
+// are not auto-approved stay in their status for manual underwriting.
+type STPDecision struct {
+	LoanID            string    `json:"loanID"`
+	LoanType          string    `json:"loanType"`
+	RequestedAmount   float64   `json:"requestedAmount"`
+	AutoApproved      bool      `json:"autoApproved"`
+	ReferralReasons   []string  `json:"referralReasons,omitempty"`   // Why the application needs a human decision
+	RiskScore         float64   `json:"riskScore"`                   // Highest AML risk score across the parties
+	RatePolicyVersion int       `json:"ratePolicyVersion,omitempty"` // Version of the loan type's rate policy the policy rate was held to
+	Sampled           bool      `json:"sampled"`                     // Queued for quality review
+	EvaluatedDate     time.Time `json:"evaluatedDate"`
+	EvaluatedBy       string    `json:"evaluatedBy"`
+	TransactionID     string    `json:"transactionID"`
+}
+
+// STPRateReport summarises straight-through processing over a date range
+type STPRateReport struct {
+	FromDate        string         `json:"fromDate"`
+	ToDate          string         `json:"toDate"`
+	Evaluated       int            `json:"evaluated"`
+	AutoApproved    int            `json:"autoApproved"`
+	Referred        int            `json:"referred"`
+	STPRate         float64        `json:"stpRate"` // Percent of evaluated applications auto-approved
+	Sampled         int            `json:"sampled"`
+	ReferralReasons map[string]int `json:"referralReasons"` // Count of referrals by reason code
+	GeneratedDate   time.Time      `json:"generatedDate"`
+}
+
+// STPSampleQueueResult is one page of auto-approved applications sampled for quality review
+type STPSampleQueueResult struct {
+	Decisions []STPDecision `json:"decisions"`
+	Count     int           `json:"count"`
+	Bookmark  string        `json:"bookmark"`
+}
@@ -0,0 +1,92 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// StressTestSchemaVersion identifies the layout of StressTestInput records. It changes whenever a
+// field is added, removed or changes meaning, so downstream models can reject extracts they do not understand.
+const StressTestSchemaVersion = 1
+
+// Delinquency buckets, by days past due on the oldest installment unpaid at the as-of date
+const (
+	DelinquencyCurrent = "CURRENT"
+	Delinquency1To29   = "DPD_1_29"
+	Delinquency30To59  = "DPD_30_59"
+	Delinquency60To89  = "DPD_60_89"
+	Delinquency90Plus  = "DPD_90_PLUS"
+)
+
+// Customer risk tiers, from the loan's risk score (0-100, higher is riskier)
+const (
+	RiskTierLow      = "LOW"       // Below 30
+	RiskTierMedium   = "MEDIUM"    // 30 to below 60
+	RiskTierHigh     = "HIGH"      // 60 to below 80
+	RiskTierVeryHigh = "VERY_HIGH" // 80 and above
+	RiskTierUnscored = "UNSCORED"  // No risk score recorded
+)
+
+// StressTestInput is one loan's record in a portfolio stress-test extract. Every figure is as at
+// the end of the extract's as-of date (UTC), reconstructed from the loan's dated records rather
+// than read from its current state.
+type StressTestInput struct {
+	LoanID              string                           `json:"loanID"`
+	CustomerID          string                           `json:"customerID"`
+	LoanType            string                           `json:"loanType"`
+	Status              validation.LoanApplicationStatus `json:"status"` // Current status; DISBURSED, DELINQUENT or DEFAULTED
+	DisbursementDate    time.Time                        `json:"disbursementDate"`
+	Balance             float64                          `json:"balance"`             // Balance after the last loan transaction on or before the as-of date
+	InterestRate        *float64                         `json:"interestRate"`        // Current annual rate in percent; null if never priced
+	RateIndex           string                           `json:"rateIndex,omitempty"` // Set for floating-rate loans
+	TermMonths          int                              `json:"termMonths"`
+	TermRemainingMonths int                              `json:"termRemainingMonths"` // Scheduled installments falling due after the as-of date
+	CollateralValue     float64                          `json:"collateralValue"`     // Valuation of collateral pledged and not released at the as-of date
+	DaysPastDue         int                              `json:"daysPastDue"`
+	DelinquencyBucket   string                           `json:"delinquencyBucket"`
+	RiskScore           *float64                         `json:"riskScore"`
+	RiskTier            string                           `json:"riskTier"`
+}
+
+// StressTestExtract is one page of a portfolio stress-test extract. Callers page through the
+// book by passing Bookmark back with the same as-of date until it comes back empty.
+type StressTestExtract struct {
+	SchemaVersion int               `json:"schemaVersion"`
+	AsOfDate      string            `json:"asOfDate"` // YYYY-MM-DD
+	Records       []StressTestInput `json:"records"`
+	Count         int               `json:"count"`
+	Bookmark      string            `json:"bookmark"`
+}
+
+// DelinquencyBucketFor returns the delinquency bucket for a number of days past due
+func DelinquencyBucketFor(daysPastDue int) string {
+	switch {
+	case daysPastDue <= 0:
+		return DelinquencyCurrent
+	case daysPastDue < 30:
+		return Delinquency1To29
+	case daysPastDue < 60:
+		return Delinquency30To59
+	case daysPastDue < 90:
+		return Delinquency60To89
+	default:
+		return Delinquency90Plus
+	}
+}
+
+// RiskTierFor returns the customer risk tier for a loan's risk score
+func RiskTierFor(riskScore *float64) string {
+	switch {
+	case riskScore == nil:
+		return RiskTierUnscored
+	case *riskScore < 30:
+		return RiskTierLow
+	case *riskScore < 60:
+		return RiskTierMedium
+	case *riskScore < 80:
+		return RiskTierHigh
+	default:
+		return RiskTierVeryHigh
+	}
+}
@@ -0,0 +1,43 @@
+package domain
+
+import (
+	"time"
+
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// Customer-facing states of an outstanding document requirement
+const (
+	DocumentRequirementMissing      = "REQUIRED"
+	DocumentRequirementUnderReview  = "UNDER_REVIEW"
+	DocumentRequirementResubmission = "RESUBMISSION_REQUIRED"
+)
+
+// ApplicationTimeline is the customer-safe view of a loan application's progress.
+// It deliberately omits internal fields such as risk scores, notes and staff identifiers.
+type ApplicationTimeline struct {
+	LoanID               string                            `json:"loanID"`
+	LoanType             string                            `json:"loanType"`
+	RequestedAmount      float64                           `json:"requestedAmount"`
+	ApprovedAmount       *float64                          `json:"approvedAmount,omitempty"`
+	CurrentStatus        validation.LoanApplicationStatus `json:"currentStatus"`
+	StatusLabel          string                            `json:"statusLabel"`
+	Milestones           []TimelineMilestone               `json:"milestones"`
+	OutstandingDocuments []DocumentRequirement             `json:"outstandingDocuments"`
+	LastUpdated          time.Time                         `json:"lastUpdated"`
+}
+
+// TimelineMilestone represents one stage of the application journey
+type TimelineMilestone struct {
+	Status      validation.LoanApplicationStatus `json:"status"`
+	Label       string                            `json:"label"`
+	Reached     bool                              `json:"reached"`
+	ReachedDate *time.Time                        `json:"reachedDate,omitempty"`
+}
+
+// DocumentRequirement represents a required document that has not yet been verified
+type DocumentRequirement struct {
+	DocumentType string `json:"documentType"`
+	State        string `json:"state"`
+	Reason       string `json:"reason,omitempty"`
+}
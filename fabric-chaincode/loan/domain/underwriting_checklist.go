@@ -0,0 +1,80 @@
+package domain
+
+import "time"
+
+// ChecklistItemType groups underwriting checklist items by what completing them involves
+type ChecklistItemType string
+
+const (
+	ChecklistItemDocument     ChecklistItemType = "DOCUMENT"     // A document collected and reviewed
+	ChecklistItemVerification ChecklistItemType = "VERIFICATION" // A check made with the customer or a third party
+	ChecklistItemRule         ChecklistItemType = "RULE"         // A credit policy rule confirmed as met
+)
+
+// ChecklistItemDefinition is one requirement of an underwriting checklist
+type ChecklistItemDefinition struct {
+	ItemID      string            `json:"itemID"`
+	Type        ChecklistItemType `json:"type"`
+	Description string            `json:"description"`
+}
+
+// UnderwritingChecklist lists the requirements applications of a loan type with a requested
+// amount in the band MinAmount to MaxAmount inclusive must meet before credit review or
+// approval. Bands of the same loan type may not overlap; applications outside every band have no
+// checklist.
+type UnderwritingChecklist struct {
+	LoanType      string                    `json:"loanType"`
+	BandID        string                    `json:"bandID"`
+	MinAmount     float64                   `json:"minAmount"`
+	MaxAmount     float64                   `json:"maxAmount"`
+	Items         []ChecklistItemDefinition `json:"items"`
+	LastUpdated   time.Time                 `json:"lastUpdated"`
+	LastUpdatedBy string                    `json:"lastUpdatedBy"`
+}
+
+// UnderwritingChecklistRequest represents a request to create or replace the checklist of a loan
+// type's amount band
+type UnderwritingChecklistRequest struct {
+	LoanType  string                    `json:"loanType"`
+	BandID    string                    `json:"bandID"`
+	MinAmount float64                   `json:"minAmount"`
+	MaxAmount float64                   `json:"maxAmount"`
+	Items     []ChecklistItemDefinition `json:"items"`
+	ActorID   string                    `json:"actorID"`
+}
+
+// ChecklistItemCompletion records who marked a checklist item complete on a loan, when, and the
+// evidence relied on
+type ChecklistItemCompletion struct {
+	LoanID            string    `json:"loanID"`
+	ItemID            string    `json:"itemID"`
+	EvidenceReference string    `json:"evidenceReference"` // Document ID, report reference or other evidence of the item
+	CompletedBy       string    `json:"completedBy"`
+	CompletedDate     time.Time `json:"completedDate"`
+	TransactionID     string    `json:"transactionID"`
+}
+
+// ChecklistItemCompletionRequest represents a request to mark a checklist item complete on a loan
+type ChecklistItemCompletionRequest struct {
+	LoanID            string `json:"loanID"`
+	ItemID            string `json:"itemID"`
+	EvidenceReference string `json:"evidenceReference"`
+	ActorID           string `json:"actorID"`
+}
+
+// LoanChecklistItem is a checklist item with its completion on a loan, if any
+type LoanChecklistItem struct {
+	ChecklistItemDefinition
+	Completion *ChecklistItemCompletion `json:"completion,omitempty"`
+}
+
+// LoanChecklistStatus shows a loan's progress through the checklist of its loan type and amount
+// band. BandID is empty when no checklist applies, in which case the checklist is complete.
+type LoanChecklistStatus struct {
+	LoanID      string              `json:"loanID"`
+	LoanType    string              `json:"loanType"`
+	BandID      string              `json:"bandID,omitempty"`
+	Items       []LoanChecklistItem `json:"items"`
+	Outstanding []string            `json:"outstanding"` // IDs of items not yet complete
+	Complete    bool                `json:"complete"`
+}
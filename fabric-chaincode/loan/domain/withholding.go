@@ -0,0 +1,51 @@
+package domain
+
+import (
+	"time"
+)
+
+// WithholdingRecord records the tax withheld on one interest or fee transaction
+type WithholdingRecord struct {
+	LoanID         string              `json:"loanID"`
+	TransactionID  string              `json:"transactionID"`
+	Jurisdiction   string              `json:"jurisdiction"`
+	IncomeType     LoanTransactionType `json:"incomeType"`
+	GrossAmount    float64             `json:"grossAmount"`
+	Rate           float64             `json:"rate"`
+	WithheldAmount float64             `json:"withheldAmount"`
+	Period         string              `json:"period"` // Remittance month, YYYY-MM
+	CreatedDate    time.Time           `json:"createdDate"`
+	CreatedBy      string              `json:"createdBy"`
+}
+
+// LoanWithholding lists the tax withheld on a loan
+type LoanWithholding struct {
+	LoanID        string              `json:"loanID"`
+	Jurisdiction  string              `json:"jurisdiction"`
+	TotalWithheld float64             `json:"totalWithheld"`
+	Records       []WithholdingRecord `json:"records"`
+}
+
+// WithholdingRemittanceLine totals one loan's withholding within a remittance period
+type WithholdingRemittanceLine struct {
+	LoanID           string  `json:"loanID"`
+	CustomerID       string  `json:"customerID"`
+	InterestGross    float64 `json:"interestGross"`
+	InterestWithheld float64 `json:"interestWithheld"`
+	FeeGross         float64 `json:"feeGross"`
+	FeeWithheld      float64 `json:"feeWithheld"`
+	TotalWithheld    float64 `json:"totalWithheld"`
+}
+
+// WithholdingRemittanceReport totals the tax withheld in a jurisdiction for one month, for the
+// finance team to remit
+type WithholdingRemittanceReport struct {
+	Jurisdiction          string                      `json:"jurisdiction"`
+	Period                string                      `json:"period"`
+	Lines                 []WithholdingRemittanceLine `json:"lines"`
+	RecordCount           int                         `json:"recordCount"`
+	TotalInterestWithheld float64                     `json:"totalInterestWithheld"`
+	TotalFeeWithheld      float64                     `json:"totalFeeWithheld"`
+	TotalWithheld         float64                     `json:"totalWithheld"`
+	GeneratedDate         time.Time                   `json:"generatedDate"`
+}
@@ -0,0 +1,453 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	loanServices "github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// AmountPolicyHandler handles the loan amount bounds set per customer risk tier and loan type, and
+// the exceptions register used to depart from them
+type AmountPolicyHandler struct {
+	persistenceService *services.PersistenceService
+	eventService       *loanServices.EventService
+}
+
+// NewAmountPolicyHandler creates a new amount policy handler
+func NewAmountPolicyHandler() *AmountPolicyHandler {
+	return &AmountPolicyHandler{
+		persistenceService: services.NewPersistenceService(),
+		eventService:       loanServices.NewEventService(),
+	}
+}
+
+// SetLoanAmountBound creates or replaces the amount bound for customers in a risk tier borrowing
+// on a loan type
+func (h *AmountPolicyHandler) SetLoanAmountBound(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.LoanAmountBoundRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse loan amount bound request: %v", err)
+	}
+
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return nil, err
+	}
+	if role != string(validation.ActorRoleCreditOfficer) {
+		return nil, services.NewChaincodeError(services.ErrCodeAccessDenied, "", "loan amount bounds may only be set by a %s", validation.ActorRoleCreditOfficer)
+	}
+
+	riskTier, err := normalizeAmountBoundTier(req.RiskTier)
+	if err != nil {
+		return nil, err
+	}
+	loanType := strings.TrimSpace(req.LoanType)
+	if loanType != domain.LoanAmountBoundWildcard {
+		if err := validation.ValidateLoanType(loanType); err != nil {
+			return nil, fmt.Errorf("invalid loan type: %w", err)
+		}
+	}
+	if err := validateAmountRange(req.MinAmount, req.MaxAmount); err != nil {
+		return nil, err
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	bound := &domain.LoanAmountBound{
+		RiskTier:      riskTier,
+		LoanType:      loanType,
+		MinAmount:     req.MinAmount,
+		MaxAmount:     req.MaxAmount,
+		LastUpdated:   now,
+		LastUpdatedBy: req.ActorID,
+	}
+
+	boundKey := loanAmountBoundKey(riskTier, loanType)
+	previousJSON := ""
+	if previous, err := stub.GetState(boundKey); err != nil {
+		return nil, fmt.Errorf("failed to read loan amount bound: %w", err)
+	} else if previous != nil {
+		previousJSON = string(previous)
+	}
+
+	if err := h.persistenceService.Put(stub, boundKey, bound); err != nil {
+		return nil, fmt.Errorf("failed to store loan amount bound: %w", err)
+	}
+
+	// Record history
+	boundJSON, _ := utils.MarshalJSONString(bound)
+	if err := h.recordEntityHistory(stub, fmt.Sprintf("%s_%s", riskTier, loanType), "LoanAmountBound", "UPDATE", "loanAmountBound", previousJSON, boundJSON, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to record history: %w", err)
+	}
+
+	// Emit event
+	if err := h.eventService.EmitLoanAmountBoundUpdated(stub, bound, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(bound)
+}
+
+// GetLoanAmountBound retrieves the bound that applies to a risk tier and loan type, falling back
+// to wildcard bounds.
+// Args: riskTier, loanType
+func (h *AmountPolicyHandler) GetLoanAmountBound(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 2, got %d", len(args))
+	}
+
+	riskTier, err := normalizeAmountBoundTier(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	bound, err := findLoanAmountBound(stub, h.persistenceService, riskTier, strings.TrimSpace(args[1]))
+	if err != nil {
+		return nil, err
+	}
+	if bound == nil {
+		return nil, fmt.Errorf("no loan amount bound applies to %s risk customers on %s loans", riskTier, args[1])
+	}
+
+	return json.Marshal(bound)
+}
+
+// RequestPolicyException raises a loan amount exception for a customer and loan type. It must be
+// approved by a credit officer before a loan can rely on it.
+func (h *AmountPolicyHandler) RequestPolicyException(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.PolicyExceptionRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse policy exception request: %v", err)
+	}
+
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+	if strings.TrimSpace(req.CustomerID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "customerID", "customerID is required")
+	}
+	if err := validation.ValidateLoanType(req.LoanType); err != nil {
+		return nil, fmt.Errorf("invalid loan type: %w", err)
+	}
+	if err := validateAmountRange(req.MinAmount, req.MaxAmount); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.Justification) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "justification", "justification is required")
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	exception := &domain.PolicyException{
+		ExceptionID:   services.GenerateDeterministicID(stub, config.PolicyExceptionPrefix),
+		Type:          domain.PolicyExceptionLoanAmount,
+		CustomerID:    req.CustomerID,
+		LoanType:      req.LoanType,
+		MinAmount:     req.MinAmount,
+		MaxAmount:     req.MaxAmount,
+		Justification: req.Justification,
+		Status:        domain.PolicyExceptionPending,
+		RequestedBy:   req.ActorID,
+		RequestedDate: now,
+	}
+
+	if err := h.persistenceService.Put(stub, policyExceptionKey(exception.ExceptionID), exception); err != nil {
+		return nil, fmt.Errorf("failed to store policy exception: %w", err)
+	}
+
+	// Index by customer so a customer's exceptions can be listed
+	indexKey, err := stub.CreateCompositeKey("CUSTOMER_POLICY_EXCEPTION", []string{exception.CustomerID, exception.ExceptionID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := stub.PutState(indexKey, []byte(exception.ExceptionID)); err != nil {
+		return nil, fmt.Errorf("failed to create policy exception index: %w", err)
+	}
+
+	// Emit event
+	if err := h.eventService.EmitPolicyExceptionRequested(stub, exception, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(exception)
+}
+
+// DecidePolicyException approves or rejects a pending exception. The decision must be made by a
+// credit officer other than the requester; approved exceptions lapse after
+// config.PolicyExceptionValidity unless a loan has relied on them.
+func (h *AmountPolicyHandler) DecidePolicyException(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.PolicyExceptionDecisionRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, fmt.Errorf("failed to parse policy exception decision: %w", err)
+	}
+
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return nil, err
+	}
+	if role != string(validation.ActorRoleCreditOfficer) {
+		return nil, services.NewChaincodeError(services.ErrCodeAccessDenied, "", "policy exceptions may only be decided by a %s", validation.ActorRoleCreditOfficer)
+	}
+
+	var exception domain.PolicyException
+	if err := h.persistenceService.Get(stub, policyExceptionKey(req.ExceptionID), &exception); err != nil {
+		return nil, fmt.Errorf("policy exception not found: %w", err)
+	}
+	if exception.Status != domain.PolicyExceptionPending {
+		return nil, fmt.Errorf("policy exception %s has already been decided: %s", exception.ExceptionID, exception.Status)
+	}
+	if exception.RequestedBy == req.ActorID {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "policy exception %s must be decided by someone other than its requester", exception.ExceptionID)
+	}
+	if !req.Approve && strings.TrimSpace(req.Notes) == "" {
+		return nil, fmt.Errorf("notes are required to reject a policy exception")
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	exception.Status = domain.PolicyExceptionRejected
+	if req.Approve {
+		exception.Status = domain.PolicyExceptionApproved
+		expiry := now.Add(config.PolicyExceptionValidity)
+		exception.ExpiryDate = &expiry
+	}
+	exception.DecidedBy = req.ActorID
+	exception.DecidedDate = &now
+	exception.DecisionNotes = req.Notes
+
+	if err := h.persistenceService.Put(stub, policyExceptionKey(exception.ExceptionID), &exception); err != nil {
+		return nil, fmt.Errorf("failed to update policy exception: %w", err)
+	}
+
+	// Emit event
+	if err := h.eventService.EmitPolicyExceptionDecided(stub, &exception, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(&exception)
+}
+
+// GetPolicyException retrieves an entry of the exceptions register
+func (h *AmountPolicyHandler) GetPolicyException(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var exception domain.PolicyException
+	if err := h.persistenceService.Get(stub, policyExceptionKey(args[0]), &exception); err != nil {
+		return nil, fmt.Errorf("policy exception not found: %w", err)
+	}
+
+	return json.Marshal(&exception)
+}
+
+// GetPolicyExceptionsByCustomer lists every exception raised for a customer
+func (h *AmountPolicyHandler) GetPolicyExceptionsByCustomer(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	iterator, err := stub.GetStateByPartialCompositeKey("CUSTOMER_POLICY_EXCEPTION", []string{args[0]})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get policy exceptions by customer: %w", err)
+	}
+	defer iterator.Close()
+
+	exceptions := []domain.PolicyException{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate policy exceptions: %w", err)
+		}
+
+		var exception domain.PolicyException
+		if err := h.persistenceService.Get(stub, policyExceptionKey(string(response.Value)), &exception); err != nil {
+			continue // Skip if exception not found
+		}
+		exceptions = append(exceptions, exception)
+	}
+
+	return json.Marshal(exceptions)
+}
+
+// Helper methods
+
+func (h *AmountPolicyHandler) recordEntityHistory(stub shim.ChaincodeStubInterface, entityID, entityType, changeType, fieldName, previousValue, newValue, actorID string) error {
+	historyID, compositeKey, err := services.NewHistoryKey(stub, entityID)
+	if err != nil {
+		return err
+	}
+	txID := stub.GetTxID()
+
+	timestamp, err := services.TxTimeString(stub)
+	if err != nil {
+		return err
+	}
+
+	historyEntry := map[string]interface{}{
+		"historyID":     historyID,
+		"entityID":      entityID,
+		"entityType":    entityType,
+		"timestamp":     timestamp,
+		"changeType":    changeType,
+		"fieldName":     fieldName,
+		"previousValue": previousValue,
+		"newValue":      newValue,
+		"actorID":       actorID,
+		"transactionID": txID,
+	}
+
+	return h.persistenceService.Put(stub, compositeKey, historyEntry)
+}
+
+// checkLoanAmountBound holds a loan amount to the bound for the customer's risk tier and the loan
+// type. Customers who have not been rated fall under LoanAmountTierUnrated. When an exception ID
+// is given the exception's range applies instead of the bound: it must be an approved loan amount
+// exception for the same customer and loan type that has not lapsed or been relied on by another
+// loan, and relying on it binds it to the loan. It returns the exception ID relied on, if any.
+func checkLoanAmountBound(stub shim.ChaincodeStubInterface, ps *services.PersistenceService, customerID, riskTier, loanType string, amount float64, loanID, exceptionID string) (string, error) {
+	if exceptionID != "" {
+		var exception domain.PolicyException
+		if err := ps.Get(stub, policyExceptionKey(exceptionID), &exception); err != nil {
+			return "", fmt.Errorf("policy exception not found: %w", err)
+		}
+		if exception.Type != domain.PolicyExceptionLoanAmount || exception.Status != domain.PolicyExceptionApproved {
+			return "", fmt.Errorf("policy exception %s is not an approved loan amount exception", exceptionID)
+		}
+		if exception.CustomerID != customerID || exception.LoanType != loanType {
+			return "", fmt.Errorf("policy exception %s was granted for customer %s on %s loans", exceptionID, exception.CustomerID, exception.LoanType)
+		}
+		if exception.LoanID != "" && exception.LoanID != loanID {
+			return "", fmt.Errorf("policy exception %s has already been relied on by loan %s", exceptionID, exception.LoanID)
+		}
+		if exception.LoanID == "" {
+			now, err := services.TxTime(stub)
+			if err != nil {
+				return "", err
+			}
+			if exception.ExpiryDate != nil && now.After(*exception.ExpiryDate) {
+				return "", fmt.Errorf("policy exception %s lapsed on %s", exceptionID, exception.ExpiryDate.Format("2006-01-02"))
+			}
+		}
+		if amount < exception.MinAmount || amount > exception.MaxAmount {
+			return "", fmt.Errorf("amount %.2f is outside the %.2f to %.2f range of policy exception %s", amount, exception.MinAmount, exception.MaxAmount, exceptionID)
+		}
+
+		if exception.LoanID == "" {
+			exception.LoanID = loanID
+			if err := ps.Put(stub, policyExceptionKey(exceptionID), &exception); err != nil {
+				return "", fmt.Errorf("failed to update policy exception: %w", err)
+			}
+		}
+		return exceptionID, nil
+	}
+
+	if riskTier == "" {
+		riskTier = domain.LoanAmountTierUnrated
+	}
+	bound, err := findLoanAmountBound(stub, ps, riskTier, loanType)
+	if err != nil {
+		return "", err
+	}
+	if bound != nil && (amount < bound.MinAmount || amount > bound.MaxAmount) {
+		return "", fmt.Errorf("amount %.2f is outside the %.2f to %.2f bound for %s risk customers on %s loans; an approved policy exception is required", amount, bound.MinAmount, bound.MaxAmount, riskTier, loanType)
+	}
+	return "", nil
+}
+
+func findLoanAmountBound(stub shim.ChaincodeStubInterface, persistenceService *services.PersistenceService, riskTier, loanType string) (*domain.LoanAmountBound, error) {
+	candidates := [][2]string{
+		{riskTier, loanType},
+		{riskTier, domain.LoanAmountBoundWildcard},
+		{domain.LoanAmountBoundWildcard, loanType},
+		{domain.LoanAmountBoundWildcard, domain.LoanAmountBoundWildcard},
+	}
+	for _, candidate := range candidates {
+		boundKey := loanAmountBoundKey(candidate[0], candidate[1])
+		exists, err := persistenceService.Exists(stub, boundKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check loan amount bound: %w", err)
+		}
+		if !exists {
+			continue
+		}
+		var bound domain.LoanAmountBound
+		if err := persistenceService.Get(stub, boundKey, &bound); err != nil {
+			return nil, fmt.Errorf("failed to get loan amount bound: %w", err)
+		}
+		return &bound, nil
+	}
+	return nil, nil
+}
+
+func loanAmountBoundKey(riskTier, loanType string) string {
+	return fmt.Sprintf("AMOUNT_BOUND_%s_%s", riskTier, loanType)
+}
+
+func policyExceptionKey(exceptionID string) string {
+	return fmt.Sprintf("POLICY_EXCEPTION_%s", exceptionID)
+}
+
+// normalizeAmountBoundTier upper-cases a risk tier, which must be a customer risk tier,
+// LoanAmountTierUnrated or the wildcard
+func normalizeAmountBoundTier(riskTier string) (string, error) {
+	riskTier = strings.ToUpper(strings.TrimSpace(riskTier))
+	if riskTier == domain.LoanAmountBoundWildcard {
+		return riskTier, nil
+	}
+	for _, tier := range domain.LoanAmountTiers {
+		if riskTier == tier {
+			return riskTier, nil
+		}
+	}
+	return "", services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid risk tier %s, expected one of %s or %s", riskTier, strings.Join(domain.LoanAmountTiers, ", "), domain.LoanAmountBoundWildcard)
+}
+
+// validateAmountRange checks a bound or exception range lies within the product-wide limits
+func validateAmountRange(minAmount, maxAmount float64) error {
+	if minAmount < 0 {
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "minAmount cannot be negative")
+	}
+	if maxAmount <= 0 || maxAmount > config.MaxLoanAmount {
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "maxAmount must be greater than 0 and at most %.2f, got %.2f", config.MaxLoanAmount, maxAmount)
+	}
+	if minAmount > maxAmount {
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "minAmount %.2f exceeds maxAmount %.2f", minAmount, maxAmount)
+	}
+	return nil
+}
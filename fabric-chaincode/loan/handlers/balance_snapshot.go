@@ -0,0 +1,287 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	loanServices "github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+const (
+	balanceSnapshotDateFormat = "2006-01-02"
+	loanKeyPrefix             = "LOAN_"
+)
+
+// BalanceSnapshotHandler certifies end-of-day loan balances, giving general ledger reconciliation
+// a fixed reference point that later transactions cannot move
+type BalanceSnapshotHandler struct {
+	persistenceService *services.PersistenceService
+	eventService       *loanServices.EventService
+}
+
+// NewBalanceSnapshotHandler creates a new balance snapshot handler
+func NewBalanceSnapshotHandler() *BalanceSnapshotHandler {
+	return &BalanceSnapshotHandler{
+		persistenceService: services.NewPersistenceService(),
+		eventService:       loanServices.NewEventService(),
+	}
+}
+
+// RunBalanceSnapshot runs the next batch of the end-of-day snapshot for a date. It is triggered by
+// an external scheduler once the day has closed: each call reads one capped batch of loans in key
+// order, certifies the balance of every funded loan as at the cutoff and checkpoints its progress.
+// The call that reads the last loan fixes the totals and the snapshot hash and marks it CERTIFIED.
+func (h *BalanceSnapshotHandler) RunBalanceSnapshot(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.BalanceSnapshotRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse balance snapshot request: %v", err)
+	}
+	if req.ActorID == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+	snapshotDate, err := time.Parse(balanceSnapshotDateFormat, req.SnapshotDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid snapshotDate, expected YYYY-MM-DD: %w", err)
+	}
+	if err := checkSnapshotRole(stub); err != nil {
+		return nil, err
+	}
+
+	// Balances are fixed only once every transaction of the day has been recorded
+	cutoff := snapshotDate.AddDate(0, 0, 1)
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	if now.Before(cutoff) {
+		return nil, fmt.Errorf("snapshot for %s cannot run before its cutoff %s", req.SnapshotDate, cutoff.Format(time.RFC3339))
+	}
+
+	batchSize := req.BatchSize
+	if batchSize <= 0 || batchSize > config.MaxSnapshotBatchSize {
+		batchSize = config.MaxSnapshotBatchSize
+	}
+
+	snapshotKey := fmt.Sprintf("BALANCE_SNAPSHOT_%s", req.SnapshotDate)
+	snapshot := &domain.BalanceSnapshot{}
+	if err := h.persistenceService.Get(stub, snapshotKey, snapshot); err != nil {
+		snapshot = &domain.BalanceSnapshot{
+			SnapshotDate: req.SnapshotDate,
+			CutoffTime:   cutoff,
+			Status:       domain.BalanceSnapshotInProgress,
+			ByStatus:     map[string]domain.BalanceAggregate{},
+			ByLoanType:   map[string]domain.BalanceAggregate{},
+			StartedBy:    req.ActorID,
+			StartedDate:  now,
+		}
+	}
+	if snapshot.Status == domain.BalanceSnapshotCertified {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "balance snapshot for %s is already certified", req.SnapshotDate)
+	}
+
+	// Loan keys never change, so resuming after the checkpoint neither skips nor repeats a loan
+	startKey := loanKeyPrefix
+	if snapshot.Checkpoint != "" {
+		startKey = snapshot.Checkpoint + "\x00"
+	}
+	iterator, err := stub.GetStateByRange(startKey, loanKeyPrefix+string(utf8.MaxRune))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loans: %w", err)
+	}
+	defer iterator.Close()
+
+	read := 0
+	for read < batchSize && iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate loans: %w", err)
+		}
+		read++
+		snapshot.Checkpoint = response.Key
+
+		var loanApp domain.LoanApplication
+		if err := json.Unmarshal(response.Value, &loanApp); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal loan %s: %w", response.Key, err)
+		}
+		if err := h.certifyLoanBalance(stub, snapshot, &loanApp); err != nil {
+			return nil, err
+		}
+	}
+	snapshot.LoansScanned += read
+	snapshot.Batches++
+
+	if !iterator.HasNext() {
+		snapshot.Status = domain.BalanceSnapshotCertified
+		snapshot.Checkpoint = ""
+		snapshot.SnapshotHash = balanceSnapshotHash(snapshot)
+		snapshot.CertifiedBy = req.ActorID
+		snapshot.CertifiedDate = &now
+	}
+
+	if err := h.persistenceService.Put(stub, snapshotKey, snapshot); err != nil {
+		return nil, fmt.Errorf("failed to store balance snapshot: %w", err)
+	}
+
+	// Emit event
+	if snapshot.Status == domain.BalanceSnapshotCertified {
+		if err := h.eventService.EmitBalanceSnapshotCertified(stub, snapshot, req.ActorID); err != nil {
+			return nil, fmt.Errorf("failed to emit event: %w", err)
+		}
+	}
+
+	return json.Marshal(snapshot)
+}
+
+// GetBalanceSnapshot retrieves the snapshot record for a date, certified or in progress
+func (h *BalanceSnapshotHandler) GetBalanceSnapshot(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var snapshot domain.BalanceSnapshot
+	if err := h.persistenceService.Get(stub, fmt.Sprintf("BALANCE_SNAPSHOT_%s", args[0]), &snapshot); err != nil {
+		return nil, fmt.Errorf("balance snapshot not found: %w", err)
+	}
+
+	return json.Marshal(&snapshot)
+}
+
+// GetCertifiedBalances returns a page of the loan balances certified for a date, with the snapshot
+// header. Balances are only served once the whole snapshot is certified.
+// Args: snapshotDate (YYYY-MM-DD) [, pageSize [, bookmark]]
+func (h *BalanceSnapshotHandler) GetCertifiedBalances(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) < 1 || len(args) > 3 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1 to 3, got %d", len(args))
+	}
+
+	var snapshot domain.BalanceSnapshot
+	if err := h.persistenceService.Get(stub, fmt.Sprintf("BALANCE_SNAPSHOT_%s", args[0]), &snapshot); err != nil {
+		return nil, fmt.Errorf("balance snapshot not found: %w", err)
+	}
+	if snapshot.Status != domain.BalanceSnapshotCertified {
+		return nil, fmt.Errorf("balance snapshot for %s is not certified", args[0])
+	}
+
+	pageSize, bookmark, err := services.ParsePageArgs(args[1:])
+	if err != nil {
+		return nil, err
+	}
+	entries, nextBookmark, err := h.persistenceService.GetPageByPartialCompositeKeys(stub, "CERTIFIED_BALANCE", [][]string{{args[0]}}, pageSize, bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get certified balances: %w", err)
+	}
+
+	balances := []domain.CertifiedLoanBalance{}
+	for _, entry := range entries {
+		var balance domain.CertifiedLoanBalance
+		if err := json.Unmarshal(entry.Value, &balance); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal certified balance: %w", err)
+		}
+		balances = append(balances, balance)
+	}
+
+	return json.Marshal(&domain.CertifiedBalancesResult{
+		Snapshot: snapshot,
+		Balances: balances,
+		Count:    len(balances),
+		Bookmark: nextBookmark,
+	})
+}
+
+// certifyLoanBalance records a loan's balance at the cutoff and adds it to the snapshot totals and
+// hash chain. Sandbox loans and loans not funded by the cutoff are skipped.
+func (h *BalanceSnapshotHandler) certifyLoanBalance(stub shim.ChaincodeStubInterface, snapshot *domain.BalanceSnapshot, loanApp *domain.LoanApplication) error {
+	if loanApp.Sandbox || loanApp.DisbursementDate == nil || !loanApp.DisbursementDate.Before(snapshot.CutoffTime) {
+		return nil
+	}
+	switch loanApp.Status {
+	case validation.LoanStatusDisbursed, validation.LoanStatusDelinquent, validation.LoanStatusDefaulted:
+	default:
+		return nil
+	}
+
+	latest, recorded, err := lastLoanTransactionBefore(stub, loanApp.LoanID, snapshot.CutoffTime)
+	if err != nil {
+		return err
+	}
+	balance := &domain.CertifiedLoanBalance{
+		SnapshotDate: snapshot.SnapshotDate,
+		LoanID:       loanApp.LoanID,
+		CustomerID:   loanApp.CustomerID,
+		LoanType:     loanApp.LoanType,
+		Status:       loanApp.Status,
+	}
+	switch {
+	case !recorded:
+		balance.Balance = loanApp.OutstandingBalance.Float64()
+	case latest != nil:
+		balance.Balance = latest.BalanceAfter.Float64()
+		balance.LastTransactionID = latest.TransactionID
+	}
+	balance.EntryHash = certifiedBalanceHash(*balance)
+
+	balanceKey, err := stub.CreateCompositeKey("CERTIFIED_BALANCE", []string{snapshot.SnapshotDate, loanApp.LoanID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := h.persistenceService.Put(stub, balanceKey, balance); err != nil {
+		return fmt.Errorf("failed to store certified balance: %w", err)
+	}
+
+	snapshot.LoanCount++
+	snapshot.TotalBalance = roundToCents(snapshot.TotalBalance + balance.Balance)
+	snapshot.ByStatus[string(balance.Status)] = addToAggregate(snapshot.ByStatus[string(balance.Status)], balance.Balance)
+	snapshot.ByLoanType[balance.LoanType] = addToAggregate(snapshot.ByLoanType[balance.LoanType], balance.Balance)
+	snapshot.EntriesHash = utils.HashValue(snapshot.EntriesHash + "|" + balance.EntryHash)
+	return nil
+}
+
+func addToAggregate(aggregate domain.BalanceAggregate, balance float64) domain.BalanceAggregate {
+	aggregate.LoanCount++
+	aggregate.Balance = roundToCents(aggregate.Balance + balance)
+	return aggregate
+}
+
+// certifiedBalanceHash hashes a certified balance with its own hash field cleared
+func certifiedBalanceHash(balance domain.CertifiedLoanBalance) string {
+	balance.EntryHash = ""
+	balanceJSON, _ := json.Marshal(balance)
+	return utils.HashValue(string(balanceJSON))
+}
+
+// balanceSnapshotHash binds the snapshot date, cutoff and totals to the chain of entry hashes
+func balanceSnapshotHash(snapshot *domain.BalanceSnapshot) string {
+	parts := []string{
+		snapshot.SnapshotDate,
+		snapshot.CutoffTime.UTC().Format(time.RFC3339),
+		fmt.Sprintf("%d", snapshot.LoanCount),
+		fmt.Sprintf("%.2f", snapshot.TotalBalance),
+		snapshot.EntriesHash,
+	}
+	return utils.HashValue(strings.Join(parts, "|"))
+}
+
+// checkSnapshotRole checks the invoker may run the end-of-day snapshot
+func checkSnapshotRole(stub shim.ChaincodeStubInterface) error {
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return err
+	}
+	if role != string(validation.ActorRoleLoanOperationsManager) && role != string(validation.ActorRoleSystemAdministrator) {
+		return services.NewChaincodeError(services.ErrCodeAccessDenied, "", "balance snapshots may only be run by a %s or %s", validation.ActorRoleLoanOperationsManager, validation.ActorRoleSystemAdministrator)
+	}
+	return nil
+}
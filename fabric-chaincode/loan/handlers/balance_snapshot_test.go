@@ -0,0 +1,148 @@
+package handlers
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// snapshotClose is shortly after the end of 31 March 2026, when that day's snapshot may run
+var snapshotClose = time.Date(2026, 4, 1, 1, 0, 0, 0, time.UTC)
+
+// seedSnapshotBook stores five loans, two of them funded and live at the end of 31 March
+func seedSnapshotBook(t *testing.T, stub *shimtest.MockStub) {
+	t.Helper()
+	seedServicedLoan(t, stub, "LOAN_EOD1", "USD", 12000, 12)
+	postIncome(t, stub, "fee_march", "LOAN_EOD1", time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC), domain.LoanTransactionFee, 50)
+	postIncome(t, stub, "fee_after_cutoff", "LOAN_EOD1", time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC), domain.LoanTransactionFee, 25)
+	fundedOn := func(date time.Time) func(*domain.LoanApplication) {
+		return func(loanApp *domain.LoanApplication) {
+			loanApp.DisbursementDate = &date
+			loanApp.OutstandingBalance = utils.NewMoney(5000, "USD")
+		}
+	}
+	seedLoan(t, stub, "LOAN_EOD2", validation.LoanStatusDelinquent, fundedOn(time.Date(2025, 12, 1, 0, 0, 0, 0, time.UTC)), func(loanApp *domain.LoanApplication) {
+		loanApp.LoanType = "MORTGAGE"
+	})
+	seedLoan(t, stub, "LOAN_EOD3", validation.LoanStatusDisbursed, fundedOn(time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)))
+	seedLoan(t, stub, "LOAN_EOD4", validation.LoanStatusSubmitted)
+	seedLoan(t, stub, "LOAN_UAT", validation.LoanStatusDisbursed, fundedOn(scheduleStart), func(loanApp *domain.LoanApplication) { loanApp.Sandbox = true })
+}
+
+func runSnapshot(t *testing.T, stub *shimtest.MockStub, txID string, at time.Time, batchSize int) (*domain.BalanceSnapshot, error) {
+	payload, err := inTxAt(stub, txID, at, func() ([]byte, error) {
+		return NewBalanceSnapshotHandler().RunBalanceSnapshot(stub, []string{mustJSON(t, domain.BalanceSnapshotRequest{SnapshotDate: "2026-03-31", BatchSize: batchSize, ActorID: "ACTOR_005"})})
+	})
+	if err != nil {
+		return nil, err
+	}
+	var snapshot domain.BalanceSnapshot
+	if err := json.Unmarshal(payload, &snapshot); err != nil {
+		t.Fatalf("failed to decode snapshot: %v", err)
+	}
+	return &snapshot, nil
+}
+
+func TestBalanceSnapshotCertifiesInCheckpointedBatches(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	seedSnapshotBook(t, stub)
+	h := NewBalanceSnapshotHandler()
+	certifiedBalances := func(args ...string) (*domain.CertifiedBalancesResult, error) {
+		payload, err := inTx(stub, "certified", func() ([]byte, error) {
+			return h.GetCertifiedBalances(stub, args)
+		})
+		if err != nil {
+			return nil, err
+		}
+		var result domain.CertifiedBalancesResult
+		if err := json.Unmarshal(payload, &result); err != nil {
+			t.Fatalf("failed to decode certified balances: %v", err)
+		}
+		return &result, nil
+	}
+
+	if _, err := runSnapshot(t, stub, "early", snapshotClose.Add(-2*time.Hour), 2); err == nil || !strings.Contains(err.Error(), "before its cutoff") {
+		t.Errorf("expected a snapshot before the day closed to be refused, got %v", err)
+	}
+	stub.Creator = newTestIdentity(t, string(validation.ActorRoleUnderwriter))
+	_, err := runSnapshot(t, stub, "underwriter", snapshotClose, 2)
+	expectErrorCode(t, err, services.ErrCodeAccessDenied)
+	stub.Creator = newTestIdentity(t, string(validation.ActorRoleSystemAdministrator))
+
+	// Each batch resumes after the checkpoint; balances are not served until the last one
+	first, err := runSnapshot(t, stub, "batch_1", snapshotClose, 2)
+	if err != nil {
+		t.Fatalf("RunBalanceSnapshot failed: %v", err)
+	}
+	if first.Status != domain.BalanceSnapshotInProgress || first.Checkpoint != "LOAN_LOAN_EOD2" || first.LoanCount != 2 || first.SnapshotHash != "" {
+		t.Fatalf("expected the first two loans certified and a checkpoint, got %+v", first)
+	}
+	if _, err := certifiedBalances("2026-03-31"); err == nil || !strings.Contains(err.Error(), "is not certified") {
+		t.Errorf("expected balances withheld while the snapshot is in progress, got %v", err)
+	}
+	if second, err := runSnapshot(t, stub, "batch_2", snapshotClose.Add(time.Minute), 2); err != nil || second.Status != domain.BalanceSnapshotInProgress || second.Checkpoint != "LOAN_LOAN_EOD4" {
+		t.Fatalf("expected the second batch to checkpoint at LOAN_EOD4, got %+v (%v)", second, err)
+	}
+	snapshot, err := runSnapshot(t, stub, "batch_3", snapshotClose.Add(2*time.Minute), 2)
+	if err != nil || snapshot.Status != domain.BalanceSnapshotCertified {
+		t.Fatalf("expected the third batch to certify the snapshot, got %+v (%v)", snapshot, err)
+	}
+	if snapshot.Batches != 3 || snapshot.LoansScanned != 5 || snapshot.Checkpoint != "" || snapshot.SnapshotHash == "" {
+		t.Errorf("expected 5 loans read over 3 batches, got %+v", snapshot)
+	}
+	if snapshot.LoanCount != 2 || snapshot.TotalBalance != 17050 {
+		t.Errorf("expected 12050 + 5000 certified, got %d loans totalling %.2f", snapshot.LoanCount, snapshot.TotalBalance)
+	}
+	if delinquent := snapshot.ByStatus[string(validation.LoanStatusDelinquent)]; delinquent.LoanCount != 1 || delinquent.Balance != 5000 {
+		t.Errorf("expected the delinquent mortgage aggregated on its own, got %+v", snapshot.ByStatus)
+	}
+	if personal := snapshot.ByLoanType["PERSONAL"]; personal.LoanCount != 1 || personal.Balance != 12050 {
+		t.Errorf("expected the personal loan aggregated on its own, got %+v", snapshot.ByLoanType)
+	}
+	_, err = runSnapshot(t, stub, "rerun", snapshotClose.Add(time.Hour), 2)
+	expectErrorCode(t, err, services.ErrCodeInvalidTransition)
+
+	// Later postings do not move the certified figures
+	postIncome(t, stub, "fee_april", "LOAN_EOD2", time.Date(2026, 4, 2, 9, 0, 0, 0, time.UTC), domain.LoanTransactionFee, 40)
+	page, err := certifiedBalances("2026-03-31", "1")
+	if err != nil {
+		t.Fatalf("GetCertifiedBalances failed: %v", err)
+	}
+	rest, err := certifiedBalances("2026-03-31", "1", page.Bookmark)
+	if err != nil {
+		t.Fatalf("GetCertifiedBalances failed: %v", err)
+	}
+	if page.Snapshot.SnapshotHash != snapshot.SnapshotHash || page.Count != 1 || rest.Count != 1 || rest.Bookmark != "" {
+		t.Fatalf("expected one certified balance per page under the snapshot header, got %+v then %+v", page, rest)
+	}
+	eod1, eod2 := page.Balances[0], rest.Balances[0]
+	if eod1.LoanID != "LOAN_EOD1" || eod1.Balance != 12050 || eod1.LastTransactionID == "" {
+		t.Errorf("expected LOAN_EOD1 certified at 12050 after the March fee, got %+v", eod1)
+	}
+	if eod2.LoanID != "LOAN_EOD2" || eod2.Balance != 5000 || eod2.LastTransactionID != "" {
+		t.Errorf("expected LOAN_EOD2 certified at its stored 5000, got %+v", eod2)
+	}
+	for _, balance := range []domain.CertifiedLoanBalance{eod1, eod2} {
+		if certifiedBalanceHash(balance) != balance.EntryHash {
+			t.Errorf("expected %s's entry hash to match its contents", balance.LoanID)
+		}
+	}
+
+	// The same book certified in a single batch, at another time, yields the same hash
+	single := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	seedSnapshotBook(t, single)
+	once, err := runSnapshot(t, single, "single", snapshotClose.AddDate(0, 0, 3), 0)
+	if err != nil {
+		t.Fatalf("RunBalanceSnapshot failed: %v", err)
+	}
+	if once.Batches != 1 || once.Status != domain.BalanceSnapshotCertified || once.SnapshotHash != snapshot.SnapshotHash {
+		t.Errorf("expected a single batch to certify the same snapshot hash, got %+v", once)
+	}
+}
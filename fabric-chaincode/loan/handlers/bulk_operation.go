@@ -0,0 +1,437 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	loanServices "github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// BulkOperationHandler applies administrative operations to a segment of the loan book. Each call
+// works through one capped batch of the segment and may be run as a dry run first.
+type BulkOperationHandler struct {
+	persistenceService *services.PersistenceService
+	eventService       *loanServices.EventService
+	customerVerifier   *loanServices.CustomerVerificationService
+	identityService    *services.IdentityService
+	freezeService      *services.EntityFreezeService
+}
+
+// NewBulkOperationHandler creates a new bulk operation handler
+func NewBulkOperationHandler() *BulkOperationHandler {
+	return &BulkOperationHandler{
+		persistenceService: services.NewPersistenceService(),
+		eventService:       loanServices.NewEventService(),
+		customerVerifier:   loanServices.NewCustomerVerificationService(),
+		identityService:    services.NewIdentityService(),
+		freezeService:      services.NewEntityFreezeService(),
+	}
+}
+
+// complianceBulkOperations may act on frozen loans; every other bulk operation leaves them alone
+var complianceBulkOperations = map[string]bool{
+	domain.BulkOperationPlaceHold:   true,
+	domain.BulkOperationReleaseHold: true,
+}
+
+// bulkApply applies an operation to one loan of the segment, filling in its outcome. It reports
+// false for loans the operation does not concern.
+type bulkApply func(operation *domain.BulkLoanOperation, loanApp *domain.LoanApplication, outcome *domain.BulkLoanOutcome) (bool, error)
+
+// BulkPlaceComplianceHold places a compliance hold on the loans in a segment. Held loans may not
+// be approved, rejected, disbursed or change status until the hold is released.
+func (h *BulkOperationHandler) BulkPlaceComplianceHold(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	req, err := parseBulkLoanOperationRequest(args)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "reason", "reason is required")
+	}
+	if err := checkBulkRole(stub, "compliance holds", validation.ActorRoleComplianceOfficer, validation.ActorRoleLoanOperationsManager); err != nil {
+		return nil, err
+	}
+
+	return h.run(stub, domain.BulkOperationPlaceHold, "complianceHold", req, func(operation *domain.BulkLoanOperation, loanApp *domain.LoanApplication, outcome *domain.BulkLoanOutcome) (bool, error) {
+		if loanApp.ComplianceHold != nil {
+			outcome.Detail = fmt.Sprintf("already on hold: %s", loanApp.ComplianceHold.Reason)
+			return true, nil
+		}
+		loanApp.ComplianceHold = &domain.ComplianceHold{
+			Reason:      req.Reason,
+			PlacedBy:    req.ActorID,
+			PlacedDate:  operation.PerformedDate,
+			OperationID: operation.OperationID,
+		}
+		outcome.Changed = true
+		outcome.NewValue = req.Reason
+		return true, nil
+	})
+}
+
+// BulkReleaseComplianceHold releases the compliance holds on the loans in a segment
+func (h *BulkOperationHandler) BulkReleaseComplianceHold(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	req, err := parseBulkLoanOperationRequest(args)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkBulkRole(stub, "compliance holds", validation.ActorRoleComplianceOfficer, validation.ActorRoleLoanOperationsManager); err != nil {
+		return nil, err
+	}
+
+	return h.run(stub, domain.BulkOperationReleaseHold, "complianceHold", req, func(operation *domain.BulkLoanOperation, loanApp *domain.LoanApplication, outcome *domain.BulkLoanOutcome) (bool, error) {
+		if loanApp.ComplianceHold == nil {
+			outcome.Detail = "not on hold"
+			return true, nil
+		}
+		outcome.Changed = true
+		outcome.PreviousValue = loanApp.ComplianceHold.Reason
+		loanApp.ComplianceHold = nil
+		return true, nil
+	})
+}
+
+// BulkReassignLoanOwner moves the loans in a segment owned by one staff member, e.g. one who has
+// left, to another registered and active actor
+func (h *BulkOperationHandler) BulkReassignLoanOwner(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	req, err := parseBulkLoanOperationRequest(args)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.FromActorID) == "" || strings.TrimSpace(req.ToActorID) == "" {
+		return nil, fmt.Errorf("fromActorID and toActorID are required")
+	}
+	if req.FromActorID == req.ToActorID {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "fromActorID and toActorID must differ")
+	}
+	if err := checkBulkRole(stub, "loan owners", validation.ActorRoleLoanOperationsManager); err != nil {
+		return nil, err
+	}
+
+	newOwner, err := h.identityService.GetActor(stub, req.ToActorID)
+	if err != nil {
+		return nil, err
+	}
+	if !newOwner.Active {
+		return nil, fmt.Errorf("actor %s is inactive", req.ToActorID)
+	}
+
+	return h.run(stub, domain.BulkOperationReassignOwner, "ownerActorID", req, func(operation *domain.BulkLoanOperation, loanApp *domain.LoanApplication, outcome *domain.BulkLoanOutcome) (bool, error) {
+		if loanApp.Owner() != req.FromActorID {
+			return false, nil
+		}
+		outcome.Changed = true
+		outcome.PreviousValue = req.FromActorID
+		outcome.NewValue = req.ToActorID
+		loanApp.OwnerActorID = req.ToActorID
+		return true, nil
+	})
+}
+
+// BulkRevalidateEligibility re-checks every party of the undecided and undisbursed loans in a
+// segment against the customer chaincode, e.g. after an eligibility rule change, and records the
+// result on each loan. Ineligible loans are reported, not held; place holds separately.
+func (h *BulkOperationHandler) BulkRevalidateEligibility(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	req, err := parseBulkLoanOperationRequest(args)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkBulkRole(stub, "eligibility re-validations", validation.ActorRoleLoanOperationsManager); err != nil {
+		return nil, err
+	}
+
+	return h.run(stub, domain.BulkOperationRevalidateEligibility, "eligibility", req, func(operation *domain.BulkLoanOperation, loanApp *domain.LoanApplication, outcome *domain.BulkLoanOutcome) (bool, error) {
+		switch loanApp.Status {
+		case validation.LoanStatusRejected, validation.LoanStatusCancelled, validation.LoanStatusDisbursed, validation.LoanStatusDelinquent, validation.LoanStatusDefaulted:
+			return false, nil
+		}
+
+		customerIDs := []string{loanApp.CustomerID}
+		if len(loanApp.Parties) > 0 {
+			customerIDs = []string{}
+			for _, party := range loanApp.Parties {
+				customerIDs = append(customerIDs, party.CustomerID)
+			}
+		}
+
+		problems := []string{}
+		for _, customerID := range customerIDs {
+			_, partyProblems, err := h.customerVerifier.CheckEligibility(stub, customerID)
+			if err != nil {
+				return false, err
+			}
+			for _, problem := range partyProblems {
+				problems = append(problems, fmt.Sprintf("%s: %s", customerID, problem))
+			}
+		}
+
+		eligibility := &domain.LoanEligibility{
+			Eligible:    len(problems) == 0,
+			Problems:    problems,
+			CheckedDate: operation.PerformedDate,
+			CheckedBy:   operation.PerformedBy,
+		}
+		outcome.Changed = true
+		outcome.PreviousValue = eligibilityLabel(loanApp.Eligibility)
+		outcome.NewValue = eligibilityLabel(eligibility)
+		outcome.Detail = strings.Join(problems, "; ")
+		loanApp.Eligibility = eligibility
+		return true, nil
+	})
+}
+
+// GetBulkLoanOperation retrieves the record of an applied bulk operation batch
+func (h *BulkOperationHandler) GetBulkLoanOperation(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var operation domain.BulkLoanOperation
+	if err := h.persistenceService.Get(stub, fmt.Sprintf("BULK_LOAN_OPERATION_%s", args[0]), &operation); err != nil {
+		return nil, fmt.Errorf("bulk loan operation not found: %w", err)
+	}
+
+	return json.Marshal(&operation)
+}
+
+// Helper methods
+
+// run scans one batch of the request's segment and applies an operation to each loan in it.
+// Unless it is a dry run, changed loans are stored with a history entry on field, and the batch is
+// recorded and announced with a single event.
+func (h *BulkOperationHandler) run(stub shim.ChaincodeStubInterface, operationType, field string, req *domain.BulkLoanOperationRequest, apply bulkApply) ([]byte, error) {
+	segment, err := parseLoanSegment(req.Filter)
+	if err != nil {
+		return nil, err
+	}
+
+	batchSize := req.BatchSize
+	if batchSize <= 0 {
+		batchSize = config.MaxBulkBatchSize
+	}
+	if batchSize > config.MaxBulkBatchSize {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "batchSize %d exceeds maximum of %d", batchSize, config.MaxBulkBatchSize)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	operation := &domain.BulkLoanOperation{
+		Operation:     operationType,
+		Filter:        req.Filter,
+		DryRun:        req.DryRun,
+		Outcomes:      []domain.BulkLoanOutcome{},
+		PerformedBy:   req.ActorID,
+		PerformedDate: now,
+		TransactionID: stub.GetTxID(),
+	}
+	if !req.DryRun {
+		operation.OperationID = services.GenerateDeterministicID(stub, config.BulkLoanOperationPrefix)
+	}
+
+	objectType, partialKeys := segment.indexKeys()
+	entries, nextBookmark, err := h.persistenceService.GetPageByPartialCompositeKeys(stub, objectType, partialKeys, batchSize, req.Bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan loan book: %w", err)
+	}
+	operation.Scanned = len(entries)
+	operation.Bookmark = nextBookmark
+
+	for _, entry := range entries {
+		var loanApp domain.LoanApplication
+		if err := h.persistenceService.Get(stub, fmt.Sprintf("LOAN_%s", string(entry.Value)), &loanApp); err != nil {
+			continue // Skip if loan not found
+		}
+		if !segment.contains(&loanApp) {
+			continue
+		}
+
+		outcome := domain.BulkLoanOutcome{LoanID: loanApp.LoanID, Status: string(loanApp.Status)}
+		if !complianceBulkOperations[operationType] {
+			frozen, err := h.freezeService.IsFrozen(stub, services.FreezeEntityLoan, loanApp.LoanID)
+			if err != nil {
+				return nil, err
+			}
+			if frozen {
+				outcome.Detail = "loan is frozen"
+				operation.Matched++
+				operation.Outcomes = append(operation.Outcomes, outcome)
+				continue
+			}
+		}
+		matched, err := apply(operation, &loanApp, &outcome)
+		if err != nil {
+			return nil, fmt.Errorf("failed to apply %s to loan %s: %w", operationType, loanApp.LoanID, err)
+		}
+		if !matched {
+			continue
+		}
+		operation.Matched++
+		operation.Outcomes = append(operation.Outcomes, outcome)
+		if !outcome.Changed {
+			continue
+		}
+		operation.Changed++
+		if req.DryRun {
+			continue
+		}
+
+		loanApp.LastUpdated = now
+		loanApp.LastUpdatedBy = req.ActorID
+		if err := putLoanApplication(stub, h.persistenceService, &loanApp); err != nil {
+			return nil, fmt.Errorf("failed to update loan application: %w", err)
+		}
+		if err := recordLoanHistory(stub, h.persistenceService, loanApp.LoanID, operationType, field, outcome.PreviousValue, outcome.NewValue, req.ActorID); err != nil {
+			return nil, err
+		}
+	}
+
+	if req.DryRun {
+		return json.Marshal(operation)
+	}
+
+	if err := h.persistenceService.Put(stub, fmt.Sprintf("BULK_LOAN_OPERATION_%s", operation.OperationID), operation); err != nil {
+		return nil, fmt.Errorf("failed to store bulk loan operation: %w", err)
+	}
+	if err := h.eventService.EmitLoanBulkOperationApplied(stub, operation, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(operation)
+}
+
+// loanSegment is a validated loan book filter
+type loanSegment struct {
+	filter domain.LoanBookFilter
+	from   time.Time // Zero when the filter has no date range
+	to     time.Time
+}
+
+// parseLoanSegment validates a filter. Without a status it must have a date range no wider than
+// config.MaxQueryRangeDays.
+func parseLoanSegment(filter domain.LoanBookFilter) (*loanSegment, error) {
+	segment := &loanSegment{filter: filter}
+
+	if filter.LoanType != "" {
+		if err := validation.ValidateLoanType(filter.LoanType); err != nil {
+			return nil, fmt.Errorf("invalid loan type: %w", err)
+		}
+	}
+	if filter.Status != "" {
+		if err := validation.ValidateLoanApplicationStatus(filter.Status); err != nil {
+			return nil, fmt.Errorf("invalid loan status: %w", err)
+		}
+	}
+
+	if (filter.FromDate == "") != (filter.ToDate == "") {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "fromDate and toDate must be given together")
+	}
+	if filter.FromDate != "" {
+		from, err := time.Parse(loanIndexDateFormat, filter.FromDate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid from date %s: %w", filter.FromDate, err)
+		}
+		to, err := time.Parse(loanIndexDateFormat, filter.ToDate)
+		if err != nil {
+			return nil, fmt.Errorf("invalid to date %s: %w", filter.ToDate, err)
+		}
+		if to.Before(from) {
+			return nil, fmt.Errorf("to date %s is before from date %s", filter.ToDate, filter.FromDate)
+		}
+		if days := int(to.Sub(from).Hours()/24) + 1; days > config.MaxQueryRangeDays {
+			return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "date range of %d days exceeds maximum of %d", days, config.MaxQueryRangeDays)
+		}
+		segment.from, segment.to = from, to
+	} else if filter.Status == "" {
+		return nil, fmt.Errorf("filter requires a status or a date range")
+	}
+
+	return segment, nil
+}
+
+// indexKeys returns the index a segment is scanned through: LOAN_BY_STATUS when it has a
+// status, otherwise one LOAN_BY_DATE day bucket per day of its range
+func (s *loanSegment) indexKeys() (string, [][]string) {
+	if s.filter.Status != "" {
+		return "LOAN_BY_STATUS", [][]string{{s.filter.Status}}
+	}
+
+	var days [][]string
+	for day := s.from; !day.After(s.to); day = day.AddDate(0, 0, 1) {
+		days = append(days, []string{day.Format(loanIndexDateFormat)})
+	}
+	return "LOAN_BY_DATE", days
+}
+
+// contains reports whether a loan is in the segment. Sandbox loans are never included.
+func (s *loanSegment) contains(loanApp *domain.LoanApplication) bool {
+	if loanApp.Sandbox {
+		return false
+	}
+	if s.filter.Status != "" && string(loanApp.Status) != s.filter.Status {
+		return false
+	}
+	if s.filter.LoanType != "" && loanApp.LoanType != s.filter.LoanType {
+		return false
+	}
+	if !s.from.IsZero() {
+		applied := loanApp.ApplicationDate.UTC()
+		if applied.Before(s.from) || !applied.Before(s.to.AddDate(0, 0, 1)) {
+			return false
+		}
+	}
+	return true
+}
+
+func parseBulkLoanOperationRequest(args []string) (*domain.BulkLoanOperationRequest, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.BulkLoanOperationRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse bulk loan operation request: %v", err)
+	}
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+	return &req, nil
+}
+
+// checkBulkRole rejects invokers without one of the roles allowed to run an operation, dry runs
+// included
+func checkBulkRole(stub shim.ChaincodeStubInterface, subject string, roles ...validation.ActorRole) error {
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, len(roles))
+	for i, allowed := range roles {
+		if role == string(allowed) {
+			return nil
+		}
+		names[i] = string(allowed)
+	}
+	return services.NewChaincodeError(services.ErrCodeAccessDenied, "", "bulk changes to %s may only be made by a %s", subject, strings.Join(names, " or "))
+}
+
+func eligibilityLabel(eligibility *domain.LoanEligibility) string {
+	switch {
+	case eligibility == nil:
+		return ""
+	case eligibility.Eligible:
+		return "ELIGIBLE"
+	default:
+		return "INELIGIBLE"
+	}
+}
@@ -0,0 +1,198 @@
+package handlers
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// bulk runs one batch of a bulk loan operation and decodes its record
+func bulk(t *testing.T, stub *shimtest.MockStub, txID string, operation func(shim.ChaincodeStubInterface, []string) ([]byte, error), req domain.BulkLoanOperationRequest) (*domain.BulkLoanOperation, error) {
+	if req.ActorID == "" {
+		req.ActorID = "ACTOR_005"
+	}
+	payload, err := inTx(stub, txID, func() ([]byte, error) {
+		return operation(stub, []string{mustJSON(t, req)})
+	})
+	if err != nil {
+		return nil, err
+	}
+	var result domain.BulkLoanOperation
+	if err := json.Unmarshal(payload, &result); err != nil {
+		t.Fatalf("failed to decode bulk operation: %v", err)
+	}
+	return &result, nil
+}
+
+func TestBulkComplianceHoldPreviewsThenAppliesInBatches(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	for _, loanID := range []string{"LOAN_B1", "LOAN_B2", "LOAN_B3"} {
+		seedLoan(t, stub, loanID, validation.LoanStatusSubmitted)
+	}
+	seedLoan(t, stub, "LOAN_B4", validation.LoanStatusSubmitted, func(loanApp *domain.LoanApplication) { loanApp.LoanType = "MORTGAGE" })
+	seedLoan(t, stub, "LOAN_B5", validation.LoanStatusUnderwriting)
+	seedLoan(t, stub, "LOAN_UAT", validation.LoanStatusSubmitted, func(loanApp *domain.LoanApplication) { loanApp.Sandbox = true })
+	h := NewBulkOperationHandler()
+	personal := domain.LoanBookFilter{Status: string(validation.LoanStatusSubmitted), LoanType: "PERSONAL"}
+
+	if _, err := bulk(t, stub, "no_reason", h.BulkPlaceComplianceHold, domain.BulkLoanOperationRequest{Filter: personal}); err == nil {
+		t.Errorf("expected a hold without a reason to be refused")
+	}
+	if _, err := bulk(t, stub, "no_segment", h.BulkPlaceComplianceHold, domain.BulkLoanOperationRequest{Filter: domain.LoanBookFilter{LoanType: "PERSONAL"}, Reason: "Sanctions list update"}); err == nil {
+		t.Errorf("expected a filter without a status or date range to be refused")
+	}
+	_, err := bulk(t, stub, "oversized", h.BulkPlaceComplianceHold, domain.BulkLoanOperationRequest{Filter: personal, Reason: "Sanctions list update", BatchSize: 51})
+	expectErrorCode(t, err, services.ErrCodeInvalidArgument)
+
+	// A dry run reports the production PERSONAL loans it would hold and writes nothing
+	preview, err := bulk(t, stub, "preview", h.BulkPlaceComplianceHold, domain.BulkLoanOperationRequest{Filter: personal, Reason: "Sanctions list update", DryRun: true})
+	if err != nil {
+		t.Fatalf("BulkPlaceComplianceHold failed: %v", err)
+	}
+	if preview.OperationID != "" || preview.Matched != 3 || preview.Changed != 3 || preview.Bookmark != "" {
+		t.Fatalf("expected an unrecorded preview holding 3 loans, got %+v", preview)
+	}
+	if loanApp := getLoan(t, stub, "LOAN_B1"); loanApp.ComplianceHold != nil {
+		t.Fatalf("expected the dry run to leave LOAN_B1 unheld, got %+v", loanApp.ComplianceHold)
+	}
+
+	// Applied in batches of two, the bookmark carries on where the first batch stopped
+	first, err := bulk(t, stub, "hold_1", h.BulkPlaceComplianceHold, domain.BulkLoanOperationRequest{Filter: personal, Reason: "Sanctions list update", BatchSize: 2})
+	if err != nil {
+		t.Fatalf("BulkPlaceComplianceHold failed: %v", err)
+	}
+	if first.OperationID == "" || first.Scanned != 2 || first.Changed != 2 || first.Bookmark == "" {
+		t.Fatalf("expected a recorded first batch of 2 with a bookmark, got %+v", first)
+	}
+	rest, err := bulk(t, stub, "hold_2", h.BulkPlaceComplianceHold, domain.BulkLoanOperationRequest{Filter: personal, Reason: "Sanctions list update", BatchSize: 2, Bookmark: first.Bookmark})
+	if err != nil {
+		t.Fatalf("BulkPlaceComplianceHold failed: %v", err)
+	}
+	if rest.Scanned != 2 || rest.Changed != 1 || rest.Outcomes[0].LoanID != "LOAN_B3" {
+		t.Errorf("expected LOAN_B3 held and the mortgage skipped in the second batch, got %+v", rest)
+	}
+	last, err := bulk(t, stub, "hold_3", h.BulkPlaceComplianceHold, domain.BulkLoanOperationRequest{Filter: personal, Reason: "Sanctions list update", BatchSize: 2, Bookmark: rest.Bookmark})
+	if err != nil || last.Scanned != 1 || last.Matched != 0 || last.Bookmark != "" {
+		t.Errorf("expected the sandbox loan scanned but not held in the last batch, got %+v (%v)", last, err)
+	}
+	for loanID, held := range map[string]bool{"LOAN_B1": true, "LOAN_B3": true, "LOAN_B4": false, "LOAN_B5": false, "LOAN_UAT": false} {
+		if loanApp := getLoan(t, stub, loanID); (loanApp.ComplianceHold != nil) != held {
+			t.Errorf("expected %s held=%t, got %+v", loanID, held, loanApp.ComplianceHold)
+		}
+	}
+	if hold := getLoan(t, stub, "LOAN_B2").ComplianceHold; hold.OperationID != first.OperationID || hold.Reason != "Sanctions list update" {
+		t.Errorf("expected LOAN_B2's hold to name the first batch, got %+v", hold)
+	}
+
+	// A held loan cannot progress, and holding it again changes nothing
+	_, err = inTx(stub, "progress", func() ([]byte, error) {
+		return NewLoanApplicationHandler().UpdateLoanStatus(stub, []string{mustJSON(t, domain.LoanStatusUpdateRequest{LoanID: "LOAN_B1", NewStatus: validation.LoanStatusUnderwriting, ActorID: "ACTOR_005"})})
+	})
+	if err == nil || !strings.Contains(err.Error(), "on compliance hold") {
+		t.Errorf("expected a held loan's status change to be refused, got %v", err)
+	}
+	again, err := bulk(t, stub, "hold_again", h.BulkPlaceComplianceHold, domain.BulkLoanOperationRequest{Filter: personal, Reason: "Second list update"})
+	if err != nil || again.Matched != 3 || again.Changed != 0 || !strings.HasPrefix(again.Outcomes[0].Detail, "already on hold") {
+		t.Errorf("expected every loan reported as already held, got %+v (%v)", again, err)
+	}
+
+	// Underwriters may not place holds; releasing clears every hold in the segment
+	stub.Creator = newTestIdentity(t, string(validation.ActorRoleUnderwriter))
+	_, err = bulk(t, stub, "underwriter", h.BulkReleaseComplianceHold, domain.BulkLoanOperationRequest{Filter: personal, DryRun: true})
+	expectErrorCode(t, err, services.ErrCodeAccessDenied)
+	stub.Creator = newTestIdentity(t, string(validation.ActorRoleComplianceOfficer))
+	released, err := bulk(t, stub, "release", h.BulkReleaseComplianceHold, domain.BulkLoanOperationRequest{Filter: personal})
+	if err != nil || released.Changed != 3 || released.Outcomes[0].PreviousValue != "Sanctions list update" {
+		t.Fatalf("expected all 3 holds released, got %+v (%v)", released, err)
+	}
+	if loanApp := getLoan(t, stub, "LOAN_B1"); loanApp.ComplianceHold != nil {
+		t.Errorf("expected LOAN_B1 released, got %+v", loanApp.ComplianceHold)
+	}
+
+	payload, err := inTx(stub, "record", func() ([]byte, error) {
+		return h.GetBulkLoanOperation(stub, []string{first.OperationID})
+	})
+	var recorded domain.BulkLoanOperation
+	if err != nil || json.Unmarshal(payload, &recorded) != nil || recorded.Operation != domain.BulkOperationPlaceHold || recorded.Changed != 2 {
+		t.Errorf("expected the first batch recorded, got %s (%v)", payload, err)
+	}
+}
+
+func TestBulkReassignOwnerAndRevalidateEligibility(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	lapsed := eligibleCustomer("CUST_002", 10)
+	lapsed.KYCStatus = string(validation.KYCStatusPending)
+	withCustomers(stub, eligibleCustomer("CUST_001", 10), lapsed)
+	for _, actor := range []*services.ActorIdentity{
+		{ActorID: "ACTOR_NEW", Role: string(validation.ActorRoleUnderwriter), Active: true},
+		{ActorID: "ACTOR_GONE", Role: string(validation.ActorRoleUnderwriter), Active: false},
+	} {
+		if _, err := inTx(stub, "actor_"+actor.ActorID, func() ([]byte, error) {
+			return nil, services.NewIdentityService().PutActor(stub, actor)
+		}); err != nil {
+			t.Fatalf("failed to register %s: %v", actor.ActorID, err)
+		}
+	}
+	ownedBy := func(ownerActorID, createdBy string) func(*domain.LoanApplication) {
+		return func(loanApp *domain.LoanApplication) {
+			loanApp.OwnerActorID = ownerActorID
+			loanApp.CreatedBy = createdBy
+		}
+	}
+	seedLoan(t, stub, "LOAN_R1", validation.LoanStatusSubmitted, ownedBy("ACTOR_LEAVER", "ACTOR_001"))
+	seedLoan(t, stub, "LOAN_R2", validation.LoanStatusUnderwriting, ownedBy("", "ACTOR_LEAVER"), func(loanApp *domain.LoanApplication) { loanApp.CustomerID = "CUST_002" })
+	seedLoan(t, stub, "LOAN_R3", validation.LoanStatusSubmitted, ownedBy("ACTOR_STAYS", "ACTOR_LEAVER"))
+	seedLoan(t, stub, "LOAN_R4", validation.LoanStatusDisbursed, ownedBy("ACTOR_LEAVER", "ACTOR_001"), func(loanApp *domain.LoanApplication) { loanApp.CustomerID = "CUST_002" })
+	h := NewBulkOperationHandler()
+	applied := domain.LoanBookFilter{FromDate: "2026-01-05", ToDate: "2026-01-05"}
+
+	reassign := domain.BulkLoanOperationRequest{Filter: applied, FromActorID: "ACTOR_LEAVER", ToActorID: "ACTOR_GONE"}
+	if _, err := bulk(t, stub, "inactive", h.BulkReassignLoanOwner, reassign); err == nil || !strings.Contains(err.Error(), "is inactive") {
+		t.Errorf("expected reassignment to an inactive actor to be refused, got %v", err)
+	}
+	reassign.ToActorID = "ACTOR_UNKNOWN"
+	_, err := bulk(t, stub, "unregistered", h.BulkReassignLoanOwner, reassign)
+	expectErrorCode(t, err, services.ErrCodeNotFound)
+
+	// Loans the leaver owns, including by having submitted them, move across whatever their status
+	reassign.ToActorID = "ACTOR_NEW"
+	moved, err := bulk(t, stub, "reassign", h.BulkReassignLoanOwner, reassign)
+	if err != nil {
+		t.Fatalf("BulkReassignLoanOwner failed: %v", err)
+	}
+	if moved.Scanned != 4 || moved.Changed != 3 {
+		t.Fatalf("expected 3 of the 4 loans reassigned, got %+v", moved)
+	}
+	for loanID, owner := range map[string]string{"LOAN_R1": "ACTOR_NEW", "LOAN_R2": "ACTOR_NEW", "LOAN_R3": "ACTOR_STAYS", "LOAN_R4": "ACTOR_NEW"} {
+		if loanApp := getLoan(t, stub, loanID); loanApp.Owner() != owner {
+			t.Errorf("expected %s owned by %s, got %s", loanID, owner, loanApp.Owner())
+		}
+	}
+
+	// Only undisbursed loans are re-validated; each party is checked against the customer chaincode
+	checked, err := bulk(t, stub, "revalidate", h.BulkRevalidateEligibility, domain.BulkLoanOperationRequest{Filter: applied})
+	if err != nil {
+		t.Fatalf("BulkRevalidateEligibility failed: %v", err)
+	}
+	if checked.Matched != 3 || checked.Changed != 3 {
+		t.Fatalf("expected the 3 undisbursed loans re-validated, got %+v", checked)
+	}
+	if eligibility := getLoan(t, stub, "LOAN_R1").Eligibility; eligibility == nil || !eligibility.Eligible || eligibility.CheckedBy != "ACTOR_005" {
+		t.Errorf("expected LOAN_R1 eligible, got %+v", eligibility)
+	}
+	if eligibility := getLoan(t, stub, "LOAN_R2").Eligibility; eligibility == nil || eligibility.Eligible || len(eligibility.Problems) != 1 || !strings.HasPrefix(eligibility.Problems[0], "CUST_002: KYC status") {
+		t.Errorf("expected LOAN_R2 ineligible on CUST_002's KYC, got %+v", eligibility)
+	}
+	if loanApp := getLoan(t, stub, "LOAN_R2"); loanApp.ComplianceHold != nil {
+		t.Errorf("expected an ineligible loan to be reported, not held")
+	}
+	if getLoan(t, stub, "LOAN_R4").Eligibility != nil {
+		t.Errorf("expected the disbursed loan left alone")
+	}
+}
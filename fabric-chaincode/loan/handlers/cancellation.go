@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// cancellationRoles lists the roles that may record a cancellation for each initiator. Customers
+// do not hold ledger identities, so their withdrawals are recorded by the staff they instructed.
+var cancellationRoles = map[string][]validation.ActorRole{
+	domain.CancellationByCustomer: {
+		validation.ActorRoleCustomerServiceRep,
+		validation.ActorRoleUnderwriter,
+		validation.ActorRoleCreditOfficer,
+		validation.ActorRoleLoanOperationsManager,
+	},
+	domain.CancellationByIntroducer: {
+		validation.ActorRoleIntroducer,
+	},
+	domain.CancellationByBank: {
+		validation.ActorRoleUnderwriter,
+		validation.ActorRoleCreditOfficer,
+		validation.ActorRoleLoanOperationsManager,
+		validation.ActorRoleComplianceOfficer,
+	},
+}
+
+// CancelApplication cancels an application at the request of the customer, the introducer that
+// submitted it or the bank. Only applications whose funds have not been released may be cancelled.
+func (h *LoanApplicationHandler) CancelApplication(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.LoanCancellationRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse cancellation request: %v", err)
+	}
+	if err := validateCancellationRequest(&req); err != nil {
+		return nil, err
+	}
+	if err := checkCancellationRole(stub, req.InitiatedBy); err != nil {
+		return nil, err
+	}
+
+	// Get existing loan application
+	loanKey := fmt.Sprintf("LOAN_%s", req.LoanID)
+	var loanApp domain.LoanApplication
+	if err := h.persistenceService.Get(stub, loanKey, &loanApp); err != nil {
+		return nil, fmt.Errorf("loan application not found: %w", err)
+	}
+	if err := services.CheckExpectedVersion("LoanApplication", req.LoanID, req.ExpectedVersion, loanApp.Version); err != nil {
+		return nil, err
+	}
+
+	// Introducers may only withdraw applications they submitted
+	if req.InitiatedBy == domain.CancellationByIntroducer && req.ActorID != loanApp.CreatedBy {
+		return nil, fmt.Errorf("loan %s was not submitted by introducer %s", req.LoanID, req.ActorID)
+	}
+
+	// A hold stops withdrawals so a loan under investigation is not walked away from; the bank
+	// may still cancel it
+	if req.InitiatedBy != domain.CancellationByBank {
+		if err := checkComplianceHold(&loanApp); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := validation.ValidateStatusTransition(string(loanApp.Status), string(validation.LoanStatusCancelled), "LoanApplication"); err != nil {
+		return nil, fmt.Errorf("invalid status transition: %w", err)
+	}
+
+	// Funds released in any tranche mean the loan must be repaid, not cancelled
+	if loanApp.DisbursedAmount.IsPositive() {
+		return nil, fmt.Errorf("loan %s has disbursed %s and cannot be cancelled", req.LoanID, loanApp.DisbursedAmount)
+	}
+	disbursements, err := getLoanDisbursements(stub, req.LoanID)
+	if err != nil {
+		return nil, err
+	}
+	if len(disbursements) > 0 {
+		return nil, fmt.Errorf("loan %s has %d disbursement(s) recorded and cannot be cancelled", req.LoanID, len(disbursements))
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	// Update loan application with cancellation details
+	previousStatus := loanApp.Status
+	loanApp.Status = validation.LoanStatusCancelled
+	loanApp.Cancellation = &domain.LoanCancellation{
+		InitiatedBy:    req.InitiatedBy,
+		ReasonCode:     req.ReasonCode,
+		Notes:          req.Notes,
+		PreviousStatus: string(previousStatus),
+		CancelledBy:    req.ActorID,
+		CancelledDate:  now,
+	}
+	loanApp.DecisionDate = &now
+	loanApp.Notes = fmt.Sprintf("Cancelled by %s: %s", strings.ToLower(req.InitiatedBy), req.ReasonCode)
+	loanApp.LastUpdated = now
+	loanApp.LastUpdatedBy = req.ActorID
+
+	// Store updated loan application
+	if err := putLoanApplication(stub, h.persistenceService, &loanApp); err != nil {
+		return nil, fmt.Errorf("failed to update loan application: %w", err)
+	}
+
+	// Record history
+	if err := recordLoanHistory(stub, h.persistenceService, req.LoanID, "CANCELLATION", "status", string(previousStatus), string(validation.LoanStatusCancelled), req.ActorID); err != nil {
+		return nil, err
+	}
+
+	// Emit event
+	if err := h.eventService.EmitLoanApplicationCancelled(stub, &loanApp, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(&loanApp)
+}
+
+// validateCancellationRequest checks the initiator and that the reason code is one the initiator may give
+func validateCancellationRequest(req *domain.LoanCancellationRequest) error {
+	if req.LoanID == "" {
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "loanID", "loanID is required")
+	}
+	if req.ActorID == "" {
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	reasons, ok := domain.CancellationReasons[req.InitiatedBy]
+	if !ok {
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "initiatedBy must be one of %s, %s or %s", domain.CancellationByCustomer, domain.CancellationByIntroducer, domain.CancellationByBank)
+	}
+	for _, reason := range reasons {
+		if req.ReasonCode != reason {
+			continue
+		}
+		if reason == domain.CancellationReasonOther && strings.TrimSpace(req.Notes) == "" {
+			return fmt.Errorf("notes are required for reason %s", domain.CancellationReasonOther)
+		}
+		return nil
+	}
+	return fmt.Errorf("reason %q is not valid for a cancellation by %s; expected one of %s", req.ReasonCode, req.InitiatedBy, strings.Join(reasons, ", "))
+}
+
+// checkCancellationRole checks the invoker may record a cancellation for the initiator
+func checkCancellationRole(stub shim.ChaincodeStubInterface, initiatedBy string) error {
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return err
+	}
+
+	roles := cancellationRoles[initiatedBy]
+	names := make([]string, len(roles))
+	for i, allowed := range roles {
+		if role == string(allowed) {
+			return nil
+		}
+		names[i] = string(allowed)
+	}
+	return services.NewChaincodeError(services.ErrCodeAccessDenied, "", "cancellations by %s may only be recorded by a %s", strings.ToLower(initiatedBy), strings.Join(names, " or "))
+}
@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+func TestCancelApplicationByInitiator(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleCustomerServiceRep))
+	h := NewLoanApplicationHandler()
+	introduced := func(loanApp *domain.LoanApplication) { loanApp.CreatedBy = "INTRO_001" }
+	seedLoan(t, stub, "LOAN_C1", validation.LoanStatusUnderwriting)
+	seedLoan(t, stub, "LOAN_C2", validation.LoanStatusSubmitted, introduced)
+	seedLoan(t, stub, "LOAN_C3", validation.LoanStatusCreditApproval, func(loanApp *domain.LoanApplication) {
+		loanApp.ComplianceHold = &domain.ComplianceHold{Reason: "Suspected identity fraud", PlacedBy: "ACTOR_004"}
+	})
+	cancel := func(txID, loanID, initiatedBy, reasonCode, actorID string) (*domain.LoanApplication, error) {
+		payload, err := inTx(stub, txID, func() ([]byte, error) {
+			return h.CancelApplication(stub, []string{mustJSON(t, domain.LoanCancellationRequest{LoanID: loanID, InitiatedBy: initiatedBy, ReasonCode: reasonCode, ActorID: actorID})})
+		})
+		if err != nil {
+			return nil, err
+		}
+		var loanApp domain.LoanApplication
+		if err := json.Unmarshal(payload, &loanApp); err != nil {
+			t.Fatalf("failed to decode loan: %v", err)
+		}
+		return &loanApp, nil
+	}
+
+	_, err := cancel("unknown_initiator", "LOAN_C1", "BROKER", "CLIENT_WITHDREW", "ACTOR_003")
+	expectErrorCode(t, err, services.ErrCodeInvalidArgument)
+	if _, err := cancel("wrong_reason", "LOAN_C1", domain.CancellationByCustomer, "SUSPECTED_FRAUD", "ACTOR_003"); err == nil || !strings.Contains(err.Error(), "is not valid for a cancellation by CUSTOMER") {
+		t.Errorf("expected a bank reason on a customer withdrawal to be refused, got %v", err)
+	}
+	if _, err := cancel("unexplained", "LOAN_C1", domain.CancellationByCustomer, domain.CancellationReasonOther, "ACTOR_003"); err == nil {
+		t.Errorf("expected reason OTHER without notes to be refused")
+	}
+
+	// Staff record a customer's withdrawal, keeping the status it was withdrawn from
+	withdrawn, err := cancel("customer", "LOAN_C1", domain.CancellationByCustomer, "FOUND_ALTERNATIVE_FINANCE", "ACTOR_003")
+	if err != nil {
+		t.Fatalf("CancelApplication failed: %v", err)
+	}
+	if withdrawn.Status != validation.LoanStatusCancelled || withdrawn.DecisionDate == nil || withdrawn.Cancellation == nil ||
+		withdrawn.Cancellation.PreviousStatus != string(validation.LoanStatusUnderwriting) || withdrawn.Cancellation.ReasonCode != "FOUND_ALTERNATIVE_FINANCE" {
+		t.Errorf("expected LOAN_C1 cancelled from UNDERWRITING, got %+v", withdrawn)
+	}
+	payload, err := inTx(stub, "history", func() ([]byte, error) {
+		return h.GetLoanHistory(stub, []string{"LOAN_C1"})
+	})
+	var history []map[string]interface{}
+	if err != nil || json.Unmarshal(payload, &history) != nil || len(history) != 1 || history[0]["changeType"] != "CANCELLATION" || history[0]["newValue"] != string(validation.LoanStatusCancelled) {
+		t.Errorf("expected a CANCELLATION history entry, got %s (%v)", payload, err)
+	}
+	if _, err := cancel("customer_again", "LOAN_C1", domain.CancellationByCustomer, "NO_LONGER_REQUIRED", "ACTOR_003"); err == nil {
+		t.Errorf("expected a cancelled application not to be cancelled again")
+	}
+
+	// Only the introducer that submitted an application may withdraw it
+	_, err = cancel("introducer_as_staff", "LOAN_C2", domain.CancellationByIntroducer, "CLIENT_WITHDREW", "INTRO_001")
+	expectErrorCode(t, err, services.ErrCodeAccessDenied)
+	stub.Creator = newTestIdentity(t, string(validation.ActorRoleIntroducer))
+	if _, err := cancel("other_introducer", "LOAN_C2", domain.CancellationByIntroducer, "CLIENT_WITHDREW", "INTRO_002"); err == nil || !strings.Contains(err.Error(), "was not submitted by introducer INTRO_002") {
+		t.Errorf("expected another introducer to be refused, got %v", err)
+	}
+	_, err = cancel("introducer_as_bank", "LOAN_C2", domain.CancellationByBank, "OFFER_EXPIRED", "INTRO_001")
+	expectErrorCode(t, err, services.ErrCodeAccessDenied)
+	if withdrawn, err := cancel("introducer", "LOAN_C2", domain.CancellationByIntroducer, "CLIENT_WITHDREW", "INTRO_001"); err != nil || withdrawn.Cancellation.InitiatedBy != domain.CancellationByIntroducer {
+		t.Errorf("expected the introducer's withdrawal recorded, got %+v (%v)", withdrawn, err)
+	}
+
+	// A compliance hold stops a withdrawal but not the bank's own cancellation
+	stub.Creator = newTestIdentity(t, string(validation.ActorRoleCustomerServiceRep))
+	if _, err := cancel("held", "LOAN_C3", domain.CancellationByCustomer, "NO_LONGER_REQUIRED", "ACTOR_003"); err == nil || !strings.Contains(err.Error(), "on compliance hold") {
+		t.Errorf("expected a held loan's withdrawal to be refused, got %v", err)
+	}
+	stub.Creator = newTestIdentity(t, string(validation.ActorRoleComplianceOfficer))
+	if cancelled, err := cancel("bank", "LOAN_C3", domain.CancellationByBank, "SUSPECTED_FRAUD", "ACTOR_004"); err != nil || cancelled.Status != validation.LoanStatusCancelled {
+		t.Errorf("expected the bank to cancel the held loan, got %+v (%v)", cancelled, err)
+	}
+}
+
+func TestCancelApplicationRefusesFundedLoans(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	seedLoan(t, stub, "LOAN_F1", validation.LoanStatusApproved, approvedTerms(12000, 12), func(loanApp *domain.LoanApplication) {
+		loanApp.DisbursedAmount = utils.NewMoney(4000, "USD")
+	})
+	seedLoan(t, stub, "LOAN_F2", validation.LoanStatusDisbursed, approvedTerms(12000, 12))
+	cancel := func(txID, loanID string) error {
+		_, err := inTx(stub, txID, func() ([]byte, error) {
+			return NewLoanApplicationHandler().CancelApplication(stub, []string{mustJSON(t, domain.LoanCancellationRequest{
+				LoanID: loanID, InitiatedBy: domain.CancellationByBank, ReasonCode: "OFFER_EXPIRED", ActorID: "ACTOR_005",
+			})})
+		})
+		return err
+	}
+
+	// A first tranche released on an approved loan is enough to refuse cancellation
+	if err := cancel("tranche", "LOAN_F1"); err == nil || !strings.Contains(err.Error(), "cannot be cancelled") {
+		t.Errorf("expected a partly disbursed loan to be refused, got %v", err)
+	}
+	if err := cancel("disbursed", "LOAN_F2"); err == nil || !strings.Contains(err.Error(), "invalid status transition") {
+		t.Errorf("expected a disbursed loan to be refused, got %v", err)
+	}
+	if loanApp := getLoan(t, stub, "LOAN_F1"); loanApp.Status != validation.LoanStatusApproved || loanApp.Cancellation != nil {
+		t.Errorf("expected LOAN_F1 left approved, got %s", loanApp.Status)
+	}
+}
@@ -0,0 +1,354 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	loanServices "github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// CollateralHandler handles collateral pledged against loan applications
+type CollateralHandler struct {
+	persistenceService *services.PersistenceService
+	eventService      *loanServices.EventService
+}
+
+// NewCollateralHandler creates a new collateral handler
+func NewCollateralHandler() *CollateralHandler {
+	return &CollateralHandler{
+		persistenceService: services.NewPersistenceService(),
+		eventService:      loanServices.NewEventService(),
+	}
+}
+
+// AddCollateral pledges an appraised asset against a loan application
+func (h *CollateralHandler) AddCollateral(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.CollateralRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse collateral request: %v", err)
+	}
+
+	if err := validation.ValidateCollateralType(req.CollateralType); err != nil {
+		return nil, fmt.Errorf("invalid collateral type: %w", err)
+	}
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateCollateralValuation(req.Valuation, req.ValuationDate, req.AppraiserID, now); err != nil {
+		return nil, err
+	}
+
+	// Get existing loan application
+	var loanApp domain.LoanApplication
+	if err := h.persistenceService.Get(stub, fmt.Sprintf("LOAN_%s", req.LoanID), &loanApp); err != nil {
+		return nil, fmt.Errorf("loan application not found: %w", err)
+	}
+	if err := services.CheckExpectedVersion("LoanApplication", req.LoanID, req.ExpectedVersion, loanApp.Version); err != nil {
+		return nil, err
+	}
+	if loanApp.Status == validation.LoanStatusRejected || loanApp.Status == validation.LoanStatusCancelled || loanApp.Status == validation.LoanStatusDefaulted {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "collateral cannot be added to loan in status: %s", loanApp.Status)
+	}
+
+	collateral := &domain.Collateral{
+		CollateralID:   services.GenerateDeterministicID(stub, config.CollateralPrefix),
+		LoanID:         req.LoanID,
+		CollateralType: req.CollateralType,
+		Description:    req.Description,
+		Valuation:      req.Valuation,
+		ValuationDate:  req.ValuationDate,
+		AppraiserID:    req.AppraiserID,
+		LienStatus:     domain.LienStatusActive,
+		CreatedDate:    now,
+		LastUpdated:    now,
+		CreatedBy:      req.ActorID,
+		LastUpdatedBy:  req.ActorID,
+		Version:        1,
+	}
+
+	if err := h.persistenceService.Put(stub, fmt.Sprintf("COLLATERAL_%s", collateral.CollateralID), collateral); err != nil {
+		return nil, fmt.Errorf("failed to store collateral: %w", err)
+	}
+
+	// Create index by loan ID
+	loanCollateralKey, err := stub.CreateCompositeKey("LOAN_COLLATERAL", []string{req.LoanID, collateral.CollateralID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := stub.PutState(loanCollateralKey, []byte(collateral.CollateralID)); err != nil {
+		return nil, fmt.Errorf("failed to create loan collateral index: %w", err)
+	}
+
+	// Record history
+	collateralJSON, _ := utils.MarshalJSONString(collateral)
+	if err := h.recordEntityHistory(stub, collateral.CollateralID, "Collateral", "CREATE", "collateral", "", collateralJSON, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to record history: %w", err)
+	}
+	if err := h.recordEntityHistory(stub, req.LoanID, "LoanApplication", "COLLATERAL_ADDED", "collateralID", "", collateral.CollateralID, req.ActorID); err != nil {
+		return nil, err
+	}
+
+	// Emit event
+	if err := h.eventService.EmitCollateralEvent(stub, config.EventCollateralAdded, collateral, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(collateral)
+}
+
+// UpdateCollateralValuation records a new appraisal for pledged collateral
+func (h *CollateralHandler) UpdateCollateralValuation(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.CollateralValuationRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse collateral valuation request: %v", err)
+	}
+
+	collateralKey := fmt.Sprintf("COLLATERAL_%s", req.CollateralID)
+	var collateral domain.Collateral
+	if err := h.persistenceService.Get(stub, collateralKey, &collateral); err != nil {
+		return nil, fmt.Errorf("collateral not found: %w", err)
+	}
+	if err := services.CheckExpectedVersion("Collateral", req.CollateralID, req.ExpectedVersion, collateral.Version); err != nil {
+		return nil, err
+	}
+	if collateral.LienStatus != domain.LienStatusActive {
+		return nil, fmt.Errorf("collateral %s has been released", req.CollateralID)
+	}
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	if err := validateCollateralValuation(req.Valuation, req.ValuationDate, req.AppraiserID, now); err != nil {
+		return nil, err
+	}
+	if req.ValuationDate.Before(collateral.ValuationDate) {
+		return nil, fmt.Errorf("valuation date %s is older than current valuation %s", utils.FormatTime(req.ValuationDate), utils.FormatTime(collateral.ValuationDate))
+	}
+
+	// Record history
+	if err := h.recordEntityHistory(stub, req.CollateralID, "Collateral", "REVALUATION", "valuation", formatAmount(collateral.Valuation), formatAmount(req.Valuation), req.ActorID); err != nil {
+		return nil, err
+	}
+
+	collateral.Valuation = req.Valuation
+	collateral.ValuationDate = req.ValuationDate
+	collateral.AppraiserID = req.AppraiserID
+	collateral.LastUpdated = now
+	collateral.LastUpdatedBy = req.ActorID
+	collateral.Version++
+
+	if err := h.persistenceService.Put(stub, collateralKey, &collateral); err != nil {
+		return nil, fmt.Errorf("failed to update collateral: %w", err)
+	}
+
+	// Emit event
+	if err := h.eventService.EmitCollateralEvent(stub, config.EventCollateralRevalued, &collateral, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(&collateral)
+}
+
+// ReleaseCollateral releases the lien over collateral once it no longer secures a balance
+func (h *CollateralHandler) ReleaseCollateral(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.CollateralReleaseRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse collateral release request: %v", err)
+	}
+
+	collateralKey := fmt.Sprintf("COLLATERAL_%s", req.CollateralID)
+	var collateral domain.Collateral
+	if err := h.persistenceService.Get(stub, collateralKey, &collateral); err != nil {
+		return nil, fmt.Errorf("collateral not found: %w", err)
+	}
+	if err := services.CheckExpectedVersion("Collateral", req.CollateralID, req.ExpectedVersion, collateral.Version); err != nil {
+		return nil, err
+	}
+	if collateral.LienStatus != domain.LienStatusActive {
+		return nil, fmt.Errorf("collateral %s has already been released", req.CollateralID)
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, fmt.Errorf("release reason is required")
+	}
+
+	var loanApp domain.LoanApplication
+	if err := h.persistenceService.Get(stub, fmt.Sprintf("LOAN_%s", collateral.LoanID), &loanApp); err != nil {
+		return nil, fmt.Errorf("loan application not found: %w", err)
+	}
+	if loanApp.OutstandingBalance.IsPositive() {
+		return nil, fmt.Errorf("collateral secures outstanding balance %s on loan %s", loanApp.OutstandingBalance, loanApp.LoanID)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	collateral.LienStatus = domain.LienStatusReleased
+	collateral.ReleaseDate = &now
+	collateral.ReleaseReason = req.Reason
+	collateral.LastUpdated = now
+	collateral.LastUpdatedBy = req.ActorID
+	collateral.Version++
+
+	if err := h.persistenceService.Put(stub, collateralKey, &collateral); err != nil {
+		return nil, fmt.Errorf("failed to update collateral: %w", err)
+	}
+
+	// Record history
+	if err := h.recordEntityHistory(stub, req.CollateralID, "Collateral", "RELEASE", "lienStatus", string(domain.LienStatusActive), string(domain.LienStatusReleased), req.ActorID); err != nil {
+		return nil, err
+	}
+
+	// Emit event
+	if err := h.eventService.EmitCollateralEvent(stub, config.EventCollateralReleased, &collateral, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(&collateral)
+}
+
+// GetCollateral retrieves collateral by ID
+func (h *CollateralHandler) GetCollateral(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var collateral domain.Collateral
+	if err := h.persistenceService.Get(stub, fmt.Sprintf("COLLATERAL_%s", args[0]), &collateral); err != nil {
+		return nil, fmt.Errorf("collateral not found: %w", err)
+	}
+
+	return json.Marshal(&collateral)
+}
+
+// QueryCollateralByLoan retrieves all collateral pledged against a loan
+func (h *CollateralHandler) QueryCollateralByLoan(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	collaterals, err := getLoanCollateral(stub, h.persistenceService, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(collaterals)
+}
+
+// Helper methods
+
+func validateCollateralValuation(valuation float64, valuationDate time.Time, appraiserID string, now time.Time) error {
+	if valuation <= 0 {
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "valuation must be positive")
+	}
+	if strings.TrimSpace(appraiserID) == "" {
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "appraiserID", "appraiserID is required")
+	}
+	if valuationDate.IsZero() || valuationDate.After(now) {
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "valuation date must be set and not in the future")
+	}
+	if now.Sub(valuationDate) > config.CollateralValuationMaxAge {
+		return fmt.Errorf("valuation dated %s is too old", utils.FormatTime(valuationDate))
+	}
+	return nil
+}
+
+func getLoanCollateral(stub shim.ChaincodeStubInterface, persistenceService *services.PersistenceService, loanID string) ([]domain.Collateral, error) {
+	iterator, err := stub.GetStateByPartialCompositeKey("LOAN_COLLATERAL", []string{loanID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query collateral by loan: %w", err)
+	}
+	defer iterator.Close()
+
+	collaterals := []domain.Collateral{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate collateral: %w", err)
+		}
+
+		var collateral domain.Collateral
+		if err := persistenceService.Get(stub, fmt.Sprintf("COLLATERAL_%s", string(response.Value)), &collateral); err != nil {
+			continue // Skip if collateral not found
+		}
+
+		collaterals = append(collaterals, collateral)
+	}
+
+	return collaterals, nil
+}
+
+// getCollateralValue totals the current valuation of a loan's active collateral, rejecting stale appraisals
+func getCollateralValue(stub shim.ChaincodeStubInterface, persistenceService *services.PersistenceService, loanID string) (float64, error) {
+	collaterals, err := getLoanCollateral(stub, persistenceService, loanID)
+	if err != nil {
+		return 0, err
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return 0, err
+	}
+
+	total := 0.0
+	for _, collateral := range collaterals {
+		if collateral.LienStatus != domain.LienStatusActive {
+			continue
+		}
+		if now.Sub(collateral.ValuationDate) > config.CollateralValuationMaxAge {
+			return 0, fmt.Errorf("collateral %s valuation dated %s is stale", collateral.CollateralID, utils.FormatTime(collateral.ValuationDate))
+		}
+		total += collateral.Valuation
+	}
+
+	return total, nil
+}
+
+func (h *CollateralHandler) recordEntityHistory(stub shim.ChaincodeStubInterface, entityID, entityType, changeType, fieldName, previousValue, newValue, actorID string) error {
+	historyID, compositeKey, err := services.NewHistoryKey(stub, entityID)
+	if err != nil {
+		return err
+	}
+	txID := stub.GetTxID()
+
+	timestamp, err := services.TxTimeString(stub)
+	if err != nil {
+		return err
+	}
+
+	historyEntry := map[string]interface{}{
+		"historyID":     historyID,
+		"entityID":      entityID,
+		"entityType":    entityType,
+		"timestamp":     timestamp,
+		"changeType":    changeType,
+		"fieldName":     fieldName,
+		"previousValue": previousValue,
+		"newValue":      newValue,
+		"actorID":       actorID,
+		"transactionID": txID,
+	}
+
+	return h.persistenceService.Put(stub, compositeKey, historyEntry)
+}
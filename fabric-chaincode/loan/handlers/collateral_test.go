@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// appraisalTime is when collateral is pledged and decisions are made in the collateral tests
+var appraisalTime = time.Date(2026, 4, 1, 10, 0, 0, 0, time.UTC)
+
+func pledgeCollateral(t *testing.T, stub *shimtest.MockStub, txID, loanID string, valuation float64, valuationDate time.Time) (*domain.Collateral, error) {
+	payload, err := inTxAt(stub, txID, appraisalTime, func() ([]byte, error) {
+		return NewCollateralHandler().AddCollateral(stub, []string{mustJSON(t, domain.CollateralRequest{
+			LoanID: loanID, CollateralType: "VEHICLE", Description: "2025 estate car", Valuation: valuation, ValuationDate: valuationDate, AppraiserID: "APPRAISER_001", ActorID: "ACTOR_002",
+		})})
+	})
+	if err != nil {
+		return nil, err
+	}
+	var collateral domain.Collateral
+	if err := json.Unmarshal(payload, &collateral); err != nil {
+		t.Fatalf("failed to decode collateral: %v", err)
+	}
+	return &collateral, nil
+}
+
+func TestApproveLoanChecksLoanToValueOfActiveCollateral(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleUnderwriter))
+	withCustomers(stub, eligibleCustomer("CUST_001", 10))
+	autoLoan := func(loanApp *domain.LoanApplication) { loanApp.LoanType = "AUTO" }
+	seedLoan(t, stub, "LOAN_LTV1", validation.LoanStatusCreditApproval, autoLoan)
+
+	approve := func(txID string, amount float64) error {
+		_, err := inTxAt(stub, txID, appraisalTime, func() ([]byte, error) {
+			return NewLoanApplicationHandler().ApproveLoan(stub, []string{mustJSON(t, domain.LoanApprovalRequest{
+				LoanID: "LOAN_LTV1", ApprovedAmount: amount, InterestRate: 6, ActorID: "ACTOR_002",
+			})})
+		})
+		return err
+	}
+
+	// A secured product cannot be approved on no collateral
+	if err := approve("approve_unsecured", 8000); err == nil || !strings.Contains(err.Error(), "AUTO loans require collateral") {
+		t.Fatalf("expected approval without collateral to be refused, got %v", err)
+	}
+
+	// Rejected valuations never reach the loan
+	if _, err := pledgeCollateral(t, stub, "pledge_future", "LOAN_LTV1", 10000, appraisalTime.AddDate(0, 0, 1)); err == nil {
+		t.Errorf("expected a valuation dated tomorrow to be refused")
+	}
+	if _, err := pledgeCollateral(t, stub, "pledge_old", "LOAN_LTV1", 10000, appraisalTime.AddDate(-1, 0, -1)); err == nil {
+		t.Errorf("expected a valuation over a year old to be refused")
+	}
+	collateral, err := pledgeCollateral(t, stub, "pledge", "LOAN_LTV1", 10000, appraisalTime.AddDate(0, -1, 0))
+	if err != nil {
+		t.Fatalf("pledging collateral failed: %v", err)
+	}
+	if collateral.LienStatus != domain.LienStatusActive {
+		t.Errorf("expected an active lien, got %s", collateral.LienStatus)
+	}
+
+	// A revaluation lowers the cover the approval is checked against
+	if _, err := inTxAt(stub, "revalue", appraisalTime, func() ([]byte, error) {
+		return NewCollateralHandler().UpdateCollateralValuation(stub, []string{mustJSON(t, domain.CollateralValuationRequest{
+			CollateralID: collateral.CollateralID, Valuation: 8000, ValuationDate: appraisalTime, AppraiserID: "APPRAISER_002", ActorID: "ACTOR_002",
+		})})
+	}); err != nil {
+		t.Fatalf("revaluation failed: %v", err)
+	}
+	if err := approve("approve_over", 8000.01); err == nil || !strings.Contains(err.Error(), "exceeds maximum 100.00% for AUTO loans") {
+		t.Fatalf("expected approval above the collateral value to be refused, got %v", err)
+	}
+	if err := approve("approve", 6000); err != nil {
+		t.Fatalf("approval within the LTV limit failed: %v", err)
+	}
+	if loanApp := getLoan(t, stub, "LOAN_LTV1"); loanApp.LoanToValue == nil || *loanApp.LoanToValue != 75 {
+		t.Errorf("expected the approval to record an LTV of 75%%, got %v", loanApp.LoanToValue)
+	}
+}
+
+func TestReleaseCollateralWaitsForBalanceToClear(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleUnderwriter))
+	seedLoan(t, stub, "LOAN_LTV2", validation.LoanStatusDisbursed, approvedTerms(6000, 6), func(loanApp *domain.LoanApplication) {
+		loanApp.LoanType = "AUTO"
+		loanApp.DisbursedAmount = utils.NewMoney(6000, "USD")
+		loanApp.OutstandingBalance = utils.NewMoney(250, "USD")
+	})
+	first, err := pledgeCollateral(t, stub, "pledge_1", "LOAN_LTV2", 9000, appraisalTime)
+	if err != nil {
+		t.Fatalf("pledging collateral failed: %v", err)
+	}
+	if _, err := pledgeCollateral(t, stub, "pledge_2", "LOAN_LTV2", 3000, appraisalTime); err != nil {
+		t.Fatalf("pledging collateral failed: %v", err)
+	}
+
+	release := func(txID, reason string) error {
+		_, err := inTxAt(stub, txID, appraisalTime, func() ([]byte, error) {
+			return NewCollateralHandler().ReleaseCollateral(stub, []string{mustJSON(t, domain.CollateralReleaseRequest{
+				CollateralID: first.CollateralID, Reason: reason, ActorID: "ACTOR_002",
+			})})
+		})
+		return err
+	}
+
+	if err := release("release_owing", "Loan repaid"); err == nil || !strings.Contains(err.Error(), "secures outstanding balance 250") {
+		t.Fatalf("expected release while a balance is owed to be refused, got %v", err)
+	}
+	repaid := getLoan(t, stub, "LOAN_LTV2")
+	repaid.OutstandingBalance = utils.ZeroMoney("USD")
+	if _, err := inTx(stub, "repaid", func() ([]byte, error) {
+		return nil, putLoanApplication(stub, services.NewPersistenceService(), repaid)
+	}); err != nil {
+		t.Fatalf("failed to clear balance: %v", err)
+	}
+	if err := release("release_unexplained", " "); err == nil {
+		t.Errorf("expected a release without a reason to be refused")
+	}
+	if err := release("release", "Loan repaid"); err != nil {
+		t.Fatalf("release of a repaid loan's collateral failed: %v", err)
+	}
+	if err := release("release_again", "Loan repaid"); err == nil {
+		t.Errorf("expected a second release to be refused")
+	}
+
+	// Released collateral stays on the loan's record but no longer counts as cover
+	payload, err := inTxAt(stub, "by_loan", appraisalTime, func() ([]byte, error) {
+		return NewCollateralHandler().QueryCollateralByLoan(stub, []string{"LOAN_LTV2"})
+	})
+	if err != nil {
+		t.Fatalf("QueryCollateralByLoan failed: %v", err)
+	}
+	var collaterals []domain.Collateral
+	if err := json.Unmarshal(payload, &collaterals); err != nil {
+		t.Fatalf("failed to decode collateral: %v", err)
+	}
+	if len(collaterals) != 2 {
+		t.Errorf("expected both pledges on the loan's record, got %d", len(collaterals))
+	}
+	var value float64
+	if _, err := inTxAt(stub, "value", appraisalTime, func() ([]byte, error) {
+		value, err = getCollateralValue(stub, services.NewPersistenceService(), "LOAN_LTV2")
+		return nil, err
+	}); err != nil {
+		t.Fatalf("failed to value collateral: %v", err)
+	}
+	if value != 3000 {
+		t.Errorf("expected only the remaining 3000 pledge to count, got %.2f", value)
+	}
+}
@@ -0,0 +1,614 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	loanServices "github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// CounterpartyHandler handles counterparty onboarding and loan participation operations
+type CounterpartyHandler struct {
+	persistenceService *services.PersistenceService
+	eventService      *loanServices.EventService
+	textScreeningService *loanServices.TextScreeningService
+}
+
+// NewCounterpartyHandler creates a new counterparty handler
+func NewCounterpartyHandler() *CounterpartyHandler {
+	return &CounterpartyHandler{
+		persistenceService: services.NewPersistenceService(),
+		eventService:      loanServices.NewEventService(),
+		textScreeningService: loanServices.NewTextScreeningService(),
+	}
+}
+
+// RegisterCounterparty onboards a new counterparty pending KYC and sanctions screening
+func (h *CounterpartyHandler) RegisterCounterparty(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.CounterpartyRegistrationRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse counterparty registration request: %v", err)
+	}
+
+	// Validate counterparty type
+	if err := validation.ValidateCounterpartyType(req.CounterpartyType); err != nil {
+		return nil, fmt.Errorf("invalid counterparty type: %w", err)
+	}
+
+	if strings.TrimSpace(req.LegalName) == "" || strings.TrimSpace(req.RegistrationNumber) == "" || strings.TrimSpace(req.Jurisdiction) == "" {
+		return nil, fmt.Errorf("legalName, registrationNumber and jurisdiction are required")
+	}
+
+	// Check for duplicate registration
+	registrationKey := fmt.Sprintf("COUNTERPARTY_BY_REGISTRATION_%s_%s", req.Jurisdiction, req.RegistrationNumber)
+	exists, err := h.persistenceService.Exists(stub, registrationKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check registration number: %w", err)
+	}
+	if exists {
+		return nil, fmt.Errorf("counterparty with registration number %s already exists in %s", req.RegistrationNumber, req.Jurisdiction)
+	}
+
+	// Generate counterparty ID
+	counterpartyID := services.GenerateDeterministicID(stub, config.CounterpartyPrefix)
+
+	// Create counterparty
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	counterparty := &domain.Counterparty{
+		CounterpartyID:     counterpartyID,
+		LegalName:          req.LegalName,
+		CounterpartyType:   req.CounterpartyType,
+		RegistrationNumber: req.RegistrationNumber,
+		Jurisdiction:       req.Jurisdiction,
+		BIC:                req.BIC,
+		Purpose:            req.Purpose,
+		Status:             validation.CounterpartyStatusPending,
+		KYCStatus:          validation.KYCStatusPending,
+		DocumentHashes:     req.DocumentHashes,
+		SanctionsStatus:    validation.AMLStatusReviewing,
+		CreatedDate:        now,
+		LastUpdated:        now,
+		CreatedBy:          req.ActorID,
+		LastUpdatedBy:      req.ActorID,
+		Version:            1,
+	}
+
+	// Screen the stated purpose; a blocked purpose blocks sanctions clearance, so the counterparty
+	// cannot be activated until compliance records a clear screening
+	if strings.TrimSpace(req.Purpose) != "" {
+		screening, err := h.textScreeningService.Screen(stub, counterpartyID, "Counterparty", map[string]string{"purpose": req.Purpose}, req.ActorID)
+		if err != nil {
+			return nil, err
+		}
+		if screening.Flagged {
+			counterparty.PurposeScreening = screening
+		}
+		if screening.Blocked {
+			counterparty.SanctionsStatus = validation.AMLStatusBlocked
+		}
+	}
+
+	// Store counterparty
+	counterpartyKey := fmt.Sprintf("COUNTERPARTY_%s", counterpartyID)
+	if err := h.persistenceService.Put(stub, counterpartyKey, counterparty); err != nil {
+		return nil, fmt.Errorf("failed to store counterparty: %w", err)
+	}
+
+	// Create indexes
+	if err := stub.PutState(registrationKey, []byte(counterpartyID)); err != nil {
+		return nil, fmt.Errorf("failed to create registration index: %w", err)
+	}
+
+	typeKey, err := stub.CreateCompositeKey("COUNTERPARTY_TYPE", []string{req.CounterpartyType, counterpartyID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := stub.PutState(typeKey, []byte(counterpartyID)); err != nil {
+		return nil, fmt.Errorf("failed to create counterparty type index: %w", err)
+	}
+
+	// Record history
+	counterpartyJSON, _ := utils.MarshalJSONString(counterparty)
+	if err := h.recordEntityHistory(stub, counterpartyID, "Counterparty", "CREATE", "counterparty", "", counterpartyJSON, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to record history: %w", err)
+	}
+
+	// Emit event
+	if err := h.eventService.EmitCounterpartyEvent(stub, config.EventCounterpartyRegistered, counterparty, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(counterparty)
+}
+
+// UpdateCounterpartyKYC records the outcome of a counterparty KYC review
+func (h *CounterpartyHandler) UpdateCounterpartyKYC(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.CounterpartyKYCUpdateRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse counterparty KYC update request: %v", err)
+	}
+
+	if err := validation.ValidateKYCStatus(string(req.NewStatus)); err != nil {
+		return nil, fmt.Errorf("invalid KYC status: %w", err)
+	}
+
+	counterpartyKey := fmt.Sprintf("COUNTERPARTY_%s", req.CounterpartyID)
+	var counterparty domain.Counterparty
+	if err := h.persistenceService.Get(stub, counterpartyKey, &counterparty); err != nil {
+		return nil, fmt.Errorf("counterparty not found: %w", err)
+	}
+
+	if counterparty.Status == validation.CounterpartyStatusTerminated {
+		return nil, fmt.Errorf("counterparty %s is terminated", req.CounterpartyID)
+	}
+
+	// Record history for KYC status change
+	if err := h.recordEntityHistory(stub, req.CounterpartyID, "Counterparty", "KYC_UPDATE", "kycStatus", string(counterparty.KYCStatus), string(req.NewStatus), req.ActorID); err != nil {
+		return nil, err
+	}
+
+	// Update KYC details
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	counterparty.KYCStatus = req.NewStatus
+	counterparty.Notes = req.VerificationNotes
+	if len(req.DocumentHashes) > 0 {
+		counterparty.DocumentHashes = req.DocumentHashes
+	}
+	if req.NewStatus == validation.KYCStatusVerified {
+		counterparty.KYCVerificationDate = &now
+		expiryDate := now.Add(config.KYCValidityPeriod)
+		counterparty.KYCExpiryDate = &expiryDate
+	}
+
+	// Failed or expired KYC takes an active counterparty out of service
+	if req.NewStatus != validation.KYCStatusVerified && counterparty.Status == validation.CounterpartyStatusActive {
+		if err := h.recordEntityHistory(stub, req.CounterpartyID, "Counterparty", "STATUS_UPDATE", "status", string(counterparty.Status), string(validation.CounterpartyStatusSuspended), req.ActorID); err != nil {
+			return nil, err
+		}
+		counterparty.Status = validation.CounterpartyStatusSuspended
+	}
+
+	counterparty.LastUpdated = now
+	counterparty.LastUpdatedBy = req.ActorID
+	counterparty.Version++
+
+	if err := h.persistenceService.Put(stub, counterpartyKey, &counterparty); err != nil {
+		return nil, fmt.Errorf("failed to update counterparty: %w", err)
+	}
+
+	// Emit event
+	if err := h.eventService.EmitCounterpartyEvent(stub, config.EventCounterpartyKYCUpdated, &counterparty, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(&counterparty)
+}
+
+// RecordCounterpartyScreening records the outcome of a sanctions screening of a counterparty
+func (h *CounterpartyHandler) RecordCounterpartyScreening(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.CounterpartyScreeningRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse counterparty screening request: %v", err)
+	}
+
+	if err := validation.ValidateAMLStatus(string(req.Result)); err != nil {
+		return nil, fmt.Errorf("invalid screening result: %w", err)
+	}
+
+	if req.ScreeningID == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "screeningID", "screeningID is required")
+	}
+
+	counterpartyKey := fmt.Sprintf("COUNTERPARTY_%s", req.CounterpartyID)
+	var counterparty domain.Counterparty
+	if err := h.persistenceService.Get(stub, counterpartyKey, &counterparty); err != nil {
+		return nil, fmt.Errorf("counterparty not found: %w", err)
+	}
+
+	if counterparty.Status == validation.CounterpartyStatusTerminated {
+		return nil, fmt.Errorf("counterparty %s is terminated", req.CounterpartyID)
+	}
+
+	// Record history for sanctions status change
+	if err := h.recordEntityHistory(stub, req.CounterpartyID, "Counterparty", "SANCTIONS_SCREENING", "sanctionsStatus", string(counterparty.SanctionsStatus), string(req.Result), req.ActorID); err != nil {
+		return nil, err
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	counterparty.SanctionsStatus = req.Result
+	counterparty.SanctionsScreeningID = req.ScreeningID
+	counterparty.SanctionsScreenedDate = &now
+	counterparty.Notes = req.Notes
+
+	// Any non-clear result takes an active counterparty out of service
+	if req.Result != validation.AMLStatusClear && counterparty.Status == validation.CounterpartyStatusActive {
+		if err := h.recordEntityHistory(stub, req.CounterpartyID, "Counterparty", "STATUS_UPDATE", "status", string(counterparty.Status), string(validation.CounterpartyStatusSuspended), req.ActorID); err != nil {
+			return nil, err
+		}
+		counterparty.Status = validation.CounterpartyStatusSuspended
+	}
+
+	counterparty.LastUpdated = now
+	counterparty.LastUpdatedBy = req.ActorID
+	counterparty.Version++
+
+	if err := h.persistenceService.Put(stub, counterpartyKey, &counterparty); err != nil {
+		return nil, fmt.Errorf("failed to update counterparty: %w", err)
+	}
+
+	// Emit event
+	if err := h.eventService.EmitCounterpartyEvent(stub, config.EventCounterpartyScreened, &counterparty, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(&counterparty)
+}
+
+// UpdateCounterpartyStatus updates the lifecycle status of a counterparty
+func (h *CounterpartyHandler) UpdateCounterpartyStatus(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.CounterpartyStatusUpdateRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse counterparty status update request: %v", err)
+	}
+
+	counterpartyKey := fmt.Sprintf("COUNTERPARTY_%s", req.CounterpartyID)
+	var counterparty domain.Counterparty
+	if err := h.persistenceService.Get(stub, counterpartyKey, &counterparty); err != nil {
+		return nil, fmt.Errorf("counterparty not found: %w", err)
+	}
+	if err := services.CheckExpectedVersion("Counterparty", req.CounterpartyID, req.ExpectedVersion, counterparty.Version); err != nil {
+		return nil, err
+	}
+
+	// Validate status transition
+	if err := validation.ValidateStatusTransition(string(counterparty.Status), string(req.NewStatus), "Counterparty"); err != nil {
+		return nil, fmt.Errorf("invalid status transition: %w", err)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	// Activation requires completed onboarding
+	if req.NewStatus == validation.CounterpartyStatusActive {
+		if err := checkCounterpartyOnboarded(&counterparty, now); err != nil {
+			return nil, err
+		}
+	}
+
+	// Record history
+	if err := h.recordEntityHistory(stub, req.CounterpartyID, "Counterparty", "STATUS_UPDATE", "status", string(counterparty.Status), string(req.NewStatus), req.ActorID); err != nil {
+		return nil, err
+	}
+
+	counterparty.Status = req.NewStatus
+	counterparty.Notes = req.Reason
+	counterparty.LastUpdated = now
+	counterparty.LastUpdatedBy = req.ActorID
+	counterparty.Version++
+
+	if err := h.persistenceService.Put(stub, counterpartyKey, &counterparty); err != nil {
+		return nil, fmt.Errorf("failed to update counterparty: %w", err)
+	}
+
+	// Emit event
+	if err := h.eventService.EmitCounterpartyEvent(stub, config.EventCounterpartyStatusChanged, &counterparty, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(&counterparty)
+}
+
+// GetCounterparty retrieves a counterparty by ID
+func (h *CounterpartyHandler) GetCounterparty(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	counterpartyKey := fmt.Sprintf("COUNTERPARTY_%s", args[0])
+	var counterparty domain.Counterparty
+	if err := h.persistenceService.Get(stub, counterpartyKey, &counterparty); err != nil {
+		return nil, fmt.Errorf("counterparty not found: %w", err)
+	}
+
+	return json.Marshal(&counterparty)
+}
+
+// QueryCounterpartiesByType queries counterparties by type
+func (h *CounterpartyHandler) QueryCounterpartiesByType(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	counterpartyType := args[0]
+	if err := validation.ValidateCounterpartyType(counterpartyType); err != nil {
+		return nil, fmt.Errorf("invalid counterparty type: %w", err)
+	}
+
+	iterator, err := stub.GetStateByPartialCompositeKey("COUNTERPARTY_TYPE", []string{counterpartyType})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get counterparties by type: %w", err)
+	}
+	defer iterator.Close()
+
+	counterparties := []domain.Counterparty{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate counterparties: %w", err)
+		}
+
+		counterpartyKey := fmt.Sprintf("COUNTERPARTY_%s", string(response.Value))
+		var counterparty domain.Counterparty
+		if err := h.persistenceService.Get(stub, counterpartyKey, &counterparty); err != nil {
+			continue // Skip if counterparty not found
+		}
+
+		counterparties = append(counterparties, counterparty)
+	}
+
+	return json.Marshal(counterparties)
+}
+
+// GetCounterpartyHistory retrieves the history of a counterparty
+func (h *CounterpartyHandler) GetCounterpartyHistory(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	iterator, err := stub.GetStateByPartialCompositeKey("HISTORY", []string{args[0]})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get history iterator: %w", err)
+	}
+	defer iterator.Close()
+
+	var history []interface{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate history: %w", err)
+		}
+
+		var entry interface{}
+		if err := json.Unmarshal(response.Value, &entry); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal history entry: %w", err)
+		}
+
+		history = append(history, entry)
+	}
+
+	return json.Marshal(history)
+}
+
+// AddLoanParticipation records an active counterparty as an investor or servicer on a loan
+func (h *CounterpartyHandler) AddLoanParticipation(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.LoanParticipationRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse loan participation request: %v", err)
+	}
+
+	// Get existing loan application
+	loanKey := fmt.Sprintf("LOAN_%s", req.LoanID)
+	var loanApp domain.LoanApplication
+	if err := h.persistenceService.Get(stub, loanKey, &loanApp); err != nil {
+		return nil, fmt.Errorf("loan application not found: %w", err)
+	}
+
+	counterparty, err := getActiveCounterparty(stub, h.persistenceService, req.CounterpartyID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Validate role against counterparty type
+	switch req.Role {
+	case "INVESTOR":
+		if counterparty.CounterpartyType != "INVESTOR" && counterparty.CounterpartyType != "BANK" {
+			return nil, fmt.Errorf("counterparty of type %s cannot participate as INVESTOR", counterparty.CounterpartyType)
+		}
+		if req.SharePercentage <= 0 || req.SharePercentage > 100 {
+			return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "investor share percentage must be greater than 0 and at most 100")
+		}
+	case "SERVICER":
+		if counterparty.CounterpartyType != "SERVICER" && counterparty.CounterpartyType != "BANK" {
+			return nil, fmt.Errorf("counterparty of type %s cannot participate as SERVICER", counterparty.CounterpartyType)
+		}
+	default:
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid participation role: %s", req.Role)
+	}
+
+	// Investor shares across a loan cannot exceed the whole loan
+	participations, err := h.getLoanParticipations(stub, req.LoanID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Role == "INVESTOR" {
+		totalShare := req.SharePercentage
+		for _, p := range participations {
+			if p.Role == "INVESTOR" {
+				totalShare += p.SharePercentage
+			}
+		}
+		if totalShare > 100 {
+			return nil, fmt.Errorf("investor shares for loan %s would total %.2f%%", req.LoanID, totalShare)
+		}
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	participation := &domain.LoanParticipation{
+		ParticipationID: services.GenerateDeterministicID(stub, config.LoanParticipationPrefix),
+		LoanID:          req.LoanID,
+		CounterpartyID:  req.CounterpartyID,
+		Role:            req.Role,
+		SharePercentage: req.SharePercentage,
+		CreatedDate:     now,
+		CreatedBy:       req.ActorID,
+	}
+
+	participationKey, err := stub.CreateCompositeKey("LOAN_PARTICIPATION", []string{req.LoanID, participation.ParticipationID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := h.persistenceService.Put(stub, participationKey, participation); err != nil {
+		return nil, fmt.Errorf("failed to store loan participation: %w", err)
+	}
+
+	// Record history against the loan
+	participationJSON, _ := utils.MarshalJSONString(participation)
+	if err := h.recordEntityHistory(stub, req.LoanID, "LoanApplication", "PARTICIPATION_ADDED", "participation", "", participationJSON, req.ActorID); err != nil {
+		return nil, err
+	}
+
+	// Emit event
+	if err := h.eventService.EmitLoanParticipationAdded(stub, participation, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(participation)
+}
+
+// GetLoanParticipations retrieves all counterparty participations on a loan
+func (h *CounterpartyHandler) GetLoanParticipations(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	participations, err := h.getLoanParticipations(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(participations)
+}
+
+// Helper methods
+
+func (h *CounterpartyHandler) getLoanParticipations(stub shim.ChaincodeStubInterface, loanID string) ([]domain.LoanParticipation, error) {
+	iterator, err := stub.GetStateByPartialCompositeKey("LOAN_PARTICIPATION", []string{loanID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loan participations: %w", err)
+	}
+	defer iterator.Close()
+
+	participations := []domain.LoanParticipation{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate loan participations: %w", err)
+		}
+
+		var participation domain.LoanParticipation
+		if err := json.Unmarshal(response.Value, &participation); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal loan participation: %w", err)
+		}
+
+		participations = append(participations, participation)
+	}
+
+	return participations, nil
+}
+
+func (h *CounterpartyHandler) recordEntityHistory(stub shim.ChaincodeStubInterface, entityID, entityType, changeType, fieldName, previousValue, newValue, actorID string) error {
+	historyID, compositeKey, err := services.NewHistoryKey(stub, entityID)
+	if err != nil {
+		return err
+	}
+	txID := stub.GetTxID()
+
+	timestamp, err := services.TxTimeString(stub)
+	if err != nil {
+		return err
+	}
+
+	historyEntry := map[string]interface{}{
+		"historyID":     historyID,
+		"entityID":      entityID,
+		"entityType":    entityType,
+		"timestamp":     timestamp,
+		"changeType":    changeType,
+		"fieldName":     fieldName,
+		"previousValue": previousValue,
+		"newValue":      newValue,
+		"actorID":       actorID,
+		"transactionID": txID,
+	}
+
+	return h.persistenceService.Put(stub, compositeKey, historyEntry)
+}
+
+// checkCounterpartyOnboarded verifies a counterparty has passed KYC and sanctions screening
+func checkCounterpartyOnboarded(counterparty *domain.Counterparty, now time.Time) error {
+	if counterparty.KYCStatus != validation.KYCStatusVerified {
+		return fmt.Errorf("counterparty %s KYC is %s", counterparty.CounterpartyID, counterparty.KYCStatus)
+	}
+	if counterparty.KYCExpiryDate != nil && now.After(*counterparty.KYCExpiryDate) {
+		return fmt.Errorf("counterparty %s KYC expired on %s", counterparty.CounterpartyID, utils.FormatTime(*counterparty.KYCExpiryDate))
+	}
+	if counterparty.SanctionsStatus != validation.AMLStatusClear {
+		return fmt.Errorf("counterparty %s sanctions screening is %s", counterparty.CounterpartyID, counterparty.SanctionsStatus)
+	}
+	return nil
+}
+
+// getActiveCounterparty loads a counterparty and ensures it may currently be transacted with
+func getActiveCounterparty(stub shim.ChaincodeStubInterface, persistenceService *services.PersistenceService, counterpartyID string) (*domain.Counterparty, error) {
+	counterpartyKey := fmt.Sprintf("COUNTERPARTY_%s", counterpartyID)
+	var counterparty domain.Counterparty
+	if err := persistenceService.Get(stub, counterpartyKey, &counterparty); err != nil {
+		return nil, fmt.Errorf("counterparty not found: %w", err)
+	}
+
+	if counterparty.Status != validation.CounterpartyStatusActive {
+		return nil, fmt.Errorf("counterparty %s is not active (status: %s)", counterpartyID, counterparty.Status)
+	}
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	if err := checkCounterpartyOnboarded(&counterparty, now); err != nil {
+		return nil, err
+	}
+
+	return &counterparty, nil
+}
@@ -0,0 +1,197 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+func registerCounterparty(t *testing.T, stub *shimtest.MockStub, counterpartyType, registrationNumber string) (*domain.Counterparty, error) {
+	payload, err := inTx(stub, "register_"+registrationNumber, func() ([]byte, error) {
+		return NewCounterpartyHandler().RegisterCounterparty(stub, []string{mustJSON(t, domain.CounterpartyRegistrationRequest{
+			LegalName: registrationNumber + " Capital", CounterpartyType: counterpartyType, RegistrationNumber: registrationNumber, Jurisdiction: "GB", ActorID: "ACTOR_005",
+		})})
+	})
+	if err != nil {
+		return nil, err
+	}
+	var counterparty domain.Counterparty
+	if err := json.Unmarshal(payload, &counterparty); err != nil {
+		t.Fatalf("failed to decode counterparty: %v", err)
+	}
+	return &counterparty, nil
+}
+
+// onboardCounterparty verifies, clears and activates a registered counterparty
+func onboardCounterparty(t *testing.T, stub *shimtest.MockStub, counterpartyID string) {
+	t.Helper()
+	handler := NewCounterpartyHandler()
+	if _, err := inTx(stub, "kyc_"+counterpartyID, func() ([]byte, error) {
+		return handler.UpdateCounterpartyKYC(stub, []string{mustJSON(t, domain.CounterpartyKYCUpdateRequest{
+			CounterpartyID: counterpartyID, NewStatus: validation.KYCStatusVerified, ActorID: "ACTOR_005",
+		})})
+	}); err != nil {
+		t.Fatalf("KYC update failed: %v", err)
+	}
+	if err := screenCounterparty(t, stub, "screen_"+counterpartyID, counterpartyID, validation.AMLStatusClear); err != nil {
+		t.Fatalf("screening failed: %v", err)
+	}
+	if err := setCounterpartyStatus(t, stub, "activate_"+counterpartyID, counterpartyID, validation.CounterpartyStatusActive); err != nil {
+		t.Fatalf("activation failed: %v", err)
+	}
+}
+
+func screenCounterparty(t *testing.T, stub *shimtest.MockStub, txID, counterpartyID string, result validation.AMLStatus) error {
+	_, err := inTx(stub, txID, func() ([]byte, error) {
+		return NewCounterpartyHandler().RecordCounterpartyScreening(stub, []string{mustJSON(t, domain.CounterpartyScreeningRequest{
+			CounterpartyID: counterpartyID, ScreeningID: "SCR_" + txID, Result: result, ActorID: "ACTOR_005",
+		})})
+	})
+	return err
+}
+
+func setCounterpartyStatus(t *testing.T, stub *shimtest.MockStub, txID, counterpartyID string, status validation.CounterpartyStatus) error {
+	_, err := inTx(stub, txID, func() ([]byte, error) {
+		return NewCounterpartyHandler().UpdateCounterpartyStatus(stub, []string{mustJSON(t, domain.CounterpartyStatusUpdateRequest{
+			CounterpartyID: counterpartyID, NewStatus: status, ActorID: "ACTOR_005",
+		})})
+	})
+	return err
+}
+
+func getCounterparty(t *testing.T, stub *shimtest.MockStub, counterpartyID string) *domain.Counterparty {
+	t.Helper()
+	payload, err := inTx(stub, "get_"+counterpartyID, func() ([]byte, error) {
+		return NewCounterpartyHandler().GetCounterparty(stub, []string{counterpartyID})
+	})
+	if err != nil {
+		t.Fatalf("GetCounterparty failed: %v", err)
+	}
+	var counterparty domain.Counterparty
+	if err := json.Unmarshal(payload, &counterparty); err != nil {
+		t.Fatalf("failed to decode counterparty: %v", err)
+	}
+	return &counterparty
+}
+
+func TestCounterpartyActivationRequiresOnboarding(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	counterparty, err := registerCounterparty(t, stub, "INVESTOR", "INV-2001")
+	if err != nil {
+		t.Fatalf("registration failed: %v", err)
+	}
+	if counterparty.Status != validation.CounterpartyStatusPending || counterparty.SanctionsStatus != validation.AMLStatusReviewing {
+		t.Errorf("expected a new counterparty PENDING with screening under review, got %s / %s", counterparty.Status, counterparty.SanctionsStatus)
+	}
+	if _, err := registerCounterparty(t, stub, "INVESTOR", "INV-2001"); err == nil {
+		t.Errorf("expected a second registration of INV-2001 to be refused")
+	}
+
+	// Neither KYC nor screening alone is enough to transact with
+	if err := setCounterpartyStatus(t, stub, "activate_unverified", counterparty.CounterpartyID, validation.CounterpartyStatusActive); err == nil {
+		t.Errorf("expected activation before KYC to be refused")
+	}
+	if _, err := inTx(stub, "kyc", func() ([]byte, error) {
+		return NewCounterpartyHandler().UpdateCounterpartyKYC(stub, []string{mustJSON(t, domain.CounterpartyKYCUpdateRequest{
+			CounterpartyID: counterparty.CounterpartyID, NewStatus: validation.KYCStatusVerified, ActorID: "ACTOR_005",
+		})})
+	}); err != nil {
+		t.Fatalf("KYC update failed: %v", err)
+	}
+	if err := setCounterpartyStatus(t, stub, "activate_unscreened", counterparty.CounterpartyID, validation.CounterpartyStatusActive); err == nil {
+		t.Errorf("expected activation before a clear screening to be refused")
+	}
+	if err := screenCounterparty(t, stub, "screen_clear", counterparty.CounterpartyID, validation.AMLStatusClear); err != nil {
+		t.Fatalf("screening failed: %v", err)
+	}
+	if err := setCounterpartyStatus(t, stub, "activate", counterparty.CounterpartyID, validation.CounterpartyStatusActive); err != nil {
+		t.Fatalf("activation of an onboarded counterparty failed: %v", err)
+	}
+
+	// A later sanctions hit takes the counterparty out of service
+	if err := screenCounterparty(t, stub, "screen_hit", counterparty.CounterpartyID, validation.AMLStatusFlagged); err != nil {
+		t.Fatalf("rescreening failed: %v", err)
+	}
+	if stored := getCounterparty(t, stub, counterparty.CounterpartyID); stored.Status != validation.CounterpartyStatusSuspended || stored.SanctionsStatus != validation.AMLStatusFlagged {
+		t.Errorf("expected a flagged counterparty SUSPENDED, got %s / %s", stored.Status, stored.SanctionsStatus)
+	}
+	if err := setCounterpartyStatus(t, stub, "reactivate_flagged", counterparty.CounterpartyID, validation.CounterpartyStatusActive); err == nil {
+		t.Errorf("expected reactivation while flagged to be refused")
+	}
+	if err := setCounterpartyStatus(t, stub, "terminate", counterparty.CounterpartyID, validation.CounterpartyStatusTerminated); err != nil {
+		t.Fatalf("termination failed: %v", err)
+	}
+	if err := screenCounterparty(t, stub, "screen_terminated", counterparty.CounterpartyID, validation.AMLStatusClear); err == nil {
+		t.Errorf("expected screening of a terminated counterparty to be refused")
+	}
+}
+
+func TestAddLoanParticipationReferencesActiveCounterparties(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	seedLoan(t, stub, "LOAN_CP1", validation.LoanStatusApproved, approvedTerms(12000, 7))
+	investor, err := registerCounterparty(t, stub, "INVESTOR", "INV-3001")
+	if err != nil {
+		t.Fatalf("registration failed: %v", err)
+	}
+	bank, err := registerCounterparty(t, stub, "BANK", "BNK-3002")
+	if err != nil {
+		t.Fatalf("registration failed: %v", err)
+	}
+
+	participate := func(txID, counterpartyID, role string, share float64) error {
+		_, err := inTx(stub, txID, func() ([]byte, error) {
+			return NewCounterpartyHandler().AddLoanParticipation(stub, []string{mustJSON(t, domain.LoanParticipationRequest{
+				LoanID: "LOAN_CP1", CounterpartyID: counterpartyID, Role: role, SharePercentage: share, ActorID: "ACTOR_005",
+			})})
+		})
+		return err
+	}
+
+	// Money only moves with counterparties that have been onboarded
+	if err := participate("invest_pending", investor.CounterpartyID, "INVESTOR", 60); err == nil {
+		t.Errorf("expected a pending counterparty to be refused")
+	}
+	onboardCounterparty(t, stub, investor.CounterpartyID)
+	onboardCounterparty(t, stub, bank.CounterpartyID)
+
+	if err := participate("invest", investor.CounterpartyID, "INVESTOR", 60); err != nil {
+		t.Fatalf("investor participation failed: %v", err)
+	}
+	if err := participate("service_as_investor", investor.CounterpartyID, "SERVICER", 0); err == nil {
+		t.Errorf("expected an investor to be refused as servicer")
+	}
+	if err := participate("invest_over", bank.CounterpartyID, "INVESTOR", 40.01); err == nil {
+		t.Errorf("expected investor shares above 100%% to be refused")
+	}
+	if err := participate("invest_rest", bank.CounterpartyID, "INVESTOR", 40); err != nil {
+		t.Fatalf("participation up to the whole loan failed: %v", err)
+	}
+	if err := participate("service", bank.CounterpartyID, "SERVICER", 0); err != nil {
+		t.Fatalf("bank servicing participation failed: %v", err)
+	}
+
+	payload, err := inTx(stub, "participations", func() ([]byte, error) {
+		return NewCounterpartyHandler().GetLoanParticipations(stub, []string{"LOAN_CP1"})
+	})
+	if err != nil {
+		t.Fatalf("GetLoanParticipations failed: %v", err)
+	}
+	var participations []domain.LoanParticipation
+	if err := json.Unmarshal(payload, &participations); err != nil {
+		t.Fatalf("failed to decode participations: %v", err)
+	}
+	if len(participations) != 3 {
+		t.Errorf("expected 3 participations, got %+v", participations)
+	}
+
+	// A suspended counterparty takes no new participations
+	if err := setCounterpartyStatus(t, stub, "suspend", bank.CounterpartyID, validation.CounterpartyStatusSuspended); err != nil {
+		t.Fatalf("suspension failed: %v", err)
+	}
+	if err := participate("service_suspended", bank.CounterpartyID, "SERVICER", 0); err == nil {
+		t.Errorf("expected a suspended counterparty to be refused")
+	}
+}
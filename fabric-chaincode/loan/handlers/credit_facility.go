@@ -0,0 +1,597 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	loanServices "github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// FacilityHandler handles revolving credit facility operations
+type FacilityHandler struct {
+	persistenceService *services.PersistenceService
+	eventService      *loanServices.EventService
+	customerVerifier  *loanServices.CustomerVerificationService
+	indexRateService  *loanServices.IndexRateService
+	scheduleService   *loanServices.ScheduleService
+	sandboxService    *services.SandboxService
+}
+
+// NewFacilityHandler creates a new credit facility handler
+func NewFacilityHandler() *FacilityHandler {
+	return &FacilityHandler{
+		persistenceService: services.NewPersistenceService(),
+		eventService:      loanServices.NewEventService(),
+		customerVerifier:  loanServices.NewCustomerVerificationService(),
+		indexRateService:  loanServices.NewIndexRateService(),
+		scheduleService:   loanServices.NewScheduleService(),
+		sandboxService:    services.NewSandboxService(),
+	}
+}
+
+// OpenCreditFacility opens an approved credit limit for a customer
+func (h *FacilityHandler) OpenCreditFacility(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.CreditFacilityRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse credit facility request: %v", err)
+	}
+
+	// Validate limit against the product's lending bounds
+	if err := validation.ValidateLoanType(req.LoanType); err != nil {
+		return nil, fmt.Errorf("invalid loan type: %w", err)
+	}
+	if err := validation.ValidateLoanAmount(req.CreditLimit, req.LoanType); err != nil {
+		return nil, fmt.Errorf("invalid credit limit: %w", err)
+	}
+	if req.TermMonths <= 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "termMonths must be positive")
+	}
+
+	// Verify customer KYC/AML/consent with the customer chaincode
+	if _, err := h.customerVerifier.VerifyCustomer(stub, req.CustomerID); err != nil {
+		return nil, fmt.Errorf("customer verification failed: %w", err)
+	}
+
+	// Facilities opened by sandbox actors are UAT data
+	sandbox, err := h.sandboxService.IsSandboxActor(stub, req.ActorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check sandbox actor: %w", err)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	facility := &domain.CreditFacility{
+		FacilityID:    services.GenerateDeterministicID(stub, config.CreditFacilityPrefix),
+		CustomerID:    req.CustomerID,
+		LoanType:      req.LoanType,
+		CreditLimit:   req.CreditLimit,
+		InterestRate:  req.InterestRate,
+		Status:        domain.FacilityStatusActive,
+		ApprovalDate:  now,
+		ExpiryDate:    now.AddDate(0, req.TermMonths, 0),
+		Sandbox:       sandbox,
+		CreatedDate:   now,
+		LastUpdated:   now,
+		CreatedBy:     req.ActorID,
+		LastUpdatedBy: req.ActorID,
+	}
+
+	// Price variable-rate facilities off the current index fixing
+	if req.RateIndex != "" {
+		indexRate, err := h.indexRateService.GetCurrentRate(stub, req.RateIndex)
+		if err != nil {
+			return nil, fmt.Errorf("failed to price facility: %w", err)
+		}
+		facility.InterestRate = indexRate.Rate + req.RateMargin
+		facility.RateIndex = req.RateIndex
+		facility.RateMargin = &req.RateMargin
+		facility.IndexRateDate = indexRate.EffectiveDate
+	}
+
+	applyFacilityBalance(facility, 0)
+
+	// Store the facility
+	facilityKey := fmt.Sprintf("FACILITY_%s", facility.FacilityID)
+	if err := h.persistenceService.Put(stub, facilityKey, facility); err != nil {
+		return nil, fmt.Errorf("failed to store credit facility: %w", err)
+	}
+
+	// Create index by customer ID
+	customerFacilityKey, err := stub.CreateCompositeKey("CUSTOMER_FACILITY", []string{req.CustomerID, facility.FacilityID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := stub.PutState(customerFacilityKey, []byte(facility.FacilityID)); err != nil {
+		return nil, fmt.Errorf("failed to create customer facility index: %w", err)
+	}
+
+	// Opening a facility is a hard pull; sandbox facilities leave no inquiry trail
+	if !facility.Sandbox {
+		if err := recordHardInquiry(stub, h.persistenceService, req.CustomerID, facility.FacilityID, "CreditFacility", "Credit facility application", req.ActorID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Record history
+	facilityJSON, _ := utils.MarshalJSONString(facility)
+	if err := h.recordEntityHistory(stub, facility.FacilityID, "CreditFacility", "CREATE", "credit_facility", "", facilityJSON, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to record history: %w", err)
+	}
+
+	// Emit event
+	if err := h.eventService.EmitFacilityEvent(stub, config.EventFacilityOpened, facility, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(facility)
+}
+
+// DrawdownFacility draws funds against the available limit of a facility
+func (h *FacilityHandler) DrawdownFacility(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.FacilityTransactionRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse drawdown request: %v", err)
+	}
+
+	facility, err := h.getFacility(stub, req.FacilityID)
+	if err != nil {
+		return nil, err
+	}
+
+	if facility.Status != domain.FacilityStatusActive {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "drawdowns not permitted for facility in status: %s", facility.Status)
+	}
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	if now.After(facility.ExpiryDate) {
+		return nil, fmt.Errorf("facility %s expired on %s", facility.FacilityID, utils.FormatTime(facility.ExpiryDate))
+	}
+	if req.Amount > facility.AvailableAmount+balanceTolerance {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "drawdown %.2f exceeds available amount %.2f", req.Amount, facility.AvailableAmount)
+	}
+
+	return h.postFacilityTransaction(stub, facility, domain.FacilityTransactionDrawdown, &req, config.EventFacilityDrawdown)
+}
+
+// RepayFacility repays drawn funds, restoring the available limit
+func (h *FacilityHandler) RepayFacility(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.FacilityTransactionRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse repayment request: %v", err)
+	}
+
+	facility, err := h.getFacility(stub, req.FacilityID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Frozen and expired facilities still accept repayments
+	if facility.Status != domain.FacilityStatusActive && facility.Status != domain.FacilityStatusFrozen {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "repayments not permitted for facility in status: %s", facility.Status)
+	}
+	if req.Amount > facility.DrawnBalance+balanceTolerance {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "repayment %.2f exceeds drawn balance %.2f", req.Amount, facility.DrawnBalance)
+	}
+
+	return h.postFacilityTransaction(stub, facility, domain.FacilityTransactionRepayment, &req, config.EventFacilityRepayment)
+}
+
+// ReviewCreditFacility records a periodic limit review, adjusting the limit, expiry or freeze state
+func (h *FacilityHandler) ReviewCreditFacility(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.FacilityReviewRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse facility review request: %v", err)
+	}
+
+	facility, err := h.getFacility(stub, req.FacilityID)
+	if err != nil {
+		return nil, err
+	}
+
+	if facility.Status != domain.FacilityStatusActive && facility.Status != domain.FacilityStatusFrozen {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "facility cannot be reviewed in status: %s", facility.Status)
+	}
+	if req.ExtensionMonths < 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "extensionMonths cannot be negative")
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	previousLimit := facility.CreditLimit
+	previousStatus := facility.Status
+
+	// A reduced limit may leave the facility over-drawn; that blocks drawdowns until repaid
+	if req.NewCreditLimit > 0 && req.NewCreditLimit != facility.CreditLimit {
+		if err := validation.ValidateLoanAmount(req.NewCreditLimit, facility.LoanType); err != nil {
+			return nil, fmt.Errorf("invalid credit limit: %w", err)
+		}
+		facility.CreditLimit = req.NewCreditLimit
+	}
+
+	// Extensions run from the later of today and the current expiry
+	if req.ExtensionMonths > 0 {
+		base := facility.ExpiryDate
+		if now.After(base) {
+			base = now
+		}
+		facility.ExpiryDate = base.AddDate(0, req.ExtensionMonths, 0)
+	}
+
+	if req.Freeze {
+		facility.Status = domain.FacilityStatusFrozen
+	} else {
+		facility.Status = domain.FacilityStatusActive
+	}
+
+	applyFacilityBalance(facility, facility.DrawnBalance)
+	facility.LastReviewDate = &now
+	facility.LastReviewedBy = req.ActorID
+	facility.Notes = req.Notes
+	facility.LastUpdated = now
+	facility.LastUpdatedBy = req.ActorID
+
+	if err := h.persistenceService.Put(stub, fmt.Sprintf("FACILITY_%s", facility.FacilityID), facility); err != nil {
+		return nil, fmt.Errorf("failed to update credit facility: %w", err)
+	}
+
+	// Record history
+	if facility.CreditLimit != previousLimit {
+		if err := h.recordEntityHistory(stub, facility.FacilityID, "CreditFacility", "LIMIT_REVIEW", "creditLimit", formatAmount(previousLimit), formatAmount(facility.CreditLimit), req.ActorID); err != nil {
+			return nil, err
+		}
+	}
+	if facility.Status != previousStatus {
+		if err := h.recordEntityHistory(stub, facility.FacilityID, "CreditFacility", "STATUS_UPDATE", "status", string(previousStatus), string(facility.Status), req.ActorID); err != nil {
+			return nil, err
+		}
+	}
+	if err := h.recordEntityHistory(stub, facility.FacilityID, "CreditFacility", "REVIEW", "expiryDate", "", utils.FormatTime(facility.ExpiryDate), req.ActorID); err != nil {
+		return nil, err
+	}
+
+	// Emit event
+	if err := h.eventService.EmitFacilityEvent(stub, config.EventFacilityReviewed, facility, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(facility)
+}
+
+// ConvertFacilityToTermLoan terms out the drawn balance of a facility into a disbursed term loan
+func (h *FacilityHandler) ConvertFacilityToTermLoan(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.FacilityConversionRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse facility conversion request: %v", err)
+	}
+
+	facility, err := h.getFacility(stub, req.FacilityID)
+	if err != nil {
+		return nil, err
+	}
+
+	if facility.Status != domain.FacilityStatusActive && facility.Status != domain.FacilityStatusFrozen {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "facility cannot be converted in status: %s", facility.Status)
+	}
+	if facility.DrawnBalance <= 0 {
+		return nil, fmt.Errorf("facility %s has no drawn balance to convert", facility.FacilityID)
+	}
+	if req.TermMonths <= 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "termMonths must be positive")
+	}
+
+	// Create the term loan on the facility's pricing
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	amount := facility.DrawnBalance
+	principal := utils.NewMoney(amount, config.BaseCurrency)
+	interestRate := facility.InterestRate
+	loanApp := &domain.LoanApplication{
+		LoanID:          services.GenerateDeterministicID(stub, config.LoanApplicationPrefix),
+		CustomerID:      facility.CustomerID,
+		Parties: []domain.LoanParty{
+			{CustomerID: facility.CustomerID, Role: validation.LoanPartyRolePrimaryBorrower, LiabilityShare: 100},
+		},
+		LoanType:        facility.LoanType,
+		Currency:        config.BaseCurrency,
+		RequestedAmount: principal,
+		DisbursedAmount:    utils.ZeroMoney(config.BaseCurrency),
+		OutstandingBalance: utils.ZeroMoney(config.BaseCurrency),
+		AccruedInterest:    utils.ZeroMoney(config.BaseCurrency),
+		TaxWithheld:        utils.ZeroMoney(config.BaseCurrency),
+		TermMonths:      req.TermMonths,
+		Purpose:         fmt.Sprintf("Conversion of credit facility %s", facility.FacilityID),
+		Status:          validation.LoanStatusApproved,
+		ApplicationDate: now,
+		ApprovedAmount:  &principal,
+		InterestRate:    &interestRate,
+		RateIndex:       facility.RateIndex,
+		RateMargin:      facility.RateMargin,
+		IndexRateDate:   facility.IndexRateDate,
+		DecisionDate:    &now,
+		Sandbox:         facility.Sandbox,
+		Notes:           "",
+		CreatedDate:     now,
+		LastUpdated:     now,
+		CreatedBy:       req.ActorID,
+		LastUpdatedBy:   req.ActorID,
+	}
+
+	// Disburse the balance onto the loan ledger so it reconciles like any other loan
+	if _, err := recordDisbursement(stub, h.persistenceService, loanApp, principal, facility.FacilityID, "", "", req.ActorID); err != nil {
+		return nil, err
+	}
+
+	if err := putLoanApplication(stub, h.persistenceService, loanApp); err != nil {
+		return nil, fmt.Errorf("failed to store loan application: %w", err)
+	}
+	if err := putLoanPartyIndex(stub, h.persistenceService, loanApp); err != nil {
+		return nil, err
+	}
+	if _, err := h.scheduleService.GenerateSchedule(stub, loanApp, now); err != nil {
+		return nil, err
+	}
+
+	// Clear the facility balance against the new loan
+	if _, err := h.storeFacilityTransaction(stub, facility, domain.FacilityTransactionRepayment, amount, loanApp.LoanID, "", req.ActorID); err != nil {
+		return nil, err
+	}
+	facility.Status = domain.FacilityStatusConverted
+	facility.ConvertedLoanID = loanApp.LoanID
+	facility.AvailableAmount = 0
+
+	if err := h.persistenceService.Put(stub, fmt.Sprintf("FACILITY_%s", facility.FacilityID), facility); err != nil {
+		return nil, fmt.Errorf("failed to update credit facility: %w", err)
+	}
+
+	// Record history
+	loanJSON, _ := utils.MarshalJSONString(loanApp)
+	if err := h.recordEntityHistory(stub, loanApp.LoanID, "LoanApplication", "CREATE", "loan_application", "", loanJSON, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to record history: %w", err)
+	}
+	if err := h.recordEntityHistory(stub, facility.FacilityID, "CreditFacility", "CONVERSION", "convertedLoanID", "", loanApp.LoanID, req.ActorID); err != nil {
+		return nil, err
+	}
+
+	// Emit event
+	if err := h.eventService.EmitFacilityEvent(stub, config.EventFacilityConverted, facility, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(loanApp)
+}
+
+// GetCreditFacility retrieves a credit facility by ID
+func (h *FacilityHandler) GetCreditFacility(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	facility, err := h.getFacility(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(facility)
+}
+
+// GetFacilityTransactions retrieves all drawdowns and repayments against a facility
+func (h *FacilityHandler) GetFacilityTransactions(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	iterator, err := stub.GetStateByPartialCompositeKey("FACILITY_TRANSACTION", []string{args[0]})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get facility transactions: %w", err)
+	}
+	defer iterator.Close()
+
+	transactions := []domain.FacilityTransaction{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate facility transactions: %w", err)
+		}
+
+		var txn domain.FacilityTransaction
+		if err := json.Unmarshal(response.Value, &txn); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal facility transaction: %w", err)
+		}
+
+		transactions = append(transactions, txn)
+	}
+
+	return json.Marshal(transactions)
+}
+
+// QueryFacilitiesByCustomer retrieves all credit facilities for a customer
+func (h *FacilityHandler) QueryFacilitiesByCustomer(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	iterator, err := stub.GetStateByPartialCompositeKey("CUSTOMER_FACILITY", []string{args[0]})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query facilities by customer: %w", err)
+	}
+	defer iterator.Close()
+
+	facilities := []domain.CreditFacility{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate facilities: %w", err)
+		}
+
+		facility, err := h.getFacility(stub, string(response.Value))
+		if err != nil {
+			continue // Skip if facility not found
+		}
+
+		facilities = append(facilities, *facility)
+	}
+
+	return json.Marshal(facilities)
+}
+
+// Helper methods
+
+func (h *FacilityHandler) getFacility(stub shim.ChaincodeStubInterface, facilityID string) (*domain.CreditFacility, error) {
+	var facility domain.CreditFacility
+	if err := h.persistenceService.Get(stub, fmt.Sprintf("FACILITY_%s", facilityID), &facility); err != nil {
+		return nil, fmt.Errorf("credit facility not found: %w", err)
+	}
+	return &facility, nil
+}
+
+// postFacilityTransaction applies a validated drawdown or repayment and persists the facility
+func (h *FacilityHandler) postFacilityTransaction(stub shim.ChaincodeStubInterface, facility *domain.CreditFacility, txnType domain.FacilityTransactionType, req *domain.FacilityTransactionRequest, eventName string) ([]byte, error) {
+	if req.Amount <= 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "transaction amount must be positive")
+	}
+
+	// Money may only move with an active, onboarded counterparty
+	if req.CounterpartyID != "" {
+		if _, err := getActiveCounterparty(stub, h.persistenceService, req.CounterpartyID); err != nil {
+			return nil, err
+		}
+	}
+
+	previousBalance := facility.DrawnBalance
+	txn, err := h.storeFacilityTransaction(stub, facility, txnType, req.Amount, req.Reference, req.CounterpartyID, req.ActorID)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.persistenceService.Put(stub, fmt.Sprintf("FACILITY_%s", facility.FacilityID), facility); err != nil {
+		return nil, fmt.Errorf("failed to update credit facility: %w", err)
+	}
+
+	// Record history
+	if err := h.recordEntityHistory(stub, facility.FacilityID, "CreditFacility", string(txnType), "drawnBalance", formatAmount(previousBalance), formatAmount(facility.DrawnBalance), req.ActorID); err != nil {
+		return nil, err
+	}
+
+	// Emit event
+	if err := h.eventService.EmitFacilityEvent(stub, eventName, facility, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(txn)
+}
+
+// storeFacilityTransaction stores a facility transaction and applies it to the facility's balance; the caller persists the facility
+func (h *FacilityHandler) storeFacilityTransaction(stub shim.ChaincodeStubInterface, facility *domain.CreditFacility, txnType domain.FacilityTransactionType, amount float64, reference, counterpartyID, actorID string) (*domain.FacilityTransaction, error) {
+	newBalance := facility.DrawnBalance + amount
+	if txnType == domain.FacilityTransactionRepayment {
+		newBalance = facility.DrawnBalance - amount
+	}
+	if newBalance < 0 {
+		newBalance = 0
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	txn := &domain.FacilityTransaction{
+		TransactionID:   services.GenerateDeterministicID(stub, config.FacilityTransactionPrefix),
+		FacilityID:      facility.FacilityID,
+		TransactionType: txnType,
+		Amount:          amount,
+		Reference:       reference,
+		CounterpartyID:  counterpartyID,
+		BalanceAfter:    roundToCents(newBalance),
+		CreatedDate:     now,
+		CreatedBy:       actorID,
+	}
+
+	txnKey, err := stub.CreateCompositeKey("FACILITY_TRANSACTION", []string{facility.FacilityID, txn.TransactionID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := h.persistenceService.Put(stub, txnKey, txn); err != nil {
+		return nil, fmt.Errorf("failed to store facility transaction: %w", err)
+	}
+
+	applyFacilityBalance(facility, txn.BalanceAfter)
+	facility.LastUpdated = now
+	facility.LastUpdatedBy = actorID
+
+	return txn, nil
+}
+
+// applyFacilityBalance sets the drawn balance and recomputes availability and utilization
+func applyFacilityBalance(facility *domain.CreditFacility, drawnBalance float64) {
+	facility.DrawnBalance = roundToCents(drawnBalance)
+	facility.AvailableAmount = roundToCents(facility.CreditLimit - facility.DrawnBalance)
+	if facility.AvailableAmount < 0 {
+		facility.AvailableAmount = 0
+	}
+	facility.Utilization = 0
+	if facility.CreditLimit > 0 {
+		facility.Utilization = roundToCents(facility.DrawnBalance / facility.CreditLimit * 100)
+	}
+}
+
+func (h *FacilityHandler) recordEntityHistory(stub shim.ChaincodeStubInterface, entityID, entityType, changeType, fieldName, previousValue, newValue, actorID string) error {
+	historyID, compositeKey, err := services.NewHistoryKey(stub, entityID)
+	if err != nil {
+		return err
+	}
+	txID := stub.GetTxID()
+
+	timestamp, err := services.TxTimeString(stub)
+	if err != nil {
+		return err
+	}
+
+	historyEntry := map[string]interface{}{
+		"historyID":     historyID,
+		"entityID":      entityID,
+		"entityType":    entityType,
+		"timestamp":     timestamp,
+		"changeType":    changeType,
+		"fieldName":     fieldName,
+		"previousValue": previousValue,
+		"newValue":      newValue,
+		"actorID":       actorID,
+		"transactionID": txID,
+	}
+
+	return h.persistenceService.Put(stub, compositeKey, historyEntry)
+}
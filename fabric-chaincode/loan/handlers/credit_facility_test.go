@@ -0,0 +1,115 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// openFacility opens a personal credit facility for an eligible customer
+func openFacility(t *testing.T, stub *shimtest.MockStub, creditLimit float64) *domain.CreditFacility {
+	t.Helper()
+	withCustomers(stub, eligibleCustomer("CUST_001", 10))
+	payload, err := inTx(stub, "open_facility", func() ([]byte, error) {
+		return NewFacilityHandler().OpenCreditFacility(stub, []string{mustJSON(t, domain.CreditFacilityRequest{
+			CustomerID: "CUST_001", LoanType: "PERSONAL", CreditLimit: creditLimit, InterestRate: 9.5, TermMonths: 24, ActorID: "ACTOR_005",
+		})})
+	})
+	if err != nil {
+		t.Fatalf("failed to open facility: %v", err)
+	}
+	var facility domain.CreditFacility
+	if err := json.Unmarshal(payload, &facility); err != nil {
+		t.Fatalf("failed to decode facility: %v", err)
+	}
+	return &facility
+}
+
+func drawdown(t *testing.T, stub *shimtest.MockStub, txID, facilityID string, amount float64) ([]byte, error) {
+	return inTx(stub, txID, func() ([]byte, error) {
+		return NewFacilityHandler().DrawdownFacility(stub, []string{mustJSON(t, domain.FacilityTransactionRequest{
+			FacilityID: facilityID, Amount: amount, Reference: txID, ActorID: "ACTOR_005",
+		})})
+	})
+}
+
+func getFacility(t *testing.T, stub *shimtest.MockStub, facilityID string) *domain.CreditFacility {
+	t.Helper()
+	var facility domain.CreditFacility
+	if err := services.NewPersistenceService().Get(stub, "FACILITY_"+facilityID, &facility); err != nil {
+		t.Fatalf("failed to load facility %s: %v", facilityID, err)
+	}
+	return &facility
+}
+
+func TestDrawdownFacilityEnforcesCreditLimit(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	facility := openFacility(t, stub, 10000)
+
+	if _, err := drawdown(t, stub, "draw_1", facility.FacilityID, 6000); err != nil {
+		t.Fatalf("drawdown within the limit failed: %v", err)
+	}
+
+	// One cent over what is left of the limit is refused and leaves the facility as it was
+	_, err := drawdown(t, stub, "draw_over", facility.FacilityID, 4000.01)
+	expectErrorCode(t, err, services.ErrCodeInvalidArgument)
+	if stored := getFacility(t, stub, facility.FacilityID); stored.DrawnBalance != 6000 || stored.AvailableAmount != 4000 {
+		t.Errorf("expected 6000 drawn and 4000 available after the refused drawdown, got %+v", stored)
+	}
+
+	// Drawing exactly what is left uses the whole limit
+	payload, err := drawdown(t, stub, "draw_rest", facility.FacilityID, 4000)
+	if err != nil {
+		t.Fatalf("drawdown of the exact remaining limit failed: %v", err)
+	}
+	var txn domain.FacilityTransaction
+	if err := json.Unmarshal(payload, &txn); err != nil {
+		t.Fatalf("failed to decode transaction: %v", err)
+	}
+	stored := getFacility(t, stub, facility.FacilityID)
+	if txn.BalanceAfter != 10000 || stored.DrawnBalance != 10000 || stored.AvailableAmount != 0 || stored.Utilization != 100 {
+		t.Errorf("expected the facility fully drawn at 100%% utilization, got %+v", stored)
+	}
+	_, err = drawdown(t, stub, "draw_full", facility.FacilityID, 0.01)
+	expectErrorCode(t, err, services.ErrCodeInvalidArgument)
+}
+
+func TestDrawdownFacilityRefusedOnceClosed(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	facility := openFacility(t, stub, 10000)
+	if _, err := drawdown(t, stub, "draw_1", facility.FacilityID, 2500); err != nil {
+		t.Fatalf("drawdown failed: %v", err)
+	}
+
+	// Terming out the balance converts the facility; no further draws are allowed against it
+	if _, err := inTx(stub, "convert", func() ([]byte, error) {
+		return NewFacilityHandler().ConvertFacilityToTermLoan(stub, []string{mustJSON(t, domain.FacilityConversionRequest{
+			FacilityID: facility.FacilityID, TermMonths: 12, ActorID: "ACTOR_005",
+		})})
+	}); err != nil {
+		t.Fatalf("conversion failed: %v", err)
+	}
+	_, err := drawdown(t, stub, "draw_converted", facility.FacilityID, 100)
+	expectErrorCode(t, err, services.ErrCodeInvalidTransition)
+
+	// A closed facility takes neither draws nor repayments
+	closed := getFacility(t, stub, facility.FacilityID)
+	closed.Status = domain.FacilityStatusClosed
+	if _, err := inTx(stub, "close", func() ([]byte, error) {
+		return nil, services.NewPersistenceService().Put(stub, "FACILITY_"+facility.FacilityID, closed)
+	}); err != nil {
+		t.Fatalf("failed to close facility: %v", err)
+	}
+	_, err = drawdown(t, stub, "draw_closed", facility.FacilityID, 100)
+	expectErrorCode(t, err, services.ErrCodeInvalidTransition)
+	_, err = inTx(stub, "repay_closed", func() ([]byte, error) {
+		return NewFacilityHandler().RepayFacility(stub, []string{mustJSON(t, domain.FacilityTransactionRequest{
+			FacilityID: facility.FacilityID, Amount: 100, ActorID: "ACTOR_005",
+		})})
+	})
+	expectErrorCode(t, err, services.ErrCodeInvalidTransition)
+}
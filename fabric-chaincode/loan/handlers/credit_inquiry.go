@@ -0,0 +1,340 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	loanServices "github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// CreditInquiryHandler handles soft and hard credit inquiry tracking
+type CreditInquiryHandler struct {
+	persistenceService *services.PersistenceService
+	eventService      *loanServices.EventService
+	customerVerifier  *loanServices.CustomerVerificationService
+}
+
+// NewCreditInquiryHandler creates a new credit inquiry handler
+func NewCreditInquiryHandler() *CreditInquiryHandler {
+	return &CreditInquiryHandler{
+		persistenceService: services.NewPersistenceService(),
+		eventService:      loanServices.NewEventService(),
+		customerVerifier:  loanServices.NewCustomerVerificationService(),
+	}
+}
+
+// RecordCreditInquiry records a standalone credit check, typically a soft pre-qualification pull.
+// Hard inquiries require the customer's credit check consent.
+func (h *CreditInquiryHandler) RecordCreditInquiry(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.CreditInquiryRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse credit inquiry request: %v", err)
+	}
+
+	if err := validation.ValidateCreditInquiryType(req.InquiryType); err != nil {
+		return nil, fmt.Errorf("invalid inquiry type: %w", err)
+	}
+	if strings.TrimSpace(req.Purpose) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "purpose", "purpose is required")
+	}
+
+	inquiryType := validation.CreditInquiryType(req.InquiryType)
+	if err := h.customerVerifier.CheckCreditInquiryConsent(stub, req.CustomerID, inquiryType); err != nil {
+		return nil, err
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	inquiry := &domain.CreditInquiry{
+		InquiryID:   services.GenerateDeterministicID(stub, config.CreditInquiryPrefix),
+		CustomerID:  req.CustomerID,
+		InquiryType: inquiryType,
+		Purpose:     req.Purpose,
+		InquiryDate: now,
+		RequestedBy: req.ActorID,
+	}
+	if err := putCreditInquiry(stub, h.persistenceService, inquiry); err != nil {
+		return nil, err
+	}
+
+	// Emit event
+	if err := h.eventService.EmitCreditInquiryRecorded(stub, inquiry, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(inquiry)
+}
+
+// RequestCreditCheck records an inquiry to a credit bureau. The customer's bureau sharing consent
+// must be in force, and hard inquiries also need credit check consent; the consent relied on is
+// kept with the inquiry.
+func (h *CreditInquiryHandler) RequestCreditCheck(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.CreditCheckRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse credit check request: %v", err)
+	}
+
+	if err := validation.ValidateCreditInquiryType(req.InquiryType); err != nil {
+		return nil, fmt.Errorf("invalid inquiry type: %w", err)
+	}
+	if strings.TrimSpace(req.BureauName) == "" || strings.TrimSpace(req.Purpose) == "" {
+		return nil, fmt.Errorf("bureauName and purpose are required")
+	}
+
+	// A check for a loan must be on one of its parties
+	referenceType := ""
+	if req.LoanID != "" {
+		var loanApp domain.LoanApplication
+		if err := h.persistenceService.Get(stub, fmt.Sprintf("LOAN_%s", req.LoanID), &loanApp); err != nil {
+			return nil, fmt.Errorf("loan application not found: %w", err)
+		}
+		if !isLoanParty(&loanApp, req.CustomerID) {
+			return nil, fmt.Errorf("customer %s is not a party to loan %s", req.CustomerID, req.LoanID)
+		}
+		referenceType = "LoanApplication"
+	}
+
+	inquiryType := validation.CreditInquiryType(req.InquiryType)
+	if err := h.customerVerifier.CheckCreditInquiryConsent(stub, req.CustomerID, inquiryType); err != nil {
+		return nil, err
+	}
+	consent, err := h.customerVerifier.CheckConsentInForce(stub, req.CustomerID, config.ConsentCreditBureauSharing)
+	if err != nil {
+		return nil, fmt.Errorf("credit bureau check refused: %w", err)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	inquiry := &domain.CreditInquiry{
+		InquiryID:        services.GenerateDeterministicID(stub, config.CreditInquiryPrefix),
+		CustomerID:       req.CustomerID,
+		InquiryType:      inquiryType,
+		ReferenceID:      req.LoanID,
+		ReferenceType:    referenceType,
+		Purpose:          req.Purpose,
+		InquiryDate:      now,
+		RequestedBy:      req.ActorID,
+		BureauName:       req.BureauName,
+		ConsentReference: consent.TransactionID,
+	}
+	if err := putCreditInquiry(stub, h.persistenceService, inquiry); err != nil {
+		return nil, err
+	}
+
+	// Emit event
+	if err := h.eventService.EmitCreditInquiryRecorded(stub, inquiry, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(inquiry)
+}
+
+// RecordCreditReport records the report a bureau returned for a credit check. Each inquiry takes
+// one report.
+func (h *CreditInquiryHandler) RecordCreditReport(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.CreditReportRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse credit report request: %v", err)
+	}
+
+	if strings.TrimSpace(req.ReportHash) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "reportHash", "reportHash is required")
+	}
+	if req.Score < 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "score cannot be negative")
+	}
+
+	inquiryKey, err := stub.CreateCompositeKey("CUSTOMER_CREDIT_INQUIRY", []string{req.CustomerID, req.InquiryID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %w", err)
+	}
+	var inquiry domain.CreditInquiry
+	if err := h.persistenceService.Get(stub, inquiryKey, &inquiry); err != nil {
+		return nil, fmt.Errorf("credit inquiry not found: %w", err)
+	}
+	if inquiry.BureauName == "" {
+		return nil, fmt.Errorf("credit inquiry %s was not requested from a credit bureau", req.InquiryID)
+	}
+	if inquiry.ReportID != "" {
+		return nil, fmt.Errorf("credit inquiry %s already has report %s", req.InquiryID, inquiry.ReportID)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.CreditReport{
+		ReportID:         services.GenerateDeterministicID(stub, config.CreditReportPrefix),
+		CustomerID:       inquiry.CustomerID,
+		InquiryID:        inquiry.InquiryID,
+		BureauName:       inquiry.BureauName,
+		ReportHash:       req.ReportHash,
+		Score:            req.Score,
+		InquiryDate:      inquiry.InquiryDate,
+		ConsentReference: inquiry.ConsentReference,
+		RecordedDate:     now,
+		RecordedBy:       req.ActorID,
+	}
+
+	reportKey, err := stub.CreateCompositeKey("CUSTOMER_CREDIT_REPORT", []string{report.CustomerID, report.ReportID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := h.persistenceService.Put(stub, reportKey, report); err != nil {
+		return nil, fmt.Errorf("failed to store credit report: %w", err)
+	}
+
+	inquiry.ReportID = report.ReportID
+	if err := putCreditInquiry(stub, h.persistenceService, &inquiry); err != nil {
+		return nil, err
+	}
+
+	// Emit event
+	if err := h.eventService.EmitCreditReportRecorded(stub, report, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(report)
+}
+
+// GetCreditReports retrieves the bureau reports recorded for a customer
+func (h *CreditInquiryHandler) GetCreditReports(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	iterator, err := stub.GetStateByPartialCompositeKey("CUSTOMER_CREDIT_REPORT", []string{args[0]})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query credit reports: %w", err)
+	}
+	defer iterator.Close()
+
+	reports := []domain.CreditReport{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate credit reports: %w", err)
+		}
+
+		var report domain.CreditReport
+		if err := json.Unmarshal(response.Value, &report); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal credit report: %w", err)
+		}
+		reports = append(reports, report)
+	}
+
+	return json.Marshal(reports)
+}
+
+// GetCreditInquiryHistory retrieves a customer's credit inquiries with soft and hard counts over
+// each rolling window in config.CreditInquiryWindowDays
+func (h *CreditInquiryHandler) GetCreditInquiryHistory(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	iterator, err := stub.GetStateByPartialCompositeKey("CUSTOMER_CREDIT_INQUIRY", []string{args[0]})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query credit inquiries: %w", err)
+	}
+	defer iterator.Close()
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	history := &domain.CreditInquiryHistory{
+		CustomerID: args[0],
+		Inquiries:  []domain.CreditInquiry{},
+		Windows:    []domain.CreditInquiryWindow{},
+		AsOf:       now,
+	}
+	for _, days := range config.CreditInquiryWindowDays {
+		history.Windows = append(history.Windows, domain.CreditInquiryWindow{Days: days})
+	}
+
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate credit inquiries: %w", err)
+		}
+
+		var inquiry domain.CreditInquiry
+		if err := json.Unmarshal(response.Value, &inquiry); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal credit inquiry: %w", err)
+		}
+		history.Inquiries = append(history.Inquiries, inquiry)
+
+		for i := range history.Windows {
+			window := &history.Windows[i]
+			if inquiry.InquiryDate.Before(history.AsOf.AddDate(0, 0, -window.Days)) {
+				continue
+			}
+			if inquiry.InquiryType == validation.CreditInquiryHard {
+				window.HardCount++
+			} else {
+				window.SoftCount++
+			}
+		}
+	}
+
+	return json.Marshal(history)
+}
+
+// recordHardInquiry records the hard credit pull made by a loan or facility application. Callers
+// verify the customer, including credit check consent, beforehand.
+func recordHardInquiry(stub shim.ChaincodeStubInterface, persistenceService *services.PersistenceService, customerID, referenceID, referenceType, purpose, actorID string) error {
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return err
+	}
+
+	inquiry := &domain.CreditInquiry{
+		InquiryID:     services.GenerateDeterministicID(stub, config.CreditInquiryPrefix),
+		CustomerID:    customerID,
+		InquiryType:   validation.CreditInquiryHard,
+		ReferenceID:   referenceID,
+		ReferenceType: referenceType,
+		Purpose:       purpose,
+		InquiryDate:   now,
+		RequestedBy:   actorID,
+	}
+	return putCreditInquiry(stub, persistenceService, inquiry)
+}
+
+func putCreditInquiry(stub shim.ChaincodeStubInterface, persistenceService *services.PersistenceService, inquiry *domain.CreditInquiry) error {
+	inquiryKey, err := stub.CreateCompositeKey("CUSTOMER_CREDIT_INQUIRY", []string{inquiry.CustomerID, inquiry.InquiryID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := persistenceService.Put(stub, inquiryKey, inquiry); err != nil {
+		return fmt.Errorf("failed to store credit inquiry: %w", err)
+	}
+	return nil
+}
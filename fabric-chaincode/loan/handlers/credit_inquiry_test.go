@@ -0,0 +1,249 @@
+package handlers
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/interfaces"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// inquiryTime is when the inquiry history is read in the credit inquiry tests
+var inquiryTime = time.Date(2026, 6, 30, 12, 0, 0, 0, time.UTC)
+
+func recordInquiry(t *testing.T, stub *shimtest.MockStub, txID, customerID string, inquiryType validation.CreditInquiryType, daysAgo int) error {
+	_, err := inTxAt(stub, txID, inquiryTime.AddDate(0, 0, -daysAgo), func() ([]byte, error) {
+		return NewCreditInquiryHandler().RecordCreditInquiry(stub, []string{mustJSON(t, domain.CreditInquiryRequest{
+			CustomerID: customerID, InquiryType: string(inquiryType), Purpose: "Pre-qualification quote", ActorID: "ACTOR_005",
+		})})
+	})
+	return err
+}
+
+func getInquiryHistory(t *testing.T, stub *shimtest.MockStub, customerID string) *domain.CreditInquiryHistory {
+	t.Helper()
+	payload, err := inTxAt(stub, "history_"+customerID, inquiryTime, func() ([]byte, error) {
+		return NewCreditInquiryHandler().GetCreditInquiryHistory(stub, []string{customerID})
+	})
+	if err != nil {
+		t.Fatalf("GetCreditInquiryHistory failed: %v", err)
+	}
+	var history domain.CreditInquiryHistory
+	if err := json.Unmarshal(payload, &history); err != nil {
+		t.Fatalf("failed to decode inquiry history: %v", err)
+	}
+	return &history
+}
+
+func TestHardCreditInquiryRequiresConsent(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	withheldConsent := eligibleCustomer("CUST_002", 10)
+	withheldConsent.ConsentPreferences = `{"creditCheck": false}`
+	withCustomers(stub, eligibleCustomer("CUST_001", 10), withheldConsent)
+
+	// A soft pull needs no consent; a hard pull does
+	if err := recordInquiry(t, stub, "soft", "CUST_002", validation.CreditInquirySoft, 0); err != nil {
+		t.Fatalf("soft inquiry without consent failed: %v", err)
+	}
+	if err := recordInquiry(t, stub, "hard", "CUST_002", validation.CreditInquiryHard, 0); err == nil || !strings.Contains(err.Error(), "requires consent creditCheck") {
+		t.Errorf("expected a hard inquiry without consent to be refused, got %v", err)
+	}
+	if err := recordInquiry(t, stub, "unknown", "CUST_404", validation.CreditInquirySoft, 0); err == nil {
+		t.Errorf("expected an inquiry on an unknown customer to be refused")
+	}
+	if err := recordInquiry(t, stub, "medium", "CUST_001", validation.CreditInquiryType("MEDIUM"), 0); err == nil {
+		t.Errorf("expected an unknown inquiry type to be refused")
+	}
+	if history := getInquiryHistory(t, stub, "CUST_002"); len(history.Inquiries) != 1 || history.Inquiries[0].InquiryType != validation.CreditInquirySoft {
+		t.Errorf("expected only the soft inquiry recorded, got %+v", history.Inquiries)
+	}
+}
+
+func TestCreditInquiryHistoryCountsRollingWindows(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	withCustomers(stub, eligibleCustomer("CUST_001", 10))
+	for _, inquiry := range []struct {
+		txID        string
+		inquiryType validation.CreditInquiryType
+		daysAgo     int
+	}{
+		{"soft_400", validation.CreditInquirySoft, 400},
+		{"hard_200", validation.CreditInquiryHard, 200},
+		{"soft_60", validation.CreditInquirySoft, 60},
+		{"hard_30", validation.CreditInquiryHard, 30},
+		{"soft_1", validation.CreditInquirySoft, 1},
+	} {
+		if err := recordInquiry(t, stub, inquiry.txID, "CUST_001", inquiry.inquiryType, inquiry.daysAgo); err != nil {
+			t.Fatalf("inquiry %s failed: %v", inquiry.txID, err)
+		}
+	}
+
+	// Opening a facility is a hard pull against the customer
+	if _, err := inTxAt(stub, "open", inquiryTime.AddDate(0, 0, -2), func() ([]byte, error) {
+		return NewFacilityHandler().OpenCreditFacility(stub, []string{mustJSON(t, domain.CreditFacilityRequest{
+			CustomerID: "CUST_001", LoanType: "PERSONAL", CreditLimit: 5000, InterestRate: 9.5, TermMonths: 24, ActorID: "ACTOR_005",
+		})})
+	}); err != nil {
+		t.Fatalf("failed to open facility: %v", err)
+	}
+
+	history := getInquiryHistory(t, stub, "CUST_001")
+	if len(history.Inquiries) != 6 {
+		t.Fatalf("expected every inquiry in the history, got %d", len(history.Inquiries))
+	}
+	facilityPulls := 0
+	for _, inquiry := range history.Inquiries {
+		if inquiry.ReferenceType == "CreditFacility" && inquiry.InquiryType == validation.CreditInquiryHard {
+			facilityPulls++
+		}
+	}
+	if facilityPulls != 1 {
+		t.Errorf("expected the facility application recorded as a hard inquiry, got %+v", history.Inquiries)
+	}
+
+	// Windows include inquiries exactly their length ago and nothing older
+	expected := map[int][2]int{30: {1, 2}, 90: {2, 2}, 365: {2, 3}}
+	if len(history.Windows) != len(expected) {
+		t.Fatalf("expected windows %v, got %+v", expected, history.Windows)
+	}
+	for _, window := range history.Windows {
+		if counts := expected[window.Days]; window.SoftCount != counts[0] || window.HardCount != counts[1] {
+			t.Errorf("expected %d soft and %d hard in the last %d days, got %+v", counts[0], counts[1], window.Days, window)
+		}
+	}
+}
+
+func requestCreditCheck(t *testing.T, stub *shimtest.MockStub, txID string, at time.Time, req domain.CreditCheckRequest) (*domain.CreditInquiry, error) {
+	t.Helper()
+	payload, err := inTxAt(stub, txID, at, func() ([]byte, error) {
+		return NewCreditInquiryHandler().RequestCreditCheck(stub, []string{mustJSON(t, req)})
+	})
+	if err != nil {
+		return nil, err
+	}
+	var inquiry domain.CreditInquiry
+	if err := json.Unmarshal(payload, &inquiry); err != nil {
+		t.Fatalf("failed to decode inquiry: %v", err)
+	}
+	return &inquiry, nil
+}
+
+func TestCreditBureauCheckRequiresSharingConsentInForce(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	withheldConsent := eligibleCustomer("CUST_003", 10)
+	withheldConsent.ConsentPreferences = `{"creditCheck": false}`
+	customers := withCustomers(stub, eligibleCustomer("CUST_001", 10), eligibleCustomer("CUST_002", 10), withheldConsent)
+	expiry := inquiryTime.AddDate(0, 0, -10)
+	customers.withConsent("CUST_001", config.ConsentCreditBureauSharing, interfaces.ConsentGrant{
+		Version: 1, Granted: true, EffectiveDate: inquiryTime.AddDate(0, -3, 0), ExpiryDate: &expiry, TransactionID: "TX_CONSENT_1",
+	})
+	customers.withConsent("CUST_003", config.ConsentCreditBureauSharing, interfaces.ConsentGrant{
+		Version: 1, Granted: true, EffectiveDate: inquiryTime.AddDate(0, -3, 0), TransactionID: "TX_CONSENT_3",
+	})
+	seedLoan(t, stub, "LOAN_CB1", validation.LoanStatusSubmitted)
+	check := func(customerID, inquiryType, loanID string) domain.CreditCheckRequest {
+		return domain.CreditCheckRequest{
+			CustomerID: customerID, BureauName: "Equifax", InquiryType: inquiryType, Purpose: "Loan underwriting", LoanID: loanID, ActorID: "ACTOR_005",
+		}
+	}
+	checkDate := inquiryTime.AddDate(0, 0, -20)
+
+	// The bureau check keeps the consent it relied on and the loan it was made for
+	inquiry, err := requestCreditCheck(t, stub, "check_1", checkDate, check("CUST_001", "HARD", "LOAN_CB1"))
+	if err != nil {
+		t.Fatalf("credit check failed: %v", err)
+	}
+	if inquiry.BureauName != "Equifax" || inquiry.ConsentReference != "TX_CONSENT_1" || inquiry.ReferenceType != "LoanApplication" || !inquiry.InquiryDate.Equal(checkDate) {
+		t.Errorf("unexpected bureau inquiry: %+v", inquiry)
+	}
+
+	// Missing, expired and unrelated consents are all refused
+	if _, err := requestCreditCheck(t, stub, "check_no_consent", checkDate, check("CUST_002", "SOFT", "")); err == nil || !strings.Contains(err.Error(), "credit bureau check refused") {
+		t.Errorf("expected a customer without sharing consent to be refused, got %v", err)
+	}
+	if _, err := requestCreditCheck(t, stub, "check_expired", inquiryTime, check("CUST_001", "SOFT", "")); err == nil || !strings.Contains(err.Error(), "not granted") {
+		t.Errorf("expected an expired sharing consent to be refused, got %v", err)
+	}
+	if _, err := requestCreditCheck(t, stub, "check_hard", checkDate, check("CUST_003", "HARD", "")); err == nil || !strings.Contains(err.Error(), "requires consent creditCheck") {
+		t.Errorf("expected a hard check without credit check consent to be refused, got %v", err)
+	}
+	if _, err := requestCreditCheck(t, stub, "check_party", checkDate, check("CUST_003", "SOFT", "LOAN_CB1")); err == nil || !strings.Contains(err.Error(), "not a party") {
+		t.Errorf("expected a check on a non-party to be refused, got %v", err)
+	}
+	noBureau := check("CUST_003", "SOFT", "")
+	noBureau.BureauName = " "
+	if _, err := requestCreditCheck(t, stub, "check_no_bureau", checkDate, noBureau); err == nil {
+		t.Error("expected a check without a bureau to be refused")
+	}
+	if _, err := requestCreditCheck(t, stub, "check_soft", checkDate, check("CUST_003", "SOFT", "")); err != nil {
+		t.Errorf("expected a soft check with sharing consent to pass, got %v", err)
+	}
+
+	// Each bureau inquiry takes exactly one report
+	recordReport := func(txID, inquiryID, reportHash string) (*domain.CreditReport, error) {
+		payload, err := inTxAt(stub, txID, checkDate.Add(time.Hour), func() ([]byte, error) {
+			return NewCreditInquiryHandler().RecordCreditReport(stub, []string{mustJSON(t, domain.CreditReportRequest{
+				CustomerID: "CUST_001", InquiryID: inquiryID, ReportHash: reportHash, Score: 712, ActorID: "ACTOR_005",
+			})})
+		})
+		if err != nil {
+			return nil, err
+		}
+		var report domain.CreditReport
+		if err := json.Unmarshal(payload, &report); err != nil {
+			t.Fatalf("failed to decode report: %v", err)
+		}
+		return &report, nil
+	}
+	if _, err := recordReport("report_no_hash", inquiry.InquiryID, ""); err == nil {
+		t.Error("expected a report without a hash to be refused")
+	}
+	if err := recordInquiry(t, stub, "soft_internal", "CUST_001", validation.CreditInquirySoft, 25); err != nil {
+		t.Fatalf("internal inquiry failed: %v", err)
+	}
+	history := getInquiryHistory(t, stub, "CUST_001")
+	if len(history.Inquiries) != 2 {
+		t.Fatalf("expected the bureau and internal inquiries, got %+v", history.Inquiries)
+	}
+	for _, internal := range history.Inquiries {
+		if internal.BureauName != "" {
+			continue
+		}
+		if _, err := recordReport("report_internal", internal.InquiryID, "sha256:aa"); err == nil || !strings.Contains(err.Error(), "not requested from a credit bureau") {
+			t.Errorf("expected a report against an internal inquiry to be refused, got %v", err)
+		}
+	}
+	report, err := recordReport("report_1", inquiry.InquiryID, "sha256:bb")
+	if err != nil {
+		t.Fatalf("failed to record report: %v", err)
+	}
+	if report.ConsentReference != "TX_CONSENT_1" || report.BureauName != "Equifax" || report.Score != 712 || !report.InquiryDate.Equal(checkDate) {
+		t.Errorf("unexpected credit report: %+v", report)
+	}
+	if _, err := recordReport("report_again", inquiry.InquiryID, "sha256:cc"); err == nil || !strings.Contains(err.Error(), "already has report") {
+		t.Errorf("expected a second report to be refused, got %v", err)
+	}
+
+	payload, err := inTx(stub, "reports", func() ([]byte, error) {
+		return NewCreditInquiryHandler().GetCreditReports(stub, []string{"CUST_001"})
+	})
+	if err != nil {
+		t.Fatalf("failed to list reports: %v", err)
+	}
+	var reports []domain.CreditReport
+	if err := json.Unmarshal(payload, &reports); err != nil || len(reports) != 1 || reports[0].ReportHash != "sha256:bb" {
+		t.Errorf("expected the one report listed, got %+v (%v)", reports, err)
+	}
+	linked := false
+	for _, recorded := range getInquiryHistory(t, stub, "CUST_001").Inquiries {
+		linked = linked || recorded.ReportID == report.ReportID
+	}
+	if !linked {
+		t.Error("expected the inquiry to reference its report")
+	}
+}
@@ -0,0 +1,367 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	loanServices "github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// corridorPeriodFormat buckets corridor report entries into monthly reporting periods
+const corridorPeriodFormat = "2006-01"
+
+// CrossBorderHandler handles the corridor rules applied to cross-border disbursements and the
+// regulatory reports they generate
+type CrossBorderHandler struct {
+	persistenceService *services.PersistenceService
+	eventService      *loanServices.EventService
+}
+
+// NewCrossBorderHandler creates a new cross-border handler
+func NewCrossBorderHandler() *CrossBorderHandler {
+	return &CrossBorderHandler{
+		persistenceService: services.NewPersistenceService(),
+		eventService:      loanServices.NewEventService(),
+	}
+}
+
+// SetCorridorRule creates or replaces the rule for payments from customers resident in one
+// country to payees in another
+func (h *CrossBorderHandler) SetCorridorRule(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.CorridorRuleRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse corridor rule request: %v", err)
+	}
+
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return nil, err
+	}
+	if role != string(validation.ActorRoleComplianceOfficer) && role != string(validation.ActorRoleChiefComplianceOfficer) {
+		return nil, services.NewChaincodeError(services.ErrCodeAccessDenied, "", "corridor rules may only be set by a %s or %s", validation.ActorRoleComplianceOfficer, validation.ActorRoleChiefComplianceOfficer)
+	}
+
+	origin, err := normalizeCorridorCountry(req.OriginCountry)
+	if err != nil {
+		return nil, err
+	}
+	destination, err := normalizeCorridorCountry(req.DestinationCountry)
+	if err != nil {
+		return nil, err
+	}
+	if origin == destination && origin != domain.CorridorWildcard {
+		return nil, fmt.Errorf("a corridor needs different origin and destination countries")
+	}
+
+	switch req.Action {
+	case domain.CorridorActionAllow, domain.CorridorActionFlag:
+	case domain.CorridorActionBlock:
+		if strings.TrimSpace(req.Reason) == "" {
+			return nil, fmt.Errorf("reason is required to block a corridor")
+		}
+	default:
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid corridor action %s, expected %s, %s or %s", req.Action, domain.CorridorActionAllow, domain.CorridorActionFlag, domain.CorridorActionBlock)
+	}
+	if req.ReportingThreshold < 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "reportingThreshold cannot be negative")
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	rule := &domain.CorridorRule{
+		OriginCountry:      origin,
+		DestinationCountry: destination,
+		Action:             req.Action,
+		ReportingThreshold: req.ReportingThreshold,
+		ReportType:         strings.TrimSpace(req.ReportType),
+		Reason:             req.Reason,
+		LastUpdated:        now,
+		LastUpdatedBy:      req.ActorID,
+	}
+
+	ruleKey := corridorRuleKey(origin, destination)
+	previousJSON := ""
+	if previous, err := stub.GetState(ruleKey); err != nil {
+		return nil, fmt.Errorf("failed to read corridor rule: %w", err)
+	} else if previous != nil {
+		previousJSON = string(previous)
+	}
+
+	if err := h.persistenceService.Put(stub, ruleKey, rule); err != nil {
+		return nil, fmt.Errorf("failed to store corridor rule: %w", err)
+	}
+
+	// Record history
+	ruleJSON, _ := utils.MarshalJSONString(rule)
+	if err := h.recordEntityHistory(stub, fmt.Sprintf("%s_%s", origin, destination), "CorridorRule", "UPDATE", "corridorRule", previousJSON, ruleJSON, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to record history: %w", err)
+	}
+
+	// Emit event
+	if err := h.eventService.EmitCorridorRuleUpdated(stub, rule, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(rule)
+}
+
+// GetCorridorRule retrieves the rule that applies to a corridor, falling back to wildcard rules.
+// Args: originCountry, destinationCountry
+func (h *CrossBorderHandler) GetCorridorRule(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 2, got %d", len(args))
+	}
+
+	origin, err := normalizeCorridorCountry(args[0])
+	if err != nil {
+		return nil, err
+	}
+	destination, err := normalizeCorridorCountry(args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	rule, err := findCorridorRule(stub, h.persistenceService, origin, destination)
+	if err != nil {
+		return nil, err
+	}
+	if rule == nil {
+		return nil, fmt.Errorf("no corridor rule applies from %s to %s", origin, destination)
+	}
+
+	return json.Marshal(rule)
+}
+
+// GetCorridorReport lists the disbursements reported for a corridor in one month.
+// Args: originCountry, destinationCountry, period (YYYY-MM)
+func (h *CrossBorderHandler) GetCorridorReport(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 3 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 3, got %d", len(args))
+	}
+
+	origin, err := normalizeCorridorCountry(args[0])
+	if err != nil {
+		return nil, err
+	}
+	destination, err := normalizeCorridorCountry(args[1])
+	if err != nil {
+		return nil, err
+	}
+	if origin == domain.CorridorWildcard || destination == domain.CorridorWildcard {
+		return nil, fmt.Errorf("corridor reports are kept per country pair")
+	}
+	if _, err := time.Parse(corridorPeriodFormat, args[2]); err != nil {
+		return nil, fmt.Errorf("invalid period %s, expected YYYY-MM: %w", args[2], err)
+	}
+
+	iterator, err := stub.GetStateByPartialCompositeKey("CORRIDOR_REPORT", []string{origin, destination, args[2]})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query corridor report entries: %w", err)
+	}
+	defer iterator.Close()
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.CorridorReport{
+		OriginCountry:      origin,
+		DestinationCountry: destination,
+		Period:             args[2],
+		Entries:            []domain.CorridorReportEntry{},
+		GeneratedDate:      now,
+	}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate corridor report entries: %w", err)
+		}
+
+		var entry domain.CorridorReportEntry
+		if err := json.Unmarshal(response.Value, &entry); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal corridor report entry: %w", err)
+		}
+		report.Entries = append(report.Entries, entry)
+		report.TotalAmount = roundToCents(report.TotalAmount + entry.Amount)
+		if entry.Flagged {
+			report.FlaggedCount++
+		}
+	}
+	report.EntryCount = len(report.Entries)
+
+	return json.Marshal(report)
+}
+
+// Helper methods
+
+func (h *CrossBorderHandler) recordEntityHistory(stub shim.ChaincodeStubInterface, entityID, entityType, changeType, fieldName, previousValue, newValue, actorID string) error {
+	historyID, compositeKey, err := services.NewHistoryKey(stub, entityID)
+	if err != nil {
+		return err
+	}
+	txID := stub.GetTxID()
+
+	timestamp, err := services.TxTimeString(stub)
+	if err != nil {
+		return err
+	}
+
+	historyEntry := map[string]interface{}{
+		"historyID":     historyID,
+		"entityID":      entityID,
+		"entityType":    entityType,
+		"timestamp":     timestamp,
+		"changeType":    changeType,
+		"fieldName":     fieldName,
+		"previousValue": previousValue,
+		"newValue":      newValue,
+		"actorID":       actorID,
+		"transactionID": txID,
+	}
+
+	return h.persistenceService.Put(stub, compositeKey, historyEntry)
+}
+
+// checkCrossBorder applies the corridor rule to a disbursement whose payee country differs from
+// the customer's residency, rejecting blocked corridors. It returns a nil check for domestic
+// payments and payments with no payee country. Customers with no recorded residency are treated
+// as resident in the loan's jurisdiction.
+func checkCrossBorder(stub shim.ChaincodeStubInterface, persistenceService *services.PersistenceService, loanApp *domain.LoanApplication, payeeCountry string) (*domain.CrossBorderCheck, *domain.CorridorRule, error) {
+	if payeeCountry == "" {
+		return nil, nil, nil
+	}
+
+	residency, err := loanServices.NewCustomerVerificationService().CustomerResidency(stub, loanApp.CustomerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	origin := strings.ToUpper(residency)
+	if origin == "" {
+		origin = loanApp.Jurisdiction
+	}
+	if origin == "" {
+		return nil, nil, fmt.Errorf("customer %s has no residency to check a payment to %s against", loanApp.CustomerID, payeeCountry)
+	}
+	if origin == payeeCountry {
+		return nil, nil, nil
+	}
+
+	check := &domain.CrossBorderCheck{
+		OriginCountry:      origin,
+		DestinationCountry: payeeCountry,
+		Action:             domain.CorridorActionAllow,
+	}
+	rule, err := findCorridorRule(stub, persistenceService, origin, payeeCountry)
+	if err != nil {
+		return nil, nil, err
+	}
+	if rule == nil {
+		return check, nil, nil
+	}
+
+	check.RuleOrigin = rule.OriginCountry
+	check.RuleDestination = rule.DestinationCountry
+	check.Action = rule.Action
+	switch rule.Action {
+	case domain.CorridorActionBlock:
+		return nil, nil, fmt.Errorf("payments from %s to %s are blocked: %s", origin, payeeCountry, rule.Reason)
+	case domain.CorridorActionFlag:
+		check.Flagged = true
+	}
+	return check, rule, nil
+}
+
+// recordCorridorReport generates the corridor's regulatory report entry for a disbursement at or
+// above the reporting threshold, or flagged. Sandbox loans are kept out of regulatory reporting.
+func recordCorridorReport(stub shim.ChaincodeStubInterface, persistenceService *services.PersistenceService, loanApp *domain.LoanApplication, disbursement *domain.LoanDisbursement, rule *domain.CorridorRule, actorID string) error {
+	check := disbursement.CrossBorder
+	if check == nil || rule == nil || rule.ReportType == "" || loanApp.Sandbox {
+		return nil
+	}
+	if disbursement.Amount.Float64() < rule.ReportingThreshold && !check.Flagged {
+		return nil
+	}
+
+	entry := &domain.CorridorReportEntry{
+		EntryID:            services.GenerateDeterministicID(stub, config.CorridorReportPrefix),
+		ReportType:         rule.ReportType,
+		OriginCountry:      check.OriginCountry,
+		DestinationCountry: check.DestinationCountry,
+		Period:             disbursement.DisbursementDate.Format(corridorPeriodFormat),
+		LoanID:             loanApp.LoanID,
+		CustomerID:         loanApp.CustomerID,
+		DisbursementID:     disbursement.DisbursementID,
+		Amount:             disbursement.Amount.Float64(),
+		Threshold:          rule.ReportingThreshold,
+		Flagged:            check.Flagged,
+		DisbursementDate:   disbursement.DisbursementDate,
+		RecordedBy:         actorID,
+	}
+
+	entryKey, err := stub.CreateCompositeKey("CORRIDOR_REPORT", []string{entry.OriginCountry, entry.DestinationCountry, entry.Period, entry.EntryID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := persistenceService.Put(stub, entryKey, entry); err != nil {
+		return fmt.Errorf("failed to store corridor report entry: %w", err)
+	}
+
+	check.ReportEntryID = entry.EntryID
+	return nil
+}
+
+// findCorridorRule returns the most specific rule for a corridor: the exact country pair, then any
+// destination from the origin, then the destination from any origin, then the catch-all rule
+func findCorridorRule(stub shim.ChaincodeStubInterface, persistenceService *services.PersistenceService, origin, destination string) (*domain.CorridorRule, error) {
+	candidates := [][2]string{
+		{origin, destination},
+		{origin, domain.CorridorWildcard},
+		{domain.CorridorWildcard, destination},
+		{domain.CorridorWildcard, domain.CorridorWildcard},
+	}
+	for _, corridor := range candidates {
+		ruleKey := corridorRuleKey(corridor[0], corridor[1])
+		exists, err := persistenceService.Exists(stub, ruleKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check corridor rule: %w", err)
+		}
+		if !exists {
+			continue
+		}
+		var rule domain.CorridorRule
+		if err := persistenceService.Get(stub, ruleKey, &rule); err != nil {
+			return nil, fmt.Errorf("failed to get corridor rule: %w", err)
+		}
+		return &rule, nil
+	}
+	return nil, nil
+}
+
+func corridorRuleKey(origin, destination string) string {
+	return fmt.Sprintf("CORRIDOR_RULE_%s_%s", origin, destination)
+}
+
+// normalizeCorridorCountry upper-cases a corridor country, which must be an ISO 3166-1 alpha-2
+// code or the wildcard
+func normalizeCorridorCountry(country string) (string, error) {
+	country = strings.ToUpper(strings.TrimSpace(country))
+	if country != domain.CorridorWildcard && len(country) != 2 {
+		return "", services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid country %s, expected an ISO 3166-1 alpha-2 country code or %s", country, domain.CorridorWildcard)
+	}
+	return country, nil
+}
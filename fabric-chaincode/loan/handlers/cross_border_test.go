@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// corridorTime is when the cross-border tests disburse, in reporting period 2026-05
+var corridorTime = time.Date(2026, 5, 12, 14, 0, 0, 0, time.UTC)
+
+func setCorridorRule(t *testing.T, stub *shimtest.MockStub, txID string, req domain.CorridorRuleRequest) error {
+	t.Helper()
+	req.ActorID = "ACTOR_008"
+	_, err := inTx(stub, txID, func() ([]byte, error) {
+		return NewCrossBorderHandler().SetCorridorRule(stub, []string{mustJSON(t, req)})
+	})
+	return err
+}
+
+func disburseAbroad(t *testing.T, stub *shimtest.MockStub, txID, loanID string, amount float64, payeeCountry string) (*domain.LoanDisbursement, error) {
+	t.Helper()
+	payload, err := inTxAt(stub, txID, corridorTime, func() ([]byte, error) {
+		return NewDisbursementHandler().DisburseLoan(stub, []string{mustJSON(t, domain.LoanDisbursementRequest{
+			LoanID:                loanID,
+			Amount:                amount,
+			DestinationAccountRef: "IBAN-" + txID,
+			PayeeCountry:          payeeCountry,
+			ActorID:               "ACTOR_004",
+		})})
+	})
+	if err != nil {
+		return nil, err
+	}
+	var disbursement domain.LoanDisbursement
+	if err := json.Unmarshal(payload, &disbursement); err != nil {
+		t.Fatalf("failed to decode disbursement: %v", err)
+	}
+	return &disbursement, nil
+}
+
+func corridorReport(t *testing.T, stub *shimtest.MockStub, origin, destination, period string) *domain.CorridorReport {
+	t.Helper()
+	payload, err := inTx(stub, "report_"+origin+destination, func() ([]byte, error) {
+		return NewCrossBorderHandler().GetCorridorReport(stub, []string{origin, destination, period})
+	})
+	if err != nil {
+		t.Fatalf("failed to read %s-%s report: %v", origin, destination, err)
+	}
+	var report domain.CorridorReport
+	if err := json.Unmarshal(payload, &report); err != nil {
+		t.Fatalf("failed to decode corridor report: %v", err)
+	}
+	return &report
+}
+
+func TestCorridorRulesGateAndReportCrossBorderDisbursements(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleUnderwriter))
+	resident := eligibleCustomer("CUST_001", 10)
+	resident.Residency = "gb"
+	withCustomers(stub, resident, eligibleCustomer("CUST_002", 10))
+
+	allowFromGB := domain.CorridorRuleRequest{OriginCountry: "gb", DestinationCountry: "*", Action: domain.CorridorActionAllow, ReportingThreshold: 10000, ReportType: "CROSS_BORDER_FUNDS"}
+	expectErrorCode(t, setCorridorRule(t, stub, "rule_denied", allowFromGB), services.ErrCodeAccessDenied)
+
+	// Rules need a real corridor, a known action and a reason to block
+	stub.Creator = newTestIdentity(t, string(validation.ActorRoleComplianceOfficer))
+	if err := setCorridorRule(t, stub, "rule_domestic", domain.CorridorRuleRequest{OriginCountry: "GB", DestinationCountry: "gb", Action: domain.CorridorActionAllow}); err == nil {
+		t.Error("expected a corridor within one country to be refused")
+	}
+	expectErrorCode(t, setCorridorRule(t, stub, "rule_alpha3", domain.CorridorRuleRequest{OriginCountry: "GBR", DestinationCountry: "US", Action: domain.CorridorActionAllow}), services.ErrCodeInvalidArgument)
+	expectErrorCode(t, setCorridorRule(t, stub, "rule_action", domain.CorridorRuleRequest{OriginCountry: "GB", DestinationCountry: "US", Action: "REVIEW"}), services.ErrCodeInvalidArgument)
+	blockNG := domain.CorridorRuleRequest{OriginCountry: "GB", DestinationCountry: "NG", Action: domain.CorridorActionBlock}
+	if err := setCorridorRule(t, stub, "rule_block_no_reason", blockNG); err == nil || !strings.Contains(err.Error(), "reason is required") {
+		t.Errorf("expected a block without a reason to be refused, got %v", err)
+	}
+	blockNG.Reason = "Sanctions programme"
+	for txID, rule := range map[string]domain.CorridorRuleRequest{
+		"rule_allow_gb": allowFromGB,
+		"rule_block_ng": blockNG,
+		"rule_flag_us":  {OriginCountry: "*", DestinationCountry: "US", Action: domain.CorridorActionFlag, ReportingThreshold: 5000, ReportType: "US_INBOUND"},
+	} {
+		if err := setCorridorRule(t, stub, txID, rule); err != nil {
+			t.Fatalf("failed to set %s: %v", txID, err)
+		}
+	}
+
+	// The most specific rule applies
+	for _, lookup := range []struct{ origin, destination, ruleOrigin, ruleDestination string }{
+		{"gb", "us", "GB", "*"},
+		{"GB", "NG", "GB", "NG"},
+		{"DE", "US", "*", "US"},
+	} {
+		payload, err := inTx(stub, "lookup", func() ([]byte, error) {
+			return NewCrossBorderHandler().GetCorridorRule(stub, []string{lookup.origin, lookup.destination})
+		})
+		var rule domain.CorridorRule
+		if err != nil || json.Unmarshal(payload, &rule) != nil || rule.OriginCountry != lookup.ruleOrigin || rule.DestinationCountry != lookup.ruleDestination {
+			t.Errorf("expected %s-%s to resolve to %s-%s, got %+v (%v)", lookup.origin, lookup.destination, lookup.ruleOrigin, lookup.ruleDestination, rule, err)
+		}
+	}
+	if _, err := inTx(stub, "lookup_none", func() ([]byte, error) {
+		return NewCrossBorderHandler().GetCorridorRule(stub, []string{"DE", "FR"})
+	}); err == nil {
+		t.Error("expected no rule for an unconfigured corridor")
+	}
+
+	// Payments from a GB resident are allowed abroad and reported from the threshold
+	seedLoan(t, stub, "LOAN_X1", validation.LoanStatusApproved, approvedTerms(30000, 8))
+	below, err := disburseAbroad(t, stub, "below", "LOAN_X1", 5000, "fr")
+	if err != nil {
+		t.Fatalf("disbursement below the threshold failed: %v", err)
+	}
+	if check := below.CrossBorder; check == nil || check.OriginCountry != "GB" || check.DestinationCountry != "FR" || check.RuleDestination != "*" || check.Flagged || check.ReportEntryID != "" {
+		t.Errorf("expected an unreported allowed payment, got %+v", check)
+	}
+	above, err := disburseAbroad(t, stub, "above", "LOAN_X1", 12000, "FR")
+	if err != nil {
+		t.Fatalf("disbursement above the threshold failed: %v", err)
+	}
+	if above.CrossBorder == nil || above.CrossBorder.ReportEntryID == "" {
+		t.Errorf("expected a reported payment, got %+v", above.CrossBorder)
+	}
+	if domestic, err := disburseAbroad(t, stub, "domestic", "LOAN_X1", 1000, "GB"); err != nil || domestic.CrossBorder != nil {
+		t.Errorf("expected a domestic payment to skip the corridor checks, got %+v (%v)", domestic, err)
+	}
+
+	// Blocked corridors refuse the payment and leave the loan untouched
+	if _, err := disburseAbroad(t, stub, "blocked", "LOAN_X1", 1000, "NG"); err == nil || !strings.Contains(err.Error(), "blocked: Sanctions programme") {
+		t.Errorf("expected the NG payment to be blocked, got %v", err)
+	}
+	if loanApp := getLoan(t, stub, "LOAN_X1"); loanApp.DisbursedAmount.Float64() != 18000 {
+		t.Errorf("expected 18000 disbursed, got %.2f", loanApp.DisbursedAmount.Float64())
+	}
+
+	// Without a residency the loan's jurisdiction is the origin; flagged payments are always reported
+	inGermany := func(loanApp *domain.LoanApplication) {
+		loanApp.CustomerID = "CUST_002"
+		loanApp.Jurisdiction = "DE"
+	}
+	seedLoan(t, stub, "LOAN_X2", validation.LoanStatusApproved, approvedTerms(5000, 8), inGermany)
+	seedLoan(t, stub, "LOAN_X3", validation.LoanStatusApproved, approvedTerms(5000, 8), inGermany, func(loanApp *domain.LoanApplication) {
+		loanApp.Sandbox = true
+	})
+	flagged, err := disburseAbroad(t, stub, "flagged", "LOAN_X2", 100, "US")
+	if err != nil {
+		t.Fatalf("flagged disbursement failed: %v", err)
+	}
+	if check := flagged.CrossBorder; check == nil || check.OriginCountry != "DE" || !check.Flagged || check.Action != domain.CorridorActionFlag || check.ReportEntryID == "" {
+		t.Errorf("expected a flagged, reported payment, got %+v", check)
+	}
+	if sandbox, err := disburseAbroad(t, stub, "sandbox", "LOAN_X3", 100, "US"); err != nil || sandbox.CrossBorder == nil || !sandbox.CrossBorder.Flagged || sandbox.CrossBorder.ReportEntryID != "" {
+		t.Errorf("expected a sandbox payment flagged but not reported, got %+v (%v)", sandbox, err)
+	}
+
+	// Reports are kept per country pair and month
+	if report := corridorReport(t, stub, "gb", "fr", "2026-05"); report.EntryCount != 1 || report.TotalAmount != 12000 || report.Entries[0].ReportType != "CROSS_BORDER_FUNDS" || report.Entries[0].DisbursementID != above.DisbursementID {
+		t.Errorf("unexpected GB-FR report: %+v", report)
+	}
+	if report := corridorReport(t, stub, "DE", "US", "2026-05"); report.EntryCount != 1 || report.FlaggedCount != 1 || report.Entries[0].Threshold != 5000 || report.Entries[0].LoanID != "LOAN_X2" {
+		t.Errorf("unexpected DE-US report: %+v", report)
+	}
+	if report := corridorReport(t, stub, "GB", "FR", "2026-04"); report.EntryCount != 0 {
+		t.Errorf("expected nothing reported in April, got %+v", report)
+	}
+	if _, err := inTx(stub, "report_wildcard", func() ([]byte, error) {
+		return NewCrossBorderHandler().GetCorridorReport(stub, []string{"GB", "*", "2026-05"})
+	}); err == nil {
+		t.Error("expected a wildcard report to be refused")
+	}
+}
@@ -0,0 +1,349 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	loanServices "github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/interfaces"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// MarkLoanDelinquent moves a disbursed loan with installments past due to DELINQUENT, recording its
+// arrears. Repayments that clear the arrears return the loan to DISBURSED.
+func (h *RepaymentHandler) MarkLoanDelinquent(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.LoanDelinquencyRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse delinquency request: %v", err)
+	}
+	if req.LoanID == "" || req.ActorID == "" {
+		return nil, fmt.Errorf("loanID and actorID are required")
+	}
+	if err := checkArrearsRole(stub, "delinquency", validation.ActorRoleLoanOperationsManager, validation.ActorRoleCreditOfficer); err != nil {
+		return nil, err
+	}
+
+	// Get existing loan application
+	loanKey := fmt.Sprintf("LOAN_%s", req.LoanID)
+	var loanApp domain.LoanApplication
+	if err := h.persistenceService.Get(stub, loanKey, &loanApp); err != nil {
+		return nil, fmt.Errorf("loan application not found: %w", err)
+	}
+
+	// Arrears reflect the borrower's payments rather than a decision, so a compliance hold does
+	// not stop them being recorded
+	if err := validation.ValidateStatusTransition(string(loanApp.Status), string(validation.LoanStatusDelinquent), "LoanApplication"); err != nil {
+		return nil, fmt.Errorf("invalid status transition: %w", err)
+	}
+
+	delinquency, err := h.assessDelinquency(stub, req.LoanID)
+	if err != nil {
+		return nil, err
+	}
+	if delinquency.DaysPastDue == 0 {
+		return nil, fmt.Errorf("loan %s has no installments past due", req.LoanID)
+	}
+	delinquency.AssessedBy = req.ActorID
+
+	// Update loan application
+	previousStatus := loanApp.Status
+	loanApp.Status = validation.LoanStatusDelinquent
+	loanApp.Delinquency = delinquency
+	loanApp.LastUpdated = delinquency.AssessedDate
+	loanApp.LastUpdatedBy = req.ActorID
+
+	// Store updated loan application
+	if err := putLoanApplication(stub, h.persistenceService, &loanApp); err != nil {
+		return nil, fmt.Errorf("failed to update loan application: %w", err)
+	}
+
+	// Record history
+	if err := recordLoanHistory(stub, h.persistenceService, req.LoanID, "DELINQUENCY", "status", string(previousStatus), string(validation.LoanStatusDelinquent), req.ActorID); err != nil {
+		return nil, err
+	}
+	if err := addArrearsNote(stub, &loanApp, req.Notes, req.ActorID); err != nil {
+		return nil, err
+	}
+
+	// Emit event
+	if err := h.eventService.EmitLoanDelinquent(stub, &loanApp, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(&loanApp)
+}
+
+// MarkLoanDefaulted moves a disbursed or delinquent loan whose oldest unpaid installment is at least
+// config.DefaultDaysPastDue days past due to DEFAULTED, and reports the default to the compliance
+// chaincode in the same transaction
+func (h *RepaymentHandler) MarkLoanDefaulted(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.LoanDefaultRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse default request: %v", err)
+	}
+	if req.LoanID == "" || req.ActorID == "" {
+		return nil, fmt.Errorf("loanID and actorID are required")
+	}
+	if err := checkArrearsRole(stub, "defaults", validation.ActorRoleCreditOfficer); err != nil {
+		return nil, err
+	}
+
+	// Get existing loan application
+	loanKey := fmt.Sprintf("LOAN_%s", req.LoanID)
+	var loanApp domain.LoanApplication
+	if err := h.persistenceService.Get(stub, loanKey, &loanApp); err != nil {
+		return nil, fmt.Errorf("loan application not found: %w", err)
+	}
+
+	if err := validation.ValidateStatusTransition(string(loanApp.Status), string(validation.LoanStatusDefaulted), "LoanApplication"); err != nil {
+		return nil, fmt.Errorf("invalid status transition: %w", err)
+	}
+
+	delinquency, err := h.assessDelinquency(stub, req.LoanID)
+	if err != nil {
+		return nil, err
+	}
+	if delinquency.DaysPastDue < config.DefaultDaysPastDue {
+		return nil, fmt.Errorf("loan %s is %d days past due; a loan may be marked defaulted from %d days", req.LoanID, delinquency.DaysPastDue, config.DefaultDaysPastDue)
+	}
+	delinquency.AssessedBy = req.ActorID
+
+	// Sandbox loans are kept out of compliance reporting
+	complianceEventID := ""
+	if !loanApp.Sandbox {
+		complianceEventID, err = h.defaultReportingService.ReportDefault(stub, &interfaces.LoanDefaultReport{
+			LoanID:             loanApp.LoanID,
+			CustomerIDs:        loanCustomerIDs(&loanApp),
+			LoanType:           loanApp.LoanType,
+			OutstandingBalance: loanApp.OutstandingBalance.Float64(),
+			AmountOverdue:      delinquency.AmountOverdue,
+			DaysPastDue:        delinquency.DaysPastDue,
+			Notes:              req.Notes,
+			ActorID:            req.ActorID,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// Update loan application
+	previousStatus := loanApp.Status
+	loanApp.Status = validation.LoanStatusDefaulted
+	loanApp.Delinquency = delinquency
+	loanApp.Default = &domain.LoanDefault{
+		DaysPastDue:        delinquency.DaysPastDue,
+		AmountOverdue:      delinquency.AmountOverdue,
+		OutstandingBalance: loanApp.OutstandingBalance.Float64(),
+		PreviousStatus:     string(previousStatus),
+		Notes:              req.Notes,
+		DefaultedBy:        req.ActorID,
+		DefaultDate:        delinquency.AssessedDate,
+		ComplianceEventID:  complianceEventID,
+	}
+	loanApp.LastUpdated = delinquency.AssessedDate
+	loanApp.LastUpdatedBy = req.ActorID
+
+	// An early default claws back the introducer's commission
+	loanApp.Default.CommissionClawedBack, err = clawBackCommission(stub, h.persistenceService, &loanApp, delinquency.AssessedDate, req.ActorID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Store updated loan application
+	if err := putLoanApplication(stub, h.persistenceService, &loanApp); err != nil {
+		return nil, fmt.Errorf("failed to update loan application: %w", err)
+	}
+
+	// Record history
+	if err := recordLoanHistory(stub, h.persistenceService, req.LoanID, "DEFAULT", "status", string(previousStatus), string(validation.LoanStatusDefaulted), req.ActorID); err != nil {
+		return nil, err
+	}
+	if err := addArrearsNote(stub, &loanApp, req.Notes, req.ActorID); err != nil {
+		return nil, err
+	}
+
+	// Emit event
+	if err := h.eventService.EmitLoanDefaulted(stub, &loanApp, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(&loanApp)
+}
+
+// GetDelinquentPortfolio returns a page of the loans in arrears, optionally restricted to one
+// delinquency bucket. Days past due are assessed at the transaction time, so disbursed loans that
+// have fallen behind are listed before anyone marks them delinquent. Sandbox loans are excluded.
+// Args: bucket (empty for all buckets) [, pageSize [, bookmark]]
+func (h *RepaymentHandler) GetDelinquentPortfolio(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) < 1 || len(args) > 3 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1 to 3, got %d", len(args))
+	}
+
+	bucket := args[0]
+	if bucket != "" && !containsString(domain.DelinquencyBuckets, bucket) {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid delinquency bucket %q; expected one of %s", bucket, strings.Join(domain.DelinquencyBuckets, ", "))
+	}
+
+	pageSize, bookmark, err := services.ParsePageArgs(args[1:])
+	if err != nil {
+		return nil, err
+	}
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	// Partial keys must be in ascending order for the bookmark to work
+	statuses := [][]string{{string(validation.LoanStatusDelinquent)}, {string(validation.LoanStatusDisbursed)}}
+	entries, nextBookmark, err := h.persistenceService.GetPageByPartialCompositeKeys(stub, "LOAN_BY_STATUS", statuses, pageSize, bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loans by status: %w", err)
+	}
+
+	portfolio := &domain.DelinquentPortfolio{
+		Bucket:              bucket,
+		AsOf:                now,
+		Loans:               []domain.DelinquentLoan{},
+		AmountsOverdue:      utils.CurrencyTotals{},
+		OutstandingBalances: utils.CurrencyTotals{},
+		Bookmark:            nextBookmark,
+	}
+	for _, entry := range entries {
+		var loanApp domain.LoanApplication
+		if err := h.persistenceService.Get(stub, fmt.Sprintf("LOAN_%s", string(entry.Value)), &loanApp); err != nil {
+			continue // Skip if loan not found
+		}
+		if loanApp.Sandbox {
+			continue
+		}
+
+		installments, err := h.scheduleService.GetSchedule(stub, loanApp.LoanID)
+		if err != nil {
+			return nil, err
+		}
+		delinquency, err := loanServices.AssessDelinquency(installments, now)
+		if err != nil {
+			return nil, fmt.Errorf("failed to assess delinquency of loan %s: %w", loanApp.LoanID, err)
+		}
+		if delinquency.DaysPastDue == 0 || (bucket != "" && delinquency.Bucket != bucket) {
+			continue
+		}
+
+		portfolio.Loans = append(portfolio.Loans, domain.DelinquentLoan{
+			LoanID:             loanApp.LoanID,
+			CustomerID:         loanApp.CustomerID,
+			LoanType:           loanApp.LoanType,
+			Status:             loanApp.Status,
+			DaysPastDue:        delinquency.DaysPastDue,
+			Bucket:             delinquency.Bucket,
+			Currency:           loanApp.OutstandingBalance.Currency(),
+			AmountOverdue:      delinquency.AmountOverdue,
+			OutstandingBalance: loanApp.OutstandingBalance.Float64(),
+		})
+		portfolio.AmountsOverdue.Add(utils.NewMoney(delinquency.AmountOverdue, loanApp.Currency))
+		portfolio.OutstandingBalances.Add(loanApp.OutstandingBalance)
+	}
+	portfolio.Count = len(portfolio.Loans)
+
+	return json.Marshal(portfolio)
+}
+
+// cureDelinquency returns a delinquent loan to DISBURSED once its installments are no longer past
+// due. The caller stores the loan.
+func (h *RepaymentHandler) cureDelinquency(stub shim.ChaincodeStubInterface, loanApp *domain.LoanApplication, installments []domain.Installment, actorID string) error {
+	if loanApp.Status != validation.LoanStatusDelinquent {
+		return nil
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return err
+	}
+	delinquency, err := loanServices.AssessDelinquency(installments, now)
+	if err != nil {
+		return fmt.Errorf("failed to assess delinquency: %w", err)
+	}
+	if delinquency.DaysPastDue > 0 {
+		return nil
+	}
+
+	loanApp.Status = validation.LoanStatusDisbursed
+	loanApp.Delinquency = nil
+	if err := recordLoanHistory(stub, h.persistenceService, loanApp.LoanID, "DELINQUENCY_CURED", "status", string(validation.LoanStatusDelinquent), string(validation.LoanStatusDisbursed), actorID); err != nil {
+		return err
+	}
+	if err := h.eventService.EmitLoanDelinquencyCured(stub, loanApp, actorID); err != nil {
+		return fmt.Errorf("failed to emit event: %w", err)
+	}
+	return nil
+}
+
+// assessDelinquency measures a loan's arrears against its repayment schedule at the transaction time
+func (h *RepaymentHandler) assessDelinquency(stub shim.ChaincodeStubInterface, loanID string) (*domain.LoanDelinquency, error) {
+	installments, err := h.scheduleService.GetSchedule(stub, loanID)
+	if err != nil {
+		return nil, err
+	}
+	if len(installments) == 0 {
+		return nil, fmt.Errorf("loan %s has no repayment schedule", loanID)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	delinquency, err := loanServices.AssessDelinquency(installments, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to assess delinquency: %w", err)
+	}
+	return &delinquency, nil
+}
+
+// checkArrearsRole checks the invoker holds one of the roles that may record a change in arrears status
+func checkArrearsRole(stub shim.ChaincodeStubInterface, subject string, roles ...validation.ActorRole) error {
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return err
+	}
+
+	names := make([]string, len(roles))
+	for i, allowed := range roles {
+		if role == string(allowed) {
+			return nil
+		}
+		names[i] = string(allowed)
+	}
+	return services.NewChaincodeError(services.ErrCodeAccessDenied, "", "loan %s may only be recorded by a %s", subject, strings.Join(names, " or "))
+}
+
+// addArrearsNote keeps commentary on an arrears change as an internal entity note
+func addArrearsNote(stub shim.ChaincodeStubInterface, loanApp *domain.LoanApplication, text, actorID string) error {
+	if strings.TrimSpace(text) == "" {
+		return nil
+	}
+	note := &services.EntityNote{
+		EntityID:   loanApp.LoanID,
+		EntityType: "LoanApplication",
+		Text:       text,
+		Visibility: string(validation.NoteVisibilityInternal),
+		AuthorID:   actorID,
+	}
+	if err := services.NewEntityNoteService().AddNote(stub, note); err != nil {
+		return fmt.Errorf("failed to record arrears note: %w", err)
+	}
+	return nil
+}
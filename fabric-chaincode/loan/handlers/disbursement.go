@@ -0,0 +1,235 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	loanServices "github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// DisbursementHandler handles loan disbursement operations
+type DisbursementHandler struct {
+	persistenceService *services.PersistenceService
+	eventService      *loanServices.EventService
+}
+
+// NewDisbursementHandler creates a new disbursement handler
+func NewDisbursementHandler() *DisbursementHandler {
+	return &DisbursementHandler{
+		persistenceService: services.NewPersistenceService(),
+		eventService:      loanServices.NewEventService(),
+	}
+}
+
+// DisburseLoan releases all or part of an approved loan amount to a destination account
+func (h *DisbursementHandler) DisburseLoan(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.LoanDisbursementRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse disbursement request: %v", err)
+	}
+
+	if strings.TrimSpace(req.DestinationAccountRef) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "destinationAccountRef", "destinationAccountRef is required")
+	}
+
+	// Get existing loan application
+	loanKey := fmt.Sprintf("LOAN_%s", req.LoanID)
+	var loanApp domain.LoanApplication
+	if err := h.persistenceService.Get(stub, loanKey, &loanApp); err != nil {
+		return nil, fmt.Errorf("loan application not found: %w", err)
+	}
+
+	// Funds must go to an active, onboarded counterparty
+	payeeCountry := strings.ToUpper(strings.TrimSpace(req.PayeeCountry))
+	if req.CounterpartyID != "" {
+		counterparty, err := getActiveCounterparty(stub, h.persistenceService, req.CounterpartyID)
+		if err != nil {
+			return nil, err
+		}
+		if payeeCountry == "" {
+			payeeCountry = strings.ToUpper(counterparty.Jurisdiction)
+		}
+	}
+	if payeeCountry != "" && len(payeeCountry) != 2 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid payeeCountry %s, expected an ISO 3166-1 alpha-2 country code", req.PayeeCountry)
+	}
+
+	previousStatus := loanApp.Status
+	previousDisbursed := loanApp.DisbursedAmount
+	disbursement, err := recordDisbursement(stub, h.persistenceService, &loanApp, utils.NewMoney(req.Amount, loanApp.Currency), req.DestinationAccountRef, req.CounterpartyID, payeeCountry, req.ActorID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Store updated loan application
+	if err := putLoanApplication(stub, h.persistenceService, &loanApp); err != nil {
+		return nil, fmt.Errorf("failed to update loan application: %w", err)
+	}
+
+	// Record history
+	if loanApp.Status != previousStatus {
+		if err := recordLoanHistory(stub, h.persistenceService, req.LoanID, "STATUS_UPDATE", "status", string(previousStatus), string(loanApp.Status), req.ActorID); err != nil {
+			return nil, err
+		}
+	}
+	if err := recordLoanHistory(stub, h.persistenceService, req.LoanID, "DISBURSEMENT", "disbursedAmount", previousDisbursed.String(), loanApp.DisbursedAmount.String(), req.ActorID); err != nil {
+		return nil, err
+	}
+
+	// Emit event
+	if err := h.eventService.EmitLoanDisbursed(stub, &loanApp, disbursement, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(disbursement)
+}
+
+// GetLoanDisbursements retrieves all funding records for a loan
+func (h *DisbursementHandler) GetLoanDisbursements(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	disbursements, err := getLoanDisbursements(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(disbursements)
+}
+
+// Helper methods
+
+// recordDisbursement validates and stores a disbursement, posts it to the loan ledger and moves the loan to DISBURSED; the caller persists the loan.
+// Payments to a payee country other than the customer's residency pass the corridor checks first.
+func recordDisbursement(stub shim.ChaincodeStubInterface, persistenceService *services.PersistenceService, loanApp *domain.LoanApplication, amount utils.Money, destinationAccountRef, counterpartyID, payeeCountry, actorID string) (*domain.LoanDisbursement, error) {
+	if err := checkComplianceHold(loanApp); err != nil {
+		return nil, err
+	}
+	if loanApp.ApprovedAmount == nil {
+		return nil, fmt.Errorf("loan %s has no approved amount", loanApp.LoanID)
+	}
+
+	// The first tranche transitions the loan; later tranches draw down the remainder
+	switch loanApp.Status {
+	case validation.LoanStatusApproved:
+		if err := validation.ValidateStatusTransition(string(loanApp.Status), string(validation.LoanStatusDisbursed), "LoanApplication"); err != nil {
+			return nil, fmt.Errorf("invalid status transition: %w", err)
+		}
+	case validation.LoanStatusDisbursed:
+	default:
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "loan cannot be disbursed from current status: %s", loanApp.Status)
+	}
+
+	remaining, err := loanApp.ApprovedAmount.Sub(loanApp.DisbursedAmount)
+	if err != nil {
+		return nil, fmt.Errorf("loan %s: %w", loanApp.LoanID, err)
+	}
+	if !remaining.IsPositive() {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "loan %s is fully disbursed", loanApp.LoanID)
+	}
+	if cmp, err := amount.Cmp(remaining); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "disbursement currency %s does not match loan currency %s", amount.Currency(), remaining.Currency())
+	} else if cmp > 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "disbursement %s exceeds undisbursed amount %s", amount, remaining)
+	}
+
+	crossBorder, corridorRule, err := checkCrossBorder(stub, persistenceService, loanApp, payeeCountry)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := getLoanDisbursements(stub, loanApp.LoanID)
+	if err != nil {
+		return nil, err
+	}
+
+	txn, err := postLoanTransaction(stub, persistenceService, loanApp, domain.LoanTransactionDisbursement, amount, destinationAccountRef, counterpartyID, actorID)
+	if err != nil {
+		return nil, err
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	loanApp.DisbursedAmount, _ = loanApp.DisbursedAmount.Add(amount)
+	remaining, _ = remaining.Sub(amount)
+	loanApp.Status = validation.LoanStatusDisbursed
+	if loanApp.DisbursementDate == nil {
+		loanApp.DisbursementDate = &now
+	}
+
+	disbursement := &domain.LoanDisbursement{
+		DisbursementID:        services.GenerateDeterministicID(stub, config.LoanDisbursementPrefix),
+		LoanID:                loanApp.LoanID,
+		TrancheNumber:         len(existing) + 1,
+		Currency:              loanApp.Currency,
+		Amount:                amount,
+		DisbursementDate:      now,
+		DestinationAccountRef: destinationAccountRef,
+		CounterpartyID:        counterpartyID,
+		TransactionID:         txn.TransactionID,
+		DisbursedAmountAfter:  loanApp.DisbursedAmount,
+		RemainingAmount:       remaining,
+		DisbursedBy:           actorID,
+		PayeeCountry:          payeeCountry,
+		CrossBorder:           crossBorder,
+	}
+
+	// Cross-border payments over the corridor's threshold are reported to its regulator
+	if err := recordCorridorReport(stub, persistenceService, loanApp, disbursement, corridorRule, actorID); err != nil {
+		return nil, err
+	}
+
+	// Introduced loans earn the introducer commission on each tranche
+	if err := accrueCommission(stub, persistenceService, loanApp, disbursement, actorID); err != nil {
+		return nil, err
+	}
+
+	disbursementKey, err := stub.CreateCompositeKey("LOAN_DISBURSEMENT", []string{loanApp.LoanID, disbursement.DisbursementID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := persistenceService.Put(stub, disbursementKey, disbursement); err != nil {
+		return nil, fmt.Errorf("failed to store disbursement: %w", err)
+	}
+
+	return disbursement, nil
+}
+
+func getLoanDisbursements(stub shim.ChaincodeStubInterface, loanID string) ([]domain.LoanDisbursement, error) {
+	iterator, err := stub.GetStateByPartialCompositeKey("LOAN_DISBURSEMENT", []string{loanID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loan disbursements: %w", err)
+	}
+	defer iterator.Close()
+
+	disbursements := []domain.LoanDisbursement{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate loan disbursements: %w", err)
+		}
+
+		var disbursement domain.LoanDisbursement
+		if err := json.Unmarshal(response.Value, &disbursement); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal loan disbursement: %w", err)
+		}
+
+		disbursements = append(disbursements, disbursement)
+	}
+
+	return disbursements, nil
+}
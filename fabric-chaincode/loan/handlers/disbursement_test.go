@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// disburse runs DisburseLoan as its own transaction and decodes the funding record
+func disburse(t *testing.T, stub *shimtest.MockStub, txID, loanID string, amount float64) (*domain.LoanDisbursement, error) {
+	t.Helper()
+	payload, err := inTx(stub, txID, func() ([]byte, error) {
+		return NewDisbursementHandler().DisburseLoan(stub, []string{mustJSON(t, domain.LoanDisbursementRequest{
+			LoanID:                loanID,
+			Amount:                amount,
+			DestinationAccountRef: "ACC-0001",
+			ActorID:               "ACTOR_004",
+		})})
+	})
+	if err != nil {
+		return nil, err
+	}
+	var disbursement domain.LoanDisbursement
+	if err := json.Unmarshal(payload, &disbursement); err != nil {
+		t.Fatalf("failed to decode disbursement: %v", err)
+	}
+	return &disbursement, nil
+}
+
+func TestDisburseLoanInTranches(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	seedLoan(t, stub, "LOAN_D1", validation.LoanStatusApproved, approvedTerms(10000, 6))
+
+	// The first tranche moves the loan to DISBURSED and opens the balance
+	first, err := disburse(t, stub, "disburse_1", "LOAN_D1", 4000)
+	if err != nil {
+		t.Fatalf("first tranche failed: %v", err)
+	}
+	if first.TrancheNumber != 1 || first.DisbursedAmountAfter.Float64() != 4000 || first.RemainingAmount.Float64() != 6000 {
+		t.Errorf("unexpected first tranche: %+v", first)
+	}
+	loanApp := getLoan(t, stub, "LOAN_D1")
+	if loanApp.Status != validation.LoanStatusDisbursed || loanApp.OutstandingBalance.Float64() != 4000 || loanApp.DisbursementDate == nil {
+		t.Fatalf("expected DISBURSED with balance 4000, got %s with %.2f", loanApp.Status, loanApp.OutstandingBalance.Float64())
+	}
+
+	// The second tranche draws down the remainder and keeps the first disbursement date
+	second, err := disburse(t, stub, "disburse_2", "LOAN_D1", 6000)
+	if err != nil {
+		t.Fatalf("second tranche failed: %v", err)
+	}
+	if second.TrancheNumber != 2 || second.RemainingAmount.Float64() != 0 || second.TransactionID == first.TransactionID {
+		t.Errorf("unexpected second tranche: %+v", second)
+	}
+	loanApp = getLoan(t, stub, "LOAN_D1")
+	if loanApp.DisbursedAmount.Float64() != 10000 || loanApp.OutstandingBalance.Float64() != 10000 || !loanApp.DisbursementDate.Equal(first.DisbursementDate) {
+		t.Errorf("expected 10000 disbursed and outstanding, got %.2f and %.2f", loanApp.DisbursedAmount.Float64(), loanApp.OutstandingBalance.Float64())
+	}
+
+	payload, err := inTx(stub, "list_disbursements", func() ([]byte, error) {
+		return NewDisbursementHandler().GetLoanDisbursements(stub, []string{"LOAN_D1"})
+	})
+	if err != nil {
+		t.Fatalf("failed to list disbursements: %v", err)
+	}
+	var disbursements []domain.LoanDisbursement
+	if err := json.Unmarshal(payload, &disbursements); err != nil || len(disbursements) != 2 {
+		t.Fatalf("expected 2 funding records, got %d (%v)", len(disbursements), err)
+	}
+}
+
+func TestDisburseLoanReplayDoesNotFundTwice(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	seedLoan(t, stub, "LOAN_D2", validation.LoanStatusApproved, approvedTerms(5000, 6))
+
+	if _, err := disburse(t, stub, "disburse_full", "LOAN_D2", 5000); err != nil {
+		t.Fatalf("disbursement failed: %v", err)
+	}
+
+	// A resubmitted disbursement finds nothing left to release
+	_, err := disburse(t, stub, "disburse_replay", "LOAN_D2", 5000)
+	expectErrorCode(t, err, services.ErrCodeInvalidTransition)
+	if loanApp := getLoan(t, stub, "LOAN_D2"); loanApp.DisbursedAmount.Float64() != 5000 || loanApp.OutstandingBalance.Float64() != 5000 || loanApp.Version != 2 {
+		t.Errorf("replay must leave the loan unchanged, got %.2f disbursed at version %d", loanApp.DisbursedAmount.Float64(), loanApp.Version)
+	}
+}
+
+func TestDisburseLoanIsDeterministicAcrossEndorsements(t *testing.T) {
+	// Every endorsing peer runs the same transaction against the same state and must derive the
+	// same funding record and ledger transaction
+	records := make([]*domain.LoanDisbursement, 2)
+	for i := range records {
+		stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+		seedLoan(t, stub, "LOAN_D3", validation.LoanStatusApproved, approvedTerms(5000, 6))
+		disbursement, err := disburse(t, stub, "disburse_endorse", "LOAN_D3", 2500)
+		if err != nil {
+			t.Fatalf("disbursement failed: %v", err)
+		}
+		records[i] = disbursement
+	}
+	if records[0].DisbursementID != records[1].DisbursementID || records[0].TransactionID != records[1].TransactionID {
+		t.Errorf("endorsements derived different records: %+v and %+v", records[0], records[1])
+	}
+}
+
+func TestDisburseLoanRejectsWrongState(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+
+	tests := []struct {
+		name   string
+		loanID string
+		status validation.LoanApplicationStatus
+		amount float64
+		code   string
+	}{
+		{"before approval", "LOAN_D4", validation.LoanStatusCreditApproval, 1000, services.ErrCodeInvalidTransition},
+		{"after rejection", "LOAN_D5", validation.LoanStatusRejected, 1000, services.ErrCodeInvalidTransition},
+		{"more than approved", "LOAN_D6", validation.LoanStatusApproved, 5000.01, services.ErrCodeInvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			seedLoan(t, stub, tt.loanID, tt.status, approvedTerms(5000, 6))
+			_, err := disburse(t, stub, "disburse_"+tt.loanID, tt.loanID, tt.amount)
+			expectErrorCode(t, err, tt.code)
+			if loanApp := getLoan(t, stub, tt.loanID); loanApp.Status != tt.status || loanApp.DisbursedAmount.Float64() != 0 {
+				t.Errorf("rejected disbursement changed the loan: %s with %.2f disbursed", loanApp.Status, loanApp.DisbursedAmount.Float64())
+			}
+		})
+	}
+}
+
+func TestDisburseLoanRoundsToCents(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	seedLoan(t, stub, "LOAN_D7", validation.LoanStatusApproved, approvedTerms(10000, 6))
+
+	// Thirds of the approved amount accumulate in cents and still draw down to exactly zero
+	for _, txID := range []string{"disburse_third_1", "disburse_third_2"} {
+		if _, err := disburse(t, stub, txID, "LOAN_D7", 3333.333); err != nil {
+			t.Fatalf("%s failed: %v", txID, err)
+		}
+	}
+	loanApp := getLoan(t, stub, "LOAN_D7")
+	if loanApp.DisbursedAmount.Float64() != 6666.66 || loanApp.OutstandingBalance.Float64() != 6666.66 {
+		t.Fatalf("expected 6666.66 disbursed and outstanding, got %.4f and %.4f", loanApp.DisbursedAmount.Float64(), loanApp.OutstandingBalance.Float64())
+	}
+
+	last, err := disburse(t, stub, "disburse_third_3", "LOAN_D7", 3333.34)
+	if err != nil {
+		t.Fatalf("final tranche failed: %v", err)
+	}
+	if last.RemainingAmount.Float64() != 0 || last.DisbursedAmountAfter.Float64() != 10000 {
+		t.Errorf("expected the final tranche to leave nothing undisbursed, got %+v", last)
+	}
+}
+
+func TestDisburseLoanKeepsThreeDecimalAmounts(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	seedLoan(t, stub, "LOAN_D8", validation.LoanStatusApproved, func(loanApp *domain.LoanApplication) {
+		loanApp.Currency = "KWD"
+		loanApp.RequestedAmount = utils.NewMoney(10000, "KWD")
+	}, approvedTerms(10000, 6))
+
+	// Dinars have three minor units, so thirds of the approved amount are held to the fils
+	for _, txID := range []string{"disburse_kwd_1", "disburse_kwd_2"} {
+		if _, err := disburse(t, stub, txID, "LOAN_D8", 3333.333); err != nil {
+			t.Fatalf("%s failed: %v", txID, err)
+		}
+	}
+	loanApp := getLoan(t, stub, "LOAN_D8")
+	if loanApp.DisbursedAmount.String() != "6666.666" || loanApp.OutstandingBalance.String() != "6666.666" || loanApp.OutstandingBalance.Currency() != "KWD" {
+		t.Fatalf("expected 6666.666 KWD disbursed and outstanding, got %s and %s", loanApp.DisbursedAmount, loanApp.OutstandingBalance)
+	}
+
+	last, err := disburse(t, stub, "disburse_kwd_3", "LOAN_D8", 3333.334)
+	if err != nil {
+		t.Fatalf("final tranche failed: %v", err)
+	}
+	if last.Currency != "KWD" || last.Amount.String() != "3333.334" || !last.RemainingAmount.IsZero() || last.DisbursedAmountAfter.String() != "10000" {
+		t.Errorf("expected the final tranche to leave nothing undisbursed, got %+v", last)
+	}
+}
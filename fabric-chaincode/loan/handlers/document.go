@@ -3,34 +3,241 @@ package handlers
 import (
 	"encoding/json"
 	"fmt"
+	"strings"
 
 	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	loanServices "github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
 )
 
-// DocumentHandler handles document operations (placeholder implementation)
-type DocumentHandler struct{}
+// DocumentHandler handles document operations. Document contents stay off-chain; only hashes are recorded.
+type DocumentHandler struct {
+	persistenceService *services.PersistenceService
+	eventService      *loanServices.EventService
+}
 
 // NewDocumentHandler creates a new document handler
 func NewDocumentHandler() *DocumentHandler {
-	return &DocumentHandler{}
+	return &DocumentHandler{
+		persistenceService: services.NewPersistenceService(),
+		eventService:      loanServices.NewEventService(),
+	}
 }
 
-// UploadDocument uploads a document (placeholder)
+// UploadDocument records the hash of a document supporting a loan application
 func (h *DocumentHandler) UploadDocument(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
-	return json.Marshal(map[string]string{"message": "Document upload functionality to be implemented"})
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.DocumentUploadRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse document upload request: %v", err)
+	}
+
+	if err := validation.ValidateDocumentType(req.DocumentType); err != nil {
+		return nil, fmt.Errorf("invalid document type: %w", err)
+	}
+	if strings.TrimSpace(req.DocumentHash) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "documentHash", "documentHash is required")
+	}
+
+	// Get existing loan application
+	var loanApp domain.LoanApplication
+	if err := h.persistenceService.Get(stub, fmt.Sprintf("LOAN_%s", req.LoanID), &loanApp); err != nil {
+		return nil, fmt.Errorf("loan application not found: %w", err)
+	}
+	if loanApp.Status == validation.LoanStatusRejected || loanApp.Status == validation.LoanStatusCancelled {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "documents cannot be uploaded for loan in status: %s", loanApp.Status)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	document := &domain.LoanDocument{
+		DocumentID:    services.GenerateDeterministicID(stub, config.LoanDocumentPrefix),
+		LoanID:        req.LoanID,
+		DocumentType:  req.DocumentType,
+		DocumentName:  req.DocumentName,
+		DocumentHash:  req.DocumentHash,
+		Status:        domain.DocumentStatusPending,
+		CreatedDate:   now,
+		LastUpdated:   now,
+		CreatedBy:     req.ActorID,
+		LastUpdatedBy: req.ActorID,
+	}
+
+	if err := h.persistenceService.Put(stub, fmt.Sprintf("DOCUMENT_%s", document.DocumentID), document); err != nil {
+		return nil, fmt.Errorf("failed to store document: %w", err)
+	}
+
+	// Create index by loan ID
+	loanDocumentKey, err := stub.CreateCompositeKey("LOAN_DOCUMENT", []string{req.LoanID, document.DocumentID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := stub.PutState(loanDocumentKey, []byte(document.DocumentID)); err != nil {
+		return nil, fmt.Errorf("failed to create loan document index: %w", err)
+	}
+
+	// Record history
+	if err := h.recordEntityHistory(stub, req.LoanID, "LoanApplication", "DOCUMENT_UPLOADED", req.DocumentType, "", document.DocumentID, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to record history: %w", err)
+	}
+
+	// Emit event
+	if err := h.eventService.EmitDocumentEvent(stub, config.EventDocumentUploaded, &loanApp, document, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(document)
 }
 
-// VerifyDocument verifies a document (placeholder)
+// VerifyDocument records a reviewer's verification or rejection of a pending document
 func (h *DocumentHandler) VerifyDocument(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
-	return json.Marshal(map[string]string{"message": "Document verification functionality to be implemented"})
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.DocumentVerificationRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse document verification request: %v", err)
+	}
+
+	if req.Status != domain.DocumentStatusVerified && req.Status != domain.DocumentStatusRejected {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "status must be %s or %s", domain.DocumentStatusVerified, domain.DocumentStatusRejected)
+	}
+	if req.Status == domain.DocumentStatusRejected && strings.TrimSpace(req.Reason) == "" {
+		return nil, fmt.Errorf("reason is required when rejecting a document")
+	}
+
+	documentKey := fmt.Sprintf("DOCUMENT_%s", req.DocumentID)
+	var document domain.LoanDocument
+	if err := h.persistenceService.Get(stub, documentKey, &document); err != nil {
+		return nil, fmt.Errorf("document not found: %w", err)
+	}
+	if document.Status != domain.DocumentStatusPending {
+		return nil, fmt.Errorf("document %s has already been reviewed (status: %s)", req.DocumentID, document.Status)
+	}
+
+	var loanApp domain.LoanApplication
+	if err := h.persistenceService.Get(stub, fmt.Sprintf("LOAN_%s", document.LoanID), &loanApp); err != nil {
+		return nil, fmt.Errorf("loan application not found: %w", err)
+	}
+
+	// Record history
+	if err := h.recordEntityHistory(stub, document.LoanID, "LoanApplication", "DOCUMENT_REVIEW", document.DocumentType, string(document.Status), string(req.Status), req.ActorID); err != nil {
+		return nil, err
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	document.Status = req.Status
+	if req.Status == domain.DocumentStatusRejected {
+		document.RejectionReason = req.Reason
+	}
+	document.VerifiedDate = &now
+	document.VerifiedBy = req.ActorID
+	document.LastUpdated = now
+	document.LastUpdatedBy = req.ActorID
+
+	if err := h.persistenceService.Put(stub, documentKey, &document); err != nil {
+		return nil, fmt.Errorf("failed to update document: %w", err)
+	}
+
+	// Emit event
+	if err := h.eventService.EmitDocumentEvent(stub, config.EventDocumentVerified, &loanApp, &document, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(&document)
 }
 
-// GetDocument retrieves a document (placeholder)
+// GetDocument retrieves a document record by ID
 func (h *DocumentHandler) GetDocument(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
-	return json.Marshal(map[string]string{"message": "Document retrieval functionality to be implemented"})
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var document domain.LoanDocument
+	if err := h.persistenceService.Get(stub, fmt.Sprintf("DOCUMENT_%s", args[0]), &document); err != nil {
+		return nil, fmt.Errorf("document not found: %w", err)
+	}
+
+	return json.Marshal(&document)
 }
 
-// GetLoanDocuments retrieves all documents for a loan (placeholder)
+// GetLoanDocuments retrieves all documents recorded against a loan
 func (h *DocumentHandler) GetLoanDocuments(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
-	return json.Marshal(map[string]string{"message": "Loan documents retrieval functionality to be implemented"})
-}
\ No newline at end of file
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	documents, err := getLoanDocuments(stub, h.persistenceService, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(documents)
+}
+
+// Helper methods
+
+func getLoanDocuments(stub shim.ChaincodeStubInterface, ps *services.PersistenceService, loanID string) ([]domain.LoanDocument, error) {
+	iterator, err := stub.GetStateByPartialCompositeKey("LOAN_DOCUMENT", []string{loanID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loan documents: %w", err)
+	}
+	defer iterator.Close()
+
+	documents := []domain.LoanDocument{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate loan documents: %w", err)
+		}
+
+		var document domain.LoanDocument
+		if err := ps.Get(stub, fmt.Sprintf("DOCUMENT_%s", string(response.Value)), &document); err != nil {
+			continue // Skip if document not found
+		}
+
+		documents = append(documents, document)
+	}
+
+	return documents, nil
+}
+
+func (h *DocumentHandler) recordEntityHistory(stub shim.ChaincodeStubInterface, entityID, entityType, changeType, fieldName, previousValue, newValue, actorID string) error {
+	historyID, compositeKey, err := services.NewHistoryKey(stub, entityID)
+	if err != nil {
+		return err
+	}
+	txID := stub.GetTxID()
+
+	timestamp, err := services.TxTimeString(stub)
+	if err != nil {
+		return err
+	}
+
+	historyEntry := map[string]interface{}{
+		"historyID":     historyID,
+		"entityID":      entityID,
+		"entityType":    entityType,
+		"timestamp":     timestamp,
+		"changeType":    changeType,
+		"fieldName":     fieldName,
+		"previousValue": previousValue,
+		"newValue":      newValue,
+		"actorID":       actorID,
+		"transactionID": txID,
+	}
+
+	return h.persistenceService.Put(stub, compositeKey, historyEntry)
+}
@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	sharedChaincode "github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/chaincode"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// claimTime is when the first claim in each lock test is taken
+var claimTime = time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+// claimLoan claims a loan application for an actor as its own transaction
+func claimLoan(t *testing.T, stub *shimtest.MockStub, txID string, at time.Time, req sharedChaincode.EntityClaimRequest) (*services.EntityLock, error) {
+	t.Helper()
+	req.EntityType = "LoanApplication"
+	payload, err := inTxAt(stub, txID, at, func() ([]byte, error) {
+		return sharedChaincode.NewEntityLockHandler().ClaimEntity(stub, []string{mustJSON(t, req)})
+	})
+	if err != nil {
+		return nil, err
+	}
+	var lock services.EntityLock
+	if err := json.Unmarshal(payload, &lock); err != nil {
+		t.Fatalf("failed to decode claim: %v", err)
+	}
+	return &lock, nil
+}
+
+// rejectLoanAt rejects a loan application for an actor as its own transaction
+func rejectLoanAt(t *testing.T, stub *shimtest.MockStub, txID string, at time.Time, loanID, actorID string) error {
+	t.Helper()
+	_, err := inTxAt(stub, txID, at, func() ([]byte, error) {
+		return NewLoanApplicationHandler().RejectLoan(stub, []string{mustJSON(t, domain.LoanRejectionRequest{
+			LoanID: loanID, Reason: "Insufficient income", ActorID: actorID,
+		})})
+	})
+	return err
+}
+
+func TestLoanDecisionsRespectAnotherUnderwritersClaim(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleUnderwriter))
+	seedLoan(t, stub, "LOAN_L1", validation.LoanStatusCreditApproval)
+
+	if _, err := claimLoan(t, stub, "claim_1", claimTime, sharedChaincode.EntityClaimRequest{EntityID: "LOAN_L1", TTLMinutes: 30, ActorID: "ACTOR_A"}); err != nil {
+		t.Fatalf("claim failed: %v", err)
+	}
+
+	// Another underwriter cannot decide the application while the claim is live
+	err := rejectLoanAt(t, stub, "reject_other", claimTime.Add(5*time.Minute), "LOAN_L1", "ACTOR_B")
+	expectErrorCode(t, err, services.ErrCodeConflict)
+	_, err = inTxAt(stub, "approve_other", claimTime.Add(5*time.Minute), func() ([]byte, error) {
+		return NewLoanApplicationHandler().ApproveLoan(stub, []string{mustJSON(t, domain.LoanApprovalRequest{
+			LoanID: "LOAN_L1", ApprovedAmount: 10000, InterestRate: 5, ActorID: "ACTOR_B",
+		})})
+	})
+	expectErrorCode(t, err, services.ErrCodeConflict)
+	if loanApp := getLoan(t, stub, "LOAN_L1"); loanApp.Status != validation.LoanStatusCreditApproval || loanApp.Version != 1 {
+		t.Fatalf("blocked decisions must leave the loan unchanged, got %s at version %d", loanApp.Status, loanApp.Version)
+	}
+
+	// Re-claiming extends the holder's own claim rather than conflicting with it
+	extended, err := claimLoan(t, stub, "claim_again", claimTime.Add(20*time.Minute), sharedChaincode.EntityClaimRequest{EntityID: "LOAN_L1", TTLMinutes: 30, ActorID: "ACTOR_A"})
+	if err != nil {
+		t.Fatalf("re-claim failed: %v", err)
+	}
+	if !extended.ExpiresDate.Equal(claimTime.Add(50 * time.Minute)) {
+		t.Errorf("expected the claim extended to %s, got %s", claimTime.Add(50*time.Minute), extended.ExpiresDate)
+	}
+
+	// The holder's decision goes through and completes the claim
+	if err := rejectLoanAt(t, stub, "reject_holder", claimTime.Add(25*time.Minute), "LOAN_L1", "ACTOR_A"); err != nil {
+		t.Fatalf("holder's rejection failed: %v", err)
+	}
+	stub.MockTransactionStart("lock_after")
+	lock, err := services.NewEntityLockService().GetLock(stub, "LoanApplication", "LOAN_L1")
+	stub.MockTransactionEnd("lock_after")
+	if err != nil || lock != nil {
+		t.Errorf("expected the claim released with the decision, got %+v (%v)", lock, err)
+	}
+}
+
+func TestExpiredClaimDoesNotBlockDecisions(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleUnderwriter))
+	seedLoan(t, stub, "LOAN_L2", validation.LoanStatusCreditApproval)
+
+	if _, err := claimLoan(t, stub, "claim_short", claimTime, sharedChaincode.EntityClaimRequest{EntityID: "LOAN_L2", TTLMinutes: 10, ActorID: "ACTOR_A"}); err != nil {
+		t.Fatalf("claim failed: %v", err)
+	}
+
+	// At the expiry instant the claim is no longer in force
+	if err := rejectLoanAt(t, stub, "reject_expired", claimTime.Add(10*time.Minute), "LOAN_L2", "ACTOR_B"); err != nil {
+		t.Fatalf("rejection after the claim expired failed: %v", err)
+	}
+	if loanApp := getLoan(t, stub, "LOAN_L2"); loanApp.Status != validation.LoanStatusRejected || loanApp.LastUpdatedBy != "ACTOR_B" {
+		t.Errorf("expected REJECTED by ACTOR_B, got %s by %s", loanApp.Status, loanApp.LastUpdatedBy)
+	}
+}
+
+func TestSupervisorOverridesLiveClaim(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleUnderwriter))
+	seedLoan(t, stub, "LOAN_L3", validation.LoanStatusCreditApproval)
+
+	if _, err := claimLoan(t, stub, "claim_holder", claimTime, sharedChaincode.EntityClaimRequest{EntityID: "LOAN_L3", ActorID: "ACTOR_A"}); err != nil {
+		t.Fatalf("claim failed: %v", err)
+	}
+
+	// A peer can neither claim over a live claim nor override it
+	_, err := claimLoan(t, stub, "claim_peer", claimTime.Add(time.Minute), sharedChaincode.EntityClaimRequest{EntityID: "LOAN_L3", ActorID: "ACTOR_B"})
+	expectErrorCode(t, err, services.ErrCodeConflict)
+	_, err = claimLoan(t, stub, "override_peer", claimTime.Add(time.Minute), sharedChaincode.EntityClaimRequest{EntityID: "LOAN_L3", Override: true, Reason: "Holder on leave", ActorID: "ACTOR_B"})
+	expectErrorCode(t, err, services.ErrCodeAccessDenied)
+
+	// A credit officer takes the claim over with a reason
+	stub.Creator = newTestIdentity(t, string(validation.ActorRoleCreditOfficer))
+	_, err = claimLoan(t, stub, "override_blank", claimTime.Add(2*time.Minute), sharedChaincode.EntityClaimRequest{EntityID: "LOAN_L3", Override: true, ActorID: "ACTOR_C"})
+	expectErrorCode(t, err, services.ErrCodeInvalidArgument)
+	lock, err := claimLoan(t, stub, "override_officer", claimTime.Add(2*time.Minute), sharedChaincode.EntityClaimRequest{EntityID: "LOAN_L3", Override: true, Reason: "Holder on leave", ActorID: "ACTOR_C"})
+	if err != nil {
+		t.Fatalf("supervisor override failed: %v", err)
+	}
+	if lock.HolderID != "ACTOR_C" || lock.OverriddenHolderID != "ACTOR_A" || lock.OverrideReason != "Holder on leave" {
+		t.Errorf("expected ACTOR_C to hold the claim over ACTOR_A, got %+v", lock)
+	}
+
+	// The previous holder is now the one blocked
+	err = rejectLoanAt(t, stub, "reject_previous", claimTime.Add(3*time.Minute), "LOAN_L3", "ACTOR_A")
+	expectErrorCode(t, err, services.ErrCodeConflict)
+}
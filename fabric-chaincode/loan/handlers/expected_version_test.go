@@ -0,0 +1,264 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+func TestLoanDecisionsRejectStaleExpectedVersion(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleUnderwriter))
+	handler := NewLoanApplicationHandler()
+	seedLoan(t, stub, "LOAN_V1", validation.LoanStatusCreditApproval)
+
+	_, err := inTx(stub, "approve_stale", func() ([]byte, error) {
+		return handler.ApproveLoan(stub, []string{mustJSON(t, domain.LoanApprovalRequest{
+			LoanID: "LOAN_V1", ApprovedAmount: 10000, InterestRate: 5, ExpectedVersion: intPtr(2), ActorID: "ACTOR_002",
+		})})
+	})
+	expectVersionConflict(t, err, 1)
+
+	_, err = inTx(stub, "reprice_stale", func() ([]byte, error) {
+		return handler.RepriceLoan(stub, []string{mustJSON(t, domain.LoanRepriceRequest{
+			LoanID: "LOAN_V1", ExpectedVersion: intPtr(0), ActorID: "ACTOR_002",
+		})})
+	})
+	expectVersionConflict(t, err, 1)
+
+	_, err = inTx(stub, "reject_stale", func() ([]byte, error) {
+		return handler.RejectLoan(stub, []string{mustJSON(t, domain.LoanRejectionRequest{
+			LoanID: "LOAN_V1", Reason: "Affordability", ExpectedVersion: intPtr(0), ActorID: "ACTOR_002",
+		})})
+	})
+	expectVersionConflict(t, err, 1)
+	if loanApp := getLoan(t, stub, "LOAN_V1"); loanApp.Status != validation.LoanStatusCreditApproval || loanApp.Version != 1 {
+		t.Fatalf("stale decisions must leave the loan unchanged, got %s at version %d", loanApp.Status, loanApp.Version)
+	}
+
+	// A decision made against the current version is applied and advances it
+	_, err = inTx(stub, "reject_current", func() ([]byte, error) {
+		return handler.RejectLoan(stub, []string{mustJSON(t, domain.LoanRejectionRequest{
+			LoanID: "LOAN_V1", Reason: "Affordability", ExpectedVersion: intPtr(1), ActorID: "ACTOR_002",
+		})})
+	})
+	if err != nil {
+		t.Fatalf("rejection at the current version failed: %v", err)
+	}
+	if loanApp := getLoan(t, stub, "LOAN_V1"); loanApp.Status != validation.LoanStatusRejected || loanApp.Version != 2 {
+		t.Errorf("expected REJECTED at version 2, got %s at version %d", loanApp.Status, loanApp.Version)
+	}
+}
+
+func TestCancelApplicationRejectsStaleExpectedVersion(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleCustomerServiceRep))
+	handler := NewLoanApplicationHandler()
+	seedLoan(t, stub, "LOAN_V2", validation.LoanStatusSubmitted)
+
+	cancel := func(txID string, expectedVersion int) error {
+		_, err := inTx(stub, txID, func() ([]byte, error) {
+			return handler.CancelApplication(stub, []string{mustJSON(t, domain.LoanCancellationRequest{
+				LoanID:          "LOAN_V2",
+				InitiatedBy:     domain.CancellationByCustomer,
+				ReasonCode:      "NO_LONGER_REQUIRED",
+				ExpectedVersion: intPtr(expectedVersion),
+				ActorID:         "ACTOR_003",
+			})})
+		})
+		return err
+	}
+
+	expectVersionConflict(t, cancel("cancel_stale", 3), 1)
+	if err := cancel("cancel_current", 1); err != nil {
+		t.Fatalf("cancellation at the current version failed: %v", err)
+	}
+	if loanApp := getLoan(t, stub, "LOAN_V2"); loanApp.Status != validation.LoanStatusCancelled || loanApp.Version != 2 {
+		t.Errorf("expected CANCELLED at version 2, got %s at version %d", loanApp.Status, loanApp.Version)
+	}
+}
+
+func TestCollateralUpdatesRejectStaleExpectedVersion(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleUnderwriter))
+	handler := NewCollateralHandler()
+	seedLoan(t, stub, "LOAN_V3", validation.LoanStatusSubmitted)
+	valuationDate := time.Date(2026, 1, 2, 0, 0, 0, 0, time.UTC)
+
+	add := func(txID string, expectedVersion int) ([]byte, error) {
+		return inTx(stub, txID, func() ([]byte, error) {
+			return handler.AddCollateral(stub, []string{mustJSON(t, domain.CollateralRequest{
+				LoanID:          "LOAN_V3",
+				CollateralType:  "VEHICLE",
+				Description:     "2024 hatchback",
+				Valuation:       15000,
+				ValuationDate:   valuationDate,
+				AppraiserID:     "APPRAISER_001",
+				ExpectedVersion: intPtr(expectedVersion),
+				ActorID:         "ACTOR_002",
+			})})
+		})
+	}
+
+	// Pledging collateral is checked against the loan's version
+	_, err := add("add_stale", 0)
+	expectVersionConflict(t, err, 1)
+	payload, err := add("add_current", 1)
+	if err != nil {
+		t.Fatalf("adding collateral at the current loan version failed: %v", err)
+	}
+	var collateral domain.Collateral
+	if err := json.Unmarshal(payload, &collateral); err != nil {
+		t.Fatalf("failed to decode collateral: %v", err)
+	}
+	if collateral.Version != 1 {
+		t.Fatalf("expected new collateral at version 1, got %d", collateral.Version)
+	}
+
+	revalue := func(txID string, expectedVersion int) ([]byte, error) {
+		return inTx(stub, txID, func() ([]byte, error) {
+			return handler.UpdateCollateralValuation(stub, []string{mustJSON(t, domain.CollateralValuationRequest{
+				CollateralID:    collateral.CollateralID,
+				Valuation:       14000,
+				ValuationDate:   valuationDate.AddDate(0, 0, 1),
+				AppraiserID:     "APPRAISER_001",
+				ExpectedVersion: intPtr(expectedVersion),
+				ActorID:         "ACTOR_002",
+			})})
+		})
+	}
+	_, err = revalue("revalue_stale", 2)
+	expectVersionConflict(t, err, 1)
+	if _, err := revalue("revalue_current", 1); err != nil {
+		t.Fatalf("revaluation at the current version failed: %v", err)
+	}
+
+	release := func(txID string, expectedVersion int) ([]byte, error) {
+		return inTx(stub, txID, func() ([]byte, error) {
+			return handler.ReleaseCollateral(stub, []string{mustJSON(t, domain.CollateralReleaseRequest{
+				CollateralID:    collateral.CollateralID,
+				Reason:          "Application withdrawn",
+				ExpectedVersion: intPtr(expectedVersion),
+				ActorID:         "ACTOR_002",
+			})})
+		})
+	}
+
+	// The revaluation advanced the collateral, so a release made against the first read is refused
+	_, err = release("release_stale", 1)
+	expectVersionConflict(t, err, 2)
+	payload, err = release("release_current", 2)
+	if err != nil {
+		t.Fatalf("release at the current version failed: %v", err)
+	}
+	if err := json.Unmarshal(payload, &collateral); err != nil {
+		t.Fatalf("failed to decode collateral: %v", err)
+	}
+	if collateral.LienStatus != domain.LienStatusReleased || collateral.Version != 3 {
+		t.Errorf("expected RELEASED at version 3, got %s at version %d", collateral.LienStatus, collateral.Version)
+	}
+}
+
+func TestCounterpartyStatusUpdateRejectsStaleExpectedVersion(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	handler := NewCounterpartyHandler()
+
+	payload, err := inTx(stub, "register_counterparty", func() ([]byte, error) {
+		return handler.RegisterCounterparty(stub, []string{mustJSON(t, domain.CounterpartyRegistrationRequest{
+			LegalName: "Harbor Servicing Ltd", CounterpartyType: "SERVICER", RegistrationNumber: "HS-1001", Jurisdiction: "GB", ActorID: "ACTOR_005",
+		})})
+	})
+	if err != nil {
+		t.Fatalf("registration failed: %v", err)
+	}
+	var counterparty domain.Counterparty
+	if err := json.Unmarshal(payload, &counterparty); err != nil {
+		t.Fatalf("failed to decode counterparty: %v", err)
+	}
+	if counterparty.Version != 1 {
+		t.Fatalf("expected a new counterparty at version 1, got %d", counterparty.Version)
+	}
+
+	// Onboarding writes advance the version past what a client read at registration
+	if _, err := inTx(stub, "verify_kyc", func() ([]byte, error) {
+		return handler.UpdateCounterpartyKYC(stub, []string{mustJSON(t, domain.CounterpartyKYCUpdateRequest{
+			CounterpartyID: counterparty.CounterpartyID, NewStatus: validation.KYCStatusVerified, ActorID: "ACTOR_005",
+		})})
+	}); err != nil {
+		t.Fatalf("KYC update failed: %v", err)
+	}
+	if _, err := inTx(stub, "screen", func() ([]byte, error) {
+		return handler.RecordCounterpartyScreening(stub, []string{mustJSON(t, domain.CounterpartyScreeningRequest{
+			CounterpartyID: counterparty.CounterpartyID, ScreeningID: "SCR_001", Result: validation.AMLStatusClear, ActorID: "ACTOR_005",
+		})})
+	}); err != nil {
+		t.Fatalf("screening failed: %v", err)
+	}
+
+	activate := func(txID string, expectedVersion int) ([]byte, error) {
+		return inTx(stub, txID, func() ([]byte, error) {
+			return handler.UpdateCounterpartyStatus(stub, []string{mustJSON(t, domain.CounterpartyStatusUpdateRequest{
+				CounterpartyID: counterparty.CounterpartyID, NewStatus: validation.CounterpartyStatusActive, ExpectedVersion: intPtr(expectedVersion), ActorID: "ACTOR_005",
+			})})
+		})
+	}
+	_, err = activate("activate_stale", 1)
+	expectVersionConflict(t, err, 3)
+
+	payload, err = activate("activate_current", 3)
+	if err != nil {
+		t.Fatalf("activation at the current version failed: %v", err)
+	}
+	if err := json.Unmarshal(payload, &counterparty); err != nil {
+		t.Fatalf("failed to decode counterparty: %v", err)
+	}
+	if counterparty.Status != validation.CounterpartyStatusActive || counterparty.Version != 4 {
+		t.Errorf("expected ACTIVE at version 4, got %s at version %d", counterparty.Status, counterparty.Version)
+	}
+}
+
+func TestIntroducerStatusUpdateRejectsStaleExpectedVersion(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	handler := NewIntroducerHandler()
+	if _, err := inTx(stub, "schedule", func() ([]byte, error) {
+		return handler.SetCommissionSchedule(stub, []string{mustJSON(t, domain.CommissionScheduleRequest{
+			ScheduleID: "SCHED_STD", Rates: []domain.CommissionRate{{LoanType: "PERSONAL", RatePercent: 1}}, ActorID: "ACTOR_005",
+		})})
+	}); err != nil {
+		t.Fatalf("failed to set commission schedule: %v", err)
+	}
+	if _, err := inTx(stub, "register_introducer", func() ([]byte, error) {
+		return handler.RegisterIntroducer(stub, []string{mustJSON(t, domain.IntroducerRegistrationRequest{
+			IntroducerID: "BROKER_001", LegalName: "Northgate Brokers", ScheduleID: "SCHED_STD", ActorID: "ACTOR_005",
+		})})
+	}); err != nil {
+		t.Fatalf("registration failed: %v", err)
+	}
+
+	update := func(txID string, status domain.IntroducerStatus, expectedVersion int) ([]byte, error) {
+		return inTx(stub, txID, func() ([]byte, error) {
+			return handler.UpdateIntroducerStatus(stub, []string{mustJSON(t, domain.IntroducerStatusUpdateRequest{
+				IntroducerID: "BROKER_001", NewStatus: status, Reason: "Compliance review", ExpectedVersion: intPtr(expectedVersion), ActorID: "ACTOR_005",
+			})})
+		})
+	}
+
+	// Two managers read version 1; the suspension wins and the termination is refused
+	if _, err := update("suspend", domain.IntroducerStatusSuspended, 1); err != nil {
+		t.Fatalf("suspension at the current version failed: %v", err)
+	}
+	_, err := update("terminate_stale", domain.IntroducerStatusTerminated, 1)
+	expectVersionConflict(t, err, 2)
+
+	payload, err := update("terminate_current", domain.IntroducerStatusTerminated, 2)
+	if err != nil {
+		t.Fatalf("termination at the current version failed: %v", err)
+	}
+	var introducer domain.Introducer
+	if err := json.Unmarshal(payload, &introducer); err != nil {
+		t.Fatalf("failed to decode introducer: %v", err)
+	}
+	if introducer.Status != domain.IntroducerStatusTerminated || introducer.Version != 3 {
+		t.Errorf("expected TERMINATED at version 3, got %s at version %d", introducer.Status, introducer.Version)
+	}
+}
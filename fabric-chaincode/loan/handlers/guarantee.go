@@ -0,0 +1,519 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	loanServices "github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// GuaranteeHandler handles loan guarantees and their invocation on default
+type GuaranteeHandler struct {
+	persistenceService *services.PersistenceService
+	eventService      *loanServices.EventService
+	customerVerifier  *loanServices.CustomerVerificationService
+}
+
+// NewGuaranteeHandler creates a new guarantee handler
+func NewGuaranteeHandler() *GuaranteeHandler {
+	return &GuaranteeHandler{
+		persistenceService: services.NewPersistenceService(),
+		eventService:      loanServices.NewEventService(),
+		customerVerifier:  loanServices.NewCustomerVerificationService(),
+	}
+}
+
+// AddLoanGuarantee registers a guarantor customer's guarantee over part or all of a loan
+func (h *GuaranteeHandler) AddLoanGuarantee(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.LoanGuaranteeRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse loan guarantee request: %v", err)
+	}
+
+	// Get existing loan application
+	loanKey := fmt.Sprintf("LOAN_%s", req.LoanID)
+	var loanApp domain.LoanApplication
+	if err := h.persistenceService.Get(stub, loanKey, &loanApp); err != nil {
+		return nil, fmt.Errorf("loan application not found: %w", err)
+	}
+
+	if loanApp.Status == validation.LoanStatusRejected || loanApp.Status == validation.LoanStatusCancelled || loanApp.Status == validation.LoanStatusDefaulted {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "guarantees cannot be added to loan in status: %s", loanApp.Status)
+	}
+	if req.GuarantorCustomerID == loanApp.CustomerID {
+		return nil, fmt.Errorf("borrower cannot guarantee their own loan")
+	}
+
+	// Cover cannot exceed the approved amount, or the requested amount before approval
+	loanAmount := loanApp.RequestedAmount.Float64()
+	if loanApp.ApprovedAmount != nil {
+		loanAmount = loanApp.ApprovedAmount.Float64()
+	}
+	if req.CoverageAmount <= 0 || req.CoverageAmount > loanAmount+balanceTolerance {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "coverage amount must be positive and at most %.2f", loanAmount)
+	}
+
+	// Verify guarantor KYC/AML/consent with the customer chaincode
+	if _, err := h.customerVerifier.VerifyCustomer(stub, req.GuarantorCustomerID); err != nil {
+		return nil, fmt.Errorf("guarantor verification failed: %w", err)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	guarantee := &domain.LoanGuarantee{
+		GuaranteeID:         services.GenerateDeterministicID(stub, config.LoanGuaranteePrefix),
+		LoanID:              req.LoanID,
+		BorrowerCustomerID:  loanApp.CustomerID,
+		GuarantorCustomerID: req.GuarantorCustomerID,
+		CoverageAmount:      req.CoverageAmount,
+		Status:              domain.GuaranteeStatusActive,
+		CreatedDate:         now,
+		LastUpdated:         now,
+		CreatedBy:           req.ActorID,
+		LastUpdatedBy:       req.ActorID,
+	}
+
+	if err := h.persistenceService.Put(stub, fmt.Sprintf("GUARANTEE_%s", guarantee.GuaranteeID), guarantee); err != nil {
+		return nil, fmt.Errorf("failed to store guarantee: %w", err)
+	}
+	if err := h.putIndex(stub, "LOAN_GUARANTEE", req.LoanID, guarantee.GuaranteeID); err != nil {
+		return nil, err
+	}
+	if err := h.putIndex(stub, "GUARANTOR_GUARANTEE", req.GuarantorCustomerID, guarantee.GuaranteeID); err != nil {
+		return nil, err
+	}
+
+	// Guarantor takes on contingent liability; the borrower's loan gains cover
+	if err := h.adjustExposure(stub, req.GuarantorCustomerID, func(e *domain.CustomerExposure) {
+		e.ContingentLiability += req.CoverageAmount
+	}); err != nil {
+		return nil, err
+	}
+	if err := h.adjustExposure(stub, loanApp.CustomerID, func(e *domain.CustomerExposure) {
+		e.GuaranteedBorrowing += req.CoverageAmount
+	}); err != nil {
+		return nil, err
+	}
+
+	// Record history
+	if err := h.recordEntityHistory(stub, req.LoanID, "LoanApplication", "GUARANTEE_ADDED", "guaranteeID", "", guarantee.GuaranteeID, req.ActorID); err != nil {
+		return nil, err
+	}
+
+	// Emit event
+	if err := h.eventService.EmitGuaranteeAdded(stub, guarantee, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(guarantee)
+}
+
+// InvokeGuarantee calls on a guarantee after loan default, creating a recovery obligation against the guarantor
+func (h *GuaranteeHandler) InvokeGuarantee(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.GuaranteeInvocationRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse guarantee invocation request: %v", err)
+	}
+
+	guaranteeKey := fmt.Sprintf("GUARANTEE_%s", req.GuaranteeID)
+	var guarantee domain.LoanGuarantee
+	if err := h.persistenceService.Get(stub, guaranteeKey, &guarantee); err != nil {
+		return nil, fmt.Errorf("guarantee not found: %w", err)
+	}
+	if guarantee.Status != domain.GuaranteeStatusActive {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "guarantee %s cannot be invoked in status: %s", req.GuaranteeID, guarantee.Status)
+	}
+
+	var loanApp domain.LoanApplication
+	if err := h.persistenceService.Get(stub, fmt.Sprintf("LOAN_%s", guarantee.LoanID), &loanApp); err != nil {
+		return nil, fmt.Errorf("loan application not found: %w", err)
+	}
+	if loanApp.Status != validation.LoanStatusDefaulted {
+		return nil, services.NewChaincodeError(services.ErrCodeAccessDenied, "", "guarantee can only be invoked on a defaulted loan (status: %s)", loanApp.Status)
+	}
+
+	// Claim the lesser of the cover and the balance not already claimed from other guarantors
+	obligations, err := h.getLoanObligations(stub, guarantee.LoanID)
+	if err != nil {
+		return nil, err
+	}
+	unclaimed := loanApp.OutstandingBalance.Float64()
+	for _, existing := range obligations {
+		unclaimed -= existing.OutstandingAmount
+	}
+	claim := roundToCents(guarantee.CoverageAmount)
+	if unclaimed < claim {
+		claim = roundToCents(unclaimed)
+	}
+	if claim <= 0 {
+		return nil, fmt.Errorf("loan %s has no unclaimed balance to recover", guarantee.LoanID)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	obligation := &domain.RecoveryObligation{
+		ObligationID:        services.GenerateDeterministicID(stub, config.RecoveryObligationPrefix),
+		GuaranteeID:         guarantee.GuaranteeID,
+		LoanID:              guarantee.LoanID,
+		GuarantorCustomerID: guarantee.GuarantorCustomerID,
+		Amount:              claim,
+		OutstandingAmount:   claim,
+		Status:              domain.RecoveryObligationOpen,
+		CreatedDate:         now,
+		LastUpdated:         now,
+		CreatedBy:           req.ActorID,
+		LastUpdatedBy:       req.ActorID,
+	}
+	if err := h.persistenceService.Put(stub, fmt.Sprintf("RECOVERY_OBLIGATION_%s", obligation.ObligationID), obligation); err != nil {
+		return nil, fmt.Errorf("failed to store recovery obligation: %w", err)
+	}
+	if err := h.putIndex(stub, "LOAN_RECOVERY_OBLIGATION", guarantee.LoanID, obligation.ObligationID); err != nil {
+		return nil, err
+	}
+
+	guarantee.Status = domain.GuaranteeStatusInvoked
+	guarantee.InvokedAmount = claim
+	guarantee.InvokedDate = &now
+	guarantee.ObligationID = obligation.ObligationID
+	guarantee.LastUpdated = now
+	guarantee.LastUpdatedBy = req.ActorID
+	if err := h.persistenceService.Put(stub, guaranteeKey, &guarantee); err != nil {
+		return nil, fmt.Errorf("failed to update guarantee: %w", err)
+	}
+
+	// Contingent liability crystallises into a recovery obligation; the borrower loses the cover
+	if err := h.adjustExposure(stub, guarantee.GuarantorCustomerID, func(e *domain.CustomerExposure) {
+		e.ContingentLiability -= guarantee.CoverageAmount
+		e.RecoveryObligations += claim
+	}); err != nil {
+		return nil, err
+	}
+	if err := h.adjustExposure(stub, guarantee.BorrowerCustomerID, func(e *domain.CustomerExposure) {
+		e.GuaranteedBorrowing -= guarantee.CoverageAmount
+	}); err != nil {
+		return nil, err
+	}
+
+	// Record history
+	if err := h.recordEntityHistory(stub, guarantee.GuaranteeID, "LoanGuarantee", "STATUS_UPDATE", "status", string(domain.GuaranteeStatusActive), string(domain.GuaranteeStatusInvoked), req.ActorID); err != nil {
+		return nil, err
+	}
+	if err := h.recordEntityHistory(stub, guarantee.LoanID, "LoanApplication", "GUARANTEE_INVOKED", "obligationID", "", obligation.ObligationID, req.ActorID); err != nil {
+		return nil, err
+	}
+
+	// Emit event
+	if err := h.eventService.EmitGuaranteeInvoked(stub, &guarantee, obligation, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(obligation)
+}
+
+// RecordGuarantorPayment records a guarantor payment against a recovery obligation and applies it to the loan
+func (h *GuaranteeHandler) RecordGuarantorPayment(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.GuarantorPaymentRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse guarantor payment request: %v", err)
+	}
+
+	obligationKey := fmt.Sprintf("RECOVERY_OBLIGATION_%s", req.ObligationID)
+	var obligation domain.RecoveryObligation
+	if err := h.persistenceService.Get(stub, obligationKey, &obligation); err != nil {
+		return nil, fmt.Errorf("recovery obligation not found: %w", err)
+	}
+	if obligation.Status != domain.RecoveryObligationOpen {
+		return nil, fmt.Errorf("recovery obligation %s is %s", req.ObligationID, obligation.Status)
+	}
+	if req.Amount <= 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "payment amount must be positive")
+	}
+	if req.Amount > obligation.OutstandingAmount+balanceTolerance {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "payment %.2f exceeds outstanding obligation %.2f", req.Amount, obligation.OutstandingAmount)
+	}
+
+	// Apply the payment to the defaulted loan's ledger
+	loanKey := fmt.Sprintf("LOAN_%s", obligation.LoanID)
+	var loanApp domain.LoanApplication
+	if err := h.persistenceService.Get(stub, loanKey, &loanApp); err != nil {
+		return nil, fmt.Errorf("loan application not found: %w", err)
+	}
+	previousBalance := loanApp.OutstandingBalance
+	txn, err := postLoanTransaction(stub, h.persistenceService, &loanApp, domain.LoanTransactionRepayment, utils.NewMoney(req.Amount, loanApp.Currency), obligation.ObligationID, "", req.ActorID)
+	if err != nil {
+		return nil, err
+	}
+	if err := putLoanApplication(stub, h.persistenceService, &loanApp); err != nil {
+		return nil, fmt.Errorf("failed to update loan application: %w", err)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	obligation.PaidAmount = roundToCents(obligation.PaidAmount + req.Amount)
+	obligation.OutstandingAmount = roundToCents(obligation.Amount - obligation.PaidAmount)
+	if obligation.OutstandingAmount <= balanceTolerance {
+		obligation.OutstandingAmount = 0
+		obligation.Status = domain.RecoveryObligationSettled
+	}
+	obligation.LastUpdated = now
+	obligation.LastUpdatedBy = req.ActorID
+	if err := h.persistenceService.Put(stub, obligationKey, &obligation); err != nil {
+		return nil, fmt.Errorf("failed to update recovery obligation: %w", err)
+	}
+
+	// Guarantor payments are tracked separately from borrower repayments
+	payment := &domain.GuarantorPayment{
+		PaymentID:        services.GenerateDeterministicID(stub, config.GuarantorPaymentPrefix),
+		ObligationID:     obligation.ObligationID,
+		LoanID:           obligation.LoanID,
+		Amount:           req.Amount,
+		Reference:        req.Reference,
+		TransactionID:    txn.TransactionID,
+		OutstandingAfter: obligation.OutstandingAmount,
+		CreatedDate:      now,
+		CreatedBy:        req.ActorID,
+	}
+	paymentKey, err := stub.CreateCompositeKey("GUARANTOR_PAYMENT", []string{obligation.ObligationID, payment.PaymentID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := h.persistenceService.Put(stub, paymentKey, payment); err != nil {
+		return nil, fmt.Errorf("failed to store guarantor payment: %w", err)
+	}
+
+	if err := h.adjustExposure(stub, obligation.GuarantorCustomerID, func(e *domain.CustomerExposure) {
+		e.RecoveryObligations -= req.Amount
+	}); err != nil {
+		return nil, err
+	}
+	if err := h.adjustExposure(stub, loanApp.CustomerID, func(e *domain.CustomerExposure) {
+		e.RecoveredFromGuarantors += req.Amount
+	}); err != nil {
+		return nil, err
+	}
+
+	// Record history
+	if err := h.recordEntityHistory(stub, obligation.LoanID, "LoanApplication", "GUARANTOR_PAYMENT", "outstandingBalance", previousBalance.String(), loanApp.OutstandingBalance.String(), req.ActorID); err != nil {
+		return nil, err
+	}
+
+	// Emit event
+	if err := h.eventService.EmitGuarantorPaymentRecorded(stub, payment, &obligation, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(payment)
+}
+
+// GetLoanGuarantees retrieves all guarantees registered on a loan
+func (h *GuaranteeHandler) GetLoanGuarantees(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	iterator, err := stub.GetStateByPartialCompositeKey("LOAN_GUARANTEE", []string{args[0]})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loan guarantees: %w", err)
+	}
+	defer iterator.Close()
+
+	guarantees := []domain.LoanGuarantee{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate guarantees: %w", err)
+		}
+
+		var guarantee domain.LoanGuarantee
+		if err := h.persistenceService.Get(stub, fmt.Sprintf("GUARANTEE_%s", string(response.Value)), &guarantee); err != nil {
+			continue // Skip if guarantee not found
+		}
+
+		guarantees = append(guarantees, guarantee)
+	}
+
+	return json.Marshal(guarantees)
+}
+
+// GetRecoveryObligation retrieves a recovery obligation with its guarantor payments
+func (h *GuaranteeHandler) GetRecoveryObligation(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var obligation domain.RecoveryObligation
+	if err := h.persistenceService.Get(stub, fmt.Sprintf("RECOVERY_OBLIGATION_%s", args[0]), &obligation); err != nil {
+		return nil, fmt.Errorf("recovery obligation not found: %w", err)
+	}
+
+	iterator, err := stub.GetStateByPartialCompositeKey("GUARANTOR_PAYMENT", []string{args[0]})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get guarantor payments: %w", err)
+	}
+	defer iterator.Close()
+
+	payments := []domain.GuarantorPayment{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate guarantor payments: %w", err)
+		}
+
+		var payment domain.GuarantorPayment
+		if err := json.Unmarshal(response.Value, &payment); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal guarantor payment: %w", err)
+		}
+
+		payments = append(payments, payment)
+	}
+
+	result := map[string]interface{}{
+		"obligation": obligation,
+		"payments":   payments,
+	}
+
+	return json.Marshal(result)
+}
+
+// GetCustomerExposure retrieves a customer's guarantee exposure as borrower and guarantor
+func (h *GuaranteeHandler) GetCustomerExposure(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	exposure := &domain.CustomerExposure{CustomerID: args[0]}
+	exists, err := h.persistenceService.Exists(stub, fmt.Sprintf("CUSTOMER_EXPOSURE_%s", args[0]))
+	if err != nil {
+		return nil, fmt.Errorf("failed to check customer exposure: %w", err)
+	}
+	if exists {
+		if err := h.persistenceService.Get(stub, fmt.Sprintf("CUSTOMER_EXPOSURE_%s", args[0]), exposure); err != nil {
+			return nil, fmt.Errorf("failed to get customer exposure: %w", err)
+		}
+	}
+
+	return json.Marshal(exposure)
+}
+
+// Helper methods
+
+func (h *GuaranteeHandler) getLoanObligations(stub shim.ChaincodeStubInterface, loanID string) ([]domain.RecoveryObligation, error) {
+	iterator, err := stub.GetStateByPartialCompositeKey("LOAN_RECOVERY_OBLIGATION", []string{loanID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recovery obligations: %w", err)
+	}
+	defer iterator.Close()
+
+	obligations := []domain.RecoveryObligation{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate recovery obligations: %w", err)
+		}
+
+		var obligation domain.RecoveryObligation
+		if err := h.persistenceService.Get(stub, fmt.Sprintf("RECOVERY_OBLIGATION_%s", string(response.Value)), &obligation); err != nil {
+			return nil, fmt.Errorf("recovery obligation not found: %w", err)
+		}
+
+		obligations = append(obligations, obligation)
+	}
+
+	return obligations, nil
+}
+
+func (h *GuaranteeHandler) putIndex(stub shim.ChaincodeStubInterface, indexName, attribute, entityID string) error {
+	indexKey, err := stub.CreateCompositeKey(indexName, []string{attribute, entityID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := stub.PutState(indexKey, []byte(entityID)); err != nil {
+		return fmt.Errorf("failed to create %s index: %w", indexName, err)
+	}
+	return nil
+}
+
+// adjustExposure loads (or starts) a customer's exposure record, applies the change and stores it
+func (h *GuaranteeHandler) adjustExposure(stub shim.ChaincodeStubInterface, customerID string, apply func(*domain.CustomerExposure)) error {
+	exposureKey := fmt.Sprintf("CUSTOMER_EXPOSURE_%s", customerID)
+	exposure := &domain.CustomerExposure{CustomerID: customerID}
+
+	exists, err := h.persistenceService.Exists(stub, exposureKey)
+	if err != nil {
+		return fmt.Errorf("failed to check customer exposure: %w", err)
+	}
+	if exists {
+		if err := h.persistenceService.Get(stub, exposureKey, exposure); err != nil {
+			return fmt.Errorf("failed to get customer exposure: %w", err)
+		}
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return err
+	}
+
+	apply(exposure)
+	exposure.ContingentLiability = roundToCents(exposure.ContingentLiability)
+	exposure.RecoveryObligations = roundToCents(exposure.RecoveryObligations)
+	exposure.GuaranteedBorrowing = roundToCents(exposure.GuaranteedBorrowing)
+	exposure.RecoveredFromGuarantors = roundToCents(exposure.RecoveredFromGuarantors)
+	exposure.LastUpdated = now
+
+	if err := h.persistenceService.Put(stub, exposureKey, exposure); err != nil {
+		return fmt.Errorf("failed to update customer exposure: %w", err)
+	}
+	return nil
+}
+
+func (h *GuaranteeHandler) recordEntityHistory(stub shim.ChaincodeStubInterface, entityID, entityType, changeType, fieldName, previousValue, newValue, actorID string) error {
+	historyID, compositeKey, err := services.NewHistoryKey(stub, entityID)
+	if err != nil {
+		return err
+	}
+	txID := stub.GetTxID()
+
+	timestamp, err := services.TxTimeString(stub)
+	if err != nil {
+		return err
+	}
+
+	historyEntry := map[string]interface{}{
+		"historyID":     historyID,
+		"entityID":      entityID,
+		"entityType":    entityType,
+		"timestamp":     timestamp,
+		"changeType":    changeType,
+		"fieldName":     fieldName,
+		"previousValue": previousValue,
+		"newValue":      newValue,
+		"actorID":       actorID,
+		"transactionID": txID,
+	}
+
+	return h.persistenceService.Put(stub, compositeKey, historyEntry)
+}
@@ -0,0 +1,187 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+func addGuarantee(t *testing.T, stub *shimtest.MockStub, txID, loanID, guarantorID string, coverage float64) (*domain.LoanGuarantee, error) {
+	payload, err := inTx(stub, txID, func() ([]byte, error) {
+		return NewGuaranteeHandler().AddLoanGuarantee(stub, []string{mustJSON(t, domain.LoanGuaranteeRequest{
+			LoanID: loanID, GuarantorCustomerID: guarantorID, CoverageAmount: coverage, ActorID: "ACTOR_002",
+		})})
+	})
+	if err != nil {
+		return nil, err
+	}
+	var guarantee domain.LoanGuarantee
+	if err := json.Unmarshal(payload, &guarantee); err != nil {
+		t.Fatalf("failed to decode guarantee: %v", err)
+	}
+	return &guarantee, nil
+}
+
+func invokeGuarantee(t *testing.T, stub *shimtest.MockStub, txID, guaranteeID string) (*domain.RecoveryObligation, error) {
+	payload, err := inTx(stub, txID, func() ([]byte, error) {
+		return NewGuaranteeHandler().InvokeGuarantee(stub, []string{mustJSON(t, domain.GuaranteeInvocationRequest{GuaranteeID: guaranteeID, ActorID: "ACTOR_007"})})
+	})
+	if err != nil {
+		return nil, err
+	}
+	var obligation domain.RecoveryObligation
+	if err := json.Unmarshal(payload, &obligation); err != nil {
+		t.Fatalf("failed to decode recovery obligation: %v", err)
+	}
+	return &obligation, nil
+}
+
+func getExposure(t *testing.T, stub *shimtest.MockStub, customerID string) domain.CustomerExposure {
+	t.Helper()
+	payload, err := inTx(stub, "exposure_"+customerID, func() ([]byte, error) {
+		return NewGuaranteeHandler().GetCustomerExposure(stub, []string{customerID})
+	})
+	if err != nil {
+		t.Fatalf("GetCustomerExposure failed: %v", err)
+	}
+	var exposure domain.CustomerExposure
+	if err := json.Unmarshal(payload, &exposure); err != nil {
+		t.Fatalf("failed to decode exposure: %v", err)
+	}
+	return exposure
+}
+
+// seedGuaranteedLoan stores a disbursed 12000 loan with 9000 still outstanding
+func seedGuaranteedLoan(t *testing.T, stub *shimtest.MockStub, loanID string) {
+	t.Helper()
+	seedLoan(t, stub, loanID, validation.LoanStatusDisbursed, approvedTerms(12000, 7), func(loanApp *domain.LoanApplication) {
+		loanApp.DisbursedAmount = utils.NewMoney(12000, "USD")
+		loanApp.OutstandingBalance = utils.NewMoney(9000, "USD")
+	})
+}
+
+func TestAddLoanGuaranteeLimitsCoverage(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleUnderwriter))
+	withCustomers(stub, eligibleCustomer("CUST_002", 10))
+	seedGuaranteedLoan(t, stub, "LOAN_G1")
+
+	// Cover is capped at the approved amount and cannot come from the borrower
+	_, err := addGuarantee(t, stub, "cover_over", "LOAN_G1", "CUST_002", 12000.01)
+	expectErrorCode(t, err, services.ErrCodeInvalidArgument)
+	_, err = addGuarantee(t, stub, "cover_zero", "LOAN_G1", "CUST_002", 0)
+	expectErrorCode(t, err, services.ErrCodeInvalidArgument)
+	if _, err := addGuarantee(t, stub, "cover_self", "LOAN_G1", "CUST_001", 5000); err == nil {
+		t.Errorf("expected the borrower's own guarantee to be refused")
+	}
+	if _, err := addGuarantee(t, stub, "cover_unknown", "LOAN_G1", "CUST_404", 5000); err == nil {
+		t.Errorf("expected a guarantor unknown to the customer chaincode to be refused")
+	}
+
+	guarantee, err := addGuarantee(t, stub, "cover_full", "LOAN_G1", "CUST_002", 12000)
+	if err != nil {
+		t.Fatalf("guarantee of the full approved amount failed: %v", err)
+	}
+	if guarantee.Status != domain.GuaranteeStatusActive || guarantee.CoverageAmount != 12000 || guarantee.BorrowerCustomerID != "CUST_001" {
+		t.Errorf("expected an active 12000 guarantee of CUST_001's loan, got %+v", guarantee)
+	}
+	if exposure := getExposure(t, stub, "CUST_002"); exposure.ContingentLiability != 12000 {
+		t.Errorf("expected the guarantor to carry 12000 contingent liability, got %+v", exposure)
+	}
+	if exposure := getExposure(t, stub, "CUST_001"); exposure.GuaranteedBorrowing != 12000 {
+		t.Errorf("expected the borrower to carry 12000 guaranteed borrowing, got %+v", exposure)
+	}
+
+	// A closed-out loan takes no new cover
+	seedLoan(t, stub, "LOAN_G2", validation.LoanStatusCancelled)
+	_, err = addGuarantee(t, stub, "cover_cancelled", "LOAN_G2", "CUST_002", 1000)
+	expectErrorCode(t, err, services.ErrCodeInvalidTransition)
+}
+
+func TestInvokeGuaranteeReleasesCoverIntoRecoveryObligation(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleUnderwriter))
+	withCustomers(stub, eligibleCustomer("CUST_002", 10), eligibleCustomer("CUST_003", 10))
+	seedGuaranteedLoan(t, stub, "LOAN_G1")
+	first, err := addGuarantee(t, stub, "cover_1", "LOAN_G1", "CUST_002", 5000)
+	if err != nil {
+		t.Fatalf("first guarantee failed: %v", err)
+	}
+	second, err := addGuarantee(t, stub, "cover_2", "LOAN_G1", "CUST_003", 6000)
+	if err != nil {
+		t.Fatalf("second guarantee failed: %v", err)
+	}
+
+	// Guarantees are only called on once the loan has defaulted
+	_, err = invokeGuarantee(t, stub, "invoke_early", first.GuaranteeID)
+	expectErrorCode(t, err, services.ErrCodeAccessDenied)
+
+	defaulted := getLoan(t, stub, "LOAN_G1")
+	defaulted.Status = validation.LoanStatusDefaulted
+	if _, err := inTx(stub, "default", func() ([]byte, error) {
+		return nil, putLoanApplication(stub, services.NewPersistenceService(), defaulted)
+	}); err != nil {
+		t.Fatalf("failed to default loan: %v", err)
+	}
+
+	// The first guarantor is claimed in full; the second only for the 4000 balance left unclaimed
+	obligation, err := invokeGuarantee(t, stub, "invoke_1", first.GuaranteeID)
+	if err != nil {
+		t.Fatalf("invocation failed: %v", err)
+	}
+	if obligation.Amount != 5000 || obligation.OutstandingAmount != 5000 || obligation.Status != domain.RecoveryObligationOpen {
+		t.Errorf("expected an open 5000 obligation, got %+v", obligation)
+	}
+	remainder, err := invokeGuarantee(t, stub, "invoke_2", second.GuaranteeID)
+	if err != nil {
+		t.Fatalf("second invocation failed: %v", err)
+	}
+	if remainder.Amount != 4000 {
+		t.Errorf("expected the second claim limited to the 4000 unclaimed balance, got %.2f", remainder.Amount)
+	}
+	_, err = invokeGuarantee(t, stub, "invoke_again", first.GuaranteeID)
+	expectErrorCode(t, err, services.ErrCodeInvalidTransition)
+
+	// Contingent liability is released into recovery obligations and the borrower loses the cover
+	if exposure := getExposure(t, stub, "CUST_002"); exposure.ContingentLiability != 0 || exposure.RecoveryObligations != 5000 {
+		t.Errorf("expected CUST_002 to owe 5000 with no contingent liability left, got %+v", exposure)
+	}
+	if exposure := getExposure(t, stub, "CUST_003"); exposure.ContingentLiability != 0 || exposure.RecoveryObligations != 4000 {
+		t.Errorf("expected CUST_003 to owe 4000 with no contingent liability left, got %+v", exposure)
+	}
+	if exposure := getExposure(t, stub, "CUST_001"); exposure.GuaranteedBorrowing != 0 {
+		t.Errorf("expected the borrower's guaranteed borrowing released, got %+v", exposure)
+	}
+
+	pay := func(txID string, amount float64) error {
+		_, err := inTx(stub, txID, func() ([]byte, error) {
+			return NewGuaranteeHandler().RecordGuarantorPayment(stub, []string{mustJSON(t, domain.GuarantorPaymentRequest{
+				ObligationID: obligation.ObligationID, Amount: amount, ActorID: "ACTOR_007",
+			})})
+		})
+		return err
+	}
+	expectErrorCode(t, pay("pay_over", 5000.01), services.ErrCodeInvalidArgument)
+	if err := pay("pay_part", 2000); err != nil {
+		t.Fatalf("part payment failed: %v", err)
+	}
+	if err := pay("pay_rest", 3000); err != nil {
+		t.Fatalf("final payment failed: %v", err)
+	}
+	if err := pay("pay_settled", 1); err == nil {
+		t.Errorf("expected a payment against a settled obligation to be refused")
+	}
+
+	if balance := getLoan(t, stub, "LOAN_G1").OutstandingBalance.String(); balance != "4000" {
+		t.Errorf("expected guarantor payments to bring the loan down to 4000, got %s", balance)
+	}
+	if exposure := getExposure(t, stub, "CUST_002"); exposure.RecoveryObligations != 0 {
+		t.Errorf("expected CUST_002's obligation paid off, got %+v", exposure)
+	}
+	if exposure := getExposure(t, stub, "CUST_001"); exposure.RecoveredFromGuarantors != 5000 {
+		t.Errorf("expected 5000 recovered from guarantors on the borrower's record, got %+v", exposure)
+	}
+}
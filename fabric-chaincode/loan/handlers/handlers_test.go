@@ -0,0 +1,279 @@
+package handlers
+
+import (
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/asn1"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/golang/protobuf/proto"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/hyperledger/fabric-protos-go/msp"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"google.golang.org/protobuf/types/known/timestamppb"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/interfaces"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// attrsExtensionOID is the certificate extension Fabric CA stores identity attributes under
+var attrsExtensionOID = asn1.ObjectIdentifier{1, 2, 3, 4, 5, 6, 7, 8, 1}
+
+// newTestIdentity returns a serialized identity of the bank's organization whose certificate
+// carries the given role
+func newTestIdentity(t *testing.T, role string) []byte {
+	t.Helper()
+	return newMSPIdentity(t, config.BankMSPID, role)
+}
+
+// newMSPIdentity returns a serialized identity of an organization whose certificate carries the
+// given role
+func newMSPIdentity(t *testing.T, mspID, role string) []byte {
+	t.Helper()
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	if err != nil {
+		t.Fatalf("failed to generate key: %v", err)
+	}
+	attrs, _ := json.Marshal(map[string]map[string]string{"attrs": {"role": role}})
+
+	template := &x509.Certificate{
+		SerialNumber:    big.NewInt(time.Now().UnixNano()),
+		Subject:         pkix.Name{CommonName: "tester"},
+		NotBefore:       time.Now().Add(-time.Hour),
+		NotAfter:        time.Now().Add(time.Hour),
+		ExtraExtensions: []pkix.Extension{{Id: attrsExtensionOID, Value: attrs}},
+	}
+	certDER, err := x509.CreateCertificate(rand.Reader, template, template, &key.PublicKey, key)
+	if err != nil {
+		t.Fatalf("failed to create certificate: %v", err)
+	}
+
+	identity, err := proto.Marshal(&msp.SerializedIdentity{
+		Mspid:   mspID,
+		IdBytes: pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: certDER}),
+	})
+	if err != nil {
+		t.Fatalf("failed to marshal identity: %v", err)
+	}
+	return identity
+}
+
+// newLoanStub returns a stub invoked by an identity with the given role. Handlers are called
+// directly, so tests wrap each call in a transaction with inTx.
+func newLoanStub(t *testing.T, role string) *shimtest.MockStub {
+	stub := shimtest.NewMockStub("loan", nil)
+	stub.Creator = newTestIdentity(t, role)
+	return stub
+}
+
+// inTx runs a handler call as its own transaction
+func inTx(stub *shimtest.MockStub, txID string, call func() ([]byte, error)) ([]byte, error) {
+	stub.MockTransactionStart(txID)
+	defer stub.MockTransactionEnd(txID)
+	return call()
+}
+
+// inTxAt runs a handler call as its own transaction timestamped at the given time
+func inTxAt(stub *shimtest.MockStub, txID string, at time.Time, call func() ([]byte, error)) ([]byte, error) {
+	stub.MockTransactionStart(txID)
+	defer stub.MockTransactionEnd(txID)
+	stub.TxTimestamp = timestamppb.New(at)
+	return call()
+}
+
+// seedLoan stores a USD loan application in the given status, adjusted by any configure funcs
+func seedLoan(t *testing.T, stub *shimtest.MockStub, loanID string, status validation.LoanApplicationStatus, configure ...func(*domain.LoanApplication)) *domain.LoanApplication {
+	t.Helper()
+	loanApp := &domain.LoanApplication{
+		LoanID:          loanID,
+		CustomerID:      "CUST_001",
+		LoanType:        "PERSONAL",
+		Currency:        "USD",
+		RequestedAmount: utils.NewMoney(12000, "USD"),
+		TermMonths:      12,
+		Purpose:         "Home improvement",
+		Status:          status,
+		ApplicationDate: time.Date(2026, 1, 5, 0, 0, 0, 0, time.UTC),
+		CreatedBy:       "ACTOR_001",
+		LastUpdatedBy:   "ACTOR_001",
+	}
+	for _, apply := range configure {
+		apply(loanApp)
+	}
+	zero := utils.ZeroMoney(loanApp.Currency)
+	for _, amount := range []*utils.Money{&loanApp.DisbursedAmount, &loanApp.OutstandingBalance, &loanApp.AccruedInterest, &loanApp.TaxWithheld} {
+		if amount.IsZero() {
+			*amount = zero
+		}
+	}
+	_, err := inTx(stub, "seed_"+loanID, func() ([]byte, error) {
+		return nil, putLoanApplication(stub, services.NewPersistenceService(), loanApp)
+	})
+	if err != nil {
+		t.Fatalf("failed to seed loan: %v", err)
+	}
+	return loanApp
+}
+
+// approvedTerms gives a seeded loan an approved amount and fixed rate
+func approvedTerms(amount, rate float64) func(*domain.LoanApplication) {
+	return func(loanApp *domain.LoanApplication) {
+		approved := utils.NewMoney(amount, loanApp.Currency)
+		loanApp.ApprovedAmount = &approved
+		loanApp.InterestRate = &rate
+	}
+}
+
+// getLoan loads a stored loan application
+func getLoan(t *testing.T, stub *shimtest.MockStub, loanID string) *domain.LoanApplication {
+	t.Helper()
+	var loanApp domain.LoanApplication
+	if err := services.NewPersistenceService().Get(stub, "LOAN_"+loanID, &loanApp); err != nil {
+		t.Fatalf("failed to load loan %s: %v", loanID, err)
+	}
+	return &loanApp
+}
+
+// mustJSON marshals a request argument
+func mustJSON(t *testing.T, v interface{}) string {
+	t.Helper()
+	data, err := json.Marshal(v)
+	if err != nil {
+		t.Fatalf("failed to marshal request: %v", err)
+	}
+	return string(data)
+}
+
+// expectVersionConflict fails unless err reports the entity at currentVersion
+func expectVersionConflict(t *testing.T, err error, currentVersion int) {
+	t.Helper()
+	var conflict *services.VersionConflictError
+	if !errors.As(err, &conflict) {
+		t.Fatalf("expected a version conflict, got %v", err)
+	}
+	if conflict.Code != services.ErrCodeConflict || conflict.CurrentVersion != currentVersion {
+		t.Errorf("expected CONFLICT at version %d, got %s at version %d", currentVersion, conflict.Code, conflict.CurrentVersion)
+	}
+}
+
+func intPtr(v int) *int {
+	return &v
+}
+
+// expectErrorCode fails unless err reaches clients with the given error code
+func expectErrorCode(t *testing.T, err error, code string) {
+	t.Helper()
+	if err == nil {
+		t.Fatalf("expected a %s error, got none", code)
+	}
+	if got := services.ClassifyError(err).Code; got != code {
+		t.Errorf("expected a %s error, got %s: %v", code, got, err)
+	}
+}
+
+// fakeCustomerChaincode answers the customer chaincode's compliance status and consent lookups
+// from the statuses and consent records it holds
+type fakeCustomerChaincode struct {
+	statuses map[string]interfaces.CustomerComplianceStatus
+	consents map[string]interfaces.ConsentGrant // Keyed by customer ID and purpose
+}
+
+func (f *fakeCustomerChaincode) Init(stub shim.ChaincodeStubInterface) peer.Response {
+	return shim.Success(nil)
+}
+
+func (f *fakeCustomerChaincode) Invoke(stub shim.ChaincodeStubInterface) peer.Response {
+	function, args := stub.GetFunctionAndParameters()
+	switch function {
+	case "GetCustomerComplianceStatus":
+		status, ok := f.statuses[args[0]]
+		if !ok {
+			return shim.Error("customer not found: " + args[0])
+		}
+		statusBytes, _ := json.Marshal(status)
+		return shim.Success(statusBytes)
+	case "GetConsentAt":
+		asOf, err := time.Parse(time.RFC3339, args[2])
+		if err != nil {
+			return shim.Error("invalid asOf " + args[2])
+		}
+		status := interfaces.ConsentStatus{CustomerID: args[0], Purpose: args[1], AsOf: asOf}
+		if grant, ok := f.consents[args[0]+"/"+args[1]]; ok && !grant.EffectiveDate.After(asOf) {
+			status.Record = &grant
+			status.Granted = grant.Granted && (grant.ExpiryDate == nil || grant.ExpiryDate.After(asOf))
+		}
+		statusBytes, _ := json.Marshal(status)
+		return shim.Success(statusBytes)
+	}
+	return shim.Error("unknown function " + function)
+}
+
+// withConsent records a customer's consent to a purpose, in force from the grant's effective date
+func (f *fakeCustomerChaincode) withConsent(customerID, purpose string, grant interfaces.ConsentGrant) {
+	if f.consents == nil {
+		f.consents = map[string]interfaces.ConsentGrant{}
+	}
+	f.consents[customerID+"/"+purpose] = grant
+}
+
+// withCustomers serves the given customers' compliance statuses to the loan handlers
+func withCustomers(stub *shimtest.MockStub, statuses ...interfaces.CustomerComplianceStatus) *fakeCustomerChaincode {
+	customers := &fakeCustomerChaincode{statuses: map[string]interfaces.CustomerComplianceStatus{}}
+	for _, status := range statuses {
+		customers.statuses[status.CustomerID] = status
+	}
+	stub.MockPeerChaincode(config.CustomerChaincodeName, shimtest.NewMockStub(config.CustomerChaincodeName, customers), "")
+	return customers
+}
+
+// fakeComplianceChaincode clears every channel report and text screening it is sent and acknowledges default reports
+type fakeComplianceChaincode struct{}
+
+func (f *fakeComplianceChaincode) Init(stub shim.ChaincodeStubInterface) peer.Response {
+	return shim.Success(nil)
+}
+
+func (f *fakeComplianceChaincode) Invoke(stub shim.ChaincodeStubInterface) peer.Response {
+	function, _ := stub.GetFunctionAndParameters()
+	switch function {
+	case "MonitorApplicationChannel":
+		resultBytes, _ := json.Marshal(interfaces.ApplicationChannelResult{})
+		return shim.Success(resultBytes)
+	case "ScreenText":
+		resultBytes, _ := json.Marshal(interfaces.TextScreeningResult{})
+		return shim.Success(resultBytes)
+	case "RecordLoanDefault":
+		resultBytes, _ := json.Marshal(interfaces.LoanDefaultReportResult{EventID: "EVENT_DEFAULT_" + stub.GetTxID()})
+		return shim.Success(resultBytes)
+	}
+	return shim.Error("unknown function " + function)
+}
+
+// withCompliance lets the loan handlers report submissions to the compliance chaincode
+func withCompliance(stub *shimtest.MockStub) {
+	stub.MockPeerChaincode(config.ComplianceChaincodeName, shimtest.NewMockStub(config.ComplianceChaincodeName, &fakeComplianceChaincode{}), "")
+}
+
+// eligibleCustomer is an active, verified customer with credit check consent and the given AML risk score
+func eligibleCustomer(customerID string, riskScore float64) interfaces.CustomerComplianceStatus {
+	return interfaces.CustomerComplianceStatus{
+		CustomerID:         customerID,
+		Status:             string(validation.CustomerStatusActive),
+		KYCStatus:          string(validation.KYCStatusVerified),
+		AMLStatus:          string(validation.AMLStatusClear),
+		AMLRiskScore:       riskScore,
+		ConsentPreferences: `{"` + config.ConsentCreditCheck + `": true}`,
+	}
+}
@@ -0,0 +1,243 @@
+package handlers
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	loanServices "github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// Sanity bounds for published index rates (percent)
+const (
+	minIndexRate = -5.0
+	maxIndexRate = 50.0
+)
+
+// IndexRateHandler handles rate oracle registration and index rate publication
+type IndexRateHandler struct {
+	persistenceService *services.PersistenceService
+	eventService      *loanServices.EventService
+	indexRateService  *loanServices.IndexRateService
+}
+
+// NewIndexRateHandler creates a new index rate handler
+func NewIndexRateHandler() *IndexRateHandler {
+	return &IndexRateHandler{
+		persistenceService: services.NewPersistenceService(),
+		eventService:      loanServices.NewEventService(),
+		indexRateService:  loanServices.NewIndexRateService(),
+	}
+}
+
+// RegisterRateOracle registers a market-data oracle and the indexes it may publish
+func (h *IndexRateHandler) RegisterRateOracle(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.RateOracleRegistrationRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse rate oracle registration request: %v", err)
+	}
+
+	if strings.TrimSpace(req.OracleID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "oracleID", "oracleID is required")
+	}
+	if len(req.Indexes) == 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "at least one index is required")
+	}
+	if _, err := decodeOraclePublicKey(req.PublicKey); err != nil {
+		return nil, err
+	}
+
+	oracleKey := fmt.Sprintf("RATE_ORACLE_%s", req.OracleID)
+	exists, err := h.persistenceService.Exists(stub, oracleKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check rate oracle: %w", err)
+	}
+	if exists {
+		return nil, services.NewChaincodeError(services.ErrCodeConflict, "", "rate oracle %s already registered", req.OracleID)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	oracle := &domain.RateOracle{
+		OracleID:    req.OracleID,
+		Name:        req.Name,
+		PublicKey:   req.PublicKey,
+		Indexes:     req.Indexes,
+		IsActive:    true,
+		CreatedDate: now,
+		CreatedBy:   req.ActorID,
+	}
+
+	if err := h.persistenceService.Put(stub, oracleKey, oracle); err != nil {
+		return nil, fmt.Errorf("failed to store rate oracle: %w", err)
+	}
+
+	return json.Marshal(oracle)
+}
+
+// PublishIndexRate records a signed index rate fixing from a registered oracle
+func (h *IndexRateHandler) PublishIndexRate(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.IndexRatePublishRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse index rate publish request: %v", err)
+	}
+
+	// Authenticate the oracle
+	var oracle domain.RateOracle
+	if err := h.persistenceService.Get(stub, fmt.Sprintf("RATE_ORACLE_%s", req.OracleID), &oracle); err != nil {
+		return nil, fmt.Errorf("rate oracle not found: %w", err)
+	}
+	if !oracle.IsActive {
+		return nil, fmt.Errorf("rate oracle %s is not active", req.OracleID)
+	}
+	if !containsString(oracle.Indexes, req.IndexName) {
+		return nil, services.NewChaincodeError(services.ErrCodeAccessDenied, "", "rate oracle %s is not authorised to publish %s", req.OracleID, req.IndexName)
+	}
+
+	publicKey, err := decodeOraclePublicKey(oracle.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := base64.StdEncoding.DecodeString(req.SourceSignature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid source signature encoding: %w", err)
+	}
+	if !ed25519.Verify(publicKey, domain.IndexRateSigningPayload(req.IndexName, req.Rate, req.EffectiveDate), signature) {
+		return nil, fmt.Errorf("source signature verification failed for %s", req.IndexName)
+	}
+
+	// Validate the fixing
+	if req.Rate < minIndexRate || req.Rate > maxIndexRate {
+		return nil, fmt.Errorf("index rate %.4f outside allowed range %.2f to %.2f", req.Rate, minIndexRate, maxIndexRate)
+	}
+	effectiveDate, err := time.Parse(loanServices.IndexRateDateFormat, req.EffectiveDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid effective date %s: %w", req.EffectiveDate, err)
+	}
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	if effectiveDate.After(now) {
+		return nil, fmt.Errorf("effective date %s is in the future", req.EffectiveDate)
+	}
+
+	rate := &domain.IndexRate{
+		IndexName:       req.IndexName,
+		Rate:            req.Rate,
+		EffectiveDate:   req.EffectiveDate,
+		OracleID:        req.OracleID,
+		SourceSignature: req.SourceSignature,
+		PublishedDate:   now,
+		TransactionID:   stub.GetTxID(),
+	}
+
+	// Store in rate history
+	historyKey, err := stub.CreateCompositeKey("INDEX_RATE", []string{req.IndexName, req.EffectiveDate, rate.TransactionID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := h.persistenceService.Put(stub, historyKey, rate); err != nil {
+		return nil, fmt.Errorf("failed to store index rate: %w", err)
+	}
+
+	// Advance the latest fixing unless this is a back-dated publication
+	latestKey := fmt.Sprintf("INDEX_RATE_LATEST_%s", req.IndexName)
+	var latest domain.IndexRate
+	if err := h.persistenceService.Get(stub, latestKey, &latest); err != nil || latest.EffectiveDate <= req.EffectiveDate {
+		if err := h.persistenceService.Put(stub, latestKey, rate); err != nil {
+			return nil, fmt.Errorf("failed to update latest index rate: %w", err)
+		}
+	}
+
+	// Emit event
+	if err := h.eventService.EmitIndexRatePublished(stub, rate); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(rate)
+}
+
+// GetIndexRate retrieves the latest fixing for an index with its staleness flag
+func (h *IndexRateHandler) GetIndexRate(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	rate, err := h.indexRateService.GetLatestRate(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(rate)
+}
+
+// GetIndexRateHistory retrieves all published fixings for an index
+func (h *IndexRateHandler) GetIndexRateHistory(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	iterator, err := stub.GetStateByPartialCompositeKey("INDEX_RATE", []string{args[0]})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get index rate history: %w", err)
+	}
+	defer iterator.Close()
+
+	rates := []domain.IndexRate{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate index rates: %w", err)
+		}
+
+		var rate domain.IndexRate
+		if err := json.Unmarshal(response.Value, &rate); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal index rate: %w", err)
+		}
+
+		rates = append(rates, rate)
+	}
+
+	return json.Marshal(rates)
+}
+
+// Helper methods
+
+func decodeOraclePublicKey(encoded string) (ed25519.PublicKey, error) {
+	key, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return nil, fmt.Errorf("invalid oracle public key encoding: %w", err)
+	}
+	if len(key) != ed25519.PublicKeySize {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "oracle public key must be %d bytes, got %d", ed25519.PublicKeySize, len(key))
+	}
+	return ed25519.PublicKey(key), nil
+}
+
+func containsString(values []string, target string) bool {
+	for _, value := range values {
+		if value == target {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,157 @@
+package handlers
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// oracleKey is a fixed signing key so publications are reproducible
+var oracleKey = ed25519.NewKeyFromSeed([]byte("market-data-oracle-test-seed-032"))
+
+// fixingTime is the morning of 2 March 2026, after that day's fixings are published
+var fixingTime = time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+func registerOracle(t *testing.T, stub *shimtest.MockStub, indexes ...string) {
+	t.Helper()
+	if _, err := inTxAt(stub, "register_oracle", fixingTime, func() ([]byte, error) {
+		return NewIndexRateHandler().RegisterRateOracle(stub, []string{mustJSON(t, domain.RateOracleRegistrationRequest{
+			OracleID: "ORACLE_1", Name: "Market data feed", PublicKey: base64.StdEncoding.EncodeToString(oracleKey.Public().(ed25519.PublicKey)), Indexes: indexes, ActorID: "ACTOR_005",
+		})})
+	}); err != nil {
+		t.Fatalf("oracle registration failed: %v", err)
+	}
+}
+
+// publishRate publishes a fixing signed with the given key at the given time
+func publishRate(t *testing.T, stub *shimtest.MockStub, txID string, at time.Time, key ed25519.PrivateKey, indexName string, rate float64, effectiveDate string) error {
+	signature := ed25519.Sign(key, domain.IndexRateSigningPayload(indexName, rate, effectiveDate))
+	_, err := inTxAt(stub, txID, at, func() ([]byte, error) {
+		return NewIndexRateHandler().PublishIndexRate(stub, []string{mustJSON(t, domain.IndexRatePublishRequest{
+			OracleID: "ORACLE_1", IndexName: indexName, Rate: rate, EffectiveDate: effectiveDate, SourceSignature: base64.StdEncoding.EncodeToString(signature),
+		})})
+	})
+	return err
+}
+
+func latestRate(t *testing.T, stub *shimtest.MockStub, at time.Time, indexName string) *domain.IndexRate {
+	t.Helper()
+	payload, err := inTxAt(stub, "latest_"+indexName, at, func() ([]byte, error) {
+		return NewIndexRateHandler().GetIndexRate(stub, []string{indexName})
+	})
+	if err != nil {
+		t.Fatalf("GetIndexRate failed: %v", err)
+	}
+	var rate domain.IndexRate
+	if err := json.Unmarshal(payload, &rate); err != nil {
+		t.Fatalf("failed to decode index rate: %v", err)
+	}
+	return &rate
+}
+
+func TestPublishIndexRateAuthenticatesOracle(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	registerOracle(t, stub, "SOFR")
+
+	// Only signed fixings of the oracle's own indexes, dated no later than today, are accepted
+	forged := ed25519.NewKeyFromSeed([]byte("someone-else-entirely-test-seed!"))
+	if err := publishRate(t, stub, "forged", fixingTime, forged, "SOFR", 5.3, "2026-03-02"); err == nil || !strings.Contains(err.Error(), "signature verification failed") {
+		t.Errorf("expected a forged signature to be refused, got %v", err)
+	}
+	expectErrorCode(t, publishRate(t, stub, "other_index", fixingTime, oracleKey, "EURIBOR", 3.1, "2026-03-02"), services.ErrCodeAccessDenied)
+	if err := publishRate(t, stub, "future", fixingTime, oracleKey, "SOFR", 5.3, "2026-03-03"); err == nil {
+		t.Errorf("expected a fixing effective tomorrow to be refused")
+	}
+	if err := publishRate(t, stub, "out_of_range", fixingTime, oracleKey, "SOFR", 50.5, "2026-03-02"); err == nil {
+		t.Errorf("expected a fixing above the allowed range to be refused")
+	}
+
+	// A back-dated fixing joins the history without replacing the latest
+	if err := publishRate(t, stub, "fix_0302", fixingTime, oracleKey, "SOFR", 5.3, "2026-03-02"); err != nil {
+		t.Fatalf("publication failed: %v", err)
+	}
+	if err := publishRate(t, stub, "fix_0227", fixingTime, oracleKey, "SOFR", 5.25, "2026-02-27"); err != nil {
+		t.Fatalf("back-dated publication failed: %v", err)
+	}
+	if rate := latestRate(t, stub, fixingTime, "SOFR"); rate.Rate != 5.3 || rate.EffectiveDate != "2026-03-02" || rate.IsStale {
+		t.Errorf("expected the 2 March fixing of 5.3 to stay latest, got %+v", rate)
+	}
+
+	payload, err := inTxAt(stub, "history", fixingTime, func() ([]byte, error) {
+		return NewIndexRateHandler().GetIndexRateHistory(stub, []string{"SOFR"})
+	})
+	if err != nil {
+		t.Fatalf("GetIndexRateHistory failed: %v", err)
+	}
+	var history []domain.IndexRate
+	if err := json.Unmarshal(payload, &history); err != nil {
+		t.Fatalf("failed to decode history: %v", err)
+	}
+	if len(history) != 2 || history[0].EffectiveDate != "2026-02-27" || history[1].EffectiveDate != "2026-03-02" {
+		t.Errorf("expected both fixings in effective date order, got %+v", history)
+	}
+}
+
+func TestRepriceLoanFollowsIndexAndRefusesStaleFixing(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	registerOracle(t, stub, "SOFR")
+	if err := publishRate(t, stub, "fix_0302", fixingTime, oracleKey, "SOFR", 5.3, "2026-03-02"); err != nil {
+		t.Fatalf("publication failed: %v", err)
+	}
+	seedLoan(t, stub, "LOAN_IX1", validation.LoanStatusDisbursed, approvedTerms(12000, 7.1), func(loanApp *domain.LoanApplication) {
+		margin := 2.0
+		loanApp.RateIndex = "SOFR"
+		loanApp.RateMargin = &margin
+		loanApp.IndexRateDate = "2026-02-02"
+	})
+
+	reprice := func(txID string, at time.Time) (*domain.LoanApplication, error) {
+		payload, err := inTxAt(stub, txID, at, func() ([]byte, error) {
+			return NewLoanApplicationHandler().RepriceLoan(stub, []string{mustJSON(t, domain.LoanRepriceRequest{LoanID: "LOAN_IX1", ActorID: "ACTOR_005"})})
+		})
+		if err != nil {
+			return nil, err
+		}
+		var loanApp domain.LoanApplication
+		if err := json.Unmarshal(payload, &loanApp); err != nil {
+			t.Fatalf("failed to decode loan: %v", err)
+		}
+		return &loanApp, nil
+	}
+
+	// The loan moves to the new fixing plus its margin, once
+	loanApp, err := reprice("reprice", fixingTime)
+	if err != nil {
+		t.Fatalf("repricing failed: %v", err)
+	}
+	if *loanApp.InterestRate != 7.3 || loanApp.IndexRateDate != "2026-03-02" {
+		t.Errorf("expected 7.3 off the 2 March fixing, got %v off %s", *loanApp.InterestRate, loanApp.IndexRateDate)
+	}
+	again, err := reprice("reprice_again", fixingTime)
+	if err != nil || again.Version != loanApp.Version {
+		t.Errorf("expected repricing off the same fixing to leave the loan at version %d, got %+v (%v)", loanApp.Version, again, err)
+	}
+
+	// Five days without a new fixing is past the maximum age; the rate is flagged and not priced from
+	staleTime := fixingTime.AddDate(0, 0, 5)
+	if rate := latestRate(t, stub, staleTime, "SOFR"); !rate.IsStale {
+		t.Errorf("expected the 2 March fixing stale on 7 March")
+	}
+	if _, err := reprice("reprice_stale", staleTime); err == nil || !strings.Contains(err.Error(), "stale") {
+		t.Errorf("expected repricing off a stale fixing to be refused, got %v", err)
+	}
+	if err := publishRate(t, stub, "fix_0306", staleTime, oracleKey, "SOFR", 5.1, "2026-03-06"); err != nil {
+		t.Fatalf("publication failed: %v", err)
+	}
+	if loanApp, err := reprice("reprice_fresh", staleTime); err != nil || *loanApp.InterestRate != 7.1 {
+		t.Errorf("expected 7.1 off the fresh fixing, got %v", err)
+	}
+}
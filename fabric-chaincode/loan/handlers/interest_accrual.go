@@ -0,0 +1,322 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	loanServices "github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// InterestAccrualHandler computes accrued interest and payoff amounts from the on-chain schedule,
+// so servicing systems read them from the ledger rather than replicating the calculation
+type InterestAccrualHandler struct {
+	persistenceService *services.PersistenceService
+	eventService       *loanServices.EventService
+	scheduleService    *loanServices.ScheduleService
+}
+
+// NewInterestAccrualHandler creates a new interest accrual handler
+func NewInterestAccrualHandler() *InterestAccrualHandler {
+	return &InterestAccrualHandler{
+		persistenceService: services.NewPersistenceService(),
+		eventService:       loanServices.NewEventService(),
+		scheduleService:    loanServices.NewScheduleService(),
+	}
+}
+
+// GetPayoffQuote returns the amount that settles a funded loan in full on a date: the principal
+// outstanding on the schedule, interest due and accrued to that date, and fees posted against the
+// loan. The date defaults to today and may not be in the past, since the quote is built from the
+// schedule as it stands now.
+// Args: loanID, asOfDate (YYYY-MM-DD or empty)
+func (h *InterestAccrualHandler) GetPayoffQuote(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 2, got %d", len(args))
+	}
+
+	var loanApp domain.LoanApplication
+	if err := h.persistenceService.Get(stub, fmt.Sprintf("LOAN_%s", args[0]), &loanApp); err != nil {
+		return nil, fmt.Errorf("loan application not found: %w", err)
+	}
+	if !isAccruingLoan(&loanApp) {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "payoff quotes are not available for loan in status: %s", loanApp.Status)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	today := now.UTC().Format(balanceSnapshotDateFormat)
+	asOfDate := args[1]
+	if asOfDate == "" {
+		asOfDate = today
+	}
+	asOf, err := time.Parse(balanceSnapshotDateFormat, asOfDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid asOfDate, expected YYYY-MM-DD: %w", err)
+	}
+	if asOfDate < today {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "asOfDate", "asOfDate %s is in the past", asOfDate)
+	}
+
+	position, err := h.interestPosition(stub, &loanApp, asOf)
+	if err != nil {
+		return nil, err
+	}
+	fees, err := loanFeesPosted(stub, &loanApp)
+	if err != nil {
+		return nil, err
+	}
+	payoff, err := sumMoney(utils.NewMoney(position.PrincipalOutstanding, loanApp.Currency), utils.NewMoney(position.AccruedInterest, loanApp.Currency), fees)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute payoff for loan %s: %w", loanApp.LoanID, err)
+	}
+
+	quote := &domain.PayoffQuote{
+		LoanID:          loanApp.LoanID,
+		AsOfDate:        asOfDate,
+		Status:          string(loanApp.Status),
+		Position:        *position,
+		FeesOutstanding: fees.Float64(),
+		PayoffAmount:    payoff.Float64(),
+		QuotedDate:      now,
+	}
+	return json.Marshal(quote)
+}
+
+// AccrueInterest runs the next batch of the daily interest accrual for a date. It is triggered by an
+// external scheduler once the day has started: each call reads one capped batch of loans in key
+// order, records the interest each funded loan has accrued through the date, sets it on the loan
+// and checkpoints its progress. A loan already accrued through a later date is left as it is.
+func (h *InterestAccrualHandler) AccrueInterest(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.InterestAccrualRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse interest accrual request: %v", err)
+	}
+	if req.ActorID == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+	accrualDate, err := time.Parse(balanceSnapshotDateFormat, req.AccrualDate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid accrualDate, expected YYYY-MM-DD: %w", err)
+	}
+	if err := checkAccrualRole(stub); err != nil {
+		return nil, err
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	if accrualDate.After(now) {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "accrualDate", "interest cannot be accrued through %s before the day has started", req.AccrualDate)
+	}
+
+	batchSize := req.BatchSize
+	if batchSize <= 0 || batchSize > config.MaxAccrualBatchSize {
+		batchSize = config.MaxAccrualBatchSize
+	}
+
+	runKey := fmt.Sprintf("INTEREST_ACCRUAL_RUN_%s", req.AccrualDate)
+	run := &domain.InterestAccrualRun{}
+	if err := h.persistenceService.Get(stub, runKey, run); err != nil {
+		run = &domain.InterestAccrualRun{
+			AccrualDate: req.AccrualDate,
+			Status:      domain.InterestAccrualInProgress,
+			StartedBy:   req.ActorID,
+			StartedDate: now,
+		}
+	}
+	if run.Status == domain.InterestAccrualCompleted {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "interest accrual for %s is already complete", req.AccrualDate)
+	}
+
+	// Loan keys never change, so resuming after the checkpoint neither skips nor repeats a loan
+	startKey := loanKeyPrefix
+	if run.Checkpoint != "" {
+		startKey = run.Checkpoint + "\x00"
+	}
+	iterator, err := stub.GetStateByRange(startKey, loanKeyPrefix+string(utf8.MaxRune))
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loans: %w", err)
+	}
+	defer iterator.Close()
+
+	read := 0
+	for read < batchSize && iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate loans: %w", err)
+		}
+		read++
+		run.Checkpoint = response.Key
+
+		var loanApp domain.LoanApplication
+		if err := json.Unmarshal(response.Value, &loanApp); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal loan %s: %w", response.Key, err)
+		}
+		if err := h.accrueLoan(stub, run, &loanApp, accrualDate, req.ActorID, now); err != nil {
+			return nil, err
+		}
+	}
+	run.LoansScanned += read
+	run.Batches++
+
+	if !iterator.HasNext() {
+		run.Status = domain.InterestAccrualCompleted
+		run.Checkpoint = ""
+		run.CompletedDate = &now
+	}
+
+	if err := h.persistenceService.Put(stub, runKey, run); err != nil {
+		return nil, fmt.Errorf("failed to store interest accrual run: %w", err)
+	}
+
+	// Emit event
+	if run.Status == domain.InterestAccrualCompleted {
+		if err := h.eventService.EmitInterestAccrualCompleted(stub, run, req.ActorID); err != nil {
+			return nil, fmt.Errorf("failed to emit event: %w", err)
+		}
+	}
+
+	return json.Marshal(run)
+}
+
+// GetInterestAccrualRun retrieves the accrual run record for a date, completed or in progress
+func (h *InterestAccrualHandler) GetInterestAccrualRun(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var run domain.InterestAccrualRun
+	if err := h.persistenceService.Get(stub, fmt.Sprintf("INTEREST_ACCRUAL_RUN_%s", args[0]), &run); err != nil {
+		return nil, fmt.Errorf("interest accrual run not found: %w", err)
+	}
+
+	return json.Marshal(&run)
+}
+
+// Helper methods
+
+// accrueLoan records the interest a loan has accrued through the accrual date and sets it on the
+// loan. Sandbox loans, loans not funded and loans already accrued through a later date are skipped.
+func (h *InterestAccrualHandler) accrueLoan(stub shim.ChaincodeStubInterface, run *domain.InterestAccrualRun, loanApp *domain.LoanApplication, accrualDate time.Time, actorID string, now time.Time) error {
+	if loanApp.Sandbox || !isAccruingLoan(loanApp) || loanApp.InterestAccruedThrough > run.AccrualDate {
+		return nil
+	}
+
+	position, err := h.interestPosition(stub, loanApp, accrualDate)
+	if err != nil {
+		return err
+	}
+	accrual := &domain.InterestAccrual{
+		AccrualDate:     run.AccrualDate,
+		LoanID:          loanApp.LoanID,
+		Position:        *position,
+		PreviousAccrued: loanApp.AccruedInterest.Float64(),
+		RecordedBy:      actorID,
+		RecordedDate:    now,
+	}
+	accrualKey, err := stub.CreateCompositeKey("INTEREST_ACCRUAL", []string{loanApp.LoanID, run.AccrualDate})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := h.persistenceService.Put(stub, accrualKey, accrual); err != nil {
+		return fmt.Errorf("failed to store interest accrual: %w", err)
+	}
+
+	loanApp.AccruedInterest = utils.NewMoney(position.AccruedInterest, loanApp.Currency)
+	loanApp.InterestAccruedThrough = run.AccrualDate
+	loanApp.LastUpdated = now
+	loanApp.LastUpdatedBy = actorID
+	if err := putLoanApplication(stub, h.persistenceService, loanApp); err != nil {
+		return fmt.Errorf("failed to update loan application: %w", err)
+	}
+
+	run.LoansAccrued++
+	run.TotalAccruedInterest = roundToCents(run.TotalAccruedInterest + position.AccruedInterest)
+	return nil
+}
+
+// interestPosition computes the loan's position on a date from its stored schedule. The schedule
+// runs from the approval date.
+func (h *InterestAccrualHandler) interestPosition(stub shim.ChaincodeStubInterface, loanApp *domain.LoanApplication, asOf time.Time) (*domain.InterestPosition, error) {
+	installments, err := h.scheduleService.GetSchedule(stub, loanApp.LoanID)
+	if err != nil {
+		return nil, err
+	}
+	if len(installments) == 0 {
+		return nil, fmt.Errorf("loan %s has no repayment schedule", loanApp.LoanID)
+	}
+
+	scheduleStart := installments[0].DueDate.AddDate(0, -1, 0)
+	if loanApp.DecisionDate != nil {
+		scheduleStart = *loanApp.DecisionDate
+	}
+	position, err := loanServices.ComputeInterestPosition(installments, scheduleStart, asOf)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute interest position: %w", err)
+	}
+	return &position, nil
+}
+
+// isAccruingLoan reports whether a loan is funded and so accrues interest
+func isAccruingLoan(loanApp *domain.LoanApplication) bool {
+	switch loanApp.Status {
+	case validation.LoanStatusDisbursed, validation.LoanStatusDelinquent, validation.LoanStatusDefaulted:
+		return true
+	}
+	return false
+}
+
+// loanFeesPosted totals the fees posted against a loan. Repayments settle installments only, so
+// every fee posted remains outstanding until payoff.
+func loanFeesPosted(stub shim.ChaincodeStubInterface, loanApp *domain.LoanApplication) (utils.Money, error) {
+	iterator, err := stub.GetStateByPartialCompositeKey("LOAN_TRANSACTION", []string{loanApp.LoanID})
+	if err != nil {
+		return utils.Money{}, fmt.Errorf("failed to get loan transactions: %w", err)
+	}
+	defer iterator.Close()
+
+	fees := utils.ZeroMoney(loanApp.Currency)
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return utils.Money{}, fmt.Errorf("failed to iterate loan transactions: %w", err)
+		}
+		var txn domain.LoanTransaction
+		if err := json.Unmarshal(response.Value, &txn); err != nil {
+			return utils.Money{}, fmt.Errorf("failed to unmarshal loan transaction: %w", err)
+		}
+		if txn.TransactionType == domain.LoanTransactionFee {
+			if fees, err = fees.Add(txn.Amount); err != nil {
+				return utils.Money{}, fmt.Errorf("transaction %s: %w", txn.TransactionID, err)
+			}
+		}
+	}
+	return fees, nil
+}
+
+// checkAccrualRole checks the invoker may run the daily interest accrual
+func checkAccrualRole(stub shim.ChaincodeStubInterface) error {
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return err
+	}
+	if role != string(validation.ActorRoleLoanOperationsManager) && role != string(validation.ActorRoleSystemAdministrator) {
+		return services.NewChaincodeError(services.ErrCodeAccessDenied, "", "interest accrual may only be run by a %s or %s", validation.ActorRoleLoanOperationsManager, validation.ActorRoleSystemAdministrator)
+	}
+	return nil
+}
@@ -0,0 +1,162 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// accrualDay is ten days into the first period of loans scheduled from scheduleStart
+var accrualDay = time.Date(2026, 1, 25, 9, 0, 0, 0, time.UTC)
+
+func TestGetPayoffQuote(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	installments := seedServicedLoan(t, stub, "LOAN_P1", "USD", 12000, 12)
+	handler := NewInterestAccrualHandler()
+
+	// A fee posted against the loan stays outstanding until payoff
+	_, err := inTxAt(stub, "post_fee", accrualDay, func() ([]byte, error) {
+		loanApp := getLoan(t, stub, "LOAN_P1")
+		if _, err := postLoanTransaction(stub, handler.persistenceService, loanApp, domain.LoanTransactionFee, utils.NewMoney(25.5, "USD"), "LATE-FEE", "", "ACTOR_005"); err != nil {
+			return nil, err
+		}
+		return nil, putLoanApplication(stub, handler.persistenceService, loanApp)
+	})
+	if err != nil {
+		t.Fatalf("failed to post fee: %v", err)
+	}
+
+	quote := func(txID, asOfDate string) (*domain.PayoffQuote, error) {
+		payload, err := inTxAt(stub, txID, accrualDay, func() ([]byte, error) {
+			return handler.GetPayoffQuote(stub, []string{"LOAN_P1", asOfDate})
+		})
+		if err != nil {
+			return nil, err
+		}
+		var quote domain.PayoffQuote
+		if err := json.Unmarshal(payload, &quote); err != nil {
+			t.Fatalf("failed to decode quote: %v", err)
+		}
+		return &quote, nil
+	}
+
+	// Ten of the first period's 31 days have accrued, in whole cents
+	today, err := quote("quote_today", "")
+	if err != nil {
+		t.Fatalf("quote failed: %v", err)
+	}
+	accrued := installments[0].InterestDue.Mul(10.0 / 31).Float64()
+	if today.AsOfDate != "2026-01-25" || today.Position.PrincipalOutstanding != 12000 || today.Position.AccruedInterest != accrued {
+		t.Errorf("expected 12000 principal and %.2f accrued on 2026-01-25, got %+v", accrued, today)
+	}
+	if today.FeesOutstanding != 25.5 || today.PayoffAmount != roundToCents(12000+accrued+25.5) {
+		t.Errorf("expected payoff %.2f, got %.2f", 12000+accrued+25.5, today.PayoffAmount)
+	}
+
+	// Quoting is deterministic: the same date gives the same amount, a later date accrues more
+	again, err := quote("quote_again", "2026-01-25")
+	if err != nil || again.PayoffAmount != today.PayoffAmount {
+		t.Errorf("expected the same payoff on requote, got %v (%v)", again, err)
+	}
+	later, err := quote("quote_later", "2026-02-05")
+	if err != nil {
+		t.Fatalf("quote failed: %v", err)
+	}
+	if later.Position.AccruedInterest != installments[0].InterestDue.Mul(21.0/31).Float64() {
+		t.Errorf("expected 21 days accrued on 2026-02-05, got %.2f", later.Position.AccruedInterest)
+	}
+
+	_, err = quote("quote_past", "2026-01-24")
+	expectErrorCode(t, err, services.ErrCodeInvalidArgument)
+}
+
+func TestGetPayoffQuoteRejectsUnfundedLoan(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	seedLoan(t, stub, "LOAN_P2", validation.LoanStatusApproved, approvedTerms(12000, 12))
+
+	_, err := inTxAt(stub, "quote_unfunded", accrualDay, func() ([]byte, error) {
+		return NewInterestAccrualHandler().GetPayoffQuote(stub, []string{"LOAN_P2", ""})
+	})
+	expectErrorCode(t, err, services.ErrCodeInvalidTransition)
+}
+
+func TestAccrueInterestBatchesAndResumes(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	first := seedServicedLoan(t, stub, "LOAN_A1", "USD", 12000, 12)
+	second := seedServicedLoan(t, stub, "LOAN_A2", "USD", 5000, 7.5)
+	seedLoan(t, stub, "LOAN_A3", validation.LoanStatusApproved, approvedTerms(8000, 6))
+	handler := NewInterestAccrualHandler()
+
+	accrue := func(txID string, at time.Time, req domain.InterestAccrualRequest) (*domain.InterestAccrualRun, error) {
+		payload, err := inTxAt(stub, txID, at, func() ([]byte, error) {
+			return handler.AccrueInterest(stub, []string{mustJSON(t, req)})
+		})
+		if err != nil {
+			return nil, err
+		}
+		var run domain.InterestAccrualRun
+		if err := json.Unmarshal(payload, &run); err != nil {
+			t.Fatalf("failed to decode run: %v", err)
+		}
+		return &run, nil
+	}
+	req := domain.InterestAccrualRequest{AccrualDate: "2026-01-25", BatchSize: 2, ActorID: "ACTOR_005"}
+
+	// The first batch stops at its cap and checkpoints
+	run, err := accrue("accrue_1", accrualDay, req)
+	if err != nil {
+		t.Fatalf("first batch failed: %v", err)
+	}
+	if run.Status != domain.InterestAccrualInProgress || run.LoansScanned != 2 || run.Checkpoint == "" {
+		t.Fatalf("expected an in-progress run after 2 loans, got %+v", run)
+	}
+
+	// The next call resumes after the checkpoint; the unfunded loan is read but not accrued
+	run, err = accrue("accrue_2", accrualDay, req)
+	if err != nil {
+		t.Fatalf("second batch failed: %v", err)
+	}
+	if run.Status != domain.InterestAccrualCompleted || run.LoansScanned != 3 || run.LoansAccrued != 2 || run.Batches != 2 {
+		t.Fatalf("expected a completed run over 3 loans, got %+v", run)
+	}
+
+	firstAccrued := first[0].InterestDue.Mul(10.0 / 31).Float64()
+	secondAccrued := second[0].InterestDue.Mul(10.0 / 31).Float64()
+	if run.TotalAccruedInterest != roundToCents(firstAccrued+secondAccrued) {
+		t.Errorf("expected %.2f accrued in total, got %.2f", firstAccrued+secondAccrued, run.TotalAccruedInterest)
+	}
+	if loanApp := getLoan(t, stub, "LOAN_A1"); loanApp.AccruedInterest.Float64() != firstAccrued || loanApp.InterestAccruedThrough != "2026-01-25" {
+		t.Errorf("expected %.2f accrued through 2026-01-25, got %.2f through %s", firstAccrued, loanApp.AccruedInterest.Float64(), loanApp.InterestAccruedThrough)
+	}
+	if loanApp := getLoan(t, stub, "LOAN_A3"); loanApp.InterestAccruedThrough != "" || loanApp.Version != 1 {
+		t.Errorf("unfunded loan must not accrue, got %+v", loanApp)
+	}
+
+	// Replaying a completed run is refused rather than accruing twice
+	_, err = accrue("accrue_replay", accrualDay, req)
+	expectErrorCode(t, err, services.ErrCodeInvalidTransition)
+	if loanApp := getLoan(t, stub, "LOAN_A1"); loanApp.Version != 2 {
+		t.Errorf("replay must leave the loan unchanged, got version %d", loanApp.Version)
+	}
+}
+
+func TestAccrueInterestRejectsInvalidRuns(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	handler := NewInterestAccrualHandler()
+
+	_, err := inTxAt(stub, "accrue_future", accrualDay, func() ([]byte, error) {
+		return handler.AccrueInterest(stub, []string{mustJSON(t, domain.InterestAccrualRequest{AccrualDate: "2026-01-26", ActorID: "ACTOR_005"})})
+	})
+	expectErrorCode(t, err, services.ErrCodeInvalidArgument)
+
+	stub.Creator = newTestIdentity(t, string(validation.ActorRoleUnderwriter))
+	_, err = inTxAt(stub, "accrue_underwriter", accrualDay, func() ([]byte, error) {
+		return handler.AccrueInterest(stub, []string{mustJSON(t, domain.InterestAccrualRequest{AccrualDate: "2026-01-25", ActorID: "ACTOR_002"})})
+	})
+	expectErrorCode(t, err, services.ErrCodeAccessDenied)
+}
@@ -0,0 +1,572 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	loanServices "github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// commissionPeriodFormat is the layout of commission statement periods
+const commissionPeriodFormat = "2006-01"
+
+// IntroducerHandler handles introducer registration and commission operations
+type IntroducerHandler struct {
+	persistenceService *services.PersistenceService
+	eventService      *loanServices.EventService
+}
+
+// NewIntroducerHandler creates a new introducer handler
+func NewIntroducerHandler() *IntroducerHandler {
+	return &IntroducerHandler{
+		persistenceService: services.NewPersistenceService(),
+		eventService:      loanServices.NewEventService(),
+	}
+}
+
+// RegisterIntroducer registers a broker to earn commission on the loans it introduces
+func (h *IntroducerHandler) RegisterIntroducer(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.IntroducerRegistrationRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse introducer registration request: %v", err)
+	}
+	if strings.TrimSpace(req.IntroducerID) == "" || strings.TrimSpace(req.LegalName) == "" || req.ScheduleID == "" {
+		return nil, fmt.Errorf("introducerID, legalName and scheduleID are required")
+	}
+	if err := checkIntroducerRole(stub); err != nil {
+		return nil, err
+	}
+
+	introducerKey := fmt.Sprintf("INTRODUCER_%s", req.IntroducerID)
+	exists, err := h.persistenceService.Exists(stub, introducerKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check introducer: %w", err)
+	}
+	if exists {
+		return nil, services.NewChaincodeError(services.ErrCodeConflict, "", "introducer %s is already registered", req.IntroducerID)
+	}
+	if _, err := getCommissionSchedule(stub, h.persistenceService, req.ScheduleID); err != nil {
+		return nil, err
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	introducer := &domain.Introducer{
+		IntroducerID:  req.IntroducerID,
+		LegalName:     req.LegalName,
+		ScheduleID:    req.ScheduleID,
+		Status:        domain.IntroducerStatusActive,
+		CreatedDate:   now,
+		LastUpdated:   now,
+		CreatedBy:     req.ActorID,
+		LastUpdatedBy: req.ActorID,
+		Version:       1,
+	}
+
+	// Store introducer
+	if err := h.persistenceService.Put(stub, introducerKey, introducer); err != nil {
+		return nil, fmt.Errorf("failed to store introducer: %w", err)
+	}
+
+	// Record history
+	introducerJSON, _ := utils.MarshalJSONString(introducer)
+	if err := h.recordEntityHistory(stub, req.IntroducerID, "Introducer", "CREATE", "introducer", "", introducerJSON, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to record history: %w", err)
+	}
+
+	// Emit event
+	if err := h.eventService.EmitIntroducerEvent(stub, config.EventIntroducerRegistered, introducer, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(introducer)
+}
+
+// UpdateIntroducerStatus suspends, reinstates or terminates an introducer. Commission already
+// accrued stands and remains subject to clawback.
+func (h *IntroducerHandler) UpdateIntroducerStatus(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.IntroducerStatusUpdateRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse introducer status update request: %v", err)
+	}
+	if err := checkIntroducerRole(stub); err != nil {
+		return nil, err
+	}
+
+	introducer, err := getIntroducer(stub, h.persistenceService, req.IntroducerID)
+	if err != nil {
+		return nil, err
+	}
+	if err := services.CheckExpectedVersion("Introducer", req.IntroducerID, req.ExpectedVersion, introducer.Version); err != nil {
+		return nil, err
+	}
+
+	switch req.NewStatus {
+	case domain.IntroducerStatusActive, domain.IntroducerStatusSuspended, domain.IntroducerStatusTerminated:
+	default:
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid introducer status: %s", req.NewStatus)
+	}
+	if introducer.Status == domain.IntroducerStatusTerminated {
+		return nil, fmt.Errorf("introducer %s is terminated", req.IntroducerID)
+	}
+	if introducer.Status == req.NewStatus {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "introducer %s is already %s", req.IntroducerID, req.NewStatus)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	previousStatus := introducer.Status
+	introducer.Status = req.NewStatus
+	introducer.StatusReason = req.Reason
+	introducer.LastUpdated = now
+	introducer.LastUpdatedBy = req.ActorID
+	introducer.Version++
+
+	if err := h.persistenceService.Put(stub, fmt.Sprintf("INTRODUCER_%s", introducer.IntroducerID), introducer); err != nil {
+		return nil, fmt.Errorf("failed to update introducer: %w", err)
+	}
+
+	// Record history
+	if err := h.recordEntityHistory(stub, introducer.IntroducerID, "Introducer", "STATUS_UPDATE", "status", string(previousStatus), string(req.NewStatus), req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to record history: %w", err)
+	}
+
+	// Emit event
+	if err := h.eventService.EmitIntroducerEvent(stub, config.EventIntroducerStatusChanged, introducer, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(introducer)
+}
+
+// SetCommissionSchedule creates or replaces a commission schedule. Rates apply to tranches
+// disbursed afterwards; loans that have already earned commission keep their clawback terms.
+func (h *IntroducerHandler) SetCommissionSchedule(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.CommissionScheduleRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse commission schedule request: %v", err)
+	}
+	if strings.TrimSpace(req.ScheduleID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "scheduleID", "scheduleID is required")
+	}
+	if err := checkIntroducerRole(stub); err != nil {
+		return nil, err
+	}
+
+	if len(req.Rates) == 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "a commission schedule needs at least one rate")
+	}
+	seen := make(map[string]bool)
+	for _, rate := range req.Rates {
+		if err := validation.ValidateLoanType(rate.LoanType); err != nil {
+			return nil, fmt.Errorf("invalid loan type: %w", err)
+		}
+		if seen[rate.LoanType] {
+			return nil, fmt.Errorf("duplicate commission rate for loan type %s", rate.LoanType)
+		}
+		seen[rate.LoanType] = true
+		if rate.RatePercent <= 0 || rate.RatePercent > 100 {
+			return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "commission rate for %s must be greater than 0 and at most 100 percent", rate.LoanType)
+		}
+		if rate.CapAmount < 0 {
+			return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "commission cap for %s cannot be negative", rate.LoanType)
+		}
+	}
+	if req.ClawbackWindowMonths < 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "clawbackWindowMonths cannot be negative")
+	}
+	if req.ClawbackPercent < 0 || req.ClawbackPercent > 100 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "clawbackPercent must be between 0 and 100")
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	schedule := &domain.CommissionSchedule{
+		ScheduleID:           req.ScheduleID,
+		Rates:                req.Rates,
+		ClawbackWindowMonths: req.ClawbackWindowMonths,
+		ClawbackPercent:      req.ClawbackPercent,
+		LastUpdated:          now,
+		LastUpdatedBy:        req.ActorID,
+	}
+
+	scheduleKey := fmt.Sprintf("COMMISSION_SCHEDULE_%s", req.ScheduleID)
+	previousJSON := ""
+	if previous, err := stub.GetState(scheduleKey); err != nil {
+		return nil, fmt.Errorf("failed to read commission schedule: %w", err)
+	} else if previous != nil {
+		previousJSON = string(previous)
+	}
+
+	if err := h.persistenceService.Put(stub, scheduleKey, schedule); err != nil {
+		return nil, fmt.Errorf("failed to store commission schedule: %w", err)
+	}
+
+	// Record history
+	scheduleJSON, _ := utils.MarshalJSONString(schedule)
+	if err := h.recordEntityHistory(stub, req.ScheduleID, "CommissionSchedule", "UPDATE", "commissionSchedule", previousJSON, scheduleJSON, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to record history: %w", err)
+	}
+
+	// Emit event
+	if err := h.eventService.EmitCommissionScheduleUpdated(stub, schedule, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(schedule)
+}
+
+// GetIntroducer retrieves an introducer by ID
+func (h *IntroducerHandler) GetIntroducer(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	introducer, err := getIntroducer(stub, h.persistenceService, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(introducer)
+}
+
+// GetCommissionSchedule retrieves a commission schedule by ID
+func (h *IntroducerHandler) GetCommissionSchedule(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	schedule, err := getCommissionSchedule(stub, h.persistenceService, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(schedule)
+}
+
+// GetCommissionStatement lists an introducer's commission accruals and clawbacks for a range of
+// periods with their totals. Introducers may only read their own statement.
+// Args: introducerID, fromPeriod (YYYY-MM), toPeriod (YYYY-MM)
+func (h *IntroducerHandler) GetCommissionStatement(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 3 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 3, got %d", len(args))
+	}
+
+	introducerID, fromPeriod, toPeriod := args[0], args[1], args[2]
+	if _, err := time.Parse(commissionPeriodFormat, fromPeriod); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid fromPeriod %s, expected YYYY-MM", fromPeriod)
+	}
+	if _, err := time.Parse(commissionPeriodFormat, toPeriod); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid toPeriod %s, expected YYYY-MM", toPeriod)
+	}
+	if toPeriod < fromPeriod {
+		return nil, fmt.Errorf("toPeriod %s is before fromPeriod %s", toPeriod, fromPeriod)
+	}
+
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return nil, err
+	}
+	if role == string(validation.ActorRoleIntroducer) {
+		if err := services.NewIdentityService().VerifyActor(stub, introducerID); err != nil {
+			return nil, fmt.Errorf("introducers may only read their own commission statement: %w", err)
+		}
+	}
+
+	if _, err := getIntroducer(stub, h.persistenceService, introducerID); err != nil {
+		return nil, err
+	}
+
+	iterator, err := stub.GetStateByPartialCompositeKey("COMMISSION_ENTRY", []string{introducerID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get commission entries: %w", err)
+	}
+	defer iterator.Close()
+
+	statement := &domain.CommissionStatement{
+		IntroducerID: introducerID,
+		FromPeriod:   fromPeriod,
+		ToPeriod:     toPeriod,
+		Entries:      []domain.CommissionEntry{},
+	}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate commission entries: %w", err)
+		}
+
+		var entry domain.CommissionEntry
+		if err := json.Unmarshal(response.Value, &entry); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal commission entry: %w", err)
+		}
+		if entry.Period < fromPeriod || entry.Period > toPeriod {
+			continue
+		}
+
+		statement.Entries = append(statement.Entries, entry)
+		if entry.EntryType == domain.CommissionEntryClawback {
+			statement.TotalClawedBack = roundToCents(statement.TotalClawedBack - entry.Amount)
+		} else {
+			statement.TotalAccrued = roundToCents(statement.TotalAccrued + entry.Amount)
+		}
+	}
+	statement.NetCommission = roundToCents(statement.TotalAccrued - statement.TotalClawedBack)
+
+	return json.Marshal(statement)
+}
+
+// Helper methods
+
+func (h *IntroducerHandler) recordEntityHistory(stub shim.ChaincodeStubInterface, entityID, entityType, changeType, fieldName, previousValue, newValue, actorID string) error {
+	historyID, compositeKey, err := services.NewHistoryKey(stub, entityID)
+	if err != nil {
+		return err
+	}
+	txID := stub.GetTxID()
+
+	timestamp, err := services.TxTimeString(stub)
+	if err != nil {
+		return err
+	}
+
+	historyEntry := map[string]interface{}{
+		"historyID":     historyID,
+		"entityID":      entityID,
+		"entityType":    entityType,
+		"timestamp":     timestamp,
+		"changeType":    changeType,
+		"fieldName":     fieldName,
+		"previousValue": previousValue,
+		"newValue":      newValue,
+		"actorID":       actorID,
+		"transactionID": txID,
+	}
+
+	return h.persistenceService.Put(stub, compositeKey, historyEntry)
+}
+
+func getIntroducer(stub shim.ChaincodeStubInterface, persistenceService *services.PersistenceService, introducerID string) (*domain.Introducer, error) {
+	var introducer domain.Introducer
+	if err := persistenceService.Get(stub, fmt.Sprintf("INTRODUCER_%s", introducerID), &introducer); err != nil {
+		return nil, fmt.Errorf("introducer not found: %w", err)
+	}
+	return &introducer, nil
+}
+
+func getCommissionSchedule(stub shim.ChaincodeStubInterface, persistenceService *services.PersistenceService, scheduleID string) (*domain.CommissionSchedule, error) {
+	var schedule domain.CommissionSchedule
+	if err := persistenceService.Get(stub, fmt.Sprintf("COMMISSION_SCHEDULE_%s", scheduleID), &schedule); err != nil {
+		return nil, fmt.Errorf("commission schedule not found: %w", err)
+	}
+	return &schedule, nil
+}
+
+// resolveIntroducer settles the introducer of a new application. Introducers submitting directly
+// are recorded under their own actor ID; staff may name a registered introducer.
+func resolveIntroducer(stub shim.ChaincodeStubInterface, persistenceService *services.PersistenceService, requestedID, actorID string) (string, error) {
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return "", err
+	}
+
+	introducerID := requestedID
+	if role == string(validation.ActorRoleIntroducer) {
+		if requestedID != "" && requestedID != actorID {
+			return "", services.NewChaincodeError(services.ErrCodeAccessDenied, "", "introducers may only submit applications under their own introducer ID")
+		}
+		introducerID = actorID
+
+		// Introducers that have not been registered may still submit; they earn no commission
+		exists, err := persistenceService.Exists(stub, fmt.Sprintf("INTRODUCER_%s", introducerID))
+		if err != nil {
+			return "", fmt.Errorf("failed to check introducer: %w", err)
+		}
+		if !exists {
+			return introducerID, nil
+		}
+	}
+	if introducerID == "" {
+		return "", nil
+	}
+
+	introducer, err := getIntroducer(stub, persistenceService, introducerID)
+	if err != nil {
+		return "", err
+	}
+	if introducer.Status == domain.IntroducerStatusTerminated {
+		return "", fmt.Errorf("introducer %s is terminated", introducerID)
+	}
+	return introducerID, nil
+}
+
+// accrueCommission accrues the introducer's commission on a disbursed tranche, recording it on the
+// disbursement and the loan; the caller persists both. Nothing accrues when the loan has no
+// registered, active introducer or its schedule pays nothing for the loan type.
+func accrueCommission(stub shim.ChaincodeStubInterface, persistenceService *services.PersistenceService, loanApp *domain.LoanApplication, disbursement *domain.LoanDisbursement, actorID string) error {
+	if loanApp.IntroducerID == "" {
+		return nil
+	}
+
+	introducerKey := fmt.Sprintf("INTRODUCER_%s", loanApp.IntroducerID)
+	exists, err := persistenceService.Exists(stub, introducerKey)
+	if err != nil {
+		return fmt.Errorf("failed to check introducer: %w", err)
+	}
+	if !exists {
+		return nil
+	}
+	introducer, err := getIntroducer(stub, persistenceService, loanApp.IntroducerID)
+	if err != nil {
+		return err
+	}
+	if introducer.Status != domain.IntroducerStatusActive {
+		return nil
+	}
+
+	schedule, err := getCommissionSchedule(stub, persistenceService, introducer.ScheduleID)
+	if err != nil {
+		return err
+	}
+	var rate *domain.CommissionRate
+	for i := range schedule.Rates {
+		if schedule.Rates[i].LoanType == loanApp.LoanType {
+			rate = &schedule.Rates[i]
+			break
+		}
+	}
+	if rate == nil {
+		return nil
+	}
+
+	if loanApp.Commission == nil {
+		loanApp.Commission = &domain.LoanCommission{
+			IntroducerID:         introducer.IntroducerID,
+			ScheduleID:           schedule.ScheduleID,
+			ClawbackWindowMonths: schedule.ClawbackWindowMonths,
+			ClawbackPercent:      schedule.ClawbackPercent,
+		}
+	}
+
+	amount := roundToCents(disbursement.Amount.Float64() * rate.RatePercent / 100)
+	if rate.CapAmount > 0 {
+		if remaining := roundToCents(rate.CapAmount - loanApp.Commission.Accrued); amount > remaining {
+			amount = remaining
+		}
+	}
+	if amount <= 0 {
+		return nil
+	}
+
+	entry := &domain.CommissionEntry{
+		EntryID:        services.GenerateDeterministicID(stub, config.CommissionEntryPrefix),
+		IntroducerID:   introducer.IntroducerID,
+		Period:         disbursement.DisbursementDate.Format(commissionPeriodFormat),
+		EntryType:      domain.CommissionEntryAccrual,
+		LoanID:         loanApp.LoanID,
+		LoanType:       loanApp.LoanType,
+		ScheduleID:     schedule.ScheduleID,
+		DisbursementID: disbursement.DisbursementID,
+		BaseAmount:     disbursement.Amount.Float64(),
+		RatePercent:    rate.RatePercent,
+		Amount:         amount,
+		EntryDate:      disbursement.DisbursementDate,
+		RecordedBy:     actorID,
+	}
+	if err := putCommissionEntry(stub, persistenceService, entry); err != nil {
+		return err
+	}
+
+	loanApp.Commission.Accrued = roundToCents(loanApp.Commission.Accrued + amount)
+	disbursement.CommissionEntryID = entry.EntryID
+	disbursement.CommissionAmount = amount
+	return nil
+}
+
+// clawBackCommission claws back the agreed share of a loan's net commission when it defaults
+// within the clawback window of its first disbursement, returning the amount clawed back. The
+// caller persists the loan.
+func clawBackCommission(stub shim.ChaincodeStubInterface, persistenceService *services.PersistenceService, loanApp *domain.LoanApplication, defaultDate time.Time, actorID string) (float64, error) {
+	commission := loanApp.Commission
+	if commission == nil || commission.ClawbackWindowMonths == 0 || commission.ClawbackPercent == 0 || loanApp.DisbursementDate == nil {
+		return 0, nil
+	}
+	if defaultDate.After(loanApp.DisbursementDate.AddDate(0, commission.ClawbackWindowMonths, 0)) {
+		return 0, nil
+	}
+
+	net := roundToCents(commission.Accrued - commission.ClawedBack)
+	amount := roundToCents(net * commission.ClawbackPercent / 100)
+	if amount <= 0 {
+		return 0, nil
+	}
+
+	entry := &domain.CommissionEntry{
+		EntryID:      services.GenerateDeterministicID(stub, config.CommissionEntryPrefix),
+		IntroducerID: commission.IntroducerID,
+		Period:       defaultDate.Format(commissionPeriodFormat),
+		EntryType:    domain.CommissionEntryClawback,
+		LoanID:       loanApp.LoanID,
+		LoanType:     loanApp.LoanType,
+		ScheduleID:   commission.ScheduleID,
+		BaseAmount:   net,
+		RatePercent:  commission.ClawbackPercent,
+		Amount:       -amount,
+		EntryDate:    defaultDate,
+		RecordedBy:   actorID,
+	}
+	if err := putCommissionEntry(stub, persistenceService, entry); err != nil {
+		return 0, err
+	}
+
+	commission.ClawedBack = roundToCents(commission.ClawedBack + amount)
+	return amount, nil
+}
+
+func putCommissionEntry(stub shim.ChaincodeStubInterface, persistenceService *services.PersistenceService, entry *domain.CommissionEntry) error {
+	entryKey, err := stub.CreateCompositeKey("COMMISSION_ENTRY", []string{entry.IntroducerID, entry.Period, entry.EntryID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := persistenceService.Put(stub, entryKey, entry); err != nil {
+		return fmt.Errorf("failed to store commission entry: %w", err)
+	}
+	return nil
+}
+
+// checkIntroducerRole checks the invoker may manage introducers and commission schedules
+func checkIntroducerRole(stub shim.ChaincodeStubInterface) error {
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return err
+	}
+	if role != string(validation.ActorRoleLoanOperationsManager) && role != string(validation.ActorRoleSystemAdministrator) {
+		return services.NewChaincodeError(services.ErrCodeAccessDenied, "", "introducers may only be managed by a %s or %s", validation.ActorRoleLoanOperationsManager, validation.ActorRoleSystemAdministrator)
+	}
+	return nil
+}
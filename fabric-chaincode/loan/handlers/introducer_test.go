@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	loanServices "github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// disburseAt runs DisburseLoan as its own transaction on the given date
+func disburseAt(t *testing.T, stub *shimtest.MockStub, txID, loanID string, at time.Time, amount float64) *domain.LoanDisbursement {
+	t.Helper()
+	payload, err := inTxAt(stub, txID, at, func() ([]byte, error) {
+		return NewDisbursementHandler().DisburseLoan(stub, []string{mustJSON(t, domain.LoanDisbursementRequest{
+			LoanID:                loanID,
+			Amount:                amount,
+			DestinationAccountRef: "ACC-0001",
+			ActorID:               "ACTOR_004",
+		})})
+	})
+	if err != nil {
+		t.Fatalf("disbursement %s failed: %v", txID, err)
+	}
+	var disbursement domain.LoanDisbursement
+	if err := json.Unmarshal(payload, &disbursement); err != nil {
+		t.Fatalf("failed to decode disbursement: %v", err)
+	}
+	return &disbursement
+}
+
+// commissionStatement reads an introducer's statement for the given periods
+func commissionStatement(t *testing.T, stub *shimtest.MockStub, txID, introducerID, fromPeriod, toPeriod string) *domain.CommissionStatement {
+	t.Helper()
+	payload, err := inTx(stub, txID, func() ([]byte, error) {
+		return NewIntroducerHandler().GetCommissionStatement(stub, []string{introducerID, fromPeriod, toPeriod})
+	})
+	if err != nil {
+		t.Fatalf("failed to read statement: %v", err)
+	}
+	var statement domain.CommissionStatement
+	if err := json.Unmarshal(payload, &statement); err != nil {
+		t.Fatalf("failed to decode statement: %v", err)
+	}
+	return &statement
+}
+
+func TestIntroducerCommissionAccruesCapsAndClawsBackOnEarlyDefault(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleUnderwriter))
+	withCompliance(stub)
+	handler := NewIntroducerHandler()
+
+	schedule := domain.CommissionScheduleRequest{
+		ScheduleID:           "SCHED_STD",
+		Rates:                []domain.CommissionRate{{LoanType: "PERSONAL", RatePercent: 2, CapAmount: 200}},
+		ClawbackWindowMonths: 12,
+		ClawbackPercent:      50,
+		ActorID:              "ACTOR_006",
+	}
+	_, err := inTx(stub, "schedule_denied", func() ([]byte, error) {
+		return handler.SetCommissionSchedule(stub, []string{mustJSON(t, schedule)})
+	})
+	expectErrorCode(t, err, services.ErrCodeAccessDenied)
+
+	// An introducer can only be put on a schedule that exists
+	stub.Creator = newTestIdentity(t, string(validation.ActorRoleLoanOperationsManager))
+	register := func(txID, introducerID string) error {
+		_, err := inTx(stub, txID, func() ([]byte, error) {
+			return handler.RegisterIntroducer(stub, []string{mustJSON(t, domain.IntroducerRegistrationRequest{
+				IntroducerID: introducerID,
+				LegalName:    "Broker " + introducerID,
+				ScheduleID:   "SCHED_STD",
+				ActorID:      "ACTOR_006",
+			})})
+		})
+		return err
+	}
+	if err := register("register_early", "BROKER_001"); err == nil {
+		t.Fatal("expected an unknown schedule to be refused")
+	}
+	if _, err := inTx(stub, "schedule", func() ([]byte, error) {
+		return handler.SetCommissionSchedule(stub, []string{mustJSON(t, schedule)})
+	}); err != nil {
+		t.Fatalf("failed to set schedule: %v", err)
+	}
+	for _, introducerID := range []string{"BROKER_001", "BROKER_002"} {
+		if err := register("register_"+introducerID, introducerID); err != nil {
+			t.Fatalf("failed to register %s: %v", introducerID, err)
+		}
+	}
+	expectErrorCode(t, register("register_again", "BROKER_001"), services.ErrCodeConflict)
+
+	// Each tranche earns 2% until the loan reaches its 200 cap
+	seedLoan(t, stub, "LOAN_I1", validation.LoanStatusApproved, approvedTerms(12000, 12), func(loanApp *domain.LoanApplication) {
+		loanApp.IntroducerID = "BROKER_001"
+	})
+	first := disburseAt(t, stub, "disburse_1", "LOAN_I1", scheduleStart, 6000)
+	second := disburseAt(t, stub, "disburse_2", "LOAN_I1", scheduleStart.AddDate(0, 0, 5), 6000)
+	if first.CommissionAmount != 120 || first.CommissionEntryID == "" || second.CommissionAmount != 80 {
+		t.Fatalf("expected commission of 120 then 80, got %.2f and %.2f", first.CommissionAmount, second.CommissionAmount)
+	}
+	loanApp := getLoan(t, stub, "LOAN_I1")
+	if loanApp.Commission == nil || loanApp.Commission.Accrued != 200 || loanApp.Commission.ScheduleID != "SCHED_STD" {
+		t.Fatalf("expected 200 accrued on SCHED_STD, got %+v", loanApp.Commission)
+	}
+
+	// A suspended introducer earns nothing on new disbursements
+	if _, err := inTx(stub, "suspend", func() ([]byte, error) {
+		return handler.UpdateIntroducerStatus(stub, []string{mustJSON(t, domain.IntroducerStatusUpdateRequest{
+			IntroducerID: "BROKER_002",
+			NewStatus:    domain.IntroducerStatusSuspended,
+			Reason:       "Licence under review",
+			ActorID:      "ACTOR_006",
+		})})
+	}); err != nil {
+		t.Fatalf("failed to suspend introducer: %v", err)
+	}
+	seedLoan(t, stub, "LOAN_I2", validation.LoanStatusApproved, approvedTerms(5000, 12), func(loanApp *domain.LoanApplication) {
+		loanApp.IntroducerID = "BROKER_002"
+	})
+	if suspended := disburseAt(t, stub, "disburse_suspended", "LOAN_I2", scheduleStart, 5000); suspended.CommissionAmount != 0 {
+		t.Errorf("expected no commission for a suspended introducer, got %.2f", suspended.CommissionAmount)
+	}
+	if getLoan(t, stub, "LOAN_I2").Commission != nil {
+		t.Error("expected no commission on the suspended introducer's loan")
+	}
+
+	// A default inside the clawback window takes back half of the net commission
+	_, err = inTx(stub, "schedule_I1", func() ([]byte, error) {
+		_, err := loanServices.NewScheduleService().GenerateSchedule(stub, loanApp, scheduleStart)
+		return nil, err
+	})
+	if err != nil {
+		t.Fatalf("failed to generate schedule: %v", err)
+	}
+	stub.Creator = newTestIdentity(t, string(validation.ActorRoleCreditOfficer))
+	defaultDate := time.Date(2026, 6, 1, 10, 0, 0, 0, time.UTC)
+	if _, err := inTxAt(stub, "default", defaultDate, func() ([]byte, error) {
+		return NewRepaymentHandler().MarkLoanDefaulted(stub, []string{mustJSON(t, domain.LoanDefaultRequest{LoanID: "LOAN_I1", ActorID: "ACTOR_007"})})
+	}); err != nil {
+		t.Fatalf("failed to mark default: %v", err)
+	}
+	loanApp = getLoan(t, stub, "LOAN_I1")
+	if loanApp.Default == nil || loanApp.Default.CommissionClawedBack != 100 || loanApp.Commission.ClawedBack != 100 {
+		t.Fatalf("expected 100 clawed back, got %+v", loanApp.Default)
+	}
+
+	// Statements net accruals against clawbacks within the requested periods
+	january := commissionStatement(t, stub, "statement_jan", "BROKER_001", "2026-01", "2026-01")
+	if len(january.Entries) != 2 || january.TotalAccrued != 200 || january.NetCommission != 200 {
+		t.Errorf("expected 200 accrued in January, got %+v", january)
+	}
+	halfYear := commissionStatement(t, stub, "statement_h1", "BROKER_001", "2026-01", "2026-06")
+	if len(halfYear.Entries) != 3 || halfYear.TotalClawedBack != 100 || halfYear.NetCommission != 100 {
+		t.Errorf("expected 100 net over the half year, got %+v", halfYear)
+	}
+
+	// An introducer may only read a statement for its own registered actor
+	stub.Creator = newTestIdentity(t, string(validation.ActorRoleIntroducer))
+	_, err = inTx(stub, "statement_other", func() ([]byte, error) {
+		return handler.GetCommissionStatement(stub, []string{"BROKER_002", "2026-01", "2026-06"})
+	})
+	if err == nil || !strings.Contains(err.Error(), "their own commission statement") {
+		t.Errorf("expected another introducer's statement to be refused, got %v", err)
+	}
+}
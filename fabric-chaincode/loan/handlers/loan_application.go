@@ -3,12 +3,15 @@ package handlers
 import (
 	"encoding/json"
 	"fmt"
+	"strconv"
+	"strings"
 	"time"
 
 	"github.com/hyperledger/fabric-chaincode-go/shim"
 	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
 	loanServices "github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/services"
 	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/interfaces"
 	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
 	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
 	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
@@ -18,6 +21,15 @@ import (
 type LoanApplicationHandler struct {
 	persistenceService *services.PersistenceService
 	eventService      *loanServices.EventService
+	customerVerifier  *loanServices.CustomerVerificationService
+	indexRateService  *loanServices.IndexRateService
+	scheduleService   *loanServices.ScheduleService
+	sandboxService    *services.SandboxService
+	qaService         *services.QAService
+	idempotencyService *services.IdempotencyService
+	textScreeningService *loanServices.TextScreeningService
+	channelMonitoringService *loanServices.ChannelMonitoringService
+	lockService       *services.EntityLockService
 }
 
 // NewLoanApplicationHandler creates a new loan application handler
@@ -25,71 +37,226 @@ func NewLoanApplicationHandler() *LoanApplicationHandler {
 	return &LoanApplicationHandler{
 		persistenceService: services.NewPersistenceService(),
 		eventService:      loanServices.NewEventService(),
+		customerVerifier:  loanServices.NewCustomerVerificationService(),
+		indexRateService:  loanServices.NewIndexRateService(),
+		scheduleService:   loanServices.NewScheduleService(),
+		sandboxService:    services.NewSandboxService(),
+		qaService:         services.NewQAService(),
+		idempotencyService: services.NewIdempotencyService(),
+		textScreeningService: loanServices.NewTextScreeningService(),
+		channelMonitoringService: loanServices.NewChannelMonitoringService(),
+		lockService:       services.NewEntityLockService(),
 	}
 }
 
 // SubmitLoanApplication submits a new loan application
 func (h *LoanApplicationHandler) SubmitLoanApplication(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
 	if len(args) != 1 {
-		return nil, fmt.Errorf("incorrect number of arguments. Expected 1, got %d", len(args))
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
 	}
 
 	var req domain.LoanApplicationRequest
 	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
-		return nil, fmt.Errorf("failed to parse loan application request: %v", err)
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse loan application request: %v", err)
+	}
+
+	// A retried submission returns the application the original created
+	existingID, err := h.idempotencyService.Replay(stub, "SubmitLoanApplication", req.IdempotencyKey, &req)
+	if err != nil {
+		return nil, err
+	}
+	if existingID != "" {
+		var existing domain.LoanApplication
+		if err := h.persistenceService.Get(stub, fmt.Sprintf("LOAN_%s", existingID), &existing); err != nil {
+			return nil, fmt.Errorf("loan application not found: %w", err)
+		}
+		return json.Marshal(&existing)
 	}
 
 	// Validate loan type
 	if err := validation.ValidateLoanType(req.LoanType); err != nil {
-		return nil, fmt.Errorf("invalid loan type: %v", err)
+		return nil, fmt.Errorf("invalid loan type: %w", err)
 	}
 
+	// Amounts are held in the currency's minor units; product limits are set in the base currency
+	currency := strings.ToUpper(strings.TrimSpace(req.Currency))
+	if currency == "" {
+		currency = config.BaseCurrency
+	}
+	if err := validation.ValidateCurrency(currency); err != nil {
+		return nil, fmt.Errorf("invalid currency: %w", err)
+	}
+	requested := utils.NewMoney(req.RequestedAmount, currency)
+	baseRequested, _, err := h.indexRateService.ConvertToBase(stub, requested)
+	if err != nil {
+		return nil, err
+	}
+	baseAmount := baseRequested.Float64()
+
 	// Validate loan amount
-	if err := validation.ValidateLoanAmount(req.RequestedAmount, req.LoanType); err != nil {
-		return nil, fmt.Errorf("invalid loan amount: %v", err)
+	if err := validation.ValidateLoanAmount(baseAmount, req.LoanType); err != nil {
+		return nil, fmt.Errorf("invalid loan amount: %w", err)
+	}
+
+	// Verify customer KYC/AML/consent with the customer chaincode
+	customerStatus, err := h.customerVerifier.VerifyCustomer(stub, req.CustomerID)
+	if err != nil {
+		return nil, fmt.Errorf("customer verification failed: %w", err)
+	}
+
+	// Screen co-borrowers and guarantors and settle liability shares
+	parties, err := h.buildLoanParties(stub, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	// Withholding rates are keyed by upper-case country code
+	jurisdiction := strings.ToUpper(strings.TrimSpace(req.Jurisdiction))
+	if jurisdiction != "" && len(jurisdiction) != 2 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid jurisdiction %s, expected an ISO 3166-1 alpha-2 country code", req.Jurisdiction)
+	}
+
+	// Record where the application came from for channel fraud monitoring
+	if err := validation.ValidateOriginationChannel(req.Channel); err != nil {
+		return nil, fmt.Errorf("invalid channel: %w", err)
+	}
+	deviceHash := strings.ToLower(strings.TrimSpace(req.DeviceHash))
+	if deviceHash != "" {
+		if err := validation.ValidateDeviceHash(deviceHash); err != nil {
+			return nil, err
+		}
+	}
+
+	// Loans submitted by sandbox actors are UAT data
+	sandbox, err := h.sandboxService.IsSandboxActor(stub, req.ActorID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check sandbox actor: %w", err)
+	}
+
+	// Introducers earn commission on the applications they bring in
+	introducerID, err := resolveIntroducer(stub, h.persistenceService, req.IntroducerID, req.ActorID)
+	if err != nil {
+		return nil, err
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
 	}
 
 	// Generate loan ID
-	loanID := utils.GenerateID(config.LoanApplicationPrefix)
+	loanID := services.GenerateDeterministicID(stub, config.LoanApplicationPrefix)
+
+	// Hold the amount to the customer's risk tier bound unless an approved exception lifts it
+	amountExceptionID, err := checkLoanAmountBound(stub, h.persistenceService, req.CustomerID, customerStatus.RiskTier, req.LoanType, baseAmount, loanID, req.AmountExceptionID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid loan amount: %w", err)
+	}
 
 	// Create loan application
 	loanApp := &domain.LoanApplication{
 		LoanID:          loanID,
 		CustomerID:      req.CustomerID,
+		Parties:         parties,
 		LoanType:        req.LoanType,
-		RequestedAmount: req.RequestedAmount,
+		Currency:        currency,
+		RequestedAmount: requested,
+		DisbursedAmount:    utils.ZeroMoney(currency),
+		OutstandingBalance: utils.ZeroMoney(currency),
+		AccruedInterest:    utils.ZeroMoney(currency),
+		TaxWithheld:        utils.ZeroMoney(currency),
 		TermMonths:      req.TermMonths,
 		Purpose:         req.Purpose,
+		Jurisdiction:    jurisdiction,
 		Status:          validation.LoanStatusSubmitted,
-		ApplicationDate: time.Now(),
+		ApplicationDate: now,
+		Sandbox:         sandbox,
 		Notes:           "",
-		CreatedDate:     time.Now(),
-		LastUpdated:     time.Now(),
+		CreatedDate:     now,
+		LastUpdated:     now,
 		CreatedBy:       req.ActorID,
 		LastUpdatedBy:   req.ActorID,
+		OwnerActorID:    req.ActorID,
+		IntroducerID:    introducerID,
+		Channel:         validation.OriginationChannel(req.Channel),
+		DeviceHash:      deviceHash,
+		AmountExceptionID: amountExceptionID,
 	}
 
-	// Store the loan application
-	loanKey := fmt.Sprintf("LOAN_%s", loanID)
-	if err := h.persistenceService.Put(stub, loanKey, loanApp); err != nil {
-		return nil, fmt.Errorf("failed to store loan application: %v", err)
+	// Screen the purpose for prohibited activities; a blocked purpose holds the application until
+	// compliance has reviewed the match
+	if strings.TrimSpace(req.Purpose) != "" {
+		screening, err := h.textScreeningService.Screen(stub, loanID, "LoanApplication", map[string]string{"purpose": req.Purpose}, req.ActorID)
+		if err != nil {
+			return nil, err
+		}
+		if screening.Flagged {
+			loanApp.PurposeScreening = screening
+		}
+		if screening.Blocked {
+			loanApp.ComplianceHold = &domain.ComplianceHold{
+				Reason:     fmt.Sprintf("Purpose matched prohibited activity terms: %s", strings.Join(loanServices.ScreeningCategories(screening), ", ")),
+				PlacedBy:   req.ActorID,
+				PlacedDate: now,
+			}
+		}
 	}
 
-	// Create index by customer ID
-	customerLoanKey := fmt.Sprintf("CUSTOMER_LOAN_%s_%s", req.CustomerID, loanID)
-	if err := stub.PutState(customerLoanKey, []byte(loanID)); err != nil {
-		return nil, fmt.Errorf("failed to create customer loan index: %v", err)
+	// Feed the channel and device to compliance monitoring; alerts are reviewed there and do not
+	// hold the application
+	channelScreening, err := h.channelMonitoringService.Monitor(stub, &interfaces.ApplicationChannelReport{
+		LoanID:          loanID,
+		CustomerID:      req.CustomerID,
+		Channel:         req.Channel,
+		DeviceHash:      deviceHash,
+		LoanType:        req.LoanType,
+		RequestedAmount: baseAmount,
+		IntroducerID:    introducerID,
+		ActorID:         req.ActorID,
+	})
+	if err != nil {
+		return nil, err
+	}
+	if channelScreening.Flagged {
+		loanApp.ChannelScreening = channelScreening
+	}
+
+	// Store the loan application with its customer, channel, status and date indexes
+	if err := putLoanApplication(stub, h.persistenceService, loanApp); err != nil {
+		return nil, fmt.Errorf("failed to store loan application: %w", err)
+	}
+
+	// Index every party so applications can be found under any role
+	if err := putLoanPartyIndex(stub, h.persistenceService, loanApp); err != nil {
+		return nil, err
+	}
+
+	if err := h.idempotencyService.Record(stub, "SubmitLoanApplication", req.IdempotencyKey, &req, loanID); err != nil {
+		return nil, err
+	}
+
+	// A full application is a hard pull on every party; sandbox applications leave no inquiry trail
+	// and stay out of the production totals
+	if !loanApp.Sandbox {
+		for _, party := range loanApp.Parties {
+			if err := recordHardInquiry(stub, h.persistenceService, party.CustomerID, loanID, "LoanApplication", "Loan application", req.ActorID); err != nil {
+				return nil, err
+			}
+		}
+		if err := services.IncrementCounter(stub, config.MetricLoanApplications); err != nil {
+			return nil, err
+		}
 	}
 
 	// Record history
 	loanJSON, _ := utils.MarshalJSONString(loanApp)
-	if err := h.recordLoanHistory(stub, loanID, "CREATE", "loan_application", "", loanJSON, req.ActorID); err != nil {
-		return nil, fmt.Errorf("failed to record history: %v", err)
+	if err := recordLoanHistory(stub, h.persistenceService, loanID, "CREATE", "loan_application", "", loanJSON, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to record history: %w", err)
 	}
 
 	// Emit event
 	if err := h.eventService.EmitLoanSubmitted(stub, loanApp, req.ActorID); err != nil {
-		return nil, fmt.Errorf("failed to emit event: %v", err)
+		return nil, fmt.Errorf("failed to emit event: %w", err)
 	}
 
 	return json.Marshal(loanApp)
@@ -98,55 +265,111 @@ func (h *LoanApplicationHandler) SubmitLoanApplication(stub shim.ChaincodeStubIn
 // UpdateLoanStatus updates the status of a loan application
 func (h *LoanApplicationHandler) UpdateLoanStatus(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
 	if len(args) != 1 {
-		return nil, fmt.Errorf("incorrect number of arguments. Expected 1, got %d", len(args))
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
 	}
 
 	var req domain.LoanStatusUpdateRequest
 	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
-		return nil, fmt.Errorf("failed to parse status update request: %v", err)
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse status update request: %v", err)
 	}
 
 	// Get existing loan application
 	loanKey := fmt.Sprintf("LOAN_%s", req.LoanID)
 	var loanApp domain.LoanApplication
 	if err := h.persistenceService.Get(stub, loanKey, &loanApp); err != nil {
-		return nil, fmt.Errorf("loan application not found: %v", err)
+		return nil, fmt.Errorf("loan application not found: %w", err)
+	}
+	if err := services.CheckExpectedVersion("LoanApplication", req.LoanID, req.ExpectedVersion, loanApp.Version); err != nil {
+		return nil, err
+	}
+
+	if err := checkComplianceHold(&loanApp); err != nil {
+		return nil, err
 	}
 
 	// Validate status transition
 	if err := validation.ValidateStatusTransition(string(loanApp.Status), string(req.NewStatus), "LoanApplication"); err != nil {
-		return nil, fmt.Errorf("invalid status transition: %v", err)
+		return nil, fmt.Errorf("invalid status transition: %w", err)
+	}
+
+	// Disbursement must go through DisburseLoan so funding records are kept
+	if req.NewStatus == validation.LoanStatusDisbursed {
+		return nil, fmt.Errorf("loans are disbursed via DisburseLoan")
+	}
+
+	// Cancellation must go through CancelApplication so the initiator and reason are kept
+	if req.NewStatus == validation.LoanStatusCancelled {
+		return nil, fmt.Errorf("applications are cancelled via CancelApplication")
+	}
+
+	// Arrears transitions must go through MarkLoanDelinquent and MarkLoanDefaulted so days past due are kept
+	if req.NewStatus == validation.LoanStatusDelinquent {
+		return nil, fmt.Errorf("loans are marked delinquent via MarkLoanDelinquent")
+	}
+	if req.NewStatus == validation.LoanStatusDefaulted {
+		return nil, fmt.Errorf("loans are marked defaulted via MarkLoanDefaulted")
+	}
+
+	// Without decided terms there is nothing to approve; ApproveLoan sets the amount and rate
+	if req.NewStatus == validation.LoanStatusApproved && (loanApp.ApprovedAmount == nil || loanApp.InterestRate == nil) {
+		return nil, fmt.Errorf("loans without approved terms are approved via ApproveLoan")
+	}
+
+	// Credit review and approval wait on the underwriting checklist of the loan's type and amount band
+	if req.NewStatus == validation.LoanStatusCreditApproval || req.NewStatus == validation.LoanStatusApproved {
+		if err := checkUnderwritingChecklist(stub, h.persistenceService, &loanApp); err != nil {
+			return nil, err
+		}
 	}
 
 	// Record history
-	if err := h.recordLoanHistory(stub, req.LoanID, "STATUS_UPDATE", "status", string(loanApp.Status), string(req.NewStatus), req.ActorID); err != nil {
+	if err := recordLoanHistory(stub, h.persistenceService, req.LoanID, "STATUS_UPDATE", "status", string(loanApp.Status), string(req.NewStatus), req.ActorID); err != nil {
+		return nil, err
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
 		return nil, err
 	}
 
 	// Update loan application
+	previousStatus := loanApp.Status
 	loanApp.Status = req.NewStatus
-	loanApp.Notes = req.Notes
-	loanApp.LastUpdated = time.Now()
+	loanApp.LastUpdated = now
 	loanApp.LastUpdatedBy = req.ActorID
 
 	// Store updated loan application
-	if err := h.persistenceService.Put(stub, loanKey, &loanApp); err != nil {
-		return nil, fmt.Errorf("failed to update loan application: %v", err)
+	if err := putLoanApplication(stub, h.persistenceService, &loanApp); err != nil {
+		return nil, fmt.Errorf("failed to update loan application: %w", err)
+	}
+
+	// Commentary on a status change is kept as an entity note rather than overwriting the loan's notes
+	if strings.TrimSpace(req.Notes) != "" {
+		note := &services.EntityNote{
+			EntityID:   loanApp.LoanID,
+			EntityType: "LoanApplication",
+			Text:       req.Notes,
+			Visibility: string(validation.NoteVisibilityInternal),
+			AuthorID:   req.ActorID,
+		}
+		if err := services.NewEntityNoteService().AddNote(stub, note); err != nil {
+			return nil, fmt.Errorf("failed to record status note: %w", err)
+		}
 	}
 
 	// Emit appropriate event based on status
 	switch req.NewStatus {
 	case validation.LoanStatusApproved:
 		if err := h.eventService.EmitLoanApproved(stub, &loanApp, req.ActorID); err != nil {
-			return nil, fmt.Errorf("failed to emit approved event: %v", err)
+			return nil, fmt.Errorf("failed to emit approved event: %w", err)
 		}
 	case validation.LoanStatusRejected:
 		if err := h.eventService.EmitLoanRejected(stub, &loanApp, req.ActorID); err != nil {
-			return nil, fmt.Errorf("failed to emit rejected event: %v", err)
+			return nil, fmt.Errorf("failed to emit rejected event: %w", err)
 		}
-	case validation.LoanStatusDisbursed:
-		if err := h.eventService.EmitLoanDisbursed(stub, &loanApp, req.ActorID); err != nil {
-			return nil, fmt.Errorf("failed to emit disbursed event: %v", err)
+	default:
+		if err := h.eventService.EmitLoanStatusUpdated(stub, &loanApp, string(previousStatus), req.ActorID); err != nil {
+			return nil, fmt.Errorf("failed to emit status updated event: %w", err)
 		}
 	}
 
@@ -156,7 +379,7 @@ func (h *LoanApplicationHandler) UpdateLoanStatus(stub shim.ChaincodeStubInterfa
 // GetLoanApplication retrieves a loan application by ID
 func (h *LoanApplicationHandler) GetLoanApplication(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
 	if len(args) != 1 {
-		return nil, fmt.Errorf("incorrect number of arguments. Expected 1, got %d", len(args))
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
 	}
 
 	loanID := args[0]
@@ -164,22 +387,49 @@ func (h *LoanApplicationHandler) GetLoanApplication(stub shim.ChaincodeStubInter
 
 	var loanApp domain.LoanApplication
 	if err := h.persistenceService.Get(stub, loanKey, &loanApp); err != nil {
-		return nil, fmt.Errorf("loan application not found: %v", err)
+		return nil, fmt.Errorf("loan application not found: %w", err)
 	}
 
 	return json.Marshal(&loanApp)
 }
 
-// GetLoanHistory retrieves the history of a loan application
+// GetLoanHistory retrieves the history of a loan application. Roles without the full view, see
+// domain.LoanHistoryViews, get the status changes only. With the RECONCILE mode as a second
+// argument, roles with the full view get a services.HistoryReconciliation of the recorded history
+// against the ledger history of the loan record instead.
 func (h *LoanApplicationHandler) GetLoanHistory(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
-	if len(args) != 1 {
-		return nil, fmt.Errorf("incorrect number of arguments. Expected 1, got %d", len(args))
+	if len(args) != 1 && len(args) != 2 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1 or 2, got %d", len(args))
+	}
+	reconcile := len(args) == 2
+	if reconcile && args[1] != services.HistoryModeReconcile {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid history mode: %s", args[1])
 	}
 
 	loanID := args[0]
 	history, err := h.getEntityHistory(stub, loanID)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get loan history: %v", err)
+		return nil, fmt.Errorf("failed to get loan history: %w", err)
+	}
+
+	// An identity whose role cannot be read fails closed to milestones
+	role, _ := services.InvokerRole(stub)
+	milestonesOnly := domain.LoanHistoryViewForRole(role) == domain.LoanHistoryViewMilestones
+
+	if reconcile {
+		// Reconciling against milestones alone would flag every other change as unrecorded
+		if milestonesOnly {
+			return nil, services.NewChaincodeError(services.ErrCodeAccessDenied, "", "history reconciliation requires the full loan history view")
+		}
+		reconciliation, err := services.ReconcileHistory(stub, loanID, fmt.Sprintf("LOAN_%s", loanID), history)
+		if err != nil {
+			return nil, fmt.Errorf("failed to reconcile loan history: %w", err)
+		}
+		return json.Marshal(reconciliation)
+	}
+
+	if milestonesOnly {
+		history = statusMilestones(history)
 	}
 
 	return json.Marshal(history)
@@ -188,31 +438,111 @@ func (h *LoanApplicationHandler) GetLoanHistory(stub shim.ChaincodeStubInterface
 // ApproveLoan approves a loan application
 func (h *LoanApplicationHandler) ApproveLoan(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
 	if len(args) != 1 {
-		return nil, fmt.Errorf("incorrect number of arguments. Expected 1, got %d", len(args))
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
 	}
 
 	var req domain.LoanApprovalRequest
 	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
-		return nil, fmt.Errorf("failed to parse approval request: %v", err)
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse approval request: %v", err)
 	}
 
 	// Get existing loan application
 	loanKey := fmt.Sprintf("LOAN_%s", req.LoanID)
 	var loanApp domain.LoanApplication
 	if err := h.persistenceService.Get(stub, loanKey, &loanApp); err != nil {
-		return nil, fmt.Errorf("loan application not found: %v", err)
+		return nil, fmt.Errorf("loan application not found: %w", err)
+	}
+	if err := services.CheckExpectedVersion("LoanApplication", req.LoanID, req.ExpectedVersion, loanApp.Version); err != nil {
+		return nil, err
+	}
+
+	if err := checkComplianceHold(&loanApp); err != nil {
+		return nil, err
+	}
+
+	// Another underwriter's live claim means they are working the application
+	if err := h.lockService.CheckLock(stub, "LoanApplication", req.LoanID, req.ActorID); err != nil {
+		return nil, err
 	}
 
 	// Validate current status allows approval
 	if loanApp.Status != validation.LoanStatusCreditApproval {
-		return nil, fmt.Errorf("loan cannot be approved from current status: %s", loanApp.Status)
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "loan cannot be approved from current status: %s", loanApp.Status)
+	}
+
+	if err := checkUnderwritingChecklist(stub, h.persistenceService, &loanApp); err != nil {
+		return nil, err
+	}
+
+	// The approved amount is held in the currency's minor units and checked against the
+	// base-currency limits at the current FX fixing, which is recorded with the decision
+	currency := loanCurrency(&loanApp)
+	approved := utils.NewMoney(req.ApprovedAmount, currency)
+	baseApproved, conversion, err := h.indexRateService.ConvertToBase(stub, approved)
+	if err != nil {
+		return nil, err
+	}
+	baseApprovedAmount := baseApproved.Float64()
+
+	// Check loan-to-value against the loan's active collateral
+	collateralValue, err := getCollateralValue(stub, h.persistenceService, req.LoanID)
+	if err != nil {
+		return nil, fmt.Errorf("failed to value collateral: %w", err)
+	}
+	ltv, err := validation.ValidateLoanToValue(baseApprovedAmount, collateralValue, loanApp.LoanType)
+	if err != nil {
+		return nil, fmt.Errorf("loan-to-value check failed: %w", err)
+	}
+	if collateralValue > 0 {
+		loanApp.LoanToValue = &ltv
+	}
+
+	// The approved amount is held to the customer's current risk tier bound, or to the exception
+	// the application relies on
+	riskTier, err := h.customerVerifier.CustomerRiskTier(stub, loanApp.CustomerID)
+	if err != nil {
+		return nil, err
+	}
+	amountExceptionID := req.AmountExceptionID
+	if amountExceptionID == "" {
+		amountExceptionID = loanApp.AmountExceptionID
+	}
+	amountExceptionID, err = checkLoanAmountBound(stub, h.persistenceService, loanApp.CustomerID, riskTier, loanApp.LoanType, baseApprovedAmount, loanApp.LoanID, amountExceptionID)
+	if err != nil {
+		return nil, fmt.Errorf("invalid approved amount: %w", err)
 	}
+	loanApp.AmountExceptionID = amountExceptionID
+
+	// Price variable-rate loans off the current index fixing
+	interestRate := req.InterestRate
+	if req.RateIndex != "" {
+		indexRate, err := h.indexRateService.GetCurrentRate(stub, req.RateIndex)
+		if err != nil {
+			return nil, fmt.Errorf("failed to price loan: %w", err)
+		}
+		interestRate = indexRate.Rate + req.RateMargin
+		loanApp.RateIndex = req.RateIndex
+		loanApp.RateMargin = &req.RateMargin
+		loanApp.IndexRateDate = indexRate.EffectiveDate
+	}
+
+	// The rate is held to the loan type's rate policy for the customer's risk tier
+	ratePolicyVersion, err := checkInterestRatePolicy(stub, h.persistenceService, h.indexRateService, loanApp.LoanType, riskTier, interestRate)
+	if err != nil {
+		return nil, fmt.Errorf("invalid interest rate: %w", err)
+	}
+	loanApp.RatePolicyVersion = ratePolicyVersion
 
 	// Update loan application with approval details
-	now := time.Now()
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
 	loanApp.Status = validation.LoanStatusApproved
-	loanApp.ApprovedAmount = &req.ApprovedAmount
-	loanApp.InterestRate = &req.InterestRate
+	loanApp.Currency = currency
+	loanApp.ApprovedAmount = &approved
+	loanApp.Conversion = conversion
+	loanApp.InterestRate = &interestRate
 	loanApp.RiskScore = &req.RiskScore
 	loanApp.DecisionDate = &now
 	loanApp.Notes = req.Notes
@@ -220,18 +550,43 @@ func (h *LoanApplicationHandler) ApproveLoan(stub shim.ChaincodeStubInterface, a
 	loanApp.LastUpdatedBy = req.ActorID
 
 	// Store updated loan application
-	if err := h.persistenceService.Put(stub, loanKey, &loanApp); err != nil {
-		return nil, fmt.Errorf("failed to update loan application: %v", err)
+	if err := putLoanApplication(stub, h.persistenceService, &loanApp); err != nil {
+		return nil, fmt.Errorf("failed to update loan application: %w", err)
+	}
+
+	// The decision completes the decider's claim
+	if err := h.lockService.ReleaseHeld(stub, "LoanApplication", req.LoanID, req.ActorID); err != nil {
+		return nil, err
+	}
+
+	// Generate the repayment schedule from the approval date
+	if _, err := h.scheduleService.GenerateSchedule(stub, &loanApp, now); err != nil {
+		return nil, err
 	}
 
 	// Record history
-	if err := h.recordLoanHistory(stub, req.LoanID, "APPROVAL", "status", string(validation.LoanStatusCreditApproval), string(validation.LoanStatusApproved), req.ActorID); err != nil {
+	if err := recordLoanHistory(stub, h.persistenceService, req.LoanID, "APPROVAL", "status", string(validation.LoanStatusCreditApproval), string(validation.LoanStatusApproved), req.ActorID); err != nil {
 		return nil, err
 	}
+	if ratePolicyVersion > 0 {
+		if err := recordLoanHistory(stub, h.persistenceService, req.LoanID, "APPROVAL", "ratePolicyVersion", "", strconv.Itoa(ratePolicyVersion), req.ActorID); err != nil {
+			return nil, err
+		}
+	}
+	if conversion != nil {
+		conversionJSON, _ := utils.MarshalJSONString(conversion)
+		if err := recordLoanHistory(stub, h.persistenceService, req.LoanID, "APPROVAL", "conversion", "", conversionJSON, req.ActorID); err != nil {
+			return nil, err
+		}
+	}
 
 	// Emit event
 	if err := h.eventService.EmitLoanApproved(stub, &loanApp, req.ActorID); err != nil {
-		return nil, fmt.Errorf("failed to emit event: %v", err)
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	if err := h.sampleForQA(stub, &loanApp, validation.QADecisionLoanApproval, req.ActorID, now); err != nil {
+		return nil, err
 	}
 
 	return json.Marshal(&loanApp)
@@ -240,23 +595,38 @@ func (h *LoanApplicationHandler) ApproveLoan(stub shim.ChaincodeStubInterface, a
 // RejectLoan rejects a loan application
 func (h *LoanApplicationHandler) RejectLoan(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
 	if len(args) != 1 {
-		return nil, fmt.Errorf("incorrect number of arguments. Expected 1, got %d", len(args))
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
 	}
 
 	var req domain.LoanRejectionRequest
 	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
-		return nil, fmt.Errorf("failed to parse rejection request: %v", err)
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse rejection request: %v", err)
 	}
 
 	// Get existing loan application
 	loanKey := fmt.Sprintf("LOAN_%s", req.LoanID)
 	var loanApp domain.LoanApplication
 	if err := h.persistenceService.Get(stub, loanKey, &loanApp); err != nil {
-		return nil, fmt.Errorf("loan application not found: %v", err)
+		return nil, fmt.Errorf("loan application not found: %w", err)
+	}
+	if err := services.CheckExpectedVersion("LoanApplication", req.LoanID, req.ExpectedVersion, loanApp.Version); err != nil {
+		return nil, err
+	}
+
+	if err := checkComplianceHold(&loanApp); err != nil {
+		return nil, err
+	}
+
+	// Another underwriter's live claim means they are working the application
+	if err := h.lockService.CheckLock(stub, "LoanApplication", req.LoanID, req.ActorID); err != nil {
+		return nil, err
 	}
 
 	// Update loan application with rejection details
-	now := time.Now()
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
 	loanApp.Status = validation.LoanStatusRejected
 	loanApp.DecisionDate = &now
 	loanApp.Notes = req.Reason
@@ -264,110 +634,609 @@ func (h *LoanApplicationHandler) RejectLoan(stub shim.ChaincodeStubInterface, ar
 	loanApp.LastUpdatedBy = req.ActorID
 
 	// Store updated loan application
-	if err := h.persistenceService.Put(stub, loanKey, &loanApp); err != nil {
-		return nil, fmt.Errorf("failed to update loan application: %v", err)
+	if err := putLoanApplication(stub, h.persistenceService, &loanApp); err != nil {
+		return nil, fmt.Errorf("failed to update loan application: %w", err)
+	}
+
+	// The decision completes the decider's claim
+	if err := h.lockService.ReleaseHeld(stub, "LoanApplication", req.LoanID, req.ActorID); err != nil {
+		return nil, err
 	}
 
 	// Record history
-	if err := h.recordLoanHistory(stub, req.LoanID, "REJECTION", "status", string(loanApp.Status), string(validation.LoanStatusRejected), req.ActorID); err != nil {
+	if err := recordLoanHistory(stub, h.persistenceService, req.LoanID, "REJECTION", "status", string(loanApp.Status), string(validation.LoanStatusRejected), req.ActorID); err != nil {
 		return nil, err
 	}
 
 	// Emit event
 	if err := h.eventService.EmitLoanRejected(stub, &loanApp, req.ActorID); err != nil {
-		return nil, fmt.Errorf("failed to emit event: %v", err)
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	if err := h.sampleForQA(stub, &loanApp, validation.QADecisionLoanRejection, req.ActorID, now); err != nil {
+		return nil, err
 	}
 
 	return json.Marshal(&loanApp)
 }
 
-// QueryLoansByStatus queries loans by status
-func (h *LoanApplicationHandler) QueryLoansByStatus(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+// RepriceLoan resets a variable-rate loan's interest rate to the current index fixing plus margin
+func (h *LoanApplicationHandler) RepriceLoan(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
 	if len(args) != 1 {
-		return nil, fmt.Errorf("incorrect number of arguments. Expected 1, got %d", len(args))
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.LoanRepriceRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse reprice request: %v", err)
+	}
+
+	// Get existing loan application
+	loanKey := fmt.Sprintf("LOAN_%s", req.LoanID)
+	var loanApp domain.LoanApplication
+	if err := h.persistenceService.Get(stub, loanKey, &loanApp); err != nil {
+		return nil, fmt.Errorf("loan application not found: %w", err)
+	}
+	if err := services.CheckExpectedVersion("LoanApplication", req.LoanID, req.ExpectedVersion, loanApp.Version); err != nil {
+		return nil, err
+	}
+
+	if loanApp.RateIndex == "" || loanApp.RateMargin == nil {
+		return nil, fmt.Errorf("loan %s is not a variable-rate loan", req.LoanID)
+	}
+	if loanApp.Status != validation.LoanStatusApproved && loanApp.Status != validation.LoanStatusDisbursed && loanApp.Status != validation.LoanStatusDelinquent {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "loan cannot be repriced in current status: %s", loanApp.Status)
+	}
+
+	indexRate, err := h.indexRateService.GetCurrentRate(stub, loanApp.RateIndex)
+	if err != nil {
+		return nil, fmt.Errorf("failed to reprice loan: %w", err)
+	}
+	if indexRate.EffectiveDate == loanApp.IndexRateDate {
+		return json.Marshal(&loanApp) // Already priced off this fixing
+	}
+
+	previousRate := 0.0
+	if loanApp.InterestRate != nil {
+		previousRate = *loanApp.InterestRate
+	}
+	newRate := indexRate.Rate + *loanApp.RateMargin
+
+	// Record history
+	if err := recordLoanHistory(stub, h.persistenceService, req.LoanID, "REPRICE", "interestRate", fmt.Sprintf("%.4f", previousRate), fmt.Sprintf("%.4f", newRate), req.ActorID); err != nil {
+		return nil, err
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	loanApp.InterestRate = &newRate
+	loanApp.IndexRateDate = indexRate.EffectiveDate
+	loanApp.LastUpdated = now
+	loanApp.LastUpdatedBy = req.ActorID
+
+	if err := putLoanApplication(stub, h.persistenceService, &loanApp); err != nil {
+		return nil, fmt.Errorf("failed to update loan application: %w", err)
+	}
+
+	// Emit event
+	if err := h.eventService.EmitLoanRepriced(stub, &loanApp, previousRate, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(&loanApp)
+}
+
+// QueryLoansByStatus returns a page of loan applications in a status, e.g. an underwriting work queue.
+// Args: status [, pageSize [, bookmark]]
+func (h *LoanApplicationHandler) QueryLoansByStatus(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) < 1 || len(args) > 3 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1 to 3, got %d", len(args))
 	}
 
 	status := args[0]
 	
 	// Validate status
 	if err := validation.ValidateLoanApplicationStatus(status); err != nil {
-		return nil, fmt.Errorf("invalid loan status: %v", err)
+		return nil, fmt.Errorf("invalid loan status: %w", err)
+	}
+
+	pageSize, bookmark, err := services.ParsePageArgs(args[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	entries, nextBookmark, err := h.persistenceService.GetPageByPartialCompositeKeys(stub, "LOAN_BY_STATUS", [][]string{{status}}, pageSize, bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loans by status: %w", err)
+	}
+
+	loans := []domain.LoanApplication{}
+	for _, entry := range entries {
+		var loan domain.LoanApplication
+		if err := h.persistenceService.Get(stub, fmt.Sprintf("LOAN_%s", string(entry.Value)), &loan); err != nil {
+			continue // Skip if loan not found
+		}
+
+		// Skip entries left behind by a status change earlier in the same transaction, and
+		// exclude sandbox loans from production status reporting
+		if string(loan.Status) != status || loan.Sandbox {
+			continue
+		}
+
+		loans = append(loans, loan)
+	}
+
+	// Show who is already working each loan so the queue can route around them
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	claims := map[string]domain.LoanClaim{}
+	for _, loan := range loans {
+		lock, err := h.lockService.GetLock(stub, "LoanApplication", loan.LoanID)
+		if err != nil {
+			return nil, err
+		}
+		if lock != nil && lock.Live(now) {
+			claims[loan.LoanID] = domain.LoanClaim{HolderID: lock.HolderID, ClaimedDate: lock.ClaimedDate, ExpiresDate: lock.ExpiresDate}
+		}
+	}
+
+	return json.Marshal(&domain.LoanQueryResult{Loans: loans, Count: len(loans), Bookmark: nextBookmark, Claims: claims})
+}
+
+// QueryLoansByCustomer returns a page of a customer's loan applications as primary borrower.
+// Args: customerID [, pageSize [, bookmark]]
+func (h *LoanApplicationHandler) QueryLoansByCustomer(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) < 1 || len(args) > 3 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1 to 3, got %d", len(args))
+	}
+
+	customerID := args[0]
+
+	pageSize, bookmark, err := services.ParsePageArgs(args[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	entries, nextBookmark, err := h.persistenceService.GetPageByPartialCompositeKeys(stub, "LOAN_BY_CUSTOMER", [][]string{{customerID}}, pageSize, bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loans by customer: %w", err)
+	}
+
+	loans := []domain.LoanApplication{}
+	for _, entry := range entries {
+		var loan domain.LoanApplication
+		if err := h.persistenceService.Get(stub, fmt.Sprintf("LOAN_%s", string(entry.Value)), &loan); err != nil {
+			continue // Skip if loan not found
+		}
+
+		loans = append(loans, loan)
+	}
+
+	return json.Marshal(&domain.LoanQueryResult{Loans: loans, Count: len(loans), Bookmark: nextBookmark})
+}
+
+// QueryLoansByChannel returns a page of the loan applications submitted through an origination
+// channel. Args: channel [, pageSize [, bookmark]]
+func (h *LoanApplicationHandler) QueryLoansByChannel(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) < 1 || len(args) > 3 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1 to 3, got %d", len(args))
+	}
+
+	channel := args[0]
+	if err := validation.ValidateOriginationChannel(channel); err != nil {
+		return nil, fmt.Errorf("invalid channel: %w", err)
+	}
+
+	pageSize, bookmark, err := services.ParsePageArgs(args[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	entries, nextBookmark, err := h.persistenceService.GetPageByPartialCompositeKeys(stub, "LOAN_BY_CHANNEL", [][]string{{channel}}, pageSize, bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loans by channel: %w", err)
+	}
+
+	loans := []domain.LoanApplication{}
+	for _, entry := range entries {
+		var loan domain.LoanApplication
+		if err := h.persistenceService.Get(stub, fmt.Sprintf("LOAN_%s", string(entry.Value)), &loan); err != nil {
+			continue // Skip if loan not found
+		}
+
+		loans = append(loans, loan)
+	}
+
+	return json.Marshal(&domain.LoanQueryResult{Loans: loans, Count: len(loans), Bookmark: nextBookmark})
+}
+
+// QueryLoansByDateRange returns a page of loan applications submitted between two dates inclusive,
+// oldest first. Args: fromDate, toDate (YYYY-MM-DD) [, pageSize [, bookmark]]
+func (h *LoanApplicationHandler) QueryLoansByDateRange(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) < 2 || len(args) > 4 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 2 to 4, got %d", len(args))
+	}
+
+	fromDate, err := time.Parse(loanIndexDateFormat, args[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid from date %s: %w", args[0], err)
+	}
+	toDate, err := time.Parse(loanIndexDateFormat, args[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid to date %s: %w", args[1], err)
+	}
+	if toDate.Before(fromDate) {
+		return nil, fmt.Errorf("to date %s is before from date %s", args[1], args[0])
+	}
+	if days := int(toDate.Sub(fromDate).Hours()/24) + 1; days > config.MaxQueryRangeDays {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "date range of %d days exceeds maximum of %d", days, config.MaxQueryRangeDays)
+	}
+
+	pageSize, bookmark, err := services.ParsePageArgs(args[2:])
+	if err != nil {
+		return nil, err
+	}
+
+	// One partial key per day bucket, in ascending order
+	var days [][]string
+	for day := fromDate; !day.After(toDate); day = day.AddDate(0, 0, 1) {
+		days = append(days, []string{day.Format(loanIndexDateFormat)})
+	}
+
+	entries, nextBookmark, err := h.persistenceService.GetPageByPartialCompositeKeys(stub, "LOAN_BY_DATE", days, pageSize, bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loans by date: %w", err)
+	}
+
+	loans := []domain.LoanApplication{}
+	for _, entry := range entries {
+		var loan domain.LoanApplication
+		if err := h.persistenceService.Get(stub, fmt.Sprintf("LOAN_%s", string(entry.Value)), &loan); err != nil {
+			continue // Skip if loan not found
+		}
+
+		// Sandbox loans are excluded from production reporting
+		if loan.Sandbox {
+			continue
+		}
+
+		loans = append(loans, loan)
 	}
 
-	// Query loans by status using composite key
-	iterator, err := stub.GetStateByPartialCompositeKey("LOAN_STATUS", []string{status})
+	return json.Marshal(&domain.LoanQueryResult{Loans: loans, Count: len(loans), Bookmark: nextBookmark})
+}
+
+// QueryLoansByParty queries loan applications a customer participates in under any role
+func (h *LoanApplicationHandler) QueryLoansByParty(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	customerID := args[0]
+
+	iterator, err := stub.GetStateByPartialCompositeKey("CUSTOMER_LOAN_PARTY", []string{customerID})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get loans by status: %v", err)
+		return nil, fmt.Errorf("failed to get loans by party: %w", err)
 	}
 	defer iterator.Close()
 
-	var loans []domain.LoanApplication
+	partyLoans := []domain.PartyLoan{}
 	for iterator.HasNext() {
 		response, err := iterator.Next()
 		if err != nil {
-			return nil, fmt.Errorf("failed to iterate loans: %v", err)
+			return nil, fmt.Errorf("failed to iterate party loans: %w", err)
+		}
+
+		var party domain.LoanParty
+		if err := json.Unmarshal(response.Value, &party); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal loan party: %w", err)
+		}
+		_, keyParts, err := stub.SplitCompositeKey(response.Key)
+		if err != nil || len(keyParts) != 2 {
+			return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid loan party index key: %s", response.Key)
 		}
 
 		var loan domain.LoanApplication
-		if err := json.Unmarshal(response.Value, &loan); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal loan: %v", err)
+		if err := h.persistenceService.Get(stub, fmt.Sprintf("LOAN_%s", keyParts[1]), &loan); err != nil {
+			continue // Skip if loan not found
 		}
 
-		loans = append(loans, loan)
+		partyLoans = append(partyLoans, domain.PartyLoan{
+			LoanID:          loan.LoanID,
+			CustomerID:      party.CustomerID,
+			Role:            party.Role,
+			LiabilityShare:  party.LiabilityShare,
+			LoanType:        loan.LoanType,
+			RequestedAmount: loan.RequestedAmount.Float64(),
+			Status:          loan.Status,
+		})
 	}
 
-	return json.Marshal(loans)
+	return json.Marshal(partyLoans)
 }
 
-// QueryLoansByCustomer queries loans by customer ID
-func (h *LoanApplicationHandler) QueryLoansByCustomer(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+// GetCustomerHoldings summarises a customer's loans as primary borrower and the loan transactions
+// posted in the last CustomerRiskTransactionWindowDays, for the customer chaincode's risk rating.
+// Balances are totalled per currency; the transaction volume is converted to the base currency at
+// each loan's decision-time FX fixing. Sandbox loans are excluded.
+func (h *LoanApplicationHandler) GetCustomerHoldings(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
 	if len(args) != 1 {
-		return nil, fmt.Errorf("incorrect number of arguments. Expected 1, got %d", len(args))
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
 	}
 
 	customerID := args[0]
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	windowStart := now.AddDate(0, 0, -config.CustomerRiskTransactionWindowDays)
 
-	// Query loans by customer using partial composite key
-	iterator, err := stub.GetStateByPartialCompositeKey("CUSTOMER_LOAN", []string{customerID})
+	iterator, err := stub.GetStateByPartialCompositeKey("LOAN_BY_CUSTOMER", []string{customerID})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get loans by customer: %v", err)
+		return nil, fmt.Errorf("failed to get loans by customer: %w", err)
 	}
 	defer iterator.Close()
 
-	var loans []domain.LoanApplication
+	holdings := &interfaces.CustomerHoldings{
+		CustomerID:          customerID,
+		LoanTypes:           []string{},
+		OutstandingBalances: utils.CurrencyTotals{},
+		WindowDays:          config.CustomerRiskTransactionWindowDays,
+	}
+	loanTypes := make(map[string]bool)
 	for iterator.HasNext() {
 		response, err := iterator.Next()
 		if err != nil {
-			return nil, fmt.Errorf("failed to iterate customer loans: %v", err)
+			return nil, fmt.Errorf("failed to iterate loans: %w", err)
 		}
 
-		// Get the loan ID from the index
-		loanID := string(response.Value)
-		
-		// Get the actual loan application
-		loanKey := fmt.Sprintf("LOAN_%s", loanID)
 		var loan domain.LoanApplication
-		if err := h.persistenceService.Get(stub, loanKey, &loan); err != nil {
+		if err := h.persistenceService.Get(stub, fmt.Sprintf("LOAN_%s", string(response.Value)), &loan); err != nil {
 			continue // Skip if loan not found
 		}
+		if loan.Sandbox {
+			continue
+		}
 
-		loans = append(loans, loan)
+		switch loan.Status {
+		case validation.LoanStatusDisbursed, validation.LoanStatusDelinquent:
+			holdings.ActiveLoans++
+		case validation.LoanStatusDefaulted:
+			holdings.DefaultedLoans++
+		case validation.LoanStatusRejected, validation.LoanStatusCancelled:
+			continue // Never funded, so no holding or transactions
+		default:
+			holdings.PendingApplications++
+		}
+		if loan.Status == validation.LoanStatusDisbursed || loan.Status == validation.LoanStatusDelinquent || loan.Status == validation.LoanStatusDefaulted {
+			holdings.OutstandingBalances.Add(loan.OutstandingBalance)
+			if !loanTypes[loan.LoanType] {
+				loanTypes[loan.LoanType] = true
+				holdings.LoanTypes = append(holdings.LoanTypes, loan.LoanType)
+			}
+		}
+
+		txnIterator, err := stub.GetStateByPartialCompositeKey("LOAN_TRANSACTION", []string{loan.LoanID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get loan transactions: %w", err)
+		}
+		for txnIterator.HasNext() {
+			txnResponse, err := txnIterator.Next()
+			if err != nil {
+				txnIterator.Close()
+				return nil, fmt.Errorf("failed to iterate loan transactions: %w", err)
+			}
+			var txn domain.LoanTransaction
+			if err := json.Unmarshal(txnResponse.Value, &txn); err != nil {
+				txnIterator.Close()
+				return nil, fmt.Errorf("failed to unmarshal loan transaction: %w", err)
+			}
+			if txn.CreatedDate.Before(windowStart) {
+				continue
+			}
+			volume, err := baseCurrencyAmount(&loan, txn.Amount)
+			if err != nil {
+				txnIterator.Close()
+				return nil, err
+			}
+			holdings.RecentTransactionCount++
+			holdings.RecentTransactionVolume += volume
+		}
+		txnIterator.Close()
 	}
 
-	return json.Marshal(loans)
+	return json.Marshal(holdings)
 }
 
 // Helper methods
 
-func (h *LoanApplicationHandler) recordLoanHistory(stub shim.ChaincodeStubInterface, loanID, changeType, fieldName, previousValue, newValue, actorID string) error {
-	historyID := utils.GenerateID(config.HistoryPrefix)
+// baseCurrencyAmount converts an amount of a loan to the base currency at the FX fixing snapshotted
+// when the loan was decided
+func baseCurrencyAmount(loanApp *domain.LoanApplication, amount utils.Money) (float64, error) {
+	if amount.Currency() == config.BaseCurrency {
+		return amount.Float64(), nil
+	}
+	if loanApp.Conversion == nil || loanApp.Conversion.FromCurrency != amount.Currency() {
+		return 0, fmt.Errorf("loan %s has no FX fixing to convert its %s amounts to %s", loanApp.LoanID, amount.Currency(), config.BaseCurrency)
+	}
+	return loanServices.ConvertAmount(amount, loanApp.Conversion.Rate, config.BaseCurrency).Float64(), nil
+}
+
+// buildLoanParties screens each additional party and assigns the primary borrower the
+// liability share not taken by co-borrowers
+func (h *LoanApplicationHandler) buildLoanParties(stub shim.ChaincodeStubInterface, req *domain.LoanApplicationRequest) ([]domain.LoanParty, error) {
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	seen := map[string]bool{req.CustomerID: true}
+	primaryShare := 100.0
+
+	var additional []domain.LoanParty
+	for _, partyReq := range req.Parties {
+		if err := validation.ValidateLoanPartyRole(partyReq.Role); err != nil {
+			return nil, fmt.Errorf("invalid party role: %w", err)
+		}
+		if seen[partyReq.CustomerID] {
+			return nil, fmt.Errorf("customer %s is named more than once on the application", partyReq.CustomerID)
+		}
+		seen[partyReq.CustomerID] = true
+
+		if partyReq.LiabilityShare <= 0 || partyReq.LiabilityShare > 100 {
+			return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "liability share for %s must be greater than 0 and at most 100, got %.2f", partyReq.CustomerID, partyReq.LiabilityShare)
+		}
+
+		// Every party is held to the same KYC/AML/consent standard as the borrower
+		if _, err := h.customerVerifier.VerifyCustomer(stub, partyReq.CustomerID); err != nil {
+			return nil, fmt.Errorf("%s verification failed: %w", partyReq.Role, err)
+		}
+
+		role := validation.LoanPartyRole(partyReq.Role)
+		if role == validation.LoanPartyRoleCoBorrower {
+			primaryShare -= partyReq.LiabilityShare
+		}
+
+		additional = append(additional, domain.LoanParty{
+			CustomerID:     partyReq.CustomerID,
+			Role:           role,
+			LiabilityShare: roundToCents(partyReq.LiabilityShare),
+			ScreenedDate:   &now,
+		})
+	}
+
+	if primaryShare < balanceTolerance {
+		return nil, fmt.Errorf("co-borrower liability shares total %.2f, leaving no share for the primary borrower", 100-primaryShare)
+	}
+
+	primary := domain.LoanParty{
+		CustomerID:     req.CustomerID,
+		Role:           validation.LoanPartyRolePrimaryBorrower,
+		LiabilityShare: roundToCents(primaryShare),
+		ScreenedDate:   &now,
+	}
+
+	return append([]domain.LoanParty{primary}, additional...), nil
+}
+
+// loanIndexDateFormat is the day bucket used by the LOAN_BY_DATE index
+const loanIndexDateFormat = "2006-01-02"
+
+// checkComplianceHold rejects progressing a loan held by compliance
+func checkComplianceHold(loanApp *domain.LoanApplication) error {
+	if loanApp.ComplianceHold != nil {
+		return fmt.Errorf("loan %s is on compliance hold: %s", loanApp.LoanID, loanApp.ComplianceHold.Reason)
+	}
+	return nil
+}
+
+// putLoanApplication stores a loan application and keeps the LOAN_BY_CUSTOMER, LOAN_BY_CHANNEL,
+// LOAN_BY_DATE and LOAN_BY_STATUS indexes in step with it, and advances the application's version. Every write of a loan application must go through here.
+func putLoanApplication(stub shim.ChaincodeStubInterface, ps *services.PersistenceService, loanApp *domain.LoanApplication) error {
+	loanKey := fmt.Sprintf("LOAN_%s", loanApp.LoanID)
+
+	var previous domain.LoanApplication
+	existing := ps.Get(stub, loanKey, &previous) == nil
+	loanApp.Version = previous.Version + 1
+
+	if err := ps.Put(stub, loanKey, loanApp); err != nil {
+		return err
+	}
+
+	// Customer, channel and submission date never change, so index them once. Applications from
+	// before channels were captured have none.
+	if !existing {
+		if err := putLoanIndex(stub, "LOAN_BY_CUSTOMER", loanApp.CustomerID, loanApp.LoanID); err != nil {
+			return err
+		}
+		if loanApp.Channel != "" {
+			if err := putLoanIndex(stub, "LOAN_BY_CHANNEL", string(loanApp.Channel), loanApp.LoanID); err != nil {
+				return err
+			}
+		}
+		if err := putLoanIndex(stub, "LOAN_BY_DATE", loanApp.ApplicationDate.UTC().Format(loanIndexDateFormat), loanApp.LoanID); err != nil {
+			return err
+		}
+	}
+
+	// Move the status index entry and queue the change for core banking when the status changes
+	if existing && previous.Status == loanApp.Status {
+		return nil
+	}
+	if existing {
+		previousKey, err := stub.CreateCompositeKey("LOAN_BY_STATUS", []string{string(previous.Status), loanApp.LoanID})
+		if err != nil {
+			return fmt.Errorf("failed to create composite key: %w", err)
+		}
+		if err := stub.DelState(previousKey); err != nil {
+			return fmt.Errorf("failed to remove loan status index: %w", err)
+		}
+	}
+	if err := recordStatusSync(stub, ps, loanApp, string(previous.Status)); err != nil {
+		return err
+	}
+	return putLoanIndex(stub, "LOAN_BY_STATUS", string(loanApp.Status), loanApp.LoanID)
+}
+
+// putLoanIndex writes a loan ID under a two-part composite index key
+func putLoanIndex(stub shim.ChaincodeStubInterface, objectType, attribute, loanID string) error {
+	indexKey, err := stub.CreateCompositeKey(objectType, []string{attribute, loanID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := stub.PutState(indexKey, []byte(loanID)); err != nil {
+		return fmt.Errorf("failed to create %s index: %w", objectType, err)
+	}
+	return nil
+}
+
+// putLoanPartyIndex indexes each party on a loan by customer
+func putLoanPartyIndex(stub shim.ChaincodeStubInterface, ps *services.PersistenceService, loanApp *domain.LoanApplication) error {
+	for _, party := range loanApp.Parties {
+		partyKey, err := stub.CreateCompositeKey("CUSTOMER_LOAN_PARTY", []string{party.CustomerID, loanApp.LoanID})
+		if err != nil {
+			return fmt.Errorf("failed to create composite key: %w", err)
+		}
+		if err := ps.Put(stub, partyKey, party); err != nil {
+			return fmt.Errorf("failed to create loan party index: %w", err)
+		}
+	}
+	return nil
+}
+
+// sampleForQA queues a share of credit decisions for quality review; sandbox loans are not sampled
+func (h *LoanApplicationHandler) sampleForQA(stub shim.ChaincodeStubInterface, loanApp *domain.LoanApplication, decisionType validation.QADecisionType, actorID string, decidedDate time.Time) error {
+	if loanApp.Sandbox {
+		return nil
+	}
+	if _, err := h.qaService.SampleDecision(stub, string(decisionType), loanApp.LoanID, "LoanApplication", string(loanApp.Status), actorID, decidedDate); err != nil {
+		return fmt.Errorf("failed to sample decision for quality review: %w", err)
+	}
+	return nil
+}
+
+// recordLoanHistory records a change to a loan application in its history
+func recordLoanHistory(stub shim.ChaincodeStubInterface, persistenceService *services.PersistenceService, loanID, changeType, fieldName, previousValue, newValue, actorID string) error {
+	historyID, compositeKey, err := services.NewHistoryKey(stub, loanID)
+	if err != nil {
+		return err
+	}
 	txID := stub.GetTxID()
 
+	timestamp, err := services.TxTimeString(stub)
+	if err != nil {
+		return err
+	}
+
 	historyEntry := map[string]interface{}{
 		"historyID":     historyID,
 		"entityID":      loanID,
 		"entityType":    "LoanApplication",
-		"timestamp":     utils.GetCurrentTimeString(),
+		"timestamp":     timestamp,
 		"changeType":    changeType,
 		"fieldName":     fieldName,
 		"previousValue": previousValue,
@@ -376,18 +1245,34 @@ func (h *LoanApplicationHandler) recordLoanHistory(stub shim.ChaincodeStubInterf
 		"transactionID": txID,
 	}
 
-	compositeKey, err := stub.CreateCompositeKey("HISTORY", []string{loanID, historyID})
-	if err != nil {
-		return fmt.Errorf("failed to create composite key: %v", err)
+	return persistenceService.Put(stub, compositeKey, historyEntry)
+}
+
+// loanCurrency returns a loan's currency. Applications made before currencies were recorded are in
+// the base currency.
+func loanCurrency(loanApp *domain.LoanApplication) string {
+	if loanApp.Currency == "" {
+		return config.BaseCurrency
 	}
+	return loanApp.Currency
+}
 
-	return h.persistenceService.Put(stub, compositeKey, historyEntry)
+// statusMilestones keeps the history entries that record a status change
+func statusMilestones(history []interface{}) []interface{} {
+	milestones := []interface{}{}
+	for _, entry := range history {
+		fields, ok := entry.(map[string]interface{})
+		if ok && fields["fieldName"] == "status" {
+			milestones = append(milestones, entry)
+		}
+	}
+	return milestones
 }
 
 func (h *LoanApplicationHandler) getEntityHistory(stub shim.ChaincodeStubInterface, entityID string) ([]interface{}, error) {
 	iterator, err := stub.GetStateByPartialCompositeKey("HISTORY", []string{entityID})
 	if err != nil {
-		return nil, fmt.Errorf("failed to get history iterator: %v", err)
+		return nil, fmt.Errorf("failed to get history iterator: %w", err)
 	}
 	defer iterator.Close()
 
@@ -395,12 +1280,12 @@ func (h *LoanApplicationHandler) getEntityHistory(stub shim.ChaincodeStubInterfa
 	for iterator.HasNext() {
 		response, err := iterator.Next()
 		if err != nil {
-			return nil, fmt.Errorf("failed to iterate history: %v", err)
+			return nil, fmt.Errorf("failed to iterate history: %w", err)
 		}
 
 		var entry interface{}
 		if err := json.Unmarshal(response.Value, &entry); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal history entry: %v", err)
+			return nil, fmt.Errorf("failed to unmarshal history entry: %w", err)
 		}
 
 		history = append(history, entry)
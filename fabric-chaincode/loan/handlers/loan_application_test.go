@@ -0,0 +1,177 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/interfaces"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+func TestGetLoanHistoryReturnsEntriesInRecordedOrder(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleUnderwriter))
+	seedLoan(t, stub, "LOAN_H1", validation.LoanStatusSubmitted)
+	h := NewLoanApplicationHandler()
+	invocation := services.NewInvocation(stub)
+
+	// Several entries per transaction, across transactions recorded a day apart, so that neither
+	// the hashed history IDs nor the transaction IDs happen to sort in time order
+	recorded := []string{}
+	start := time.Date(2026, 3, 1, 9, 0, 0, 0, time.UTC)
+	for day, txID := range []string{"tx_c", "tx_a", "tx_b"} {
+		_, err := inTxAt(stub, txID, start.AddDate(0, 0, day), func() ([]byte, error) {
+			for i := 0; i < 4; i++ {
+				field := txID + "_" + string(rune('0'+i))
+				recorded = append(recorded, field)
+				if err := recordLoanHistory(invocation, h.persistenceService, "LOAN_H1", "UPDATE", field, "", "", "ACTOR_001"); err != nil {
+					return nil, err
+				}
+			}
+			return nil, nil
+		})
+		if err != nil {
+			t.Fatalf("failed to record history: %v", err)
+		}
+	}
+
+	payload, err := inTx(stub, "history", func() ([]byte, error) {
+		return h.GetLoanHistory(stub, []string{"LOAN_H1"})
+	})
+	if err != nil {
+		t.Fatalf("GetLoanHistory failed: %v", err)
+	}
+	var history []map[string]interface{}
+	if err := json.Unmarshal(payload, &history); err != nil {
+		t.Fatalf("failed to decode history: %v", err)
+	}
+
+	var fields []string
+	for _, entry := range history {
+		if field, _ := entry["fieldName"].(string); field != "" && field[:3] == "tx_" {
+			fields = append(fields, field)
+		}
+	}
+	if len(fields) != len(recorded) {
+		t.Fatalf("expected %d entries, got %v", len(recorded), fields)
+	}
+	for i := range recorded {
+		if fields[i] != recorded[i] {
+			t.Fatalf("expected history in recorded order %v, got %v", recorded, fields)
+		}
+	}
+}
+
+func TestGetLoanHistoryRedactsByRole(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleUnderwriter))
+	seedLoan(t, stub, "LOAN_R1", validation.LoanStatusCreditApproval)
+	h := NewLoanApplicationHandler()
+	invocation := services.NewInvocation(stub)
+	_, err := inTx(stub, "decide", func() ([]byte, error) {
+		if err := recordLoanHistory(invocation, h.persistenceService, "LOAN_R1", "APPROVAL", "status", "CREDIT_APPROVAL", "APPROVED", "ACTOR_002"); err != nil {
+			return nil, err
+		}
+		return nil, recordLoanHistory(invocation, h.persistenceService, "LOAN_R1", "REPRICE", "interestRate", "7.2500", "6.9000", "ACTOR_002")
+	})
+	if err != nil {
+		t.Fatalf("failed to record history: %v", err)
+	}
+
+	fieldsSeenBy := func(role validation.ActorRole) map[string]bool {
+		stub.Creator = newTestIdentity(t, string(role))
+		payload, err := inTx(stub, "history_"+string(role), func() ([]byte, error) {
+			return h.GetLoanHistory(stub, []string{"LOAN_R1"})
+		})
+		if err != nil {
+			t.Fatalf("GetLoanHistory as %s failed: %v", role, err)
+		}
+		var history []map[string]interface{}
+		if err := json.Unmarshal(payload, &history); err != nil {
+			t.Fatalf("failed to decode history: %v", err)
+		}
+		fields := map[string]bool{}
+		for _, entry := range history {
+			fields[entry["fieldName"].(string)] = true
+		}
+		return fields
+	}
+
+	// Pricing changes are withheld from introducers and customer-facing staff
+	for _, role := range []validation.ActorRole{validation.ActorRoleIntroducer, validation.ActorRoleCustomerServiceRep} {
+		if fields := fieldsSeenBy(role); !fields["status"] || fields["interestRate"] {
+			t.Errorf("expected %s to see status milestones only, got %v", role, fields)
+		}
+	}
+	for _, role := range []validation.ActorRole{validation.ActorRoleUnderwriter, validation.ActorRoleRegulator} {
+		if fields := fieldsSeenBy(role); !fields["status"] || !fields["interestRate"] {
+			t.Errorf("expected %s to see the full trail, got %v", role, fields)
+		}
+	}
+
+	// Reconciling the redacted view is refused as a permission failure
+	stub.Creator = newTestIdentity(t, string(validation.ActorRoleCustomerServiceRep))
+	_, err = inTx(stub, "reconcile", func() ([]byte, error) {
+		return h.GetLoanHistory(stub, []string{"LOAN_R1", services.HistoryModeReconcile})
+	})
+	expectErrorCode(t, err, services.ErrCodeAccessDenied)
+}
+
+// inCurrency gives a seeded loan another currency, with the FX fixing it was decided at
+func inCurrency(currency string, rate float64) func(*domain.LoanApplication) {
+	return func(loanApp *domain.LoanApplication) {
+		loanApp.Currency = currency
+		loanApp.RequestedAmount = utils.NewMoney(loanApp.RequestedAmount.Float64(), currency)
+		loanApp.Conversion = &domain.CurrencyConversion{FromCurrency: currency, ToCurrency: "USD", RateIndex: "FX_" + currency + "USD", Rate: rate}
+	}
+}
+
+func TestGetCustomerHoldingsTotalsEachCurrency(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	seedLoan(t, stub, "LOAN_HC1", validation.LoanStatusApproved, approvedTerms(1000, 6))
+	seedLoan(t, stub, "LOAN_HC2", validation.LoanStatusApproved, inCurrency("KWD", 3.25), approvedTerms(500.125, 6))
+	for _, loanID := range []string{"LOAN_HC1", "LOAN_HC2"} {
+		if _, err := disburse(t, stub, "disburse_"+loanID, loanID, getLoan(t, stub, loanID).ApprovedAmount.Float64()); err != nil {
+			t.Fatalf("disbursement of %s failed: %v", loanID, err)
+		}
+	}
+
+	holdings := func(txID string) (*interfaces.CustomerHoldings, error) {
+		payload, err := inTx(stub, txID, func() ([]byte, error) {
+			return NewLoanApplicationHandler().GetCustomerHoldings(stub, []string{"CUST_001"})
+		})
+		if err != nil {
+			return nil, err
+		}
+		var holdings interfaces.CustomerHoldings
+		if err := json.Unmarshal(payload, &holdings); err != nil {
+			t.Fatalf("failed to decode holdings: %v", err)
+		}
+		return &holdings, nil
+	}
+
+	// Balances are kept apart by currency; the volume is converted at each loan's fixing
+	result, err := holdings("holdings")
+	if err != nil {
+		t.Fatalf("GetCustomerHoldings failed: %v", err)
+	}
+	if result.ActiveLoans != 2 || len(result.OutstandingBalances) != 2 || result.OutstandingBalances["USD"].String() != "1000" || result.OutstandingBalances["KWD"].String() != "500.125" {
+		t.Errorf("expected 1000 USD and 500.125 KWD outstanding, got %+v", result)
+	}
+	if result.RecentTransactionCount != 2 || result.RecentTransactionVolume != 1000+1625.41 {
+		t.Errorf("expected 2 transactions worth 2625.41 USD, got %d worth %.2f", result.RecentTransactionCount, result.RecentTransactionVolume)
+	}
+
+	// A loan with no fixing to convert its amounts is refused rather than added in its own units
+	seedLoan(t, stub, "LOAN_HC3", validation.LoanStatusApproved, inCurrency("EUR", 1.08), approvedTerms(200, 6), func(loanApp *domain.LoanApplication) {
+		loanApp.Conversion = nil
+	})
+	if _, err := disburse(t, stub, "disburse_LOAN_HC3", "LOAN_HC3", 200); err != nil {
+		t.Fatalf("disbursement of LOAN_HC3 failed: %v", err)
+	}
+	if _, err := holdings("holdings_unconverted"); err == nil {
+		t.Errorf("expected holdings with an unconverted EUR loan to be refused")
+	}
+}
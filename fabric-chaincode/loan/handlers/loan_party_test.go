@@ -0,0 +1,138 @@
+package handlers
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+func TestSubmitLoanApplicationScreensEveryParty(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	unverified := eligibleCustomer("CUST_004", 10)
+	unverified.KYCStatus = string(validation.KYCStatusPending)
+	withCustomers(stub, eligibleCustomer("CUST_001", 10), eligibleCustomer("CUST_002", 10), eligibleCustomer("CUST_003", 10), unverified)
+	withCompliance(stub)
+
+	submit := func(txID string, parties ...domain.LoanPartyRequest) (*domain.LoanApplication, error) {
+		payload, err := inTx(stub, txID, func() ([]byte, error) {
+			return NewLoanApplicationHandler().SubmitLoanApplication(stub, []string{mustJSON(t, domain.LoanApplicationRequest{
+				CustomerID: "CUST_001", LoanType: "MORTGAGE", RequestedAmount: 250000, TermMonths: 300, Purpose: "Home purchase", Parties: parties, Channel: "BRANCH", ActorID: "ACTOR_005",
+			})})
+		})
+		if err != nil {
+			return nil, err
+		}
+		var loanApp domain.LoanApplication
+		if err := json.Unmarshal(payload, &loanApp); err != nil {
+			t.Fatalf("failed to decode loan: %v", err)
+		}
+		return &loanApp, nil
+	}
+	coBorrower := func(customerID string, share float64) domain.LoanPartyRequest {
+		return domain.LoanPartyRequest{CustomerID: customerID, Role: string(validation.LoanPartyRoleCoBorrower), LiabilityShare: share}
+	}
+	guarantor := domain.LoanPartyRequest{CustomerID: "CUST_003", Role: string(validation.LoanPartyRoleGuarantor), LiabilityShare: 100}
+
+	// Each party is screened like the borrower and named once, and co-borrowers leave the borrower a share
+	if _, err := submit("unverified_guarantor", domain.LoanPartyRequest{CustomerID: "CUST_004", Role: string(validation.LoanPartyRoleGuarantor), LiabilityShare: 100}); err == nil || !strings.Contains(err.Error(), "GUARANTOR verification failed") {
+		t.Errorf("expected an unverified guarantor to be refused, got %v", err)
+	}
+	if _, err := submit("unknown_party", coBorrower("CUST_404", 50)); err == nil {
+		t.Errorf("expected a co-borrower unknown to the customer chaincode to be refused")
+	}
+	if _, err := submit("borrower_twice", coBorrower("CUST_001", 50)); err == nil || !strings.Contains(err.Error(), "named more than once") {
+		t.Errorf("expected the borrower named again as co-borrower to be refused, got %v", err)
+	}
+	if _, err := submit("whole_share", coBorrower("CUST_002", 100)); err == nil || !strings.Contains(err.Error(), "leaving no share for the primary borrower") {
+		t.Errorf("expected a co-borrower taking the whole liability to be refused, got %v", err)
+	}
+	if _, err := submit("primary_role", domain.LoanPartyRequest{CustomerID: "CUST_002", Role: string(validation.LoanPartyRolePrimaryBorrower), LiabilityShare: 50}); err == nil {
+		t.Errorf("expected a second primary borrower to be refused")
+	}
+
+	loanApp, err := submit("joint", coBorrower("CUST_002", 40), guarantor)
+	if err != nil {
+		t.Fatalf("joint application failed: %v", err)
+	}
+	if len(loanApp.Parties) != 3 {
+		t.Fatalf("expected borrower, co-borrower and guarantor on the application, got %+v", loanApp.Parties)
+	}
+	primary := loanApp.Parties[0]
+	if primary.CustomerID != "CUST_001" || primary.Role != validation.LoanPartyRolePrimaryBorrower || primary.LiabilityShare != 60 {
+		t.Errorf("expected CUST_001 primary borrower on the remaining 60%%, got %+v", primary)
+	}
+	for _, party := range loanApp.Parties {
+		if party.ScreenedDate == nil {
+			t.Errorf("expected %s screened at submission", party.CustomerID)
+		}
+	}
+}
+
+func TestQueryLoansByPartyFindsEveryRole(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	withCustomers(stub, eligibleCustomer("CUST_001", 10), eligibleCustomer("CUST_002", 10), eligibleCustomer("CUST_003", 10))
+	withCompliance(stub)
+
+	submit := func(txID, customerID string, parties ...domain.LoanPartyRequest) string {
+		payload, err := inTx(stub, txID, func() ([]byte, error) {
+			return NewLoanApplicationHandler().SubmitLoanApplication(stub, []string{mustJSON(t, domain.LoanApplicationRequest{
+				CustomerID: customerID, LoanType: "MORTGAGE", RequestedAmount: 250000, TermMonths: 300, Purpose: "Home purchase", Parties: parties, Channel: "BRANCH", ActorID: "ACTOR_005",
+			})})
+		})
+		if err != nil {
+			t.Fatalf("submission failed: %v", err)
+		}
+		var loanApp domain.LoanApplication
+		if err := json.Unmarshal(payload, &loanApp); err != nil {
+			t.Fatalf("failed to decode loan: %v", err)
+		}
+		return loanApp.LoanID
+	}
+	own := submit("own", "CUST_002")
+	joint := submit("joint", "CUST_001", domain.LoanPartyRequest{CustomerID: "CUST_002", Role: string(validation.LoanPartyRoleCoBorrower), LiabilityShare: 50})
+	guaranteed := submit("guaranteed", "CUST_003", domain.LoanPartyRequest{CustomerID: "CUST_002", Role: string(validation.LoanPartyRoleGuarantor), LiabilityShare: 25})
+	submit("unrelated", "CUST_001")
+
+	byParty := func(customerID string) map[string]domain.PartyLoan {
+		payload, err := inTx(stub, "by_party_"+customerID, func() ([]byte, error) {
+			return NewLoanApplicationHandler().QueryLoansByParty(stub, []string{customerID})
+		})
+		if err != nil {
+			t.Fatalf("QueryLoansByParty failed: %v", err)
+		}
+		var partyLoans []domain.PartyLoan
+		if err := json.Unmarshal(payload, &partyLoans); err != nil {
+			t.Fatalf("failed to decode party loans: %v", err)
+		}
+		loans := map[string]domain.PartyLoan{}
+		for _, partyLoan := range partyLoans {
+			loans[partyLoan.LoanID] = partyLoan
+		}
+		return loans
+	}
+
+	// CUST_002 borrows once, co-borrows once and guarantees once
+	loans := byParty("CUST_002")
+	if len(loans) != 3 {
+		t.Fatalf("expected CUST_002 on 3 applications, got %+v", loans)
+	}
+	expected := map[string]struct {
+		role  validation.LoanPartyRole
+		share float64
+	}{
+		own:        {validation.LoanPartyRolePrimaryBorrower, 100},
+		joint:      {validation.LoanPartyRoleCoBorrower, 50},
+		guaranteed: {validation.LoanPartyRoleGuarantor, 25},
+	}
+	for loanID, want := range expected {
+		if got := loans[loanID]; got.Role != want.role || got.LiabilityShare != want.share || got.Status != validation.LoanStatusSubmitted {
+			t.Errorf("expected %s as %s on %.0f%%, got %+v", loanID, want.role, want.share, got)
+		}
+	}
+	if loans := byParty("CUST_003"); len(loans) != 1 || loans[guaranteed].Role != validation.LoanPartyRolePrimaryBorrower {
+		t.Errorf("expected CUST_003 only as borrower on its own application, got %+v", loans)
+	}
+}
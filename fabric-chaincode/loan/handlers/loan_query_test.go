@@ -0,0 +1,147 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+func queryLoans(t *testing.T, stub *shimtest.MockStub, query func(shim.ChaincodeStubInterface, []string) ([]byte, error), args ...string) (*domain.LoanQueryResult, error) {
+	payload, err := inTx(stub, "query", func() ([]byte, error) {
+		return query(stub, args)
+	})
+	if err != nil {
+		return nil, err
+	}
+	var result domain.LoanQueryResult
+	if err := json.Unmarshal(payload, &result); err != nil {
+		t.Fatalf("failed to decode query result: %v", err)
+	}
+	return &result, nil
+}
+
+func loanIDs(result *domain.LoanQueryResult) []string {
+	ids := []string{}
+	for _, loan := range result.Loans {
+		ids = append(ids, loan.LoanID)
+	}
+	return ids
+}
+
+func expectLoanIDs(t *testing.T, result *domain.LoanQueryResult, expected ...string) {
+	t.Helper()
+	ids := loanIDs(result)
+	if len(ids) != len(expected) || result.Count != len(expected) {
+		t.Fatalf("expected %v, got %v (count %d)", expected, ids, result.Count)
+	}
+	for i := range expected {
+		if ids[i] != expected[i] {
+			t.Fatalf("expected %v, got %v", expected, ids)
+		}
+	}
+}
+
+func TestQueryLoansByStatusPagesThroughWorkQueue(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleUnderwriter))
+	for _, loanID := range []string{"LOAN_Q1", "LOAN_Q2", "LOAN_Q3", "LOAN_Q4", "LOAN_Q5"} {
+		seedLoan(t, stub, loanID, validation.LoanStatusSubmitted)
+	}
+	seedLoan(t, stub, "LOAN_Q6", validation.LoanStatusUnderwriting)
+	query := NewLoanApplicationHandler().QueryLoansByStatus
+
+	// Each page picks up after the previous one's bookmark until the queue is exhausted
+	first, err := queryLoans(t, stub, query, "SUBMITTED", "2")
+	if err != nil {
+		t.Fatalf("QueryLoansByStatus failed: %v", err)
+	}
+	expectLoanIDs(t, first, "LOAN_Q1", "LOAN_Q2")
+	second, err := queryLoans(t, stub, query, "SUBMITTED", "2", first.Bookmark)
+	if err != nil {
+		t.Fatalf("QueryLoansByStatus failed: %v", err)
+	}
+	expectLoanIDs(t, second, "LOAN_Q3", "LOAN_Q4")
+	last, err := queryLoans(t, stub, query, "SUBMITTED", "2", second.Bookmark)
+	if err != nil {
+		t.Fatalf("QueryLoansByStatus failed: %v", err)
+	}
+	expectLoanIDs(t, last, "LOAN_Q5")
+	if last.Bookmark != "" {
+		t.Errorf("expected no bookmark after the last page, got %q", last.Bookmark)
+	}
+
+	// A status change moves the loan between queues
+	moved := getLoan(t, stub, "LOAN_Q2")
+	moved.Status = validation.LoanStatusUnderwriting
+	if _, err := inTx(stub, "underwrite", func() ([]byte, error) {
+		return nil, putLoanApplication(stub, services.NewPersistenceService(), moved)
+	}); err != nil {
+		t.Fatalf("failed to update loan: %v", err)
+	}
+	submitted, err := queryLoans(t, stub, query, "SUBMITTED")
+	if err != nil {
+		t.Fatalf("QueryLoansByStatus failed: %v", err)
+	}
+	expectLoanIDs(t, submitted, "LOAN_Q1", "LOAN_Q3", "LOAN_Q4", "LOAN_Q5")
+	underwriting, err := queryLoans(t, stub, query, "UNDERWRITING")
+	if err != nil {
+		t.Fatalf("QueryLoansByStatus failed: %v", err)
+	}
+	expectLoanIDs(t, underwriting, "LOAN_Q2", "LOAN_Q6")
+
+	if _, err := queryLoans(t, stub, query, "SUBMITTED", "0"); err == nil {
+		t.Errorf("expected a zero page size to be refused")
+	}
+	if _, err := queryLoans(t, stub, query, "PENDING"); err == nil {
+		t.Errorf("expected an unknown status to be refused")
+	}
+}
+
+func TestQueryLoansByCustomerAndDateRange(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleUnderwriter))
+	applied := func(customerID string, day int) func(*domain.LoanApplication) {
+		return func(loanApp *domain.LoanApplication) {
+			loanApp.CustomerID = customerID
+			loanApp.ApplicationDate = time.Date(2026, 1, day, 15, 30, 0, 0, time.UTC)
+		}
+	}
+	seedLoan(t, stub, "LOAN_D1", validation.LoanStatusSubmitted, applied("CUST_001", 7))
+	seedLoan(t, stub, "LOAN_D2", validation.LoanStatusApproved, approvedTerms(12000, 7), applied("CUST_002", 5))
+	seedLoan(t, stub, "LOAN_D3", validation.LoanStatusRejected, applied("CUST_002", 6))
+	seedLoan(t, stub, "LOAN_D4", validation.LoanStatusSubmitted, applied("CUST_001", 9))
+	h := NewLoanApplicationHandler()
+
+	// A customer's applications are listed whatever their status
+	byCustomer, err := queryLoans(t, stub, h.QueryLoansByCustomer, "CUST_002")
+	if err != nil {
+		t.Fatalf("QueryLoansByCustomer failed: %v", err)
+	}
+	expectLoanIDs(t, byCustomer, "LOAN_D2", "LOAN_D3")
+
+	// The range is inclusive of both days and listed day by day
+	byDate, err := queryLoans(t, stub, h.QueryLoansByDateRange, "2026-01-05", "2026-01-07")
+	if err != nil {
+		t.Fatalf("QueryLoansByDateRange failed: %v", err)
+	}
+	expectLoanIDs(t, byDate, "LOAN_D2", "LOAN_D3", "LOAN_D1")
+	page, err := queryLoans(t, stub, h.QueryLoansByDateRange, "2026-01-05", "2026-01-09", "3")
+	if err != nil {
+		t.Fatalf("QueryLoansByDateRange failed: %v", err)
+	}
+	rest, err := queryLoans(t, stub, h.QueryLoansByDateRange, "2026-01-05", "2026-01-09", "3", page.Bookmark)
+	if err != nil {
+		t.Fatalf("QueryLoansByDateRange failed: %v", err)
+	}
+	expectLoanIDs(t, rest, "LOAN_D4")
+
+	if _, err := queryLoans(t, stub, h.QueryLoansByDateRange, "2026-01-09", "2026-01-05"); err == nil {
+		t.Errorf("expected a reversed range to be refused")
+	}
+	_, err = queryLoans(t, stub, h.QueryLoansByDateRange, "2025-01-01", "2026-01-02")
+	expectErrorCode(t, err, services.ErrCodeInvalidArgument)
+}
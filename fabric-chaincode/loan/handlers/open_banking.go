@@ -0,0 +1,475 @@
+package handlers
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	loanServices "github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// OpenBankingHandler handles Open Banking account-information authorizations, oracle-published
+// account summaries and the affordability assessments built from them
+type OpenBankingHandler struct {
+	persistenceService *services.PersistenceService
+	eventService      *loanServices.EventService
+}
+
+// NewOpenBankingHandler creates a new Open Banking handler
+func NewOpenBankingHandler() *OpenBankingHandler {
+	return &OpenBankingHandler{
+		persistenceService: services.NewPersistenceService(),
+		eventService:      loanServices.NewEventService(),
+	}
+}
+
+// GrantOpenBankingAuthorization records a customer's authorization for account-information access
+// through a provider oracle until the given expiry date
+func (h *OpenBankingHandler) GrantOpenBankingAuthorization(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.OpenBankingAuthorizationRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse Open Banking authorization request: %v", err)
+	}
+
+	if strings.TrimSpace(req.CustomerID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "customerID", "customerID is required")
+	}
+	if strings.TrimSpace(req.ConsentReference) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "consentReference", "consentReference is required")
+	}
+	if len(req.AccountRefs) == 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "at least one account reference is required")
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	if !req.ExpiryDate.After(now) {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "expiry date must be in the future")
+	}
+
+	if _, err := h.getOpenBankingOracle(stub, req.ProviderID); err != nil {
+		return nil, err
+	}
+
+	authorization := &domain.OpenBankingAuthorization{
+		AuthorizationID:  services.GenerateDeterministicID(stub, config.OpenBankingAuthorizationPrefix),
+		CustomerID:       req.CustomerID,
+		ProviderID:       req.ProviderID,
+		AccountRefs:      req.AccountRefs,
+		ConsentReference: req.ConsentReference,
+		Status:           domain.OpenBankingAuthorizationActive,
+		GrantedDate:      now,
+		ExpiryDate:       req.ExpiryDate,
+		CreatedBy:        req.ActorID,
+		LastUpdatedBy:    req.ActorID,
+	}
+
+	if err := h.persistenceService.Put(stub, fmt.Sprintf("OPEN_BANKING_AUTH_%s", authorization.AuthorizationID), authorization); err != nil {
+		return nil, fmt.Errorf("failed to store Open Banking authorization: %w", err)
+	}
+
+	// Index by customer
+	indexKey, err := stub.CreateCompositeKey("CUSTOMER_OPEN_BANKING_AUTH", []string{authorization.CustomerID, authorization.AuthorizationID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := stub.PutState(indexKey, []byte(authorization.AuthorizationID)); err != nil {
+		return nil, fmt.Errorf("failed to index Open Banking authorization: %w", err)
+	}
+
+	if err := h.eventService.EmitOpenBankingAuthorizationChanged(stub, config.EventOpenBankingAuthorized, authorization, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(authorization)
+}
+
+// RevokeOpenBankingAuthorization withdraws an authorization. Summaries already ingested under it
+// are kept for audit but no longer used in affordability assessments.
+func (h *OpenBankingHandler) RevokeOpenBankingAuthorization(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.OpenBankingRevocationRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse Open Banking revocation request: %v", err)
+	}
+
+	authorization, err := h.getAuthorization(stub, req.AuthorizationID)
+	if err != nil {
+		return nil, err
+	}
+	if authorization.Status == domain.OpenBankingAuthorizationRevoked {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "authorization %s is already revoked", req.AuthorizationID)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	authorization.Status = domain.OpenBankingAuthorizationRevoked
+	authorization.RevokedDate = &now
+	authorization.LastUpdatedBy = req.ActorID
+
+	if err := h.persistenceService.Put(stub, fmt.Sprintf("OPEN_BANKING_AUTH_%s", authorization.AuthorizationID), authorization); err != nil {
+		return nil, fmt.Errorf("failed to update Open Banking authorization: %w", err)
+	}
+
+	if err := h.eventService.EmitOpenBankingAuthorizationChanged(stub, config.EventOpenBankingAuthorizationRevoked, authorization, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(authorization)
+}
+
+// GetOpenBankingAuthorizations retrieves all authorizations granted by a customer
+func (h *OpenBankingHandler) GetOpenBankingAuthorizations(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	authorizations, err := h.getCustomerAuthorizations(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(authorizations)
+}
+
+// IngestAccountSummary records a categorized transaction summary signed by the provider oracle of
+// an active authorization
+func (h *OpenBankingHandler) IngestAccountSummary(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.AccountSummaryIngestRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, fmt.Errorf("failed to parse account summary: %w", err)
+	}
+
+	// The summary must fall under an authorization in force
+	authorization, err := h.getAuthorization(stub, req.AuthorizationID)
+	if err != nil {
+		return nil, err
+	}
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	if !authorization.IsEffective(now) {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "authorization %s is not in force", req.AuthorizationID)
+	}
+	if authorization.ProviderID != req.ProviderID {
+		return nil, fmt.Errorf("authorization %s was not granted to provider %s", req.AuthorizationID, req.ProviderID)
+	}
+	if !containsString(authorization.AccountRefs, req.AccountRef) {
+		return nil, fmt.Errorf("account %s is not covered by authorization %s", req.AccountRef, req.AuthorizationID)
+	}
+
+	// Authenticate the oracle
+	oracle, err := h.getOpenBankingOracle(stub, req.ProviderID)
+	if err != nil {
+		return nil, err
+	}
+	publicKey, err := decodeOraclePublicKey(oracle.PublicKey)
+	if err != nil {
+		return nil, err
+	}
+	signature, err := base64.StdEncoding.DecodeString(req.SourceSignature)
+	if err != nil {
+		return nil, fmt.Errorf("invalid source signature encoding: %w", err)
+	}
+	payload := domain.AccountSummarySigningPayload(req.AuthorizationID, req.AccountRef, req.PeriodStart, req.PeriodEnd, req.Categories)
+	if !ed25519.Verify(publicKey, payload, signature) {
+		return nil, fmt.Errorf("source signature verification failed for account %s", req.AccountRef)
+	}
+
+	// Validate the summary
+	periodStart, err := time.Parse(loanServices.IndexRateDateFormat, req.PeriodStart)
+	if err != nil {
+		return nil, fmt.Errorf("invalid period start %s: %w", req.PeriodStart, err)
+	}
+	periodEnd, err := time.Parse(loanServices.IndexRateDateFormat, req.PeriodEnd)
+	if err != nil {
+		return nil, fmt.Errorf("invalid period end %s: %w", req.PeriodEnd, err)
+	}
+	if !periodStart.Before(periodEnd) {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "period start must be before period end")
+	}
+	if len(req.Categories) == 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "at least one transaction category is required")
+	}
+	for _, category := range req.Categories {
+		if category.Direction != domain.TransactionFlowInflow && category.Direction != domain.TransactionFlowOutflow {
+			return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid direction %s for category %s", category.Direction, category.Category)
+		}
+		if category.MonthlyAverage < 0 {
+			return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "monthly average for category %s cannot be negative", category.Category)
+		}
+	}
+
+	summary := &domain.AccountTransactionSummary{
+		CustomerID:      authorization.CustomerID,
+		AuthorizationID: req.AuthorizationID,
+		ProviderID:      req.ProviderID,
+		AccountRef:      req.AccountRef,
+		PeriodStart:     req.PeriodStart,
+		PeriodEnd:       req.PeriodEnd,
+		Categories:      req.Categories,
+		SourceSignature: req.SourceSignature,
+		IngestedDate:    now,
+		TransactionID:   stub.GetTxID(),
+	}
+
+	summaryKey, err := stub.CreateCompositeKey("OPEN_BANKING_SUMMARY", []string{summary.CustomerID, summary.AccountRef, summary.PeriodEnd, summary.TransactionID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := h.persistenceService.Put(stub, summaryKey, summary); err != nil {
+		return nil, fmt.Errorf("failed to store account summary: %w", err)
+	}
+
+	return json.Marshal(summary)
+}
+
+// AssessAffordability compares the borrower's income and outgoings, taken from the latest summary
+// of each account under an authorization in force, with the loan's monthly payment. Each
+// assessment records the summaries it used and when their authorizations expire.
+func (h *OpenBankingHandler) AssessAffordability(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.AffordabilityAssessmentRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse affordability assessment request: %v", err)
+	}
+
+	var loanApp domain.LoanApplication
+	if err := h.persistenceService.Get(stub, fmt.Sprintf("LOAN_%s", req.LoanID), &loanApp); err != nil {
+		return nil, fmt.Errorf("loan application not found: %w", err)
+	}
+
+	rate := req.InterestRate
+	if loanApp.InterestRate != nil {
+		rate = loanApp.InterestRate
+	}
+	if rate == nil {
+		return nil, fmt.Errorf("loan %s has not been priced; interestRate is required", req.LoanID)
+	}
+	amount := loanApp.RequestedAmount
+	if loanApp.ApprovedAmount != nil {
+		amount = *loanApp.ApprovedAmount
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	installments, err := loanServices.BuildAmortizationSchedule(loanApp.LoanID, amount, *rate, loanApp.TermMonths, now)
+	if err != nil {
+		return nil, fmt.Errorf("failed to compute proposed payment: %w", err)
+	}
+
+	summaries, authorizations, err := h.getUsableSummaries(stub, loanApp.CustomerID, now)
+	if err != nil {
+		return nil, err
+	}
+	if len(summaries) == 0 {
+		return nil, fmt.Errorf("no Open Banking data under an authorization in force for customer %s", loanApp.CustomerID)
+	}
+
+	assessment := &domain.AffordabilityAssessment{
+		AssessmentID:    services.GenerateDeterministicID(stub, config.AffordabilityAssessmentPrefix),
+		LoanID:          loanApp.LoanID,
+		CustomerID:      loanApp.CustomerID,
+		ProposedPayment: installments[0].TotalDue.Float64(),
+		Sources:         []domain.AffordabilitySource{},
+		AssessedDate:    now,
+		AssessedBy:      req.ActorID,
+	}
+	for _, summary := range summaries {
+		for _, category := range summary.Categories {
+			if category.Direction == domain.TransactionFlowInflow {
+				assessment.MonthlyIncome += category.MonthlyAverage
+			} else {
+				assessment.MonthlyOutgoings += category.MonthlyAverage
+			}
+		}
+		assessment.Sources = append(assessment.Sources, domain.AffordabilitySource{
+			AuthorizationID:     summary.AuthorizationID,
+			ProviderID:          summary.ProviderID,
+			AccountRef:          summary.AccountRef,
+			PeriodStart:         summary.PeriodStart,
+			PeriodEnd:           summary.PeriodEnd,
+			TransactionID:       summary.TransactionID,
+			AuthorizationExpiry: authorizations[summary.AuthorizationID].ExpiryDate,
+		})
+	}
+
+	commitments := assessment.MonthlyOutgoings + assessment.ProposedPayment
+	assessment.DisposableIncome = roundToCents(assessment.MonthlyIncome - commitments)
+	if assessment.MonthlyIncome > 0 {
+		assessment.DebtServiceRatio = commitments / assessment.MonthlyIncome
+	}
+	assessment.Affordable = assessment.MonthlyIncome > 0 && assessment.DisposableIncome >= 0 && assessment.DebtServiceRatio <= config.MaxDebtServiceRatio
+
+	assessmentKey, err := stub.CreateCompositeKey("AFFORDABILITY_ASSESSMENT", []string{assessment.LoanID, assessment.AssessmentID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := h.persistenceService.Put(stub, assessmentKey, assessment); err != nil {
+		return nil, fmt.Errorf("failed to store affordability assessment: %w", err)
+	}
+
+	if err := h.eventService.EmitAffordabilityAssessed(stub, assessment); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(assessment)
+}
+
+// GetAffordabilityAssessments retrieves all affordability assessments of a loan
+func (h *OpenBankingHandler) GetAffordabilityAssessments(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	iterator, err := stub.GetStateByPartialCompositeKey("AFFORDABILITY_ASSESSMENT", []string{args[0]})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get affordability assessments: %w", err)
+	}
+	defer iterator.Close()
+
+	assessments := []domain.AffordabilityAssessment{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate affordability assessments: %w", err)
+		}
+
+		var assessment domain.AffordabilityAssessment
+		if err := json.Unmarshal(response.Value, &assessment); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal affordability assessment: %w", err)
+		}
+
+		assessments = append(assessments, assessment)
+	}
+
+	return json.Marshal(assessments)
+}
+
+// Helper methods
+
+func (h *OpenBankingHandler) getAuthorization(stub shim.ChaincodeStubInterface, authorizationID string) (*domain.OpenBankingAuthorization, error) {
+	var authorization domain.OpenBankingAuthorization
+	if err := h.persistenceService.Get(stub, fmt.Sprintf("OPEN_BANKING_AUTH_%s", authorizationID), &authorization); err != nil {
+		return nil, fmt.Errorf("Open Banking authorization not found: %w", err)
+	}
+	return &authorization, nil
+}
+
+func (h *OpenBankingHandler) getCustomerAuthorizations(stub shim.ChaincodeStubInterface, customerID string) ([]domain.OpenBankingAuthorization, error) {
+	iterator, err := stub.GetStateByPartialCompositeKey("CUSTOMER_OPEN_BANKING_AUTH", []string{customerID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get Open Banking authorizations: %w", err)
+	}
+	defer iterator.Close()
+
+	authorizations := []domain.OpenBankingAuthorization{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate Open Banking authorizations: %w", err)
+		}
+
+		authorization, err := h.getAuthorization(stub, string(response.Value))
+		if err != nil {
+			continue // Skip if authorization not found
+		}
+		authorizations = append(authorizations, *authorization)
+	}
+
+	return authorizations, nil
+}
+
+// getUsableSummaries returns the latest summary of each account whose authorization is in force,
+// with those authorizations by ID
+func (h *OpenBankingHandler) getUsableSummaries(stub shim.ChaincodeStubInterface, customerID string, at time.Time) ([]domain.AccountTransactionSummary, map[string]domain.OpenBankingAuthorization, error) {
+	authorizations, err := h.getCustomerAuthorizations(stub, customerID)
+	if err != nil {
+		return nil, nil, err
+	}
+	effective := map[string]domain.OpenBankingAuthorization{}
+	for _, authorization := range authorizations {
+		if authorization.IsEffective(at) {
+			effective[authorization.AuthorizationID] = authorization
+		}
+	}
+
+	iterator, err := stub.GetStateByPartialCompositeKey("OPEN_BANKING_SUMMARY", []string{customerID})
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to get account summaries: %w", err)
+	}
+	defer iterator.Close()
+
+	// Keys sort by account then period end, so the last usable summary of an account is its latest
+	latest := map[string]domain.AccountTransactionSummary{}
+	accounts := []string{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to iterate account summaries: %w", err)
+		}
+
+		var summary domain.AccountTransactionSummary
+		if err := json.Unmarshal(response.Value, &summary); err != nil {
+			return nil, nil, fmt.Errorf("failed to unmarshal account summary: %w", err)
+		}
+		if _, ok := effective[summary.AuthorizationID]; !ok {
+			continue
+		}
+
+		if _, seen := latest[summary.AccountRef]; !seen {
+			accounts = append(accounts, summary.AccountRef)
+		}
+		latest[summary.AccountRef] = summary
+	}
+
+	summaries := make([]domain.AccountTransactionSummary, 0, len(accounts))
+	for _, account := range accounts {
+		summaries = append(summaries, latest[account])
+	}
+	return summaries, effective, nil
+}
+
+func (h *OpenBankingHandler) getOpenBankingOracle(stub shim.ChaincodeStubInterface, oracleID string) (*domain.RateOracle, error) {
+	var oracle domain.RateOracle
+	if err := h.persistenceService.Get(stub, fmt.Sprintf("RATE_ORACLE_%s", oracleID), &oracle); err != nil {
+		return nil, fmt.Errorf("oracle not found: %w", err)
+	}
+	if !oracle.IsActive {
+		return nil, fmt.Errorf("oracle %s is not active", oracleID)
+	}
+	if !containsString(oracle.Indexes, domain.OpenBankingFeed) {
+		return nil, services.NewChaincodeError(services.ErrCodeAccessDenied, "", "oracle %s is not authorised to publish %s data", oracleID, domain.OpenBankingFeed)
+	}
+	return &oracle, nil
+}
@@ -0,0 +1,171 @@
+package handlers
+
+import (
+	"crypto/ed25519"
+	"encoding/base64"
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+func grantOpenBanking(t *testing.T, stub *shimtest.MockStub, txID string, expiry time.Time, accountRefs ...string) string {
+	t.Helper()
+	payload, err := inTxAt(stub, txID, fixingTime, func() ([]byte, error) {
+		return NewOpenBankingHandler().GrantOpenBankingAuthorization(stub, []string{mustJSON(t, domain.OpenBankingAuthorizationRequest{
+			CustomerID: "CUST_001", ProviderID: "ORACLE_1", AccountRefs: accountRefs, ConsentReference: "CONSENT-" + txID, ExpiryDate: expiry, ActorID: "ACTOR_005",
+		})})
+	})
+	if err != nil {
+		t.Fatalf("granting the authorization failed: %v", err)
+	}
+	var authorization domain.OpenBankingAuthorization
+	if err := json.Unmarshal(payload, &authorization); err != nil {
+		t.Fatalf("failed to decode authorization: %v", err)
+	}
+	return authorization.AuthorizationID
+}
+
+// ingestSummary publishes a one-month account summary signed with the given key
+func ingestSummary(t *testing.T, stub *shimtest.MockStub, txID string, key ed25519.PrivateKey, authorizationID, accountRef, periodStart, periodEnd string, categories ...domain.TransactionCategorySummary) error {
+	signature := ed25519.Sign(key, domain.AccountSummarySigningPayload(authorizationID, accountRef, periodStart, periodEnd, categories))
+	_, err := inTxAt(stub, txID, fixingTime, func() ([]byte, error) {
+		return NewOpenBankingHandler().IngestAccountSummary(stub, []string{mustJSON(t, domain.AccountSummaryIngestRequest{
+			AuthorizationID: authorizationID, ProviderID: "ORACLE_1", AccountRef: accountRef, PeriodStart: periodStart, PeriodEnd: periodEnd,
+			Categories: categories, SourceSignature: base64.StdEncoding.EncodeToString(signature),
+		})})
+	})
+	return err
+}
+
+func TestIngestAccountSummaryRequiresAuthorizedSignedData(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	registerOracle(t, stub, domain.OpenBankingFeed)
+	authorizationID := grantOpenBanking(t, stub, "grant", fixingTime.AddDate(0, 3, 0), "ACC_1")
+	salary := domain.TransactionCategorySummary{Category: "SALARY", Direction: domain.TransactionFlowInflow, MonthlyAverage: 6000}
+
+	forged := ed25519.NewKeyFromSeed([]byte("someone-else-entirely-test-seed!"))
+	if err := ingestSummary(t, stub, "forged", forged, authorizationID, "ACC_1", "2026-01-01", "2026-01-31", salary); err == nil || !strings.Contains(err.Error(), "signature verification failed") {
+		t.Errorf("expected a forged summary to be refused, got %v", err)
+	}
+	if err := ingestSummary(t, stub, "uncovered", oracleKey, authorizationID, "ACC_9", "2026-01-01", "2026-01-31", salary); err == nil || !strings.Contains(err.Error(), "not covered by authorization") {
+		t.Errorf("expected an account outside the authorization to be refused, got %v", err)
+	}
+	sideways := domain.TransactionCategorySummary{Category: "SALARY", Direction: "SIDEWAYS", MonthlyAverage: 6000}
+	expectErrorCode(t, ingestSummary(t, stub, "direction", oracleKey, authorizationID, "ACC_1", "2026-01-01", "2026-01-31", sideways), services.ErrCodeInvalidArgument)
+	if err := ingestSummary(t, stub, "january", oracleKey, authorizationID, "ACC_1", "2026-01-01", "2026-01-31", salary); err != nil {
+		t.Fatalf("ingesting the summary failed: %v", err)
+	}
+
+	// Once revoked, the authorization accepts no more data and cannot be revoked again
+	revoke := func(txID string) error {
+		_, err := inTxAt(stub, txID, fixingTime, func() ([]byte, error) {
+			return NewOpenBankingHandler().RevokeOpenBankingAuthorization(stub, []string{mustJSON(t, domain.OpenBankingRevocationRequest{AuthorizationID: authorizationID, ActorID: "ACTOR_005"})})
+		})
+		return err
+	}
+	if err := revoke("revoke"); err != nil {
+		t.Fatalf("revoking the authorization failed: %v", err)
+	}
+	expectErrorCode(t, ingestSummary(t, stub, "february", oracleKey, authorizationID, "ACC_1", "2026-02-01", "2026-02-28", salary), services.ErrCodeInvalidTransition)
+	expectErrorCode(t, revoke("revoke_again"), services.ErrCodeInvalidTransition)
+
+	// An oracle not authorised for the Open Banking feed cannot relay account data
+	other := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	registerOracle(t, other, "SOFR")
+	_, err := inTxAt(other, "grant", fixingTime, func() ([]byte, error) {
+		return NewOpenBankingHandler().GrantOpenBankingAuthorization(other, []string{mustJSON(t, domain.OpenBankingAuthorizationRequest{
+			CustomerID: "CUST_001", ProviderID: "ORACLE_1", AccountRefs: []string{"ACC_1"}, ConsentReference: "CONSENT-1", ExpiryDate: fixingTime.AddDate(0, 3, 0), ActorID: "ACTOR_005",
+		})})
+	})
+	expectErrorCode(t, err, services.ErrCodeAccessDenied)
+}
+
+func TestAssessAffordabilityUsesLatestSummaryUnderAuthorizationsInForce(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	registerOracle(t, stub, domain.OpenBankingFeed)
+	seedLoan(t, stub, "LOAN_OB", validation.LoanStatusUnderwriting)
+	current := grantOpenBanking(t, stub, "grant_current", fixingTime.AddDate(0, 3, 0), "ACC_1")
+	expiring := grantOpenBanking(t, stub, "grant_expiring", fixingTime.AddDate(0, 0, 10), "ACC_2")
+	flow := func(category, direction string, monthlyAverage float64) domain.TransactionCategorySummary {
+		return domain.TransactionCategorySummary{Category: category, Direction: direction, MonthlyAverage: monthlyAverage}
+	}
+	for _, summary := range []struct {
+		txID, authorizationID, accountRef, periodStart, periodEnd string
+		categories                                                []domain.TransactionCategorySummary
+	}{
+		{"acc1_feb", current, "ACC_1", "2026-02-01", "2026-02-28", []domain.TransactionCategorySummary{flow("SALARY", domain.TransactionFlowInflow, 6000), flow("RENT", domain.TransactionFlowOutflow, 1000)}},
+		{"acc1_jan", current, "ACC_1", "2026-01-01", "2026-01-31", []domain.TransactionCategorySummary{flow("SALARY", domain.TransactionFlowInflow, 3000), flow("RENT", domain.TransactionFlowOutflow, 1000)}},
+		{"acc2_feb", expiring, "ACC_2", "2026-02-01", "2026-02-28", []domain.TransactionCategorySummary{flow("UTILITIES", domain.TransactionFlowOutflow, 300)}},
+	} {
+		if err := ingestSummary(t, stub, summary.txID, oracleKey, summary.authorizationID, summary.accountRef, summary.periodStart, summary.periodEnd, summary.categories...); err != nil {
+			t.Fatalf("ingesting %s failed: %v", summary.txID, err)
+		}
+	}
+	assess := func(txID string, at time.Time, interestRate *float64) (*domain.AffordabilityAssessment, error) {
+		payload, err := inTxAt(stub, txID, at, func() ([]byte, error) {
+			return NewOpenBankingHandler().AssessAffordability(stub, []string{mustJSON(t, domain.AffordabilityAssessmentRequest{LoanID: "LOAN_OB", InterestRate: interestRate, ActorID: "ACTOR_005"})})
+		})
+		if err != nil {
+			return nil, err
+		}
+		var assessment domain.AffordabilityAssessment
+		if err := json.Unmarshal(payload, &assessment); err != nil {
+			t.Fatalf("failed to decode assessment: %v", err)
+		}
+		return &assessment, nil
+	}
+	rate := 12.0
+
+	if _, err := assess("unpriced", fixingTime, nil); err == nil || !strings.Contains(err.Error(), "has not been priced") {
+		t.Errorf("expected an unpriced loan without a rate to be refused, got %v", err)
+	}
+
+	// Each account contributes its latest period; February's salary is used, not January's
+	assessment, err := assess("assess", fixingTime.AddDate(0, 0, 5), &rate)
+	if err != nil {
+		t.Fatalf("AssessAffordability failed: %v", err)
+	}
+	if assessment.MonthlyIncome != 6000 || assessment.MonthlyOutgoings != 1300 || len(assessment.Sources) != 2 {
+		t.Fatalf("expected 6000 in and 1300 out from both accounts, got %+v", assessment)
+	}
+	if assessment.ProposedPayment <= 0 || assessment.DisposableIncome != roundToCents(6000-1300-assessment.ProposedPayment) || !assessment.Affordable {
+		t.Errorf("expected the 12,000 loan to be affordable, got %+v", assessment)
+	}
+	for _, source := range assessment.Sources {
+		if source.AccountRef == "ACC_1" && (source.PeriodEnd != "2026-02-28" || source.TransactionID != "acc1_feb" || !source.AuthorizationExpiry.Equal(fixingTime.AddDate(0, 3, 0))) {
+			t.Errorf("expected ACC_1's February summary with its authorization expiry, got %+v", source)
+		}
+	}
+
+	// Data under an expired authorization drops out of later assessments
+	later, err := assess("assess_later", fixingTime.AddDate(0, 0, 20), &rate)
+	if err != nil {
+		t.Fatalf("AssessAffordability failed: %v", err)
+	}
+	if later.MonthlyOutgoings != 1000 || len(later.Sources) != 1 || later.Sources[0].AuthorizationID != current {
+		t.Errorf("expected only ACC_1 once ACC_2's authorization expired, got %+v", later)
+	}
+
+	if _, err := inTxAt(stub, "revoke", fixingTime.AddDate(0, 0, 21), func() ([]byte, error) {
+		return NewOpenBankingHandler().RevokeOpenBankingAuthorization(stub, []string{mustJSON(t, domain.OpenBankingRevocationRequest{AuthorizationID: current, ActorID: "ACTOR_005"})})
+	}); err != nil {
+		t.Fatalf("revoking the authorization failed: %v", err)
+	}
+	if _, err := assess("assess_revoked", fixingTime.AddDate(0, 0, 22), &rate); err == nil || !strings.Contains(err.Error(), "no Open Banking data") {
+		t.Errorf("expected no data once every authorization lapsed, got %v", err)
+	}
+
+	payload, err := inTx(stub, "assessments", func() ([]byte, error) {
+		return NewOpenBankingHandler().GetAffordabilityAssessments(stub, []string{"LOAN_OB"})
+	})
+	var assessments []domain.AffordabilityAssessment
+	if err != nil || json.Unmarshal(payload, &assessments) != nil || len(assessments) != 2 {
+		t.Errorf("expected both assessments kept on the loan, got %s (%v)", payload, err)
+	}
+}
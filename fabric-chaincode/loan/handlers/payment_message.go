@@ -0,0 +1,128 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	loanServices "github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// PaymentMessageHandler translates disbursements and repayments to and from the ISO 20022
+// messages exchanged with core banking
+type PaymentMessageHandler struct {
+	persistenceService *services.PersistenceService
+}
+
+// NewPaymentMessageHandler creates a new payment message handler
+func NewPaymentMessageHandler() *PaymentMessageHandler {
+	return &PaymentMessageHandler{
+		persistenceService: services.NewPersistenceService(),
+	}
+}
+
+// GetDisbursementPain001 renders a disbursement as a pain.001 credit transfer initiation. The
+// message ID is the transaction ID of the request.
+func (h *PaymentMessageHandler) GetDisbursementPain001(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 2, got %d", len(args))
+	}
+
+	disbursementKey, err := stub.CreateCompositeKey("LOAN_DISBURSEMENT", []string{args[0], args[1]})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %w", err)
+	}
+	var disbursement domain.LoanDisbursement
+	if err := h.persistenceService.Get(stub, disbursementKey, &disbursement); err != nil {
+		return nil, fmt.Errorf("disbursement not found: %w", err)
+	}
+
+	createdAt, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	doc := loanServices.DisbursementToPain001(&disbursement, stub.GetTxID(), createdAt)
+	return marshalPaymentMessage(domain.MessageTypePain001, stub.GetTxID(), doc)
+}
+
+// GetRepaymentCamt054 renders a repayment transaction as a camt.054 credit notification. The
+// message ID is the transaction ID of the request.
+func (h *PaymentMessageHandler) GetRepaymentCamt054(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 2, got %d", len(args))
+	}
+
+	txnKey, err := stub.CreateCompositeKey("LOAN_TRANSACTION", []string{args[0], args[1]})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %w", err)
+	}
+	var txn domain.LoanTransaction
+	if err := h.persistenceService.Get(stub, txnKey, &txn); err != nil {
+		return nil, fmt.Errorf("loan transaction not found: %w", err)
+	}
+
+	createdAt, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	doc, err := loanServices.RepaymentToCamt054(&txn, stub.GetTxID(), createdAt)
+	if err != nil {
+		return nil, err
+	}
+	return marshalPaymentMessage(domain.MessageTypeCamt054, stub.GetTxID(), doc)
+}
+
+// ParsePain001 maps a pain.001 received from core banking to disbursement requests. Nothing is
+// written; the gateway submits each request through DisburseLoan.
+func (h *PaymentMessageHandler) ParsePain001(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 2, got %d", len(args))
+	}
+
+	doc, err := loanServices.ParsePain001(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	requests, err := loanServices.Pain001ToDisbursementRequests(doc, args[1])
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(requests)
+}
+
+// ParseCamt054 maps a camt.054 received from core banking to repayment requests. Nothing is
+// written; the gateway submits each request through RecordRepayment.
+func (h *PaymentMessageHandler) ParseCamt054(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 2, got %d", len(args))
+	}
+
+	doc, err := loanServices.ParseCamt054(args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	requests, err := loanServices.Camt054ToRepaymentRequests(doc, args[1])
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(requests)
+}
+
+func marshalPaymentMessage(messageType, messageID string, doc interface{}) ([]byte, error) {
+	document, err := loanServices.MarshalPaymentMessage(doc)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(&domain.PaymentMessage{
+		MessageType: messageType,
+		MessageID:   messageID,
+		Document:    document,
+	})
+}
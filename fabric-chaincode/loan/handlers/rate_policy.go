@@ -0,0 +1,319 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	loanServices "github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// RatePolicyHandler handles the interest rate policies set per loan type
+type RatePolicyHandler struct {
+	persistenceService *services.PersistenceService
+	eventService       *loanServices.EventService
+	indexRateService   *loanServices.IndexRateService
+}
+
+// NewRatePolicyHandler creates a new rate policy handler
+func NewRatePolicyHandler() *RatePolicyHandler {
+	return &RatePolicyHandler{
+		persistenceService: services.NewPersistenceService(),
+		eventService:       loanServices.NewEventService(),
+		indexRateService:   loanServices.NewIndexRateService(),
+	}
+}
+
+// SetInterestRatePolicy replaces a loan type's interest rate policy with a new version. Loans
+// already approved keep the version they were approved under.
+func (h *RatePolicyHandler) SetInterestRatePolicy(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.InterestRatePolicyRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse interest rate policy request: %v", err)
+	}
+
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return nil, err
+	}
+	if role != string(validation.ActorRoleCreditOfficer) {
+		return nil, services.NewChaincodeError(services.ErrCodeAccessDenied, "", "interest rate policies may only be set by a %s", validation.ActorRoleCreditOfficer)
+	}
+
+	if err := validation.ValidateLoanType(req.LoanType); err != nil {
+		return nil, fmt.Errorf("invalid loan type: %w", err)
+	}
+	if req.FloorRate < 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "floorRate cannot be negative")
+	}
+	if req.CapRate <= 0 || req.CapRate > config.MaxInterestRate {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "capRate must be greater than 0 and at most %.2f, got %.4f", config.MaxInterestRate, req.CapRate)
+	}
+	if req.FloorRate > req.CapRate {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "floorRate %.4f exceeds capRate %.4f", req.FloorRate, req.CapRate)
+	}
+
+	baseRateIndex := strings.TrimSpace(req.BaseRateIndex)
+	if baseRateIndex != "" {
+		if _, err := h.indexRateService.GetLatestRate(stub, baseRateIndex); err != nil {
+			return nil, fmt.Errorf("invalid base rate index: %w", err)
+		}
+	}
+
+	adjustments := map[string]float64{}
+	for tier, adjustment := range req.RiskTierAdjustments {
+		riskTier, err := normalizeAmountBoundTier(tier)
+		if err != nil {
+			return nil, err
+		}
+		if riskTier == domain.LoanAmountBoundWildcard {
+			return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "risk tier adjustments must name a risk tier, not %s", domain.LoanAmountBoundWildcard)
+		}
+		adjustments[riskTier] = adjustment
+	}
+
+	previous, err := getInterestRatePolicy(stub, h.persistenceService, req.LoanType)
+	if err != nil {
+		return nil, err
+	}
+	previousJSON := ""
+	version := 1
+	if previous != nil {
+		previousJSON, _ = utils.MarshalJSONString(previous)
+		version = previous.Version + 1
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	policy := &domain.InterestRatePolicy{
+		LoanType:            req.LoanType,
+		Version:             version,
+		FloorRate:           req.FloorRate,
+		CapRate:             req.CapRate,
+		BaseRateIndex:       baseRateIndex,
+		BaseRateSpread:      req.BaseRateSpread,
+		RiskTierAdjustments: adjustments,
+		LastUpdated:         now,
+		LastUpdatedBy:       req.ActorID,
+	}
+
+	if err := h.persistenceService.Put(stub, interestRatePolicyKey(policy.LoanType), policy); err != nil {
+		return nil, fmt.Errorf("failed to store interest rate policy: %w", err)
+	}
+
+	// Keep every version for tracing approvals to the policy they were held to
+	versionKey, err := interestRatePolicyVersionKey(stub, policy.LoanType, policy.Version)
+	if err != nil {
+		return nil, err
+	}
+	if err := h.persistenceService.Put(stub, versionKey, policy); err != nil {
+		return nil, fmt.Errorf("failed to store interest rate policy version: %w", err)
+	}
+
+	// Record history
+	policyJSON, _ := utils.MarshalJSONString(policy)
+	if err := h.recordEntityHistory(stub, policy.LoanType, "InterestRatePolicy", "UPDATE", "interestRatePolicy", previousJSON, policyJSON, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to record history: %w", err)
+	}
+
+	// Emit event
+	if err := h.eventService.EmitInterestRatePolicyUpdated(stub, policy, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(policy)
+}
+
+// GetInterestRatePolicy retrieves a loan type's active interest rate policy, or the given version
+// of it.
+// Args: loanType [, version]
+func (h *RatePolicyHandler) GetInterestRatePolicy(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1 or 2, got %d", len(args))
+	}
+
+	if len(args) == 2 {
+		version, err := strconv.Atoi(args[1])
+		if err != nil || version < 1 {
+			return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid version %s, expected a positive integer", args[1])
+		}
+		versionKey, err := interestRatePolicyVersionKey(stub, args[0], version)
+		if err != nil {
+			return nil, err
+		}
+		var policy domain.InterestRatePolicy
+		if err := h.persistenceService.Get(stub, versionKey, &policy); err != nil {
+			return nil, fmt.Errorf("interest rate policy version not found: %w", err)
+		}
+		return json.Marshal(&policy)
+	}
+
+	policy, err := getInterestRatePolicy(stub, h.persistenceService, args[0])
+	if err != nil {
+		return nil, err
+	}
+	if policy == nil {
+		return nil, fmt.Errorf("no interest rate policy is set for %s loans", args[0])
+	}
+
+	return json.Marshal(policy)
+}
+
+// GetInterestRateRange works out the rates a loan type's active policy allows for customers in a
+// risk tier, priced off the current base rate fixing.
+// Args: loanType, riskTier
+func (h *RatePolicyHandler) GetInterestRateRange(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 2, got %d", len(args))
+	}
+
+	riskTier, err := normalizeAmountBoundTier(args[1])
+	if err != nil {
+		return nil, err
+	}
+	if riskTier == domain.LoanAmountBoundWildcard {
+		return nil, fmt.Errorf("a risk tier is required, not %s", domain.LoanAmountBoundWildcard)
+	}
+
+	rateRange, err := interestRateRange(stub, h.persistenceService, h.indexRateService, args[0], riskTier)
+	if err != nil {
+		return nil, err
+	}
+	if rateRange == nil {
+		return nil, fmt.Errorf("no interest rate policy is set for %s loans", args[0])
+	}
+
+	return json.Marshal(rateRange)
+}
+
+// Helper methods
+
+func (h *RatePolicyHandler) recordEntityHistory(stub shim.ChaincodeStubInterface, entityID, entityType, changeType, fieldName, previousValue, newValue, actorID string) error {
+	historyID, compositeKey, err := services.NewHistoryKey(stub, entityID)
+	if err != nil {
+		return err
+	}
+	txID := stub.GetTxID()
+
+	timestamp, err := services.TxTimeString(stub)
+	if err != nil {
+		return err
+	}
+
+	historyEntry := map[string]interface{}{
+		"historyID":     historyID,
+		"entityID":      entityID,
+		"entityType":    entityType,
+		"timestamp":     timestamp,
+		"changeType":    changeType,
+		"fieldName":     fieldName,
+		"previousValue": previousValue,
+		"newValue":      newValue,
+		"actorID":       actorID,
+		"transactionID": txID,
+	}
+
+	return h.persistenceService.Put(stub, compositeKey, historyEntry)
+}
+
+// checkInterestRatePolicy holds a rate to the range the loan type's active policy allows the
+// customer's risk tier. Customers who have not been rated fall under LoanAmountTierUnrated. It
+// returns the version of the policy the rate was held to, or 0 when the loan type has no policy.
+func checkInterestRatePolicy(stub shim.ChaincodeStubInterface, ps *services.PersistenceService, indexRateService *loanServices.IndexRateService, loanType, riskTier string, rate float64) (int, error) {
+	if riskTier == "" {
+		riskTier = domain.LoanAmountTierUnrated
+	}
+	rateRange, err := interestRateRange(stub, ps, indexRateService, loanType, riskTier)
+	if err != nil {
+		return 0, err
+	}
+	if rateRange == nil {
+		return 0, nil
+	}
+	if rate < rateRange.MinRate || rate > rateRange.MaxRate {
+		return 0, fmt.Errorf("interest rate %.4f is outside the %.4f to %.4f range version %d of the %s rate policy allows %s risk customers", rate, rateRange.MinRate, rateRange.MaxRate, rateRange.PolicyVersion, loanType, riskTier)
+	}
+	return rateRange.PolicyVersion, nil
+}
+
+// interestRateRange works out the rates the loan type's active policy allows a risk tier. It
+// returns nil when the loan type has no policy, and an error when the policy's base rate index
+// has no current fixing or the policy leaves no rate for the tier.
+func interestRateRange(stub shim.ChaincodeStubInterface, ps *services.PersistenceService, indexRateService *loanServices.IndexRateService, loanType, riskTier string) (*domain.InterestRateRange, error) {
+	policy, err := getInterestRatePolicy(stub, ps, loanType)
+	if err != nil || policy == nil {
+		return nil, err
+	}
+
+	baseRate := 0.0
+	if policy.BaseRateIndex != "" {
+		indexRate, err := indexRateService.GetCurrentRate(stub, policy.BaseRateIndex)
+		if err != nil {
+			return nil, fmt.Errorf("failed to price %s rate policy: %w", loanType, err)
+		}
+		baseRate = indexRate.Rate
+	}
+
+	minRate := baseRate + policy.BaseRateSpread + policy.RiskTierAdjustments[riskTier]
+	minRate = math.Max(math.Round(minRate*10000)/10000, policy.FloorRate)
+	if minRate > policy.CapRate {
+		return nil, fmt.Errorf("version %d of the %s rate policy prices %s risk customers at %.4f, above its %.4f cap", policy.Version, loanType, riskTier, minRate, policy.CapRate)
+	}
+
+	return &domain.InterestRateRange{
+		LoanType:      loanType,
+		PolicyVersion: policy.Version,
+		RiskTier:      riskTier,
+		BaseRate:      baseRate,
+		MinRate:       minRate,
+		MaxRate:       policy.CapRate,
+	}, nil
+}
+
+// getInterestRatePolicy returns a loan type's active interest rate policy, or nil if none is set
+func getInterestRatePolicy(stub shim.ChaincodeStubInterface, ps *services.PersistenceService, loanType string) (*domain.InterestRatePolicy, error) {
+	policyKey := interestRatePolicyKey(loanType)
+	exists, err := ps.Exists(stub, policyKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check interest rate policy: %w", err)
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	var policy domain.InterestRatePolicy
+	if err := ps.Get(stub, policyKey, &policy); err != nil {
+		return nil, fmt.Errorf("failed to get interest rate policy: %w", err)
+	}
+	return &policy, nil
+}
+
+func interestRatePolicyKey(loanType string) string {
+	return fmt.Sprintf("RATE_POLICY_%s", loanType)
+}
+
+func interestRatePolicyVersionKey(stub shim.ChaincodeStubInterface, loanType string, version int) (string, error) {
+	versionKey, err := stub.CreateCompositeKey("RATE_POLICY_VERSION", []string{loanType, fmt.Sprintf("%06d", version)})
+	if err != nil {
+		return "", fmt.Errorf("failed to create composite key: %w", err)
+	}
+	return versionKey, nil
+}
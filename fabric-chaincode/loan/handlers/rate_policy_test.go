@@ -0,0 +1,142 @@
+package handlers
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// setRatePolicy stores a loan type's interest rate policy as a credit officer
+func setRatePolicy(t *testing.T, stub *shimtest.MockStub, txID string, req domain.InterestRatePolicyRequest) {
+	t.Helper()
+	creator := stub.Creator
+	defer func() { stub.Creator = creator }()
+	stub.Creator = newTestIdentity(t, string(validation.ActorRoleCreditOfficer))
+	req.ActorID = "ACTOR_004"
+	if _, err := inTx(stub, txID, func() ([]byte, error) {
+		return NewRatePolicyHandler().SetInterestRatePolicy(stub, []string{mustJSON(t, req)})
+	}); err != nil {
+		t.Fatalf("failed to set %s rate policy: %v", req.LoanType, err)
+	}
+}
+
+func TestGetInterestRateRangeLooksUpEachProductsPolicy(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleUnderwriter))
+	setRatePolicy(t, stub, "policy_personal", domain.InterestRatePolicyRequest{
+		LoanType: "PERSONAL", FloorRate: 8, CapRate: 12, BaseRateSpread: 6, RiskTierAdjustments: map[string]float64{"HIGH": 2.5},
+	})
+	setRatePolicy(t, stub, "policy_student", domain.InterestRatePolicyRequest{LoanType: "STUDENT", FloorRate: 3, CapRate: 6})
+
+	rateRange := func(loanType, riskTier string) (*domain.InterestRateRange, error) {
+		payload, err := inTx(stub, "range_"+loanType+"_"+riskTier, func() ([]byte, error) {
+			return NewRatePolicyHandler().GetInterestRateRange(stub, []string{loanType, riskTier})
+		})
+		if err != nil {
+			return nil, err
+		}
+		var result domain.InterestRateRange
+		if err := json.Unmarshal(payload, &result); err != nil {
+			t.Fatalf("failed to decode rate range: %v", err)
+		}
+		return &result, nil
+	}
+
+	tests := []struct {
+		loanType, riskTier string
+		minRate, maxRate   float64
+	}{
+		// The spread alone sits below the floor; the tier adjustment lifts high risk customers above it
+		{"PERSONAL", "LOW", 8, 12},
+		{"PERSONAL", "HIGH", 8.5, 12},
+		{"STUDENT", "HIGH", 3, 6},
+	}
+	for _, tt := range tests {
+		result, err := rateRange(tt.loanType, tt.riskTier)
+		if err != nil {
+			t.Fatalf("GetInterestRateRange(%s, %s) failed: %v", tt.loanType, tt.riskTier, err)
+		}
+		if result.LoanType != tt.loanType || result.PolicyVersion != 1 || result.MinRate != tt.minRate || result.MaxRate != tt.maxRate {
+			t.Errorf("expected %s %s customers priced %.2f to %.2f under version 1, got %+v", tt.loanType, tt.riskTier, tt.minRate, tt.maxRate, result)
+		}
+	}
+	if _, err := rateRange("MORTGAGE", "LOW"); err == nil {
+		t.Errorf("expected no rate range for a loan type without a policy")
+	}
+
+	// Replacing a policy keeps the version earlier approvals were held to
+	setRatePolicy(t, stub, "policy_personal_v2", domain.InterestRatePolicyRequest{LoanType: "PERSONAL", FloorRate: 9, CapRate: 13})
+	payload, err := inTx(stub, "policy_v1", func() ([]byte, error) {
+		return NewRatePolicyHandler().GetInterestRatePolicy(stub, []string{"PERSONAL", "1"})
+	})
+	if err != nil {
+		t.Fatalf("GetInterestRatePolicy failed: %v", err)
+	}
+	var previous domain.InterestRatePolicy
+	if err := json.Unmarshal(payload, &previous); err != nil {
+		t.Fatalf("failed to decode policy: %v", err)
+	}
+	if previous.Version != 1 || previous.FloorRate != 8 || previous.CapRate != 12 {
+		t.Errorf("expected version 1 at 8 to 12, got %+v", previous)
+	}
+	if result, err := rateRange("PERSONAL", "LOW"); err != nil || result.PolicyVersion != 2 || result.MinRate != 9 {
+		t.Errorf("expected version 2 to price from 9, got %+v (%v)", result, err)
+	}
+}
+
+func TestApproveLoanRejectsRateOutsideProductPolicy(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleUnderwriter))
+	customer := eligibleCustomer("CUST_001", 65)
+	customer.RiskTier = "HIGH"
+	withCustomers(stub, customer)
+	setRatePolicy(t, stub, "policy_personal", domain.InterestRatePolicyRequest{
+		LoanType: "PERSONAL", FloorRate: 8, CapRate: 12, BaseRateSpread: 6, RiskTierAdjustments: map[string]float64{"HIGH": 2.5},
+	})
+	setRatePolicy(t, stub, "policy_student", domain.InterestRatePolicyRequest{LoanType: "STUDENT", FloorRate: 3, CapRate: 6})
+
+	approve := func(loanID string, rate float64) error {
+		_, err := inTx(stub, "approve_"+loanID, func() ([]byte, error) {
+			return NewLoanApplicationHandler().ApproveLoan(stub, []string{mustJSON(t, domain.LoanApprovalRequest{
+				LoanID: loanID, ApprovedAmount: 10000, InterestRate: rate, ActorID: "ACTOR_002",
+			})})
+		})
+		return err
+	}
+
+	// Above the floor but below what the policy allows high risk customers, and above the cap
+	seedLoan(t, stub, "LOAN_P1", validation.LoanStatusCreditApproval)
+	for _, rate := range []float64{8.25, 12.01} {
+		err := approve("LOAN_P1", rate)
+		if err == nil || !strings.Contains(err.Error(), "outside the 8.5000 to 12.0000 range version 1 of the PERSONAL rate policy") {
+			t.Errorf("expected %.2f to be refused by the PERSONAL policy, got %v", rate, err)
+		}
+	}
+	if loanApp := getLoan(t, stub, "LOAN_P1"); loanApp.Status != validation.LoanStatusCreditApproval || loanApp.InterestRate != nil {
+		t.Fatalf("refused approvals must leave the loan unpriced in CREDIT_APPROVAL, got %s", loanApp.Status)
+	}
+	if err := approve("LOAN_P1", 8.5); err != nil {
+		t.Fatalf("approval at the policy minimum failed: %v", err)
+	}
+	if loanApp := getLoan(t, stub, "LOAN_P1"); loanApp.Status != validation.LoanStatusApproved || loanApp.RatePolicyVersion != 1 {
+		t.Errorf("expected APPROVED under rate policy version 1, got %s under %d", loanApp.Status, loanApp.RatePolicyVersion)
+	}
+
+	// Each loan is held to its own product's policy, and products without one are not rate checked
+	seedLoan(t, stub, "LOAN_S1", validation.LoanStatusCreditApproval, func(loanApp *domain.LoanApplication) { loanApp.LoanType = "STUDENT" })
+	if err := approve("LOAN_S1", 6.5); err == nil {
+		t.Errorf("expected 6.5 to be refused by the STUDENT policy's 6 cap")
+	}
+	if err := approve("LOAN_S1", 5); err != nil {
+		t.Fatalf("approval within the STUDENT policy failed: %v", err)
+	}
+	seedLoan(t, stub, "LOAN_C1", validation.LoanStatusCreditApproval, func(loanApp *domain.LoanApplication) { loanApp.LoanType = "CREDIT_CARD" })
+	if err := approve("LOAN_C1", 24); err != nil {
+		t.Fatalf("approval of a loan type without a policy failed: %v", err)
+	}
+	if loanApp := getLoan(t, stub, "LOAN_C1"); loanApp.RatePolicyVersion != 0 {
+		t.Errorf("expected no rate policy version on an unchecked approval, got %d", loanApp.RatePolicyVersion)
+	}
+}
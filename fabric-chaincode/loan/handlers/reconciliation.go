@@ -0,0 +1,475 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	loanServices "github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// balanceTolerance is the largest stored/expected difference treated as rounding noise
+const balanceTolerance = 0.005
+
+// ReconciliationHandler handles loan transaction posting and balance reconciliation
+type ReconciliationHandler struct {
+	persistenceService *services.PersistenceService
+	eventService      *loanServices.EventService
+}
+
+// NewReconciliationHandler creates a new reconciliation handler
+func NewReconciliationHandler() *ReconciliationHandler {
+	return &ReconciliationHandler{
+		persistenceService: services.NewPersistenceService(),
+		eventService:      loanServices.NewEventService(),
+	}
+}
+
+// RecordLoanTransaction posts a disbursement, repayment, fee or interest charge against a loan
+func (h *ReconciliationHandler) RecordLoanTransaction(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.LoanTransactionRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse loan transaction request: %v", err)
+	}
+
+	// Get existing loan application
+	loanKey := fmt.Sprintf("LOAN_%s", req.LoanID)
+	var loanApp domain.LoanApplication
+	if err := h.persistenceService.Get(stub, loanKey, &loanApp); err != nil {
+		return nil, fmt.Errorf("loan application not found: %w", err)
+	}
+
+	// Only approved, disbursed or defaulted loans carry a balance
+	if loanApp.Status != validation.LoanStatusApproved && loanApp.Status != validation.LoanStatusDisbursed && loanApp.Status != validation.LoanStatusDelinquent && loanApp.Status != validation.LoanStatusDefaulted {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "transactions cannot be recorded for loan in status: %s", loanApp.Status)
+	}
+
+	// Disbursements carry funding records and are posted via DisburseLoan
+	if req.TransactionType == domain.LoanTransactionDisbursement {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "disbursements must be recorded via DisburseLoan")
+	}
+
+	// Borrower repayments are allocated to installments via RecordRepayment
+	if req.TransactionType == domain.LoanTransactionRepayment {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "repayments must be recorded via RecordRepayment")
+	}
+
+	// Money may only move with an active, onboarded counterparty
+	if req.CounterpartyID != "" {
+		if _, err := getActiveCounterparty(stub, h.persistenceService, req.CounterpartyID); err != nil {
+			return nil, err
+		}
+	}
+
+	previousBalance := loanApp.OutstandingBalance
+	txn, err := postLoanTransaction(stub, h.persistenceService, &loanApp, req.TransactionType, utils.NewMoney(req.Amount, loanApp.Currency), req.Reference, req.CounterpartyID, req.ActorID)
+	if err != nil {
+		return nil, err
+	}
+	newBalance := txn.BalanceAfter
+
+	// Update stored balance
+	if err := putLoanApplication(stub, h.persistenceService, &loanApp); err != nil {
+		return nil, fmt.Errorf("failed to update loan application: %w", err)
+	}
+
+	// Record history
+	if err := recordLoanHistory(stub, h.persistenceService, req.LoanID, string(req.TransactionType), "outstandingBalance", previousBalance.String(), newBalance.String(), req.ActorID); err != nil {
+		return nil, err
+	}
+
+	// Emit event
+	if err := h.eventService.EmitLoanTransactionRecorded(stub, txn, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(txn)
+}
+
+// GetLoanTransactions retrieves all transactions posted against a loan, in the order they were posted
+func (h *ReconciliationHandler) GetLoanTransactions(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	transactions, err := h.getLoanTransactions(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(transactions)
+}
+
+// ReconcileLoan recomputes the expected balance of a loan from its transactions and compares it to the stored balance
+func (h *ReconciliationHandler) ReconcileLoan(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.ReconcileLoanRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse reconciliation request: %v", err)
+	}
+
+	// Get existing loan application
+	loanKey := fmt.Sprintf("LOAN_%s", req.LoanID)
+	var loanApp domain.LoanApplication
+	if err := h.persistenceService.Get(stub, loanKey, &loanApp); err != nil {
+		return nil, fmt.Errorf("loan application not found: %w", err)
+	}
+
+	recon, err := h.computeReconciliation(stub, &loanApp)
+	if err != nil {
+		return nil, err
+	}
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	recon.ReconciliationID = services.GenerateDeterministicID(stub, config.LoanReconciliationPrefix)
+	recon.ReconciledBy = req.ActorID
+	recon.ReconciledDate = now
+
+	// Store reconciliation
+	reconKey := fmt.Sprintf("RECONCILIATION_%s", recon.ReconciliationID)
+	if err := h.persistenceService.Put(stub, reconKey, recon); err != nil {
+		return nil, fmt.Errorf("failed to store reconciliation: %w", err)
+	}
+
+	// Record history
+	if err := recordLoanHistory(stub, h.persistenceService, req.LoanID, "RECONCILIATION", "reconciliationStatus", "", string(recon.Status), req.ActorID); err != nil {
+		return nil, err
+	}
+
+	// Flag discrepancies for compliance follow-up
+	if recon.Status == domain.ReconciliationDiscrepancy {
+		if err := h.eventService.EmitLoanBalanceDiscrepancy(stub, recon, req.ActorID); err != nil {
+			return nil, fmt.Errorf("failed to emit event: %w", err)
+		}
+	}
+
+	return json.Marshal(recon)
+}
+
+// GetReconciliation retrieves a reconciliation by ID
+func (h *ReconciliationHandler) GetReconciliation(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	reconKey := fmt.Sprintf("RECONCILIATION_%s", args[0])
+	var recon domain.LoanReconciliation
+	if err := h.persistenceService.Get(stub, reconKey, &recon); err != nil {
+		return nil, fmt.Errorf("reconciliation not found: %w", err)
+	}
+
+	return json.Marshal(&recon)
+}
+
+// RequestBalanceRepair proposes correcting the stored balance to the reconciled expected balance
+func (h *ReconciliationHandler) RequestBalanceRepair(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.BalanceRepairRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse balance repair request: %v", err)
+	}
+
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "reason", "repair reason is required")
+	}
+
+	reconKey := fmt.Sprintf("RECONCILIATION_%s", req.ReconciliationID)
+	var recon domain.LoanReconciliation
+	if err := h.persistenceService.Get(stub, reconKey, &recon); err != nil {
+		return nil, fmt.Errorf("reconciliation not found: %w", err)
+	}
+
+	if recon.Status != domain.ReconciliationDiscrepancy {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "repair cannot be requested for reconciliation in status: %s", recon.Status)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	recon.Status = domain.ReconciliationRepairPending
+	recon.RepairRequestedBy = req.ActorID
+	recon.RepairReason = req.Reason
+	recon.RepairRequestedDate = &now
+
+	if err := h.persistenceService.Put(stub, reconKey, &recon); err != nil {
+		return nil, fmt.Errorf("failed to update reconciliation: %w", err)
+	}
+
+	// Record history
+	if err := recordLoanHistory(stub, h.persistenceService, recon.LoanID, "REPAIR_REQUEST", "reconciliationStatus", string(domain.ReconciliationDiscrepancy), string(recon.Status), req.ActorID); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(&recon)
+}
+
+// ApproveBalanceRepair signs off a pending repair and applies the expected balance to the loan
+func (h *ReconciliationHandler) ApproveBalanceRepair(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.BalanceRepairApprovalRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse balance repair approval request: %v", err)
+	}
+
+	reconKey := fmt.Sprintf("RECONCILIATION_%s", req.ReconciliationID)
+	var recon domain.LoanReconciliation
+	if err := h.persistenceService.Get(stub, reconKey, &recon); err != nil {
+		return nil, fmt.Errorf("reconciliation not found: %w", err)
+	}
+
+	if recon.Status != domain.ReconciliationRepairPending {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "repair cannot be approved for reconciliation in status: %s", recon.Status)
+	}
+
+	// Four-eyes: the approver must not be the requester
+	if req.ActorID == recon.RepairRequestedBy {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "repair must be approved by a different actor than the requester")
+	}
+
+	// Get existing loan application
+	loanKey := fmt.Sprintf("LOAN_%s", recon.LoanID)
+	var loanApp domain.LoanApplication
+	if err := h.persistenceService.Get(stub, loanKey, &loanApp); err != nil {
+		return nil, fmt.Errorf("loan application not found: %w", err)
+	}
+
+	// Reject stale repairs if the loan moved since it was reconciled
+	current, err := h.computeReconciliation(stub, &loanApp)
+	if err != nil {
+		return nil, err
+	}
+	if current.StoredBalance != recon.StoredBalance || current.ExpectedBalance != recon.ExpectedBalance {
+		return nil, services.NewChaincodeError(services.ErrCodeConflict, "", "loan %s changed since reconciliation %s; reconcile again before repairing", recon.LoanID, recon.ReconciliationID)
+	}
+
+	// Apply repair
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	loanApp.OutstandingBalance = recon.ExpectedBalance
+	loanApp.LastUpdated = now
+	loanApp.LastUpdatedBy = req.ActorID
+	if err := putLoanApplication(stub, h.persistenceService, &loanApp); err != nil {
+		return nil, fmt.Errorf("failed to update loan application: %w", err)
+	}
+
+	recon.Status = domain.ReconciliationRepaired
+	recon.RepairApprovedBy = req.ActorID
+	recon.RepairApprovedDate = &now
+	if err := h.persistenceService.Put(stub, reconKey, &recon); err != nil {
+		return nil, fmt.Errorf("failed to update reconciliation: %w", err)
+	}
+
+	// Record history
+	if err := recordLoanHistory(stub, h.persistenceService, recon.LoanID, "BALANCE_REPAIR", "outstandingBalance", recon.StoredBalance.String(), recon.ExpectedBalance.String(), req.ActorID); err != nil {
+		return nil, err
+	}
+
+	// Emit event
+	if err := h.eventService.EmitLoanBalanceRepaired(stub, &recon, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(&recon)
+}
+
+// Helper methods
+
+func (h *ReconciliationHandler) computeReconciliation(stub shim.ChaincodeStubInterface, loanApp *domain.LoanApplication) (*domain.LoanReconciliation, error) {
+	transactions, err := h.getLoanTransactions(stub, loanApp.LoanID)
+	if err != nil {
+		return nil, err
+	}
+
+	zero := utils.ZeroMoney(loanApp.Currency)
+	recon := &domain.LoanReconciliation{
+		LoanID:           loanApp.LoanID,
+		Currency:         loanApp.Currency,
+		StoredBalance:    loanApp.OutstandingBalance,
+		TotalDisbursed:   zero,
+		TotalRepaid:      zero,
+		TotalFees:        zero,
+		TotalInterest:    zero,
+		TransactionCount: len(transactions),
+	}
+
+	for _, txn := range transactions {
+		var total *utils.Money
+		switch txn.TransactionType {
+		case domain.LoanTransactionDisbursement:
+			total = &recon.TotalDisbursed
+		case domain.LoanTransactionRepayment:
+			total = &recon.TotalRepaid
+		case domain.LoanTransactionFee:
+			total = &recon.TotalFees
+		case domain.LoanTransactionInterest:
+			total = &recon.TotalInterest
+		default:
+			continue
+		}
+		sum, err := total.Add(txn.Amount)
+		if err != nil {
+			return nil, fmt.Errorf("transaction %s: %w", txn.TransactionID, err)
+		}
+		*total = sum
+	}
+
+	expected, err := sumMoney(recon.TotalDisbursed, recon.TotalFees, recon.TotalInterest)
+	if err == nil {
+		expected, err = expected.Sub(recon.TotalRepaid)
+	}
+	if err == nil {
+		recon.ExpectedBalance = expected
+		recon.Discrepancy, err = recon.StoredBalance.Sub(expected)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("failed to reconcile loan %s: %w", loanApp.LoanID, err)
+	}
+
+	recon.Status = domain.ReconciliationBalanced
+	if !recon.Discrepancy.IsZero() {
+		recon.Status = domain.ReconciliationDiscrepancy
+	}
+
+	return recon, nil
+}
+
+func (h *ReconciliationHandler) getLoanTransactions(stub shim.ChaincodeStubInterface, loanID string) ([]domain.LoanTransaction, error) {
+	iterator, err := stub.GetStateByPartialCompositeKey("LOAN_TRANSACTION", []string{loanID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loan transactions: %w", err)
+	}
+	defer iterator.Close()
+
+	transactions := []domain.LoanTransaction{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate loan transactions: %w", err)
+		}
+
+		var txn domain.LoanTransaction
+		if err := json.Unmarshal(response.Value, &txn); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal loan transaction: %w", err)
+		}
+
+		transactions = append(transactions, txn)
+	}
+
+	// Keys end in hashed transaction IDs, so the posting order is restored from the transactions
+	sort.SliceStable(transactions, func(i, j int) bool {
+		if !transactions[i].CreatedDate.Equal(transactions[j].CreatedDate) {
+			return transactions[i].CreatedDate.Before(transactions[j].CreatedDate)
+		}
+		return transactions[i].Sequence < transactions[j].Sequence
+	})
+	return transactions, nil
+}
+
+// postLoanTransaction stores a transaction against a loan and applies it to the loan's balance; the caller persists the loan
+func postLoanTransaction(stub shim.ChaincodeStubInterface, persistenceService *services.PersistenceService, loanApp *domain.LoanApplication, txnType domain.LoanTransactionType, amount utils.Money, reference, counterpartyID, actorID string) (*domain.LoanTransaction, error) {
+	if !amount.IsPositive() {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "transaction amount must be positive")
+	}
+	if amount.Currency() != loanApp.OutstandingBalance.Currency() {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "transaction currency %s does not match loan currency %s", amount.Currency(), loanApp.OutstandingBalance.Currency())
+	}
+
+	var newBalance utils.Money
+	switch txnType {
+	case domain.LoanTransactionDisbursement, domain.LoanTransactionFee, domain.LoanTransactionInterest:
+		newBalance, _ = loanApp.OutstandingBalance.Add(amount)
+	case domain.LoanTransactionRepayment:
+		if cmp, _ := amount.Cmp(loanApp.OutstandingBalance); cmp > 0 {
+			return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "repayment %s exceeds outstanding balance %s", amount, loanApp.OutstandingBalance)
+		}
+		newBalance, _ = loanApp.OutstandingBalance.Sub(amount)
+	default:
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid transaction type: %s", txnType)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	transactionID, sequence := services.GenerateSequencedID(stub, config.LoanTransactionPrefix)
+	txn := &domain.LoanTransaction{
+		TransactionID:   transactionID,
+		LoanID:          loanApp.LoanID,
+		TransactionType: txnType,
+		Currency:        loanApp.Currency,
+		Amount:          amount,
+		Reference:       reference,
+		CounterpartyID:  counterpartyID,
+		BalanceAfter:    newBalance,
+		TaxWithheld:     utils.ZeroMoney(loanApp.Currency),
+		CreatedDate:     now,
+		Sequence:        sequence,
+		CreatedBy:       actorID,
+	}
+
+	// Interest and fee income is subject to withholding in some jurisdictions
+	if err := applyWithholding(stub, persistenceService, loanApp, txn); err != nil {
+		return nil, err
+	}
+
+	txnKey, err := stub.CreateCompositeKey("LOAN_TRANSACTION", []string{loanApp.LoanID, txn.TransactionID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := persistenceService.Put(stub, txnKey, txn); err != nil {
+		return nil, fmt.Errorf("failed to store loan transaction: %w", err)
+	}
+
+	loanApp.OutstandingBalance = newBalance
+	loanApp.LastUpdated = now
+	loanApp.LastUpdatedBy = actorID
+
+	return txn, nil
+}
+
+// sumMoney adds amounts of the same currency
+func sumMoney(first utils.Money, rest ...utils.Money) (utils.Money, error) {
+	total := first
+	for _, amount := range rest {
+		var err error
+		if total, err = total.Add(amount); err != nil {
+			return utils.Money{}, err
+		}
+	}
+	return total, nil
+}
+
+func roundToCents(amount float64) float64 {
+	return math.Round(amount*100) / 100
+}
+
+func formatAmount(amount float64) string {
+	return fmt.Sprintf("%.2f", amount)
+}
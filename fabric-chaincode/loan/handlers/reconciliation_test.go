@@ -0,0 +1,242 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// postFee records a fee against a loan as its own transaction
+func postFee(t *testing.T, stub *shimtest.MockStub, txID, loanID string, amount float64) error {
+	t.Helper()
+	_, err := inTx(stub, txID, func() ([]byte, error) {
+		return NewReconciliationHandler().RecordLoanTransaction(stub, []string{mustJSON(t, domain.LoanTransactionRequest{
+			LoanID: loanID, TransactionType: domain.LoanTransactionFee, Amount: amount, Reference: "FEE-" + txID, ActorID: "ACTOR_005",
+		})})
+	})
+	return err
+}
+
+// reconcile runs ReconcileLoan as its own transaction and decodes the result
+func reconcile(t *testing.T, stub *shimtest.MockStub, txID, loanID string) *domain.LoanReconciliation {
+	t.Helper()
+	payload, err := inTx(stub, txID, func() ([]byte, error) {
+		return NewReconciliationHandler().ReconcileLoan(stub, []string{mustJSON(t, domain.ReconcileLoanRequest{LoanID: loanID, ActorID: "ACTOR_006"})})
+	})
+	if err != nil {
+		t.Fatalf("reconciliation failed: %v", err)
+	}
+	var recon domain.LoanReconciliation
+	if err := json.Unmarshal(payload, &recon); err != nil {
+		t.Fatalf("failed to decode reconciliation: %v", err)
+	}
+	return &recon
+}
+
+// decideRepair requests or approves a balance repair as its own transaction
+func decideRepair(t *testing.T, stub *shimtest.MockStub, txID string, call func(*ReconciliationHandler) ([]byte, error)) error {
+	t.Helper()
+	_, err := inTx(stub, txID, func() ([]byte, error) {
+		return call(NewReconciliationHandler())
+	})
+	return err
+}
+
+// fundedLoan seeds an approved loan and disburses part of it
+func fundedLoan(t *testing.T, stub *shimtest.MockStub, loanID string, amount float64) {
+	t.Helper()
+	seedLoan(t, stub, loanID, validation.LoanStatusApproved, approvedTerms(5000, 6))
+	if _, err := disburse(t, stub, "fund_"+loanID, loanID, amount); err != nil {
+		t.Fatalf("disbursement failed: %v", err)
+	}
+}
+
+func TestReconcileLoanBalancesPostedTransactions(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	fundedLoan(t, stub, "LOAN_C1", 3000)
+
+	// A fee in fractions of a cent posts to the balance rounded half away from zero
+	if err := postFee(t, stub, "fee_1", "LOAN_C1", 12.125); err != nil {
+		t.Fatalf("fee failed: %v", err)
+	}
+	if loanApp := getLoan(t, stub, "LOAN_C1"); loanApp.OutstandingBalance.Float64() != 3012.13 {
+		t.Fatalf("expected balance 3012.13, got %.4f", loanApp.OutstandingBalance.Float64())
+	}
+
+	recon := reconcile(t, stub, "reconcile_1", "LOAN_C1")
+	if recon.Status != domain.ReconciliationBalanced || recon.ExpectedBalance.Float64() != 3012.13 || recon.Discrepancy.Float64() != 0 || recon.TransactionCount != 2 {
+		t.Errorf("expected a balanced reconciliation over 2 transactions, got %+v", recon)
+	}
+	if recon.TotalDisbursed.Float64() != 3000 || recon.TotalFees.Float64() != 12.13 {
+		t.Errorf("expected totals of 3000 disbursed and 12.13 fees, got %+v", recon)
+	}
+}
+
+func TestBalanceRepairRequiresSecondApprover(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	fundedLoan(t, stub, "LOAN_C2", 2000)
+
+	// The stored balance drifts from the transactions posted
+	_, err := inTx(stub, "drift", func() ([]byte, error) {
+		loanApp := getLoan(t, stub, "LOAN_C2")
+		loanApp.OutstandingBalance = utils.NewMoney(2100, "USD")
+		return nil, putLoanApplication(stub, services.NewPersistenceService(), loanApp)
+	})
+	if err != nil {
+		t.Fatalf("failed to corrupt balance: %v", err)
+	}
+
+	recon := reconcile(t, stub, "reconcile_drift", "LOAN_C2")
+	if recon.Status != domain.ReconciliationDiscrepancy || recon.Discrepancy.Float64() != 100 || recon.ExpectedBalance.Float64() != 2000 {
+		t.Fatalf("expected a discrepancy of 100, got %+v", recon)
+	}
+
+	request := func(reason string) func(*ReconciliationHandler) ([]byte, error) {
+		return func(h *ReconciliationHandler) ([]byte, error) {
+			return h.RequestBalanceRepair(stub, []string{mustJSON(t, domain.BalanceRepairRequest{ReconciliationID: recon.ReconciliationID, Reason: reason, ActorID: "ACTOR_006"})})
+		}
+	}
+	approve := func(actorID string) func(*ReconciliationHandler) ([]byte, error) {
+		return func(h *ReconciliationHandler) ([]byte, error) {
+			return h.ApproveBalanceRepair(stub, []string{mustJSON(t, domain.BalanceRepairApprovalRequest{ReconciliationID: recon.ReconciliationID, ActorID: actorID})})
+		}
+	}
+
+	expectErrorCode(t, decideRepair(t, stub, "repair_blank", request(" ")), services.ErrCodeInvalidArgument)
+	if err := decideRepair(t, stub, "repair_request", request("Balance posted twice")); err != nil {
+		t.Fatalf("repair request failed: %v", err)
+	}
+	expectErrorCode(t, decideRepair(t, stub, "repair_self", approve("ACTOR_006")), services.ErrCodeInvalidArgument)
+	if loanApp := getLoan(t, stub, "LOAN_C2"); loanApp.OutstandingBalance.Float64() != 2100 {
+		t.Fatalf("an unapproved repair must not change the balance, got %.2f", loanApp.OutstandingBalance.Float64())
+	}
+
+	if err := decideRepair(t, stub, "repair_approve", approve("ACTOR_007")); err != nil {
+		t.Fatalf("repair approval failed: %v", err)
+	}
+	if loanApp := getLoan(t, stub, "LOAN_C2"); loanApp.OutstandingBalance.Float64() != 2000 {
+		t.Errorf("expected the balance repaired to 2000, got %.2f", loanApp.OutstandingBalance.Float64())
+	}
+
+	// Replaying the approval finds the repair already applied
+	expectErrorCode(t, decideRepair(t, stub, "repair_replay", approve("ACTOR_007")), services.ErrCodeInvalidTransition)
+	if again := reconcile(t, stub, "reconcile_repaired", "LOAN_C2"); again.Status != domain.ReconciliationBalanced {
+		t.Errorf("expected the repaired loan to reconcile, got %+v", again)
+	}
+}
+
+func TestBalanceRepairRejectedOnceLoanMoves(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	fundedLoan(t, stub, "LOAN_C3", 2000)
+
+	_, err := inTx(stub, "drift", func() ([]byte, error) {
+		loanApp := getLoan(t, stub, "LOAN_C3")
+		loanApp.OutstandingBalance = utils.NewMoney(1950, "USD")
+		return nil, putLoanApplication(stub, services.NewPersistenceService(), loanApp)
+	})
+	if err != nil {
+		t.Fatalf("failed to corrupt balance: %v", err)
+	}
+	recon := reconcile(t, stub, "reconcile_drift", "LOAN_C3")
+	err = decideRepair(t, stub, "repair_request", func(h *ReconciliationHandler) ([]byte, error) {
+		return h.RequestBalanceRepair(stub, []string{mustJSON(t, domain.BalanceRepairRequest{ReconciliationID: recon.ReconciliationID, Reason: "Missed fee reversal", ActorID: "ACTOR_006"})})
+	})
+	if err != nil {
+		t.Fatalf("repair request failed: %v", err)
+	}
+
+	// A fee posted after the reconciliation makes the proposed balance stale
+	if err := postFee(t, stub, "fee_late", "LOAN_C3", 30); err != nil {
+		t.Fatalf("fee failed: %v", err)
+	}
+	err = decideRepair(t, stub, "repair_stale", func(h *ReconciliationHandler) ([]byte, error) {
+		return h.ApproveBalanceRepair(stub, []string{mustJSON(t, domain.BalanceRepairApprovalRequest{ReconciliationID: recon.ReconciliationID, ActorID: "ACTOR_007"})})
+	})
+	expectErrorCode(t, err, services.ErrCodeConflict)
+	if loanApp := getLoan(t, stub, "LOAN_C3"); loanApp.OutstandingBalance.Float64() != 1980 {
+		t.Errorf("a stale repair must not be applied, got balance %.2f", loanApp.OutstandingBalance.Float64())
+	}
+}
+
+func TestRecordLoanTransactionRejectsInvalidPostings(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	seedLoan(t, stub, "LOAN_C4", validation.LoanStatusSubmitted)
+	fundedLoan(t, stub, "LOAN_C5", 1000)
+
+	tests := []struct {
+		name    string
+		txID    string
+		loanID  string
+		txnType domain.LoanTransactionType
+		amount  float64
+		code    string
+	}{
+		{"loan without a balance", "post_submitted", "LOAN_C4", domain.LoanTransactionFee, 10, services.ErrCodeInvalidTransition},
+		{"disbursement outside DisburseLoan", "post_disbursement", "LOAN_C5", domain.LoanTransactionDisbursement, 10, services.ErrCodeInvalidArgument},
+		{"repayment outside RecordRepayment", "post_repayment", "LOAN_C5", domain.LoanTransactionRepayment, 10, services.ErrCodeInvalidArgument},
+		{"non-positive amount", "post_zero", "LOAN_C5", domain.LoanTransactionFee, 0, services.ErrCodeInvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := inTx(stub, tt.txID, func() ([]byte, error) {
+				return NewReconciliationHandler().RecordLoanTransaction(stub, []string{mustJSON(t, domain.LoanTransactionRequest{
+					LoanID: tt.loanID, TransactionType: tt.txnType, Amount: tt.amount, ActorID: "ACTOR_005",
+				})})
+			})
+			expectErrorCode(t, err, tt.code)
+		})
+	}
+	if loanApp := getLoan(t, stub, "LOAN_C5"); loanApp.OutstandingBalance.Float64() != 1000 {
+		t.Errorf("rejected postings must not change the balance, got %.2f", loanApp.OutstandingBalance.Float64())
+	}
+}
+
+func TestGetLoanTransactionsReturnsPostingOrder(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	loanApp := seedLoan(t, stub, "LOAN_C5", validation.LoanStatusDisbursed)
+	invocation := services.NewInvocation(stub)
+
+	// Two postings per transaction, the transactions a day apart
+	var posted []string
+	start := time.Date(2026, 4, 1, 9, 0, 0, 0, time.UTC)
+	for day, txID := range []string{"post_z", "post_m", "post_a"} {
+		_, err := inTxAt(stub, txID, start.AddDate(0, 0, day), func() ([]byte, error) {
+			for _, reference := range []string{txID + "_1", txID + "_2"} {
+				posted = append(posted, reference)
+				if _, err := postLoanTransaction(invocation, services.NewPersistenceService(), loanApp, domain.LoanTransactionFee, utils.NewMoney(10, "USD"), reference, "", "ACTOR_005"); err != nil {
+					return nil, err
+				}
+			}
+			return nil, nil
+		})
+		if err != nil {
+			t.Fatalf("posting failed: %v", err)
+		}
+	}
+
+	payload, err := inTx(stub, "list", func() ([]byte, error) {
+		return NewReconciliationHandler().GetLoanTransactions(stub, []string{"LOAN_C5"})
+	})
+	if err != nil {
+		t.Fatalf("GetLoanTransactions failed: %v", err)
+	}
+	var transactions []domain.LoanTransaction
+	if err := json.Unmarshal(payload, &transactions); err != nil {
+		t.Fatalf("failed to decode transactions: %v", err)
+	}
+	if len(transactions) != len(posted) {
+		t.Fatalf("expected %d transactions, got %d", len(posted), len(transactions))
+	}
+	for i, txn := range transactions {
+		if txn.Reference != posted[i] {
+			t.Fatalf("expected transactions in posting order %v, got %s at %d", posted, txn.Reference, i)
+		}
+	}
+}
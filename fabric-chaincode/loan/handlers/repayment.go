@@ -0,0 +1,240 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	loanServices "github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// RepaymentHandler handles repayment schedules and installment servicing
+type RepaymentHandler struct {
+	persistenceService *services.PersistenceService
+	eventService      *loanServices.EventService
+	scheduleService   *loanServices.ScheduleService
+	defaultReportingService *loanServices.DefaultReportingService
+}
+
+// NewRepaymentHandler creates a new repayment handler
+func NewRepaymentHandler() *RepaymentHandler {
+	return &RepaymentHandler{
+		persistenceService: services.NewPersistenceService(),
+		eventService:      loanServices.NewEventService(),
+		scheduleService:   loanServices.NewScheduleService(),
+		defaultReportingService: loanServices.NewDefaultReportingService(),
+	}
+}
+
+// RecordRepayment applies a borrower repayment to the oldest unpaid installments, interest first
+func (h *RepaymentHandler) RecordRepayment(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.RepaymentRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse repayment request: %v", err)
+	}
+
+	// Get existing loan application
+	loanKey := fmt.Sprintf("LOAN_%s", req.LoanID)
+	var loanApp domain.LoanApplication
+	if err := h.persistenceService.Get(stub, loanKey, &loanApp); err != nil {
+		return nil, fmt.Errorf("loan application not found: %w", err)
+	}
+
+	if loanApp.Status != validation.LoanStatusDisbursed && loanApp.Status != validation.LoanStatusDelinquent && loanApp.Status != validation.LoanStatusDefaulted {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "repayments cannot be recorded for loan in status: %s", loanApp.Status)
+	}
+	if req.Amount <= 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "repayment amount must be positive")
+	}
+
+	// Money may only move with an active, onboarded counterparty
+	if req.CounterpartyID != "" {
+		if _, err := getActiveCounterparty(stub, h.persistenceService, req.CounterpartyID); err != nil {
+			return nil, err
+		}
+	}
+
+	installments, err := h.scheduleService.GetSchedule(stub, req.LoanID)
+	if err != nil {
+		return nil, err
+	}
+	if len(installments) == 0 {
+		return nil, fmt.Errorf("loan %s has no repayment schedule", req.LoanID)
+	}
+
+	// Allocation runs in minor units of the loan's currency so partial payments never drift
+	amount := utils.NewMoney(req.Amount, loanApp.Currency)
+	totalUnpaid := utils.ZeroMoney(loanApp.Currency)
+	for i := range installments {
+		if totalUnpaid, err = totalUnpaid.Add(installments[i].AmountOutstanding()); err != nil {
+			return nil, fmt.Errorf("failed to total repayment schedule: %w", err)
+		}
+	}
+	if cmp, err := amount.Cmp(totalUnpaid); err != nil {
+		return nil, err
+	} else if cmp > 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "repayment %s exceeds scheduled amount outstanding %s", amount, totalUnpaid)
+	}
+
+	// Allocate oldest installment first, interest before principal
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	remaining := amount
+	interestAllocated := utils.ZeroMoney(loanApp.Currency)
+	allocations := []domain.RepaymentAllocation{}
+	for i := range installments {
+		if !remaining.IsPositive() {
+			break
+		}
+		installment := &installments[i]
+		if installment.Status == domain.InstallmentStatusPaid {
+			continue
+		}
+
+		interestPaid, err := remaining.Min(installment.InterestOutstanding())
+		if err != nil {
+			return nil, err
+		}
+		if remaining, err = remaining.Sub(interestPaid); err != nil {
+			return nil, err
+		}
+		principalPaid, err := remaining.Min(installment.PrincipalOutstanding())
+		if err != nil {
+			return nil, err
+		}
+		if remaining, err = remaining.Sub(principalPaid); err != nil {
+			return nil, err
+		}
+
+		if installment.InterestPaid, err = installment.InterestPaid.Add(interestPaid); err != nil {
+			return nil, err
+		}
+		if installment.PrincipalPaid, err = installment.PrincipalPaid.Add(principalPaid); err != nil {
+			return nil, err
+		}
+		installment.Status = domain.InstallmentStatusPartial
+		if !installment.AmountOutstanding().IsPositive() {
+			installment.Status = domain.InstallmentStatusPaid
+			installment.PaidDate = &now
+		}
+		if err := h.scheduleService.SaveInstallment(stub, installment); err != nil {
+			return nil, err
+		}
+
+		if interestAllocated, err = interestAllocated.Add(interestPaid); err != nil {
+			return nil, err
+		}
+		allocations = append(allocations, domain.RepaymentAllocation{
+			InstallmentNumber: installment.InstallmentNumber,
+			InterestPaid:      interestPaid.Float64(),
+			PrincipalPaid:     principalPaid.Float64(),
+		})
+	}
+
+	// Charge the scheduled interest being settled so the ledger balance stays principal-accurate
+	previousBalance := loanApp.OutstandingBalance
+	if interestAllocated.IsPositive() {
+		if _, err := postLoanTransaction(stub, h.persistenceService, &loanApp, domain.LoanTransactionInterest, interestAllocated, req.Reference, "", req.ActorID); err != nil {
+			return nil, err
+		}
+	}
+	txn, err := postLoanTransaction(stub, h.persistenceService, &loanApp, domain.LoanTransactionRepayment, amount, req.Reference, req.CounterpartyID, req.ActorID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Clearing the arrears returns a delinquent loan to DISBURSED
+	if err := h.cureDelinquency(stub, &loanApp, installments, req.ActorID); err != nil {
+		return nil, err
+	}
+
+	// Store updated loan application
+	if err := putLoanApplication(stub, h.persistenceService, &loanApp); err != nil {
+		return nil, fmt.Errorf("failed to update loan application: %w", err)
+	}
+
+	// Record history
+	if err := recordLoanHistory(stub, h.persistenceService, req.LoanID, "REPAYMENT", "outstandingBalance", previousBalance.String(), loanApp.OutstandingBalance.String(), req.ActorID); err != nil {
+		return nil, err
+	}
+
+	result := &domain.RepaymentResult{
+		LoanID:             req.LoanID,
+		Amount:             amount.Float64(),
+		TransactionID:      txn.TransactionID,
+		Allocations:        allocations,
+		OutstandingBalance: loanApp.OutstandingBalance.Float64(),
+	}
+
+	// Emit event
+	if err := h.eventService.EmitRepaymentRecorded(stub, &loanApp, result, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(result)
+}
+
+// GetRepaymentSchedule retrieves the amortization schedule of a loan
+func (h *RepaymentHandler) GetRepaymentSchedule(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	installments, err := h.scheduleService.GetSchedule(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(installments)
+}
+
+// QueryOverdueInstallments retrieves past-due unpaid installments for a loan, or for all loans when the loan ID is empty
+func (h *RepaymentHandler) QueryOverdueInstallments(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	attributes := []string{}
+	if args[0] != "" {
+		attributes = append(attributes, args[0])
+	}
+
+	iterator, err := stub.GetStateByPartialCompositeKey("REPAYMENT_INSTALLMENT", attributes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query installments: %w", err)
+	}
+	defer iterator.Close()
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	overdue := []domain.Installment{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate installments: %w", err)
+		}
+
+		var installment domain.Installment
+		if err := json.Unmarshal(response.Value, &installment); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal installment: %w", err)
+		}
+
+		if loanServices.IsInstallmentOverdue(&installment, now) {
+			overdue = append(overdue, installment)
+		}
+	}
+
+	return json.Marshal(overdue)
+}
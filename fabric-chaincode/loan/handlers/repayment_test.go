@@ -0,0 +1,236 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	loanServices "github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// scheduleStart is the disbursement date serviced test loans are scheduled from
+var scheduleStart = time.Date(2026, 1, 15, 0, 0, 0, 0, time.UTC)
+
+// seedServicedLoan stores a fully disbursed loan with its amortization schedule
+func seedServicedLoan(t *testing.T, stub *shimtest.MockStub, loanID, currency string, principal, rate float64) []domain.Installment {
+	t.Helper()
+	loanApp := seedLoan(t, stub, loanID, validation.LoanStatusDisbursed, func(loanApp *domain.LoanApplication) {
+		loanApp.Currency = currency
+		loanApp.RequestedAmount = utils.NewMoney(principal, currency)
+		approvedTerms(principal, rate)(loanApp)
+		loanApp.DisbursedAmount = utils.NewMoney(principal, currency)
+		loanApp.OutstandingBalance = utils.NewMoney(principal, currency)
+		loanApp.DisbursementDate = &scheduleStart
+	})
+
+	var installments []domain.Installment
+	_, err := inTx(stub, "schedule_"+loanID, func() ([]byte, error) {
+		var err error
+		installments, err = loanServices.NewScheduleService().GenerateSchedule(stub, loanApp, scheduleStart)
+		return nil, err
+	})
+	if err != nil {
+		t.Fatalf("failed to generate schedule: %v", err)
+	}
+	return installments
+}
+
+// repay runs RecordRepayment as its own transaction and decodes the result
+func repay(t *testing.T, stub *shimtest.MockStub, txID, loanID string, amount float64) (*domain.RepaymentResult, error) {
+	t.Helper()
+	payload, err := inTx(stub, txID, func() ([]byte, error) {
+		return NewRepaymentHandler().RecordRepayment(stub, []string{mustJSON(t, domain.RepaymentRequest{
+			LoanID:    loanID,
+			Amount:    amount,
+			Reference: "PAY-" + txID,
+			ActorID:   "ACTOR_005",
+		})})
+	})
+	if err != nil {
+		return nil, err
+	}
+	var result domain.RepaymentResult
+	if err := json.Unmarshal(payload, &result); err != nil {
+		t.Fatalf("failed to decode repayment: %v", err)
+	}
+	return &result, nil
+}
+
+// getSchedule loads a loan's stored installments
+func getSchedule(t *testing.T, stub *shimtest.MockStub, loanID string) []domain.Installment {
+	t.Helper()
+	installments, err := loanServices.NewScheduleService().GetSchedule(stub, loanID)
+	if err != nil {
+		t.Fatalf("failed to load schedule: %v", err)
+	}
+	return installments
+}
+
+func TestRecordRepaymentAllocatesInterestFirst(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	installments := seedServicedLoan(t, stub, "LOAN_R1", "USD", 12000, 12)
+	first := installments[0]
+
+	// A payment smaller than the interest due settles interest only, rounded to whole cents
+	partial, err := repay(t, stub, "repay_partial", "LOAN_R1", 50.125)
+	if err != nil {
+		t.Fatalf("partial repayment failed: %v", err)
+	}
+	if partial.Amount != 50.13 || len(partial.Allocations) != 1 || partial.Allocations[0].InterestPaid != 50.13 || partial.Allocations[0].PrincipalPaid != 0 {
+		t.Fatalf("expected 50.13 allocated to interest, got %+v", partial)
+	}
+	if partial.OutstandingBalance != 12000 {
+		t.Errorf("interest settled must not reduce principal, got balance %.2f", partial.OutstandingBalance)
+	}
+	if stored := getSchedule(t, stub, "LOAN_R1")[0]; stored.Status != domain.InstallmentStatusPartial {
+		t.Errorf("expected installment 1 PARTIAL, got %s", stored.Status)
+	}
+
+	// Paying the rest of the installment clears the remaining interest before any principal
+	rest := utils.MoneyFromMinorUnits(first.TotalDue.MinorUnits()-5013, "USD")
+	settled, err := repay(t, stub, "repay_rest", "LOAN_R1", rest.Float64())
+	if err != nil {
+		t.Fatalf("repayment of the installment failed: %v", err)
+	}
+	allocation := settled.Allocations[0]
+	if allocation.InterestPaid != utils.MoneyFromMinorUnits(first.InterestDue.MinorUnits()-5013, "USD").Float64() || allocation.PrincipalPaid != first.PrincipalDue.Float64() {
+		t.Errorf("unexpected allocation %+v for installment %+v", allocation, first)
+	}
+	if settled.OutstandingBalance != roundToCents(12000-first.PrincipalDue.Float64()) {
+		t.Errorf("expected balance %.2f, got %.2f", 12000-first.PrincipalDue.Float64(), settled.OutstandingBalance)
+	}
+
+	schedule := getSchedule(t, stub, "LOAN_R1")
+	if schedule[0].Status != domain.InstallmentStatusPaid || schedule[0].PaidDate == nil || schedule[1].Status != domain.InstallmentStatusPending {
+		t.Errorf("expected only installment 1 PAID, got %s and %s", schedule[0].Status, schedule[1].Status)
+	}
+}
+
+func TestRecordRepaymentReplayAfterPayoffIsRejected(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	installments := seedServicedLoan(t, stub, "LOAN_R2", "USD", 1200, 6)
+
+	total := utils.ZeroMoney("USD")
+	for _, installment := range installments {
+		total, _ = total.Add(installment.TotalDue)
+	}
+	result, err := repay(t, stub, "repay_all", "LOAN_R2", total.Float64())
+	if err != nil {
+		t.Fatalf("payoff failed: %v", err)
+	}
+	if result.OutstandingBalance != 0 || len(result.Allocations) != len(installments) {
+		t.Fatalf("expected every installment settled and no balance, got %+v", result)
+	}
+
+	// A resubmitted payment has nothing left to settle and is refused without touching the loan
+	_, err = repay(t, stub, "repay_replay", "LOAN_R2", total.Float64())
+	expectErrorCode(t, err, services.ErrCodeInvalidArgument)
+	if loanApp := getLoan(t, stub, "LOAN_R2"); loanApp.OutstandingBalance.Float64() != 0 || loanApp.Version != 2 {
+		t.Errorf("replay must leave the loan unchanged, got balance %.2f at version %d", loanApp.OutstandingBalance.Float64(), loanApp.Version)
+	}
+}
+
+func TestRecordRepaymentRejectsInvalidRequests(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	seedServicedLoan(t, stub, "LOAN_R3", "USD", 1200, 6)
+	seedLoan(t, stub, "LOAN_R4", validation.LoanStatusApproved, approvedTerms(1200, 6))
+
+	tests := []struct {
+		name   string
+		loanID string
+		amount float64
+		code   string
+	}{
+		{"loan not yet disbursed", "LOAN_R4", 100, services.ErrCodeInvalidTransition},
+		{"zero amount", "LOAN_R3", 0, services.ErrCodeInvalidArgument},
+		{"more than scheduled", "LOAN_R3", 5000, services.ErrCodeInvalidArgument},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := repay(t, stub, "repay_"+tt.name, tt.loanID, tt.amount)
+			expectErrorCode(t, err, tt.code)
+		})
+	}
+	if schedule := getSchedule(t, stub, "LOAN_R3"); schedule[0].Status != domain.InstallmentStatusPending {
+		t.Errorf("rejected repayments must not touch the schedule, got %s", schedule[0].Status)
+	}
+}
+
+func TestRecordRepaymentRoundsToCurrencyMinorUnits(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	seedServicedLoan(t, stub, "LOAN_R5", "JPY", 1200000, 6)
+
+	// Yen have no minor units, so a fractional payment is taken to the whole yen
+	result, err := repay(t, stub, "repay_yen", "LOAN_R5", 1000.4)
+	if err != nil {
+		t.Fatalf("repayment failed: %v", err)
+	}
+	if result.Amount != 1000 || result.Allocations[0].InterestPaid+result.Allocations[0].PrincipalPaid != 1000 {
+		t.Errorf("expected 1000 yen allocated, got %+v", result)
+	}
+	if stored := getSchedule(t, stub, "LOAN_R5")[0]; stored.InterestPaid.MinorUnits() != 1000 || stored.Currency != "JPY" {
+		t.Errorf("expected 1000 yen of interest paid, got %s", stored.InterestPaid)
+	}
+}
+
+func TestRecordRepaymentKeepsThreeDecimalBalances(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	installments := seedServicedLoan(t, stub, "LOAN_R6", "KWD", 1500.125, 6)
+	first := installments[0]
+
+	// Dinars have three minor units, so the balance must move by the exact fils of principal paid
+	if _, err := repay(t, stub, "repay_kwd", "LOAN_R6", first.TotalDue.Float64()); err != nil {
+		t.Fatalf("repayment failed: %v", err)
+	}
+	expected := utils.MoneyFromMinorUnits(1500125-first.PrincipalDue.MinorUnits(), "KWD")
+	loanApp := getLoan(t, stub, "LOAN_R6")
+	if loanApp.OutstandingBalance != expected {
+		t.Errorf("expected balance %s KWD, got %s %s", expected, loanApp.OutstandingBalance, loanApp.OutstandingBalance.Currency())
+	}
+
+	transactions, err := NewReconciliationHandler().getLoanTransactions(stub, "LOAN_R6")
+	if err != nil {
+		t.Fatalf("failed to list transactions: %v", err)
+	}
+	last := transactions[len(transactions)-1]
+	if last.TransactionType != domain.LoanTransactionRepayment || last.Currency != "KWD" || last.Amount != first.TotalDue || last.BalanceAfter != expected {
+		t.Errorf("expected a %s KWD repayment leaving %s, got %+v", first.TotalDue, expected, last)
+	}
+}
+
+func TestGetDelinquentPortfolioTotalsEachCurrency(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	seedServicedLoan(t, stub, "LOAN_R7", "USD", 1200, 6)
+	seedServicedLoan(t, stub, "LOAN_R8", "KWD", 600.125, 6)
+
+	// Both loans have missed their first installment
+	payload, err := inTxAt(stub, "portfolio", scheduleStart.AddDate(0, 1, 10), func() ([]byte, error) {
+		return NewRepaymentHandler().GetDelinquentPortfolio(stub, []string{""})
+	})
+	if err != nil {
+		t.Fatalf("GetDelinquentPortfolio failed: %v", err)
+	}
+	var portfolio domain.DelinquentPortfolio
+	if err := json.Unmarshal(payload, &portfolio); err != nil {
+		t.Fatalf("failed to decode portfolio: %v", err)
+	}
+	if portfolio.Count != 2 {
+		t.Fatalf("expected 2 loans in arrears, got %+v", portfolio)
+	}
+
+	// Each currency is totalled on its own, to its own minor units
+	if len(portfolio.OutstandingBalances) != 2 || portfolio.OutstandingBalances["USD"].String() != "1200" || portfolio.OutstandingBalances["KWD"].String() != "600.125" {
+		t.Errorf("expected 1200 USD and 600.125 KWD outstanding, got %v", portfolio.OutstandingBalances)
+	}
+	for _, loan := range portfolio.Loans {
+		if overdue := portfolio.AmountsOverdue[loan.Currency]; overdue != utils.NewMoney(loan.AmountOverdue, loan.Currency) {
+			t.Errorf("expected %.3f %s overdue, got %s", loan.AmountOverdue, loan.Currency, overdue)
+		}
+	}
+}
@@ -0,0 +1,266 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	loanServices "github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// SandboxPurgeHandler removes UAT records created by sandbox actors
+type SandboxPurgeHandler struct {
+	persistenceService *services.PersistenceService
+	eventService      *loanServices.EventService
+	sandboxService    *services.SandboxService
+}
+
+// NewSandboxPurgeHandler creates a new sandbox purge handler
+func NewSandboxPurgeHandler() *SandboxPurgeHandler {
+	return &SandboxPurgeHandler{
+		persistenceService: services.NewPersistenceService(),
+		eventService:      loanServices.NewEventService(),
+		sandboxService:    services.NewSandboxService(),
+	}
+}
+
+// PurgeSandboxLoan deletes a sandbox loan and every record that hangs off it
+func (h *SandboxPurgeHandler) PurgeSandboxLoan(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	req, err := parseSandboxPurgeRequest(args)
+	if err != nil {
+		return nil, err
+	}
+
+	loanKey := fmt.Sprintf("LOAN_%s", req.EntityID)
+	var loanApp domain.LoanApplication
+	if err := h.persistenceService.Get(stub, loanKey, &loanApp); err != nil {
+		return nil, fmt.Errorf("loan application not found: %w", err)
+	}
+	if !loanApp.Sandbox {
+		return nil, fmt.Errorf("loan %s is not a sandbox record", req.EntityID)
+	}
+
+	deleted := 0
+	deleteKey := func(key string) error {
+		if err := stub.DelState(key); err != nil {
+			return fmt.Errorf("failed to delete %s: %w", key, err)
+		}
+		deleted++
+		return nil
+	}
+	deleteRange := func(objectType string, attributes ...string) ([][]byte, error) {
+		values, err := h.sandboxService.DeleteByPartialCompositeKey(stub, objectType, attributes)
+		deleted += len(values)
+		return values, err
+	}
+
+	// Records keyed directly by loan
+	for _, objectType := range []string{"LOAN_TRANSACTION", "LOAN_DISBURSEMENT", "REPAYMENT_INSTALLMENT", "LOAN_PARTICIPATION", "HISTORY"} {
+		if _, err := deleteRange(objectType, req.EntityID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Withholding records and their remittance period index entries
+	withholdingRecords, err := deleteRange("LOAN_WITHHOLDING", req.EntityID)
+	if err != nil {
+		return nil, err
+	}
+	for _, value := range withholdingRecords {
+		var record domain.WithholdingRecord
+		if err := json.Unmarshal(value, &record); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal withholding record: %w", err)
+		}
+		if _, err := deleteRange("WITHHOLDING_BY_PERIOD", record.Jurisdiction, record.Period, record.LoanID, record.TransactionID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Collateral records referenced from the loan index
+	collateralIDs, err := deleteRange("LOAN_COLLATERAL", req.EntityID)
+	if err != nil {
+		return nil, err
+	}
+	for _, collateralID := range collateralIDs {
+		if err := deleteKey(fmt.Sprintf("COLLATERAL_%s", string(collateralID))); err != nil {
+			return nil, err
+		}
+		if _, err := deleteRange("HISTORY", string(collateralID)); err != nil {
+			return nil, err
+		}
+	}
+
+	// Documents referenced from the loan index
+	documentIDs, err := deleteRange("LOAN_DOCUMENT", req.EntityID)
+	if err != nil {
+		return nil, err
+	}
+	for _, documentID := range documentIDs {
+		if err := deleteKey(fmt.Sprintf("DOCUMENT_%s", string(documentID))); err != nil {
+			return nil, err
+		}
+	}
+
+	// Guarantees, their guarantor index entries, and any recovery obligations
+	guaranteeIDs, err := deleteRange("LOAN_GUARANTEE", req.EntityID)
+	if err != nil {
+		return nil, err
+	}
+	for _, guaranteeID := range guaranteeIDs {
+		var guarantee domain.LoanGuarantee
+		guaranteeKey := fmt.Sprintf("GUARANTEE_%s", string(guaranteeID))
+		if err := h.persistenceService.Get(stub, guaranteeKey, &guarantee); err == nil {
+			if _, err := deleteRange("GUARANTOR_GUARANTEE", guarantee.GuarantorCustomerID, guarantee.GuaranteeID); err != nil {
+				return nil, err
+			}
+		}
+		if err := deleteKey(guaranteeKey); err != nil {
+			return nil, err
+		}
+		if _, err := deleteRange("HISTORY", string(guaranteeID)); err != nil {
+			return nil, err
+		}
+	}
+	obligationIDs, err := deleteRange("LOAN_RECOVERY_OBLIGATION", req.EntityID)
+	if err != nil {
+		return nil, err
+	}
+	for _, obligationID := range obligationIDs {
+		if err := deleteKey(fmt.Sprintf("RECOVERY_OBLIGATION_%s", string(obligationID))); err != nil {
+			return nil, err
+		}
+		if _, err := deleteRange("GUARANTOR_PAYMENT", string(obligationID)); err != nil {
+			return nil, err
+		}
+	}
+
+	// Straight-through processing decision; sandbox decisions are never indexed
+	stpKey := fmt.Sprintf("STP_DECISION_%s", req.EntityID)
+	if exists, err := h.persistenceService.Exists(stub, stpKey); err != nil {
+		return nil, err
+	} else if exists {
+		if err := deleteKey(stpKey); err != nil {
+			return nil, err
+		}
+	}
+
+	// The loan itself and its customer and party indexes
+	for _, party := range loanApp.Parties {
+		if _, err := deleteRange("CUSTOMER_LOAN_PARTY", party.CustomerID, loanApp.LoanID); err != nil {
+			return nil, err
+		}
+	}
+	listingIndexes := [][]string{
+		{"LOAN_BY_CUSTOMER", loanApp.CustomerID},
+		{"LOAN_BY_STATUS", string(loanApp.Status)},
+		{"LOAN_BY_DATE", loanApp.ApplicationDate.UTC().Format(loanIndexDateFormat)},
+	}
+	for _, index := range listingIndexes {
+		if _, err := deleteRange(index[0], index[1], loanApp.LoanID); err != nil {
+			return nil, err
+		}
+	}
+
+	// Loans written before the listing indexes carry a plain customer index key
+	legacyKey := fmt.Sprintf("CUSTOMER_LOAN_%s_%s", loanApp.CustomerID, loanApp.LoanID)
+	if exists, err := h.persistenceService.Exists(stub, legacyKey); err != nil {
+		return nil, err
+	} else if exists {
+		if err := deleteKey(legacyKey); err != nil {
+			return nil, err
+		}
+	}
+	if err := deleteKey(loanKey); err != nil {
+		return nil, err
+	}
+
+	return h.completePurge(stub, req, "LoanApplication", deleted)
+}
+
+// PurgeSandboxFacility deletes a sandbox credit facility and its transactions
+func (h *SandboxPurgeHandler) PurgeSandboxFacility(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	req, err := parseSandboxPurgeRequest(args)
+	if err != nil {
+		return nil, err
+	}
+
+	facilityKey := fmt.Sprintf("FACILITY_%s", req.EntityID)
+	var facility domain.CreditFacility
+	if err := h.persistenceService.Get(stub, facilityKey, &facility); err != nil {
+		return nil, fmt.Errorf("credit facility not found: %w", err)
+	}
+	if !facility.Sandbox {
+		return nil, fmt.Errorf("facility %s is not a sandbox record", req.EntityID)
+	}
+
+	deleted := 0
+	for _, scan := range [][]string{
+		{"FACILITY_TRANSACTION", facility.FacilityID},
+		{"HISTORY", facility.FacilityID},
+		{"CUSTOMER_FACILITY", facility.CustomerID, facility.FacilityID},
+	} {
+		values, err := h.sandboxService.DeleteByPartialCompositeKey(stub, scan[0], scan[1:])
+		if err != nil {
+			return nil, err
+		}
+		deleted += len(values)
+	}
+
+	if err := stub.DelState(facilityKey); err != nil {
+		return nil, fmt.Errorf("failed to delete %s: %w", facilityKey, err)
+	}
+	deleted++
+
+	return h.completePurge(stub, req, "CreditFacility", deleted)
+}
+
+// Helper methods
+
+func parseSandboxPurgeRequest(args []string) (*domain.SandboxPurgeRequest, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.SandboxPurgeRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse sandbox purge request: %v", err)
+	}
+	if strings.TrimSpace(req.EntityID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "entityID", "entityID is required")
+	}
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	return &req, nil
+}
+
+func (h *SandboxPurgeHandler) completePurge(stub shim.ChaincodeStubInterface, req *domain.SandboxPurgeRequest, entityType string, deleted int) ([]byte, error) {
+	result := &domain.SandboxPurgeResult{
+		EntityID:       req.EntityID,
+		EntityType:     entityType,
+		RecordsDeleted: deleted,
+	}
+
+	metadata := map[string]string{
+		"sandbox":        "true",
+		"recordsDeleted": fmt.Sprintf("%d", deleted),
+	}
+	payload := h.eventService.CreateEventPayloadWithMetadata(
+		config.EventSandboxRecordsPurged,
+		req.EntityID,
+		entityType,
+		req.ActorID,
+		result,
+		metadata,
+	)
+	if err := h.eventService.EmitEvent(stub, config.EventSandboxRecordsPurged, payload); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(result)
+}
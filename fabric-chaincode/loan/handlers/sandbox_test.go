@@ -0,0 +1,144 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	sharedChaincode "github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/chaincode"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// designateSandboxActor marks an actor's records as UAT data
+func designateSandboxActor(t *testing.T, stub *shimtest.MockStub, actorID string) {
+	t.Helper()
+	if _, err := inTx(stub, "sandbox_"+actorID, func() ([]byte, error) {
+		return sharedChaincode.NewSandboxHandler().SetSandboxActor(stub, []string{mustJSON(t, sharedChaincode.SandboxActorRequest{
+			SandboxActorID: actorID, Sandbox: true, Reason: "UAT on the shared channel", ActorID: "ACTOR_ADMIN",
+		})})
+	}); err != nil {
+		t.Fatalf("failed to designate sandbox actor: %v", err)
+	}
+}
+
+func purgeSandbox(t *testing.T, stub *shimtest.MockStub, txID string, purge func(shim.ChaincodeStubInterface, []string) ([]byte, error), entityID string) (*domain.SandboxPurgeResult, error) {
+	payload, err := inTx(stub, txID, func() ([]byte, error) {
+		return purge(stub, []string{mustJSON(t, domain.SandboxPurgeRequest{EntityID: entityID, ActorID: "ACTOR_ADMIN"})})
+	})
+	if err != nil {
+		return nil, err
+	}
+	var result domain.SandboxPurgeResult
+	if err := json.Unmarshal(payload, &result); err != nil {
+		t.Fatalf("failed to decode purge result: %v", err)
+	}
+	return &result, nil
+}
+
+func TestSandboxFacilitiesAreFlaggedAndPurgeable(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	withCustomers(stub, eligibleCustomer("CUST_001", 10))
+	designateSandboxActor(t, stub, "UAT_TESTER")
+
+	open := func(txID, actorID string) *domain.CreditFacility {
+		payload, err := inTx(stub, txID, func() ([]byte, error) {
+			return NewFacilityHandler().OpenCreditFacility(stub, []string{mustJSON(t, domain.CreditFacilityRequest{
+				CustomerID: "CUST_001", LoanType: "PERSONAL", CreditLimit: 5000, InterestRate: 9.5, TermMonths: 24, ActorID: actorID,
+			})})
+		})
+		if err != nil {
+			t.Fatalf("failed to open facility as %s: %v", actorID, err)
+		}
+		var facility domain.CreditFacility
+		if err := json.Unmarshal(payload, &facility); err != nil {
+			t.Fatalf("failed to decode facility: %v", err)
+		}
+		return &facility
+	}
+	sandbox := open("open_uat", "UAT_TESTER")
+	production := open("open_live", "ACTOR_005")
+	if !sandbox.Sandbox || production.Sandbox {
+		t.Fatalf("expected only the UAT tester's facility flagged, got sandbox=%t production=%t", sandbox.Sandbox, production.Sandbox)
+	}
+	if _, err := drawdown(t, stub, "draw_uat", sandbox.FacilityID, 1000); err != nil {
+		t.Fatalf("sandbox drawdown failed: %v", err)
+	}
+
+	// The purge only ever touches sandbox records
+	if _, err := purgeSandbox(t, stub, "purge_live", NewSandboxPurgeHandler().PurgeSandboxFacility, production.FacilityID); err == nil {
+		t.Fatalf("expected purging a production facility to be refused")
+	}
+	result, err := purgeSandbox(t, stub, "purge_uat", NewSandboxPurgeHandler().PurgeSandboxFacility, sandbox.FacilityID)
+	if err != nil {
+		t.Fatalf("sandbox purge failed: %v", err)
+	}
+	if result.EntityType != "CreditFacility" || result.RecordsDeleted < 3 {
+		t.Errorf("expected the facility, its drawdown and its index removed, got %+v", result)
+	}
+
+	ps := services.NewPersistenceService()
+	stub.MockTransactionStart("check")
+	defer stub.MockTransactionEnd("check")
+	if exists, _ := ps.Exists(stub, "FACILITY_"+sandbox.FacilityID); exists {
+		t.Errorf("expected the sandbox facility deleted")
+	}
+	if exists, _ := ps.Exists(stub, "FACILITY_"+production.FacilityID); !exists {
+		t.Errorf("expected the production facility kept")
+	}
+}
+
+func TestSandboxLoansStayOutOfReportsUntilPurged(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleUnderwriter))
+	seedLoan(t, stub, "LOAN_LIVE", validation.LoanStatusSubmitted)
+	seedLoan(t, stub, "LOAN_UAT", validation.LoanStatusSubmitted, func(loanApp *domain.LoanApplication) { loanApp.Sandbox = true })
+	if _, err := inTx(stub, "history_uat", func() ([]byte, error) {
+		return nil, recordLoanHistory(stub, services.NewPersistenceService(), "LOAN_UAT", "UPDATE", "purpose", "", "UAT", "UAT_TESTER")
+	}); err != nil {
+		t.Fatalf("failed to record history: %v", err)
+	}
+
+	// Status reporting lists production loans only
+	payload, err := inTx(stub, "queue", func() ([]byte, error) {
+		return NewLoanApplicationHandler().QueryLoansByStatus(stub, []string{string(validation.LoanStatusSubmitted)})
+	})
+	if err != nil {
+		t.Fatalf("QueryLoansByStatus failed: %v", err)
+	}
+	var queue domain.LoanQueryResult
+	if err := json.Unmarshal(payload, &queue); err != nil {
+		t.Fatalf("failed to decode query result: %v", err)
+	}
+	if queue.Count != 1 || queue.Loans[0].LoanID != "LOAN_LIVE" {
+		t.Errorf("expected only LOAN_LIVE in the SUBMITTED queue, got %+v", queue.Loans)
+	}
+
+	if _, err := purgeSandbox(t, stub, "purge_live", NewSandboxPurgeHandler().PurgeSandboxLoan, "LOAN_LIVE"); err == nil {
+		t.Fatalf("expected purging a production loan to be refused")
+	}
+	if _, err := purgeSandbox(t, stub, "purge_uat", NewSandboxPurgeHandler().PurgeSandboxLoan, "LOAN_UAT"); err != nil {
+		t.Fatalf("sandbox purge failed: %v", err)
+	}
+
+	// The loan, its listing indexes and its history are gone
+	stub.MockTransactionStart("check")
+	defer stub.MockTransactionEnd("check")
+	for _, scan := range [][]string{{"LOAN_BY_STATUS", string(validation.LoanStatusSubmitted), "LOAN_UAT"}, {"HISTORY", "LOAN_UAT"}} {
+		iterator, err := stub.GetStateByPartialCompositeKey(scan[0], scan[1:])
+		if err != nil {
+			t.Fatalf("failed to scan %s: %v", scan[0], err)
+		}
+		if iterator.HasNext() {
+			t.Errorf("expected no %s entries left for LOAN_UAT", scan[0])
+		}
+		iterator.Close()
+	}
+	if exists, _ := services.NewPersistenceService().Exists(stub, "LOAN_LOAN_UAT"); exists {
+		t.Errorf("expected LOAN_UAT deleted")
+	}
+	if exists, _ := services.NewPersistenceService().Exists(stub, "LOAN_LOAN_LIVE"); !exists {
+		t.Errorf("expected LOAN_LIVE kept")
+	}
+}
@@ -0,0 +1,626 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	loanServices "github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// ServicingTransferHandler handles moving loan servicing between organizations
+type ServicingTransferHandler struct {
+	persistenceService *services.PersistenceService
+	eventService       *loanServices.EventService
+}
+
+// NewServicingTransferHandler creates a new servicing transfer handler
+func NewServicingTransferHandler() *ServicingTransferHandler {
+	return &ServicingTransferHandler{
+		persistenceService: services.NewPersistenceService(),
+		eventService:       loanServices.NewEventService(),
+	}
+}
+
+// ScheduleServicingTransfer schedules the transfer of a loan book segment serviced by the invoking
+// organization to another organization, cutting over on the effective date. The segment is fixed
+// when the transfer is scheduled and affected customers are notified.
+func (h *ServicingTransferHandler) ScheduleServicingTransfer(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.ServicingTransferRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse servicing transfer request: %v", err)
+	}
+
+	fromMSP, err := services.InvokerMSPID(stub)
+	if err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.ToServicerMSP) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "toServicerMSP", "toServicerMSP is required")
+	}
+	if req.ToServicerMSP == fromMSP {
+		return nil, fmt.Errorf("loans are already serviced by %s", fromMSP)
+	}
+
+	effectiveDate, err := time.Parse("2006-01-02", req.EffectiveDate)
+	if err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid effective date %s: expected YYYY-MM-DD", req.EffectiveDate)
+	}
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	if effectiveDate.Before(now.UTC().Truncate(24 * time.Hour)) {
+		return nil, fmt.Errorf("effective date %s is in the past", req.EffectiveDate)
+	}
+
+	loans, err := h.selectSegment(stub, req.Segment, fromMSP)
+	if err != nil {
+		return nil, err
+	}
+	if len(loans) == 0 {
+		return nil, fmt.Errorf("segment selects no loans")
+	}
+
+	transfer := &domain.ServicingTransfer{
+		TransferID:      services.GenerateDeterministicID(stub, config.ServicingTransferPrefix),
+		FromServicerMSP: fromMSP,
+		ToServicerMSP:   req.ToServicerMSP,
+		Segment:         req.Segment,
+		LoanIDs:         []string{},
+		EffectiveDate:   effectiveDate,
+		Status:          domain.ServicingTransferScheduled,
+		ScheduledBy:     req.ActorID,
+		ScheduledDate:   now,
+		LastUpdated:     now,
+	}
+
+	for _, loan := range loans {
+		if servicerMSP(loan) != fromMSP {
+			return nil, fmt.Errorf("loan %s is serviced by %s, not %s", loan.LoanID, servicerMSP(loan), fromMSP)
+		}
+
+		// A loan can only be in one scheduled transfer at a time
+		pending, err := h.pendingTransferForLoan(stub, loan.LoanID)
+		if err != nil {
+			return nil, err
+		}
+		if pending != "" {
+			return nil, services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "loan %s is already in scheduled servicing transfer %s", loan.LoanID, pending)
+		}
+		if err := h.putLoanTransferIndex(stub, loan.LoanID, transfer.TransferID); err != nil {
+			return nil, err
+		}
+
+		transfer.LoanIDs = append(transfer.LoanIDs, loan.LoanID)
+	}
+
+	if err := h.persistenceService.Put(stub, fmt.Sprintf("SERVICING_TRANSFER_%s", transfer.TransferID), transfer); err != nil {
+		return nil, fmt.Errorf("failed to store servicing transfer: %w", err)
+	}
+
+	// Emit event
+	if err := h.eventService.EmitServicingTransferEvent(stub, config.EventServicingTransferScheduled, transfer, transferNotices(transfer, loans), req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(transfer)
+}
+
+// CompleteServicingTransfer cuts servicing over to the receiving organization, which must invoke
+// it on or after the effective date. Each loan moves with its open document reviews, recovery
+// obligations and overdue installments, and an immutable manifest of the portfolio is written.
+func (h *ServicingTransferHandler) CompleteServicingTransfer(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.ServicingTransferActionRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse servicing transfer request: %v", err)
+	}
+
+	transfer, err := h.getScheduledTransfer(stub, req.TransferID)
+	if err != nil {
+		return nil, err
+	}
+
+	invokerMSP, err := services.InvokerMSPID(stub)
+	if err != nil {
+		return nil, err
+	}
+	if invokerMSP != transfer.ToServicerMSP {
+		return nil, services.NewChaincodeError(services.ErrCodeAccessDenied, "", "servicing transfer %s can only be completed by the receiving servicer %s", transfer.TransferID, transfer.ToServicerMSP)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	if now.Before(transfer.EffectiveDate) {
+		return nil, fmt.Errorf("servicing transfer %s is not effective until %s", transfer.TransferID, transfer.EffectiveDate.Format("2006-01-02"))
+	}
+
+	manifest := &domain.ServicingTransferManifest{
+		TransferID:      transfer.TransferID,
+		FromServicerMSP: transfer.FromServicerMSP,
+		ToServicerMSP:   transfer.ToServicerMSP,
+		EffectiveDate:   transfer.EffectiveDate,
+		Entries:         []domain.ServicingManifestEntry{},
+		TotalsOutstanding: utils.CurrencyTotals{},
+		CreatedDate:     now,
+		CreatedTxID:     stub.GetTxID(),
+	}
+
+	loans := []*domain.LoanApplication{}
+	for _, loanID := range transfer.LoanIDs {
+		var loanApp domain.LoanApplication
+		if err := h.persistenceService.Get(stub, fmt.Sprintf("LOAN_%s", loanID), &loanApp); err != nil {
+			return nil, fmt.Errorf("loan application not found: %w", err)
+		}
+		if servicerMSP(&loanApp) != transfer.FromServicerMSP {
+			return nil, fmt.Errorf("loan %s is no longer serviced by %s", loanID, transfer.FromServicerMSP)
+		}
+
+		entry, err := h.buildManifestEntry(stub, &loanApp, now)
+		if err != nil {
+			return nil, err
+		}
+		manifest.Entries = append(manifest.Entries, *entry)
+		manifest.TotalsOutstanding.Add(loanApp.OutstandingBalance)
+		manifest.OpenItemCount += len(entry.OpenItems)
+
+		loanApp.ServicerMSP = transfer.ToServicerMSP
+		loanApp.LastUpdated = now
+		loanApp.LastUpdatedBy = req.ActorID
+		if err := putLoanApplication(stub, h.persistenceService, &loanApp); err != nil {
+			return nil, fmt.Errorf("failed to update loan application: %w", err)
+		}
+		if err := recordLoanHistory(stub, h.persistenceService, loanID, "SERVICING_TRANSFER", "servicerMSP", transfer.FromServicerMSP, transfer.ToServicerMSP, req.ActorID); err != nil {
+			return nil, fmt.Errorf("failed to record history: %w", err)
+		}
+		if err := h.deleteLoanTransferIndex(stub, loanID, transfer.TransferID); err != nil {
+			return nil, err
+		}
+
+		loans = append(loans, &loanApp)
+	}
+
+	manifest.LoanCount = len(manifest.Entries)
+	manifest.PortfolioHash = portfolioHash(manifest)
+
+	// The manifest is written once and never updated
+	manifestKey := fmt.Sprintf("SERVICING_MANIFEST_%s", transfer.TransferID)
+	exists, err := h.persistenceService.Exists(stub, manifestKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check servicing manifest: %w", err)
+	}
+	if exists {
+		return nil, fmt.Errorf("servicing manifest for transfer %s already exists", transfer.TransferID)
+	}
+	if err := h.persistenceService.Put(stub, manifestKey, manifest); err != nil {
+		return nil, fmt.Errorf("failed to store servicing manifest: %w", err)
+	}
+
+	transfer.Status = domain.ServicingTransferCompleted
+	transfer.ManifestHash = manifest.PortfolioHash
+	transfer.CompletedBy = req.ActorID
+	transfer.CompletedDate = &now
+	transfer.LastUpdated = now
+	if err := h.persistenceService.Put(stub, fmt.Sprintf("SERVICING_TRANSFER_%s", transfer.TransferID), transfer); err != nil {
+		return nil, fmt.Errorf("failed to update servicing transfer: %w", err)
+	}
+
+	// Emit event
+	if err := h.eventService.EmitServicingTransferEvent(stub, config.EventServicingTransferCompleted, transfer, transferNotices(transfer, loans), req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(manifest)
+}
+
+// CancelServicingTransfer cancels a scheduled servicing transfer. Either organization may cancel
+// before cutover; customers are notified that their servicer is not changing.
+func (h *ServicingTransferHandler) CancelServicingTransfer(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.ServicingTransferActionRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse servicing transfer request: %v", err)
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "reason", "reason is required")
+	}
+
+	transfer, err := h.getScheduledTransfer(stub, req.TransferID)
+	if err != nil {
+		return nil, err
+	}
+
+	invokerMSP, err := services.InvokerMSPID(stub)
+	if err != nil {
+		return nil, err
+	}
+	if invokerMSP != transfer.FromServicerMSP && invokerMSP != transfer.ToServicerMSP {
+		return nil, services.NewChaincodeError(services.ErrCodeAccessDenied, "", "servicing transfer %s can only be cancelled by %s or %s", transfer.TransferID, transfer.FromServicerMSP, transfer.ToServicerMSP)
+	}
+
+	loans := []*domain.LoanApplication{}
+	for _, loanID := range transfer.LoanIDs {
+		if err := h.deleteLoanTransferIndex(stub, loanID, transfer.TransferID); err != nil {
+			return nil, err
+		}
+
+		var loanApp domain.LoanApplication
+		if err := h.persistenceService.Get(stub, fmt.Sprintf("LOAN_%s", loanID), &loanApp); err == nil {
+			loans = append(loans, &loanApp)
+		}
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	transfer.Status = domain.ServicingTransferCancelled
+	transfer.CancelledBy = req.ActorID
+	transfer.CancellationReason = req.Reason
+	transfer.LastUpdated = now
+	if err := h.persistenceService.Put(stub, fmt.Sprintf("SERVICING_TRANSFER_%s", transfer.TransferID), transfer); err != nil {
+		return nil, fmt.Errorf("failed to update servicing transfer: %w", err)
+	}
+
+	// Emit event
+	if err := h.eventService.EmitServicingTransferEvent(stub, config.EventServicingTransferCancelled, transfer, transferNotices(transfer, loans), req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(transfer)
+}
+
+// GetServicingTransfer retrieves a servicing transfer
+func (h *ServicingTransferHandler) GetServicingTransfer(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var transfer domain.ServicingTransfer
+	if err := h.persistenceService.Get(stub, fmt.Sprintf("SERVICING_TRANSFER_%s", args[0]), &transfer); err != nil {
+		return nil, fmt.Errorf("servicing transfer not found: %w", err)
+	}
+
+	return json.Marshal(&transfer)
+}
+
+// GetServicingTransferManifest retrieves the manifest written when a servicing transfer completed
+func (h *ServicingTransferHandler) GetServicingTransferManifest(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var manifest domain.ServicingTransferManifest
+	if err := h.persistenceService.Get(stub, fmt.Sprintf("SERVICING_MANIFEST_%s", args[0]), &manifest); err != nil {
+		return nil, fmt.Errorf("servicing manifest not found: %w", err)
+	}
+
+	return json.Marshal(&manifest)
+}
+
+// VerifyServicingTransferManifest recomputes a manifest's entry and portfolio hashes and checks
+// them against the stored manifest and the hash recorded on the transfer
+func (h *ServicingTransferHandler) VerifyServicingTransferManifest(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var transfer domain.ServicingTransfer
+	if err := h.persistenceService.Get(stub, fmt.Sprintf("SERVICING_TRANSFER_%s", args[0]), &transfer); err != nil {
+		return nil, fmt.Errorf("servicing transfer not found: %w", err)
+	}
+	var manifest domain.ServicingTransferManifest
+	if err := h.persistenceService.Get(stub, fmt.Sprintf("SERVICING_MANIFEST_%s", args[0]), &manifest); err != nil {
+		return nil, fmt.Errorf("servicing manifest not found: %w", err)
+	}
+
+	verification := &domain.ServicingManifestVerification{
+		TransferID:    transfer.TransferID,
+		PortfolioHash: manifest.PortfolioHash,
+		Mismatches:    []string{},
+	}
+	for _, entry := range manifest.Entries {
+		if manifestEntryHash(entry) != entry.EntryHash {
+			verification.Mismatches = append(verification.Mismatches, fmt.Sprintf("entry for loan %s does not match its hash", entry.LoanID))
+		}
+	}
+	if portfolioHash(&manifest) != manifest.PortfolioHash {
+		verification.Mismatches = append(verification.Mismatches, "portfolio hash does not match the manifest entries")
+	}
+	if manifest.PortfolioHash != transfer.ManifestHash {
+		verification.Mismatches = append(verification.Mismatches, "portfolio hash does not match the hash recorded on the transfer")
+	}
+	verification.Valid = len(verification.Mismatches) == 0
+
+	return json.Marshal(verification)
+}
+
+// Helper methods
+
+// servicerMSP returns the organization servicing a loan
+func servicerMSP(loanApp *domain.LoanApplication) string {
+	if loanApp.ServicerMSP == "" {
+		return config.BankMSPID
+	}
+	return loanApp.ServicerMSP
+}
+
+// selectSegment resolves a segment to production loans. Loans named by ID must exist; status
+// segments are read from the LOAN_BY_STATUS index and only select loans fromMSP services.
+func (h *ServicingTransferHandler) selectSegment(stub shim.ChaincodeStubInterface, segment domain.ServicingSegment, fromMSP string) ([]*domain.LoanApplication, error) {
+	if len(segment.LoanIDs) > 0 && segment.Status != "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "segment must name either loanIDs or a status, not both")
+	}
+
+	loans := []*domain.LoanApplication{}
+	if len(segment.LoanIDs) > 0 {
+		seen := map[string]bool{}
+		for _, loanID := range segment.LoanIDs {
+			if seen[loanID] {
+				return nil, fmt.Errorf("loan %s is named more than once in the segment", loanID)
+			}
+			seen[loanID] = true
+
+			var loanApp domain.LoanApplication
+			if err := h.persistenceService.Get(stub, fmt.Sprintf("LOAN_%s", loanID), &loanApp); err != nil {
+				return nil, fmt.Errorf("loan application not found: %w", err)
+			}
+			if loanApp.Sandbox {
+				return nil, fmt.Errorf("loan %s is a sandbox record and cannot be transferred", loanID)
+			}
+			if segment.LoanType != "" && loanApp.LoanType != segment.LoanType {
+				return nil, fmt.Errorf("loan %s is not a %s loan", loanID, segment.LoanType)
+			}
+			loans = append(loans, &loanApp)
+		}
+		return loans, nil
+	}
+
+	if err := validation.ValidateLoanApplicationStatus(segment.Status); err != nil {
+		return nil, fmt.Errorf("invalid segment status: %w", err)
+	}
+
+	iterator, err := stub.GetStateByPartialCompositeKey("LOAN_BY_STATUS", []string{segment.Status})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query loans by status: %w", err)
+	}
+	defer iterator.Close()
+
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate loans: %w", err)
+		}
+
+		var loanApp domain.LoanApplication
+		if err := h.persistenceService.Get(stub, fmt.Sprintf("LOAN_%s", string(response.Value)), &loanApp); err != nil {
+			continue // Skip if loan not found
+		}
+		if loanApp.Sandbox || servicerMSP(&loanApp) != fromMSP || (segment.LoanType != "" && loanApp.LoanType != segment.LoanType) {
+			continue
+		}
+		loans = append(loans, &loanApp)
+	}
+
+	return loans, nil
+}
+
+// buildManifestEntry records a loan and its open items as they stand at cutover
+func (h *ServicingTransferHandler) buildManifestEntry(stub shim.ChaincodeStubInterface, loanApp *domain.LoanApplication, asOf time.Time) (*domain.ServicingManifestEntry, error) {
+	entry := &domain.ServicingManifestEntry{
+		LoanID:             loanApp.LoanID,
+		CustomerIDs:        loanCustomerIDs(loanApp),
+		LoanType:           loanApp.LoanType,
+		Status:             string(loanApp.Status),
+		Currency:           loanApp.OutstandingBalance.Currency(),
+		OutstandingBalance: loanApp.OutstandingBalance.Float64(),
+		OpenItems:          []domain.ServicingOpenItem{},
+	}
+
+	documents, err := getLoanDocuments(stub, h.persistenceService, loanApp.LoanID)
+	if err != nil {
+		return nil, err
+	}
+	for _, document := range documents {
+		if document.Status == domain.DocumentStatusPending {
+			entry.OpenItems = append(entry.OpenItems, domain.ServicingOpenItem{
+				ItemType: domain.ServicingItemDocumentReview,
+				ItemID:   document.DocumentID,
+				Status:   string(document.Status),
+			})
+		}
+	}
+
+	obligations, err := stub.GetStateByPartialCompositeKey("LOAN_RECOVERY_OBLIGATION", []string{loanApp.LoanID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get recovery obligations: %w", err)
+	}
+	defer obligations.Close()
+	for obligations.HasNext() {
+		response, err := obligations.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate recovery obligations: %w", err)
+		}
+
+		var obligation domain.RecoveryObligation
+		if err := h.persistenceService.Get(stub, fmt.Sprintf("RECOVERY_OBLIGATION_%s", string(response.Value)), &obligation); err != nil {
+			return nil, fmt.Errorf("recovery obligation not found: %w", err)
+		}
+		if obligation.Status == domain.RecoveryObligationOpen {
+			entry.OpenItems = append(entry.OpenItems, domain.ServicingOpenItem{
+				ItemType: domain.ServicingItemRecoveryObligation,
+				ItemID:   obligation.ObligationID,
+				Status:   string(obligation.Status),
+				Amount:   obligation.OutstandingAmount,
+			})
+		}
+	}
+
+	installments, err := stub.GetStateByPartialCompositeKey("REPAYMENT_INSTALLMENT", []string{loanApp.LoanID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query installments: %w", err)
+	}
+	defer installments.Close()
+	for installments.HasNext() {
+		response, err := installments.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate installments: %w", err)
+		}
+
+		var installment domain.Installment
+		if err := json.Unmarshal(response.Value, &installment); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal installment: %w", err)
+		}
+		if loanServices.IsInstallmentOverdue(&installment, asOf) {
+			dueDate := installment.DueDate
+			entry.OpenItems = append(entry.OpenItems, domain.ServicingOpenItem{
+				ItemType: domain.ServicingItemOverdueInstallment,
+				ItemID:   fmt.Sprintf("%s-%d", loanApp.LoanID, installment.InstallmentNumber),
+				Status:   string(installment.Status),
+				Amount:   installment.AmountOutstanding().Float64(),
+				DueDate:  &dueDate,
+			})
+		}
+	}
+
+	entry.EntryHash = manifestEntryHash(*entry)
+	return entry, nil
+}
+
+// manifestEntryHash hashes a manifest entry with its own hash field cleared
+func manifestEntryHash(entry domain.ServicingManifestEntry) string {
+	entry.EntryHash = ""
+	entryJSON, _ := json.Marshal(entry)
+	return utils.HashValue(string(entryJSON))
+}
+
+// portfolioHash chains the transfer header and its per-currency totals with every entry hash in
+// manifest order
+func portfolioHash(manifest *domain.ServicingTransferManifest) string {
+	parts := []string{
+		manifest.TransferID,
+		manifest.FromServicerMSP,
+		manifest.ToServicerMSP,
+		manifest.EffectiveDate.UTC().Format("2006-01-02"),
+		fmt.Sprintf("%d", manifest.LoanCount),
+	}
+	for _, currency := range manifest.TotalsOutstanding.Currencies() {
+		parts = append(parts, currency+" "+manifest.TotalsOutstanding[currency].String())
+	}
+	for _, entry := range manifest.Entries {
+		parts = append(parts, entry.EntryHash)
+	}
+	return utils.HashValue(strings.Join(parts, "|"))
+}
+
+// loanCustomerIDs lists every customer on a loan, primary borrower first
+func loanCustomerIDs(loanApp *domain.LoanApplication) []string {
+	if len(loanApp.Parties) == 0 {
+		return []string{loanApp.CustomerID}
+	}
+	ids := make([]string, 0, len(loanApp.Parties))
+	for _, party := range loanApp.Parties {
+		ids = append(ids, party.CustomerID)
+	}
+	return ids
+}
+
+// transferNotices groups a transfer's loans by customer, one notice per customer
+func transferNotices(transfer *domain.ServicingTransfer, loans []*domain.LoanApplication) []domain.ServicingTransferNotice {
+	loansByCustomer := map[string][]string{}
+	for _, loanApp := range loans {
+		for _, customerID := range loanCustomerIDs(loanApp) {
+			loansByCustomer[customerID] = append(loansByCustomer[customerID], loanApp.LoanID)
+		}
+	}
+
+	customerIDs := make([]string, 0, len(loansByCustomer))
+	for customerID := range loansByCustomer {
+		customerIDs = append(customerIDs, customerID)
+	}
+	sort.Strings(customerIDs)
+
+	notices := make([]domain.ServicingTransferNotice, 0, len(customerIDs))
+	for _, customerID := range customerIDs {
+		notices = append(notices, domain.ServicingTransferNotice{
+			CustomerID:      customerID,
+			LoanIDs:         loansByCustomer[customerID],
+			FromServicerMSP: transfer.FromServicerMSP,
+			ToServicerMSP:   transfer.ToServicerMSP,
+			EffectiveDate:   transfer.EffectiveDate,
+		})
+	}
+	return notices
+}
+
+func (h *ServicingTransferHandler) getScheduledTransfer(stub shim.ChaincodeStubInterface, transferID string) (*domain.ServicingTransfer, error) {
+	var transfer domain.ServicingTransfer
+	if err := h.persistenceService.Get(stub, fmt.Sprintf("SERVICING_TRANSFER_%s", transferID), &transfer); err != nil {
+		return nil, fmt.Errorf("servicing transfer not found: %w", err)
+	}
+	if transfer.Status != domain.ServicingTransferScheduled {
+		return nil, fmt.Errorf("servicing transfer %s is %s", transferID, transfer.Status)
+	}
+	return &transfer, nil
+}
+
+// pendingTransferForLoan returns the scheduled transfer a loan is in, or "" if none
+func (h *ServicingTransferHandler) pendingTransferForLoan(stub shim.ChaincodeStubInterface, loanID string) (string, error) {
+	iterator, err := stub.GetStateByPartialCompositeKey("SERVICING_TRANSFER_BY_LOAN", []string{loanID})
+	if err != nil {
+		return "", fmt.Errorf("failed to query servicing transfers: %w", err)
+	}
+	defer iterator.Close()
+
+	if iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return "", fmt.Errorf("failed to iterate servicing transfers: %w", err)
+		}
+		return string(response.Value), nil
+	}
+	return "", nil
+}
+
+func (h *ServicingTransferHandler) putLoanTransferIndex(stub shim.ChaincodeStubInterface, loanID, transferID string) error {
+	indexKey, err := stub.CreateCompositeKey("SERVICING_TRANSFER_BY_LOAN", []string{loanID, transferID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := stub.PutState(indexKey, []byte(transferID)); err != nil {
+		return fmt.Errorf("failed to create servicing transfer index: %w", err)
+	}
+	return nil
+}
+
+func (h *ServicingTransferHandler) deleteLoanTransferIndex(stub shim.ChaincodeStubInterface, loanID, transferID string) error {
+	indexKey, err := stub.CreateCompositeKey("SERVICING_TRANSFER_BY_LOAN", []string{loanID, transferID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := stub.DelState(indexKey); err != nil {
+		return fmt.Errorf("failed to remove servicing transfer index: %w", err)
+	}
+	return nil
+}
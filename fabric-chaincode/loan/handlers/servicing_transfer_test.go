@@ -0,0 +1,195 @@
+package handlers
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// transferDay is the effective date of servicing transfers scheduled in these tests
+var transferDay = time.Date(2026, 4, 1, 9, 0, 0, 0, time.UTC)
+
+func TestServicingManifestTotalsEachCurrency(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	seedLoan(t, stub, "LOAN_S1", validation.LoanStatusDisbursed, func(loanApp *domain.LoanApplication) {
+		loanApp.OutstandingBalance = utils.NewMoney(1000, "USD")
+	})
+	seedLoan(t, stub, "LOAN_S2", validation.LoanStatusDisbursed, func(loanApp *domain.LoanApplication) {
+		loanApp.Currency = "KWD"
+		loanApp.OutstandingBalance = utils.NewMoney(500.125, "KWD")
+	})
+	h := NewServicingTransferHandler()
+
+	payload, err := inTxAt(stub, "schedule_transfer", transferDay, func() ([]byte, error) {
+		return h.ScheduleServicingTransfer(stub, []string{mustJSON(t, domain.ServicingTransferRequest{
+			ToServicerMSP: "Org2MSP",
+			Segment:       domain.ServicingSegment{LoanIDs: []string{"LOAN_S1", "LOAN_S2"}},
+			EffectiveDate: transferDay.Format("2006-01-02"),
+			ActorID:       "ACTOR_005",
+		})})
+	})
+	if err != nil {
+		t.Fatalf("scheduling the transfer failed: %v", err)
+	}
+	var transfer domain.ServicingTransfer
+	if err := json.Unmarshal(payload, &transfer); err != nil {
+		t.Fatalf("failed to decode transfer: %v", err)
+	}
+
+	// The receiving servicer completes the transfer
+	stub.Creator = newMSPIdentity(t, "Org2MSP", string(validation.ActorRoleLoanOperationsManager))
+	payload, err = inTxAt(stub, "complete_transfer", transferDay, func() ([]byte, error) {
+		return h.CompleteServicingTransfer(stub, []string{mustJSON(t, domain.ServicingTransferActionRequest{TransferID: transfer.TransferID, ActorID: "ACTOR_009"})})
+	})
+	if err != nil {
+		t.Fatalf("completing the transfer failed: %v", err)
+	}
+	var manifest domain.ServicingTransferManifest
+	if err := json.Unmarshal(payload, &manifest); err != nil {
+		t.Fatalf("failed to decode manifest: %v", err)
+	}
+
+	// Balances in different currencies are totalled apart, and the totals are covered by the hash
+	if len(manifest.TotalsOutstanding) != 2 || manifest.TotalsOutstanding["USD"].String() != "1000" || manifest.TotalsOutstanding["KWD"].String() != "500.125" {
+		t.Errorf("expected 1000 USD and 500.125 KWD outstanding, got %v", manifest.TotalsOutstanding)
+	}
+	if manifest.Entries[1].Currency != "KWD" {
+		t.Errorf("expected the KWD loan's entry to carry its currency, got %+v", manifest.Entries[1])
+	}
+	tampered := manifest
+	tampered.TotalsOutstanding = utils.CurrencyTotals{"USD": utils.NewMoney(1500.13, "USD")}
+	if portfolioHash(&tampered) == manifest.PortfolioHash {
+		t.Errorf("expected the portfolio hash to change with the per-currency totals")
+	}
+
+	payload, err = inTx(stub, "verify_manifest", func() ([]byte, error) {
+		return h.VerifyServicingTransferManifest(stub, []string{transfer.TransferID})
+	})
+	if err != nil {
+		t.Fatalf("verifying the manifest failed: %v", err)
+	}
+	var verification domain.ServicingManifestVerification
+	if err := json.Unmarshal(payload, &verification); err != nil || !verification.Valid {
+		t.Errorf("expected the stored manifest to verify, got %+v (%v)", verification, err)
+	}
+}
+
+func TestServicingTransferCutsOverOnEffectiveDateWithOpenItems(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	seedServicedLoan(t, stub, "LOAN_T1", "USD", 12000, 12)
+	seedDocument(t, stub, "LOAN_T1", "PAYSLIP", domain.DocumentStatusPending)
+	seedDocument(t, stub, "LOAN_T1", "ID_CARD", domain.DocumentStatusVerified)
+	seedLoan(t, stub, "LOAN_T2", validation.LoanStatusDisbursed, func(loanApp *domain.LoanApplication) {
+		loanApp.CustomerID = "CUST_002"
+		loanApp.OutstandingBalance = utils.NewMoney(800, "USD")
+	})
+	seedLoan(t, stub, "LOAN_T3", validation.LoanStatusDisbursed, func(loanApp *domain.LoanApplication) { loanApp.Sandbox = true })
+	h := NewServicingTransferHandler()
+	bank := stub.Creator
+	receiver := newMSPIdentity(t, "Org2MSP", string(validation.ActorRoleLoanOperationsManager))
+	scheduledOn := transferDay.AddDate(0, 0, -10)
+	schedule := func(txID, toMSP string, segment domain.ServicingSegment, effectiveDate string) (*domain.ServicingTransfer, error) {
+		payload, err := inTxAt(stub, txID, scheduledOn, func() ([]byte, error) {
+			return h.ScheduleServicingTransfer(stub, []string{mustJSON(t, domain.ServicingTransferRequest{
+				ToServicerMSP: toMSP, Segment: segment, EffectiveDate: effectiveDate, ActorID: "ACTOR_005",
+			})})
+		})
+		if err != nil {
+			return nil, err
+		}
+		var transfer domain.ServicingTransfer
+		if err := json.Unmarshal(payload, &transfer); err != nil {
+			t.Fatalf("failed to decode transfer: %v", err)
+		}
+		return &transfer, nil
+	}
+	act := func(txID string, at time.Time, fn func(shim.ChaincodeStubInterface, []string) ([]byte, error), request domain.ServicingTransferActionRequest) ([]byte, error) {
+		return inTxAt(stub, txID, at, func() ([]byte, error) {
+			return fn(stub, []string{mustJSON(t, request)})
+		})
+	}
+
+	if _, err := schedule("past", "Org2MSP", domain.ServicingSegment{LoanIDs: []string{"LOAN_T1"}}, scheduledOn.AddDate(0, 0, -1).Format("2006-01-02")); err == nil {
+		t.Errorf("expected a past effective date to be refused")
+	}
+	if _, err := schedule("sandbox", "Org2MSP", domain.ServicingSegment{LoanIDs: []string{"LOAN_T3"}}, transferDay.Format("2006-01-02")); err == nil {
+		t.Errorf("expected a sandbox loan to be refused")
+	}
+
+	// A status segment selects the production loans the bank services, and holds them
+	transfer, err := schedule("schedule", "Org2MSP", domain.ServicingSegment{Status: string(validation.LoanStatusDisbursed)}, transferDay.Format("2006-01-02"))
+	if err != nil {
+		t.Fatalf("scheduling the transfer failed: %v", err)
+	}
+	if len(transfer.LoanIDs) != 2 || transfer.FromServicerMSP != "Org1MSP" || transfer.Status != domain.ServicingTransferScheduled {
+		t.Fatalf("expected LOAN_T1 and LOAN_T2 scheduled from Org1MSP, got %+v", transfer)
+	}
+	_, err = schedule("schedule_again", "Org2MSP", domain.ServicingSegment{LoanIDs: []string{"LOAN_T2"}}, transferDay.Format("2006-01-02"))
+	expectErrorCode(t, err, services.ErrCodeInvalidTransition)
+
+	// Only the receiving servicer cuts over, and not before the effective date
+	complete := domain.ServicingTransferActionRequest{TransferID: transfer.TransferID, ActorID: "ACTOR_009"}
+	_, err = act("complete_sender", transferDay, h.CompleteServicingTransfer, complete)
+	expectErrorCode(t, err, services.ErrCodeAccessDenied)
+	stub.Creator = receiver
+	if _, err := act("complete_early", transferDay.Add(-10*time.Hour), h.CompleteServicingTransfer, complete); err == nil || !strings.Contains(err.Error(), "is not effective until") {
+		t.Errorf("expected completion before the effective date to be refused, got %v", err)
+	}
+	payload, err := act("complete", transferDay, h.CompleteServicingTransfer, complete)
+	if err != nil {
+		t.Fatalf("completing the transfer failed: %v", err)
+	}
+	var manifest domain.ServicingTransferManifest
+	if err := json.Unmarshal(payload, &manifest); err != nil {
+		t.Fatalf("failed to decode manifest: %v", err)
+	}
+
+	// The pending document review and each overdue installment travel with the loan
+	if manifest.LoanCount != 2 || manifest.Entries[0].LoanID != "LOAN_T1" {
+		t.Fatalf("expected both loans in the manifest, got %+v", manifest)
+	}
+	items := map[string]int{}
+	for _, item := range manifest.Entries[0].OpenItems {
+		items[item.ItemType]++
+	}
+	if items[domain.ServicingItemDocumentReview] != 1 || items[domain.ServicingItemOverdueInstallment] != 2 || manifest.OpenItemCount != 3 {
+		t.Errorf("expected the payslip review and the February and March installments handed over, got %+v", manifest.Entries[0].OpenItems)
+	}
+	if len(manifest.Entries[1].OpenItems) != 0 || manifest.Entries[1].CustomerIDs[0] != "CUST_002" {
+		t.Errorf("expected LOAN_T2 to carry no open items, got %+v", manifest.Entries[1])
+	}
+	for _, loanID := range []string{"LOAN_T1", "LOAN_T2"} {
+		if loanApp := getLoan(t, stub, loanID); loanApp.ServicerMSP != "Org2MSP" {
+			t.Errorf("expected %s serviced by Org2MSP, got %q", loanID, loanApp.ServicerMSP)
+		}
+	}
+	if _, err := act("complete_again", transferDay, h.CompleteServicingTransfer, complete); err == nil {
+		t.Errorf("expected a completed transfer not to complete again")
+	}
+
+	// The new servicer can hand the loans back; cancelling releases them for another transfer
+	back, err := schedule("schedule_back", "Org1MSP", domain.ServicingSegment{LoanIDs: []string{"LOAN_T2"}}, transferDay.AddDate(0, 1, 0).Format("2006-01-02"))
+	if err != nil || back.FromServicerMSP != "Org2MSP" {
+		t.Fatalf("expected Org2MSP to schedule a transfer of its loan, got %+v (%v)", back, err)
+	}
+	stub.Creator = bank
+	cancel := domain.ServicingTransferActionRequest{TransferID: back.TransferID, ActorID: "ACTOR_005"}
+	if _, err := act("cancel_unexplained", transferDay, h.CancelServicingTransfer, cancel); err == nil {
+		t.Errorf("expected a cancellation without a reason to be refused")
+	}
+	cancel.Reason = "Portfolio sale withdrawn"
+	if _, err := act("cancel", transferDay, h.CancelServicingTransfer, cancel); err != nil {
+		t.Fatalf("cancelling the transfer failed: %v", err)
+	}
+	stub.Creator = receiver
+	if _, err := schedule("schedule_after_cancel", "Org1MSP", domain.ServicingSegment{LoanIDs: []string{"LOAN_T2"}}, transferDay.AddDate(0, 1, 0).Format("2006-01-02")); err != nil {
+		t.Errorf("expected the cancelled transfer to release LOAN_T2, got %v", err)
+	}
+}
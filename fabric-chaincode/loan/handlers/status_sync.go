@@ -0,0 +1,379 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	loanServices "github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// StatusSyncHandler handles the outbound sync of loan status changes to core banking
+type StatusSyncHandler struct {
+	persistenceService *services.PersistenceService
+	eventService      *loanServices.EventService
+}
+
+// NewStatusSyncHandler creates a new status sync handler
+func NewStatusSyncHandler() *StatusSyncHandler {
+	return &StatusSyncHandler{
+		persistenceService: services.NewPersistenceService(),
+		eventService:      loanServices.NewEventService(),
+	}
+}
+
+// GetPendingSyncRecords returns a page of the status sync records core banking has not yet
+// acknowledged, in sequence order within each loan.
+// Args: [pageSize [, bookmark]]
+func (h *StatusSyncHandler) GetPendingSyncRecords(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) > 2 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 0 to 2, got %d", len(args))
+	}
+
+	pageSize, bookmark, err := services.ParsePageArgs(args)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, nextBookmark, err := h.persistenceService.GetPageByPartialCompositeKeys(stub, "LOAN_SYNC_PENDING", [][]string{{}}, pageSize, bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get pending sync records: %w", err)
+	}
+
+	page := &domain.StatusSyncPage{
+		Records:  []domain.StatusSyncRecord{},
+		Bookmark: nextBookmark,
+	}
+	for _, entry := range entries {
+		_, attributes, err := stub.SplitCompositeKey(entry.Key)
+		if err != nil || len(attributes) != 2 {
+			return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid pending sync key %s", entry.Key)
+		}
+		record, err := h.getSyncRecord(stub, attributes[0], attributes[1])
+		if err != nil {
+			return nil, err
+		}
+		page.Records = append(page.Records, *record)
+	}
+	page.Count = len(page.Records)
+
+	return json.Marshal(page)
+}
+
+// AckSyncRecord records that core banking applied a status sync record. Records must be
+// acknowledged in sequence; acknowledging one already acknowledged is a no-op, so the adapter may
+// safely retry.
+func (h *StatusSyncHandler) AckSyncRecord(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.StatusSyncAckRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, fmt.Errorf("failed to parse sync acknowledgment: %w", err)
+	}
+	if req.LoanID == "" || req.Sequence <= 0 {
+		return nil, fmt.Errorf("loanID and a positive sequence are required")
+	}
+	if err := checkStatusSyncRole(stub); err != nil {
+		return nil, err
+	}
+
+	state, err := h.getSyncState(stub, req.LoanID)
+	if err != nil {
+		return nil, err
+	}
+	if req.Sequence > state.LastSequence {
+		return nil, fmt.Errorf("loan %s has no sync record %d; the last is %d", req.LoanID, req.Sequence, state.LastSequence)
+	}
+
+	sequenceKey := syncSequenceKey(req.Sequence)
+	record, err := h.getSyncRecord(stub, req.LoanID, sequenceKey)
+	if err != nil {
+		return nil, err
+	}
+	if req.Sequence <= state.AckedSequence {
+		return json.Marshal(record)
+	}
+
+	// A later record acknowledged first means the adapter skipped one
+	if req.Sequence > state.AckedSequence+1 {
+		return nil, fmt.Errorf("sync records %d to %d of loan %s are unacknowledged; acknowledge records in sequence", state.AckedSequence+1, req.Sequence-1, req.LoanID)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	record.AckedDate = &now
+	record.AckedBy = req.ActorID
+	record.ConsumerRef = req.ConsumerRef
+	if err := putSyncRecord(stub, h.persistenceService, record); err != nil {
+		return nil, err
+	}
+
+	pendingKey, err := stub.CreateCompositeKey("LOAN_SYNC_PENDING", []string{req.LoanID, sequenceKey})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := stub.DelState(pendingKey); err != nil {
+		return nil, fmt.Errorf("failed to remove pending sync index: %w", err)
+	}
+
+	state.AckedSequence = req.Sequence
+	state.LastUpdated = now
+	if err := putSyncState(stub, h.persistenceService, state); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(record)
+}
+
+// ResendSyncRecords offers a loan's unacknowledged status sync records again, from a sequence or
+// from the first unacknowledged record, in a LoanStatusSyncResent event
+func (h *StatusSyncHandler) ResendSyncRecords(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.StatusSyncResendRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse sync resend request: %v", err)
+	}
+	if req.LoanID == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "loanID", "loanID is required")
+	}
+	if err := checkStatusSyncRole(stub); err != nil {
+		return nil, err
+	}
+
+	state, err := h.getSyncState(stub, req.LoanID)
+	if err != nil {
+		return nil, err
+	}
+	from := req.FromSequence
+	if from <= state.AckedSequence {
+		from = state.AckedSequence + 1
+	}
+	if from > state.LastSequence {
+		return nil, fmt.Errorf("loan %s has no unacknowledged sync records from %d", req.LoanID, from)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	page := &domain.StatusSyncPage{Records: []domain.StatusSyncRecord{}}
+	for sequence := from; sequence <= state.LastSequence; sequence++ {
+		record, err := h.getSyncRecord(stub, req.LoanID, syncSequenceKey(sequence))
+		if err != nil {
+			return nil, err
+		}
+		record.SendCount++
+		record.LastSentDate = now
+		if err := putSyncRecord(stub, h.persistenceService, record); err != nil {
+			return nil, err
+		}
+		page.Records = append(page.Records, *record)
+	}
+	page.Count = len(page.Records)
+
+	// Emit event
+	if err := h.eventService.EmitLoanStatusSyncResent(stub, req.LoanID, page, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(page)
+}
+
+// GetSyncGaps returns a page of the loans whose status sync records core banking has not fully
+// acknowledged, flagging those left unacknowledged longer than config.StatusSyncAckTimeout.
+// Args: [pageSize [, bookmark]]
+func (h *StatusSyncHandler) GetSyncGaps(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) > 2 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 0 to 2, got %d", len(args))
+	}
+
+	pageSize, bookmark, err := services.ParsePageArgs(args)
+	if err != nil {
+		return nil, err
+	}
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, nextBookmark, err := h.persistenceService.GetPageByPartialCompositeKeys(stub, "LOAN_SYNC_STATE", [][]string{{}}, pageSize, bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sync states: %w", err)
+	}
+
+	report := &domain.StatusSyncGapReport{
+		AsOf:     now,
+		Gaps:     []domain.StatusSyncGap{},
+		Bookmark: nextBookmark,
+	}
+	for _, entry := range entries {
+		var state domain.StatusSyncState
+		if err := json.Unmarshal(entry.Value, &state); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal sync state: %w", err)
+		}
+		if state.AckedSequence >= state.LastSequence {
+			continue
+		}
+
+		oldest, err := h.getSyncRecord(stub, state.LoanID, syncSequenceKey(state.AckedSequence+1))
+		if err != nil {
+			return nil, err
+		}
+		report.Gaps = append(report.Gaps, domain.StatusSyncGap{
+			LoanID:        state.LoanID,
+			AckedSequence: state.AckedSequence,
+			LastSequence:  state.LastSequence,
+			Pending:       state.LastSequence - state.AckedSequence,
+			OldestSent:    oldest.LastSentDate,
+			Stale:         now.Sub(oldest.LastSentDate) > config.StatusSyncAckTimeout,
+		})
+	}
+	report.Count = len(report.Gaps)
+
+	return json.Marshal(report)
+}
+
+// GetSyncState retrieves how far a loan's status sync records have been produced and acknowledged
+func (h *StatusSyncHandler) GetSyncState(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	state, err := h.getSyncState(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(state)
+}
+
+// Helper methods
+
+func (h *StatusSyncHandler) getSyncState(stub shim.ChaincodeStubInterface, loanID string) (*domain.StatusSyncState, error) {
+	stateKey, err := stub.CreateCompositeKey("LOAN_SYNC_STATE", []string{loanID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %w", err)
+	}
+	var state domain.StatusSyncState
+	if err := h.persistenceService.Get(stub, stateKey, &state); err != nil {
+		return nil, fmt.Errorf("no status sync records for loan %s: %w", loanID, err)
+	}
+	return &state, nil
+}
+
+func (h *StatusSyncHandler) getSyncRecord(stub shim.ChaincodeStubInterface, loanID, sequenceKey string) (*domain.StatusSyncRecord, error) {
+	recordKey, err := stub.CreateCompositeKey("LOAN_SYNC", []string{loanID, sequenceKey})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %w", err)
+	}
+	var record domain.StatusSyncRecord
+	if err := h.persistenceService.Get(stub, recordKey, &record); err != nil {
+		return nil, fmt.Errorf("sync record %s of loan %s not found: %w", sequenceKey, loanID, err)
+	}
+	return &record, nil
+}
+
+// recordStatusSync queues a loan's new status for core banking. Called by putLoanApplication
+// whenever a loan is created or changes status; sandbox loans are not synced.
+func recordStatusSync(stub shim.ChaincodeStubInterface, ps *services.PersistenceService, loanApp *domain.LoanApplication, previousStatus string) error {
+	if loanApp.Sandbox {
+		return nil
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return err
+	}
+
+	stateKey, err := stub.CreateCompositeKey("LOAN_SYNC_STATE", []string{loanApp.LoanID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %w", err)
+	}
+	state := &domain.StatusSyncState{LoanID: loanApp.LoanID}
+	exists, err := ps.Exists(stub, stateKey)
+	if err != nil {
+		return fmt.Errorf("failed to check sync state: %w", err)
+	}
+	if exists {
+		if err := ps.Get(stub, stateKey, state); err != nil {
+			return fmt.Errorf("failed to read sync state: %w", err)
+		}
+	}
+
+	state.LastSequence++
+	state.LastUpdated = now
+	record := &domain.StatusSyncRecord{
+		LoanID:         loanApp.LoanID,
+		Sequence:       state.LastSequence,
+		CustomerID:     loanApp.CustomerID,
+		LoanType:       loanApp.LoanType,
+		PreviousStatus: previousStatus,
+		Status:         string(loanApp.Status),
+		ChangedDate:    now,
+		TransactionID:  stub.GetTxID(),
+		SendCount:      1,
+		LastSentDate:   now,
+	}
+	if err := putSyncRecord(stub, ps, record); err != nil {
+		return err
+	}
+	pendingKey, err := stub.CreateCompositeKey("LOAN_SYNC_PENDING", []string{loanApp.LoanID, syncSequenceKey(record.Sequence)})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := stub.PutState(pendingKey, []byte(loanApp.LoanID)); err != nil {
+		return fmt.Errorf("failed to create pending sync index: %w", err)
+	}
+	return putSyncState(stub, ps, state)
+}
+
+func putSyncRecord(stub shim.ChaincodeStubInterface, ps *services.PersistenceService, record *domain.StatusSyncRecord) error {
+	recordKey, err := stub.CreateCompositeKey("LOAN_SYNC", []string{record.LoanID, syncSequenceKey(record.Sequence)})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := ps.Put(stub, recordKey, record); err != nil {
+		return fmt.Errorf("failed to store sync record: %w", err)
+	}
+	return nil
+}
+
+func putSyncState(stub shim.ChaincodeStubInterface, ps *services.PersistenceService, state *domain.StatusSyncState) error {
+	stateKey, err := stub.CreateCompositeKey("LOAN_SYNC_STATE", []string{state.LoanID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := ps.Put(stub, stateKey, state); err != nil {
+		return fmt.Errorf("failed to store sync state: %w", err)
+	}
+	return nil
+}
+
+// syncSequenceKey zero-pads a sequence so keys sort in sequence order
+func syncSequenceKey(sequence int) string {
+	return fmt.Sprintf("%010d", sequence)
+}
+
+// checkStatusSyncRole checks the invoker may acknowledge or resend status sync records
+func checkStatusSyncRole(stub shim.ChaincodeStubInterface) error {
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return err
+	}
+	if role != string(validation.ActorRoleLoanOperationsManager) && role != string(validation.ActorRoleSystemAdministrator) {
+		return services.NewChaincodeError(services.ErrCodeAccessDenied, "", "status sync records may only be acknowledged or resent by a %s or %s", validation.ActorRoleLoanOperationsManager, validation.ActorRoleSystemAdministrator)
+	}
+	return nil
+}
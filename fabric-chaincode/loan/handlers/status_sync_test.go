@@ -0,0 +1,192 @@
+package handlers
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// syncTime is when the status sync tests move loans through underwriting
+var syncTime = time.Date(2026, 3, 2, 9, 0, 0, 0, time.UTC)
+
+func ackSync(t *testing.T, stub *shimtest.MockStub, txID, loanID string, sequence int, consumerRef string) (*domain.StatusSyncRecord, error) {
+	t.Helper()
+	payload, err := inTxAt(stub, txID, syncTime.Add(5*time.Minute), func() ([]byte, error) {
+		return NewStatusSyncHandler().AckSyncRecord(stub, []string{mustJSON(t, domain.StatusSyncAckRequest{
+			LoanID: loanID, Sequence: sequence, ConsumerRef: consumerRef, ActorID: "ACTOR_009",
+		})})
+	})
+	if err != nil {
+		return nil, err
+	}
+	var record domain.StatusSyncRecord
+	if err := json.Unmarshal(payload, &record); err != nil {
+		t.Fatalf("failed to decode sync record: %v", err)
+	}
+	return &record, nil
+}
+
+func syncGaps(t *testing.T, stub *shimtest.MockStub, txID string, at time.Time) []domain.StatusSyncGap {
+	t.Helper()
+	payload, err := inTxAt(stub, txID, at, func() ([]byte, error) {
+		return NewStatusSyncHandler().GetSyncGaps(stub, []string{})
+	})
+	if err != nil {
+		t.Fatalf("failed to read sync gaps: %v", err)
+	}
+	var report domain.StatusSyncGapReport
+	if err := json.Unmarshal(payload, &report); err != nil {
+		t.Fatalf("failed to decode sync gaps: %v", err)
+	}
+	return report.Gaps
+}
+
+func resendSync(t *testing.T, stub *shimtest.MockStub, txID, loanID string, fromSequence int, at time.Time) (*domain.StatusSyncPage, error) {
+	t.Helper()
+	payload, err := inTxAt(stub, txID, at, func() ([]byte, error) {
+		return NewStatusSyncHandler().ResendSyncRecords(stub, []string{mustJSON(t, domain.StatusSyncResendRequest{
+			LoanID: loanID, FromSequence: fromSequence, ActorID: "ACTOR_009",
+		})})
+	})
+	if err != nil {
+		return nil, err
+	}
+	var page domain.StatusSyncPage
+	if err := json.Unmarshal(payload, &page); err != nil {
+		t.Fatalf("failed to decode resent records: %v", err)
+	}
+	return &page, nil
+}
+
+func TestStatusSyncRecordsAreAcknowledgedInOrderAndResent(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleUnderwriter))
+	handler := NewStatusSyncHandler()
+	seedLoan(t, stub, "LOAN_S1", validation.LoanStatusSubmitted)
+	seedLoan(t, stub, "LOAN_S2", validation.LoanStatusSubmitted, func(loanApp *domain.LoanApplication) {
+		loanApp.Sandbox = true
+	})
+	seedLoan(t, stub, "LOAN_S3", validation.LoanStatusSubmitted)
+
+	// Every status change queues the next record for the loan
+	for i, status := range []validation.LoanApplicationStatus{validation.LoanStatusUnderwriting, validation.LoanStatusCreditApproval} {
+		if _, err := inTxAt(stub, "move_"+string(status), syncTime.Add(time.Duration(i)*time.Minute), func() ([]byte, error) {
+			return NewLoanApplicationHandler().UpdateLoanStatus(stub, []string{mustJSON(t, domain.LoanStatusUpdateRequest{
+				LoanID: "LOAN_S1", NewStatus: status, ActorID: "ACTOR_002",
+			})})
+		}); err != nil {
+			t.Fatalf("failed to move LOAN_S1 to %s: %v", status, err)
+		}
+	}
+
+	// Sandbox loans are never queued
+	if _, err := inTx(stub, "state_sandbox", func() ([]byte, error) {
+		return handler.GetSyncState(stub, []string{"LOAN_S2"})
+	}); err == nil {
+		t.Error("expected no sync state for a sandbox loan")
+	}
+
+	payload, err := inTx(stub, "pending", func() ([]byte, error) {
+		return handler.GetPendingSyncRecords(stub, []string{"10"})
+	})
+	if err != nil {
+		t.Fatalf("failed to read pending records: %v", err)
+	}
+	var pending domain.StatusSyncPage
+	if err := json.Unmarshal(payload, &pending); err != nil || pending.Count != 4 {
+		t.Fatalf("expected 4 pending records, got %+v (%v)", pending, err)
+	}
+	for i, expected := range []struct {
+		sequence         int
+		previous, status validation.LoanApplicationStatus
+	}{
+		{1, "", validation.LoanStatusSubmitted},
+		{2, validation.LoanStatusSubmitted, validation.LoanStatusUnderwriting},
+		{3, validation.LoanStatusUnderwriting, validation.LoanStatusCreditApproval},
+	} {
+		record := pending.Records[i]
+		if record.LoanID != "LOAN_S1" || record.Sequence != expected.sequence || record.PreviousStatus != string(expected.previous) || record.Status != string(expected.status) {
+			t.Errorf("record %d: expected %s -> %s at sequence %d, got %+v", i, expected.previous, expected.status, expected.sequence, record)
+		}
+	}
+	if record := pending.Records[3]; record.LoanID != "LOAN_S3" || record.Sequence != 1 {
+		t.Errorf("expected LOAN_S3's record last, got %+v", record)
+	}
+
+	// Only operations may acknowledge, and only in sequence
+	_, err = ackSync(t, stub, "ack_denied", "LOAN_S1", 1, "CBS-1")
+	expectErrorCode(t, err, services.ErrCodeAccessDenied)
+	stub.Creator = newTestIdentity(t, string(validation.ActorRoleLoanOperationsManager))
+	if _, err := ackSync(t, stub, "ack_skip", "LOAN_S1", 2, "CBS-2"); err == nil || !strings.Contains(err.Error(), "acknowledge records in sequence") {
+		t.Errorf("expected an out-of-order acknowledgment to be refused, got %v", err)
+	}
+	if _, err := ackSync(t, stub, "ack_future", "LOAN_S1", 4, "CBS-4"); err == nil || !strings.Contains(err.Error(), "no sync record 4") {
+		t.Errorf("expected an acknowledgment past the last record to be refused, got %v", err)
+	}
+	first, err := ackSync(t, stub, "ack_1", "LOAN_S1", 1, "CBS-1")
+	if err != nil {
+		t.Fatalf("failed to acknowledge record 1: %v", err)
+	}
+	if first.AckedDate == nil || first.AckedBy != "ACTOR_009" || first.ConsumerRef != "CBS-1" {
+		t.Errorf("unexpected acknowledged record: %+v", first)
+	}
+	if retried, err := ackSync(t, stub, "ack_1_retry", "LOAN_S1", 1, "CBS-1-RETRY"); err != nil || retried.ConsumerRef != "CBS-1" {
+		t.Errorf("expected a retried acknowledgment to change nothing, got %+v (%v)", retried, err)
+	}
+	if _, err := ackSync(t, stub, "ack_s3", "LOAN_S3", 1, "CBS-S3"); err != nil {
+		t.Fatalf("failed to acknowledge LOAN_S3: %v", err)
+	}
+
+	// A gap turns stale once its oldest record has gone unacknowledged past the timeout
+	gaps := syncGaps(t, stub, "gaps_fresh", syncTime.Add(10*time.Minute))
+	if len(gaps) != 1 || gaps[0].LoanID != "LOAN_S1" || gaps[0].AckedSequence != 1 || gaps[0].Pending != 2 || gaps[0].Stale {
+		t.Fatalf("expected one fresh gap of 2 on LOAN_S1, got %+v", gaps)
+	}
+	staleAt := syncTime.Add(20 * time.Minute)
+	if gaps := syncGaps(t, stub, "gaps_stale", staleAt); len(gaps) != 1 || !gaps[0].Stale {
+		t.Fatalf("expected the gap to be stale, got %+v", gaps)
+	}
+
+	// A resend offers everything unacknowledged again and restarts the clock
+	resent, err := resendSync(t, stub, "resend", "LOAN_S1", 0, staleAt)
+	if err != nil {
+		t.Fatalf("failed to resend: %v", err)
+	}
+	if resent.Count != 2 || resent.Records[0].Sequence != 2 || resent.Records[0].SendCount != 2 || !resent.Records[0].LastSentDate.Equal(staleAt) {
+		t.Errorf("expected records 2 and 3 resent, got %+v", resent.Records)
+	}
+	if resent, err := resendSync(t, stub, "resend_from_3", "LOAN_S1", 3, staleAt); err != nil || resent.Count != 1 || resent.Records[0].SendCount != 3 {
+		t.Errorf("expected only record 3 resent, got %+v (%v)", resent, err)
+	}
+	if gaps := syncGaps(t, stub, "gaps_resent", syncTime.Add(30*time.Minute)); len(gaps) != 1 || gaps[0].Stale {
+		t.Errorf("expected the resent gap to be fresh, got %+v", gaps)
+	}
+
+	// Acknowledging the rest closes the gap
+	for _, sequence := range []int{2, 3} {
+		if _, err := ackSync(t, stub, "ack_rest", "LOAN_S1", sequence, "CBS"); err != nil {
+			t.Fatalf("failed to acknowledge record %d: %v", sequence, err)
+		}
+	}
+	payload, err = inTx(stub, "state", func() ([]byte, error) {
+		return handler.GetSyncState(stub, []string{"LOAN_S1"})
+	})
+	if err != nil {
+		t.Fatalf("failed to read sync state: %v", err)
+	}
+	var state domain.StatusSyncState
+	if err := json.Unmarshal(payload, &state); err != nil || state.LastSequence != 3 || state.AckedSequence != 3 {
+		t.Errorf("expected all 3 records acknowledged, got %+v (%v)", state, err)
+	}
+	if _, err := resendSync(t, stub, "resend_none", "LOAN_S1", 0, staleAt); err == nil || !strings.Contains(err.Error(), "no unacknowledged sync records") {
+		t.Errorf("expected nothing to resend, got %v", err)
+	}
+	if gaps := syncGaps(t, stub, "gaps_closed", syncTime.Add(time.Hour)); len(gaps) != 0 {
+		t.Errorf("expected no gaps, got %+v", gaps)
+	}
+}
@@ -0,0 +1,526 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	loanServices "github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// stpPolicyKey holds the current straight-through processing policy
+const stpPolicyKey = "STP_POLICY"
+
+// StraightThroughHandler handles automatic approval of low-risk small loans by the underwriting engine
+type StraightThroughHandler struct {
+	persistenceService *services.PersistenceService
+	eventService       *loanServices.EventService
+	customerVerifier   *loanServices.CustomerVerificationService
+	scheduleService    *loanServices.ScheduleService
+	indexRateService   *loanServices.IndexRateService
+}
+
+// NewStraightThroughHandler creates a new straight-through processing handler
+func NewStraightThroughHandler() *StraightThroughHandler {
+	return &StraightThroughHandler{
+		persistenceService: services.NewPersistenceService(),
+		eventService:       loanServices.NewEventService(),
+		customerVerifier:   loanServices.NewCustomerVerificationService(),
+		scheduleService:    loanServices.NewScheduleService(),
+		indexRateService:   loanServices.NewIndexRateService(),
+	}
+}
+
+// SetSTPPolicy replaces the straight-through processing policy
+func (h *StraightThroughHandler) SetSTPPolicy(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.STPPolicyRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse STP policy request: %v", err)
+	}
+
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+	if req.MaxAmount < 0 || req.MaxAmount > config.MaxLoanAmount {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "maxAmount must be between 0 and %.2f, got %.2f", config.MaxLoanAmount, req.MaxAmount)
+	}
+	if req.MaxRiskScore < 0 || req.MaxRiskScore > 100 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "maxRiskScore must be between 0 and 100, got %.2f", req.MaxRiskScore)
+	}
+	if req.SampleRate < 0 || req.SampleRate > 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "sampleRate must be between 0 and 1, got %.4f", req.SampleRate)
+	}
+	for loanType, rate := range req.InterestRates {
+		if err := validation.ValidateLoanType(loanType); err != nil {
+			return nil, fmt.Errorf("invalid loan type in interestRates: %w", err)
+		}
+		if rate <= 0 {
+			return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "interest rate for %s must be greater than 0", loanType)
+		}
+	}
+
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return nil, err
+	}
+	if role != string(validation.ActorRoleCreditOfficer) {
+		return nil, services.NewChaincodeError(services.ErrCodeAccessDenied, "", "the STP policy may only be set by a %s", validation.ActorRoleCreditOfficer)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &domain.STPPolicy{
+		Enabled:       req.Enabled,
+		MaxAmount:     req.MaxAmount,
+		MaxRiskScore:  req.MaxRiskScore,
+		InterestRates: req.InterestRates,
+		SampleRate:    req.SampleRate,
+		LastUpdated:   now,
+		LastUpdatedBy: req.ActorID,
+	}
+	if policy.InterestRates == nil {
+		policy.InterestRates = map[string]float64{}
+	}
+
+	if err := h.persistenceService.Put(stub, stpPolicyKey, policy); err != nil {
+		return nil, fmt.Errorf("failed to store STP policy: %w", err)
+	}
+
+	if err := h.eventService.EmitSTPPolicyUpdated(stub, policy, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(policy)
+}
+
+// GetSTPPolicy returns the straight-through processing policy. STP is disabled until a policy is set.
+func (h *StraightThroughHandler) GetSTPPolicy(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 0, got %d", len(args))
+	}
+
+	policy, err := h.getPolicy(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(policy)
+}
+
+// EvaluateStraightThrough is called by the underwriting engine for a submitted application. Loans
+// under the policy amount whose parties are all fully verified and low risk, with every required
+// document verified, are approved without human action at the policy rate; all others are left
+// for manual underwriting with the reasons recorded. A random share of auto-approvals is queued
+// for quality review.
+func (h *StraightThroughHandler) EvaluateStraightThrough(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.STPEvaluationRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse STP evaluation request: %v", err)
+	}
+
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	loanKey := fmt.Sprintf("LOAN_%s", req.LoanID)
+	var loanApp domain.LoanApplication
+	if err := h.persistenceService.Get(stub, loanKey, &loanApp); err != nil {
+		return nil, fmt.Errorf("loan application not found: %w", err)
+	}
+	if loanApp.Status != validation.LoanStatusSubmitted && loanApp.Status != validation.LoanStatusUnderwriting {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "loan cannot be evaluated for straight-through processing from current status: %s", loanApp.Status)
+	}
+	if err := checkComplianceHold(&loanApp); err != nil {
+		return nil, err
+	}
+
+	policy, err := h.getPolicy(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	decision := &domain.STPDecision{
+		LoanID:          loanApp.LoanID,
+		LoanType:        loanApp.LoanType,
+		RequestedAmount: loanApp.RequestedAmount.Float64(),
+		EvaluatedDate:   now,
+		EvaluatedBy:     req.ActorID,
+		TransactionID:   stub.GetTxID(),
+	}
+
+	interestRate, err := h.evaluate(stub, policy, &loanApp, decision)
+	if err != nil {
+		return nil, err
+	}
+	decision.AutoApproved = len(decision.ReferralReasons) == 0
+	decision.Sampled = decision.AutoApproved && !loanApp.Sandbox && services.Sampled(decision.TransactionID+"|"+loanApp.LoanID, policy.SampleRate)
+
+	if decision.AutoApproved {
+		if err := h.autoApprove(stub, &loanApp, decision, interestRate, now, req.ActorID); err != nil {
+			return nil, err
+		}
+	}
+
+	if err := h.putDecision(stub, decision, loanApp.Sandbox); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(decision)
+}
+
+// GetSTPDecision returns the latest straight-through processing evaluation of a loan
+func (h *StraightThroughHandler) GetSTPDecision(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var decision domain.STPDecision
+	if err := h.persistenceService.Get(stub, fmt.Sprintf("STP_DECISION_%s", args[0]), &decision); err != nil {
+		return nil, fmt.Errorf("STP decision not found: %w", err)
+	}
+
+	return json.Marshal(&decision)
+}
+
+// GetSTPRateReport reports how many evaluated applications were auto-approved between two dates
+// inclusive, with referral reasons. Sandbox loans are excluded. Args: fromDate, toDate (YYYY-MM-DD)
+func (h *StraightThroughHandler) GetSTPRateReport(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 2, got %d", len(args))
+	}
+
+	days, err := stpReportDays(args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.STPRateReport{
+		FromDate:        args[0],
+		ToDate:          args[1],
+		ReferralReasons: map[string]int{},
+		GeneratedDate:   now,
+	}
+	for _, day := range days {
+		iterator, err := stub.GetStateByPartialCompositeKey("STP_BY_DATE", day)
+		if err != nil {
+			return nil, fmt.Errorf("failed to query STP decisions: %w", err)
+		}
+
+		for iterator.HasNext() {
+			response, err := iterator.Next()
+			if err != nil {
+				iterator.Close()
+				return nil, fmt.Errorf("failed to iterate STP decisions: %w", err)
+			}
+
+			var decision domain.STPDecision
+			if err := h.persistenceService.Get(stub, fmt.Sprintf("STP_DECISION_%s", string(response.Value)), &decision); err != nil {
+				continue // Skip if decision not found
+			}
+
+			report.Evaluated++
+			if decision.AutoApproved {
+				report.AutoApproved++
+			} else {
+				report.Referred++
+			}
+			if decision.Sampled {
+				report.Sampled++
+			}
+			for _, reason := range decision.ReferralReasons {
+				report.ReferralReasons[reason]++
+			}
+		}
+		iterator.Close()
+	}
+
+	if report.Evaluated > 0 {
+		report.STPRate = math.Round(float64(report.AutoApproved)/float64(report.Evaluated)*10000) / 100
+	}
+
+	return json.Marshal(report)
+}
+
+// QuerySTPSampleQueue returns a page of auto-approved applications sampled for quality review,
+// oldest first. Args: fromDate, toDate (YYYY-MM-DD) [, pageSize [, bookmark]]
+func (h *StraightThroughHandler) QuerySTPSampleQueue(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) < 2 || len(args) > 4 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 2 to 4, got %d", len(args))
+	}
+
+	days, err := stpReportDays(args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	pageSize, bookmark, err := services.ParsePageArgs(args[2:])
+	if err != nil {
+		return nil, err
+	}
+
+	entries, nextBookmark, err := h.persistenceService.GetPageByPartialCompositeKeys(stub, "STP_SAMPLE", days, pageSize, bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get STP sample queue: %w", err)
+	}
+
+	decisions := []domain.STPDecision{}
+	for _, entry := range entries {
+		var decision domain.STPDecision
+		if err := h.persistenceService.Get(stub, fmt.Sprintf("STP_DECISION_%s", string(entry.Value)), &decision); err != nil {
+			continue // Skip if decision not found
+		}
+		decisions = append(decisions, decision)
+	}
+
+	return json.Marshal(&domain.STPSampleQueueResult{Decisions: decisions, Count: len(decisions), Bookmark: nextBookmark})
+}
+
+// Helper methods
+
+func (h *StraightThroughHandler) getPolicy(stub shim.ChaincodeStubInterface) (*domain.STPPolicy, error) {
+	exists, err := h.persistenceService.Exists(stub, stpPolicyKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to check STP policy: %w", err)
+	}
+	if !exists {
+		return &domain.STPPolicy{InterestRates: map[string]float64{}}, nil
+	}
+
+	var policy domain.STPPolicy
+	if err := h.persistenceService.Get(stub, stpPolicyKey, &policy); err != nil {
+		return nil, fmt.Errorf("failed to get STP policy: %w", err)
+	}
+	return &policy, nil
+}
+
+// evaluate records every reason the application needs a human decision on the decision and
+// returns the rate an auto-approval would be priced at
+func (h *StraightThroughHandler) evaluate(stub shim.ChaincodeStubInterface, policy *domain.STPPolicy, loanApp *domain.LoanApplication, decision *domain.STPDecision) (float64, error) {
+	refer := func(reason string) {
+		decision.ReferralReasons = append(decision.ReferralReasons, reason)
+	}
+
+	if !policy.Enabled {
+		refer(domain.STPReferralDisabled)
+		return 0, nil
+	}
+
+	interestRate, eligibleType := policy.InterestRates[loanApp.LoanType]
+	if !eligibleType {
+		refer(domain.STPReferralLoanType)
+	}
+	if loanApp.RequestedAmount.Float64() > policy.MaxAmount {
+		refer(domain.STPReferralAmount)
+	}
+
+	// The policy limits are in the base currency; other currencies are converted by an underwriter
+	if loanCurrency(loanApp) != config.BaseCurrency {
+		refer(domain.STPReferralCurrency)
+	}
+
+	// The policy rate must sit within the loan type's rate policy for the customer's risk tier
+	if eligibleType {
+		riskTier, err := h.customerVerifier.CustomerRiskTier(stub, loanApp.CustomerID)
+		if err == nil {
+			decision.RatePolicyVersion, err = checkInterestRatePolicy(stub, h.persistenceService, h.indexRateService, loanApp.LoanType, riskTier, interestRate)
+		}
+		if err != nil {
+			refer(domain.STPReferralRatePolicy)
+		}
+	}
+
+	// Every party must still pass the full KYC/AML/consent checks and be low risk
+	parties := []string{loanApp.CustomerID}
+	for _, party := range loanApp.Parties {
+		if party.CustomerID != loanApp.CustomerID {
+			parties = append(parties, party.CustomerID)
+		}
+	}
+	verified, lowRisk := true, true
+	for _, customerID := range parties {
+		status, err := h.customerVerifier.VerifyCustomer(stub, customerID)
+		if err != nil {
+			verified = false
+		}
+		if status == nil {
+			continue
+		}
+		decision.RiskScore = math.Max(decision.RiskScore, status.AMLRiskScore)
+		if status.AMLRiskScore > policy.MaxRiskScore {
+			lowRisk = false
+		}
+	}
+	if !verified {
+		refer(domain.STPReferralVerification)
+	}
+	if !lowRisk {
+		refer(domain.STPReferralRiskScore)
+	}
+
+	documents, err := getLoanDocuments(stub, h.persistenceService, loanApp.LoanID)
+	if err != nil {
+		return 0, err
+	}
+	if len(outstandingDocuments(loanApp.LoanType, documents)) > 0 {
+		refer(domain.STPReferralDocuments)
+	}
+
+	checklist, err := getLoanChecklistStatus(stub, h.persistenceService, loanApp)
+	if err != nil {
+		return 0, err
+	}
+	if !checklist.Complete {
+		refer(domain.STPReferralChecklist)
+	}
+
+	collateralValue, err := getCollateralValue(stub, h.persistenceService, loanApp.LoanID)
+	if err != nil {
+		return 0, fmt.Errorf("failed to value collateral: %w", err)
+	}
+	if _, err := validation.ValidateLoanToValue(loanApp.RequestedAmount.Float64(), collateralValue, loanApp.LoanType); err != nil {
+		refer(domain.STPReferralLoanToValue)
+	}
+
+	return interestRate, nil
+}
+
+// autoApprove approves the requested amount at the policy rate on behalf of the underwriting engine
+func (h *StraightThroughHandler) autoApprove(stub shim.ChaincodeStubInterface, loanApp *domain.LoanApplication, decision *domain.STPDecision, interestRate float64, now time.Time, actorID string) error {
+	previousStatus := loanApp.Status
+
+	collateralValue, err := getCollateralValue(stub, h.persistenceService, loanApp.LoanID)
+	if err != nil {
+		return fmt.Errorf("failed to value collateral: %w", err)
+	}
+	if collateralValue > 0 {
+		ltv, _ := validation.ValidateLoanToValue(loanApp.RequestedAmount.Float64(), collateralValue, loanApp.LoanType)
+		loanApp.LoanToValue = &ltv
+	}
+
+	currency := loanCurrency(loanApp)
+	approved := loanApp.RequestedAmount
+	riskScore := decision.RiskScore
+	loanApp.Status = validation.LoanStatusApproved
+	loanApp.Currency = currency
+	loanApp.ApprovedAmount = &approved
+	loanApp.InterestRate = &interestRate
+	loanApp.RatePolicyVersion = decision.RatePolicyVersion
+	loanApp.RiskScore = &riskScore
+	loanApp.AutoApproved = true
+	loanApp.UnderwriterID = actorID
+	loanApp.DecisionDate = &now
+	loanApp.Notes = "Approved by straight-through processing"
+	loanApp.LastUpdated = now
+	loanApp.LastUpdatedBy = actorID
+
+	if err := putLoanApplication(stub, h.persistenceService, loanApp); err != nil {
+		return fmt.Errorf("failed to update loan application: %w", err)
+	}
+
+	// Generate the repayment schedule from the approval date
+	if _, err := h.scheduleService.GenerateSchedule(stub, loanApp, now); err != nil {
+		return err
+	}
+
+	if err := recordLoanHistory(stub, h.persistenceService, loanApp.LoanID, "AUTO_APPROVAL", "status", string(previousStatus), string(validation.LoanStatusApproved), actorID); err != nil {
+		return err
+	}
+	if decision.RatePolicyVersion > 0 {
+		if err := recordLoanHistory(stub, h.persistenceService, loanApp.LoanID, "AUTO_APPROVAL", "ratePolicyVersion", "", strconv.Itoa(decision.RatePolicyVersion), actorID); err != nil {
+			return err
+		}
+	}
+
+	if err := h.eventService.EmitLoanApproved(stub, loanApp, actorID); err != nil {
+		return fmt.Errorf("failed to emit event: %w", err)
+	}
+	return nil
+}
+
+// putDecision stores the latest evaluation of a loan and moves its report and sample queue index
+// entries to the evaluation date. Sandbox evaluations are kept out of both indexes.
+func (h *StraightThroughHandler) putDecision(stub shim.ChaincodeStubInterface, decision *domain.STPDecision, sandbox bool) error {
+	decisionKey := fmt.Sprintf("STP_DECISION_%s", decision.LoanID)
+
+	var previous domain.STPDecision
+	if h.persistenceService.Get(stub, decisionKey, &previous) == nil {
+		previousDay := previous.EvaluatedDate.UTC().Format(loanIndexDateFormat)
+		for _, objectType := range []string{"STP_BY_DATE", "STP_SAMPLE"} {
+			indexKey, err := stub.CreateCompositeKey(objectType, []string{previousDay, decision.LoanID})
+			if err != nil {
+				return fmt.Errorf("failed to create composite key: %w", err)
+			}
+			if err := stub.DelState(indexKey); err != nil {
+				return fmt.Errorf("failed to remove %s index: %w", objectType, err)
+			}
+		}
+	}
+
+	if err := h.persistenceService.Put(stub, decisionKey, decision); err != nil {
+		return fmt.Errorf("failed to store STP decision: %w", err)
+	}
+	if sandbox {
+		return nil
+	}
+
+	day := decision.EvaluatedDate.UTC().Format(loanIndexDateFormat)
+	if err := putLoanIndex(stub, "STP_BY_DATE", day, decision.LoanID); err != nil {
+		return err
+	}
+	if decision.Sampled {
+		return putLoanIndex(stub, "STP_SAMPLE", day, decision.LoanID)
+	}
+	return nil
+}
+
+// stpReportDays returns one partial key per day bucket between two dates inclusive, in ascending order
+func stpReportDays(from, to string) ([][]string, error) {
+	fromDate, err := time.Parse(loanIndexDateFormat, from)
+	if err != nil {
+		return nil, fmt.Errorf("invalid from date %s: %w", from, err)
+	}
+	toDate, err := time.Parse(loanIndexDateFormat, to)
+	if err != nil {
+		return nil, fmt.Errorf("invalid to date %s: %w", to, err)
+	}
+	if toDate.Before(fromDate) {
+		return nil, fmt.Errorf("to date %s is before from date %s", to, from)
+	}
+	if days := int(toDate.Sub(fromDate).Hours()/24) + 1; days > config.MaxQueryRangeDays {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "date range of %d days exceeds maximum of %d", days, config.MaxQueryRangeDays)
+	}
+
+	var days [][]string
+	for day := fromDate; !day.After(toDate); day = day.AddDate(0, 0, 1) {
+		days = append(days, []string{day.Format(loanIndexDateFormat)})
+	}
+	return days, nil
+}
@@ -0,0 +1,203 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// stpPolicy auto-approves personal and auto loans up to 25,000 for parties scored 30 or below
+var stpPolicy = domain.STPPolicyRequest{
+	Enabled:       true,
+	MaxAmount:     25000,
+	MaxRiskScore:  30,
+	InterestRates: map[string]float64{"PERSONAL": 7.5, "AUTO": 6.5},
+	ActorID:       "ACTOR_004",
+}
+
+// seedDocument stores a document of a loan application in the given review status
+func seedDocument(t *testing.T, stub *shimtest.MockStub, loanID, documentType string, status domain.DocumentStatus) {
+	t.Helper()
+	documentID := fmt.Sprintf("DOC_%s_%s", loanID, documentType)
+	_, err := inTx(stub, "seed_"+documentID, func() ([]byte, error) {
+		document := &domain.LoanDocument{
+			DocumentID:   documentID,
+			LoanID:       loanID,
+			DocumentType: documentType,
+			DocumentHash: "hash_" + documentType,
+			Status:       status,
+			CreatedDate:  time.Date(2026, 1, 6, 0, 0, 0, 0, time.UTC),
+		}
+		if err := services.NewPersistenceService().Put(stub, "DOCUMENT_"+documentID, document); err != nil {
+			return nil, err
+		}
+		indexKey, err := stub.CreateCompositeKey("LOAN_DOCUMENT", []string{loanID, documentID})
+		if err != nil {
+			return nil, err
+		}
+		return nil, stub.PutState(indexKey, []byte(documentID))
+	})
+	if err != nil {
+		t.Fatalf("failed to seed document: %v", err)
+	}
+}
+
+func TestEvaluateStraightThroughAppliesPolicyThresholds(t *testing.T) {
+	tests := []struct {
+		name     string
+		policy   func(*domain.STPPolicyRequest)
+		loan     func(*domain.LoanApplication)
+		setup    func(t *testing.T, stub *shimtest.MockStub, customers *fakeCustomerChaincode)
+		referred []string
+	}{
+		{name: "eligible application is approved"},
+		{
+			name:     "disabled policy approves nothing",
+			policy:   func(policy *domain.STPPolicyRequest) { policy.Enabled = false },
+			referred: []string{domain.STPReferralDisabled},
+		},
+		{
+			name:     "loan type without a policy rate",
+			loan:     func(loanApp *domain.LoanApplication) { loanApp.LoanType = "STUDENT" },
+			referred: []string{domain.STPReferralLoanType},
+		},
+		{
+			name:     "amount above the limit",
+			loan:     func(loanApp *domain.LoanApplication) { loanApp.RequestedAmount = utils.NewMoney(25000.01, "USD") },
+			referred: []string{domain.STPReferralAmount},
+		},
+		{
+			name:     "currency other than the base currency",
+			loan:     inCurrency("EUR", 1.08),
+			referred: []string{domain.STPReferralCurrency},
+		},
+		{
+			name: "policy rate outside the rate policy",
+			setup: func(t *testing.T, stub *shimtest.MockStub, customers *fakeCustomerChaincode) {
+				if _, err := inTx(stub, "rate_policy", func() ([]byte, error) {
+					return NewRatePolicyHandler().SetInterestRatePolicy(stub, []string{mustJSON(t, domain.InterestRatePolicyRequest{
+						LoanType: "PERSONAL", FloorRate: 8, CapRate: 12, ActorID: "ACTOR_004",
+					})})
+				}); err != nil {
+					t.Fatalf("failed to set rate policy: %v", err)
+				}
+			},
+			referred: []string{domain.STPReferralRatePolicy},
+		},
+		{
+			name: "party not verified",
+			setup: func(t *testing.T, stub *shimtest.MockStub, customers *fakeCustomerChaincode) {
+				status := customers.statuses["CUST_001"]
+				status.KYCStatus = string(validation.KYCStatusPending)
+				customers.statuses["CUST_001"] = status
+			},
+			referred: []string{domain.STPReferralVerification},
+		},
+		{
+			name: "co-borrower above the risk score limit",
+			loan: func(loanApp *domain.LoanApplication) {
+				loanApp.Parties = []domain.LoanParty{
+					{CustomerID: "CUST_001", Role: validation.LoanPartyRolePrimaryBorrower, LiabilityShare: 50},
+					{CustomerID: "CUST_002", Role: validation.LoanPartyRoleCoBorrower, LiabilityShare: 50},
+				}
+			},
+			setup: func(t *testing.T, stub *shimtest.MockStub, customers *fakeCustomerChaincode) {
+				customers.statuses["CUST_002"] = eligibleCustomer("CUST_002", 30.5)
+			},
+			referred: []string{domain.STPReferralRiskScore},
+		},
+		{
+			name: "required document not verified",
+			setup: func(t *testing.T, stub *shimtest.MockStub, customers *fakeCustomerChaincode) {
+				seedDocument(t, stub, "LOAN_STP", "INCOME_PROOF", domain.DocumentStatusPending)
+			},
+			referred: []string{domain.STPReferralDocuments},
+		},
+		{
+			name: "checklist incomplete",
+			setup: func(t *testing.T, stub *shimtest.MockStub, customers *fakeCustomerChaincode) {
+				if _, err := inTx(stub, "checklist", func() ([]byte, error) {
+					return NewUnderwritingChecklistHandler().SetUnderwritingChecklist(stub, []string{mustJSON(t, domain.UnderwritingChecklistRequest{
+						LoanType: "PERSONAL", BandID: "STANDARD", MinAmount: 0, MaxAmount: 50000, ActorID: "ACTOR_004",
+						Items: []domain.ChecklistItemDefinition{{ItemID: "EMPLOYER_CALL", Type: domain.ChecklistItemVerification, Description: "Employment confirmed with the employer"}},
+					})})
+				}); err != nil {
+					t.Fatalf("failed to set checklist: %v", err)
+				}
+			},
+			referred: []string{domain.STPReferralChecklist},
+		},
+		{
+			name: "secured loan without collateral",
+			loan: func(loanApp *domain.LoanApplication) { loanApp.LoanType = "AUTO" },
+			setup: func(t *testing.T, stub *shimtest.MockStub, customers *fakeCustomerChaincode) {
+				seedDocument(t, stub, "LOAN_STP", "COLLATERAL", domain.DocumentStatusVerified)
+			},
+			referred: []string{domain.STPReferralLoanToValue},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			stub := newLoanStub(t, string(validation.ActorRoleCreditOfficer))
+			customers := withCustomers(stub, eligibleCustomer("CUST_001", 12))
+			handler := NewStraightThroughHandler()
+
+			policy := stpPolicy
+			if tt.policy != nil {
+				tt.policy(&policy)
+			}
+			if _, err := inTx(stub, "stp_policy", func() ([]byte, error) {
+				return handler.SetSTPPolicy(stub, []string{mustJSON(t, policy)})
+			}); err != nil {
+				t.Fatalf("failed to set STP policy: %v", err)
+			}
+			configure := []func(*domain.LoanApplication){}
+			if tt.loan != nil {
+				configure = append(configure, tt.loan)
+			}
+			seedLoan(t, stub, "LOAN_STP", validation.LoanStatusSubmitted, configure...)
+			seedDocument(t, stub, "LOAN_STP", "IDENTITY", domain.DocumentStatusVerified)
+			seedDocument(t, stub, "LOAN_STP", "INCOME_PROOF", domain.DocumentStatusVerified)
+			if tt.setup != nil {
+				tt.setup(t, stub, customers)
+			}
+
+			payload, err := inTx(stub, "evaluate", func() ([]byte, error) {
+				return handler.EvaluateStraightThrough(stub, []string{mustJSON(t, domain.STPEvaluationRequest{LoanID: "LOAN_STP", ActorID: "ENGINE_001"})})
+			})
+			if err != nil {
+				t.Fatalf("evaluation failed: %v", err)
+			}
+			var decision domain.STPDecision
+			if err := json.Unmarshal(payload, &decision); err != nil {
+				t.Fatalf("failed to decode decision: %v", err)
+			}
+
+			loanApp := getLoan(t, stub, "LOAN_STP")
+			if tt.referred != nil {
+				if decision.AutoApproved || !reflect.DeepEqual(decision.ReferralReasons, tt.referred) {
+					t.Errorf("expected referral for %v, got approved=%t reasons %v", tt.referred, decision.AutoApproved, decision.ReferralReasons)
+				}
+				if loanApp.Status != validation.LoanStatusSubmitted {
+					t.Errorf("expected a referred application to stay SUBMITTED, got %s", loanApp.Status)
+				}
+				return
+			}
+			if !decision.AutoApproved || len(decision.ReferralReasons) != 0 || decision.RiskScore != 12 {
+				t.Errorf("expected auto-approval at risk score 12, got %+v", decision)
+			}
+			if loanApp.Status != validation.LoanStatusApproved || !loanApp.AutoApproved || *loanApp.InterestRate != 7.5 || loanApp.ApprovedAmount.String() != "12000" {
+				t.Errorf("expected 12000 approved at the 7.5 policy rate, got %s %v at %v", loanApp.Status, loanApp.ApprovedAmount, loanApp.InterestRate)
+			}
+		})
+	}
+}
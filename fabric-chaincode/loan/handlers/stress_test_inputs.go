@@ -0,0 +1,252 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// StressTestHandler extracts per-loan inputs for periodic portfolio stress testing
+type StressTestHandler struct {
+	persistenceService *services.PersistenceService
+}
+
+// NewStressTestHandler creates a new stress-test handler
+func NewStressTestHandler() *StressTestHandler {
+	return &StressTestHandler{
+		persistenceService: services.NewPersistenceService(),
+	}
+}
+
+// ExtractStressTestInputs returns a page of stress-test input records for the live book: loans
+// disbursed on or before the as-of date that are still DISBURSED, DELINQUENT or DEFAULTED.
+// Balances, collateral and delinquency are reconstructed as at the end of the as-of date (UTC), so
+// extracts for the same date agree however late they are run. Sandbox loans are excluded.
+// Args: asOfDate (YYYY-MM-DD) [, pageSize [, bookmark]]
+func (h *StressTestHandler) ExtractStressTestInputs(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) < 1 || len(args) > 3 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1 to 3, got %d", len(args))
+	}
+
+	asOfDate, err := time.Parse("2006-01-02", args[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid asOfDate, expected YYYY-MM-DD: %w", err)
+	}
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	if asOfDate.After(now) {
+		return nil, fmt.Errorf("asOfDate %s is in the future", args[0])
+	}
+	// Records dated before the end of the as-of day count towards it
+	asOf := asOfDate.AddDate(0, 0, 1)
+
+	pageSize, bookmark, err := services.ParsePageArgs(args[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	// Partial keys must be in ascending order for the bookmark to work
+	statuses := [][]string{{string(validation.LoanStatusDefaulted)}, {string(validation.LoanStatusDelinquent)}, {string(validation.LoanStatusDisbursed)}}
+	entries, nextBookmark, err := h.persistenceService.GetPageByPartialCompositeKeys(stub, "LOAN_BY_STATUS", statuses, pageSize, bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get loans by status: %w", err)
+	}
+
+	records := []domain.StressTestInput{}
+	for _, entry := range entries {
+		var loanApp domain.LoanApplication
+		if err := h.persistenceService.Get(stub, fmt.Sprintf("LOAN_%s", string(entry.Value)), &loanApp); err != nil {
+			continue // Skip if loan not found
+		}
+
+		// Skip stale index entries, sandbox loans and loans not yet disbursed at the as-of date
+		if loanApp.Sandbox || loanApp.DisbursementDate == nil || !loanApp.DisbursementDate.Before(asOf) {
+			continue
+		}
+		if loanApp.Status != validation.LoanStatusDisbursed && loanApp.Status != validation.LoanStatusDelinquent && loanApp.Status != validation.LoanStatusDefaulted {
+			continue
+		}
+
+		record, err := h.buildStressTestInput(stub, &loanApp, asOf)
+		if err != nil {
+			return nil, err
+		}
+		records = append(records, *record)
+	}
+
+	return json.Marshal(&domain.StressTestExtract{
+		SchemaVersion: domain.StressTestSchemaVersion,
+		AsOfDate:      args[0],
+		Records:       records,
+		Count:         len(records),
+		Bookmark:      nextBookmark,
+	})
+}
+
+// buildStressTestInput reconstructs a loan's stress-test record as at the as-of instant
+func (h *StressTestHandler) buildStressTestInput(stub shim.ChaincodeStubInterface, loanApp *domain.LoanApplication, asOf time.Time) (*domain.StressTestInput, error) {
+	balance, err := balanceAsOf(stub, loanApp, asOf)
+	if err != nil {
+		return nil, err
+	}
+
+	collaterals, err := getLoanCollateral(stub, h.persistenceService, loanApp.LoanID)
+	if err != nil {
+		return nil, err
+	}
+	collateralValue := 0.0
+	for _, collateral := range collaterals {
+		if !collateral.CreatedDate.Before(asOf) {
+			continue // Pledged after the as-of date
+		}
+		if collateral.ReleaseDate != nil && collateral.ReleaseDate.Before(asOf) {
+			continue // Released by the as-of date
+		}
+		collateralValue += collateral.Valuation
+	}
+
+	daysPastDue, termRemaining, scheduled, err := scheduleAsOf(stub, loanApp.LoanID, asOf)
+	if err != nil {
+		return nil, err
+	}
+	if !scheduled {
+		// No repayment schedule was generated; count down the contractual term instead
+		termRemaining = loanApp.TermMonths - monthsBetween(*loanApp.DisbursementDate, asOf)
+		if termRemaining < 0 {
+			termRemaining = 0
+		}
+	}
+
+	return &domain.StressTestInput{
+		LoanID:              loanApp.LoanID,
+		CustomerID:          loanApp.CustomerID,
+		LoanType:            loanApp.LoanType,
+		Status:              loanApp.Status,
+		DisbursementDate:    *loanApp.DisbursementDate,
+		Balance:             balance,
+		InterestRate:        loanApp.InterestRate,
+		RateIndex:           loanApp.RateIndex,
+		TermMonths:          loanApp.TermMonths,
+		TermRemainingMonths: termRemaining,
+		CollateralValue:     collateralValue,
+		DaysPastDue:         daysPastDue,
+		DelinquencyBucket:   domain.DelinquencyBucketFor(daysPastDue),
+		RiskScore:           loanApp.RiskScore,
+		RiskTier:            domain.RiskTierFor(loanApp.RiskScore),
+	}, nil
+}
+
+// balanceAsOf returns the balance after the loan's last transaction before the as-of instant.
+// Loans without any recorded transactions report their stored outstanding balance.
+func balanceAsOf(stub shim.ChaincodeStubInterface, loanApp *domain.LoanApplication, asOf time.Time) (float64, error) {
+	latest, recorded, err := lastLoanTransactionBefore(stub, loanApp.LoanID, asOf)
+	if err != nil {
+		return 0, err
+	}
+
+	if !recorded {
+		return loanApp.OutstandingBalance.Float64(), nil
+	}
+	if latest == nil {
+		return 0, nil
+	}
+	return latest.BalanceAfter.Float64(), nil
+}
+
+// lastLoanTransactionBefore returns a loan's latest transaction recorded before asOf, and whether
+// the loan has any transactions recorded at all
+func lastLoanTransactionBefore(stub shim.ChaincodeStubInterface, loanID string, asOf time.Time) (*domain.LoanTransaction, bool, error) {
+	iterator, err := stub.GetStateByPartialCompositeKey("LOAN_TRANSACTION", []string{loanID})
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to get loan transactions: %w", err)
+	}
+	defer iterator.Close()
+
+	var latest *domain.LoanTransaction
+	recorded := false
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, false, fmt.Errorf("failed to iterate loan transactions: %w", err)
+		}
+
+		var txn domain.LoanTransaction
+		if err := json.Unmarshal(response.Value, &txn); err != nil {
+			return nil, false, fmt.Errorf("failed to unmarshal loan transaction: %w", err)
+		}
+		recorded = true
+
+		if !txn.CreatedDate.Before(asOf) {
+			continue
+		}
+		if latest == nil || txn.CreatedDate.After(latest.CreatedDate) {
+			latest = &txn
+		}
+	}
+
+	return latest, recorded, nil
+}
+
+// scheduleAsOf reads a loan's repayment schedule as at the as-of instant. It returns the days the
+// oldest installment unpaid at that instant was past due, the number of installments falling due
+// after it, and whether the loan has a schedule at all.
+func scheduleAsOf(stub shim.ChaincodeStubInterface, loanID string, asOf time.Time) (int, int, bool, error) {
+	iterator, err := stub.GetStateByPartialCompositeKey("REPAYMENT_INSTALLMENT", []string{loanID})
+	if err != nil {
+		return 0, 0, false, fmt.Errorf("failed to query installments: %w", err)
+	}
+	defer iterator.Close()
+
+	var oldestUnpaid *time.Time
+	remaining := 0
+	scheduled := false
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return 0, 0, false, fmt.Errorf("failed to iterate installments: %w", err)
+		}
+
+		var installment domain.Installment
+		if err := json.Unmarshal(response.Value, &installment); err != nil {
+			return 0, 0, false, fmt.Errorf("failed to unmarshal installment: %w", err)
+		}
+		scheduled = true
+
+		if !installment.DueDate.Before(asOf) {
+			remaining++
+			continue
+		}
+		paidByAsOf := installment.Status == domain.InstallmentStatusPaid && installment.PaidDate != nil && installment.PaidDate.Before(asOf)
+		if paidByAsOf {
+			continue
+		}
+		if oldestUnpaid == nil || installment.DueDate.Before(*oldestUnpaid) {
+			dueDate := installment.DueDate
+			oldestUnpaid = &dueDate
+		}
+	}
+
+	// Count calendar days, so an installment due on the as-of date itself is not yet past due
+	daysPastDue := 0
+	if oldestUnpaid != nil {
+		dueDay := time.Date(oldestUnpaid.Year(), oldestUnpaid.Month(), oldestUnpaid.Day(), 0, 0, 0, 0, time.UTC)
+		daysPastDue = int(asOf.AddDate(0, 0, -1).Sub(dueDay).Hours() / 24)
+	}
+	return daysPastDue, remaining, scheduled, nil
+}
+
+// monthsBetween returns the number of whole calendar months from start to end
+func monthsBetween(start, end time.Time) int {
+	months := (end.Year()-start.Year())*12 + int(end.Month()) - int(start.Month())
+	if end.Day() < start.Day() {
+		months--
+	}
+	return months
+}
@@ -0,0 +1,97 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+func TestExtractStressTestInputsReconstructsAsOfDate(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	seedServicedLoan(t, stub, "LOAN_ST1", "USD", 12000, 12)
+	postIncome(t, stub, "fee_march", "LOAN_ST1", time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC), domain.LoanTransactionFee, 50)
+	postIncome(t, stub, "fee_may", "LOAN_ST1", time.Date(2026, 5, 1, 9, 0, 0, 0, time.UTC), domain.LoanTransactionFee, 25)
+	if _, err := pledgeCollateral(t, stub, "pledge", "LOAN_ST1", 9000, appraisalTime); err != nil {
+		t.Fatalf("pledging collateral failed: %v", err)
+	}
+	disbursedOn := func(date time.Time) func(*domain.LoanApplication) {
+		return func(loanApp *domain.LoanApplication) {
+			loanApp.DisbursementDate = &date
+			loanApp.OutstandingBalance = utils.NewMoney(5000, "USD")
+		}
+	}
+	riskScore := 85.0
+	seedLoan(t, stub, "LOAN_ST2", validation.LoanStatusDelinquent, disbursedOn(time.Date(2025, 10, 20, 0, 0, 0, 0, time.UTC)), func(loanApp *domain.LoanApplication) {
+		loanApp.RiskScore = &riskScore
+	})
+	seedLoan(t, stub, "LOAN_ST3", validation.LoanStatusDisbursed, disbursedOn(time.Date(2026, 5, 1, 0, 0, 0, 0, time.UTC)))
+	seedLoan(t, stub, "LOAN_ST4", validation.LoanStatusSubmitted)
+	seedLoan(t, stub, "LOAN_UAT", validation.LoanStatusDisbursed, disbursedOn(scheduleStart), func(loanApp *domain.LoanApplication) { loanApp.Sandbox = true })
+	runAt := time.Date(2026, 6, 1, 9, 0, 0, 0, time.UTC)
+
+	// Page through the whole extract; pages may come back short where loans are skipped
+	extract := func(asOfDate string) (map[string]domain.StressTestInput, error) {
+		records := map[string]domain.StressTestInput{}
+		bookmark := ""
+		for page := 0; page == 0 || bookmark != ""; page++ {
+			payload, err := inTxAt(stub, "extract", runAt, func() ([]byte, error) {
+				return NewStressTestHandler().ExtractStressTestInputs(stub, []string{asOfDate, "2", bookmark})
+			})
+			if err != nil {
+				return nil, err
+			}
+			var result domain.StressTestExtract
+			if err := json.Unmarshal(payload, &result); err != nil {
+				t.Fatalf("failed to decode extract: %v", err)
+			}
+			if result.SchemaVersion != domain.StressTestSchemaVersion || result.AsOfDate != asOfDate || result.Count != len(result.Records) {
+				t.Fatalf("expected a versioned page as of %s, got %+v", asOfDate, result)
+			}
+			for _, record := range result.Records {
+				records[record.LoanID] = record
+			}
+			bookmark = result.Bookmark
+		}
+		return records, nil
+	}
+
+	// Only loans live and disbursed at the as-of date are extracted
+	april, err := extract("2026-04-20")
+	if err != nil {
+		t.Fatalf("ExtractStressTestInputs failed: %v", err)
+	}
+	if len(april) != 2 {
+		t.Fatalf("expected LOAN_ST1 and LOAN_ST2, got %+v", april)
+	}
+
+	// The May fee and stored balance are ignored; February's missed installment is 64 days past due
+	st1 := april["LOAN_ST1"]
+	if st1.Balance != 12050 || st1.CollateralValue != 9000 || st1.TermRemainingMonths != 9 {
+		t.Errorf("expected a 12050 balance, 9000 of collateral and 9 installments to come, got %+v", st1)
+	}
+	if st1.DaysPastDue != 64 || st1.DelinquencyBucket != domain.Delinquency60To89 || st1.RiskTier != domain.RiskTierUnscored || st1.InterestRate == nil || *st1.InterestRate != 12 {
+		t.Errorf("expected an unscored 12%% loan in the 60-89 day bucket, got %+v", st1)
+	}
+
+	// Without a schedule the contractual term is counted down from disbursement
+	st2 := april["LOAN_ST2"]
+	if st2.Balance != 5000 || st2.TermRemainingMonths != 6 || st2.DelinquencyBucket != domain.DelinquencyCurrent || st2.RiskTier != domain.RiskTierVeryHigh {
+		t.Errorf("expected a very high risk 5000 balance with 6 months to run, got %+v", st2)
+	}
+
+	// Collateral pledged after an earlier as-of date does not count towards it
+	march, err := extract("2026-03-31")
+	if err != nil {
+		t.Fatalf("ExtractStressTestInputs failed: %v", err)
+	}
+	if st1 := march["LOAN_ST1"]; st1.CollateralValue != 0 || st1.Balance != 12050 || st1.DaysPastDue != 44 || st1.DelinquencyBucket != domain.Delinquency30To59 {
+		t.Errorf("expected no collateral and 44 days past due at the end of March, got %+v", st1)
+	}
+	if _, err := extract("2026-06-02"); err == nil {
+		t.Errorf("expected an as-of date after today to be refused")
+	}
+}
@@ -0,0 +1,333 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/interfaces"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// applicationJourney is the happy-path sequence of milestones shown to borrowers
+var applicationJourney = []validation.LoanApplicationStatus{
+	validation.LoanStatusSubmitted,
+	validation.LoanStatusUnderwriting,
+	validation.LoanStatusCreditApproval,
+	validation.LoanStatusApproved,
+	validation.LoanStatusDisbursed,
+}
+
+// milestoneLabels maps internal statuses to borrower-facing wording
+var milestoneLabels = map[validation.LoanApplicationStatus]string{
+	validation.LoanStatusSubmitted:      "Application received",
+	validation.LoanStatusUnderwriting:   "Application under review",
+	validation.LoanStatusCreditApproval: "Credit decision in progress",
+	validation.LoanStatusApproved:       "Application approved",
+	validation.LoanStatusDisbursed:      "Funds released",
+	validation.LoanStatusRejected:       "Application declined",
+	validation.LoanStatusCancelled:      "Application cancelled",
+	validation.LoanStatusDelinquent:     "Payments overdue",
+	validation.LoanStatusDefaulted:      "Account in default",
+}
+
+// TimelineHandler serves the borrower-facing view of application progress
+type TimelineHandler struct {
+	persistenceService *services.PersistenceService
+}
+
+// NewTimelineHandler creates a new timeline handler
+func NewTimelineHandler() *TimelineHandler {
+	return &TimelineHandler{
+		persistenceService: services.NewPersistenceService(),
+	}
+}
+
+// GetApplicationTimeline returns the customer-safe progress view of a loan for one of its parties
+func (h *TimelineHandler) GetApplicationTimeline(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 2, got %d", len(args))
+	}
+
+	loanID, customerID := args[0], args[1]
+
+	// Loans the customer is not party to are reported as missing so their existence is not disclosed
+	var loanApp domain.LoanApplication
+	if err := h.persistenceService.Get(stub, fmt.Sprintf("LOAN_%s", loanID), &loanApp); err != nil || !isLoanParty(&loanApp, customerID) {
+		return nil, services.NewChaincodeError(services.ErrCodeNotFound, "", "loan application %s not found for customer %s", loanID, customerID)
+	}
+
+	reachedDates, err := h.getStatusDates(stub, &loanApp)
+	if err != nil {
+		return nil, err
+	}
+
+	timeline := &domain.ApplicationTimeline{
+		LoanID:               loanApp.LoanID,
+		LoanType:             loanApp.LoanType,
+		RequestedAmount:      loanApp.RequestedAmount.Float64(),
+		CurrentStatus:        loanApp.Status,
+		StatusLabel:          milestoneLabels[loanApp.Status],
+		Milestones:           buildMilestones(loanApp.Status, reachedDates),
+		OutstandingDocuments: []domain.DocumentRequirement{},
+		LastUpdated:          loanApp.LastUpdated,
+	}
+	if loanApp.ApprovedAmount != nil {
+		approvedAmount := loanApp.ApprovedAmount.Float64()
+		timeline.ApprovedAmount = &approvedAmount
+	}
+
+	// Document requirements only matter while the application awaits a decision
+	switch loanApp.Status {
+	case validation.LoanStatusSubmitted, validation.LoanStatusUnderwriting, validation.LoanStatusCreditApproval:
+		documents, err := getLoanDocuments(stub, h.persistenceService, loanApp.LoanID)
+		if err != nil {
+			return nil, err
+		}
+		timeline.OutstandingDocuments = outstandingDocuments(loanApp.LoanType, documents)
+	}
+
+	return json.Marshal(timeline)
+}
+
+// GetCustomerLoanMilestones lists every status reached by the loans a customer is party to, oldest
+// first, for the customer chaincode's event stream. Sandbox loans are excluded.
+func (h *TimelineHandler) GetCustomerLoanMilestones(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	customerID := args[0]
+
+	// Loans held as primary borrower and in any other party role
+	loanIDs := []string{}
+	seen := make(map[string]bool)
+	for _, indexName := range []string{"LOAN_BY_CUSTOMER", "CUSTOMER_LOAN_PARTY"} {
+		iterator, err := stub.GetStateByPartialCompositeKey(indexName, []string{customerID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get loans of customer: %w", err)
+		}
+		for iterator.HasNext() {
+			response, err := iterator.Next()
+			if err != nil {
+				iterator.Close()
+				return nil, fmt.Errorf("failed to iterate loans of customer: %w", err)
+			}
+			_, keyParts, err := stub.SplitCompositeKey(response.Key)
+			if err != nil || len(keyParts) < 2 {
+				iterator.Close()
+				return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid loan index key: %s", response.Key)
+			}
+			loanID := keyParts[len(keyParts)-1]
+			if !seen[loanID] {
+				seen[loanID] = true
+				loanIDs = append(loanIDs, loanID)
+			}
+		}
+		iterator.Close()
+	}
+
+	milestones := []interfaces.CustomerLoanMilestone{}
+	for _, loanID := range loanIDs {
+		var loanApp domain.LoanApplication
+		if err := h.persistenceService.Get(stub, fmt.Sprintf("LOAN_%s", loanID), &loanApp); err != nil {
+			continue // Skip if loan not found
+		}
+		if loanApp.Sandbox {
+			continue
+		}
+
+		milestones = append(milestones, interfaces.CustomerLoanMilestone{
+			LoanID:    loanApp.LoanID,
+			LoanType:  loanApp.LoanType,
+			Status:    string(validation.LoanStatusSubmitted),
+			Label:     milestoneLabels[validation.LoanStatusSubmitted],
+			Timestamp: loanApp.ApplicationDate,
+			Sequence:  "SUBMITTED",
+		})
+
+		iterator, err := stub.GetStateByPartialCompositeKey("HISTORY", []string{loanApp.LoanID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get history iterator: %w", err)
+		}
+		for iterator.HasNext() {
+			response, err := iterator.Next()
+			if err != nil {
+				iterator.Close()
+				return nil, fmt.Errorf("failed to iterate history: %w", err)
+			}
+
+			var entry map[string]interface{}
+			if err := json.Unmarshal(response.Value, &entry); err != nil {
+				iterator.Close()
+				return nil, fmt.Errorf("failed to unmarshal history entry: %w", err)
+			}
+			status, _ := entry["newValue"].(string)
+			if entry["fieldName"] != "status" || status == string(validation.LoanStatusSubmitted) {
+				continue
+			}
+			timestamp, _ := entry["timestamp"].(string)
+			reachedAt, err := utils.ParseTime(timestamp)
+			if err != nil {
+				continue
+			}
+			historyID, _ := entry["historyID"].(string)
+			label, ok := milestoneLabels[validation.LoanApplicationStatus(status)]
+			if !ok {
+				label = status
+			}
+			milestones = append(milestones, interfaces.CustomerLoanMilestone{
+				LoanID:    loanApp.LoanID,
+				LoanType:  loanApp.LoanType,
+				Status:    status,
+				Label:     label,
+				Timestamp: reachedAt,
+				Sequence:  historyID,
+			})
+		}
+		iterator.Close()
+	}
+
+	sort.SliceStable(milestones, func(i, j int) bool {
+		return milestones[i].Timestamp.Before(milestones[j].Timestamp)
+	})
+	return json.Marshal(milestones)
+}
+
+// Helper methods
+
+func isLoanParty(loanApp *domain.LoanApplication, customerID string) bool {
+	if loanApp.CustomerID == customerID {
+		return true
+	}
+	for _, party := range loanApp.Parties {
+		if party.CustomerID == customerID {
+			return true
+		}
+	}
+	return false
+}
+
+// getStatusDates finds when each status was first reached from the loan's history,
+// falling back to the decision and disbursement dates held on the loan
+func (h *TimelineHandler) getStatusDates(stub shim.ChaincodeStubInterface, loanApp *domain.LoanApplication) (map[validation.LoanApplicationStatus]time.Time, error) {
+	dates := map[validation.LoanApplicationStatus]time.Time{
+		validation.LoanStatusSubmitted: loanApp.ApplicationDate,
+	}
+
+	iterator, err := stub.GetStateByPartialCompositeKey("HISTORY", []string{loanApp.LoanID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get history iterator: %w", err)
+	}
+	defer iterator.Close()
+
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate history: %w", err)
+		}
+
+		var entry map[string]interface{}
+		if err := json.Unmarshal(response.Value, &entry); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal history entry: %w", err)
+		}
+		if entry["fieldName"] != "status" {
+			continue
+		}
+		status, _ := entry["newValue"].(string)
+		timestamp, _ := entry["timestamp"].(string)
+		reachedAt, err := utils.ParseTime(timestamp)
+		if err != nil {
+			continue
+		}
+		if existing, ok := dates[validation.LoanApplicationStatus(status)]; !ok || reachedAt.Before(existing) {
+			dates[validation.LoanApplicationStatus(status)] = reachedAt
+		}
+	}
+
+	if _, ok := dates[loanApp.Status]; !ok && loanApp.DecisionDate != nil {
+		if loanApp.Status == validation.LoanStatusApproved || loanApp.Status == validation.LoanStatusRejected || loanApp.Status == validation.LoanStatusCancelled {
+			dates[loanApp.Status] = *loanApp.DecisionDate
+		}
+	}
+	if _, ok := dates[validation.LoanStatusApproved]; !ok && loanApp.DecisionDate != nil && loanApp.DisbursementDate != nil {
+		dates[validation.LoanStatusApproved] = *loanApp.DecisionDate
+	}
+	if _, ok := dates[validation.LoanStatusDisbursed]; !ok && loanApp.DisbursementDate != nil {
+		dates[validation.LoanStatusDisbursed] = *loanApp.DisbursementDate
+	}
+
+	return dates, nil
+}
+
+// buildMilestones lays out the journey for the current status. Declined and cancelled applications
+// show the stages they actually passed through followed by the outcome; all others show the full journey.
+func buildMilestones(current validation.LoanApplicationStatus, dates map[validation.LoanApplicationStatus]time.Time) []domain.TimelineMilestone {
+	currentIndex := len(applicationJourney) // Delinquent and defaulted loans have completed the journey
+	for i, status := range applicationJourney {
+		if status == current {
+			currentIndex = i
+		}
+	}
+
+	milestones := []domain.TimelineMilestone{}
+	for i, status := range applicationJourney {
+		reached := i <= currentIndex
+		if current == validation.LoanStatusRejected || current == validation.LoanStatusCancelled {
+			if _, ok := dates[status]; !ok {
+				continue
+			}
+			reached = true
+		}
+		milestones = append(milestones, newMilestone(status, reached, dates))
+	}
+
+	if current == validation.LoanStatusRejected || current == validation.LoanStatusCancelled || current == validation.LoanStatusDelinquent || current == validation.LoanStatusDefaulted {
+		milestones = append(milestones, newMilestone(current, true, dates))
+	}
+
+	return milestones
+}
+
+func newMilestone(status validation.LoanApplicationStatus, reached bool, dates map[validation.LoanApplicationStatus]time.Time) domain.TimelineMilestone {
+	milestone := domain.TimelineMilestone{
+		Status:  status,
+		Label:   milestoneLabels[status],
+		Reached: reached,
+	}
+	if date, ok := dates[status]; ok && reached {
+		milestone.ReachedDate = &date
+	}
+	return milestone
+}
+
+// outstandingDocuments reports each required document type whose latest upload is not verified
+func outstandingDocuments(loanType string, documents []domain.LoanDocument) []domain.DocumentRequirement {
+	latest := make(map[string]domain.LoanDocument)
+	for _, document := range documents {
+		if existing, ok := latest[document.DocumentType]; !ok || !document.CreatedDate.Before(existing.CreatedDate) {
+			latest[document.DocumentType] = document
+		}
+	}
+
+	requirements := []domain.DocumentRequirement{}
+	for _, documentType := range config.RequiredLoanDocuments[loanType] {
+		document, ok := latest[documentType]
+		switch {
+		case !ok:
+			requirements = append(requirements, domain.DocumentRequirement{DocumentType: documentType, State: domain.DocumentRequirementMissing})
+		case document.Status == domain.DocumentStatusPending:
+			requirements = append(requirements, domain.DocumentRequirement{DocumentType: documentType, State: domain.DocumentRequirementUnderReview})
+		case document.Status == domain.DocumentStatusRejected:
+			requirements = append(requirements, domain.DocumentRequirement{DocumentType: documentType, State: domain.DocumentRequirementResubmission, Reason: document.RejectionReason})
+		}
+	}
+
+	return requirements
+}
@@ -0,0 +1,389 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	loanServices "github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// UnderwritingChecklistHandler handles the requirement checklists set per loan type and amount
+// band, and the completion of their items on loan applications
+type UnderwritingChecklistHandler struct {
+	persistenceService *services.PersistenceService
+	eventService       *loanServices.EventService
+}
+
+// NewUnderwritingChecklistHandler creates a new underwriting checklist handler
+func NewUnderwritingChecklistHandler() *UnderwritingChecklistHandler {
+	return &UnderwritingChecklistHandler{
+		persistenceService: services.NewPersistenceService(),
+		eventService:       loanServices.NewEventService(),
+	}
+}
+
+// SetUnderwritingChecklist creates or replaces the checklist of a loan type's amount band.
+// Completions already recorded against items kept in the checklist still count.
+func (h *UnderwritingChecklistHandler) SetUnderwritingChecklist(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.UnderwritingChecklistRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse underwriting checklist request: %v", err)
+	}
+
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return nil, err
+	}
+	if role != string(validation.ActorRoleCreditOfficer) {
+		return nil, services.NewChaincodeError(services.ErrCodeAccessDenied, "", "underwriting checklists may only be set by a %s", validation.ActorRoleCreditOfficer)
+	}
+
+	loanType := strings.TrimSpace(req.LoanType)
+	if err := validation.ValidateLoanType(loanType); err != nil {
+		return nil, fmt.Errorf("invalid loan type: %w", err)
+	}
+	bandID := strings.ToUpper(strings.TrimSpace(req.BandID))
+	if bandID == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "bandID", "bandID is required")
+	}
+	if err := validateAmountRange(req.MinAmount, req.MaxAmount); err != nil {
+		return nil, err
+	}
+	items, err := validateChecklistItems(req.Items)
+	if err != nil {
+		return nil, err
+	}
+
+	// Bands of a loan type may not overlap, so exactly one checklist applies to an amount
+	existing, err := getUnderwritingChecklists(stub, loanType)
+	if err != nil {
+		return nil, err
+	}
+	previousJSON := ""
+	for _, checklist := range existing {
+		if checklist.BandID == bandID {
+			previousJSON, _ = utils.MarshalJSONString(checklist)
+			continue
+		}
+		if req.MinAmount <= checklist.MaxAmount && checklist.MinAmount <= req.MaxAmount {
+			return nil, fmt.Errorf("amount band %.2f to %.2f overlaps band %s (%.2f to %.2f) of %s loans", req.MinAmount, req.MaxAmount, checklist.BandID, checklist.MinAmount, checklist.MaxAmount, loanType)
+		}
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	checklist := &domain.UnderwritingChecklist{
+		LoanType:      loanType,
+		BandID:        bandID,
+		MinAmount:     req.MinAmount,
+		MaxAmount:     req.MaxAmount,
+		Items:         items,
+		LastUpdated:   now,
+		LastUpdatedBy: req.ActorID,
+	}
+
+	checklistKey, err := stub.CreateCompositeKey("UNDERWRITING_CHECKLIST", []string{loanType, bandID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := h.persistenceService.Put(stub, checklistKey, checklist); err != nil {
+		return nil, fmt.Errorf("failed to store underwriting checklist: %w", err)
+	}
+
+	// Record history
+	checklistJSON, _ := utils.MarshalJSONString(checklist)
+	if err := h.recordEntityHistory(stub, fmt.Sprintf("%s_%s", loanType, bandID), "UnderwritingChecklist", "UPDATE", "underwritingChecklist", previousJSON, checklistJSON, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to record history: %w", err)
+	}
+
+	// Emit event
+	if err := h.eventService.EmitUnderwritingChecklistUpdated(stub, checklist, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(checklist)
+}
+
+// GetUnderwritingChecklists lists the checklists of a loan type in band ID order.
+// Args: loanType
+func (h *UnderwritingChecklistHandler) GetUnderwritingChecklists(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	loanType := strings.TrimSpace(args[0])
+	if err := validation.ValidateLoanType(loanType); err != nil {
+		return nil, fmt.Errorf("invalid loan type: %w", err)
+	}
+
+	checklists, err := getUnderwritingChecklists(stub, loanType)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(checklists)
+}
+
+// CompleteChecklistItem marks an item of the checklist applying to a loan complete, with the
+// evidence relied on. A completed item cannot be completed again.
+func (h *UnderwritingChecklistHandler) CompleteChecklistItem(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req domain.ChecklistItemCompletionRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse checklist item completion request: %v", err)
+	}
+
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+	if strings.TrimSpace(req.EvidenceReference) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "evidenceReference", "evidenceReference is required")
+	}
+	req.ItemID = strings.ToUpper(strings.TrimSpace(req.ItemID))
+
+	var loanApp domain.LoanApplication
+	if err := h.persistenceService.Get(stub, fmt.Sprintf("LOAN_%s", req.LoanID), &loanApp); err != nil {
+		return nil, fmt.Errorf("loan application not found: %w", err)
+	}
+	switch loanApp.Status {
+	case validation.LoanStatusSubmitted, validation.LoanStatusUnderwriting, validation.LoanStatusCreditApproval:
+	default:
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "checklist items cannot be completed for loan in status: %s", loanApp.Status)
+	}
+
+	status, err := getLoanChecklistStatus(stub, h.persistenceService, &loanApp)
+	if err != nil {
+		return nil, err
+	}
+	var item *domain.LoanChecklistItem
+	for i := range status.Items {
+		if status.Items[i].ItemID == req.ItemID {
+			item = &status.Items[i]
+			break
+		}
+	}
+	if item == nil {
+		return nil, fmt.Errorf("item %s is not on the underwriting checklist of loan %s", req.ItemID, req.LoanID)
+	}
+	if item.Completion != nil {
+		return nil, fmt.Errorf("item %s of loan %s was completed by %s", req.ItemID, req.LoanID, item.Completion.CompletedBy)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	completion := &domain.ChecklistItemCompletion{
+		LoanID:            req.LoanID,
+		ItemID:            req.ItemID,
+		EvidenceReference: strings.TrimSpace(req.EvidenceReference),
+		CompletedBy:       req.ActorID,
+		CompletedDate:     now,
+		TransactionID:     stub.GetTxID(),
+	}
+
+	completionKey, err := stub.CreateCompositeKey("LOAN_CHECKLIST_ITEM", []string{req.LoanID, req.ItemID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := h.persistenceService.Put(stub, completionKey, completion); err != nil {
+		return nil, fmt.Errorf("failed to store checklist item completion: %w", err)
+	}
+
+	// Record history
+	if err := h.recordEntityHistory(stub, req.LoanID, "LoanApplication", "CHECKLIST_ITEM_COMPLETED", req.ItemID, "", completion.EvidenceReference, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to record history: %w", err)
+	}
+
+	// Emit event
+	if err := h.eventService.EmitChecklistItemCompleted(stub, completion, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(completion)
+}
+
+// GetLoanChecklist shows a loan's progress through the checklist of its loan type and amount band
+func (h *UnderwritingChecklistHandler) GetLoanChecklist(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var loanApp domain.LoanApplication
+	if err := h.persistenceService.Get(stub, fmt.Sprintf("LOAN_%s", args[0]), &loanApp); err != nil {
+		return nil, fmt.Errorf("loan application not found: %w", err)
+	}
+
+	status, err := getLoanChecklistStatus(stub, h.persistenceService, &loanApp)
+	if err != nil {
+		return nil, err
+	}
+	return json.Marshal(status)
+}
+
+// Helper methods
+
+func (h *UnderwritingChecklistHandler) recordEntityHistory(stub shim.ChaincodeStubInterface, entityID, entityType, changeType, fieldName, previousValue, newValue, actorID string) error {
+	historyID, compositeKey, err := services.NewHistoryKey(stub, entityID)
+	if err != nil {
+		return err
+	}
+	txID := stub.GetTxID()
+
+	timestamp, err := services.TxTimeString(stub)
+	if err != nil {
+		return err
+	}
+
+	historyEntry := map[string]interface{}{
+		"historyID":     historyID,
+		"entityID":      entityID,
+		"entityType":    entityType,
+		"timestamp":     timestamp,
+		"changeType":    changeType,
+		"fieldName":     fieldName,
+		"previousValue": previousValue,
+		"newValue":      newValue,
+		"actorID":       actorID,
+		"transactionID": txID,
+	}
+
+	return h.persistenceService.Put(stub, compositeKey, historyEntry)
+}
+
+// checkUnderwritingChecklist rejects moving a loan to credit review or approval while items of
+// its checklist are outstanding
+func checkUnderwritingChecklist(stub shim.ChaincodeStubInterface, ps *services.PersistenceService, loanApp *domain.LoanApplication) error {
+	status, err := getLoanChecklistStatus(stub, ps, loanApp)
+	if err != nil {
+		return err
+	}
+	if !status.Complete {
+		return fmt.Errorf("loan %s has outstanding %s checklist items: %s", loanApp.LoanID, status.BandID, strings.Join(status.Outstanding, ", "))
+	}
+	return nil
+}
+
+// getLoanChecklistStatus matches a loan to the checklist of its loan type whose band holds the
+// requested amount and reads the completion of each item
+func getLoanChecklistStatus(stub shim.ChaincodeStubInterface, ps *services.PersistenceService, loanApp *domain.LoanApplication) (*domain.LoanChecklistStatus, error) {
+	status := &domain.LoanChecklistStatus{
+		LoanID:      loanApp.LoanID,
+		LoanType:    loanApp.LoanType,
+		Items:       []domain.LoanChecklistItem{},
+		Outstanding: []string{},
+		Complete:    true,
+	}
+
+	checklists, err := getUnderwritingChecklists(stub, loanApp.LoanType)
+	if err != nil {
+		return nil, err
+	}
+	var checklist *domain.UnderwritingChecklist
+	for i := range checklists {
+		if requested := loanApp.RequestedAmount.Float64(); requested >= checklists[i].MinAmount && requested <= checklists[i].MaxAmount {
+			checklist = &checklists[i]
+			break
+		}
+	}
+	if checklist == nil {
+		return status, nil
+	}
+	status.BandID = checklist.BandID
+
+	for _, definition := range checklist.Items {
+		item := domain.LoanChecklistItem{ChecklistItemDefinition: definition}
+		completionKey, err := stub.CreateCompositeKey("LOAN_CHECKLIST_ITEM", []string{loanApp.LoanID, definition.ItemID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create composite key: %w", err)
+		}
+		exists, err := ps.Exists(stub, completionKey)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check checklist item completion: %w", err)
+		}
+		if exists {
+			var completion domain.ChecklistItemCompletion
+			if err := ps.Get(stub, completionKey, &completion); err != nil {
+				return nil, fmt.Errorf("failed to get checklist item completion: %w", err)
+			}
+			item.Completion = &completion
+		} else {
+			status.Outstanding = append(status.Outstanding, definition.ItemID)
+		}
+		status.Items = append(status.Items, item)
+	}
+	status.Complete = len(status.Outstanding) == 0
+	return status, nil
+}
+
+// getUnderwritingChecklists reads the checklists of a loan type in band ID order
+func getUnderwritingChecklists(stub shim.ChaincodeStubInterface, loanType string) ([]domain.UnderwritingChecklist, error) {
+	iterator, err := stub.GetStateByPartialCompositeKey("UNDERWRITING_CHECKLIST", []string{loanType})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query underwriting checklists: %w", err)
+	}
+	defer iterator.Close()
+
+	checklists := []domain.UnderwritingChecklist{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate underwriting checklists: %w", err)
+		}
+		var checklist domain.UnderwritingChecklist
+		if err := json.Unmarshal(response.Value, &checklist); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal underwriting checklist: %w", err)
+		}
+		checklists = append(checklists, checklist)
+	}
+	return checklists, nil
+}
+
+// validateChecklistItems checks each item has a unique ID, a known type and a description
+func validateChecklistItems(items []domain.ChecklistItemDefinition) ([]domain.ChecklistItemDefinition, error) {
+	if len(items) == 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "at least one checklist item is required")
+	}
+
+	validated := make([]domain.ChecklistItemDefinition, 0, len(items))
+	seen := make(map[string]bool)
+	for _, item := range items {
+		item.ItemID = strings.ToUpper(strings.TrimSpace(item.ItemID))
+		item.Description = strings.TrimSpace(item.Description)
+		if item.ItemID == "" {
+			return nil, fmt.Errorf("checklist item ID is required")
+		}
+		if seen[item.ItemID] {
+			return nil, fmt.Errorf("duplicate checklist item %s", item.ItemID)
+		}
+		seen[item.ItemID] = true
+		switch item.Type {
+		case domain.ChecklistItemDocument, domain.ChecklistItemVerification, domain.ChecklistItemRule:
+		default:
+			return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid type %s for checklist item %s", item.Type, item.ItemID)
+		}
+		if item.Description == "" {
+			return nil, fmt.Errorf("checklist item %s needs a description", item.ItemID)
+		}
+		validated = append(validated, item)
+	}
+	return validated, nil
+}
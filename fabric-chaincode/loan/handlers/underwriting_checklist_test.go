@@ -0,0 +1,134 @@
+package handlers
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// setChecklist stores the checklist of a personal loan amount band as a credit officer
+func setChecklist(t *testing.T, stub *shimtest.MockStub, txID, bandID string, minAmount, maxAmount float64, itemIDs ...string) {
+	t.Helper()
+	creator := stub.Creator
+	defer func() { stub.Creator = creator }()
+	stub.Creator = newTestIdentity(t, string(validation.ActorRoleCreditOfficer))
+	items := []domain.ChecklistItemDefinition{}
+	for _, itemID := range itemIDs {
+		items = append(items, domain.ChecklistItemDefinition{ItemID: itemID, Type: domain.ChecklistItemVerification, Description: itemID + " confirmed"})
+	}
+	if _, err := inTx(stub, txID, func() ([]byte, error) {
+		return NewUnderwritingChecklistHandler().SetUnderwritingChecklist(stub, []string{mustJSON(t, domain.UnderwritingChecklistRequest{
+			LoanType: "PERSONAL", BandID: bandID, MinAmount: minAmount, MaxAmount: maxAmount, Items: items, ActorID: "ACTOR_004",
+		})})
+	}); err != nil {
+		t.Fatalf("failed to set checklist: %v", err)
+	}
+}
+
+func completeItem(t *testing.T, stub *shimtest.MockStub, loanID, itemID string) {
+	t.Helper()
+	if _, err := inTx(stub, "complete_"+loanID+"_"+itemID, func() ([]byte, error) {
+		return NewUnderwritingChecklistHandler().CompleteChecklistItem(stub, []string{mustJSON(t, domain.ChecklistItemCompletionRequest{
+			LoanID: loanID, ItemID: itemID, EvidenceReference: "REF_" + itemID, ActorID: "ACTOR_002",
+		})})
+	}); err != nil {
+		t.Fatalf("failed to complete %s on %s: %v", itemID, loanID, err)
+	}
+}
+
+func expectOutstandingChecklist(t *testing.T, err error, outstanding string) {
+	t.Helper()
+	if err == nil || !strings.Contains(err.Error(), "outstanding STANDARD checklist items: "+outstanding) {
+		t.Fatalf("expected the transition blocked on %s, got %v", outstanding, err)
+	}
+}
+
+func TestUpdateLoanStatusWaitsOnUnderwritingChecklist(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleUnderwriter))
+	setChecklist(t, stub, "checklist", "STANDARD", 0, 50000, "EMPLOYER_CALL", "BUREAU_CHECK")
+	seedLoan(t, stub, "LOAN_CL1", validation.LoanStatusUnderwriting, approvedTerms(10000, 7))
+
+	moveTo := func(txID string, status validation.LoanApplicationStatus) error {
+		_, err := inTx(stub, txID, func() ([]byte, error) {
+			return NewLoanApplicationHandler().UpdateLoanStatus(stub, []string{mustJSON(t, domain.LoanStatusUpdateRequest{
+				LoanID: "LOAN_CL1", NewStatus: status, ActorID: "ACTOR_002",
+			})})
+		})
+		return err
+	}
+
+	// Credit review waits on every item of the band, not just the first
+	expectOutstandingChecklist(t, moveTo("review_none", validation.LoanStatusCreditApproval), "EMPLOYER_CALL, BUREAU_CHECK")
+	completeItem(t, stub, "LOAN_CL1", "EMPLOYER_CALL")
+	expectOutstandingChecklist(t, moveTo("review_part", validation.LoanStatusCreditApproval), "BUREAU_CHECK")
+	if loanApp := getLoan(t, stub, "LOAN_CL1"); loanApp.Status != validation.LoanStatusUnderwriting {
+		t.Fatalf("blocked transitions must leave the loan in UNDERWRITING, got %s", loanApp.Status)
+	}
+
+	completeItem(t, stub, "LOAN_CL1", "BUREAU_CHECK")
+	if err := moveTo("review_complete", validation.LoanStatusCreditApproval); err != nil {
+		t.Fatalf("transition with a complete checklist failed: %v", err)
+	}
+
+	// Items added to the band while the loan sits in credit review hold up its approval
+	setChecklist(t, stub, "checklist_v2", "STANDARD", 0, 50000, "EMPLOYER_CALL", "BUREAU_CHECK", "SITE_VISIT")
+	expectOutstandingChecklist(t, moveTo("approve_part", validation.LoanStatusApproved), "SITE_VISIT")
+	completeItem(t, stub, "LOAN_CL1", "SITE_VISIT")
+	if err := moveTo("approve_complete", validation.LoanStatusApproved); err != nil {
+		t.Fatalf("approval with a complete checklist failed: %v", err)
+	}
+	if loanApp := getLoan(t, stub, "LOAN_CL1"); loanApp.Status != validation.LoanStatusApproved {
+		t.Errorf("expected APPROVED, got %s", loanApp.Status)
+	}
+
+	// A loan whose terms were never decided is approved through ApproveLoan, checklist or not
+	seedLoan(t, stub, "LOAN_CL4", validation.LoanStatusCreditApproval, func(loanApp *domain.LoanApplication) {
+		loanApp.RequestedAmount = utils.NewMoney(60000, "USD")
+	})
+	_, err := inTx(stub, "approve_unpriced", func() ([]byte, error) {
+		return NewLoanApplicationHandler().UpdateLoanStatus(stub, []string{mustJSON(t, domain.LoanStatusUpdateRequest{
+			LoanID: "LOAN_CL4", NewStatus: validation.LoanStatusApproved, ActorID: "ACTOR_002",
+		})})
+	})
+	if err == nil || !strings.Contains(err.Error(), "approved via ApproveLoan") {
+		t.Errorf("expected approval without terms to be sent to ApproveLoan, got %v", err)
+	}
+}
+
+func TestApproveLoanWaitsOnUnderwritingChecklist(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleUnderwriter))
+	withCustomers(stub, eligibleCustomer("CUST_001", 10))
+	setChecklist(t, stub, "checklist", "STANDARD", 0, 50000, "EMPLOYER_CALL")
+	seedLoan(t, stub, "LOAN_CL2", validation.LoanStatusCreditApproval)
+	// Amounts outside every band have no checklist to wait on
+	seedLoan(t, stub, "LOAN_CL3", validation.LoanStatusCreditApproval, func(loanApp *domain.LoanApplication) {
+		loanApp.RequestedAmount = utils.NewMoney(60000, "USD")
+	})
+
+	approve := func(txID, loanID string) error {
+		_, err := inTx(stub, txID, func() ([]byte, error) {
+			return NewLoanApplicationHandler().ApproveLoan(stub, []string{mustJSON(t, domain.LoanApprovalRequest{
+				LoanID: loanID, ApprovedAmount: 10000, InterestRate: 7, ActorID: "ACTOR_002",
+			})})
+		})
+		return err
+	}
+
+	expectOutstandingChecklist(t, approve("approve_incomplete", "LOAN_CL2"), "EMPLOYER_CALL")
+	completeItem(t, stub, "LOAN_CL2", "EMPLOYER_CALL")
+	if err := approve("approve_complete", "LOAN_CL2"); err != nil {
+		t.Fatalf("approval with a complete checklist failed: %v", err)
+	}
+	if err := approve("approve_unbanded", "LOAN_CL3"); err != nil {
+		t.Fatalf("approval outside every checklist band failed: %v", err)
+	}
+	for _, loanID := range []string{"LOAN_CL2", "LOAN_CL3"} {
+		if loanApp := getLoan(t, stub, loanID); loanApp.Status != validation.LoanStatusApproved {
+			t.Errorf("expected %s APPROVED, got %s", loanID, loanApp.Status)
+		}
+	}
+}
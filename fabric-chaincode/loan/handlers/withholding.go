@@ -0,0 +1,217 @@
+package handlers
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// withholdingPeriodFormat buckets withholding records into monthly remittance periods
+const withholdingPeriodFormat = "2006-01"
+
+// WithholdingHandler handles tax withholding reporting on loan interest and fees
+type WithholdingHandler struct {
+	persistenceService *services.PersistenceService
+}
+
+// NewWithholdingHandler creates a new withholding handler
+func NewWithholdingHandler() *WithholdingHandler {
+	return &WithholdingHandler{
+		persistenceService: services.NewPersistenceService(),
+	}
+}
+
+// GetLoanWithholding retrieves every withholding record of a loan with its running total
+func (h *WithholdingHandler) GetLoanWithholding(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	loanKey := fmt.Sprintf("LOAN_%s", args[0])
+	var loanApp domain.LoanApplication
+	if err := h.persistenceService.Get(stub, loanKey, &loanApp); err != nil {
+		return nil, fmt.Errorf("loan application not found: %w", err)
+	}
+
+	iterator, err := stub.GetStateByPartialCompositeKey("LOAN_WITHHOLDING", []string{args[0]})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query withholding records: %w", err)
+	}
+	defer iterator.Close()
+
+	result := &domain.LoanWithholding{
+		LoanID:        loanApp.LoanID,
+		Jurisdiction:  loanApp.Jurisdiction,
+		TotalWithheld: loanApp.TaxWithheld.Float64(),
+		Records:       []domain.WithholdingRecord{},
+	}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate withholding records: %w", err)
+		}
+
+		var record domain.WithholdingRecord
+		if err := json.Unmarshal(response.Value, &record); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal withholding record: %w", err)
+		}
+		result.Records = append(result.Records, record)
+	}
+
+	return json.Marshal(result)
+}
+
+// GetWithholdingRemittanceReport totals the tax withheld in a jurisdiction for one month, per loan.
+// Sandbox loans are excluded. Args: jurisdiction, period (YYYY-MM)
+func (h *WithholdingHandler) GetWithholdingRemittanceReport(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 2, got %d", len(args))
+	}
+
+	jurisdiction := args[0]
+	if _, ok := config.WithholdingTaxRates[jurisdiction]; !ok {
+		return nil, fmt.Errorf("no withholding rates configured for jurisdiction %s", jurisdiction)
+	}
+	if _, err := time.Parse(withholdingPeriodFormat, args[1]); err != nil {
+		return nil, fmt.Errorf("invalid period %s, expected YYYY-MM: %w", args[1], err)
+	}
+
+	iterator, err := stub.GetStateByPartialCompositeKey("WITHHOLDING_BY_PERIOD", []string{jurisdiction, args[1]})
+	if err != nil {
+		return nil, fmt.Errorf("failed to query withholding records: %w", err)
+	}
+	defer iterator.Close()
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &domain.WithholdingRemittanceReport{
+		Jurisdiction:  jurisdiction,
+		Period:        args[1],
+		Lines:         []domain.WithholdingRemittanceLine{},
+		GeneratedDate: now,
+	}
+
+	// Index entries are ordered by loan, so each loan's records arrive together
+	var line *domain.WithholdingRemittanceLine
+	sandboxLoans := map[string]bool{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate withholding records: %w", err)
+		}
+
+		_, attributes, err := stub.SplitCompositeKey(response.Key)
+		if err != nil {
+			return nil, fmt.Errorf("failed to split composite key: %w", err)
+		}
+		loanID, transactionID := attributes[2], attributes[3]
+		if sandboxLoans[loanID] {
+			continue
+		}
+
+		if line == nil || line.LoanID != loanID {
+			var loanApp domain.LoanApplication
+			if err := h.persistenceService.Get(stub, fmt.Sprintf("LOAN_%s", loanID), &loanApp); err != nil {
+				return nil, fmt.Errorf("loan application not found: %w", err)
+			}
+			if loanApp.Sandbox {
+				sandboxLoans[loanID] = true
+				continue
+			}
+			report.Lines = append(report.Lines, domain.WithholdingRemittanceLine{LoanID: loanID, CustomerID: loanApp.CustomerID})
+			line = &report.Lines[len(report.Lines)-1]
+		}
+
+		recordKey, err := stub.CreateCompositeKey("LOAN_WITHHOLDING", []string{loanID, transactionID})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create composite key: %w", err)
+		}
+		var record domain.WithholdingRecord
+		if err := h.persistenceService.Get(stub, recordKey, &record); err != nil {
+			return nil, fmt.Errorf("withholding record not found: %w", err)
+		}
+
+		switch record.IncomeType {
+		case domain.LoanTransactionInterest:
+			line.InterestGross = roundToCents(line.InterestGross + record.GrossAmount)
+			line.InterestWithheld = roundToCents(line.InterestWithheld + record.WithheldAmount)
+			report.TotalInterestWithheld = roundToCents(report.TotalInterestWithheld + record.WithheldAmount)
+		case domain.LoanTransactionFee:
+			line.FeeGross = roundToCents(line.FeeGross + record.GrossAmount)
+			line.FeeWithheld = roundToCents(line.FeeWithheld + record.WithheldAmount)
+			report.TotalFeeWithheld = roundToCents(report.TotalFeeWithheld + record.WithheldAmount)
+		}
+		line.TotalWithheld = roundToCents(line.TotalWithheld + record.WithheldAmount)
+		report.TotalWithheld = roundToCents(report.TotalWithheld + record.WithheldAmount)
+		report.RecordCount++
+	}
+
+	return json.Marshal(report)
+}
+
+// applyWithholding withholds tax on an interest or fee transaction at the loan jurisdiction's
+// configured rate and adds it to the loan's running total; the caller stores the transaction and
+// persists the loan
+func applyWithholding(stub shim.ChaincodeStubInterface, persistenceService *services.PersistenceService, loanApp *domain.LoanApplication, txn *domain.LoanTransaction) error {
+	rates, ok := config.WithholdingTaxRates[loanApp.Jurisdiction]
+	if !ok {
+		return nil
+	}
+
+	var rate float64
+	switch txn.TransactionType {
+	case domain.LoanTransactionInterest:
+		rate = rates.Interest
+	case domain.LoanTransactionFee:
+		rate = rates.Fee
+	}
+	withheld := txn.Amount.Mul(rate)
+	if !withheld.IsPositive() {
+		return nil
+	}
+
+	record := &domain.WithholdingRecord{
+		LoanID:         loanApp.LoanID,
+		TransactionID:  txn.TransactionID,
+		Jurisdiction:   loanApp.Jurisdiction,
+		IncomeType:     txn.TransactionType,
+		GrossAmount:    txn.Amount.Float64(),
+		Rate:           rate,
+		WithheldAmount: withheld.Float64(),
+		Period:         txn.CreatedDate.UTC().Format(withholdingPeriodFormat),
+		CreatedDate:    txn.CreatedDate,
+		CreatedBy:      txn.CreatedBy,
+	}
+
+	recordKey, err := stub.CreateCompositeKey("LOAN_WITHHOLDING", []string{loanApp.LoanID, txn.TransactionID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := persistenceService.Put(stub, recordKey, record); err != nil {
+		return fmt.Errorf("failed to store withholding record: %w", err)
+	}
+
+	periodKey, err := stub.CreateCompositeKey("WITHHOLDING_BY_PERIOD", []string{record.Jurisdiction, record.Period, loanApp.LoanID, txn.TransactionID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := stub.PutState(periodKey, []byte(loanApp.LoanID)); err != nil {
+		return fmt.Errorf("failed to create withholding period index: %w", err)
+	}
+
+	txn.TaxWithheld = withheld
+	total, err := loanApp.TaxWithheld.Add(withheld)
+	if err != nil {
+		return fmt.Errorf("failed to add withholding on loan %s: %w", loanApp.LoanID, err)
+	}
+	loanApp.TaxWithheld = total
+	return nil
+}
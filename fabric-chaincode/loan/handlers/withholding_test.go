@@ -0,0 +1,167 @@
+package handlers
+
+import (
+	"encoding/json"
+	"testing"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shimtest"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// postIncome posts an interest or fee transaction at the given time
+func postIncome(t *testing.T, stub *shimtest.MockStub, txID, loanID string, at time.Time, txnType domain.LoanTransactionType, amount float64) {
+	t.Helper()
+	if _, err := inTxAt(stub, txID, at, func() ([]byte, error) {
+		return NewReconciliationHandler().RecordLoanTransaction(stub, []string{mustJSON(t, domain.LoanTransactionRequest{
+			LoanID: loanID, TransactionType: txnType, Amount: amount, Reference: "INC-" + txID, ActorID: "ACTOR_005",
+		})})
+	}); err != nil {
+		t.Fatalf("posting %s failed: %v", txnType, err)
+	}
+}
+
+func getWithholding(t *testing.T, stub *shimtest.MockStub, loanID string) *domain.LoanWithholding {
+	t.Helper()
+	payload, err := inTx(stub, "withholding_"+loanID, func() ([]byte, error) {
+		return NewWithholdingHandler().GetLoanWithholding(stub, []string{loanID})
+	})
+	if err != nil {
+		t.Fatalf("GetLoanWithholding failed: %v", err)
+	}
+	var withholding domain.LoanWithholding
+	if err := json.Unmarshal(payload, &withholding); err != nil {
+		t.Fatalf("failed to decode withholding: %v", err)
+	}
+	return &withholding
+}
+
+func TestLoanIncomeWithheldAtJurisdictionRates(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	inJurisdiction := func(jurisdiction string) func(*domain.LoanApplication) {
+		return func(loanApp *domain.LoanApplication) {
+			loanApp.Jurisdiction = jurisdiction
+			loanApp.DisbursedAmount = utils.NewMoney(12000, "USD")
+			loanApp.OutstandingBalance = utils.NewMoney(12000, "USD")
+		}
+	}
+	seedLoan(t, stub, "LOAN_WH_KE", validation.LoanStatusDisbursed, approvedTerms(12000, 12), inJurisdiction("KE"))
+	seedLoan(t, stub, "LOAN_WH_PH", validation.LoanStatusDisbursed, approvedTerms(12000, 12), inJurisdiction("PH"))
+	seedLoan(t, stub, "LOAN_WH_US", validation.LoanStatusDisbursed, approvedTerms(12000, 12), inJurisdiction(""))
+	march := time.Date(2026, 3, 31, 23, 0, 0, 0, time.UTC)
+
+	// Kenya withholds 15% of interest and 5% of fees, on every posting
+	postIncome(t, stub, "ke_interest", "LOAN_WH_KE", march, domain.LoanTransactionInterest, 200)
+	postIncome(t, stub, "ke_fee", "LOAN_WH_KE", march, domain.LoanTransactionFee, 35.5)
+	ke := getWithholding(t, stub, "LOAN_WH_KE")
+	if len(ke.Records) != 2 || ke.TotalWithheld != 31.78 || ke.Jurisdiction != "KE" {
+		t.Fatalf("expected 30.00 + 1.78 withheld over 2 records, got %+v", ke)
+	}
+	for _, record := range ke.Records {
+		if record.Period != "2026-03" {
+			t.Errorf("expected the March remittance period, got %s", record.Period)
+		}
+	}
+	if loanApp := getLoan(t, stub, "LOAN_WH_KE"); loanApp.TaxWithheld.Float64() != 31.78 {
+		t.Errorf("expected the loan's running total at 31.78, got %s", loanApp.TaxWithheld)
+	}
+
+	// The Philippines withholds on interest only; loans without a jurisdiction have nothing withheld
+	postIncome(t, stub, "ph_fee", "LOAN_WH_PH", march, domain.LoanTransactionFee, 50)
+	postIncome(t, stub, "us_interest", "LOAN_WH_US", march, domain.LoanTransactionInterest, 200)
+	if ph := getWithholding(t, stub, "LOAN_WH_PH"); len(ph.Records) != 0 || ph.TotalWithheld != 0 {
+		t.Errorf("expected nothing withheld on a Philippine fee, got %+v", ph)
+	}
+	if us := getWithholding(t, stub, "LOAN_WH_US"); len(us.Records) != 0 {
+		t.Errorf("expected nothing withheld without a jurisdiction, got %+v", us)
+	}
+
+	// Interest settled by a scheduled repayment is withheld as it is allocated
+	seedServicedLoan(t, stub, "LOAN_WH_REPAY", "USD", 12000, 12)
+	repaying := getLoan(t, stub, "LOAN_WH_REPAY")
+	repaying.Jurisdiction = "NG"
+	if _, err := inTx(stub, "jurisdiction", func() ([]byte, error) {
+		return nil, putLoanApplication(stub, services.NewPersistenceService(), repaying)
+	}); err != nil {
+		t.Fatalf("failed to set jurisdiction: %v", err)
+	}
+	result, err := repay(t, stub, "repay", "LOAN_WH_REPAY", 500)
+	if err != nil {
+		t.Fatalf("repayment failed: %v", err)
+	}
+	interestPaid := 0.0
+	for _, allocation := range result.Allocations {
+		interestPaid += allocation.InterestPaid
+	}
+	ng := getWithholding(t, stub, "LOAN_WH_REPAY")
+	if interestPaid <= 0 || len(ng.Records) != 1 || ng.Records[0].GrossAmount != roundToCents(interestPaid) || ng.TotalWithheld != roundToCents(interestPaid*0.10) {
+		t.Errorf("expected 10%% withheld on the %.2f interest allocated, got %+v", interestPaid, ng)
+	}
+}
+
+func TestWithholdingRemittanceReportTotalsMonthByLoan(t *testing.T) {
+	stub := newLoanStub(t, string(validation.ActorRoleLoanOperationsManager))
+	inKenya := func(loanApp *domain.LoanApplication) {
+		loanApp.Jurisdiction = "KE"
+		loanApp.CustomerID = "CUST_" + loanApp.LoanID
+		loanApp.DisbursedAmount = utils.NewMoney(12000, "USD")
+		loanApp.OutstandingBalance = utils.NewMoney(12000, "USD")
+	}
+	seedLoan(t, stub, "A", validation.LoanStatusDisbursed, approvedTerms(12000, 12), inKenya)
+	seedLoan(t, stub, "B", validation.LoanStatusDisbursed, approvedTerms(12000, 12), inKenya)
+	seedLoan(t, stub, "UAT", validation.LoanStatusDisbursed, approvedTerms(12000, 12), inKenya, func(loanApp *domain.LoanApplication) { loanApp.Sandbox = true })
+	march := time.Date(2026, 3, 10, 9, 0, 0, 0, time.UTC)
+	april := time.Date(2026, 4, 1, 0, 0, 0, 0, time.UTC)
+
+	postIncome(t, stub, "a_interest", "A", march, domain.LoanTransactionInterest, 100)
+	postIncome(t, stub, "a_fee", "A", march, domain.LoanTransactionFee, 40)
+	postIncome(t, stub, "a_april", "A", april, domain.LoanTransactionInterest, 100)
+	postIncome(t, stub, "b_interest", "B", march, domain.LoanTransactionInterest, 60)
+	postIncome(t, stub, "uat_interest", "UAT", march, domain.LoanTransactionInterest, 1000)
+
+	report := func(args ...string) (*domain.WithholdingRemittanceReport, error) {
+		payload, err := inTx(stub, "remittance", func() ([]byte, error) {
+			return NewWithholdingHandler().GetWithholdingRemittanceReport(stub, args)
+		})
+		if err != nil {
+			return nil, err
+		}
+		var remittance domain.WithholdingRemittanceReport
+		if err := json.Unmarshal(payload, &remittance); err != nil {
+			t.Fatalf("failed to decode report: %v", err)
+		}
+		return &remittance, nil
+	}
+
+	// One line per production loan, with the month's interest and fees kept apart
+	remittance, err := report("KE", "2026-03")
+	if err != nil {
+		t.Fatalf("GetWithholdingRemittanceReport failed: %v", err)
+	}
+	if len(remittance.Lines) != 2 || remittance.RecordCount != 3 {
+		t.Fatalf("expected lines for A and B over 3 records, got %+v", remittance)
+	}
+	a, b := remittance.Lines[0], remittance.Lines[1]
+	if a.LoanID != "A" || a.CustomerID != "CUST_A" || a.InterestWithheld != 15 || a.FeeWithheld != 2 || a.TotalWithheld != 17 {
+		t.Errorf("expected A to owe 15 on interest and 2 on fees, got %+v", a)
+	}
+	if b.LoanID != "B" || b.InterestGross != 60 || b.TotalWithheld != 9 {
+		t.Errorf("expected B to owe 9 on 60 of interest, got %+v", b)
+	}
+	if remittance.TotalInterestWithheld != 24 || remittance.TotalFeeWithheld != 2 || remittance.TotalWithheld != 26 {
+		t.Errorf("expected 26 to remit for March, got %+v", remittance)
+	}
+	if april, err := report("KE", "2026-04"); err != nil || april.TotalWithheld != 15 {
+		t.Errorf("expected April's posting in its own period, got %+v (%v)", april, err)
+	}
+
+	if _, err := report("GB", "2026-03"); err == nil {
+		t.Errorf("expected a jurisdiction without rates to be refused")
+	}
+	if _, err := report("KE", "March 2026"); err == nil {
+		t.Errorf("expected a malformed period to be refused")
+	}
+}
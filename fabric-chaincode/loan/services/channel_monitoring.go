@@ -0,0 +1,46 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/interfaces"
+)
+
+// ChannelMonitoringService reports the channel and device of new loan applications to the
+// compliance chaincode's channel fraud rules
+type ChannelMonitoringService struct {
+	chaincodeName string
+}
+
+// NewChannelMonitoringService creates a new channel monitoring service
+func NewChannelMonitoringService() *ChannelMonitoringService {
+	return &ChannelMonitoringService{
+		chaincodeName: config.ComplianceChaincodeName,
+	}
+}
+
+// Monitor reports an application's channel and device. Alerts are raised as a compliance event by
+// the compliance chaincode; the application proceeds either way.
+func (s *ChannelMonitoringService) Monitor(stub shim.ChaincodeStubInterface, report *interfaces.ApplicationChannelReport) (*interfaces.ApplicationChannelResult, error) {
+	reqBytes, err := json.Marshal(report)
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal application channel report: %w", err)
+	}
+
+	response := stub.InvokeChaincode(s.chaincodeName, [][]byte{
+		[]byte("MonitorApplicationChannel"),
+		reqBytes,
+	}, "")
+	if response.Status != shim.OK {
+		return nil, fmt.Errorf("failed to report channel of loan %s to %s chaincode: %s", report.LoanID, s.chaincodeName, response.Message)
+	}
+
+	var result interfaces.ApplicationChannelResult
+	if err := json.Unmarshal(response.Payload, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal application channel result: %w", err)
+	}
+	return &result, nil
+}
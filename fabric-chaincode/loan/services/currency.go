@@ -0,0 +1,60 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+)
+
+// FXIndexName returns the index rate oracles publish a currency's rate to the base currency under
+func FXIndexName(currency string) string {
+	return config.FXIndexPrefix + currency + config.BaseCurrency
+}
+
+// ConvertAmount converts an amount at an FX rate and rounds it to the minor units of the target
+// currency
+func ConvertAmount(amount utils.Money, rate float64, currency string) utils.Money {
+	return utils.NewMoney(amount.Float64()*rate, currency)
+}
+
+// ConvertToBase converts an amount to config.BaseCurrency at the current fixing of its currency's
+// FX index and returns the conversion relied on. Amounts already in the base currency are returned
+// as they are with a nil conversion.
+func (s *IndexRateService) ConvertToBase(stub shim.ChaincodeStubInterface, amount utils.Money) (utils.Money, *domain.CurrencyConversion, error) {
+	currency := amount.Currency()
+	if currency == config.BaseCurrency {
+		return amount, nil, nil
+	}
+
+	indexName := FXIndexName(currency)
+	rate, err := s.GetCurrentRate(stub, indexName)
+	if err != nil {
+		return utils.Money{}, nil, fmt.Errorf("failed to convert %s to %s: %w", currency, config.BaseCurrency, err)
+	}
+	if rate.Rate <= 0 {
+		return utils.Money{}, nil, fmt.Errorf("failed to convert %s to %s: %s fixing %.6f is not positive", currency, config.BaseCurrency, indexName, rate.Rate)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return utils.Money{}, nil, err
+	}
+
+	converted := ConvertAmount(amount, rate.Rate, config.BaseCurrency)
+	conversion := &domain.CurrencyConversion{
+		FromCurrency:    currency,
+		ToCurrency:      config.BaseCurrency,
+		RateIndex:       indexName,
+		Rate:            rate.Rate,
+		EffectiveDate:   rate.EffectiveDate,
+		OracleID:        rate.OracleID,
+		Amount:          amount.Float64(),
+		ConvertedAmount: converted.Float64(),
+		CapturedDate:    now,
+	}
+	return converted, conversion, nil
+}
@@ -0,0 +1,62 @@
+package services
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+)
+
+func TestConvertAmount(t *testing.T) {
+	if name := FXIndexName("EUR"); name != "FX_EURUSD" {
+		t.Errorf("expected FX_EURUSD, got %s", name)
+	}
+
+	// Converted amounts are rounded half away from zero to the target currency's minor units
+	if converted := ConvertAmount(utils.NewMoney(10000, "EUR"), 1.08505, "USD"); converted.String() != "10850.5" {
+		t.Errorf("expected 10850.50, got %s", converted)
+	}
+	if converted := ConvertAmount(utils.NewMoney(1234567, "JPY"), 0.0066667, "USD"); converted.String() != "8230.49" {
+		t.Errorf("expected 8230.49, got %s", converted)
+	}
+	if converted := ConvertAmount(utils.NewMoney(0.125, "USD"), 1, "EUR"); converted.MinorUnits() != 13 {
+		t.Errorf("expected 0.13, got %s", converted)
+	}
+	if converted := ConvertAmount(utils.NewMoney(2500.4, "USD"), 1, "JPY"); converted.String() != "2500" || converted.Currency() != "JPY" {
+		t.Errorf("expected 2500 with no minor units, got %s %s", converted, converted.Currency())
+	}
+}
+
+func TestLoanApplicationAmountsLoadInTheLoanCurrency(t *testing.T) {
+	approved := utils.NewMoney(1234.567, "KWD")
+	loan := domain.LoanApplication{LoanID: "LOAN_KWD", Currency: "KWD", RequestedAmount: utils.NewMoney(1500.125, "KWD"), ApprovedAmount: &approved}
+	data, err := json.Marshal(loan)
+	if err != nil {
+		t.Fatalf("failed to marshal loan: %v", err)
+	}
+
+	// Three minor units survive the round trip rather than being read as base-currency cents
+	var loaded domain.LoanApplication
+	if err := json.Unmarshal(data, &loaded); err != nil {
+		t.Fatalf("failed to unmarshal loan: %v", err)
+	}
+	if loaded.RequestedAmount != loan.RequestedAmount {
+		t.Errorf("expected requested amount %s KWD, got %s %s", loan.RequestedAmount, loaded.RequestedAmount, loaded.RequestedAmount.Currency())
+	}
+	if loaded.ApprovedAmount == nil || *loaded.ApprovedAmount != approved {
+		t.Errorf("expected approved amount %s KWD, got %v", approved, loaded.ApprovedAmount)
+	}
+
+	// Applications without a currency are in the base currency, and without an approval have no approved amount
+	if err := json.Unmarshal([]byte(`{"loanID":"LOAN_OLD","requestedAmount":2500.5,"requestedAmountMinor":250050}`), &loaded); err != nil {
+		t.Fatalf("failed to unmarshal legacy loan: %v", err)
+	}
+	if loaded.RequestedAmount.MinorUnits() != 250050 || loaded.RequestedAmount.Currency() != config.BaseCurrency {
+		t.Errorf("expected 2500.5 %s, got %s %s", config.BaseCurrency, loaded.RequestedAmount, loaded.RequestedAmount.Currency())
+	}
+	if loaded.ApprovedAmount != nil {
+		t.Errorf("expected no approved amount, got %s", loaded.ApprovedAmount)
+	}
+}
@@ -0,0 +1,194 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/interfaces"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// CustomerVerificationService checks customer eligibility against the customer chaincode
+type CustomerVerificationService struct {
+	chaincodeName string
+}
+
+// NewCustomerVerificationService creates a new customer verification service
+func NewCustomerVerificationService() *CustomerVerificationService {
+	return &CustomerVerificationService{
+		chaincodeName: config.CustomerChaincodeName,
+	}
+}
+
+// VerifyCustomer fetches the customer's compliance status and rejects customers that may not borrow
+func (s *CustomerVerificationService) VerifyCustomer(stub shim.ChaincodeStubInterface, customerID string) (*interfaces.CustomerComplianceStatus, error) {
+	status, problems, err := s.CheckEligibility(stub, customerID)
+	if err != nil {
+		return nil, err
+	}
+
+	if len(problems) > 0 {
+		return status, fmt.Errorf("customer %s is not eligible: %s", customerID, strings.Join(problems, "; "))
+	}
+
+	return status, nil
+}
+
+// CheckEligibility fetches the customer's compliance status and lists the reasons they may not
+// borrow. An error means the status could not be fetched, not that the customer is ineligible.
+func (s *CustomerVerificationService) CheckEligibility(stub shim.ChaincodeStubInterface, customerID string) (*interfaces.CustomerComplianceStatus, []string, error) {
+	status, err := s.getComplianceStatus(stub, customerID)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var problems []string
+	if status.Status != string(validation.CustomerStatusActive) {
+		problems = append(problems, fmt.Sprintf("customer status is %s", status.Status))
+	}
+	if status.KYCStatus != string(validation.KYCStatusVerified) {
+		problems = append(problems, fmt.Sprintf("KYC status is %s", statusOrNone(status.KYCStatus)))
+	} else if status.KYCExpiryDate != nil && now.After(*status.KYCExpiryDate) {
+		problems = append(problems, "KYC verification has expired")
+	}
+	if status.AMLStatus != string(validation.AMLStatusClear) {
+		problems = append(problems, fmt.Sprintf("AML status is %s", statusOrNone(status.AMLStatus)))
+	}
+	if !hasConsent(status.ConsentPreferences, config.ConsentCreditCheck) {
+		problems = append(problems, fmt.Sprintf("consent %s not granted", config.ConsentCreditCheck))
+	}
+	if status.Frozen {
+		problems = append(problems, "customer is frozen by compliance")
+	}
+
+	return status, problems, nil
+}
+
+// CheckCreditInquiryConsent confirms the customer exists and, for hard inquiries, has granted
+// credit check consent. Soft pre-qualification pulls need no explicit consent.
+func (s *CustomerVerificationService) CheckCreditInquiryConsent(stub shim.ChaincodeStubInterface, customerID string, inquiryType validation.CreditInquiryType) error {
+	status, err := s.getComplianceStatus(stub, customerID)
+	if err != nil {
+		return err
+	}
+
+	if inquiryType == validation.CreditInquiryHard && !hasConsent(status.ConsentPreferences, config.ConsentCreditCheck) {
+		return fmt.Errorf("hard credit inquiry requires consent %s from customer %s", config.ConsentCreditCheck, customerID)
+	}
+	return nil
+}
+
+// CustomerResidency returns the customer's residency, or "" when none is recorded
+func (s *CustomerVerificationService) CustomerResidency(stub shim.ChaincodeStubInterface, customerID string) (string, error) {
+	status, err := s.getComplianceStatus(stub, customerID)
+	if err != nil {
+		return "", err
+	}
+	return status.Residency, nil
+}
+
+// CustomerRiskTier returns the customer's risk tier, or "" when they have not been rated
+func (s *CustomerVerificationService) CustomerRiskTier(stub shim.ChaincodeStubInterface, customerID string) (string, error) {
+	status, err := s.getComplianceStatus(stub, customerID)
+	if err != nil {
+		return "", err
+	}
+	return status.RiskTier, nil
+}
+
+// CustomerExists reports whether the customer chaincode holds a record for the customer
+func (s *CustomerVerificationService) CustomerExists(stub shim.ChaincodeStubInterface, customerID string) (bool, error) {
+	if customerID == "" {
+		return false, nil
+	}
+
+	response := stub.InvokeChaincode(s.chaincodeName, [][]byte{
+		[]byte("GetCustomerComplianceStatus"),
+		[]byte(customerID),
+	}, "")
+	if response.Status == shim.OK {
+		return true, nil
+	}
+	if strings.Contains(response.Message, "customer not found") {
+		return false, nil
+	}
+	return false, fmt.Errorf("failed to look up customer %s with %s chaincode: %s", customerID, s.chaincodeName, response.Message)
+}
+
+// CheckConsentInForce confirms the customer's consent to a purpose is in force at the transaction
+// time and returns the consent grant relied on
+func (s *CustomerVerificationService) CheckConsentInForce(stub shim.ChaincodeStubInterface, customerID, purpose string) (*interfaces.ConsentGrant, error) {
+	if customerID == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "customerID", "customerID is required")
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	response := stub.InvokeChaincode(s.chaincodeName, [][]byte{
+		[]byte("GetConsentAt"),
+		[]byte(customerID),
+		[]byte(purpose),
+		[]byte(now.Format(time.RFC3339)),
+	}, "")
+	if response.Status != shim.OK {
+		return nil, fmt.Errorf("failed to check consent %s of customer %s with %s chaincode: %s", purpose, customerID, s.chaincodeName, response.Message)
+	}
+
+	var status interfaces.ConsentStatus
+	if err := json.Unmarshal(response.Payload, &status); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal consent status: %w", err)
+	}
+	if !status.Granted || status.Record == nil {
+		return nil, fmt.Errorf("consent %s not granted by customer %s", purpose, customerID)
+	}
+	return status.Record, nil
+}
+
+func (s *CustomerVerificationService) getComplianceStatus(stub shim.ChaincodeStubInterface, customerID string) (*interfaces.CustomerComplianceStatus, error) {
+	if customerID == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "customerID", "customerID is required")
+	}
+
+	response := stub.InvokeChaincode(s.chaincodeName, [][]byte{
+		[]byte("GetCustomerComplianceStatus"),
+		[]byte(customerID),
+	}, "")
+	if response.Status != shim.OK {
+		return nil, fmt.Errorf("failed to verify customer %s with %s chaincode: %s", customerID, s.chaincodeName, response.Message)
+	}
+
+	var status interfaces.CustomerComplianceStatus
+	if err := json.Unmarshal(response.Payload, &status); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal customer compliance status: %w", err)
+	}
+	return &status, nil
+}
+
+func hasConsent(consentJSON, consentKey string) bool {
+	var consent map[string]interface{}
+	if err := json.Unmarshal([]byte(consentJSON), &consent); err != nil {
+		return false
+	}
+	granted, ok := consent[consentKey].(bool)
+	return ok && granted
+}
+
+func statusOrNone(status string) string {
+	if status == "" {
+		return "NONE"
+	}
+	return status
+}
@@ -0,0 +1,45 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/interfaces"
+)
+
+// DefaultReportingService reports loan defaults to the compliance chaincode
+type DefaultReportingService struct {
+	chaincodeName string
+}
+
+// NewDefaultReportingService creates a new default reporting service
+func NewDefaultReportingService() *DefaultReportingService {
+	return &DefaultReportingService{
+		chaincodeName: config.ComplianceChaincodeName,
+	}
+}
+
+// ReportDefault raises a loan default as a compliance event and returns the event's ID. It is
+// invoked in the same transaction as the default, so a failure to report rolls the default back.
+func (s *DefaultReportingService) ReportDefault(stub shim.ChaincodeStubInterface, report *interfaces.LoanDefaultReport) (string, error) {
+	reqBytes, err := json.Marshal(report)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal loan default report: %w", err)
+	}
+
+	response := stub.InvokeChaincode(s.chaincodeName, [][]byte{
+		[]byte("RecordLoanDefault"),
+		reqBytes,
+	}, "")
+	if response.Status != shim.OK {
+		return "", fmt.Errorf("failed to report default of loan %s to %s chaincode: %s", report.LoanID, s.chaincodeName, response.Message)
+	}
+
+	var result interfaces.LoanDefaultReportResult
+	if err := json.Unmarshal(response.Payload, &result); err != nil {
+		return "", fmt.Errorf("failed to unmarshal loan default report result: %w", err)
+	}
+	return result.EventID, nil
+}
@@ -2,6 +2,7 @@ package services
 
 import (
 	"fmt"
+	"strings"
 
 	"github.com/hyperledger/fabric-chaincode-go/shim"
 	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
@@ -25,8 +26,10 @@ func NewEventService() *EventService {
 func (es *EventService) EmitLoanSubmitted(stub shim.ChaincodeStubInterface, loan *domain.LoanApplication, actorID string) error {
 	metadata := map[string]string{
 		"customerID":      loan.CustomerID,
+		"partyIDs":        loanPartyIDs(loan),
 		"loanType":        loan.LoanType,
-		"requestedAmount": fmt.Sprintf("%.2f", loan.RequestedAmount),
+		"currency":        loan.Currency,
+		"requestedAmount": loan.RequestedAmount.String(),
 		"status":          string(loan.Status),
 	}
 	
@@ -46,8 +49,10 @@ func (es *EventService) EmitLoanSubmitted(stub shim.ChaincodeStubInterface, loan
 func (es *EventService) EmitLoanApproved(stub shim.ChaincodeStubInterface, loan *domain.LoanApplication, actorID string) error {
 	metadata := map[string]string{
 		"customerID":     loan.CustomerID,
+		"partyIDs":       loanPartyIDs(loan),
 		"loanType":       loan.LoanType,
-		"approvedAmount": fmt.Sprintf("%.2f", *loan.ApprovedAmount),
+		"currency":       loan.Currency,
+		"approvedAmount": loan.ApprovedAmount.String(),
 		"interestRate":   fmt.Sprintf("%.2f", *loan.InterestRate),
 		"status":         string(loan.Status),
 	}
@@ -68,6 +73,7 @@ func (es *EventService) EmitLoanApproved(stub shim.ChaincodeStubInterface, loan
 func (es *EventService) EmitLoanRejected(stub shim.ChaincodeStubInterface, loan *domain.LoanApplication, actorID string) error {
 	metadata := map[string]string{
 		"customerID": loan.CustomerID,
+		"partyIDs":   loanPartyIDs(loan),
 		"loanType":   loan.LoanType,
 		"status":     string(loan.Status),
 		"reason":     loan.Notes,
@@ -85,17 +91,20 @@ func (es *EventService) EmitLoanRejected(stub shim.ChaincodeStubInterface, loan
 	return es.EmitEvent(stub, config.EventLoanRejected, payload)
 }
 
-// EmitLoanDisbursed emits a loan disbursed event
-func (es *EventService) EmitLoanDisbursed(stub shim.ChaincodeStubInterface, loan *domain.LoanApplication, actorID string) error {
+// EmitLoanApplicationCancelled emits a loan application cancelled event
+func (es *EventService) EmitLoanApplicationCancelled(stub shim.ChaincodeStubInterface, loan *domain.LoanApplication, actorID string) error {
 	metadata := map[string]string{
 		"customerID":     loan.CustomerID,
+		"partyIDs":       loanPartyIDs(loan),
 		"loanType":       loan.LoanType,
-		"approvedAmount": fmt.Sprintf("%.2f", *loan.ApprovedAmount),
+		"initiatedBy":    loan.Cancellation.InitiatedBy,
+		"reasonCode":     loan.Cancellation.ReasonCode,
+		"previousStatus": loan.Cancellation.PreviousStatus,
 		"status":         string(loan.Status),
 	}
 	
 	payload := es.CreateEventPayloadWithMetadata(
-		config.EventLoanDisbursed,
+		config.EventLoanApplicationCancelled,
 		loan.LoanID,
 		"LoanApplication",
 		actorID,
@@ -103,6 +112,853 @@ func (es *EventService) EmitLoanDisbursed(stub shim.ChaincodeStubInterface, loan
 		metadata,
 	)
 	
+	return es.EmitEvent(stub, config.EventLoanApplicationCancelled, payload)
+}
+
+// EmitLoanDisbursed emits a loan disbursed event for a full or tranche disbursement
+func (es *EventService) EmitLoanDisbursed(stub shim.ChaincodeStubInterface, loan *domain.LoanApplication, disbursement *domain.LoanDisbursement, actorID string) error {
+	metadata := map[string]string{
+		"customerID":      loan.CustomerID,
+		"partyIDs":        loanPartyIDs(loan),
+		"loanType":        loan.LoanType,
+		"approvedAmount":  loan.ApprovedAmount.String(),
+		"disbursementID":  disbursement.DisbursementID,
+		"trancheNumber":   fmt.Sprintf("%d", disbursement.TrancheNumber),
+		"amount":          disbursement.Amount.String(),
+		"disbursedAmount": loan.DisbursedAmount.String(),
+		"remainingAmount": disbursement.RemainingAmount.String(),
+		"status":          string(loan.Status),
+	}
+	if disbursement.CommissionEntryID != "" {
+		metadata["introducerID"] = loan.IntroducerID
+		metadata["commissionEntryID"] = disbursement.CommissionEntryID
+		metadata["commissionAccrued"] = fmt.Sprintf("%.2f", disbursement.CommissionAmount)
+	}
+	if disbursement.CrossBorder != nil {
+		metadata["originCountry"] = disbursement.CrossBorder.OriginCountry
+		metadata["payeeCountry"] = disbursement.CrossBorder.DestinationCountry
+		metadata["corridorAction"] = string(disbursement.CrossBorder.Action)
+		metadata["crossBorderFlagged"] = fmt.Sprintf("%t", disbursement.CrossBorder.Flagged)
+		if disbursement.CrossBorder.ReportEntryID != "" {
+			metadata["corridorReportEntryID"] = disbursement.CrossBorder.ReportEntryID
+		}
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		config.EventLoanDisbursed,
+		loan.LoanID,
+		"LoanApplication",
+		actorID,
+		disbursement,
+		metadata,
+	)
+	
 	return es.EmitEvent(stub, config.EventLoanDisbursed, payload)
 }
 
+
+// EmitLoanTransactionRecorded emits a loan transaction recorded event
+func (es *EventService) EmitLoanTransactionRecorded(stub shim.ChaincodeStubInterface, txn *domain.LoanTransaction, actorID string) error {
+	metadata := map[string]string{
+		"loanID":          txn.LoanID,
+		"transactionType": string(txn.TransactionType),
+		"amount":          txn.Amount.String(),
+		"balanceAfter":    txn.BalanceAfter.String(),
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		config.EventLoanTransactionRecorded,
+		txn.TransactionID,
+		"LoanTransaction",
+		actorID,
+		txn,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, config.EventLoanTransactionRecorded, payload)
+}
+
+// EmitLoanBalanceDiscrepancy emits a compliance event for a reconciliation discrepancy
+func (es *EventService) EmitLoanBalanceDiscrepancy(stub shim.ChaincodeStubInterface, recon *domain.LoanReconciliation, actorID string) error {
+	metadata := map[string]string{
+		"loanID":          recon.LoanID,
+		"storedBalance":   recon.StoredBalance.String(),
+		"expectedBalance": recon.ExpectedBalance.String(),
+		"discrepancy":     recon.Discrepancy.String(),
+		"status":          string(recon.Status),
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		config.EventLoanBalanceDiscrepancy,
+		recon.ReconciliationID,
+		"LoanReconciliation",
+		actorID,
+		recon,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, config.EventLoanBalanceDiscrepancy, payload)
+}
+
+// EmitLoanBalanceRepaired emits a loan balance repaired event
+func (es *EventService) EmitLoanBalanceRepaired(stub shim.ChaincodeStubInterface, recon *domain.LoanReconciliation, actorID string) error {
+	metadata := map[string]string{
+		"loanID":            recon.LoanID,
+		"previousBalance":   recon.StoredBalance.String(),
+		"repairedBalance":   recon.ExpectedBalance.String(),
+		"repairRequestedBy": recon.RepairRequestedBy,
+		"repairApprovedBy":  recon.RepairApprovedBy,
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		config.EventLoanBalanceRepaired,
+		recon.ReconciliationID,
+		"LoanReconciliation",
+		actorID,
+		recon,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, config.EventLoanBalanceRepaired, payload)
+}
+
+// EmitLoanParticipationAdded emits a loan participation added event
+func (es *EventService) EmitLoanParticipationAdded(stub shim.ChaincodeStubInterface, participation *domain.LoanParticipation, actorID string) error {
+	metadata := map[string]string{
+		"loanID":          participation.LoanID,
+		"counterpartyID":  participation.CounterpartyID,
+		"role":            participation.Role,
+		"sharePercentage": fmt.Sprintf("%.2f", participation.SharePercentage),
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		config.EventLoanParticipationAdded,
+		participation.ParticipationID,
+		"LoanParticipation",
+		actorID,
+		participation,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, config.EventLoanParticipationAdded, payload)
+}
+
+// EmitCounterpartyEvent emits a counterparty lifecycle event
+func (es *EventService) EmitCounterpartyEvent(stub shim.ChaincodeStubInterface, eventName string, counterparty *domain.Counterparty, actorID string) error {
+	metadata := map[string]string{
+		"counterpartyType": counterparty.CounterpartyType,
+		"status":           string(counterparty.Status),
+		"kycStatus":        string(counterparty.KYCStatus),
+		"sanctionsStatus":  string(counterparty.SanctionsStatus),
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		eventName,
+		counterparty.CounterpartyID,
+		"Counterparty",
+		actorID,
+		counterparty,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, eventName, payload)
+}
+
+// EmitIndexRatePublished emits an index rate published event
+func (es *EventService) EmitIndexRatePublished(stub shim.ChaincodeStubInterface, rate *domain.IndexRate) error {
+	metadata := map[string]string{
+		"indexName":     rate.IndexName,
+		"rate":          fmt.Sprintf("%.4f", rate.Rate),
+		"effectiveDate": rate.EffectiveDate,
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		config.EventIndexRatePublished,
+		rate.IndexName,
+		"IndexRate",
+		rate.OracleID,
+		rate,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, config.EventIndexRatePublished, payload)
+}
+
+// EmitLoanRepriced emits a loan repriced event
+func (es *EventService) EmitLoanRepriced(stub shim.ChaincodeStubInterface, loan *domain.LoanApplication, previousRate float64, actorID string) error {
+	metadata := map[string]string{
+		"customerID":    loan.CustomerID,
+		"rateIndex":     loan.RateIndex,
+		"indexRateDate": loan.IndexRateDate,
+		"previousRate":  fmt.Sprintf("%.4f", previousRate),
+		"interestRate":  fmt.Sprintf("%.4f", *loan.InterestRate),
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		config.EventLoanRepriced,
+		loan.LoanID,
+		"LoanApplication",
+		actorID,
+		loan,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, config.EventLoanRepriced, payload)
+}
+
+// EmitSTPPolicyUpdated emits a straight-through processing policy change event
+func (es *EventService) EmitSTPPolicyUpdated(stub shim.ChaincodeStubInterface, policy *domain.STPPolicy, actorID string) error {
+	metadata := map[string]string{
+		"enabled":      fmt.Sprintf("%t", policy.Enabled),
+		"maxAmount":    fmt.Sprintf("%.2f", policy.MaxAmount),
+		"maxRiskScore": fmt.Sprintf("%.2f", policy.MaxRiskScore),
+		"sampleRate":   fmt.Sprintf("%.4f", policy.SampleRate),
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		config.EventSTPPolicyUpdated,
+		"STP_POLICY",
+		"STPPolicy",
+		actorID,
+		policy,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, config.EventSTPPolicyUpdated, payload)
+}
+
+// EmitLoanBulkOperationApplied emits one event for a batch of a bulk loan operation, listing the
+// loans it changed
+func (es *EventService) EmitLoanBulkOperationApplied(stub shim.ChaincodeStubInterface, operation *domain.BulkLoanOperation, actorID string) error {
+	changed := []string{}
+	for _, outcome := range operation.Outcomes {
+		if outcome.Changed {
+			changed = append(changed, outcome.LoanID)
+		}
+	}
+
+	metadata := map[string]string{
+		"operation":    operation.Operation,
+		"changedCount": fmt.Sprintf("%d", operation.Changed),
+		"changedLoans": strings.Join(changed, ","),
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		config.EventLoanBulkOperationApplied,
+		operation.OperationID,
+		"BulkLoanOperation",
+		actorID,
+		operation,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, config.EventLoanBulkOperationApplied, payload)
+}
+
+// EmitBalanceSnapshotCertified emits an event when an end-of-day balance snapshot is certified
+func (es *EventService) EmitBalanceSnapshotCertified(stub shim.ChaincodeStubInterface, snapshot *domain.BalanceSnapshot, actorID string) error {
+	metadata := map[string]string{
+		"snapshotDate": snapshot.SnapshotDate,
+		"loanCount":    fmt.Sprintf("%d", snapshot.LoanCount),
+		"totalBalance": fmt.Sprintf("%.2f", snapshot.TotalBalance),
+		"snapshotHash": snapshot.SnapshotHash,
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		config.EventBalanceSnapshotCertified,
+		snapshot.SnapshotDate,
+		"BalanceSnapshot",
+		actorID,
+		snapshot,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, config.EventBalanceSnapshotCertified, payload)
+}
+
+// EmitInterestAccrualCompleted emits an event when a daily interest accrual run completes
+func (es *EventService) EmitInterestAccrualCompleted(stub shim.ChaincodeStubInterface, run *domain.InterestAccrualRun, actorID string) error {
+	metadata := map[string]string{
+		"accrualDate":          run.AccrualDate,
+		"loansAccrued":         fmt.Sprintf("%d", run.LoansAccrued),
+		"totalAccruedInterest": fmt.Sprintf("%.2f", run.TotalAccruedInterest),
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		config.EventInterestAccrualCompleted,
+		run.AccrualDate,
+		"InterestAccrualRun",
+		actorID,
+		run,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, config.EventInterestAccrualCompleted, payload)
+}
+
+// EmitFacilityEvent emits a credit facility lifecycle event
+func (es *EventService) EmitFacilityEvent(stub shim.ChaincodeStubInterface, eventName string, facility *domain.CreditFacility, actorID string) error {
+	metadata := map[string]string{
+		"customerID":   facility.CustomerID,
+		"creditLimit":  fmt.Sprintf("%.2f", facility.CreditLimit),
+		"drawnBalance": fmt.Sprintf("%.2f", facility.DrawnBalance),
+		"utilization":  fmt.Sprintf("%.2f", facility.Utilization),
+		"status":       string(facility.Status),
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		eventName,
+		facility.FacilityID,
+		"CreditFacility",
+		actorID,
+		facility,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, eventName, payload)
+}
+
+// EmitLoanDefaulted emits a loan defaulted event
+func (es *EventService) EmitLoanDefaulted(stub shim.ChaincodeStubInterface, loan *domain.LoanApplication, actorID string) error {
+	metadata := map[string]string{
+		"customerID":         loan.CustomerID,
+		"loanType":           loan.LoanType,
+		"outstandingBalance": loan.OutstandingBalance.String(),
+		"status":             string(loan.Status),
+	}
+	if loan.Default != nil && loan.Default.CommissionClawedBack > 0 {
+		metadata["introducerID"] = loan.IntroducerID
+		metadata["commissionClawedBack"] = fmt.Sprintf("%.2f", loan.Default.CommissionClawedBack)
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		config.EventLoanDefaulted,
+		loan.LoanID,
+		"LoanApplication",
+		actorID,
+		loan,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, config.EventLoanDefaulted, payload)
+}
+
+// EmitLoanDelinquent emits a loan delinquent event
+func (es *EventService) EmitLoanDelinquent(stub shim.ChaincodeStubInterface, loan *domain.LoanApplication, actorID string) error {
+	metadata := map[string]string{
+		"customerID":    loan.CustomerID,
+		"loanType":      loan.LoanType,
+		"daysPastDue":   fmt.Sprintf("%d", loan.Delinquency.DaysPastDue),
+		"bucket":        loan.Delinquency.Bucket,
+		"amountOverdue": fmt.Sprintf("%.2f", loan.Delinquency.AmountOverdue),
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		config.EventLoanDelinquent,
+		loan.LoanID,
+		"LoanApplication",
+		actorID,
+		loan,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, config.EventLoanDelinquent, payload)
+}
+
+// EmitLoanDelinquencyCured emits an event when repayments clear a delinquent loan's arrears
+func (es *EventService) EmitLoanDelinquencyCured(stub shim.ChaincodeStubInterface, loan *domain.LoanApplication, actorID string) error {
+	metadata := map[string]string{
+		"customerID":         loan.CustomerID,
+		"loanType":           loan.LoanType,
+		"outstandingBalance": loan.OutstandingBalance.String(),
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		config.EventLoanDelinquencyCured,
+		loan.LoanID,
+		"LoanApplication",
+		actorID,
+		loan,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, config.EventLoanDelinquencyCured, payload)
+}
+
+// EmitGuaranteeAdded emits a guarantee added event
+func (es *EventService) EmitGuaranteeAdded(stub shim.ChaincodeStubInterface, guarantee *domain.LoanGuarantee, actorID string) error {
+	metadata := map[string]string{
+		"loanID":              guarantee.LoanID,
+		"borrowerCustomerID":  guarantee.BorrowerCustomerID,
+		"guarantorCustomerID": guarantee.GuarantorCustomerID,
+		"coverageAmount":      fmt.Sprintf("%.2f", guarantee.CoverageAmount),
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		config.EventGuaranteeAdded,
+		guarantee.GuaranteeID,
+		"LoanGuarantee",
+		actorID,
+		guarantee,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, config.EventGuaranteeAdded, payload)
+}
+
+// EmitGuaranteeInvoked emits a guarantee invoked event; the guarantor is notified via the guarantorCustomerID metadata
+func (es *EventService) EmitGuaranteeInvoked(stub shim.ChaincodeStubInterface, guarantee *domain.LoanGuarantee, obligation *domain.RecoveryObligation, actorID string) error {
+	metadata := map[string]string{
+		"loanID":              guarantee.LoanID,
+		"borrowerCustomerID":  guarantee.BorrowerCustomerID,
+		"guarantorCustomerID": guarantee.GuarantorCustomerID,
+		"obligationID":        obligation.ObligationID,
+		"obligationAmount":    fmt.Sprintf("%.2f", obligation.Amount),
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		config.EventGuaranteeInvoked,
+		guarantee.GuaranteeID,
+		"LoanGuarantee",
+		actorID,
+		obligation,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, config.EventGuaranteeInvoked, payload)
+}
+
+// EmitGuarantorPaymentRecorded emits a guarantor payment recorded event
+func (es *EventService) EmitGuarantorPaymentRecorded(stub shim.ChaincodeStubInterface, payment *domain.GuarantorPayment, obligation *domain.RecoveryObligation, actorID string) error {
+	metadata := map[string]string{
+		"loanID":              obligation.LoanID,
+		"guarantorCustomerID": obligation.GuarantorCustomerID,
+		"amount":              fmt.Sprintf("%.2f", payment.Amount),
+		"outstandingAmount":   fmt.Sprintf("%.2f", obligation.OutstandingAmount),
+		"obligationStatus":    string(obligation.Status),
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		config.EventGuarantorPaymentRecorded,
+		obligation.ObligationID,
+		"RecoveryObligation",
+		actorID,
+		payment,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, config.EventGuarantorPaymentRecorded, payload)
+}
+
+// EmitRepaymentRecorded emits a scheduled repayment recorded event
+func (es *EventService) EmitRepaymentRecorded(stub shim.ChaincodeStubInterface, loan *domain.LoanApplication, result *domain.RepaymentResult, actorID string) error {
+	metadata := map[string]string{
+		"customerID":         loan.CustomerID,
+		"amount":             fmt.Sprintf("%.2f", result.Amount),
+		"installmentsPaid":   fmt.Sprintf("%d", len(result.Allocations)),
+		"outstandingBalance": loan.OutstandingBalance.String(),
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		config.EventRepaymentRecorded,
+		loan.LoanID,
+		"LoanApplication",
+		actorID,
+		result,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, config.EventRepaymentRecorded, payload)
+}
+
+// EmitCreditInquiryRecorded emits a credit inquiry recorded event
+func (es *EventService) EmitCreditInquiryRecorded(stub shim.ChaincodeStubInterface, inquiry *domain.CreditInquiry, actorID string) error {
+	metadata := map[string]string{
+		"customerID":  inquiry.CustomerID,
+		"inquiryType": string(inquiry.InquiryType),
+	}
+	if inquiry.BureauName != "" {
+		metadata["bureauName"] = inquiry.BureauName
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		config.EventCreditInquiryRecorded,
+		inquiry.InquiryID,
+		"CreditInquiry",
+		actorID,
+		inquiry,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, config.EventCreditInquiryRecorded, payload)
+}
+
+// EmitCreditReportRecorded emits a credit report recorded event
+func (es *EventService) EmitCreditReportRecorded(stub shim.ChaincodeStubInterface, report *domain.CreditReport, actorID string) error {
+	metadata := map[string]string{
+		"customerID": report.CustomerID,
+		"inquiryID":  report.InquiryID,
+		"bureauName": report.BureauName,
+		"score":      fmt.Sprintf("%d", report.Score),
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		config.EventCreditReportRecorded,
+		report.ReportID,
+		"CreditReport",
+		actorID,
+		report,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, config.EventCreditReportRecorded, payload)
+}
+
+// EmitCollateralEvent emits a collateral lifecycle event
+func (es *EventService) EmitCollateralEvent(stub shim.ChaincodeStubInterface, eventName string, collateral *domain.Collateral, actorID string) error {
+	metadata := map[string]string{
+		"loanID":         collateral.LoanID,
+		"collateralType": collateral.CollateralType,
+		"valuation":      fmt.Sprintf("%.2f", collateral.Valuation),
+		"lienStatus":     string(collateral.LienStatus),
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		eventName,
+		collateral.CollateralID,
+		"Collateral",
+		actorID,
+		collateral,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, eventName, payload)
+}
+
+// EmitLoanStatusUpdated emits a loan status change event for workflow stages without a dedicated event
+func (es *EventService) EmitLoanStatusUpdated(stub shim.ChaincodeStubInterface, loan *domain.LoanApplication, previousStatus string, actorID string) error {
+	metadata := map[string]string{
+		"customerID":     loan.CustomerID,
+		"partyIDs":       loanPartyIDs(loan),
+		"previousStatus": previousStatus,
+		"status":         string(loan.Status),
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		config.EventLoanStatusUpdated,
+		loan.LoanID,
+		"LoanApplication",
+		actorID,
+		loan,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, config.EventLoanStatusUpdated, payload)
+}
+
+// EmitDocumentEvent emits a loan document lifecycle event
+func (es *EventService) EmitDocumentEvent(stub shim.ChaincodeStubInterface, eventName string, loan *domain.LoanApplication, document *domain.LoanDocument, actorID string) error {
+	metadata := map[string]string{
+		"loanID":       document.LoanID,
+		"customerID":   loan.CustomerID,
+		"partyIDs":     loanPartyIDs(loan),
+		"documentType": document.DocumentType,
+		"status":       string(document.Status),
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		eventName,
+		document.DocumentID,
+		"LoanDocument",
+		actorID,
+		document,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, eventName, payload)
+}
+
+// EmitServicingTransferEvent emits a servicing transfer lifecycle event. The payload carries a
+// notice per affected customer so notification services can tell borrowers of the change.
+func (es *EventService) EmitServicingTransferEvent(stub shim.ChaincodeStubInterface, eventName string, transfer *domain.ServicingTransfer, notices []domain.ServicingTransferNotice, actorID string) error {
+	customerIDs := make([]string, 0, len(notices))
+	for _, notice := range notices {
+		customerIDs = append(customerIDs, notice.CustomerID)
+	}
+
+	metadata := map[string]string{
+		"fromServicerMSP": transfer.FromServicerMSP,
+		"toServicerMSP":   transfer.ToServicerMSP,
+		"status":          string(transfer.Status),
+		"effectiveDate":   transfer.EffectiveDate.Format("2006-01-02"),
+		"loanCount":       fmt.Sprintf("%d", len(transfer.LoanIDs)),
+		"customerIDs":     strings.Join(customerIDs, ","),
+	}
+	
+	data := &domain.ServicingTransferEventData{
+		Transfer: transfer,
+		Notices:  notices,
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		eventName,
+		transfer.TransferID,
+		"ServicingTransfer",
+		actorID,
+		data,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, eventName, payload)
+}
+
+// loanPartyIDs lists every customer on a loan so subscribers can route events to co-borrowers and guarantors
+func loanPartyIDs(loan *domain.LoanApplication) string {
+	if len(loan.Parties) == 0 {
+		return loan.CustomerID
+	}
+	ids := make([]string, 0, len(loan.Parties))
+	for _, party := range loan.Parties {
+		ids = append(ids, party.CustomerID)
+	}
+	return strings.Join(ids, ",")
+}
+
+// EmitOpenBankingAuthorizationChanged emits an Open Banking authorization granted or revoked event
+func (es *EventService) EmitOpenBankingAuthorizationChanged(stub shim.ChaincodeStubInterface, eventName string, authorization *domain.OpenBankingAuthorization, actorID string) error {
+	metadata := map[string]string{
+		"customerID": authorization.CustomerID,
+		"providerID": authorization.ProviderID,
+		"status":     authorization.Status,
+		"expiryDate": authorization.ExpiryDate.Format("2006-01-02"),
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		eventName,
+		authorization.AuthorizationID,
+		"OpenBankingAuthorization",
+		actorID,
+		authorization,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, eventName, payload)
+}
+
+// EmitAffordabilityAssessed emits an affordability assessed event
+func (es *EventService) EmitAffordabilityAssessed(stub shim.ChaincodeStubInterface, assessment *domain.AffordabilityAssessment) error {
+	metadata := map[string]string{
+		"loanID":           assessment.LoanID,
+		"customerID":       assessment.CustomerID,
+		"debtServiceRatio": fmt.Sprintf("%.4f", assessment.DebtServiceRatio),
+		"affordable":       fmt.Sprintf("%t", assessment.Affordable),
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		config.EventAffordabilityAssessed,
+		assessment.AssessmentID,
+		"AffordabilityAssessment",
+		assessment.AssessedBy,
+		assessment,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, config.EventAffordabilityAssessed, payload)
+}
+
+// EmitIntroducerEvent emits an introducer registration or status event
+func (es *EventService) EmitIntroducerEvent(stub shim.ChaincodeStubInterface, eventName string, introducer *domain.Introducer, actorID string) error {
+	metadata := map[string]string{
+		"legalName":  introducer.LegalName,
+		"scheduleID": introducer.ScheduleID,
+		"status":     string(introducer.Status),
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		eventName,
+		introducer.IntroducerID,
+		"Introducer",
+		actorID,
+		introducer,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, eventName, payload)
+}
+
+// EmitCommissionScheduleUpdated emits a commission schedule updated event
+func (es *EventService) EmitCommissionScheduleUpdated(stub shim.ChaincodeStubInterface, schedule *domain.CommissionSchedule, actorID string) error {
+	metadata := map[string]string{
+		"rates":                fmt.Sprintf("%d", len(schedule.Rates)),
+		"clawbackWindowMonths": fmt.Sprintf("%d", schedule.ClawbackWindowMonths),
+		"clawbackPercent":      fmt.Sprintf("%.2f", schedule.ClawbackPercent),
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		config.EventCommissionScheduleUpdated,
+		schedule.ScheduleID,
+		"CommissionSchedule",
+		actorID,
+		schedule,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, config.EventCommissionScheduleUpdated, payload)
+}
+
+// EmitCorridorRuleUpdated emits a corridor rule updated event
+func (es *EventService) EmitCorridorRuleUpdated(stub shim.ChaincodeStubInterface, rule *domain.CorridorRule, actorID string) error {
+	metadata := map[string]string{
+		"originCountry":      rule.OriginCountry,
+		"destinationCountry": rule.DestinationCountry,
+		"action":             string(rule.Action),
+		"reportingThreshold": fmt.Sprintf("%.2f", rule.ReportingThreshold),
+		"reportType":         rule.ReportType,
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		config.EventCorridorRuleUpdated,
+		fmt.Sprintf("%s_%s", rule.OriginCountry, rule.DestinationCountry),
+		"CorridorRule",
+		actorID,
+		rule,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, config.EventCorridorRuleUpdated, payload)
+}
+
+// EmitLoanAmountBoundUpdated emits a loan amount bound updated event
+func (es *EventService) EmitLoanAmountBoundUpdated(stub shim.ChaincodeStubInterface, bound *domain.LoanAmountBound, actorID string) error {
+	metadata := map[string]string{
+		"riskTier":  bound.RiskTier,
+		"loanType":  bound.LoanType,
+		"minAmount": fmt.Sprintf("%.2f", bound.MinAmount),
+		"maxAmount": fmt.Sprintf("%.2f", bound.MaxAmount),
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		config.EventLoanAmountBoundUpdated,
+		fmt.Sprintf("%s_%s", bound.RiskTier, bound.LoanType),
+		"LoanAmountBound",
+		actorID,
+		bound,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, config.EventLoanAmountBoundUpdated, payload)
+}
+
+// EmitUnderwritingChecklistUpdated emits an underwriting checklist updated event
+func (es *EventService) EmitUnderwritingChecklistUpdated(stub shim.ChaincodeStubInterface, checklist *domain.UnderwritingChecklist, actorID string) error {
+	metadata := map[string]string{
+		"loanType":  checklist.LoanType,
+		"bandID":    checklist.BandID,
+		"minAmount": fmt.Sprintf("%.2f", checklist.MinAmount),
+		"maxAmount": fmt.Sprintf("%.2f", checklist.MaxAmount),
+		"items":     fmt.Sprintf("%d", len(checklist.Items)),
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		config.EventUnderwritingChecklistUpdated,
+		fmt.Sprintf("%s_%s", checklist.LoanType, checklist.BandID),
+		"UnderwritingChecklist",
+		actorID,
+		checklist,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, config.EventUnderwritingChecklistUpdated, payload)
+}
+
+// EmitChecklistItemCompleted emits a checklist item completed event
+func (es *EventService) EmitChecklistItemCompleted(stub shim.ChaincodeStubInterface, completion *domain.ChecklistItemCompletion, actorID string) error {
+	metadata := map[string]string{
+		"itemID":            completion.ItemID,
+		"evidenceReference": completion.EvidenceReference,
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		config.EventChecklistItemCompleted,
+		completion.LoanID,
+		"LoanApplication",
+		actorID,
+		completion,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, config.EventChecklistItemCompleted, payload)
+}
+
+// EmitInterestRatePolicyUpdated emits an interest rate policy updated event
+func (es *EventService) EmitInterestRatePolicyUpdated(stub shim.ChaincodeStubInterface, policy *domain.InterestRatePolicy, actorID string) error {
+	metadata := map[string]string{
+		"loanType":  policy.LoanType,
+		"version":   fmt.Sprintf("%d", policy.Version),
+		"floorRate": fmt.Sprintf("%.4f", policy.FloorRate),
+		"capRate":   fmt.Sprintf("%.4f", policy.CapRate),
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		config.EventInterestRatePolicyUpdated,
+		policy.LoanType,
+		"InterestRatePolicy",
+		actorID,
+		policy,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, config.EventInterestRatePolicyUpdated, payload)
+}
+
+// EmitPolicyExceptionRequested emits a policy exception requested event for credit officers to decide
+func (es *EventService) EmitPolicyExceptionRequested(stub shim.ChaincodeStubInterface, exception *domain.PolicyException, actorID string) error {
+	return es.emitPolicyException(stub, config.EventPolicyExceptionRequested, exception, actorID)
+}
+
+// EmitPolicyExceptionDecided emits a policy exception approved or rejected event
+func (es *EventService) EmitPolicyExceptionDecided(stub shim.ChaincodeStubInterface, exception *domain.PolicyException, actorID string) error {
+	return es.emitPolicyException(stub, config.EventPolicyExceptionDecided, exception, actorID)
+}
+
+func (es *EventService) emitPolicyException(stub shim.ChaincodeStubInterface, eventName string, exception *domain.PolicyException, actorID string) error {
+	metadata := map[string]string{
+		"type":       string(exception.Type),
+		"customerID": exception.CustomerID,
+		"loanType":   exception.LoanType,
+		"status":     string(exception.Status),
+		"minAmount":  fmt.Sprintf("%.2f", exception.MinAmount),
+		"maxAmount":  fmt.Sprintf("%.2f", exception.MaxAmount),
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		eventName,
+		exception.ExceptionID,
+		"PolicyException",
+		actorID,
+		exception,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, eventName, payload)
+}
+
+// EmitLoanStatusSyncResent emits a loan status sync resent event carrying the records offered again
+func (es *EventService) EmitLoanStatusSyncResent(stub shim.ChaincodeStubInterface, loanID string, page *domain.StatusSyncPage, actorID string) error {
+	metadata := map[string]string{
+		"records":      fmt.Sprintf("%d", page.Count),
+		"fromSequence": fmt.Sprintf("%d", page.Records[0].Sequence),
+		"toSequence":   fmt.Sprintf("%d", page.Records[page.Count-1].Sequence),
+	}
+	
+	payload := es.CreateEventPayloadWithMetadata(
+		config.EventLoanStatusSyncResent,
+		loanID,
+		"LoanApplication",
+		actorID,
+		page,
+		metadata,
+	)
+	
+	return es.EmitEvent(stub, config.EventLoanStatusSyncResent, payload)
+}
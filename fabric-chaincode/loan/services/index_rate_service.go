@@ -0,0 +1,70 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// IndexRateDateFormat is the layout of index rate effective dates
+const IndexRateDateFormat = "2006-01-02"
+
+// IndexRateService provides index rate lookups for pricing and repricing
+type IndexRateService struct {
+	persistenceService *services.PersistenceService
+}
+
+// NewIndexRateService creates a new index rate service
+func NewIndexRateService() *IndexRateService {
+	return &IndexRateService{
+		persistenceService: services.NewPersistenceService(),
+	}
+}
+
+// GetLatestRate returns the most recent fixing for an index, flagging it if stale
+func (s *IndexRateService) GetLatestRate(stub shim.ChaincodeStubInterface, indexName string) (*domain.IndexRate, error) {
+	var rate domain.IndexRate
+	if err := s.persistenceService.Get(stub, fmt.Sprintf("INDEX_RATE_LATEST_%s", indexName), &rate); err != nil {
+		return nil, fmt.Errorf("no rate published for index %s: %w", indexName, err)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	stale, err := IsIndexRateStale(&rate, now)
+	if err != nil {
+		return nil, err
+	}
+	rate.IsStale = stale
+
+	return &rate, nil
+}
+
+// GetCurrentRate returns the latest fixing for an index and rejects stale rates
+func (s *IndexRateService) GetCurrentRate(stub shim.ChaincodeStubInterface, indexName string) (*domain.IndexRate, error) {
+	rate, err := s.GetLatestRate(stub, indexName)
+	if err != nil {
+		return nil, err
+	}
+	if rate.IsStale {
+		return nil, fmt.Errorf("index %s rate effective %s is stale", indexName, rate.EffectiveDate)
+	}
+
+	return rate, nil
+}
+
+// IsIndexRateStale reports whether a fixing is older than the configured maximum age
+func IsIndexRateStale(rate *domain.IndexRate, now time.Time) (bool, error) {
+	effectiveDate, err := time.Parse(IndexRateDateFormat, rate.EffectiveDate)
+	if err != nil {
+		return false, fmt.Errorf("invalid effective date %s: %w", rate.EffectiveDate, err)
+	}
+
+	return now.Sub(effectiveDate) > config.IndexRateMaxAge, nil
+}
@@ -0,0 +1,209 @@
+package services
+
+import (
+	"encoding/xml"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// ISO 20022 date formats
+const (
+	isoDateFormat     = "2006-01-02"
+	isoDateTimeFormat = "2006-01-02T15:04:05"
+)
+
+// DisbursementToPain001 maps a disbursement to a pain.001 credit transfer initiation from the bank
+// to the destination account. The disbursement ID is carried as the end-to-end ID and the loan ID
+// as the unstructured remittance information.
+func DisbursementToPain001(disbursement *domain.LoanDisbursement, messageID string, createdAt time.Time) *domain.Pain001Document {
+	amount := formatISOAmount(disbursement.Amount.Float64())
+
+	return &domain.Pain001Document{
+		Initiation: domain.Pain001Initiation{
+			GroupHeader: domain.Pain001GroupHeader{
+				MessageID:        messageID,
+				CreationDateTime: createdAt.UTC().Format(isoDateTimeFormat),
+				NumberOfTxs:      1,
+				ControlSum:       amount,
+				InitiatingParty:  domain.ISOParty{OrgID: config.BankMSPID},
+			},
+			PaymentInformation: []domain.Pain001PaymentInformation{{
+				PaymentInfoID:          disbursement.DisbursementID,
+				PaymentMethod:          "TRF",
+				RequestedExecutionDate: disbursement.DisbursementDate.UTC().Format(isoDateFormat),
+				Debtor:                 domain.ISOParty{OrgID: config.BankMSPID},
+				DebtorAccount:          domain.ISOAccount{ID: disbursement.LoanID},
+				Transfers: []domain.Pain001CreditTransfer{{
+					InstructionID:   disbursement.TransactionID,
+					EndToEndID:      disbursement.DisbursementID,
+					Amount:          domain.ISOAmount{Currency: config.PaymentCurrency, Value: amount},
+					Creditor:        domain.ISOParty{OrgID: disbursement.CounterpartyID},
+					CreditorAccount: domain.ISOAccount{ID: disbursement.DestinationAccountRef},
+					Remittance:      disbursement.LoanID,
+				}},
+			}},
+		},
+	}
+}
+
+// Pain001ToDisbursementRequests maps each credit transfer in a pain.001 to a disbursement request.
+// Transfers must be in the payment currency and carry the loan ID as remittance information.
+func Pain001ToDisbursementRequests(doc *domain.Pain001Document, actorID string) ([]domain.LoanDisbursementRequest, error) {
+	requests := []domain.LoanDisbursementRequest{}
+	for _, payment := range doc.Initiation.PaymentInformation {
+		for _, transfer := range payment.Transfers {
+			amount, err := parseISOAmount(transfer.Amount)
+			if err != nil {
+				return nil, fmt.Errorf("invalid amount for transfer %s: %w", transfer.EndToEndID, err)
+			}
+			if strings.TrimSpace(transfer.Remittance) == "" {
+				return nil, fmt.Errorf("transfer %s carries no loan ID in its remittance information", transfer.EndToEndID)
+			}
+
+			requests = append(requests, domain.LoanDisbursementRequest{
+				LoanID:                transfer.Remittance,
+				Amount:                amount,
+				DestinationAccountRef: transfer.CreditorAccount.ID,
+				CounterpartyID:        transfer.Creditor.OrgID,
+				ActorID:               actorID,
+			})
+		}
+	}
+
+	if len(requests) == 0 {
+		return nil, fmt.Errorf("pain.001 message contains no credit transfers")
+	}
+	return requests, nil
+}
+
+// RepaymentToCamt054 maps a repayment transaction to a camt.054 credit notification on the loan
+// account. The repayment reference is carried as the end-to-end ID and the loan ID as the
+// unstructured remittance information.
+func RepaymentToCamt054(txn *domain.LoanTransaction, messageID string, createdAt time.Time) (*domain.Camt054Document, error) {
+	if txn.TransactionType != domain.LoanTransactionRepayment {
+		return nil, fmt.Errorf("transaction %s is a %s, not a repayment", txn.TransactionID, txn.TransactionType)
+	}
+
+	amount := domain.ISOAmount{Currency: config.PaymentCurrency, Value: formatISOAmount(txn.Amount.Float64())}
+	created := createdAt.UTC().Format(isoDateTimeFormat)
+
+	return &domain.Camt054Document{
+		Notification: domain.Camt054Notification{
+			GroupHeader: domain.Camt054GroupHeader{
+				MessageID:        messageID,
+				CreationDateTime: created,
+			},
+			Notifications: []domain.Camt054AccountNotification{{
+				ID:               txn.TransactionID,
+				CreationDateTime: created,
+				Account:          domain.ISOAccount{ID: txn.LoanID},
+				Entries: []domain.Camt054Entry{{
+					EntryReference:    txn.TransactionID,
+					Amount:            amount,
+					CreditDebit:       "CRDT",
+					Status:            "BOOK",
+					BookingDate:       txn.CreatedDate.UTC().Format(isoDateFormat),
+					ServicerReference: txn.TransactionID,
+					Details: []domain.Camt054TransactionDetail{{
+						EndToEndID: txn.Reference,
+						Amount:     amount,
+						Debtor:     domain.ISOParty{OrgID: txn.CounterpartyID},
+						Remittance: txn.LoanID,
+					}},
+				}},
+			}},
+		},
+	}, nil
+}
+
+// Camt054ToRepaymentRequests maps each booked credit in a camt.054 to a repayment request. Debits
+// and entries not yet booked are skipped; each transaction detail becomes its own repayment.
+func Camt054ToRepaymentRequests(doc *domain.Camt054Document, actorID string) ([]domain.RepaymentRequest, error) {
+	requests := []domain.RepaymentRequest{}
+	for _, notification := range doc.Notification.Notifications {
+		for _, entry := range notification.Entries {
+			if entry.CreditDebit != "CRDT" || entry.Status != "BOOK" {
+				continue
+			}
+
+			for _, detail := range entry.Details {
+				amount, err := parseISOAmount(detail.Amount)
+				if err != nil {
+					return nil, fmt.Errorf("invalid amount for entry %s: %w", entry.EntryReference, err)
+				}
+
+				loanID := detail.Remittance
+				if strings.TrimSpace(loanID) == "" {
+					loanID = notification.Account.ID
+				}
+
+				requests = append(requests, domain.RepaymentRequest{
+					LoanID:         loanID,
+					Amount:         amount,
+					Reference:      detail.EndToEndID,
+					CounterpartyID: detail.Debtor.OrgID,
+					ActorID:        actorID,
+				})
+			}
+		}
+	}
+
+	if len(requests) == 0 {
+		return nil, fmt.Errorf("camt.054 message contains no booked credits")
+	}
+	return requests, nil
+}
+
+// MarshalPaymentMessage renders an ISO 20022 document as XML with its declaration
+func MarshalPaymentMessage(doc interface{}) (string, error) {
+	documentBytes, err := xml.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal payment message: %w", err)
+	}
+	return xml.Header + string(documentBytes), nil
+}
+
+// ParsePain001 parses a pain.001 XML document
+func ParsePain001(document string) (*domain.Pain001Document, error) {
+	var doc domain.Pain001Document
+	if err := xml.Unmarshal([]byte(document), &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse pain.001 message: %w", err)
+	}
+	return &doc, nil
+}
+
+// ParseCamt054 parses a camt.054 XML document
+func ParseCamt054(document string) (*domain.Camt054Document, error) {
+	var doc domain.Camt054Document
+	if err := xml.Unmarshal([]byte(document), &doc); err != nil {
+		return nil, fmt.Errorf("failed to parse camt.054 message: %w", err)
+	}
+	return &doc, nil
+}
+
+// formatISOAmount renders an amount with two decimals, never in exponent form
+func formatISOAmount(amount float64) string {
+	return strconv.FormatFloat(amount, 'f', 2, 64)
+}
+
+// parseISOAmount reads a positive amount in the payment currency
+func parseISOAmount(amount domain.ISOAmount) (float64, error) {
+	if amount.Currency != config.PaymentCurrency {
+		return 0, fmt.Errorf("unsupported currency %s, expected %s", amount.Currency, config.PaymentCurrency)
+	}
+
+	value, err := strconv.ParseFloat(strings.TrimSpace(amount.Value), 64)
+	if err != nil {
+		return 0, err
+	}
+	if value <= 0 {
+		return 0, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "amount must be positive")
+	}
+	return value, nil
+}
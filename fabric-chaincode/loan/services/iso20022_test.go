@@ -0,0 +1,130 @@
+package services
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+)
+
+func TestDisbursementPain001RoundTrip(t *testing.T) {
+	disbursement := &domain.LoanDisbursement{
+		DisbursementID:        "DISB_001",
+		LoanID:                "LOAN_001",
+		TrancheNumber:         1,
+		Amount:                utils.NewMoney(125000.5, "USD"),
+		DisbursementDate:      time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC),
+		DestinationAccountRef: "ACCT_DEST_001",
+		CounterpartyID:        "CPTY_001",
+		TransactionID:         "TXN_001",
+	}
+
+	document, err := MarshalPaymentMessage(DisbursementToPain001(disbursement, "MSG_001", time.Date(2024, 3, 1, 11, 0, 0, 0, time.UTC)))
+	if err != nil {
+		t.Fatalf("failed to marshal pain.001: %v", err)
+	}
+	for _, element := range []string{"<CstmrCdtTrfInitn>", "<EndToEndId>DISB_001</EndToEndId>", `<InstdAmt Ccy="USD">125000.50</InstdAmt>`} {
+		if !strings.Contains(document, element) {
+			t.Errorf("pain.001 missing %s:\n%s", element, document)
+		}
+	}
+
+	doc, err := ParsePain001(document)
+	if err != nil {
+		t.Fatalf("failed to parse pain.001: %v", err)
+	}
+	requests, err := Pain001ToDisbursementRequests(doc, "ACTOR_001")
+	if err != nil {
+		t.Fatalf("failed to map pain.001: %v", err)
+	}
+
+	expected := domain.LoanDisbursementRequest{
+		LoanID:                "LOAN_001",
+		Amount:                125000.5,
+		DestinationAccountRef: "ACCT_DEST_001",
+		CounterpartyID:        "CPTY_001",
+		ActorID:               "ACTOR_001",
+	}
+	if len(requests) != 1 || requests[0] != expected {
+		t.Errorf("expected %+v, got %+v", expected, requests)
+	}
+}
+
+func TestRepaymentCamt054RoundTrip(t *testing.T) {
+	txn := &domain.LoanTransaction{
+		TransactionID:   "TXN_002",
+		LoanID:          "LOAN_001",
+		TransactionType: domain.LoanTransactionRepayment,
+		Amount:          utils.NewMoney(1234.56, "USD"),
+		Reference:       "BORROWER_REF_9",
+		CounterpartyID:  "CPTY_002",
+		CreatedDate:     time.Date(2024, 4, 1, 9, 30, 0, 0, time.UTC),
+	}
+
+	doc, err := RepaymentToCamt054(txn, "MSG_002", time.Date(2024, 4, 1, 12, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("failed to map repayment: %v", err)
+	}
+	document, err := MarshalPaymentMessage(doc)
+	if err != nil {
+		t.Fatalf("failed to marshal camt.054: %v", err)
+	}
+
+	parsed, err := ParseCamt054(document)
+	if err != nil {
+		t.Fatalf("failed to parse camt.054: %v", err)
+	}
+	requests, err := Camt054ToRepaymentRequests(parsed, "ACTOR_001")
+	if err != nil {
+		t.Fatalf("failed to map camt.054: %v", err)
+	}
+
+	expected := domain.RepaymentRequest{
+		LoanID:         "LOAN_001",
+		Amount:         1234.56,
+		Reference:      "BORROWER_REF_9",
+		CounterpartyID: "CPTY_002",
+		ActorID:        "ACTOR_001",
+	}
+	if len(requests) != 1 || requests[0] != expected {
+		t.Errorf("expected %+v, got %+v", expected, requests)
+	}
+}
+
+func TestCamt054SkipsDebitsAndRejectsOtherCurrencies(t *testing.T) {
+	doc := &domain.Camt054Document{
+		Notification: domain.Camt054Notification{
+			Notifications: []domain.Camt054AccountNotification{{
+				Account: domain.ISOAccount{ID: "LOAN_001"},
+				Entries: []domain.Camt054Entry{{
+					Amount:      domain.ISOAmount{Currency: "USD", Value: "10.00"},
+					CreditDebit: "DBIT",
+					Status:      "BOOK",
+					Details:     []domain.Camt054TransactionDetail{{Amount: domain.ISOAmount{Currency: "USD", Value: "10.00"}}},
+				}},
+			}},
+		},
+	}
+	if _, err := Camt054ToRepaymentRequests(doc, "ACTOR_001"); err == nil {
+		t.Error("expected a message with only debits to be rejected")
+	}
+
+	entry := &doc.Notification.Notifications[0].Entries[0]
+	entry.CreditDebit = "CRDT"
+	entry.Details[0].Amount.Currency = "EUR"
+	if _, err := Camt054ToRepaymentRequests(doc, "ACTOR_001"); err == nil || !strings.Contains(err.Error(), "unsupported currency") {
+		t.Errorf("expected unsupported currency error, got %v", err)
+	}
+
+	entry.Details[0].Amount.Currency = "USD"
+	requests, err := Camt054ToRepaymentRequests(doc, "ACTOR_001")
+	if err != nil {
+		t.Fatalf("failed to map camt.054: %v", err)
+	}
+	// Without remittance information the repayment is applied to the notified account
+	if len(requests) != 1 || requests[0].LoanID != "LOAN_001" {
+		t.Errorf("expected repayment to LOAN_001, got %+v", requests)
+	}
+}
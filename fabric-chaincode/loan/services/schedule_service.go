@@ -0,0 +1,261 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+)
+
+// ScheduleService generates and stores loan amortization schedules
+type ScheduleService struct {
+	persistenceService *services.PersistenceService
+}
+
+// NewScheduleService creates a new schedule service
+func NewScheduleService() *ScheduleService {
+	return &ScheduleService{
+		persistenceService: services.NewPersistenceService(),
+	}
+}
+
+// BuildAmortizationSchedule computes a level-payment schedule with monthly installments from startDate,
+// in the principal's currency and rounded to its minor units
+func BuildAmortizationSchedule(loanID string, principal utils.Money, annualRate float64, termMonths int, startDate time.Time) ([]domain.Installment, error) {
+	if !principal.IsPositive() {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "principal must be positive")
+	}
+	if termMonths <= 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "term must be positive")
+	}
+
+	monthlyRate := annualRate / 100 / 12
+	payment := principal.Mul(1 / float64(termMonths))
+	if monthlyRate != 0 {
+		payment = principal.Mul(monthlyRate / (1 - math.Pow(1+monthlyRate, -float64(termMonths))))
+	}
+
+	installments := make([]domain.Installment, 0, termMonths)
+	remaining := principal
+	for n := 1; n <= termMonths; n++ {
+		interest := remaining.Mul(monthlyRate)
+		principalDue, err := payment.Sub(interest)
+		if err != nil {
+			return nil, err
+		}
+		// The final installment absorbs rounding so the schedule retires the principal exactly
+		if cmp, err := principalDue.Cmp(remaining); err != nil {
+			return nil, err
+		} else if n == termMonths || cmp > 0 {
+			principalDue = remaining
+		}
+		if remaining, err = remaining.Sub(principalDue); err != nil {
+			return nil, err
+		}
+		totalDue, err := principalDue.Add(interest)
+		if err != nil {
+			return nil, err
+		}
+
+		zero := utils.ZeroMoney(principal.Currency())
+		installments = append(installments, domain.Installment{
+			LoanID:            loanID,
+			InstallmentNumber: n,
+			DueDate:           startDate.AddDate(0, n, 0),
+			Currency:          principal.Currency(),
+			PrincipalDue:      principalDue,
+			InterestDue:       interest,
+			TotalDue:          totalDue,
+			PrincipalPaid:     zero,
+			InterestPaid:      zero,
+			ClosingPrincipal:  remaining,
+			Status:            domain.InstallmentStatusPending,
+		})
+	}
+
+	return installments, nil
+}
+
+// GenerateSchedule builds and stores the amortization schedule for an approved loan
+func (s *ScheduleService) GenerateSchedule(stub shim.ChaincodeStubInterface, loan *domain.LoanApplication, startDate time.Time) ([]domain.Installment, error) {
+	if loan.ApprovedAmount == nil || loan.InterestRate == nil {
+		return nil, fmt.Errorf("loan %s has no approved terms to schedule", loan.LoanID)
+	}
+
+	principal := *loan.ApprovedAmount
+
+	installments, err := BuildAmortizationSchedule(loan.LoanID, principal, *loan.InterestRate, loan.TermMonths, startDate)
+	if err != nil {
+		return nil, fmt.Errorf("failed to build repayment schedule: %w", err)
+	}
+
+	for i := range installments {
+		if err := s.SaveInstallment(stub, &installments[i]); err != nil {
+			return nil, err
+		}
+	}
+
+	return installments, nil
+}
+
+// GetSchedule returns a loan's installments in due order
+func (s *ScheduleService) GetSchedule(stub shim.ChaincodeStubInterface, loanID string) ([]domain.Installment, error) {
+	iterator, err := stub.GetStateByPartialCompositeKey("REPAYMENT_INSTALLMENT", []string{loanID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get repayment schedule: %w", err)
+	}
+	defer iterator.Close()
+
+	installments := []domain.Installment{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate installments: %w", err)
+		}
+
+		var installment domain.Installment
+		if err := json.Unmarshal(response.Value, &installment); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal installment: %w", err)
+		}
+
+		installments = append(installments, installment)
+	}
+
+	return installments, nil
+}
+
+// SaveInstallment stores an installment under its loan's schedule
+func (s *ScheduleService) SaveInstallment(stub shim.ChaincodeStubInterface, installment *domain.Installment) error {
+	// Zero-padded so range scans return installments in due order
+	installmentKey, err := stub.CreateCompositeKey("REPAYMENT_INSTALLMENT", []string{installment.LoanID, fmt.Sprintf("%04d", installment.InstallmentNumber)})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := s.persistenceService.Put(stub, installmentKey, installment); err != nil {
+		return fmt.Errorf("failed to store installment: %w", err)
+	}
+	return nil
+}
+
+// IsInstallmentOverdue reports whether an installment is past due and not fully paid
+func IsInstallmentOverdue(installment *domain.Installment, now time.Time) bool {
+	return installment.Status != domain.InstallmentStatusPaid && now.After(installment.DueDate)
+}
+
+// AssessDelinquency measures a loan's arrears from its installments. Days past due are counted in
+// calendar days from the oldest unpaid installment, so an installment due today is not yet past due.
+func AssessDelinquency(installments []domain.Installment, now time.Time) (domain.LoanDelinquency, error) {
+	assessment := domain.LoanDelinquency{AssessedDate: now}
+	var amountOverdue utils.Money
+	if len(installments) > 0 {
+		amountOverdue = utils.ZeroMoney(installments[0].Currency)
+	}
+	today := time.Date(now.Year(), now.Month(), now.Day(), 0, 0, 0, 0, time.UTC)
+	for i := range installments {
+		installment := &installments[i]
+		if !IsInstallmentOverdue(installment, now) {
+			continue
+		}
+		dueDay := time.Date(installment.DueDate.Year(), installment.DueDate.Month(), installment.DueDate.Day(), 0, 0, 0, 0, time.UTC)
+		daysPastDue := int(today.Sub(dueDay).Hours() / 24)
+		if daysPastDue <= 0 {
+			continue
+		}
+
+		assessment.OverdueInstallments++
+		var err error
+		if amountOverdue, err = amountOverdue.Add(installment.AmountOutstanding()); err != nil {
+			return domain.LoanDelinquency{}, err
+		}
+		if daysPastDue > assessment.DaysPastDue {
+			dueDate := installment.DueDate
+			assessment.DaysPastDue = daysPastDue
+			assessment.OldestDueDate = &dueDate
+		}
+	}
+	assessment.AmountOverdue = amountOverdue.Float64()
+	assessment.Bucket = domain.DelinquencyBucketFor(assessment.DaysPastDue)
+	return assessment, nil
+}
+
+// ComputeInterestPosition works out a loan's principal and interest position at the start of asOf's
+// day from its schedule. Interest of installments due by then is owed in full; the installment
+// falling due next accrues its scheduled interest pro rata over the calendar days of its period,
+// which runs from the previous due date or, for the first installment, from scheduleStart.
+func ComputeInterestPosition(installments []domain.Installment, scheduleStart, asOf time.Time) (domain.InterestPosition, error) {
+	position := domain.InterestPosition{}
+	currency := ""
+	if len(installments) > 0 {
+		currency = installments[0].Currency
+	}
+	principalOutstanding, interestDueUnpaid := utils.ZeroMoney(currency), utils.ZeroMoney(currency)
+	interestAccrued, perDiemInterest := utils.ZeroMoney(currency), utils.ZeroMoney(currency)
+
+	asOfDay := calendarDay(asOf)
+	periodStart := scheduleStart
+	accruing := false
+	var err error
+	for i := range installments {
+		installment := &installments[i]
+		if principalOutstanding, err = principalOutstanding.Add(installment.PrincipalOutstanding()); err != nil {
+			return domain.InterestPosition{}, err
+		}
+
+		dueDay := calendarDay(installment.DueDate)
+		switch {
+		case !dueDay.After(asOfDay):
+			if interestDueUnpaid, err = interestDueUnpaid.Add(installment.InterestOutstanding()); err != nil {
+				return domain.InterestPosition{}, err
+			}
+		case !accruing:
+			accruing = true
+			start, end := periodStart, installment.DueDate
+			position.PeriodStart, position.PeriodEnd = &start, &end
+
+			periodDays := calendarDays(periodStart, installment.DueDate)
+			if periodDays < 1 {
+				periodDays = 1
+			}
+			elapsed := calendarDays(periodStart, asOf)
+			if elapsed < 0 {
+				elapsed = 0
+			}
+			earned := installment.InterestDue.Mul(float64(elapsed) / float64(periodDays))
+			if interestAccrued, err = earned.Sub(installment.InterestPaid); err != nil {
+				return domain.InterestPosition{}, err
+			}
+			if interestAccrued.IsNegative() {
+				interestAccrued = utils.ZeroMoney(currency)
+			}
+			perDiemInterest = installment.InterestDue.Mul(1 / float64(periodDays))
+		}
+		periodStart = installment.DueDate
+	}
+
+	accruedInterest, err := interestDueUnpaid.Add(interestAccrued)
+	if err != nil {
+		return domain.InterestPosition{}, err
+	}
+	position.PrincipalOutstanding = principalOutstanding.Float64()
+	position.InterestDueUnpaid = interestDueUnpaid.Float64()
+	position.InterestAccrued = interestAccrued.Float64()
+	position.PerDiemInterest = perDiemInterest.Float64()
+	position.AccruedInterest = accruedInterest.Float64()
+	return position, nil
+}
+
+// calendarDay truncates a time to the start of its UTC day
+func calendarDay(t time.Time) time.Time {
+	t = t.UTC()
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, time.UTC)
+}
+
+// calendarDays counts the UTC calendar days from one time to another
+func calendarDays(from, to time.Time) int {
+	return int(math.Round(calendarDay(to).Sub(calendarDay(from)).Hours() / 24))
+}
@@ -0,0 +1,63 @@
+package services
+
+import (
+	"testing"
+	"time"
+
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/loan/domain"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+)
+
+func TestComputeInterestPosition(t *testing.T) {
+	start := time.Date(2026, 1, 15, 10, 0, 0, 0, time.UTC)
+	installments, err := BuildAmortizationSchedule("LOAN_001", utils.NewMoney(12000, "USD"), 12, 12, start)
+	if err != nil {
+		t.Fatalf("failed to build schedule: %v", err)
+	}
+
+	// Part way through the first period, interest accrues pro rata over its 31 days
+	position, err := ComputeInterestPosition(installments, start, time.Date(2026, 1, 25, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("failed to compute interest position: %v", err)
+	}
+	if position.PrincipalOutstanding != 12000 || position.InterestDueUnpaid != 0 {
+		t.Errorf("unexpected position before the first due date: %+v", position)
+	}
+	if position.InterestAccrued != 38.71 || position.AccruedInterest != 38.71 || position.PerDiemInterest != 3.87 {
+		t.Errorf("expected 10/31 of 120.00 accrued at 3.87 a day, got %+v", position)
+	}
+
+	// After a missed installment its interest is owed in full on top of the next period's accrual
+	asOf := time.Date(2026, 2, 20, 0, 0, 0, 0, time.UTC)
+	position, err = ComputeInterestPosition(installments, start, asOf)
+	if err != nil {
+		t.Fatalf("failed to compute interest position: %v", err)
+	}
+	if position.InterestDueUnpaid != 120 || position.InterestAccrued != 19.74 || position.AccruedInterest != 139.74 {
+		t.Errorf("expected 120.00 due and 5/28 of 110.54 accrued, got %+v", position)
+	}
+	if position.PeriodEnd == nil || !position.PeriodEnd.Equal(installments[1].DueDate) {
+		t.Errorf("expected the second installment to be accruing, got %+v", position.PeriodEnd)
+	}
+
+	// Paying the installment clears its interest and principal
+	installments[0].InterestPaid = installments[0].InterestDue
+	installments[0].PrincipalPaid = installments[0].PrincipalDue
+	installments[0].Status = domain.InstallmentStatusPaid
+	position, err = ComputeInterestPosition(installments, start, asOf)
+	if err != nil {
+		t.Fatalf("failed to compute interest position: %v", err)
+	}
+	if position.PrincipalOutstanding != 11053.81 || position.InterestDueUnpaid != 0 || position.AccruedInterest != 19.74 {
+		t.Errorf("unexpected position after paying the first installment: %+v", position)
+	}
+
+	// Once every installment is due nothing more accrues
+	position, err = ComputeInterestPosition(installments, start, time.Date(2027, 6, 1, 0, 0, 0, 0, time.UTC))
+	if err != nil {
+		t.Fatalf("failed to compute interest position: %v", err)
+	}
+	if position.PeriodEnd != nil || position.InterestAccrued != 0 || position.PerDiemInterest != 0 {
+		t.Errorf("expected no accrual after maturity, got %+v", position)
+	}
+}
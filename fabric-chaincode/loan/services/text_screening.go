@@ -0,0 +1,66 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/interfaces"
+)
+
+// TextScreeningService screens free-text fields against the compliance chaincode's prohibited
+// activity terms
+type TextScreeningService struct {
+	chaincodeName string
+}
+
+// NewTextScreeningService creates a new text screening service
+func NewTextScreeningService() *TextScreeningService {
+	return &TextScreeningService{
+		chaincodeName: config.ComplianceChaincodeName,
+	}
+}
+
+// Screen screens an entity's free-text fields. Matches are raised as a compliance event by the
+// compliance chaincode; callers decide how a flagged or blocked entity may proceed.
+func (s *TextScreeningService) Screen(stub shim.ChaincodeStubInterface, entityID, entityType string, fields map[string]string, actorID string) (*interfaces.TextScreeningResult, error) {
+	reqBytes, err := json.Marshal(interfaces.TextScreeningRequest{
+		EntityID:   entityID,
+		EntityType: entityType,
+		Fields:     fields,
+		ActorID:    actorID,
+	})
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal text screening request: %w", err)
+	}
+
+	response := stub.InvokeChaincode(s.chaincodeName, [][]byte{
+		[]byte("ScreenText"),
+		reqBytes,
+	}, "")
+	if response.Status != shim.OK {
+		return nil, fmt.Errorf("failed to screen %s %s with %s chaincode: %s", entityType, entityID, s.chaincodeName, response.Message)
+	}
+
+	var result interfaces.TextScreeningResult
+	if err := json.Unmarshal(response.Payload, &result); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal text screening result: %w", err)
+	}
+	return &result, nil
+}
+
+// ScreeningCategories returns the distinct categories of a screening result's matches, sorted
+func ScreeningCategories(result *interfaces.TextScreeningResult) []string {
+	seen := map[string]bool{}
+	categories := []string{}
+	for _, match := range result.Matches {
+		if !seen[match.Category] {
+			seen[match.Category] = true
+			categories = append(categories, match.Category)
+		}
+	}
+	sort.Strings(categories)
+	return categories
+}
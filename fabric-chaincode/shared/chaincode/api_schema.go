@@ -0,0 +1,351 @@
+package chaincode
+
+import (
+	"bytes"
+	"encoding"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// JSON types of published response schemas. A schema without a type accepts any value.
+const (
+	APITypeObject  = "object"
+	APITypeArray   = "array"
+	APITypeString  = "string"
+	APITypeNumber  = "number"
+	APITypeInteger = "integer"
+	APITypeBoolean = "boolean"
+)
+
+// String formats of published response schemas
+const (
+	APIFormatDateTime = "date-time" // RFC 3339 timestamp
+	APIFormatByte     = "byte"      // Base64 encoded bytes
+)
+
+var (
+	apiTimeType          = reflect.TypeOf(time.Time{})
+	apiRawMessageType    = reflect.TypeOf(json.RawMessage{})
+	apiJSONMarshalerType = reflect.TypeOf((*json.Marshaler)(nil)).Elem()
+	apiTextMarshalerType = reflect.TypeOf((*encoding.TextMarshaler)(nil)).Elem()
+)
+
+// APISchema is the subset of JSON Schema used to publish the shape of chaincode responses, so
+// clients can rely on it and contract tests can catch a struct change that breaks it. Objects
+// without additionalProperties accept only their documented properties.
+type APISchema struct {
+	Type                 string                `json:"type,omitempty"`
+	Format               string                `json:"format,omitempty"`
+	Nullable             bool                  `json:"nullable,omitempty"`
+	Properties           map[string]*APISchema `json:"properties,omitempty"`
+	Required             []string              `json:"required,omitempty"`
+	AdditionalProperties *APISchema            `json:"additionalProperties,omitempty"`
+	Items                *APISchema            `json:"items,omitempty"`
+}
+
+// APISchemaDocument is the published schema of every function of a chaincode, keyed by function
+type APISchemaDocument struct {
+	Chaincode string                `json:"chaincode"`
+	Functions map[string]*APISchema `json:"functions"`
+}
+
+// APICatalog maps each function of a chaincode to a value of its response type
+type APICatalog map[string]interface{}
+
+// Functions returns the catalogued functions in name order
+func (c APICatalog) Functions() []string {
+	functions := make([]string, 0, len(c))
+	for function := range c {
+		functions = append(functions, function)
+	}
+	sort.Strings(functions)
+	return functions
+}
+
+// Document generates the schema document of a chaincode's catalog. Fields the router may mask out
+// of a function's response are optional in its schema.
+func (c APICatalog) Document(chaincodeName string, router Router) *APISchemaDocument {
+	document := &APISchemaDocument{Chaincode: chaincodeName, Functions: map[string]*APISchema{}}
+	maskingRouter, _ := router.(MaskingRouter)
+
+	for function, response := range c {
+		schema := GenerateAPISchema(response)
+		if maskingRouter != nil {
+			if policies := maskingRouter.MaskingPolicy(function); policies != nil {
+				schema.relax(policies.Fields())
+			}
+		}
+		document.Functions[function] = schema
+	}
+	return document
+}
+
+// GenerateAPISchema generates the schema of a value's JSON encoding from its Go type
+func GenerateAPISchema(value interface{}) *APISchema {
+	return apiSchemaForType(reflect.TypeOf(value), map[reflect.Type]bool{})
+}
+
+// ValidateAPIResponse checks a response payload against a published schema and reports every
+// difference
+func ValidateAPIResponse(schema *APISchema, payload []byte) error {
+	var value interface{}
+	if len(payload) > 0 {
+		decoder := json.NewDecoder(bytes.NewReader(payload))
+		decoder.UseNumber()
+		if err := decoder.Decode(&value); err != nil {
+			return fmt.Errorf("failed to parse response: %w", err)
+		}
+	}
+
+	var violations []string
+	schema.validate("$", value, &violations)
+	if len(violations) > 0 {
+		return fmt.Errorf("response does not match the published schema: %s", strings.Join(violations, "; "))
+	}
+	return nil
+}
+
+func apiSchemaForType(t reflect.Type, visiting map[reflect.Type]bool) *APISchema {
+	if t == nil {
+		return &APISchema{}
+	}
+
+	nullable := false
+	for t.Kind() == reflect.Ptr {
+		t = t.Elem()
+		nullable = true
+	}
+	schema := apiSchemaForValueType(t, visiting)
+	if schema.Type != "" && nullable {
+		schema.Nullable = true
+	}
+	return schema
+}
+
+func apiSchemaForValueType(t reflect.Type, visiting map[reflect.Type]bool) *APISchema {
+	switch {
+	case t == apiTimeType:
+		return &APISchema{Type: APITypeString, Format: APIFormatDateTime}
+	case t == apiRawMessageType:
+		return &APISchema{}
+	case t.Implements(apiJSONMarshalerType) || reflect.PtrTo(t).Implements(apiJSONMarshalerType):
+		// Custom encodings cannot be derived from the type
+		return &APISchema{}
+	case t.Implements(apiTextMarshalerType) || reflect.PtrTo(t).Implements(apiTextMarshalerType):
+		return &APISchema{Type: APITypeString}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &APISchema{Type: APITypeBoolean}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return &APISchema{Type: APITypeInteger}
+	case reflect.Float32, reflect.Float64:
+		return &APISchema{Type: APITypeNumber}
+	case reflect.String:
+		return &APISchema{Type: APITypeString}
+	case reflect.Slice:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &APISchema{Type: APITypeString, Format: APIFormatByte, Nullable: true}
+		}
+		return &APISchema{Type: APITypeArray, Nullable: true, Items: apiSchemaForType(t.Elem(), visiting)}
+	case reflect.Array:
+		return &APISchema{Type: APITypeArray, Items: apiSchemaForType(t.Elem(), visiting)}
+	case reflect.Map:
+		return &APISchema{Type: APITypeObject, Nullable: true, AdditionalProperties: apiSchemaForType(t.Elem(), visiting)}
+	case reflect.Struct:
+		// A recursive type is documented once; its nested occurrences accept any value
+		if visiting[t] {
+			return &APISchema{}
+		}
+		visiting[t] = true
+		defer delete(visiting, t)
+
+		schema := &APISchema{Type: APITypeObject, Properties: map[string]*APISchema{}}
+		schema.addStructFields(t, visiting)
+		sort.Strings(schema.Required)
+		return schema
+	default:
+		return &APISchema{}
+	}
+}
+
+// addStructFields documents the fields of a struct as encoding/json encodes them, promoting the
+// fields of embedded structs
+func (s *APISchema) addStructFields(t reflect.Type, visiting map[reflect.Type]bool) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name, options := tag, ""
+		if comma := strings.Index(tag, ","); comma >= 0 {
+			name, options = tag[:comma], tag[comma+1:]
+		}
+
+		fieldType := field.Type
+		if fieldType.Kind() == reflect.Ptr {
+			fieldType = fieldType.Elem()
+		}
+		if field.Anonymous && name == "" && fieldType.Kind() == reflect.Struct {
+			s.addStructFields(fieldType, visiting)
+			continue
+		}
+		if field.PkgPath != "" {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+
+		property := apiSchemaForType(field.Type, visiting)
+		omitEmpty := false
+		for _, option := range strings.Split(options, ",") {
+			switch option {
+			case "omitempty":
+				omitEmpty = true
+			case "string":
+				property = &APISchema{Type: APITypeString}
+			}
+		}
+		s.Properties[name] = property
+
+		// omitempty never omits a struct value
+		if !omitEmpty || field.Type.Kind() == reflect.Struct {
+			s.Required = append(s.Required, name)
+		}
+	}
+}
+
+// relax makes the named properties optional at any depth
+func (s *APISchema) relax(fields []string) {
+	if s == nil {
+		return
+	}
+
+	optional := map[string]bool{}
+	for _, field := range fields {
+		optional[field] = true
+	}
+	required := []string{}
+	for _, name := range s.Required {
+		if !optional[name] {
+			required = append(required, name)
+		}
+	}
+	if len(s.Required) > 0 {
+		s.Required = required
+	}
+
+	for _, property := range s.Properties {
+		property.relax(fields)
+	}
+	s.AdditionalProperties.relax(fields)
+	s.Items.relax(fields)
+}
+
+func (s *APISchema) validate(path string, value interface{}, violations *[]string) {
+	if s.Type == "" {
+		return
+	}
+	if value == nil {
+		if !s.Nullable {
+			*violations = append(*violations, fmt.Sprintf("%s: expected %s, got null", path, s.Type))
+		}
+		return
+	}
+
+	switch s.Type {
+	case APITypeObject:
+		object, ok := value.(map[string]interface{})
+		if !ok {
+			break
+		}
+		for _, name := range s.Required {
+			if _, present := object[name]; !present {
+				*violations = append(*violations, fmt.Sprintf("%s.%s: required property is missing", path, name))
+			}
+		}
+		names := make([]string, 0, len(object))
+		for name := range object {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		for _, name := range names {
+			property, documented := s.Properties[name]
+			if !documented {
+				property = s.AdditionalProperties
+			}
+			if property == nil {
+				*violations = append(*violations, fmt.Sprintf("%s.%s: property is not in the published schema", path, name))
+				continue
+			}
+			property.validate(path+"."+name, object[name], violations)
+		}
+		return
+	case APITypeArray:
+		items, ok := value.([]interface{})
+		if !ok {
+			break
+		}
+		if s.Items != nil {
+			for i, item := range items {
+				s.Items.validate(fmt.Sprintf("%s[%d]", path, i), item, violations)
+			}
+		}
+		return
+	case APITypeString:
+		text, ok := value.(string)
+		if !ok {
+			break
+		}
+		switch s.Format {
+		case APIFormatDateTime:
+			if _, err := time.Parse(time.RFC3339Nano, text); err != nil {
+				*violations = append(*violations, fmt.Sprintf("%s: %q is not a %s", path, text, APIFormatDateTime))
+			}
+		case APIFormatByte:
+			if _, err := base64.StdEncoding.DecodeString(text); err != nil {
+				*violations = append(*violations, fmt.Sprintf("%s: value is not base64 encoded", path))
+			}
+		}
+		return
+	case APITypeInteger:
+		if number, ok := value.(json.Number); ok && !strings.ContainsAny(number.String(), ".eE") {
+			return
+		}
+	case APITypeNumber:
+		if _, ok := value.(json.Number); ok {
+			return
+		}
+	case APITypeBoolean:
+		if _, ok := value.(bool); ok {
+			return
+		}
+	}
+	*violations = append(*violations, fmt.Sprintf("%s: expected %s, got %s", path, s.Type, apiJSONTypeOf(value)))
+}
+
+func apiJSONTypeOf(value interface{}) string {
+	switch value.(type) {
+	case map[string]interface{}:
+		return APITypeObject
+	case []interface{}:
+		return APITypeArray
+	case string:
+		return APITypeString
+	case json.Number:
+		return APITypeNumber
+	case bool:
+		return APITypeBoolean
+	default:
+		return fmt.Sprintf("%T", value)
+	}
+}
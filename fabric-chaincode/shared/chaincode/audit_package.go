@@ -0,0 +1,123 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// AuditPackageRequest represents a request to export the audit trail of an entity or case
+type AuditPackageRequest struct {
+	SubjectID string `json:"subjectID"`
+	ActorID   string `json:"actorID"`
+}
+
+// AuditPackageHandler exports evidence packages whose manifests are recorded on the ledger, giving
+// a verifiable chain of custody for evidence handed to courts or regulators. Each chaincode
+// supplies the collectors for the evidence it holds.
+type AuditPackageHandler struct {
+	packageService *services.AuditPackageService
+	eventService   *services.BaseEventService
+	collectors     []services.AuditCollector
+}
+
+// NewAuditPackageHandler creates a new audit package handler collecting the given sections
+func NewAuditPackageHandler(collectors ...services.AuditCollector) *AuditPackageHandler {
+	return &AuditPackageHandler{
+		packageService: services.NewAuditPackageService(),
+		eventService:   services.NewBaseEventService(),
+		collectors:     collectors,
+	}
+}
+
+// ExportAuditPackage bundles every section of evidence held about an entity or case, records the
+// package manifest and returns the package. Only compliance officers and regulators may export.
+func (h *AuditPackageHandler) ExportAuditPackage(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req AuditPackageRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse audit package request: %v", err)
+	}
+	if strings.TrimSpace(req.SubjectID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "subjectID", "subjectID is required")
+	}
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return nil, err
+	}
+	switch validation.ActorRole(role) {
+	case validation.ActorRoleComplianceOfficer, validation.ActorRoleChiefComplianceOfficer, validation.ActorRoleRegulator:
+	default:
+		return nil, services.NewChaincodeError(services.ErrCodeAccessDenied, "", "audit packages may only be exported by a %s, %s or %s", validation.ActorRoleComplianceOfficer, validation.ActorRoleChiefComplianceOfficer, validation.ActorRoleRegulator)
+	}
+	mspID, err := services.InvokerMSPID(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg, err := h.packageService.Build(stub, req.SubjectID, req.ActorID, mspID, h.collectors)
+	if err != nil {
+		return nil, err
+	}
+
+	// The event carries the manifest only; the evidence itself goes to the requester
+	payload := h.eventService.CreateEventPayloadWithMetadata(
+		config.EventAuditPackageExported,
+		pkg.Manifest.PackageID,
+		"AuditPackage",
+		req.ActorID,
+		pkg.Manifest,
+		map[string]string{"subjectID": req.SubjectID, "manifestHash": pkg.Manifest.ManifestHash},
+	)
+	if err := h.eventService.EmitEvent(stub, config.EventAuditPackageExported, payload); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(pkg)
+}
+
+// GetAuditManifest retrieves the manifest recorded when an audit package was exported
+func (h *AuditPackageHandler) GetAuditManifest(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	manifest, err := h.packageService.GetManifest(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(manifest)
+}
+
+// VerifyAuditPackage checks an exported audit package against the manifest recorded on the
+// ledger and reports any section or manifest that no longer matches
+func (h *AuditPackageHandler) VerifyAuditPackage(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var pkg services.AuditPackage
+	if err := json.Unmarshal([]byte(args[0]), &pkg); err != nil {
+		return nil, fmt.Errorf("failed to parse audit package: %w", err)
+	}
+
+	verification, err := h.packageService.Verify(stub, &pkg)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(verification)
+}
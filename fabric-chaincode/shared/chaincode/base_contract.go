@@ -5,6 +5,7 @@ import (
 
 	"github.com/hyperledger/fabric-chaincode-go/shim"
 	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
 )
 
 // BaseContract provides common chaincode functionality
@@ -22,14 +23,77 @@ type Router interface {
 	Route(stub shim.ChaincodeStubInterface, function string, args []string) ([]byte, error)
 }
 
+// ErrorResponse returns a failed response whose message is the error's JSON envelope, see
+// services.ChaincodeError, so clients branch on its code rather than its wording
+func ErrorResponse(err error) peer.Response {
+	return shim.Error(services.ErrorEnvelope(err))
+}
+
+// GuardInvocation runs the checks every chaincode entry point makes before dispatching a function.
+// actors and entities locate the acting actor and the customer or loan acted on where the function
+// does not take them in the usual JSON fields; carriesPII keeps the arguments out of the archive.
+func GuardInvocation(stub shim.ChaincodeStubInterface, function string, args []string, actors ActorArguments, entities FreezeArguments, carriesPII bool) error {
+	// Reject functions switched off by operators before dispatching
+	if err := CheckFunctionEnabled(stub, function); err != nil {
+		return err
+	}
+	
+	// Reject requests acting as an actor the invoking identity is not bound to
+	if err := CheckActorBinding(stub, function, args, actors); err != nil {
+		return err
+	}
+	
+	// Reject changes to customers and loans compliance has frozen
+	if err := CheckEntityFreeze(stub, function, args, entities); err != nil {
+		return err
+	}
+	
+	// Reject high-privilege functions for actors whose credentials are overdue for rotation
+	if err := CheckCredentialRotation(stub, function, args, actors); err != nil {
+		return err
+	}
+	
+	// Reject partner requests without valid signature evidence from the gateway
+	if err := CheckRequestSignature(stub); err != nil {
+		return err
+	}
+	
+	// Archive what the gateway submitted so disputed transactions can be investigated
+	return ArchiveRequestPayload(stub, function, args, carriesPII)
+}
+
 // InvokeWithRouter handles chaincode invocations using a router
 func (bc *BaseContract) InvokeWithRouter(stub shim.ChaincodeStubInterface, router Router) peer.Response {
+	// Handlers share the invocation's state, such as the IDs generated so far, through its stub
+	stub = services.NewInvocation(stub)
 	function, args := stub.GetFunctionAndParameters()
 	
+	var actors ActorArguments
+	if actorRouter, ok := router.(ActorRouter); ok {
+		actors = actorRouter.ActorArguments()
+	}
+	var entities FreezeArguments
+	if freezeRouter, ok := router.(FreezeRouter); ok {
+		entities = freezeRouter.FreezeArguments()
+	}
+	
+	piiRouter, ok := router.(PIIRouter)
+	if err := GuardInvocation(stub, function, args, actors, entities, ok && piiRouter.CarriesPII(function)); err != nil {
+		return ErrorResponse(err)
+	}
+	
 	response, err := router.Route(stub, function, args)
 	if err != nil {
-		return shim.Error(fmt.Sprintf("Error invoking function %s: %v", function, err))
+		return ErrorResponse(err)
 	}
-	
+
+	// Mask the response for the invoker's role when the router has a policy for the function
+	if maskingRouter, ok := router.(MaskingRouter); ok {
+		response, err = MaskResponse(stub, maskingRouter.MaskingPolicy(function), response)
+		if err != nil {
+			return ErrorResponse(fmt.Errorf("failed to mask response of function %s: %w", function, err))
+		}
+	}
+
 	return shim.Success(response)
 }
\ No newline at end of file
@@ -0,0 +1,98 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// TimestampCutoverRequest represents a request to record the timestamp cutover
+type TimestampCutoverRequest struct {
+	ActorID string `json:"actorID"`
+}
+
+// CompatibilityHandler exposes the pre-upgrade state compatibility check and the timestamp
+// cutover that marks where records stopped carrying peer wall-clock time
+type CompatibilityHandler struct {
+	compatibilityService *services.StateCompatibilityService
+}
+
+// NewCompatibilityHandler creates a compatibility handler for a chaincode's schema registry
+func NewCompatibilityHandler(registry *services.SchemaRegistry) *CompatibilityHandler {
+	return &CompatibilityHandler{
+		compatibilityService: services.NewStateCompatibilityService(registry),
+	}
+}
+
+// ValidateStateCompatibility checks stored state against the registered schemas. An optional
+// namespace argument limits the check to a single namespace.
+func (h *CompatibilityHandler) ValidateStateCompatibility(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) > 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 0 or 1, got %d", len(args))
+	}
+
+	namespace := ""
+	if len(args) == 1 {
+		namespace = args[0]
+	}
+
+	report, err := h.compatibilityService.CheckState(stub, namespace)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(report)
+}
+
+// RecordTimestampCutover records the current transaction as the point from which the chaincode's
+// records carry transaction timestamps. It is invoked once, by a system administrator, after the
+// upgrade that introduced them.
+func (h *CompatibilityHandler) RecordTimestampCutover(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req TimestampCutoverRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse timestamp cutover request: %v", err)
+	}
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return nil, err
+	}
+	if role != string(validation.ActorRoleSystemAdministrator) {
+		return nil, services.NewChaincodeError(services.ErrCodeAccessDenied, "", "the timestamp cutover may only be recorded by a %s", validation.ActorRoleSystemAdministrator)
+	}
+
+	cutover, err := services.PutTimestampCutover(stub, req.ActorID)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(cutover)
+}
+
+// GetTimestampCutover retrieves the recorded timestamp cutover
+func (h *CompatibilityHandler) GetTimestampCutover(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 0, got %d", len(args))
+	}
+
+	cutover, err := services.GetTimestampCutover(stub)
+	if err != nil {
+		return nil, err
+	}
+	if cutover == nil {
+		return nil, fmt.Errorf("no timestamp cutover has been recorded")
+	}
+
+	return json.Marshal(cutover)
+}
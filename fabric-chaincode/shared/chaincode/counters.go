@@ -0,0 +1,82 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// CounterHandler reads and compacts the daily and monthly metric counters, available on every
+// chaincode
+type CounterHandler struct{}
+
+// NewCounterHandler creates a new counter handler
+func NewCounterHandler() *CounterHandler {
+	return &CounterHandler{}
+}
+
+// GetCounter returns a metric's total for a day or month as of the last compaction. Args:
+// metricKey, period (YYYY-MM-DD or YYYY-MM)
+func (h *CounterHandler) GetCounter(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 2, got %d", len(args))
+	}
+
+	metricKey, period := args[0], args[1]
+	if err := validateCounterArgs(metricKey, period); err != nil {
+		return nil, err
+	}
+	if period == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "period", "period is required")
+	}
+
+	counter, err := services.GetCounter(stub, metricKey, period)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(counter)
+}
+
+// CompactCounters folds the counts recorded since the last compaction into a metric's totals and
+// returns the totals it changed. Operators schedule it, e.g. hourly, for each metric. Args:
+// metricKey, optional period (YYYY-MM-DD or YYYY-MM; every period when omitted)
+func (h *CounterHandler) CompactCounters(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) < 1 || len(args) > 2 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1 or 2, got %d", len(args))
+	}
+
+	metricKey, period := args[0], ""
+	if len(args) == 2 {
+		period = args[1]
+	}
+	if err := validateCounterArgs(metricKey, period); err != nil {
+		return nil, err
+	}
+
+	counters, err := services.CompactCounters(stub, metricKey, period)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(counters)
+}
+
+// validateCounterArgs checks a metric key and a day or month period, which may be empty only
+// where the caller allows every period
+func validateCounterArgs(metricKey, period string) error {
+	if metricKey == "" {
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "metricKey", "metricKey is required")
+	}
+	if period == "" {
+		return nil
+	}
+	if _, err := time.Parse(services.CounterDayFormat, period); err != nil {
+		if _, err := time.Parse(services.CounterMonthFormat, period); err != nil {
+			return services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid period %s, expected YYYY-MM-DD or YYYY-MM", period)
+		}
+	}
+	return nil
+}
@@ -0,0 +1,212 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/interfaces"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+)
+
+// DataQualitySweepRequest represents a request to run the next batch of a namespace's daily sweep
+type DataQualitySweepRequest struct {
+	Namespace string `json:"namespace"`
+	BatchSize int    `json:"batchSize,omitempty"` // Defaults to and is capped at config.MaxDataQualityBatchSize
+	ActorID   string `json:"actorID"`
+}
+
+// RemediationTaskResolution represents a request to mark a remediation task as put right
+type RemediationTaskResolution struct {
+	Namespace string `json:"namespace"`
+	TaskID    string `json:"taskID"`
+	Notes     string `json:"notes"`
+	ActorID   string `json:"actorID"`
+}
+
+// RemediationTaskResult is one page of a namespace's remediation tasks
+type RemediationTaskResult struct {
+	Tasks    []services.RemediationTask `json:"tasks"`
+	Count    int                        `json:"count"`
+	Bookmark string                     `json:"bookmark"`
+}
+
+// DataQualityHandler sweeps a chaincode's records for broken invariants, scoring each namespace
+// and raising remediation tasks for the records that fail. Each chaincode supplies the rules for
+// the namespaces it owns.
+type DataQualityHandler struct {
+	qualityService *services.DataQualityService
+	eventService   *services.BaseEventService
+}
+
+// NewDataQualityHandler creates a new data quality handler checking the given rules, by namespace
+func NewDataQualityHandler(registry *services.SchemaRegistry, rules map[string][]services.DataQualityRule) *DataQualityHandler {
+	return &DataQualityHandler{
+		qualityService: services.NewDataQualityService(registry, rules),
+		eventService:   services.NewBaseEventService(),
+	}
+}
+
+// RunDataQualitySweep checks the next batch of a namespace's records and returns the day's report,
+// scored once the whole namespace has been swept. Call it until the report is COMPLETED.
+func (h *DataQualityHandler) RunDataQualitySweep(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req DataQualitySweepRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse data quality sweep request: %v", err)
+	}
+	if strings.TrimSpace(req.Namespace) == "" {
+		return nil, fmt.Errorf("namespace is required, one of: %s", strings.Join(h.qualityService.Namespaces(), ", "))
+	}
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	batchSize := req.BatchSize
+	if batchSize <= 0 || batchSize > config.MaxDataQualityBatchSize {
+		batchSize = config.MaxDataQualityBatchSize
+	}
+
+	report, err := h.qualityService.Sweep(stub, req.Namespace, batchSize, req.ActorID)
+	if err != nil {
+		return nil, err
+	}
+
+	// Emit event
+	if report.Status == services.DataQualitySweepCompleted {
+		payload := h.eventService.CreateEventPayloadWithMetadata(
+			config.EventDataQualitySweepCompleted,
+			report.Namespace,
+			"DataQualityReport",
+			req.ActorID,
+			report,
+			map[string]string{
+				"sweepDate":     report.SweepDate,
+				"score":         strconv.FormatFloat(report.Score, 'f', 2, 64),
+				"recordsFailed": strconv.Itoa(report.RecordsFailed),
+			},
+		)
+		if err := h.emit(stub, config.EventDataQualitySweepCompleted, payload); err != nil {
+			return nil, err
+		}
+	}
+
+	return json.Marshal(report)
+}
+
+// GetDataQualityReport returns a namespace's sweep report for a day, completed or in progress.
+// Args: namespace, sweepDate (YYYY-MM-DD)
+func (h *DataQualityHandler) GetDataQualityReport(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 2, got %d", len(args))
+	}
+	if _, err := time.Parse(services.DataQualitySweepDateFormat, args[1]); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid sweep date %s, expected YYYY-MM-DD", args[1])
+	}
+
+	report, err := h.qualityService.GetReport(stub, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+	if report == nil {
+		return nil, fmt.Errorf("no data quality sweep of %s for %s", args[0], args[1])
+	}
+
+	return json.Marshal(report)
+}
+
+// GetRemediationTasks returns a page of a namespace's remediation tasks.
+// Args: namespace [, pageSize [, bookmark]]
+func (h *DataQualityHandler) GetRemediationTasks(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) < 1 || len(args) > 3 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1 to 3, got %d", len(args))
+	}
+
+	pageSize, bookmark, err := services.ParsePageArgs(args[1:])
+	if err != nil {
+		return nil, err
+	}
+	tasks, nextBookmark, err := h.qualityService.GetTasksPage(stub, args[0], pageSize, bookmark)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get remediation tasks: %w", err)
+	}
+
+	return json.Marshal(&RemediationTaskResult{Tasks: tasks, Count: len(tasks), Bookmark: nextBookmark})
+}
+
+// ResolveRemediationTask marks an open remediation task as put right. A later sweep reopens it if
+// the record still breaks the rule.
+func (h *DataQualityHandler) ResolveRemediationTask(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req RemediationTaskResolution
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, fmt.Errorf("failed to parse remediation task resolution: %w", err)
+	}
+	if strings.TrimSpace(req.Notes) == "" {
+		return nil, fmt.Errorf("notes are required to resolve a remediation task")
+	}
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	task, err := h.qualityService.GetTask(stub, req.Namespace, req.TaskID)
+	if err != nil {
+		return nil, err
+	}
+	if task.Status != services.RemediationTaskOpen {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "remediation task %s is already %s", task.TaskID, task.Status)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	task.Status = services.RemediationTaskResolved
+	task.ResolvedBy = req.ActorID
+	task.ResolvedDate = &now
+	task.ResolutionNotes = req.Notes
+
+	if err := h.qualityService.PutTask(stub, task); err != nil {
+		return nil, err
+	}
+
+	// Emit event
+	payload := h.eventService.CreateEventPayloadWithMetadata(
+		config.EventRemediationTaskResolved,
+		task.EntityID,
+		task.EntityType,
+		req.ActorID,
+		task,
+		map[string]string{"taskID": task.TaskID, "rule": task.Rule},
+	)
+	if err := h.emit(stub, config.EventRemediationTaskResolved, payload); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(task)
+}
+
+// emit stamps an event with the transaction time and emits it
+func (h *DataQualityHandler) emit(stub shim.ChaincodeStubInterface, eventName string, payload interfaces.EventPayload) error {
+	txTime, err := services.TxTime(stub)
+	if err != nil {
+		return err
+	}
+	payload.Timestamp = utils.FormatTime(txTime)
+
+	if err := h.eventService.EmitEvent(stub, eventName, payload); err != nil {
+		return fmt.Errorf("failed to emit event: %w", err)
+	}
+	return nil
+}
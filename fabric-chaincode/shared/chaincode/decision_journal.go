@@ -0,0 +1,190 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// DecisionRecordRequest represents a request to journal a sensitive decision
+type DecisionRecordRequest struct {
+	DecisionType string `json:"decisionType"`
+	EntityID     string `json:"entityID"`
+	EntityType   string `json:"entityType"`
+	Decision     string `json:"decision"`
+	Rationale    string `json:"rationale"`
+	ActorID      string `json:"actorID"`
+}
+
+// DecisionCosignRequest represents a regulator co-signature on a journaled decision
+type DecisionCosignRequest struct {
+	EntityID string `json:"entityID"`
+	EntryID  string `json:"entryID"`
+	Comment  string `json:"comment"`
+	ActorID  string `json:"actorID"`
+}
+
+// DecisionJournalHandler handles the append-only compliance decision journal
+type DecisionJournalHandler struct {
+	journalService *services.DecisionJournalService
+	eventService   *services.BaseEventService
+}
+
+// NewDecisionJournalHandler creates a new decision journal handler
+func NewDecisionJournalHandler() *DecisionJournalHandler {
+	return &DecisionJournalHandler{
+		journalService: services.NewDecisionJournalService(),
+		eventService:   services.NewBaseEventService(),
+	}
+}
+
+// RecordDecision journals a screening clearance, SAR filing or write-off submitted by the bank.
+// The entry stays pending until the regulator co-signs it.
+func (h *DecisionJournalHandler) RecordDecision(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req DecisionRecordRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse decision record request: %v", err)
+	}
+
+	if err := validation.ValidateJournalDecisionType(req.DecisionType); err != nil {
+		return nil, fmt.Errorf("invalid decision type: %w", err)
+	}
+	if strings.TrimSpace(req.EntityID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "entityID", "entityID is required")
+	}
+	if strings.TrimSpace(req.Decision) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "decision", "decision is required")
+	}
+	if strings.TrimSpace(req.Rationale) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "rationale", "rationale is required")
+	}
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	mspID, err := services.InvokerMSPID(stub)
+	if err != nil {
+		return nil, err
+	}
+	if mspID != config.BankMSPID {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "decisions must be recorded by %s, invoker is %s", config.BankMSPID, mspID)
+	}
+
+	// IDs and dates come from the transaction so every endorsing peer writes the same entry
+	recordedDate, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	entry := &services.DecisionJournalEntry{
+		EntryID:       stub.GetTxID(),
+		DecisionType:  req.DecisionType,
+		EntityID:      req.EntityID,
+		EntityType:    req.EntityType,
+		Decision:      req.Decision,
+		Rationale:     req.Rationale,
+		Status:        services.JournalEntryPendingCosign,
+		RecordedBy:    req.ActorID,
+		RecordedByMSP: mspID,
+		RecordedDate:  recordedDate,
+		RecordedTxID:  stub.GetTxID(),
+	}
+
+	if err := h.journalService.CreateEntry(stub, entry); err != nil {
+		return nil, fmt.Errorf("failed to store decision journal entry: %w", err)
+	}
+
+	if err := h.emitJournalEvent(stub, config.EventDecisionRecorded, entry, req.ActorID); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(entry)
+}
+
+// CosignDecision adds the regulator co-signature to a pending journal entry. The entry's key-level
+// policy means this write only commits when endorsed by peers of both organisations.
+func (h *DecisionJournalHandler) CosignDecision(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req DecisionCosignRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse decision cosign request: %v", err)
+	}
+
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	mspID, err := services.InvokerMSPID(stub)
+	if err != nil {
+		return nil, err
+	}
+	if mspID != config.RegulatorMSPID {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "decisions must be co-signed by %s, invoker is %s", config.RegulatorMSPID, mspID)
+	}
+
+	entry, err := h.journalService.CosignEntry(stub, req.EntityID, req.EntryID, req.ActorID, req.Comment)
+	if err != nil {
+		return nil, err
+	}
+
+	if err := h.emitJournalEvent(stub, config.EventDecisionCosigned, entry, req.ActorID); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(entry)
+}
+
+// GetDecisionJournal returns every journal entry recorded against an entity
+func (h *DecisionJournalHandler) GetDecisionJournal(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	entries, err := h.journalService.GetEntries(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(entries)
+}
+
+func (h *DecisionJournalHandler) emitJournalEvent(stub shim.ChaincodeStubInterface, eventName string, entry *services.DecisionJournalEntry, actorID string) error {
+	metadata := map[string]string{
+		"decisionType": entry.DecisionType,
+		"entryID":      entry.EntryID,
+		"status":       entry.Status,
+	}
+	payload := h.eventService.CreateEventPayloadWithMetadata(
+		eventName,
+		entry.EntityID,
+		entry.EntityType,
+		actorID,
+		entry,
+		metadata,
+	)
+
+	// Events are part of the proposal response, so they must match across both organisations' peers
+	txTime, err := services.TxTime(stub)
+	if err != nil {
+		return err
+	}
+	payload.Timestamp = utils.FormatTime(txTime)
+
+	if err := h.eventService.EmitEvent(stub, eventName, payload); err != nil {
+		return fmt.Errorf("failed to emit event: %w", err)
+	}
+	return nil
+}
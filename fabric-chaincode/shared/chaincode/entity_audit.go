@@ -0,0 +1,101 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// EntityAuditHandler exports the full chronological history of an entity, from the ledger history
+// of its records and the change history handlers recorded, with a hash chain over the entries so
+// auditors can verify nothing was omitted or reordered. Each chaincode names the keys of the
+// records it holds for an entity.
+type EntityAuditHandler struct {
+	auditService     *services.EntityAuditService
+	recordKeyFormats []string
+}
+
+// NewEntityAuditHandler creates a new entity audit handler exporting the history of the records
+// at the given key formats, e.g. "CUSTOMER_%s"
+func NewEntityAuditHandler(recordKeyFormats ...string) *EntityAuditHandler {
+	return &EntityAuditHandler{
+		auditService:     services.NewEntityAuditService(),
+		recordKeyFormats: recordKeyFormats,
+	}
+}
+
+// ExportEntityAudit returns an entity's full history with its hash chain. Only compliance
+// officers and regulators may export.
+func (h *EntityAuditHandler) ExportEntityAudit(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+	if strings.TrimSpace(args[0]) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "entityID", "entityID is required")
+	}
+	if err := requireEntityAuditor(stub); err != nil {
+		return nil, err
+	}
+	mspID, err := services.InvokerMSPID(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	audit, err := h.auditService.Export(stub, args[0], h.recordKeys(args[0]), mspID)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(audit)
+}
+
+// VerifyEntityAudit checks an exported entity history's hash chain against the ledger and reports
+// any entry that was altered, reordered or omitted
+func (h *EntityAuditHandler) VerifyEntityAudit(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+	if err := requireEntityAuditor(stub); err != nil {
+		return nil, err
+	}
+
+	var audit services.EntityAudit
+	if err := json.Unmarshal([]byte(args[0]), &audit); err != nil {
+		return nil, fmt.Errorf("failed to parse entity audit: %w", err)
+	}
+	if strings.TrimSpace(audit.EntityID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "entityID", "entityID is required")
+	}
+
+	verification, err := h.auditService.Verify(stub, &audit, h.recordKeys(audit.EntityID))
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(verification)
+}
+
+func (h *EntityAuditHandler) recordKeys(entityID string) []string {
+	keys := make([]string, 0, len(h.recordKeyFormats))
+	for _, format := range h.recordKeyFormats {
+		keys = append(keys, fmt.Sprintf(format, entityID))
+	}
+	return keys
+}
+
+// requireEntityAuditor allows compliance officers and regulators to read an entity's full history
+func requireEntityAuditor(stub shim.ChaincodeStubInterface) error {
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return err
+	}
+	switch validation.ActorRole(role) {
+	case validation.ActorRoleComplianceOfficer, validation.ActorRoleChiefComplianceOfficer, validation.ActorRoleRegulator:
+		return nil
+	}
+	return services.NewChaincodeError(services.ErrCodeAccessDenied, "", "entity audits may only be exported by a %s, %s or %s", validation.ActorRoleComplianceOfficer, validation.ActorRoleChiefComplianceOfficer, validation.ActorRoleRegulator)
+}
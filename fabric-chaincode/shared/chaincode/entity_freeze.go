@@ -0,0 +1,375 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// EntityFreezeRequest represents a request to freeze an entity, or to request or approve lifting
+// its freeze. The entity is named by customerID on the customer chaincode and by loanID on the
+// loan chaincode, as in the functions the freeze blocks.
+type EntityFreezeRequest struct {
+	CustomerID    string                    `json:"customerID,omitempty"`
+	LoanID        string                    `json:"loanID,omitempty"`
+	ReasonCode    services.FreezeReasonCode `json:"reasonCode,omitempty"` // Required to freeze
+	Reason        string                    `json:"reason"`
+	CaseReference string                    `json:"caseReference,omitempty"`
+	ActorID       string                    `json:"actorID"`
+}
+
+// EntityFreezeHandler handles emergency freezes compliance places on one type of entity. Each
+// chaincode freezes the entity it holds, whose record is stored at recordKeyFormat.
+type EntityFreezeHandler struct {
+	freezeService      *services.EntityFreezeService
+	persistenceService *services.PersistenceService
+	eventService       *services.BaseEventService
+	entityType         string
+	recordKeyFormat    string
+}
+
+// NewEntityFreezeHandler creates a new entity freeze handler for the entities of a type stored at
+// the given key format, e.g. "CUSTOMER_%s"
+func NewEntityFreezeHandler(entityType, recordKeyFormat string) *EntityFreezeHandler {
+	return &EntityFreezeHandler{
+		freezeService:      services.NewEntityFreezeService(),
+		persistenceService: services.NewPersistenceService(),
+		eventService:       services.NewBaseEventService(),
+		entityType:         entityType,
+		recordKeyFormat:    recordKeyFormat,
+	}
+}
+
+// Freeze places a freeze on an entity, blocking every state-changing function on it except
+// compliance actions until it is unfrozen. Only compliance officers may freeze. Routed as
+// FreezeCustomer or FreezeLoan.
+func (h *EntityFreezeHandler) Freeze(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	req, entityID, err := h.parseRequest(stub, args)
+	if err != nil {
+		return nil, err
+	}
+	if !services.ValidFreezeReasonCodes[req.ReasonCode] {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "invalid reasonCode: %s", req.ReasonCode)
+	}
+
+	exists, err := h.persistenceService.Exists(stub, fmt.Sprintf(h.recordKeyFormat, entityID))
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, services.NewChaincodeError(services.ErrCodeNotFound, "", "%s %s not found", h.entityType, entityID)
+	}
+
+	existing, err := h.freezeService.GetFreeze(stub, h.entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil && existing.Active() {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "%s %s is already frozen", h.entityType, entityID)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	freeze := &services.EntityFreeze{
+		EntityID:      entityID,
+		EntityType:    h.entityType,
+		Status:        services.FreezeStatusActive,
+		ReasonCode:    req.ReasonCode,
+		Reason:        req.Reason,
+		CaseReference: req.CaseReference,
+		FrozenBy:      req.ActorID,
+		FrozenDate:    now,
+		TxID:          stub.GetTxID(),
+	}
+	if err := h.freezeService.PutFreeze(stub, freeze); err != nil {
+		return nil, err
+	}
+
+	if err := h.emitFreezeEvent(stub, config.EventEntityFrozen, freeze, req.Reason, req.ActorID); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(freeze)
+}
+
+// RequestUnfreeze asks for an entity's freeze to be lifted. The freeze stays in force until a
+// second compliance officer approves the request.
+func (h *EntityFreezeHandler) RequestUnfreeze(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	req, entityID, err := h.parseRequest(stub, args)
+	if err != nil {
+		return nil, err
+	}
+
+	freeze, err := h.activeFreeze(stub, entityID)
+	if err != nil {
+		return nil, err
+	}
+	if freeze.UnfreezeRequest != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "unfreeze of %s %s is already requested by %s", h.entityType, entityID, freeze.UnfreezeRequest.RequestedBy)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	freeze.UnfreezeRequest = &services.UnfreezeRequest{
+		RequestedBy:   req.ActorID,
+		Reason:        req.Reason,
+		RequestedDate: now,
+	}
+	if err := h.freezeService.PutFreeze(stub, freeze); err != nil {
+		return nil, err
+	}
+
+	if err := h.emitFreezeEvent(stub, config.EventEntityUnfreezeRequested, freeze, req.Reason, req.ActorID); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(freeze)
+}
+
+// ApproveUnfreeze lifts an entity's freeze on a pending unfreeze request. The approver must be a
+// compliance officer other than the one who requested it.
+func (h *EntityFreezeHandler) ApproveUnfreeze(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	req, entityID, err := h.parseRequest(stub, args)
+	if err != nil {
+		return nil, err
+	}
+
+	freeze, err := h.activeFreeze(stub, entityID)
+	if err != nil {
+		return nil, err
+	}
+	if freeze.UnfreezeRequest == nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "no unfreeze of %s %s has been requested", h.entityType, entityID)
+	}
+	if freeze.UnfreezeRequest.RequestedBy == req.ActorID {
+		return nil, services.NewChaincodeError(services.ErrCodeAccessDenied, "", "unfreeze may only be approved by a compliance officer other than the requester")
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	freeze.Status = services.FreezeStatusReleased
+	freeze.ReleasedBy = req.ActorID
+	freeze.ReleasedDate = &now
+	freeze.ReleaseReason = req.Reason
+	if err := h.freezeService.PutFreeze(stub, freeze); err != nil {
+		return nil, err
+	}
+
+	if err := h.emitFreezeEvent(stub, config.EventEntityUnfrozen, freeze, req.Reason, req.ActorID); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(freeze)
+}
+
+// GetEntityFreeze returns the latest freeze on an entity, active or released. Args: entityID
+func (h *EntityFreezeHandler) GetEntityFreeze(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	freeze, err := h.freezeService.GetFreeze(stub, h.entityType, args[0])
+	if err != nil {
+		return nil, err
+	}
+	if freeze == nil {
+		return nil, fmt.Errorf("no freeze found for %s %s", h.entityType, args[0])
+	}
+
+	return json.Marshal(freeze)
+}
+
+// FreezeArgument locates the customer or loan a function acts on when its request does not name it
+// in the customerID or loanID field of a JSON first argument: the argument at Index itself or, when
+// Field is set, that field of the JSON request at Index. An argument with no Entity marks a
+// function that takes positional arguments but acts on no single customer or loan.
+type FreezeArgument struct {
+	Entity string // services.FreezeEntityCustomer or services.FreezeEntityLoan
+	Index  int
+	Field  string
+}
+
+// FreezeArguments gives the FreezeArgument of each function naming its entity elsewhere than the
+// customerID or loanID field of a JSON request
+type FreezeArguments map[string]FreezeArgument
+
+// FreezeExempt returns FreezeArguments exempting each of the functions from entity freezes, for
+// chaincodes whose state-changing functions are compliance actions that go on during a freeze
+func FreezeExempt(functions ...string) FreezeArguments {
+	entities := FreezeArguments{}
+	for _, function := range functions {
+		entities[function] = FreezeArgument{}
+	}
+	return entities
+}
+
+// FreezeRouter is implemented by routers with functions listed in FreezeArguments
+type FreezeRouter interface {
+	FreezeArguments() FreezeArguments
+}
+
+// entityID returns the entity named by the argument, failing when the invocation does not carry it
+func (a FreezeArgument) entityID(function string, args []string) (string, error) {
+	if a.Index >= len(args) {
+		return "", fmt.Errorf("function %s takes its %s as argument %d, got %d arguments", function, a.Entity, a.Index, len(args))
+	}
+	if a.Field == "" {
+		return strings.TrimSpace(args[a.Index]), nil
+	}
+
+	var req map[string]interface{}
+	if err := json.Unmarshal([]byte(args[a.Index]), &req); err != nil {
+		return "", fmt.Errorf("function %s takes its %s in field %s of a JSON request: %w", function, a.Entity, a.Field, err)
+	}
+	entityID, _ := req[a.Field].(string)
+	return strings.TrimSpace(entityID), nil
+}
+
+// CheckEntityFreeze rejects state-changing functions acting on a frozen customer or loan. Queries
+// and the compliance actions in config.FreezeExemptFunctions are never blocked. The entity is taken
+// from where entities lists it for the function, else from the customerID and loanID of a JSON
+// request; any other invocation is rejected rather than let through unchecked.
+func CheckEntityFreeze(stub shim.ChaincodeStubInterface, function string, args []string, entities FreezeArguments) error {
+	if config.FreezeExemptFunctions[function] || isQueryFunction(function) || len(args) == 0 {
+		return nil
+	}
+
+	freezeService := services.NewEntityFreezeService()
+	if arg, ok := entities[function]; ok {
+		if arg.Entity == "" {
+			return nil
+		}
+		entityID, err := arg.entityID(function, args)
+		if err != nil {
+			return services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "%s", err.Error())
+		}
+		if entityID == "" {
+			return nil
+		}
+		return freezeService.CheckNotFrozen(stub, arg.Entity, entityID)
+	}
+
+	var req struct {
+		CustomerID string `json:"customerID"`
+		LoanID     string `json:"loanID"`
+	}
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "function %s does not take a JSON request naming its customer or loan, so entity freezes cannot be checked", function)
+	}
+
+	if req.CustomerID != "" {
+		if err := freezeService.CheckNotFrozen(stub, services.FreezeEntityCustomer, req.CustomerID); err != nil {
+			return err
+		}
+	}
+	if req.LoanID != "" {
+		if err := freezeService.CheckNotFrozen(stub, services.FreezeEntityLoan, req.LoanID); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// isQueryFunction reports whether a function only reads the ledger, going by the naming of the
+// chaincode functions
+func isQueryFunction(function string) bool {
+	for _, prefix := range []string{"Get", "Query", "Search"} {
+		if strings.HasPrefix(function, prefix) {
+			return true
+		}
+	}
+	return false
+}
+
+// parseRequest parses a freeze workflow request and checks the invoker is a compliance officer
+// who gave a reason. It returns the request and the ID of the entity it names.
+func (h *EntityFreezeHandler) parseRequest(stub shim.ChaincodeStubInterface, args []string) (*EntityFreezeRequest, string, error) {
+	if len(args) != 1 {
+		return nil, "", services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req EntityFreezeRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, "", services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse freeze request: %v", err)
+	}
+
+	entityID, field := req.LoanID, "loanID"
+	if h.entityType == services.FreezeEntityCustomer {
+		entityID, field = req.CustomerID, "customerID"
+	}
+	if strings.TrimSpace(entityID) == "" {
+		return nil, "", services.NewChaincodeError(services.ErrCodeInvalidArgument, field, "%s is required", field)
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, "", services.NewChaincodeError(services.ErrCodeInvalidArgument, "reason", "reason is required")
+	}
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, "", services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return nil, "", err
+	}
+	if role != string(validation.ActorRoleComplianceOfficer) {
+		return nil, "", services.NewChaincodeError(services.ErrCodeAccessDenied, "", "freezes may only be managed by a %s", validation.ActorRoleComplianceOfficer)
+	}
+
+	return &req, entityID, nil
+}
+
+// activeFreeze returns an entity's freeze, failing when it is not frozen
+func (h *EntityFreezeHandler) activeFreeze(stub shim.ChaincodeStubInterface, entityID string) (*services.EntityFreeze, error) {
+	freeze, err := h.freezeService.GetFreeze(stub, h.entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+	if freeze == nil || !freeze.Active() {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "%s %s is not frozen", h.entityType, entityID)
+	}
+	return freeze, nil
+}
+
+// emitFreezeEvent announces a change to a freeze so downstream systems can hold or resume work on
+// the entity
+func (h *EntityFreezeHandler) emitFreezeEvent(stub shim.ChaincodeStubInterface, eventName string, freeze *services.EntityFreeze, reason, actorID string) error {
+	metadata := map[string]string{
+		"status":     string(freeze.Status),
+		"reasonCode": string(freeze.ReasonCode),
+		"reason":     reason,
+	}
+	if freeze.CaseReference != "" {
+		metadata["caseReference"] = freeze.CaseReference
+	}
+	payload := h.eventService.CreateEventPayloadWithMetadata(
+		eventName,
+		freeze.EntityID,
+		freeze.EntityType,
+		actorID,
+		freeze,
+		metadata,
+	)
+
+	txTime, err := services.TxTime(stub)
+	if err != nil {
+		return err
+	}
+	payload.Timestamp = utils.FormatTime(txTime)
+
+	if err := h.eventService.EmitEvent(stub, eventName, payload); err != nil {
+		return fmt.Errorf("failed to emit event: %w", err)
+	}
+	return nil
+}
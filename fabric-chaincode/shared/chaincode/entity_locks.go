@@ -0,0 +1,249 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// lockSupervisorRoles may take over or release claims other actors still hold
+var lockSupervisorRoles = map[string]bool{
+	string(validation.ActorRoleCreditOfficer):         true,
+	string(validation.ActorRoleLoanOperationsManager): true,
+}
+
+// EntityClaimRequest represents a request to claim an entity before working on it
+type EntityClaimRequest struct {
+	EntityID   string `json:"entityID"`
+	EntityType string `json:"entityType"`
+	TTLMinutes int    `json:"ttlMinutes,omitempty"` // Defaults to config.DefaultEntityLockTTL
+	Override   bool   `json:"override,omitempty"`   // Take over another actor's live claim; supervisors only
+	Reason     string `json:"reason,omitempty"`     // Required with override
+	ActorID    string `json:"actorID"`
+}
+
+// EntityReleaseRequest represents a request to give up a claim on an entity
+type EntityReleaseRequest struct {
+	EntityID   string `json:"entityID"`
+	EntityType string `json:"entityType"`
+	Override   bool   `json:"override,omitempty"` // Release another actor's live claim; supervisors only
+	Reason     string `json:"reason,omitempty"`   // Required with override
+	ActorID    string `json:"actorID"`
+}
+
+// EntityLockHandler handles advisory claims that stop two actors working the same entity at once
+type EntityLockHandler struct {
+	lockService  *services.EntityLockService
+	eventService *services.BaseEventService
+}
+
+// NewEntityLockHandler creates a new entity lock handler
+func NewEntityLockHandler() *EntityLockHandler {
+	return &EntityLockHandler{
+		lockService:  services.NewEntityLockService(),
+		eventService: services.NewBaseEventService(),
+	}
+}
+
+// ClaimEntity claims an entity for the actor until the claim expires. The holder may re-claim to
+// extend it; a live claim held by someone else may only be taken over by a supervisor.
+func (h *EntityLockHandler) ClaimEntity(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req EntityClaimRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse claim request: %v", err)
+	}
+	if err := validateLockTarget(req.EntityType, req.EntityID, req.ActorID); err != nil {
+		return nil, err
+	}
+
+	ttl := config.DefaultEntityLockTTL
+	if req.TTLMinutes < 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "ttlMinutes cannot be negative")
+	}
+	if req.TTLMinutes > 0 {
+		ttl = time.Duration(req.TTLMinutes) * time.Minute
+	}
+	if ttl > config.MaxEntityLockTTL {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "ttlMinutes exceeds the %d minute maximum", int(config.MaxEntityLockTTL/time.Minute))
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	existing, err := h.lockService.GetLock(stub, req.EntityType, req.EntityID)
+	if err != nil {
+		return nil, err
+	}
+
+	lock := &services.EntityLock{
+		EntityID:    req.EntityID,
+		EntityType:  req.EntityType,
+		HolderID:    req.ActorID,
+		ClaimedDate: now,
+		ExpiresDate: now.Add(ttl),
+		TxID:        stub.GetTxID(),
+	}
+	if existing != nil && existing.Live(now) && existing.HolderID != req.ActorID {
+		if !req.Override {
+			return nil, services.NewChaincodeError(services.ErrCodeConflict, "", "%s %s is claimed by %s until %s", req.EntityType, req.EntityID, existing.HolderID, existing.ExpiresDate.Format(time.RFC3339))
+		}
+		if err := checkLockOverride(stub, req.Reason); err != nil {
+			return nil, err
+		}
+		lock.OverriddenHolderID = existing.HolderID
+		lock.OverrideReason = req.Reason
+	}
+
+	if err := h.lockService.PutLock(stub, lock); err != nil {
+		return nil, err
+	}
+
+	if err := h.emitLockEvent(stub, config.EventEntityClaimed, lock, lock.OverriddenHolderID, req.Reason, req.ActorID); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(lock)
+}
+
+// ReleaseEntity gives up a claim. Holders release their own claims and anyone may clear an expired
+// one; a live claim held by someone else may only be released by a supervisor.
+func (h *EntityLockHandler) ReleaseEntity(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req EntityReleaseRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse release request: %v", err)
+	}
+	if err := validateLockTarget(req.EntityType, req.EntityID, req.ActorID); err != nil {
+		return nil, err
+	}
+
+	lock, err := h.lockService.GetLock(stub, req.EntityType, req.EntityID)
+	if err != nil {
+		return nil, err
+	}
+	if lock == nil {
+		return nil, services.NewChaincodeError(services.ErrCodeNotFound, "", "%s %s is not claimed", req.EntityType, req.EntityID)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	overriddenHolderID := ""
+	if lock.Live(now) && lock.HolderID != req.ActorID {
+		if !req.Override {
+			return nil, services.NewChaincodeError(services.ErrCodeConflict, "", "%s %s is claimed by %s until %s", req.EntityType, req.EntityID, lock.HolderID, lock.ExpiresDate.Format(time.RFC3339))
+		}
+		if err := checkLockOverride(stub, req.Reason); err != nil {
+			return nil, err
+		}
+		overriddenHolderID = lock.HolderID
+	}
+
+	if err := h.lockService.DeleteLock(stub, req.EntityType, req.EntityID); err != nil {
+		return nil, err
+	}
+
+	if err := h.emitLockEvent(stub, config.EventEntityReleased, lock, overriddenHolderID, req.Reason, req.ActorID); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(lock)
+}
+
+// GetEntityLock returns the live claim on an entity. Args: entityType, entityID
+func (h *EntityLockHandler) GetEntityLock(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 2, got %d", len(args))
+	}
+
+	lock, err := h.lockService.GetLock(stub, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	if lock == nil || !lock.Live(now) {
+		return nil, services.NewChaincodeError(services.ErrCodeNotFound, "", "%s %s is not claimed", args[0], args[1])
+	}
+
+	return json.Marshal(lock)
+}
+
+// emitLockEvent announces a claim or release so task queues can refresh
+func (h *EntityLockHandler) emitLockEvent(stub shim.ChaincodeStubInterface, eventName string, lock *services.EntityLock, overriddenHolderID, reason, actorID string) error {
+	metadata := map[string]string{
+		"holderID":    lock.HolderID,
+		"expiresDate": utils.FormatTime(lock.ExpiresDate),
+	}
+	if overriddenHolderID != "" {
+		metadata["overriddenHolderID"] = overriddenHolderID
+		metadata["overrideReason"] = reason
+	}
+	payload := h.eventService.CreateEventPayloadWithMetadata(
+		eventName,
+		lock.EntityID,
+		lock.EntityType,
+		actorID,
+		lock,
+		metadata,
+	)
+
+	txTime, err := services.TxTime(stub)
+	if err != nil {
+		return err
+	}
+	payload.Timestamp = utils.FormatTime(txTime)
+
+	if err := h.eventService.EmitEvent(stub, eventName, payload); err != nil {
+		return fmt.Errorf("failed to emit event: %w", err)
+	}
+	return nil
+}
+
+// checkLockOverride checks the invoker may override another actor's claim and gave a reason
+func checkLockOverride(stub shim.ChaincodeStubInterface, reason string) error {
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return err
+	}
+	if !lockSupervisorRoles[role] {
+		return services.NewChaincodeError(services.ErrCodeAccessDenied, "", "claims held by others may only be overridden by a %s or %s", validation.ActorRoleCreditOfficer, validation.ActorRoleLoanOperationsManager)
+	}
+	if strings.TrimSpace(reason) == "" {
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "reason", "reason is required to override a claim")
+	}
+	return nil
+}
+
+func validateLockTarget(entityType, entityID, actorID string) error {
+	if strings.TrimSpace(entityType) == "" {
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "entityType", "entityType is required")
+	}
+	if strings.TrimSpace(entityID) == "" {
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "entityID", "entityID is required")
+	}
+	if strings.TrimSpace(actorID) == "" {
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+	return nil
+}
@@ -0,0 +1,258 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// maxNoteLength bounds note text so commentary doesn't grow into document storage
+const maxNoteLength = 4000
+
+// EntityNoteRequest represents a request to leave an operational note on an entity
+type EntityNoteRequest struct {
+	EntityID   string `json:"entityID"`
+	EntityType string `json:"entityType"`
+	Text       string `json:"text"`
+	Visibility string `json:"visibility"`
+	ActorID    string `json:"actorID"`
+}
+
+// EntityNoteEditRequest represents a request to change the text of an existing note
+type EntityNoteEditRequest struct {
+	EntityID string `json:"entityID"`
+	NoteID   string `json:"noteID"`
+	Text     string `json:"text"`
+	ActorID  string `json:"actorID"`
+}
+
+// EntityNotePageResult is one page of the notes on an entity visible to the invoker
+type EntityNotePageResult struct {
+	Notes    []services.EntityNote `json:"notes"`
+	Count    int                   `json:"count"`
+	Bookmark string                `json:"bookmark"`
+}
+
+// EntityNoteHandler handles staff notes on customers, loans and other entities
+type EntityNoteHandler struct {
+	noteService  *services.EntityNoteService
+	eventService *services.BaseEventService
+}
+
+// NewEntityNoteHandler creates a new entity note handler
+func NewEntityNoteHandler() *EntityNoteHandler {
+	return &EntityNoteHandler{
+		noteService:  services.NewEntityNoteService(),
+		eventService: services.NewBaseEventService(),
+	}
+}
+
+// AddNote records a note on an entity. Compliance-only notes may only be written by compliance
+// officers, since only they and the regulator can read them back.
+func (h *EntityNoteHandler) AddNote(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req EntityNoteRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse note request: %v", err)
+	}
+
+	if strings.TrimSpace(req.EntityID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "entityID", "entityID is required")
+	}
+	if strings.TrimSpace(req.EntityType) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "entityType", "entityType is required")
+	}
+	if err := validateNoteText(req.Text); err != nil {
+		return nil, err
+	}
+	if err := validation.ValidateNoteVisibility(req.Visibility); err != nil {
+		return nil, fmt.Errorf("invalid visibility: %w", err)
+	}
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	if req.Visibility == string(validation.NoteVisibilityComplianceOnly) {
+		role, err := services.InvokerRole(stub)
+		if err != nil {
+			return nil, err
+		}
+		switch validation.ActorRole(role) {
+		case validation.ActorRoleComplianceOfficer, validation.ActorRoleChiefComplianceOfficer:
+		default:
+			return nil, services.NewChaincodeError(services.ErrCodeAccessDenied, "", "compliance-only notes may only be written by a %s or %s", validation.ActorRoleComplianceOfficer, validation.ActorRoleChiefComplianceOfficer)
+		}
+	}
+
+	note := &services.EntityNote{
+		EntityID:   req.EntityID,
+		EntityType: req.EntityType,
+		Text:       req.Text,
+		Visibility: req.Visibility,
+		AuthorID:   req.ActorID,
+	}
+	if err := h.noteService.AddNote(stub, note); err != nil {
+		return nil, fmt.Errorf("failed to store note: %w", err)
+	}
+
+	if err := h.emitNoteEvent(stub, config.EventEntityNoteAdded, note, req.ActorID); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(note)
+}
+
+// EditNote changes the text of a note. Only the author may edit a note, and the visibility
+// chosen when it was written cannot be changed; earlier text stays in the revision history.
+func (h *EntityNoteHandler) EditNote(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req EntityNoteEditRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse note edit request: %v", err)
+	}
+
+	if err := validateNoteText(req.Text); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	note, err := h.noteService.GetNote(stub, req.EntityID, req.NoteID)
+	if err != nil {
+		return nil, err
+	}
+	if note.AuthorID != req.ActorID {
+		return nil, services.NewChaincodeError(services.ErrCodeAccessDenied, "", "note %s may only be edited by its author %s", note.NoteID, note.AuthorID)
+	}
+	if note.Text == req.Text {
+		return nil, fmt.Errorf("note text is unchanged")
+	}
+
+	if err := h.noteService.EditNote(stub, note, req.Text, req.ActorID); err != nil {
+		return nil, fmt.Errorf("failed to update note: %w", err)
+	}
+
+	if err := h.emitNoteEvent(stub, config.EventEntityNoteEdited, note, req.ActorID); err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(note)
+}
+
+// GetEntityNotes returns a page of the notes on an entity the invoker may read, oldest first.
+// Args: entityID [, pageSize [, bookmark]]
+func (h *EntityNoteHandler) GetEntityNotes(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) < 1 || len(args) > 3 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1 to 3, got %d", len(args))
+	}
+
+	pageSize, bookmark, err := services.ParsePageArgs(args[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	scope, err := invokerNoteScope(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	notes, nextBookmark, err := h.noteService.GetNotesPage(stub, args[0], scope, pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(&EntityNotePageResult{Notes: notes, Count: len(notes), Bookmark: nextBookmark})
+}
+
+// GetNoteHistory returns every revision of a note's text, oldest first
+func (h *EntityNoteHandler) GetNoteHistory(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 2, got %d", len(args))
+	}
+
+	note, err := h.noteService.GetNote(stub, args[0], args[1])
+	if err != nil {
+		return nil, err
+	}
+
+	scope, err := invokerNoteScope(stub)
+	if err != nil {
+		return nil, err
+	}
+	if note.Visibility == string(validation.NoteVisibilityComplianceOnly) && scope != string(validation.NoteVisibilityComplianceOnly) {
+		return nil, services.NewChaincodeError(services.ErrCodeNotFound, "", "note not found: %s", args[1])
+	}
+
+	revisions, err := h.noteService.GetRevisions(stub, note.NoteID)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(revisions)
+}
+
+// emitNoteEvent announces a note without its text. Events are readable by every listener on
+// the channel, so the text itself stays behind the visibility check in the query functions.
+func (h *EntityNoteHandler) emitNoteEvent(stub shim.ChaincodeStubInterface, eventName string, note *services.EntityNote, actorID string) error {
+	metadata := map[string]string{
+		"noteID":     note.NoteID,
+		"visibility": note.Visibility,
+		"revision":   fmt.Sprintf("%d", note.Revision),
+	}
+	payload := h.eventService.CreateEventPayloadWithMetadata(
+		eventName,
+		note.EntityID,
+		note.EntityType,
+		actorID,
+		metadata,
+		metadata,
+	)
+
+	txTime, err := services.TxTime(stub)
+	if err != nil {
+		return err
+	}
+	payload.Timestamp = utils.FormatTime(txTime)
+
+	if err := h.eventService.EmitEvent(stub, eventName, payload); err != nil {
+		return fmt.Errorf("failed to emit event: %w", err)
+	}
+	return nil
+}
+
+// invokerNoteScope maps the invoker's role to the note scope they may read
+func invokerNoteScope(stub shim.ChaincodeStubInterface) (string, error) {
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return "", err
+	}
+	switch validation.ActorRole(role) {
+	case validation.ActorRoleComplianceOfficer, validation.ActorRoleChiefComplianceOfficer, validation.ActorRoleRegulator:
+		return string(validation.NoteVisibilityComplianceOnly), nil
+	default:
+		return string(validation.NoteVisibilityInternal), nil
+	}
+}
+
+func validateNoteText(text string) error {
+	if strings.TrimSpace(text) == "" {
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "text", "text is required")
+	}
+	if len(text) > maxNoteLength {
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "text exceeds %d characters", maxNoteLength)
+	}
+	return nil
+}
@@ -0,0 +1,130 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// Kill switch administration functions, available on every chaincode
+const (
+	FunctionSetFunctionFlag  = "SetFunctionFlag"
+	FunctionGetFunctionFlags = "GetFunctionFlags"
+)
+
+// FunctionFlagRequest represents a request to disable or restore a function
+type FunctionFlagRequest struct {
+	FunctionName        string     `json:"functionName"`
+	Disabled            bool       `json:"disabled"`
+	OperatorMessage     string     `json:"operatorMessage"`
+	ExpectedRestoration *time.Time `json:"expectedRestoration,omitempty"`
+	ActorID             string     `json:"actorID"`
+}
+
+// FunctionFlagHandler handles kill switch administration
+type FunctionFlagHandler struct {
+	flagService  *services.FunctionFlagService
+	eventService *services.BaseEventService
+}
+
+// NewFunctionFlagHandler creates a new function flag handler
+func NewFunctionFlagHandler() *FunctionFlagHandler {
+	return &FunctionFlagHandler{
+		flagService:  services.NewFunctionFlagService(),
+		eventService: services.NewBaseEventService(),
+	}
+}
+
+// SetFunctionFlag disables or restores a chaincode function
+func (h *FunctionFlagHandler) SetFunctionFlag(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req FunctionFlagRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse function flag request: %v", err)
+	}
+
+	if strings.TrimSpace(req.FunctionName) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "functionName", "functionName is required")
+	}
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+	if isFunctionFlagAdmin(req.FunctionName) {
+		return nil, fmt.Errorf("function %s cannot be disabled", req.FunctionName)
+	}
+	if req.Disabled && strings.TrimSpace(req.OperatorMessage) == "" {
+		return nil, fmt.Errorf("operatorMessage is required when disabling a function")
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	flag := &services.FunctionFlag{
+		FunctionName:        req.FunctionName,
+		Disabled:            req.Disabled,
+		OperatorMessage:     req.OperatorMessage,
+		ExpectedRestoration: req.ExpectedRestoration,
+		LastUpdated:         now,
+		LastUpdatedBy:       req.ActorID,
+	}
+
+	if err := h.flagService.PutFunctionFlag(stub, flag); err != nil {
+		return nil, fmt.Errorf("failed to store function flag: %w", err)
+	}
+
+	metadata := map[string]string{
+		"disabled": fmt.Sprintf("%t", flag.Disabled),
+	}
+	payload := h.eventService.CreateEventPayloadWithMetadata(
+		config.EventFunctionFlagUpdated,
+		flag.FunctionName,
+		"FunctionFlag",
+		req.ActorID,
+		flag,
+		metadata,
+	)
+	if err := h.eventService.EmitEvent(stub, config.EventFunctionFlagUpdated, payload); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(flag)
+}
+
+// GetFunctionFlags returns all function flags stored for the chaincode
+func (h *FunctionFlagHandler) GetFunctionFlags(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 0, got %d", len(args))
+	}
+
+	flags, err := h.flagService.GetFunctionFlags(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(flags)
+}
+
+// CheckFunctionEnabled rejects functions switched off by operators; the kill
+// switch administration functions themselves are always allowed
+func CheckFunctionEnabled(stub shim.ChaincodeStubInterface, function string) error {
+	if isFunctionFlagAdmin(function) {
+		return nil
+	}
+	return services.NewFunctionFlagService().CheckFunctionEnabled(stub, function)
+}
+
+// isFunctionFlagAdmin reports whether the function administers kill switches,
+// which must stay reachable so operators can restore service
+func isFunctionFlagAdmin(function string) bool {
+	return function == FunctionSetFunctionFlag || function == FunctionGetFunctionFlags
+}
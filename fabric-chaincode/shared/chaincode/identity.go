@@ -0,0 +1,358 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// Actor registry functions, available on every chaincode
+const (
+	FunctionRegisterActor = "RegisterActor"
+)
+
+// ActorRegistrationRequest represents a request to register or update an actor's identity binding
+type ActorRegistrationRequest struct {
+	RegisteredActorID  string `json:"registeredActorID"`
+	BlockchainIdentity string `json:"blockchainIdentity"` // As returned by GetInvokerIdentity for the actor's certificate
+	MSPID              string `json:"mspID"`
+	Role               string `json:"role"`
+	Active             bool   `json:"active"`
+	ActorID            string `json:"actorID"`
+}
+
+// CredentialRotationRequest represents an attestation that an actor's credentials were rotated
+type CredentialRotationRequest struct {
+	RotatedActorID string    `json:"rotatedActorID"`
+	RotationDate   time.Time `json:"rotationDate"`
+	Method         string    `json:"method"`
+	Notes          string    `json:"notes,omitempty"`
+	ActorID        string    `json:"actorID"`
+}
+
+// InvokerIdentity describes the identity that submitted a transaction
+type InvokerIdentity struct {
+	BlockchainIdentity string `json:"blockchainIdentity"`
+	MSPID              string `json:"mspID"`
+	Role               string `json:"role"`
+}
+
+// IdentityHandler handles actor registration and identity lookup
+type IdentityHandler struct {
+	identityService *services.IdentityService
+	eventService    *services.BaseEventService
+}
+
+// NewIdentityHandler creates a new identity handler
+func NewIdentityHandler() *IdentityHandler {
+	return &IdentityHandler{
+		identityService: services.NewIdentityService(),
+		eventService:    services.NewBaseEventService(),
+	}
+}
+
+// RegisterActor binds an actor ID to a blockchain identity. Only identities carrying the
+// System_Administrator role attribute may register actors.
+func (h *IdentityHandler) RegisterActor(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req ActorRegistrationRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse actor registration request: %v", err)
+	}
+
+	if strings.TrimSpace(req.RegisteredActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "registeredActorID", "registeredActorID is required")
+	}
+	if strings.TrimSpace(req.BlockchainIdentity) == "" || strings.TrimSpace(req.MSPID) == "" {
+		return nil, fmt.Errorf("blockchainIdentity and mspID are required")
+	}
+	if err := validation.ValidateActorRole(req.Role); err != nil {
+		return nil, fmt.Errorf("invalid role: %w", err)
+	}
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return nil, err
+	}
+	if role != string(validation.ActorRoleSystemAdministrator) {
+		return nil, services.NewChaincodeError(services.ErrCodeAccessDenied, "", "actors may only be registered by a %s", validation.ActorRoleSystemAdministrator)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	actor := &services.ActorIdentity{
+		ActorID:            req.RegisteredActorID,
+		BlockchainIdentity: req.BlockchainIdentity,
+		MSPID:              req.MSPID,
+		Role:               req.Role,
+		Active:             req.Active,
+		LastUpdated:        now,
+		LastUpdatedBy:      req.ActorID,
+	}
+
+	if err := h.identityService.PutActor(stub, actor); err != nil {
+		return nil, fmt.Errorf("failed to store actor: %w", err)
+	}
+
+	metadata := map[string]string{
+		"mspID":  actor.MSPID,
+		"role":   actor.Role,
+		"active": fmt.Sprintf("%t", actor.Active),
+	}
+	payload := h.eventService.CreateEventPayloadWithMetadata(
+		config.EventActorRegistered,
+		actor.ActorID,
+		"Actor",
+		req.ActorID,
+		actor,
+		metadata,
+	)
+	if err := h.eventService.EmitEvent(stub, config.EventActorRegistered, payload); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(actor)
+}
+
+// GetActor returns an actor's identity binding
+func (h *IdentityHandler) GetActor(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	actor, err := h.identityService.GetActor(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(actor)
+}
+
+// AttestCredentialRotation records that an actor's off-chain credentials were rotated. Only
+// identities carrying the System_Administrator role attribute may attest, and never for themselves.
+func (h *IdentityHandler) AttestCredentialRotation(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req CredentialRotationRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse credential rotation request: %v", err)
+	}
+
+	if strings.TrimSpace(req.RotatedActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "rotatedActorID", "rotatedActorID is required")
+	}
+	if err := validation.ValidateCredentialRotationMethod(req.Method); err != nil {
+		return nil, fmt.Errorf("invalid method: %w", err)
+	}
+	if req.RotationDate.IsZero() {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "rotationDate", "rotationDate is required")
+	}
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	if req.RotationDate.After(now) {
+		return nil, fmt.Errorf("rotationDate cannot be in the future")
+	}
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+	if req.ActorID == req.RotatedActorID {
+		return nil, fmt.Errorf("actors may not attest their own credential rotation")
+	}
+
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return nil, err
+	}
+	if role != string(validation.ActorRoleSystemAdministrator) {
+		return nil, services.NewChaincodeError(services.ErrCodeAccessDenied, "", "credential rotations may only be attested by a %s", validation.ActorRoleSystemAdministrator)
+	}
+
+	if _, err := h.identityService.GetActor(stub, req.RotatedActorID); err != nil {
+		return nil, err
+	}
+	if previous, err := h.identityService.GetCredentialRotation(stub, req.RotatedActorID); err == nil && !req.RotationDate.After(previous.RotationDate) {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "rotationDate must be after the previously attested rotation on %s", previous.RotationDate.Format(time.RFC3339))
+	}
+
+	rotation := &services.CredentialRotation{
+		ActorID:      req.RotatedActorID,
+		RotationDate: req.RotationDate,
+		Method:       req.Method,
+		AttestedBy:   req.ActorID,
+		AttestedDate: now,
+		Notes:        req.Notes,
+	}
+
+	if err := h.identityService.PutCredentialRotation(stub, rotation); err != nil {
+		return nil, fmt.Errorf("failed to store credential rotation: %w", err)
+	}
+
+	metadata := map[string]string{
+		"method":       rotation.Method,
+		"rotationDate": rotation.RotationDate.Format(time.RFC3339),
+	}
+	payload := h.eventService.CreateEventPayloadWithMetadata(
+		config.EventCredentialRotationAttested,
+		rotation.ActorID,
+		"Actor",
+		req.ActorID,
+		rotation,
+		metadata,
+	)
+	if err := h.eventService.EmitEvent(stub, config.EventCredentialRotationAttested, payload); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(rotation)
+}
+
+// GetCredentialRotation returns the latest credential rotation attested for an actor
+func (h *IdentityHandler) GetCredentialRotation(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	rotation, err := h.identityService.GetCredentialRotation(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(rotation)
+}
+
+// GetInvokerIdentity returns the caller's own blockchain identity, MSP and role, as needed to
+// register it against an actor
+func (h *IdentityHandler) GetInvokerIdentity(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 0, got %d", len(args))
+	}
+
+	id, err := services.InvokerID(stub)
+	if err != nil {
+		return nil, err
+	}
+	mspID, err := services.InvokerMSPID(stub)
+	if err != nil {
+		return nil, err
+	}
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(&InvokerIdentity{BlockchainIdentity: id, MSPID: mspID, Role: role})
+}
+
+// ActorArgument locates the acting actor of a function that does not name it in the actorID field
+// of a JSON first argument: the argument at Index itself or, when Field is set, that field of the
+// JSON request at Index
+type ActorArgument struct {
+	Index int
+	Field string
+}
+
+// ActorArguments gives the ActorArgument of each function taking its actor elsewhere than the
+// actorID field of a JSON request
+type ActorArguments map[string]ActorArgument
+
+// ActorRouter is implemented by routers with functions listed in ActorArguments
+type ActorRouter interface {
+	ActorArguments() ActorArguments
+}
+
+// actorID returns the actor named by the argument, failing when the invocation does not carry it
+func (a ActorArgument) actorID(function string, args []string) (string, error) {
+	if a.Index >= len(args) {
+		return "", fmt.Errorf("function %s takes its actor as argument %d, got %d arguments", function, a.Index, len(args))
+	}
+	if a.Field == "" {
+		return strings.TrimSpace(args[a.Index]), nil
+	}
+
+	var req map[string]interface{}
+	if err := json.Unmarshal([]byte(args[a.Index]), &req); err != nil {
+		return "", fmt.Errorf("function %s takes its actor in field %s of a JSON request: %w", function, a.Field, err)
+	}
+	actorID, _ := req[a.Field].(string)
+	return strings.TrimSpace(actorID), nil
+}
+
+// resolveActorID returns the actor an invocation acts as. Functions listed in actors must carry it
+// where listed and fail otherwise; others are checked when their JSON request names an actorID.
+func resolveActorID(function string, args []string, actors ActorArguments) (string, bool, error) {
+	if arg, ok := actors[function]; ok {
+		actorID, err := arg.actorID(function, args)
+		return actorID, true, err
+	}
+	actorID, ok := requestActorID(args)
+	return actorID, ok, nil
+}
+
+// CheckActorBinding rejects invocations acting as an actor that is not registered to the invoking
+// identity. The actor is taken from where actors lists it for the function, else from the actorID
+// of a JSON request; invocations of listed functions that do not carry their actor are rejected.
+// RegisterActor is exempt so administrators, gated by role attribute, can bootstrap the registry.
+func CheckActorBinding(stub shim.ChaincodeStubInterface, function string, args []string, actors ActorArguments) error {
+	if function == FunctionRegisterActor {
+		return nil
+	}
+
+	actorID, ok, err := resolveActorID(function, args, actors)
+	if err != nil || !ok {
+		return err
+	}
+	return services.NewIdentityService().VerifyActor(stub, actorID)
+}
+
+// CheckCredentialRotation rejects high-privilege functions acting as an actor with credentials
+// older than config.CredentialMaxAge. The actor is resolved as for CheckActorBinding.
+func CheckCredentialRotation(stub shim.ChaincodeStubInterface, function string, args []string, actors ActorArguments) error {
+	actorID, ok, err := resolveActorID(function, args, actors)
+	if err != nil || !ok {
+		return err
+	}
+	return CheckActorCredentialRotation(stub, function, actorID)
+}
+
+// CheckActorCredentialRotation rejects high-privilege functions invoked as an actor whose
+// credentials are overdue for rotation
+func CheckActorCredentialRotation(stub shim.ChaincodeStubInterface, function, actorID string) error {
+	if !config.HighPrivilegeFunctions[function] {
+		return nil
+	}
+	return services.NewIdentityService().CheckCredentialAge(stub, actorID, config.CredentialMaxAge)
+}
+
+// requestActorID returns the actorID of a JSON request argument, if it carries one
+func requestActorID(args []string) (string, bool) {
+	if len(args) == 0 {
+		return "", false
+	}
+
+	var req struct {
+		ActorID *string `json:"actorID"`
+	}
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil || req.ActorID == nil {
+		return "", false
+	}
+	return *req.ActorID, true
+}
@@ -0,0 +1,24 @@
+package chaincode
+
+import (
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/masking"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// MaskingRouter is implemented by routers that mask the responses of some functions for the
+// invoker's role. MaskingPolicy returns nil for functions whose responses are not masked.
+type MaskingRouter interface {
+	MaskingPolicy(function string) *masking.PolicySet
+}
+
+// MaskResponse applies the policy for the invoking identity's role to a response. An identity whose
+// role cannot be read gets the set's fallback policy, so responses fail closed to the least detail.
+func MaskResponse(stub shim.ChaincodeStubInterface, policies *masking.PolicySet, response []byte) ([]byte, error) {
+	if policies == nil {
+		return response, nil
+	}
+
+	role, _ := services.InvokerRole(stub)
+	return policies.ForRole(role).Apply(response)
+}
@@ -0,0 +1,150 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// PayloadArchivePolicyRequest represents a request to flag or unflag a function for full payload retention
+type PayloadArchivePolicyRequest struct {
+	FunctionName  string `json:"functionName"`
+	RetainPayload bool   `json:"retainPayload"`
+	ActorID       string `json:"actorID"`
+}
+
+// PayloadArchiveHandler handles the archive of submitted request payloads used to investigate disputes
+type PayloadArchiveHandler struct {
+	archiveService *services.PayloadArchiveService
+	eventService   *services.BaseEventService
+}
+
+// NewPayloadArchiveHandler creates a new payload archive handler
+func NewPayloadArchiveHandler() *PayloadArchiveHandler {
+	return &PayloadArchiveHandler{
+		archiveService: services.NewPayloadArchiveService(),
+		eventService:   services.NewBaseEventService(),
+	}
+}
+
+// SetPayloadArchivePolicy flags or unflags a function for full payload retention. Unflagged
+// functions still have the hash of every request archived, as do flagged functions carrying PII.
+func (h *PayloadArchiveHandler) SetPayloadArchivePolicy(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req PayloadArchivePolicyRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse payload archive policy request: %v", err)
+	}
+
+	if strings.TrimSpace(req.FunctionName) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "functionName", "functionName is required")
+	}
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return nil, err
+	}
+	if role != string(validation.ActorRoleChiefComplianceOfficer) && role != string(validation.ActorRoleSystemAdministrator) {
+		return nil, services.NewChaincodeError(services.ErrCodeAccessDenied, "", "payload archive policies may only be set by a %s or %s", validation.ActorRoleChiefComplianceOfficer, validation.ActorRoleSystemAdministrator)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	policy := &services.PayloadArchivePolicy{
+		FunctionName:  req.FunctionName,
+		RetainPayload: req.RetainPayload,
+		LastUpdated:   now,
+		LastUpdatedBy: req.ActorID,
+	}
+
+	if err := h.archiveService.PutPolicy(stub, policy); err != nil {
+		return nil, fmt.Errorf("failed to store payload archive policy: %w", err)
+	}
+
+	metadata := map[string]string{
+		"retainPayload": fmt.Sprintf("%t", policy.RetainPayload),
+	}
+	payload := h.eventService.CreateEventPayloadWithMetadata(
+		config.EventPayloadArchivePolicyUpdated,
+		policy.FunctionName,
+		"PayloadArchivePolicy",
+		req.ActorID,
+		policy,
+		metadata,
+	)
+	if err := h.eventService.EmitEvent(stub, config.EventPayloadArchivePolicyUpdated, payload); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(policy)
+}
+
+// GetPayloadArchivePolicies returns the functions flagged for full payload retention
+func (h *PayloadArchiveHandler) GetPayloadArchivePolicies(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 0, got %d", len(args))
+	}
+
+	policies, err := h.archiveService.GetPolicies(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(policies)
+}
+
+// GetArchivedPayload returns what was submitted in a transaction. Archived requests carry customer
+// data, so only compliance investigators and the regulator may retrieve them.
+func (h *PayloadArchiveHandler) GetArchivedPayload(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return nil, err
+	}
+	switch validation.ActorRole(role) {
+	case validation.ActorRoleComplianceOfficer, validation.ActorRoleChiefComplianceOfficer, validation.ActorRoleRegulator:
+	default:
+		return nil, services.NewChaincodeError(services.ErrCodeAccessDenied, "", "archived payloads may only be retrieved by a %s, %s or %s", validation.ActorRoleComplianceOfficer, validation.ActorRoleChiefComplianceOfficer, validation.ActorRoleRegulator)
+	}
+
+	archived, err := h.archiveService.GetArchivedPayload(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(archived)
+}
+
+// PIIRouter is implemented by routers with functions whose arguments carry customer PII. The
+// arguments of those functions are never retained in the payload archive, only their hash.
+type PIIRouter interface {
+	CarriesPII(function string) bool
+}
+
+// ArchiveRequestPayload archives the hash of the transaction's request, and the request itself for
+// flagged functions whose arguments carry no PII. Call it after the pre-dispatch checks: a failed
+// transaction is never committed, so only requests that were actually processed remain archived.
+func ArchiveRequestPayload(stub shim.ChaincodeStubInterface, function string, args []string, carriesPII bool) error {
+	actorID, _ := requestActorID(args)
+	if err := services.NewPayloadArchiveService().Archive(stub, function, args, actorID, carriesPII); err != nil {
+		return fmt.Errorf("failed to archive request payload: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,360 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// QASamplingRateRequest represents a request to set the share of a decision type sampled for review
+type QASamplingRateRequest struct {
+	DecisionType string  `json:"decisionType"`
+	Rate         float64 `json:"rate"` // 0-1; 0 stops sampling
+	ActorID      string  `json:"actorID"`
+}
+
+// QAReviewRequest represents a reviewer's findings on a sampled decision
+type QAReviewRequest struct {
+	ItemID      string   `json:"itemID"`
+	Outcome     string   `json:"outcome"`
+	DefectCodes []string `json:"defectCodes,omitempty"` // Required when the outcome is DEFECT
+	Findings    string   `json:"findings"`
+	ActorID     string   `json:"actorID"`
+}
+
+// QAReviewQueueResult is one page of decisions awaiting quality review
+type QAReviewQueueResult struct {
+	Items    []services.QAReviewItem `json:"items"`
+	Count    int                     `json:"count"`
+	Bookmark string                  `json:"bookmark"`
+}
+
+// QADefectStats summarises quality review results for one team or decision type
+type QADefectStats struct {
+	Sampled     int            `json:"sampled"`
+	Reviewed    int            `json:"reviewed"`
+	Defects     int            `json:"defects"`
+	DefectRate  float64        `json:"defectRate"` // Percent of reviewed decisions found defective
+	DefectCodes map[string]int `json:"defectCodes"`
+}
+
+// QADefectReport reports quality review defect rates per team and per decision type
+type QADefectReport struct {
+	FromDate       string                    `json:"fromDate"`
+	ToDate         string                    `json:"toDate"`
+	Total          *QADefectStats            `json:"total"`
+	ByTeam         map[string]*QADefectStats `json:"byTeam"`
+	ByDecisionType map[string]*QADefectStats `json:"byDecisionType"`
+	GeneratedDate  time.Time                 `json:"generatedDate"`
+}
+
+// QAReviewHandler handles the quality review queue of sampled decisions, available on every
+// chaincode that makes reviewable decisions
+type QAReviewHandler struct {
+	qaService    *services.QAService
+	eventService *services.BaseEventService
+}
+
+// NewQAReviewHandler creates a new quality review handler
+func NewQAReviewHandler() *QAReviewHandler {
+	return &QAReviewHandler{
+		qaService:    services.NewQAService(),
+		eventService: services.NewBaseEventService(),
+	}
+}
+
+// SetQASamplingRate sets the share of completed decisions of a type queued for quality review
+func (h *QAReviewHandler) SetQASamplingRate(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req QASamplingRateRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse QA sampling rate request: %v", err)
+	}
+
+	if err := validation.ValidateQADecisionType(req.DecisionType); err != nil {
+		return nil, fmt.Errorf("invalid decision type: %w", err)
+	}
+	if req.Rate < 0 || req.Rate > 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "rate must be between 0 and 1, got %.4f", req.Rate)
+	}
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return nil, err
+	}
+	if role != string(validation.ActorRoleChiefComplianceOfficer) && role != string(validation.ActorRoleLoanOperationsManager) {
+		return nil, services.NewChaincodeError(services.ErrCodeAccessDenied, "", "QA sampling rates may only be set by a %s or %s", validation.ActorRoleChiefComplianceOfficer, validation.ActorRoleLoanOperationsManager)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	rate := &services.QASamplingRate{
+		DecisionType:  req.DecisionType,
+		Rate:          req.Rate,
+		LastUpdated:   now,
+		LastUpdatedBy: req.ActorID,
+	}
+	if err := h.qaService.PutSamplingRate(stub, rate); err != nil {
+		return nil, fmt.Errorf("failed to store QA sampling rate: %w", err)
+	}
+
+	metadata := map[string]string{
+		"rate": fmt.Sprintf("%.4f", rate.Rate),
+	}
+	payload := h.eventService.CreateEventPayloadWithMetadata(
+		config.EventQASamplingRateUpdated,
+		rate.DecisionType,
+		"QASamplingRate",
+		req.ActorID,
+		rate,
+		metadata,
+	)
+	if err := h.eventService.EmitEvent(stub, config.EventQASamplingRateUpdated, payload); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(rate)
+}
+
+// GetQASamplingRates returns the sampling rate of every configured decision type
+func (h *QAReviewHandler) GetQASamplingRates(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 0, got %d", len(args))
+	}
+
+	rates, err := h.qaService.GetSamplingRates(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(rates)
+}
+
+// GetQAReviewQueue returns a page of sampled decisions of a type awaiting review.
+// Args: decisionType [, pageSize [, bookmark]]
+func (h *QAReviewHandler) GetQAReviewQueue(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) < 1 || len(args) > 3 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1 to 3, got %d", len(args))
+	}
+
+	if err := validation.ValidateQADecisionType(args[0]); err != nil {
+		return nil, fmt.Errorf("invalid decision type: %w", err)
+	}
+
+	pageSize, bookmark, err := services.ParsePageArgs(args[1:])
+	if err != nil {
+		return nil, err
+	}
+
+	items, nextBookmark, err := h.qaService.GetQueuePage(stub, args[0], pageSize, bookmark)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(&QAReviewQueueResult{Items: items, Count: len(items), Bookmark: nextBookmark})
+}
+
+// GetQAReviewItem returns a sampled decision and any review findings
+func (h *QAReviewHandler) GetQAReviewItem(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	item, err := h.qaService.GetItem(stub, args[0])
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(item)
+}
+
+// RecordQAReview captures a reviewer's findings on a sampled decision and removes it from the
+// queue. Reviewers may not review their own decisions.
+func (h *QAReviewHandler) RecordQAReview(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req QAReviewRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse QA review request: %v", err)
+	}
+
+	if err := validation.ValidateQAReviewOutcome(req.Outcome); err != nil {
+		return nil, fmt.Errorf("invalid outcome: %w", err)
+	}
+	if req.Outcome == string(validation.QAReviewDefect) && len(req.DefectCodes) == 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "at least one defect code is required when the outcome is %s", validation.QAReviewDefect)
+	}
+	if req.Outcome == string(validation.QAReviewPass) && len(req.DefectCodes) > 0 {
+		return nil, fmt.Errorf("defect codes may not be recorded when the outcome is %s", validation.QAReviewPass)
+	}
+	for _, code := range req.DefectCodes {
+		if err := validation.ValidateQADefectCode(code); err != nil {
+			return nil, fmt.Errorf("invalid defect code: %w", err)
+		}
+	}
+	if strings.TrimSpace(req.Findings) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "findings", "findings are required")
+	}
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return nil, err
+	}
+	if role != string(validation.ActorRoleComplianceOfficer) && role != string(validation.ActorRoleChiefComplianceOfficer) && role != string(validation.ActorRoleRiskAnalyst) {
+		return nil, services.NewChaincodeError(services.ErrCodeAccessDenied, "", "QA reviews may only be recorded by a %s, %s or %s", validation.ActorRoleComplianceOfficer, validation.ActorRoleChiefComplianceOfficer, validation.ActorRoleRiskAnalyst)
+	}
+
+	item, err := h.qaService.GetItem(stub, req.ItemID)
+	if err != nil {
+		return nil, err
+	}
+	if item.Status != services.QAReviewItemPending {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidTransition, "", "review item %s is already %s", req.ItemID, item.Status)
+	}
+	if item.DecidedBy == req.ActorID {
+		return nil, fmt.Errorf("actor %s may not review their own decision", req.ActorID)
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	item.Status = services.QAReviewItemReviewed
+	item.ReviewedBy = req.ActorID
+	item.ReviewedDate = &now
+	item.Outcome = req.Outcome
+	item.DefectCodes = req.DefectCodes
+	item.Findings = req.Findings
+
+	if err := h.qaService.CompleteReview(stub, item); err != nil {
+		return nil, fmt.Errorf("failed to store QA review: %w", err)
+	}
+
+	metadata := map[string]string{
+		"decisionType": item.DecisionType,
+		"team":         item.Team,
+		"outcome":      item.Outcome,
+		"defectCodes":  strings.Join(item.DefectCodes, ","),
+	}
+	payload := h.eventService.CreateEventPayloadWithMetadata(
+		config.EventQAReviewRecorded,
+		item.ItemID,
+		"QAReviewItem",
+		req.ActorID,
+		item,
+		metadata,
+	)
+	if err := h.eventService.EmitEvent(stub, config.EventQAReviewRecorded, payload); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(item)
+}
+
+// GetQADefectReport reports sampled, reviewed and defective decisions made between two dates
+// inclusive, per team and per decision type. Args: fromDate, toDate (YYYY-MM-DD)
+func (h *QAReviewHandler) GetQADefectReport(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 2 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 2, got %d", len(args))
+	}
+
+	fromDate, err := time.Parse("2006-01-02", args[0])
+	if err != nil {
+		return nil, fmt.Errorf("invalid from date %s: %w", args[0], err)
+	}
+	toDate, err := time.Parse("2006-01-02", args[1])
+	if err != nil {
+		return nil, fmt.Errorf("invalid to date %s: %w", args[1], err)
+	}
+	if toDate.Before(fromDate) {
+		return nil, fmt.Errorf("to date %s is before from date %s", args[1], args[0])
+	}
+	if days := int(toDate.Sub(fromDate).Hours()/24) + 1; days > config.MaxQueryRangeDays {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "date range of %d days exceeds maximum of %d", days, config.MaxQueryRangeDays)
+	}
+
+	items, err := h.qaService.GetItemsByDate(stub, fromDate, toDate)
+	if err != nil {
+		return nil, err
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &QADefectReport{
+		FromDate:       args[0],
+		ToDate:         args[1],
+		Total:          newQADefectStats(),
+		ByTeam:         map[string]*QADefectStats{},
+		ByDecisionType: map[string]*QADefectStats{},
+		GeneratedDate:  now,
+	}
+	for _, item := range items {
+		if report.ByTeam[item.Team] == nil {
+			report.ByTeam[item.Team] = newQADefectStats()
+		}
+		if report.ByDecisionType[item.DecisionType] == nil {
+			report.ByDecisionType[item.DecisionType] = newQADefectStats()
+		}
+		for _, stats := range []*QADefectStats{report.Total, report.ByTeam[item.Team], report.ByDecisionType[item.DecisionType]} {
+			stats.add(&item)
+		}
+	}
+
+	report.Total.finish()
+	for _, stats := range report.ByTeam {
+		stats.finish()
+	}
+	for _, stats := range report.ByDecisionType {
+		stats.finish()
+	}
+
+	return json.Marshal(report)
+}
+
+func newQADefectStats() *QADefectStats {
+	return &QADefectStats{DefectCodes: map[string]int{}}
+}
+
+func (s *QADefectStats) add(item *services.QAReviewItem) {
+	s.Sampled++
+	if item.Status != services.QAReviewItemReviewed {
+		return
+	}
+	s.Reviewed++
+	if item.Outcome == string(validation.QAReviewDefect) {
+		s.Defects++
+	}
+	for _, code := range item.DefectCodes {
+		s.DefectCodes[code]++
+	}
+}
+
+func (s *QADefectStats) finish() {
+	if s.Reviewed > 0 {
+		s.DefectRate = math.Round(float64(s.Defects)/float64(s.Reviewed)*10000) / 100
+	}
+}
@@ -0,0 +1,30 @@
+package chaincode
+
+import (
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// CheckRequestSignature rejects requests carrying invalid signature evidence, and requests from
+// external partner identities that carry none, so every partner-originated transaction records
+// verifiable evidence of its origin
+func CheckRequestSignature(stub shim.ChaincodeStubInterface) error {
+	signature, err := services.GetRequestSignature(stub)
+	if err != nil {
+		return err
+	}
+	if signature != nil {
+		return nil
+	}
+
+	role, err := services.InvokerRole(stub)
+	if err != nil {
+		return err
+	}
+	if config.SignedRequestRoles[role] {
+		return services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "requests from %s identities must carry a request signature from the gateway", role)
+	}
+	return nil
+}
@@ -0,0 +1,103 @@
+package chaincode
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/services"
+)
+
+// SandboxActorRequest represents a request to designate or undesignate a sandbox actor
+type SandboxActorRequest struct {
+	SandboxActorID string `json:"sandboxActorID"`
+	Sandbox        bool   `json:"sandbox"`
+	Reason         string `json:"reason"`
+	ActorID        string `json:"actorID"`
+}
+
+// SandboxHandler handles sandbox actor administration
+type SandboxHandler struct {
+	sandboxService *services.SandboxService
+	eventService   *services.BaseEventService
+}
+
+// NewSandboxHandler creates a new sandbox handler
+func NewSandboxHandler() *SandboxHandler {
+	return &SandboxHandler{
+		sandboxService: services.NewSandboxService(),
+		eventService:   services.NewBaseEventService(),
+	}
+}
+
+// SetSandboxActor designates an actor as a sandbox (UAT) actor or returns it to production
+func (h *SandboxHandler) SetSandboxActor(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 1 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 1, got %d", len(args))
+	}
+
+	var req SandboxActorRequest
+	if err := json.Unmarshal([]byte(args[0]), &req); err != nil {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "failed to parse sandbox actor request: %v", err)
+	}
+
+	if strings.TrimSpace(req.SandboxActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "sandboxActorID", "sandboxActorID is required")
+	}
+	if strings.TrimSpace(req.ActorID) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+	if strings.TrimSpace(req.Reason) == "" {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "reason", "reason is required")
+	}
+
+	now, err := services.TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	actor := &services.SandboxActor{
+		ActorID:       req.SandboxActorID,
+		Sandbox:       req.Sandbox,
+		Reason:        req.Reason,
+		LastUpdated:   now,
+		LastUpdatedBy: req.ActorID,
+	}
+
+	if err := h.sandboxService.PutSandboxActor(stub, actor); err != nil {
+		return nil, fmt.Errorf("failed to store sandbox actor: %w", err)
+	}
+
+	metadata := map[string]string{
+		"sandbox": fmt.Sprintf("%t", actor.Sandbox),
+	}
+	payload := h.eventService.CreateEventPayloadWithMetadata(
+		config.EventSandboxActorUpdated,
+		actor.ActorID,
+		"SandboxActor",
+		req.ActorID,
+		actor,
+		metadata,
+	)
+	if err := h.eventService.EmitEvent(stub, config.EventSandboxActorUpdated, payload); err != nil {
+		return nil, fmt.Errorf("failed to emit event: %w", err)
+	}
+
+	return json.Marshal(actor)
+}
+
+// GetSandboxActors returns all sandbox actor designations stored for the chaincode
+func (h *SandboxHandler) GetSandboxActors(stub shim.ChaincodeStubInterface, args []string) ([]byte, error) {
+	if len(args) != 0 {
+		return nil, services.NewChaincodeError(services.ErrCodeInvalidArgument, "", "incorrect number of arguments. Expected 0, got %d", len(args))
+	}
+
+	actors, err := h.sandboxService.GetSandboxActors(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	return json.Marshal(actors)
+}
@@ -15,16 +15,313 @@ const (
 	MaxCustomerAge      = 150
 	MaxLoanAmount       = 10000000.0 // 10 million
 	MinLoanAmount       = 1000.0
+	MaxInterestRate     = 100.0 // Annual percentage; interest rate policy caps may not exceed it
+	DefaultDaysPastDue  = 90 // Days the oldest installment must be past due before a loan may be marked defaulted
+	LargeTransactionReportThreshold = 10000.0 // Amount at or above which a transaction is included in large-transaction (CTR) reports
+	BeneficialOwnershipThreshold = 25.0 // Effective ownership or voting percentage at which an individual is a beneficial owner of an organization
+	MaxOwnershipDepth   = 10 // Most levels of intermediate organizations beneficial owner resolution follows
+	DefaultRelationshipDepth = 2 // Relationship hops related-party queries follow unless asked for more
+	MaxRelationshipDepth = 3 // Most relationship hops a related-party query may follow
 	
 	// Time limits
 	KYCValidityPeriod   = 365 * 24 * time.Hour // 1 year
+	ConsentValidityPeriod = 365 * 24 * time.Hour // Consent must be renewed yearly
 	SessionTimeout      = 30 * time.Minute
 	TransactionTimeout  = 5 * time.Minute
+	IndexRateMaxAge     = 4 * 24 * time.Hour // Covers weekends and one holiday
+	CollateralValuationMaxAge = 365 * 24 * time.Hour
+	CredentialMaxAge    = 90 * 24 * time.Hour // Longest an actor may go without rotating credentials before high-privilege functions are blocked
+	StatusSyncAckTimeout = 15 * time.Minute // Longest core banking may leave a loan status sync record unacknowledged before it is due a resend
+	DefaultEntityLockTTL = 15 * time.Minute // How long a claim on an entity lasts unless the claimant asks for less
+	MaxEntityLockTTL     = 2 * time.Hour    // Longest a single claim may last; longer work must re-claim
+	PolicyExceptionValidity = 30 * 24 * time.Hour // How long an approved policy exception may wait for a loan to rely on it
 	
 	// Pagination
 	DefaultPageSize     = 20
 	MaxPageSize         = 100
+	MaxQueryRangeDays   = 366 // Widest date range a single listing query may scan
+	MaxBulkBatchSize    = 50  // Most index entries one batch of a bulk loan operation may scan
+	MaxSnapshotBatchSize = 200 // Most loans one batch of the end-of-day balance snapshot may read
+	MaxAccrualBatchSize  = 200 // Most loans one batch of the daily interest accrual may read
+	MaxDataQualityBatchSize = 200 // Most records one batch of a data quality sweep may read
+	MaxTransactionIngestBatchSize = 200 // Most transaction summaries one monitoring ingest may carry
+	MaxPaymentMessageBytes = 1 << 20 // Largest ISO 20022 payment message one ingest may carry
+	MaxSanctionListDocumentBytes = 32 << 20 // Largest official sanction list document one update may carry
+	MaxBatchScreenSize = 50 // Most customers one batch screening may screen
 	
 	// Encryption
 	EncryptionKeySize   = 32 // 256 bits
-)
\ No newline at end of file
+)
+
+// RequiredLoanDocuments lists the document types an application must have verified before a decision, by loan type
+var RequiredLoanDocuments = map[string][]string{
+	"PERSONAL":    {"IDENTITY", "INCOME_PROOF"},
+	"MORTGAGE":    {"IDENTITY", "INCOME_PROOF", "BANK_STATEMENT", "COLLATERAL"},
+	"AUTO":        {"IDENTITY", "INCOME_PROOF", "COLLATERAL"},
+	"BUSINESS":    {"IDENTITY", "INCOME_PROOF", "BANK_STATEMENT"},
+	"STUDENT":     {"IDENTITY"},
+	"CREDIT_CARD": {"IDENTITY", "INCOME_PROOF"},
+}
+
+// CreditInquiryWindowDays are the rolling windows inquiry counts are reported over for
+// responsible-lending checks
+var CreditInquiryWindowDays = []int{30, 90, 365}
+
+// WithholdingTaxRate is the share of loan income withheld for a jurisdiction's tax authority
+type WithholdingTaxRate struct {
+	Interest float64 // Applied to interest settled by repayments or charged directly
+	Fee      float64 // Applied to fees posted against the loan
+}
+
+// WithholdingTaxRates lists withholding rates by loan jurisdiction (ISO 3166-1 alpha-2). Loans in
+// jurisdictions not listed here have nothing withheld.
+var WithholdingTaxRates = map[string]WithholdingTaxRate{
+	"IN": {Interest: 0.10, Fee: 0.10},
+	"KE": {Interest: 0.15, Fee: 0.05},
+	"NG": {Interest: 0.10, Fee: 0.10},
+	"PH": {Interest: 0.20},
+}
+
+// Chaincode names used for cross-chaincode invocation
+const (
+	CustomerChaincodeName   = "customer"
+	LoanChaincodeName       = "loan"
+	ComplianceChaincodeName = "compliance"
+	
+	// Consent key a customer must grant before credit processing
+	ConsentCreditCheck = "creditCheck"
+	// Consent key (CREDIT_BUREAU_SHARING) a customer must grant before their data is sent to a credit bureau
+	ConsentCreditBureauSharing = "creditBureauSharing"
+	// Consent key a customer must grant before their data is read for marketing
+	ConsentMarketing = "marketing"
+)
+
+// HighPrivilegeFunctions lists the functions, across all chaincodes, that actors may only invoke
+// while their credentials are younger than CredentialMaxAge
+var HighPrivilegeFunctions = map[string]bool{
+	// Operational
+	"SetFunctionFlag":      true,
+	"SetSandboxActor":      true,
+	"SetQASamplingRate":    true,
+	"SetPayloadArchivePolicy": true,
+	"RecordTimestampCutover":  true,
+	"PurgeSandboxCustomer": true,
+	"PurgeSandboxLoan":     true,
+	"PurgeSandboxFacility": true,
+	"ExportAuditPackage":   true,
+	"FreezeCustomer":       true,
+	"FreezeLoan":           true,
+	"ApproveUnfreeze":      true,
+
+	// Customer
+	"UpdateCustomerStatus": true,
+	"UpdateKYCStatus":      true,
+	"UpdateAMLStatus":      true,
+	"CreateDataSharingAgreement": true,
+
+	// Loan
+	"ApproveLoan":               true,
+	"RepriceLoan":               true,
+	"DisburseLoan":              true,
+	"ApproveBalanceRepair":      true,
+	"RegisterRateOracle":        true,
+	"CompleteServicingTransfer": true,
+	"SetSTPPolicy":              true,
+	"SetInterestRatePolicy":     true,
+	"MarkLoanDefaulted":         true,
+
+	// Compliance
+	"ApproveRule":                  true,
+	"CosignDecision":               true,
+	"BulkImportPEPList":            true,
+	"DeactivatePEPEntry":           true,
+	"DeactivateAdverseMediaRecord": true,
+}
+
+// FreezeExemptFunctions lists the state-changing functions, across all chaincodes, that may still
+// act on a frozen customer or loan: the freeze workflow itself and other compliance actions
+var FreezeExemptFunctions = map[string]bool{
+	"FreezeCustomer":  true,
+	"FreezeLoan":      true,
+	"RequestUnfreeze": true,
+	"ApproveUnfreeze": true,
+	"AddNote":         true,
+	"EditNote":        true,
+
+	// Customer
+	"InitiateKYC":             true,
+	"UpdateKYCStatus":         true,
+	"InitiateAMLCheck":        true,
+	"UpdateAMLStatus":         true,
+	"RecalculateCustomerRisk": true,
+
+	// Loan
+	"BulkPlaceComplianceHold":   true,
+	"BulkReleaseComplianceHold": true,
+}
+
+// Certificate attributes carrying the invoking actor's role and team
+const (
+	RoleAttribute = "role"
+	TeamAttribute = "team" // Optional; quality review reporting falls back to the role when absent
+)
+
+// RequestSignatureTransientKey is the transient data field in which the gateway passes the
+// signature evidence of a partner's request. Transient data never reaches the ledger, so chaincode
+// copies the evidence onto the records it writes.
+const RequestSignatureTransientKey = "requestSignature"
+
+// Transient data fields in which clients pass the data keys for field-level PII encryption, released
+// from the off-chain key store or HSM for the one transaction. The key reference names the key new
+// values are sealed under; each key's 32 bytes go under FieldEncryptionKeyTransientPrefix and its
+// reference, so a transaction may carry retired keys to open older values.
+const (
+	FieldEncryptionKeyRefTransientKey = "piiKeyRef"
+	FieldEncryptionKeyTransientPrefix = "piiKey:"
+)
+
+// CustomerPIITransientKey is the transient data field in which clients pass a registering customer's
+// personal data as a JSON CustomerPII. Arguments are recorded on the ledger with the transaction,
+// so a customer with a residency must be registered this way to keep their PII off the ledger.
+const CustomerPIITransientKey = "customerPII"
+
+// CustomerContactTransientKey is the transient data field in which clients pass the email, phone
+// and address a registration or update sets, as a JSON CustomerContact. Values sealed under a data
+// key must be passed this way, or in CustomerPIITransientKey, so their plaintext stays off the ledger.
+const CustomerContactTransientKey = "customerContact"
+
+// Transient data fields in which clients declare why they read a customer's PII and, optionally,
+// the registered actor reading it. Each read is recorded in the customer's access log.
+const (
+	AccessPurposeTransientKey = "accessPurpose"
+	AccessActorTransientKey   = "accessActorID"
+)
+
+// RecordAccessFunction is the customer chaincode function logging a read of a customer's PII
+const RecordAccessFunction = "RecordAccess"
+
+// AccessLoggedFunctions are the customer chaincode functions that read a customer's PII and log
+// each read in the access log. Their responses carry the PII, so they are evaluated, never
+// submitted; an evaluated call's writes are discarded, so clients submit RecordAccess for the read
+// before evaluating it.
+var AccessLoggedFunctions = map[string]bool{
+	"GetCustomer":               true,
+	"GetCustomer360":            true,
+	"GetCustomerCrossResidency": true,
+	"GetConsentHistory":         true,
+	"GetConsentAt":              true,
+}
+
+// SignedRequestRoles are the invoker roles of external partners, whose requests must carry
+// signature evidence from the gateway
+var SignedRequestRoles = map[string]bool{
+	"Introducer": true,
+}
+
+// MSPs that must jointly endorse decision journal entries. The bank records a decision and the
+// compliance/regulator organisation co-signs it.
+const (
+	BankMSPID      = "Org1MSP"
+	RegulatorMSPID = "Org2MSP"
+)
+
+// PaymentCurrency is the ISO 4217 currency of loan amounts in payment messages exchanged with
+// core banking
+const PaymentCurrency = "USD"
+
+// BaseCurrency is the ISO 4217 currency product limits, amount bounds and policy thresholds are
+// set in. Amounts in other currencies are converted for those checks at the current fixing of the
+// currency's FX index, FXIndexPrefix + currency + BaseCurrency (e.g. FX_EURUSD), which rate
+// oracles publish as units of BaseCurrency per unit of the currency.
+const BaseCurrency = PaymentCurrency
+
+// FXIndexPrefix prefixes the index names FX conversion rates are published under
+const FXIndexPrefix = "FX_"
+
+// MaxDebtServiceRatio is the highest share of monthly income that outgoings plus the proposed loan
+// payment may take for a loan to be assessed as affordable
+const MaxDebtServiceRatio = 0.45
+
+// Metrics kept as daily and monthly counters (see services.IncrementCounter)
+const (
+	MetricCustomersRegistered = "CUSTOMERS_REGISTERED"
+	MetricLoanApplications    = "LOAN_APPLICATIONS_SUBMITTED"
+	MetricComplianceEvents    = "COMPLIANCE_EVENTS"
+	MetricSanctionScreenings  = "SANCTION_SCREENINGS"
+	MetricSanctionMatches     = "SANCTION_MATCHES"
+	MetricSanctionSuppressions = "SANCTION_SUPPRESSIONS" // Screenings with a whitelisted false positive
+)
+
+// HighRiskJurisdictions scores the money-laundering risk (0-100) of customers resident in
+// jurisdictions under increased monitoring. Residencies not listed add no geographic risk.
+var HighRiskJurisdictions = map[string]float64{
+	"AF": 90, "IR": 85, "KP": 95, "SY": 90, "YE": 80,
+	"SO": 85, "LY": 80, "IQ": 75, "MM": 70, "VE": 65,
+}
+
+// CustomerRiskTransactionWindowDays is how far back loan transactions count towards a customer's
+// risk rating
+const CustomerRiskTransactionWindowDays = 90
+
+// ResidencyCollections maps a customer residency (ISO 3166-1 alpha-2) to the private data
+// collection holding the PII of customers resident there. Each collection must also be defined in
+// the customer chaincode's collection config, with membership limited to that country's
+// organizations.
+var ResidencyCollections = map[string]string{
+	"US": "customerPII_US",
+	"GB": "customerPII_GB",
+}
+
+// MSPResidencies maps each organization to the residency whose customer PII it may read without
+// a legal basis
+var MSPResidencies = map[string]string{
+	BankMSPID:      "US",
+	RegulatorMSPID: "US",
+}
+
+// Legal bases under which customer PII may be read from outside the customer's residency
+const (
+	LegalBasisLegalObligation     = "LEGAL_OBLIGATION"
+	LegalBasisRegulatoryRequest   = "REGULATORY_REQUEST"
+	LegalBasisContractPerformance = "CONTRACT_PERFORMANCE"
+	LegalBasisExplicitConsent     = "EXPLICIT_CONSENT"
+)
+
+// CrossResidencyLegalBases lists the legal bases accepted for cross-residency reads
+var CrossResidencyLegalBases = map[string]bool{
+	LegalBasisLegalObligation:     true,
+	LegalBasisRegulatoryRequest:   true,
+	LegalBasisContractPerformance: true,
+	LegalBasisExplicitConsent:     true,
+}
+
+// Purposes for which customer PII may be read. A read that declares none is logged as
+// AccessPurposeUnspecified.
+const (
+	AccessPurposeUnspecified       = "UNSPECIFIED"
+	AccessPurposeCustomerService   = "CUSTOMER_SERVICE"
+	AccessPurposeKYCReview         = "KYC_REVIEW"
+	AccessPurposeAMLInvestigation  = "AML_INVESTIGATION"
+	AccessPurposeCreditAssessment  = "CREDIT_ASSESSMENT"
+	AccessPurposeMarketing         = "MARKETING"
+	AccessPurposeRegulatoryRequest = "REGULATORY_REQUEST"
+	AccessPurposeAudit             = "AUDIT"
+)
+
+// AccessPurposeBasis is the legal basis for reading customer PII for a purpose. Consent names the
+// consent key the customer must have granted when the basis is their explicit consent.
+type AccessPurposeBasis struct {
+	LegalBasis string
+	Consent    string
+}
+
+// AccessPurposes maps each accepted purpose to its legal basis
+var AccessPurposes = map[string]AccessPurposeBasis{
+	AccessPurposeCustomerService:   {LegalBasis: LegalBasisContractPerformance},
+	AccessPurposeKYCReview:         {LegalBasis: LegalBasisLegalObligation},
+	AccessPurposeAMLInvestigation:  {LegalBasis: LegalBasisLegalObligation},
+	AccessPurposeCreditAssessment:  {LegalBasis: LegalBasisExplicitConsent, Consent: ConsentCreditCheck},
+	AccessPurposeMarketing:         {LegalBasis: LegalBasisExplicitConsent, Consent: ConsentMarketing},
+	AccessPurposeRegulatoryRequest: {LegalBasis: LegalBasisRegulatoryRequest},
+	AccessPurposeAudit:             {LegalBasis: LegalBasisLegalObligation},
+}
@@ -9,18 +9,126 @@ const (
 	EventKYCFailed           = "KYCFailed"
 	EventAMLCheckCompleted   = "AMLCheckCompleted"
 	EventAMLFlagged          = "AMLFlagged"
+	EventDataSharingAgreementRecorded = "DataSharingAgreementRecorded"
+	EventConsentWithdrawn    = "ConsentWithdrawn"
+	EventConsentRecorded     = "ConsentRecorded"
+	EventConsentExpiring     = "ConsentExpiring"
+	EventConsentExpired      = "ConsentExpired"
+	EventDisclosureRecorded  = "DisclosureRecorded"
+	EventCustomerRiskTierChanged = "CustomerRiskTierChanged"
+	EventOwnershipRecorded   = "OwnershipRecorded"
+	EventOwnershipRemoved    = "OwnershipRemoved"
+	EventRelationshipCreated = "RelationshipCreated"
+	EventRelationshipTerminated = "RelationshipTerminated"
 	
 	// Loan events
 	EventLoanSubmitted       = "LoanSubmitted"
+	EventLoanStatusUpdated   = "LoanStatusUpdated"
 	EventLoanApproved        = "LoanApproved"
 	EventLoanRejected        = "LoanRejected"
 	EventLoanDisbursed       = "LoanDisbursed"
 	EventDocumentUploaded    = "DocumentUploaded"
 	EventDocumentVerified    = "DocumentVerified"
+	EventLoanTransactionRecorded = "LoanTransactionRecorded"
+	EventLoanBalanceRepaired = "LoanBalanceRepaired"
+	EventLoanParticipationAdded = "LoanParticipationAdded"
+	EventLoanRepriced        = "LoanRepriced"
+	EventLoanDefaulted       = "LoanDefaulted"
+	EventLoanDelinquent      = "LoanDelinquent"
+	EventLoanDelinquencyCured = "LoanDelinquencyCured"
+	EventRepaymentRecorded   = "RepaymentRecorded"
+	EventCreditInquiryRecorded = "CreditInquiryRecorded"
+	EventCreditReportRecorded  = "CreditReportRecorded"
+	EventServicingTransferScheduled = "ServicingTransferScheduled"
+	EventServicingTransferCompleted = "ServicingTransferCompleted"
+	EventServicingTransferCancelled = "ServicingTransferCancelled"
+	EventSTPPolicyUpdated    = "STPPolicyUpdated"
+	EventLoanBulkOperationApplied = "LoanBulkOperationApplied"
+	EventLoanApplicationCancelled = "LoanApplicationCancelled"
+	EventBalanceSnapshotCertified = "BalanceSnapshotCertified"
+	EventInterestAccrualCompleted = "InterestAccrualCompleted"
+	EventLoanStatusSyncResent = "LoanStatusSyncResent"
+	EventCorridorRuleUpdated  = "CorridorRuleUpdated"
+	EventLoanAmountBoundUpdated = "LoanAmountBoundUpdated"
+	EventPolicyExceptionRequested = "PolicyExceptionRequested"
+	EventPolicyExceptionDecided   = "PolicyExceptionDecided"
+	EventUnderwritingChecklistUpdated = "UnderwritingChecklistUpdated"
+	EventChecklistItemCompleted       = "ChecklistItemCompleted"
+	EventInterestRatePolicyUpdated    = "InterestRatePolicyUpdated"
+	
+	// Collateral events
+	EventCollateralAdded    = "CollateralAdded"
+	EventCollateralRevalued = "CollateralRevalued"
+	EventCollateralReleased = "CollateralReleased"
+	
+	// Guarantee events
+	EventGuaranteeAdded            = "GuaranteeAdded"
+	EventGuaranteeInvoked          = "GuaranteeInvoked"
+	EventGuarantorPaymentRecorded  = "GuarantorPaymentRecorded"
+	
+	// Credit facility events
+	EventFacilityOpened     = "FacilityOpened"
+	EventFacilityDrawdown   = "FacilityDrawdown"
+	EventFacilityRepayment  = "FacilityRepayment"
+	EventFacilityReviewed   = "FacilityReviewed"
+	EventFacilityConverted  = "FacilityConverted"
+	
+	// Market data events
+	EventIndexRatePublished = "IndexRatePublished"
+	
+	// Introducer events
+	EventIntroducerRegistered      = "IntroducerRegistered"
+	EventIntroducerStatusChanged   = "IntroducerStatusChanged"
+	EventCommissionScheduleUpdated = "CommissionScheduleUpdated"
+	
+	// Open Banking events
+	EventOpenBankingAuthorized           = "OpenBankingAuthorized"
+	EventOpenBankingAuthorizationRevoked = "OpenBankingAuthorizationRevoked"
+	EventAffordabilityAssessed           = "AffordabilityAssessed"
+	
+	// Counterparty events
+	EventCounterpartyRegistered    = "CounterpartyRegistered"
+	EventCounterpartyKYCUpdated    = "CounterpartyKYCUpdated"
+	EventCounterpartyScreened      = "CounterpartyScreened"
+	EventCounterpartyStatusChanged = "CounterpartyStatusChanged"
 	
 	// Compliance events
 	EventComplianceCheckTriggered = "ComplianceCheckTriggered"
 	EventComplianceRuleViolation  = "ComplianceRuleViolation"
 	EventComplianceReportGenerated = "ComplianceReportGenerated"
 	EventRegulatoryAlert          = "RegulatoryAlert"
-)
\ No newline at end of file
+	EventLoanBalanceDiscrepancy   = "LoanBalanceDiscrepancy"
+	EventDecisionRecorded         = "DecisionRecorded"
+	EventDecisionCosigned         = "DecisionCosigned"
+	
+	// Operational events
+	EventFunctionFlagUpdated = "FunctionFlagUpdated"
+	EventSandboxActorUpdated = "SandboxActorUpdated"
+	EventActorRegistered     = "ActorRegistered"
+	EventCredentialRotationAttested = "CredentialRotationAttested"
+	EventQASamplingRateUpdated = "QASamplingRateUpdated"
+	EventQAReviewRecorded    = "QAReviewRecorded"
+	EventPayloadArchivePolicyUpdated = "PayloadArchivePolicyUpdated"
+	EventSandboxRecordsPurged = "SandboxRecordsPurged"
+	EventEntityNoteAdded     = "EntityNoteAdded"
+	EventEntityNoteEdited    = "EntityNoteEdited"
+	EventEntityClaimed       = "EntityClaimed"
+	EventEntityReleased      = "EntityReleased"
+	EventEntityFrozen        = "EntityFrozen"
+	EventEntityUnfreezeRequested = "EntityUnfreezeRequested"
+	EventEntityUnfrozen      = "EntityUnfrozen"
+	EventAuditPackageExported = "AuditPackageExported"
+	EventDataQualitySweepCompleted = "DataQualitySweepCompleted"
+	EventRemediationTaskResolved   = "RemediationTaskResolved"
+)
+
+// Event schema versions. Events carry DefaultEventSchemaVersion unless listed in
+// EventSchemaVersions; bump an event's entry whenever the shape of its data changes incompatibly.
+// Events emitted before versioning carry no version and decode as LegacyEventSchemaVersion.
+const (
+	LegacyEventSchemaVersion  = 0
+	DefaultEventSchemaVersion = 1
+)
+
+// EventSchemaVersions lists events whose payload schema has moved past DefaultEventSchemaVersion
+var EventSchemaVersions = map[string]int{}
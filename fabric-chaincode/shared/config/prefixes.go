@@ -6,19 +6,88 @@ const (
 	CustomerPrefix    = "CUST"
 	KYCRecordPrefix   = "KYC"
 	AMLCheckPrefix    = "AML"
+	DataSharingAgreementPrefix = "DSA"
+	CustomerRelationshipPrefix = "REL"
 	
 	// Loan domain prefixes
 	LoanApplicationPrefix = "LOAN"
 	LoanDocumentPrefix    = "DOC"
 	LoanHistoryPrefix     = "LHIST"
+	LoanTransactionPrefix = "LTXN"
+	LoanDisbursementPrefix = "DISB"
+	LoanReconciliationPrefix = "RECON"
+	CounterpartyPrefix    = "CPTY"
+	LoanParticipationPrefix = "PART"
+	LoanGuaranteePrefix   = "GUAR"
+	CollateralPrefix      = "COLL"
+	RecoveryObligationPrefix = "RECOV"
+	GuarantorPaymentPrefix = "GPAY"
+	CreditFacilityPrefix  = "FAC"
+	FacilityTransactionPrefix = "FTXN"
+	CreditInquiryPrefix   = "INQ"
+	CreditReportPrefix    = "CRPT"
+	ServicingTransferPrefix = "SVT"
+	OpenBankingAuthorizationPrefix = "OBAUTH"
+	AffordabilityAssessmentPrefix  = "AFFORD"
+	BulkLoanOperationPrefix        = "BULKOP"
+	CommissionEntryPrefix          = "COMM"
+	CorridorReportPrefix           = "CBR"
+	PolicyExceptionPrefix          = "PEXC"
 	
 	// Compliance domain prefixes
 	ComplianceCasePrefix = "COMP"
 	ComplianceRulePrefix = "RULE"
 	ComplianceReportPrefix = "REPORT"
+	ComplianceEventPrefix  = "CEVT"
+	SanctionListPrefix     = "SLIST"
+	EscalationPrefix       = "ESC"
+	DecisionJournalPrefix  = "DECISION_JOURNAL"
+	PEPEntryPrefix         = "PEP"
+	AdverseMediaPrefix     = "ADVERSE_MEDIA"
+	ScreeningTermPrefix    = "SCREEN_TERM"
+	ChannelFraudRulePrefix = "CHANNEL_FRAUD_RULE"
+	DeviceApplicationPrefix = "DEVICE_APPLICATION"
+	TransactionTypologyRulePrefix = "TXN_TYPOLOGY_RULE"
+	MonitoredTransactionPrefix    = "MONITORED_TRANSACTION"
+	TransactionAlertPrefix        = "TXA"
+	CountryRiskPrefix             = "COUNTRY_RISK"
+	CountryRiskHistoryPrefix      = "COUNTRY_RISK_HISTORY"
+	RiskCatalogEntryPrefix        = "RISK_CATALOG_ENTRY"
+	RiskModelPrefix               = "RISK_MODEL"
+	RiskModelActivePrefix         = "RISK_MODEL_ACTIVE"
+	ScreeningWhitelistPrefix      = "SCREENING_WHITELIST"
+	ScreeningJobPrefix            = "SCREENING_JOB"
+	ScreeningBatchPrefix          = "SCREENING_BATCH"
+	ComplianceReportIndexPrefix   = "REPORT_PERIOD"
+	ReportingPeriodPrefix         = "REPORTING_PERIOD"
+	GovernanceMemberPrefix        = "GOVERNANCE_MEMBER"
+	GovernanceProposalPrefix      = "GOVP"
+	PlatformParameterPrefix       = "PLATFORM_PARAMETER"
+	PlatformParameterHistoryPrefix = "PLATFORM_PARAMETER_HISTORY"
 	
 	// Shared prefixes
 	ActorPrefix   = "ACTOR"
+	CredentialRotationPrefix = "CREDENTIAL_ROTATION"
+	QASamplingRatePrefix = "QA_SAMPLING_RATE"
+	QAReviewItemPrefix   = "QA_REVIEW_ITEM"
+	EntityNotePrefix     = "ENTITY_NOTE"
+	EntityNoteRevisionPrefix = "ENTITY_NOTE_REVISION"
+	EntityLockPrefix     = "ENTITY_LOCK"
+	EntityFreezePrefix   = "ENTITY_FREEZE"
+	CounterPrefix        = "COUNTER"
+	CounterTotalPrefix   = "COUNTER_TOTAL"
+	AuditPackagePrefix   = "AUDIT_PACKAGE"
+	DataQualityReportPrefix = "DATA_QUALITY_REPORT"
+	RemediationTaskPrefix   = "REMEDIATION_TASK"
+	EscalationRoutePrefix   = "ESCALATION_ROUTE"
 	HistoryPrefix = "HIST"
 	EventPrefix   = "EVENT"
+	
+	// Operational config prefixes
+	FunctionFlagPrefix = "FUNCTION_FLAG"
+	SandboxActorPrefix = "SANDBOX_ACTOR"
+	PayloadArchivePolicyPrefix = "PAYLOAD_ARCHIVE_POLICY"
+	PayloadArchivePrefix       = "PAYLOAD_ARCHIVE"
+	TimestampCutoverKey        = "TIMESTAMP_CUTOVER"
+	IdempotencyKeyPrefix       = "IDEMPOTENCY_KEY"
 )
\ No newline at end of file
@@ -0,0 +1,110 @@
+package encryption
+
+import (
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+)
+
+// DataKeySize is the length of an AES-256 data key in bytes
+const DataKeySize = 32
+
+// DataKey is a data encryption key released to a transaction from the off-chain key store or HSM.
+// Only its reference is ever written to the ledger.
+type DataKey struct {
+	Ref string
+	Key []byte
+}
+
+// Validate checks the key has a reference and is an AES-256 key
+func (k *DataKey) Validate() error {
+	if k.Ref == "" {
+		return fmt.Errorf("data key reference is required")
+	}
+	if len(k.Key) != DataKeySize {
+		return fmt.Errorf("data key %s must be %d bytes, got %d", k.Ref, DataKeySize, len(k.Key))
+	}
+	return nil
+}
+
+// EncryptedField is a field value sealed with AES-256-GCM under an off-chain data key. The
+// ciphertext carries the GCM tag and is bound to the entity and field it was sealed for, so it
+// cannot be moved to another record or field and still open.
+type EncryptedField struct {
+	KeyRef     string `json:"keyRef"`
+	Nonce      string `json:"nonce"`      // Base64
+	Ciphertext string `json:"ciphertext"` // Base64
+}
+
+// Seal encrypts a field value for an entity. Every endorsing peer must produce the same
+// ciphertext, so the nonce is derived from the key, the transaction ID and the field's place
+// rather than drawn at random; a transaction seals each field of an entity at most once, which
+// keeps nonces unique under a key.
+func Seal(key *DataKey, txID, entityID, field, plaintext string) (*EncryptedField, error) {
+	if err := key.Validate(); err != nil {
+		return nil, err
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return nil, err
+	}
+
+	mac := hmac.New(sha256.New, key.Key)
+	mac.Write([]byte(txID + "\x00" + entityID + "\x00" + field))
+	nonce := mac.Sum(nil)[:aead.NonceSize()]
+
+	ciphertext := aead.Seal(nil, nonce, []byte(plaintext), additionalData(entityID, field))
+	return &EncryptedField{
+		KeyRef:     key.Ref,
+		Nonce:      base64.StdEncoding.EncodeToString(nonce),
+		Ciphertext: base64.StdEncoding.EncodeToString(ciphertext),
+	}, nil
+}
+
+// Open decrypts a field value sealed for an entity. The key must be the one the field names.
+func Open(key *DataKey, entityID, field string, sealed *EncryptedField) (string, error) {
+	if err := key.Validate(); err != nil {
+		return "", err
+	}
+	if key.Ref != sealed.KeyRef {
+		return "", fmt.Errorf("%s is sealed under data key %s, not %s", field, sealed.KeyRef, key.Ref)
+	}
+	aead, err := newAEAD(key)
+	if err != nil {
+		return "", err
+	}
+
+	nonce, err := base64.StdEncoding.DecodeString(sealed.Nonce)
+	if err != nil || len(nonce) != aead.NonceSize() {
+		return "", fmt.Errorf("invalid nonce on %s", field)
+	}
+	ciphertext, err := base64.StdEncoding.DecodeString(sealed.Ciphertext)
+	if err != nil {
+		return "", fmt.Errorf("invalid ciphertext on %s", field)
+	}
+	plaintext, err := aead.Open(nil, nonce, ciphertext, additionalData(entityID, field))
+	if err != nil {
+		return "", fmt.Errorf("failed to decrypt %s: %w", field, err)
+	}
+	return string(plaintext), nil
+}
+
+func newAEAD(key *DataKey) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key.Key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create GCM: %w", err)
+	}
+	return aead, nil
+}
+
+// additionalData binds a ciphertext to the entity and field it was sealed for
+func additionalData(entityID, field string) []byte {
+	return []byte(entityID + "\x00" + field)
+}
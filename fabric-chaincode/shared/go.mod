@@ -3,12 +3,12 @@ module github.com/brycemacchaveli/origin.block/fabric-chaincode/shared
 go 1.23
 
 require (
+	github.com/golang/protobuf v1.5.2
 	github.com/hyperledger/fabric-chaincode-go v0.0.0-20220920210243-7bc6fa0dd58b
 	github.com/hyperledger/fabric-protos-go v0.0.0-20220827195505-ce4c067a561d
 )
 
 require (
-	github.com/golang/protobuf v1.5.2 // indirect
 	github.com/stretchr/testify v1.8.4 // indirect
 	golang.org/x/net v0.0.0-20220708220712-1185a9018129 // indirect
 	golang.org/x/sys v0.26.0 // indirect
@@ -0,0 +1,29 @@
+package interfaces
+
+// ApplicationChannelReport tells the compliance chaincode how and from which device a loan
+// application was submitted, so channel-specific fraud rules such as device velocity can be applied
+type ApplicationChannelReport struct {
+	LoanID          string  `json:"loanID"`
+	CustomerID      string  `json:"customerID"`
+	Channel         string  `json:"channel"`
+	DeviceHash      string  `json:"deviceHash,omitempty"` // SHA-256 of the device fingerprint
+	LoanType        string  `json:"loanType"`
+	RequestedAmount float64 `json:"requestedAmount"` // In config.BaseCurrency
+	IntroducerID    string  `json:"introducerID,omitempty"`
+	ActorID         string  `json:"actorID"`
+}
+
+// ApplicationChannelResult is the compliance chaincode's answer to an application channel report.
+// Flagged applications have been raised as a compliance event for review.
+type ApplicationChannelResult struct {
+	Flagged  bool                      `json:"flagged"`
+	Severity string                    `json:"severity,omitempty"`
+	Alerts   []ApplicationChannelAlert `json:"alerts"`
+	EventID  string                    `json:"eventID,omitempty"` // Compliance event raised for the alerts
+}
+
+// ApplicationChannelAlert is a channel fraud rule an application tripped
+type ApplicationChannelAlert struct {
+	Code   string `json:"code"` // DEVICE_VELOCITY, DEVICE_SHARED_ACROSS_CUSTOMERS or DEVICE_HASH_MISSING
+	Detail string `json:"detail"`
+}
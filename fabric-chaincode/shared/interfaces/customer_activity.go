@@ -0,0 +1,54 @@
+package interfaces
+
+import "time"
+
+// CustomerLoanMilestone is a status a customer's loan reached, as reported by the loan chaincode
+// for the customer's event stream. Sequence distinguishes milestones of the same loan.
+type CustomerLoanMilestone struct {
+	LoanID    string    `json:"loanID"`
+	LoanType  string    `json:"loanType"`
+	Status    string    `json:"status"`
+	Label     string    `json:"label"` // Borrower-facing wording of the status
+	Timestamp time.Time `json:"timestamp"`
+	Sequence  string    `json:"sequence"`
+}
+
+// ComplianceEventSummary is the part of a compliance event the customer chaincode reads when it
+// lists the screenings and alerts raised against a customer
+type ComplianceEventSummary struct {
+	EventID          string    `json:"eventID"`
+	Timestamp        time.Time `json:"timestamp"`
+	RuleID           string    `json:"ruleID"`
+	EventType        string    `json:"eventType"`
+	Severity         string    `json:"severity"`
+	IsAlerted        bool      `json:"isAlerted"`
+	ResolutionStatus string    `json:"resolutionStatus"`
+}
+
+// CustomerLoanSummary is the part of a loan application the customer chaincode reads when it
+// lists a customer's loans
+type CustomerLoanSummary struct {
+	LoanID          string     `json:"loanID"`
+	LoanType        string     `json:"loanType"`
+	Status          string     `json:"status"`
+	Currency        string     `json:"currency,omitempty"`
+	RequestedAmount float64    `json:"requestedAmount"`
+	ApprovedAmount  *float64   `json:"approvedAmount,omitempty"`
+	TermMonths      int        `json:"termMonths"`
+	ApplicationDate time.Time  `json:"applicationDate"`
+	DecisionDate    *time.Time `json:"decisionDate,omitempty"`
+}
+
+// CustomerScreeningSummary is the outcome of one compliance screening of a customer, without the
+// matched list entries
+type CustomerScreeningSummary struct {
+	CheckID       string    `json:"checkID"`
+	CheckType     string    `json:"checkType"`
+	Status        string    `json:"status"`
+	RiskLevel     string    `json:"riskLevel"`
+	RiskScore     float64   `json:"riskScore"`
+	SanctionMatch bool      `json:"sanctionMatch"`
+	PEPMatch      bool      `json:"pepMatch"`
+	CheckDate     time.Time `json:"checkDate"`
+	ExpiryDate    time.Time `json:"expiryDate"`
+}
@@ -0,0 +1,17 @@
+package interfaces
+
+import "github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+
+// CustomerHoldings is the loan chaincode's summary of a customer's loans and recent loan
+// transactions, used to rate the customer's risk
+type CustomerHoldings struct {
+	CustomerID              string               `json:"customerID"`
+	PendingApplications     int                  `json:"pendingApplications"`
+	ActiveLoans             int                  `json:"activeLoans"`
+	DefaultedLoans          int                  `json:"defaultedLoans"`
+	LoanTypes               []string             `json:"loanTypes"`           // Distinct types of active and defaulted loans
+	OutstandingBalances     utils.CurrencyTotals `json:"outstandingBalances"` // Per loan currency
+	RecentTransactionCount  int                  `json:"recentTransactionCount"`
+	RecentTransactionVolume float64              `json:"recentTransactionVolume"` // Sum of transaction amounts in the window, in the base currency
+	WindowDays              int                  `json:"windowDays"`
+}
@@ -0,0 +1,36 @@
+package interfaces
+
+import "time"
+
+// CustomerComplianceStatus is the customer chaincode's answer to cross-chaincode eligibility checks
+type CustomerComplianceStatus struct {
+	CustomerID         string     `json:"customerID"`
+	Status             string     `json:"status"`
+	KYCStatus          string     `json:"kycStatus"`
+	KYCExpiryDate      *time.Time `json:"kycExpiryDate,omitempty"`
+	AMLStatus          string     `json:"amlStatus"`
+	AMLRiskScore       float64    `json:"amlRiskScore"` // Risk score of the latest AML check, 0-100
+	ConsentPreferences string     `json:"consentPreferences"`
+	Residency          string     `json:"residency,omitempty"` // ISO 3166-1 alpha-2
+	RiskTier           string     `json:"riskTier,omitempty"`  // Tier of the latest risk rating; empty until the customer is rated
+	Frozen             bool       `json:"frozen,omitempty"`    // Compliance has frozen the customer; no new business may be written
+}
+
+// ConsentStatus is the customer chaincode's answer to whether a consent purpose was in force at an
+// instant. Record is the consent record in force then, or nil when none had been recorded.
+type ConsentStatus struct {
+	CustomerID string        `json:"customerID"`
+	Purpose    string        `json:"purpose"`
+	AsOf       time.Time     `json:"asOf"`
+	Granted    bool          `json:"granted"`
+	Record     *ConsentGrant `json:"record,omitempty"`
+}
+
+// ConsentGrant is the part of a customer consent record other chaincodes rely on
+type ConsentGrant struct {
+	Version       int        `json:"version"`
+	Granted       bool       `json:"granted"`
+	EffectiveDate time.Time  `json:"effectiveDate"`
+	ExpiryDate    *time.Time `json:"expiryDate,omitempty"`
+	TransactionID string     `json:"transactionID"` // Transaction that recorded the consent
+}
@@ -13,6 +13,20 @@ type EventPayload struct {
 	Metadata    map[string]string `json:"metadata,omitempty"`
 }
 
+// EventHeader identifies an event and the schema version of its payload. Consumers switch on
+// SchemaVersion to pick the struct the payload's data decodes into.
+type EventHeader struct {
+	EventName     string `json:"eventName"`
+	SchemaVersion int    `json:"schemaVersion"`
+}
+
+// VersionedEvent is the body of every emitted event. The header and payload are embedded so the
+// payload fields stay at the top level, where listeners written before versioning read them.
+type VersionedEvent struct {
+	EventHeader
+	EventPayload
+}
+
 // EventEmitter defines the interface for emitting blockchain events
 type EventEmitter interface {
 	// Emit a single event
@@ -1,13 +1,13 @@
 package interfaces
 
 import (
-	"github.com/hyperledger/fabric-protos-go/common"
+	"github.com/golang/protobuf/ptypes/timestamp"
 )
 
 // HistoryEntry represents a single history entry from Fabric
 type HistoryEntry struct {
-	TxID      string             `json:"txId"`
-	Timestamp *common.Timestamp  `json:"timestamp"`
-	IsDelete  bool               `json:"isDelete"`
-	Value     []byte             `json:"value"`
+	TxID      string               `json:"txId"`
+	Timestamp *timestamp.Timestamp `json:"timestamp"`
+	IsDelete  bool                 `json:"isDelete"`
+	Value     []byte               `json:"value"`
 }
\ No newline at end of file
@@ -0,0 +1,19 @@
+package interfaces
+
+// LoanDefaultReport tells the compliance chaincode that a loan has been marked defaulted, so the
+// default is raised through the compliance event flow for review and regulatory reporting
+type LoanDefaultReport struct {
+	LoanID             string   `json:"loanID"`
+	CustomerIDs        []string `json:"customerIDs"` // Borrower and any co-borrowers
+	LoanType           string   `json:"loanType"`
+	OutstandingBalance float64  `json:"outstandingBalance"`
+	AmountOverdue      float64  `json:"amountOverdue"`
+	DaysPastDue        int      `json:"daysPastDue"`
+	Notes              string   `json:"notes,omitempty"`
+	ActorID            string   `json:"actorID"`
+}
+
+// LoanDefaultReportResult is the compliance chaincode's acknowledgement of a loan default report
+type LoanDefaultReportResult struct {
+	EventID string `json:"eventID"` // Compliance event raised for the default
+}
@@ -0,0 +1,22 @@
+package interfaces
+
+// PEPScreeningRequest asks the compliance chaincode to screen a name against its PEP list
+type PEPScreeningRequest struct {
+	Name string `json:"name"`
+}
+
+// PEPScreeningResult is the compliance chaincode's answer to a cross-chaincode PEP screening
+type PEPScreeningResult struct {
+	IsMatch         bool                `json:"isMatch"`
+	MatchConfidence float64             `json:"matchConfidence"`
+	Matches         []PEPScreeningMatch `json:"matches"`
+}
+
+// PEPScreeningMatch is a PEP list entry resembling the screened name
+type PEPScreeningMatch struct {
+	MatchedName  string  `json:"matchedName"`
+	Position     string  `json:"position"`
+	Country      string  `json:"country"`
+	RiskCategory string  `json:"riskCategory"`
+	Confidence   float64 `json:"confidence"`
+}
@@ -0,0 +1,22 @@
+package interfaces
+
+import "time"
+
+// ScreeningCandidate is a customer found by the customer chaincode's name search, decoded to the
+// fields sanction screening needs
+type ScreeningCandidate struct {
+	CustomerID  string    `json:"customerID"`
+	FirstName   string    `json:"firstName"`
+	LastName    string    `json:"lastName"`
+	DateOfBirth time.Time `json:"dateOfBirth"`
+	NationalID  string    `json:"nationalID"`
+	Address     string    `json:"address"`
+	Residency   string    `json:"residency,omitempty"`
+}
+
+// ScreeningCandidatePage is one page of the customer chaincode's SearchCustomersByName
+type ScreeningCandidatePage struct {
+	Customers []ScreeningCandidate `json:"customers"`
+	Count     int                  `json:"count"`
+	Bookmark  string               `json:"bookmark"`
+}
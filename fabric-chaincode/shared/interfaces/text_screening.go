@@ -0,0 +1,30 @@
+package interfaces
+
+// TextScreeningRequest asks the compliance chaincode to screen an entity's free-text fields, such
+// as a loan purpose, against its prohibited activity terms
+type TextScreeningRequest struct {
+	EntityID   string            `json:"entityID"`
+	EntityType string            `json:"entityType"`
+	Fields     map[string]string `json:"fields"` // Field name to text
+	ActorID    string            `json:"actorID"`
+}
+
+// TextScreeningResult is the compliance chaincode's answer to a cross-chaincode text screening.
+// Blocked is set when a matched term is severe enough that the entity must not proceed until
+// compliance has reviewed it.
+type TextScreeningResult struct {
+	Flagged  bool                 `json:"flagged"`
+	Blocked  bool                 `json:"blocked"`
+	Severity string               `json:"severity,omitempty"` // Highest severity matched
+	Matches  []TextScreeningMatch `json:"matches"`
+	EventID  string               `json:"eventID,omitempty"` // Compliance event raised for the matches
+}
+
+// TextScreeningMatch is a screening term found in one of the screened fields
+type TextScreeningMatch struct {
+	Field    string `json:"field"`
+	TermID   string `json:"termID"`
+	Term     string `json:"term"`
+	Category string `json:"category"`
+	Severity string `json:"severity"`
+}
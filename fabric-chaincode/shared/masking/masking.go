@@ -0,0 +1,51 @@
+package masking
+
+import (
+	"strings"
+)
+
+// MaskTrailing replaces all but the last reveal characters with '*'
+func MaskTrailing(value string, reveal int) string {
+	runes := []rune(value)
+	if reveal < 0 {
+		reveal = 0
+	}
+	if len(runes) <= reveal {
+		return strings.Repeat("*", len(runes))
+	}
+	return strings.Repeat("*", len(runes)-reveal) + string(runes[len(runes)-reveal:])
+}
+
+// MaskEmail keeps the first reveal characters of the local part and the domain
+func MaskEmail(email string, reveal int) string {
+	at := strings.LastIndex(email, "@")
+	if at < 1 {
+		return MaskTrailing(email, 0)
+	}
+
+	local := []rune(email[:at])
+	if reveal < 0 {
+		reveal = 0
+	}
+	if reveal >= len(local) {
+		reveal = len(local) - 1
+	}
+	return string(local[:reveal]) + strings.Repeat("*", len(local)-reveal) + email[at:]
+}
+
+// MaskPhone keeps the last reveal characters of a phone number
+func MaskPhone(phone string, reveal int) string {
+	return MaskTrailing(phone, reveal)
+}
+
+// MaskNationalID keeps the last reveal characters of a national identifier
+func MaskNationalID(nationalID string, reveal int) string {
+	return MaskTrailing(nationalID, reveal)
+}
+
+// MaskAccountNumber keeps the last reveal characters of an account number or IBAN. Spaces and
+// dashes used for grouping are dropped first so they do not count towards the revealed digits.
+func MaskAccountNumber(accountNumber string, reveal int) string {
+	compact := strings.NewReplacer(" ", "", "-", "").Replace(accountNumber)
+	return MaskTrailing(compact, reveal)
+}
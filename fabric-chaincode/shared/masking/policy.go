@@ -0,0 +1,138 @@
+package masking
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// Strategy selects how a response field is masked
+type Strategy string
+
+const (
+	StrategyEmail         Strategy = "EMAIL"
+	StrategyPhone         Strategy = "PHONE"
+	StrategyNationalID    Strategy = "NATIONAL_ID"
+	StrategyAccountNumber Strategy = "ACCOUNT_NUMBER"
+	StrategyOmit          Strategy = "OMIT" // Drop the field from the response
+)
+
+// Rule masks a single response field
+type Rule struct {
+	Strategy Strategy
+	Reveal   int // Characters left visible; for emails, leading characters of the local part
+}
+
+// Policy maps response field names, matched as JSON keys at any depth, to the rule masking them.
+// An empty policy leaves responses unchanged.
+type Policy map[string]Rule
+
+// Apply masks every field of a JSON response named in the policy. Non-string values of masked
+// fields are omitted rather than guessed at.
+func (p Policy) Apply(payload []byte) ([]byte, error) {
+	if len(p) == 0 || len(payload) == 0 {
+		return payload, nil
+	}
+
+	decoder := json.NewDecoder(bytes.NewReader(payload))
+	decoder.UseNumber()
+
+	var value interface{}
+	if err := decoder.Decode(&value); err != nil {
+		// Plain text responses carry no fields to mask
+		return payload, nil
+	}
+
+	masked, err := json.Marshal(p.mask(value))
+	if err != nil {
+		return nil, fmt.Errorf("failed to marshal masked response: %w", err)
+	}
+	return masked, nil
+}
+
+func (p Policy) mask(value interface{}) interface{} {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		for key, field := range v {
+			rule, ok := p[key]
+			if !ok {
+				v[key] = p.mask(field)
+				continue
+			}
+
+			text, isString := field.(string)
+			if rule.Strategy == StrategyOmit || !isString {
+				delete(v, key)
+				continue
+			}
+			v[key] = rule.maskString(text)
+		}
+		return v
+	case []interface{}:
+		for i := range v {
+			v[i] = p.mask(v[i])
+		}
+		return v
+	default:
+		return v
+	}
+}
+
+func (r Rule) maskString(value string) string {
+	switch r.Strategy {
+	case StrategyEmail:
+		return MaskEmail(value, r.Reveal)
+	case StrategyPhone:
+		return MaskPhone(value, r.Reveal)
+	case StrategyNationalID:
+		return MaskNationalID(value, r.Reveal)
+	case StrategyAccountNumber:
+		return MaskAccountNumber(value, r.Reveal)
+	default:
+		return MaskTrailing(value, r.Reveal)
+	}
+}
+
+// PolicySet selects the masking policy for an actor role
+type PolicySet struct {
+	byRole   map[validation.ActorRole]Policy
+	fallback Policy
+}
+
+// NewPolicySet creates a policy set. Roles not listed, and identities whose role cannot be read,
+// get the fallback policy, so it should be the most restrictive.
+func NewPolicySet(fallback Policy, byRole map[validation.ActorRole]Policy) *PolicySet {
+	return &PolicySet{byRole: byRole, fallback: fallback}
+}
+
+// ForRole returns the policy for an actor role
+func (s *PolicySet) ForRole(role string) Policy {
+	if policy, ok := s.byRole[validation.ActorRole(role)]; ok {
+		return policy
+	}
+	return s.fallback
+}
+
+// Fields returns the names of the fields any policy in the set masks, which may therefore be
+// missing from a masked response
+func (s *PolicySet) Fields() []string {
+	seen := map[string]bool{}
+	for field := range s.fallback {
+		seen[field] = true
+	}
+	for _, policy := range s.byRole {
+		for field := range policy {
+			seen[field] = true
+		}
+	}
+
+	fields := make([]string, 0, len(seen))
+	for field := range seen {
+		fields = append(fields, field)
+	}
+	sort.Strings(fields)
+	return fields
+}
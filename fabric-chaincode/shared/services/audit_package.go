@@ -0,0 +1,349 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+)
+
+// AuditPackageFormat identifies the layout of audit packages and how their hashes are computed.
+// Each section hash is the hex SHA-256 of the section's records encoded as a JSON array; the
+// manifest hash is the hex SHA-256 of the manifest encoded as JSON with manifestHash empty.
+const AuditPackageFormat = "origin.block/audit-package/v1"
+
+// AuditCollector gathers one section of evidence about a subject from the ledger. Collect returns
+// the stored records as they are on the ledger, in key order.
+type AuditCollector struct {
+	Name        string
+	Description string
+	Collect     func(stub shim.ChaincodeStubInterface, subjectID string) ([]json.RawMessage, error)
+}
+
+// AuditSection is one section of evidence in an audit package
+type AuditSection struct {
+	Name    string            `json:"name"`
+	Records []json.RawMessage `json:"records"`
+}
+
+// AuditManifestSection describes and fingerprints one section of an audit package
+type AuditManifestSection struct {
+	Name        string `json:"name"`
+	Description string `json:"description"`
+	RecordCount int    `json:"recordCount"`
+	Hash        string `json:"hash"`
+}
+
+// AuditManifest lists an audit package's sections with their hashes. It is written to the ledger
+// when the package is exported, so whoever receives the package can check it against the ledger.
+type AuditManifest struct {
+	PackageID     string                 `json:"packageID"`
+	Format        string                 `json:"format"`
+	SubjectID     string                 `json:"subjectID"`
+	Sections      []AuditManifestSection `json:"sections"`
+	ExportedBy    string                 `json:"exportedBy"`
+	ExportedByMSP string                 `json:"exportedByMSP"`
+	ExportedDate  time.Time              `json:"exportedDate"`
+	TransactionID string                 `json:"transactionID"`
+	ManifestHash  string                 `json:"manifestHash"`
+}
+
+// AuditPackage is the evidence bundle handed over for a subject, with its manifest
+type AuditPackage struct {
+	Manifest AuditManifest  `json:"manifest"`
+	Sections []AuditSection `json:"sections"`
+}
+
+// AuditPackageVerification is the result of checking a package against its recorded manifest
+type AuditPackageVerification struct {
+	PackageID string   `json:"packageID"`
+	Valid     bool     `json:"valid"`
+	Problems  []string `json:"problems"`
+}
+
+// AuditPackageService builds audit packages and keeps their manifests on the ledger
+type AuditPackageService struct {
+	persistenceService *PersistenceService
+}
+
+// NewAuditPackageService creates a new audit package service
+func NewAuditPackageService() *AuditPackageService {
+	return &AuditPackageService{
+		persistenceService: NewPersistenceService(),
+	}
+}
+
+// Build collects every section for a subject and records the package manifest. Sections with no
+// records are kept, so the manifest shows they were searched.
+func (s *AuditPackageService) Build(stub shim.ChaincodeStubInterface, subjectID, actorID, actorMSP string, collectors []AuditCollector) (*AuditPackage, error) {
+	now, err := TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	pkg := &AuditPackage{
+		Manifest: AuditManifest{
+			PackageID:     GenerateDeterministicID(stub, config.AuditPackagePrefix),
+			Format:        AuditPackageFormat,
+			SubjectID:     subjectID,
+			Sections:      []AuditManifestSection{},
+			ExportedBy:    actorID,
+			ExportedByMSP: actorMSP,
+			ExportedDate:  now,
+			TransactionID: stub.GetTxID(),
+		},
+		Sections: []AuditSection{},
+	}
+
+	total := 0
+	for _, collector := range collectors {
+		records, err := collector.Collect(stub, subjectID)
+		if err != nil {
+			return nil, fmt.Errorf("failed to collect %s: %w", collector.Name, err)
+		}
+		if records == nil {
+			records = []json.RawMessage{}
+		}
+		hash, err := hashAuditSection(records)
+		if err != nil {
+			return nil, err
+		}
+		total += len(records)
+
+		pkg.Sections = append(pkg.Sections, AuditSection{Name: collector.Name, Records: records})
+		pkg.Manifest.Sections = append(pkg.Manifest.Sections, AuditManifestSection{
+			Name:        collector.Name,
+			Description: collector.Description,
+			RecordCount: len(records),
+			Hash:        hash,
+		})
+	}
+	if total == 0 {
+		return nil, fmt.Errorf("no audit records found for %s", subjectID)
+	}
+
+	manifestHash, err := hashAuditManifest(pkg.Manifest)
+	if err != nil {
+		return nil, err
+	}
+	pkg.Manifest.ManifestHash = manifestHash
+
+	manifestKey, err := stub.CreateCompositeKey(config.AuditPackagePrefix, []string{pkg.Manifest.PackageID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := s.persistenceService.Put(stub, manifestKey, &pkg.Manifest); err != nil {
+		return nil, fmt.Errorf("failed to store audit manifest: %w", err)
+	}
+	indexKey, err := stub.CreateCompositeKey("AUDIT_PACKAGE_BY_SUBJECT", []string{subjectID, pkg.Manifest.PackageID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := stub.PutState(indexKey, []byte(pkg.Manifest.PackageID)); err != nil {
+		return nil, fmt.Errorf("failed to index audit manifest: %w", err)
+	}
+
+	return pkg, nil
+}
+
+// GetManifest retrieves the manifest recorded when a package was exported
+func (s *AuditPackageService) GetManifest(stub shim.ChaincodeStubInterface, packageID string) (*AuditManifest, error) {
+	manifestKey, err := stub.CreateCompositeKey(config.AuditPackagePrefix, []string{packageID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create composite key: %w", err)
+	}
+	var manifest AuditManifest
+	if err := s.persistenceService.Get(stub, manifestKey, &manifest); err != nil {
+		return nil, fmt.Errorf("audit package %s not found: %w", packageID, err)
+	}
+	return &manifest, nil
+}
+
+// Verify checks a package handed back for verification against the manifest recorded on the
+// ledger: every section must hash to the recorded value and the manifest must be unaltered
+func (s *AuditPackageService) Verify(stub shim.ChaincodeStubInterface, pkg *AuditPackage) (*AuditPackageVerification, error) {
+	recorded, err := s.GetManifest(stub, pkg.Manifest.PackageID)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &AuditPackageVerification{PackageID: recorded.PackageID, Problems: []string{}}
+	manifestHash, err := hashAuditManifest(pkg.Manifest)
+	if err != nil {
+		return nil, err
+	}
+	if manifestHash != recorded.ManifestHash {
+		result.Problems = append(result.Problems, "manifest does not match the manifest recorded on the ledger")
+	}
+
+	sections := make(map[string]AuditSection)
+	for _, section := range pkg.Sections {
+		sections[section.Name] = section
+	}
+	for _, expected := range recorded.Sections {
+		section, ok := sections[expected.Name]
+		if !ok {
+			result.Problems = append(result.Problems, fmt.Sprintf("section %s is missing", expected.Name))
+			continue
+		}
+		if section.Records == nil {
+			section.Records = []json.RawMessage{}
+		}
+		hash, err := hashAuditSection(section.Records)
+		if err != nil {
+			return nil, err
+		}
+		if hash != expected.Hash || len(section.Records) != expected.RecordCount {
+			result.Problems = append(result.Problems, fmt.Sprintf("section %s has been altered", expected.Name))
+		}
+	}
+	recordedSections := make(map[string]bool)
+	for _, expected := range recorded.Sections {
+		recordedSections[expected.Name] = true
+	}
+	for _, section := range pkg.Sections {
+		if !recordedSections[section.Name] {
+			result.Problems = append(result.Problems, fmt.Sprintf("section %s is not in the recorded manifest", section.Name))
+		}
+	}
+
+	result.Valid = len(result.Problems) == 0
+	return result, nil
+}
+
+// RecordCollector returns a collector for the single record stored at keyFormat formatted with
+// the subject, e.g. "CUSTOMER_%s"
+func RecordCollector(name, description, keyFormat string) AuditCollector {
+	return AuditCollector{
+		Name:        name,
+		Description: description,
+		Collect: func(stub shim.ChaincodeStubInterface, subjectID string) ([]json.RawMessage, error) {
+			return collectAuditRecord(stub, fmt.Sprintf(keyFormat, subjectID))
+		},
+	}
+}
+
+// PointerCollector returns a collector for the record a pointer key refers to, e.g. the latest KYC
+// record through CUSTOMER_KYC_<id>. The pointer holds the record ID, formatted into recordFormat.
+func PointerCollector(name, description, pointerFormat, recordFormat string) AuditCollector {
+	return AuditCollector{
+		Name:        name,
+		Description: description,
+		Collect: func(stub shim.ChaincodeStubInterface, subjectID string) ([]json.RawMessage, error) {
+			pointerKey := fmt.Sprintf(pointerFormat, subjectID)
+			recordID, err := stub.GetState(pointerKey)
+			if err != nil {
+				return nil, fmt.Errorf("failed to read %s: %w", pointerKey, err)
+			}
+			if recordID == nil {
+				return []json.RawMessage{}, nil
+			}
+			return collectAuditRecord(stub, fmt.Sprintf(recordFormat, string(recordID)))
+		},
+	}
+}
+
+// CompositeKeyCollector returns a collector for records stored under a composite key whose first
+// attribute is the subject, e.g. HISTORY entries or decision journal entries
+func CompositeKeyCollector(name, description, objectType string) AuditCollector {
+	return AuditCollector{
+		Name:        name,
+		Description: description,
+		Collect: func(stub shim.ChaincodeStubInterface, subjectID string) ([]json.RawMessage, error) {
+			iterator, err := stub.GetStateByPartialCompositeKey(objectType, []string{subjectID})
+			if err != nil {
+				return nil, fmt.Errorf("failed to query %s: %w", objectType, err)
+			}
+			defer iterator.Close()
+
+			records := []json.RawMessage{}
+			for iterator.HasNext() {
+				response, err := iterator.Next()
+				if err != nil {
+					return nil, fmt.Errorf("failed to iterate %s: %w", objectType, err)
+				}
+				records = append(records, json.RawMessage(response.Value))
+			}
+			return records, nil
+		},
+	}
+}
+
+// HistoryCollector returns a collector for the field-level change history handlers record under
+// HISTORY composite keys
+func HistoryCollector() AuditCollector {
+	return CompositeKeyCollector("history", "Field-level change history, oldest key first", "HISTORY")
+}
+
+// IndexedRecordCollector returns a collector for records found through a composite index whose
+// first attribute is the subject and whose last attribute is the record ID; keyFor maps an ID to
+// the record's key
+func IndexedRecordCollector(name, description, indexType string, keyFor func(recordID string) string) AuditCollector {
+	return AuditCollector{
+		Name:        name,
+		Description: description,
+		Collect: func(stub shim.ChaincodeStubInterface, subjectID string) ([]json.RawMessage, error) {
+			iterator, err := stub.GetStateByPartialCompositeKey(indexType, []string{subjectID})
+			if err != nil {
+				return nil, fmt.Errorf("failed to query %s: %w", indexType, err)
+			}
+			defer iterator.Close()
+
+			records := []json.RawMessage{}
+			for iterator.HasNext() {
+				response, err := iterator.Next()
+				if err != nil {
+					return nil, fmt.Errorf("failed to iterate %s: %w", indexType, err)
+				}
+				_, attributes, err := stub.SplitCompositeKey(response.Key)
+				if err != nil || len(attributes) < 2 {
+					continue
+				}
+				recordID := attributes[len(attributes)-1]
+				recordBytes, err := stub.GetState(keyFor(recordID))
+				if err != nil {
+					return nil, fmt.Errorf("failed to read record %s: %w", recordID, err)
+				}
+				if recordBytes == nil {
+					continue // Skip index entries whose record is gone
+				}
+				records = append(records, json.RawMessage(recordBytes))
+			}
+			return records, nil
+		},
+	}
+}
+
+func collectAuditRecord(stub shim.ChaincodeStubInterface, key string) ([]json.RawMessage, error) {
+	recordBytes, err := stub.GetState(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read %s: %w", key, err)
+	}
+	if recordBytes == nil {
+		return []json.RawMessage{}, nil
+	}
+	return []json.RawMessage{json.RawMessage(recordBytes)}, nil
+}
+
+func hashAuditSection(records []json.RawMessage) (string, error) {
+	recordsBytes, err := json.Marshal(records)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode audit section: %w", err)
+	}
+	hash := sha256.Sum256(recordsBytes)
+	return hex.EncodeToString(hash[:]), nil
+}
+
+func hashAuditManifest(manifest AuditManifest) (string, error) {
+	manifest.ManifestHash = ""
+	manifestBytes, err := json.Marshal(manifest)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode audit manifest: %w", err)
+	}
+	hash := sha256.Sum256(manifestBytes)
+	return hex.EncodeToString(hash[:]), nil
+}
@@ -0,0 +1,80 @@
+package services
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// Clock tells business logic the current time. Chaincode runs on TxClock so every endorsing peer
+// sees the same time; tests substitute a FixedClock to exercise expiries, SLAs and accruals at
+// chosen times without rewriting ledger state.
+type Clock interface {
+	Now(stub shim.ChaincodeStubInterface) (time.Time, error)
+}
+
+// TxClock reads the transaction timestamp
+type TxClock struct{}
+
+// Now returns the transaction timestamp in UTC
+func (TxClock) Now(stub shim.ChaincodeStubInterface) (time.Time, error) {
+	timestamp, err := stub.GetTxTimestamp()
+	if err != nil {
+		return time.Time{}, fmt.Errorf("failed to get transaction timestamp: %w", err)
+	}
+	return time.Unix(timestamp.Seconds, int64(timestamp.Nanos)).UTC(), nil
+}
+
+// FixedClock returns a time set by the test, which only moves when the test moves it
+type FixedClock struct {
+	mu  sync.Mutex
+	now time.Time
+}
+
+// NewFixedClock creates a clock stopped at a time
+func NewFixedClock(now time.Time) *FixedClock {
+	return &FixedClock{now: now.UTC()}
+}
+
+// Now returns the clock's time
+func (c *FixedClock) Now(stub shim.ChaincodeStubInterface) (time.Time, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now, nil
+}
+
+// Set moves the clock to a time
+func (c *FixedClock) Set(now time.Time) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = now.UTC()
+}
+
+// Advance moves the clock forward by a duration
+func (c *FixedClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.now = c.now.Add(d)
+}
+
+// clock is the Clock TxTime reads
+var clock = struct {
+	sync.RWMutex
+	current Clock
+}{current: TxClock{}}
+
+// SetClock replaces the clock TxTime reads and returns a function restoring the previous one.
+// Only tests should call it, deferring the restore.
+func SetClock(c Clock) func() {
+	clock.Lock()
+	defer clock.Unlock()
+	previous := clock.current
+	clock.current = c
+	return func() {
+		clock.Lock()
+		defer clock.Unlock()
+		clock.current = previous
+	}
+}
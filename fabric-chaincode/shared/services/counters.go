@@ -0,0 +1,140 @@
+package services
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+)
+
+// Counter periods: a day (YYYY-MM-DD) or a month (YYYY-MM)
+const (
+	CounterDayFormat   = "2006-01-02"
+	CounterMonthFormat = "2006-01"
+)
+
+// Counter is the running total of a metric for one day or month, maintained as the counted
+// records are written so reports read the metric's counter keys instead of scanning the records
+type Counter struct {
+	MetricKey   string    `json:"metricKey"`
+	Period      string    `json:"period"`
+	Count       int64     `json:"count"`
+	LastUpdated time.Time `json:"lastUpdated"`
+}
+
+// IncrementCounter adds one to a metric's counters for the transaction's day and month
+func IncrementCounter(stub shim.ChaincodeStubInterface, metricKey string) error {
+	return IncrementCounterBy(stub, metricKey, 1)
+}
+
+// IncrementCounterBy adds n to a metric's counters for the transaction's day and month. Each
+// transaction writes its own delta key (metric, period, transaction ID) rather than rewriting a
+// shared total, so concurrent transactions counting the same metric never fail MVCC validation
+// against each other; CompactCounters folds the deltas into the total GetCounter reads.
+func IncrementCounterBy(stub shim.ChaincodeStubInterface, metricKey string, n int64) error {
+	now, err := TxTime(stub)
+	if err != nil {
+		return err
+	}
+
+	txID := stub.GetTxID()
+	ps := NewPersistenceService()
+	for _, period := range []string{now.Format(CounterDayFormat), now.Format(CounterMonthFormat)} {
+		deltaKey, err := stub.CreateCompositeKey(config.CounterPrefix, []string{metricKey, period, txID})
+		if err != nil {
+			return fmt.Errorf("failed to create counter key: %w", err)
+		}
+
+		delta := &Counter{
+			MetricKey:   metricKey,
+			Period:      period,
+			Count:       invocationOf(stub).addCounterDelta(deltaKey, n),
+			LastUpdated: now,
+		}
+		if err := ps.Put(stub, deltaKey, delta); err != nil {
+			return fmt.Errorf("failed to store %s counter: %w", metricKey, err)
+		}
+	}
+	return nil
+}
+
+// GetCounter returns a metric's counter for a day or month from its total key, with a zero count
+// when nothing has been counted in it. Increments reach the total when CompactCounters runs.
+func GetCounter(stub shim.ChaincodeStubInterface, metricKey, period string) (*Counter, error) {
+	totalKey, err := stub.CreateCompositeKey(config.CounterTotalPrefix, []string{metricKey, period})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create counter key: %w", err)
+	}
+
+	counter := &Counter{MetricKey: metricKey, Period: period}
+	if err := NewPersistenceService().Get(stub, totalKey, counter); err != nil && !errors.Is(err, ErrNotFound) {
+		return nil, fmt.Errorf("failed to get %s counter: %w", metricKey, err)
+	}
+	return counter, nil
+}
+
+// CompactCounters folds the delta keys written since the last compaction into the totals of a
+// metric's periods and deletes them, for one period or, when period is empty, every period. It
+// returns the totals it changed. Compaction reads the delta keys, so it only conflicts with an
+// increment committed while it runs, and is then simply run again.
+func CompactCounters(stub shim.ChaincodeStubInterface, metricKey, period string) ([]*Counter, error) {
+	attributes := []string{metricKey}
+	if period != "" {
+		attributes = append(attributes, period)
+	}
+	iterator, err := stub.GetStateByPartialCompositeKey(config.CounterPrefix, attributes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get %s counter deltas: %w", metricKey, err)
+	}
+	defer iterator.Close()
+
+	totals := map[string]*Counter{}
+	periods := []string{}
+	deltaKeys := []string{}
+	for iterator.HasNext() {
+		kv, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to read %s counter delta: %w", metricKey, err)
+		}
+
+		var delta Counter
+		if err := json.Unmarshal(kv.Value, &delta); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s counter delta: %w", metricKey, err)
+		}
+		total, ok := totals[delta.Period]
+		if !ok {
+			if total, err = GetCounter(stub, metricKey, delta.Period); err != nil {
+				return nil, err
+			}
+			totals[delta.Period] = total
+			periods = append(periods, delta.Period)
+		}
+		total.Count += delta.Count
+		if delta.LastUpdated.After(total.LastUpdated) {
+			total.LastUpdated = delta.LastUpdated
+		}
+		deltaKeys = append(deltaKeys, kv.Key)
+	}
+
+	ps := NewPersistenceService()
+	compacted := make([]*Counter, 0, len(periods))
+	for _, period := range periods {
+		totalKey, err := stub.CreateCompositeKey(config.CounterTotalPrefix, []string{metricKey, period})
+		if err != nil {
+			return nil, fmt.Errorf("failed to create counter key: %w", err)
+		}
+		if err := ps.Put(stub, totalKey, totals[period]); err != nil {
+			return nil, fmt.Errorf("failed to store %s counter: %w", metricKey, err)
+		}
+		compacted = append(compacted, totals[period])
+	}
+	for _, deltaKey := range deltaKeys {
+		if err := stub.DelState(deltaKey); err != nil {
+			return nil, fmt.Errorf("failed to delete %s counter delta: %w", metricKey, err)
+		}
+	}
+	return compacted, nil
+}
@@ -0,0 +1,365 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+)
+
+// Data quality sweep and remediation task statuses
+const (
+	DataQualitySweepInProgress = "IN_PROGRESS"
+	DataQualitySweepCompleted  = "COMPLETED"
+	RemediationTaskOpen        = "OPEN"
+	RemediationTaskResolved    = "RESOLVED"
+)
+
+// DataQualitySweepDateFormat is the day a sweep report is kept under
+const DataQualitySweepDateFormat = "2006-01-02"
+
+// dataQualitySchemaRule is the built-in rule every swept record is held to: it must decode into
+// its registered schema before any other rule can be checked
+const dataQualitySchemaRule = "schema"
+
+// DataQualityRule is one invariant every record of a namespace must hold. Check is given the record
+// decoded into its registered schema and returns the problem found, or "" when the record holds the
+// invariant. An error means the check could not be made and stops the sweep.
+type DataQualityRule struct {
+	Name        string
+	Description string
+	Check       func(stub shim.ChaincodeStubInterface, record interface{}) (string, error)
+}
+
+// DataQualityRuleResult counts the records of a sweep that failed one rule
+type DataQualityRuleResult struct {
+	Rule        string `json:"rule"`
+	Description string `json:"description"`
+	Failures    int    `json:"failures"`
+}
+
+// DataQualityReport is the scored result of sweeping one namespace on one day. Sweeps run in
+// batches, resuming after the checkpoint, until every record has been checked.
+type DataQualityReport struct {
+	Namespace      string                  `json:"namespace"`
+	EntityType     string                  `json:"entityType"`
+	SweepDate      string                  `json:"sweepDate"`
+	Status         string                  `json:"status"`
+	Checkpoint     string                  `json:"checkpoint,omitempty"` // Last key checked by an unfinished sweep
+	Batches        int                     `json:"batches"`
+	RecordsChecked int                     `json:"recordsChecked"`
+	RecordsFailed  int                     `json:"recordsFailed"`
+	Score          float64                 `json:"score"` // Percent of checked records holding every rule
+	Rules          []DataQualityRuleResult `json:"rules"`
+	TasksRaised    int                     `json:"tasksRaised"` // Remediation tasks opened or reopened
+	StartedBy      string                  `json:"startedBy"`
+	StartedDate    time.Time               `json:"startedDate"`
+	CompletedDate  *time.Time              `json:"completedDate,omitempty"`
+}
+
+// RemediationTask is a record found breaking a data quality rule, to be put right and resolved.
+// There is one task per record and rule; a resolved task is reopened if a later sweep finds the
+// record breaking the rule again.
+type RemediationTask struct {
+	TaskID          string     `json:"taskID"`
+	Namespace       string     `json:"namespace"`
+	EntityType      string     `json:"entityType"`
+	EntityID        string     `json:"entityID"`
+	Rule            string     `json:"rule"`
+	Problem         string     `json:"problem"`
+	Status          string     `json:"status"`
+	RaisedDate      time.Time  `json:"raisedDate"`
+	LastSeenDate    time.Time  `json:"lastSeenDate"`
+	LastSweepDate   string     `json:"lastSweepDate"`
+	ResolvedBy      string     `json:"resolvedBy,omitempty"`
+	ResolvedDate    *time.Time `json:"resolvedDate,omitempty"`
+	ResolutionNotes string     `json:"resolutionNotes,omitempty"`
+}
+
+// DataQualityService sweeps the namespaces of a schema registry for records breaking their rules
+type DataQualityService struct {
+	registry           *SchemaRegistry
+	rules              map[string][]DataQualityRule
+	persistenceService *PersistenceService
+}
+
+// NewDataQualityService creates a data quality service checking the given rules, by namespace
+func NewDataQualityService(registry *SchemaRegistry, rules map[string][]DataQualityRule) *DataQualityService {
+	return &DataQualityService{
+		registry:           registry,
+		rules:              rules,
+		persistenceService: NewPersistenceService(),
+	}
+}
+
+// Namespaces returns the namespaces that have data quality rules
+func (s *DataQualityService) Namespaces() []string {
+	namespaces := []string{}
+	for _, entry := range s.registry.Entries() {
+		if _, ok := s.rules[entry.Namespace]; ok {
+			namespaces = append(namespaces, entry.Namespace)
+		}
+	}
+	return namespaces
+}
+
+// Sweep checks the next batch of up to batchSize records of a namespace against its rules,
+// continuing the day's sweep where the last batch stopped, and raises a remediation task for each
+// rule a record breaks. The report is completed and scored once the last record is checked.
+func (s *DataQualityService) Sweep(stub shim.ChaincodeStubInterface, namespace string, batchSize int, actorID string) (*DataQualityReport, error) {
+	entry, ok := s.registry.Lookup(namespace)
+	rules, hasRules := s.rules[namespace]
+	if !ok || !hasRules || entry.Composite || entry.IsIndex() {
+		return nil, fmt.Errorf("namespace %s has no data quality rules", namespace)
+	}
+
+	now, err := TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	sweepDate := now.Format(DataQualitySweepDateFormat)
+
+	report, err := s.GetReport(stub, namespace, sweepDate)
+	if err != nil {
+		return nil, err
+	}
+	if report == nil {
+		report = &DataQualityReport{
+			Namespace:   namespace,
+			EntityType:  entry.EntityType,
+			SweepDate:   sweepDate,
+			Status:      DataQualitySweepInProgress,
+			Rules:       []DataQualityRuleResult{{Rule: dataQualitySchemaRule, Description: fmt.Sprintf("Record decodes as a %s", entry.EntityType)}},
+			StartedBy:   actorID,
+			StartedDate: now,
+		}
+		for _, rule := range rules {
+			report.Rules = append(report.Rules, DataQualityRuleResult{Rule: rule.Name, Description: rule.Description})
+		}
+	}
+	if report.Status == DataQualitySweepCompleted {
+		return nil, NewChaincodeError(ErrCodeInvalidTransition, "", "data quality sweep of %s for %s is already completed", namespace, sweepDate)
+	}
+
+	// Record keys never change, so resuming after the checkpoint neither skips nor repeats a record
+	startKey := namespace
+	if report.Checkpoint != "" {
+		startKey = report.Checkpoint + "\x00"
+	}
+	iterator, err := stub.GetStateByRange(startKey, namespace+string(utf8.MaxRune))
+	if err != nil {
+		return nil, fmt.Errorf("failed to scan namespace %s: %w", namespace, err)
+	}
+	defer iterator.Close()
+
+	read := 0
+	for read < batchSize && iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate namespace %s: %w", namespace, err)
+		}
+		read++
+		report.Checkpoint = response.Key
+
+		// Keys under a longer registered prefix belong to that namespace
+		if owner, ok := s.registry.OwnerOf(response.Key); ok && owner.Namespace != namespace {
+			continue
+		}
+
+		problems, err := s.checkRecord(stub, entry, rules, response.Value)
+		if err != nil {
+			return nil, fmt.Errorf("failed to check %s: %w", response.Key, err)
+		}
+		report.RecordsChecked++
+		if len(problems) > 0 {
+			report.RecordsFailed++
+		}
+		entityID := strings.TrimPrefix(response.Key, namespace)
+		for i := range report.Rules {
+			problem, failed := problems[report.Rules[i].Rule]
+			if !failed {
+				continue
+			}
+			report.Rules[i].Failures++
+			raised, err := s.raiseTask(stub, entry, entityID, report.Rules[i].Rule, problem, sweepDate, now)
+			if err != nil {
+				return nil, err
+			}
+			if raised {
+				report.TasksRaised++
+			}
+		}
+	}
+	report.Batches++
+
+	if !iterator.HasNext() {
+		report.Status = DataQualitySweepCompleted
+		report.Checkpoint = ""
+		report.CompletedDate = &now
+	}
+	report.Score = 100
+	if report.RecordsChecked > 0 {
+		report.Score = float64(report.RecordsChecked-report.RecordsFailed) / float64(report.RecordsChecked) * 100
+	}
+
+	reportKey, err := stub.CreateCompositeKey(config.DataQualityReportPrefix, []string{namespace, sweepDate})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create data quality report key: %w", err)
+	}
+	if err := s.persistenceService.Put(stub, reportKey, report); err != nil {
+		return nil, fmt.Errorf("failed to store data quality report: %w", err)
+	}
+
+	return report, nil
+}
+
+// GetReport retrieves the sweep report of a namespace for a day, or nil when none was run
+func (s *DataQualityService) GetReport(stub shim.ChaincodeStubInterface, namespace, sweepDate string) (*DataQualityReport, error) {
+	reportKey, err := stub.CreateCompositeKey(config.DataQualityReportPrefix, []string{namespace, sweepDate})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create data quality report key: %w", err)
+	}
+	exists, err := s.persistenceService.Exists(stub, reportKey)
+	if err != nil || !exists {
+		return nil, err
+	}
+
+	var report DataQualityReport
+	if err := s.persistenceService.Get(stub, reportKey, &report); err != nil {
+		return nil, fmt.Errorf("failed to get data quality report: %w", err)
+	}
+	return &report, nil
+}
+
+// GetTask retrieves a remediation task
+func (s *DataQualityService) GetTask(stub shim.ChaincodeStubInterface, namespace, taskID string) (*RemediationTask, error) {
+	taskKey, err := stub.CreateCompositeKey(config.RemediationTaskPrefix, []string{namespace, taskID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create remediation task key: %w", err)
+	}
+	var task RemediationTask
+	if err := s.persistenceService.Get(stub, taskKey, &task); err != nil {
+		return nil, fmt.Errorf("remediation task %s not found: %w", taskID, err)
+	}
+	return &task, nil
+}
+
+// PutTask stores a remediation task
+func (s *DataQualityService) PutTask(stub shim.ChaincodeStubInterface, task *RemediationTask) error {
+	taskKey, err := stub.CreateCompositeKey(config.RemediationTaskPrefix, []string{task.Namespace, task.TaskID})
+	if err != nil {
+		return fmt.Errorf("failed to create remediation task key: %w", err)
+	}
+	if err := s.persistenceService.Put(stub, taskKey, task); err != nil {
+		return fmt.Errorf("failed to store remediation task: %w", err)
+	}
+	return nil
+}
+
+// GetTasksPage returns a page of a namespace's remediation tasks, open and resolved
+func (s *DataQualityService) GetTasksPage(stub shim.ChaincodeStubInterface, namespace string, pageSize int, bookmark string) ([]RemediationTask, string, error) {
+	entries, nextBookmark, err := s.persistenceService.GetPageByPartialCompositeKeys(stub, config.RemediationTaskPrefix, [][]string{{namespace}}, pageSize, bookmark)
+	if err != nil {
+		return nil, "", err
+	}
+
+	tasks := []RemediationTask{}
+	for _, entry := range entries {
+		var task RemediationTask
+		if err := utils.UnmarshalJSON(entry.Value, &task); err != nil {
+			return nil, "", err
+		}
+		tasks = append(tasks, task)
+	}
+	return tasks, nextBookmark, nil
+}
+
+// checkRecord returns the problems found with a record, by rule. A record that does not decode
+// is not checked against the other rules.
+func (s *DataQualityService) checkRecord(stub shim.ChaincodeStubInterface, entry SchemaEntry, rules []DataQualityRule, value []byte) (map[string]string, error) {
+	problems := map[string]string{}
+
+	record := entry.Factory()
+	if err := json.Unmarshal(value, record); err != nil {
+		problems[dataQualitySchemaRule] = fmt.Sprintf("record does not decode as a %s: %v", entry.EntityType, err)
+		return problems, nil
+	}
+
+	for _, rule := range rules {
+		problem, err := rule.Check(stub, record)
+		if err != nil {
+			return nil, fmt.Errorf("rule %s: %w", rule.Name, err)
+		}
+		if problem != "" {
+			problems[rule.Name] = problem
+		}
+	}
+	return problems, nil
+}
+
+// raiseTask opens a remediation task for a record breaking a rule, or updates the one already
+// open. It reports whether a task was opened or reopened.
+func (s *DataQualityService) raiseTask(stub shim.ChaincodeStubInterface, entry SchemaEntry, entityID, rule, problem, sweepDate string, now time.Time) (bool, error) {
+	taskID := remediationTaskID(entry.Namespace, entityID, rule)
+	taskKey, err := stub.CreateCompositeKey(config.RemediationTaskPrefix, []string{entry.Namespace, taskID})
+	if err != nil {
+		return false, fmt.Errorf("failed to create remediation task key: %w", err)
+	}
+
+	task := &RemediationTask{}
+	exists, err := s.persistenceService.Exists(stub, taskKey)
+	if err != nil {
+		return false, err
+	}
+	if exists {
+		if err := s.persistenceService.Get(stub, taskKey, task); err != nil {
+			return false, fmt.Errorf("failed to get remediation task: %w", err)
+		}
+	}
+
+	raised := !exists || task.Status == RemediationTaskResolved
+	if raised {
+		*task = RemediationTask{
+			TaskID:     taskID,
+			Namespace:  entry.Namespace,
+			EntityType: entry.EntityType,
+			EntityID:   entityID,
+			Rule:       rule,
+			Status:     RemediationTaskOpen,
+			RaisedDate: now,
+		}
+	}
+	task.Problem = problem
+	task.LastSeenDate = now
+	task.LastSweepDate = sweepDate
+
+	if err := s.PutTask(stub, task); err != nil {
+		return false, err
+	}
+	return raised, nil
+}
+
+// remediationTaskID derives a task's ID from the record and rule it is about, so each pairing has
+// at most one task
+func remediationTaskID(namespace, entityID, rule string) string {
+	return "DQT_" + utils.HashValue(namespace + "\x00" + entityID + "\x00" + rule)[:16]
+}
+
+// IndexEntryExists reports whether a composite index entry is present, for rules checking that a
+// record's indexes are in step with it
+func IndexEntryExists(stub shim.ChaincodeStubInterface, objectType string, attributes ...string) (bool, error) {
+	indexKey, err := stub.CreateCompositeKey(objectType, attributes)
+	if err != nil {
+		return false, fmt.Errorf("failed to create composite key: %w", err)
+	}
+	value, err := stub.GetState(indexKey)
+	if err != nil {
+		return false, fmt.Errorf("failed to read %s index: %w", objectType, err)
+	}
+	return value != nil, nil
+}
@@ -0,0 +1,172 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/pkg/statebased"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+)
+
+// Decision journal entry statuses
+const (
+	JournalEntryPendingCosign = "PENDING_COSIGN"
+	JournalEntryCosigned      = "COSIGNED"
+)
+
+// DecisionJournalEntry is an append-only record of a sensitive decision and its co-signature
+type DecisionJournalEntry struct {
+	EntryID       string     `json:"entryID"`
+	DecisionType  string     `json:"decisionType"`
+	EntityID      string     `json:"entityID"`
+	EntityType    string     `json:"entityType"`
+	Decision      string     `json:"decision"`
+	Rationale     string     `json:"rationale"`
+	Status        string     `json:"status"`
+	RecordedBy    string     `json:"recordedBy"`
+	RecordedByMSP string     `json:"recordedByMSP"`
+	RecordedDate  time.Time  `json:"recordedDate"`
+	RecordedTxID  string     `json:"recordedTxID"`
+	CosignedBy    string     `json:"cosignedBy,omitempty"`
+	CosignedByMSP string     `json:"cosignedByMSP,omitempty"`
+	CosignedDate  *time.Time `json:"cosignedDate,omitempty"`
+	CosignTxID    string     `json:"cosignTxID,omitempty"`
+	CosignComment string     `json:"cosignComment,omitempty"`
+}
+
+// DecisionJournalService stores journal entries under a key-level endorsement policy that
+// requires peers of both the bank and regulator MSPs
+type DecisionJournalService struct {
+	persistenceService *PersistenceService
+}
+
+// NewDecisionJournalService creates a new decision journal service
+func NewDecisionJournalService() *DecisionJournalService {
+	return &DecisionJournalService{
+		persistenceService: NewPersistenceService(),
+	}
+}
+
+// CreateEntry appends a new entry and pins the dual endorsement policy to its key, so the
+// co-signature and any later write only validate when both organisations endorse it
+func (js *DecisionJournalService) CreateEntry(stub shim.ChaincodeStubInterface, entry *DecisionJournalEntry) error {
+	entryKey, err := js.entryKey(stub, entry.EntityID, entry.EntryID)
+	if err != nil {
+		return err
+	}
+
+	exists, err := js.persistenceService.Exists(stub, entryKey)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("decision journal entry %s already exists", entry.EntryID)
+	}
+
+	if err := js.persistenceService.Put(stub, entryKey, entry); err != nil {
+		return err
+	}
+
+	policy, err := statebased.NewStateEP(nil)
+	if err != nil {
+		return fmt.Errorf("failed to create journal endorsement policy: %w", err)
+	}
+	if err := policy.AddOrgs(statebased.RoleTypePeer, config.BankMSPID, config.RegulatorMSPID); err != nil {
+		return fmt.Errorf("failed to build journal endorsement policy: %w", err)
+	}
+	policyBytes, err := policy.Policy()
+	if err != nil {
+		return fmt.Errorf("failed to marshal journal endorsement policy: %w", err)
+	}
+	if err := stub.SetStateValidationParameter(entryKey, policyBytes); err != nil {
+		return fmt.Errorf("failed to set journal endorsement policy: %w", err)
+	}
+
+	return nil
+}
+
+// CosignEntry records the regulator co-signature on a pending entry. Co-signed entries are final.
+func (js *DecisionJournalService) CosignEntry(stub shim.ChaincodeStubInterface, entityID, entryID, actorID, comment string) (*DecisionJournalEntry, error) {
+	entry, err := js.GetEntry(stub, entityID, entryID)
+	if err != nil {
+		return nil, err
+	}
+	if entry.Status != JournalEntryPendingCosign {
+		return nil, NewChaincodeError(ErrCodeInvalidTransition, "", "decision journal entry %s is already %s", entryID, entry.Status)
+	}
+
+	mspID, err := InvokerMSPID(stub)
+	if err != nil {
+		return nil, err
+	}
+	cosignedDate, err := TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	entry.Status = JournalEntryCosigned
+	entry.CosignedBy = actorID
+	entry.CosignedByMSP = mspID
+	entry.CosignedDate = &cosignedDate
+	entry.CosignTxID = stub.GetTxID()
+	entry.CosignComment = comment
+
+	entryKey, err := js.entryKey(stub, entityID, entryID)
+	if err != nil {
+		return nil, err
+	}
+	if err := js.persistenceService.Put(stub, entryKey, entry); err != nil {
+		return nil, err
+	}
+
+	return entry, nil
+}
+
+// GetEntry retrieves a single journal entry
+func (js *DecisionJournalService) GetEntry(stub shim.ChaincodeStubInterface, entityID, entryID string) (*DecisionJournalEntry, error) {
+	entryKey, err := js.entryKey(stub, entityID, entryID)
+	if err != nil {
+		return nil, err
+	}
+
+	var entry DecisionJournalEntry
+	if err := js.persistenceService.Get(stub, entryKey, &entry); err != nil {
+		return nil, fmt.Errorf("decision journal entry not found: %w", err)
+	}
+	return &entry, nil
+}
+
+// GetEntries retrieves every journal entry recorded against an entity
+func (js *DecisionJournalService) GetEntries(stub shim.ChaincodeStubInterface, entityID string) ([]DecisionJournalEntry, error) {
+	iterator, err := stub.GetStateByPartialCompositeKey(config.DecisionJournalPrefix, []string{entityID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get decision journal: %w", err)
+	}
+	defer iterator.Close()
+
+	entries := []DecisionJournalEntry{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate decision journal: %w", err)
+		}
+
+		var entry DecisionJournalEntry
+		if err := utils.UnmarshalJSON(response.Value, &entry); err != nil {
+			return nil, err
+		}
+		entries = append(entries, entry)
+	}
+
+	return entries, nil
+}
+
+func (js *DecisionJournalService) entryKey(stub shim.ChaincodeStubInterface, entityID, entryID string) (string, error) {
+	entryKey, err := stub.CreateCompositeKey(config.DecisionJournalPrefix, []string{entityID, entryID})
+	if err != nil {
+		return "", fmt.Errorf("failed to create decision journal key: %w", err)
+	}
+	return entryKey, nil
+}
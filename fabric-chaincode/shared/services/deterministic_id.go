@@ -0,0 +1,71 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+)
+
+// IDGenerator derives the IDs of one transaction from its transaction ID and the number of IDs
+// with the same prefix generated before them
+type IDGenerator struct {
+	txID   string
+	counts map[string]int
+}
+
+// NewIDGenerator creates the ID generator of a transaction
+func NewIDGenerator(txID string) *IDGenerator {
+	return &IDGenerator{txID: txID, counts: map[string]int{}}
+}
+
+// Next returns the transaction's next ID with the given prefix
+func (g *IDGenerator) Next(prefix string) string {
+	id, _ := g.NextSequenced(prefix)
+	return id
+}
+
+// NextSequenced returns the transaction's next ID with the given prefix and the number of IDs
+// with that prefix generated before it
+func (g *IDGenerator) NextSequenced(prefix string) (string, int) {
+	counter := g.counts[prefix]
+	g.counts[prefix]++
+
+	hash := sha256.Sum256([]byte(fmt.Sprintf("%s\x00%s\x00%d", g.txID, prefix, counter)))
+	return fmt.Sprintf("%s_%s", prefix, hex.EncodeToString(hash[:8])), counter
+}
+
+// GenerateDeterministicID creates an identifier with the given prefix derived from the transaction ID
+// and the number of IDs with that prefix generated so far in the invocation. Every endorsing peer
+// runs the same code in the same order, so every peer derives the same IDs, unlike utils.GenerateID
+// which mixes in the peer's clock and random bytes.
+func GenerateDeterministicID(stub shim.ChaincodeStubInterface, prefix string) string {
+	return invocationOf(stub).IDs().Next(prefix)
+}
+
+// GenerateSequencedID creates an identifier as GenerateDeterministicID does and also returns the
+// number of IDs with the prefix generated before it in the invocation, which orders records posted
+// by the same transaction
+func GenerateSequencedID(stub shim.ChaincodeStubInterface, prefix string) (string, int) {
+	return invocationOf(stub).IDs().NextSequenced(prefix)
+}
+
+// NewHistoryKey returns the ID and composite key of an entity's next history entry in the
+// transaction. The key is HISTORY~entityID~time~sequence~historyID, the transaction time in
+// zero-padded nanoseconds and the sequence numbering the transaction's history entries, so that
+// iterating an entity's history returns its entries in the order they were recorded rather than
+// in the order of their hashed IDs.
+func NewHistoryKey(stub shim.ChaincodeStubInterface, entityID string) (string, string, error) {
+	now, err := TxTime(stub)
+	if err != nil {
+		return "", "", err
+	}
+	historyID, sequence := GenerateSequencedID(stub, config.HistoryPrefix)
+	key, err := stub.CreateCompositeKey("HISTORY", []string{entityID, fmt.Sprintf("%019d", now.UnixNano()), fmt.Sprintf("%06d", sequence), historyID})
+	if err != nil {
+		return "", "", fmt.Errorf("failed to create composite key: %w", err)
+	}
+	return historyID, key, nil
+}
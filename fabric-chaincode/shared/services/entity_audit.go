@@ -0,0 +1,276 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+)
+
+// EntityAuditFormat identifies the layout of entity audit exports and how their hashes chain.
+// Each entry hash is the hex SHA-256 of the previous entry hash followed by the entry encoded as
+// JSON with entryHash empty; the first entry chains from an empty hash. The head hash is the last
+// entry hash, so dropping, inserting, altering or reordering any entry changes it.
+const EntityAuditFormat = "origin.block/entity-audit/v1"
+
+// Sources of entity audit entries
+const (
+	EntityAuditSourceLedger  = "LEDGER"      // A write to one of the entity's records, from the ledger history
+	EntityAuditSourceHistory = "APPLICATION" // A field-level change recorded by a handler under HISTORY
+)
+
+// EntityAuditEntry is one change to an entity, in chronological order
+type EntityAuditEntry struct {
+	Sequence      int             `json:"sequence"`
+	Source        string          `json:"source"`
+	Key           string          `json:"key"` // Ledger key written, or the history entry ID
+	TransactionID string          `json:"transactionID"`
+	Timestamp     time.Time       `json:"timestamp"`
+	IsDelete      bool            `json:"isDelete,omitempty"`
+	Value         json.RawMessage `json:"value,omitempty"`
+	PreviousHash  string          `json:"previousHash"`
+	EntryHash     string          `json:"entryHash"`
+}
+
+// EntityAudit is the full chronological history of an entity with its hash chain. The export is
+// determined by the ledger alone, so an auditor can export again from any peer and compare head
+// hashes; who exported it and when are not part of the chain.
+type EntityAudit struct {
+	Format        string             `json:"format"`
+	EntityID      string             `json:"entityID"`
+	RecordKeys    []string           `json:"recordKeys"`
+	Entries       []EntityAuditEntry `json:"entries"`
+	EntryCount    int                `json:"entryCount"`
+	HeadHash      string             `json:"headHash"`
+	ExportedByMSP string             `json:"exportedByMSP"`
+	ExportedDate  time.Time          `json:"exportedDate"`
+}
+
+// EntityAuditVerification reports whether an exported entity audit is intact and complete
+type EntityAuditVerification struct {
+	EntityID       string   `json:"entityID"`
+	Valid          bool     `json:"valid"`
+	Problems       []string `json:"problems"`
+	LedgerHeadHash string   `json:"ledgerHeadHash"`
+	LedgerEntries  int      `json:"ledgerEntries"`
+}
+
+// EntityAuditService assembles and verifies entity audit exports
+type EntityAuditService struct{}
+
+// NewEntityAuditService creates a new entity audit service
+func NewEntityAuditService() *EntityAuditService {
+	return &EntityAuditService{}
+}
+
+// Export assembles the ledger history of each record key with the entity's HISTORY entries,
+// orders them by transaction time and chains their hashes
+func (s *EntityAuditService) Export(stub shim.ChaincodeStubInterface, entityID string, recordKeys []string, exportedByMSP string) (*EntityAudit, error) {
+	entries := []EntityAuditEntry{}
+	txTimes := make(map[string]time.Time)
+	for _, key := range recordKeys {
+		ledgerEntries, err := ledgerAuditEntries(stub, key)
+		if err != nil {
+			return nil, err
+		}
+		for _, entry := range ledgerEntries {
+			txTimes[entry.TransactionID] = entry.Timestamp
+		}
+		entries = append(entries, ledgerEntries...)
+	}
+	historyEntries, err := historyAuditEntries(stub, entityID, txTimes)
+	if err != nil {
+		return nil, err
+	}
+	entries = append(entries, historyEntries...)
+
+	sort.SliceStable(entries, func(i, j int) bool {
+		a, b := entries[i], entries[j]
+		if !a.Timestamp.Equal(b.Timestamp) {
+			return a.Timestamp.Before(b.Timestamp)
+		}
+		if a.TransactionID != b.TransactionID {
+			return a.TransactionID < b.TransactionID
+		}
+		if a.Source != b.Source {
+			return a.Source == EntityAuditSourceLedger
+		}
+		return a.Key < b.Key
+	})
+
+	previousHash := ""
+	for i := range entries {
+		entries[i].Sequence = i + 1
+		entries[i].PreviousHash = previousHash
+		hash, err := hashEntityAuditEntry(entries[i])
+		if err != nil {
+			return nil, err
+		}
+		entries[i].EntryHash = hash
+		previousHash = hash
+	}
+
+	exportedDate, err := TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	return &EntityAudit{
+		Format:        EntityAuditFormat,
+		EntityID:      entityID,
+		RecordKeys:    recordKeys,
+		Entries:       entries,
+		EntryCount:    len(entries),
+		HeadHash:      previousHash,
+		ExportedByMSP: exportedByMSP,
+		ExportedDate:  exportedDate,
+	}, nil
+}
+
+// Verify checks an export's hash chain and compares it with a fresh export of the same entity, so
+// entries omitted, added or reordered since the ledger was read are reported
+func (s *EntityAuditService) Verify(stub shim.ChaincodeStubInterface, audit *EntityAudit, recordKeys []string) (*EntityAuditVerification, error) {
+	result := &EntityAuditVerification{EntityID: audit.EntityID, Problems: VerifyEntityAuditChain(audit)}
+
+	current, err := s.Export(stub, audit.EntityID, recordKeys, "")
+	if err != nil {
+		return nil, err
+	}
+	result.LedgerHeadHash = current.HeadHash
+	result.LedgerEntries = current.EntryCount
+	if len(audit.Entries) != current.EntryCount {
+		result.Problems = append(result.Problems, fmt.Sprintf("export has %d entries, the ledger has %d", len(audit.Entries), current.EntryCount))
+	}
+	if audit.HeadHash != current.HeadHash {
+		result.Problems = append(result.Problems, "head hash does not match the ledger")
+	}
+
+	result.Valid = len(result.Problems) == 0
+	return result, nil
+}
+
+// VerifyEntityAuditChain recomputes an export's hash chain offline and reports each entry that is
+// out of sequence, does not chain from its predecessor or has been altered
+func VerifyEntityAuditChain(audit *EntityAudit) []string {
+	problems := []string{}
+	if audit.Format != EntityAuditFormat {
+		problems = append(problems, fmt.Sprintf("unknown export format %s", audit.Format))
+	}
+	previousHash := ""
+	for i, entry := range audit.Entries {
+		if entry.Sequence != i+1 {
+			problems = append(problems, fmt.Sprintf("entry %d is out of sequence (sequence %d)", i+1, entry.Sequence))
+		}
+		if entry.PreviousHash != previousHash {
+			problems = append(problems, fmt.Sprintf("entry %d does not chain from the entry before it", i+1))
+		}
+		hash, err := hashEntityAuditEntry(entry)
+		if err != nil || hash != entry.EntryHash {
+			problems = append(problems, fmt.Sprintf("entry %d has been altered", i+1))
+		}
+		previousHash = entry.EntryHash
+	}
+	if audit.EntryCount != len(audit.Entries) {
+		problems = append(problems, fmt.Sprintf("export lists %d entries but declares %d", len(audit.Entries), audit.EntryCount))
+	}
+	if audit.HeadHash != previousHash {
+		problems = append(problems, "head hash does not match the last entry")
+	}
+	return problems
+}
+
+// ledgerAuditEntries returns every write to a key from the ledger history
+func ledgerAuditEntries(stub shim.ChaincodeStubInterface, key string) ([]EntityAuditEntry, error) {
+	iterator, err := stub.GetHistoryForKey(key)
+	if err != nil {
+		return nil, fmt.Errorf("failed to get history for key %s: %w", key, err)
+	}
+	defer iterator.Close()
+
+	entries := []EntityAuditEntry{}
+	for iterator.HasNext() {
+		modification, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate history for key %s: %w", key, err)
+		}
+		entry := EntityAuditEntry{
+			Source:        EntityAuditSourceLedger,
+			Key:           key,
+			TransactionID: modification.TxId,
+			IsDelete:      modification.IsDelete,
+			Value:         auditValue(modification.Value),
+		}
+		if modification.Timestamp != nil {
+			entry.Timestamp = time.Unix(modification.Timestamp.Seconds, int64(modification.Timestamp.Nanos)).UTC()
+		}
+		entries = append(entries, entry)
+	}
+	return entries, nil
+}
+
+// historyAuditEntries returns the HISTORY entries recorded for an entity. Entries written by a
+// transaction that also wrote one of the entity's records take that write's ledger timestamp,
+// since handlers record history timestamps to the second.
+func historyAuditEntries(stub shim.ChaincodeStubInterface, entityID string, txTimes map[string]time.Time) ([]EntityAuditEntry, error) {
+	iterator, err := stub.GetStateByPartialCompositeKey("HISTORY", []string{entityID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get history iterator: %w", err)
+	}
+	defer iterator.Close()
+
+	entries := []EntityAuditEntry{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate history: %w", err)
+		}
+		var recorded struct {
+			HistoryID     string `json:"historyID"`
+			Timestamp     string `json:"timestamp"`
+			TransactionID string `json:"transactionID"`
+		}
+		if err := json.Unmarshal(response.Value, &recorded); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal history entry: %w", err)
+		}
+		timestamp, ok := txTimes[recorded.TransactionID]
+		if !ok {
+			if timestamp, err = utils.ParseTime(recorded.Timestamp); err != nil {
+				return nil, fmt.Errorf("history entry %s: %w", recorded.HistoryID, err)
+			}
+		}
+		entries = append(entries, EntityAuditEntry{
+			Source:        EntityAuditSourceHistory,
+			Key:           recorded.HistoryID,
+			TransactionID: recorded.TransactionID,
+			Timestamp:     timestamp.UTC(),
+			Value:         json.RawMessage(response.Value),
+		})
+	}
+	return entries, nil
+}
+
+// auditValue returns a stored value as JSON, quoting values that are not JSON themselves
+func auditValue(value []byte) json.RawMessage {
+	if len(value) == 0 {
+		return nil
+	}
+	if json.Valid(value) {
+		return json.RawMessage(value)
+	}
+	quoted, _ := json.Marshal(string(value))
+	return json.RawMessage(quoted)
+}
+
+func hashEntityAuditEntry(entry EntityAuditEntry) (string, error) {
+	entry.EntryHash = ""
+	entryBytes, err := json.Marshal(entry)
+	if err != nil {
+		return "", fmt.Errorf("failed to encode entity audit entry: %w", err)
+	}
+	hash := sha256.Sum256(append([]byte(entry.PreviousHash), entryBytes...))
+	return hex.EncodeToString(hash[:]), nil
+}
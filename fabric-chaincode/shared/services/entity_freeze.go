@@ -0,0 +1,146 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+)
+
+// Entity types compliance may freeze
+const (
+	FreezeEntityCustomer = "Customer"
+	FreezeEntityLoan     = "LoanApplication"
+)
+
+// FreezeReasonCode classifies why compliance froze an entity
+type FreezeReasonCode string
+
+const (
+	FreezeReasonLegalHold          FreezeReasonCode = "LEGAL_HOLD"
+	FreezeReasonCourtOrder         FreezeReasonCode = "COURT_ORDER"
+	FreezeReasonSanctions          FreezeReasonCode = "SANCTIONS"
+	FreezeReasonFraudInvestigation FreezeReasonCode = "FRAUD_INVESTIGATION"
+	FreezeReasonRegulatorRequest   FreezeReasonCode = "REGULATOR_REQUEST"
+)
+
+// ValidFreezeReasonCodes lists the reason codes a freeze may be placed under
+var ValidFreezeReasonCodes = map[FreezeReasonCode]bool{
+	FreezeReasonLegalHold:          true,
+	FreezeReasonCourtOrder:         true,
+	FreezeReasonSanctions:          true,
+	FreezeReasonFraudInvestigation: true,
+	FreezeReasonRegulatorRequest:   true,
+}
+
+// FreezeStatus is the state of an entity freeze
+type FreezeStatus string
+
+const (
+	FreezeStatusActive   FreezeStatus = "ACTIVE"
+	FreezeStatusReleased FreezeStatus = "RELEASED"
+)
+
+// EntityFreeze is an emergency freeze placed on an entity by compliance. While active it blocks
+// every state-changing function on the entity except compliance actions. It is lifted by a second
+// compliance officer approving the unfreeze another requested, and is kept once released.
+type EntityFreeze struct {
+	EntityID        string           `json:"entityID"`
+	EntityType      string           `json:"entityType"`
+	Status          FreezeStatus     `json:"status"`
+	ReasonCode      FreezeReasonCode `json:"reasonCode"`
+	Reason          string           `json:"reason"`
+	CaseReference   string           `json:"caseReference,omitempty"` // Legal or investigation case the freeze serves
+	FrozenBy        string           `json:"frozenBy"`
+	FrozenDate      time.Time        `json:"frozenDate"`
+	TxID            string           `json:"txID"`
+	UnfreezeRequest *UnfreezeRequest `json:"unfreezeRequest,omitempty"` // Pending request to lift the freeze
+	ReleasedBy      string           `json:"releasedBy,omitempty"`
+	ReleasedDate    *time.Time       `json:"releasedDate,omitempty"`
+	ReleaseReason   string           `json:"releaseReason,omitempty"`
+}
+
+// UnfreezeRequest is a compliance officer's request to lift a freeze, awaiting a second officer
+type UnfreezeRequest struct {
+	RequestedBy   string    `json:"requestedBy"`
+	Reason        string    `json:"reason"`
+	RequestedDate time.Time `json:"requestedDate"`
+}
+
+// Active reports whether the freeze still blocks the entity
+func (f *EntityFreeze) Active() bool {
+	return f.Status == FreezeStatusActive
+}
+
+// EntityFreezeService stores entity freezes and checks actions against them
+type EntityFreezeService struct {
+	persistenceService *PersistenceService
+}
+
+// NewEntityFreezeService creates a new entity freeze service
+func NewEntityFreezeService() *EntityFreezeService {
+	return &EntityFreezeService{
+		persistenceService: NewPersistenceService(),
+	}
+}
+
+// GetFreeze retrieves the latest freeze on an entity, active or released, or nil when it has none
+func (fs *EntityFreezeService) GetFreeze(stub shim.ChaincodeStubInterface, entityType, entityID string) (*EntityFreeze, error) {
+	freezeKey, err := fs.freezeKey(stub, entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := fs.persistenceService.Exists(stub, freezeKey)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	var freeze EntityFreeze
+	if err := fs.persistenceService.Get(stub, freezeKey, &freeze); err != nil {
+		return nil, fmt.Errorf("failed to get entity freeze: %w", err)
+	}
+	return &freeze, nil
+}
+
+// PutFreeze stores a freeze, replacing any earlier freeze on the entity
+func (fs *EntityFreezeService) PutFreeze(stub shim.ChaincodeStubInterface, freeze *EntityFreeze) error {
+	freezeKey, err := fs.freezeKey(stub, freeze.EntityType, freeze.EntityID)
+	if err != nil {
+		return err
+	}
+	if err := fs.persistenceService.Put(stub, freezeKey, freeze); err != nil {
+		return fmt.Errorf("failed to store entity freeze: %w", err)
+	}
+	return nil
+}
+
+// IsFrozen reports whether an entity is under an active freeze
+func (fs *EntityFreezeService) IsFrozen(stub shim.ChaincodeStubInterface, entityType, entityID string) (bool, error) {
+	freeze, err := fs.GetFreeze(stub, entityType, entityID)
+	if err != nil {
+		return false, err
+	}
+	return freeze != nil && freeze.Active(), nil
+}
+
+// CheckNotFrozen rejects an action on an entity under an active freeze
+func (fs *EntityFreezeService) CheckNotFrozen(stub shim.ChaincodeStubInterface, entityType, entityID string) error {
+	freeze, err := fs.GetFreeze(stub, entityType, entityID)
+	if err != nil || freeze == nil || !freeze.Active() {
+		return err
+	}
+	return NewChaincodeError(ErrCodeInvalidTransition, "", "%s %s is frozen (%s): only compliance actions are permitted", entityType, entityID, freeze.ReasonCode)
+}
+
+func (fs *EntityFreezeService) freezeKey(stub shim.ChaincodeStubInterface, entityType, entityID string) (string, error) {
+	freezeKey, err := stub.CreateCompositeKey(config.EntityFreezePrefix, []string{entityType, entityID})
+	if err != nil {
+		return "", fmt.Errorf("failed to create entity freeze key: %w", err)
+	}
+	return freezeKey, nil
+}
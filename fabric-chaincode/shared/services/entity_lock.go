@@ -0,0 +1,122 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+)
+
+// EntityLock is a short-lived advisory claim by one actor on an entity, such as an underwriter
+// working an application. Functions that check claims reject actions by anyone else while the
+// claim is live; once it expires it no longer blocks anyone and may be claimed afresh.
+type EntityLock struct {
+	EntityID           string    `json:"entityID"`
+	EntityType         string    `json:"entityType"`
+	HolderID           string    `json:"holderID"`
+	ClaimedDate        time.Time `json:"claimedDate"`
+	ExpiresDate        time.Time `json:"expiresDate"`
+	TxID               string    `json:"txID"`
+	OverriddenHolderID string    `json:"overriddenHolderID,omitempty"` // Holder whose live claim a supervisor took over
+	OverrideReason     string    `json:"overrideReason,omitempty"`
+}
+
+// Live reports whether the claim is still in force at a time
+func (l *EntityLock) Live(at time.Time) bool {
+	return at.Before(l.ExpiresDate)
+}
+
+// EntityLockService stores entity claims and checks actions against them
+type EntityLockService struct {
+	persistenceService *PersistenceService
+}
+
+// NewEntityLockService creates a new entity lock service
+func NewEntityLockService() *EntityLockService {
+	return &EntityLockService{
+		persistenceService: NewPersistenceService(),
+	}
+}
+
+// GetLock retrieves the claim on an entity, live or expired, or nil when it has none
+func (ls *EntityLockService) GetLock(stub shim.ChaincodeStubInterface, entityType, entityID string) (*EntityLock, error) {
+	lockKey, err := ls.lockKey(stub, entityType, entityID)
+	if err != nil {
+		return nil, err
+	}
+
+	exists, err := ls.persistenceService.Exists(stub, lockKey)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	var lock EntityLock
+	if err := ls.persistenceService.Get(stub, lockKey, &lock); err != nil {
+		return nil, fmt.Errorf("failed to get entity lock: %w", err)
+	}
+	return &lock, nil
+}
+
+// PutLock stores a claim, replacing any earlier claim on the entity
+func (ls *EntityLockService) PutLock(stub shim.ChaincodeStubInterface, lock *EntityLock) error {
+	lockKey, err := ls.lockKey(stub, lock.EntityType, lock.EntityID)
+	if err != nil {
+		return err
+	}
+	if err := ls.persistenceService.Put(stub, lockKey, lock); err != nil {
+		return fmt.Errorf("failed to store entity lock: %w", err)
+	}
+	return nil
+}
+
+// DeleteLock removes the claim on an entity
+func (ls *EntityLockService) DeleteLock(stub shim.ChaincodeStubInterface, entityType, entityID string) error {
+	lockKey, err := ls.lockKey(stub, entityType, entityID)
+	if err != nil {
+		return err
+	}
+	if err := stub.DelState(lockKey); err != nil {
+		return fmt.Errorf("failed to delete entity lock: %w", err)
+	}
+	return nil
+}
+
+// CheckLock rejects an action by an actor on an entity another actor holds a live claim on.
+// Unclaimed entities and expired claims do not block anyone.
+func (ls *EntityLockService) CheckLock(stub shim.ChaincodeStubInterface, entityType, entityID, actorID string) error {
+	lock, err := ls.GetLock(stub, entityType, entityID)
+	if err != nil || lock == nil {
+		return err
+	}
+
+	now, err := TxTime(stub)
+	if err != nil {
+		return err
+	}
+	if lock.Live(now) && lock.HolderID != actorID {
+		return NewChaincodeError(ErrCodeConflict, "", "%s %s is claimed by %s until %s", entityType, entityID, lock.HolderID, lock.ExpiresDate.Format(time.RFC3339))
+	}
+	return nil
+}
+
+// ReleaseHeld removes an actor's own claim on an entity once their action on it is complete. Claims
+// held by others are left in place.
+func (ls *EntityLockService) ReleaseHeld(stub shim.ChaincodeStubInterface, entityType, entityID, actorID string) error {
+	lock, err := ls.GetLock(stub, entityType, entityID)
+	if err != nil || lock == nil || lock.HolderID != actorID {
+		return err
+	}
+	return ls.DeleteLock(stub, entityType, entityID)
+}
+
+func (ls *EntityLockService) lockKey(stub shim.ChaincodeStubInterface, entityType, entityID string) (string, error) {
+	lockKey, err := stub.CreateCompositeKey(config.EntityLockPrefix, []string{entityType, entityID})
+	if err != nil {
+		return "", fmt.Errorf("failed to create entity lock key: %w", err)
+	}
+	return lockKey, nil
+}
@@ -0,0 +1,214 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// noteScopeIndex lists an entity's notes per reader scope, oldest first. Internal notes are indexed
+// under both scopes, compliance-only notes under the compliance scope alone, so each reader pages
+// through exactly the notes they may see.
+const noteScopeIndex = "ENTITY_NOTE_BY_SCOPE"
+
+// noteIndexTimeFormat is a fixed-width UTC format, so index keys sort chronologically
+const noteIndexTimeFormat = "2006-01-02T15:04:05.000000000Z"
+
+// EntityNote is an operational note left by staff on a customer, loan or other entity. The text
+// may be edited by its author; every version is kept as an EntityNoteRevision.
+type EntityNote struct {
+	NoteID         string     `json:"noteID"`
+	EntityID       string     `json:"entityID"`
+	EntityType     string     `json:"entityType"`
+	Text           string     `json:"text"`
+	Visibility     string     `json:"visibility"`
+	Revision       int        `json:"revision"`
+	AuthorID       string     `json:"authorID"`
+	CreatedDate    time.Time  `json:"createdDate"`
+	CreatedTxID    string     `json:"createdTxID"`
+	LastEditedDate *time.Time `json:"lastEditedDate,omitempty"`
+}
+
+// EntityNoteRevision is one version of a note's text. Revisions are written once and never changed.
+type EntityNoteRevision struct {
+	NoteID       string    `json:"noteID"`
+	EntityID     string    `json:"entityID"`
+	Revision     int       `json:"revision"`
+	Text         string    `json:"text"`
+	RecordedBy   string    `json:"recordedBy"`
+	RecordedDate time.Time `json:"recordedDate"`
+	TxID         string    `json:"txID"`
+}
+
+// EntityNoteService stores entity notes, their revision history and their reader scope indexes
+type EntityNoteService struct {
+	persistenceService *PersistenceService
+}
+
+// NewEntityNoteService creates a new entity note service
+func NewEntityNoteService() *EntityNoteService {
+	return &EntityNoteService{
+		persistenceService: NewPersistenceService(),
+	}
+}
+
+// AddNote stores a new note as its first revision. The note ID, creation date and revision come from
+// the transaction, so callers only set the entity, text, visibility and author.
+func (ns *EntityNoteService) AddNote(stub shim.ChaincodeStubInterface, note *EntityNote) error {
+	if err := validation.ValidateNoteVisibility(note.Visibility); err != nil {
+		return fmt.Errorf("invalid visibility: %w", err)
+	}
+
+	now, err := TxTime(stub)
+	if err != nil {
+		return err
+	}
+	note.NoteID = stub.GetTxID()
+	note.Revision = 1
+	note.CreatedDate = now
+	note.CreatedTxID = stub.GetTxID()
+
+	noteKey, err := ns.noteKey(stub, note.EntityID, note.NoteID)
+	if err != nil {
+		return err
+	}
+	exists, err := ns.persistenceService.Exists(stub, noteKey)
+	if err != nil {
+		return err
+	}
+	if exists {
+		return fmt.Errorf("note %s already exists", note.NoteID)
+	}
+
+	if err := ns.persistenceService.Put(stub, noteKey, note); err != nil {
+		return err
+	}
+	if err := ns.putRevision(stub, note, note.AuthorID, now); err != nil {
+		return err
+	}
+
+	scopes := []string{string(validation.NoteVisibilityComplianceOnly)}
+	if note.Visibility == string(validation.NoteVisibilityInternal) {
+		scopes = append(scopes, string(validation.NoteVisibilityInternal))
+	}
+	for _, scope := range scopes {
+		indexKey, err := stub.CreateCompositeKey(noteScopeIndex, []string{note.EntityID, scope, now.Format(noteIndexTimeFormat), note.NoteID})
+		if err != nil {
+			return fmt.Errorf("failed to create composite key: %w", err)
+		}
+		if err := stub.PutState(indexKey, []byte(note.NoteID)); err != nil {
+			return fmt.Errorf("failed to create %s index: %w", noteScopeIndex, err)
+		}
+	}
+
+	return nil
+}
+
+// EditNote replaces a note's text, keeping the previous text in its revision history
+func (ns *EntityNoteService) EditNote(stub shim.ChaincodeStubInterface, note *EntityNote, text, actorID string) error {
+	now, err := TxTime(stub)
+	if err != nil {
+		return err
+	}
+	note.Text = text
+	note.Revision++
+	note.LastEditedDate = &now
+
+	noteKey, err := ns.noteKey(stub, note.EntityID, note.NoteID)
+	if err != nil {
+		return err
+	}
+	if err := ns.persistenceService.Put(stub, noteKey, note); err != nil {
+		return err
+	}
+	return ns.putRevision(stub, note, actorID, now)
+}
+
+// GetNote retrieves a note
+func (ns *EntityNoteService) GetNote(stub shim.ChaincodeStubInterface, entityID, noteID string) (*EntityNote, error) {
+	noteKey, err := ns.noteKey(stub, entityID, noteID)
+	if err != nil {
+		return nil, err
+	}
+
+	var note EntityNote
+	if err := ns.persistenceService.Get(stub, noteKey, &note); err != nil {
+		return nil, fmt.Errorf("note not found: %w", err)
+	}
+	return &note, nil
+}
+
+// GetNotesPage returns a page of an entity's notes visible to a reader scope, oldest first
+func (ns *EntityNoteService) GetNotesPage(stub shim.ChaincodeStubInterface, entityID, scope string, pageSize int, bookmark string) ([]EntityNote, string, error) {
+	entries, nextBookmark, err := ns.persistenceService.GetPageByPartialCompositeKeys(stub, noteScopeIndex, [][]string{{entityID, scope}}, pageSize, bookmark)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get notes: %w", err)
+	}
+
+	notes := []EntityNote{}
+	for _, entry := range entries {
+		note, err := ns.GetNote(stub, entityID, string(entry.Value))
+		if err != nil {
+			continue // Skip if note not found
+		}
+		notes = append(notes, *note)
+	}
+	return notes, nextBookmark, nil
+}
+
+// GetRevisions returns every version of a note's text, oldest first
+func (ns *EntityNoteService) GetRevisions(stub shim.ChaincodeStubInterface, noteID string) ([]EntityNoteRevision, error) {
+	iterator, err := stub.GetStateByPartialCompositeKey(config.EntityNoteRevisionPrefix, []string{noteID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get note revisions: %w", err)
+	}
+	defer iterator.Close()
+
+	revisions := []EntityNoteRevision{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate note revisions: %w", err)
+		}
+
+		var revision EntityNoteRevision
+		if err := utils.UnmarshalJSON(response.Value, &revision); err != nil {
+			return nil, err
+		}
+		revisions = append(revisions, revision)
+	}
+	return revisions, nil
+}
+
+func (ns *EntityNoteService) putRevision(stub shim.ChaincodeStubInterface, note *EntityNote, actorID string, recordedDate time.Time) error {
+	revisionKey, err := stub.CreateCompositeKey(config.EntityNoteRevisionPrefix, []string{note.NoteID, fmt.Sprintf("%06d", note.Revision)})
+	if err != nil {
+		return fmt.Errorf("failed to create note revision key: %w", err)
+	}
+
+	revision := &EntityNoteRevision{
+		NoteID:       note.NoteID,
+		EntityID:     note.EntityID,
+		Revision:     note.Revision,
+		Text:         note.Text,
+		RecordedBy:   actorID,
+		RecordedDate: recordedDate,
+		TxID:         stub.GetTxID(),
+	}
+	if err := ns.persistenceService.Put(stub, revisionKey, revision); err != nil {
+		return fmt.Errorf("failed to store note revision: %w", err)
+	}
+	return nil
+}
+
+func (ns *EntityNoteService) noteKey(stub shim.ChaincodeStubInterface, entityID, noteID string) (string, error) {
+	noteKey, err := stub.CreateCompositeKey(config.EntityNotePrefix, []string{entityID, noteID})
+	if err != nil {
+		return "", fmt.Errorf("failed to create note key: %w", err)
+	}
+	return noteKey, nil
+}
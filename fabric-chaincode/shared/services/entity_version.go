@@ -0,0 +1,47 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// ErrorCodeVersionConflict is returned when an update names a version other than the stored one
+const ErrorCodeVersionConflict = ErrCodeConflict
+
+// VersionConflictError is the structured error returned when an update was made against a stale
+// read. It encodes as the error envelope plus the versions, so clients re-read the entity and retry
+// against its current version.
+type VersionConflictError struct {
+	Code            string `json:"code"`
+	Message         string `json:"message"`
+	Retryable       bool   `json:"retryable"`
+	EntityType      string `json:"entityType"`
+	EntityID        string `json:"entityID"`
+	ExpectedVersion int    `json:"expectedVersion"`
+	CurrentVersion  int    `json:"currentVersion"`
+}
+
+// Error renders the error as JSON so clients can parse the code and current version
+func (e *VersionConflictError) Error() string {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Sprintf("%s: %s %s is at version %d, expected %d", e.Code, e.EntityType, e.EntityID, e.CurrentVersion, e.ExpectedVersion)
+	}
+	return string(data)
+}
+
+// CheckExpectedVersion rejects an update whose expected version differs from the entity's stored
+// version. Updates that name no expected version are applied as before.
+func CheckExpectedVersion(entityType, entityID string, expected *int, current int) error {
+	if expected == nil || *expected == current {
+		return nil
+	}
+	return &VersionConflictError{
+		Code:            ErrorCodeVersionConflict,
+		Message:         fmt.Sprintf("%s %s is at version %d, expected %d", entityType, entityID, current, *expected),
+		EntityType:      entityType,
+		EntityID:        entityID,
+		ExpectedVersion: *expected,
+		CurrentVersion:  current,
+	}
+}
@@ -0,0 +1,159 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Error codes of the chaincode error envelope. Clients branch on the code; the message is for
+// people and may change.
+const (
+	ErrCodeInvalidArgument   = "ERR_INVALID_ARGUMENT"   // The request is malformed or fails validation
+	ErrCodeNotFound          = "ERR_NOT_FOUND"          // A record the request names does not exist
+	ErrCodeAccessDenied      = "ERR_ACCESS_DENIED"      // The invoker may not perform the request
+	ErrCodeInvalidTransition = "ERR_INVALID_TRANSITION" // The record's current state does not allow the request
+	ErrCodeConflict          = "ERR_CONFLICT"           // The request duplicates or collides with existing state
+	ErrCodeUnknownFunction   = "ERR_UNKNOWN_FUNCTION"   // The chaincode has no such function
+	ErrCodeLedger            = "ERR_LEDGER"             // The ledger could not be read or written; the request may be retried
+	ErrCodeInternal          = "ERR_INTERNAL"           // Any other failure
+)
+
+// Sentinels for errors.Is. An error matches the sentinel of its code when it is, or wraps, a
+// ChaincodeError with that code, e.g. errors.Is(err, ErrNotFound).
+var (
+	ErrInvalidArgument   = &ChaincodeError{Code: ErrCodeInvalidArgument}
+	ErrNotFound          = &ChaincodeError{Code: ErrCodeNotFound}
+	ErrAccessDenied      = &ChaincodeError{Code: ErrCodeAccessDenied}
+	ErrInvalidTransition = &ChaincodeError{Code: ErrCodeInvalidTransition}
+	ErrConflict          = &ChaincodeError{Code: ErrCodeConflict}
+	ErrUnknownFunction   = &ChaincodeError{Code: ErrCodeUnknownFunction}
+	ErrLedger            = &ChaincodeError{Code: ErrCodeLedger}
+)
+
+// ChaincodeError is the error envelope every chaincode returns as the message of a failed
+// response, encoded as JSON
+type ChaincodeError struct {
+	Code      string `json:"code"`
+	Message   string `json:"message"`
+	Field     string `json:"field,omitempty"`
+	Retryable bool   `json:"retryable"`
+}
+
+// NewChaincodeError creates an error with an explicit code, naming the request field at fault
+// when there is one
+func NewChaincodeError(code, field, format string, args ...interface{}) *ChaincodeError {
+	return &ChaincodeError{
+		Code:      code,
+		Message:   fmt.Sprintf(format, args...),
+		Field:     field,
+		Retryable: code == ErrCodeLedger,
+	}
+}
+
+// Error returns the message, so a ChaincodeError reads like any other error when wrapped
+func (e *ChaincodeError) Error() string {
+	return e.Message
+}
+
+// Is reports whether target is a ChaincodeError with the same code, so an error matches its
+// code's sentinel whatever its message
+func (e *ChaincodeError) Is(target error) bool {
+	targetErr, ok := target.(*ChaincodeError)
+	return ok && targetErr.Code == e.Code
+}
+
+// JSON encodes the envelope for a response message
+func (e *ChaincodeError) JSON() string {
+	var buffer bytes.Buffer
+	encoder := json.NewEncoder(&buffer)
+	encoder.SetEscapeHTML(false)
+	if err := encoder.Encode(e); err != nil {
+		return fmt.Sprintf(`{"code":%q,"message":%q,"retryable":false}`, ErrCodeInternal, e.Message)
+	}
+	return strings.TrimSpace(buffer.String())
+}
+
+// errorClassifications map the wording of errors created without a code to error codes. Handlers
+// return their argument, not found, access and state errors with NewChaincodeError; the wording is
+// only a last resort for errors that reach ClassifyError uncoded, such as the legacy shim.Error
+// messages. Earlier entries win.
+var errorClassifications = []struct {
+	code    string
+	phrases []string
+}{
+	{ErrCodeUnknownFunction, []string{"unknown function"}},
+	{ErrCodeLedger, []string{"failed to get state", "failed to put state", "failed to delete state", "failed to check existence", "failed to iterate", "failed to get history for key"}},
+	{ErrCodeAccessDenied, []string{"may only", "can only be", "not authorised", "not authorized", "unauthorized", "access denied"}},
+	{ErrCodeNotFound, []string{"not found", "no data found", "does not exist"}},
+	{ErrCodeConflict, []string{"already exists", "already used", "is claimed by"}},
+	{ErrCodeInvalidTransition, []string{"status transition", "current status", "in status", "not permitted for", "is already", "already finalized", "already completed", "is not in force"}},
+	{ErrCodeInvalidArgument, []string{"incorrect number of arguments", "failed to parse", "is required", "are required", "invalid", "must ", "cannot be negative", "validation failed", "validation errors", "malformed", "at least one"}},
+}
+
+// requiredFieldPattern finds the request field an argument error names, e.g. "actorID is required"
+var requiredFieldPattern = regexp.MustCompile(`\b([a-z][A-Za-z0-9]*) (?:is required|are required|must be|cannot be negative)`)
+
+// ClassifyError returns the envelope for an error. An error that is or wraps a ChaincodeError,
+// VersionConflictError or ServiceDisabledError keeps that error's code, with the message of the
+// error as wrapped; only an uncoded error falls back to being classified by its wording.
+func ClassifyError(err error) *ChaincodeError {
+	var chaincodeErr *ChaincodeError
+	if errors.As(err, &chaincodeErr) {
+		if chaincodeErr == err {
+			return chaincodeErr
+		}
+		return &ChaincodeError{Code: chaincodeErr.Code, Message: err.Error(), Field: chaincodeErr.Field, Retryable: chaincodeErr.Retryable}
+	}
+	var conflictErr *VersionConflictError
+	if errors.As(err, &conflictErr) {
+		return &ChaincodeError{Code: conflictErr.Code, Message: conflictErr.Message}
+	}
+	var disabledErr *ServiceDisabledError
+	if errors.As(err, &disabledErr) {
+		return &ChaincodeError{Code: disabledErr.Code, Message: disabledErr.Message, Retryable: disabledErr.Retryable}
+	}
+
+	message := err.Error()
+	lower := strings.ToLower(message)
+	for _, classification := range errorClassifications {
+		for _, phrase := range classification.phrases {
+			if !strings.Contains(lower, phrase) {
+				continue
+			}
+			classified := NewChaincodeError(classification.code, "", "%s", message)
+			if classification.code == ErrCodeInvalidArgument {
+				if match := requiredFieldPattern.FindStringSubmatch(message); match != nil {
+					classified.Field = match[1]
+				}
+			}
+			return classified
+		}
+	}
+	return NewChaincodeError(ErrCodeInternal, "", "%s", message)
+}
+
+// ErrorEnvelope returns the JSON envelope for an error. A ServiceDisabledError keeps its own
+// encoding, which carries the envelope's code, message and retryable flag along with the operator's
+// restoration details; a VersionConflictError likewise adds the entity's current version.
+func ErrorEnvelope(err error) string {
+	var disabledErr *ServiceDisabledError
+	if errors.As(err, &disabledErr) {
+		return disabledErr.Error()
+	}
+	var conflictErr *VersionConflictError
+	if errors.As(err, &conflictErr) {
+		return conflictErr.Error()
+	}
+	return ClassifyError(err).JSON()
+}
+
+// IsErrorEnvelope reports whether a failed response's message is already an error envelope, so
+// contracts converting plain shim.Error messages leave envelopes as they are
+func IsErrorEnvelope(message string) bool {
+	var envelope ChaincodeError
+	return json.Unmarshal([]byte(message), &envelope) == nil && envelope.Code != ""
+}
@@ -5,8 +5,8 @@ import (
 	"fmt"
 
 	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
 	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/interfaces"
-	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
 )
 
 // BaseEventService provides common event emission functionality
@@ -17,42 +17,109 @@ func NewBaseEventService() *BaseEventService {
 	return &BaseEventService{}
 }
 
-// EmitEvent emits a standardized event
+// EmitEvent emits a standardized event stamped with the transaction time; events raised by
+// sandbox actors are tagged so production reporting consumers can exclude them
 func (es *BaseEventService) EmitEvent(stub shim.ChaincodeStubInterface, eventName string, payload interfaces.EventPayload) error {
-	payloadBytes, err := json.Marshal(payload)
+	timestamp, err := TxTimeString(stub)
 	if err != nil {
-		return fmt.Errorf("failed to marshal event payload: %v", err)
+		return err
+	}
+	payload.Timestamp = timestamp
+
+	sandbox, err := NewSandboxService().IsSandboxActor(stub, payload.ActorID)
+	if err != nil {
+		return fmt.Errorf("failed to check sandbox actor: %w", err)
+	}
+	if sandbox {
+		if payload.Metadata == nil {
+			payload.Metadata = make(map[string]string)
+		}
+		payload.Metadata["sandbox"] = "true"
+	}
+	
+	event := interfaces.VersionedEvent{
+		EventHeader:  NewEventHeader(eventName),
+		EventPayload: payload,
+	}
+	payloadBytes, err := json.Marshal(event)
+	if err != nil {
+		return fmt.Errorf("failed to marshal event payload: %w", err)
 	}
 	
 	if err := stub.SetEvent(eventName, payloadBytes); err != nil {
-		return fmt.Errorf("failed to emit event %s: %v", eventName, err)
+		return fmt.Errorf("failed to emit event %s: %w", eventName, err)
 	}
 	
 	return nil
 }
 
-// CreateEventPayload creates a standardized event payload
+// CreateEventPayload creates a standardized event payload. EmitEvent sets its timestamp.
 func (es *BaseEventService) CreateEventPayload(eventType, entityID, entityType, actorID string, data interface{}) interfaces.EventPayload {
 	return interfaces.EventPayload{
 		EventType:  eventType,
 		EntityID:   entityID,
 		EntityType: entityType,
 		ActorID:    actorID,
-		Timestamp:  utils.GetCurrentTimeString(),
 		Data:       data,
 		Metadata:   make(map[string]string),
 	}
 }
 
-// CreateEventPayloadWithMetadata creates a standardized event payload with metadata
+// CreateEventPayloadWithMetadata creates a standardized event payload with metadata. EmitEvent sets
+// its timestamp.
 func (es *BaseEventService) CreateEventPayloadWithMetadata(eventType, entityID, entityType, actorID string, data interface{}, metadata map[string]string) interfaces.EventPayload {
 	return interfaces.EventPayload{
 		EventType:  eventType,
 		EntityID:   entityID,
 		EntityType: entityType,
 		ActorID:    actorID,
-		Timestamp:  utils.GetCurrentTimeString(),
 		Data:       data,
 		Metadata:   metadata,
 	}
-}
\ No newline at end of file
+}
+
+// NewEventHeader returns the header for an event at its current schema version
+func NewEventHeader(eventName string) interfaces.EventHeader {
+	version, ok := config.EventSchemaVersions[eventName]
+	if !ok {
+		version = config.DefaultEventSchemaVersion
+	}
+	return interfaces.EventHeader{
+		EventName:     eventName,
+		SchemaVersion: version,
+	}
+}
+
+// DecodeEvent decodes an emitted event, unmarshalling its data into data when non-nil. Events
+// emitted before versioning are accepted: they decode with LegacyEventSchemaVersion and take
+// their name from the payload's event type.
+func DecodeEvent(eventBytes []byte, data interface{}) (*interfaces.VersionedEvent, error) {
+	var raw struct {
+		interfaces.EventHeader
+		interfaces.EventPayload
+		Data json.RawMessage `json:"data"`
+	}
+	if err := json.Unmarshal(eventBytes, &raw); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal event: %w", err)
+	}
+
+	event := &interfaces.VersionedEvent{
+		EventHeader:  raw.EventHeader,
+		EventPayload: raw.EventPayload,
+	}
+	if event.EventName == "" {
+		event.EventName = event.EventType
+		event.SchemaVersion = config.LegacyEventSchemaVersion
+	}
+
+	// Without a target the data is left raw for the caller to decode once it knows the version
+	event.Data = raw.Data
+	if data != nil && len(raw.Data) > 0 {
+		if err := json.Unmarshal(raw.Data, data); err != nil {
+			return nil, fmt.Errorf("failed to unmarshal %s v%d data: %w", event.EventName, event.SchemaVersion, err)
+		}
+		event.Data = data
+	}
+
+	return event, nil
+}
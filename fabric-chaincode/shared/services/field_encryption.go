@@ -0,0 +1,91 @@
+package services
+
+import (
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/encryption"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// FieldEncryptionService seals and opens sensitive fields under the data keys a client passes in a
+// transaction's transient data. Keys are held off-chain; the ledger only ever sees their references.
+type FieldEncryptionService struct{}
+
+// NewFieldEncryptionService creates a new field encryption service
+func NewFieldEncryptionService() *FieldEncryptionService {
+	return &FieldEncryptionService{}
+}
+
+// ActiveKey returns the data key new values are sealed under, or nil when the client supplied none
+func (s *FieldEncryptionService) ActiveKey(stub shim.ChaincodeStubInterface) (*encryption.DataKey, error) {
+	transient, err := stub.GetTransient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transient data: %w", err)
+	}
+	ref, ok := transient[config.FieldEncryptionKeyRefTransientKey]
+	if !ok {
+		return nil, nil
+	}
+
+	key, err := s.Key(stub, string(ref))
+	if err != nil {
+		return nil, err
+	}
+	if key == nil {
+		return nil, fmt.Errorf("data key %s is named for sealing but not supplied", string(ref))
+	}
+	return key, nil
+}
+
+// Key returns the data key with a reference, or nil when the client did not supply it
+func (s *FieldEncryptionService) Key(stub shim.ChaincodeStubInterface, ref string) (*encryption.DataKey, error) {
+	transient, err := stub.GetTransient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transient data: %w", err)
+	}
+	keyBytes, ok := transient[config.FieldEncryptionKeyTransientPrefix+ref]
+	if !ok {
+		return nil, nil
+	}
+
+	key := &encryption.DataKey{Ref: ref, Key: keyBytes}
+	if err := key.Validate(); err != nil {
+		return nil, err
+	}
+	return key, nil
+}
+
+// Seal encrypts a field value of an entity under a data key
+func (s *FieldEncryptionService) Seal(stub shim.ChaincodeStubInterface, key *encryption.DataKey, entityID, field, plaintext string) (*encryption.EncryptedField, error) {
+	return encryption.Seal(key, stub.GetTxID(), entityID, field, plaintext)
+}
+
+// Open decrypts a field value of an entity for the invoker. It returns ok false, leaving the value
+// sealed, when the invoker's role lacks PermissionViewPII or the client did not supply the key.
+func (s *FieldEncryptionService) Open(stub shim.ChaincodeStubInterface, entityID, field string, sealed *encryption.EncryptedField) (string, bool, error) {
+	if !s.CanDecrypt(stub) {
+		return "", false, nil
+	}
+	key, err := s.Key(stub, sealed.KeyRef)
+	if err != nil || key == nil {
+		return "", false, err
+	}
+
+	plaintext, err := encryption.Open(key, entityID, field, sealed)
+	if err != nil {
+		return "", false, err
+	}
+	return plaintext, true, nil
+}
+
+// CanDecrypt reports whether the invoker's role holds PermissionViewPII. Identities whose role
+// cannot be read hold no permissions.
+func (s *FieldEncryptionService) CanDecrypt(stub shim.ChaincodeStubInterface) bool {
+	role, err := InvokerRole(stub)
+	if err != nil {
+		return false
+	}
+	return validation.ActorRole(role).HasPermission(validation.PermissionViewPII)
+}
@@ -0,0 +1,132 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+)
+
+// ErrorCodeServiceDisabled is returned when an invoked function has been switched off
+const ErrorCodeServiceDisabled = "SERVICE_DISABLED"
+
+// FunctionFlag records the operator-controlled availability of a chaincode function
+type FunctionFlag struct {
+	FunctionName        string     `json:"functionName"`
+	Disabled            bool       `json:"disabled"`
+	OperatorMessage     string     `json:"operatorMessage"`
+	ExpectedRestoration *time.Time `json:"expectedRestoration,omitempty"`
+	LastUpdated         time.Time  `json:"lastUpdated"`
+	LastUpdatedBy       string     `json:"lastUpdatedBy"`
+}
+
+// ServiceDisabledError is the structured error returned for disabled functions. It carries the
+// fields of the ChaincodeError envelope, and is retryable once the function is restored.
+type ServiceDisabledError struct {
+	Code                string     `json:"code"`
+	Message             string     `json:"message"`
+	Retryable           bool       `json:"retryable"`
+	FunctionName        string     `json:"functionName"`
+	OperatorMessage     string     `json:"operatorMessage"`
+	ExpectedRestoration *time.Time `json:"expectedRestoration,omitempty"`
+}
+
+// Error renders the error as JSON so clients can parse the code and restoration time
+func (e *ServiceDisabledError) Error() string {
+	data, err := json.Marshal(e)
+	if err != nil {
+		return fmt.Sprintf("%s: function %s is disabled: %s", e.Code, e.FunctionName, e.OperatorMessage)
+	}
+	return string(data)
+}
+
+// FunctionFlagService manages per-function kill switches in the ledger config store
+type FunctionFlagService struct {
+	persistenceService *PersistenceService
+}
+
+// NewFunctionFlagService creates a new function flag service
+func NewFunctionFlagService() *FunctionFlagService {
+	return &FunctionFlagService{
+		persistenceService: NewPersistenceService(),
+	}
+}
+
+// GetFunctionFlag retrieves the flag for a function, returning nil if none is set
+func (fs *FunctionFlagService) GetFunctionFlag(stub shim.ChaincodeStubInterface, functionName string) (*FunctionFlag, error) {
+	flagKey, err := stub.CreateCompositeKey(config.FunctionFlagPrefix, []string{functionName})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create function flag key: %w", err)
+	}
+
+	exists, err := fs.persistenceService.Exists(stub, flagKey)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	var flag FunctionFlag
+	if err := fs.persistenceService.Get(stub, flagKey, &flag); err != nil {
+		return nil, err
+	}
+	return &flag, nil
+}
+
+// PutFunctionFlag stores the flag for a function
+func (fs *FunctionFlagService) PutFunctionFlag(stub shim.ChaincodeStubInterface, flag *FunctionFlag) error {
+	flagKey, err := stub.CreateCompositeKey(config.FunctionFlagPrefix, []string{flag.FunctionName})
+	if err != nil {
+		return fmt.Errorf("failed to create function flag key: %w", err)
+	}
+	return fs.persistenceService.Put(stub, flagKey, flag)
+}
+
+// GetFunctionFlags retrieves all stored function flags
+func (fs *FunctionFlagService) GetFunctionFlags(stub shim.ChaincodeStubInterface) ([]FunctionFlag, error) {
+	iterator, err := stub.GetStateByPartialCompositeKey(config.FunctionFlagPrefix, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get function flags: %w", err)
+	}
+	defer iterator.Close()
+
+	var flags []FunctionFlag
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate function flags: %w", err)
+		}
+
+		var flag FunctionFlag
+		if err := utils.UnmarshalJSON(response.Value, &flag); err != nil {
+			return nil, err
+		}
+		flags = append(flags, flag)
+	}
+
+	return flags, nil
+}
+
+// CheckFunctionEnabled returns a ServiceDisabledError if the function has been disabled
+func (fs *FunctionFlagService) CheckFunctionEnabled(stub shim.ChaincodeStubInterface, functionName string) error {
+	flag, err := fs.GetFunctionFlag(stub, functionName)
+	if err != nil {
+		return fmt.Errorf("failed to check function flag for %s: %w", functionName, err)
+	}
+	if flag == nil || !flag.Disabled {
+		return nil
+	}
+
+	return &ServiceDisabledError{
+		Code:                ErrorCodeServiceDisabled,
+		Message:             fmt.Sprintf("function %s is disabled", functionName),
+		Retryable:           true,
+		FunctionName:        functionName,
+		OperatorMessage:     flag.OperatorMessage,
+		ExpectedRestoration: flag.ExpectedRestoration,
+	}
+}
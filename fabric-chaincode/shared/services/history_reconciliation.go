@@ -0,0 +1,140 @@
+package services
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+)
+
+// HistoryModeReconcile asks a history function to reconcile the recorded history with the ledger
+// history of the entity's record
+const HistoryModeReconcile = "RECONCILE"
+
+// Integrity warnings raised when the recorded history and the ledger disagree
+const (
+	HistoryWarningUnrecordedChange   = "UNRECORDED_CHANGE"   // The record was written with no history entry in the same transaction
+	HistoryWarningOrphanedEntry      = "ORPHANED_ENTRY"      // A history entry whose transaction never wrote the record
+	HistoryWarningMissingTransaction = "MISSING_TRANSACTION" // A history entry that does not name its transaction
+)
+
+// HistoryIntegrityWarning is one disagreement between the recorded history and the ledger
+type HistoryIntegrityWarning struct {
+	Type          string `json:"type"`
+	TransactionID string `json:"transactionID,omitempty"`
+	HistoryID     string `json:"historyID,omitempty"`
+	Message       string `json:"message"`
+}
+
+// ReconciledHistoryEntry is one transaction in the merged history: whether it wrote the record,
+// and the history entries it recorded
+type ReconciledHistoryEntry struct {
+	TransactionID string        `json:"transactionID"`
+	Timestamp     time.Time     `json:"timestamp"`
+	LedgerWrite   bool          `json:"ledgerWrite"`
+	IsDelete      bool          `json:"isDelete,omitempty"`
+	Changes       []interface{} `json:"changes"`
+}
+
+// HistoryReconciliation merges an entity's recorded history with the ledger history of its record.
+// Record values are left out, so reconciling reveals no more than the recorded history does.
+type HistoryReconciliation struct {
+	EntityID       string                    `json:"entityID"`
+	RecordKey      string                    `json:"recordKey"`
+	Entries        []ReconciledHistoryEntry  `json:"entries"`
+	LedgerVersions int                       `json:"ledgerVersions"`
+	HistoryEntries int                       `json:"historyEntries"`
+	Warnings       []HistoryIntegrityWarning `json:"warnings"`
+	Consistent     bool                      `json:"consistent"`
+}
+
+// ReconcileHistory walks the ledger history of an entity's record and merges it, by transaction,
+// with the history entries recorded for the entity. Each record write without a history entry,
+// and each history entry whose transaction did not write the record, is flagged.
+func ReconcileHistory(stub shim.ChaincodeStubInterface, entityID, recordKey string, history []interface{}) (*HistoryReconciliation, error) {
+	ledgerEntries, err := ledgerAuditEntries(stub, recordKey)
+	if err != nil {
+		return nil, err
+	}
+
+	result := &HistoryReconciliation{
+		EntityID:       entityID,
+		RecordKey:      recordKey,
+		LedgerVersions: len(ledgerEntries),
+		HistoryEntries: len(history),
+		Entries:        []ReconciledHistoryEntry{},
+		Warnings:       []HistoryIntegrityWarning{},
+	}
+	byTransaction := make(map[string]*ReconciledHistoryEntry)
+	var order []string
+	for _, ledgerEntry := range ledgerEntries {
+		byTransaction[ledgerEntry.TransactionID] = &ReconciledHistoryEntry{
+			TransactionID: ledgerEntry.TransactionID,
+			Timestamp:     ledgerEntry.Timestamp,
+			LedgerWrite:   true,
+			IsDelete:      ledgerEntry.IsDelete,
+			Changes:       []interface{}{},
+		}
+		order = append(order, ledgerEntry.TransactionID)
+	}
+
+	for _, entry := range history {
+		fields, _ := entry.(map[string]interface{})
+		txID, _ := fields["transactionID"].(string)
+		historyID, _ := fields["historyID"].(string)
+		if txID == "" {
+			result.Warnings = append(result.Warnings, HistoryIntegrityWarning{
+				Type:      HistoryWarningMissingTransaction,
+				HistoryID: historyID,
+				Message:   fmt.Sprintf("history entry %s does not name the transaction that recorded it", historyID),
+			})
+		}
+
+		merged, ok := byTransaction[txID]
+		if !ok {
+			merged = &ReconciledHistoryEntry{TransactionID: txID, Changes: []interface{}{}}
+			if recorded, ok := fields["timestamp"].(string); ok {
+				if timestamp, err := utils.ParseTime(recorded); err == nil {
+					merged.Timestamp = timestamp.UTC()
+				}
+			}
+			byTransaction[txID] = merged
+			order = append(order, txID)
+		}
+		merged.Changes = append(merged.Changes, entry)
+
+		if !merged.LedgerWrite && txID != "" {
+			result.Warnings = append(result.Warnings, HistoryIntegrityWarning{
+				Type:          HistoryWarningOrphanedEntry,
+				TransactionID: txID,
+				HistoryID:     historyID,
+				Message:       fmt.Sprintf("history entry %s was recorded by transaction %s, which did not write %s", historyID, txID, recordKey),
+			})
+		}
+	}
+
+	for _, txID := range order {
+		merged := byTransaction[txID]
+		if merged.LedgerWrite && len(merged.Changes) == 0 {
+			result.Warnings = append(result.Warnings, HistoryIntegrityWarning{
+				Type:          HistoryWarningUnrecordedChange,
+				TransactionID: txID,
+				Message:       fmt.Sprintf("transaction %s wrote %s without recording a history entry", txID, recordKey),
+			})
+		}
+		result.Entries = append(result.Entries, *merged)
+	}
+
+	sort.SliceStable(result.Entries, func(i, j int) bool {
+		a, b := result.Entries[i], result.Entries[j]
+		if !a.Timestamp.Equal(b.Timestamp) {
+			return a.Timestamp.Before(b.Timestamp)
+		}
+		return a.TransactionID < b.TransactionID
+	})
+
+	result.Consistent = len(result.Warnings) == 0
+	return result, nil
+}
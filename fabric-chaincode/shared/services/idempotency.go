@@ -0,0 +1,127 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+)
+
+// maxIdempotencyKeyLength bounds client-supplied keys, which become part of a ledger key
+const maxIdempotencyKeyLength = 128
+
+// IdempotencyRecord maps a client-supplied idempotency key to the entity created by the first
+// submission carrying it
+type IdempotencyRecord struct {
+	Function    string    `json:"function"`
+	Key         string    `json:"key"`
+	EntityID    string    `json:"entityID"`
+	RequestHash string    `json:"requestHash"`
+	TxID        string    `json:"txID"`
+	CreatedDate time.Time `json:"createdDate"`
+}
+
+// IdempotencyService lets create functions recognise a retried submission and return the entity
+// the original submission created instead of creating a duplicate
+type IdempotencyService struct {
+	persistenceService *PersistenceService
+}
+
+// NewIdempotencyService creates a new idempotency service
+func NewIdempotencyService() *IdempotencyService {
+	return &IdempotencyService{
+		persistenceService: NewPersistenceService(),
+	}
+}
+
+// Replay returns the entity ID recorded for an idempotency key, or an empty string when the key is
+// empty or has not been used. Reusing a key for a different request is an error, so a client bug
+// cannot silently return someone else's entity.
+func (s *IdempotencyService) Replay(stub shim.ChaincodeStubInterface, function, key string, request interface{}) (string, error) {
+	if key == "" {
+		return "", nil
+	}
+	recordKey, err := s.recordKey(stub, function, key)
+	if err != nil {
+		return "", err
+	}
+
+	recordBytes, err := stub.GetState(recordKey)
+	if err != nil {
+		return "", fmt.Errorf("failed to read idempotency key: %w", err)
+	}
+	if recordBytes == nil {
+		return "", nil
+	}
+
+	var record IdempotencyRecord
+	if err := utils.UnmarshalJSON(recordBytes, &record); err != nil {
+		return "", err
+	}
+	requestHash, err := idempotencyRequestHash(request)
+	if err != nil {
+		return "", err
+	}
+	if record.RequestHash != requestHash {
+		return "", fmt.Errorf("idempotency key %s was already used for a different %s request", key, function)
+	}
+	return record.EntityID, nil
+}
+
+// Record maps an idempotency key to the entity created by this submission. It does nothing when
+// the client supplied no key.
+func (s *IdempotencyService) Record(stub shim.ChaincodeStubInterface, function, key string, request interface{}, entityID string) error {
+	if key == "" {
+		return nil
+	}
+	recordKey, err := s.recordKey(stub, function, key)
+	if err != nil {
+		return err
+	}
+	requestHash, err := idempotencyRequestHash(request)
+	if err != nil {
+		return err
+	}
+	now, err := TxTime(stub)
+	if err != nil {
+		return err
+	}
+
+	record := &IdempotencyRecord{
+		Function:    function,
+		Key:         key,
+		EntityID:    entityID,
+		RequestHash: requestHash,
+		TxID:        stub.GetTxID(),
+		CreatedDate: now,
+	}
+	if err := s.persistenceService.Put(stub, recordKey, record); err != nil {
+		return fmt.Errorf("failed to store idempotency key: %w", err)
+	}
+	return nil
+}
+
+func (s *IdempotencyService) recordKey(stub shim.ChaincodeStubInterface, function, key string) (string, error) {
+	if strings.TrimSpace(key) != key || len(key) > maxIdempotencyKeyLength {
+		return "", NewChaincodeError(ErrCodeInvalidArgument, "", "idempotency key must be at most %d characters without surrounding whitespace", maxIdempotencyKeyLength)
+	}
+	recordKey, err := stub.CreateCompositeKey(config.IdempotencyKeyPrefix, []string{function, key})
+	if err != nil {
+		return "", fmt.Errorf("failed to create idempotency key: %w", err)
+	}
+	return recordKey, nil
+}
+
+// idempotencyRequestHash hashes the parsed request, so a retry matches however the client
+// serialised it
+func idempotencyRequestHash(request interface{}) (string, error) {
+	requestBytes, err := json.Marshal(request)
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal request: %w", err)
+	}
+	return utils.HashValue(string(requestBytes)), nil
+}
@@ -0,0 +1,188 @@
+package services
+
+import (
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/pkg/cid"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+)
+
+// ActorIdentity binds a registered actor to the blockchain identity allowed to act as it
+type ActorIdentity struct {
+	ActorID            string    `json:"actorID"`
+	BlockchainIdentity string    `json:"blockchainIdentity"` // cid.GetID of the actor's enrollment certificate
+	MSPID              string    `json:"mspID"`
+	Role               string    `json:"role"`
+	Active             bool      `json:"active"`
+	LastUpdated        time.Time `json:"lastUpdated"`
+	LastUpdatedBy      string    `json:"lastUpdatedBy"`
+}
+
+// CredentialRotation attests that an actor's off-chain credentials were rotated. Only the latest
+// attestation per actor is kept in state; earlier ones remain in the key's ledger history.
+type CredentialRotation struct {
+	ActorID      string    `json:"actorID"`
+	RotationDate time.Time `json:"rotationDate"`
+	Method       string    `json:"method"`
+	AttestedBy   string    `json:"attestedBy"`
+	AttestedDate time.Time `json:"attestedDate"`
+	Notes        string    `json:"notes,omitempty"`
+}
+
+// IdentityService manages actor registrations and binds self-asserted actor IDs to the invoker
+type IdentityService struct {
+	persistenceService *PersistenceService
+}
+
+// NewIdentityService creates a new identity service
+func NewIdentityService() *IdentityService {
+	return &IdentityService{
+		persistenceService: NewPersistenceService(),
+	}
+}
+
+// PutActor stores an actor registration
+func (is *IdentityService) PutActor(stub shim.ChaincodeStubInterface, actor *ActorIdentity) error {
+	actorKey, err := stub.CreateCompositeKey(config.ActorPrefix, []string{actor.ActorID})
+	if err != nil {
+		return fmt.Errorf("failed to create actor key: %w", err)
+	}
+	return is.persistenceService.Put(stub, actorKey, actor)
+}
+
+// GetActor retrieves an actor registration
+func (is *IdentityService) GetActor(stub shim.ChaincodeStubInterface, actorID string) (*ActorIdentity, error) {
+	actorKey, err := stub.CreateCompositeKey(config.ActorPrefix, []string{actorID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create actor key: %w", err)
+	}
+
+	var actor ActorIdentity
+	if err := is.persistenceService.Get(stub, actorKey, &actor); err != nil {
+		if errors.Is(err, ErrNotFound) {
+			return nil, NewChaincodeError(ErrCodeNotFound, "", "actor %s is not registered", actorID)
+		}
+		return nil, err
+	}
+	return &actor, nil
+}
+
+// PutCredentialRotation stores the latest credential rotation attestation for an actor
+func (is *IdentityService) PutCredentialRotation(stub shim.ChaincodeStubInterface, rotation *CredentialRotation) error {
+	rotationKey, err := stub.CreateCompositeKey(config.CredentialRotationPrefix, []string{rotation.ActorID})
+	if err != nil {
+		return fmt.Errorf("failed to create credential rotation key: %w", err)
+	}
+	return is.persistenceService.Put(stub, rotationKey, rotation)
+}
+
+// GetCredentialRotation retrieves the latest credential rotation attestation for an actor
+func (is *IdentityService) GetCredentialRotation(stub shim.ChaincodeStubInterface, actorID string) (*CredentialRotation, error) {
+	rotationKey, err := stub.CreateCompositeKey(config.CredentialRotationPrefix, []string{actorID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create credential rotation key: %w", err)
+	}
+
+	var rotation CredentialRotation
+	if err := is.persistenceService.Get(stub, rotationKey, &rotation); err != nil {
+		return nil, fmt.Errorf("no credential rotation attested for actor %s", actorID)
+	}
+	return &rotation, nil
+}
+
+// CheckCredentialAge rejects actors whose credentials are older than maxAge. Credentials date from
+// the latest attested rotation or, for actors never attested, from their last registration, which
+// binds a freshly issued certificate.
+func (is *IdentityService) CheckCredentialAge(stub shim.ChaincodeStubInterface, actorID string, maxAge time.Duration) error {
+	actor, err := is.GetActor(stub, actorID)
+	if err != nil {
+		return err
+	}
+
+	issued := actor.LastUpdated
+	if rotation, err := is.GetCredentialRotation(stub, actorID); err == nil && rotation.RotationDate.After(issued) {
+		issued = rotation.RotationDate
+	}
+
+	now, err := TxTime(stub)
+	if err != nil {
+		return err
+	}
+	if age := now.Sub(issued); age > maxAge {
+		return NewChaincodeError(ErrCodeAccessDenied, "", "credentials of actor %s were last rotated %s, more than %d days ago; attest a credential rotation first",
+			actorID, issued.Format(time.RFC3339), int(maxAge.Hours()/24))
+	}
+	return nil
+}
+
+// VerifyActor confirms the transaction was submitted by the identity registered for the actor
+func (is *IdentityService) VerifyActor(stub shim.ChaincodeStubInterface, actorID string) error {
+	if actorID == "" {
+		return NewChaincodeError(ErrCodeInvalidArgument, "actorID", "actorID is required")
+	}
+
+	actor, err := is.GetActor(stub, actorID)
+	if err != nil {
+		return err
+	}
+	if !actor.Active {
+		return NewChaincodeError(ErrCodeAccessDenied, "", "actor %s is inactive", actorID)
+	}
+
+	invokerID, err := InvokerID(stub)
+	if err != nil {
+		return err
+	}
+	mspID, err := InvokerMSPID(stub)
+	if err != nil {
+		return err
+	}
+	if invokerID != actor.BlockchainIdentity || mspID != actor.MSPID {
+		return NewChaincodeError(ErrCodeAccessDenied, "", "invoking identity is not bound to actor %s", actorID)
+	}
+	return nil
+}
+
+// InvokerID returns the unique ID (subject and issuer) of the identity that submitted the transaction
+func InvokerID(stub shim.ChaincodeStubInterface) (string, error) {
+	id, err := cid.GetID(stub)
+	if err != nil {
+		return "", fmt.Errorf("failed to get invoker ID: %w", err)
+	}
+	return id, nil
+}
+
+// InvokerMSPID returns the MSP ID of the identity that submitted the transaction
+func InvokerMSPID(stub shim.ChaincodeStubInterface) (string, error) {
+	mspID, err := cid.GetMSPID(stub)
+	if err != nil {
+		return "", fmt.Errorf("failed to get invoker MSP ID: %w", err)
+	}
+	return mspID, nil
+}
+
+// InvokerRole returns the role attribute of the identity that submitted the transaction,
+// or "" when its certificate carries no role
+func InvokerRole(stub shim.ChaincodeStubInterface) (string, error) {
+	role, _, err := cid.GetAttributeValue(stub, config.RoleAttribute)
+	if err != nil {
+		return "", fmt.Errorf("failed to get invoker role: %w", err)
+	}
+	return role, nil
+}
+
+// InvokerTeam returns the team attribute of the identity that submitted the transaction, falling
+// back to its role when the certificate carries no team
+func InvokerTeam(stub shim.ChaincodeStubInterface) (string, error) {
+	team, _, err := cid.GetAttributeValue(stub, config.TeamAttribute)
+	if err != nil {
+		return "", fmt.Errorf("failed to get invoker team: %w", err)
+	}
+	if team != "" {
+		return team, nil
+	}
+	return InvokerRole(stub)
+}
@@ -0,0 +1,61 @@
+package services
+
+import (
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// Invocation is the stub of one chaincode invocation, carrying the state its handlers share while
+// it runs: the IDs generated and the counter deltas written so far. Entry points wrap the stub with NewInvocation before dispatching, so handlers receive
+// it as their stub and the state goes away with the invocation instead of living in the process.
+type Invocation struct {
+	shim.ChaincodeStubInterface
+	txID          string
+	ids           *IDGenerator
+	counterDeltas map[string]int64
+}
+
+// NewInvocation wraps a stub for one invocation. A stub already wrapped is returned as it is.
+func NewInvocation(stub shim.ChaincodeStubInterface) *Invocation {
+	if invocation, ok := stub.(*Invocation); ok {
+		return invocation
+	}
+	return &Invocation{ChaincodeStubInterface: stub}
+}
+
+// Stub returns the stub the invocation wraps
+func (i *Invocation) Stub() shim.ChaincodeStubInterface {
+	return i.ChaincodeStubInterface
+}
+
+// IDs returns the invocation's ID generator. A reused mock stub moves on to a new transaction
+// under the same wrapper, so the state starts over whenever the transaction ID changes.
+func (i *Invocation) IDs() *IDGenerator {
+	i.reset()
+	return i.ids
+}
+
+// reset starts the invocation's state over when the stub has moved on to another transaction
+func (i *Invocation) reset() {
+	txID := i.GetTxID()
+	if i.ids != nil && i.txID == txID {
+		return
+	}
+	i.txID = txID
+	i.ids = NewIDGenerator(txID)
+	i.counterDeltas = map[string]int64{}
+}
+
+// addCounterDelta adds n to what the invocation has counted under a delta key and returns the
+// sum. Fabric does not expose a transaction's own writes to its reads, so a second increment
+// rewrites the delta key with the sum kept here instead of reading the first back.
+func (i *Invocation) addCounterDelta(deltaKey string, n int64) int64 {
+	i.reset()
+	i.counterDeltas[deltaKey] += n
+	return i.counterDeltas[deltaKey]
+}
+
+// invocationOf returns the invocation a stub belongs to. A stub no entry point has wrapped, such
+// as one a test passes straight to a handler, gets fresh state on every call.
+func invocationOf(stub shim.ChaincodeStubInterface) *Invocation {
+	return NewInvocation(stub)
+}
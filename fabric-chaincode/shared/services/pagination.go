@@ -0,0 +1,96 @@
+package services
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+)
+
+// StateEntry is a single key/value pair read from the ledger
+type StateEntry struct {
+	Key   string
+	Value []byte
+}
+
+// ParsePageSize parses an optional page size argument, applying the default and maximum
+func ParsePageSize(arg string) (int, error) {
+	if arg == "" {
+		return config.DefaultPageSize, nil
+	}
+
+	pageSize, err := strconv.Atoi(arg)
+	if err != nil || pageSize <= 0 {
+		return 0, NewChaincodeError(ErrCodeInvalidArgument, "", "invalid page size: %s", arg)
+	}
+	if pageSize > config.MaxPageSize {
+		pageSize = config.MaxPageSize
+	}
+	return pageSize, nil
+}
+
+// ParsePageArgs parses the optional trailing pageSize and bookmark arguments of a listing query
+func ParsePageArgs(args []string) (int, string, error) {
+	pageSizeArg, bookmark := "", ""
+	if len(args) > 0 {
+		pageSizeArg = args[0]
+	}
+	if len(args) > 1 {
+		bookmark = args[1]
+	}
+
+	pageSize, err := ParsePageSize(pageSizeArg)
+	if err != nil {
+		return 0, "", err
+	}
+	return pageSize, bookmark, nil
+}
+
+// GetPageByPartialCompositeKeys scans each partial composite key in turn and returns up to pageSize
+// entries sorting after the bookmark, together with the bookmark for the next page ("" when there
+// are no more). The bookmark is the last composite key returned, so paging behaves the same against
+// MockStub as on a peer; callers must pass partial keys in ascending key order.
+func (ps *PersistenceService) GetPageByPartialCompositeKeys(stub shim.ChaincodeStubInterface, objectType string, partialKeys [][]string, pageSize int, bookmark string) ([]StateEntry, string, error) {
+	entries := []StateEntry{}
+
+	for _, attributes := range partialKeys {
+		// Skip ranges wholly before the bookmark without reading them
+		if bookmark != "" {
+			prefix, err := stub.CreateCompositeKey(objectType, attributes)
+			if err != nil {
+				return nil, "", fmt.Errorf("failed to create composite key: %w", err)
+			}
+			if prefix < bookmark && !strings.HasPrefix(bookmark, prefix) {
+				continue
+			}
+		}
+
+		iterator, err := stub.GetStateByPartialCompositeKey(objectType, attributes)
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to get state by partial composite key: %w", err)
+		}
+
+		for iterator.HasNext() {
+			response, err := iterator.Next()
+			if err != nil {
+				iterator.Close()
+				return nil, "", fmt.Errorf("failed to iterate partial composite key results: %w", err)
+			}
+			if bookmark != "" && response.Key <= bookmark {
+				continue
+			}
+
+			// A further entry means there is another page
+			if len(entries) == pageSize {
+				iterator.Close()
+				return entries, entries[len(entries)-1].Key, nil
+			}
+			entries = append(entries, StateEntry{Key: response.Key, Value: response.Value})
+		}
+		iterator.Close()
+	}
+
+	return entries, "", nil
+}
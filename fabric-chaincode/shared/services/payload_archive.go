@@ -0,0 +1,185 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+)
+
+// PayloadArchivePolicy flags a function whose full request payload is retained alongside its hash.
+// Functions carrying customer PII in their arguments only ever have the hash archived.
+type PayloadArchivePolicy struct {
+	FunctionName  string    `json:"functionName"`
+	RetainPayload bool      `json:"retainPayload"`
+	LastUpdated   time.Time `json:"lastUpdated"`
+	LastUpdatedBy string    `json:"lastUpdatedBy"`
+}
+
+// ArchivedPayload records what the gateway submitted in a transaction. The hash is always kept;
+// the arguments only for functions flagged by a PayloadArchivePolicy that carry no customer PII.
+type ArchivedPayload struct {
+	TransactionID    string            `json:"transactionID"`
+	FunctionName     string            `json:"functionName"`
+	PayloadHash      string            `json:"payloadHash"` // Hex SHA-256 of the JSON array [function, args...]
+	Payload          []string          `json:"payload,omitempty"`
+	PayloadWithheld  bool              `json:"payloadWithheld,omitempty"` // Set when a flagged function's arguments were not kept because they carry PII
+	ActorID          string            `json:"actorID,omitempty"` // Actor named in the request, if any
+	InvokerID        string            `json:"invokerID"`
+	InvokerMSPID     string            `json:"invokerMSPID"`
+	SubmittedDate    time.Time         `json:"submittedDate"`
+	RequestSignature *RequestSignature `json:"requestSignature,omitempty"` // Evidence of a partner's signature, for partner requests
+}
+
+// PayloadArchiveService archives request payloads keyed by transaction ID
+type PayloadArchiveService struct {
+	persistenceService *PersistenceService
+}
+
+// NewPayloadArchiveService creates a new payload archive service
+func NewPayloadArchiveService() *PayloadArchiveService {
+	return &PayloadArchiveService{
+		persistenceService: NewPersistenceService(),
+	}
+}
+
+// GetPolicy retrieves the archive policy for a function, returning nil if none is set
+func (ps *PayloadArchiveService) GetPolicy(stub shim.ChaincodeStubInterface, functionName string) (*PayloadArchivePolicy, error) {
+	policyKey, err := stub.CreateCompositeKey(config.PayloadArchivePolicyPrefix, []string{functionName})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create archive policy key: %w", err)
+	}
+
+	exists, err := ps.persistenceService.Exists(stub, policyKey)
+	if err != nil {
+		return nil, err
+	}
+	if !exists {
+		return nil, nil
+	}
+
+	var policy PayloadArchivePolicy
+	if err := ps.persistenceService.Get(stub, policyKey, &policy); err != nil {
+		return nil, err
+	}
+	return &policy, nil
+}
+
+// PutPolicy stores the archive policy for a function
+func (ps *PayloadArchiveService) PutPolicy(stub shim.ChaincodeStubInterface, policy *PayloadArchivePolicy) error {
+	policyKey, err := stub.CreateCompositeKey(config.PayloadArchivePolicyPrefix, []string{policy.FunctionName})
+	if err != nil {
+		return fmt.Errorf("failed to create archive policy key: %w", err)
+	}
+	return ps.persistenceService.Put(stub, policyKey, policy)
+}
+
+// GetPolicies retrieves all stored archive policies
+func (ps *PayloadArchiveService) GetPolicies(stub shim.ChaincodeStubInterface) ([]PayloadArchivePolicy, error) {
+	iterator, err := stub.GetStateByPartialCompositeKey(config.PayloadArchivePolicyPrefix, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get archive policies: %w", err)
+	}
+	defer iterator.Close()
+
+	policies := []PayloadArchivePolicy{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate archive policies: %w", err)
+		}
+
+		var policy PayloadArchivePolicy
+		if err := utils.UnmarshalJSON(response.Value, &policy); err != nil {
+			return nil, err
+		}
+		policies = append(policies, policy)
+	}
+
+	return policies, nil
+}
+
+// Archive records the hash of the transaction's request, and the request itself when the function
+// is flagged for full retention and its arguments carry no PII. A partner request's signature
+// evidence is archived with it.
+func (ps *PayloadArchiveService) Archive(stub shim.ChaincodeStubInterface, function string, args []string, actorID string, carriesPII bool) error {
+	policy, err := ps.GetPolicy(stub, function)
+	if err != nil {
+		return err
+	}
+
+	hash, err := PayloadHash(function, args)
+	if err != nil {
+		return err
+	}
+	invokerID, err := InvokerID(stub)
+	if err != nil {
+		return err
+	}
+	mspID, err := InvokerMSPID(stub)
+	if err != nil {
+		return err
+	}
+	submittedDate, err := TxTime(stub)
+	if err != nil {
+		return err
+	}
+	signature, err := GetRequestSignature(stub)
+	if err != nil {
+		return err
+	}
+
+	archived := &ArchivedPayload{
+		TransactionID:    stub.GetTxID(),
+		FunctionName:     function,
+		PayloadHash:      hash,
+		ActorID:          actorID,
+		InvokerID:        invokerID,
+		InvokerMSPID:     mspID,
+		SubmittedDate:    submittedDate,
+		RequestSignature: signature,
+	}
+	if policy != nil && policy.RetainPayload {
+		if carriesPII {
+			archived.PayloadWithheld = true
+		} else {
+			archived.Payload = args
+		}
+	}
+
+	archiveKey, err := stub.CreateCompositeKey(config.PayloadArchivePrefix, []string{archived.TransactionID})
+	if err != nil {
+		return fmt.Errorf("failed to create payload archive key: %w", err)
+	}
+	return ps.persistenceService.Put(stub, archiveKey, archived)
+}
+
+// GetArchivedPayload retrieves the archived request of a transaction
+func (ps *PayloadArchiveService) GetArchivedPayload(stub shim.ChaincodeStubInterface, txID string) (*ArchivedPayload, error) {
+	archiveKey, err := stub.CreateCompositeKey(config.PayloadArchivePrefix, []string{txID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create payload archive key: %w", err)
+	}
+
+	var archived ArchivedPayload
+	if err := ps.persistenceService.Get(stub, archiveKey, &archived); err != nil {
+		return nil, fmt.Errorf("no payload archived for transaction %s: %w", txID, err)
+	}
+	return &archived, nil
+}
+
+// PayloadHash returns the hex SHA-256 of the JSON array [function, args...], so investigators can
+// check a payload held off-chain against the archived hash
+func PayloadHash(function string, args []string) (string, error) {
+	data, err := json.Marshal(append([]string{function}, args...))
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal payload: %w", err)
+	}
+	digest := sha256.Sum256(data)
+	return hex.EncodeToString(digest[:]), nil
+}
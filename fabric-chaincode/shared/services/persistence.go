@@ -20,14 +20,14 @@ func NewPersistenceService() *PersistenceService {
 func (ps *PersistenceService) Get(stub shim.ChaincodeStubInterface, key string, result interface{}) error {
 	data, err := stub.GetState(key)
 	if err != nil {
-		return fmt.Errorf("failed to get state for key %s: %v", key, err)
+		return NewChaincodeError(ErrCodeLedger, "", "failed to get state for key %s: %v", key, err)
 	}
 	if data == nil {
-		return fmt.Errorf("no data found for key %s", key)
+		return NewChaincodeError(ErrCodeNotFound, "", "no data found for key %s", key)
 	}
 	
 	if err := json.Unmarshal(data, result); err != nil {
-		return fmt.Errorf("failed to unmarshal data for key %s: %v", key, err)
+		return fmt.Errorf("failed to unmarshal data for key %s: %w", key, err)
 	}
 	
 	return nil
@@ -37,11 +37,11 @@ func (ps *PersistenceService) Get(stub shim.ChaincodeStubInterface, key string,
 func (ps *PersistenceService) Put(stub shim.ChaincodeStubInterface, key string, value interface{}) error {
 	data, err := json.Marshal(value)
 	if err != nil {
-		return fmt.Errorf("failed to marshal data for key %s: %v", key, err)
+		return fmt.Errorf("failed to marshal data for key %s: %w", key, err)
 	}
 	
 	if err := stub.PutState(key, data); err != nil {
-		return fmt.Errorf("failed to put state for key %s: %v", key, err)
+		return NewChaincodeError(ErrCodeLedger, "", "failed to put state for key %s: %v", key, err)
 	}
 	
 	return nil
@@ -50,7 +50,7 @@ func (ps *PersistenceService) Put(stub shim.ChaincodeStubInterface, key string,
 // Delete removes data from the ledger
 func (ps *PersistenceService) Delete(stub shim.ChaincodeStubInterface, key string) error {
 	if err := stub.DelState(key); err != nil {
-		return fmt.Errorf("failed to delete state for key %s: %v", key, err)
+		return NewChaincodeError(ErrCodeLedger, "", "failed to delete state for key %s: %v", key, err)
 	}
 	return nil
 }
@@ -59,16 +59,16 @@ func (ps *PersistenceService) Delete(stub shim.ChaincodeStubInterface, key strin
 func (ps *PersistenceService) Exists(stub shim.ChaincodeStubInterface, key string) (bool, error) {
 	data, err := stub.GetState(key)
 	if err != nil {
-		return false, fmt.Errorf("failed to check existence for key %s: %v", key, err)
+		return false, NewChaincodeError(ErrCodeLedger, "", "failed to check existence for key %s: %v", key, err)
 	}
 	return data != nil, nil
 }
 
 // GetByCompositeKey retrieves data using a composite key
 func (ps *PersistenceService) GetByCompositeKey(stub shim.ChaincodeStubInterface, objectType string, attributes []string) ([]interface{}, error) {
-	iterator, err := stub.GetStateByCompositeKey(objectType, attributes)
+	iterator, err := stub.GetStateByPartialCompositeKey(objectType, attributes)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get state by composite key: %v", err)
+		return nil, NewChaincodeError(ErrCodeLedger, "", "failed to get state by composite key: %v", err)
 	}
 	defer iterator.Close()
 	
@@ -76,12 +76,12 @@ func (ps *PersistenceService) GetByCompositeKey(stub shim.ChaincodeStubInterface
 	for iterator.HasNext() {
 		response, err := iterator.Next()
 		if err != nil {
-			return nil, fmt.Errorf("failed to iterate composite key results: %v", err)
+			return nil, NewChaincodeError(ErrCodeLedger, "", "failed to iterate composite key results: %v", err)
 		}
 		
 		var result interface{}
 		if err := json.Unmarshal(response.Value, &result); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal composite key result: %v", err)
+			return nil, fmt.Errorf("failed to unmarshal composite key result: %w", err)
 		}
 		
 		results = append(results, result)
@@ -94,7 +94,7 @@ func (ps *PersistenceService) GetByCompositeKey(stub shim.ChaincodeStubInterface
 func (ps *PersistenceService) GetByPartialCompositeKey(stub shim.ChaincodeStubInterface, objectType string, attributes []string) ([]interface{}, error) {
 	iterator, err := stub.GetStateByPartialCompositeKey(objectType, attributes)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get state by partial composite key: %v", err)
+		return nil, NewChaincodeError(ErrCodeLedger, "", "failed to get state by partial composite key: %v", err)
 	}
 	defer iterator.Close()
 	
@@ -102,12 +102,12 @@ func (ps *PersistenceService) GetByPartialCompositeKey(stub shim.ChaincodeStubIn
 	for iterator.HasNext() {
 		response, err := iterator.Next()
 		if err != nil {
-			return nil, fmt.Errorf("failed to iterate partial composite key results: %v", err)
+			return nil, NewChaincodeError(ErrCodeLedger, "", "failed to iterate partial composite key results: %v", err)
 		}
 		
 		var result interface{}
 		if err := json.Unmarshal(response.Value, &result); err != nil {
-			return nil, fmt.Errorf("failed to unmarshal partial composite key result: %v", err)
+			return nil, fmt.Errorf("failed to unmarshal partial composite key result: %w", err)
 		}
 		
 		results = append(results, result)
@@ -130,7 +130,7 @@ func (ps *PersistenceService) SplitCompositeKey(stub shim.ChaincodeStubInterface
 func (ps *PersistenceService) GetHistory(stub shim.ChaincodeStubInterface, key string) ([]interfaces.HistoryEntry, error) {
 	iterator, err := stub.GetHistoryForKey(key)
 	if err != nil {
-		return nil, fmt.Errorf("failed to get history for key %s: %v", key, err)
+		return nil, NewChaincodeError(ErrCodeLedger, "", "failed to get history for key %s: %v", key, err)
 	}
 	defer iterator.Close()
 	
@@ -138,7 +138,7 @@ func (ps *PersistenceService) GetHistory(stub shim.ChaincodeStubInterface, key s
 	for iterator.HasNext() {
 		response, err := iterator.Next()
 		if err != nil {
-			return nil, fmt.Errorf("failed to iterate history: %v", err)
+			return nil, NewChaincodeError(ErrCodeLedger, "", "failed to iterate history: %v", err)
 		}
 		
 		entry := interfaces.HistoryEntry{
@@ -0,0 +1,256 @@
+package services
+
+import (
+	"crypto/sha256"
+	"encoding/binary"
+	"fmt"
+	"math"
+	"time"
+
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// Quality review item statuses
+const (
+	QAReviewItemPending  = "PENDING"
+	QAReviewItemReviewed = "REVIEWED"
+)
+
+// qaDateFormat is the day bucket used by the QA_BY_DATE index
+const qaDateFormat = "2006-01-02"
+
+// QASamplingRate is the share of completed decisions of one type queued for quality review
+type QASamplingRate struct {
+	DecisionType  string    `json:"decisionType"`
+	Rate          float64   `json:"rate"` // 0-1
+	LastUpdated   time.Time `json:"lastUpdated"`
+	LastUpdatedBy string    `json:"lastUpdatedBy"`
+}
+
+// QAReviewItem is a completed decision sampled for quality review, with the reviewer's findings
+type QAReviewItem struct {
+	ItemID       string     `json:"itemID"`
+	DecisionType string     `json:"decisionType"`
+	EntityID     string     `json:"entityID"`
+	EntityType   string     `json:"entityType"`
+	Decision     string     `json:"decision"`
+	Team         string     `json:"team"` // Team of the decision maker, for defect rate reporting
+	DecidedBy    string     `json:"decidedBy"`
+	DecidedDate  time.Time  `json:"decidedDate"`
+	DecisionTxID string     `json:"decisionTxID"`
+	Status       string     `json:"status"`
+	ReviewedBy   string     `json:"reviewedBy,omitempty"`
+	ReviewedDate *time.Time `json:"reviewedDate,omitempty"`
+	Outcome      string     `json:"outcome,omitempty"`
+	DefectCodes  []string   `json:"defectCodes,omitempty"`
+	Findings     string     `json:"findings,omitempty"`
+}
+
+// QAService samples completed decisions into the quality review queue and tracks their review
+type QAService struct {
+	persistenceService *PersistenceService
+}
+
+// NewQAService creates a new quality review service
+func NewQAService() *QAService {
+	return &QAService{
+		persistenceService: NewPersistenceService(),
+	}
+}
+
+// PutSamplingRate stores the sampling rate of a decision type
+func (qs *QAService) PutSamplingRate(stub shim.ChaincodeStubInterface, rate *QASamplingRate) error {
+	rateKey, err := stub.CreateCompositeKey(config.QASamplingRatePrefix, []string{rate.DecisionType})
+	if err != nil {
+		return fmt.Errorf("failed to create sampling rate key: %w", err)
+	}
+	return qs.persistenceService.Put(stub, rateKey, rate)
+}
+
+// GetSamplingRates returns every configured sampling rate; unconfigured decision types are not sampled
+func (qs *QAService) GetSamplingRates(stub shim.ChaincodeStubInterface) ([]QASamplingRate, error) {
+	iterator, err := stub.GetStateByPartialCompositeKey(config.QASamplingRatePrefix, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sampling rates: %w", err)
+	}
+	defer iterator.Close()
+
+	rates := []QASamplingRate{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate sampling rates: %w", err)
+		}
+
+		var rate QASamplingRate
+		if err := utils.UnmarshalJSON(response.Value, &rate); err != nil {
+			return nil, err
+		}
+		rates = append(rates, rate)
+	}
+
+	return rates, nil
+}
+
+// SampleDecision draws against the decision type's sampling rate and, when selected, queues the
+// decision for review. It returns the queued item, or nil when the decision was not sampled. The
+// decision maker's team is taken from the invoking identity.
+func (qs *QAService) SampleDecision(stub shim.ChaincodeStubInterface, decisionType, entityID, entityType, decision, decidedBy string, decidedDate time.Time) (*QAReviewItem, error) {
+	rateKey, err := stub.CreateCompositeKey(config.QASamplingRatePrefix, []string{decisionType})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create sampling rate key: %w", err)
+	}
+	exists, err := qs.persistenceService.Exists(stub, rateKey)
+	if err != nil || !exists {
+		return nil, err
+	}
+	var rate QASamplingRate
+	if err := qs.persistenceService.Get(stub, rateKey, &rate); err != nil {
+		return nil, fmt.Errorf("failed to get sampling rate: %w", err)
+	}
+
+	if !Sampled(stub.GetTxID()+"|"+entityID, rate.Rate) {
+		return nil, nil
+	}
+
+	team, err := InvokerTeam(stub)
+	if err != nil {
+		return nil, err
+	}
+	if team == "" {
+		team = "UNASSIGNED"
+	}
+
+	item := &QAReviewItem{
+		ItemID:       fmt.Sprintf("%s_%s_%s", config.QAReviewItemPrefix, entityID, stub.GetTxID()),
+		DecisionType: decisionType,
+		EntityID:     entityID,
+		EntityType:   entityType,
+		Decision:     decision,
+		Team:         team,
+		DecidedBy:    decidedBy,
+		DecidedDate:  decidedDate,
+		DecisionTxID: stub.GetTxID(),
+		Status:       QAReviewItemPending,
+	}
+	if err := qs.PutItem(stub, item); err != nil {
+		return nil, err
+	}
+	if err := qs.putIndex(stub, "QA_QUEUE", decisionType, item.ItemID); err != nil {
+		return nil, err
+	}
+	if err := qs.putIndex(stub, "QA_BY_DATE", decidedDate.UTC().Format(qaDateFormat), item.ItemID); err != nil {
+		return nil, err
+	}
+
+	return item, nil
+}
+
+// CompleteReview stores a reviewed item and removes it from the review queue
+func (qs *QAService) CompleteReview(stub shim.ChaincodeStubInterface, item *QAReviewItem) error {
+	if err := qs.PutItem(stub, item); err != nil {
+		return err
+	}
+
+	queueKey, err := stub.CreateCompositeKey("QA_QUEUE", []string{item.DecisionType, item.ItemID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := stub.DelState(queueKey); err != nil {
+		return fmt.Errorf("failed to remove QA_QUEUE index: %w", err)
+	}
+	return nil
+}
+
+// PutItem stores a review item
+func (qs *QAService) PutItem(stub shim.ChaincodeStubInterface, item *QAReviewItem) error {
+	itemKey, err := stub.CreateCompositeKey(config.QAReviewItemPrefix, []string{item.ItemID})
+	if err != nil {
+		return fmt.Errorf("failed to create review item key: %w", err)
+	}
+	return qs.persistenceService.Put(stub, itemKey, item)
+}
+
+// GetItem retrieves a review item
+func (qs *QAService) GetItem(stub shim.ChaincodeStubInterface, itemID string) (*QAReviewItem, error) {
+	itemKey, err := stub.CreateCompositeKey(config.QAReviewItemPrefix, []string{itemID})
+	if err != nil {
+		return nil, fmt.Errorf("failed to create review item key: %w", err)
+	}
+
+	var item QAReviewItem
+	if err := qs.persistenceService.Get(stub, itemKey, &item); err != nil {
+		return nil, fmt.Errorf("review item not found: %w", err)
+	}
+	return &item, nil
+}
+
+// GetQueuePage returns a page of items awaiting review for a decision type
+func (qs *QAService) GetQueuePage(stub shim.ChaincodeStubInterface, decisionType string, pageSize int, bookmark string) ([]QAReviewItem, string, error) {
+	entries, nextBookmark, err := qs.persistenceService.GetPageByPartialCompositeKeys(stub, "QA_QUEUE", [][]string{{decisionType}}, pageSize, bookmark)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to get review queue: %w", err)
+	}
+	return qs.itemsFor(stub, entries), nextBookmark, nil
+}
+
+// GetItemsByDate returns every item sampled from decisions made between two days inclusive
+func (qs *QAService) GetItemsByDate(stub shim.ChaincodeStubInterface, fromDate, toDate time.Time) ([]QAReviewItem, error) {
+	items := []QAReviewItem{}
+	for day := fromDate; !day.After(toDate); day = day.AddDate(0, 0, 1) {
+		iterator, err := stub.GetStateByPartialCompositeKey("QA_BY_DATE", []string{day.Format(qaDateFormat)})
+		if err != nil {
+			return nil, fmt.Errorf("failed to get review items: %w", err)
+		}
+
+		entries := []StateEntry{}
+		for iterator.HasNext() {
+			response, err := iterator.Next()
+			if err != nil {
+				iterator.Close()
+				return nil, fmt.Errorf("failed to iterate review items: %w", err)
+			}
+			entries = append(entries, StateEntry{Key: response.Key, Value: response.Value})
+		}
+		iterator.Close()
+
+		items = append(items, qs.itemsFor(stub, entries)...)
+	}
+	return items, nil
+}
+
+func (qs *QAService) itemsFor(stub shim.ChaincodeStubInterface, entries []StateEntry) []QAReviewItem {
+	items := []QAReviewItem{}
+	for _, entry := range entries {
+		item, err := qs.GetItem(stub, string(entry.Value))
+		if err != nil {
+			continue // Skip if item not found
+		}
+		items = append(items, *item)
+	}
+	return items
+}
+
+func (qs *QAService) putIndex(stub shim.ChaincodeStubInterface, objectType, attribute, itemID string) error {
+	indexKey, err := stub.CreateCompositeKey(objectType, []string{attribute, itemID})
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %w", err)
+	}
+	if err := stub.PutState(indexKey, []byte(itemID)); err != nil {
+		return fmt.Errorf("failed to create %s index: %w", objectType, err)
+	}
+	return nil
+}
+
+// Sampled makes a random draw against rate (0-1) seeded by key. Seed with the transaction ID so
+// every endorsing peer makes the same draw.
+func Sampled(key string, rate float64) bool {
+	if rate <= 0 {
+		return false
+	}
+	digest := sha256.Sum256([]byte(key))
+	draw := float64(binary.BigEndian.Uint64(digest[:8])) / float64(math.MaxUint64)
+	return draw < rate
+}
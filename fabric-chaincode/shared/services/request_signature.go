@@ -0,0 +1,158 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/hyperledger/fabric-protos-go/peer"
+	"github.com/golang/protobuf/proto"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+)
+
+// Ways the gateway authenticates a partner's request
+const (
+	RequestSignatureHMAC = "HMAC-SHA256" // Signature with the partner's shared key over the canonical request
+	RequestSignatureMTLS = "MTLS"        // Client certificate presented on the partner's TLS connection
+)
+
+// RequestSignature is the gateway's evidence that a partner originated a request. The gateway
+// verifies the partner's signature or client certificate before submitting and passes the evidence
+// in transient data. The canonical request is METHOD\nPATH\nTIMESTAMP\nNONCE\nBODYHASH, so an
+// HMAC can be re-verified later with the partner's key from the recorded fields alone.
+type RequestSignature struct {
+	PartnerID             string `json:"partnerID"`
+	KeyID                 string `json:"keyID,omitempty"`
+	Algorithm             string `json:"algorithm"`
+	Signature             string `json:"signature,omitempty"` // Hex HMAC of the canonical request, for HMAC-SHA256
+	Method                string `json:"method"`
+	Path                  string `json:"path"`
+	Timestamp             string `json:"timestamp"` // RFC 3339, as the partner signed it
+	Nonce                 string `json:"nonce"`
+	BodyHash              string `json:"bodyHash"`                        // Hex SHA-256 of the HTTP body the partner sent
+	ClientCertFingerprint string `json:"clientCertFingerprint,omitempty"` // Hex SHA-256 of the partner's TLS client certificate
+	PayloadHash           string `json:"payloadHash"`                     // PayloadHash of the chaincode request the gateway built from it
+}
+
+// GetRequestSignature returns the signature evidence the gateway passed with the transaction, or
+// nil when the request did not come from a partner. Evidence that is incomplete, stale or bound to
+// a different chaincode request is rejected.
+func GetRequestSignature(stub shim.ChaincodeStubInterface) (*RequestSignature, error) {
+	transient, err := stub.GetTransient()
+	if err != nil {
+		return nil, fmt.Errorf("failed to read transient data: %w", err)
+	}
+	signatureBytes, ok := transient[config.RequestSignatureTransientKey]
+	if !ok {
+		return nil, nil
+	}
+
+	var signature RequestSignature
+	if err := json.Unmarshal(signatureBytes, &signature); err != nil {
+		return nil, NewChaincodeError(ErrCodeInvalidArgument, "", "failed to parse request signature: %v", err)
+	}
+	if err := validateRequestSignature(stub, &signature); err != nil {
+		return nil, fmt.Errorf("invalid request signature: %w", err)
+	}
+	return &signature, nil
+}
+
+// validateRequestSignature checks the evidence is complete, was signed within
+// config.TransactionTimeout of the transaction and covers the payload submitted
+func validateRequestSignature(stub shim.ChaincodeStubInterface, signature *RequestSignature) error {
+	var missing []string
+	for field, value := range map[string]string{
+		"partnerID":   signature.PartnerID,
+		"method":      signature.Method,
+		"path":        signature.Path,
+		"timestamp":   signature.Timestamp,
+		"nonce":       signature.Nonce,
+		"bodyHash":    signature.BodyHash,
+		"payloadHash": signature.PayloadHash,
+	} {
+		if strings.TrimSpace(value) == "" {
+			missing = append(missing, field)
+		}
+	}
+	switch signature.Algorithm {
+	case RequestSignatureHMAC:
+		if signature.KeyID == "" {
+			missing = append(missing, "keyID")
+		}
+		if signature.Signature == "" {
+			missing = append(missing, "signature")
+		}
+	case RequestSignatureMTLS:
+		if signature.ClientCertFingerprint == "" {
+			missing = append(missing, "clientCertFingerprint")
+		}
+	default:
+		return fmt.Errorf("unsupported algorithm %q", signature.Algorithm)
+	}
+	if len(missing) > 0 {
+		sort.Strings(missing)
+		return fmt.Errorf("missing %s", strings.Join(missing, ", "))
+	}
+
+	signedAt, err := time.Parse(time.RFC3339, signature.Timestamp)
+	if err != nil {
+		return fmt.Errorf("timestamp %s is not RFC 3339", signature.Timestamp)
+	}
+	now, err := TxTime(stub)
+	if err != nil {
+		return err
+	}
+	if skew := now.Sub(signedAt); skew > config.TransactionTimeout || skew < -config.TransactionTimeout {
+		return fmt.Errorf("signed at %s, more than %s from the transaction time", signature.Timestamp, config.TransactionTimeout)
+	}
+
+	function, args, err := submittedPayload(stub)
+	if err != nil {
+		return err
+	}
+	payloadHash, err := PayloadHash(function, args)
+	if err != nil {
+		return err
+	}
+	if signature.PayloadHash != payloadHash {
+		return fmt.Errorf("bound to a different payload than the one submitted")
+	}
+	return nil
+}
+
+// submittedPayload returns the function and arguments of the proposal the client submitted. In a
+// chaincode-to-chaincode call the stub holds the called function instead, while the evidence
+// covers the proposal, so the proposal's input is used whenever it is available.
+func submittedPayload(stub shim.ChaincodeStubInterface) (string, []string, error) {
+	function, args := stub.GetFunctionAndParameters()
+	signedProposal, err := stub.GetSignedProposal()
+	if err != nil || signedProposal == nil {
+		return function, args, nil
+	}
+
+	var proposal peer.Proposal
+	if err := proto.Unmarshal(signedProposal.ProposalBytes, &proposal); err != nil {
+		return "", nil, fmt.Errorf("failed to parse proposal: %w", err)
+	}
+	var payload peer.ChaincodeProposalPayload
+	if err := proto.Unmarshal(proposal.Payload, &payload); err != nil {
+		return "", nil, fmt.Errorf("failed to parse proposal payload: %w", err)
+	}
+	var invocation peer.ChaincodeInvocationSpec
+	if err := proto.Unmarshal(payload.Input, &invocation); err != nil {
+		return "", nil, fmt.Errorf("failed to parse proposal input: %w", err)
+	}
+
+	input := invocation.GetChaincodeSpec().GetInput().GetArgs()
+	if len(input) == 0 {
+		return function, args, nil
+	}
+	args = make([]string, 0, len(input)-1)
+	for _, arg := range input[1:] {
+		args = append(args, string(arg))
+	}
+	return string(input[0]), args, nil
+}
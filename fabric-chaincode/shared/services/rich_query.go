@@ -0,0 +1,98 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// Selector builds a CouchDB Mango selector. Rich queries only run on peers using CouchDB as the
+// state database; the matching index definitions live under each chaincode's
+// META-INF/statedb/couchdb/indexes so they are deployed with it.
+type Selector struct {
+	conditions map[string]interface{}
+}
+
+// NewSelector creates an empty selector, which matches every document
+func NewSelector() *Selector {
+	return &Selector{conditions: map[string]interface{}{}}
+}
+
+// Eq matches documents whose field equals value
+func (s *Selector) Eq(field string, value interface{}) *Selector {
+	return s.operator(field, "$eq", value)
+}
+
+// In matches documents whose field equals one of values
+func (s *Selector) In(field string, values ...interface{}) *Selector {
+	return s.operator(field, "$in", values)
+}
+
+// Range matches documents whose field lies between from and to inclusive. A nil bound leaves that
+// side of the range open.
+func (s *Selector) Range(field string, from, to interface{}) *Selector {
+	if from != nil {
+		s.operator(field, "$gte", from)
+	}
+	if to != nil {
+		s.operator(field, "$lte", to)
+	}
+	return s
+}
+
+// KeyPrefix matches documents whose ledger key starts with prefix. CouchDB holds the key in _id,
+// so this also keeps a query from reading other entity types.
+func (s *Selector) KeyPrefix(prefix string) *Selector {
+	s.operator("_id", "$gte", prefix)
+	return s.operator("_id", "$lt", prefix+"\uffff")
+}
+
+func (s *Selector) operator(field, op string, value interface{}) *Selector {
+	condition, ok := s.conditions[field].(map[string]interface{})
+	if !ok {
+		condition = map[string]interface{}{}
+		s.conditions[field] = condition
+	}
+	condition[op] = value
+	return s
+}
+
+// Query returns the query string to pass to GetQueryResultWithPagination
+func (s *Selector) Query() (string, error) {
+	queryBytes, err := json.Marshal(map[string]interface{}{"selector": s.conditions})
+	if err != nil {
+		return "", fmt.Errorf("failed to marshal rich query: %w", err)
+	}
+	return string(queryBytes), nil
+}
+
+// GetPageByQuery runs a rich query and returns up to pageSize entries after the bookmark, together
+// with the bookmark for the next page ("" when there are no more). Unlike
+// GetPageByPartialCompositeKeys the bookmark is opaque and issued by CouchDB.
+func (ps *PersistenceService) GetPageByQuery(stub shim.ChaincodeStubInterface, query string, pageSize int, bookmark string) ([]StateEntry, string, error) {
+	iterator, metadata, err := stub.GetQueryResultWithPagination(query, int32(pageSize), bookmark)
+	if err != nil {
+		return nil, "", fmt.Errorf("failed to execute rich query: %w", err)
+	}
+	if iterator == nil {
+		return nil, "", fmt.Errorf("rich queries require a CouchDB state database")
+	}
+	defer iterator.Close()
+
+	entries := []StateEntry{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, "", fmt.Errorf("failed to iterate rich query results: %w", err)
+		}
+		entries = append(entries, StateEntry{Key: response.Key, Value: response.Value})
+	}
+
+	// A short page is the last one
+	nextBookmark := ""
+	if metadata != nil && len(entries) == pageSize {
+		nextBookmark = metadata.Bookmark
+	}
+	return entries, nextBookmark, nil
+}
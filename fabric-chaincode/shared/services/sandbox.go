@@ -0,0 +1,116 @@
+package services
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+)
+
+// SandboxActor designates an actor whose records are UAT data on a shared channel
+type SandboxActor struct {
+	ActorID       string    `json:"actorID"`
+	Sandbox       bool      `json:"sandbox"`
+	Reason        string    `json:"reason"`
+	LastUpdated   time.Time `json:"lastUpdated"`
+	LastUpdatedBy string    `json:"lastUpdatedBy"`
+}
+
+// SandboxService manages sandbox actor designations and sandbox record cleanup
+type SandboxService struct {
+	persistenceService *PersistenceService
+}
+
+// NewSandboxService creates a new sandbox service
+func NewSandboxService() *SandboxService {
+	return &SandboxService{
+		persistenceService: NewPersistenceService(),
+	}
+}
+
+// PutSandboxActor stores the sandbox designation for an actor
+func (ss *SandboxService) PutSandboxActor(stub shim.ChaincodeStubInterface, actor *SandboxActor) error {
+	actorKey, err := stub.CreateCompositeKey(config.SandboxActorPrefix, []string{actor.ActorID})
+	if err != nil {
+		return fmt.Errorf("failed to create sandbox actor key: %w", err)
+	}
+	return ss.persistenceService.Put(stub, actorKey, actor)
+}
+
+// GetSandboxActors retrieves all stored sandbox designations
+func (ss *SandboxService) GetSandboxActors(stub shim.ChaincodeStubInterface) ([]SandboxActor, error) {
+	iterator, err := stub.GetStateByPartialCompositeKey(config.SandboxActorPrefix, []string{})
+	if err != nil {
+		return nil, fmt.Errorf("failed to get sandbox actors: %w", err)
+	}
+	defer iterator.Close()
+
+	var actors []SandboxActor
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate sandbox actors: %w", err)
+		}
+
+		var actor SandboxActor
+		if err := utils.UnmarshalJSON(response.Value, &actor); err != nil {
+			return nil, err
+		}
+		actors = append(actors, actor)
+	}
+
+	return actors, nil
+}
+
+// IsSandboxActor reports whether records created by the actor belong to the sandbox
+func (ss *SandboxService) IsSandboxActor(stub shim.ChaincodeStubInterface, actorID string) (bool, error) {
+	if actorID == "" {
+		return false, nil
+	}
+
+	actorKey, err := stub.CreateCompositeKey(config.SandboxActorPrefix, []string{actorID})
+	if err != nil {
+		return false, fmt.Errorf("failed to create sandbox actor key: %w", err)
+	}
+
+	exists, err := ss.persistenceService.Exists(stub, actorKey)
+	if err != nil || !exists {
+		return false, err
+	}
+
+	var actor SandboxActor
+	if err := ss.persistenceService.Get(stub, actorKey, &actor); err != nil {
+		return false, err
+	}
+	return actor.Sandbox, nil
+}
+
+// DeleteByPartialCompositeKey deletes every key under a partial composite key, returning the values removed
+func (ss *SandboxService) DeleteByPartialCompositeKey(stub shim.ChaincodeStubInterface, objectType string, attributes []string) ([][]byte, error) {
+	iterator, err := stub.GetStateByPartialCompositeKey(objectType, attributes)
+	if err != nil {
+		return nil, fmt.Errorf("failed to query %s records: %w", objectType, err)
+	}
+	defer iterator.Close()
+
+	var keys []string
+	var values [][]byte
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, fmt.Errorf("failed to iterate %s records: %w", objectType, err)
+		}
+		keys = append(keys, response.Key)
+		values = append(values, response.Value)
+	}
+
+	for _, key := range keys {
+		if err := stub.DelState(key); err != nil {
+			return nil, fmt.Errorf("failed to delete %s record: %w", objectType, err)
+		}
+	}
+
+	return values, nil
+}
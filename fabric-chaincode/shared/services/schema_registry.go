@@ -0,0 +1,104 @@
+package services
+
+import (
+	"strings"
+
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+)
+
+// SchemaFactory returns a pointer to a zero value of the struct a stored value must decode into
+type SchemaFactory func() interface{}
+
+// SchemaEntry maps a state namespace to the struct its values are stored as
+type SchemaEntry struct {
+	Namespace  string        `json:"namespace"` // Simple-key prefix or composite key object type
+	EntityType string        `json:"entityType"`
+	Composite  bool          `json:"composite"`
+	Factory    SchemaFactory `json:"-"` // nil for index namespaces whose values are raw IDs
+}
+
+// IsIndex reports whether the namespace holds raw ID index values rather than JSON entities
+func (e SchemaEntry) IsIndex() bool {
+	return e.Factory == nil
+}
+
+// SchemaRegistry lists the state namespaces a chaincode owns and the structs their values decode into
+type SchemaRegistry struct {
+	entries []SchemaEntry
+}
+
+// NewSchemaRegistry creates a schema registry pre-populated with the shared operational namespaces
+func NewSchemaRegistry() *SchemaRegistry {
+	r := &SchemaRegistry{}
+	r.RegisterCompositeKey(config.FunctionFlagPrefix, "FunctionFlag", func() interface{} { return &FunctionFlag{} })
+	r.RegisterCompositeKey(config.SandboxActorPrefix, "SandboxActor", func() interface{} { return &SandboxActor{} })
+	r.RegisterCompositeKey(config.ActorPrefix, "ActorIdentity", func() interface{} { return &ActorIdentity{} })
+	r.RegisterCompositeKey(config.CredentialRotationPrefix, "CredentialRotation", func() interface{} { return &CredentialRotation{} })
+	r.RegisterCompositeKey(config.QASamplingRatePrefix, "QASamplingRate", func() interface{} { return &QASamplingRate{} })
+	r.RegisterCompositeKey(config.QAReviewItemPrefix, "QAReviewItem", func() interface{} { return &QAReviewItem{} })
+	r.RegisterCompositeKey(config.PayloadArchivePolicyPrefix, "PayloadArchivePolicy", func() interface{} { return &PayloadArchivePolicy{} })
+	r.RegisterCompositeKey(config.PayloadArchivePrefix, "ArchivedPayload", func() interface{} { return &ArchivedPayload{} })
+	r.RegisterCompositeKey(config.EntityNotePrefix, "EntityNote", func() interface{} { return &EntityNote{} })
+	r.RegisterCompositeKey(config.EntityNoteRevisionPrefix, "EntityNoteRevision", func() interface{} { return &EntityNoteRevision{} })
+	r.RegisterCompositeKey(config.EntityLockPrefix, "EntityLock", func() interface{} { return &EntityLock{} })
+	r.RegisterCompositeKey(config.EntityFreezePrefix, "EntityFreeze", func() interface{} { return &EntityFreeze{} })
+	r.RegisterCompositeKey(config.CounterPrefix, "Counter", func() interface{} { return &Counter{} })
+	r.RegisterCompositeKey(config.CounterTotalPrefix, "Counter", func() interface{} { return &Counter{} })
+	r.RegisterPrefix(config.TimestampCutoverKey, "TimestampCutover", func() interface{} { return &TimestampCutover{} })
+	r.RegisterCompositeKey(config.IdempotencyKeyPrefix, "IdempotencyRecord", func() interface{} { return &IdempotencyRecord{} })
+	r.RegisterCompositeKey(config.AuditPackagePrefix, "AuditManifest", func() interface{} { return &AuditManifest{} })
+	r.RegisterCompositeKey(config.DataQualityReportPrefix, "DataQualityReport", func() interface{} { return &DataQualityReport{} })
+	r.RegisterCompositeKey(config.RemediationTaskPrefix, "RemediationTask", func() interface{} { return &RemediationTask{} })
+	r.RegisterCompositeKey("HISTORY", "HistoryEntry", func() interface{} { return &map[string]interface{}{} })
+	return r
+}
+
+// RegisterPrefix registers a simple-key namespace such as "LOAN_"
+func (r *SchemaRegistry) RegisterPrefix(prefix, entityType string, factory SchemaFactory) *SchemaRegistry {
+	r.entries = append(r.entries, SchemaEntry{Namespace: prefix, EntityType: entityType, Factory: factory})
+	return r
+}
+
+// RegisterIndexPrefix registers a simple-key index namespace whose values are raw IDs. Index
+// prefixes are never decoded but stop their keys being attributed to a shorter entity prefix.
+func (r *SchemaRegistry) RegisterIndexPrefix(prefix string) *SchemaRegistry {
+	r.entries = append(r.entries, SchemaEntry{Namespace: prefix, EntityType: "Index"})
+	return r
+}
+
+// RegisterCompositeKey registers a composite key namespace by object type
+func (r *SchemaRegistry) RegisterCompositeKey(objectType, entityType string, factory SchemaFactory) *SchemaRegistry {
+	r.entries = append(r.entries, SchemaEntry{Namespace: objectType, EntityType: entityType, Composite: true, Factory: factory})
+	return r
+}
+
+// Entries returns all registered namespaces in registration order
+func (r *SchemaRegistry) Entries() []SchemaEntry {
+	return r.entries
+}
+
+// Lookup finds a registered namespace by name
+func (r *SchemaRegistry) Lookup(namespace string) (SchemaEntry, bool) {
+	for _, entry := range r.entries {
+		if entry.Namespace == namespace {
+			return entry, true
+		}
+	}
+	return SchemaEntry{}, false
+}
+
+// OwnerOf returns the simple-key namespace that owns a key, preferring the longest matching prefix
+func (r *SchemaRegistry) OwnerOf(key string) (SchemaEntry, bool) {
+	var owner SchemaEntry
+	found := false
+	for _, entry := range r.entries {
+		if entry.Composite || !strings.HasPrefix(key, entry.Namespace) {
+			continue
+		}
+		if !found || len(entry.Namespace) > len(owner.Namespace) {
+			owner = entry
+			found = true
+		}
+	}
+	return owner, found
+}
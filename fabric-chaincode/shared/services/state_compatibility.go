@@ -0,0 +1,213 @@
+package services
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// CompatibilityIssue describes a stored value that does not decode into its registered schema
+type CompatibilityIssue struct {
+	Namespace  string `json:"namespace"`
+	EntityType string `json:"entityType"`
+	Key        string `json:"key"`
+	Error      string `json:"error"`
+}
+
+// NamespaceCompatibility summarises the check of one namespace
+type NamespaceCompatibility struct {
+	Namespace        string `json:"namespace"`
+	EntityType       string `json:"entityType"`
+	KeysChecked      int    `json:"keysChecked"`
+	IncompatibleKeys int    `json:"incompatibleKeys"`
+}
+
+// CompatibilityReport is the result of checking stored state against a schema registry
+type CompatibilityReport struct {
+	Compatible bool                     `json:"compatible"`
+	Namespaces []NamespaceCompatibility `json:"namespaces"`
+	Issues     []CompatibilityIssue     `json:"issues"`
+	CheckedAt  time.Time                `json:"checkedAt"`
+}
+
+// StateCompatibilityService checks that stored state decodes into the structs of a schema registry
+type StateCompatibilityService struct {
+	registry *SchemaRegistry
+}
+
+// NewStateCompatibilityService creates a new state compatibility service
+func NewStateCompatibilityService(registry *SchemaRegistry) *StateCompatibilityService {
+	return &StateCompatibilityService{
+		registry: registry,
+	}
+}
+
+// CheckState walks each registered namespace, or only the named one, and reports incompatible keys
+func (s *StateCompatibilityService) CheckState(stub shim.ChaincodeStubInterface, namespace string) (*CompatibilityReport, error) {
+	entries := s.registry.Entries()
+	if namespace != "" {
+		entry, ok := s.registry.Lookup(namespace)
+		if !ok {
+			return nil, fmt.Errorf("namespace %s is not registered", namespace)
+		}
+		entries = []SchemaEntry{entry}
+	}
+
+	now, err := TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+
+	report := &CompatibilityReport{
+		Compatible: true,
+		Namespaces: []NamespaceCompatibility{},
+		Issues:     []CompatibilityIssue{},
+		CheckedAt:  now,
+	}
+
+	for _, entry := range entries {
+		if entry.IsIndex() {
+			continue
+		}
+
+		summary, issues, err := s.checkNamespace(stub, entry)
+		if err != nil {
+			return nil, err
+		}
+		report.Namespaces = append(report.Namespaces, *summary)
+		report.Issues = append(report.Issues, issues...)
+	}
+	report.Compatible = len(report.Issues) == 0
+
+	return report, nil
+}
+
+func (s *StateCompatibilityService) checkNamespace(stub shim.ChaincodeStubInterface, entry SchemaEntry) (*NamespaceCompatibility, []CompatibilityIssue, error) {
+	var iterator shim.StateQueryIteratorInterface
+	var err error
+	if entry.Composite {
+		iterator, err = stub.GetStateByPartialCompositeKey(entry.Namespace, []string{})
+	} else {
+		iterator, err = stub.GetStateByRange(entry.Namespace, entry.Namespace+string(utf8.MaxRune))
+	}
+	if err != nil {
+		return nil, nil, fmt.Errorf("failed to scan namespace %s: %w", entry.Namespace, err)
+	}
+	defer iterator.Close()
+
+	summary := &NamespaceCompatibility{Namespace: entry.Namespace, EntityType: entry.EntityType}
+	issues := []CompatibilityIssue{}
+	for iterator.HasNext() {
+		response, err := iterator.Next()
+		if err != nil {
+			return nil, nil, fmt.Errorf("failed to iterate namespace %s: %w", entry.Namespace, err)
+		}
+
+		// Keys under a longer registered prefix belong to that namespace
+		if !entry.Composite {
+			if owner, ok := s.registry.OwnerOf(response.Key); ok && owner.Namespace != entry.Namespace {
+				continue
+			}
+		}
+
+		summary.KeysChecked++
+		if err := decodeStrict(response.Value, entry.Factory()); err != nil {
+			summary.IncompatibleKeys++
+			issues = append(issues, CompatibilityIssue{
+				Namespace:  entry.Namespace,
+				EntityType: entry.EntityType,
+				Key:        response.Key,
+				Error:      err.Error(),
+			})
+		}
+	}
+
+	return summary, issues, nil
+}
+
+// decodeStrict rejects type mismatches and fields the current struct no longer declares,
+// since those would be silently dropped on the next write
+func decodeStrict(data []byte, target interface{}) error {
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(target); err != nil {
+		return err
+	}
+	if decoder.More() {
+		return fmt.Errorf("unexpected data after JSON value")
+	}
+
+	// Structs with their own UnmarshalJSON decode without the decoder's unknown field check
+	if _, ok := target.(json.Unmarshaler); ok {
+		return checkDeclaredFields(data, target)
+	}
+	return nil
+}
+
+// checkDeclaredFields rejects top-level fields the target struct does not declare. Names match
+// case-insensitively, as they do when decoding.
+func checkDeclaredFields(data []byte, target interface{}) error {
+	var fields map[string]json.RawMessage
+	if err := json.Unmarshal(data, &fields); err != nil {
+		return nil // Not an object; the struct's own UnmarshalJSON has accepted it
+	}
+
+	targetType := reflect.TypeOf(target)
+	for targetType.Kind() == reflect.Ptr {
+		targetType = targetType.Elem()
+	}
+	if targetType.Kind() != reflect.Struct {
+		return nil
+	}
+	declared := map[string]bool{}
+	collectJSONFields(targetType, declared)
+
+	unknown := []string{}
+	for name := range fields {
+		if !declared[strings.ToLower(name)] {
+			unknown = append(unknown, name)
+		}
+	}
+	if len(unknown) > 0 {
+		sort.Strings(unknown)
+		return fmt.Errorf("json: unknown field %q", unknown[0])
+	}
+	return nil
+}
+
+// collectJSONFields adds the lower-cased JSON names of a struct's fields, including those
+// promoted from embedded structs
+func collectJSONFields(structType reflect.Type, declared map[string]bool) {
+	for i := 0; i < structType.NumField(); i++ {
+		field := structType.Field(i)
+		tag := field.Tag.Get("json")
+		if tag == "-" {
+			continue
+		}
+		name := strings.Split(tag, ",")[0]
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Ptr {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				collectJSONFields(embedded, declared)
+				continue
+			}
+		}
+		if !field.IsExported() {
+			continue
+		}
+		if name == "" {
+			name = field.Name
+		}
+		declared[strings.ToLower(name)] = true
+	}
+}
@@ -0,0 +1,91 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/utils"
+)
+
+// TxTime returns the current time from the installed Clock: the transaction timestamp, which is
+// identical on every endorsing peer, unless a test has installed another clock with SetClock.
+// Chaincode must use it rather than time.Now() for anything written to the ledger, emitted in an
+// event or decided by the time, otherwise peers endorse different write sets.
+func TxTime(stub shim.ChaincodeStubInterface) (time.Time, error) {
+	clock.RLock()
+	current := clock.current
+	clock.RUnlock()
+	return current.Now(stub)
+}
+
+// TxTimeString returns the transaction timestamp in utils.TimeFormat, for records and events that
+// hold timestamps as strings
+func TxTimeString(stub shim.ChaincodeStubInterface) (string, error) {
+	now, err := TxTime(stub)
+	if err != nil {
+		return "", err
+	}
+	return utils.FormatTime(now), nil
+}
+
+// TimestampCutover records the transaction from which a chaincode's records carry transaction
+// timestamps. Records written before it carry the endorsing peer's wall-clock time in the peer's
+// local zone, so their timestamps may disagree between peers and should not be compared to the
+// second.
+type TimestampCutover struct {
+	CutoverTime   time.Time `json:"cutoverTime"`
+	TransactionID string    `json:"transactionID"`
+	RecordedBy    string    `json:"recordedBy"`
+}
+
+// IsLegacy reports whether a stored timestamp predates the cutover and so came from a peer's clock
+func (c *TimestampCutover) IsLegacy(timestamp time.Time) bool {
+	return timestamp.Before(c.CutoverTime)
+}
+
+// GetTimestampCutover retrieves the chaincode's timestamp cutover, or nil when none is recorded, in
+// which case every existing record must be treated as legacy
+func GetTimestampCutover(stub shim.ChaincodeStubInterface) (*TimestampCutover, error) {
+	cutoverBytes, err := stub.GetState(config.TimestampCutoverKey)
+	if err != nil {
+		return nil, fmt.Errorf("failed to read timestamp cutover: %w", err)
+	}
+	if cutoverBytes == nil {
+		return nil, nil
+	}
+
+	var cutover TimestampCutover
+	if err := json.Unmarshal(cutoverBytes, &cutover); err != nil {
+		return nil, fmt.Errorf("failed to unmarshal timestamp cutover: %w", err)
+	}
+	return &cutover, nil
+}
+
+// PutTimestampCutover records the current transaction as the timestamp cutover. It is recorded
+// once, after the upgrade introducing transaction timestamps.
+func PutTimestampCutover(stub shim.ChaincodeStubInterface, actorID string) (*TimestampCutover, error) {
+	existing, err := GetTimestampCutover(stub)
+	if err != nil {
+		return nil, err
+	}
+	if existing != nil {
+		return nil, fmt.Errorf("timestamp cutover was already recorded at %s", existing.CutoverTime.Format(time.RFC3339))
+	}
+
+	now, err := TxTime(stub)
+	if err != nil {
+		return nil, err
+	}
+	cutover := &TimestampCutover{
+		CutoverTime:   now,
+		TransactionID: stub.GetTxID(),
+		RecordedBy:    actorID,
+	}
+	if err := NewPersistenceService().Put(stub, config.TimestampCutoverKey, cutover); err != nil {
+		return nil, fmt.Errorf("failed to store timestamp cutover: %w", err)
+	}
+	return cutover, nil
+}
@@ -0,0 +1,99 @@
+package services
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/hyperledger/fabric-chaincode-go/shim"
+)
+
+// EventFunc emits an event once a write batch's writes have been applied
+type EventFunc func(stub shim.ChaincodeStubInterface) error
+
+// batchWrite is a buffered put or delete
+type batchWrite struct {
+	key    string
+	value  []byte
+	delete bool
+}
+
+// WriteBatch accumulates the puts, index entries, deletes and events of a multi-write operation
+// so they reach the stub together in Flush, after every step has been validated. A step that
+// fails before Flush leaves no writes behind, which matters to functions that record an error and
+// carry on, such as batch operations. Fabric does not expose a transaction's own writes to its
+// reads, so buffering does not change what later GetState calls in the transaction see.
+type WriteBatch struct {
+	writes []batchWrite
+	events []EventFunc
+}
+
+// NewWriteBatch creates an empty write batch
+func NewWriteBatch() *WriteBatch {
+	return &WriteBatch{}
+}
+
+// Put marshals a value for key. Marshalling happens immediately so errors surface before Flush.
+func (b *WriteBatch) Put(key string, value interface{}) error {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("failed to marshal data for key %s: %w", key, err)
+	}
+	b.writes = append(b.writes, batchWrite{key: key, value: data})
+	return nil
+}
+
+// PutRaw stores raw bytes for key, typically an ID held by an index entry
+func (b *WriteBatch) PutRaw(key string, value []byte) {
+	b.writes = append(b.writes, batchWrite{key: key, value: value})
+}
+
+// PutIndex stores a composite-key index entry pointing at an ID
+func (b *WriteBatch) PutIndex(stub shim.ChaincodeStubInterface, objectType string, attributes []string, id string) error {
+	key, err := stub.CreateCompositeKey(objectType, attributes)
+	if err != nil {
+		return fmt.Errorf("failed to create composite key: %w", err)
+	}
+	b.PutRaw(key, []byte(id))
+	return nil
+}
+
+// Delete removes key
+func (b *WriteBatch) Delete(key string) {
+	b.writes = append(b.writes, batchWrite{key: key, delete: true})
+}
+
+// Emit queues an event to emit after the batch's writes
+func (b *WriteBatch) Emit(event EventFunc) {
+	b.events = append(b.events, event)
+}
+
+// Len returns the number of buffered writes
+func (b *WriteBatch) Len() int {
+	return len(b.writes)
+}
+
+// Flush applies the buffered writes in order, then emits the queued events. A Flush error may
+// leave some writes applied, so the caller must fail the transaction rather than continue.
+func (b *WriteBatch) Flush(stub shim.ChaincodeStubInterface) error {
+	for _, write := range b.writes {
+		if write.delete {
+			if err := stub.DelState(write.key); err != nil {
+				return NewChaincodeError(ErrCodeLedger, "", "failed to delete state for key %s: %v", write.key, err)
+			}
+			continue
+		}
+		if err := stub.PutState(write.key, write.value); err != nil {
+			return NewChaincodeError(ErrCodeLedger, "", "failed to put state for key %s: %v", write.key, err)
+		}
+	}
+
+	for _, emit := range b.events {
+		if err := emit(stub); err != nil {
+			return fmt.Errorf("failed to emit event: %w", err)
+		}
+	}
+
+	b.writes = nil
+	b.events = nil
+	return nil
+}
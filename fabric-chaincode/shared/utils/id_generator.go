@@ -8,7 +8,8 @@ import (
 	"time"
 )
 
-// GenerateID creates a unique identifier with the given prefix
+// GenerateID creates a unique identifier with the given prefix. It differs between endorsing peers,
+// so IDs written to the ledger must come from services.GenerateDeterministicID instead.
 func GenerateID(prefix string) string {
 	timestamp := time.Now().UnixNano()
 	
@@ -49,6 +50,12 @@ func GenerateUUID() string {
 		randomBytes[10:16])
 }
 
+// HashValue returns the hex SHA-256 of a value, for indexing personal data without storing it in keys
+func HashValue(value string) string {
+	hash := sha256.Sum256([]byte(value))
+	return hex.EncodeToString(hash[:])
+}
+
 // ValidateID checks if an ID has the expected format
 func ValidateID(id, expectedPrefix string) error {
 	if len(id) < len(expectedPrefix)+1 {
@@ -9,7 +9,7 @@ import (
 func MarshalJSON(obj interface{}) ([]byte, error) {
 	data, err := json.Marshal(obj)
 	if err != nil {
-		return nil, fmt.Errorf("failed to marshal JSON: %v", err)
+		return nil, fmt.Errorf("failed to marshal JSON: %w", err)
 	}
 	return data, nil
 }
@@ -17,7 +17,7 @@ func MarshalJSON(obj interface{}) ([]byte, error) {
 // UnmarshalJSON safely unmarshals JSON to an object
 func UnmarshalJSON(data []byte, obj interface{}) error {
 	if err := json.Unmarshal(data, obj); err != nil {
-		return fmt.Errorf("failed to unmarshal JSON: %v", err)
+		return fmt.Errorf("failed to unmarshal JSON: %w", err)
 	}
 	return nil
 }
@@ -40,7 +40,7 @@ func UnmarshalJSONString(jsonStr string, obj interface{}) error {
 func PrettyPrintJSON(obj interface{}) (string, error) {
 	data, err := json.MarshalIndent(obj, "", "  ")
 	if err != nil {
-		return "", fmt.Errorf("failed to pretty print JSON: %v", err)
+		return "", fmt.Errorf("failed to pretty print JSON: %w", err)
 	}
 	return string(data), nil
 }
\ No newline at end of file
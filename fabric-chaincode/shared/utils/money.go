@@ -0,0 +1,315 @@
+package utils
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/config"
+	"github.com/brycemacchaveli/origin.block/fabric-chaincode/shared/validation"
+)
+
+// Money is an amount held as integer minor units of a currency, so sums and differences are exact
+// and every peer computes the same value. It marshals to JSON as a plain decimal number so stored
+// records and API payloads keep the shape they had when amounts were float64.
+type Money struct {
+	minor    int64
+	currency string
+}
+
+// NewMoney rounds an amount half away from zero to the minor units of a currency
+func NewMoney(amount float64, currency string) Money {
+	currency = moneyCurrency(currency)
+	return Money{minor: ToMinorUnits(amount, currencyExponent(currency)), currency: currency}
+}
+
+// MoneyFromMinorUnits builds an amount from integer minor units of a currency
+func MoneyFromMinorUnits(minorUnits int64, currency string) Money {
+	return Money{minor: minorUnits, currency: moneyCurrency(currency)}
+}
+
+// ZeroMoney returns a zero amount of a currency
+func ZeroMoney(currency string) Money {
+	return Money{currency: moneyCurrency(currency)}
+}
+
+// MinorUnits returns the amount in integer minor units of its currency
+func (m Money) MinorUnits() int64 {
+	return m.minor
+}
+
+// Currency returns the ISO 4217 code of the amount, the base currency when unset
+func (m Money) Currency() string {
+	return moneyCurrency(m.currency)
+}
+
+// Float64 returns the amount in major units for callers still working in float64
+func (m Money) Float64() float64 {
+	return FromMinorUnits(m.minor, currencyExponent(m.Currency()))
+}
+
+// IsZero reports whether the amount is zero
+func (m Money) IsZero() bool {
+	return m.minor == 0
+}
+
+// IsPositive reports whether the amount is greater than zero
+func (m Money) IsPositive() bool {
+	return m.minor > 0
+}
+
+// IsNegative reports whether the amount is less than zero
+func (m Money) IsNegative() bool {
+	return m.minor < 0
+}
+
+// Add returns the sum of two amounts of the same currency
+func (m Money) Add(other Money) (Money, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return Money{}, err
+	}
+	return Money{minor: m.minor + other.minor, currency: m.Currency()}, nil
+}
+
+// Sub returns the difference of two amounts of the same currency
+func (m Money) Sub(other Money) (Money, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return Money{}, err
+	}
+	return Money{minor: m.minor - other.minor, currency: m.Currency()}, nil
+}
+
+// Cmp compares two amounts of the same currency, returning -1, 0 or +1
+func (m Money) Cmp(other Money) (int, error) {
+	if err := m.sameCurrency(other); err != nil {
+		return 0, err
+	}
+	switch {
+	case m.minor < other.minor:
+		return -1, nil
+	case m.minor > other.minor:
+		return 1, nil
+	}
+	return 0, nil
+}
+
+// Min returns the smaller of two amounts of the same currency
+func (m Money) Min(other Money) (Money, error) {
+	cmp, err := m.Cmp(other)
+	if err != nil {
+		return Money{}, err
+	}
+	if cmp > 0 {
+		return other, nil
+	}
+	return m, nil
+}
+
+// Mul scales the amount by a factor such as a rate or a proportion, rounding half away from zero
+// to the minor units of its currency
+func (m Money) Mul(factor float64) Money {
+	return Money{minor: int64(math.Round(float64(m.minor) * factor)), currency: m.Currency()}
+}
+
+// In relabels the amount as a currency with a different minor-unit exponent, keeping its value.
+// It fails when the currency has too few minor units to hold the amount exactly.
+func (m Money) In(currency string) (Money, error) {
+	currency = moneyCurrency(currency)
+	from, to := currencyExponent(m.Currency()), currencyExponent(currency)
+	minor := m.minor
+	for ; from < to; from++ {
+		minor *= 10
+	}
+	for ; from > to; from-- {
+		if minor%10 != 0 {
+			return Money{}, fmt.Errorf("amount %s cannot be held exactly in %s", m, currency)
+		}
+		minor /= 10
+	}
+	return Money{minor: minor, currency: currency}, nil
+}
+
+// String formats the amount as an exact decimal in major units, trailing zeros trimmed
+func (m Money) String() string {
+	exponent := currencyExponent(m.Currency())
+	sign := ""
+	minor := m.minor
+	if minor < 0 {
+		sign = "-"
+		minor = -minor
+	}
+	digits := strconv.FormatInt(minor, 10)
+	if exponent == 0 {
+		return sign + digits
+	}
+	if len(digits) <= exponent {
+		digits = strings.Repeat("0", exponent-len(digits)+1) + digits
+	}
+	whole, fraction := digits[:len(digits)-exponent], strings.TrimRight(digits[len(digits)-exponent:], "0")
+	if fraction == "" {
+		return sign + whole
+	}
+	return sign + whole + "." + fraction
+}
+
+// MarshalJSON writes the amount as a plain JSON number
+func (m Money) MarshalJSON() ([]byte, error) {
+	return []byte(m.String()), nil
+}
+
+// UnmarshalJSON reads a plain JSON number in the currency already set on the receiver, the base
+// currency otherwise. Digits beyond the currency's minor units are rounded half away from zero
+// so records written while amounts were float64 still load.
+func (m *Money) UnmarshalJSON(data []byte) error {
+	if string(data) == "null" {
+		return nil
+	}
+	var number json.Number
+	if err := json.Unmarshal(data, &number); err != nil {
+		return fmt.Errorf("failed to unmarshal money amount: %w", err)
+	}
+	currency := m.Currency()
+	minor, err := parseMinorUnits(number.String(), currencyExponent(currency))
+	if err != nil {
+		return err
+	}
+	m.minor, m.currency = minor, currency
+	return nil
+}
+
+// CurrencyTotals sums amounts of several currencies, keeping one total per ISO 4217 code so that
+// amounts of different currencies are never added together. It marshals to JSON as an object of
+// plain decimal numbers keyed by currency.
+type CurrencyTotals map[string]Money
+
+// Add adds an amount to the total of its currency
+func (t CurrencyTotals) Add(amount Money) {
+	currency := amount.Currency()
+	total, ok := t[currency]
+	if !ok {
+		total = ZeroMoney(currency)
+	}
+	t[currency] = Money{minor: total.minor + amount.minor, currency: currency}
+}
+
+// Currencies returns the currencies with a total, in code order
+func (t CurrencyTotals) Currencies() []string {
+	currencies := make([]string, 0, len(t))
+	for currency := range t {
+		currencies = append(currencies, currency)
+	}
+	sort.Strings(currencies)
+	return currencies
+}
+
+// UnmarshalJSON reads each total in the currency it is keyed by
+func (t *CurrencyTotals) UnmarshalJSON(data []byte) error {
+	var raw map[string]json.RawMessage
+	if err := json.Unmarshal(data, &raw); err != nil {
+		return fmt.Errorf("failed to unmarshal currency totals: %w", err)
+	}
+	if raw == nil {
+		*t = nil
+		return nil
+	}
+	totals := make(CurrencyTotals, len(raw))
+	for currency, value := range raw {
+		total := ZeroMoney(currency)
+		if err := total.UnmarshalJSON(value); err != nil {
+			return err
+		}
+		totals[currency] = total
+	}
+	*t = totals
+	return nil
+}
+
+// ToMinorUnits converts an amount to integer minor units of a currency with the given ISO 4217
+// exponent, rounding half away from zero. Amounts are held in minor units so every peer stores
+// the same value however the float was computed.
+func ToMinorUnits(amount float64, exponent int) int64 {
+	return int64(math.Round(amount * math.Pow10(exponent)))
+}
+
+// FromMinorUnits converts integer minor units of a currency with the given ISO 4217 exponent back
+// to an amount
+func FromMinorUnits(minorUnits int64, exponent int) float64 {
+	return float64(minorUnits) / math.Pow10(exponent)
+}
+
+func (m Money) sameCurrency(other Money) error {
+	if m.Currency() != other.Currency() {
+		return fmt.Errorf("currency mismatch: %s and %s", m.Currency(), other.Currency())
+	}
+	return nil
+}
+
+func moneyCurrency(currency string) string {
+	if currency == "" {
+		return config.BaseCurrency
+	}
+	return currency
+}
+
+// currencyExponent defaults to two minor units for currencies outside the supported list
+func currencyExponent(currency string) int {
+	if exponent, ok := validation.CurrencyMinorUnits[currency]; ok {
+		return exponent
+	}
+	return 2
+}
+
+// maxMinorUnitDigits is the most digits an amount in minor units may have and still fit an int64
+const maxMinorUnitDigits = 18
+
+// parseMinorUnits converts a decimal literal to minor units without going through float64,
+// rounding digits beyond the minor units half away from zero
+func parseMinorUnits(literal string, exponent int) (int64, error) {
+	value := strings.ToLower(literal)
+	scale := 0
+	if i := strings.IndexByte(value, 'e'); i >= 0 {
+		e, err := strconv.Atoi(value[i+1:])
+		if err != nil {
+			return 0, fmt.Errorf("invalid money amount %s", literal)
+		}
+		value, scale = value[:i], e
+	}
+	negative := strings.HasPrefix(value, "-")
+	value = strings.TrimPrefix(value, "-")
+	whole, fraction := value, ""
+	if i := strings.IndexByte(value, '.'); i >= 0 {
+		whole, fraction = value[:i], value[i+1:]
+	}
+	whole = strings.TrimLeft(whole, "0")
+	digits := whole + fraction
+	// Position of the decimal point within digits once scaled to minor units
+	point := len(whole) + scale + exponent
+	if point < 0 {
+		// Less than a tenth of a minor unit, which rounds to zero
+		return 0, nil
+	}
+	if point > maxMinorUnitDigits {
+		return 0, fmt.Errorf("money amount %s is too large", literal)
+	}
+	if point > len(digits) {
+		digits += strings.Repeat("0", point-len(digits))
+	}
+	kept, dropped := digits[:point], digits[point:]
+	if kept == "" {
+		kept = "0"
+	}
+	minor, err := strconv.ParseInt(kept, 10, 64)
+	if err != nil {
+		return 0, fmt.Errorf("invalid money amount %s", literal)
+	}
+	if dropped != "" && dropped[0] >= '5' {
+		minor++
+	}
+	if negative {
+		minor = -minor
+	}
+	return minor, nil
+}
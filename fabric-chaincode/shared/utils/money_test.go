@@ -0,0 +1,155 @@
+package utils
+
+import (
+	"encoding/json"
+	"testing"
+)
+
+func TestParseMinorUnits(t *testing.T) {
+	tests := []struct {
+		name     string
+		literal  string
+		exponent int
+		minor    int64
+		wantErr  bool
+	}{
+		{"whole amount", "125", 2, 12500, false},
+		{"exact cents", "12.34", 2, 1234, false},
+		{"fewer decimals than minor units", "12.3", 2, 1230, false},
+		{"more decimals rounds down below half", "12.344", 2, 1234, false},
+		{"more decimals rounds half away from zero", "12.345", 2, 1235, false},
+		{"float64 artefact rounds to the intended cent", "0.30000000000000004", 2, 30, false},
+		{"negative rounds half away from zero", "-12.345", 2, -1235, false},
+		{"negative below half", "-0.004", 2, 0, false},
+		{"negative half of a minor unit", "-0.005", 2, -1, false},
+		{"zero-exponent currency", "1500.5", 0, 1501, false},
+		{"three-exponent currency", "1.2345", 3, 1235, false},
+		{"exponent notation", "1.5e3", 2, 150000, false},
+		{"negative exponent notation", "15e-1", 2, 150, false},
+		{"far below a minor unit", "1e-30", 2, 0, false},
+		{"leading zeros", "000012.5", 2, 1250, false},
+		{"largest amount that fits", "9999999999999999.99", 2, 999999999999999999, false},
+		{"too large", "99999999999999999999", 2, 0, true},
+		{"huge exponent", "1e400", 2, 0, true},
+		{"malformed exponent", "1e", 2, 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			minor, err := parseMinorUnits(tt.literal, tt.exponent)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("parseMinorUnits(%q) = %d, want an error", tt.literal, minor)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("parseMinorUnits(%q) failed: %v", tt.literal, err)
+			}
+			if minor != tt.minor {
+				t.Errorf("parseMinorUnits(%q) = %d, want %d", tt.literal, minor, tt.minor)
+			}
+		})
+	}
+}
+
+func TestMoneyUnmarshalJSON(t *testing.T) {
+	tests := []struct {
+		name     string
+		json     string
+		currency string
+		minor    int64
+		wantErr  bool
+	}{
+		{"number", `12.34`, "USD", 1234, false},
+		{"string holding a number", `"12.34"`, "USD", 1234, false},
+		{"number with too many decimals is rounded", `12.345`, "USD", 1235, false},
+		{"string with too many decimals is rounded", `"12.345"`, "USD", 1235, false},
+		{"negative number", `-7.5`, "USD", -750, false},
+		{"zero-exponent currency", `1234.5`, "JPY", 1235, false},
+		{"three-exponent currency", `1.2345`, "KWD", 1235, false},
+		{"base currency when unset", `3.21`, "", 321, false},
+		{"string not holding a number", `"twelve"`, "USD", 0, true},
+		{"empty string", `""`, "USD", 0, true},
+		{"boolean", `true`, "USD", 0, true},
+		{"too large", `99999999999999999999`, "USD", 0, true},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			amount := ZeroMoney(tt.currency)
+			err := json.Unmarshal([]byte(tt.json), &amount)
+			if tt.wantErr {
+				if err == nil {
+					t.Fatalf("unmarshalling %s gave %s, want an error", tt.json, amount)
+				}
+				return
+			}
+			if err != nil {
+				t.Fatalf("unmarshalling %s failed: %v", tt.json, err)
+			}
+			if amount.MinorUnits() != tt.minor {
+				t.Errorf("unmarshalling %s gave %d minor units, want %d", tt.json, amount.MinorUnits(), tt.minor)
+			}
+			if amount.Currency() != ZeroMoney(tt.currency).Currency() {
+				t.Errorf("unmarshalling %s gave currency %s, want %s", tt.json, amount.Currency(), ZeroMoney(tt.currency).Currency())
+			}
+		})
+	}
+
+	// null leaves the amount as it was
+	amount := NewMoney(5, "USD")
+	if err := json.Unmarshal([]byte(`null`), &amount); err != nil {
+		t.Fatalf("unmarshalling null failed: %v", err)
+	}
+	if amount.MinorUnits() != 500 {
+		t.Errorf("unmarshalling null changed the amount to %s", amount)
+	}
+
+	// Amounts round-trip through their JSON form
+	for _, original := range []Money{MoneyFromMinorUnits(1234, "USD"), MoneyFromMinorUnits(-5, "USD"), MoneyFromMinorUnits(1235, "KWD"), MoneyFromMinorUnits(7, "JPY")} {
+		data, err := json.Marshal(original)
+		if err != nil {
+			t.Fatalf("marshalling %s failed: %v", original, err)
+		}
+		decoded := ZeroMoney(original.Currency())
+		if err := json.Unmarshal(data, &decoded); err != nil {
+			t.Fatalf("unmarshalling %s failed: %v", data, err)
+		}
+		if decoded != original {
+			t.Errorf("%s round-tripped as %s", original, decoded)
+		}
+	}
+}
+
+func TestCurrencyTotals(t *testing.T) {
+	totals := CurrencyTotals{}
+	totals.Add(NewMoney(100.25, "USD"))
+	totals.Add(NewMoney(1.125, "KWD"))
+	totals.Add(NewMoney(50.5, "USD"))
+	totals.Add(NewMoney(2.002, "KWD"))
+
+	// Each currency keeps its own total rather than being added to the others
+	if totals["USD"].String() != "150.75" || totals["KWD"].String() != "3.127" || len(totals) != 2 {
+		t.Fatalf("expected 150.75 USD and 3.127 KWD, got %v", totals)
+	}
+	if currencies := totals.Currencies(); len(currencies) != 2 || currencies[0] != "KWD" || currencies[1] != "USD" {
+		t.Errorf("expected currencies in code order, got %v", currencies)
+	}
+
+	// Totals round-trip through their JSON form in the currency they are keyed by
+	data, err := json.Marshal(totals)
+	if err != nil {
+		t.Fatalf("marshalling totals failed: %v", err)
+	}
+	if string(data) != `{"KWD":3.127,"USD":150.75}` {
+		t.Errorf("unexpected JSON %s", data)
+	}
+	var decoded CurrencyTotals
+	if err := json.Unmarshal(data, &decoded); err != nil {
+		t.Fatalf("unmarshalling %s failed: %v", data, err)
+	}
+	if decoded["KWD"] != totals["KWD"] || decoded["USD"] != totals["USD"] {
+		t.Errorf("%v round-tripped as %v", totals, decoded)
+	}
+}
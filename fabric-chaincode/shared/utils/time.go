@@ -17,16 +17,11 @@ func FormatTime(t time.Time) string {
 func ParseTime(timeStr string) (time.Time, error) {
 	t, err := time.Parse(TimeFormat, timeStr)
 	if err != nil {
-		return time.Time{}, fmt.Errorf("failed to parse time %s: %v", timeStr, err)
+		return time.Time{}, fmt.Errorf("failed to parse time %s: %w", timeStr, err)
 	}
 	return t, nil
 }
 
-// GetCurrentTimeString returns the current time as a formatted string
-func GetCurrentTimeString() string {
-	return FormatTime(time.Now())
-}
-
 // IsValidTimeRange checks if start time is before end time
 func IsValidTimeRange(start, end time.Time) error {
 	if start.After(end) {
@@ -56,9 +51,8 @@ func AddBusinessDays(start time.Time, days int) time.Time {
 	return current
 }
 
-// CalculateAge calculates age in years from date of birth
-func CalculateAge(dob time.Time) int {
-	now := time.Now()
+// CalculateAge calculates age in years from date of birth as of a time
+func CalculateAge(dob time.Time, now time.Time) int {
 	age := now.Year() - dob.Year()
 	
 	// Adjust if birthday hasn't occurred this year
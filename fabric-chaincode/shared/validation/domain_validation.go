@@ -20,6 +20,9 @@ const (
 	LoanStatusApproved      LoanApplicationStatus = "APPROVED"
 	LoanStatusRejected      LoanApplicationStatus = "REJECTED"
 	LoanStatusDisbursed     LoanApplicationStatus = "DISBURSED"
+	LoanStatusDelinquent    LoanApplicationStatus = "DELINQUENT"
+	LoanStatusDefaulted     LoanApplicationStatus = "DEFAULTED"
+	LoanStatusCancelled     LoanApplicationStatus = "CANCELLED"
 )
 
 // CustomerStatus represents valid customer statuses
@@ -51,6 +54,140 @@ const (
 	AMLStatusBlocked   AMLStatus = "BLOCKED"
 )
 
+// CounterpartyStatus represents counterparty lifecycle statuses
+type CounterpartyStatus string
+
+const (
+	CounterpartyStatusPending    CounterpartyStatus = "PENDING"
+	CounterpartyStatusActive     CounterpartyStatus = "ACTIVE"
+	CounterpartyStatusSuspended  CounterpartyStatus = "SUSPENDED"
+	CounterpartyStatusTerminated CounterpartyStatus = "TERMINATED"
+)
+
+// LoanPartyRole represents the role a customer holds on a loan application
+type LoanPartyRole string
+
+const (
+	LoanPartyRolePrimaryBorrower LoanPartyRole = "PRIMARY_BORROWER"
+	LoanPartyRoleCoBorrower      LoanPartyRole = "CO_BORROWER"
+	LoanPartyRoleGuarantor       LoanPartyRole = "GUARANTOR"
+)
+
+// ActorRole represents the role carried in an invoking identity's "role" certificate attribute
+type ActorRole string
+
+const (
+	ActorRoleUnderwriter            ActorRole = "Underwriter"
+	ActorRoleIntroducer             ActorRole = "Introducer"
+	ActorRoleComplianceOfficer      ActorRole = "Compliance_Officer"
+	ActorRoleCreditOfficer          ActorRole = "Credit_Officer"
+	ActorRoleCustomerServiceRep     ActorRole = "Customer_Service_Rep"
+	ActorRoleRiskAnalyst            ActorRole = "Risk_Analyst"
+	ActorRoleSystemAdministrator    ActorRole = "System_Administrator"
+	ActorRoleLoanOperationsManager  ActorRole = "Loan_Operations_Manager"
+	ActorRoleChiefComplianceOfficer ActorRole = "Chief_Compliance_Officer"
+	ActorRoleRegulator              ActorRole = "Regulator"
+)
+
+// Permission is a capability granted to actor roles beyond what a function's own checks allow
+type Permission string
+
+const (
+	PermissionViewPII Permission = "VIEW_PII" // Decrypt customer PII stored with field-level encryption
+)
+
+// RolePermissions lists the permissions each actor role holds; roles not listed hold none
+var RolePermissions = map[ActorRole][]Permission{
+	ActorRoleComplianceOfficer:      {PermissionViewPII},
+	ActorRoleChiefComplianceOfficer: {PermissionViewPII},
+	ActorRoleRegulator:              {PermissionViewPII},
+	ActorRoleCustomerServiceRep:     {PermissionViewPII},
+}
+
+// HasPermission reports whether the role holds a permission
+func (r ActorRole) HasPermission(permission Permission) bool {
+	for _, held := range RolePermissions[r] {
+		if held == permission {
+			return true
+		}
+	}
+	return false
+}
+
+// CredentialRotationMethod represents how an actor's off-chain credentials were rotated
+type CredentialRotationMethod string
+
+const (
+	CredentialRotationPasswordReset    CredentialRotationMethod = "PASSWORD_RESET"
+	CredentialRotationCertReenrollment CredentialRotationMethod = "CERTIFICATE_REENROLLMENT"
+	CredentialRotationKeyRotation      CredentialRotationMethod = "KEY_ROTATION"
+	CredentialRotationMFAReset         CredentialRotationMethod = "MFA_RESET"
+)
+
+// JournalDecisionType represents a decision sensitive enough to require regulator co-signature
+type JournalDecisionType string
+
+const (
+	JournalDecisionScreeningClearance JournalDecisionType = "SCREENING_CLEARANCE"
+	JournalDecisionSARFiling          JournalDecisionType = "SAR_FILING"
+	JournalDecisionWriteOff           JournalDecisionType = "WRITE_OFF"
+)
+
+// QADecisionType represents a completed decision that may be sampled for quality review
+type QADecisionType string
+
+const (
+	QADecisionLoanApproval       QADecisionType = "LOAN_APPROVAL"
+	QADecisionLoanRejection      QADecisionType = "LOAN_REJECTION"
+	QADecisionScreeningClearance QADecisionType = "SCREENING_CLEARANCE"
+)
+
+// NoteVisibility represents who may read an operational note left on an entity
+type NoteVisibility string
+
+const (
+	NoteVisibilityInternal       NoteVisibility = "INTERNAL"        // All staff
+	NoteVisibilityComplianceOnly NoteVisibility = "COMPLIANCE_ONLY" // Compliance officers and the regulator
+)
+
+// QAReviewOutcome represents a quality reviewer's verdict on a sampled decision
+type QAReviewOutcome string
+
+const (
+	QAReviewPass   QAReviewOutcome = "PASS"
+	QAReviewDefect QAReviewOutcome = "DEFECT"
+)
+
+// QADefectCode classifies what a quality reviewer found wrong with a decision
+type QADefectCode string
+
+const (
+	QADefectIncorrectDecision QADefectCode = "INCORRECT_DECISION"
+	QADefectPolicyBreach      QADefectCode = "POLICY_BREACH"
+	QADefectDocumentationGap  QADefectCode = "DOCUMENTATION_GAP"
+	QADefectMissingRationale  QADefectCode = "MISSING_RATIONALE"
+	QADefectDataEntryError    QADefectCode = "DATA_ENTRY_ERROR"
+	QADefectScreeningMiss     QADefectCode = "SCREENING_MISS"
+)
+
+// CreditInquiryType distinguishes pre-qualification pulls from full application pulls
+type CreditInquiryType string
+
+const (
+	CreditInquirySoft CreditInquiryType = "SOFT" // Pre-qualification; not visible to other lenders
+	CreditInquiryHard CreditInquiryType = "HARD" // Full application; requires credit check consent
+)
+
+// OriginationChannel represents the channel a loan application was submitted through
+type OriginationChannel string
+
+const (
+	OriginationChannelBranch    OriginationChannel = "BRANCH"
+	OriginationChannelMobile    OriginationChannel = "MOBILE"
+	OriginationChannelWeb       OriginationChannel = "WEB"
+	OriginationChannelBrokerAPI OriginationChannel = "BROKER_API"
+)
+
 // ValidateStatus checks if status is in allowed list
 func ValidateStatus(status string, allowedStatuses []string) error {
 	for _, allowed := range allowedStatuses {
@@ -70,6 +207,9 @@ func ValidateLoanApplicationStatus(status string) error {
 		string(LoanStatusApproved),
 		string(LoanStatusRejected),
 		string(LoanStatusDisbursed),
+		string(LoanStatusDelinquent),
+		string(LoanStatusDefaulted),
+		string(LoanStatusCancelled),
 	}
 	return ValidateStatus(status, validStatuses)
 }
@@ -106,6 +246,169 @@ func ValidateAMLStatus(status string) error {
 	return ValidateStatus(status, validStatuses)
 }
 
+// ValidateCounterpartyStatus checks if counterparty status is valid
+func ValidateCounterpartyStatus(status string) error {
+	validStatuses := []string{
+		string(CounterpartyStatusPending),
+		string(CounterpartyStatusActive),
+		string(CounterpartyStatusSuspended),
+		string(CounterpartyStatusTerminated),
+	}
+	return ValidateStatus(status, validStatuses)
+}
+
+// ValidateCounterpartyType checks if counterparty type is valid
+func ValidateCounterpartyType(counterpartyType string) error {
+	validTypes := []string{
+		"BANK",
+		"INVESTOR",
+		"SERVICER",
+	}
+	return ValidateStatus(counterpartyType, validTypes)
+}
+
+// ValidateCollateralType checks if collateral type is valid
+func ValidateCollateralType(collateralType string) error {
+	validTypes := []string{
+		"REAL_ESTATE",
+		"VEHICLE",
+		"DEPOSIT",
+		"SECURITIES",
+		"EQUIPMENT",
+		"OTHER",
+	}
+	return ValidateStatus(collateralType, validTypes)
+}
+
+// ValidateLoanPartyRole checks if an additional loan party role is valid
+func ValidateLoanPartyRole(role string) error {
+	validRoles := []string{
+		string(LoanPartyRoleCoBorrower),
+		string(LoanPartyRoleGuarantor),
+	}
+	return ValidateStatus(role, validRoles)
+}
+
+// ValidateJournalDecisionType checks if a decision journal entry type is valid
+func ValidateJournalDecisionType(decisionType string) error {
+	validTypes := []string{
+		string(JournalDecisionScreeningClearance),
+		string(JournalDecisionSARFiling),
+		string(JournalDecisionWriteOff),
+	}
+	return ValidateStatus(decisionType, validTypes)
+}
+
+// ValidateNoteVisibility checks if an entity note visibility is valid
+func ValidateNoteVisibility(visibility string) error {
+	validVisibilities := []string{
+		string(NoteVisibilityInternal),
+		string(NoteVisibilityComplianceOnly),
+	}
+	return ValidateStatus(visibility, validVisibilities)
+}
+
+// ValidateQADecisionType checks if a quality review decision type is valid
+func ValidateQADecisionType(decisionType string) error {
+	validTypes := []string{
+		string(QADecisionLoanApproval),
+		string(QADecisionLoanRejection),
+		string(QADecisionScreeningClearance),
+	}
+	return ValidateStatus(decisionType, validTypes)
+}
+
+// ValidateQAReviewOutcome checks if a quality review outcome is valid
+func ValidateQAReviewOutcome(outcome string) error {
+	validOutcomes := []string{
+		string(QAReviewPass),
+		string(QAReviewDefect),
+	}
+	return ValidateStatus(outcome, validOutcomes)
+}
+
+// ValidateQADefectCode checks if a quality review defect code is valid
+func ValidateQADefectCode(code string) error {
+	validCodes := []string{
+		string(QADefectIncorrectDecision),
+		string(QADefectPolicyBreach),
+		string(QADefectDocumentationGap),
+		string(QADefectMissingRationale),
+		string(QADefectDataEntryError),
+		string(QADefectScreeningMiss),
+	}
+	return ValidateStatus(code, validCodes)
+}
+
+// ValidateActorRole checks if an actor role is valid
+func ValidateActorRole(role string) error {
+	validRoles := []string{
+		string(ActorRoleUnderwriter),
+		string(ActorRoleIntroducer),
+		string(ActorRoleComplianceOfficer),
+		string(ActorRoleCreditOfficer),
+		string(ActorRoleCustomerServiceRep),
+		string(ActorRoleRiskAnalyst),
+		string(ActorRoleSystemAdministrator),
+		string(ActorRoleLoanOperationsManager),
+		string(ActorRoleChiefComplianceOfficer),
+		string(ActorRoleRegulator),
+	}
+	return ValidateStatus(role, validRoles)
+}
+
+// ValidateCredentialRotationMethod checks if a credential rotation method is valid
+func ValidateCredentialRotationMethod(method string) error {
+	validMethods := []string{
+		string(CredentialRotationPasswordReset),
+		string(CredentialRotationCertReenrollment),
+		string(CredentialRotationKeyRotation),
+		string(CredentialRotationMFAReset),
+	}
+	return ValidateStatus(method, validMethods)
+}
+
+// ValidateCreditInquiryType checks if a credit inquiry type is valid
+func ValidateCreditInquiryType(inquiryType string) error {
+	validTypes := []string{
+		string(CreditInquirySoft),
+		string(CreditInquiryHard),
+	}
+	return ValidateStatus(inquiryType, validTypes)
+}
+
+// ValidateOriginationChannel checks if a loan origination channel is valid
+func ValidateOriginationChannel(channel string) error {
+	validChannels := []string{
+		string(OriginationChannelBranch),
+		string(OriginationChannelMobile),
+		string(OriginationChannelWeb),
+		string(OriginationChannelBrokerAPI),
+	}
+	return ValidateStatus(channel, validChannels)
+}
+
+// ValidateDeviceHash checks that a device fingerprint is a hex-encoded SHA-256 digest. Raw
+// fingerprints never reach the ledger; clients hash them before submitting.
+func ValidateDeviceHash(deviceHash string) error {
+	if !regexp.MustCompile(`^[0-9a-f]{64}$`).MatchString(deviceHash) {
+		return fmt.Errorf("device hash must be a lower-case hex SHA-256 digest")
+	}
+	return nil
+}
+
+// ValidateDocumentType checks if loan document type is valid
+func ValidateDocumentType(documentType string) error {
+	validTypes := []string{
+		"IDENTITY",
+		"INCOME_PROOF",
+		"BANK_STATEMENT",
+		"COLLATERAL",
+		"OTHER",
+	}
+	return ValidateStatus(documentType, validTypes)
+}
+
 // ValidateLoanType checks if loan type is valid
 func ValidateLoanType(loanType string) error {
 	validTypes := []string{
@@ -119,6 +422,39 @@ func ValidateLoanType(loanType string) error {
 	return ValidateStatus(loanType, validTypes)
 }
 
+// ValidateEmail validates an email address against EmailRule
+func ValidateEmail(email string) error {
+	return EmailRule.Validator(email)
+}
+
+// ValidatePhone validates a phone number against PhoneRule
+func ValidatePhone(phone string) error {
+	return PhoneRule.Validator(phone)
+}
+
+// CurrencyMinorUnits gives the ISO 4217 minor-unit exponent of each currency loans may be made in
+var CurrencyMinorUnits = map[string]int{
+	"USD": 2,
+	"EUR": 2,
+	"GBP": 2,
+	"CHF": 2,
+	"CAD": 2,
+	"AUD": 2,
+	"SGD": 2,
+	"HKD": 2,
+	"JPY": 0,
+	"KWD": 3,
+	"BHD": 3,
+}
+
+// ValidateCurrency checks a currency is an upper-case ISO 4217 code loans may be made in
+func ValidateCurrency(currency string) error {
+	if _, ok := CurrencyMinorUnits[currency]; !ok {
+		return fmt.Errorf("unsupported currency %s", currency)
+	}
+	return nil
+}
+
 // ValidateNationalID validates national ID format (basic validation)
 func ValidateNationalID(nationalID string) error {
 	if len(nationalID) < 5 || len(nationalID) > 20 {
@@ -134,9 +470,8 @@ func ValidateNationalID(nationalID string) error {
 	return nil
 }
 
-// ValidateDateOfBirth validates date of birth
-func ValidateDateOfBirth(dob time.Time) error {
-	now := time.Now()
+// ValidateDateOfBirth validates date of birth as of a time, normally services.TxTime
+func ValidateDateOfBirth(dob time.Time, now time.Time) error {
 	
 	// Check if date is in the future
 	if dob.After(now) {
@@ -213,6 +548,31 @@ func ValidateLoanAmount(amount float64, loanType string) error {
 	return nil
 }
 
+// ValidateLoanToValue calculates the loan-to-value ratio (percent) and checks it against type-specific limits
+func ValidateLoanToValue(loanAmount, collateralValue float64, loanType string) (float64, error) {
+	// Maximum LTV for secured loan types; other types may be secured but are not required to be
+	maxLTV := map[string]float64{
+		"MORTGAGE": 95,
+		"AUTO":     100,
+		"BUSINESS": 80,
+	}
+	
+	limit, secured := maxLTV[loanType]
+	if collateralValue <= 0 {
+		if secured {
+			return 0, fmt.Errorf("%s loans require collateral", loanType)
+		}
+		return 0, nil
+	}
+	
+	ltv := loanAmount / collateralValue * 100
+	if secured && ltv > limit {
+		return ltv, fmt.Errorf("loan-to-value %.2f%% exceeds maximum %.2f%% for %s loans", ltv, limit, loanType)
+	}
+	
+	return ltv, nil
+}
+
 // ValidateStatusTransition checks if a status transition is valid
 func ValidateStatusTransition(currentStatus, newStatus string, entityType string) error {
 	var validTransitions map[string][]string
@@ -220,12 +580,15 @@ func ValidateStatusTransition(currentStatus, newStatus string, entityType string
 	switch entityType {
 	case "LoanApplication":
 		validTransitions = map[string][]string{
-			string(LoanStatusSubmitted):     {string(LoanStatusUnderwriting), string(LoanStatusRejected)},
-			string(LoanStatusUnderwriting):  {string(LoanStatusCreditApproval), string(LoanStatusRejected)},
-			string(LoanStatusCreditApproval): {string(LoanStatusApproved), string(LoanStatusRejected)},
-			string(LoanStatusApproved):      {string(LoanStatusDisbursed)},
+			string(LoanStatusSubmitted):     {string(LoanStatusUnderwriting), string(LoanStatusRejected), string(LoanStatusCancelled)},
+			string(LoanStatusUnderwriting):  {string(LoanStatusCreditApproval), string(LoanStatusRejected), string(LoanStatusCancelled)},
+			string(LoanStatusCreditApproval): {string(LoanStatusApproved), string(LoanStatusRejected), string(LoanStatusCancelled)},
+			string(LoanStatusApproved):      {string(LoanStatusDisbursed), string(LoanStatusCancelled)},
 			string(LoanStatusRejected):      {}, // Terminal state
-			string(LoanStatusDisbursed):     {}, // Terminal state
+			string(LoanStatusDisbursed):     {string(LoanStatusDelinquent), string(LoanStatusDefaulted)},
+			string(LoanStatusDelinquent):    {string(LoanStatusDisbursed), string(LoanStatusDefaulted)}, // Back to DISBURSED once arrears are cleared
+			string(LoanStatusDefaulted):     {}, // Terminal state
+			string(LoanStatusCancelled):     {}, // Terminal state
 		}
 	case "Customer":
 		validTransitions = map[string][]string{
@@ -233,6 +596,13 @@ func ValidateStatusTransition(currentStatus, newStatus string, entityType string
 			string(CustomerStatusInactive): {string(CustomerStatusActive)},
 			string(CustomerStatusSuspended): {string(CustomerStatusActive), string(CustomerStatusInactive)},
 		}
+	case "Counterparty":
+		validTransitions = map[string][]string{
+			string(CounterpartyStatusPending):    {string(CounterpartyStatusActive), string(CounterpartyStatusTerminated)},
+			string(CounterpartyStatusActive):     {string(CounterpartyStatusSuspended), string(CounterpartyStatusTerminated)},
+			string(CounterpartyStatusSuspended):  {string(CounterpartyStatusActive), string(CounterpartyStatusTerminated)},
+			string(CounterpartyStatusTerminated): {}, // Terminal state
+		}
 	default:
 		return fmt.Errorf("unknown entity type for status transition: %s", entityType)
 	}